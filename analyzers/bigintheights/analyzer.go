@@ -0,0 +1,193 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package bigintheights defines an analyzer which keeps heights and lengths of history commitments
+// from being handled as big integers outside the layer converting to and from the contract
+// bindings. The bindings represent heights and lengths as *big.Int, which says nothing about
+// whether a value is a height or a length and invites the off-by-one and unit mistakes of
+// mixing the two. Everywhere else they are protocol.Height and protocol.Length.
+//
+// The analyzer reports:
+//   - variables, parameters, results and struct fields of type big.Int or *big.Int named as a
+//     height or a length, and functions named as one which return a big integer.
+//   - reads of heights and lengths from the bindings, such as the fields of an edge or the
+//     layer zero heights of the challenge manager, unless converted right away with
+//     protocol.HeightFromBig or protocol.LengthFromBig. Writes into the bindings are allowed,
+//     as those convert from the typed values.
+//
+// Packages under the prefixes of the -allow flag, by default the bindings and the Solidity
+// implementation of the chain abstraction, are not checked.
+package bigintheights
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	modulePath   = "github.com/OffchainLabs/bold"
+	protocolPath = modulePath + "/chain-abstraction"
+	bindingsPath = modulePath + "/solgen/"
+)
+
+// Analyzer reports raw big integer heights and lengths outside the binding conversion layer.
+var Analyzer = &analysis.Analyzer{
+	Name:     "bigintheights",
+	Doc:      "reports heights and lengths handled as big integers outside the binding conversion layer",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var allow = modulePath + "/solgen/," + modulePath + "/chain-abstraction/sol-implementation"
+
+func init() {
+	Analyzer.Flags.StringVar(&allow, "allow", allow, "comma-separated package path prefixes which are not checked")
+}
+
+var heightOrLength = regexp.MustCompile(`(?i)height|length`)
+
+// The conversions from the bindings which raw heights and lengths may be passed to.
+var conversions = map[string]bool{
+	"HeightFromBig": true,
+	"LengthFromBig": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if allowed(pass.Pkg.Path()) {
+		return nil, nil
+	}
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodes := []ast.Node{
+		(*ast.Ident)(nil),
+		(*ast.SelectorExpr)(nil),
+	}
+	in.WithStack(nodes, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.Ident:
+			checkDeclaration(pass, n)
+		case *ast.SelectorExpr:
+			checkBindingRead(pass, n, stack)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+func allowed(pkgPath string) bool {
+	for _, prefix := range strings.Split(allow, ",") {
+		if prefix != "" && strings.HasPrefix(pkgPath, strings.TrimSpace(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports a big integer variable, field or function declared as a height or length.
+func checkDeclaration(pass *analysis.Pass, id *ast.Ident) {
+	obj := pass.TypesInfo.Defs[id]
+	if obj == nil || !heightOrLength.MatchString(id.Name) {
+		return
+	}
+	switch obj := obj.(type) {
+	case *types.Var:
+		if isBigInt(obj.Type()) {
+			pass.Reportf(id.Pos(), "%s is a big integer height or length, use protocol.Height or protocol.Length", id.Name)
+		}
+	case *types.Func:
+		if returnsBigInt(obj) {
+			pass.Reportf(id.Pos(), "%s returns a big integer height or length, use protocol.Height or protocol.Length", id.Name)
+		}
+	}
+}
+
+// Reports a height or length read from the bindings which is not converted right away.
+func checkBindingRead(pass *analysis.Pass, sel *ast.SelectorExpr, stack []ast.Node) {
+	if !heightOrLength.MatchString(sel.Sel.Name) {
+		return
+	}
+	obj := pass.TypesInfo.Uses[sel.Sel]
+	if obj == nil || obj.Pkg() == nil || !strings.HasPrefix(obj.Pkg().Path(), bindingsPath) {
+		return
+	}
+	// The node the value is used by, which for a method is the node using the call's result.
+	use := len(stack) - 2
+	switch obj := obj.(type) {
+	case *types.Var:
+		if !obj.IsField() || !isBigInt(obj.Type()) {
+			return
+		}
+	case *types.Func:
+		call, ok := parent(stack, use).(*ast.CallExpr)
+		if !ok || call.Fun != sel || !returnsBigInt(obj) {
+			return
+		}
+		use--
+	default:
+		return
+	}
+	switch p := parent(stack, use).(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range p.Lhs {
+			if lhs == sel {
+				return
+			}
+		}
+	case *ast.CallExpr:
+		if isConversion(pass, p.Fun) {
+			return
+		}
+	}
+	pass.Reportf(sel.Pos(), "%s is a big integer height or length from the bindings, convert it with protocol.HeightFromBig or protocol.LengthFromBig", sel.Sel.Name)
+}
+
+func parent(stack []ast.Node, i int) ast.Node {
+	if i < 0 {
+		return nil
+	}
+	return stack[i]
+}
+
+func isConversion(pass *analysis.Pass, fun ast.Expr) bool {
+	var id *ast.Ident
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return false
+	}
+	obj, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	return ok && obj.Pkg() != nil && obj.Pkg().Path() == protocolPath && conversions[obj.Name()]
+}
+
+func returnsBigInt(fn *types.Func) bool {
+	results := fn.Type().(*types.Signature).Results()
+	for i := 0; i < results.Len(); i++ {
+		if isBigInt(results.At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBigInt(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "math/big" && obj.Name() == "Int"
+}
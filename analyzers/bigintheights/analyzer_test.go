@@ -0,0 +1,175 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package bigintheights
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+var want = regexp.MustCompile("// want `([^`]*)`")
+
+// Checks the diagnostics on the testdata packages against their want comments, as analysistest
+// does, with the packages type checked from source.
+func TestAnalyzer(t *testing.T) {
+	fset := token.NewFileSet()
+	testdata := make(map[string]*types.Package)
+	std := importer.Default().(types.ImporterFrom)
+	imp := importerFunc(func(path, dir string, mode types.ImportMode) (*types.Package, error) {
+		if pkg, ok := testdata[path]; ok {
+			return pkg, nil
+		}
+		return std.ImportFrom(path, dir, mode)
+	})
+	load := func(path string) ([]*ast.File, *types.Package, *types.Info) {
+		dir := filepath.Join("testdata", "src", filepath.FromSlash(path))
+		names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		require.NoError(t, err)
+		files := make([]*ast.File, 0, len(names))
+		for _, name := range names {
+			f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+			require.NoError(t, err)
+			files = append(files, f)
+		}
+		pkg, info := typeCheck(t, fset, path, files, imp)
+		testdata[path] = pkg
+		return files, pkg, info
+	}
+	load("github.com/OffchainLabs/bold/chain-abstraction")
+	bindingFiles, bindings, bindingInfo := load("github.com/OffchainLabs/bold/solgen/go/challengeV2gen")
+	require.Empty(t, runAnalyzer(t, fset, bindingFiles, bindings, bindingInfo))
+
+	files, pkg, info := load("domain")
+	expected := make(map[int]*regexp.Regexp)
+	for _, f := range files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if m := want.FindStringSubmatch(c.Text); m != nil {
+					expected[fset.Position(c.Pos()).Line] = regexp.MustCompile(m[1])
+				}
+			}
+		}
+	}
+	for _, d := range runAnalyzer(t, fset, files, pkg, info) {
+		line := fset.Position(d.Pos).Line
+		re, ok := expected[line]
+		if !ok {
+			t.Errorf("unexpected diagnostic on line %d: %s", line, d.Message)
+			continue
+		}
+		require.Regexp(t, re, d.Message)
+		delete(expected, line)
+	}
+	for line, re := range expected {
+		t.Errorf("no diagnostic on line %d matching %s", line, re)
+	}
+}
+
+// Runs the analyzer over the packages of the module, as nogo does in Bazel builds.
+func TestModule(t *testing.T) {
+	if os.Getenv("TEST_SRCDIR") != "" {
+		t.Skip("checked by nogo under Bazel")
+	}
+	type listedPackage struct {
+		Dir        string
+		ImportPath string
+		Export     string
+		GoFiles    []string
+		Standard   bool
+		DepOnly    bool
+	}
+	cmd := exec.Command("go", "list", "-export", "-deps", "-json", "./...")
+	cmd.Dir = filepath.Join("..", "..")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	require.NoError(t, err, stderr.String())
+
+	exports := make(map[string]string)
+	var checked []*listedPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		pkg := &listedPackage{}
+		if err = dec.Decode(pkg); err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		exports[pkg.ImportPath] = pkg.Export
+		if !pkg.Standard && !pkg.DepOnly {
+			checked = append(checked, pkg)
+		}
+	}
+
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		return os.Open(exports[path])
+	})
+	for _, listed := range checked {
+		files := make([]*ast.File, 0, len(listed.GoFiles))
+		for _, name := range listed.GoFiles {
+			f, err := parser.ParseFile(fset, filepath.Join(listed.Dir, name), nil, parser.ParseComments)
+			require.NoError(t, err)
+			files = append(files, f)
+		}
+		pkg, info := typeCheck(t, fset, listed.ImportPath, files, imp)
+		for _, d := range runAnalyzer(t, fset, files, pkg, info) {
+			t.Errorf("%s: %s", fset.Position(d.Pos), d.Message)
+		}
+	}
+}
+
+type importerFunc func(path, dir string, mode types.ImportMode) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path, "", 0)
+}
+
+func (f importerFunc) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	return f(path, dir, mode)
+}
+
+func typeCheck(t *testing.T, fset *token.FileSet, path string, files []*ast.File, imp types.Importer) (*types.Package, *types.Info) {
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	pkg, err := (&types.Config{Importer: imp}).Check(path, fset, files, info)
+	require.NoError(t, err, path)
+	return pkg, info
+}
+
+func runAnalyzer(t *testing.T, fset *token.FileSet, files []*ast.File, pkg *types.Package, info *types.Info) []analysis.Diagnostic {
+	var diagnostics []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:  Analyzer,
+		Fset:      fset,
+		Files:     files,
+		Pkg:       pkg,
+		TypesInfo: info,
+		ResultOf:  map[*analysis.Analyzer]interface{}{inspect.Analyzer: inspector.New(files)},
+		Report: func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		},
+	}
+	_, err := Analyzer.Run(pass)
+	require.NoError(t, err)
+	return diagnostics
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"math/big"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+)
+
+type edge struct {
+	startHeight *big.Int // want `startHeight is a big integer height or length`
+	endHeight   protocol.Height
+	stake       *big.Int
+}
+
+func endHeight(e *challengeV2gen.ChallengeEdge) (protocol.Height, error) {
+	return protocol.HeightFromBig(e.EndHeight)
+}
+
+func startHeight(e *challengeV2gen.ChallengeEdge) uint64 {
+	return e.StartHeight.Uint64() // want `StartHeight is a big integer height or length from the bindings`
+}
+
+func bisectionLength(start, end *big.Int) big.Int { // want `bisectionLength returns a big integer height or length`
+	var length big.Int // want `length is a big integer height or length`
+	length.Sub(end, start)
+	return length
+}
+
+func layerZeroHeight(c *challengeV2gen.EdgeChallengeManagerCaller) (protocol.Height, error) {
+	h, err := c.LAYERZEROBLOCKEDGEHEIGHT() // want `LAYERZEROBLOCKEDGEHEIGHT is a big integer height or length from the bindings`
+	if err != nil {
+		return 0, err
+	}
+	return protocol.HeightFromBig(h)
+}
+
+func matches(ev *challengeV2gen.EdgeChallengeManagerEdgeAdded, want protocol.Length) bool {
+	got, err := protocol.LengthFromBig(ev.Length)
+	return err == nil && got == want
+}
+
+func edgeArgs(h protocol.Height) *challengeV2gen.ChallengeEdge {
+	e := &challengeV2gen.ChallengeEdge{EndHeight: h.Big()}
+	e.StartHeight = big.NewInt(0)
+	return e
+}
@@ -0,0 +1,19 @@
+package protocol
+
+import "math/big"
+
+type Height uint64
+
+type Length uint64
+
+func HeightFromBig(h *big.Int) (Height, error) {
+	return Height(h.Uint64()), nil
+}
+
+func LengthFromBig(l *big.Int) (Length, error) {
+	return Length(l.Uint64()), nil
+}
+
+func (h Height) Big() *big.Int {
+	return new(big.Int).SetUint64(uint64(h))
+}
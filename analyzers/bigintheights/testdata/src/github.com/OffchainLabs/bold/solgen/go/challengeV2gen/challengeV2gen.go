@@ -0,0 +1,19 @@
+package challengeV2gen
+
+import "math/big"
+
+type ChallengeEdge struct {
+	StartHeight *big.Int
+	EndHeight   *big.Int
+	Level       uint8
+}
+
+type EdgeChallengeManagerCaller struct{}
+
+func (c *EdgeChallengeManagerCaller) LAYERZEROBLOCKEDGEHEIGHT() (*big.Int, error) {
+	return big.NewInt(32), nil
+}
+
+type EdgeChallengeManagerEdgeAdded struct {
+	Length *big.Int
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AuditChainError identifies the first audit entry that breaks the chain of hashes.
+type AuditChainError struct {
+	Seq    uint64
+	Reason string
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("audit entry %d %s", e.Seq, e.Reason)
+}
+
+// ComputeHash computes the hash of an audit entry, which commits to all of its fields
+// and to the hash of the previous entry. Variable length fields are length prefixed, so
+// that no two different entries share an encoding.
+func (e *JsonAuditEntry) ComputeHash() common.Hash {
+	var buf [8]byte
+	data := make([]byte, 0, 32+16+len(e.Principal)+len(e.Action)+len(e.Inputs)+len(e.Result)+len(e.Error)+5*8)
+	data = append(data, e.PrevHash.Bytes()...)
+	binary.BigEndian.PutUint64(buf[:], e.Seq)
+	data = append(data, buf[:]...)
+	binary.BigEndian.PutUint64(buf[:], uint64(e.Timestamp.UnixNano()))
+	data = append(data, buf[:]...)
+	for _, field := range []string{e.Principal, e.Action, e.Inputs, e.Result, e.Error} {
+		binary.BigEndian.PutUint64(buf[:], uint64(len(field)))
+		data = append(data, buf[:]...)
+		data = append(data, field...)
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// VerifyAuditChain checks that consecutive audit entries are numbered in sequence, that each links
+// to the hash of the one before it, starting from prevHash, and that each hash matches its contents.
+// The hash of the entry before the first one in the log is the zero hash.
+func VerifyAuditChain(entries []*JsonAuditEntry, prevHash common.Hash) error {
+	for i, e := range entries {
+		if i > 0 && e.Seq != entries[i-1].Seq+1 {
+			return &AuditChainError{Seq: e.Seq, Reason: fmt.Sprintf("follows entry %d", entries[i-1].Seq)}
+		}
+		if e.PrevHash != prevHash {
+			return &AuditChainError{Seq: e.Seq, Reason: fmt.Sprintf("links to %#x, expected %#x", e.PrevHash, prevHash)}
+		}
+		if computed := e.ComputeHash(); e.Hash != computed {
+			return &AuditChainError{Seq: e.Seq, Reason: fmt.Sprintf("has hash %#x, but its contents hash to %#x", e.Hash, computed)}
+		}
+		prevHash = e.Hash
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+)
+
+const (
+	defaultAnalyticsRows  = 1000
+	maxAnalyticsRows      = 10000
+	analyticsQueryTimeout = 30 * time.Second
+)
+
+// QueryAnalytics runs an ad-hoc read-only query against the database, returning up to maxRows
+// rows. A maxRows of zero returns the default number of rows, and larger values are capped.
+func (b *Backend) QueryAnalytics(ctx context.Context, query string, maxRows int) (*api.JsonAnalyticsQueryResult, error) {
+	if maxRows <= 0 {
+		maxRows = defaultAnalyticsRows
+	}
+	maxRows = min(maxRows, maxAnalyticsRows)
+	ctx, cancel := context.WithTimeout(ctx, analyticsQueryTimeout)
+	defer cancel()
+	return b.db.QueryAnalytics(ctx, query, maxRows)
+}
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The number of audit entries read from the database at a time when verifying the log.
+const auditVerificationPageSize = 1000
+
+func (b *Backend) GetAuditEntries(_ context.Context, opts ...db.AuditEntryOption) ([]*api.JsonAuditEntry, error) {
+	return b.db.GetAuditEntries(opts...)
+}
+
+// VerifyAuditLog checks the chain of hashes of the entire audit log, starting from the first entry.
+// A broken chain is reported in the result rather than as an error, which is reserved for failing
+// to read the log. Entries removed from the end of the log can only be detected by comparing the
+// head hash against one exported previously.
+func (b *Backend) VerifyAuditLog(ctx context.Context) (*api.JsonAuditVerification, error) {
+	result := &api.JsonAuditVerification{Valid: true}
+	prevHash := common.Hash{}
+	nextSeq := uint64(1)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entries, err := b.db.GetAuditEntries(
+			db.WithAuditEntriesFromSeq(nextSeq),
+			db.WithAuditEntryLimit(auditVerificationPageSize),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return result, nil
+		}
+		if entries[0].Seq != nextSeq {
+			return invalidAuditLog(result, &api.AuditChainError{Seq: nextSeq, Reason: "is missing"}), nil
+		}
+		if err = api.VerifyAuditChain(entries, prevHash); err != nil {
+			return invalidAuditLog(result, err), nil
+		}
+		last := entries[len(entries)-1]
+		result.NumEntries += uint64(len(entries))
+		result.HeadHash = last.Hash
+		prevHash = last.Hash
+		nextSeq = last.Seq + 1
+	}
+}
+
+func invalidAuditLog(result *api.JsonAuditVerification, err error) *api.JsonAuditVerification {
+	result.Valid = false
+	result.Error = err.Error()
+	var chainErr *api.AuditChainError
+	if errors.As(err, &chainErr) {
+		result.FirstInvalidSeq = chainErr.Seq
+	}
+	return result
+}
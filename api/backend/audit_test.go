@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAuditLog(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "bold.db"))
+	require.NoError(t, err)
+	b := &Backend{db: database}
+
+	verification, err := b.VerifyAuditLog(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &api.JsonAuditVerification{Valid: true}, verification)
+
+	logger := db.NewAuditLogger(database, "staker")
+	for i := 0; i < 3; i++ {
+		logger.Record(api.AuditActionBisectEdge, map[string]any{"i": i}, nil, nil)
+	}
+	entries, err := b.GetAuditEntries(ctx)
+	require.NoError(t, err)
+	verification, err = b.VerifyAuditLog(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &api.JsonAuditVerification{
+		Valid:      true,
+		NumEntries: 3,
+		HeadHash:   entries[2].Hash,
+	}, verification)
+
+	// Removing an entry breaks the chain at the entry after it.
+	b.db = &auditEntryRemovingDatabase{ReadUpdateDatabase: database, removedSeq: 2}
+	verification, err = b.VerifyAuditLog(ctx)
+	require.NoError(t, err)
+	require.False(t, verification.Valid)
+	require.Equal(t, uint64(3), verification.FirstInvalidSeq)
+}
+
+type auditEntryRemovingDatabase struct {
+	db.ReadUpdateDatabase
+	removedSeq uint64
+}
+
+func (d *auditEntryRemovingDatabase) GetAuditEntries(opts ...db.AuditEntryOption) ([]*api.JsonAuditEntry, error) {
+	entries, err := d.ReadUpdateDatabase.GetAuditEntries(opts...)
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]*api.JsonAuditEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.Seq != d.removedSeq {
+			kept = append(kept, e)
+		}
+	}
+	return kept, nil
+}
@@ -12,11 +12,13 @@ import (
 
 	"github.com/OffchainLabs/bold/api"
 	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/api/stateexport"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	watcher "github.com/OffchainLabs/bold/challenge-manager/chain-watcher"
 	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
 	"github.com/OffchainLabs/bold/containers/option"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 type BusinessLogicProvider interface {
@@ -26,6 +28,28 @@ type BusinessLogicProvider interface {
 	GetTrackedRoyalEdges(ctx context.Context) ([]*api.JsonEdgesByChallengedAssertion, error)
 	GetMiniStakes(ctx context.Context, assertionHash protocol.AssertionHash, opts ...db.EdgeOption) (*api.JsonMiniStakes, error)
 	LatestConfirmedAssertion(ctx context.Context) (*api.JsonAssertion, error)
+	GetStakeEvents(ctx context.Context, opts ...db.StakeEventOption) ([]*api.JsonStakeEvent, error)
+	GetStakeExposure(ctx context.Context, interval StakeExposureInterval, opts ...db.StakeEventOption) ([]*api.JsonStakeExposure, error)
+	GetValidatorAllowlist(ctx context.Context) (*api.JsonValidatorAllowlist, error)
+	GetProtocolInfo(ctx context.Context) (*api.JsonProtocolInfo, error)
+	GetAuditEntries(ctx context.Context, opts ...db.AuditEntryOption) ([]*api.JsonAuditEntry, error)
+	VerifyAuditLog(ctx context.Context) (*api.JsonAuditVerification, error)
+	GetChallengeExplanation(ctx context.Context, assertionHash protocol.AssertionHash) (*api.JsonChallengeExplanation, error)
+	GetDisputeStats(ctx context.Context, opts ...db.AssertionOption) (*api.JsonDisputeStatsFeed, error)
+	GetTrackerDecision(ctx context.Context, edgeId protocol.EdgeId, blockNumber option.Option[uint64]) (*api.JsonTrackerDecision, error)
+	ExportChallengeState(ctx context.Context, assertionHash protocol.AssertionHash) (*stateexport.ChallengeState, error)
+	GetRequiredActions(ctx context.Context, withinBlocks uint64) (*api.JsonRequiredActions, error)
+	GetStuckTxs(ctx context.Context) ([]*api.JsonStuckTx, error)
+	ResolveStuckTx(ctx context.Context, txHash common.Hash) error
+	GetPoison(ctx context.Context) (*api.JsonPoison, error)
+	ClearPoison(ctx context.Context) error
+	WithdrawEdge(ctx context.Context, edgeId protocol.EdgeId) (*api.JsonEdgeWithdrawal, error)
+	GetConfirmationMethods(ctx context.Context) (*api.JsonConfirmationMethods, error)
+	SetConfirmationMethodEnabled(ctx context.Context, method string, enabled bool) (*api.JsonConfirmationMethods, error)
+	GetLoadShedding(ctx context.Context) (*api.JsonLoadShedding, error)
+	GetLatencyBudget(ctx context.Context) (*api.JsonLatencyBudget, error)
+	QueryAnalytics(ctx context.Context, query string, maxRows int) (*api.JsonAnalyticsQueryResult, error)
+	SearchByHashPrefix(ctx context.Context, prefix db.HashPrefix, limit int) (*api.JsonHashSearchResults, error)
 }
 
 type EdgeTrackerFetcher interface {
@@ -93,6 +117,9 @@ func (b *Backend) GetAssertions(ctx context.Context, opts ...db.AssertionOption)
 			return nil, err
 		}
 	}
+	for _, a := range assertions {
+		a.DecodeStates()
+	}
 	return assertions, nil
 }
 
@@ -153,6 +180,13 @@ func (b *Backend) GetEdges(ctx context.Context, opts ...db.EdgeOption) ([]*api.J
 			if err != nil {
 				return nil, err
 			}
+			if status == protocol.EdgeConfirmed && e.ConfirmedAtBlock == nil {
+				confirmedAtBlock, err := edge.ConfirmedAtBlock(ctx)
+				if err != nil {
+					return nil, err
+				}
+				e.ConfirmedAtBlock = &confirmedAtBlock
+			}
 			hasRival, err := edge.HasRival(ctx)
 			if err != nil {
 				return nil, err
@@ -210,6 +244,7 @@ func (b *Backend) GetEdges(ctx context.Context, opts ...db.EdgeOption) ([]*api.J
 				if fsmState.Error != nil {
 					e.FSMError = fsmState.Error.Error()
 				}
+				e.BlockedBy, e.BlockedByError = jsonBlockedBy(ctx, trackerOpt.Unwrap())
 			}
 		}
 		if err := b.db.UpdateEdges(edges); err != nil {
@@ -219,6 +254,31 @@ func (b *Backend) GetEdges(ctx context.Context, opts ...db.EdgeOption) ([]*api.J
 	return edges, nil
 }
 
+type blockingReasonsSource interface {
+	BlockedBy(ctx context.Context) ([]*edgetracker.BlockingReason, error)
+}
+
+// Lists why an edge is blocked, or why that could not be determined, so that one edge failing to
+// be analyzed does not fail the response for every other edge.
+func jsonBlockedBy(ctx context.Context, source blockingReasonsSource) ([]*api.JsonBlockingReason, string) {
+	blockedBy, err := source.BlockedBy(ctx)
+	if err != nil {
+		log.Warn("Could not determine what blocks an edge", "err", err)
+		return nil, err.Error()
+	}
+	reasons := make([]*api.JsonBlockingReason, len(blockedBy))
+	for i, reason := range blockedBy {
+		reasons[i] = &api.JsonBlockingReason{
+			Kind:   reason.Kind.String(),
+			Detail: reason.Detail,
+		}
+		if reason.BlockingEdge.IsSome() {
+			reasons[i].BlockingEdgeId = reason.BlockingEdge.Unwrap().Hex()
+		}
+	}
+	return reasons, ""
+}
+
 func (b *Backend) GetMiniStakes(ctx context.Context, assertionHash protocol.AssertionHash, opts ...db.EdgeOption) (*api.JsonMiniStakes, error) {
 	edgeOpts := opts
 	edgeOpts = append(
@@ -273,6 +333,83 @@ func (b *Backend) GetTrackedRoyalEdges(ctx context.Context) ([]*api.JsonEdgesByC
 	return edgesByAssertion, nil
 }
 
+func (b *Backend) GetValidatorAllowlist(ctx context.Context) (*api.JsonValidatorAllowlist, error) {
+	allowlist, err := b.chainDataFetcher.ValidatorAllowlist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	staker := b.chainDataFetcher.StakerAddress()
+	return &api.JsonValidatorAllowlist{
+		Enabled:       allowlist.Enabled,
+		Validators:    allowlist.Validators,
+		Staker:        staker,
+		StakerAllowed: allowlist.IsAllowed(staker),
+	}, nil
+}
+
+// GetProtocolInfo reads the protocol constants of the challenge manager, naming each challenge level.
+func (b *Backend) GetProtocolInfo(ctx context.Context) (*api.JsonProtocolInfo, error) {
+	challengeManager, err := b.chainDataFetcher.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	numBigStepLevels, err := challengeManager.NumBigSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	challengePeriodBlocks, err := challengeManager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stakeToken, err := challengeManager.StakeToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	heights, err := challengeManager.LayerZeroHeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// There is one block level and one small step level in addition to the big step levels.
+	numLevels := int(numBigStepLevels) + 2
+	stakeAmounts, err := challengeManager.StakeAmounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	withdrawalSupported, err := challengeManager.SupportsEdgeWithdrawal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(stakeAmounts) != numLevels {
+		return nil, fmt.Errorf("got %d stake amounts for %d challenge levels", len(stakeAmounts), numLevels)
+	}
+	levels := make([]*api.JsonChallengeLevelInfo, numLevels)
+	for i := 0; i < numLevels; i++ {
+		level := protocol.ChallengeLevel(i)
+		stakeAmount := stakeAmounts[i]
+		height := heights.BigStepChallengeHeight
+		if level.IsBlockChallengeLevel() {
+			height = heights.BlockChallengeHeight
+		} else if i == numLevels-1 {
+			height = heights.SmallStepChallengeHeight
+		}
+		levels[i] = &api.JsonChallengeLevelInfo{
+			Level:           level.Uint8(),
+			Name:            level.Name(numBigStepLevels),
+			LayerZeroHeight: height,
+			StakeAmount:     stakeAmount.String(),
+		}
+	}
+	return &api.JsonProtocolInfo{
+		ChallengeManager:        challengeManager.Address(),
+		NumBigStepLevels:        numBigStepLevels,
+		ChallengePeriodBlocks:   challengePeriodBlocks,
+		StakeToken:              stakeToken,
+		ChallengeStakeCost:      protocol.ChallengeStakeCost(stakeAmounts, protocol.NewBlockChallengeLevel()).String(),
+		Levels:                  levels,
+		EdgeWithdrawalSupported: withdrawalSupported,
+	}, nil
+}
+
 func (b *Backend) LatestConfirmedAssertion(ctx context.Context) (*api.JsonAssertion, error) {
 	latestConfirmedAssertion, err := b.chainDataFetcher.LatestConfirmed(ctx)
 	if err != nil {
@@ -305,31 +442,24 @@ func (b *Backend) LatestConfirmedAssertion(ctx context.Context) (*api.JsonAssert
 	}
 	beforeState := protocol.GoExecutionStateFromSolidity(creationInfo.BeforeState)
 	afterState := protocol.GoExecutionStateFromSolidity(creationInfo.AfterState)
-	return &api.JsonAssertion{
-		Hash:                     hash.Hash,
-		ConfirmPeriodBlocks:      creationInfo.ConfirmPeriodBlocks,
-		RequiredStake:            creationInfo.RequiredStake.String(),
-		ParentAssertionHash:      creationInfo.ParentAssertionHash,
-		InboxMaxCount:            creationInfo.InboxMaxCount.String(),
-		AfterInboxBatchAcc:       creationInfo.AfterInboxBatchAcc,
-		WasmModuleRoot:           creationInfo.WasmModuleRoot,
-		TransactionHash:          creationInfo.TransactionHash,
-		CreationBlock:            creationInfo.CreationBlock,
-		ChallengeManager:         creationInfo.ChallengeManager,
-		AfterStateBlockHash:      afterState.GlobalState.BlockHash,
-		AfterStateSendRoot:       afterState.GlobalState.SendRoot,
-		AfterStateBatch:          afterState.GlobalState.Batch,
-		AfterStatePosInBatch:     afterState.GlobalState.PosInBatch,
-		AfterStateMachineStatus:  afterState.MachineStatus,
-		BeforeStateBlockHash:     beforeState.GlobalState.BlockHash,
-		BeforeStateSendRoot:      beforeState.GlobalState.SendRoot,
-		BeforeStateBatch:         beforeState.GlobalState.Batch,
-		BeforeStatePosInBatch:    beforeState.GlobalState.PosInBatch,
-		BeforeStateMachineStatus: beforeState.MachineStatus,
-		IsFirstChild:             isFirstChild,
-		FirstChildBlock:          &firstChildBlock,
-		SecondChildBlock:         &secondChildBlock,
-		Status:                   status.String(),
-		LastUpdatedAt:            time.Now(),
-	}, nil
+	assertion := &api.JsonAssertion{
+		Hash:                hash.Hash,
+		ConfirmPeriodBlocks: creationInfo.ConfirmPeriodBlocks,
+		RequiredStake:       creationInfo.RequiredStake.String(),
+		ParentAssertionHash: creationInfo.ParentAssertionHash,
+		InboxMaxCount:       creationInfo.InboxMaxCount.String(),
+		AfterInboxBatchAcc:  creationInfo.AfterInboxBatchAcc,
+		WasmModuleRoot:      creationInfo.WasmModuleRoot,
+		TransactionHash:     creationInfo.TransactionHash,
+		CreationBlock:       creationInfo.CreationBlock,
+		ChallengeManager:    creationInfo.ChallengeManager,
+		IsFirstChild:        isFirstChild,
+		FirstChildBlock:     &firstChildBlock,
+		SecondChildBlock:    &secondChildBlock,
+		Status:              status.String(),
+		LastUpdatedAt:       time.Now(),
+	}
+	assertion.SetStates(beforeState, afterState)
+	assertion.DecodeStates()
+	return assertion, nil
 }
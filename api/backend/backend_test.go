@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProtocolInfo(t *testing.T) {
+	ctx := context.Background()
+	stakeToken := common.BytesToAddress([]byte("token"))
+	challengeManager := &mocks.MockSpecChallengeManager{MockAddr: common.BytesToAddress([]byte("manager"))}
+	challengeManager.On("NumBigSteps", ctx).Return(uint8(2), nil)
+	challengeManager.On("ChallengePeriodBlocks", ctx).Return(uint64(100), nil)
+	challengeManager.On("StakeToken", ctx).Return(stakeToken, nil)
+	challengeManager.On("LayerZeroHeights", ctx).Return(&protocol.LayerZeroHeights{
+		BlockChallengeHeight:     32,
+		BigStepChallengeHeight:   16,
+		SmallStepChallengeHeight: 8,
+	}, nil)
+	challengeManager.On("StakeAmounts", ctx).Return([]*big.Int{big.NewInt(10), big.NewInt(9), big.NewInt(8), big.NewInt(7)}, nil)
+	challengeManager.On("SupportsEdgeWithdrawal", ctx).Return(false, nil)
+	chain := &mocks.MockProtocol{}
+	chain.On("SpecChallengeManager", ctx).Return(challengeManager, nil)
+
+	info, err := NewBackend(nil, chain, nil, nil).GetProtocolInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &api.JsonProtocolInfo{
+		ChallengeManager:      challengeManager.MockAddr,
+		NumBigStepLevels:      2,
+		ChallengePeriodBlocks: 100,
+		StakeToken:            stakeToken,
+		ChallengeStakeCost:    "34",
+		Levels: []*api.JsonChallengeLevelInfo{
+			{Level: 0, Name: "block", LayerZeroHeight: 32, StakeAmount: "10"},
+			{Level: 1, Name: "big_step_1", LayerZeroHeight: 16, StakeAmount: "9"},
+			{Level: 2, Name: "big_step_2", LayerZeroHeight: 16, StakeAmount: "8"},
+			{Level: 3, Name: "small_step", LayerZeroHeight: 8, StakeAmount: "7"},
+		},
+	}, info)
+}
+
+type fakeBlockingReasons struct {
+	reasons []*edgetracker.BlockingReason
+	err     error
+}
+
+func (f *fakeBlockingReasons) BlockedBy(context.Context) ([]*edgetracker.BlockingReason, error) {
+	return f.reasons, f.err
+}
+
+func TestJsonBlockedBy(t *testing.T) {
+	ctx := context.Background()
+	childId := protocol.EdgeId{Hash: common.BytesToHash([]byte("child"))}
+	reasons, errMsg := jsonBlockedBy(ctx, &fakeBlockingReasons{reasons: []*edgetracker.BlockingReason{
+		{Kind: edgetracker.BlockedByUnconfirmedChild, BlockingEdge: option.Some(childId), Detail: "child is pending"},
+		{Kind: edgetracker.BlockedByInsufficientTimer, BlockingEdge: option.None[protocol.EdgeId](), Detail: "onchain timer is 1, need 2"},
+	}})
+	require.Empty(t, errMsg)
+	require.Equal(t, []*api.JsonBlockingReason{
+		{Kind: "unconfirmed_child", BlockingEdgeId: childId.Hex(), Detail: "child is pending"},
+		{Kind: "insufficient_timer", Detail: "onchain timer is 1, need 2"},
+	}, reasons)
+
+	// A failure is reported on the edge rather than failing the response for every edge.
+	reasons, errMsg = jsonBlockedBy(ctx, &fakeBlockingReasons{err: errors.New("rpc down")})
+	require.Nil(t, reasons)
+	require.Equal(t, "rpc down", errMsg)
+}
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+)
+
+// ErrNoConfirmationMethods is returned when toggling a confirmation method of a challenge manager
+// whose confirmation methods cannot be changed at runtime.
+var ErrNoConfirmationMethods = errors.New("the confirmation methods of the challenge manager cannot be changed")
+
+// ConfirmationMethodToggler is implemented by challenge managers whose confirmation methods may be
+// disabled at runtime, such as the challenge manager of the challenge-manager package.
+type ConfirmationMethodToggler interface {
+	ConfirmationMethods() *types.ConfirmationMethods
+}
+
+// GetConfirmationMethods lists the enabled and disabled confirmation methods. Every method is
+// enabled if the challenge manager cannot disable them.
+func (b *Backend) GetConfirmationMethods(_ context.Context) (*api.JsonConfirmationMethods, error) {
+	var methods *types.ConfirmationMethods
+	if toggler, ok := b.trackerFetcher.(ConfirmationMethodToggler); ok {
+		methods = toggler.ConfirmationMethods()
+	}
+	resp := &api.JsonConfirmationMethods{
+		Enabled:      []string{},
+		Disabled:     []string{},
+		CanBeToggled: []string{},
+	}
+	for _, m := range types.AllConfirmationMethods() {
+		if methods.Enabled(m) {
+			resp.Enabled = append(resp.Enabled, string(m))
+		} else {
+			resp.Disabled = append(resp.Disabled, string(m))
+		}
+		if m.CanBeDisabled() {
+			resp.CanBeToggled = append(resp.CanBeToggled, string(m))
+		}
+	}
+	return resp, nil
+}
+
+// SetConfirmationMethodEnabled enables or disables a confirmation method, and returns the resulting
+// confirmation methods. Edge trackers route around the methods disabled from their next action.
+func (b *Backend) SetConfirmationMethodEnabled(ctx context.Context, method string, enabled bool) (*api.JsonConfirmationMethods, error) {
+	m, err := types.ParseConfirmationMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	toggler, ok := b.trackerFetcher.(ConfirmationMethodToggler)
+	if !ok || toggler.ConfirmationMethods() == nil {
+		return nil, ErrNoConfirmationMethods
+	}
+	if err = toggler.ConfirmationMethods().SetEnabled(m, enabled); err != nil {
+		return nil, err
+	}
+	return b.GetConfirmationMethods(ctx)
+}
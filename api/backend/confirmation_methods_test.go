@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	"github.com/stretchr/testify/require"
+)
+
+type confirmationMethodsManager struct {
+	EdgeTrackerFetcher
+	methods *types.ConfirmationMethods
+}
+
+func (m *confirmationMethodsManager) ConfirmationMethods() *types.ConfirmationMethods {
+	return m.methods
+}
+
+func TestConfirmationMethods(t *testing.T) {
+	ctx := context.Background()
+	methods, err := types.NewConfirmationMethods(types.ConfirmByClaim)
+	require.NoError(t, err)
+	b := NewBackend(nil, nil, nil, &confirmationMethodsManager{methods: methods})
+
+	resp, err := b.GetConfirmationMethods(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &api.JsonConfirmationMethods{
+		Enabled:      []string{"time", "one-step-proof", "children"},
+		Disabled:     []string{"claim"},
+		CanBeToggled: []string{"claim", "children"},
+	}, resp)
+
+	resp, err = b.SetConfirmationMethodEnabled(ctx, "children", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"claim", "children"}, resp.Disabled)
+	require.False(t, methods.Enabled(types.ConfirmByChildren))
+
+	resp, err = b.SetConfirmationMethodEnabled(ctx, "claim", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"children"}, resp.Disabled)
+	require.Equal(t, []types.ConfirmationMethod{types.ConfirmByChildren}, methods.Disabled())
+
+	_, err = b.SetConfirmationMethodEnabled(ctx, "time", false)
+	require.ErrorContains(t, err, "cannot be disabled")
+	_, err = b.SetConfirmationMethodEnabled(ctx, "bisection", false)
+	require.ErrorContains(t, err, "unknown confirmation method")
+	require.True(t, methods.Enabled(types.ConfirmByTime))
+}
+
+func TestConfirmationMethods_NotToggleable(t *testing.T) {
+	ctx := context.Background()
+	b := NewBackend(nil, nil, nil, nil)
+
+	resp, err := b.GetConfirmationMethods(ctx)
+	require.NoError(t, err)
+	require.Empty(t, resp.Disabled)
+	require.Len(t, resp.Enabled, len(types.AllConfirmationMethods()))
+
+	_, err = b.SetConfirmationMethodEnabled(ctx, "claim", false)
+	require.ErrorIs(t, err, ErrNoConfirmationMethods)
+}
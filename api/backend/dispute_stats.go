@@ -0,0 +1,266 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GetDisputeStats aggregates the challenges on every challenged assertion matching the options
+// into a feed suitable for public dashboards.
+func (b *Backend) GetDisputeStats(ctx context.Context, opts ...db.AssertionOption) (*api.JsonDisputeStatsFeed, error) {
+	assertions, err := b.db.GetChallengedAssertions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	events, err := b.db.GetStakeEvents(db.WithStakeEventSource(api.StakeSourceEdge))
+	if err != nil {
+		return nil, err
+	}
+	stakes, err := indexEdgeStakes(events)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*disputeStats, 0, len(assertions))
+	for _, a := range assertions {
+		edges, err := b.db.GetEdges(db.WithEdgeAssertionHash(protocol.AssertionHash{Hash: a.Hash}))
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, aggregateDisputeStats(a.Hash, edges, stakes))
+	}
+	return disputeStatsFeed(time.Now(), stats), nil
+}
+
+// The mini-stake locked in an edge, and the amount refunded from it if it was.
+type edgeStake struct {
+	amount      *big.Int
+	lockedAt    time.Time
+	blockNumber uint64
+	logIndex    uint
+	refunded    *big.Int
+}
+
+// Whether the stake was locked before another, in the order the challenge manager processed them.
+func (s *edgeStake) lockedBefore(other *edgeStake) bool {
+	if s.blockNumber != other.blockNumber {
+		return s.blockNumber < other.blockNumber
+	}
+	return s.logIndex < other.logIndex
+}
+
+// Indexes the mini-stakes locked in edges and their refunds by edge id.
+func indexEdgeStakes(events []*api.JsonStakeEvent) (map[common.Hash]*edgeStake, error) {
+	stakes := make(map[common.Hash]*edgeStake)
+	refunds := make(map[common.Hash]*big.Int)
+	for _, e := range events {
+		if e.Source != api.StakeSourceEdge {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(e.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("could not parse stake amount %q", e.Amount)
+		}
+		switch e.Kind {
+		case api.StakeEventLocked:
+			stakes[e.EdgeId] = &edgeStake{amount: amount, lockedAt: e.Timestamp, blockNumber: e.BlockNumber, logIndex: e.LogIndex}
+		case api.StakeEventRefunded:
+			refunds[e.EdgeId] = amount
+		}
+	}
+	for edgeId, amount := range refunds {
+		if stake, ok := stakes[edgeId]; ok {
+			stake.refunded = amount
+		}
+	}
+	return stakes, nil
+}
+
+// Finds the level zero edges created while their mutual already had a staked edge, whose
+// mini-stakes the challenge manager sends to its excess stake receiver instead of holding them.
+// Only the first edge of a mutual keeps its stake in the contract, to be refunded if confirmed.
+func edgesWithExcessStake(edges []*api.JsonEdge, stakes map[common.Hash]*edgeStake) map[common.Hash]bool {
+	first := make(map[common.Hash]common.Hash)
+	for _, e := range edges {
+		stake, ok := stakes[e.Id]
+		if !ok || e.ClaimId == (common.Hash{}) {
+			continue
+		}
+		firstId, ok := first[e.MutualId]
+		if !ok || stake.lockedBefore(stakes[firstId]) {
+			first[e.MutualId] = e.Id
+		}
+	}
+	excess := make(map[common.Hash]bool)
+	for _, e := range edges {
+		if _, ok := stakes[e.Id]; !ok || e.ClaimId == (common.Hash{}) {
+			continue
+		}
+		if first[e.MutualId] != e.Id {
+			excess[e.Id] = true
+		}
+	}
+	return excess
+}
+
+// Stats on the challenges of an assertion, along with the sums needed to total them up.
+type disputeStats struct {
+	*api.JsonDisputeStats
+	resolutionTime time.Duration
+	// Resolved challenges whose opening time is known, which the average is taken over.
+	numTimed  int64
+	forfeited *big.Int
+	refunded  *big.Int
+	excess    *big.Int
+}
+
+// Identifies a challenge, which level zero edges share the challenge level and origin id of.
+type challengeKey struct {
+	level    uint8
+	originId common.Hash
+}
+
+type challengeProgress struct {
+	openedAt   time.Time
+	resolvedAt time.Time
+	locked     *big.Int
+	refunded   *big.Int
+	excess     *big.Int
+	forfeited  *big.Int
+}
+
+func aggregateDisputeStats(assertionHash common.Hash, edges []*api.JsonEdge, stakes map[common.Hash]*edgeStake) *disputeStats {
+	stats := &disputeStats{
+		JsonDisputeStats: &api.JsonDisputeStats{
+			ChallengedAssertionHash: assertionHash,
+		},
+		forfeited: new(big.Int),
+		refunded:  new(big.Int),
+		excess:    new(big.Int),
+	}
+	excess := edgesWithExcessStake(edges, stakes)
+	confirmedMutualIds := make(map[common.Hash]bool)
+	for _, e := range edges {
+		if e.Status == protocol.EdgeConfirmed.String() {
+			confirmedMutualIds[e.MutualId] = true
+		}
+	}
+	challenges := make(map[challengeKey]*challengeProgress)
+	for _, e := range edges {
+		stats.EdgesCreated++
+		confirmed := e.Status == protocol.EdgeConfirmed.String()
+		if confirmed {
+			stats.EdgesConfirmed++
+		}
+		// Only level zero edges open challenges and carry mini-stakes.
+		if e.ClaimId == (common.Hash{}) {
+			continue
+		}
+		key := challengeKey{level: e.ChallengeLevel, originId: e.OriginId}
+		challenge, ok := challenges[key]
+		if !ok {
+			challenge = &challengeProgress{
+				locked:    new(big.Int),
+				refunded:  new(big.Int),
+				excess:    new(big.Int),
+				forfeited: new(big.Int),
+			}
+			challenges[key] = challenge
+		}
+		if confirmed {
+			challenge.resolvedAt = e.LastUpdatedAt
+		}
+		stake, staked := stakes[e.Id]
+		if !staked {
+			continue
+		}
+		if challenge.openedAt.IsZero() || stake.lockedAt.Before(challenge.openedAt) {
+			challenge.openedAt = stake.lockedAt
+		}
+		challenge.locked.Add(challenge.locked, stake.amount)
+		if stake.refunded != nil {
+			challenge.refunded.Add(challenge.refunded, stake.refunded)
+		}
+		if excess[e.Id] {
+			challenge.excess.Add(challenge.excess, stake.amount)
+		}
+		if !confirmed && confirmedMutualIds[e.MutualId] {
+			challenge.forfeited.Add(challenge.forfeited, stake.amount)
+		}
+	}
+	stats.ChallengesOpened = uint64(len(challenges))
+	for key, c := range challenges {
+		stats.forfeited.Add(stats.forfeited, c.forfeited)
+		stats.refunded.Add(stats.refunded, c.refunded)
+		stats.excess.Add(stats.excess, c.excess)
+		stats.Challenges = append(stats.Challenges, &api.JsonChallengeOutcome{
+			ChallengeLevel:            key.level,
+			OriginId:                  key.originId,
+			Resolved:                  !c.resolvedAt.IsZero(),
+			StakeLocked:               c.locked.String(),
+			StakeRefunded:             c.refunded.String(),
+			StakeSentToExcessReceiver: c.excess.String(),
+			AttackerStakeForfeited:    c.forfeited.String(),
+		})
+		if c.resolvedAt.IsZero() {
+			continue
+		}
+		stats.ChallengesResolved++
+		if c.openedAt.IsZero() || c.resolvedAt.Before(c.openedAt) {
+			continue
+		}
+		stats.resolutionTime += c.resolvedAt.Sub(c.openedAt)
+		stats.numTimed++
+	}
+	if stats.numTimed > 0 {
+		stats.AverageResolutionSeconds = uint64((stats.resolutionTime / time.Duration(stats.numTimed)).Seconds())
+	}
+	sort.Slice(stats.Challenges, func(i, j int) bool {
+		if stats.Challenges[i].ChallengeLevel != stats.Challenges[j].ChallengeLevel {
+			return stats.Challenges[i].ChallengeLevel < stats.Challenges[j].ChallengeLevel
+		}
+		return bytes.Compare(stats.Challenges[i].OriginId.Bytes(), stats.Challenges[j].OriginId.Bytes()) < 0
+	})
+	stats.AttackerStakeForfeited = stats.forfeited.String()
+	stats.StakeRefunded = stats.refunded.String()
+	stats.StakeSentToExcessReceiver = stats.excess.String()
+	return stats
+}
+
+func disputeStatsFeed(generatedAt time.Time, stats []*disputeStats) *api.JsonDisputeStatsFeed {
+	feed := &api.JsonDisputeStatsFeed{
+		GeneratedAt: generatedAt.UTC(),
+		Totals:      &api.JsonDisputeStats{},
+		Assertions:  make([]*api.JsonDisputeStats, len(stats)),
+	}
+	var resolutionTime time.Duration
+	var numTimed int64
+	forfeited, refunded, excess := new(big.Int), new(big.Int), new(big.Int)
+	for i, s := range stats {
+		feed.Assertions[i] = s.JsonDisputeStats
+		feed.Totals.ChallengesOpened += s.ChallengesOpened
+		feed.Totals.ChallengesResolved += s.ChallengesResolved
+		feed.Totals.EdgesCreated += s.EdgesCreated
+		feed.Totals.EdgesConfirmed += s.EdgesConfirmed
+		resolutionTime += s.resolutionTime
+		numTimed += s.numTimed
+		forfeited.Add(forfeited, s.forfeited)
+		refunded.Add(refunded, s.refunded)
+		excess.Add(excess, s.excess)
+	}
+	if numTimed > 0 {
+		feed.Totals.AverageResolutionSeconds = uint64((resolutionTime / time.Duration(numTimed)).Seconds())
+	}
+	feed.Totals.AttackerStakeForfeited = forfeited.String()
+	feed.Totals.StakeRefunded = refunded.String()
+	feed.Totals.StakeSentToExcessReceiver = excess.String()
+	return feed
+}
@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateDisputeStats(t *testing.T) {
+	start := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	hash := func(s string) common.Hash {
+		return common.BytesToHash([]byte(s))
+	}
+	assertion := hash("assertion")
+	edges := []*api.JsonEdge{
+		// A block challenge, won by the honest edge an hour after it was opened.
+		{Id: hash("honest"), ClaimId: hash("a"), OriginId: assertion, MutualId: hash("block"), Status: "confirmed", LastUpdatedAt: start.Add(time.Hour)},
+		{Id: hash("evil"), ClaimId: hash("b"), OriginId: assertion, MutualId: hash("block"), Status: "pending"},
+		// A child of the honest edge, which carries no stake.
+		{Id: hash("child"), OriginId: assertion, MutualId: hash("child"), Status: "confirmed"},
+		// A big step subchallenge which is still ongoing.
+		{Id: hash("honest-big"), ChallengeLevel: 1, ClaimId: hash("child"), OriginId: hash("block"), MutualId: hash("big"), Status: "pending"},
+		{Id: hash("evil-big"), ChallengeLevel: 1, ClaimId: hash("c"), OriginId: hash("block"), MutualId: hash("big"), Status: "pending"},
+	}
+	events := []*api.JsonStakeEvent{
+		// Refunds may be listed before the stakes they refund.
+		{Source: api.StakeSourceEdge, Kind: api.StakeEventRefunded, EdgeId: hash("honest"), Amount: "100", BlockNumber: 9, Timestamp: start.Add(2 * time.Hour)},
+		{Source: api.StakeSourceEdge, Kind: api.StakeEventLocked, EdgeId: hash("honest"), Amount: "100", BlockNumber: 1, Timestamp: start},
+		// The rival of the honest edge, whose stake goes to the excess stake receiver.
+		{Source: api.StakeSourceEdge, Kind: api.StakeEventLocked, EdgeId: hash("evil"), Amount: "100", BlockNumber: 2, Timestamp: start.Add(time.Minute)},
+		// Rivals created in the same block are ordered by their logs.
+		{Source: api.StakeSourceEdge, Kind: api.StakeEventLocked, EdgeId: hash("evil-big"), Amount: "10", BlockNumber: 3, LogIndex: 5, Timestamp: start},
+		{Source: api.StakeSourceEdge, Kind: api.StakeEventLocked, EdgeId: hash("honest-big"), Amount: "10", BlockNumber: 3, LogIndex: 2, Timestamp: start},
+		{Source: api.StakeSourceAssertion, Kind: api.StakeEventLocked, Amount: "1000", Timestamp: start},
+	}
+	stakes, err := indexEdgeStakes(events)
+	require.NoError(t, err)
+
+	stats := aggregateDisputeStats(assertion, edges, stakes)
+	require.Equal(t, &api.JsonDisputeStats{
+		ChallengedAssertionHash:   assertion,
+		ChallengesOpened:          2,
+		ChallengesResolved:        1,
+		EdgesCreated:              5,
+		EdgesConfirmed:            2,
+		AverageResolutionSeconds:  3600,
+		AttackerStakeForfeited:    "100",
+		StakeRefunded:             "100",
+		StakeSentToExcessReceiver: "110",
+		Challenges: []*api.JsonChallengeOutcome{
+			{
+				OriginId:                  assertion,
+				Resolved:                  true,
+				StakeLocked:               "200",
+				StakeRefunded:             "100",
+				StakeSentToExcessReceiver: "100",
+				AttackerStakeForfeited:    "100",
+			},
+			{
+				ChallengeLevel:            1,
+				OriginId:                  hash("block"),
+				StakeLocked:               "20",
+				StakeRefunded:             "0",
+				StakeSentToExcessReceiver: "10",
+				AttackerStakeForfeited:    "0",
+			},
+		},
+	}, stats.JsonDisputeStats)
+
+	// A second assertion whose challenge took three hours to resolve.
+	other := aggregateDisputeStats(hash("other"), []*api.JsonEdge{
+		{Id: hash("other-honest"), ClaimId: hash("d"), OriginId: hash("other"), MutualId: hash("other"), Status: "confirmed", LastUpdatedAt: start.Add(3 * time.Hour)},
+	}, map[common.Hash]*edgeStake{hash("other-honest"): {amount: common.Big1, lockedAt: start}})
+	feed := disputeStatsFeed(start, []*disputeStats{stats, other})
+	require.Equal(t, start, feed.GeneratedAt)
+	require.Len(t, feed.Assertions, 2)
+	require.Equal(t, &api.JsonDisputeStats{
+		ChallengesOpened:          3,
+		ChallengesResolved:        2,
+		EdgesCreated:              6,
+		EdgesConfirmed:            3,
+		AverageResolutionSeconds:  7200,
+		AttackerStakeForfeited:    "100",
+		StakeRefunded:             "100",
+		StakeSentToExcessReceiver: "110",
+	}, feed.Totals)
+
+	_, err = indexEdgeStakes([]*api.JsonStakeEvent{{Source: api.StakeSourceEdge, Kind: api.StakeEventLocked, Amount: "foo"}})
+	require.ErrorContains(t, err, "could not parse stake amount")
+}
@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoEdgeWithdrawal is returned when withdrawing an edge through a challenge manager which
+// cannot withdraw edges.
+var ErrNoEdgeWithdrawal = errors.New("the challenge manager cannot withdraw edges")
+
+// EdgeWithdrawer is implemented by challenge managers which withdraw the edges staked by the
+// validator on request, such as the challenge manager of the challenge-manager package.
+type EdgeWithdrawer interface {
+	WithdrawEdge(ctx context.Context, edgeId protocol.EdgeId) (*gethtypes.Transaction, error)
+}
+
+// WithdrawEdge withdraws a layer zero edge staked by the validator to recover its stake, if the
+// contract supports it and the edge passes the withdrawal policy of the challenge manager.
+func (b *Backend) WithdrawEdge(ctx context.Context, edgeId protocol.EdgeId) (*api.JsonEdgeWithdrawal, error) {
+	withdrawer, ok := b.trackerFetcher.(EdgeWithdrawer)
+	if !ok {
+		return nil, ErrNoEdgeWithdrawal
+	}
+	tx, err := withdrawer.WithdrawEdge(ctx, edgeId)
+	if err != nil {
+		return nil, err
+	}
+	return &api.JsonEdgeWithdrawal{
+		EdgeId:          edgeId.Hash,
+		TransactionHash: tx.Hash(),
+	}, nil
+}
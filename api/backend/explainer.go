@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strconv"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GetChallengeExplanation narrates the state of the challenge on an assertion from the edges
+// stored in the database, such as "Rival disagrees at block 12,345; we are at the big-step level,
+// 3 bisections from length one; our timer leads by 140 blocks."
+func (b *Backend) GetChallengeExplanation(ctx context.Context, assertionHash protocol.AssertionHash) (*api.JsonChallengeExplanation, error) {
+	challengeManager, err := b.chainDataFetcher.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	numBigStepLevels, err := challengeManager.NumBigSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := b.db.GetEdges(db.WithEdgeAssertionHash(assertionHash))
+	if err != nil {
+		return nil, err
+	}
+	return explainChallenge(assertionHash, edges, numBigStepLevels), nil
+}
+
+func explainChallenge(assertionHash protocol.AssertionHash, edges []*api.JsonEdge, numBigStepLevels uint8) *api.JsonChallengeExplanation {
+	explanation := &api.JsonChallengeExplanation{
+		ChallengedAssertionHash: assertionHash.Hash,
+	}
+	var numRoyal int
+	var frontier *api.JsonEdge
+	for _, e := range edges {
+		if !e.IsRoyal {
+			continue
+		}
+		numRoyal++
+		if e.HasRival && isCloserToOneStepProof(e, frontier) {
+			frontier = e
+		}
+	}
+	if numRoyal == 0 {
+		explanation.Narrative = "We have no edges in this challenge."
+		return explanation
+	}
+	if frontier == nil {
+		explanation.Narrative = fmt.Sprintf("No rival disagrees with any of our %d edges in this challenge.", numRoyal)
+		return explanation
+	}
+	tree := newEdgeTree(edges)
+	level := protocol.ChallengeLevel(frontier.ChallengeLevel)
+	explanation.Level = frontier.ChallengeLevel
+	explanation.LevelName = level.Name(numBigStepLevels)
+	explanation.DisagreementEdgeId = frontier.Id
+	explanation.DisagreementStartHeight = frontier.StartHeight
+	explanation.DisagreementEndHeight = frontier.EndHeight
+	explanation.BisectionsToLengthOne = uint64(bits.Len64(frontier.EndHeight - frontier.StartHeight - 1))
+	explanation.OurPathTimer = tree.pathTimer(frontier, true)
+	for _, e := range edges {
+		if e.MutualId == frontier.MutualId && e.Id != frontier.Id {
+			explanation.RivalPathTimer = max(explanation.RivalPathTimer, tree.pathTimer(e, false))
+		}
+	}
+	explanation.TimerLead = int64(explanation.OurPathTimer) - int64(explanation.RivalPathTimer)
+	explanation.Narrative = fmt.Sprintf(
+		"Rival disagrees %s; we are at %s, %s; %s.",
+		disagreementPhrase(level, frontier.StartHeight, frontier.EndHeight, numBigStepLevels),
+		levelPhrase(level, numBigStepLevels),
+		bisectionsPhrase(level, explanation.BisectionsToLengthOne, numBigStepLevels),
+		timerPhrase(explanation.TimerLead),
+	)
+	return explanation
+}
+
+// Whether a rivaled edge is closer to a one step proof than the current candidate, by being at a
+// deeper challenge level or having a shorter range.
+func isCloserToOneStepProof(e, candidate *api.JsonEdge) bool {
+	if candidate == nil {
+		return true
+	}
+	if e.ChallengeLevel != candidate.ChallengeLevel {
+		return e.ChallengeLevel > candidate.ChallengeLevel
+	}
+	length, candidateLength := e.EndHeight-e.StartHeight, candidate.EndHeight-candidate.StartHeight
+	if length != candidateLength {
+		return length < candidateLength
+	}
+	return e.StartHeight < candidate.StartHeight
+}
+
+// Links the edges of a challenge to their parents. Children may have two parents, as a child can
+// be shared by rival edges, and level zero edges of a subchallenge have the edge they claim as
+// their parent.
+type edgeTree struct {
+	byId    map[common.Hash]*api.JsonEdge
+	parents map[common.Hash][]*api.JsonEdge
+}
+
+func newEdgeTree(edges []*api.JsonEdge) *edgeTree {
+	t := &edgeTree{
+		byId:    make(map[common.Hash]*api.JsonEdge, len(edges)),
+		parents: make(map[common.Hash][]*api.JsonEdge),
+	}
+	for _, e := range edges {
+		t.byId[e.Id] = e
+		for _, child := range []common.Hash{e.LowerChildId, e.UpperChildId} {
+			if child != (common.Hash{}) {
+				t.parents[child] = append(t.parents[child], e)
+			}
+		}
+	}
+	return t
+}
+
+// Sums the time each edge was unrivaled along the path from an edge to the root of the challenge,
+// following royal parents if royal is set, or other parents otherwise, where there is a choice.
+func (t *edgeTree) pathTimer(e *api.JsonEdge, royal bool) uint64 {
+	var timer uint64
+	visited := make(map[common.Hash]bool)
+	for e != nil && !visited[e.Id] {
+		visited[e.Id] = true
+		timer += e.TimeUnrivaled
+		parents := t.parents[e.Id]
+		switch {
+		case len(parents) == 0 && e.ClaimId != (common.Hash{}):
+			e = t.byId[e.ClaimId]
+		case len(parents) == 0:
+			e = nil
+		default:
+			e = parents[0]
+			for _, p := range parents {
+				if p.IsRoyal == royal {
+					e = p
+					break
+				}
+			}
+		}
+	}
+	return timer
+}
+
+func levelPhrase(level protocol.ChallengeLevel, numBigStepLevels uint8) string {
+	switch {
+	case level.IsBlockChallengeLevel():
+		return "the block level"
+	case uint8(level) == numBigStepLevels+1:
+		return "the small-step level"
+	case numBigStepLevels == 1:
+		return "the big-step level"
+	default:
+		return fmt.Sprintf("big-step level %d of %d", level, numBigStepLevels)
+	}
+}
+
+// The unit the heights of edges at a level count.
+func heightUnit(level protocol.ChallengeLevel, numBigStepLevels uint8) string {
+	switch {
+	case level.IsBlockChallengeLevel():
+		return "block"
+	case uint8(level) == numBigStepLevels+1:
+		return "step"
+	default:
+		return "big step"
+	}
+}
+
+// Rivals agree on the start of the edge, so they disagree at its end once it has length one, or
+// somewhere within its range otherwise.
+func disagreementPhrase(level protocol.ChallengeLevel, start, end uint64, numBigStepLevels uint8) string {
+	unit := heightUnit(level, numBigStepLevels)
+	if end-start == 1 {
+		return fmt.Sprintf("at %s %s", unit, formatNumber(end))
+	}
+	return fmt.Sprintf("between %ss %s and %s", unit, formatNumber(start+1), formatNumber(end))
+}
+
+func bisectionsPhrase(level protocol.ChallengeLevel, bisections uint64, numBigStepLevels uint8) string {
+	switch {
+	case bisections == 0 && uint8(level) == numBigStepLevels+1:
+		return "at length one and ready for a one step proof"
+	case bisections == 0:
+		return "at length one and ready to open a subchallenge"
+	case bisections == 1:
+		return "1 bisection from length one"
+	default:
+		return fmt.Sprintf("%d bisections from length one", bisections)
+	}
+}
+
+func timerPhrase(lead int64) string {
+	switch {
+	case lead > 0:
+		return fmt.Sprintf("our timer leads by %s blocks", formatNumber(uint64(lead)))
+	case lead < 0:
+		return fmt.Sprintf("our timer trails by %s blocks", formatNumber(uint64(-lead)))
+	default:
+		return "our timer is level with the rival's"
+	}
+}
+
+// Formats a number with thousands separators, such as 12,345.
+func formatNumber(n uint64) string {
+	s := strconv.FormatUint(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func hashOf(s string) common.Hash {
+	return common.BytesToHash([]byte(s))
+}
+
+func TestExplainChallenge(t *testing.T) {
+	assertionHash := protocol.AssertionHash{Hash: hashOf("assertion")}
+	// The royal and rival level zero block edges share a lower child, which has no rival.
+	blockEdges := []*api.JsonEdge{
+		{Id: hashOf("r0"), EndHeight: 32, MutualId: hashOf("m0"), IsRoyal: true, HasRival: true, TimeUnrivaled: 10, LowerChildId: hashOf("r1"), UpperChildId: hashOf("r2")},
+		{Id: hashOf("e0"), EndHeight: 32, MutualId: hashOf("m0"), HasRival: true, TimeUnrivaled: 5, LowerChildId: hashOf("r1"), UpperChildId: hashOf("e2")},
+		{Id: hashOf("r1"), EndHeight: 16, MutualId: hashOf("m1"), IsRoyal: true, TimeUnrivaled: 100},
+		{Id: hashOf("r2"), StartHeight: 16, EndHeight: 32, MutualId: hashOf("m2"), IsRoyal: true, HasRival: true, TimeUnrivaled: 150},
+		{Id: hashOf("e2"), StartHeight: 16, EndHeight: 32, MutualId: hashOf("m2"), HasRival: true, TimeUnrivaled: 20},
+	}
+
+	t.Run("no edges", func(t *testing.T) {
+		explanation := explainChallenge(assertionHash, nil, 1)
+		require.Equal(t, "We have no edges in this challenge.", explanation.Narrative)
+	})
+	t.Run("no rivals", func(t *testing.T) {
+		explanation := explainChallenge(assertionHash, []*api.JsonEdge{blockEdges[2]}, 1)
+		require.Equal(t, "No rival disagrees with any of our 1 edges in this challenge.", explanation.Narrative)
+	})
+	t.Run("block level", func(t *testing.T) {
+		explanation := explainChallenge(assertionHash, blockEdges, 1)
+		require.Equal(t, &api.JsonChallengeExplanation{
+			ChallengedAssertionHash: assertionHash.Hash,
+			Level:                   0,
+			LevelName:               "block",
+			DisagreementEdgeId:      hashOf("r2"),
+			DisagreementStartHeight: 16,
+			DisagreementEndHeight:   32,
+			BisectionsToLengthOne:   4,
+			OurPathTimer:            160,
+			RivalPathTimer:          25,
+			TimerLead:               135,
+			Narrative:               "Rival disagrees between blocks 17 and 32; we are at the block level, 4 bisections from length one; our timer leads by 135 blocks.",
+		}, explanation)
+
+		// A child shared by rivals is on the path of both.
+		tree := newEdgeTree(blockEdges)
+		require.Equal(t, uint64(110), tree.pathTimer(blockEdges[2], true))
+		require.Equal(t, uint64(105), tree.pathTimer(blockEdges[2], false))
+	})
+	t.Run("big step level", func(t *testing.T) {
+		edges := append([]*api.JsonEdge{
+			{Id: hashOf("rb"), StartHeight: 20, EndHeight: 21, MutualId: hashOf("mb"), IsRoyal: true, HasRival: true, TimeUnrivaled: 1},
+			{Id: hashOf("eb"), StartHeight: 20, EndHeight: 21, MutualId: hashOf("mb"), HasRival: true, TimeUnrivaled: 3},
+			{Id: hashOf("b0"), ChallengeLevel: 1, EndHeight: 1 << 14, MutualId: hashOf("mb0"), ClaimId: hashOf("rb"), IsRoyal: true, HasRival: true, TimeUnrivaled: 2000},
+			{Id: hashOf("eb0"), ChallengeLevel: 1, EndHeight: 1 << 14, MutualId: hashOf("mb0"), ClaimId: hashOf("eb"), HasRival: true},
+		}, blockEdges...)
+		explanation := explainChallenge(assertionHash, edges, 1)
+		require.Equal(t, hashOf("b0"), explanation.DisagreementEdgeId)
+		require.Equal(t, "big_step_1", explanation.LevelName)
+		require.Equal(t, "Rival disagrees between big steps 1 and 16,384; we are at the big-step level, 14 bisections from length one; our timer leads by 1,998 blocks.", explanation.Narrative)
+
+		explanation = explainChallenge(assertionHash, edges, 2)
+		require.Contains(t, explanation.Narrative, "we are at big-step level 1 of 2")
+	})
+	t.Run("small step level at length one", func(t *testing.T) {
+		edges := []*api.JsonEdge{
+			{Id: hashOf("s"), ChallengeLevel: 3, StartHeight: 5, EndHeight: 6, MutualId: hashOf("ms"), IsRoyal: true, HasRival: true},
+			{Id: hashOf("es"), ChallengeLevel: 3, StartHeight: 5, EndHeight: 6, MutualId: hashOf("ms"), HasRival: true, TimeUnrivaled: 7},
+		}
+		explanation := explainChallenge(assertionHash, edges, 2)
+		require.Equal(t, int64(-7), explanation.TimerLead)
+		require.Equal(t, "Rival disagrees at step 6; we are at the small-step level, at length one and ready for a one step proof; our timer trails by 7 blocks.", explanation.Narrative)
+	})
+}
+
+func TestFormatNumber(t *testing.T) {
+	for n, want := range map[uint64]string{0: "0", 999: "999", 1000: "1,000", 12345: "12,345", 1234567: "1,234,567"} {
+		require.Equal(t, want, formatNumber(n))
+	}
+}
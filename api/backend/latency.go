@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/challenge-manager/latency"
+)
+
+// LatencyBudgetReporter is implemented by challenge managers which track the latency of their
+// counter-moves, such as the challenge manager of the challenge-manager package.
+type LatencyBudgetReporter interface {
+	LatencyBudget() *latency.Budget
+}
+
+// DeadlineMarginReporter is implemented by challenge managers which keep a safety margin before the
+// deadlines of challenges.
+type DeadlineMarginReporter interface {
+	DeadlineMargin() *latency.Margin
+}
+
+// GetLatencyBudget describes the latency of our counter-moves at each challenge level, which is
+// disabled if the challenge manager does not track it.
+func (b *Backend) GetLatencyBudget(_ context.Context) (*api.JsonLatencyBudget, error) {
+	resp := &api.JsonLatencyBudget{
+		Levels: []*api.JsonLevelLatency{},
+	}
+	reporter, ok := b.trackerFetcher.(LatencyBudgetReporter)
+	if !ok || reporter.LatencyBudget() == nil {
+		return resp, nil
+	}
+	summary := reporter.LatencyBudget().Summary()
+	resp.Enabled = true
+	resp.ChallengePeriodBlocks = summary.ChallengePeriodBlocks
+	resp.BudgetFraction = summary.Fraction
+	resp.BudgetBlocks = summary.BudgetBlocks
+	for _, l := range summary.Levels {
+		resp.Levels = append(resp.Levels, &api.JsonLevelLatency{
+			ChallengeLevel: uint8(l.Level),
+			Samples:        l.Samples,
+			Total:          l.Total,
+			Exceeded:       l.Exceeded,
+			AtRisk:         l.AtRisk,
+			P50Seconds:     l.P50.Seconds(),
+			P90Seconds:     l.P90.Seconds(),
+			P99Seconds:     l.P99.Seconds(),
+			MaxSeconds:     l.Max.Seconds(),
+			P50Blocks:      l.P50Blocks,
+			P90Blocks:      l.P90Blocks,
+			P99Blocks:      l.P99Blocks,
+			MaxBlocks:      l.MaxBlocks,
+		})
+	}
+	if reporter, ok := b.trackerFetcher.(DeadlineMarginReporter); ok && reporter.DeadlineMargin() != nil {
+		margin := reporter.DeadlineMargin()
+		resp.DeadlineMargin = &api.JsonDeadlineMargin{
+			ConfiguredBlocks: margin.ConfiguredBlocks(),
+			ObservedBlocks:   margin.ObservedBlocks(),
+			Blocks:           margin.Blocks(),
+			Tightened:        margin.Tightened(),
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/challenge-manager/latency"
+	"github.com/stretchr/testify/require"
+)
+
+type latencyManager struct {
+	EdgeTrackerFetcher
+	budget *latency.Budget
+	margin *latency.Margin
+}
+
+func (m *latencyManager) LatencyBudget() *latency.Budget {
+	return m.budget
+}
+
+func (m *latencyManager) DeadlineMargin() *latency.Margin {
+	return m.margin
+}
+
+func TestGetLatencyBudget(t *testing.T) {
+	ctx := context.Background()
+	resp, err := NewBackend(nil, nil, nil, nil).GetLatencyBudget(ctx)
+	require.NoError(t, err)
+	require.False(t, resp.Enabled)
+	require.Empty(t, resp.Levels)
+
+	budget, err := latency.New(1000, 0.1)
+	require.NoError(t, err)
+	budget.Record(latency.Sample{Level: 1, Kind: latency.MoveSubchallenge, RivalBlock: 10, MoveBlock: 14, Latency: 48 * time.Second})
+	resp, err = NewBackend(nil, nil, nil, &latencyManager{budget: budget}).GetLatencyBudget(ctx)
+	require.NoError(t, err)
+	require.True(t, resp.Enabled)
+	require.Equal(t, uint64(100), resp.BudgetBlocks)
+	require.Len(t, resp.Levels, 1)
+	require.Equal(t, uint8(1), resp.Levels[0].ChallengeLevel)
+	require.Equal(t, uint64(4), resp.Levels[0].MaxBlocks)
+	require.Equal(t, 48.0, resp.Levels[0].P50Seconds)
+	require.Nil(t, resp.DeadlineMargin)
+
+	resp, err = NewBackend(nil, nil, nil, &latencyManager{budget: budget, margin: latency.NewMargin(3, budget)}).GetLatencyBudget(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &api.JsonDeadlineMargin{ConfiguredBlocks: 3, ObservedBlocks: 4, Blocks: 4, Tightened: true}, resp.DeadlineMargin)
+}
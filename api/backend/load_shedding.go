@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/api"
+)
+
+// GetLoadShedding describes the load shedding of the chain watcher, which is disabled if the
+// backend has no chain watcher.
+func (b *Backend) GetLoadShedding(_ context.Context) (*api.JsonLoadShedding, error) {
+	resp := &api.JsonLoadShedding{
+		ShedEdges: []*api.JsonShedEdge{},
+	}
+	if b.chainWatcher == nil {
+		return resp, nil
+	}
+	status := b.chainWatcher.SheddingStatus()
+	resp.Enabled = status.Enabled
+	resp.Active = status.Active
+	resp.Backlog = status.Backlog
+	resp.BacklogThreshold = status.BacklogThreshold
+	resp.DeadlineWindowBlocks = status.DeadlineWindowBlocks
+	resp.TotalShed = status.TotalShed
+	resp.TotalRestored = status.TotalRestored
+	for _, edge := range status.ShedEdges {
+		resp.ShedEdges = append(resp.ShedEdges, &api.JsonShedEdge{
+			AssertionHash:  edge.AssertionHash.Hash,
+			EdgeId:         edge.EdgeId.Hash,
+			ChallengeLevel: uint8(edge.ChallengeLevel),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/util/poison"
+)
+
+// ErrNoPoisonFlag is returned when clearing the poisoned flag of an assertion chain which does not
+// freeze posting behind one.
+var ErrNoPoisonFlag = errors.New("the assertion chain has no poisoned flag")
+
+// PoisonFlagHolder is implemented by assertion chains which freeze posting behind a poisoned flag,
+// such as the Solidity implementation.
+type PoisonFlagHolder interface {
+	PoisonFlag() *poison.Flag
+}
+
+// GetPoison describes whether posting is frozen since an edge we agreed with lost by one step proof,
+// which is never the case if the assertion chain has no poisoned flag.
+func (b *Backend) GetPoison(_ context.Context) (*api.JsonPoison, error) {
+	holder, ok := b.chainDataFetcher.(PoisonFlagHolder)
+	if !ok {
+		return &api.JsonPoison{}, nil
+	}
+	incident := holder.PoisonFlag().Incident()
+	if incident == nil {
+		return &api.JsonPoison{}, nil
+	}
+	return &api.JsonPoison{
+		Poisoned:                true,
+		ChallengedAssertionHash: incident.ChallengedAssertionHash,
+		LostEdgeId:              incident.LostEdgeId,
+		WinningEdgeId:           incident.WinningEdgeId,
+		ChallengeLevel:          incident.ChallengeLevel,
+		BlockNumber:             incident.BlockNumber,
+		DetectedAt:              incident.DetectedAt,
+		Bundle:                  incident.Bundle,
+	}, nil
+}
+
+// ClearPoison clears the poisoned flag once an operator has fixed the state provider, resuming
+// posting.
+func (b *Backend) ClearPoison(_ context.Context) error {
+	holder, ok := b.chainDataFetcher.(PoisonFlagHolder)
+	if !ok || holder.PoisonFlag() == nil {
+		return ErrNoPoisonFlag
+	}
+	return holder.PoisonFlag().Clear()
+}
@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/OffchainLabs/bold/util/poison"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type poisonChain struct {
+	*mocks.MockProtocol
+	flag *poison.Flag
+}
+
+func (c *poisonChain) PoisonFlag() *poison.Flag {
+	return c.flag
+}
+
+func TestPoison(t *testing.T) {
+	ctx := context.Background()
+	flag, err := poison.Open(filepath.Join(t.TempDir(), "poisoned.json"))
+	require.NoError(t, err)
+	b := NewBackend(nil, &poisonChain{MockProtocol: &mocks.MockProtocol{}, flag: flag}, nil, nil)
+
+	p, err := b.GetPoison(ctx)
+	require.NoError(t, err)
+	require.False(t, p.Poisoned)
+
+	require.NoError(t, flag.Poison(ctx, &poison.Incident{LostEdgeId: common.HexToHash("0x1"), WinningEdgeId: common.HexToHash("0x2")}, nil))
+	p, err = b.GetPoison(ctx)
+	require.NoError(t, err)
+	require.True(t, p.Poisoned)
+	require.Equal(t, common.HexToHash("0x1"), p.LostEdgeId)
+	require.Equal(t, common.HexToHash("0x2"), p.WinningEdgeId)
+
+	require.NoError(t, b.ClearPoison(ctx))
+	p, err = b.GetPoison(ctx)
+	require.NoError(t, err)
+	require.False(t, p.Poisoned)
+
+	// Assertion chains without a flag are never poisoned.
+	b = NewBackend(nil, &mocks.MockProtocol{}, nil, nil)
+	p, err = b.GetPoison(ctx)
+	require.NoError(t, err)
+	require.False(t, p.Poisoned)
+	require.ErrorIs(t, b.ClearPoison(ctx), ErrNoPoisonFlag)
+}
@@ -0,0 +1,219 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/api/stateexport"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The action on a pending assertion whose confirmation period is over, or about to be.
+const confirmAssertionByTimeAction = "confirm_assertion_by_time"
+
+// The action on a challenge in which we have no edges yet.
+const openChallengeAction = "open_challenge"
+
+// GetRequiredActions lists the actions the validator has to take at the desired RPC head block, or
+// can take within the given number of blocks after it, on pending assertions and ongoing
+// challenges, with the deadline by which each has to be taken. The actions on edges are derived
+// from the edges stored in the database as in the challenge state export.
+func (b *Backend) GetRequiredActions(ctx context.Context, withinBlocks uint64) (*api.JsonRequiredActions, error) {
+	challengeManager, err := b.chainDataFetcher.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	numBigStepLevels, err := challengeManager.NumBigSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	challengePeriod, err := challengeManager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	header, err := b.chainDataFetcher.Backend().HeaderByNumber(ctx, b.chainDataFetcher.GetDesiredRpcHeadBlockNumber())
+	if err != nil {
+		return nil, err
+	}
+	blockNumber := header.Number.Uint64()
+	actions := make([]*api.JsonRequiredAction, 0)
+
+	pending, err := b.db.GetAssertions(db.WithAssertionStatus(protocol.AssertionPending))
+	if err != nil {
+		return nil, err
+	}
+	// Challenges are on the parents of pending assertions, so those with a confirmed child are over.
+	challenged := make(map[common.Hash]bool)
+	for _, a := range pending {
+		parents, err := b.db.GetAssertions(db.WithAssertionHash(protocol.AssertionHash{Hash: a.ParentAssertionHash}))
+		if err != nil {
+			return nil, err
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		if isRivaled(parents[0]) {
+			challenged[a.ParentAssertionHash] = true
+			continue
+		}
+		if action := confirmAssertionAction(a, blockNumber, withinBlocks); action != nil {
+			actions = append(actions, action)
+		}
+	}
+	for assertionHash := range challenged {
+		edges, err := b.db.GetEdges(db.WithEdgeAssertionHash(protocol.AssertionHash{Hash: assertionHash}))
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, challengeActions(assertionHash, edges, numBigStepLevels, challengePeriod, blockNumber)...)
+	}
+	sortRequiredActions(actions)
+	return &api.JsonRequiredActions{
+		BlockNumber: blockNumber,
+		Actions:     actions,
+	}, nil
+}
+
+func isRivaled(parent *api.JsonAssertion) bool {
+	return parent.SecondChildBlock != nil && *parent.SecondChildBlock != 0
+}
+
+// Returns the confirmation of an unrivaled assertion if its confirmation period ends within the
+// given number of blocks.
+func confirmAssertionAction(a *api.JsonAssertion, blockNumber, withinBlocks uint64) *api.JsonRequiredAction {
+	confirmableAt := a.CreationBlock + a.ConfirmPeriodBlocks
+	if confirmableAt > blockNumber+withinBlocks {
+		return nil
+	}
+	reason := fmt.Sprintf("Unrivaled since block %d and confirmable by time", a.CreationBlock)
+	if confirmableAt > blockNumber {
+		reason = fmt.Sprintf("Unrivaled since block %d and confirmable by time in %d blocks", a.CreationBlock, confirmableAt-blockNumber)
+	}
+	return &api.JsonRequiredAction{
+		AssertionHash:    a.Hash,
+		Action:           confirmAssertionByTimeAction,
+		AvailableAtBlock: confirmableAt,
+		Reason:           reason,
+	}
+}
+
+// Derives the actions on the edges of an ongoing challenge. An action on an edge has to be taken
+// before the rival with the longest path timer reaches the challenge period, as it could be
+// confirmed by time then.
+func challengeActions(
+	assertionHash common.Hash,
+	edges []*api.JsonEdge,
+	numBigStepLevels uint8,
+	challengePeriod uint64,
+	blockNumber uint64,
+) []*api.JsonRequiredAction {
+	tree := newEdgeTree(edges)
+	deadline := func(e *api.JsonEdge) (*uint64, uint64) {
+		var rivalTimer uint64
+		hasRival := false
+		for _, rival := range edges {
+			if rival.MutualId == e.MutualId && rival.Id != e.Id && !rival.IsRoyal {
+				rivalTimer = max(rivalTimer, tree.pathTimer(rival, false))
+				hasRival = true
+			}
+		}
+		if !hasRival {
+			return nil, 0
+		}
+		block := blockNumber
+		if rivalTimer < challengePeriod {
+			block += challengePeriod - rivalTimer
+		}
+		return &block, rivalTimer
+	}
+
+	var royalRoot *api.JsonEdge
+	for _, e := range edges {
+		if e.IsRoyal && e.ChallengeLevel == protocol.NewBlockChallengeLevel().Uint8() && e.ClaimId != (common.Hash{}) {
+			royalRoot = e
+			break
+		}
+	}
+	if royalRoot == nil {
+		action := &api.JsonRequiredAction{
+			AssertionHash:    assertionHash,
+			Action:           openChallengeAction,
+			AvailableAtBlock: blockNumber,
+			Reason:           "The assertion is rivaled and we have no edge in its challenge",
+		}
+		for _, e := range edges {
+			if e.ChallengeLevel != protocol.NewBlockChallengeLevel().Uint8() || e.ClaimId == (common.Hash{}) {
+				continue
+			}
+			// Any rival root edge can be confirmed by time once its timer reaches the period.
+			block := blockNumber
+			if timer := tree.pathTimer(e, false); timer < challengePeriod {
+				block += challengePeriod - timer
+			}
+			if action.DeadlineBlock == nil || block < *action.DeadlineBlock {
+				action.DeadlineBlock = &block
+			}
+		}
+		return []*api.JsonRequiredAction{action}
+	}
+	if royalRoot.Status == protocol.EdgeConfirmed.String() {
+		return nil
+	}
+
+	state := stateexport.FromEdges(&stateexport.ChallengeState{
+		NumBigStepLevels:      numBigStepLevels,
+		ChallengePeriodBlocks: challengePeriod,
+	}, edges)
+	actions := make([]*api.JsonRequiredAction, 0, len(state.PendingActions))
+	for _, pendingAction := range state.PendingActions {
+		e, ok := tree.byId[pendingAction.EdgeId]
+		if !ok {
+			continue
+		}
+		edgeId := e.Id
+		level := e.ChallengeLevel
+		action := &api.JsonRequiredAction{
+			AssertionHash:    assertionHash,
+			EdgeId:           &edgeId,
+			ChallengeLevel:   &level,
+			Action:           pendingAction.Action,
+			BisectionHeight:  pendingAction.BisectionHeight,
+			AvailableAtBlock: blockNumber,
+		}
+		if pendingAction.Action == edgetracker.DecideConfirmByTime.String() {
+			action.Reason = fmt.Sprintf("Our timer of %d blocks has reached the challenge period", e.InheritedTimer)
+		} else {
+			var rivalTimer uint64
+			action.DeadlineBlock, rivalTimer = deadline(e)
+			action.Reason = fmt.Sprintf(
+				"Rivaled from height %d to %d, and the rival's path timer is %d of %d blocks",
+				e.StartHeight, e.EndHeight, rivalTimer, challengePeriod,
+			)
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// Orders actions by their deadline, with those without one last, then by when they are available.
+func sortRequiredActions(actions []*api.JsonRequiredAction) {
+	sort.SliceStable(actions, func(i, j int) bool {
+		a, b := actions[i], actions[j]
+		if (a.DeadlineBlock == nil) != (b.DeadlineBlock == nil) {
+			return a.DeadlineBlock != nil
+		}
+		if a.DeadlineBlock != nil && *a.DeadlineBlock != *b.DeadlineBlock {
+			return *a.DeadlineBlock < *b.DeadlineBlock
+		}
+		if a.AvailableAtBlock != b.AvailableAtBlock {
+			return a.AvailableAtBlock < b.AvailableAtBlock
+		}
+		return bytes.Compare(a.AssertionHash[:], b.AssertionHash[:]) < 0
+	})
+}
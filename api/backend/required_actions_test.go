@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmAssertionAction(t *testing.T) {
+	a := &api.JsonAssertion{Hash: hashOf("assertion"), CreationBlock: 100, ConfirmPeriodBlocks: 50}
+	require.Nil(t, confirmAssertionAction(a, 120, 10))
+
+	action := confirmAssertionAction(a, 120, 30)
+	require.Equal(t, uint64(150), action.AvailableAtBlock)
+	require.Equal(t, "Unrivaled since block 100 and confirmable by time in 30 blocks", action.Reason)
+	require.Nil(t, action.DeadlineBlock)
+
+	action = confirmAssertionAction(a, 200, 0)
+	require.Equal(t, confirmAssertionByTimeAction, action.Action)
+	require.Equal(t, "Unrivaled since block 100 and confirmable by time", action.Reason)
+}
+
+func TestChallengeActions(t *testing.T) {
+	assertionHash := hashOf("assertion")
+	pending := protocol.EdgePending.String()
+	edges := []*api.JsonEdge{
+		{Id: hashOf("r0"), EndHeight: 32, MutualId: hashOf("m0"), ClaimId: hashOf("ours"), IsRoyal: true, HasRival: true, TimeUnrivaled: 10, Status: pending},
+		{Id: hashOf("e0"), EndHeight: 32, MutualId: hashOf("m0"), ClaimId: hashOf("theirs"), HasRival: true, TimeUnrivaled: 40, Status: pending},
+	}
+
+	t.Run("no edges of ours", func(t *testing.T) {
+		actions := challengeActions(assertionHash, edges[1:], 1, 100, 1000)
+		require.Len(t, actions, 1)
+		require.Equal(t, openChallengeAction, actions[0].Action)
+		require.Equal(t, uint64(1060), *actions[0].DeadlineBlock)
+	})
+	t.Run("bisection", func(t *testing.T) {
+		actions := challengeActions(assertionHash, edges, 1, 100, 1000)
+		require.Len(t, actions, 1)
+		require.Equal(t, hashOf("r0"), *actions[0].EdgeId)
+		require.Equal(t, "bisect", actions[0].Action)
+		require.Equal(t, uint64(16), *actions[0].BisectionHeight)
+		require.Equal(t, uint64(1060), *actions[0].DeadlineBlock)
+		require.Equal(t, "Rivaled from height 0 to 32, and the rival's path timer is 40 of 100 blocks", actions[0].Reason)
+	})
+	t.Run("won", func(t *testing.T) {
+		won := *edges[0]
+		won.Status = protocol.EdgeConfirmed.String()
+		require.Empty(t, challengeActions(assertionHash, []*api.JsonEdge{&won, edges[1]}, 1, 100, 1000))
+	})
+}
+
+func TestSortRequiredActions(t *testing.T) {
+	early, late := uint64(10), uint64(20)
+	actions := []*api.JsonRequiredAction{
+		{Action: "no deadline", AvailableAtBlock: 1},
+		{Action: "late", DeadlineBlock: &late},
+		{Action: "early", DeadlineBlock: &early},
+		{Action: "no deadline earlier", AvailableAtBlock: 0, AssertionHash: common.Hash{1}},
+	}
+	sortRequiredActions(actions)
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		names = append(names, a.Action)
+	}
+	require.Equal(t, []string{"early", "late", "no deadline earlier", "no deadline"}, names)
+}
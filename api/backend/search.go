@@ -0,0 +1,46 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+)
+
+const (
+	defaultSearchResults = 20
+	maxSearchResults     = 100
+)
+
+// SearchByHashPrefix finds the assertions whose hash or creation transaction hash, and the edges
+// whose id or mutual id, start with a prefix, returning up to limit items of each. A limit of zero
+// returns the default number of items, and larger values are capped.
+func (b *Backend) SearchByHashPrefix(ctx context.Context, prefix db.HashPrefix, limit int) (*api.JsonHashSearchResults, error) {
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	limit = min(limit, maxSearchResults)
+	byHash, err := b.GetAssertions(ctx, db.WithAssertionHashPrefix(prefix), db.WithAssertionLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	byTransactionHash, err := b.GetAssertions(ctx, db.WithTransactionHashPrefix(prefix), db.WithAssertionLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	byId, err := b.GetEdges(ctx, db.WithIdPrefix(prefix), db.WithLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	byMutualId, err := b.GetEdges(ctx, db.WithMutualIdPrefix(prefix), db.WithLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &api.JsonHashSearchResults{
+		Prefix:                      prefix.String(),
+		AssertionsByHash:            byHash,
+		AssertionsByTransactionHash: byTransactionHash,
+		EdgesById:                   byId,
+		EdgesByMutualId:             byMutualId,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchByHashPrefix(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "bold.db"))
+	require.NoError(t, err)
+	b := &Backend{db: database}
+
+	for i, hash := range []string{"abcd01", "abcd02", "abce03"} {
+		require.NoError(t, database.InsertAssertion(&api.JsonAssertion{
+			Hash:                     common.HexToHash("0x" + hash + strings.Repeat("0", 58)),
+			ConfirmPeriodBlocks:      100,
+			RequiredStake:            "1",
+			InboxMaxCount:            "1",
+			CreationBlock:            uint64(i),
+			TransactionHash:          common.HexToHash("0x1234" + strings.Repeat("0", 54) + hash),
+			BeforeStateMachineStatus: protocol.MachineStatusFinished,
+			AfterStateMachineStatus:  protocol.MachineStatusFinished,
+			Status:                   protocol.AssertionPending.String(),
+		}))
+	}
+	prefix, err := db.ParseHashPrefix("0xABCD…")
+	require.NoError(t, err)
+	results, err := b.SearchByHashPrefix(ctx, prefix, 0)
+	require.NoError(t, err)
+	require.Equal(t, "0xabcd", results.Prefix)
+	require.Len(t, results.AssertionsByHash, 2)
+	require.Empty(t, results.AssertionsByTransactionHash)
+	require.Empty(t, results.EdgesById)
+	require.Empty(t, results.EdgesByMutualId)
+
+	prefix, err = db.ParseHashPrefix("1234")
+	require.NoError(t, err)
+	results, err = b.SearchByHashPrefix(ctx, prefix, 2)
+	require.NoError(t, err)
+	require.Empty(t, results.AssertionsByHash)
+	require.Len(t, results.AssertionsByTransactionHash, 2)
+}
@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+)
+
+// StakeExposureInterval is the length of the periods stake exposure is aggregated over.
+type StakeExposureInterval string
+
+const (
+	StakeExposureDaily  StakeExposureInterval = "day"
+	StakeExposureWeekly StakeExposureInterval = "week"
+)
+
+func (b *Backend) GetStakeEvents(ctx context.Context, opts ...db.StakeEventOption) ([]*api.JsonStakeEvent, error) {
+	return b.db.GetStakeEvents(opts...)
+}
+
+// GetStakeExposure aggregates all stake events matching the options into periods of the given interval.
+// Exposure carries over between periods, so the options should not filter out earlier events if the
+// absolute amount of tokens locked is of interest.
+func (b *Backend) GetStakeExposure(
+	ctx context.Context,
+	interval StakeExposureInterval,
+	opts ...db.StakeEventOption,
+) ([]*api.JsonStakeExposure, error) {
+	events, err := b.db.GetStakeEvents(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateStakeExposure(events, interval)
+}
+
+type stakeExposurePeriod struct {
+	start         time.Time
+	locked        *big.Int
+	refunded      *big.Int
+	highWaterMark *big.Int
+	endExposure   *big.Int
+}
+
+// Aggregates stake events, which must be sorted by time, into periods of the given interval.
+// Periods without any events are omitted.
+func aggregateStakeExposure(events []*api.JsonStakeEvent, interval StakeExposureInterval) ([]*api.JsonStakeExposure, error) {
+	periods := make([]*stakeExposurePeriod, 0)
+	exposure := new(big.Int)
+	for _, e := range events {
+		amount, ok := new(big.Int).SetString(e.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("could not parse stake amount %q", e.Amount)
+		}
+		start, err := periodStart(e.Timestamp, interval)
+		if err != nil {
+			return nil, err
+		}
+		if len(periods) == 0 || !periods[len(periods)-1].start.Equal(start) {
+			periods = append(periods, &stakeExposurePeriod{
+				start:         start,
+				locked:        new(big.Int),
+				refunded:      new(big.Int),
+				highWaterMark: new(big.Int).Set(exposure),
+				endExposure:   new(big.Int),
+			})
+		}
+		period := periods[len(periods)-1]
+		switch e.Kind {
+		case api.StakeEventLocked:
+			period.locked.Add(period.locked, amount)
+			exposure.Add(exposure, amount)
+		case api.StakeEventRefunded:
+			period.refunded.Add(period.refunded, amount)
+			exposure.Sub(exposure, amount)
+		default:
+			return nil, fmt.Errorf("unknown stake event kind %q", e.Kind)
+		}
+		if exposure.Cmp(period.highWaterMark) > 0 {
+			period.highWaterMark.Set(exposure)
+		}
+		period.endExposure.Set(exposure)
+	}
+	resp := make([]*api.JsonStakeExposure, len(periods))
+	for i, p := range periods {
+		resp[i] = &api.JsonStakeExposure{
+			PeriodStart:   p.start,
+			Locked:        p.locked.String(),
+			Refunded:      p.refunded.String(),
+			HighWaterMark: p.highWaterMark.String(),
+			EndExposure:   p.endExposure.String(),
+		}
+	}
+	return resp, nil
+}
+
+// Gets the start of the period containing a time in UTC. Weeks start on Mondays.
+func periodStart(t time.Time, interval StakeExposureInterval) (time.Time, error) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case StakeExposureDaily:
+		return day, nil
+	case StakeExposureWeekly:
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown stake exposure interval %q", interval)
+	}
+}
@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateStakeExposure(t *testing.T) {
+	// A Wednesday.
+	start := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	events := []*api.JsonStakeEvent{
+		{Kind: api.StakeEventLocked, Amount: "100", Timestamp: start},
+		{Kind: api.StakeEventLocked, Amount: "50", Timestamp: start.Add(time.Hour)},
+		{Kind: api.StakeEventRefunded, Amount: "100", Timestamp: start.Add(2 * time.Hour)},
+		{Kind: api.StakeEventLocked, Amount: "10", Timestamp: start.Add(24 * time.Hour)},
+		{Kind: api.StakeEventRefunded, Amount: "60", Timestamp: start.Add(7 * 24 * time.Hour)},
+	}
+
+	daily, err := aggregateStakeExposure(events, StakeExposureDaily)
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStakeExposure{
+		{PeriodStart: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Locked: "150", Refunded: "100", HighWaterMark: "150", EndExposure: "50"},
+		{PeriodStart: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), Locked: "10", Refunded: "0", HighWaterMark: "60", EndExposure: "60"},
+		{PeriodStart: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), Locked: "0", Refunded: "60", HighWaterMark: "60", EndExposure: "0"},
+	}, daily)
+
+	weekly, err := aggregateStakeExposure(events, StakeExposureWeekly)
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStakeExposure{
+		{PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Locked: "160", Refunded: "100", HighWaterMark: "150", EndExposure: "60"},
+		{PeriodStart: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Locked: "0", Refunded: "60", HighWaterMark: "60", EndExposure: "0"},
+	}, weekly)
+
+	_, err = aggregateStakeExposure(events, "month")
+	require.ErrorContains(t, err, "unknown stake exposure interval")
+
+	_, err = aggregateStakeExposure([]*api.JsonStakeEvent{{Kind: api.StakeEventLocked, Amount: "foo"}}, StakeExposureDaily)
+	require.ErrorContains(t, err, "could not parse stake amount")
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/api/stateexport"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/pkg/errors"
+)
+
+// ChallengeEdgeReader is implemented by challenge managers which can read edges as stored by the
+// contract, such as the Solidity implementation, letting exports include the canonical encoding of
+// each edge.
+type ChallengeEdgeReader interface {
+	ChallengeEdge(ctx context.Context, edgeId protocol.EdgeId) (challengeV2gen.ChallengeEdge, error)
+}
+
+// ExportChallengeState exports the state of the challenge on an assertion from the edges stored
+// in the database, in the client independent format of the stateexport package. Edges are exported
+// with their canonical encoding read at the export block if the challenge manager can read them.
+func (b *Backend) ExportChallengeState(ctx context.Context, assertionHash protocol.AssertionHash) (*stateexport.ChallengeState, error) {
+	challengeManager, err := b.chainDataFetcher.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	numBigStepLevels, err := challengeManager.NumBigSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	challengePeriod, err := challengeManager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	header, err := b.chainDataFetcher.Backend().HeaderByNumber(ctx, b.chainDataFetcher.GetDesiredRpcHeadBlockNumber())
+	if err != nil {
+		return nil, err
+	}
+	edges, err := b.db.GetEdges(db.WithEdgeAssertionHash(assertionHash))
+	if err != nil {
+		return nil, err
+	}
+	state := stateexport.FromEdges(&stateexport.ChallengeState{
+		ChallengeManager:        challengeManager.Address(),
+		ChallengedAssertionHash: assertionHash.Hash,
+		Validator:               b.chainDataFetcher.StakerAddress(),
+		BlockNumber:             header.Number.Uint64(),
+		NumBigStepLevels:        numBigStepLevels,
+		ChallengePeriodBlocks:   challengePeriod,
+	}, edges)
+	reader, ok := challengeManager.(ChallengeEdgeReader)
+	if !ok {
+		return state, nil
+	}
+	ctx = protocol.PinReads(ctx, header)
+	for _, e := range state.Edges {
+		edge, err := reader.ChallengeEdge(ctx, protocol.EdgeId{Hash: e.Id})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read edge %#x", e.Id)
+		}
+		if e.Encoding, err = solimpl.EncodeChallengeEdge(&edge); err != nil {
+			return nil, errors.Wrapf(err, "could not encode edge %#x", e.Id)
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,60 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OffchainLabs/bold/api"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNoStuckTxQueue is returned when resolving a stuck transaction of an assertion chain which does
+// not escalate stuck transactions.
+var ErrNoStuckTxQueue = errors.New("the assertion chain has no queue of stuck transactions")
+
+// StuckTxQueue is implemented by assertion chains which escalate transactions stuck for too long
+// to a queue for manual intervention, such as the Solidity implementation.
+type StuckTxQueue interface {
+	StuckTxs() []*solimpl.StuckTx
+	ResolveStuckTx(txHash common.Hash) error
+}
+
+// GetStuckTxs lists the transactions waiting for manual intervention, which is empty if the
+// assertion chain does not escalate stuck transactions.
+func (b *Backend) GetStuckTxs(_ context.Context) ([]*api.JsonStuckTx, error) {
+	queue, ok := b.chainDataFetcher.(StuckTxQueue)
+	if !ok {
+		return []*api.JsonStuckTx{}, nil
+	}
+	stuck := queue.StuckTxs()
+	txs := make([]*api.JsonStuckTx, 0, len(stuck))
+	for _, tx := range stuck {
+		txs = append(txs, &api.JsonStuckTx{
+			TxHash:         tx.TxHash,
+			Nonce:          tx.Nonce,
+			From:           tx.From,
+			To:             tx.To,
+			Data:           tx.Data,
+			GasFeeCap:      tx.GasFeeCap.String(),
+			GasTipCap:      tx.GasTipCap.String(),
+			Method:         tx.Method,
+			Args:           tx.Args,
+			FirstSentAt:    tx.FirstSentAt,
+			Attempts:       tx.Attempts,
+			EscalatedAt:    tx.EscalatedAt,
+			SuggestedFixes: tx.SuggestedFixes,
+		})
+	}
+	return txs, nil
+}
+
+// ResolveStuckTx removes a transaction an operator has unblocked from the queue, so that the
+// validator may send it again.
+func (b *Backend) ResolveStuckTx(_ context.Context, txHash common.Hash) error {
+	queue, ok := b.chainDataFetcher.(StuckTxQueue)
+	if !ok {
+		return ErrNoStuckTxQueue
+	}
+	return queue.ResolveStuckTx(txHash)
+}
@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type stuckTxChain struct {
+	*mocks.MockProtocol
+	stuck []*solimpl.StuckTx
+}
+
+func (c *stuckTxChain) StuckTxs() []*solimpl.StuckTx {
+	return c.stuck
+}
+
+func (c *stuckTxChain) ResolveStuckTx(txHash common.Hash) error {
+	for i, tx := range c.stuck {
+		if tx.TxHash == txHash {
+			c.stuck = append(c.stuck[:i], c.stuck[i+1:]...)
+			return nil
+		}
+	}
+	return solimpl.ErrNotFound
+}
+
+func TestStuckTxs(t *testing.T) {
+	ctx := context.Background()
+	txHash := common.HexToHash("0x1")
+	chain := &stuckTxChain{
+		MockProtocol: &mocks.MockProtocol{},
+		stuck: []*solimpl.StuckTx{{
+			TxHash:         txHash,
+			Nonce:          7,
+			GasFeeCap:      big.NewInt(10),
+			GasTipCap:      big.NewInt(1),
+			Method:         "confirmEdgeByTime",
+			Args:           map[string]string{"edgeId": common.HexToHash("0x2").Hex()},
+			Attempts:       3,
+			SuggestedFixes: []string{"fund it"},
+		}},
+	}
+	b := NewBackend(nil, chain, nil, nil)
+
+	txs, err := b.GetStuckTxs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStuckTx{{
+		TxHash:         txHash,
+		Nonce:          7,
+		GasFeeCap:      "10",
+		GasTipCap:      "1",
+		Method:         "confirmEdgeByTime",
+		Args:           map[string]string{"edgeId": common.HexToHash("0x2").Hex()},
+		Attempts:       3,
+		SuggestedFixes: []string{"fund it"},
+	}}, txs)
+
+	require.ErrorIs(t, b.ResolveStuckTx(ctx, common.HexToHash("0x3")), solimpl.ErrNotFound)
+	require.NoError(t, b.ResolveStuckTx(ctx, txHash))
+	txs, err = b.GetStuckTxs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+
+	// Assertion chains without a queue have no stuck transactions.
+	b = NewBackend(nil, &mocks.MockProtocol{}, nil, nil)
+	txs, err = b.GetStuckTxs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+	require.ErrorIs(t, b.ResolveStuckTx(ctx, txHash), ErrNoStuckTxQueue)
+}
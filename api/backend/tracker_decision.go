@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/OffchainLabs/bold/containers/option"
+)
+
+// GetTrackerDecision reconstructs what the tracker of an edge decided, or would have, at a block,
+// defaulting to the desired RPC head block. The decision is made by re-running the tracker's
+// decision logic against the state of the chain at the block, and is returned along with the
+// operations the validator recorded for the edge in the audit log between the block and the next.
+func (b *Backend) GetTrackerDecision(
+	ctx context.Context,
+	edgeId protocol.EdgeId,
+	blockNumber option.Option[uint64],
+) (*api.JsonTrackerDecision, error) {
+	trackerOpt := b.trackerFetcher.GetEdgeTracker(edgeId)
+	if trackerOpt.IsNone() {
+		return nil, fmt.Errorf("no tracker for edge %#x", edgeId.Hash)
+	}
+	tracker := trackerOpt.Unwrap()
+	chainBackend := b.chainDataFetcher.Backend()
+	number := b.chainDataFetcher.GetDesiredRpcHeadBlockNumber()
+	if blockNumber.IsSome() {
+		number = new(big.Int).SetUint64(blockNumber.Unwrap())
+	}
+	header, err := chainBackend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	decision, err := tracker.DecideAt(ctx, header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	journalOpts := []db.AuditEntryOption{
+		db.WithAuditEntryInputsContaining(edgeId.Hash.Hex()),
+		db.WithAuditEntriesSince(time.Unix(int64(header.Time), 0)),
+	}
+	// The next block may not exist yet, in which case the journal runs up to the present.
+	if next, err := chainBackend.HeaderByNumber(ctx, new(big.Int).Add(header.Number, big.NewInt(1))); err == nil && next != nil {
+		journalOpts = append(journalOpts, db.WithAuditEntriesUntil(time.Unix(int64(next.Time), 0)))
+	}
+	journal, err := b.db.GetAuditEntries(journalOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return trackerDecisionToJson(edgeId, tracker.FSMSummary().CurrentState, decision, journal), nil
+}
+
+func trackerDecisionToJson(
+	edgeId protocol.EdgeId,
+	fsmState string,
+	decision *edgetracker.Decision,
+	journal []*api.JsonAuditEntry,
+) *api.JsonTrackerDecision {
+	result := &api.JsonTrackerDecision{
+		EdgeId:   edgeId.Hash,
+		FSMState: fsmState,
+		Decision: decision.Kind.String(),
+		Reasons:  decision.Reasons,
+		Journal:  journal,
+	}
+	if decision.BlockNumber.IsSome() {
+		result.BlockNumber = decision.BlockNumber.Unwrap()
+	}
+	if decision.BisectionHeight.IsSome() {
+		height := decision.BisectionHeight.Unwrap()
+		result.BisectionHeight = &height
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// AnalyticsViews lists the views maintained for analysts to query: edges_enriched, which adds the
+// length, number of rivals, and challenged assertion of each edge, challenge_timelines, which
+// summarizes the edges of each challenge, and stake_flows, which tags each stake event with its
+// direction and the edge it was made on.
+var AnalyticsViews = []string{"edges_enriched", "challenge_timelines", "stake_flows"}
+
+// ErrNoAnalyticsConnection is returned for analytics queries on a database without a read-only
+// connection, which is only opened for databases stored in a file.
+var ErrNoAnalyticsConnection = errors.New("database has no read-only connection for analytics queries")
+
+// ErrInvalidAnalyticsQuery is returned for analytics queries which are not a single SELECT
+// statement.
+var ErrInvalidAnalyticsQuery = errors.New("invalid analytics query")
+
+// QueryAnalytics runs an ad-hoc SELECT statement on a read-only connection, without blocking the
+// writes of the database, and returns up to maxRows of its rows. Binary values, such as hashes, are
+// hex encoded.
+func (d *SqliteDatabase) QueryAnalytics(ctx context.Context, query string, maxRows int) (*api.JsonAnalyticsQueryResult, error) {
+	query, err := checkAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if d.analyticsDB == nil {
+		return nil, ErrNoAnalyticsConnection
+	}
+	// Writes are refused by SQLite itself, including those in common table expressions, rather
+	// than by inspecting the query.
+	rows, err := d.analyticsDB.QueryxContext(ctx, query)
+	if err != nil {
+		return nil, analyticsQueryError(ctx, err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &api.JsonAnalyticsQueryResult{
+		Columns: columns,
+		Rows:    make([][]any, 0),
+	}
+	for rows.Next() {
+		if len(result.Rows) == maxRows {
+			result.Truncated = true
+			break
+		}
+		row, err := rows.SliceScan()
+		if err != nil {
+			return nil, analyticsQueryError(ctx, err)
+		}
+		for i, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[i] = hexutil.Encode(b)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, analyticsQueryError(ctx, err)
+	}
+	return result, nil
+}
+
+// Errors running a query are the fault of the query, such as a syntax error or an attempted
+// write, unless the query timed out.
+func analyticsQueryError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidAnalyticsQuery, err)
+}
+
+// Checks that a query is a single SELECT statement, optionally with common table expressions,
+// and returns it without a trailing semicolon. Semicolons are rejected anywhere else, even within
+// string literals, so that no further statement can follow.
+func checkAnalyticsQuery(query string) (string, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if query == "" {
+		return "", fmt.Errorf("%w: empty query", ErrInvalidAnalyticsQuery)
+	}
+	if strings.Contains(query, ";") {
+		return "", fmt.Errorf("%w: only a single statement is allowed", ErrInvalidAnalyticsQuery)
+	}
+	keyword := strings.ToLower(strings.Fields(query)[0])
+	if keyword != "select" && keyword != "with" {
+		return "", fmt.Errorf("%w: expected a SELECT statement, got %s", ErrInvalidAnalyticsQuery, keyword)
+	}
+	return query, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// AuditLogger records the operations performed by a principal, such as the validator's staker,
+// in the audit log of a database. A nil logger, or one without a database, records nothing.
+type AuditLogger struct {
+	db        Database
+	principal string
+}
+
+func NewAuditLogger(db Database, principal string) *AuditLogger {
+	return &AuditLogger{
+		db:        db,
+		principal: principal,
+	}
+}
+
+// Record appends an operation to the audit log, along with its inputs and result encoded as JSON
+// and the error it failed with, if any. Failing to record an operation does not fail the operation
+// itself, so errors are only logged.
+func (l *AuditLogger) Record(action string, inputs, result any, opErr error) {
+	if l == nil || api.IsNil(l.db) {
+		return
+	}
+	entry := &api.JsonAuditEntry{
+		Timestamp: time.Now(),
+		Principal: l.principal,
+		Action:    action,
+		Inputs:    encodeAuditField(inputs),
+		Result:    encodeAuditField(result),
+	}
+	if opErr != nil {
+		entry.Error = opErr.Error()
+	}
+	if err := l.db.AppendAuditEntry(entry); err != nil {
+		log.Error("Could not append audit log entry", "action", action, "err", err)
+	}
+}
+
+func encodeAuditField(v any) string {
+	if v == nil {
+		return "null"
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		log.Warn("Could not encode audit log field", "err", err)
+		return "null"
+	}
+	return string(encoded)
+}
@@ -3,10 +3,13 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/OffchainLabs/bold/api"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
@@ -24,12 +27,17 @@ type Database interface {
 	InsertAssertions(assertions []*api.JsonAssertion) error
 	InsertAssertion(assertion *api.JsonAssertion) error
 	InsertCollectMachineHash(collectMachineHashes *api.JsonCollectMachineHashes) error
+	InsertStakeEvent(stakeEvent *api.JsonStakeEvent) (bool, error)
+	InsertDeploymentStartBlock(startBlock *api.JsonDeploymentStartBlock) error
+	AppendAuditEntry(entry *api.JsonAuditEntry) error
 }
 
 type ReadUpdateDatabase interface {
 	ReadOnlyDatabase
 	UpdateAssertions(assertion []*api.JsonAssertion) error
 	UpdateEdges(edge []*api.JsonEdge) error
+	UpdateAdoptedLowerChild(parentId, lowerChildId common.Hash) error
+	UpdateEdgeConfirmation(edgeId common.Hash, confirmedAtBlock uint64) error
 	UpdateCollectMachineHash(collectMachineHashes *api.JsonCollectMachineHashes) error
 }
 
@@ -38,10 +46,17 @@ type ReadOnlyDatabase interface {
 	GetCollectMachineHashes(opts ...CollectMachineHashesOption) ([]*api.JsonCollectMachineHashes, error)
 	GetChallengedAssertions(opts ...AssertionOption) ([]*api.JsonAssertion, error)
 	GetEdges(opts ...EdgeOption) ([]*api.JsonEdge, error)
+	GetStakeEvents(opts ...StakeEventOption) ([]*api.JsonStakeEvent, error)
+	GetDeploymentStartBlock(challengeManager common.Address) (option.Option[*api.JsonDeploymentStartBlock], error)
+	GetAuditEntries(opts ...AuditEntryOption) ([]*api.JsonAuditEntry, error)
+	QueryAnalytics(ctx context.Context, query string, maxRows int) (*api.JsonAnalyticsQueryResult, error)
 }
 
 type SqliteDatabase struct {
-	sqlDB               *sqlx.DB
+	sqlDB *sqlx.DB
+	// A separate read-only connection for ad-hoc analytics queries, so that they neither take the
+	// lock nor can modify the database.
+	analyticsDB         *sqlx.DB
 	lock                sync.Mutex
 	currentTableVersion int
 }
@@ -62,8 +77,17 @@ func NewDatabase(path string) (*SqliteDatabase, error) {
 	if err != nil {
 		return nil, err
 	}
+	// In WAL mode, readers such as analytics queries do not block writers for as long as they run.
+	if _, err = db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		return nil, err
+	}
+	analyticsDB, err := sqlx.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro&_query_only=true", path))
+	if err != nil {
+		return nil, err
+	}
 	return &SqliteDatabase{
 		sqlDB:               db,
+		analyticsDB:         analyticsDB,
 		currentTableVersion: -1,
 	}, nil
 }
@@ -140,6 +164,8 @@ type AssertionQuery struct {
 	withChallenge     bool
 	fromCreationBlock option.Option[uint64]
 	toCreationBlock   option.Option[uint64]
+	fromConfirmation  option.Option[uint64]
+	toConfirmation    option.Option[uint64]
 	forceUpdate       bool
 }
 
@@ -314,6 +340,14 @@ func (q *AssertionQuery) ToSQL() (string, []interface{}) {
 		q.filters = append(q.filters, "a.CreationBlock < ?")
 		q.args = append(q.args, q.toCreationBlock.Unwrap())
 	}
+	if q.fromConfirmation.IsSome() {
+		q.filters = append(q.filters, "e.ConfirmedAtBlock >= ?")
+		q.args = append(q.args, q.fromConfirmation.Unwrap())
+	}
+	if q.toConfirmation.IsSome() {
+		q.filters = append(q.filters, "e.ConfirmedAtBlock < ?")
+		q.args = append(q.args, q.toConfirmation.Unwrap())
+	}
 	if len(q.filters) > 0 {
 		baseQuery += " WHERE " + strings.Join(q.filters, " AND ")
 	}
@@ -358,6 +392,60 @@ func (d *SqliteDatabase) GetCollectMachineHashes(opts ...CollectMachineHashesOpt
 	return collectMachineHashes, nil
 }
 
+func (d *SqliteDatabase) GetStakeEvents(opts ...StakeEventOption) ([]*api.JsonStakeEvent, error) {
+	query := NewStakeEventQuery(opts...)
+	sql, args := query.ToSQL()
+	stakeEvents := make([]*api.JsonStakeEvent, 0)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	err := d.sqlDB.Select(&stakeEvents, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return stakeEvents, nil
+}
+
+// SumStakeAmounts sums the amounts of stake events, which are summed exactly rather than by the
+// database, as they are too large for its integers.
+func SumStakeAmounts(events []*api.JsonStakeEvent) (*big.Int, error) {
+	total := new(big.Int)
+	for _, e := range events {
+		amount, ok := new(big.Int).SetString(e.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("could not parse stake amount %q", e.Amount)
+		}
+		total.Add(total, amount)
+	}
+	return total, nil
+}
+
+func (d *SqliteDatabase) GetDeploymentStartBlock(challengeManager common.Address) (option.Option[*api.JsonDeploymentStartBlock], error) {
+	startBlocks := make([]*api.JsonDeploymentStartBlock, 0)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	err := d.sqlDB.Select(&startBlocks, "SELECT * FROM DeploymentStartBlocks WHERE ChallengeManager = ?", challengeManager)
+	if err != nil {
+		return option.None[*api.JsonDeploymentStartBlock](), err
+	}
+	if len(startBlocks) == 0 {
+		return option.None[*api.JsonDeploymentStartBlock](), nil
+	}
+	return option.Some(startBlocks[0]), nil
+}
+
+func (d *SqliteDatabase) GetAuditEntries(opts ...AuditEntryOption) ([]*api.JsonAuditEntry, error) {
+	query := NewAuditEntryQuery(opts...)
+	sql, args := query.ToSQL()
+	entries := make([]*api.JsonAuditEntry, 0)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	err := d.sqlDB.Select(&entries, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func (d *SqliteDatabase) GetChallengedAssertions(opts ...AssertionOption) ([]*api.JsonAssertion, error) {
 	newOpts := []AssertionOption{
 		WithChallenge(),
@@ -374,6 +462,8 @@ type EdgeQuery struct {
 	orderBy           string
 	fromCreationBlock option.Option[uint64]
 	toCreationBlock   option.Option[uint64]
+	fromConfirmation  option.Option[uint64]
+	toConfirmation    option.Option[uint64]
 	forceUpdate       bool
 	onlySubchallenged bool
 }
@@ -536,6 +626,20 @@ func ToEdgeCreationBlock(n uint64) EdgeOption {
 		q.toCreationBlock = option.Some(n)
 	}
 }
+
+// FromEdgeConfirmationBlock only matches edges confirmed at or after a block.
+func FromEdgeConfirmationBlock(n uint64) EdgeOption {
+	return func(q *EdgeQuery) {
+		q.fromConfirmation = option.Some(n)
+	}
+}
+
+// ToEdgeConfirmationBlock only matches edges confirmed before a block.
+func ToEdgeConfirmationBlock(n uint64) EdgeOption {
+	return func(q *EdgeQuery) {
+		q.toConfirmation = option.Some(n)
+	}
+}
 func WithLengthOneRival() EdgeOption {
 	return func(q *EdgeQuery) {
 		q.filters = append(q.filters, "HasLengthOneRival = true")
@@ -571,6 +675,14 @@ func (q *EdgeQuery) ToSQL() (string, []interface{}) {
 		q.filters = append(q.filters, "e.CreatedAtBlock < ?")
 		q.args = append(q.args, q.toCreationBlock.Unwrap())
 	}
+	if q.fromConfirmation.IsSome() {
+		q.filters = append(q.filters, "e.ConfirmedAtBlock >= ?")
+		q.args = append(q.args, q.fromConfirmation.Unwrap())
+	}
+	if q.toConfirmation.IsSome() {
+		q.filters = append(q.filters, "e.ConfirmedAtBlock < ?")
+		q.args = append(q.args, q.toConfirmation.Unwrap())
+	}
 	if len(q.filters) > 0 {
 		if !q.onlySubchallenged {
 			baseQuery += " WHERE "
@@ -655,6 +767,165 @@ func (q *CollectMachineHashesQuery) ToSQL() (string, []interface{}) {
 	return baseQuery, q.args
 }
 
+type StakeEventQuery struct {
+	filters []string
+	args    []interface{}
+	limit   int
+	offset  int
+}
+
+func NewStakeEventQuery(opts ...StakeEventOption) *StakeEventQuery {
+	query := &StakeEventQuery{}
+	for _, opt := range opts {
+		opt(query)
+	}
+	return query
+}
+
+type StakeEventOption func(*StakeEventQuery)
+
+func WithStakeEventStaker(staker common.Address) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.filters = append(q.filters, "Staker = ?")
+		q.args = append(q.args, staker)
+	}
+}
+
+func WithStakeEventSource(source string) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.filters = append(q.filters, "Source = ?")
+		q.args = append(q.args, source)
+	}
+}
+
+func WithStakeEventKind(kind string) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.filters = append(q.filters, "Kind = ?")
+		q.args = append(q.args, kind)
+	}
+}
+
+func WithStakeEventSentToExcessReceiver() StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.filters = append(q.filters, "SentToExcessReceiver = TRUE")
+	}
+}
+
+func WithStakeEventsSince(since time.Time) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.filters = append(q.filters, "Timestamp >= ?")
+		q.args = append(q.args, since.UTC())
+	}
+}
+
+func WithStakeEventsUntil(until time.Time) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.filters = append(q.filters, "Timestamp < ?")
+		q.args = append(q.args, until.UTC())
+	}
+}
+
+func WithStakeEventLimit(limit int) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.limit = limit
+	}
+}
+
+func WithStakeEventOffset(offset int) StakeEventOption {
+	return func(q *StakeEventQuery) {
+		q.offset = offset
+	}
+}
+
+func (q *StakeEventQuery) ToSQL() (string, []interface{}) {
+	baseQuery := "SELECT * FROM StakeEvents"
+	if len(q.filters) > 0 {
+		baseQuery += " WHERE " + strings.Join(q.filters, " AND ")
+	}
+	baseQuery += " ORDER BY Timestamp ASC, BlockNumber ASC, LogIndex ASC"
+	if q.limit > 0 {
+		baseQuery += " LIMIT ?"
+		q.args = append(q.args, q.limit)
+	}
+	if q.offset > 0 {
+		baseQuery += " OFFSET ?"
+		q.args = append(q.args, q.offset)
+	}
+	return baseQuery, q.args
+}
+
+type AuditEntryQuery struct {
+	filters []string
+	args    []interface{}
+	limit   int
+}
+
+func NewAuditEntryQuery(opts ...AuditEntryOption) *AuditEntryQuery {
+	query := &AuditEntryQuery{}
+	for _, opt := range opts {
+		opt(query)
+	}
+	return query
+}
+
+type AuditEntryOption func(*AuditEntryQuery)
+
+func WithAuditEntriesFromSeq(seq uint64) AuditEntryOption {
+	return func(q *AuditEntryQuery) {
+		q.filters = append(q.filters, "Seq >= ?")
+		q.args = append(q.args, seq)
+	}
+}
+
+func WithAuditEntryAction(action string) AuditEntryOption {
+	return func(q *AuditEntryQuery) {
+		q.filters = append(q.filters, "Action = ?")
+		q.args = append(q.args, action)
+	}
+}
+
+func WithAuditEntriesSince(since time.Time) AuditEntryOption {
+	return func(q *AuditEntryQuery) {
+		q.filters = append(q.filters, "Timestamp >= ?")
+		q.args = append(q.args, since.UTC())
+	}
+}
+
+func WithAuditEntriesUntil(until time.Time) AuditEntryOption {
+	return func(q *AuditEntryQuery) {
+		q.filters = append(q.filters, "Timestamp < ?")
+		q.args = append(q.args, until.UTC())
+	}
+}
+
+// WithAuditEntryInputsContaining only includes entries whose JSON encoded inputs contain a string,
+// such as the hex encoded id of the edge an operation was performed on.
+func WithAuditEntryInputsContaining(s string) AuditEntryOption {
+	return func(q *AuditEntryQuery) {
+		q.filters = append(q.filters, "Inputs LIKE ?")
+		q.args = append(q.args, "%"+s+"%")
+	}
+}
+
+func WithAuditEntryLimit(limit int) AuditEntryOption {
+	return func(q *AuditEntryQuery) {
+		q.limit = limit
+	}
+}
+
+func (q *AuditEntryQuery) ToSQL() (string, []interface{}) {
+	baseQuery := "SELECT * FROM AuditLog"
+	if len(q.filters) > 0 {
+		baseQuery += " WHERE " + strings.Join(q.filters, " AND ")
+	}
+	baseQuery += " ORDER BY Seq ASC"
+	if q.limit > 0 {
+		baseQuery += " LIMIT ?"
+		q.args = append(q.args, q.limit)
+	}
+	return baseQuery, q.args
+}
+
 func (d *SqliteDatabase) GetEdges(opts ...EdgeOption) ([]*api.JsonEdge, error) {
 	query := NewEdgeQuery(opts...)
 	sql, args := query.ToSQL()
@@ -693,13 +964,13 @@ func (d *SqliteDatabase) InsertAssertion(a *api.JsonAssertion) error {
         AfterInboxBatchAcc, WasmModuleRoot, ChallengeManager, CreationBlock, TransactionHash,
         BeforeStateBlockHash, BeforeStateSendRoot, BeforeStateBatch, BeforeStatePosInBatch, BeforeStateMachineStatus, AfterStateBlockHash,
         AfterStateSendRoot, AfterStateBatch, AfterStatePosInBatch, AfterStateMachineStatus, FirstChildBlock, SecondChildBlock,
-        IsFirstChild, Status
+        IsFirstChild, Status, BeforeStateEndHistoryRoot, AfterStateEndHistoryRoot
     ) VALUES (
         :Hash, :ConfirmPeriodBlocks, :RequiredStake, :ParentAssertionHash, :InboxMaxCount,
         :AfterInboxBatchAcc, :WasmModuleRoot, :ChallengeManager, :CreationBlock, :TransactionHash,
         :BeforeStateBlockHash, :BeforeStateSendRoot, :BeforeStateBatch, :BeforeStatePosInBatch, :BeforeStateMachineStatus, :AfterStateBlockHash,
         :AfterStateSendRoot,:AfterStateBatch,:AfterStatePosInBatch, :AfterStateMachineStatus, :FirstChildBlock, :SecondChildBlock,
-        :IsFirstChild, :Status
+        :IsFirstChild, :Status, :BeforeStateEndHistoryRoot, :AfterStateEndHistoryRoot
     )`
 	_, err = d.sqlDB.NamedExec(query, a)
 	if err != nil {
@@ -798,7 +1069,7 @@ func (d *SqliteDatabase) InsertEdge(edge *api.JsonEdge) error {
 		}
 		if claimExistsInDb == 0 {
 			var refersTo string
-			if edge.ChallengeLevel == 0 {
+			if protocol.ChallengeLevel(edge.ChallengeLevel).IsBlockChallengeLevel() {
 				refersTo = "assertion"
 			} else {
 				refersTo = "edge"
@@ -841,7 +1112,8 @@ func (d *SqliteDatabase) UpdateEdges(edges []*api.JsonEdge) error {
 	 IsRoyal = :IsRoyal,
 	 InheritedTimer = :InheritedTimer,
 	 CumulativePathTimer = :CumulativePathTimer,
-	 RawAncestors = :RawAncestors
+	 RawAncestors = :RawAncestors,
+	 ConfirmedAtBlock = COALESCE(:ConfirmedAtBlock, ConfirmedAtBlock)
 	 WHERE Id = :Id`
 	tx, err := d.sqlDB.Beginx()
 	if err != nil {
@@ -862,6 +1134,131 @@ func (d *SqliteDatabase) UpdateEdges(edges []*api.JsonEdge) error {
 	return tx.Commit()
 }
 
+// UpdateAdoptedLowerChild records that the bisection of an edge adopted a lower child which had
+// already been created by the bisection of a rival. As the child is shared with our edge, it is royal.
+func (d *SqliteDatabase) UpdateAdoptedLowerChild(parentId, lowerChildId common.Hash) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	tx, err := d.sqlDB.Beginx()
+	if err != nil {
+		return err
+	}
+	// Edges is a view written through triggers, which do not count towards the rows affected by an
+	// update, so whether the bisected edge exists is checked beforehand.
+	var parentExists int
+	if err = tx.Get(&parentExists, "SELECT COUNT(*) FROM Edges WHERE Id = ?", parentId); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return err2
+		}
+		return err
+	}
+	if parentExists == 0 {
+		if err2 := tx.Rollback(); err2 != nil {
+			return err2
+		}
+		return fmt.Errorf("bisected edge %#x not found", parentId)
+	}
+	if _, err = tx.Exec(
+		"UPDATE Edges SET HasChildren = TRUE, LowerChildId = ?, LowerChildAlreadyExists = TRUE WHERE Id = ?",
+		lowerChildId, parentId,
+	); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return err2
+		}
+		return err
+	}
+	if _, err = tx.Exec("UPDATE Edges SET IsRoyal = TRUE WHERE Id = ?", lowerChildId); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return err2
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateEdgeConfirmation records that an edge was confirmed at a block. Edges which are not stored
+// are ignored, as the edges of challenges which are not tracked are not.
+func (d *SqliteDatabase) UpdateEdgeConfirmation(edgeId common.Hash, confirmedAtBlock uint64) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, err := d.sqlDB.Exec(
+		"UPDATE Edges SET Status = ?, ConfirmedAtBlock = ? WHERE Id = ?",
+		protocol.EdgeConfirmed.String(), confirmedAtBlock, edgeId,
+	)
+	return err
+}
+
+// InsertStakeEvent inserts a stake event, returning false if it had already been inserted.
+func (d *SqliteDatabase) InsertStakeEvent(e *api.JsonStakeEvent) (bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	query := `INSERT OR IGNORE INTO StakeEvents (
+        Staker, Kind, Source, EdgeId, Amount, BlockNumber, TransactionHash, LogIndex, Timestamp,
+        SentToExcessReceiver
+    ) VALUES (
+        :Staker, :Kind, :Source, :EdgeId, :Amount, :BlockNumber, :TransactionHash, :LogIndex, :Timestamp,
+        :SentToExcessReceiver
+    )`
+	res, err := d.sqlDB.NamedExec(query, e)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted > 0, nil
+}
+
+// InsertDeploymentStartBlock records the start block of a challenge manager deployment,
+// replacing any previously recorded one.
+func (d *SqliteDatabase) InsertDeploymentStartBlock(b *api.JsonDeploymentStartBlock) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	query := `INSERT OR REPLACE INTO DeploymentStartBlocks (
+        ChallengeManager, StartBlock, Source
+    ) VALUES (
+        :ChallengeManager, :StartBlock, :Source
+    )`
+	_, err := d.sqlDB.NamedExec(query, b)
+	return err
+}
+
+// AppendAuditEntry appends an entry to the audit log, setting its sequence number, the hash of the
+// previous entry and its own hash.
+func (d *SqliteDatabase) AppendAuditEntry(e *api.JsonAuditEntry) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	tx, err := d.sqlDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	last := make([]*api.JsonAuditEntry, 0, 1)
+	if err = tx.Select(&last, "SELECT * FROM AuditLog ORDER BY Seq DESC LIMIT 1"); err != nil {
+		return err
+	}
+	e.Seq = 1
+	e.PrevHash = common.Hash{}
+	if len(last) > 0 {
+		e.Seq = last[0].Seq + 1
+		e.PrevHash = last[0].Hash
+	}
+	e.Timestamp = e.Timestamp.UTC()
+	e.Hash = e.ComputeHash()
+	query := `INSERT INTO AuditLog (
+        Seq, Timestamp, Principal, Action, Inputs, Result, Error, PrevHash, Hash
+    ) VALUES (
+        :Seq, :Timestamp, :Principal, :Action, :Inputs, :Result, :Error, :PrevHash, :Hash
+    )`
+	if _, err = tx.NamedExec(query, e); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (d *SqliteDatabase) InsertCollectMachineHash(h *api.JsonCollectMachineHashes) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
@@ -924,7 +1321,9 @@ func (d *SqliteDatabase) UpdateAssertions(assertions []*api.JsonAssertion) error
    FirstChildBlock = :FirstChildBlock,
    SecondChildBlock = :SecondChildBlock,
    IsFirstChild = :IsFirstChild,
-   Status = :Status
+   Status = :Status,
+   BeforeStateEndHistoryRoot = :BeforeStateEndHistoryRoot,
+   AfterStateEndHistoryRoot = :AfterStateEndHistoryRoot
    WHERE Hash = :Hash`
 	tx, err := d.sqlDB.Beginx()
 	if err != nil {
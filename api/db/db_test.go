@@ -1,7 +1,12 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,6 +14,7 @@ import (
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	"github.com/OffchainLabs/bold/state-commitments/history"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
@@ -58,6 +64,94 @@ func TestSqliteDatabase_CollectMachineHashes(t *testing.T) {
 	require.Equal(t, len(ongoingMachineHashesFromDb), 0)
 }
 
+func TestSqliteDatabase_StakeEvents(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	err = dbInit(sqlDB, schemaList)
+	require.NoError(t, err)
+
+	db := &SqliteDatabase{sqlDB: sqlDB}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	alice := common.BytesToAddress([]byte("alice"))
+	bob := common.BytesToAddress([]byte("bob"))
+	events := []*api.JsonStakeEvent{
+		{Staker: alice, Kind: api.StakeEventLocked, Source: api.StakeSourceAssertion, Amount: "100", BlockNumber: 1, TransactionHash: common.BytesToHash([]byte("tx1")), Timestamp: start},
+		{Staker: bob, Kind: api.StakeEventLocked, Source: api.StakeSourceEdge, EdgeId: common.BytesToHash([]byte("edge")), Amount: "10", BlockNumber: 2, TransactionHash: common.BytesToHash([]byte("tx2")), Timestamp: start.Add(time.Hour)},
+		{Staker: alice, Kind: api.StakeEventRefunded, Source: api.StakeSourceAssertion, Amount: "100", BlockNumber: 3, TransactionHash: common.BytesToHash([]byte("tx3")), Timestamp: start.Add(48 * time.Hour)},
+	}
+	for _, e := range events {
+		inserted, err2 := db.InsertStakeEvent(e)
+		require.NoError(t, err2)
+		require.True(t, inserted)
+	}
+	// Events are uniquely identified by their log, so rescanning a range does not duplicate them.
+	inserted, err := db.InsertStakeEvent(events[0])
+	require.NoError(t, err)
+	require.False(t, inserted)
+
+	fromDb, err := db.GetStakeEvents()
+	require.NoError(t, err)
+	require.Equal(t, events, fromDb)
+
+	fromDb, err = db.GetStakeEvents(WithStakeEventStaker(alice))
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStakeEvent{events[0], events[2]}, fromDb)
+
+	fromDb, err = db.GetStakeEvents(WithStakeEventsSince(start.Add(time.Minute)), WithStakeEventsUntil(start.Add(24*time.Hour)))
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStakeEvent{events[1]}, fromDb)
+
+	fromDb, err = db.GetStakeEvents(WithStakeEventSource(api.StakeSourceEdge))
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStakeEvent{events[1]}, fromDb)
+
+	fromDb, err = db.GetStakeEvents(WithStakeEventLimit(1), WithStakeEventOffset(1))
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonStakeEvent{events[1]}, fromDb)
+}
+
+func TestSqliteDatabase_DeploymentStartBlocks(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	err = dbInit(sqlDB, schemaList)
+	require.NoError(t, err)
+
+	db := &SqliteDatabase{sqlDB: sqlDB}
+	challengeManager := common.BytesToAddress([]byte("challengeManager"))
+	fromDb, err := db.GetDeploymentStartBlock(challengeManager)
+	require.NoError(t, err)
+	require.True(t, fromDb.IsNone())
+
+	discovered := &api.JsonDeploymentStartBlock{
+		ChallengeManager: challengeManager,
+		StartBlock:       100,
+		Source:           api.DeploymentStartBlockDiscovered,
+	}
+	require.NoError(t, db.InsertDeploymentStartBlock(discovered))
+	fromDb, err = db.GetDeploymentStartBlock(challengeManager)
+	require.NoError(t, err)
+	require.Equal(t, discovered, fromDb.Unwrap())
+
+	// An override replaces the discovered start block.
+	override := &api.JsonDeploymentStartBlock{
+		ChallengeManager: challengeManager,
+		StartBlock:       150,
+		Source:           api.DeploymentStartBlockOverride,
+	}
+	require.NoError(t, db.InsertDeploymentStartBlock(override))
+	fromDb, err = db.GetDeploymentStartBlock(challengeManager)
+	require.NoError(t, err)
+	require.Equal(t, override, fromDb.Unwrap())
+
+	fromDb, err = db.GetDeploymentStartBlock(common.BytesToAddress([]byte("other")))
+	require.NoError(t, err)
+	require.True(t, fromDb.IsNone())
+}
+
 func TestSqliteDatabase_UpdateEdgeSchema(t *testing.T) {
 	t.Skip()
 	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
@@ -153,6 +247,46 @@ func TestSqliteDatabase_Updates(t *testing.T) {
 	require.Equal(t, true, lastUpdated.Before(updatedEdges[0].LastUpdatedAt))
 }
 
+func TestSqliteDatabase_UpdateAdoptedLowerChild(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	err = dbInit(sqlDB, schemaList)
+	require.NoError(t, err)
+
+	db := &SqliteDatabase{sqlDB: sqlDB}
+	assertion := baseAssertion()
+	require.NoError(t, db.InsertAssertion(assertion))
+
+	// The shared lower child was created by the rival's bisection, before we knew it to be royal.
+	parent := baseEdge()
+	parent.Id = common.BytesToHash([]byte("parent"))
+	parent.AssertionHash = assertion.Hash
+	parent.IsRoyal = true
+	child := baseEdge()
+	child.Id = common.BytesToHash([]byte("child"))
+	child.AssertionHash = assertion.Hash
+	require.NoError(t, db.InsertEdges([]*api.JsonEdge{parent, child}))
+
+	require.NoError(t, db.UpdateAdoptedLowerChild(parent.Id, child.Id))
+
+	edges, err := db.GetEdges(WithId(protocol.EdgeId{Hash: parent.Id}))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(edges))
+	require.True(t, edges[0].HasChildren)
+	require.True(t, edges[0].LowerChildAlreadyExists)
+	require.Equal(t, child.Id, edges[0].LowerChildId)
+	edges, err = db.GetEdges(WithId(protocol.EdgeId{Hash: child.Id}))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(edges))
+	require.True(t, edges[0].IsRoyal)
+	require.False(t, edges[0].LowerChildAlreadyExists)
+
+	err = db.UpdateAdoptedLowerChild(common.BytesToHash([]byte("unknown")), child.Id)
+	require.ErrorContains(t, err, "not found")
+}
+
 func TestSqliteDatabase_Assertions(t *testing.T) {
 	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
 	require.NoError(t, err)
@@ -602,3 +736,407 @@ func baseEdge() *api.JsonEdge {
 		CreatedAtBlock:    1,
 	}
 }
+
+func TestSqliteDatabase_AuditLog(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	err = dbInit(sqlDB, schemaList)
+	require.NoError(t, err)
+
+	db := &SqliteDatabase{sqlDB: sqlDB}
+	logger := NewAuditLogger(db, "staker")
+	logger.Record(api.AuditActionPostAssertion, map[string]any{"batch": 1}, map[string]any{"assertionHash": common.Hash{1}}, nil)
+	logger.Record(api.AuditActionBisectEdge, map[string]any{"edgeId": common.Hash{2}}, nil, errors.New("reverted"))
+	logger.Record(api.AuditActionConfirmEdgeByTime, map[string]any{"edgeId": common.Hash{3}}, nil, nil)
+
+	entries, err := db.GetAuditEntries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for i, e := range entries {
+		require.Equal(t, uint64(i+1), e.Seq)
+		require.Equal(t, "staker", e.Principal)
+	}
+	require.Equal(t, common.Hash{}, entries[0].PrevHash)
+	require.Equal(t, `{"batch":1}`, entries[0].Inputs)
+	require.Equal(t, "null", entries[1].Result)
+	require.Equal(t, "reverted", entries[1].Error)
+	require.NoError(t, api.VerifyAuditChain(entries, common.Hash{}))
+
+	entries, err = db.GetAuditEntries(WithAuditEntriesFromSeq(2), WithAuditEntryLimit(1))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, api.AuditActionBisectEdge, entries[0].Action)
+
+	entries, err = db.GetAuditEntries(WithAuditEntryInputsContaining(common.Hash{3}.Hex()))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, api.AuditActionConfirmEdgeByTime, entries[0].Action)
+
+	entries, err = db.GetAuditEntries(WithAuditEntriesSince(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	require.Len(t, entries, 0)
+	entries, err = db.GetAuditEntries(WithAuditEntriesUntil(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// Modifying an entry breaks the chain at that entry.
+	_, err = sqlDB.Exec(`UPDATE AuditLog SET Error = '' WHERE Seq = 2`)
+	require.NoError(t, err)
+	entries, err = db.GetAuditEntries()
+	require.NoError(t, err)
+	err = api.VerifyAuditChain(entries, common.Hash{})
+	var chainErr *api.AuditChainError
+	require.ErrorAs(t, err, &chainErr)
+	require.Equal(t, uint64(2), chainErr.Seq)
+}
+
+func TestSqliteDatabase_QueryAnalytics(t *testing.T) {
+	// Analytics queries run on a separate connection, which needs a database stored in a file.
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "bold.db"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	assertion := baseAssertion()
+	require.NoError(t, db.InsertAssertion(assertion))
+	royal := baseEdge()
+	royal.Id = common.BytesToHash([]byte("royal"))
+	royal.AssertionHash = assertion.Hash
+	royal.MutualId = common.BytesToHash([]byte("mutual"))
+	royal.IsRoyal = true
+	royal.CreatedAtBlock = 5
+	rival := baseEdge()
+	rival.Id = common.BytesToHash([]byte("rival"))
+	rival.AssertionHash = assertion.Hash
+	rival.MutualId = royal.MutualId
+	rival.CreatedAtBlock = 9
+	require.NoError(t, db.InsertEdges([]*api.JsonEdge{royal, rival}))
+	staker := common.BytesToAddress([]byte("staker"))
+	_, err = db.InsertStakeEvent(&api.JsonStakeEvent{
+		Staker:          staker,
+		Kind:            api.StakeEventLocked,
+		Source:          api.StakeSourceEdge,
+		EdgeId:          rival.Id,
+		Amount:          "10",
+		BlockNumber:     9,
+		TransactionHash: common.BytesToHash([]byte("tx")),
+		Timestamp:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	result, err := db.QueryAnalytics(ctx, "SELECT Id, NumRivals, BlocksSinceAssertion FROM edges_enriched ORDER BY CreatedAtBlock;", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Id", "NumRivals", "BlocksSinceAssertion"}, result.Columns)
+	require.Equal(t, [][]any{
+		{royal.Id.Hex(), int64(1), int64(4)},
+		{rival.Id.Hex(), int64(1), int64(8)},
+	}, result.Rows)
+	require.False(t, result.Truncated)
+
+	result, err = db.QueryAnalytics(ctx, "SELECT NumEdges, NumRoyalEdges, DurationBlocks FROM challenge_timelines", 10)
+	require.NoError(t, err)
+	require.Equal(t, [][]any{{int64(2), int64(1), int64(4)}}, result.Rows)
+
+	result, err = db.QueryAnalytics(ctx, "with flows as (select Staker, Direction, IsRoyal from stake_flows) select * from flows", 10)
+	require.NoError(t, err)
+	require.Equal(t, [][]any{{staker.Hex(), "in", false}}, result.Rows)
+
+	result, err = db.QueryAnalytics(ctx, "SELECT Id FROM Edges", 1)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	require.True(t, result.Truncated)
+
+	// Queries do not wait for the lock held by writes.
+	db.lock.Lock()
+	result, err = db.QueryAnalytics(ctx, "SELECT Id FROM Edges", 10)
+	db.lock.Unlock()
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+
+	// Only single SELECT statements are run, and the database cannot be modified.
+	for _, query := range []string{
+		"",
+		"DELETE FROM Edges",
+		"SELECT 1; DELETE FROM Edges",
+		"WITH x AS (SELECT 1) DELETE FROM Edges",
+		"SELECT * FROM NoSuchTable",
+	} {
+		_, err = db.QueryAnalytics(ctx, query, 10)
+		require.ErrorIs(t, err, ErrInvalidAnalyticsQuery, query)
+	}
+	_, err = (&SqliteDatabase{}).QueryAnalytics(ctx, "SELECT 1", 10)
+	require.ErrorIs(t, err, ErrNoAnalyticsConnection)
+	edges, err := db.GetEdges()
+	require.NoError(t, err)
+	require.Len(t, edges, 2)
+	require.NoError(t, db.InsertEdge(func() *api.JsonEdge {
+		e := baseEdge()
+		e.Id = common.BytesToHash([]byte("writable"))
+		e.AssertionHash = assertion.Hash
+		return e
+	}()))
+}
+
+func TestSqliteDatabase_AssertionExecutionStates(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	err = dbInit(sqlDB, schemaList)
+	require.NoError(t, err)
+
+	db := &SqliteDatabase{sqlDB: sqlDB}
+	before := &protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash:  common.BytesToHash([]byte("before")),
+			SendRoot:   common.BytesToHash([]byte("beforeSendRoot")),
+			Batch:      2,
+			PosInBatch: 0,
+		},
+		MachineStatus:  protocol.MachineStatusFinished,
+		EndHistoryRoot: common.BytesToHash([]byte("beforeRoot")),
+	}
+	after := &protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash:  common.BytesToHash([]byte("after")),
+			SendRoot:   common.BytesToHash([]byte("afterSendRoot")),
+			Batch:      5,
+			PosInBatch: 3,
+		},
+		MachineStatus:  protocol.MachineStatusErrored,
+		EndHistoryRoot: common.BytesToHash([]byte("afterRoot")),
+	}
+	assertion := baseAssertion()
+	assertion.SetStates(before, after)
+	require.NoError(t, db.InsertAssertion(assertion))
+
+	assertions, err := db.GetAssertions()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(assertions))
+	assertions[0].DecodeStates()
+	require.Equal(t, &api.JsonExecutionState{
+		BlockHash:       before.GlobalState.BlockHash,
+		SendRoot:        before.GlobalState.SendRoot,
+		Batch:           2,
+		PosInBatch:      0,
+		MachineStatus:   "finished",
+		EndHistoryRoot:  before.EndHistoryRoot,
+		GlobalStateHash: before.GlobalState.Hash(),
+		RequiredBatches: 2,
+	}, assertions[0].BeforeState)
+	require.Equal(t, &api.JsonExecutionState{
+		BlockHash:       after.GlobalState.BlockHash,
+		SendRoot:        after.GlobalState.SendRoot,
+		Batch:           5,
+		PosInBatch:      3,
+		MachineStatus:   "errored",
+		EndHistoryRoot:  after.EndHistoryRoot,
+		GlobalStateHash: after.GlobalState.Hash(),
+		RequiredBatches: 6,
+	}, assertions[0].AfterState)
+}
+
+// challengeEdges makes the edges of challenges between two stakers each bisecting their root edge
+// down to one step, the way rivals do, with the values shared between the edges of a challenge.
+func challengeEdges(numChallenges int, height uint64) []*api.JsonEdge {
+	stakers := []common.Address{common.BytesToAddress([]byte("alice")), common.BytesToAddress([]byte("bob"))}
+	var edges []*api.JsonEdge
+	for c := 0; c < numChallenges; c++ {
+		assertionHash := crypto.Keccak256Hash([]byte("assertion"), []byte{byte(c), byte(c >> 8)})
+		origin := crypto.Keccak256Hash(assertionHash.Bytes(), []byte("origin"))
+		startRoot := crypto.Keccak256Hash(origin.Bytes(), []byte("start"))
+		for s, staker := range stakers {
+			root := func(h uint64) common.Hash {
+				if h == 0 {
+					return startRoot
+				}
+				return crypto.Keccak256Hash(origin.Bytes(), staker.Bytes(), common.BigToHash(new(big.Int).SetUint64(h)).Bytes())
+			}
+			id := func(start, end uint64) common.Hash {
+				return crypto.Keccak256Hash(origin.Bytes(), root(start).Bytes(), root(end).Bytes())
+			}
+			var bisect func(start, end uint64)
+			bisect = func(start, end uint64) {
+				edge := baseEdge()
+				edge.Id = id(start, end)
+				edge.OriginId = origin
+				edge.AssertionHash = assertionHash
+				edge.StartHistoryRoot = root(start)
+				edge.StartHeight = start
+				edge.EndHistoryRoot = root(end)
+				edge.EndHeight = end
+				edge.MutualId = crypto.Keccak256Hash(origin.Bytes(), root(start).Bytes(), []byte{byte(start), byte(end)})
+				edge.MiniStaker = staker
+				edge.HasRival = true
+				edge.IsRoyal = s == 0
+				edge.CreatedAtBlock = uint64(c)*1000 + end
+				if end-start > 1 {
+					mid := start + (end-start)/2
+					edge.HasChildren = true
+					edge.LowerChildId = id(start, mid)
+					edge.UpperChildId = id(mid, end)
+				}
+				edges = append(edges, edge)
+				if end-start > 1 {
+					mid := start + (end-start)/2
+					bisect(start, mid)
+					bisect(mid, end)
+				}
+			}
+			bisect(0, height)
+		}
+	}
+	return edges
+}
+
+func databaseSize(t *testing.T, sqlDB *sqlx.DB) int {
+	_, err := sqlDB.Exec("VACUUM")
+	require.NoError(t, err)
+	var pageCount, pageSize int
+	require.NoError(t, sqlDB.Get(&pageCount, "PRAGMA page_count"))
+	require.NoError(t, sqlDB.Get(&pageSize, "PRAGMA page_size"))
+	return pageCount * pageSize
+}
+
+func TestSqliteDatabase_EdgeCompression(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", filepath.Join(t.TempDir(), "bold.db"))
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	// Edges stored before the compression are migrated.
+	uncompressed := schemaList[:9]
+	require.NoError(t, dbInit(sqlDB, uncompressed))
+	db := &SqliteDatabase{sqlDB: sqlDB}
+	require.NoError(t, db.InsertEdges(challengeEdges(20, 64)))
+	want, err := db.GetEdges()
+	require.NoError(t, err)
+	sizeBefore := databaseSize(t, sqlDB)
+
+	require.NoError(t, dbInit(sqlDB, schemaList))
+	got, err := db.GetEdges()
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	sizeAfter := databaseSize(t, sqlDB)
+	t.Logf("Database of %d edges compressed from %d to %d bytes", len(got), sizeBefore, sizeAfter)
+	// Most values of these edges are distinct, each edge adding two or three to the dictionary, so
+	// this is a lower bound: the more challenges share values, the more they are compressed.
+	require.Less(t, sizeAfter*3, sizeBefore*2)
+
+	// Edges are queried by their values, and written through the view.
+	edge := want[len(want)-1]
+	edges, err := db.GetEdges(WithId(protocol.EdgeId{Hash: edge.Id}))
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonEdge{edge}, edges)
+	edges, err = db.GetEdges(WithMutualId(protocol.MutualId(edge.MutualId)), WithMiniStaker(edge.MiniStaker))
+	require.NoError(t, err)
+	require.Equal(t, []*api.JsonEdge{edge}, edges)
+	edges, err = db.GetEdges(WithEdgeAssertionHash(protocol.AssertionHash{Hash: edge.AssertionHash}))
+	require.NoError(t, err)
+	require.Len(t, edges, len(want)/20)
+
+	edge.Status = "confirmed"
+	edge.ClaimId = common.BytesToHash([]byte("claim"))
+	edge.InheritedTimer = 7
+	require.NoError(t, db.UpdateEdges([]*api.JsonEdge{edge}))
+	edges, err = db.GetEdges(WithClaimId(protocol.ClaimId(edge.ClaimId)))
+	require.NoError(t, err)
+	require.Len(t, edges, 1)
+	require.Equal(t, "confirmed", edges[0].Status)
+	require.Equal(t, uint64(7), edges[0].InheritedTimer)
+
+	inserted := baseEdge()
+	inserted.Id = common.BytesToHash([]byte("inserted"))
+	inserted.MutualId = edge.MutualId
+	require.NoError(t, db.InsertEdge(inserted))
+	edges, err = db.GetEdges(WithMutualId(protocol.MutualId(edge.MutualId)))
+	require.NoError(t, err)
+	require.Len(t, edges, 2)
+	require.Equal(t, inserted.Id, edges[1].Id)
+	_, err = sqlDB.Exec("INSERT INTO Edges SELECT * FROM Edges WHERE Id = ?", inserted.Id)
+	require.ErrorContains(t, err, "UNIQUE constraint failed")
+}
+
+func TestParseHashPrefix(t *testing.T) {
+	for _, invalid := range []string{"", "0x", "0xab", "0xabcg", "0x" + strings.Repeat("a", 65)} {
+		_, err := ParseHashPrefix(invalid)
+		require.Error(t, err, invalid)
+	}
+	hash := common.HexToHash("0xabcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789")
+	for _, valid := range []string{"0xabcd", "ABCDE", " 0xabcdef01... ", "0xabcdef01…", hash.Hex()} {
+		prefix, err := ParseHashPrefix(valid)
+		require.NoError(t, err, valid)
+		require.True(t, prefix.Matches(hash), valid)
+	}
+	prefix, err := ParseHashPrefix("0xabcdf")
+	require.NoError(t, err)
+	require.Equal(t, "0xabcdf", prefix.String())
+	require.False(t, prefix.Matches(hash))
+}
+
+func TestSqliteDatabase_HashPrefixSearch(t *testing.T) {
+	sqlDB, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	require.NoError(t, dbInit(sqlDB, schemaList))
+	db := &SqliteDatabase{sqlDB: sqlDB}
+
+	for i, hash := range []string{"0xabcd01", "0xabcd02", "0xabce01"} {
+		a := baseAssertion()
+		a.Hash = common.HexToHash(hash + strings.Repeat("0", 58))
+		a.TransactionHash = common.HexToHash(fmt.Sprintf("0x%02x", i) + strings.Repeat("f", 62))
+		require.NoError(t, db.InsertAssertion(a))
+	}
+	edges := challengeEdges(2, 8)
+	require.NoError(t, db.InsertEdges(edges))
+
+	parse := func(s string) HashPrefix {
+		prefix, err := ParseHashPrefix(s)
+		require.NoError(t, err)
+		return prefix
+	}
+	assertions, err := db.GetAssertions(WithAssertionHashPrefix(parse("0xabcd")))
+	require.NoError(t, err)
+	require.Len(t, assertions, 2)
+	assertions, err = db.GetAssertions(WithAssertionHashPrefix(parse("0xabcd02")))
+	require.NoError(t, err)
+	require.Len(t, assertions, 1)
+	require.Equal(t, common.HexToHash("0xabcd02"+strings.Repeat("0", 58)), assertions[0].Hash)
+	assertions, err = db.GetAssertions(WithTransactionHashPrefix(parse("0x01ff")))
+	require.NoError(t, err)
+	require.Len(t, assertions, 1)
+	require.Equal(t, assertions[0].Hash, common.HexToHash("0xabcd02"+strings.Repeat("0", 58)))
+
+	edge := edges[len(edges)-1]
+	got, err := db.GetEdges(WithIdPrefix(parse(edge.Id.Hex()[:10])))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, edge.Id, got[0].Id)
+	// The root edges of the stakers of a challenge are rivals.
+	root := edges[0]
+	got, err = db.GetEdges(WithMutualIdPrefix(parse(root.MutualId.Hex()[:10])))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, e := range got {
+		require.Equal(t, root.MutualId, e.MutualId)
+	}
+
+	// Searches use the indexes on the hashes rather than scanning the tables.
+	plans := map[string][]interface{}{}
+	query, args := NewAssertionQuery(WithTransactionHashPrefix(parse("0x01ff"))).ToSQL()
+	plans[query] = args
+	query, args = NewEdgeQuery(WithIdPrefix(parse("0xabcd"))).ToSQL()
+	plans[query] = args
+	query, args = NewEdgeQuery(WithMutualIdPrefix(parse("0xabcd"))).ToSQL()
+	plans[query] = args
+	for query, args := range plans {
+		rows, err := sqlDB.Queryx("EXPLAIN QUERY PLAN "+query, args...)
+		require.NoError(t, err)
+		for rows.Next() {
+			row, err := rows.SliceScan()
+			require.NoError(t, err)
+			detail := fmt.Sprint(row[len(row)-1])
+			require.NotContains(t, detail, "SCAN", query)
+		}
+		require.NoError(t, rows.Close())
+	}
+}
@@ -121,7 +121,454 @@ CREATE TABLE IF NOT EXISTS CollectMachineHashes (
 `
 	version3 = `
 	ALTER TABLE Edges ADD COLUMN CumulativePathTimer INTEGER NOT NULL DEFAULT 0;
+`
+	version4 = `
+CREATE TABLE IF NOT EXISTS StakeEvents (
+    Staker TEXT NOT NULL,
+    Kind TEXT NOT NULL, -- 'locked' or 'refunded'
+    Source TEXT NOT NULL, -- 'assertion' or 'edge'
+    EdgeId TEXT NOT NULL,
+    Amount TEXT NOT NULL,
+    BlockNumber INTEGER NOT NULL,
+    TransactionHash TEXT NOT NULL,
+    LogIndex INTEGER NOT NULL,
+    Timestamp DATETIME NOT NULL,
+    PRIMARY KEY(TransactionHash, LogIndex)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stake_events_timestamp ON StakeEvents(Timestamp);
+`
+	version5 = `
+CREATE TABLE IF NOT EXISTS DeploymentStartBlocks (
+    ChallengeManager TEXT NOT NULL PRIMARY KEY,
+    StartBlock INTEGER NOT NULL,
+    Source TEXT NOT NULL -- 'discovered' or 'override'
+);
+`
+	version6 = `
+CREATE TABLE IF NOT EXISTS AuditLog (
+    Seq INTEGER NOT NULL PRIMARY KEY,
+    Timestamp DATETIME NOT NULL,
+    Principal TEXT NOT NULL,
+    Action TEXT NOT NULL,
+    Inputs TEXT NOT NULL,
+    Result TEXT NOT NULL,
+    Error TEXT NOT NULL,
+    PrevHash TEXT NOT NULL,
+    Hash TEXT NOT NULL
+);
+`
+	version7 = `
+ALTER TABLE Edges ADD COLUMN LowerChildAlreadyExists BOOLEAN NOT NULL DEFAULT FALSE;
+`
+	// Views for analysts, queried through the read-only analytics endpoint. Being views, they are
+	// always up to date with the tables they are built on.
+	version8 = `
+CREATE VIEW IF NOT EXISTS edges_enriched AS
+SELECT
+    e.Id,
+    e.AssertionHash,
+    e.ChallengeLevel,
+    e.OriginId,
+    e.MutualId,
+    e.ClaimId,
+    e.MiniStaker,
+    e.StartHeight,
+    e.EndHeight,
+    e.EndHeight - e.StartHeight AS Length,
+    e.CreatedAtBlock,
+    e.Status,
+    e.IsRoyal,
+    e.HasRival,
+    e.HasLengthOneRival,
+    e.HasChildren,
+    e.InheritedTimer,
+    e.CumulativePathTimer,
+    (SELECT COUNT(*) FROM Edges r WHERE r.MutualId = e.MutualId AND r.Id != e.Id) AS NumRivals,
+    a.CreationBlock AS AssertionCreationBlock,
+    e.CreatedAtBlock - a.CreationBlock AS BlocksSinceAssertion,
+    a.Status AS AssertionStatus
+FROM Edges e
+LEFT JOIN Assertions a ON a.Hash = e.AssertionHash;
+
+CREATE VIEW IF NOT EXISTS challenge_timelines AS
+SELECT
+    e.AssertionHash,
+    a.CreationBlock AS AssertionCreationBlock,
+    MIN(e.CreatedAtBlock) AS FirstEdgeBlock,
+    MAX(e.CreatedAtBlock) AS LastEdgeBlock,
+    MAX(e.CreatedAtBlock) - MIN(e.CreatedAtBlock) AS DurationBlocks,
+    COUNT(*) AS NumEdges,
+    SUM(e.IsRoyal) AS NumRoyalEdges,
+    SUM(e.HasRival) AS NumRivaledEdges,
+    SUM(e.Status = 'confirmed') AS NumConfirmedEdges,
+    MAX(e.ChallengeLevel) AS DeepestChallengeLevel,
+    COUNT(DISTINCT e.MiniStaker) AS NumStakers
+FROM Edges e
+LEFT JOIN Assertions a ON a.Hash = e.AssertionHash
+GROUP BY e.AssertionHash;
+
+CREATE VIEW IF NOT EXISTS stake_flows AS
+SELECT
+    s.Staker,
+    s.Kind,
+    s.Source,
+    CASE s.Kind WHEN 'locked' THEN 'in' ELSE 'out' END AS Direction,
+    s.Amount,
+    s.BlockNumber,
+    s.Timestamp,
+    s.TransactionHash,
+    s.LogIndex,
+    s.EdgeId,
+    e.AssertionHash,
+    e.ChallengeLevel,
+    e.IsRoyal
+FROM StakeEvents s
+LEFT JOIN Edges e ON s.Source = 'edge' AND e.Id = s.EdgeId;
+`
+	// The end history roots of the states of assertions, which complete the execution states they
+	// claim. Assertions stored before are assumed to have zero roots, as the genesis assertion does.
+	version9 = `
+ALTER TABLE Assertions ADD COLUMN BeforeStateEndHistoryRoot TEXT NOT NULL DEFAULT X'0000000000000000000000000000000000000000000000000000000000000000';
+ALTER TABLE Assertions ADD COLUMN AfterStateEndHistoryRoot TEXT NOT NULL DEFAULT X'0000000000000000000000000000000000000000000000000000000000000000';
+`
+	// Edges dominate the database in challenge-heavy histories, and most of each row is made of
+	// 32-byte roots and IDs repeated across rows: rivals share a mutual ID, siblings share a history
+	// root, children are the IDs of other edges, and every edge of a challenge refers to its
+	// assertion and origin. They are moved to a content-addressed dictionary, so that each distinct
+	// value is stored once and rows and their indexes refer to it by a small integer.
+	//
+	// Edges becomes a view over EdgesCompact which decodes the references, so that it is read as
+	// before, and its INSTEAD OF triggers encode the values written to it. Existing edges are
+	// migrated in their insertion order.
+	version10 = `
+CREATE TABLE IF NOT EXISTS Dictionary (
+    Ref INTEGER NOT NULL PRIMARY KEY,
+    Value BLOB NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS EdgesCompact (
+    IdRef INTEGER NOT NULL UNIQUE,
+    ChallengeLevel INTEGER NOT NULL,
+    OriginRef INTEGER NOT NULL,
+    StartHistoryRootRef INTEGER NOT NULL,
+    StartHeight INTEGER NOT NULL,
+    EndHistoryRootRef INTEGER NOT NULL,
+    EndHeight INTEGER NOT NULL,
+    CreatedAtBlock INTEGER NOT NULL,
+    MutualRef INTEGER NOT NULL,
+    ClaimRef INTEGER NOT NULL,
+    MiniStakerRef INTEGER NOT NULL,
+    AssertionRef INTEGER NOT NULL,
+    HasChildren BOOLEAN NOT NULL,
+    LowerChildRef INTEGER NOT NULL,
+    UpperChildRef INTEGER NOT NULL,
+    HasRival BOOLEAN NOT NULL,
+    Status TEXT NOT NULL,
+    HasLengthOneRival BOOLEAN NOT NULL,
+    IsRoyal BOOLEAN NOT NULL,
+    RawAncestors TEXT NOT NULL,
+    LastUpdatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+    InheritedTimer INTEGER NOT NULL DEFAULT 0,
+    CumulativePathTimer INTEGER NOT NULL DEFAULT 0,
+    LowerChildAlreadyExists BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+INSERT OR IGNORE INTO Dictionary (Value)
+SELECT Id FROM Edges UNION SELECT OriginId FROM Edges
+UNION SELECT StartHistoryRoot FROM Edges UNION SELECT EndHistoryRoot FROM Edges
+UNION SELECT MutualId FROM Edges UNION SELECT ClaimId FROM Edges
+UNION SELECT MiniStaker FROM Edges UNION SELECT AssertionHash FROM Edges
+UNION SELECT LowerChildId FROM Edges UNION SELECT UpperChildId FROM Edges;
+
+INSERT INTO EdgesCompact
+SELECT
+    (SELECT Ref FROM Dictionary WHERE Value = e.Id),
+    e.ChallengeLevel,
+    (SELECT Ref FROM Dictionary WHERE Value = e.OriginId),
+    (SELECT Ref FROM Dictionary WHERE Value = e.StartHistoryRoot),
+    e.StartHeight,
+    (SELECT Ref FROM Dictionary WHERE Value = e.EndHistoryRoot),
+    e.EndHeight,
+    e.CreatedAtBlock,
+    (SELECT Ref FROM Dictionary WHERE Value = e.MutualId),
+    (SELECT Ref FROM Dictionary WHERE Value = e.ClaimId),
+    (SELECT Ref FROM Dictionary WHERE Value = e.MiniStaker),
+    (SELECT Ref FROM Dictionary WHERE Value = e.AssertionHash),
+    e.HasChildren,
+    (SELECT Ref FROM Dictionary WHERE Value = e.LowerChildId),
+    (SELECT Ref FROM Dictionary WHERE Value = e.UpperChildId),
+    e.HasRival,
+    e.Status,
+    e.HasLengthOneRival,
+    e.IsRoyal,
+    e.RawAncestors,
+    e.LastUpdatedAt,
+    e.InheritedTimer,
+    e.CumulativePathTimer,
+    e.LowerChildAlreadyExists
+FROM Edges e
+ORDER BY e.rowid;
+
+DROP TABLE Edges;
+
+CREATE INDEX IF NOT EXISTS idx_edge_compact_assertion ON EdgesCompact(AssertionRef);
+CREATE INDEX IF NOT EXISTS idx_edge_compact_claim ON EdgesCompact(ClaimRef);
+CREATE INDEX IF NOT EXISTS idx_edge_compact_mutual ON EdgesCompact(MutualRef);
+CREATE INDEX IF NOT EXISTS idx_edge_compact_end_height ON EdgesCompact(EndHeight);
+CREATE INDEX IF NOT EXISTS idx_edge_compact_end_history_root ON EdgesCompact(EndHistoryRootRef);
+
+CREATE VIEW IF NOT EXISTS Edges AS
+SELECT
+    id.Value AS Id,
+    e.ChallengeLevel,
+    origin.Value AS OriginId,
+    startRoot.Value AS StartHistoryRoot,
+    e.StartHeight,
+    endRoot.Value AS EndHistoryRoot,
+    e.EndHeight,
+    e.CreatedAtBlock,
+    mutual.Value AS MutualId,
+    claim.Value AS ClaimId,
+    staker.Value AS MiniStaker,
+    assertion.Value AS AssertionHash,
+    e.HasChildren,
+    lower.Value AS LowerChildId,
+    upper.Value AS UpperChildId,
+    e.HasRival,
+    e.Status,
+    e.HasLengthOneRival,
+    e.IsRoyal,
+    e.RawAncestors,
+    e.LastUpdatedAt,
+    e.InheritedTimer,
+    e.CumulativePathTimer,
+    e.LowerChildAlreadyExists
+FROM EdgesCompact e
+JOIN Dictionary id ON id.Ref = e.IdRef
+JOIN Dictionary origin ON origin.Ref = e.OriginRef
+JOIN Dictionary startRoot ON startRoot.Ref = e.StartHistoryRootRef
+JOIN Dictionary endRoot ON endRoot.Ref = e.EndHistoryRootRef
+JOIN Dictionary mutual ON mutual.Ref = e.MutualRef
+JOIN Dictionary claim ON claim.Ref = e.ClaimRef
+JOIN Dictionary staker ON staker.Ref = e.MiniStakerRef
+JOIN Dictionary assertion ON assertion.Ref = e.AssertionRef
+JOIN Dictionary lower ON lower.Ref = e.LowerChildRef
+JOIN Dictionary upper ON upper.Ref = e.UpperChildRef;
+
+CREATE TRIGGER IF NOT EXISTS InsertEdge
+INSTEAD OF INSERT ON Edges
+FOR EACH ROW
+BEGIN
+    INSERT OR IGNORE INTO Dictionary (Value) VALUES
+        (NEW.Id), (NEW.OriginId), (NEW.StartHistoryRoot), (NEW.EndHistoryRoot), (NEW.MutualId),
+        (NEW.ClaimId), (NEW.MiniStaker), (NEW.AssertionHash), (NEW.LowerChildId), (NEW.UpperChildId);
+    INSERT INTO EdgesCompact (
+        IdRef, ChallengeLevel, OriginRef, StartHistoryRootRef, StartHeight,
+        EndHistoryRootRef, EndHeight, CreatedAtBlock, MutualRef, ClaimRef,
+        MiniStakerRef, AssertionRef, HasChildren, LowerChildRef, UpperChildRef,
+        HasRival, Status, HasLengthOneRival, IsRoyal, RawAncestors,
+        InheritedTimer, CumulativePathTimer, LowerChildAlreadyExists
+    ) VALUES (
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.Id),
+        NEW.ChallengeLevel,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.OriginId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.StartHistoryRoot),
+        NEW.StartHeight,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.EndHistoryRoot),
+        NEW.EndHeight,
+        NEW.CreatedAtBlock,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.MutualId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.ClaimId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.MiniStaker),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.AssertionHash),
+        NEW.HasChildren,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.LowerChildId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.UpperChildId),
+        NEW.HasRival,
+        NEW.Status,
+        NEW.HasLengthOneRival,
+        NEW.IsRoyal,
+        NEW.RawAncestors,
+        COALESCE(NEW.InheritedTimer, 0),
+        COALESCE(NEW.CumulativePathTimer, 0),
+        COALESCE(NEW.LowerChildAlreadyExists, FALSE)
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS UpdateEdge
+INSTEAD OF UPDATE ON Edges
+FOR EACH ROW
+BEGIN
+    INSERT OR IGNORE INTO Dictionary (Value) VALUES
+        (NEW.OriginId), (NEW.StartHistoryRoot), (NEW.EndHistoryRoot), (NEW.MutualId), (NEW.ClaimId),
+        (NEW.MiniStaker), (NEW.AssertionHash), (NEW.LowerChildId), (NEW.UpperChildId);
+    UPDATE EdgesCompact SET
+        ChallengeLevel = NEW.ChallengeLevel,
+        OriginRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.OriginId),
+        StartHistoryRootRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.StartHistoryRoot),
+        StartHeight = NEW.StartHeight,
+        EndHistoryRootRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.EndHistoryRoot),
+        EndHeight = NEW.EndHeight,
+        CreatedAtBlock = NEW.CreatedAtBlock,
+        MutualRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.MutualId),
+        ClaimRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.ClaimId),
+        MiniStakerRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.MiniStaker),
+        AssertionRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.AssertionHash),
+        HasChildren = NEW.HasChildren,
+        LowerChildRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.LowerChildId),
+        UpperChildRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.UpperChildId),
+        HasRival = NEW.HasRival,
+        Status = NEW.Status,
+        HasLengthOneRival = NEW.HasLengthOneRival,
+        IsRoyal = NEW.IsRoyal,
+        RawAncestors = NEW.RawAncestors,
+        LastUpdatedAt = CURRENT_TIMESTAMP,
+        InheritedTimer = NEW.InheritedTimer,
+        CumulativePathTimer = NEW.CumulativePathTimer,
+        LowerChildAlreadyExists = NEW.LowerChildAlreadyExists
+    WHERE IdRef = (SELECT Ref FROM Dictionary WHERE Value = OLD.Id);
+END;
+`
+	// Assertions are searched by a prefix of the hash of the transaction which created them, as
+	// operators paste truncated hashes from explorers.
+	version11 = `
+CREATE INDEX IF NOT EXISTS idx_assertions_transaction_hash ON Assertions(TransactionHash);
+`
+	// The block at which an edge was confirmed, which is unknown for edges that are not confirmed
+	// and for those confirmed before the column was added. LastUpdatedAt is bumped by every update
+	// of an edge, so it does not tell when the edge was confirmed. Edges is a view, so it is
+	// recreated along with its triggers to expose the column.
+	version12 = `
+ALTER TABLE EdgesCompact ADD COLUMN ConfirmedAtBlock INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_edge_compact_confirmed_at_block ON EdgesCompact(ConfirmedAtBlock);
+
+DROP TRIGGER IF EXISTS InsertEdge;
+DROP TRIGGER IF EXISTS UpdateEdge;
+DROP VIEW IF EXISTS Edges;
+
+CREATE VIEW IF NOT EXISTS Edges AS
+SELECT
+    id.Value AS Id,
+    e.ChallengeLevel,
+    origin.Value AS OriginId,
+    startRoot.Value AS StartHistoryRoot,
+    e.StartHeight,
+    endRoot.Value AS EndHistoryRoot,
+    e.EndHeight,
+    e.CreatedAtBlock,
+    mutual.Value AS MutualId,
+    claim.Value AS ClaimId,
+    staker.Value AS MiniStaker,
+    assertion.Value AS AssertionHash,
+    e.HasChildren,
+    lower.Value AS LowerChildId,
+    upper.Value AS UpperChildId,
+    e.HasRival,
+    e.Status,
+    e.HasLengthOneRival,
+    e.IsRoyal,
+    e.RawAncestors,
+    e.LastUpdatedAt,
+    e.InheritedTimer,
+    e.CumulativePathTimer,
+    e.LowerChildAlreadyExists,
+    e.ConfirmedAtBlock
+FROM EdgesCompact e
+JOIN Dictionary id ON id.Ref = e.IdRef
+JOIN Dictionary origin ON origin.Ref = e.OriginRef
+JOIN Dictionary startRoot ON startRoot.Ref = e.StartHistoryRootRef
+JOIN Dictionary endRoot ON endRoot.Ref = e.EndHistoryRootRef
+JOIN Dictionary mutual ON mutual.Ref = e.MutualRef
+JOIN Dictionary claim ON claim.Ref = e.ClaimRef
+JOIN Dictionary staker ON staker.Ref = e.MiniStakerRef
+JOIN Dictionary assertion ON assertion.Ref = e.AssertionRef
+JOIN Dictionary lower ON lower.Ref = e.LowerChildRef
+JOIN Dictionary upper ON upper.Ref = e.UpperChildRef;
+
+CREATE TRIGGER IF NOT EXISTS InsertEdge
+INSTEAD OF INSERT ON Edges
+FOR EACH ROW
+BEGIN
+    INSERT OR IGNORE INTO Dictionary (Value) VALUES
+        (NEW.Id), (NEW.OriginId), (NEW.StartHistoryRoot), (NEW.EndHistoryRoot), (NEW.MutualId),
+        (NEW.ClaimId), (NEW.MiniStaker), (NEW.AssertionHash), (NEW.LowerChildId), (NEW.UpperChildId);
+    INSERT INTO EdgesCompact (
+        IdRef, ChallengeLevel, OriginRef, StartHistoryRootRef, StartHeight,
+        EndHistoryRootRef, EndHeight, CreatedAtBlock, MutualRef, ClaimRef,
+        MiniStakerRef, AssertionRef, HasChildren, LowerChildRef, UpperChildRef,
+        HasRival, Status, HasLengthOneRival, IsRoyal, RawAncestors,
+        InheritedTimer, CumulativePathTimer, LowerChildAlreadyExists, ConfirmedAtBlock
+    ) VALUES (
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.Id),
+        NEW.ChallengeLevel,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.OriginId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.StartHistoryRoot),
+        NEW.StartHeight,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.EndHistoryRoot),
+        NEW.EndHeight,
+        NEW.CreatedAtBlock,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.MutualId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.ClaimId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.MiniStaker),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.AssertionHash),
+        NEW.HasChildren,
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.LowerChildId),
+        (SELECT Ref FROM Dictionary WHERE Value = NEW.UpperChildId),
+        NEW.HasRival,
+        NEW.Status,
+        NEW.HasLengthOneRival,
+        NEW.IsRoyal,
+        NEW.RawAncestors,
+        COALESCE(NEW.InheritedTimer, 0),
+        COALESCE(NEW.CumulativePathTimer, 0),
+        COALESCE(NEW.LowerChildAlreadyExists, FALSE),
+        NEW.ConfirmedAtBlock
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS UpdateEdge
+INSTEAD OF UPDATE ON Edges
+FOR EACH ROW
+BEGIN
+    INSERT OR IGNORE INTO Dictionary (Value) VALUES
+        (NEW.OriginId), (NEW.StartHistoryRoot), (NEW.EndHistoryRoot), (NEW.MutualId), (NEW.ClaimId),
+        (NEW.MiniStaker), (NEW.AssertionHash), (NEW.LowerChildId), (NEW.UpperChildId);
+    UPDATE EdgesCompact SET
+        ChallengeLevel = NEW.ChallengeLevel,
+        OriginRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.OriginId),
+        StartHistoryRootRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.StartHistoryRoot),
+        StartHeight = NEW.StartHeight,
+        EndHistoryRootRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.EndHistoryRoot),
+        EndHeight = NEW.EndHeight,
+        CreatedAtBlock = NEW.CreatedAtBlock,
+        MutualRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.MutualId),
+        ClaimRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.ClaimId),
+        MiniStakerRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.MiniStaker),
+        AssertionRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.AssertionHash),
+        HasChildren = NEW.HasChildren,
+        LowerChildRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.LowerChildId),
+        UpperChildRef = (SELECT Ref FROM Dictionary WHERE Value = NEW.UpperChildId),
+        HasRival = NEW.HasRival,
+        Status = NEW.Status,
+        HasLengthOneRival = NEW.HasLengthOneRival,
+        IsRoyal = NEW.IsRoyal,
+        RawAncestors = NEW.RawAncestors,
+        LastUpdatedAt = CURRENT_TIMESTAMP,
+        InheritedTimer = NEW.InheritedTimer,
+        CumulativePathTimer = NEW.CumulativePathTimer,
+        LowerChildAlreadyExists = NEW.LowerChildAlreadyExists,
+        ConfirmedAtBlock = NEW.ConfirmedAtBlock
+    WHERE IdRef = (SELECT Ref FROM Dictionary WHERE Value = OLD.Id);
+END;
+`
+	// Whether the mini-stake locked by a layer zero edge went to the excess stake receiver, as its
+	// edge was created with a rival, so that the total sent there is known after a restart. Stakes
+	// recorded before are assumed to have stayed in the challenge manager.
+	version13 = `
+ALTER TABLE StakeEvents ADD COLUMN SentToExcessReceiver BOOLEAN NOT NULL DEFAULT FALSE;
 `
 	// schemaList is a list of schema versions.
-	schemaList = []string{version1, version2, version3}
+	schemaList = []string{version1, version2, version3, version4, version5, version6, version7, version8, version9, version10, version11, version12, version13}
 )
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// MinHashPrefixLength is the minimum number of hex digits of a hash prefix, below which a search
+// would match too much to be useful.
+const MinHashPrefixLength = 4
+
+// HashPrefix is the leading hex digits of a hash, such as one truncated in logs or by an explorer.
+// It matches the hashes in the range from the prefix followed by zeros to the prefix followed by
+// f's, so that searching by it uses the indexes on the hashes.
+type HashPrefix struct {
+	digits string
+	low    []byte
+	high   []byte
+}
+
+// ParseHashPrefix parses between MinHashPrefixLength and 64 hex digits, with or without a 0x
+// prefix. Truncated hashes may end with an ellipsis, which is ignored.
+func ParseHashPrefix(s string) (HashPrefix, error) {
+	digits := strings.ToLower(strings.TrimSpace(s))
+	digits = strings.TrimPrefix(digits, "0x")
+	digits = strings.TrimRight(digits, ".…")
+	if len(digits) < MinHashPrefixLength || len(digits) > 2*common.HashLength {
+		return HashPrefix{}, fmt.Errorf("hash prefix must have between %d and %d hex digits, got %q", MinHashPrefixLength, 2*common.HashLength, s)
+	}
+	padding := 2*common.HashLength - len(digits)
+	low, err := hexutil.Decode("0x" + digits + strings.Repeat("0", padding))
+	if err != nil {
+		return HashPrefix{}, fmt.Errorf("invalid hash prefix %q: %w", s, err)
+	}
+	high, err := hexutil.Decode("0x" + digits + strings.Repeat("f", padding))
+	if err != nil {
+		return HashPrefix{}, fmt.Errorf("invalid hash prefix %q: %w", s, err)
+	}
+	return HashPrefix{digits: digits, low: low, high: high}, nil
+}
+
+// String is the prefix as 0x-prefixed hex digits.
+func (p HashPrefix) String() string {
+	return "0x" + p.digits
+}
+
+// Matches checks if a hash starts with the prefix.
+func (p HashPrefix) Matches(hash common.Hash) bool {
+	return bytes.Compare(hash[:], p.low) >= 0 && bytes.Compare(hash[:], p.high) <= 0
+}
+
+// The filter of a column by the prefix, with its arguments.
+func (p HashPrefix) filter(column string) (string, []interface{}) {
+	return column + " BETWEEN ? AND ?", []interface{}{p.low, p.high}
+}
+
+// WithAssertionHashPrefix filters assertions by a prefix of their hash.
+func WithAssertionHashPrefix(prefix HashPrefix) AssertionOption {
+	return func(q *AssertionQuery) {
+		filter, args := prefix.filter("a.Hash")
+		q.filters = append(q.filters, filter)
+		q.args = append(q.args, args...)
+	}
+}
+
+// WithTransactionHashPrefix filters assertions by a prefix of the hash of the transaction which
+// created them.
+func WithTransactionHashPrefix(prefix HashPrefix) AssertionOption {
+	return func(q *AssertionQuery) {
+		filter, args := prefix.filter("a.TransactionHash")
+		q.filters = append(q.filters, filter)
+		q.args = append(q.args, args...)
+	}
+}
+
+// WithIdPrefix filters edges by a prefix of their id.
+func WithIdPrefix(prefix HashPrefix) EdgeOption {
+	return func(q *EdgeQuery) {
+		filter, args := prefix.filter("e.Id")
+		q.filters = append(q.filters, filter)
+		q.args = append(q.args, args...)
+	}
+}
+
+// WithMutualIdPrefix filters edges by a prefix of their mutual id, which matches rivals.
+func WithMutualIdPrefix(prefix HashPrefix) EdgeOption {
+	return func(q *EdgeQuery) {
+		filter, args := prefix.filter("e.MutualId")
+		q.filters = append(q.filters, filter)
+		q.args = append(q.args, args...)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package digest periodically summarizes the dispute activity recorded in the API database, such as
+// the assertions posted, the challenges opened and resolved, and the stake spent and refunded, and
+// delivers the summary through the channels operators are alerted on. Operators who do not watch
+// dashboards stay informed, and anomalies such as failed actions or lost challenges stand out.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/api/webhooks"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/util/stopwaiter"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	generatedCounter = metrics.NewRegisteredCounter("arb/validator/digest/generated", nil)
+	failedCounter    = metrics.NewRegisteredCounter("arb/validator/digest/failed", nil)
+)
+
+const (
+	defaultAvgBlockCreationTime = 12 * time.Second
+	// At most this many anomalies are listed in a digest, which still counts all of them.
+	maxListedAnomalies = 50
+)
+
+// AnomalyKind names a kind of activity operators should look into.
+type AnomalyKind string
+
+const (
+	// An operation of the validator failed, as recorded in its audit log.
+	FailedAction AnomalyKind = "failed_action"
+	// A rival's level zero edge was confirmed, so a challenge was resolved against us.
+	ChallengeLost AnomalyKind = "challenge_lost"
+)
+
+// Anomaly is an occurrence during the period of a digest operators should look into.
+type Anomaly struct {
+	Kind      AnomalyKind `json:"kind"`
+	Detail    string      `json:"detail"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// Digest summarizes the dispute activity over a period, which spans the times from From until To
+// and the parent chain blocks from FromBlock until ToBlock, exclusive of To and ToBlock. Amounts
+// are in wei of the stake token.
+type Digest struct {
+	Kind      webhooks.EventKind `json:"kind"`
+	Staker    common.Address     `json:"staker"`
+	From      time.Time          `json:"from"`
+	To        time.Time          `json:"to"`
+	FromBlock uint64             `json:"fromBlock"`
+	ToBlock   uint64             `json:"toBlock"`
+	// Assertions created by anyone, and those posted by the staker.
+	AssertionsCreated uint64 `json:"assertionsCreated"`
+	AssertionsPosted  uint64 `json:"assertionsPosted"`
+	// Edges created by anyone, and those created by the staker.
+	EdgesCreated uint64 `json:"edgesCreated"`
+	EdgesOurs    uint64 `json:"edgesOurs"`
+	// Challenges are identified by the assertion they are on. A challenge is opened by its first
+	// level zero edge, and resolved by the confirmation of one, which is won if the edge is royal.
+	ChallengesOpened   uint64 `json:"challengesOpened"`
+	ChallengesResolved uint64 `json:"challengesResolved"`
+	ChallengesWon      uint64 `json:"challengesWon"`
+	ChallengesLost     uint64 `json:"challengesLost"`
+	// Stake locked and refunded to the staker, in assertions and edges.
+	Spend        string `json:"spend"`
+	Refunds      string `json:"refunds"`
+	NumAnomalies uint64 `json:"numAnomalies"`
+	// The first anomalies of the period, in the order they occurred.
+	Anomalies []*Anomaly `json:"anomalies"`
+}
+
+// Generate summarizes the dispute activity recorded in the database over a period, for a staker.
+func Generate(database db.ReadOnlyDatabase, staker common.Address, from, to time.Time, fromBlock, toBlock uint64) (*Digest, error) {
+	d := &Digest{
+		Kind:      webhooks.Digest,
+		Staker:    staker,
+		From:      from.UTC(),
+		To:        to.UTC(),
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Anomalies: make([]*Anomaly, 0),
+	}
+	assertions, err := database.GetAssertions(db.FromAssertionCreationBlock(fromBlock), db.ToAssertionCreationBlock(toBlock))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get assertions")
+	}
+	d.AssertionsCreated = uint64(len(assertions))
+
+	entries, err := database.GetAuditEntries(db.WithAuditEntriesSince(from), db.WithAuditEntriesUntil(to))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get audit entries")
+	}
+	for _, e := range entries {
+		if e.Principal != staker.Hex() {
+			continue
+		}
+		if e.Error != "" {
+			d.addAnomaly(FailedAction, fmt.Sprintf("%s failed: %s", e.Action, e.Error), e.Timestamp)
+			continue
+		}
+		if e.Action == api.AuditActionPostAssertion {
+			d.AssertionsPosted++
+		}
+	}
+
+	edges, err := database.GetEdges(db.FromEdgeCreationBlock(fromBlock), db.ToEdgeCreationBlock(toBlock))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edges")
+	}
+	d.EdgesCreated = uint64(len(edges))
+	opened := make(map[common.Hash]bool)
+	for _, e := range edges {
+		if e.MiniStaker == staker {
+			d.EdgesOurs++
+		}
+		if e.ChallengeLevel != 0 || opened[e.AssertionHash] {
+			continue
+		}
+		// The challenge was opened during the period if it has no level zero edge from before.
+		earlier, err := database.GetEdges(
+			db.WithEdgeAssertionHash(protocol.AssertionHash{Hash: e.AssertionHash}),
+			db.WithChallengeLevel(protocol.NewBlockChallengeLevel().Uint8()),
+			db.ToEdgeCreationBlock(fromBlock),
+			db.WithLimit(1),
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get earlier edges")
+		}
+		if len(earlier) == 0 {
+			opened[e.AssertionHash] = true
+		}
+	}
+	d.ChallengesOpened = uint64(len(opened))
+
+	confirmed, err := database.GetEdges(
+		db.WithChallengeLevel(protocol.NewBlockChallengeLevel().Uint8()),
+		db.WithEdgeStatus(protocol.EdgeConfirmed),
+		db.FromEdgeConfirmationBlock(fromBlock),
+		db.ToEdgeConfirmationBlock(toBlock),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get confirmed edges")
+	}
+	for _, e := range confirmed {
+		d.ChallengesResolved++
+		if e.IsRoyal {
+			d.ChallengesWon++
+			continue
+		}
+		d.ChallengesLost++
+		d.addAnomaly(ChallengeLost, fmt.Sprintf("rival edge %#x was confirmed in the challenge on assertion %#x at block %d", e.Id, e.AssertionHash, *e.ConfirmedAtBlock), d.blockTime(*e.ConfirmedAtBlock))
+	}
+
+	stakeEvents, err := database.GetStakeEvents(
+		db.WithStakeEventStaker(staker),
+		db.WithStakeEventsSince(from),
+		db.WithStakeEventsUntil(to),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get stake events")
+	}
+	spend, refunds := new(big.Int), new(big.Int)
+	for _, e := range stakeEvents {
+		amount, ok := new(big.Int).SetString(e.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("could not parse stake amount %q", e.Amount)
+		}
+		switch e.Kind {
+		case api.StakeEventLocked:
+			spend.Add(spend, amount)
+		case api.StakeEventRefunded:
+			refunds.Add(refunds, amount)
+		}
+	}
+	d.Spend = spend.String()
+	d.Refunds = refunds.String()
+
+	sort.SliceStable(d.Anomalies, func(i, j int) bool {
+		return d.Anomalies[i].Timestamp.Before(d.Anomalies[j].Timestamp)
+	})
+	d.NumAnomalies = uint64(len(d.Anomalies))
+	if len(d.Anomalies) > maxListedAnomalies {
+		d.Anomalies = d.Anomalies[:maxListedAnomalies]
+	}
+	return d, nil
+}
+
+// Estimates the time of a block of the period, assuming blocks are evenly spread over it, as the
+// database does not record the times of blocks.
+func (d *Digest) blockTime(block uint64) time.Time {
+	if d.ToBlock <= d.FromBlock || block < d.FromBlock {
+		return d.From
+	}
+	elapsed := d.To.Sub(d.From) * time.Duration(block-d.FromBlock) / time.Duration(d.ToBlock-d.FromBlock)
+	return d.From.Add(elapsed)
+}
+
+func (d *Digest) addAnomaly(kind AnomalyKind, detail string, timestamp time.Time) {
+	d.Anomalies = append(d.Anomalies, &Anomaly{Kind: kind, Detail: detail, Timestamp: timestamp.UTC()})
+}
+
+// Channel delivers digests to operators.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, d *Digest) error
+}
+
+// LogChannel logs digests, as a warning if they have anomalies.
+type LogChannel struct{}
+
+func (LogChannel) Name() string {
+	return "log"
+}
+
+func (LogChannel) Deliver(_ context.Context, d *Digest) error {
+	ctx := []any{
+		"from", d.From,
+		"to", d.To,
+		"assertionsCreated", d.AssertionsCreated,
+		"assertionsPosted", d.AssertionsPosted,
+		"edgesCreated", d.EdgesCreated,
+		"edgesOurs", d.EdgesOurs,
+		"challengesOpened", d.ChallengesOpened,
+		"challengesResolved", d.ChallengesResolved,
+		"challengesWon", d.ChallengesWon,
+		"challengesLost", d.ChallengesLost,
+		"spend", d.Spend,
+		"refunds", d.Refunds,
+		"anomalies", d.NumAnomalies,
+	}
+	if d.NumAnomalies == 0 {
+		log.Info("Dispute activity digest", ctx...)
+		return nil
+	}
+	log.Warn("Dispute activity digest has anomalies", ctx...)
+	for _, a := range d.Anomalies {
+		log.Warn("Dispute activity anomaly", "kind", a.Kind, "detail", a.Detail, "timestamp", a.Timestamp)
+	}
+	return nil
+}
+
+// WebhookChannel delivers digests as signed webhooks, of kind digest, to the endpoints of a
+// dispatcher.
+type WebhookChannel struct {
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewWebhookChannel(dispatcher *webhooks.Dispatcher) *WebhookChannel {
+	return &WebhookChannel{dispatcher: dispatcher}
+}
+
+func (c *WebhookChannel) Name() string {
+	return "webhooks"
+}
+
+func (c *WebhookChannel) Deliver(ctx context.Context, d *Digest) error {
+	return c.dispatcher.Send(ctx, webhooks.Digest, d)
+}
+
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Scheduler generates a digest at the end of each period and delivers it to every channel. Periods
+// are aligned to multiples of their length since the Unix epoch, so daily digests cover UTC days.
+type Scheduler struct {
+	stopwaiter.StopWaiter
+	db                   db.ReadOnlyDatabase
+	backend              headerReader
+	staker               common.Address
+	interval             time.Duration
+	channels             []Channel
+	avgBlockCreationTime time.Duration
+	timeRef              func() time.Time
+	lock                 sync.Mutex
+	// The end of the last period a digest was generated for, zero before the first.
+	lastTime  time.Time
+	lastBlock uint64
+}
+
+type Opt func(*Scheduler)
+
+// WithAvgBlockCreationTime sets the average time between blocks of the parent chain, from which the
+// first block of the first period is estimated. Defaults to 12 seconds.
+func WithAvgBlockCreationTime(d time.Duration) Opt {
+	return func(s *Scheduler) {
+		s.avgBlockCreationTime = d
+	}
+}
+
+func NewScheduler(
+	database db.ReadOnlyDatabase,
+	backend headerReader,
+	staker common.Address,
+	interval time.Duration,
+	channels []Channel,
+	opts ...Opt,
+) (*Scheduler, error) {
+	if api.IsNil(database) {
+		return nil, errors.New("digests require an API database")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("digest interval must be positive, got %v", interval)
+	}
+	if len(channels) == 0 {
+		return nil, errors.New("no digest channels given")
+	}
+	s := &Scheduler{
+		db:                   database,
+		backend:              backend,
+		staker:               staker,
+		interval:             interval,
+		channels:             channels,
+		avgBlockCreationTime: defaultAvgBlockCreationTime,
+		timeRef:              time.Now,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	s.StopWaiter.Start(ctx, s)
+	s.LaunchThread(s.run)
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	for {
+		now := s.timeRef()
+		next := now.Truncate(s.interval).Add(s.interval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+		if _, err := s.GenerateAndDeliver(ctx, next); err != nil {
+			failedCounter.Inc(1)
+			log.Error("Could not generate dispute activity digest", "err", err)
+		}
+	}
+}
+
+// GenerateAndDeliver generates the digest of the period ending at a time, which starts at the end
+// of the previous one, and delivers it to every channel. Failing to deliver to a channel does not
+// prevent delivery to the others.
+func (s *Scheduler) GenerateAndDeliver(ctx context.Context, end time.Time) (*Digest, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	header, err := s.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get latest header")
+	}
+	// Blocks created at the end of the period belong to the next one.
+	toBlock := header.Number.Uint64() + 1
+	from, fromBlock := s.lastTime, s.lastBlock
+	if from.IsZero() {
+		from = end.Add(-s.interval)
+		periodBlocks := uint64(s.interval / s.avgBlockCreationTime)
+		if toBlock > periodBlocks {
+			fromBlock = toBlock - periodBlocks
+		}
+	}
+	d, err := Generate(s.db, s.staker, from, end, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	s.lastTime, s.lastBlock = end, toBlock
+	generatedCounter.Inc(1)
+	for _, c := range s.channels {
+		if err := c.Deliver(ctx, d); err != nil {
+			failedCounter.Inc(1)
+			log.Error("Could not deliver dispute activity digest", "channel", c.Name(), "err", err)
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/api/webhooks"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	staker = common.BytesToAddress([]byte("staker"))
+	rival  = common.BytesToAddress([]byte("rival"))
+)
+
+func edge(id string, assertion common.Hash, level uint8, createdAt uint64, miniStaker common.Address) *api.JsonEdge {
+	return &api.JsonEdge{
+		Id:             common.BytesToHash([]byte(id)),
+		ChallengeLevel: level,
+		AssertionHash:  assertion,
+		MiniStaker:     miniStaker,
+		CreatedAtBlock: createdAt,
+		Status:         "pending",
+	}
+}
+
+func setupDatabase(t *testing.T, from time.Time) *db.SqliteDatabase {
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "bold.db"))
+	require.NoError(t, err)
+
+	for i, block := range []uint64{90, 110, 120} {
+		require.NoError(t, database.InsertAssertion(&api.JsonAssertion{
+			Hash:          common.BytesToHash([]byte{byte(i + 1)}),
+			CreationBlock: block,
+			Status:        "pending",
+		}))
+	}
+	old := common.BytesToHash([]byte("old challenge"))
+	opened := common.BytesToHash([]byte("new challenge"))
+	require.NoError(t, database.InsertEdges([]*api.JsonEdge{
+		// A challenge opened before the period, with a rival edge during it.
+		edge("old", old, 0, 95, staker),
+		edge("old rival", old, 0, 105, rival),
+		// A challenge opened during the period.
+		edge("new", opened, 0, 110, rival),
+		edge("new ours", opened, 0, 111, staker),
+		edge("subchallenge", opened, 1, 115, staker),
+		// After the period.
+		edge("later", opened, 0, 200, staker),
+	}))
+	won := edge("new ours", opened, 0, 111, staker)
+	won.IsRoyal = true
+	require.NoError(t, database.UpdateEdges([]*api.JsonEdge{won}))
+	require.NoError(t, database.UpdateEdgeConfirmation(won.Id, 130))
+	require.NoError(t, database.UpdateEdgeConfirmation(common.BytesToHash([]byte("old rival")), 140))
+	// Confirmed before the period.
+	require.NoError(t, database.UpdateEdgeConfirmation(common.BytesToHash([]byte("old")), 99))
+
+	stakes := []*api.JsonStakeEvent{
+		{Staker: staker, Kind: api.StakeEventLocked, Amount: "100", Timestamp: from.Add(-time.Minute)},
+		{Staker: staker, Kind: api.StakeEventLocked, Amount: "30", Timestamp: from.Add(time.Minute)},
+		{Staker: staker, Kind: api.StakeEventLocked, Amount: "12", Timestamp: from.Add(2 * time.Minute)},
+		{Staker: staker, Kind: api.StakeEventRefunded, Amount: "100", Timestamp: from.Add(3 * time.Minute)},
+		{Staker: rival, Kind: api.StakeEventLocked, Amount: "1000", Timestamp: from.Add(time.Minute)},
+	}
+	for i, e := range stakes {
+		e.Source = api.StakeSourceEdge
+		e.TransactionHash = common.BytesToHash([]byte{byte(i)})
+		_, err := database.InsertStakeEvent(e)
+		require.NoError(t, err)
+	}
+
+	audit := db.NewAuditLogger(database, staker.Hex())
+	audit.Record(api.AuditActionPostAssertion, nil, nil, nil)
+	audit.Record(api.AuditActionBisectEdge, nil, nil, errors.New("execution reverted"))
+	db.NewAuditLogger(database, rival.Hex()).Record(api.AuditActionPostAssertion, nil, nil, nil)
+	return database
+}
+
+func TestGenerate(t *testing.T) {
+	// Timestamps written by the database have a resolution of a second.
+	from := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	to := from.Add(2 * time.Hour)
+	database := setupDatabase(t, from)
+
+	d, err := Generate(database, staker, from, to, 100, 150)
+	require.NoError(t, err)
+	require.Equal(t, webhooks.Digest, d.Kind)
+	require.Equal(t, uint64(2), d.AssertionsCreated)
+	require.Equal(t, uint64(1), d.AssertionsPosted)
+	require.Equal(t, uint64(4), d.EdgesCreated)
+	require.Equal(t, uint64(2), d.EdgesOurs)
+	require.Equal(t, uint64(1), d.ChallengesOpened)
+	require.Equal(t, uint64(2), d.ChallengesResolved)
+	require.Equal(t, uint64(1), d.ChallengesWon)
+	require.Equal(t, uint64(1), d.ChallengesLost)
+	require.Equal(t, "42", d.Spend)
+	require.Equal(t, "100", d.Refunds)
+	require.Equal(t, uint64(2), d.NumAnomalies)
+	kinds := []AnomalyKind{d.Anomalies[0].Kind, d.Anomalies[1].Kind}
+	require.ElementsMatch(t, []AnomalyKind{FailedAction, ChallengeLost}, kinds)
+	require.False(t, d.Anomalies[1].Timestamp.Before(d.Anomalies[0].Timestamp))
+	for _, a := range d.Anomalies {
+		if a.Kind == ChallengeLost {
+			// Block 140 is four fifths into the period.
+			require.Equal(t, from.Add(96*time.Minute), a.Timestamp)
+		}
+	}
+
+	// Refreshing edges rewrites their rows, which keep the blocks they were confirmed at.
+	edges, err := database.GetEdges()
+	require.NoError(t, err)
+	for _, e := range edges {
+		e.ConfirmedAtBlock = nil
+	}
+	require.NoError(t, database.UpdateEdges(edges))
+
+	// Nothing happened in a later period.
+	d, err = Generate(database, staker, to, to.Add(time.Hour), 300, 400)
+	require.NoError(t, err)
+	require.Equal(t, &Digest{
+		Kind:      webhooks.Digest,
+		Staker:    staker,
+		From:      to,
+		To:        to.Add(time.Hour),
+		FromBlock: 300,
+		ToBlock:   400,
+		Spend:     "0",
+		Refunds:   "0",
+		Anomalies: []*Anomaly{},
+	}, d)
+}
+
+type recordingChannel struct {
+	digests []*Digest
+	err     error
+}
+
+func (c *recordingChannel) Name() string {
+	return "recording"
+}
+
+func (c *recordingChannel) Deliver(_ context.Context, d *Digest) error {
+	c.digests = append(c.digests, d)
+	return c.err
+}
+
+type headers struct {
+	number uint64
+}
+
+func (h *headers) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(h.number)}, nil
+}
+
+func TestScheduler_GenerateAndDeliver(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "bold.db"))
+	require.NoError(t, err)
+	_, err = NewScheduler(database, &headers{}, staker, 0, []Channel{LogChannel{}})
+	require.ErrorContains(t, err, "must be positive")
+	_, err = NewScheduler(nil, &headers{}, staker, time.Hour, []Channel{LogChannel{}})
+	require.ErrorContains(t, err, "require an API database")
+
+	failing := &recordingChannel{err: errors.New("unreachable")}
+	recording := &recordingChannel{}
+	backend := &headers{number: 1000}
+	s, err := NewScheduler(database, backend, staker, time.Hour, []Channel{failing, recording, LogChannel{}}, WithAvgBlockCreationTime(time.Minute))
+	require.NoError(t, err)
+
+	end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	first, err := s.GenerateAndDeliver(ctx, end)
+	require.NoError(t, err)
+	// The first period is estimated to have started an hour of blocks ago.
+	require.Equal(t, end.Add(-time.Hour), first.From)
+	require.Equal(t, uint64(941), first.FromBlock)
+	require.Equal(t, uint64(1001), first.ToBlock)
+
+	backend.number = 1050
+	second, err := s.GenerateAndDeliver(ctx, end.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, end, second.From)
+	require.Equal(t, uint64(1001), second.FromBlock)
+	require.Equal(t, uint64(1051), second.ToBlock)
+
+	// A channel failing does not prevent delivery to the others.
+	require.Equal(t, []*Digest{first, second}, failing.digests)
+	require.Equal(t, []*Digest{first, second}, recording.digests)
+}
+
+func TestWebhookChannel(t *testing.T) {
+	received := make(chan []byte, 1)
+	var signer common.Address
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		signature, err := hexutil.Decode(r.Header.Get(webhooks.SignatureHeader))
+		require.NoError(t, err)
+		signer, err = webhooks.RecoverSigner(body, signature)
+		require.NoError(t, err)
+		received <- body
+	}))
+	defer srv.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	dispatcher, err := webhooks.New([]string{srv.URL}, webhooks.NewKeySigner(key), nil)
+	require.NoError(t, err)
+	d := &Digest{Kind: webhooks.Digest, Staker: staker, Spend: "1", Refunds: "2", Anomalies: []*Anomaly{}}
+	require.NoError(t, NewWebhookChannel(dispatcher).Deliver(context.Background(), d))
+
+	body := <-received
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+	got := &Digest{}
+	require.NoError(t, json.Unmarshal(body, got))
+	require.Equal(t, d, got)
+
+	srv.Close()
+	dispatcher, err = webhooks.New([]string{srv.URL}, webhooks.NewKeySigner(key), nil, webhooks.WithRetries(1, 0))
+	require.NoError(t, err)
+	require.ErrorContains(t, NewWebhookChannel(dispatcher).Deliver(context.Background(), d), "could not deliver digest webhook")
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/backend"
+	challengemanager "github.com/OffchainLabs/bold/challenge-manager"
+)
+
+// WithChallengeManagerAPI serves the API of a challenge manager on the given address. The challenge
+// manager must have a database, set with challengemanager.WithAPIDatabase or
+// challengemanager.WithDatabase. Does nothing if the address is empty, so it may be given the
+// API address of a config as is.
+func WithChallengeManagerAPI(addr string) challengemanager.Opt {
+	if addr == "" {
+		return func(*challengemanager.Manager) {}
+	}
+	return challengemanager.WithService(func(m *challengemanager.Manager) (challengemanager.Service, error) {
+		if api.IsNil(m.Database()) {
+			return nil, errors.New("the API requires the challenge manager to have a database")
+		}
+		return New(addr, backend.NewBackend(m.Database(), m.Chain(), m.Watcher(), m))
+	})
+}
@@ -1,16 +1,25 @@
 package server
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/backend"
 	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/api/stateexport"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
+	"github.com/OffchainLabs/bold/containers/option"
 	"github.com/OffchainLabs/bold/state-commitments/history"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -20,6 +29,9 @@ import (
 
 var contentType = "application/json"
 
+// About an hour of blocks on Ethereum.
+const defaultRequiredActionsWithinBlocks uint64 = 300
+
 // Healthz checks if the API server is ready to serve queries. Returns 200 if it is ready.
 //
 // method:
@@ -245,7 +257,7 @@ func (s *Server) AllChallengeEdges(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if val, ok := query["status"]; ok && len(val) > 0 {
-		status, err2 := parseEdgeStatus(strings.Join(val, ""))
+		status, err2 := protocol.EdgeStatusFromString(strings.Join(val, ""))
 		if err2 != nil {
 			http.Error(w, fmt.Sprintf("Could not parse status: %v", err2), http.StatusBadRequest)
 			return
@@ -386,16 +398,6 @@ func (s *Server) AllChallengeEdges(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, edges)
 }
 
-func parseEdgeStatus(str string) (protocol.EdgeStatus, error) {
-	s := strings.TrimSpace(strings.ToLower(str))
-	if s == "pending" {
-		return protocol.EdgePending, nil
-	} else if s == "confirmed" {
-		return protocol.EdgeConfirmed, nil
-	}
-	return protocol.EdgePending, errors.New("unknown edge status, expected pending or confirmed")
-}
-
 // EdgeByIdentifier fetches an edge by its specific id in a challenge.
 //
 // method:
@@ -407,7 +409,8 @@ func parseEdgeStatus(str string) (protocol.EdgeStatus, error) {
 // - 0x-prefixed edge id
 //
 // query params:
-// - force_update: refetch the updatable fields of each item in the response
+//   - force_update: refetch the updatable fields of each item in the response. For edges tracked
+//     by the validator, this also reports the conditions blocking the edge from being confirmed
 //
 // response:
 // - *JsonEdge
@@ -465,6 +468,42 @@ func (s *Server) RoyalTrackedChallengeEdges(w http.ResponseWriter, r *http.Reque
 	writeJSONResponse(w, resp)
 }
 
+// TrackerDecision reconstructs what the tracker of a locally tracked edge decided, or would have,
+// at a block and why, by re-running its decision logic against the state of the chain at the block.
+//
+// method:
+// - GET
+// - /api/v1/tracked/edges/<edge-id>/decision
+//
+// request query params:
+//   - block: the block number to reconstruct the decision at. Defaults to the latest block
+//
+// response:
+// - *JsonTrackerDecision
+func (s *Server) TrackerDecision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := hexutil.Decode(vars["edge-id"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse edge id: %v", err), http.StatusBadRequest)
+		return
+	}
+	blockNumber := option.None[uint64]()
+	if val, ok := r.URL.Query()["block"]; ok && len(val) > 0 {
+		v, err2 := strconv.ParseUint(val[0], 10, 64)
+		if err2 != nil {
+			http.Error(w, fmt.Sprintf("Could not parse block number: %v", err2), http.StatusBadRequest)
+			return
+		}
+		blockNumber = option.Some(v)
+	}
+	decision, err := s.backend.GetTrackerDecision(r.Context(), protocol.EdgeId{Hash: common.BytesToHash(id)}, blockNumber)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not reconstruct tracker decision: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, decision)
+}
+
 // EdgeByHistoryCommitment fetches an edge by its specific history commitment in a challenge.
 //
 // method:
@@ -600,6 +639,709 @@ func (s *Server) MiniStakes(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, miniStakes)
 }
 
+// ChallengeExplanation narrates the state of a challenge from the point of view of the validator,
+// such as where it disagrees with its rivals and whose timer leads.
+//
+// method:
+// - GET
+// - /api/v1/challenge/<assertion-hash>/explain
+//
+// identifier options:
+//   - 0x-prefixed assertion hash
+//
+// response:
+// - *JsonChallengeExplanation
+func (s *Server) ChallengeExplanation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hash, err := hexutil.Decode(vars["assertion-hash"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse assertion hash: %v", err), http.StatusBadRequest)
+		return
+	}
+	explanation, err := s.backend.GetChallengeExplanation(r.Context(), protocol.AssertionHash{Hash: common.BytesToHash(hash)})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not explain challenge: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, explanation)
+}
+
+// ChallengeStateExport exports the complete state of a challenge as seen by the validator, in a
+// client independent format other BOLD clients can import to verify they reach the same royal
+// edges and pending actions.
+//
+// method:
+// - GET
+// - /api/v1/challenge/<assertion-hash>/export
+//
+// identifier options:
+//   - 0x-prefixed assertion hash
+//
+// response:
+// - *stateexport.ChallengeState
+func (s *Server) ChallengeStateExport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hash, err := hexutil.Decode(vars["assertion-hash"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse assertion hash: %v", err), http.StatusBadRequest)
+		return
+	}
+	state, err := s.backend.ExportChallengeState(r.Context(), protocol.AssertionHash{Hash: common.BytesToHash(hash)})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not export challenge state: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, state)
+}
+
+// ChallengeStateExportSchema returns the JSON schema of exported challenge states.
+//
+// method:
+// - GET
+// - /api/v1/challenge/export/schema
+//
+// response:
+// - JSON schema
+func (s *Server) ChallengeStateExportSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(stateexport.Schema()); err != nil {
+		log.Error("could not write response body", "err", err, "status", http.StatusInternalServerError)
+	}
+}
+
+// StakeEvents lists changes to the amount of tokens locked in assertion stakes and edge mini-stakes.
+//
+// method:
+// - GET
+// - /api/v1/stakes/events
+//
+// request query params:
+//   - limit: the max number of items in the response
+//   - offset: the offset index in the DB
+//   - staker: only include events for a staker address
+//   - source: only include events of a source, either "assertion" or "edge"
+//   - since: only include events at or after a unix timestamp
+//   - until: only include events before a unix timestamp
+//   - format: "csv" to download the response as a CSV file instead of JSON
+//
+// response:
+// - []*JsonStakeEvent
+func (s *Server) StakeEvents(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseStakeEventOptions(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse stake event filters: %v", err), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	if val, ok := query["limit"]; ok && len(val) > 0 {
+		if v, err2 := strconv.Atoi(val[0]); err2 == nil {
+			opts = append(opts, db.WithStakeEventLimit(v))
+		}
+	}
+	if val, ok := query["offset"]; ok && len(val) > 0 {
+		if v, err2 := strconv.Atoi(val[0]); err2 == nil {
+			opts = append(opts, db.WithStakeEventOffset(v))
+		}
+	}
+	events, err := s.backend.GetStakeEvents(r.Context(), opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get stake events from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if query.Get("format") == "csv" {
+		rows := make([][]string, len(events))
+		for i, e := range events {
+			rows[i] = []string{
+				e.Timestamp.UTC().Format(time.RFC3339),
+				strconv.FormatUint(e.BlockNumber, 10),
+				e.TransactionHash.Hex(),
+				strconv.FormatUint(uint64(e.LogIndex), 10),
+				e.Staker.Hex(),
+				e.Kind,
+				e.Source,
+				e.EdgeId.Hex(),
+				e.Amount,
+			}
+		}
+		writeCSVResponse(w, "stake-events.csv", []string{
+			"timestamp", "blockNumber", "transactionHash", "logIndex", "staker", "kind", "source", "edgeId", "amount",
+		}, rows)
+		return
+	}
+	writeJSONResponse(w, events)
+}
+
+// StakeExposure aggregates the tokens locked in and refunded from stakes per day or week,
+// along with the high-water mark of tokens locked at any point during each period.
+//
+// method:
+// - GET
+// - /api/v1/stakes/exposure
+//
+// request query params:
+//   - interval: the period to aggregate over, either "day" (default) or "week"
+//   - staker: only include events for a staker address
+//   - source: only include events of a source, either "assertion" or "edge"
+//   - since: only include events at or after a unix timestamp
+//   - until: only include events before a unix timestamp
+//   - format: "csv" to download the response as a CSV file instead of JSON
+//
+// response:
+// - []*JsonStakeExposure
+func (s *Server) StakeExposure(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseStakeEventOptions(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse stake event filters: %v", err), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	interval := backend.StakeExposureDaily
+	if val := query.Get("interval"); val != "" {
+		interval = backend.StakeExposureInterval(val)
+	}
+	if interval != backend.StakeExposureDaily && interval != backend.StakeExposureWeekly {
+		http.Error(w, fmt.Sprintf("Unknown interval %q", interval), http.StatusBadRequest)
+		return
+	}
+	exposure, err := s.backend.GetStakeExposure(r.Context(), interval, opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get stake exposure from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if query.Get("format") == "csv" {
+		rows := make([][]string, len(exposure))
+		for i, e := range exposure {
+			rows[i] = []string{
+				e.PeriodStart.UTC().Format(time.RFC3339),
+				e.Locked,
+				e.Refunded,
+				e.HighWaterMark,
+				e.EndExposure,
+			}
+		}
+		writeCSVResponse(w, "stake-exposure.csv", []string{
+			"periodStart", "locked", "refunded", "highWaterMark", "endExposure",
+		}, rows)
+		return
+	}
+	writeJSONResponse(w, exposure)
+}
+
+// ValidatorAllowlist lists the addresses the rollup allows to post and confirm assertions and
+// create layer zero edges, and whether this validator's address is one of them.
+//
+// method:
+// - GET
+// - /api/v1/validators/allowlist
+//
+// response:
+// - *JsonValidatorAllowlist
+func (s *Server) ValidatorAllowlist(w http.ResponseWriter, r *http.Request) {
+	allowlist, err := s.backend.GetValidatorAllowlist(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get validator allowlist from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, allowlist)
+}
+
+// ProtocolInfo returns the protocol constants of the challenge manager, such as the number of
+// big step levels, the layer zero heights and the stake amounts of each challenge level.
+//
+// method:
+// - GET
+// - /api/v1/protocol/info
+//
+// response:
+// - *JsonProtocolInfo
+func (s *Server) ProtocolInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := s.backend.GetProtocolInfo(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get protocol info from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, info)
+}
+
+// AuditEntries exports the hash-chained audit log of operations performed by the validator.
+//
+// method:
+// - GET
+// - /api/v1/audit/entries
+//
+// request query params:
+//   - limit: the max number of items in the response
+//   - from_seq: only include entries with a sequence number at or after this one
+//   - action: only include entries for an action, such as "bisect_edge"
+//
+// response:
+// - []*JsonAuditEntry
+func (s *Server) AuditEntries(w http.ResponseWriter, r *http.Request) {
+	opts := make([]db.AuditEntryOption, 0)
+	query := r.URL.Query()
+	if val, ok := query["limit"]; ok && len(val) > 0 {
+		if v, err := strconv.Atoi(val[0]); err == nil {
+			opts = append(opts, db.WithAuditEntryLimit(v))
+		}
+	}
+	if val := query.Get("from_seq"); val != "" {
+		v, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not parse from_seq: %v", err), http.StatusBadRequest)
+			return
+		}
+		opts = append(opts, db.WithAuditEntriesFromSeq(v))
+	}
+	if val := query.Get("action"); val != "" {
+		opts = append(opts, db.WithAuditEntryAction(val))
+	}
+	entries, err := s.backend.GetAuditEntries(r.Context(), opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get audit entries from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, entries)
+}
+
+// VerifyAuditLog checks the chain of hashes of the audit log, reporting the first entry that
+// breaks it, if any, and the hash of the latest entry.
+//
+// method:
+// - GET
+// - /api/v1/audit/verify
+//
+// response:
+// - *JsonAuditVerification
+func (s *Server) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	verification, err := s.backend.VerifyAuditLog(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not verify audit log: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, verification)
+}
+
+// DisputeStatsFeed is a public feed of statistics on the challenges on each challenged assertion,
+// such as how long they took to resolve and how much attacker stake was forfeited, for ecosystem
+// dashboards reporting on how disputes play out. It may be requested from any origin.
+//
+// method:
+// - GET
+// - /api/v1/feed/dispute-stats
+//
+// request query params:
+//   - limit: the max number of assertions in the response
+//   - offset: the offset index in the DB
+//   - from_block_number: only include assertions created at or after a block number
+//   - to_block_number: only include assertions created before a block number
+//
+// response:
+// - *JsonDisputeStatsFeed
+func (s *Server) DisputeStatsFeed(w http.ResponseWriter, r *http.Request) {
+	opts := make([]db.AssertionOption, 0)
+	query := r.URL.Query()
+	if val, ok := query["limit"]; ok && len(val) > 0 {
+		if v, err := strconv.Atoi(val[0]); err == nil {
+			opts = append(opts, db.WithAssertionLimit(v))
+		}
+	}
+	if val, ok := query["offset"]; ok && len(val) > 0 {
+		if v, err := strconv.Atoi(val[0]); err == nil {
+			opts = append(opts, db.WithAssertionOffset(v))
+		}
+	}
+	if val, ok := query["from_block_number"]; ok && len(val) > 0 {
+		if v, err := strconv.ParseUint(val[0], 10, 64); err == nil {
+			opts = append(opts, db.FromAssertionCreationBlock(v))
+		}
+	}
+	if val, ok := query["to_block_number"]; ok && len(val) > 0 {
+		if v, err := strconv.ParseUint(val[0], 10, 64); err == nil {
+			opts = append(opts, db.ToAssertionCreationBlock(v))
+		}
+	}
+	feed, err := s.backend.GetDisputeStats(r.Context(), opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get dispute stats from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	writeJSONResponse(w, feed)
+}
+
+// RequiredActions is the work queue of an operator, listing only the pending assertions and edges
+// the validator has to act on now or soon, most urgent first, with the deadline of each action.
+//
+// method:
+// - GET
+// - /api/v1/actions/required
+//
+// request query params:
+//   - within_blocks: also include the actions which become available within this many blocks,
+//     such as confirmations. Defaults to 300
+//
+// response:
+// - *JsonRequiredActions
+func (s *Server) RequiredActions(w http.ResponseWriter, r *http.Request) {
+	withinBlocks := defaultRequiredActionsWithinBlocks
+	if val := r.URL.Query().Get("within_blocks"); val != "" {
+		v, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not parse within_blocks: %v", err), http.StatusBadRequest)
+			return
+		}
+		withinBlocks = v
+	}
+	actions, err := s.backend.GetRequiredActions(r.Context(), withinBlocks)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get required actions from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, actions)
+}
+
+// StuckTxs lists the transactions which stayed unconfirmed for too long and were escalated for
+// manual intervention, with the intent decoded from their calldata and suggested fixes. The
+// validator does not send a stuck transaction again until it is resolved.
+//
+// method:
+// - GET
+// - /api/v1/txs/stuck
+//
+// response:
+// - []*JsonStuckTx
+func (s *Server) StuckTxs(w http.ResponseWriter, r *http.Request) {
+	txs, err := s.backend.GetStuckTxs(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get stuck transactions from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, txs)
+}
+
+// ResolveStuckTx removes a stuck transaction from the manual intervention queue once an operator
+// has unblocked it, such as by replacing it with a higher fee, so that the validator may send it
+// again.
+//
+// method:
+// - POST
+// - /api/v1/txs/stuck/<tx-hash>/resolve
+func (s *Server) ResolveStuckTx(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	txHash, err := hexutil.Decode(vars["tx-hash"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse transaction hash: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err = s.backend.ResolveStuckTx(r.Context(), common.BytesToHash(txHash)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, solimpl.ErrNotFound) || errors.Is(err, backend.ErrNoStuckTxQueue) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, fmt.Sprintf("Could not resolve stuck transaction: %v", err), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Poison describes whether posting is frozen since an edge the validator agreed with lost by one
+// step proof, which shows its state provider to be faulty, and the incident which froze it.
+//
+// method:
+// - GET
+// - /api/v1/poison
+//
+// response:
+// - *JsonPoison
+func (s *Server) Poison(w http.ResponseWriter, r *http.Request) {
+	p, err := s.backend.GetPoison(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get poisoned flag from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, p)
+}
+
+// ClearPoison clears the poisoned flag once an operator has fixed the state provider, so that the
+// validator resumes posting.
+//
+// method:
+// - POST
+// - /api/v1/poison/clear
+func (s *Server) ClearPoison(w http.ResponseWriter, r *http.Request) {
+	if err := s.backend.ClearPoison(r.Context()); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, backend.ErrNoPoisonFlag) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, fmt.Sprintf("Could not clear poisoned flag: %v", err), status)
+		return
+	}
+	log.Warn("Poisoned flag cleared through the API")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WithdrawEdge withdraws a layer zero edge staked by the validator to recover its stake. Only
+// pending edges whose challenge claims a confirmed assertion may be withdrawn, and only if the
+// challenge manager contract supports it, as shown by the protocol info.
+//
+// method:
+// - POST
+// - /api/v1/edges/<edge-id>/withdraw
+//
+// response:
+// - *JsonEdgeWithdrawal
+func (s *Server) WithdrawEdge(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := hexutil.Decode(vars["edge-id"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse edge id: %v", err), http.StatusBadRequest)
+		return
+	}
+	withdrawal, err := s.backend.WithdrawEdge(r.Context(), protocol.EdgeId{Hash: common.BytesToHash(id)})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, solimpl.ErrNotFound) || errors.Is(err, backend.ErrNoEdgeWithdrawal):
+			status = http.StatusNotFound
+		case errors.Is(err, edgetracker.ErrWithdrawalUnsupported):
+			status = http.StatusNotImplemented
+		case errors.Is(err, edgetracker.ErrWithdrawalNotPermitted) || errors.Is(err, hooks.ErrVetoed) || errors.Is(err, hooks.ErrDelayed):
+			status = http.StatusConflict
+		}
+		http.Error(w, fmt.Sprintf("Could not withdraw edge: %v", err), status)
+		return
+	}
+	log.Warn("Edge withdrawn through the API", "edgeId", withdrawal.EdgeId, "txHash", withdrawal.TransactionHash)
+	writeJSONResponse(w, withdrawal)
+}
+
+// ConfirmationMethods lists the confirmation methods the validator uses and those an operator
+// disabled, such as during the disclosure of a contract bug in one of them.
+//
+// method:
+// - GET
+// - /api/v1/confirmation/methods
+//
+// response:
+// - *JsonConfirmationMethods
+func (s *Server) ConfirmationMethods(w http.ResponseWriter, r *http.Request) {
+	methods, err := s.backend.GetConfirmationMethods(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get confirmation methods from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, methods)
+}
+
+// EnableConfirmationMethod enables a confirmation method an operator disabled.
+//
+// method:
+// - POST
+// - /api/v1/confirmation/methods/<method>/enable
+//
+// response:
+// - *JsonConfirmationMethods
+func (s *Server) EnableConfirmationMethod(w http.ResponseWriter, r *http.Request) {
+	s.setConfirmationMethodEnabled(w, r, true)
+}
+
+// DisableConfirmationMethod stops the validator from using a confirmation method, among claim and
+// children. Edge trackers route around it until it is enabled again. Confirming by time and by one
+// step proof cannot be disabled.
+//
+// method:
+// - POST
+// - /api/v1/confirmation/methods/<method>/disable
+//
+// response:
+// - *JsonConfirmationMethods
+func (s *Server) DisableConfirmationMethod(w http.ResponseWriter, r *http.Request) {
+	s.setConfirmationMethodEnabled(w, r, false)
+}
+
+func (s *Server) setConfirmationMethodEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	vars := mux.Vars(r)
+	methods, err := s.backend.SetConfirmationMethodEnabled(r.Context(), vars["method"], enabled)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, backend.ErrNoConfirmationMethods) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, fmt.Sprintf("Could not set confirmation method: %v", err), status)
+		return
+	}
+	log.Warn("Confirmation method toggled through the API", "method", vars["method"], "enabled", enabled)
+	writeJSONResponse(w, methods)
+}
+
+// LoadShedding describes whether the chain watcher is shedding the tracking of edges which cannot
+// threaten the royal path under peak load, and lists the edges it shed and has not tracked yet.
+//
+// method:
+// - GET
+// - /api/v1/watcher/load-shedding
+//
+// response:
+// - *JsonLoadShedding
+func (s *Server) LoadShedding(w http.ResponseWriter, r *http.Request) {
+	shedding, err := s.backend.GetLoadShedding(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get load shedding from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, shedding)
+}
+
+// LatencyBudget describes how long our counter-moves to rivals took at each challenge level, from
+// the block at which a rival moved against our edge to the block our move was included in, and
+// whether recent latencies put the budget, a fraction of the challenge period, at risk. Includes the
+// safety margin kept before deadlines, if configured, and whether it is tightened.
+//
+// method:
+// - GET
+// - /api/v1/latency/counter-moves
+//
+// response:
+// - *JsonLatencyBudget
+func (s *Server) LatencyBudget(w http.ResponseWriter, r *http.Request) {
+	budget, err := s.backend.GetLatencyBudget(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not get latency budget from backend: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, budget)
+}
+
+// AnalyticsQuery runs an ad-hoc SELECT statement against the database, for analysts asking
+// questions about dispute behavior. The connection it runs on cannot modify the database, and
+// queries time out. Besides the tables, the edges_enriched, challenge_timelines, and stake_flows
+// views may be queried.
+//
+// method:
+// - GET
+// - /api/v1/analytics/query
+//
+// request query params:
+//   - sql: a single SELECT statement, which may start with common table expressions
+//   - limit: the max number of rows in the response, 1000 by default and at most 10000
+//
+// response:
+// - *JsonAnalyticsQueryResult
+func (s *Server) AnalyticsQuery(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 0
+	if val := query.Get("limit"); val != "" {
+		v, err := strconv.Atoi(val)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not parse limit: %v", err), http.StatusBadRequest)
+			return
+		}
+		limit = v
+	}
+	result, err := s.backend.QueryAnalytics(r.Context(), query.Get("sql"), limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrInvalidAnalyticsQuery) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("Could not run analytics query: %v", err), status)
+		return
+	}
+	writeJSONResponse(w, result)
+}
+
+// SearchByHashPrefix finds the assertions and edges with a hash starting with a prefix, as
+// operators investigating a challenge often only have hashes truncated in logs or by explorers.
+//
+// method:
+// - GET
+// - /api/v1/search
+//
+// request query params:
+//   - hash: at least 4 leading hex digits of an assertion hash, assertion creation transaction
+//     hash, edge id, or edge mutual id, optionally 0x-prefixed and ending with an ellipsis
+//   - limit: the max number of items of each kind in the response, 20 by default and at most 100
+//
+// response:
+// - *JsonHashSearchResults
+func (s *Server) SearchByHashPrefix(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix, err := db.ParseHashPrefix(query.Get("hash"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not parse hash prefix: %v", err), http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if val := query.Get("limit"); val != "" {
+		v, err := strconv.Atoi(val)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not parse limit: %v", err), http.StatusBadRequest)
+			return
+		}
+		limit = v
+	}
+	results, err := s.backend.SearchByHashPrefix(r.Context(), prefix, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not search by hash prefix: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, results)
+}
+
+func parseStakeEventOptions(r *http.Request) ([]db.StakeEventOption, error) {
+	opts := make([]db.StakeEventOption, 0)
+	query := r.URL.Query()
+	if val := query.Get("staker"); val != "" {
+		if !common.IsHexAddress(val) {
+			return nil, fmt.Errorf("could not parse staker address %q", val)
+		}
+		opts = append(opts, db.WithStakeEventStaker(common.HexToAddress(val)))
+	}
+	if val := query.Get("source"); val != "" {
+		if val != api.StakeSourceAssertion && val != api.StakeSourceEdge {
+			return nil, fmt.Errorf("unknown stake source %q", val)
+		}
+		opts = append(opts, db.WithStakeEventSource(val))
+	}
+	if val := query.Get("since"); val != "" {
+		v, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse since timestamp: %v", err)
+		}
+		opts = append(opts, db.WithStakeEventsSince(time.Unix(v, 0)))
+	}
+	if val := query.Get("until"); val != "" {
+		v, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse until timestamp: %v", err)
+		}
+		opts = append(opts, db.WithStakeEventsUntil(time.Unix(v, 0)))
+	}
+	return opts, nil
+}
+
+func writeCSVResponse(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		http.Error(w, fmt.Sprintf("Could not write response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		http.Error(w, fmt.Sprintf("Could not write response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error("could not write response body", "err", err, "status", http.StatusInternalServerError)
+		return
+	}
+}
+
 func writeJSONResponse(w http.ResponseWriter, data any) {
 	body, err := json.Marshal(data)
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/OffchainLabs/bold/api/backend"
 	"github.com/OffchainLabs/bold/util/stopwaiter"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/gorilla/mux"
 )
 
@@ -48,13 +49,25 @@ func New(addr string, backend backend.BusinessLogicProvider) (*Server, error) {
 
 func (s *Server) Start(ctx context.Context) error {
 	s.StopWaiter.Start(ctx, s)
-	return s.srv.ListenAndServe()
+	if err := s.srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 func (s *Server) Stop(ctx context.Context) error {
 	return s.srv.Shutdown(ctx)
 }
 
+// StopAndWait shuts down the HTTP server, so that it no longer listens once the challenge manager
+// it serves is stopped.
+func (s *Server) StopAndWait() {
+	if err := s.Stop(context.Background()); err != nil {
+		log.Error("Could not shut down API server", "err", err)
+	}
+	s.StopWaiter.StopAndWait()
+}
+
 func (s *Server) registerMethods() error {
 	if s.registered {
 		return errors.New("API server methods already registered")
@@ -68,8 +81,32 @@ func (s *Server) registerMethods() error {
 	r.HandleFunc("/challenge/{assertion-hash}/edges/id/{edge-id}", s.EdgeByIdentifier).Methods("GET")
 	r.HandleFunc("/challenge/{assertion-hash}/edges/history/{history-commitment}", s.EdgeByHistoryCommitment).Methods("GET")
 	r.HandleFunc("/challenge/{assertion-hash}/ministakes", s.MiniStakes).Methods("GET")
+	r.HandleFunc("/challenge/{assertion-hash}/explain", s.ChallengeExplanation).Methods("GET")
+	r.HandleFunc("/challenge/{assertion-hash}/export", s.ChallengeStateExport).Methods("GET")
+	r.HandleFunc("/challenge/export/schema", s.ChallengeStateExportSchema).Methods("GET")
 	r.HandleFunc("/tracked/royal-edges", s.RoyalTrackedChallengeEdges).Methods("GET")
+	r.HandleFunc("/tracked/edges/{edge-id}/decision", s.TrackerDecision).Methods("GET")
+	r.HandleFunc("/edges/{edge-id}/withdraw", s.WithdrawEdge).Methods("POST")
 	r.HandleFunc("/state-provider/requests/collect-machine-hashes", s.CollectMachineHashes).Methods("GET")
+	r.HandleFunc("/stakes/events", s.StakeEvents).Methods("GET")
+	r.HandleFunc("/stakes/exposure", s.StakeExposure).Methods("GET")
+	r.HandleFunc("/validators/allowlist", s.ValidatorAllowlist).Methods("GET")
+	r.HandleFunc("/protocol/info", s.ProtocolInfo).Methods("GET")
+	r.HandleFunc("/audit/entries", s.AuditEntries).Methods("GET")
+	r.HandleFunc("/audit/verify", s.VerifyAuditLog).Methods("GET")
+	r.HandleFunc("/feed/dispute-stats", s.DisputeStatsFeed).Methods("GET")
+	r.HandleFunc("/actions/required", s.RequiredActions).Methods("GET")
+	r.HandleFunc("/txs/stuck", s.StuckTxs).Methods("GET")
+	r.HandleFunc("/txs/stuck/{tx-hash}/resolve", s.ResolveStuckTx).Methods("POST")
+	r.HandleFunc("/poison", s.Poison).Methods("GET")
+	r.HandleFunc("/poison/clear", s.ClearPoison).Methods("POST")
+	r.HandleFunc("/confirmation/methods", s.ConfirmationMethods).Methods("GET")
+	r.HandleFunc("/confirmation/methods/{method}/enable", s.EnableConfirmationMethod).Methods("POST")
+	r.HandleFunc("/confirmation/methods/{method}/disable", s.DisableConfirmationMethod).Methods("POST")
+	r.HandleFunc("/watcher/load-shedding", s.LoadShedding).Methods("GET")
+	r.HandleFunc("/latency/counter-moves", s.LatencyBudget).Methods("GET")
+	r.HandleFunc("/analytics/query", s.AnalyticsQuery).Methods("GET")
+	r.HandleFunc("/search", s.SearchByHashPrefix).Methods("GET")
 	s.registered = true
 	return nil
 }
@@ -0,0 +1,317 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package stateexport defines a client independent export format of the state of a challenge as
+// seen by a validator, so that other implementations of BOLD can import it and verify they reach
+// the same royal edges and pending actions from the same edges. The format is JSON, described by
+// the JSON schema returned by Schema, and versioned by FormatVersion.
+package stateexport
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"sort"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/OffchainLabs/bold/math"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// FormatVersion identifies the version of the export format, which changes whenever a field is
+// added, removed or changes meaning.
+const FormatVersion = "bold-challenge-state/2"
+
+//go:embed schema.json
+var schemaJson []byte
+
+// Schema returns the JSON schema of an exported challenge state.
+func Schema() []byte {
+	return bytes.Clone(schemaJson)
+}
+
+// ChallengeState is the complete state of the challenge on an assertion as seen by a validator.
+type ChallengeState struct {
+	FormatVersion           string         `json:"formatVersion"`
+	ChallengeManager        common.Address `json:"challengeManager"`
+	ChallengedAssertionHash common.Hash    `json:"challengedAssertionHash"`
+	// The validator the state is seen by, whose edges are royal.
+	Validator common.Address `json:"validator"`
+	// The block the state was exported at.
+	BlockNumber           uint64 `json:"blockNumber"`
+	NumBigStepLevels      uint8  `json:"numBigStepLevels"`
+	ChallengePeriodBlocks uint64 `json:"challengePeriodBlocks"`
+	// Edges ordered by challenge level, start height, end height and id.
+	Edges []*Edge `json:"edges"`
+	// The ids of the royal edges in ascending order.
+	RoyalEdgeIds []common.Hash `json:"royalEdgeIds"`
+	// The actions the validator takes next, derived from the edges by DerivePendingActions.
+	PendingActions []*PendingAction `json:"pendingActions"`
+}
+
+// Edge is an edge of the challenge. Every field but royal and inheritedTimer is read from the
+// chain, so all clients should agree on them.
+type Edge struct {
+	Id                common.Hash    `json:"id"`
+	ChallengeLevel    uint8          `json:"challengeLevel"`
+	OriginId          common.Hash    `json:"originId"`
+	MutualId          common.Hash    `json:"mutualId"`
+	ClaimId           common.Hash    `json:"claimId"`
+	StartHeight       uint64         `json:"startHeight"`
+	StartHistoryRoot  common.Hash    `json:"startHistoryRoot"`
+	EndHeight         uint64         `json:"endHeight"`
+	EndHistoryRoot    common.Hash    `json:"endHistoryRoot"`
+	LowerChildId      common.Hash    `json:"lowerChildId"`
+	UpperChildId      common.Hash    `json:"upperChildId"`
+	CreatedAtBlock    uint64         `json:"createdAtBlock"`
+	MiniStaker        common.Address `json:"miniStaker"`
+	Status            string         `json:"status"`
+	HasRival          bool           `json:"hasRival"`
+	HasLengthOneRival bool           `json:"hasLengthOneRival"`
+	TimeUnrivaled     uint64         `json:"timeUnrivaled"`
+	// Whether the edge agrees with the validator's view of the history.
+	Royal bool `json:"royal"`
+	// The timer of the edge inherited from its children, as computed by the validator.
+	InheritedTimer uint64 `json:"inheritedTimer"`
+	// The canonical encoding of the edge as stored by the challenge manager at the block of the
+	// export, as given by solimpl.EncodeChallengeEdge, if the exporting client could read it.
+	Encoding hexutil.Bytes `json:"encoding,omitempty"`
+}
+
+// PendingAction is an action the validator takes next on one of its edges.
+type PendingAction struct {
+	EdgeId common.Hash `json:"edgeId"`
+	// The decision of the edge's tracker, such as bisect or open_subchallenge_leaf.
+	Action string `json:"action"`
+	// The height the edge is bisected at, for bisections.
+	BisectionHeight *uint64 `json:"bisectionHeight,omitempty"`
+}
+
+// FromEdges exports the edges of the challenge on an assertion as stored by the validator. The
+// other fields of the state are left to the caller.
+func FromEdges(state *ChallengeState, edges []*api.JsonEdge) *ChallengeState {
+	state.FormatVersion = FormatVersion
+	state.Edges = make([]*Edge, 0, len(edges))
+	state.RoyalEdgeIds = make([]common.Hash, 0)
+	for _, e := range edges {
+		state.Edges = append(state.Edges, &Edge{
+			Id:                e.Id,
+			ChallengeLevel:    e.ChallengeLevel,
+			OriginId:          e.OriginId,
+			MutualId:          e.MutualId,
+			ClaimId:           e.ClaimId,
+			StartHeight:       e.StartHeight,
+			StartHistoryRoot:  e.StartHistoryRoot,
+			EndHeight:         e.EndHeight,
+			EndHistoryRoot:    e.EndHistoryRoot,
+			LowerChildId:      e.LowerChildId,
+			UpperChildId:      e.UpperChildId,
+			CreatedAtBlock:    e.CreatedAtBlock,
+			MiniStaker:        e.MiniStaker,
+			Status:            e.Status,
+			HasRival:          e.HasRival,
+			HasLengthOneRival: e.HasLengthOneRival,
+			TimeUnrivaled:     e.TimeUnrivaled,
+			Royal:             e.IsRoyal,
+			InheritedTimer:    e.InheritedTimer,
+		})
+		if e.IsRoyal {
+			state.RoyalEdgeIds = append(state.RoyalEdgeIds, e.Id)
+		}
+	}
+	sort.Slice(state.Edges, func(i, j int) bool {
+		a, b := state.Edges[i], state.Edges[j]
+		if a.ChallengeLevel != b.ChallengeLevel {
+			return a.ChallengeLevel < b.ChallengeLevel
+		}
+		if a.StartHeight != b.StartHeight {
+			return a.StartHeight < b.StartHeight
+		}
+		if a.EndHeight != b.EndHeight {
+			return a.EndHeight < b.EndHeight
+		}
+		return bytes.Compare(a.Id[:], b.Id[:]) < 0
+	})
+	sort.Slice(state.RoyalEdgeIds, func(i, j int) bool {
+		return bytes.Compare(state.RoyalEdgeIds[i][:], state.RoyalEdgeIds[j][:]) < 0
+	})
+	state.PendingActions = DerivePendingActions(state)
+	return state
+}
+
+// DerivePendingActions derives the actions the validator takes next from the edges of a state
+// alone, in the order of the edges, so that importers can derive them in the same way. For each
+// pending royal edge, the first of these rules that applies gives its action:
+//
+//  1. An edge of length one at the small step level is one step proven.
+//  2. A root block challenge edge, which claims an assertion, whose inherited timer has reached
+//     the challenge period is confirmed by time.
+//  3. An edge without a rival has no action.
+//  4. An edge with a length one rival opens a subchallenge leaf claiming it, unless one does.
+//  5. An edge with a rival which is not bisected yet is bisected at its end height minus one,
+//     with the bits below the most significant bit where it differs from the start height
+//     cleared, as computed by math.Bisect.
+func DerivePendingActions(state *ChallengeState) []*PendingAction {
+	claimed := make(map[common.Hash]bool)
+	for _, e := range state.Edges {
+		if e.Royal && e.ClaimId != (common.Hash{}) {
+			claimed[e.ClaimId] = true
+		}
+	}
+	actions := make([]*PendingAction, 0)
+	for _, e := range state.Edges {
+		if !e.Royal || e.Status != protocol.EdgePending.String() || e.EndHeight <= e.StartHeight {
+			continue
+		}
+		action := &PendingAction{EdgeId: e.Id}
+		switch {
+		case e.EndHeight-e.StartHeight == 1 && e.ChallengeLevel == state.NumBigStepLevels+1:
+			action.Action = edgetracker.DecideOneStepProof.String()
+		case e.ChallengeLevel == protocol.NewBlockChallengeLevel().Uint8() &&
+			e.ClaimId != (common.Hash{}) &&
+			e.InheritedTimer >= state.ChallengePeriodBlocks:
+			action.Action = edgetracker.DecideConfirmByTime.String()
+		case !e.HasRival:
+			continue
+		case e.HasLengthOneRival:
+			if claimed[e.Id] {
+				continue
+			}
+			action.Action = edgetracker.DecideOpenSubchallengeLeaf.String()
+		case e.LowerChildId == (common.Hash{}):
+			bisectTo, err := math.Bisect(e.StartHeight, e.EndHeight)
+			if err != nil {
+				continue
+			}
+			action.Action = edgetracker.DecideBisect.String()
+			action.BisectionHeight = &bisectTo
+		default:
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// Compare verifies that another client reached the same state of a challenge from the same
+// edges, returning a description of each difference in their royal edges, pending actions or
+// the onchain fields of their edges. Edges created after the earlier of the two exports are
+// ignored, as only one of the clients may have seen them.
+func Compare(ours, theirs *ChallengeState) []string {
+	var diffs []string
+	if ours.FormatVersion != theirs.FormatVersion {
+		return []string{fmt.Sprintf("format version %q differs from ours %q", theirs.FormatVersion, ours.FormatVersion)}
+	}
+	if ours.ChallengedAssertionHash != theirs.ChallengedAssertionHash {
+		return []string{fmt.Sprintf("challenged assertion %#x differs from ours %#x", theirs.ChallengedAssertionHash, ours.ChallengedAssertionHash)}
+	}
+	cutoff := min(ours.BlockNumber, theirs.BlockNumber)
+	ourEdges := edgesUpTo(ours, cutoff)
+	theirEdges := edgesUpTo(theirs, cutoff)
+	for _, id := range sortedIds(ourEdges, theirEdges) {
+		ourEdge, theirEdge := ourEdges[id], theirEdges[id]
+		switch {
+		case theirEdge == nil:
+			diffs = append(diffs, fmt.Sprintf("edge %#x is missing", id))
+		case ourEdge == nil:
+			diffs = append(diffs, fmt.Sprintf("edge %#x is unknown to us", id))
+		default:
+			if onchainFields(ourEdge) != onchainFields(theirEdge) {
+				diffs = append(diffs, fmt.Sprintf("edge %#x has onchain fields %+v, ours are %+v", id, onchainFields(theirEdge), onchainFields(ourEdge)))
+			}
+			// Exports at the same block must agree on the whole state of each edge, which only
+			// exports at different blocks may not.
+			if ours.BlockNumber == theirs.BlockNumber && len(ourEdge.Encoding) > 0 && len(theirEdge.Encoding) > 0 &&
+				!bytes.Equal(ourEdge.Encoding, theirEdge.Encoding) {
+				diffs = append(diffs, fmt.Sprintf("edge %#x has encoding hash %#x, ours is %#x", id, crypto.Keccak256Hash(theirEdge.Encoding), crypto.Keccak256Hash(ourEdge.Encoding)))
+			}
+			if ourEdge.Royal != theirEdge.Royal {
+				diffs = append(diffs, fmt.Sprintf("edge %#x is royal: %t, ours is: %t", id, theirEdge.Royal, ourEdge.Royal))
+			}
+		}
+	}
+	ourActions := actionsOf(ours, ourEdges)
+	theirActions := actionsOf(theirs, theirEdges)
+	for _, id := range sortedIds(ourActions, theirActions) {
+		ourAction, theirAction := ourActions[id], theirActions[id]
+		if ourAction != theirAction {
+			diffs = append(diffs, fmt.Sprintf("pending action on edge %#x is %q, ours is %q", id, theirAction, ourAction))
+		}
+	}
+	return diffs
+}
+
+// The fields of an edge read from the chain, which are not subject to the view of a validator.
+// Fields which change over time, such as the status or time unrivaled, are left out.
+type edgeOnchainFields struct {
+	ChallengeLevel   uint8
+	OriginId         common.Hash
+	MutualId         common.Hash
+	ClaimId          common.Hash
+	StartHeight      uint64
+	StartHistoryRoot common.Hash
+	EndHeight        uint64
+	EndHistoryRoot   common.Hash
+	CreatedAtBlock   uint64
+	MiniStaker       common.Address
+}
+
+func onchainFields(e *Edge) edgeOnchainFields {
+	return edgeOnchainFields{
+		ChallengeLevel:   e.ChallengeLevel,
+		OriginId:         e.OriginId,
+		MutualId:         e.MutualId,
+		ClaimId:          e.ClaimId,
+		StartHeight:      e.StartHeight,
+		StartHistoryRoot: e.StartHistoryRoot,
+		EndHeight:        e.EndHeight,
+		EndHistoryRoot:   e.EndHistoryRoot,
+		CreatedAtBlock:   e.CreatedAtBlock,
+		MiniStaker:       e.MiniStaker,
+	}
+}
+
+func edgesUpTo(state *ChallengeState, blockNumber uint64) map[common.Hash]*Edge {
+	edges := make(map[common.Hash]*Edge, len(state.Edges))
+	for _, e := range state.Edges {
+		if e.CreatedAtBlock <= blockNumber {
+			edges[e.Id] = e
+		}
+	}
+	return edges
+}
+
+// The pending actions of a state on the given edges, formatted for comparison.
+func actionsOf(state *ChallengeState, edges map[common.Hash]*Edge) map[common.Hash]string {
+	actions := make(map[common.Hash]string)
+	for _, a := range state.PendingActions {
+		if _, ok := edges[a.EdgeId]; !ok {
+			continue
+		}
+		actions[a.EdgeId] = a.Action
+		if a.BisectionHeight != nil {
+			actions[a.EdgeId] = fmt.Sprintf("%s at height %d", a.Action, *a.BisectionHeight)
+		}
+	}
+	return actions
+}
+
+func sortedIds[V any](a, b map[common.Hash]V) []common.Hash {
+	ids := make([]common.Hash, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+	return ids
+}
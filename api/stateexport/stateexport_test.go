@@ -0,0 +1,184 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package stateexport
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func hash(s string) common.Hash {
+	return common.BytesToHash([]byte(s))
+}
+
+func edge(id string, level uint8, start, end uint64, royal bool) *api.JsonEdge {
+	return &api.JsonEdge{
+		Id:             hash(id),
+		ChallengeLevel: level,
+		StartHeight:    start,
+		EndHeight:      end,
+		MutualId:       hash(id + " mutual"),
+		Status:         protocol.EdgePending.String(),
+		IsRoyal:        royal,
+	}
+}
+
+// A challenge with one big step level, where we are bisecting at the block level, have opened a
+// big step subchallenge on one length one edge but not yet on another, and can one step prove.
+func testChallenge() (*ChallengeState, []*api.JsonEdge) {
+	root := edge("root", 0, 0, 32, true)
+	root.ClaimId = hash("claimed assertion")
+	root.HasRival = true
+	root.LowerChildId = hash("lower")
+	root.UpperChildId = hash("upper")
+	root.InheritedTimer = 10
+	evilRoot := edge("evil root", 0, 0, 32, false)
+	evilRoot.HasRival = true
+	upper := edge("upper", 0, 16, 32, true)
+	upper.HasRival = true
+	unrivaled := edge("lower", 0, 0, 16, true)
+	forkWithLeaf := edge("fork with leaf", 0, 7, 8, true)
+	forkWithLeaf.HasRival = true
+	forkWithLeaf.HasLengthOneRival = true
+	forkWithoutLeaf := edge("fork without leaf", 0, 8, 9, true)
+	forkWithoutLeaf.HasRival = true
+	forkWithoutLeaf.HasLengthOneRival = true
+	leaf := edge("leaf", 1, 0, 1, true)
+	leaf.ClaimId = forkWithLeaf.Id
+	oneStep := edge("one step", 2, 3, 4, true)
+	confirmed := edge("confirmed", 2, 4, 5, true)
+	confirmed.Status = protocol.EdgeConfirmed.String()
+	state := &ChallengeState{
+		ChallengedAssertionHash: hash("challenged assertion"),
+		BlockNumber:             100,
+		NumBigStepLevels:        1,
+		ChallengePeriodBlocks:   50,
+	}
+	return state, []*api.JsonEdge{oneStep, upper, root, leaf, evilRoot, forkWithLeaf, unrivaled, forkWithoutLeaf, confirmed}
+}
+
+func TestFromEdges(t *testing.T) {
+	state, edges := testChallenge()
+	state = FromEdges(state, edges)
+	require.Equal(t, FormatVersion, state.FormatVersion)
+
+	ids := make([]common.Hash, 0, len(state.Edges))
+	for _, e := range state.Edges {
+		ids = append(ids, e.Id)
+	}
+	// Root ids are ordered by their bytes, so the shorter string comes first.
+	require.Equal(t, []common.Hash{
+		hash("lower"), hash("root"), hash("evil root"), hash("fork with leaf"), hash("fork without leaf"), hash("upper"),
+		hash("leaf"), hash("one step"), hash("confirmed"),
+	}, ids)
+	require.Len(t, state.RoyalEdgeIds, len(edges)-1)
+	require.True(t, sort.SliceIsSorted(state.RoyalEdgeIds, func(i, j int) bool {
+		return state.RoyalEdgeIds[i].Hex() < state.RoyalEdgeIds[j].Hex()
+	}))
+
+	bisectRoot, bisectUpper := uint64(16), uint64(24)
+	require.Equal(t, []*PendingAction{
+		{EdgeId: hash("fork without leaf"), Action: "open_subchallenge_leaf"},
+		{EdgeId: hash("upper"), Action: "bisect", BisectionHeight: &bisectUpper},
+		{EdgeId: hash("one step"), Action: "one_step_proof"},
+	}, state.PendingActions)
+
+	// The root edge is confirmed by time once its timer reaches the challenge period, or bisected
+	// if it has no children yet.
+	edges[2].InheritedTimer = 50
+	require.Equal(t, &PendingAction{EdgeId: hash("root"), Action: "confirm_by_time"}, FromEdges(state, edges).PendingActions[0])
+	edges[2].InheritedTimer = 10
+	edges[2].LowerChildId = common.Hash{}
+	require.Equal(t, &PendingAction{EdgeId: hash("root"), Action: "bisect", BisectionHeight: &bisectRoot}, FromEdges(state, edges).PendingActions[0])
+}
+
+func TestCompare(t *testing.T) {
+	ours := FromEdges(testChallenge())
+	theirs := FromEdges(testChallenge())
+	require.Empty(t, Compare(ours, theirs))
+
+	// Survives a round trip through the format.
+	data, err := json.Marshal(ours)
+	require.NoError(t, err)
+	var imported ChallengeState
+	require.NoError(t, json.Unmarshal(data, &imported))
+	require.Empty(t, Compare(ours, &imported))
+
+	// Edges created after the earlier of the exports are ignored.
+	state, edges := testChallenge()
+	late := edge("late", 0, 0, 32, true)
+	late.HasRival = true
+	late.CreatedAtBlock = 101
+	state.BlockNumber = 101
+	require.Empty(t, Compare(ours, FromEdges(state, append(edges, late))))
+
+	state, edges = testChallenge()
+	edges[1].IsRoyal = false
+	edges[6].EndHistoryRoot = hash("other root")
+	diffs := Compare(ours, FromEdges(state, edges))
+	require.Len(t, diffs, 3)
+	require.Contains(t, diffs, "edge "+hash("upper").Hex()+" is royal: false, ours is: true")
+	require.Contains(t, diffs, "pending action on edge "+hash("upper").Hex()+` is "", ours is "bisect at height 24"`)
+	require.Contains(t, strings.Join(diffs, "\n"), "edge "+hash("lower").Hex()+" has onchain fields")
+
+	// Exports at the same block must agree on the encodings of edges, if both have them.
+	theirs = FromEdges(testChallenge())
+	ours.Edges[0].Encoding = []byte{1, 2}
+	require.Empty(t, Compare(ours, theirs))
+	theirs.Edges[0].Encoding = []byte{1, 3}
+	require.Len(t, Compare(ours, theirs), 1)
+	theirs.BlockNumber++
+	require.Empty(t, Compare(ours, theirs))
+	ours.Edges[0].Encoding = nil
+
+	theirs = FromEdges(testChallenge())
+	theirs.FormatVersion = "bold-challenge-state/0"
+	require.Len(t, Compare(ours, theirs), 1)
+}
+
+// The schema must describe every field of the format, and require all but the optional ones.
+func TestSchemaMatchesFormat(t *testing.T) {
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+		Defs       map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(Schema(), &schema))
+	var formatVersion struct {
+		Const string `json:"const"`
+	}
+	require.NoError(t, json.Unmarshal(schema.Properties["formatVersion"], &formatVersion))
+	require.Equal(t, FormatVersion, formatVersion.Const)
+
+	check := func(typ reflect.Type, properties map[string]json.RawMessage, required []string) {
+		var fields, requiredFields []string
+		for i := 0; i < typ.NumField(); i++ {
+			name, opts, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			fields = append(fields, name)
+			if opts != "omitempty" {
+				requiredFields = append(requiredFields, name)
+			}
+		}
+		schemaFields := make([]string, 0, len(properties))
+		for name := range properties {
+			schemaFields = append(schemaFields, name)
+		}
+		require.ElementsMatch(t, fields, schemaFields, typ.Name())
+		require.ElementsMatch(t, requiredFields, required, typ.Name())
+	}
+	check(reflect.TypeOf(ChallengeState{}), schema.Properties, schema.Required)
+	check(reflect.TypeOf(Edge{}), schema.Defs["edge"].Properties, schema.Defs["edge"].Required)
+	check(reflect.TypeOf(PendingAction{}), schema.Defs["pendingAction"].Properties, schema.Defs["pendingAction"].Required)
+}
@@ -6,34 +6,110 @@ import (
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 type JsonAssertion struct {
-	Hash                     common.Hash            `json:"hash" db:"Hash"`
-	ConfirmPeriodBlocks      uint64                 `json:"confirmPeriodBlocks" db:"ConfirmPeriodBlocks"`
-	RequiredStake            string                 `json:"requiredStake" db:"RequiredStake"`
-	ParentAssertionHash      common.Hash            `json:"parentAssertionHash" db:"ParentAssertionHash"`
-	InboxMaxCount            string                 `json:"inboxMaxCount" db:"InboxMaxCount"`
-	AfterInboxBatchAcc       common.Hash            `json:"afterInboxBatchAcc" db:"AfterInboxBatchAcc"`
-	WasmModuleRoot           common.Hash            `json:"wasmModuleRoot" db:"WasmModuleRoot"`
-	ChallengeManager         common.Address         `json:"challengeManager" db:"ChallengeManager"`
-	CreationBlock            uint64                 `json:"creationBlock" db:"CreationBlock"`
-	TransactionHash          common.Hash            `json:"transactionHash" db:"TransactionHash"`
-	BeforeStateBlockHash     common.Hash            `json:"beforeStateBlockHash" db:"BeforeStateBlockHash"`
-	BeforeStateSendRoot      common.Hash            `json:"beforeStateSendRoot" db:"BeforeStateSendRoot"`
-	BeforeStateBatch         uint64                 `json:"beforeStateBatch" db:"BeforeStateBatch"`
-	BeforeStatePosInBatch    uint64                 `json:"beforeStatePosInBatch" db:"BeforeStatePosInBatch"`
-	BeforeStateMachineStatus protocol.MachineStatus `json:"beforeStateMachineStatus" db:"BeforeStateMachineStatus"`
-	AfterStateBlockHash      common.Hash            `json:"afterStateBlockHash" db:"AfterStateBlockHash"`
-	AfterStateSendRoot       common.Hash            `json:"afterStateSendRoot" db:"AfterStateSendRoot"`
-	AfterStateBatch          uint64                 `json:"afterStateBatch" db:"AfterStateBatch"`
-	AfterStatePosInBatch     uint64                 `json:"afterStatePosInBatch" db:"AfterStatePosInBatch"`
-	AfterStateMachineStatus  protocol.MachineStatus `json:"afterStateMachineStatus" db:"AfterStateMachineStatus"`
-	FirstChildBlock          *uint64                `json:"firstChildBlock" db:"FirstChildBlock"`
-	SecondChildBlock         *uint64                `json:"secondChildBlock" db:"SecondChildBlock"`
-	IsFirstChild             bool                   `json:"isFirstChild" db:"IsFirstChild"`
-	Status                   string                 `json:"status" db:"Status"`
-	LastUpdatedAt            time.Time              `json:"lastUpdatedAt" db:"LastUpdatedAt"`
+	Hash                      common.Hash            `json:"hash" db:"Hash"`
+	ConfirmPeriodBlocks       uint64                 `json:"confirmPeriodBlocks" db:"ConfirmPeriodBlocks"`
+	RequiredStake             string                 `json:"requiredStake" db:"RequiredStake"`
+	ParentAssertionHash       common.Hash            `json:"parentAssertionHash" db:"ParentAssertionHash"`
+	InboxMaxCount             string                 `json:"inboxMaxCount" db:"InboxMaxCount"`
+	AfterInboxBatchAcc        common.Hash            `json:"afterInboxBatchAcc" db:"AfterInboxBatchAcc"`
+	WasmModuleRoot            common.Hash            `json:"wasmModuleRoot" db:"WasmModuleRoot"`
+	ChallengeManager          common.Address         `json:"challengeManager" db:"ChallengeManager"`
+	CreationBlock             uint64                 `json:"creationBlock" db:"CreationBlock"`
+	TransactionHash           common.Hash            `json:"transactionHash" db:"TransactionHash"`
+	BeforeStateBlockHash      common.Hash            `json:"beforeStateBlockHash" db:"BeforeStateBlockHash"`
+	BeforeStateSendRoot       common.Hash            `json:"beforeStateSendRoot" db:"BeforeStateSendRoot"`
+	BeforeStateBatch          uint64                 `json:"beforeStateBatch" db:"BeforeStateBatch"`
+	BeforeStatePosInBatch     uint64                 `json:"beforeStatePosInBatch" db:"BeforeStatePosInBatch"`
+	BeforeStateMachineStatus  protocol.MachineStatus `json:"beforeStateMachineStatus" db:"BeforeStateMachineStatus"`
+	AfterStateBlockHash       common.Hash            `json:"afterStateBlockHash" db:"AfterStateBlockHash"`
+	AfterStateSendRoot        common.Hash            `json:"afterStateSendRoot" db:"AfterStateSendRoot"`
+	AfterStateBatch           uint64                 `json:"afterStateBatch" db:"AfterStateBatch"`
+	AfterStatePosInBatch      uint64                 `json:"afterStatePosInBatch" db:"AfterStatePosInBatch"`
+	AfterStateMachineStatus   protocol.MachineStatus `json:"afterStateMachineStatus" db:"AfterStateMachineStatus"`
+	FirstChildBlock           *uint64                `json:"firstChildBlock" db:"FirstChildBlock"`
+	SecondChildBlock          *uint64                `json:"secondChildBlock" db:"SecondChildBlock"`
+	IsFirstChild              bool                   `json:"isFirstChild" db:"IsFirstChild"`
+	Status                    string                 `json:"status" db:"Status"`
+	LastUpdatedAt             time.Time              `json:"lastUpdatedAt" db:"LastUpdatedAt"`
+	BeforeStateEndHistoryRoot common.Hash            `json:"beforeStateEndHistoryRoot" db:"BeforeStateEndHistoryRoot"`
+	AfterStateEndHistoryRoot  common.Hash            `json:"afterStateEndHistoryRoot" db:"AfterStateEndHistoryRoot"`
+	// The execution states the assertion claims to start and end at, decoded from the fields
+	// above by DecodeStates.
+	BeforeState *JsonExecutionState `json:"beforeState,omitempty" db:"-"`
+	AfterState  *JsonExecutionState `json:"afterState,omitempty" db:"-"`
+}
+
+// JsonExecutionState is a decoded execution state claimed by an assertion.
+type JsonExecutionState struct {
+	BlockHash  common.Hash `json:"blockHash"`
+	SendRoot   common.Hash `json:"sendRoot"`
+	Batch      uint64      `json:"batch"`
+	PosInBatch uint64      `json:"posInBatch"`
+	// One of running, finished, or errored.
+	MachineStatus  string      `json:"machineStatus"`
+	EndHistoryRoot common.Hash `json:"endHistoryRoot"`
+	// The hash of the global state, as committed to in history commitments.
+	GlobalStateHash common.Hash `json:"globalStateHash"`
+	// The number of batches the machine read to reach the state.
+	RequiredBatches uint64 `json:"requiredBatches"`
+}
+
+// NewJsonExecutionState decodes an execution state.
+func NewJsonExecutionState(state *protocol.ExecutionState) *JsonExecutionState {
+	return &JsonExecutionState{
+		BlockHash:       state.GlobalState.BlockHash,
+		SendRoot:        state.GlobalState.SendRoot,
+		Batch:           state.GlobalState.Batch,
+		PosInBatch:      state.GlobalState.PosInBatch,
+		MachineStatus:   state.MachineStatus.String(),
+		EndHistoryRoot:  state.EndHistoryRoot,
+		GlobalStateHash: state.GlobalState.Hash(),
+		RequiredBatches: state.RequiredBatches(),
+	}
+}
+
+// SetStates sets the fields of the execution states an assertion claims.
+func (a *JsonAssertion) SetStates(before, after *protocol.ExecutionState) {
+	a.BeforeStateBlockHash = before.GlobalState.BlockHash
+	a.BeforeStateSendRoot = before.GlobalState.SendRoot
+	a.BeforeStateBatch = before.GlobalState.Batch
+	a.BeforeStatePosInBatch = before.GlobalState.PosInBatch
+	a.BeforeStateMachineStatus = before.MachineStatus
+	a.BeforeStateEndHistoryRoot = before.EndHistoryRoot
+	a.AfterStateBlockHash = after.GlobalState.BlockHash
+	a.AfterStateSendRoot = after.GlobalState.SendRoot
+	a.AfterStateBatch = after.GlobalState.Batch
+	a.AfterStatePosInBatch = after.GlobalState.PosInBatch
+	a.AfterStateMachineStatus = after.MachineStatus
+	a.AfterStateEndHistoryRoot = after.EndHistoryRoot
+}
+
+// DecodeStates fills in the decoded execution states an assertion claims from its fields.
+func (a *JsonAssertion) DecodeStates() {
+	a.BeforeState = NewJsonExecutionState(&protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash:  a.BeforeStateBlockHash,
+			SendRoot:   a.BeforeStateSendRoot,
+			Batch:      a.BeforeStateBatch,
+			PosInBatch: a.BeforeStatePosInBatch,
+		},
+		MachineStatus:  a.BeforeStateMachineStatus,
+		EndHistoryRoot: a.BeforeStateEndHistoryRoot,
+	})
+	a.AfterState = NewJsonExecutionState(&protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash:  a.AfterStateBlockHash,
+			SendRoot:   a.AfterStateSendRoot,
+			Batch:      a.AfterStateBatch,
+			PosInBatch: a.AfterStatePosInBatch,
+		},
+		MachineStatus:  a.AfterStateMachineStatus,
+		EndHistoryRoot: a.AfterStateEndHistoryRoot,
+	})
 }
 
 type JsonEdge struct {
@@ -57,15 +133,43 @@ type JsonEdge struct {
 	Status            string         `json:"status" db:"Status"`
 	HasLengthOneRival bool           `json:"hasLengthOneRival" db:"HasLengthOneRival"`
 	LastUpdatedAt     time.Time      `json:"lastUpdatedAt" db:"LastUpdatedAt"`
+	// The block at which the edge was confirmed, if it is confirmed and the block is known.
+	ConfirmedAtBlock *uint64 `json:"confirmedAtBlock,omitempty" db:"ConfirmedAtBlock"`
+	// Whether the lower child already existed when the edge was bisected, created by the bisection
+	// of a rival, and was adopted by the edge.
+	LowerChildAlreadyExists bool `json:"lowerChildAlreadyExists" db:"LowerChildAlreadyExists"`
 	// Honest validator's point of view
-	Ancestors           []common.Hash `json:"ancestors"`
-	RawAncestors        string        `json:"-" db:"RawAncestors"`
-	IsRoyal             bool          `json:"isRoyal" db:"IsRoyal"`
-	CumulativePathTimer uint64        `json:"cumulativePathTimer" db:"CumulativePathTimer"`
-	InheritedTimer      uint64        `json:"inheritedTimer" db:"InheritedTimer"`
-	RefersTo            string        `json:"refersTo" db:"RefersTo"`
-	FSMState            string        `json:"fsmState"`
-	FSMError            string        `json:"fsmError"`
+	Ancestors           []common.Hash         `json:"ancestors"`
+	RawAncestors        string                `json:"-" db:"RawAncestors"`
+	IsRoyal             bool                  `json:"isRoyal" db:"IsRoyal"`
+	CumulativePathTimer uint64                `json:"cumulativePathTimer" db:"CumulativePathTimer"`
+	InheritedTimer      uint64                `json:"inheritedTimer" db:"InheritedTimer"`
+	RefersTo            string                `json:"refersTo" db:"RefersTo"`
+	FSMState            string                `json:"fsmState"`
+	FSMError            string                `json:"fsmError"`
+	BlockedBy           []*JsonBlockingReason `json:"blockedBy"`
+	// Why the reasons the edge is blocked could not be determined, if they could not.
+	BlockedByError string `json:"blockedByError,omitempty"`
+}
+
+// JsonBlockingReason describes a condition preventing an edge from being confirmed.
+type JsonBlockingReason struct {
+	Kind           string `json:"kind"`
+	BlockingEdgeId string `json:"blockingEdgeId,omitempty"`
+	Detail         string `json:"detail"`
+}
+
+// JsonTrackerDecision is the action an edge tracker decided on, or would have, from its start state
+// at a block, along with the checks that led to it and the operations the validator recorded
+// for the edge in its audit log while the block was the latest.
+type JsonTrackerDecision struct {
+	EdgeId          common.Hash       `json:"edgeId"`
+	BlockNumber     uint64            `json:"blockNumber"`
+	FSMState        string            `json:"fsmState"`
+	Decision        string            `json:"decision"`
+	Reasons         []string          `json:"reasons"`
+	BisectionHeight *uint64           `json:"bisectionHeight,omitempty"`
+	Journal         []*JsonAuditEntry `json:"journal"`
 }
 
 type JsonTrackedRoyalEdge struct {
@@ -115,6 +219,328 @@ type JsonCollectMachineHashes struct {
 	FinishTime           *time.Time  `json:"finishTime" db:"FinishTime"`
 }
 
+// JsonStakeEvent is a change in the amount of tokens a staker has locked in the protocol,
+// either in an assertion stake or in a mini-stake on a layer zero edge.
+type JsonStakeEvent struct {
+	Staker          common.Address `json:"staker" db:"Staker"`
+	Kind            string         `json:"kind" db:"Kind"`
+	Source          string         `json:"source" db:"Source"`
+	EdgeId          common.Hash    `json:"edgeId" db:"EdgeId"`
+	Amount          string         `json:"amount" db:"Amount"`
+	BlockNumber     uint64         `json:"blockNumber" db:"BlockNumber"`
+	TransactionHash common.Hash    `json:"transactionHash" db:"TransactionHash"`
+	LogIndex        uint           `json:"logIndex" db:"LogIndex"`
+	Timestamp       time.Time      `json:"timestamp" db:"Timestamp"`
+	// Whether a mini-stake locked by a layer zero edge created with a rival went to the excess
+	// stake receiver rather than staying in the challenge manager.
+	SentToExcessReceiver bool `json:"sentToExcessReceiver" db:"SentToExcessReceiver"`
+}
+
+const (
+	StakeEventLocked   = "locked"
+	StakeEventRefunded = "refunded"
+
+	StakeSourceAssertion = "assertion"
+	StakeSourceEdge      = "edge"
+)
+
+// JsonDeploymentStartBlock is the block from which events of a challenge manager deployment
+// are scanned, either discovered from the chain or overridden by the operator.
+type JsonDeploymentStartBlock struct {
+	ChallengeManager common.Address `json:"challengeManager" db:"ChallengeManager"`
+	StartBlock       uint64         `json:"startBlock" db:"StartBlock"`
+	Source           string         `json:"source" db:"Source"`
+}
+
+const (
+	DeploymentStartBlockDiscovered = "discovered"
+	DeploymentStartBlockOverride   = "override"
+)
+
+// JsonStakeExposure aggregates stake events over a period of time. The high-water mark
+// is the largest amount of tokens that were locked at any point during the period.
+type JsonStakeExposure struct {
+	PeriodStart   time.Time `json:"periodStart"`
+	Locked        string    `json:"locked"`
+	Refunded      string    `json:"refunded"`
+	HighWaterMark string    `json:"highWaterMark"`
+	EndExposure   string    `json:"endExposure"`
+}
+
+// JsonValidatorAllowlist lists the addresses a rollup allows to act as validators, and whether
+// the address this validator sends transactions from is one of them.
+type JsonValidatorAllowlist struct {
+	Enabled       bool             `json:"enabled"`
+	Validators    []common.Address `json:"validators"`
+	Staker        common.Address   `json:"staker"`
+	StakerAllowed bool             `json:"stakerAllowed"`
+}
+
+// JsonProtocolInfo holds the protocol constants of a challenge manager deployment, which
+// tools should read rather than hardcode, as they differ between deployments.
+type JsonProtocolInfo struct {
+	ChallengeManager      common.Address            `json:"challengeManager"`
+	NumBigStepLevels      uint8                     `json:"numBigStepLevels"`
+	ChallengePeriodBlocks uint64                    `json:"challengePeriodBlocks"`
+	StakeToken            common.Address            `json:"stakeToken"`
+	ChallengeStakeCost    string                    `json:"challengeStakeCost"`
+	Levels                []*JsonChallengeLevelInfo `json:"levels"`
+	// Whether the challenge manager lets stakers withdraw layer zero edges to recover their stakes.
+	EdgeWithdrawalSupported bool `json:"edgeWithdrawalSupported"`
+}
+
+// JsonChallengeLevelInfo holds the protocol constants of a single challenge level.
+type JsonChallengeLevelInfo struct {
+	Level           uint8  `json:"level"`
+	Name            string `json:"name"`
+	LayerZeroHeight uint64 `json:"layerZeroHeight"`
+	StakeAmount     string `json:"stakeAmount"`
+}
+
+// JsonAuditEntry is an operation performed by the validator, such as posting an assertion or
+// bisecting an edge, recorded in an append-only audit log. Each entry commits to the hash of the
+// entry before it, so modifying or removing an entry breaks the chain of hashes.
+type JsonAuditEntry struct {
+	Seq       uint64      `json:"seq" db:"Seq"`
+	Timestamp time.Time   `json:"timestamp" db:"Timestamp"`
+	Principal string      `json:"principal" db:"Principal"`
+	Action    string      `json:"action" db:"Action"`
+	Inputs    string      `json:"inputs" db:"Inputs"`
+	Result    string      `json:"result" db:"Result"`
+	Error     string      `json:"error" db:"Error"`
+	PrevHash  common.Hash `json:"prevHash" db:"PrevHash"`
+	Hash      common.Hash `json:"hash" db:"Hash"`
+}
+
+const (
+	AuditActionPostAssertion            = "post_assertion"
+	AuditActionCreateBlockChallengeEdge = "create_block_challenge_edge"
+	AuditActionCreateSubchallengeEdge   = "create_subchallenge_edge"
+	AuditActionBisectEdge               = "bisect_edge"
+	AuditActionConfirmEdgeByTime        = "confirm_edge_by_time"
+	AuditActionWithdrawEdge             = "withdraw_edge"
+)
+
+// JsonEdgeWithdrawal is the withdrawal of a layer zero edge to recover its stake.
+type JsonEdgeWithdrawal struct {
+	EdgeId          common.Hash `json:"edgeId"`
+	TransactionHash common.Hash `json:"transactionHash"`
+}
+
+// JsonAuditVerification is the result of verifying the chain of hashes of the audit log.
+type JsonAuditVerification struct {
+	Valid           bool        `json:"valid"`
+	NumEntries      uint64      `json:"numEntries"`
+	HeadHash        common.Hash `json:"headHash"`
+	FirstInvalidSeq uint64      `json:"firstInvalidSeq,omitempty"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// JsonChallengeExplanation narrates the state of a challenge from the point of view of the
+// validator, for operators who are not familiar with the edge tree.
+type JsonChallengeExplanation struct {
+	ChallengedAssertionHash common.Hash `json:"challengedAssertionHash"`
+	// The deepest challenge level the validator has royal edges at.
+	Level     uint8  `json:"level"`
+	LevelName string `json:"levelName"`
+	// The royal edge with the shortest range among the rivaled ones at the deepest level, which
+	// is where the validator and its rivals currently disagree.
+	DisagreementEdgeId      common.Hash `json:"disagreementEdgeId"`
+	DisagreementStartHeight uint64      `json:"disagreementStartHeight"`
+	DisagreementEndHeight   uint64      `json:"disagreementEndHeight"`
+	BisectionsToLengthOne   uint64      `json:"bisectionsToLengthOne"`
+	// Path timers sum the time each edge on the path to the root was unrivaled, in blocks.
+	OurPathTimer   uint64 `json:"ourPathTimer"`
+	RivalPathTimer uint64 `json:"rivalPathTimer"`
+	TimerLead      int64  `json:"timerLead"`
+	Narrative      string `json:"narrative"`
+}
+
+// JsonDisputeStats aggregates the challenges on a single assertion, for dashboards reporting
+// on how disputes play out.
+type JsonDisputeStats struct {
+	ChallengedAssertionHash common.Hash `json:"challengedAssertionHash"`
+	// Challenges are counted at every level, so a block challenge and the subchallenges opened
+	// within it each count as one.
+	ChallengesOpened   uint64 `json:"challengesOpened"`
+	ChallengesResolved uint64 `json:"challengesResolved"`
+	EdgesCreated       uint64 `json:"edgesCreated"`
+	EdgesConfirmed     uint64 `json:"edgesConfirmed"`
+	// The average time from the first stake locked in a challenge until one of its level zero
+	// edges was confirmed, over the resolved challenges.
+	AverageResolutionSeconds uint64 `json:"averageResolutionSeconds"`
+	// The mini-stakes locked in level zero edges whose rivals were confirmed instead, in wei.
+	AttackerStakeForfeited string `json:"attackerStakeForfeited"`
+	// The mini-stakes refunded to the stakers of confirmed level zero edges, in wei.
+	StakeRefunded string `json:"stakeRefunded"`
+	// The mini-stakes of level zero edges created with a rival, which the challenge manager sends
+	// to its excess stake receiver right away, in wei.
+	StakeSentToExcessReceiver string `json:"stakeSentToExcessReceiver"`
+	// The outcome of each challenge, which is left empty in totals.
+	Challenges []*JsonChallengeOutcome `json:"challenges,omitempty"`
+}
+
+// JsonChallengeOutcome reports where the mini-stakes locked in the level zero edges of a
+// challenge ended up. Amounts are in wei.
+type JsonChallengeOutcome struct {
+	ChallengeLevel            uint8       `json:"challengeLevel"`
+	OriginId                  common.Hash `json:"originId"`
+	Resolved                  bool        `json:"resolved"`
+	StakeLocked               string      `json:"stakeLocked"`
+	StakeRefunded             string      `json:"stakeRefunded"`
+	StakeSentToExcessReceiver string      `json:"stakeSentToExcessReceiver"`
+	AttackerStakeForfeited    string      `json:"attackerStakeForfeited"`
+}
+
+// JsonDisputeStatsFeed is the public feed of dispute statistics, with totals over all the
+// assertions it covers. The challenged assertion hash of the totals is left zero.
+type JsonDisputeStatsFeed struct {
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Totals      *JsonDisputeStats   `json:"totals"`
+	Assertions  []*JsonDisputeStats `json:"assertions"`
+}
+
+// JsonRequiredActions is the work queue of an operator: the actions the validator has to take on
+// assertions and edges, now or soon, most urgent first.
+type JsonRequiredActions struct {
+	// The block the actions were derived at.
+	BlockNumber uint64                `json:"blockNumber"`
+	Actions     []*JsonRequiredAction `json:"actions"`
+}
+
+// JsonRequiredAction is an action the validator has to take on an assertion, or on an edge in the
+// challenge on it.
+type JsonRequiredAction struct {
+	AssertionHash   common.Hash  `json:"assertionHash"`
+	EdgeId          *common.Hash `json:"edgeId,omitempty"`
+	ChallengeLevel  *uint8       `json:"challengeLevel,omitempty"`
+	Action          string       `json:"action"`
+	BisectionHeight *uint64      `json:"bisectionHeight,omitempty"`
+	// The block the action can be taken from, which is ahead of the current block for imminent
+	// actions.
+	AvailableAtBlock uint64 `json:"availableAtBlock"`
+	// The block by which the action has to be taken, after which a rival can win by time. Unset
+	// for actions without a deadline, such as confirmations.
+	DeadlineBlock *uint64 `json:"deadlineBlock,omitempty"`
+	Reason        string  `json:"reason"`
+}
+
+// JsonStuckTx is a transaction which stayed unconfirmed for too long and waits for an operator to
+// unblock it, with the intent decoded from its calldata and suggested fixes.
+type JsonStuckTx struct {
+	TxHash         common.Hash       `json:"txHash"`
+	Nonce          uint64            `json:"nonce"`
+	From           common.Address    `json:"from"`
+	To             common.Address    `json:"to"`
+	Data           hexutil.Bytes     `json:"data"`
+	GasFeeCap      string            `json:"gasFeeCap"`
+	GasTipCap      string            `json:"gasTipCap"`
+	Method         string            `json:"method"`
+	Args           map[string]string `json:"args,omitempty"`
+	FirstSentAt    time.Time         `json:"firstSentAt"`
+	Attempts       int               `json:"attempts"`
+	EscalatedAt    time.Time         `json:"escalatedAt"`
+	SuggestedFixes []string          `json:"suggestedFixes"`
+}
+
+// JsonPoison describes whether posting is frozen since an edge we agreed with lost by one step
+// proof, and the incident which froze it.
+type JsonPoison struct {
+	Poisoned                bool        `json:"poisoned"`
+	ChallengedAssertionHash common.Hash `json:"challengedAssertionHash,omitempty"`
+	LostEdgeId              common.Hash `json:"lostEdgeId,omitempty"`
+	WinningEdgeId           common.Hash `json:"winningEdgeId,omitempty"`
+	ChallengeLevel          uint8       `json:"challengeLevel,omitempty"`
+	BlockNumber             uint64      `json:"blockNumber,omitempty"`
+	DetectedAt              time.Time   `json:"detectedAt,omitempty"`
+	Bundle                  string      `json:"bundle,omitempty"`
+}
+
+// JsonConfirmationMethods lists which confirmation methods the validator uses, and which of them an
+// operator may disable.
+type JsonConfirmationMethods struct {
+	Enabled      []string `json:"enabled"`
+	Disabled     []string `json:"disabled"`
+	CanBeToggled []string `json:"canBeToggled"`
+}
+
+// JsonLoadShedding describes whether the chain watcher is shedding the tracking of edges off the
+// royal path under peak load, and the edges it shed and has not tracked yet.
+type JsonLoadShedding struct {
+	Enabled              bool            `json:"enabled"`
+	Active               bool            `json:"active"`
+	Backlog              int             `json:"backlog"`
+	BacklogThreshold     int             `json:"backlogThreshold"`
+	DeadlineWindowBlocks uint64          `json:"deadlineWindowBlocks"`
+	TotalShed            uint64          `json:"totalShed"`
+	TotalRestored        uint64          `json:"totalRestored"`
+	ShedEdges            []*JsonShedEdge `json:"shedEdges"`
+}
+
+// JsonShedEdge is an edge whose tracking was shed under peak load.
+type JsonShedEdge struct {
+	AssertionHash  common.Hash `json:"assertionHash"`
+	EdgeId         common.Hash `json:"edgeId"`
+	ChallengeLevel uint8       `json:"challengeLevel"`
+}
+
+// JsonLatencyBudget describes the latency of our counter-moves to rivals, from the block at which a
+// rival moved against our edge to the block our move was included in, against a budget which is a
+// fraction of the challenge period.
+type JsonLatencyBudget struct {
+	Enabled               bool                `json:"enabled"`
+	ChallengePeriodBlocks uint64              `json:"challengePeriodBlocks"`
+	BudgetFraction        float64             `json:"budgetFraction"`
+	BudgetBlocks          uint64              `json:"budgetBlocks"`
+	Levels                []*JsonLevelLatency `json:"levels"`
+	// The safety margin kept before the deadlines of challenges, if configured, which is tightened to
+	// the observed inclusion latency while that exceeds the configured margin.
+	DeadlineMargin *JsonDeadlineMargin `json:"deadlineMargin,omitempty"`
+}
+
+// JsonDeadlineMargin describes the safety margin of blocks kept before the deadlines of challenges.
+type JsonDeadlineMargin struct {
+	ConfiguredBlocks uint64 `json:"configuredBlocks"`
+	ObservedBlocks   uint64 `json:"observedBlocks"`
+	Blocks           uint64 `json:"blocks"`
+	Tightened        bool   `json:"tightened"`
+}
+
+// JsonLevelLatency holds the percentiles of the recent counter-move latencies of a challenge level.
+type JsonLevelLatency struct {
+	ChallengeLevel uint8   `json:"challengeLevel"`
+	Samples        int     `json:"samples"`
+	Total          uint64  `json:"total"`
+	Exceeded       uint64  `json:"exceeded"`
+	AtRisk         bool    `json:"atRisk"`
+	P50Seconds     float64 `json:"p50Seconds"`
+	P90Seconds     float64 `json:"p90Seconds"`
+	P99Seconds     float64 `json:"p99Seconds"`
+	MaxSeconds     float64 `json:"maxSeconds"`
+	P50Blocks      uint64  `json:"p50Blocks"`
+	P90Blocks      uint64  `json:"p90Blocks"`
+	P99Blocks      uint64  `json:"p99Blocks"`
+	MaxBlocks      uint64  `json:"maxBlocks"`
+}
+
+// JsonAnalyticsQueryResult holds the rows of an ad-hoc analytics query, each with a value for
+// every column.
+type JsonAnalyticsQueryResult struct {
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated"`
+}
+
+// JsonHashSearchResults holds the assertions and edges with a hash starting with a prefix, such as
+// one truncated in logs or by an explorer, grouped by which of their hashes matched.
+type JsonHashSearchResults struct {
+	Prefix                      string           `json:"prefix"`
+	AssertionsByHash            []*JsonAssertion `json:"assertionsByHash"`
+	AssertionsByTransactionHash []*JsonAssertion `json:"assertionsByTransactionHash"`
+	EdgesById                   []*JsonEdge      `json:"edgesById"`
+	EdgesByMutualId             []*JsonEdge      `json:"edgesByMutualId"`
+}
+
 func IsNil(i any) bool {
 	return i == nil || reflect.ValueOf(i).IsNil()
 }
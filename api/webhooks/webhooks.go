@@ -0,0 +1,419 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package webhooks delivers signed notifications of the outcomes of disputes, such as an assertion
+// or edge being confirmed, to downstream systems like bridges and exchanges which automate their
+// reactions to them. Each notification carries the data needed to check the inclusion of the
+// confirmation's log in the parent chain against a trusted block header.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/OffchainLabs/bold/util/stopwaiter"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader holds the hex encoded signature of the request body, made over its
+	// EIP-191 personal message hash.
+	SignatureHeader = "X-Bold-Signature"
+	// SignerHeader holds the address of the operator key the request body is signed with.
+	SignerHeader = "X-Bold-Signer"
+
+	defaultMaxAttempts   = 5
+	defaultRetryInterval = 5 * time.Second
+	defaultQueueSize     = 1024
+	defaultTimeout       = 10 * time.Second
+)
+
+var (
+	deliveredCounter = metrics.NewRegisteredCounter("arb/validator/webhooks/delivered", nil)
+	failedCounter    = metrics.NewRegisteredCounter("arb/validator/webhooks/failed", nil)
+	droppedCounter   = metrics.NewRegisteredCounter("arb/validator/webhooks/dropped", nil)
+)
+
+type EventKind string
+
+const (
+	AssertionConfirmed EventKind = "assertion_confirmed"
+	EdgeConfirmed      EventKind = "edge_confirmed"
+	// A periodic digest of dispute activity, sent by the api/digest package.
+	Digest EventKind = "digest"
+)
+
+// Event is the body of a webhook request. Events may be delivered more than once, such as when
+// the validator restarts, so receivers should deduplicate them by the transaction hash and log
+// index of their inclusion data.
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// Whether the confirmed assertion or edge is one the validator agrees with, rather than a
+	// rival's.
+	Ours          bool        `json:"ours"`
+	AssertionHash common.Hash `json:"assertionHash"`
+	// Set for edge confirmations, which are either by "time" or by "one_step_proof".
+	EdgeId         *common.Hash `json:"edgeId,omitempty"`
+	ChallengeLevel *uint8       `json:"challengeLevel,omitempty"`
+	ConfirmedBy    string       `json:"confirmedBy,omitempty"`
+	Inclusion      *Inclusion   `json:"inclusion"`
+	Timestamp      time.Time    `json:"timestamp"`
+}
+
+// Inclusion identifies the log emitted by a confirmation. A receiver can check it by fetching the
+// receipt of the transaction from a node it trusts, or prove it against the receipts root of a
+// trusted header of the block.
+type Inclusion struct {
+	BlockNumber  uint64         `json:"blockNumber"`
+	BlockHash    common.Hash    `json:"blockHash"`
+	ReceiptsRoot common.Hash    `json:"receiptsRoot"`
+	TxHash       common.Hash    `json:"txHash"`
+	TxIndex      uint           `json:"txIndex"`
+	LogIndex     uint           `json:"logIndex"`
+	Address      common.Address `json:"address"`
+	Topics       []common.Hash  `json:"topics"`
+	Data         hexutil.Bytes  `json:"data"`
+}
+
+// Signer signs the bodies of webhook requests with the operator key.
+type Signer interface {
+	Address() common.Address
+	Sign(digest []byte) ([]byte, error)
+}
+
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner signs webhook requests with a private key.
+func NewKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key: key}
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) Sign(digest []byte) ([]byte, error) {
+	return crypto.Sign(digest, s.key)
+}
+
+// RecoverSigner returns the address which signed a webhook request body, for receivers to check
+// against the operator's address.
+func RecoverSigner(body []byte, signature []byte) (common.Address, error) {
+	pubKey, err := crypto.SigToPub(accounts.TextHash(body), signature)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "could not recover webhook signer")
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// The reads needed to fetch the inclusion data of a confirmation's log.
+type inclusionReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// A notification waiting to be delivered, whose inclusion data is fetched on delivery.
+type pending struct {
+	event    *Event
+	txHash   common.Hash
+	logIndex uint
+}
+
+// Dispatcher delivers events to a set of webhook endpoints in the background, retrying failed
+// deliveries. Notifying a dispatcher never blocks: events are dropped if its queue is full.
+type Dispatcher struct {
+	stopwaiter.StopWaiter
+	urls          []string
+	signer        Signer
+	backend       inclusionReader
+	client        *http.Client
+	queue         chan *pending
+	maxAttempts   int
+	retryInterval time.Duration
+	edges         *eventbus.Subscription[eventbus.EdgeEvent]
+	assertions    *eventbus.Subscription[eventbus.AssertionEvent]
+}
+
+type Opt func(*Dispatcher)
+
+// WithRetries sets how many times a delivery to an endpoint is attempted, and the wait between
+// attempts. Defaults to 5 attempts, 5 seconds apart.
+func WithRetries(maxAttempts int, interval time.Duration) Opt {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.retryInterval = interval
+	}
+}
+
+// WithHTTPClient sets the client requests are made with.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithEventBus notifies the assertion and edge confirmations published to the event bus.
+func WithEventBus(bus *eventbus.Bus) Opt {
+	return func(d *Dispatcher) {
+		d.edges = bus.Edges.Subscribe("webhooks")
+		d.assertions = bus.Assertions.Subscribe("webhooks")
+	}
+}
+
+func New(urls []string, signer Signer, backend inclusionReader, opts ...Opt) (*Dispatcher, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no webhook urls given")
+	}
+	if signer == nil {
+		return nil, errors.New("webhooks must be signed")
+	}
+	d := &Dispatcher{
+		urls:          urls,
+		signer:        signer,
+		backend:       backend,
+		client:        &http.Client{Timeout: defaultTimeout},
+		queue:         make(chan *pending, defaultQueueSize),
+		maxAttempts:   defaultMaxAttempts,
+		retryInterval: defaultRetryInterval,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d, nil
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.StopWaiter.Start(ctx, d)
+	d.LaunchThread(d.deliverQueued)
+	if d.edges != nil {
+		d.LaunchThread(d.notifyConfirmedEdges)
+	}
+	if d.assertions != nil {
+		d.LaunchThread(d.notifyConfirmedAssertions)
+	}
+}
+
+func (d *Dispatcher) notifyConfirmedEdges(ctx context.Context) {
+	defer d.edges.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-d.edges.Events():
+			var confirmedBy string
+			switch ev.Kind {
+			case eventbus.EdgeConfirmedByTime:
+				confirmedBy = "time"
+			case eventbus.EdgeConfirmedByOneStepProof:
+				confirmedBy = "one_step_proof"
+			default:
+				continue
+			}
+			edgeId := ev.EdgeId.Hash
+			level := ev.Level.Uint8()
+			d.Notify(&Event{
+				Kind:           EdgeConfirmed,
+				Ours:           ev.Royal,
+				AssertionHash:  ev.AssertionHash.Hash,
+				EdgeId:         &edgeId,
+				ChallengeLevel: &level,
+				ConfirmedBy:    confirmedBy,
+			}, ev.TxHash, ev.LogIndex)
+		}
+	}
+}
+
+func (d *Dispatcher) notifyConfirmedAssertions(ctx context.Context) {
+	defer d.assertions.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-d.assertions.Events():
+			if ev.Kind != eventbus.AssertionConfirmed {
+				continue
+			}
+			d.Notify(&Event{
+				Kind:          AssertionConfirmed,
+				Ours:          ev.Canonical,
+				AssertionHash: ev.AssertionHash.Hash,
+			}, ev.TxHash, ev.LogIndex)
+		}
+	}
+}
+
+// Notify queues an event for delivery, along with the transaction hash and log index of the
+// confirmation's log, from which its inclusion data is fetched. Notifying a nil dispatcher, when
+// no webhooks are configured, does nothing.
+func (d *Dispatcher) Notify(ev *Event, txHash common.Hash, logIndex uint) {
+	if d == nil {
+		return
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+	select {
+	case d.queue <- &pending{event: ev, txHash: txHash, logIndex: logIndex}:
+	default:
+		droppedCounter.Inc(1)
+		log.Error("Webhook queue is full, dropping event", "kind", ev.Kind, "txHash", txHash)
+	}
+}
+
+func (d *Dispatcher) deliverQueued(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-d.queue:
+			if err := d.deliver(ctx, p); err != nil {
+				log.Error("Could not deliver webhook", "kind", p.event.Kind, "txHash", p.txHash, "err", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, p *pending) error {
+	inclusion, err := attempt(ctx, d.maxAttempts, d.retryInterval, func() (*Inclusion, error) {
+		return FetchInclusion(ctx, d.backend, p.txHash, p.logIndex)
+	})
+	if err != nil {
+		failedCounter.Inc(1)
+		return errors.Wrap(err, "could not fetch inclusion data")
+	}
+	p.event.Inclusion = inclusion
+	body, err := json.Marshal(p.event)
+	if err != nil {
+		return err
+	}
+	d.deliverToAll(ctx, p.event.Kind, body)
+	return nil
+}
+
+// Send signs and delivers a payload other than a confirmation, such as a digest of dispute
+// activity, to every endpoint right away, retrying failed deliveries. It returns an error if the
+// payload could not be delivered to any endpoint.
+func (d *Dispatcher) Send(ctx context.Context, kind EventKind, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if d.deliverToAll(ctx, kind, body) == 0 {
+		return fmt.Errorf("could not deliver %s webhook to any endpoint", kind)
+	}
+	return nil
+}
+
+// Signs a body and delivers it to every endpoint, returning the number it was delivered to.
+func (d *Dispatcher) deliverToAll(ctx context.Context, kind EventKind, body []byte) int {
+	signature, err := d.signer.Sign(accounts.TextHash(body))
+	if err != nil {
+		failedCounter.Inc(1)
+		log.Error("Could not sign webhook", "kind", kind, "err", err)
+		return 0
+	}
+	delivered := 0
+	for _, url := range d.urls {
+		if _, err := attempt(ctx, d.maxAttempts, d.retryInterval, func() (struct{}, error) {
+			return struct{}{}, d.post(ctx, url, body, signature)
+		}); err != nil {
+			failedCounter.Inc(1)
+			log.Error("Could not deliver webhook", "url", url, "kind", kind, "err", err)
+			continue
+		}
+		deliveredCounter.Inc(1)
+		delivered++
+	}
+	return delivered
+}
+
+// Attempts fn up to a maximum number of times, returning the last error if no attempt succeeds.
+func attempt[T any](ctx context.Context, maxAttempts int, interval time.Duration, fn func() (T, error)) (T, error) {
+	var result T
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+		if result, err = fn(); err == nil {
+			return result, nil
+		}
+	}
+	return result, err
+}
+
+func (d *Dispatcher) post(ctx context.Context, url string, body []byte, signature []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, hexutil.Encode(signature))
+	req.Header.Set(SignerHeader, d.signer.Address().Hex())
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FetchInclusion gets the inclusion data of the log at an index of a transaction's receipt.
+func FetchInclusion(ctx context.Context, backend inclusionReader, txHash common.Hash, logIndex uint) (*Inclusion, error) {
+	receipt, err := backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get receipt of transaction %#x", txHash)
+	}
+	var emitted *types.Log
+	for _, l := range receipt.Logs {
+		if l.Index == logIndex {
+			emitted = l
+			break
+		}
+	}
+	if emitted == nil {
+		return nil, fmt.Errorf("transaction %#x has no log with index %d", txHash, logIndex)
+	}
+	header, err := backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get header of block %d", receipt.BlockNumber)
+	}
+	// The block may have been reorged out since the receipt was read.
+	if header.Hash() != receipt.BlockHash {
+		return nil, fmt.Errorf("block %d of transaction %#x is no longer canonical", receipt.BlockNumber, txHash)
+	}
+	return &Inclusion{
+		BlockNumber:  receipt.BlockNumber.Uint64(),
+		BlockHash:    receipt.BlockHash,
+		ReceiptsRoot: header.ReceiptHash,
+		TxHash:       txHash,
+		TxIndex:      receipt.TransactionIndex,
+		LogIndex:     logIndex,
+		Address:      emitted.Address,
+		Topics:       emitted.Topics,
+		Data:         emitted.Data,
+	}, nil
+}
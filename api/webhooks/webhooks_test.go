@@ -0,0 +1,203 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedWebhook struct {
+	body      []byte
+	signature []byte
+	signer    common.Address
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var lock sync.Mutex
+	attempts := 0
+	received := make(chan receivedWebhook, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		attempts++
+		failing := attempts == 1
+		lock.Unlock()
+		// The first delivery fails and is retried.
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		signature, err := hexutil.Decode(r.Header.Get(SignatureHeader))
+		require.NoError(t, err)
+		received <- receivedWebhook{
+			body:      body,
+			signature: signature,
+			signer:    common.HexToAddress(r.Header.Get(SignerHeader)),
+		}
+	}))
+	defer srv.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewKeySigner(key)
+	backend := newFakeInclusionBackend()
+	d, err := New([]string{srv.URL}, signer, backend, WithRetries(3, time.Millisecond))
+	require.NoError(t, err)
+	d.Start(ctx)
+	defer d.StopAndWait()
+
+	edgeId := common.BytesToHash([]byte("edge"))
+	d.Notify(&Event{
+		Kind:          EdgeConfirmed,
+		Ours:          true,
+		AssertionHash: common.BytesToHash([]byte("assertion")),
+		EdgeId:        &edgeId,
+		ConfirmedBy:   "time",
+	}, backend.txHash, 1)
+
+	var got receivedWebhook
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	require.Equal(t, signer.Address(), got.signer)
+	recovered, err := RecoverSigner(got.body, got.signature)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), recovered)
+
+	ev := &Event{}
+	require.NoError(t, json.Unmarshal(got.body, ev))
+	require.Equal(t, EdgeConfirmed, ev.Kind)
+	require.Equal(t, edgeId, *ev.EdgeId)
+	require.Equal(t, backend.header.Hash(), ev.Inclusion.BlockHash)
+	require.Equal(t, backend.header.ReceiptHash, ev.Inclusion.ReceiptsRoot)
+	require.Equal(t, uint(3), ev.Inclusion.TxIndex)
+	require.Equal(t, uint(1), ev.Inclusion.LogIndex)
+	require.Equal(t, []common.Hash{common.BytesToHash([]byte("topic"))}, ev.Inclusion.Topics)
+}
+
+func TestDispatcher_NotifiesConfirmationsFromEventBus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan *Event, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ev := &Event{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(ev))
+		received <- ev
+	}))
+	defer srv.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	backend := newFakeInclusionBackend()
+	bus := eventbus.New()
+	d, err := New([]string{srv.URL}, NewKeySigner(key), backend, WithEventBus(bus))
+	require.NoError(t, err)
+	d.Start(ctx)
+	defer d.StopAndWait()
+
+	edgeId := protocol.EdgeId{Hash: common.BytesToHash([]byte("edge"))}
+	// Edge additions are not notified.
+	bus.PublishEdge(eventbus.EdgeEvent{Kind: eventbus.EdgeAdded, EdgeId: edgeId, TxHash: backend.txHash, LogIndex: 1})
+	bus.PublishEdge(eventbus.EdgeEvent{
+		Kind:     eventbus.EdgeConfirmedByOneStepProof,
+		EdgeId:   edgeId,
+		Level:    protocol.ChallengeLevel(2),
+		Royal:    true,
+		TxHash:   backend.txHash,
+		LogIndex: 1,
+	})
+	bus.PublishAssertion(eventbus.AssertionEvent{
+		Kind:          eventbus.AssertionConfirmed,
+		AssertionHash: protocol.AssertionHash{Hash: common.BytesToHash([]byte("assertion"))},
+		TxHash:        backend.txHash,
+		LogIndex:      1,
+	})
+
+	got := make(map[EventKind]*Event)
+	for len(got) < 2 {
+		select {
+		case ev := <-received:
+			got[ev.Kind] = ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhooks were not delivered")
+		}
+	}
+	require.Equal(t, edgeId.Hash, *got[EdgeConfirmed].EdgeId)
+	require.Equal(t, uint8(2), *got[EdgeConfirmed].ChallengeLevel)
+	require.Equal(t, "one_step_proof", got[EdgeConfirmed].ConfirmedBy)
+	require.True(t, got[EdgeConfirmed].Ours)
+	require.Equal(t, common.BytesToHash([]byte("assertion")), got[AssertionConfirmed].AssertionHash)
+	require.False(t, got[AssertionConfirmed].Ours)
+}
+
+func TestFetchInclusion(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeInclusionBackend()
+	_, err := FetchInclusion(ctx, backend, backend.txHash, 5)
+	require.ErrorContains(t, err, "no log with index 5")
+
+	// A receipt of a block which is no longer canonical is not included.
+	backend.header = &types.Header{Number: big.NewInt(10), ReceiptHash: common.BytesToHash([]byte("reorged"))}
+	_, err = FetchInclusion(ctx, backend, backend.txHash, 1)
+	require.ErrorContains(t, err, "no longer canonical")
+}
+
+func TestNotify_NilDispatcher(t *testing.T) {
+	var d *Dispatcher
+	d.Notify(&Event{Kind: AssertionConfirmed}, common.Hash{}, 0)
+}
+
+type fakeInclusionBackend struct {
+	txHash  common.Hash
+	header  *types.Header
+	receipt *types.Receipt
+}
+
+func newFakeInclusionBackend() *fakeInclusionBackend {
+	header := &types.Header{Number: big.NewInt(10), ReceiptHash: common.BytesToHash([]byte("receipts"))}
+	txHash := common.BytesToHash([]byte("tx"))
+	return &fakeInclusionBackend{
+		txHash: txHash,
+		header: header,
+		receipt: &types.Receipt{
+			TxHash:           txHash,
+			BlockHash:        header.Hash(),
+			BlockNumber:      header.Number,
+			TransactionIndex: 3,
+			Logs: []*types.Log{
+				{Index: 0},
+				{Index: 1, Topics: []common.Hash{common.BytesToHash([]byte("topic"))}},
+			},
+		},
+	}
+}
+
+func (b *fakeInclusionBackend) TransactionReceipt(_ context.Context, _ common.Hash) (*types.Receipt, error) {
+	return b.receipt, nil
+}
+
+func (b *fakeInclusionBackend) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	return b.header, nil
+}
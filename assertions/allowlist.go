@@ -0,0 +1,90 @@
+package assertions
+
+import (
+	"context"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotAllowlisted = errors.New("validator address is not in the rollup's allowlist")
+
+	validatorAllowlistedGauge = metrics.NewRegisteredGauge("arb/validator/allowlist/allowed", nil)
+	validatorRemovedCounter   = metrics.NewRegisteredCounter("arb/validator/allowlist/removed", nil)
+)
+
+// Reads the rollup's validator allowlist and caches whether our address is allowed in it,
+// alerting if our address was allowed before and no longer is.
+func (m *Manager) refreshValidatorAllowlist(ctx context.Context) (*protocol.ValidatorAllowlist, error) {
+	allowlist, err := m.chain.ValidatorAllowlist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	staker := m.chain.StakerAddress()
+	allowed := allowlist.IsAllowed(staker)
+	m.validatorAllowedLock.Lock()
+	wasAllowed := m.validatorAllowed
+	m.validatorAllowed = &allowed
+	m.validatorAllowedLock.Unlock()
+	if allowed {
+		validatorAllowlistedGauge.Update(1)
+	} else {
+		validatorAllowlistedGauge.Update(0)
+	}
+	if !allowed && (wasAllowed == nil || *wasAllowed) {
+		if wasAllowed != nil {
+			validatorRemovedCounter.Inc(1)
+		}
+		log.Error(
+			"Validator address is not in the rollup's allowlist, will not post or confirm assertions",
+			"address", staker,
+			"validatorName", m.validatorName,
+		)
+	}
+	if allowed && wasAllowed != nil && !*wasAllowed {
+		log.Info("Validator address was added to the rollup's allowlist", "address", staker, "validatorName", m.validatorName)
+	}
+	return allowlist, nil
+}
+
+// Checks if our address may post assertions by reading the latest allowlist from the chain.
+func (m *Manager) checkValidatorAllowlisted(ctx context.Context) error {
+	allowlist, err := m.refreshValidatorAllowlist(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not read validator allowlist")
+	}
+	if !allowlist.IsAllowed(m.chain.StakerAddress()) {
+		return errors.Wrapf(ErrNotAllowlisted, "address %#x", m.chain.StakerAddress())
+	}
+	return nil
+}
+
+// Checks if our address was allowed the last time the allowlist was read. If it has not been read yet,
+// we optimistically assume our address is allowed, as the chain will reject the action otherwise.
+func (m *Manager) lastKnownValidatorAllowlisted() bool {
+	m.validatorAllowedLock.RLock()
+	defer m.validatorAllowedLock.RUnlock()
+	return m.validatorAllowed == nil || *m.validatorAllowed
+}
+
+func (m *Manager) monitorValidatorAllowlist(ctx context.Context) {
+	if _, err := m.refreshValidatorAllowlist(ctx); err != nil {
+		log.Error("Could not read validator allowlist", "err", err)
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.refreshValidatorAllowlist(ctx); err != nil {
+				log.Error("Could not read validator allowlist", "err", err)
+			}
+		}
+	}
+}
@@ -10,6 +10,11 @@ import (
 	"github.com/OffchainLabs/bold/challenge-manager/types"
 	"github.com/OffchainLabs/bold/containers/option"
 	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -31,14 +36,14 @@ func (m *Manager) keepTryingAssertionConfirmation(ctx context.Context, assertion
 	}
 	creationInfo, err := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
 		return m.chain.ReadAssertionCreationInfo(ctx, assertionHash)
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
 	if err != nil {
 		log.Error("Could not get assertion creation info", "err", err)
 		return
 	}
 	prevCreationInfo, err := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
 		return m.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: creationInfo.ParentAssertionHash})
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
 	if err != nil {
 		log.Error("Could not get prev assertion creation info", "err", err)
 		return
@@ -64,6 +69,10 @@ func (m *Manager) keepTryingAssertionConfirmation(ctx context.Context, assertion
 			if parentAssertionHasSecondChild {
 				return
 			}
+			// Rollups with an allowlist only accept confirmations from validators in it.
+			if !m.lastKnownValidatorAllowlisted() {
+				continue
+			}
 			confirmed, err := solimpl.TryConfirmingAssertion(ctx, creationInfo.AssertionHash, prevCreationInfo.ConfirmPeriodBlocks+creationInfo.CreationBlock, m.chain, m.averageTimeForBlockCreation, option.None[protocol.EdgeId]())
 			if err != nil {
 				if !strings.Contains(err.Error(), "PREV_NOT_LATEST_CONFIRMED") {
@@ -102,3 +111,70 @@ func (m *Manager) updateLatestConfirmedMetrics(ctx context.Context) {
 		}
 	}
 }
+
+// Publishes the assertions confirmed after startup to the event bus, scanning for confirmation
+// events each confirmation attempt interval.
+func (m *Manager) publishConfirmedAssertions(ctx context.Context) {
+	filterer, err := rollupgen.NewRollupCoreFilterer(m.rollupAddr, m.backend)
+	if err != nil {
+		log.Error("Could not create rollup filterer to publish assertion confirmations", "err", err)
+		return
+	}
+	header, err := retry.UntilSucceeds(ctx, func() (*gethtypes.Header, error) {
+		return m.backend.HeaderByNumber(ctx, nil)
+	}, retry.WithPolicy(retry.ConfirmationCritical))
+	if err != nil {
+		return
+	}
+	fromBlock := header.Number.Uint64() + 1
+	ticker := time.NewTicker(m.confirmationAttemptInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			latest, err := m.backend.HeaderByNumber(ctx, nil)
+			if err != nil {
+				log.Debug("Could not get latest header to scan for assertion confirmations", "err", err)
+				continue
+			}
+			toBlock := latest.Number.Uint64()
+			if toBlock < fromBlock {
+				continue
+			}
+			if err := m.publishConfirmedAssertionsInRange(ctx, filterer, fromBlock, toBlock); err != nil {
+				log.Error("Could not scan for assertion confirmations", "fromBlock", fromBlock, "toBlock", toBlock, "err", err)
+				continue
+			}
+			fromBlock = toBlock + 1
+		}
+	}
+}
+
+func (m *Manager) publishConfirmedAssertionsInRange(
+	ctx context.Context,
+	filterer *rollupgen.RollupCoreFilterer,
+	fromBlock,
+	toBlock uint64,
+) error {
+	it, err := filterer.FilterAssertionConfirmed(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: ctx}, nil)
+	if err != nil {
+		return err
+	}
+	return iterators.ForEachEvent[*rollupgen.RollupCoreAssertionConfirmed](it, func(event *rollupgen.RollupCoreAssertionConfirmed) error {
+		assertionHash := protocol.AssertionHash{Hash: event.AssertionHash}
+		m.assertionChainData.RLock()
+		_, ours := m.assertionChainData.canonicalAssertions[assertionHash]
+		m.assertionChainData.RUnlock()
+		m.eventBus.PublishAssertion(eventbus.AssertionEvent{
+			Kind:          eventbus.AssertionConfirmed,
+			AssertionHash: assertionHash,
+			Canonical:     ours,
+			BlockNumber:   event.Raw.BlockNumber,
+			TxHash:        event.Raw.TxHash,
+			LogIndex:      event.Raw.Index,
+		})
+		return nil
+	})
+}
@@ -0,0 +1,271 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package assertions
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	finalizedAssertionCounter        = metrics.NewRegisteredCounter("arb/validator/scanner/finalized_assertion", nil)
+	finalizationHandlerErrorsCounter = metrics.NewRegisteredCounter("arb/validator/scanner/finalization_handler_errors", nil)
+)
+
+// Resolution is how an assertion came to be confirmed.
+type Resolution uint8
+
+const (
+	// The assertion had no rival and was confirmed once its confirmation period passed.
+	ResolvedByTime Resolution = iota
+	// The assertion won the challenge against its rivals.
+	ResolvedByChallenge
+)
+
+func (r Resolution) String() string {
+	switch r {
+	case ResolvedByTime:
+		return "by_time"
+	case ResolvedByChallenge:
+		return "by_challenge"
+	default:
+		return "unknown"
+	}
+}
+
+// FinalizedAssertion is an assertion confirmed beyond challenge, with the context downstream
+// consumers need to act on it.
+type FinalizedAssertion struct {
+	AssertionHash       protocol.AssertionHash
+	ParentAssertionHash protocol.AssertionHash
+	// The execution state claimed by the assertion, whose block hash and send root are now final.
+	ExecutionState *protocol.ExecutionState
+	InboxMaxCount  *big.Int
+	WasmModuleRoot common.Hash
+	CreationBlock  uint64
+	Resolution     Resolution
+	// The block challenge edge that won the challenge on the assertion, known if it was resolved
+	// by challenge and confirmed by a call to the rollup itself.
+	WinningEdge option.Option[protocol.EdgeId]
+	// Whether the assertion agrees with our state provider.
+	Canonical bool
+	// Identifies the log of the confirmation.
+	ConfirmedAtBlock uint64
+	TxHash           common.Hash
+	LogIndex         uint
+}
+
+// FinalizationHandler is notified of each assertion confirmed beyond challenge, letting embedders
+// such as bridges and withdrawal processors trigger their own logic off confirmations.
+type FinalizationHandler interface {
+	Name() string
+	// AssertionFinalized is retried until it succeeds, and may see an assertion again after a
+	// restart, so handlers should be idempotent by assertion hash.
+	AssertionFinalized(ctx context.Context, assertion *FinalizedAssertion) error
+}
+
+// WithFinalizationHandler notifies a handler of the assertions confirmed after startup, once
+// their confirmation is at or below the block the assertion chain reads at. Handlers are
+// notified in the order assertions were confirmed, one at a time.
+func WithFinalizationHandler(handler FinalizationHandler) Opt {
+	return func(m *Manager) {
+		m.finalizationHandlers = append(m.finalizationHandlers, handler)
+	}
+}
+
+// WithFinalizationFromBlock notifies finalization handlers of the assertions confirmed from a
+// block on, such as the block after the last one they processed before a restart, rather than of
+// those confirmed after startup.
+func WithFinalizationFromBlock(block uint64) Opt {
+	return func(m *Manager) {
+		m.finalizationFromBlock = option.Some(block)
+	}
+}
+
+// Notifies finalization handlers of confirmed assertions, scanning for confirmation events each
+// confirmation attempt interval up to the block the assertion chain reads at.
+func (m *Manager) notifyFinalizedAssertions(ctx context.Context) {
+	filterer, err := rollupgen.NewRollupCoreFilterer(m.rollupAddr, m.backend)
+	if err != nil {
+		log.Error("Could not create rollup filterer to notify of finalized assertions", "err", err)
+		return
+	}
+	fromBlock := m.finalizationFromBlock
+	ticker := time.NewTicker(m.confirmationAttemptInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			head, err := m.backend.HeaderByNumber(ctx, m.chain.GetDesiredRpcHeadBlockNumber())
+			if err != nil {
+				log.Debug("Could not get header to scan for finalized assertions", "err", err)
+				continue
+			}
+			toBlock := head.Number.Uint64()
+			if fromBlock.IsNone() {
+				fromBlock = option.Some(toBlock + 1)
+				continue
+			}
+			if toBlock < fromBlock.Unwrap() {
+				continue
+			}
+			if err := m.notifyFinalizedAssertionsInRange(ctx, filterer, fromBlock.Unwrap(), toBlock); err != nil {
+				log.Error("Could not scan for finalized assertions", "fromBlock", fromBlock.Unwrap(), "toBlock", toBlock, "err", err)
+				continue
+			}
+			fromBlock = option.Some(toBlock + 1)
+		}
+	}
+}
+
+func (m *Manager) notifyFinalizedAssertionsInRange(
+	ctx context.Context,
+	filterer *rollupgen.RollupCoreFilterer,
+	fromBlock,
+	toBlock uint64,
+) error {
+	it, err := filterer.FilterAssertionConfirmed(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: ctx}, nil)
+	if err != nil {
+		return err
+	}
+	return iterators.ForEachEvent[*rollupgen.RollupCoreAssertionConfirmed](it, func(event *rollupgen.RollupCoreAssertionConfirmed) error {
+		finalized, err := retry.UntilSucceeds(ctx, func() (*FinalizedAssertion, error) {
+			return m.finalizedAssertion(ctx, event)
+		}, retry.WithPolicy(retry.ConfirmationCritical))
+		if err != nil {
+			return err
+		}
+		finalizedAssertionCounter.Inc(1)
+		log.Info(
+			"Assertion finalized",
+			"assertionHash", finalized.AssertionHash.Hash,
+			"resolution", finalized.Resolution,
+			"canonical", finalized.Canonical,
+			"blockHash", finalized.ExecutionState.GlobalState.BlockHash,
+		)
+		return notifyFinalizationHandlers(ctx, m.finalizationHandlers, finalized)
+	})
+}
+
+func (m *Manager) finalizedAssertion(ctx context.Context, event *rollupgen.RollupCoreAssertionConfirmed) (*FinalizedAssertion, error) {
+	assertionHash := protocol.AssertionHash{Hash: event.AssertionHash}
+	creationInfo, err := m.chain.ReadAssertionCreationInfo(ctx, assertionHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read creation info of assertion %#x", assertionHash.Hash)
+	}
+	parentHash := protocol.AssertionHash{Hash: creationInfo.ParentAssertionHash}
+	tx, _, err := m.chain.Backend().TransactionByHash(ctx, event.Raw.TxHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get confirmation transaction %#x", event.Raw.TxHash)
+	}
+	resolution := ResolvedByTime
+	winningEdge, decoded := confirmationWinningEdge(tx, m.rollupAddr)
+	if decoded {
+		if winningEdge.IsSome() {
+			resolution = ResolvedByChallenge
+		}
+	} else {
+		// The rollup was called by another contract, so tell whether the assertion was challenged
+		// by whether it had a rival.
+		parent, err := m.chain.GetAssertion(ctx, parentHash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get parent assertion %#x", parentHash.Hash)
+		}
+		challenged, err := parent.HasSecondChild()
+		if err != nil {
+			return nil, err
+		}
+		if challenged {
+			resolution = ResolvedByChallenge
+		}
+	}
+	m.assertionChainData.RLock()
+	_, canonical := m.assertionChainData.canonicalAssertions[assertionHash]
+	m.assertionChainData.RUnlock()
+	return &FinalizedAssertion{
+		AssertionHash:       assertionHash,
+		ParentAssertionHash: parentHash,
+		ExecutionState:      protocol.GoExecutionStateFromSolidity(creationInfo.AfterState),
+		InboxMaxCount:       creationInfo.InboxMaxCount,
+		WasmModuleRoot:      creationInfo.WasmModuleRoot,
+		CreationBlock:       creationInfo.CreationBlock,
+		Resolution:          resolution,
+		WinningEdge:         winningEdge,
+		Canonical:           canonical,
+		ConfirmedAtBlock:    event.Raw.BlockNumber,
+		TxHash:              event.Raw.TxHash,
+		LogIndex:            event.Raw.Index,
+	}, nil
+}
+
+// Decodes the winning edge a rollup was called to confirm an assertion with, which is none for
+// assertions confirmed by time. Returns false if the transaction did not call the rollup to
+// confirm an assertion.
+func confirmationWinningEdge(tx *gethtypes.Transaction, rollupAddr common.Address) (option.Option[protocol.EdgeId], bool) {
+	if tx.To() == nil || *tx.To() != rollupAddr || len(tx.Data()) < 4 {
+		return option.None[protocol.EdgeId](), false
+	}
+	rollupAbi, err := rollupgen.RollupUserLogicMetaData.GetAbi()
+	if err != nil {
+		return option.None[protocol.EdgeId](), false
+	}
+	method, err := rollupAbi.MethodById(tx.Data()[:4])
+	if err != nil || method.Name != "confirmAssertion" {
+		return option.None[protocol.EdgeId](), false
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil || len(args) < 4 {
+		return option.None[protocol.EdgeId](), false
+	}
+	winningEdgeId, ok := args[3].([32]byte)
+	if !ok {
+		return option.None[protocol.EdgeId](), false
+	}
+	if winningEdgeId == ([32]byte{}) {
+		return option.None[protocol.EdgeId](), true
+	}
+	return option.Some(protocol.EdgeId{Hash: winningEdgeId}), true
+}
+
+// Notifies each handler of a finalized assertion in turn, retrying a handler until it succeeds.
+func notifyFinalizationHandlers(ctx context.Context, handlers []FinalizationHandler, finalized *FinalizedAssertion) error {
+	for _, h := range handlers {
+		if _, err := retry.UntilSucceeds(ctx, func() (struct{}, error) {
+			if err := callFinalizationHandler(ctx, h, finalized); err != nil {
+				finalizationHandlerErrorsCounter.Inc(1)
+				return struct{}{}, errors.Wrapf(err, "finalization handler %s errored on assertion %#x", h.Name(), finalized.AssertionHash.Hash)
+			}
+			return struct{}{}, nil
+		}, retry.WithPolicy(retry.ConfirmationCritical)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func callFinalizationHandler(ctx context.Context, h FinalizationHandler, finalized *FinalizedAssertion) (err error) {
+	// A misbehaving handler must not bring down the validator.
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("handler panicked: %v", rec)
+		}
+	}()
+	return h.AssertionFinalized(ctx, finalized)
+}
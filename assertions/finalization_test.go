@@ -0,0 +1,109 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package assertions
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_confirmationWinningEdge(t *testing.T) {
+	rollupAbi, err := rollupgen.RollupUserLogicMetaData.GetAbi()
+	require.NoError(t, err)
+	rollupAddr := common.BytesToAddress([]byte("rollup"))
+	confirmTx := func(to common.Address, winningEdgeId common.Hash) *gethtypes.Transaction {
+		data, err := rollupAbi.Pack(
+			"confirmAssertion",
+			common.HexToHash("0x01"),
+			common.HexToHash("0x02"),
+			rollupgen.AssertionState{MachineStatus: protocol.MachineStatusFinished.Uint8()},
+			winningEdgeId,
+			rollupgen.ConfigData{RequiredStake: big.NewInt(1)},
+			common.HexToHash("0x03"),
+		)
+		require.NoError(t, err)
+		return gethtypes.NewTx(&gethtypes.LegacyTx{To: &to, Data: data})
+	}
+
+	t.Run("confirmed by time", func(t *testing.T) {
+		edge, decoded := confirmationWinningEdge(confirmTx(rollupAddr, common.Hash{}), rollupAddr)
+		require.True(t, decoded)
+		require.True(t, edge.IsNone())
+	})
+	t.Run("confirmed by challenge winner", func(t *testing.T) {
+		winner := common.HexToHash("0xabcd")
+		edge, decoded := confirmationWinningEdge(confirmTx(rollupAddr, winner), rollupAddr)
+		require.True(t, decoded)
+		require.Equal(t, protocol.EdgeId{Hash: winner}, edge.Unwrap())
+	})
+	t.Run("rollup called by another contract", func(t *testing.T) {
+		other := common.BytesToAddress([]byte("multisig"))
+		_, decoded := confirmationWinningEdge(confirmTx(other, common.HexToHash("0xabcd")), rollupAddr)
+		require.False(t, decoded)
+	})
+	t.Run("other method", func(t *testing.T) {
+		data, err := rollupAbi.Pack("withdrawStakerFunds")
+		require.NoError(t, err)
+		tx := gethtypes.NewTx(&gethtypes.LegacyTx{To: &rollupAddr, Data: data})
+		_, decoded := confirmationWinningEdge(tx, rollupAddr)
+		require.False(t, decoded)
+	})
+}
+
+type recordingFinalizationHandler struct {
+	name     string
+	failures int
+	panics   bool
+	seen     *[]string
+}
+
+func (h *recordingFinalizationHandler) Name() string {
+	return h.name
+}
+
+func (h *recordingFinalizationHandler) AssertionFinalized(_ context.Context, _ *FinalizedAssertion) error {
+	if h.panics {
+		h.panics = false
+		panic("bad plugin")
+	}
+	if h.failures > 0 {
+		h.failures--
+		return errors.New("unavailable")
+	}
+	*h.seen = append(*h.seen, h.name)
+	return nil
+}
+
+func Test_notifyFinalizationHandlers(t *testing.T) {
+	ctx := context.Background()
+	var seen []string
+	handlers := []FinalizationHandler{
+		&recordingFinalizationHandler{name: "bridge", failures: 1, seen: &seen},
+		&recordingFinalizationHandler{name: "withdrawals", panics: true, seen: &seen},
+	}
+	finalized := &FinalizedAssertion{AssertionHash: protocol.AssertionHash{Hash: common.HexToHash("0x01")}}
+	require.NoError(t, notifyFinalizationHandlers(ctx, handlers, finalized))
+	require.Equal(t, []string{"bridge", "withdrawals"}, seen)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err := notifyFinalizationHandlers(cancelled, []FinalizationHandler{
+		&recordingFinalizationHandler{name: "bridge", failures: 1, seen: &seen},
+	}, finalized)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestResolution_String(t *testing.T) {
+	require.Equal(t, "by_time", ResolvedByTime.String())
+	require.Equal(t, "by_challenge", ResolvedByChallenge.String())
+	require.Equal(t, "unknown", Resolution(2).String())
+}
@@ -13,14 +13,19 @@ import (
 	"sync"
 	"time"
 
+	"github.com/OffchainLabs/bold/util/eventbus"
 	"github.com/OffchainLabs/bold/util/stopwaiter"
 	"github.com/ethereum/go-ethereum/metrics"
 
+	"github.com/OffchainLabs/bold/api"
 	"github.com/OffchainLabs/bold/api/db"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
 	"github.com/OffchainLabs/bold/challenge-manager/types"
+	"github.com/OffchainLabs/bold/containers/option"
 	"github.com/OffchainLabs/bold/containers/threadsafe"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/util/intents"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -63,6 +68,7 @@ type Manager struct {
 	postInterval                time.Duration
 	submittedAssertions         *threadsafe.LruSet[common.Hash]
 	apiDB                       db.Database
+	stakeTotals                 map[string]*big.Int
 	assertionChainData          *assertionChainData
 	observedCanonicalAssertions chan protocol.AssertionHash
 	isReadyToPost               bool
@@ -70,12 +76,27 @@ type Manager struct {
 	startPostingSignal          chan struct{}
 	layerZeroHeightsCache       *protocol.LayerZeroHeights
 	layerZeroHeightsCacheLock   sync.RWMutex
+	batchAvailabilityChecker    l2stateprovider.BatchAvailabilityChecker
+	batchAvailabilityConfig     l2stateprovider.BatchAvailabilityConfig
+	validatorAllowed            *bool
+	validatorAllowedLock        sync.RWMutex
+	auditLog                    *db.AuditLogger
+	blockWindowReexecutor       l2stateprovider.BlockWindowReexecutor
+	onchainBatchAccumulators    l2stateprovider.BatchAccumulatorReader
+	eventBus                    *eventbus.Bus
+	hooks                       *hooks.Registry
+	intents                     *intents.Journal
+	siblingPolicy               types.SiblingPolicy
+	finalizationHandlers        []FinalizationHandler
+	finalizationFromBlock       option.Option[uint64]
 }
 
 type assertionChainData struct {
 	sync.RWMutex
 	latestAgreedAssertion protocol.AssertionHash
 	canonicalAssertions   map[protocol.AssertionHash]*protocol.AssertionCreatedInfo
+	// Siblings of canonical assertions whose states we agree with, which are not challenged.
+	agreedSiblings map[protocol.AssertionHash]*protocol.AssertionCreatedInfo
 }
 
 type Opt func(*Manager)
@@ -86,6 +107,55 @@ func WithPostingDisabled() Opt {
 	}
 }
 
+// WithBatchAvailabilityChecker ensures the batch data referenced by an assertion
+// is retrievable before the assertion is posted onchain.
+func WithBatchAvailabilityChecker(
+	checker l2stateprovider.BatchAvailabilityChecker,
+	cfg l2stateprovider.BatchAvailabilityConfig,
+) Opt {
+	return func(m *Manager) {
+		m.batchAvailabilityChecker = checker
+		m.batchAvailabilityConfig = cfg
+	}
+}
+
+// WithBlockWindowReexecution re-executes only the window of blocks around the first suspected
+// divergence from an assertion we disagree with, instead of its entire span, finding it by comparing
+// the batch accumulators of the local node against those onchain.
+func WithBlockWindowReexecution(
+	reexecutor l2stateprovider.BlockWindowReexecutor,
+	onchainAccumulators l2stateprovider.BatchAccumulatorReader,
+) Opt {
+	return func(m *Manager) {
+		m.blockWindowReexecutor = reexecutor
+		m.onchainBatchAccumulators = onchainAccumulators
+	}
+}
+
+// WithEventBus publishes the assertions we post, and the assertion confirmations observed after
+// startup, to the event bus.
+func WithEventBus(bus *eventbus.Bus) Opt {
+	return func(m *Manager) {
+		m.eventBus = bus
+	}
+}
+
+// WithHooks consults the hooks of a registry before posting a rival to an assertion we disagree
+// with. A rival vetoed by a hook is not posted.
+func WithHooks(registry *hooks.Registry) Opt {
+	return func(m *Manager) {
+		m.hooks = registry
+	}
+}
+
+// WithIntentJournal checks the assertions observed from our address against the journal of those
+// we intended to post, pausing posting on any we did not.
+func WithIntentJournal(journal *intents.Journal) Opt {
+	return func(m *Manager) {
+		m.intents = journal
+	}
+}
+
 func WithDangerousReadyToPost() Opt {
 	return func(m *Manager) {
 		m.isReadyToPost = true
@@ -136,6 +206,7 @@ func NewManager(
 		assertionChainData: &assertionChainData{
 			latestAgreedAssertion: protocol.AssertionHash{},
 			canonicalAssertions:   make(map[protocol.AssertionHash]*protocol.AssertionCreatedInfo),
+			agreedSiblings:        make(map[protocol.AssertionHash]*protocol.AssertionCreatedInfo),
 		},
 		observedCanonicalAssertions: make(chan protocol.AssertionHash, 1000),
 		isReadyToPost:               false,
@@ -144,6 +215,9 @@ func NewManager(
 	for _, o := range opts {
 		o(m)
 	}
+	if !api.IsNil(apiDB) {
+		m.auditLog = db.NewAuditLogger(apiDB, chain.StakerAddress().Hex())
+	}
 	return m, nil
 }
 
@@ -156,6 +230,13 @@ func (m *Manager) Start(ctx context.Context) {
 	m.LaunchThread(m.syncAssertions)
 	m.LaunchThread(m.queueCanonicalAssertionsForConfirmation)
 	m.LaunchThread(m.checkLatestDesiredBlock)
+	m.LaunchThread(m.monitorValidatorAllowlist)
+	if m.eventBus != nil {
+		m.LaunchThread(m.publishConfirmedAssertions)
+	}
+	if len(m.finalizationHandlers) > 0 {
+		m.LaunchThread(m.notifyFinalizedAssertions)
+	}
 }
 
 func (m *Manager) checkLatestDesiredBlock(ctx context.Context) {
@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/OffchainLabs/bold/api"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
 	"github.com/OffchainLabs/bold/challenge-manager/types"
 	"github.com/OffchainLabs/bold/containers"
 	"github.com/OffchainLabs/bold/containers/option"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/OffchainLabs/bold/util/intents"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/pkg/errors"
@@ -32,7 +35,7 @@ func (m *Manager) postAssertionRoutine(ctx context.Context) {
 	}
 	log.Info("Ready to post")
 	if _, err := m.PostAssertion(ctx); err != nil {
-		if !errors.Is(err, solimpl.ErrAlreadyExists) {
+		if !errors.Is(err, solimpl.ErrAlreadyExists) && !errors.Is(err, ErrNotAllowlisted) && !errors.Is(err, intents.ErrPaused) {
 			log.Error("Could not submit latest assertion to L1", "err", err)
 			errorPostingAssertionCounter.Inc(1)
 		}
@@ -48,6 +51,10 @@ func (m *Manager) postAssertionRoutine(ctx context.Context) {
 				case errors.Is(err, solimpl.ErrAlreadyExists):
 				case errors.Is(err, solimpl.ErrBatchNotYetFound):
 					log.Info("Waiting for more batches to post assertions about them onchain")
+				case errors.Is(err, ErrNotAllowlisted):
+					// Already alerted on when the allowlist was read.
+				case errors.Is(err, intents.ErrPaused):
+					log.Warn("Not posting assertions while paused after detecting split brain", "err", err, "validatorName", m.validatorName)
 				default:
 					log.Error("Could not submit latest assertion", "err", err, "validatorName", m.validatorName)
 					errorPostingAssertionCounter.Inc(1)
@@ -89,6 +96,10 @@ func (m *Manager) PostAssertion(ctx context.Context) (option.Option[*protocol.As
 			m.assertionChainData.latestAgreedAssertion.Hash,
 		)
 	}
+	// Rollups with an allowlist revert assertions posted by anyone else.
+	if err := m.checkValidatorAllowlisted(ctx); err != nil {
+		return none, err
+	}
 	staked, err := m.chain.IsStaked(ctx)
 	if err != nil {
 		return none, err
@@ -147,6 +158,17 @@ func (m *Manager) PostAssertionBasedOnParent(
 		return none, errors.Wrapf(err, "could not get execution state at batch count %d with parent block hash %v", batchCount, parentBlockHash)
 	}
 
+	// We must be able to later prove every step of the execution we are about to claim,
+	// so the data for all batches it consumes must be retrievable.
+	if err = l2stateprovider.EnsureBatchDataAvailable(
+		ctx,
+		m.batchAvailabilityChecker,
+		l2stateprovider.BatchCountForState(newState.GlobalState),
+		m.batchAvailabilityConfig,
+	); err != nil {
+		return none, err
+	}
+
 	// If the assertion is not an overflow assertion (has a 0 position in batch),
 	// then should check if we need to wait for the minimum number of blocks in between
 	// assertions. Overflow ones are not subject to this check onchain.
@@ -161,19 +183,30 @@ func (m *Manager) PostAssertionBasedOnParent(
 		"requiredInboxMaxCount", batchCount,
 		"validatorName", m.validatorName,
 	)
+	auditInputs := map[string]any{
+		"parentAssertionHash": parentCreationInfo.AssertionHash,
+		"postState":           newState,
+	}
 	assertion, err := submitFn(
 		ctx,
 		parentCreationInfo,
 		newState,
 	)
 	if err != nil {
+		m.auditLog.Record(api.AuditActionPostAssertion, auditInputs, nil, err)
 		return none, err
 	}
 	assertionPostedCounter.Inc(1)
+	auditResult := map[string]any{
+		"assertionHash": assertion.Id().Hash,
+	}
 	creationInfo, err := m.chain.ReadAssertionCreationInfo(ctx, assertion.Id())
 	if err != nil {
+		m.auditLog.Record(api.AuditActionPostAssertion, auditInputs, auditResult, nil)
 		return none, err
 	}
+	auditResult["transactionHash"] = creationInfo.TransactionHash
+	m.auditLog.Record(api.AuditActionPostAssertion, auditInputs, auditResult, nil)
 	log.Info("Successfully submitted assertion",
 		"validatorName", m.validatorName,
 		"requiredInboxMaxCount", batchCount,
@@ -182,6 +215,7 @@ func (m *Manager) PostAssertionBasedOnParent(
 		"transactionHash", creationInfo.TransactionHash,
 	)
 	m.observedCanonicalAssertions <- assertion.Id()
+	m.publishPostedAssertion(creationInfo)
 	return option.Some(creationInfo), nil
 }
 
@@ -221,3 +255,13 @@ func (m *Manager) waitToPostIfNeeded(
 	}
 	return nil
 }
+
+func (m *Manager) publishPostedAssertion(creationInfo *protocol.AssertionCreatedInfo) {
+	m.eventBus.PublishAssertion(eventbus.AssertionEvent{
+		Kind:          eventbus.AssertionPosted,
+		AssertionHash: protocol.AssertionHash{Hash: creationInfo.AssertionHash},
+		Canonical:     true,
+		BlockNumber:   creationInfo.CreationBlock,
+		TxHash:        creationInfo.TransactionHash,
+	})
+}
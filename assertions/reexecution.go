@@ -0,0 +1,56 @@
+package assertions
+
+import (
+	"context"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	blockWindowReexecutionCounter      = metrics.NewRegisteredCounter("arb/validator/scanner/block_window_reexecution", nil)
+	blockWindowReexecutionErrorCounter = metrics.NewRegisteredCounter("arb/validator/scanner/block_window_reexecution_error", nil)
+	blockWindowReexecutionTimer        = metrics.NewRegisteredTimer("arb/validator/scanner/block_window_reexecution_time", nil)
+)
+
+// Re-executes the window of blocks around the first suspected divergence from an assertion we
+// disagree with, so that the caches needed to challenge it are populated without re-executing its
+// entire span. Does nothing unless configured with a block window reexecutor.
+func (m *Manager) reexecuteDivergenceWindow(ctx context.Context, args rivalPosterArgs) error {
+	if m.blockWindowReexecutor == nil || m.onchainBatchAccumulators == nil {
+		return nil
+	}
+	expectedState, err := m.ExecutionStateAfterParent(ctx, args.canonicalParent)
+	if err != nil {
+		return errors.Wrap(err, "could not get expected execution state")
+	}
+	window, err := l2stateprovider.FindReexecutionWindow(
+		ctx,
+		m.blockWindowReexecutor,
+		m.onchainBatchAccumulators,
+		protocol.GoGlobalStateFromSolidity(args.canonicalParent.AfterState.GlobalState),
+		protocol.GoGlobalStateFromSolidity(args.invalidAssertion.AfterState.GlobalState),
+		expectedState.GlobalState,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not find block window to re-execute")
+	}
+	window.WasmModuleRoot = args.canonicalParent.WasmModuleRoot
+	window.ClaimId = args.invalidAssertion.AssertionHash
+	log.Info("Re-executing block window around suspected divergence from assertion",
+		"validatorName", m.validatorName,
+		"assertionHash", args.invalidAssertion.AssertionHash,
+		"window", window.String(),
+	)
+	start := time.Now()
+	if err = m.blockWindowReexecutor.ReexecuteBlockWindow(ctx, window); err != nil {
+		return errors.Wrapf(err, "could not re-execute block window %s", window)
+	}
+	blockWindowReexecutionTimer.UpdateSince(start)
+	blockWindowReexecutionCounter.Inc(1)
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package assertions
+
+import (
+	"context"
+	"sort"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var agreedSiblingCounter = metrics.NewRegisteredCounter("arb/validator/scanner/agreed_sibling_assertion", nil)
+
+// WithSiblingPolicy sets which branch to defend when another party posts a sibling of an assertion
+// we agree with whose state is equivalent to ours. Defaults to types.DefendExactSibling.
+func WithSiblingPolicy(policy types.SiblingPolicy) Opt {
+	return func(m *Manager) {
+		m.siblingPolicy = policy
+	}
+}
+
+// How the state of an assertion compares to the one we compute after its parent.
+type stateAgreement uint8
+
+const (
+	disagrees stateAgreement = iota
+	// The same block hash, send root, and machine status, at a different position in the inbox.
+	agreesEquivalent
+	agreesExactly
+)
+
+func compareStates(expected, actual *protocol.ExecutionState) stateAgreement {
+	if expected.Equals(actual) {
+		return agreesExactly
+	}
+	if expected.MachineStatus == actual.MachineStatus &&
+		expected.GlobalState.BlockHash == actual.GlobalState.BlockHash &&
+		expected.GlobalState.SendRoot == actual.GlobalState.SendRoot {
+		return agreesEquivalent
+	}
+	return disagrees
+}
+
+// Compares the state of an assertion to the one we compute after its parent, retrying until our
+// chain has caught up to it.
+func (m *Manager) agreementWithAssertion(ctx context.Context, fullInfo assertionAndParentCreationInfo) (stateAgreement, error) {
+	return retry.UntilSucceeds(ctx, func() (stateAgreement, error) {
+		expectedState, err := m.ExecutionStateAfterParent(ctx, fullInfo.parent)
+		switch {
+		case errors.Is(err, l2stateprovider.ErrChainCatchingUp):
+			// Otherwise, we return the error that we are still catching up to the
+			// execution state claimed by the assertion, and this function will be retried
+			// by the caller if wrapped in a retryable call.
+			chainCatchingUpCounter.Inc(1)
+			log.Info("Chain still syncing "+
+				"will reattempt processing when caught up", "err", err)
+			return disagrees, l2stateprovider.ErrChainCatchingUp
+		case err != nil:
+			return disagrees, err
+		}
+		return compareStates(expectedState, protocol.GoExecutionStateFromSolidity(fullInfo.assertion.AfterState)), nil
+	}, retry.WithPolicy(retry.StateProvider))
+}
+
+// Checks if an assertion with a given agreement becomes the branch we defend, when its parent is
+// the latest assertion we agree with.
+func (m *Manager) defendsAssertion(agreement stateAgreement) bool {
+	switch agreement {
+	case agreesExactly:
+		return true
+	case agreesEquivalent:
+		return m.siblingPolicy == types.DefendFirstSibling
+	default:
+		return false
+	}
+}
+
+// Checks if an assertion may be a sibling of the branch we defend that we have yet to compare
+// our state against. This function must hold the lock on m.assertionChainData.
+func (d *assertionChainData) mayBeAgreedSibling(assertion *protocol.AssertionCreatedInfo) bool {
+	if _, ok := d.canonicalAssertions[protocol.AssertionHash{Hash: assertion.ParentAssertionHash}]; !ok {
+		return false
+	}
+	if _, ok := d.canonicalAssertions[protocol.AssertionHash{Hash: assertion.AssertionHash}]; ok {
+		return false
+	}
+	_, ok := d.agreedSiblings[protocol.AssertionHash{Hash: assertion.AssertionHash}]
+	return !ok
+}
+
+// Records a sibling of the branch we defend whose state we agree with, so that it is not
+// challenged. This function must hold the lock on m.assertionChainData.
+func (m *Manager) recordAgreedSibling(assertion *protocol.AssertionCreatedInfo, agreement stateAgreement) {
+	if m.assertionChainData.agreedSiblings == nil {
+		m.assertionChainData.agreedSiblings = make(map[protocol.AssertionHash]*protocol.AssertionCreatedInfo)
+	}
+	m.assertionChainData.agreedSiblings[protocol.AssertionHash{Hash: assertion.AssertionHash}] = assertion
+	agreedSiblingCounter.Inc(1)
+	log.Info("Observed a sibling assertion we agree with, which will not be challenged",
+		"validatorName", m.validatorName,
+		"assertionHash", assertion.AssertionHash,
+		"parentAssertionHash", assertion.ParentAssertionHash,
+		"exactState", agreement == agreesExactly,
+		"siblingPolicy", m.siblingPolicy,
+	)
+}
+
+// AgreedSiblings lists the siblings of the assertions we defend whose states we agree with, which
+// are not challenged, sorted by hash.
+func (m *Manager) AgreedSiblings() []protocol.AssertionHash {
+	m.assertionChainData.RLock()
+	defer m.assertionChainData.RUnlock()
+	siblings := make([]protocol.AssertionHash, 0, len(m.assertionChainData.agreedSiblings))
+	for hash := range m.assertionChainData.agreedSiblings {
+		siblings = append(siblings, hash)
+	}
+	sort.Slice(siblings, func(i, j int) bool {
+		return siblings[i].Hash.Cmp(siblings[j].Hash) < 0
+	})
+	return siblings
+}
@@ -0,0 +1,41 @@
+package assertions
+
+import (
+	"context"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// Checks whether an assertion was posted from our address, and if so, whether we intended to post
+// it. The intent journal pauses posting if we did not.
+func (m *Manager) checkForSplitBrain(ctx context.Context, creationInfo *protocol.AssertionCreatedInfo) error {
+	if m.intents == nil {
+		return nil
+	}
+	tx, _, err := m.chain.Backend().TransactionByHash(ctx, creationInfo.TransactionHash)
+	if err != nil {
+		return errors.Wrapf(err, "could not get transaction %#x which created assertion %#x", creationInfo.TransactionHash, creationInfo.AssertionHash)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		// Transactions signed with custom schemes may not have a recoverable sender.
+		log.Debug("Could not recover sender of assertion creation", "assertionHash", creationInfo.AssertionHash, "err", err)
+		return nil
+	}
+	if sender != m.chain.StakerAddress() {
+		return nil
+	}
+	if !m.intents.ObserveAssertion(creationInfo.AssertionHash, creationInfo.CreationBlock) {
+		log.Error(
+			"Split brain: observed an assertion from our staker address we did not post, is another instance using our key? Posting is paused",
+			"assertionHash", creationInfo.AssertionHash,
+			"transactionHash", creationInfo.TransactionHash,
+			"staker", sender,
+			"validatorName", m.validatorName,
+		)
+	}
+	return nil
+}
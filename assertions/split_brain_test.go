@@ -0,0 +1,66 @@
+package assertions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	challenge_testing "github.com/OffchainLabs/bold/testing"
+	statemanager "github.com/OffchainLabs/bold/testing/mocks/state-provider"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/OffchainLabs/bold/util/intents"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkForSplitBrain(t *testing.T) {
+	ctx := context.Background()
+	setup, err := setup.ChainsWithEdgeChallengeManager(
+		setup.WithMockOneStepProver(),
+		setup.WithChallengeTestingOpts(
+			challenge_testing.WithLayerZeroHeights(&protocol.LayerZeroHeights{
+				BlockChallengeHeight:     64,
+				BigStepChallengeHeight:   32,
+				SmallStepChallengeHeight: 32,
+			}),
+		),
+	)
+	require.NoError(t, err)
+	aliceChain := setup.Chains[0]
+	genesisHash, err := aliceChain.GenesisAssertionHash(ctx)
+	require.NoError(t, err)
+	genesisCreationInfo, err := aliceChain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: genesisHash})
+	require.NoError(t, err)
+	stateManager, err := statemanager.NewForSimpleMachine(setup.StateManagerOpts...)
+	require.NoError(t, err)
+	preState, err := stateManager.ExecutionStateAfterPreviousState(ctx, 0, nil, 1<<26)
+	require.NoError(t, err)
+	postState, err := stateManager.ExecutionStateAfterPreviousState(ctx, 1, &preState.GlobalState, 1<<26)
+	require.NoError(t, err)
+
+	// Another instance posts an assertion with our key, without recording it in our journal.
+	assertion, err := aliceChain.NewStakeOnNewAssertion(ctx, genesisCreationInfo, postState)
+	require.NoError(t, err)
+	creationInfo, err := aliceChain.ReadAssertionCreationInfo(ctx, assertion.Id())
+	require.NoError(t, err)
+
+	journal, err := intents.NewJournal(filepath.Join(t.TempDir(), "intents.journal"), 0)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, journal.Close()) }()
+	m := &Manager{chain: aliceChain, intents: journal}
+
+	// Assertions from other addresses are not checked.
+	m.chain = setup.Chains[1]
+	require.NoError(t, m.checkForSplitBrain(ctx, creationInfo))
+	require.False(t, journal.Paused())
+
+	m.chain = aliceChain
+	require.NoError(t, m.checkForSplitBrain(ctx, creationInfo))
+	require.True(t, journal.Paused())
+	require.ErrorIs(t, journal.RecordAssertion(creationInfo.AssertionHash), intents.ErrPaused)
+
+	// Once resumed, the assertion is considered ours.
+	require.NoError(t, journal.Resume())
+	require.NoError(t, m.checkForSplitBrain(ctx, creationInfo))
+	require.False(t, journal.Paused())
+}
@@ -0,0 +1,116 @@
+package assertions
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+// The gauges of the total assertion stakes, by kind of stake event.
+var assertionStakeGauges = map[string]metrics.GaugeFloat64{
+	api.StakeEventLocked:   metrics.GetOrRegisterGaugeFloat64("arb/validator/stake/assertion/locked", nil),
+	api.StakeEventRefunded: metrics.GetOrRegisterGaugeFloat64("arb/validator/stake/assertion/refunded", nil),
+}
+
+// Filters for changes to stakers' assertion stakes within a range, and records the tokens
+// locked and refunded in the API database so stake exposure can be tracked over time.
+func (m *Manager) recordStakeUpdatesInRange(
+	ctx context.Context,
+	filterer *rollupgen.RollupUserLogicFilterer,
+	filterOpts *bind.FilterOpts,
+) error {
+	if api.IsNil(m.apiDB) {
+		return nil
+	}
+	if err := m.loadStakeTotals(); err != nil {
+		return err
+	}
+	it, err := filterer.FilterUserStakeUpdated(filterOpts, nil, nil)
+	if err != nil {
+		return err
+	}
+	timestamps := make(map[uint64]time.Time)
+	err = iterators.ForEachEvent[*rollupgen.RollupUserLogicUserStakeUpdated](it, func(event *rollupgen.RollupUserLogicUserStakeUpdated) error {
+		// A stake is locked when its balance grows, and refunded to the staker's
+		// withdrawable funds when it shrinks.
+		kind := api.StakeEventLocked
+		amount := new(big.Int).Sub(event.FinalBalance, event.InitialBalance)
+		if amount.Sign() < 0 {
+			kind = api.StakeEventRefunded
+			amount.Neg(amount)
+		}
+		if amount.Sign() == 0 {
+			return nil
+		}
+		blockNum := event.Raw.BlockNumber
+		timestamp, ok := timestamps[blockNum]
+		if !ok {
+			header, err := m.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNum))
+			if err != nil {
+				return err
+			}
+			timestamp = time.Unix(int64(header.Time), 0).UTC()
+			timestamps[blockNum] = timestamp
+		}
+		inserted, err := m.apiDB.InsertStakeEvent(&api.JsonStakeEvent{
+			Staker:          event.User,
+			Kind:            kind,
+			Source:          api.StakeSourceAssertion,
+			EdgeId:          common.Hash{},
+			Amount:          amount.String(),
+			BlockNumber:     blockNum,
+			TransactionHash: event.Raw.TxHash,
+			LogIndex:        event.Raw.Index,
+			Timestamp:       timestamp,
+		})
+		if err != nil {
+			return err
+		}
+		// Events in a range may be scanned more than once, so only new events are counted.
+		if inserted {
+			total := m.stakeTotals[kind]
+			total.Add(total, amount)
+			updateStakeGauge(assertionStakeGauges[kind], total)
+		}
+		return nil
+	})
+	return errors.Wrapf(err, "could not scan stake updates from block %d to %d", filterOpts.Start, *filterOpts.End)
+}
+
+// Computes the totals reported by the stake gauges from the stake events recorded, so that they
+// carry over restarts. They are then kept as integers, as amounts in wei are too large to be summed
+// exactly as floats.
+func (m *Manager) loadStakeTotals() error {
+	if m.stakeTotals != nil {
+		return nil
+	}
+	totals := make(map[string]*big.Int)
+	for kind, gauge := range assertionStakeGauges {
+		events, err := m.apiDB.GetStakeEvents(db.WithStakeEventSource(api.StakeSourceAssertion), db.WithStakeEventKind(kind))
+		if err != nil {
+			return errors.Wrapf(err, "could not get %s assertion stakes", kind)
+		}
+		total, err := db.SumStakeAmounts(events)
+		if err != nil {
+			return err
+		}
+		totals[kind] = total
+		updateStakeGauge(gauge, total)
+	}
+	m.stakeTotals = totals
+	return nil
+}
+
+func updateStakeGauge(gauge metrics.GaugeFloat64, total *big.Int) {
+	totalFloat, _ := new(big.Float).SetInt(total).Float64()
+	gauge.Update(totalFloat)
+}
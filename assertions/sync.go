@@ -7,9 +7,10 @@ import (
 
 	"github.com/OffchainLabs/bold/api"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
 	"github.com/OffchainLabs/bold/containers/option"
-	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -21,14 +22,14 @@ import (
 func (m *Manager) syncAssertions(ctx context.Context) {
 	latestConfirmed, err := retry.UntilSucceeds(ctx, func() (protocol.Assertion, error) {
 		return m.chain.LatestConfirmed(ctx)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get latest confirmed assertion", "err", err)
 		return
 	}
 	latestConfirmedInfo, err := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
 		return m.chain.ReadAssertionCreationInfo(ctx, latestConfirmed.Id())
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get latest confirmed assertion", "err", err)
 		return
@@ -54,7 +55,7 @@ func (m *Manager) syncAssertions(ctx context.Context) {
 	}
 	latestBlock, err := retry.UntilSucceeds(ctx, func() (*gethtypes.Header, error) {
 		return m.backend.HeaderByNumber(ctx, m.chain.GetDesiredRpcHeadBlockNumber())
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get header by number", "err", err)
 		return
@@ -82,6 +83,9 @@ func (m *Manager) syncAssertions(ctx context.Context) {
 			log.Error("Could not check for assertion added event")
 			return
 		}
+		if err = m.recordStakeUpdatesInRange(ctx, filterer, filterOpts); err != nil {
+			log.Error("Could not record stake updates", "err", err)
+		}
 		fromBlock = toBlock
 	}
 
@@ -120,6 +124,9 @@ func (m *Manager) syncAssertions(ctx context.Context) {
 				log.Error("Could not check for assertion added", "err", err)
 				return
 			}
+			if err = m.recordStakeUpdatesInRange(ctx, filterer, filterOpts); err != nil {
+				log.Error("Could not record stake updates", "err", err)
+			}
 			fromBlock = toBlock
 		case <-ctx.Done():
 			return
@@ -143,37 +150,29 @@ func (m *Manager) processAllAssertionsInRange(
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err = it.Close(); err != nil {
-			log.Error("Could not close filter iterator", "err", err)
-		}
-	}()
 
 	// Extract all assertion creation events from the log filter iterator.
 	assertions := make([]assertionAndParentCreationInfo, 0)
 	assertionsByHash := make(map[common.Hash]*protocol.AssertionCreatedInfo)
-	for it.Next() {
-		if it.Error() != nil {
-			return errors.Wrapf(
-				err,
-				"got iterator error when scanning assertion creations from block %d to %d",
-				filterOpts.Start,
-				*filterOpts.End,
-			)
-		}
+	err = iterators.ForEachEvent[*rollupgen.RollupUserLogicAssertionCreated](it, func(event *rollupgen.RollupUserLogicAssertionCreated) error {
 		assertionOpt, err := retry.UntilSucceeds(ctx, func() (option.Option[*protocol.AssertionCreatedInfo], error) {
-			item, innerErr := m.extractAssertionFromEvent(ctx, it.Event)
+			item, innerErr := m.extractAssertionFromEvent(ctx, event)
 			if innerErr != nil {
 				log.Error("Could not extract assertion from event", "err", innerErr)
 				return option.None[*protocol.AssertionCreatedInfo](), innerErr
 			}
 			return item, nil
-		})
+		}, retry.WithPolicy(retry.RPCRead))
 		if err != nil {
 			return err
 		}
 		if assertionOpt.IsSome() {
 			creationInfo := assertionOpt.Unwrap()
+			if _, err = retry.UntilSucceeds(ctx, func() (bool, error) {
+				return true, m.checkForSplitBrain(ctx, creationInfo)
+			}); err != nil {
+				return err
+			}
 			assertionsByHash[creationInfo.AssertionHash] = creationInfo
 			fullInfo := assertionAndParentCreationInfo{
 				assertion: creationInfo,
@@ -182,7 +181,7 @@ func (m *Manager) processAllAssertionsInRange(
 			if fullInfo.parent == nil {
 				parentInfo, err := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
 					return m.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: creationInfo.ParentAssertionHash})
-				})
+				}, retry.WithPolicy(retry.RPCRead))
 				if err != nil {
 					return errors.Wrapf(err, "could not read assertion creation info for %#x (parent of %#x)", creationInfo.ParentAssertionHash, creationInfo.AssertionHash)
 				}
@@ -191,6 +190,10 @@ func (m *Manager) processAllAssertionsInRange(
 			}
 			assertions = append(assertions, fullInfo)
 		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not scan assertion creations from block %d to %d", filterOpts.Start, *filterOpts.End)
 	}
 
 	// Save all observed assertions to the database.
@@ -264,6 +267,8 @@ func (m *Manager) extractAssertionFromEvent(
 // Starts by setting a cursor to the latest confirmed assertion, then finds all assertions parent == cursor.
 // We then check which one we agree with.
 // From there, checks all assertions that have that assertion as parent, etc.
+// Siblings of canonical assertions whose states we agree with are recorded, so they are not
+// challenged, and an equivalent sibling becomes canonical itself under the DefendFirstSibling policy.
 // This function must hold the lock on m.assertionChainData.
 func (m *Manager) findCanonicalAssertionBranch(
 	ctx context.Context,
@@ -274,32 +279,23 @@ func (m *Manager) findCanonicalAssertionBranch(
 
 	for _, fullInfo := range assertions {
 		assertion := fullInfo.assertion
-		if assertion.ParentAssertionHash == cursor.Hash {
-			agreedWithAssertion, err := retry.UntilSucceeds(ctx, func() (bool, error) {
-				expectedState, err := m.ExecutionStateAfterParent(ctx, fullInfo.parent)
-				switch {
-				case errors.Is(err, l2stateprovider.ErrChainCatchingUp):
-					// Otherwise, we return the error that we are still catching up to the
-					// execution state claimed by the assertion, and this function will be retried
-					// by the caller if wrapped in a retryable call.
-					chainCatchingUpCounter.Inc(1)
-					log.Info("Chain still syncing "+
-						"will reattempt processing when caught up", "err", err)
-					return false, l2stateprovider.ErrChainCatchingUp
-				case err != nil:
-					return false, err
-				}
-				return expectedState.Equals(protocol.GoExecutionStateFromSolidity(assertion.AfterState)), nil
-			})
-			if err != nil {
-				return errors.New("could not check for assertion agreements")
-			}
-			if agreedWithAssertion {
-				cursor = protocol.AssertionHash{Hash: assertion.AssertionHash}
-				m.assertionChainData.latestAgreedAssertion = cursor
-				m.assertionChainData.canonicalAssertions[cursor] = assertion
-				m.observedCanonicalAssertions <- cursor
-			}
+		atCursor := assertion.ParentAssertionHash == cursor.Hash
+		if !atCursor && !m.assertionChainData.mayBeAgreedSibling(assertion) {
+			continue
+		}
+		agreement, err := m.agreementWithAssertion(ctx, fullInfo)
+		if err != nil {
+			return errors.New("could not check for assertion agreements")
+		}
+		if atCursor && m.defendsAssertion(agreement) {
+			cursor = protocol.AssertionHash{Hash: assertion.AssertionHash}
+			m.assertionChainData.latestAgreedAssertion = cursor
+			m.assertionChainData.canonicalAssertions[cursor] = assertion
+			m.observedCanonicalAssertions <- cursor
+			continue
+		}
+		if agreement != disagrees {
+			m.recordAgreedSibling(assertion, agreement)
 		}
 	}
 	return nil
@@ -335,11 +331,27 @@ func (m *Manager) respondToAnyInvalidAssertions(
 		_, isCanonical := m.assertionChainData.canonicalAssertions[protocol.AssertionHash{
 			Hash: assertion.AssertionHash,
 		}]
+		// Siblings we agree with are not challenged, as only one branch is defended.
+		if _, isAgreedSibling := m.assertionChainData.agreedSiblings[protocol.AssertionHash{
+			Hash: assertion.AssertionHash,
+		}]; isAgreedSibling {
+			continue
+		}
 		// If an assertion has a canonical parent but is not canonical itself,
 		// then we should challenge the assertion if we are configured to do so,
 		// or raise an alarm if we are only a watchtower validator.
 		if hasCanonicalParent && !isCanonical {
 			postedRival, err := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
+				if innerErr := m.hooks.Gate(ctx, &hooks.Action{
+					Point:         hooks.OnRivalDetected,
+					Kind:          "rival_assertion",
+					AssertionHash: assertion.AssertionHash,
+				}); innerErr != nil {
+					if errors.Is(innerErr, hooks.ErrVetoed) {
+						return nil, nil
+					}
+					return nil, innerErr
+				}
 				posted, innerErr := rivalPoster.maybePostRivalAssertionAndChallenge(ctx, rivalPosterArgs{
 					canonicalParent:  canonicalParent,
 					invalidAssertion: assertion,
@@ -349,7 +361,7 @@ func (m *Manager) respondToAnyInvalidAssertions(
 					return nil, innerErr
 				}
 				return posted, nil
-			})
+			}, retry.WithPolicy(retry.RPCWrite))
 			if err != nil {
 				return err
 			}
@@ -360,6 +372,7 @@ func (m *Manager) respondToAnyInvalidAssertions(
 					m.submittedAssertions.Insert(postedAssertionHash.Hash)
 					m.submittedRivalsCount++
 					m.observedCanonicalAssertions <- postedAssertionHash
+					m.publishPostedAssertion(postedRival)
 				}
 			}
 		}
@@ -395,6 +408,13 @@ func (m *Manager) maybePostRivalAssertionAndChallenge(
 	log.Warn("Disagreed with an observed assertion onchain", logFields...)
 	evilAssertionCounter.Inc(1)
 
+	// Failing to re-execute the window is not fatal, as the blocks are otherwise executed in full
+	// once needed in the challenge.
+	if err := m.reexecuteDivergenceWindow(ctx, args); err != nil {
+		blockWindowReexecutionErrorCounter.Inc(1)
+		log.Error("Could not re-execute block window around divergence", append(logFields, "err", err)...)
+	}
+
 	// Post what we believe is the correct rival assertion that follows the ancestor we agree with.
 	correctRivalAssertion, err := m.maybePostRivalAssertion(ctx, args.canonicalParent)
 	if err != nil {
@@ -536,30 +556,22 @@ func (m *Manager) saveAssertionToDB(ctx context.Context, creationInfo *protocol.
 	if err != nil {
 		return err
 	}
-	return m.apiDB.InsertAssertion(&api.JsonAssertion{
-		Hash:                     assertionHash.Hash,
-		ConfirmPeriodBlocks:      creationInfo.ConfirmPeriodBlocks,
-		RequiredStake:            creationInfo.RequiredStake.String(),
-		ParentAssertionHash:      creationInfo.ParentAssertionHash,
-		InboxMaxCount:            creationInfo.InboxMaxCount.String(),
-		AfterInboxBatchAcc:       creationInfo.AfterInboxBatchAcc,
-		WasmModuleRoot:           creationInfo.WasmModuleRoot,
-		ChallengeManager:         creationInfo.ChallengeManager,
-		CreationBlock:            creationInfo.CreationBlock,
-		TransactionHash:          creationInfo.TransactionHash,
-		BeforeStateBlockHash:     beforeState.GlobalState.BlockHash,
-		BeforeStateSendRoot:      beforeState.GlobalState.SendRoot,
-		BeforeStateBatch:         beforeState.GlobalState.Batch,
-		BeforeStatePosInBatch:    beforeState.GlobalState.PosInBatch,
-		BeforeStateMachineStatus: beforeState.MachineStatus,
-		AfterStateBlockHash:      afterState.GlobalState.BlockHash,
-		AfterStateSendRoot:       afterState.GlobalState.SendRoot,
-		AfterStateBatch:          afterState.GlobalState.Batch,
-		AfterStatePosInBatch:     afterState.GlobalState.PosInBatch,
-		AfterStateMachineStatus:  afterState.MachineStatus,
-		FirstChildBlock:          &firstChildBlock,
-		SecondChildBlock:         &secondChildBlock,
-		IsFirstChild:             isFirstChild,
-		Status:                   status.String(),
-	})
+	jsonAssertion := &api.JsonAssertion{
+		Hash:                assertionHash.Hash,
+		ConfirmPeriodBlocks: creationInfo.ConfirmPeriodBlocks,
+		RequiredStake:       creationInfo.RequiredStake.String(),
+		ParentAssertionHash: creationInfo.ParentAssertionHash,
+		InboxMaxCount:       creationInfo.InboxMaxCount.String(),
+		AfterInboxBatchAcc:  creationInfo.AfterInboxBatchAcc,
+		WasmModuleRoot:      creationInfo.WasmModuleRoot,
+		ChallengeManager:    creationInfo.ChallengeManager,
+		CreationBlock:       creationInfo.CreationBlock,
+		TransactionHash:     creationInfo.TransactionHash,
+		FirstChildBlock:     &firstChildBlock,
+		SecondChildBlock:    &secondChildBlock,
+		IsFirstChild:        isFirstChild,
+		Status:              status.String(),
+	}
+	jsonAssertion.SetStates(beforeState, afterState)
+	return m.apiDB.InsertAssertion(jsonAssertion)
 }
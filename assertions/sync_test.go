@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
+	"github.com/OffchainLabs/bold/challenge-manager/types"
 	"github.com/OffchainLabs/bold/containers/threadsafe"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	"github.com/OffchainLabs/bold/solgen/go/mocksgen"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	challenge_testing "github.com/OffchainLabs/bold/testing"
@@ -210,6 +213,77 @@ func Test_findCanonicalAssertionBranch(t *testing.T) {
 	}
 }
 
+func Test_findCanonicalAssertionBranch_agreedSiblings(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Assertion 3 claims the same state as assertion 2 at a different position in the inbox.
+	equivalentState := numToState(2)
+	equivalentState.GlobalState.U64Vals[1] = 1
+	assertions := []assertionAndParentCreationInfo{
+		{
+			parent: &protocol.AssertionCreatedInfo{InboxMaxCount: big.NewInt(3)},
+			assertion: &protocol.AssertionCreatedInfo{
+				ParentAssertionHash: numToHash(1),
+				AssertionHash:       numToHash(3),
+				AfterState:          equivalentState,
+			},
+		},
+		{
+			parent: &protocol.AssertionCreatedInfo{InboxMaxCount: big.NewInt(2)},
+			assertion: &protocol.AssertionCreatedInfo{
+				ParentAssertionHash: numToHash(1),
+				AssertionHash:       numToHash(2),
+				AfterState:          numToState(2),
+			},
+		},
+		{
+			parent: &protocol.AssertionCreatedInfo{InboxMaxCount: big.NewInt(7)},
+			assertion: &protocol.AssertionCreatedInfo{
+				ParentAssertionHash: numToHash(1),
+				AssertionHash:       numToHash(7),
+				AfterState:          numToState(7),
+			},
+		},
+	}
+	provider := &mockStateProvider{
+		agreesWith: map[uint64]*protocol.AssertionCreatedInfo{
+			2: {AfterState: numToState(2)},
+			3: {AfterState: numToState(2)},
+		},
+	}
+	newManager := func(policy types.SiblingPolicy) *Manager {
+		manager := &Manager{
+			stateProvider:               provider,
+			observedCanonicalAssertions: make(chan protocol.AssertionHash, 10),
+			siblingPolicy:               policy,
+			assertionChainData: &assertionChainData{
+				latestAgreedAssertion: numToAssertionHash(1),
+				canonicalAssertions:   make(map[protocol.AssertionHash]*protocol.AssertionCreatedInfo),
+			},
+			layerZeroHeightsCache: &protocol.LayerZeroHeights{
+				BlockChallengeHeight:     32,
+				BigStepChallengeHeight:   32,
+				SmallStepChallengeHeight: 32,
+			},
+		}
+		manager.assertionChainData.canonicalAssertions[numToAssertionHash(1)] = &protocol.AssertionCreatedInfo{}
+		return manager
+	}
+
+	t.Run("defends the exact sibling", func(t *testing.T) {
+		manager := newManager(types.DefendExactSibling)
+		require.NoError(t, manager.findCanonicalAssertionBranch(ctx, assertions))
+		require.Equal(t, numToAssertionHash(2), manager.assertionChainData.latestAgreedAssertion)
+		require.Equal(t, []protocol.AssertionHash{numToAssertionHash(3)}, manager.AgreedSiblings())
+	})
+	t.Run("defends the first sibling", func(t *testing.T) {
+		manager := newManager(types.DefendFirstSibling)
+		require.NoError(t, manager.findCanonicalAssertionBranch(ctx, assertions))
+		require.Equal(t, numToAssertionHash(3), manager.assertionChainData.latestAgreedAssertion)
+		require.Equal(t, []protocol.AssertionHash{numToAssertionHash(2)}, manager.AgreedSiblings())
+	})
+}
+
 func numToAssertionHash(i int) protocol.AssertionHash {
 	return protocol.AssertionHash{Hash: common.BytesToHash([]byte(fmt.Sprintf("%d", i)))}
 }
@@ -336,6 +410,26 @@ func Test_respondToAnyInvalidAssertions(t *testing.T) {
 		))
 		require.Equal(t, uint64(0), manager.submittedRivalsCount)
 	})
+	t.Run("rivals vetoed by hooks", func(t *testing.T) {
+		poster := &mockRivalPoster{}
+		manager.hooks = hooks.NewRegistry([]hooks.Hook{&vetoHook{}})
+		defer func() { manager.hooks = nil }()
+		require.NoError(t, manager.respondToAnyInvalidAssertions(
+			ctx,
+			[]assertionAndParentCreationInfo{
+				{
+					parent: &protocol.AssertionCreatedInfo{},
+					assertion: &protocol.AssertionCreatedInfo{
+						ParentAssertionHash: numToHash(2),
+						AssertionHash:       numToHash(3),
+						AfterState:          numToState(3),
+					},
+				},
+			},
+			poster,
+		))
+		require.Equal(t, uint64(0), manager.submittedRivalsCount)
+	})
 	t.Run("rivals posted successfully", func(t *testing.T) {
 		poster := &mockRivalPoster{}
 		require.NoError(t, manager.respondToAnyInvalidAssertions(
@@ -363,6 +457,38 @@ func Test_respondToAnyInvalidAssertions(t *testing.T) {
 		))
 		require.Equal(t, uint64(2), manager.submittedRivalsCount)
 	})
+	t.Run("agreed siblings not challenged", func(t *testing.T) {
+		poster := &mockRivalPoster{}
+		manager.assertionChainData.agreedSiblings = map[protocol.AssertionHash]*protocol.AssertionCreatedInfo{
+			numToAssertionHash(8): {},
+		}
+		defer func() { manager.assertionChainData.agreedSiblings = nil }()
+		require.NoError(t, manager.respondToAnyInvalidAssertions(
+			ctx,
+			[]assertionAndParentCreationInfo{
+				{
+					parent: &protocol.AssertionCreatedInfo{},
+					assertion: &protocol.AssertionCreatedInfo{
+						ParentAssertionHash: numToHash(2),
+						AssertionHash:       numToHash(8),
+						AfterState:          numToState(8),
+					},
+				},
+			},
+			poster,
+		))
+		require.Equal(t, uint64(2), manager.submittedRivalsCount)
+	})
+}
+
+type vetoHook struct{}
+
+func (*vetoHook) Name() string {
+	return "veto"
+}
+
+func (*vetoHook) Evaluate(_ context.Context, action *hooks.Action) (*hooks.Verdict, error) {
+	return &hooks.Verdict{Veto: action.Point == hooks.OnRivalDetected, Reason: "not today"}, nil
 }
 
 type mockRivalPoster struct {
@@ -384,3 +510,59 @@ func (m *mockRivalPoster) maybePostRivalAssertionAndChallenge(
 	}
 	panic("must have been able to post")
 }
+
+func Test_reexecuteDivergenceWindow(t *testing.T) {
+	ctx := context.Background()
+	parent := &protocol.AssertionCreatedInfo{
+		AssertionHash:  numToHash(1),
+		AfterState:     numToState(2),
+		InboxMaxCount:  big.NewInt(4),
+		WasmModuleRoot: common.BytesToHash([]byte("wasm")),
+	}
+	invalid := &protocol.AssertionCreatedInfo{
+		ParentAssertionHash: numToHash(1),
+		AssertionHash:       numToHash(2),
+		AfterState:          numToState(9),
+	}
+	reexecutor := &mockBlockWindowReexecutor{divergeAt: 3}
+	manager := &Manager{
+		stateProvider: &mockStateProvider{
+			agreesWith: map[uint64]*protocol.AssertionCreatedInfo{4: {AfterState: numToState(4)}},
+		},
+		layerZeroHeightsCache: &protocol.LayerZeroHeights{
+			BlockChallengeHeight:     32,
+			BigStepChallengeHeight:   32,
+			SmallStepChallengeHeight: 32,
+		},
+	}
+	args := rivalPosterArgs{canonicalParent: parent, invalidAssertion: invalid}
+
+	// Nothing is re-executed unless configured.
+	require.NoError(t, manager.reexecuteDivergenceWindow(ctx, args))
+
+	manager.blockWindowReexecutor = reexecutor
+	manager.onchainBatchAccumulators = &mockBlockWindowReexecutor{divergeAt: 100}
+	require.NoError(t, manager.reexecuteDivergenceWindow(ctx, args))
+	require.Len(t, reexecutor.windows, 1)
+	window := reexecutor.windows[0]
+	require.Equal(t, "3:0-4:0", window.String())
+	require.Equal(t, parent.WasmModuleRoot, window.WasmModuleRoot)
+	require.Equal(t, invalid.AssertionHash, window.ClaimId)
+}
+
+type mockBlockWindowReexecutor struct {
+	divergeAt uint64
+	windows   []*l2stateprovider.ReexecutionWindow
+}
+
+func (m *mockBlockWindowReexecutor) BatchAccumulator(_ context.Context, batch l2stateprovider.Batch) (common.Hash, error) {
+	if uint64(batch) >= m.divergeAt {
+		return common.BytesToHash([]byte(fmt.Sprintf("diverged-%d", batch))), nil
+	}
+	return numToHash(int(batch)), nil
+}
+
+func (m *mockBlockWindowReexecutor) ReexecuteBlockWindow(_ context.Context, window *l2stateprovider.ReexecutionWindow) error {
+	m.windows = append(m.windows, window)
+	return nil
+}
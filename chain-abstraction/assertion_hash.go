@@ -0,0 +1,39 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"encoding/binary"
+
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ComputeAssertionHash computes the hash of an assertion the same way as the rollup's
+// computeAssertionHash, which is the keccak256 hash of the packed encoding of the parent assertion
+// hash, the hash of the assertion's after state, and the inbox accumulator after the last batch
+// the assertion consumed.
+func ComputeAssertionHash(
+	parentAssertionHash common.Hash,
+	afterState rollupgen.AssertionState,
+	inboxAcc common.Hash,
+) AssertionHash {
+	stateHash := ComputeAssertionStateHash(afterState)
+	return AssertionHash{Hash: crypto.Keccak256Hash(parentAssertionHash[:], stateHash[:], inboxAcc[:])}
+}
+
+// ComputeAssertionStateHash computes the hash of an assertion state the same way as the rollup,
+// which is the keccak256 hash of its ABI encoding. All its fields are static, so each is encoded
+// in place as a 32 byte word.
+func ComputeAssertionStateHash(state rollupgen.AssertionState) common.Hash {
+	data := make([]byte, 32*6)
+	copy(data[0:32], state.GlobalState.Bytes32Vals[0][:])
+	copy(data[32:64], state.GlobalState.Bytes32Vals[1][:])
+	binary.BigEndian.PutUint64(data[88:96], state.GlobalState.U64Vals[0])
+	binary.BigEndian.PutUint64(data[120:128], state.GlobalState.U64Vals[1])
+	data[159] = state.MachineStatus
+	copy(data[160:192], state.EndHistoryRoot[:])
+	return crypto.Keccak256Hash(data)
+}
@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"encoding/binary"
+
+	"github.com/OffchainLabs/bold/math"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ComputeMutualId computes the mutual id of an edge the same way as the challenge manager's
+// calculateMutualId, which is the keccak256 hash of the packed encoding of the edge's level,
+// origin id, start height, start history root and end height.
+func ComputeMutualId(
+	level ChallengeLevel,
+	originId OriginId,
+	startHeight Height,
+	startHistoryRoot common.Hash,
+	endHeight Height,
+) MutualId {
+	return MutualId(crypto.Keccak256Hash(packMutualId(level, originId, startHeight, startHistoryRoot, endHeight)))
+}
+
+// ComputeEdgeId computes the id of an edge the same way as the challenge manager's
+// calculateEdgeId, which is the keccak256 hash of the edge's mutual id and end history root.
+func ComputeEdgeId(
+	level ChallengeLevel,
+	originId OriginId,
+	startHeight Height,
+	startHistoryRoot common.Hash,
+	endHeight Height,
+	endHistoryRoot common.Hash,
+) EdgeId {
+	mutualId := ComputeMutualId(level, originId, startHeight, startHistoryRoot, endHeight)
+	return EdgeId{Hash: crypto.Keccak256Hash(mutualId[:], endHistoryRoot[:])}
+}
+
+// Heights are packed as uint256 values, of which only the lowest 8 bytes can be set.
+func packMutualId(
+	level ChallengeLevel,
+	originId OriginId,
+	startHeight Height,
+	startHistoryRoot common.Hash,
+	endHeight Height,
+) []byte {
+	data := make([]byte, 1+32*4)
+	data[0] = uint8(level)
+	copy(data[1:33], originId[:])
+	binary.BigEndian.PutUint64(data[57:65], uint64(startHeight))
+	copy(data[65:97], startHistoryRoot[:])
+	binary.BigEndian.PutUint64(data[121:129], uint64(endHeight))
+	return data
+}
+
+// ComputeBisectionChildIds computes the ids of the lower and upper children created by bisecting an
+// edge to a history root, the same way as the challenge manager's bisectEdge. The children share the
+// level and origin of the edge, and meet at its mandatory bisection height, which is returned along
+// with them.
+func ComputeBisectionChildIds(
+	edge ReadOnlyEdge,
+	bisectionHistoryRoot common.Hash,
+) (bisectionHeight Height, lower EdgeId, upper EdgeId, err error) {
+	startHeight, startRoot := edge.StartCommitment()
+	endHeight, endRoot := edge.EndCommitment()
+	height, err := math.Bisect(uint64(startHeight), uint64(endHeight))
+	if err != nil {
+		return 0, EdgeId{}, EdgeId{}, err
+	}
+	bisectionHeight = Height(height)
+	level := edge.GetChallengeLevel()
+	originId := edge.OriginId()
+	lower = ComputeEdgeId(level, originId, startHeight, startRoot, bisectionHeight, bisectionHistoryRoot)
+	upper = ComputeEdgeId(level, originId, bisectionHeight, bisectionHistoryRoot, endHeight, endRoot)
+	return bisectionHeight, lower, upper, nil
+}
@@ -61,14 +61,6 @@ func (s GoGlobalState) Equals(other GoGlobalState) bool {
 	return s == other
 }
 
-type MachineStatus uint8
-
-const (
-	MachineStatusRunning  MachineStatus = 0
-	MachineStatusFinished MachineStatus = 1
-	MachineStatusErrored  MachineStatus = 2
-)
-
 type ExecutionState struct {
 	GlobalState    GoGlobalState
 	MachineStatus  MachineStatus
@@ -86,7 +78,7 @@ func GoExecutionStateFromSolidity(executionState rollupgen.AssertionState) *Exec
 func (s *ExecutionState) AsSolidityStruct() rollupgen.AssertionState {
 	return rollupgen.AssertionState{
 		GlobalState:    rollupgen.GlobalState(s.GlobalState.AsSolidityStruct()),
-		MachineStatus:  uint8(s.MachineStatus),
+		MachineStatus:  s.MachineStatus.Uint8(),
 		EndHistoryRoot: s.EndHistoryRoot,
 	}
 }
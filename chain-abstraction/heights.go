@@ -0,0 +1,43 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Length is the number of steps between two heights of a history commitment, such as from the
+// start to the end height of an edge. It is kept apart from Height so that a length cannot be
+// used as a height, or a height as a length, without an explicit conversion.
+type Length uint64
+
+// HeightFromBig converts a height as represented in the contract bindings. Heights and lengths
+// are only handled as big integers in the layer converting to and from the bindings, which the
+// bigintheights analyzer enforces.
+func HeightFromBig(h *big.Int) (Height, error) {
+	if h == nil || !h.IsUint64() {
+		return 0, errors.Errorf("height %v is not a uint64", h)
+	}
+	return Height(h.Uint64()), nil
+}
+
+// LengthFromBig converts a length as represented in the contract bindings.
+func LengthFromBig(l *big.Int) (Length, error) {
+	if l == nil || !l.IsUint64() {
+		return 0, errors.Errorf("length %v is not a uint64", l)
+	}
+	return Length(l.Uint64()), nil
+}
+
+// Big converts a height to its representation in the contract bindings.
+func (h Height) Big() *big.Int {
+	return new(big.Int).SetUint64(uint64(h))
+}
+
+// Big converts a length to its representation in the contract bindings.
+func (l Length) Big() *big.Int {
+	return new(big.Int).SetUint64(uint64(l))
+}
@@ -0,0 +1,30 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeightsFromBig(t *testing.T) {
+	h, err := HeightFromBig(big.NewInt(32))
+	require.NoError(t, err)
+	require.Equal(t, Height(32), h)
+	require.Equal(t, big.NewInt(32), h.Big())
+
+	l, err := LengthFromBig(new(big.Int).SetUint64(math.MaxUint64))
+	require.NoError(t, err)
+	require.Equal(t, Length(math.MaxUint64), l)
+
+	_, err = HeightFromBig(nil)
+	require.ErrorContains(t, err, "height <nil> is not a uint64")
+	_, err = LengthFromBig(new(big.Int).Lsh(big.NewInt(1), 64))
+	require.ErrorContains(t, err, "length 18446744073709551616 is not a uint64")
+	_, err = LengthFromBig(big.NewInt(-1))
+	require.Error(t, err)
+}
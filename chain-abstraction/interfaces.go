@@ -43,6 +43,12 @@ type HeadSubscriber interface {
 	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
 }
 
+// StorageReader defines the ability to read the storage of a contract, such as the implementation
+// slot of a proxy. Chain backends may optionally implement it.
+type StorageReader interface {
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
 // LayerZeroHeights for edges configured as parameters in the challenge manager contract.
 type LayerZeroHeights struct {
 	BlockChallengeHeight     uint64
@@ -147,6 +153,8 @@ type AssertionChain interface {
 	AssertionUnrivaledBlocks(ctx context.Context, assertionHash AssertionHash) (uint64, error)
 	TopLevelAssertion(ctx context.Context, edgeId EdgeId) (AssertionHash, error)
 	TopLevelClaimHeights(ctx context.Context, edgeId EdgeId) (OriginHeights, error)
+	StakerAddress() common.Address
+	ValidatorAllowlist(ctx context.Context) (*ValidatorAllowlist, error)
 
 	// Mutating methods.
 	NewStakeOnNewAssertion(
@@ -173,6 +181,26 @@ type AssertionChain interface {
 	SpecChallengeManager(ctx context.Context) (SpecChallengeManager, error)
 }
 
+// ValidatorAllowlist describes the addresses a rollup allows to post assertions, confirm them,
+// and create layer zero edges. If the allowlist is disabled, anyone may do so.
+type ValidatorAllowlist struct {
+	Enabled    bool
+	Validators []common.Address
+}
+
+// IsAllowed checks if an address may perform validator actions in the rollup.
+func (v *ValidatorAllowlist) IsAllowed(addr common.Address) bool {
+	if !v.Enabled {
+		return true
+	}
+	for _, validator := range v.Validators {
+		if validator == addr {
+			return true
+		}
+	}
+	return false
+}
+
 // InheritedTimer for an edge from its children or claiming edges.
 type InheritedTimer uint64
 
@@ -203,6 +231,32 @@ func (et ChallengeLevel) String() string {
 	return fmt.Sprintf("challenge_level_%d_edge", et)
 }
 
+// Name is a human-readable name of the challenge level, given the number of big step
+// levels in the protocol, such as "block", "big_step_1" or "small_step".
+func (et ChallengeLevel) Name(numBigStepLevels uint8) string {
+	switch {
+	case et == 0:
+		return "block"
+	case uint8(et) <= numBigStepLevels:
+		return fmt.Sprintf("big_step_%d", et)
+	case uint8(et) == numBigStepLevels+1:
+		return "small_step"
+	default:
+		return fmt.Sprintf("unknown_level_%d", et)
+	}
+}
+
+// ChallengeStakeCost estimates the stake needed to take part in a challenge from a level down to
+// the small step level, given the stake amounts of each level indexed by level, which is the sum
+// of the stakes of a layer zero edge at each level.
+func ChallengeStakeCost(amounts []*big.Int, from ChallengeLevel) *big.Int {
+	total := new(big.Int)
+	for level := int(from); level < len(amounts); level++ {
+		total.Add(total, amounts[level])
+	}
+	return total
+}
+
 func ChallengeLevelFromString(s string) (ChallengeLevel, error) {
 	switch s {
 	case "block_challenge_edge":
@@ -261,6 +315,20 @@ type SpecChallengeManager interface {
 	NumBigSteps(ctx context.Context) (uint8, error)
 	// Duration of the challenge period in blocks.
 	ChallengePeriodBlocks(ctx context.Context) (uint64, error)
+	// Token in which stakes on layer zero edges are denominated.
+	StakeToken(ctx context.Context) (common.Address, error)
+	// Amount of stake required to create a layer zero edge at a challenge level.
+	StakeAmount(ctx context.Context, level ChallengeLevel) (*big.Int, error)
+	// Amounts of stake required to create a layer zero edge at each challenge level, indexed by level.
+	StakeAmounts(ctx context.Context) ([]*big.Int, error)
+	// Whether the challenge manager was deployed without stakes, as on devnets, in which case layer
+	// zero edges lock no tokens.
+	NoStakeMode(ctx context.Context) (bool, error)
+	// Whether the challenge manager lets the staker of a pending layer zero edge withdraw it to
+	// recover its stake. No deployed version does yet, so support is detected from the contract.
+	SupportsEdgeWithdrawal(ctx context.Context) (bool, error)
+	// Withdraws a pending layer zero edge staked by the validator to recover its stake.
+	WithdrawEdge(ctx context.Context, edgeId EdgeId) (*types.Transaction, error)
 	// Gets an edge by its id.
 	GetEdge(ctx context.Context, edgeId EdgeId) (option.Option[SpecEdge], error)
 	MultiUpdateInheritedTimers(
@@ -309,25 +377,6 @@ type SpecChallengeManager interface {
 // Heights are 0-indexed.
 type Height uint64
 
-// EdgeStatus of an edge in the protocol.
-type EdgeStatus uint8
-
-const (
-	EdgePending EdgeStatus = iota
-	EdgeConfirmed
-)
-
-func (e EdgeStatus) String() string {
-	switch e {
-	case EdgePending:
-		return "pending"
-	case EdgeConfirmed:
-		return "confirmed"
-	default:
-		return "unknown"
-	}
-}
-
 type OriginHeights struct {
 	ChallengeOriginHeights []Height `json:"challengeOriginHeights"`
 }
@@ -398,6 +447,16 @@ type VerifiedRoyalEdge interface {
 	Honest()
 }
 
+// AdoptedEdge marks the lower child returned by a bisection which already existed onchain.
+// A rival that agrees with the bisected edge up to the bisection point can bisect first, creating
+// the same lower child, which the bisection then adopts rather than creates. The child is shared
+// by both parents.
+type AdoptedEdge interface {
+	VerifiedRoyalEdge
+	// The id of the bisected edge which adopted the child.
+	AdoptedBy() EdgeId
+}
+
 // SpecEdge according to the protocol specification.
 type SpecEdge interface {
 	ReadOnlyEdge
@@ -0,0 +1,39 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type pinnedReadsKey struct{}
+
+// PinReads returns a context under which the contract reads of the assertion chain and challenge
+// manager are all made at the given block, rather than at the desired RPC head block of the time
+// of each read. Pinning the reads of one decision, such as an edge tracker deciding how to act,
+// keeps it from mixing state of different blocks.
+func PinReads(ctx context.Context, header *types.Header) context.Context {
+	return context.WithValue(ctx, pinnedReadsKey{}, header)
+}
+
+// UnpinReads returns a context under which reads are no longer pinned, for work which outlives
+// the decision the reads of ctx were pinned for.
+func UnpinReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pinnedReadsKey{}, (*types.Header)(nil))
+}
+
+// PinnedReads returns the header of the block the reads of a context are pinned to, if any.
+func PinnedReads(ctx context.Context) option.Option[*types.Header] {
+	if ctx == nil {
+		return option.None[*types.Header]()
+	}
+	header, ok := ctx.Value(pinnedReadsKey{}).(*types.Header)
+	if !ok || header == nil {
+		return option.None[*types.Header]()
+	}
+	return option.Some(header)
+}
@@ -0,0 +1,28 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinnedReads(t *testing.T) {
+	ctx := context.Background()
+	require.True(t, PinnedReads(ctx).IsNone())
+
+	header := &types.Header{Number: big.NewInt(10)}
+	pinned := PinReads(ctx, header)
+	require.Equal(t, header, PinnedReads(pinned).Unwrap())
+
+	// Pinning again within a pinned context replaces the pin.
+	repinned := PinReads(pinned, &types.Header{Number: big.NewInt(11)})
+	require.Equal(t, int64(11), PinnedReads(repinned).Unwrap().Number.Int64())
+
+	require.True(t, PinnedReads(UnpinReads(pinned)).IsNone())
+}
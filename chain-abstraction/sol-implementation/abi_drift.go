@@ -0,0 +1,182 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// The storage slot at which EIP-1967 proxies store the address of their implementation.
+var eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// ErrStorageUnsupported is returned when reading storage through a backend which cannot.
+var ErrStorageUnsupported = errors.New("backend cannot read storage to resolve proxy implementations")
+
+// StorageAt reads the storage of a contract through a backend which may not support it, such as the
+// backend wrapped by a chain backend wrapper, returning ErrStorageUnsupported if it does not.
+func StorageAt(ctx context.Context, backend bind.ContractCaller, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	reader, ok := backend.(protocol.StorageReader)
+	if !ok {
+		return nil, ErrStorageUnsupported
+	}
+	return reader.StorageAt(ctx, account, key, blockNumber)
+}
+
+// AbiDrift describes the differences between the code deployed for a contract and the
+// ABI embedded in our bindings, which usually indicates the bindings need to be regenerated.
+type AbiDrift struct {
+	// Implementation is the address whose code was inspected, which differs from the
+	// contract address if it is behind an EIP-1967 proxy.
+	Implementation common.Address
+	// MissingMethods are methods in our bindings that the deployed code does not dispatch.
+	MissingMethods []string
+	// UnknownSelectors are selectors the deployed code dispatches that are not in our bindings.
+	UnknownSelectors []string
+	// MissingEvents are events in our bindings that the deployed code never emits.
+	MissingEvents []string
+}
+
+// HasDrift returns true if the deployed code and our bindings differ.
+func (d *AbiDrift) HasDrift() bool {
+	return len(d.MissingMethods) > 0 || len(d.UnknownSelectors) > 0 || len(d.MissingEvents) > 0
+}
+
+// DetectAbiDrift compares the function selectors and event topics found in the deployed code
+// of a contract against an ABI. If the contract is an EIP-1967 proxy, the code of its implementation
+// is inspected instead, so the backend must be able to read storage, or ErrStorageUnsupported is
+// returned.
+func DetectAbiDrift(
+	ctx context.Context,
+	backend bind.ContractCaller,
+	addr common.Address,
+	contractAbi *abi.ABI,
+) (*AbiDrift, error) {
+	// Inspecting the code of a proxy would report every method as missing.
+	impl := addr
+	slot, err := StorageAt(ctx, backend, addr, eip1967ImplementationSlot, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read implementation slot of %#x", addr)
+	}
+	if implAddr := common.BytesToAddress(slot); implAddr != (common.Address{}) {
+		impl = implAddr
+	}
+	code, err := backend.CodeAt(ctx, impl, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get code at %#x", impl)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no code deployed at %#x", impl)
+	}
+	drift := abiDriftFromCode(code, contractAbi)
+	drift.Implementation = impl
+	return drift, nil
+}
+
+// Solidity dispatches functions by comparing the selector in the calldata against each
+// selector it knows with an EQ, and emits events by pushing their topic onto the stack,
+// so we scan the code for both patterns.
+func abiDriftFromCode(code []byte, contractAbi *abi.ABI) *AbiDrift {
+	dispatched := make(map[[4]byte]bool)
+	pushed := make(map[common.Hash]bool)
+	for i := 0; i < len(code); i++ {
+		op := vm.OpCode(code[i])
+		if op < vm.PUSH1 || op > vm.PUSH32 {
+			continue
+		}
+		size := int(op-vm.PUSH1) + 1
+		if i+size >= len(code) {
+			break
+		}
+		value := code[i+1 : i+1+size]
+		next := i + 1 + size
+		if size <= 4 {
+			// The selector may be duplicated before being compared.
+			if next < len(code) && vm.OpCode(code[next]) >= vm.DUP1 && vm.OpCode(code[next]) <= vm.DUP16 {
+				next++
+			}
+			if next < len(code) && vm.OpCode(code[next]) == vm.EQ {
+				var selector [4]byte
+				copy(selector[4-size:], value)
+				dispatched[selector] = true
+			}
+		}
+		if size == 32 {
+			pushed[common.BytesToHash(value)] = true
+		}
+		i += size
+	}
+
+	drift := &AbiDrift{
+		MissingMethods:   make([]string, 0),
+		UnknownSelectors: make([]string, 0),
+		MissingEvents:    make([]string, 0),
+	}
+	known := make(map[[4]byte]bool)
+	for _, method := range contractAbi.Methods {
+		var selector [4]byte
+		copy(selector[:], method.ID)
+		known[selector] = true
+		if !dispatched[selector] {
+			drift.MissingMethods = append(drift.MissingMethods, method.Sig)
+		}
+	}
+	for selector := range dispatched {
+		// Comparisons against small constants are common outside the dispatcher, so only
+		// full width values are reported as unknown selectors.
+		if !known[selector] && selector[0] != 0 {
+			drift.UnknownSelectors = append(drift.UnknownSelectors, fmt.Sprintf("%#x", selector))
+		}
+	}
+	for _, event := range contractAbi.Events {
+		if !pushed[event.ID] {
+			drift.MissingEvents = append(drift.MissingEvents, event.Sig)
+		}
+	}
+	sort.Strings(drift.MissingMethods)
+	sort.Strings(drift.UnknownSelectors)
+	sort.Strings(drift.MissingEvents)
+	return drift
+}
+
+// CheckChallengeManagerAbiDrift warns if the deployed challenge manager differs from the
+// ABI embedded in our bindings. The check is skipped for backends which cannot read storage.
+func CheckChallengeManagerAbiDrift(ctx context.Context, backend bind.ContractCaller, addr common.Address) error {
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	drift, err := DetectAbiDrift(ctx, backend, addr, contractAbi)
+	if errors.Is(err, ErrStorageUnsupported) {
+		log.Info("Skipping challenge manager ABI drift check, as the backend cannot read storage", "address", addr)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !drift.HasDrift() {
+		log.Info("Challenge manager code matches embedded ABI", "address", addr, "implementation", drift.Implementation)
+		return nil
+	}
+	log.Warn(
+		"Deployed challenge manager differs from embedded ABI, bindings may need to be regenerated",
+		"address", addr,
+		"implementation", drift.Implementation,
+		"missingMethods", drift.MissingMethods,
+		"unknownSelectors", drift.UnknownSelectors,
+		"missingEvents", drift.MissingEvents,
+	)
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCodeBackend struct {
+	code    map[common.Address][]byte
+	storage map[common.Address]map[common.Hash][]byte
+}
+
+func (m *mockCodeBackend) CodeAt(_ context.Context, contract common.Address, _ *big.Int) ([]byte, error) {
+	return m.code[contract], nil
+}
+
+func (m *mockCodeBackend) CallContract(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockCodeBackend) StorageAt(_ context.Context, account common.Address, key common.Hash, _ *big.Int) ([]byte, error) {
+	return m.storage[account][key], nil
+}
+
+// A backend which cannot read storage, and so cannot resolve proxies.
+type codeOnlyBackend struct {
+	inner *mockCodeBackend
+}
+
+func (c *codeOnlyBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.inner.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *codeOnlyBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.inner.CallContract(ctx, call, blockNumber)
+}
+
+func TestAbiDriftFromCode(t *testing.T) {
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	code := common.FromHex(challengeV2gen.EdgeChallengeManagerMetaData.Bin)
+	require.False(t, abiDriftFromCode(code, contractAbi).HasDrift())
+
+	// An ABI with a method and event the code does not know about.
+	extended, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"foo","inputs":[],"outputs":[]},
+		{"type":"event","name":"Bar","inputs":[],"anonymous":false}
+	]`))
+	require.NoError(t, err)
+	drift := abiDriftFromCode(code, &extended)
+	require.True(t, drift.HasDrift())
+	require.Equal(t, []string{"foo()"}, drift.MissingMethods)
+	require.Equal(t, []string{"Bar()"}, drift.MissingEvents)
+	require.NotEmpty(t, drift.UnknownSelectors)
+
+	// Code dispatching a selector unknown to the ABI.
+	dispatch := []byte{byte(vm.DUP1), byte(vm.PUSH4), 0xde, 0xad, 0xbe, 0xef, byte(vm.EQ)}
+	drift = abiDriftFromCode(append(dispatch, code...), contractAbi)
+	require.Equal(t, []string{"0xdeadbeef"}, drift.UnknownSelectors)
+	require.Empty(t, drift.MissingMethods)
+}
+
+func TestDetectAbiDrift_FollowsProxy(t *testing.T) {
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	proxy := common.BytesToAddress([]byte("proxy"))
+	impl := common.BytesToAddress([]byte("impl"))
+	backend := &mockCodeBackend{
+		code: map[common.Address][]byte{
+			proxy: {byte(vm.STOP)},
+			impl:  common.FromHex(challengeV2gen.EdgeChallengeManagerMetaData.Bin),
+		},
+		storage: map[common.Address]map[common.Hash][]byte{
+			proxy: {eip1967ImplementationSlot: common.LeftPadBytes(impl.Bytes(), 32)},
+		},
+	}
+	drift, err := DetectAbiDrift(context.Background(), backend, proxy, contractAbi)
+	require.NoError(t, err)
+	require.Equal(t, impl, drift.Implementation)
+	require.False(t, drift.HasDrift())
+
+	_, err = DetectAbiDrift(context.Background(), backend, common.BytesToAddress([]byte("none")), contractAbi)
+	require.ErrorContains(t, err, "no code deployed")
+
+	_, err = DetectAbiDrift(context.Background(), &codeOnlyBackend{inner: backend}, proxy, contractAbi)
+	require.ErrorIs(t, err, ErrStorageUnsupported)
+	// The startup check is skipped rather than failed for such backends.
+	require.NoError(t, CheckChallengeManagerAbiDrift(context.Background(), &codeOnlyBackend{inner: backend}, proxy))
+}
@@ -17,17 +17,24 @@ import (
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	"github.com/OffchainLabs/bold/containers"
+	inprogresscache "github.com/OffchainLabs/bold/containers/in-progress-cache"
 	"github.com/OffchainLabs/bold/containers/option"
 	"github.com/OffchainLabs/bold/containers/threadsafe"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	"github.com/OffchainLabs/bold/solgen/go/bridgegen"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/OffchainLabs/bold/util/intents"
+	"github.com/OffchainLabs/bold/util/poison"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -38,6 +45,11 @@ var (
 	ErrTooLate          = errors.New("too late to create assertion sibling")
 )
 
+var (
+	pinnedReadsCounter      = metrics.NewRegisteredCounter("arb/validator/rpc/pinned_reads", nil)
+	assertionDedupedCounter = metrics.NewRegisteredCounter("arb/validator/poster/assertion_deduplicated", nil)
+)
+
 var assertionCreatedId common.Hash
 
 func init() {
@@ -70,14 +82,40 @@ type Transactor interface {
 
 type ChainBackendTransactor struct {
 	ChainBackend
-	fifo *FIFO
+	fifo      *FIFO
+	txBuilder TxBuilder
+	nonces    *nonceTracker
+}
+
+type ChainBackendTransactorOpt func(*ChainBackendTransactor)
+
+// WithTxBuilder builds and signs transactions with a custom tx builder, instead of with the signer
+// of the transaction options.
+func WithTxBuilder(builder TxBuilder) ChainBackendTransactorOpt {
+	return func(d *ChainBackendTransactor) {
+		d.txBuilder = builder
+	}
 }
 
-func NewChainBackendTransactor(backend protocol.ChainBackend) *ChainBackendTransactor {
-	return &ChainBackendTransactor{
+// WithNonceHealing tracks the nonces of the transactions sent, rather than reading the pending nonce
+// of the node for each, and heals them when someone else sends transactions from the same key, such
+// as an operator sending one by hand. Transactions displaced by those are sent again in order, and
+// gaps below transactions waiting for their nonce are filled.
+func WithNonceHealing() ChainBackendTransactorOpt {
+	return func(d *ChainBackendTransactor) {
+		d.nonces = newNonceTracker(d.ChainBackend)
+	}
+}
+
+func NewChainBackendTransactor(backend protocol.ChainBackend, opts ...ChainBackendTransactorOpt) *ChainBackendTransactor {
+	d := &ChainBackendTransactor{
 		ChainBackend: backend,
 		fifo:         NewFIFO(1000),
 	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
 }
 
 func (d *ChainBackendTransactor) SendTransaction(ctx context.Context, fn func(opts *bind.TransactOpts) (*types.Transaction, error), opts *bind.TransactOpts, gas uint64) (*types.Transaction, error) {
@@ -86,13 +124,56 @@ func (d *ChainBackendTransactor) SendTransaction(ctx context.Context, fn func(op
 		<-time.After(100 * time.Millisecond)
 	}
 	defer d.fifo.Unlock()
-	tx, err := fn(opts)
+	if d.nonces != nil {
+		return d.nonces.send(ctx, opts.From, func(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+			nonceOpts := copyTxOpts(opts)
+			nonceOpts.Nonce = new(big.Int).SetUint64(nonce)
+			return buildTx(ctx, d.txBuilder, fn, nonceOpts)
+		}, func(ctx context.Context, template *types.Transaction) (*types.Transaction, error) {
+			if d.txBuilder != nil {
+				return d.txBuilder.BuildTx(ctx, opts.From, template)
+			}
+			return opts.Signer(opts.From, template)
+		})
+	}
+	tx, err := buildTx(ctx, d.txBuilder, fn, opts)
 	if err != nil {
 		return nil, err
 	}
 	return tx, d.ChainBackend.SendTransaction(ctx, tx)
 }
 
+// WaitMined waits for a transaction sent by the transactor to be mined, and returns the transaction
+// which was mined with its receipt. With nonce healing, it follows the transaction when it is
+// displaced and sent again, healing the nonces while it waits.
+func (d *ChainBackendTransactor) WaitMined(ctx context.Context, tx *types.Transaction) (*types.Transaction, *types.Receipt, error) {
+	if d.nonces == nil {
+		receipt, err := bind.WaitMined(ctx, d.ChainBackend, tx)
+		return tx, receipt, err
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		current := d.nonces.current(tx)
+		receipt, err := d.TransactionReceipt(ctx, current.Hash())
+		if err == nil && receipt != nil {
+			d.nonces.forget(tx)
+			return current, receipt, nil
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			log.Trace("Could not get transaction receipt", "txHash", current.Hash(), "err", err)
+		}
+		if err := d.nonces.heal(ctx); err != nil {
+			log.Warn("Could not heal nonces", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 // DataPoster is an interface that allows posting simple transactions without providing a nonce.
 // This is implemented in nitro repository.
 type DataPoster interface {
@@ -138,6 +219,21 @@ type AssertionChain struct {
 	specChallengeManager                     protocol.SpecChallengeManager
 	averageTimeForBlockCreation              time.Duration
 	transactor                               Transactor
+	inboxAccumulatorVerifier                 *l2stateprovider.InboxAccumulatorVerifier
+	upgradeCheckInterval                     time.Duration
+	logResultCap                             int
+	eventBus                                 *eventbus.Bus
+	intents                                  *intents.Journal
+	poison                                   *poison.Flag
+	manageStakeAllowance                     bool
+	revertReproDir                           string
+	stuckTxs                                 *stuckTxQueue
+	challengeManagerVersions                 map[common.Address]string
+	pauseCheckInterval                       time.Duration
+	pause                                    pauseWaiters
+	// Assertions being posted by their hash, so that concurrent callers posting the same assertion
+	// send a single transaction and all get the posted assertion.
+	postingAssertions *inprogresscache.Cache[common.Hash, protocol.Assertion]
 
 	// rpcHeadBlockNumber is the block number of the latest block on the chain.
 	// It is set to rpc.FinalizedBlockNumber by default.
@@ -159,12 +255,113 @@ func WithMetricsContractBackend() Opt {
 	}
 }
 
+// WithRpcRateLimiter bounds the rate of requests to the chain backend, which is shared by every
+// service of the validator, with the given limiter.
+func WithRpcRateLimiter(limiter *rate.Limiter) Opt {
+	return func(a *AssertionChain) {
+		a.backend = NewRateLimitedContractBackend(a.backend, limiter)
+	}
+}
+
+// WithRevertReproDir writes a cast script and a forge script replaying each transaction of the chain
+// which reverts unexpectedly to a directory, for contract engineers to debug the revert with.
+// Reproductions are attached to the errors of reverted transactions regardless.
+func WithRevertReproDir(dir string) Opt {
+	return func(a *AssertionChain) {
+		a.revertReproDir = dir
+	}
+}
+
+// WithMaxPendingTxAge sets how long the transactions sent for the same purpose may stay unconfirmed
+// before the latest is escalated to the manual intervention queue, rather than sent again forever.
+// Defaults to 30 minutes, and zero disables escalation.
+func WithMaxPendingTxAge(age time.Duration) Opt {
+	return func(a *AssertionChain) {
+		a.stuckTxs = newStuckTxQueue(age)
+	}
+}
+
+// WithChallengeManagerVersion binds to the challenge manager at an address with the bindings of a
+// version of the contract, one of ChallengeManagerVersions, rather than those of the default
+// version.
+func WithChallengeManagerVersion(addr common.Address, version string) Opt {
+	return func(a *AssertionChain) {
+		if a.challengeManagerVersions == nil {
+			a.challengeManagerVersions = make(map[common.Address]string)
+		}
+		a.challengeManagerVersions[addr] = version
+	}
+}
+
 func WithRpcHeadBlockNumber(rpcHeadBlockNumber rpc.BlockNumber) Opt {
 	return func(a *AssertionChain) {
 		a.rpcHeadBlockNumber = rpcHeadBlockNumber
 	}
 }
 
+// WithInboxAccumulatorVerifier checks the inbox accumulator of the state data of assertions
+// against the local sequencer batches before confirming edges by time.
+func WithInboxAccumulatorVerifier(verifier *l2stateprovider.InboxAccumulatorVerifier) Opt {
+	return func(a *AssertionChain) {
+		a.inboxAccumulatorVerifier = verifier
+	}
+}
+
+// WithUpgradeCheckInterval sets how often the challenge manager's proxy is checked for an upgrade,
+// which invalidates the cached values of its getters fixed at initialization. Defaults to a
+// minute, and zero disables the check.
+func WithUpgradeCheckInterval(interval time.Duration) Opt {
+	return func(a *AssertionChain) {
+		a.upgradeCheckInterval = interval
+	}
+}
+
+// WithLogResultCap sets the number of logs the provider truncates the result of eth_getLogs to,
+// such as 10,000, at which a result is assumed to be truncated and its block range narrowed and
+// requested again. Disabled by default.
+func WithLogResultCap(maxLogs int) Opt {
+	return func(a *AssertionChain) {
+		a.logResultCap = maxLogs
+	}
+}
+
+// WithEventBus publishes the lifecycle of each transaction sent by the assertion chain to the
+// event bus.
+func WithEventBus(bus *eventbus.Bus) Opt {
+	return func(a *AssertionChain) {
+		a.eventBus = bus
+	}
+}
+
+// WithIntentJournal records each assertion and level zero edge in the journal before posting it,
+// and refuses to post while the journal is paused after detecting split brain.
+func WithIntentJournal(journal *intents.Journal) Opt {
+	return func(a *AssertionChain) {
+		a.intents = journal
+	}
+}
+
+// WithPoisonFlag refuses to post anything while the flag is poisoned after an edge of ours lost by
+// one step proof.
+func WithPoisonFlag(flag *poison.Flag) Opt {
+	return func(a *AssertionChain) {
+		a.poison = flag
+	}
+}
+
+// PoisonFlag returns the poisoned flag posting is frozen behind, if any.
+func (a *AssertionChain) PoisonFlag() *poison.Flag {
+	return a.poison
+}
+
+// WithStakeAllowanceManagement approves the challenge manager to transfer the stake of a layer zero
+// edge from the staker when its allowance does not cover it, rather than failing to create the edge.
+func WithStakeAllowanceManagement() Opt {
+	return func(a *AssertionChain) {
+		a.manageStakeAllowance = true
+	}
+}
+
 // NewAssertionChain instantiates an assertion chain
 // instance from a chain backend and provided options.
 func NewAssertionChain(
@@ -188,10 +385,18 @@ func NewAssertionChain(
 		averageTimeForBlockCreation:              time.Second * 12,
 		transactor:                               transactor,
 		rpcHeadBlockNumber:                       rpc.FinalizedBlockNumber,
+		postingAssertions:                        inprogresscache.New[common.Hash, protocol.Assertion](),
+		upgradeCheckInterval:                     time.Minute,
+		stuckTxs:                                 newStuckTxQueue(defaultMaxPendingTxAge),
+		pauseCheckInterval:                       defaultPauseCheckInterval,
 	}
 	for _, opt := range opts {
 		opt(chain)
 	}
+	// Wraps the other backends, so the narrowed requests are also rate limited and measured.
+	if chain.logResultCap > 0 {
+		chain.backend = NewLogCapBackend(chain.backend, chain.logResultCap)
+	}
 	coreBinding, err := rollupgen.NewRollupCore(
 		rollupAddr, chain.backend,
 	)
@@ -357,13 +562,9 @@ func (a *AssertionChain) createAndStakeOnAssertion(
 	if postState.GlobalState.Batch == 0 {
 		return nil, errors.New("assertion post state cannot have a batch count of 0, as only genesis can")
 	}
-	bridgeAddr, err := a.userLogic.Bridge(a.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
-	if err != nil {
-		return nil, errors.Wrap(err, "could not retrieve bridge address for user rollup logic contract")
-	}
-	bridge, err := bridgegen.NewIBridgeCaller(bridgeAddr, a.backend)
+	bridge, err := a.bridge(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not initialize bridge at address %#x", bridgeAddr)
+		return nil, err
 	}
 	inboxBatchAcc, err := bridge.SequencerInboxAccs(
 		a.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}),
@@ -381,14 +582,34 @@ func (a *AssertionChain) createAndStakeOnAssertion(
 	if err != nil {
 		return nil, errors.Wrap(err, "could not compute assertion hash")
 	}
+	// Assertions are content addressed by their hash, which commits to their parent, post state and
+	// inbox position, so callers posting the same assertion concurrently share a single transaction.
+	return a.postingAssertions.Compute(computedHash, func() (protocol.Assertion, error) {
+		return a.postAssertion(ctx, parentAssertionCreationInfo, postState, computedHash, stakeFn)
+	})
+}
+
+// Posts an assertion with a precomputed hash, unless it already exists onchain, in which case the
+// existing assertion is returned instead.
+func (a *AssertionChain) postAssertion(
+	ctx context.Context,
+	parentAssertionCreationInfo *protocol.AssertionCreatedInfo,
+	postState *protocol.ExecutionState,
+	computedHash common.Hash,
+	stakeFn func(opts *bind.TransactOpts, requiredStake *big.Int, assertionInputs rollupgen.AssertionInputs, assertionHash [32]byte) (*types.Transaction, error),
+) (protocol.Assertion, error) {
 	existingAssertion, err := a.GetAssertion(ctx, protocol.AssertionHash{Hash: computedHash})
 	switch {
 	case err == nil:
+		assertionDedupedCounter.Inc(1)
 		return existingAssertion, nil
 	case !errors.Is(err, ErrNotFound):
 		return nil, errors.Wrapf(err, "could not fetch assertion with computed hash %#x", computedHash)
 	default:
 	}
+	if err = a.intents.RecordAssertion(computedHash); err != nil {
+		return nil, err
+	}
 	receipt, err := a.transact(ctx, a.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
 		return stakeFn(
 			opts,
@@ -411,13 +632,26 @@ func (a *AssertionChain) createAndStakeOnAssertion(
 			computedHash,
 		)
 	})
+	// The assertion, ours or one created by another poster with our key, did not exist yet at any
+	// block reads were pinned to.
+	ctx = protocol.UnpinReads(ctx)
+	if errors.Is(err, ErrRollupPaused) {
+		// Nothing can have created the assertion while the rollup is paused.
+		return nil, err
+	}
 	if createErr := handleCreateAssertionError(err, postState.GlobalState.BlockHash); createErr != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			assertionItem, err2 := a.GetAssertion(ctx, protocol.AssertionHash{Hash: computedHash})
-			if err2 != nil {
-				return nil, err2
-			}
+		// Another poster with our key, such as a second instance, may have created the assertion
+		// since we checked, making ours revert as already existing or already staked. The existing
+		// assertion is returned rather than the revert.
+		assertionItem, err2 := a.GetAssertion(ctx, protocol.AssertionHash{Hash: computedHash})
+		switch {
+		case err2 == nil:
+			assertionDedupedCounter.Inc(1)
+			log.Info("Assertion to post was already created", "assertionHash", computedHash, "err", createErr)
 			return assertionItem, nil
+		case !errors.Is(err2, ErrNotFound):
+			return nil, err2
+		default:
 		}
 		return nil, fmt.Errorf("could not create assertion: %w", createErr)
 	}
@@ -427,7 +661,7 @@ func (a *AssertionChain) createAndStakeOnAssertion(
 	var assertionCreated *rollupgen.RollupCoreAssertionCreated
 	var found bool
 	for _, log := range receipt.Logs {
-		creationEvent, err := a.rollup.ParseAssertionCreated(*log)
+		creationEvent, err := eventbus.DecodeLog(a.eventBus, *log, a.rollup.ParseAssertionCreated)
 		if err == nil {
 			assertionCreated = creationEvent
 			found = true
@@ -455,6 +689,57 @@ func (a *AssertionChain) MinAssertionPeriodBlocks(ctx context.Context) (uint64,
 	return minPeriod.Uint64(), nil
 }
 
+// BatchAccumulator reads the sequencer inbox accumulator after the batch with the given
+// sequence number from the bridge.
+func (a *AssertionChain) BatchAccumulator(ctx context.Context, batch l2stateprovider.Batch) (common.Hash, error) {
+	bridge, err := a.bridge(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	acc, err := bridge.SequencerInboxAccs(
+		a.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}),
+		new(big.Int).SetUint64(uint64(batch)),
+	)
+	if err != nil {
+		return common.Hash{}, errors.Wrapf(err, "could not read accumulator of batch %d", batch)
+	}
+	return acc, nil
+}
+
+func (a *AssertionChain) bridge(ctx context.Context) (*bridgegen.IBridgeCaller, error) {
+	bridgeAddr, err := a.userLogic.Bridge(a.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve bridge address for user rollup logic contract")
+	}
+	bridge, err := bridgegen.NewIBridgeCaller(bridgeAddr, a.backend)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not initialize bridge at address %#x", bridgeAddr)
+	}
+	return bridge, nil
+}
+
+// StakerAddress is the address the assertion chain sends transactions from.
+func (a *AssertionChain) StakerAddress() common.Address {
+	return a.txOpts.From
+}
+
+// ValidatorAllowlist reads which addresses the rollup allows to act as validators.
+func (a *AssertionChain) ValidatorAllowlist(ctx context.Context) (*protocol.ValidatorAllowlist, error) {
+	opts := a.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx})
+	disabled, err := a.userLogic.ValidatorWhitelistDisabled(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check if validator whitelist is disabled")
+	}
+	if disabled {
+		return &protocol.ValidatorAllowlist{Enabled: false, Validators: make([]common.Address, 0)}, nil
+	}
+	validators, err := a.userLogic.GetValidators(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get validators")
+	}
+	return &protocol.ValidatorAllowlist{Enabled: true, Validators: validators}, nil
+}
+
 func TryConfirmingAssertion(
 	ctx context.Context,
 	assertionHash common.Hash,
@@ -496,7 +781,11 @@ func TryConfirmingAssertion(
 					timeToWait,
 				),
 			)
-			<-time.After(timeToWait)
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-time.After(timeToWait):
+			}
 		} else {
 			break
 		}
@@ -606,6 +895,14 @@ func (a *AssertionChain) SpecChallengeManager(ctx context.Context) (protocol.Spe
 	return a.specChallengeManager, nil
 }
 
+// InvalidateImmutables drops the cached values of the challenge manager's getters fixed at
+// initialization, for when it is known to have been upgraded before the next upgrade check.
+func (a *AssertionChain) InvalidateImmutables() {
+	if cm, ok := a.specChallengeManager.(*specChallengeManager); ok {
+		cm.immutables.invalidate()
+	}
+}
+
 // AssertionUnrivaledBlocks gets the number of blocks an assertion was unrivaled. That is, it looks up the
 // assertion's parent, and from that parent, computes second_child_creation_block - first_child_creation_block.
 // If an assertion is a second child, this function will return 0.
@@ -756,7 +1053,7 @@ func (a *AssertionChain) LatestCreatedAssertion(ctx context.Context) (protocol.A
 		return nil, errors.New("no assertion creation events found")
 	}
 
-	creationEvent, err := a.rollup.ParseAssertionCreated(*latestLog)
+	creationEvent, err := eventbus.DecodeLog(a.eventBus, *latestLog, a.rollup.ParseAssertionCreated)
 	if err != nil {
 		return nil, err
 	}
@@ -849,7 +1146,7 @@ func (a *AssertionChain) ReadAssertionCreationInfo(
 		return nil, errors.New("found multiple instances of requested node")
 	}
 	ethLog := logs[0]
-	parsedLog, err := a.rollup.ParseAssertionCreated(ethLog)
+	parsedLog, err := eventbus.DecodeLog(a.eventBus, ethLog, a.rollup.ParseAssertionCreated)
 	if err != nil {
 		return nil, err
 	}
@@ -907,6 +1204,13 @@ func (a *AssertionChain) GetCallOptsWithDesiredRpcHeadBlockNumber(opts *bind.Cal
 	if opts == nil {
 		opts = &bind.CallOpts{}
 	}
+	// Reads pinned to a block for a decision are all made at that block, including in tests, so that
+	// tests see what a decision sees.
+	if pinned := protocol.PinnedReads(opts.Context); pinned.IsSome() {
+		pinnedReadsCounter.Inc(1)
+		opts.BlockNumber = new(big.Int).Set(pinned.Unwrap().Number)
+		return opts
+	}
 	// If we are running tests, we want to use the latest block number since
 	// simulated backends only support the latest block number.
 	if flag.Lookup("test.v") != nil {
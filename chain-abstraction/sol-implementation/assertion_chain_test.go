@@ -25,11 +25,17 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 )
 
+// Restores the default setup from a snapshot, which is much faster than deploying it for every test.
+var defaultSetup = setup.SnapshotOnce(func() (*setup.ChainSetup, error) {
+	return setup.ChainsWithEdgeChallengeManager()
+})
+
 func TestNewStakeOnNewAssertion(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 	backend := cfg.Backend
@@ -82,9 +88,58 @@ func TestNewStakeOnNewAssertion(t *testing.T) {
 	})
 }
 
+func TestNewStakeOnNewAssertion_ConcurrentPostsDeduplicated(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := defaultSetup()
+	require.NoError(t, err)
+	chain := cfg.Chains[0]
+	backend := cfg.Backend
+
+	genesisHash, err := chain.GenesisAssertionHash(ctx)
+	require.NoError(t, err)
+	genesisInfo, err := chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: genesisHash})
+	require.NoError(t, err)
+	latestBlockHash := common.Hash{}
+	for i := uint64(0); i < 100; i++ {
+		latestBlockHash = backend.Commit()
+	}
+	postState := &protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash: latestBlockHash,
+			Batch:     1,
+		},
+		MachineStatus: protocol.MachineStatusFinished,
+	}
+	nonceBefore, err := backend.PendingNonceAt(ctx, chain.StakerAddress())
+	require.NoError(t, err)
+
+	// Both callers get the same assertion, posted by a single transaction.
+	const numPosters = 4
+	ids := make([]protocol.AssertionHash, numPosters)
+	var eg errgroup.Group
+	for i := 0; i < numPosters; i++ {
+		i := i
+		eg.Go(func() error {
+			assertion, err := chain.NewStakeOnNewAssertion(ctx, genesisInfo, postState)
+			if err != nil {
+				return err
+			}
+			ids[i] = assertion.Id()
+			return nil
+		})
+	}
+	require.NoError(t, eg.Wait())
+	for _, id := range ids[1:] {
+		require.Equal(t, ids[0], id)
+	}
+	nonceAfter, err := backend.PendingNonceAt(ctx, chain.StakerAddress())
+	require.NoError(t, err)
+	require.Equal(t, nonceBefore+1, nonceAfter)
+}
+
 func TestStakeOnNewAssertion(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 	backend := cfg.Backend
@@ -193,7 +248,7 @@ func enqueueSequencerMessageAsExecutor(
 
 func TestAssertionUnrivaledBlocks(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 	backend := cfg.Backend
@@ -365,6 +420,13 @@ func TestConfirmAssertionByChallengeWinner(t *testing.T) {
 			ctx, createdData.Leaf1.Id(), honestEdge.Id(),
 		)
 		require.ErrorContains(t, err, "EDGE_NOT_CONFIRMED")
+		// The revert comes with a reproduction of the call against the rollup.
+		var revertErr *solimpl.RevertError
+		require.ErrorAs(t, err, &revertErr)
+		require.Equal(t, chain.RollupAddress(), revertErr.Repro.To)
+		require.Equal(t, chain.StakerAddress(), revertErr.Repro.From)
+		require.NotNil(t, revertErr.Repro.BlockNumber)
+		require.Contains(t, revertErr.Repro.CastCommands(), "cast call")
 	})
 	t.Run("level zero block edge confirmed allows assertion confirmation", func(t *testing.T) {
 		_, err = honestEdge.ConfirmByTimer(ctx)
@@ -394,7 +456,7 @@ func TestConfirmAssertionByChallengeWinner(t *testing.T) {
 
 func TestAssertionBySequenceNum(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 	latestConfirmed, err := chain.LatestConfirmed(ctx)
@@ -406,9 +468,26 @@ func TestAssertionBySequenceNum(t *testing.T) {
 	require.ErrorIs(t, err, solimpl.ErrNotFound)
 }
 
+func TestValidatorAllowlist(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := defaultSetup()
+	require.NoError(t, err)
+	chain := cfg.Chains[0]
+
+	// The test rollup disables its validator whitelist, so anyone may act as a validator.
+	allowlist, err := chain.ValidatorAllowlist(ctx)
+	require.NoError(t, err)
+	require.False(t, allowlist.Enabled)
+	require.True(t, allowlist.IsAllowed(chain.StakerAddress()))
+
+	allowlist = &protocol.ValidatorAllowlist{Enabled: true, Validators: []common.Address{cfg.Accounts[1].AccountAddr}}
+	require.True(t, allowlist.IsAllowed(cfg.Accounts[1].AccountAddr))
+	require.False(t, allowlist.IsAllowed(cfg.Accounts[2].AccountAddr))
+}
+
 func TestChallengePeriodBlocks(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 
@@ -521,7 +600,7 @@ func (mb *mockBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuer
 
 func TestLatestCreatedAssertion(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 
@@ -634,7 +713,7 @@ func TestLatestCreatedAssertion(t *testing.T) {
 
 func TestLatestCreatedAssertionHashes(t *testing.T) {
 	ctx := context.Background()
-	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	cfg, err := defaultSetup()
 	require.NoError(t, err)
 	chain := cfg.Chains[0]
 
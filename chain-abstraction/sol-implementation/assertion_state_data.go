@@ -0,0 +1,247 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// AsSolidityStruct converts the data to the struct expected by the edge challenge manager's
+// confirmEdgeByTime.
+func (d *AssertionStateData) AsSolidityStruct() challengeV2gen.AssertionStateData {
+	return challengeV2gen.AssertionStateData{
+		AssertionState: challengeV2gen.AssertionState{
+			GlobalState:    challengeV2gen.GlobalState(d.AssertionState.GlobalState),
+			MachineStatus:  d.AssertionState.MachineStatus,
+			EndHistoryRoot: d.AssertionState.EndHistoryRoot,
+		},
+		PrevAssertionHash: d.PrevAssertionHash,
+		InboxAcc:          d.InboxAcc,
+	}
+}
+
+// AssertionStateDataBuilder builds the state data of assertions from their creation info and,
+// if configured, the local execution state provider, and checks it against the rollup before
+// returning it.
+type AssertionStateDataBuilder struct {
+	chain            *AssertionChain
+	stateProvider    l2stateprovider.ExecutionProvider
+	inboxAccVerifier *l2stateprovider.InboxAccumulatorVerifier
+}
+
+type AssertionStateDataBuilderOpt func(*AssertionStateDataBuilder)
+
+// WithExecutionProvider computes the state of assertions using the given execution provider,
+// instead of taking the state recorded in their creation info.
+func WithExecutionProvider(stateProvider l2stateprovider.ExecutionProvider) AssertionStateDataBuilderOpt {
+	return func(b *AssertionStateDataBuilder) {
+		b.stateProvider = stateProvider
+	}
+}
+
+// WithInboxAccumulatorVerification checks the inbox accumulator of assertions against the one
+// reconstructed from the local sequencer batches. Defaults to the verifier of the assertion chain.
+func WithInboxAccumulatorVerification(verifier *l2stateprovider.InboxAccumulatorVerifier) AssertionStateDataBuilderOpt {
+	return func(b *AssertionStateDataBuilder) {
+		b.inboxAccVerifier = verifier
+	}
+}
+
+func NewAssertionStateDataBuilder(chain *AssertionChain, opts ...AssertionStateDataBuilderOpt) *AssertionStateDataBuilder {
+	b := &AssertionStateDataBuilder{
+		chain:            chain,
+		inboxAccVerifier: chain.inboxAccumulatorVerifier,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Build gets the state data of an assertion. It fails if the previous assertion hash or inbox
+// accumulator cannot be proven against chain history, if the rollup's validateAssertionHash
+// rejects the data, such as if the local execution state provider disagrees with the assertion,
+// or if the inbox accumulator does not match the local sequencer batches.
+func (b *AssertionStateDataBuilder) Build(ctx context.Context, assertionHash protocol.AssertionHash) (*AssertionStateData, error) {
+	info, err := b.chain.ReadAssertionCreationInfo(ctx, assertionHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read creation info for assertion %#x", assertionHash.Hash)
+	}
+	data := &AssertionStateData{
+		AssertionState:    info.AfterState,
+		PrevAssertionHash: info.ParentAssertionHash,
+		InboxAcc:          info.AfterInboxBatchAcc,
+	}
+	if err = b.chain.VerifyAssertionHistory(ctx, info, data); err != nil {
+		return nil, err
+	}
+	// The genesis assertion has no parent to execute from.
+	if b.stateProvider != nil && info.ParentAssertionHash != (common.Hash{}) {
+		state, localErr := b.localExecutionState(ctx, info)
+		if localErr != nil {
+			return nil, localErr
+		}
+		data.AssertionState = state.AsSolidityStruct()
+	}
+	// The accumulator is that of the last batch the assertion consumed, of which only genesis has none.
+	afterBatch := protocol.GoGlobalStateFromSolidity(info.AfterState.GlobalState).Batch
+	if b.inboxAccVerifier != nil && afterBatch > 0 {
+		batch := l2stateprovider.Batch(afterBatch - 1)
+		if err = b.inboxAccVerifier.Verify(ctx, batch, data.InboxAcc); err != nil {
+			return nil, errors.Wrapf(err, "could not verify inbox accumulator of assertion %#x", assertionHash.Hash)
+		}
+	}
+	if err = b.chain.userLogic.ValidateAssertionHash(
+		b.chain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}),
+		assertionHash.Hash,
+		data.AssertionState,
+		data.PrevAssertionHash,
+		data.InboxAcc,
+	); err != nil {
+		return nil, errors.Wrapf(err, "rollup rejected state data for assertion %#x", assertionHash.Hash)
+	}
+	return data, nil
+}
+
+// Computes the state of an assertion by executing from its parent's state, the same way the
+// assertion manager does when posting assertions.
+func (b *AssertionStateDataBuilder) localExecutionState(
+	ctx context.Context,
+	info *protocol.AssertionCreatedInfo,
+) (*protocol.ExecutionState, error) {
+	parentInfo, err := b.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: info.ParentAssertionHash})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read creation info for parent assertion %#x", info.ParentAssertionHash)
+	}
+	challengeManager, err := b.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	layerZeroHeights, err := challengeManager.LayerZeroHeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if layerZeroHeights.BlockChallengeHeight == 0 {
+		return nil, errors.New("block challenge height is zero")
+	}
+	parentGlobalState := protocol.GoGlobalStateFromSolidity(parentInfo.AfterState.GlobalState)
+	state, err := b.stateProvider.ExecutionStateAfterPreviousState(
+		ctx,
+		parentInfo.InboxMaxCount.Uint64(),
+		&parentGlobalState,
+		layerZeroHeights.BlockChallengeHeight-1,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not compute local execution state after parent assertion %#x", info.ParentAssertionHash)
+	}
+	return state, nil
+}
+
+// ErrAssertionHistoryMismatch is returned when the state data of an assertion does not correspond
+// to the assertion creation events and bridge accumulators of the parent chain.
+var ErrAssertionHistoryMismatch = errors.New("assertion state data does not match chain history")
+
+// VerifyAssertionHistory checks locally that the previous assertion hash and inbox accumulator of
+// an assertion's state data correspond to real chain history, given the assertion's creation info,
+// before the data is submitted to confirm an edge by time. It checks that:
+//   - the creation events of the assertion and its parent hash to the assertion hashes they
+//     were emitted for, so the previous assertion hash refers to an assertion which was created,
+//     and created no later than its child.
+//   - the inbox accumulator is the one the bridge recorded after the last batch the assertion
+//     consumed.
+//
+// Unlike the rollup's validateAssertionHash, these checks do not trust the rollup to hash the data
+// honestly, only the logs and storage read from the parent chain.
+func (a *AssertionChain) VerifyAssertionHistory(
+	ctx context.Context,
+	info *protocol.AssertionCreatedInfo,
+	data *AssertionStateData,
+) error {
+	if data.PrevAssertionHash != info.ParentAssertionHash {
+		return errors.Wrapf(
+			ErrAssertionHistoryMismatch,
+			"previous assertion hash %#x is not the parent %#x of assertion %#x",
+			data.PrevAssertionHash, info.ParentAssertionHash, info.AssertionHash,
+		)
+	}
+	if data.InboxAcc != info.AfterInboxBatchAcc {
+		return errors.Wrapf(
+			ErrAssertionHistoryMismatch,
+			"inbox accumulator %#x is not the one %#x assertion %#x was created with",
+			data.InboxAcc, info.AfterInboxBatchAcc, info.AssertionHash,
+		)
+	}
+	if err := verifyCreationEventHash(info); err != nil {
+		return err
+	}
+	// The genesis assertion has no parent.
+	if info.ParentAssertionHash != (common.Hash{}) {
+		parentInfo, err := a.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: info.ParentAssertionHash})
+		if err != nil {
+			return errors.Wrapf(err, "could not read creation info for parent assertion %#x", info.ParentAssertionHash)
+		}
+		if parentInfo.AssertionHash != info.ParentAssertionHash {
+			return errors.Wrapf(
+				ErrAssertionHistoryMismatch,
+				"creation event of parent assertion %#x is for assertion %#x",
+				info.ParentAssertionHash, parentInfo.AssertionHash,
+			)
+		}
+		if err = verifyCreationEventHash(parentInfo); err != nil {
+			return err
+		}
+		if parentInfo.CreationBlock > info.CreationBlock {
+			return errors.Wrapf(
+				ErrAssertionHistoryMismatch,
+				"parent assertion %#x was created at block %d, after its child %#x at block %d",
+				parentInfo.AssertionHash, parentInfo.CreationBlock, info.AssertionHash, info.CreationBlock,
+			)
+		}
+	}
+	// The accumulator is that of the last batch the assertion consumed, of which only genesis has none.
+	afterBatch := protocol.GoGlobalStateFromSolidity(data.AssertionState.GlobalState).Batch
+	if afterBatch == 0 {
+		if data.InboxAcc != (common.Hash{}) {
+			return errors.Wrapf(
+				ErrAssertionHistoryMismatch,
+				"assertion %#x consumed no batch, but has inbox accumulator %#x",
+				info.AssertionHash, data.InboxAcc,
+			)
+		}
+		return nil
+	}
+	bridgeAcc, err := a.BatchAccumulator(ctx, l2stateprovider.Batch(afterBatch-1))
+	if err != nil {
+		return err
+	}
+	if bridgeAcc != data.InboxAcc {
+		return errors.Wrapf(
+			ErrAssertionHistoryMismatch,
+			"inbox accumulator %#x of assertion %#x does not match the bridge's accumulator %#x after batch %d",
+			data.InboxAcc, info.AssertionHash, bridgeAcc, afterBatch-1,
+		)
+	}
+	return nil
+}
+
+// Checks that the data of an assertion creation event hashes to the assertion hash it was emitted
+// for.
+func verifyCreationEventHash(info *protocol.AssertionCreatedInfo) error {
+	computed := protocol.ComputeAssertionHash(info.ParentAssertionHash, info.AfterState, info.AfterInboxBatchAcc)
+	if computed.Hash != info.AssertionHash {
+		return errors.Wrapf(
+			ErrAssertionHistoryMismatch,
+			"creation event of assertion %#x hashes to %#x",
+			info.AssertionHash, computed.Hash,
+		)
+	}
+	return nil
+}
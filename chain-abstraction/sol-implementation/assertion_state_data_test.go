@@ -0,0 +1,144 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl_test
+
+import (
+	"context"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedExecutionProvider struct {
+	state *protocol.ExecutionState
+}
+
+func (p *fixedExecutionProvider) ExecutionStateAfterPreviousState(
+	_ context.Context, _ uint64, _ *protocol.GoGlobalState, _ uint64,
+) (*protocol.ExecutionState, error) {
+	return p.state, nil
+}
+
+func TestAssertionStateDataBuilder(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := defaultSetup()
+	require.NoError(t, err)
+	chain := cfg.Chains[0]
+
+	genesisHash, err := chain.GenesisAssertionHash(ctx)
+	require.NoError(t, err)
+	genesisInfo, err := chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: genesisHash})
+	require.NoError(t, err)
+	latestBlockHash := common.Hash{}
+	for i := uint64(0); i < 100; i++ {
+		latestBlockHash = cfg.Backend.Commit()
+	}
+	postState := &protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash: latestBlockHash,
+			Batch:     1,
+		},
+		MachineStatus: protocol.MachineStatusFinished,
+	}
+	assertion, err := chain.NewStakeOnNewAssertion(ctx, genesisInfo, postState)
+	require.NoError(t, err)
+	info, err := chain.ReadAssertionCreationInfo(ctx, assertion.Id())
+	require.NoError(t, err)
+
+	t.Run("from creation info", func(t *testing.T) {
+		data, err := solimpl.NewAssertionStateDataBuilder(chain).Build(ctx, assertion.Id())
+		require.NoError(t, err)
+		require.Equal(t, &solimpl.AssertionStateData{
+			AssertionState:    info.AfterState,
+			PrevAssertionHash: genesisHash,
+			InboxAcc:          info.AfterInboxBatchAcc,
+		}, data)
+	})
+	t.Run("genesis", func(t *testing.T) {
+		_, err := solimpl.NewAssertionStateDataBuilder(chain).Build(ctx, protocol.AssertionHash{Hash: genesisHash})
+		require.NoError(t, err)
+	})
+	t.Run("agreeing execution provider", func(t *testing.T) {
+		builder := solimpl.NewAssertionStateDataBuilder(
+			chain,
+			solimpl.WithExecutionProvider(&fixedExecutionProvider{state: postState}),
+		)
+		data, err := builder.Build(ctx, assertion.Id())
+		require.NoError(t, err)
+		require.Equal(t, postState, protocol.GoExecutionStateFromSolidity(data.AssertionState))
+	})
+	t.Run("disagreeing execution provider", func(t *testing.T) {
+		evilState := *postState
+		evilState.GlobalState.BlockHash = common.BytesToHash([]byte("evil"))
+		builder := solimpl.NewAssertionStateDataBuilder(
+			chain,
+			solimpl.WithExecutionProvider(&fixedExecutionProvider{state: &evilState}),
+		)
+		_, err := builder.Build(ctx, assertion.Id())
+		require.ErrorContains(t, err, "rollup rejected state data")
+	})
+	t.Run("inbox accumulator verification", func(t *testing.T) {
+		verifier := l2stateprovider.NewInboxAccumulatorVerifier(nil, l2stateprovider.WithTrustedInboxAccumulator(0, info.AfterInboxBatchAcc))
+		builder := solimpl.NewAssertionStateDataBuilder(chain, solimpl.WithInboxAccumulatorVerification(verifier))
+		_, err := builder.Build(ctx, assertion.Id())
+		require.NoError(t, err)
+
+		verifier = l2stateprovider.NewInboxAccumulatorVerifier(nil, l2stateprovider.WithTrustedInboxAccumulator(0, common.BytesToHash([]byte("evil"))))
+		builder = solimpl.NewAssertionStateDataBuilder(chain, solimpl.WithInboxAccumulatorVerification(verifier))
+		_, err = builder.Build(ctx, assertion.Id())
+		require.ErrorIs(t, err, l2stateprovider.ErrInboxAccumulatorMismatch)
+	})
+	t.Run("assertion hashes computed locally match the rollup", func(t *testing.T) {
+		for _, i := range []*protocol.AssertionCreatedInfo{genesisInfo, info} {
+			onchain, err := chain.RollupUserLogic().ComputeAssertionHash(&bind.CallOpts{Context: ctx}, i.ParentAssertionHash, i.AfterState, i.AfterInboxBatchAcc)
+			require.NoError(t, err)
+			require.Equal(t, common.Hash(onchain), protocol.ComputeAssertionHash(i.ParentAssertionHash, i.AfterState, i.AfterInboxBatchAcc).Hash)
+			require.Equal(t, i.AssertionHash, common.Hash(onchain))
+		}
+	})
+	t.Run("history verification", func(t *testing.T) {
+		data := &solimpl.AssertionStateData{
+			AssertionState:    info.AfterState,
+			PrevAssertionHash: genesisHash,
+			InboxAcc:          info.AfterInboxBatchAcc,
+		}
+		require.NoError(t, chain.VerifyAssertionHistory(ctx, info, data))
+
+		wrongPrev := *data
+		wrongPrev.PrevAssertionHash = common.BytesToHash([]byte("evil"))
+		require.ErrorIs(t, chain.VerifyAssertionHistory(ctx, info, &wrongPrev), solimpl.ErrAssertionHistoryMismatch)
+
+		wrongAcc := *data
+		wrongAcc.InboxAcc = common.BytesToHash([]byte("evil"))
+		require.ErrorIs(t, chain.VerifyAssertionHistory(ctx, info, &wrongAcc), solimpl.ErrAssertionHistoryMismatch)
+
+		// A creation event whose data does not hash to its assertion hash.
+		forged := *info
+		forged.AfterInboxBatchAcc = common.BytesToHash([]byte("evil"))
+		forgedData := *data
+		forgedData.InboxAcc = forged.AfterInboxBatchAcc
+		err := chain.VerifyAssertionHistory(ctx, &forged, &forgedData)
+		require.ErrorIs(t, err, solimpl.ErrAssertionHistoryMismatch)
+		require.ErrorContains(t, err, "hashes to")
+
+		// A consistent assertion whose inbox accumulator the bridge never recorded.
+		forged.AssertionHash = protocol.ComputeAssertionHash(forged.ParentAssertionHash, forged.AfterState, forged.AfterInboxBatchAcc).Hash
+		err = chain.VerifyAssertionHistory(ctx, &forged, &forgedData)
+		require.ErrorIs(t, err, solimpl.ErrAssertionHistoryMismatch)
+		require.ErrorContains(t, err, "bridge's accumulator")
+
+		// A parent which was never created.
+		forged = *info
+		forged.ParentAssertionHash = common.BytesToHash([]byte("missing"))
+		forged.AssertionHash = protocol.ComputeAssertionHash(forged.ParentAssertionHash, forged.AfterState, forged.AfterInboxBatchAcc).Hash
+		forgedData = *data
+		forgedData.PrevAssertionHash = forged.ParentAssertionHash
+		require.Error(t, chain.VerifyAssertionHistory(ctx, &forged, &forgedData))
+	})
+}
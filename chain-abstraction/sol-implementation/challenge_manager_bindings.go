@@ -0,0 +1,134 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultChallengeManagerVersion is the version of the challenge manager bindings used for
+// challenge managers whose version is not configured.
+const DefaultChallengeManagerVersion = "v2"
+
+// EdgeChallengeManagerCaller is the subset of the getters of the challenge manager used by the
+// spec challenge manager, which the bindings of every version of the contract implement. Values
+// are in the types of the current bindings, which the bindings of other versions convert to.
+type EdgeChallengeManagerCaller interface {
+	ChallengePeriodBlocks(opts *bind.CallOpts) (uint64, error)
+	FirstRival(opts *bind.CallOpts, mutualId [32]byte) ([32]byte, error)
+	GetEdge(opts *bind.CallOpts, edgeId [32]byte) (challengeV2gen.ChallengeEdge, error)
+	GetPrevAssertionHash(opts *bind.CallOpts, edgeId [32]byte) ([32]byte, error)
+	HasLengthOneRival(opts *bind.CallOpts, edgeId [32]byte) (bool, error)
+	HasMadeLayerZeroRival(opts *bind.CallOpts, account common.Address, mutualId [32]byte) (bool, error)
+	HasRival(opts *bind.CallOpts, edgeId [32]byte) (bool, error)
+	LAYERZEROBIGSTEPEDGEHEIGHT(opts *bind.CallOpts) (*big.Int, error)
+	LAYERZEROBLOCKEDGEHEIGHT(opts *bind.CallOpts) (*big.Int, error)
+	LAYERZEROSMALLSTEPEDGEHEIGHT(opts *bind.CallOpts) (*big.Int, error)
+	NUMBIGSTEPLEVEL(opts *bind.CallOpts) (uint8, error)
+	OneStepProofEntry(opts *bind.CallOpts) (common.Address, error)
+	StakeAmounts(opts *bind.CallOpts, level *big.Int) (*big.Int, error)
+	StakeToken(opts *bind.CallOpts) (common.Address, error)
+	TimeUnrivaled(opts *bind.CallOpts, edgeId [32]byte) (*big.Int, error)
+}
+
+// EdgeChallengeManagerTransactor is the subset of the methods of the challenge manager the spec
+// challenge manager sends transactions to.
+type EdgeChallengeManagerTransactor interface {
+	BisectEdge(opts *bind.TransactOpts, edgeId [32]byte, bisectionHistoryRoot [32]byte, prefixProof []byte) (*types.Transaction, error)
+	ConfirmEdgeByOneStepProof(
+		opts *bind.TransactOpts,
+		edgeId [32]byte,
+		oneStepData challengeV2gen.OneStepData,
+		prevConfig challengeV2gen.ConfigData,
+		beforeHistoryInclusionProof [][32]byte,
+		afterHistoryInclusionProof [][32]byte,
+	) (*types.Transaction, error)
+	ConfirmEdgeByTime(opts *bind.TransactOpts, edgeId [32]byte, claimStateData challengeV2gen.AssertionStateData) (*types.Transaction, error)
+	CreateLayerZeroEdge(opts *bind.TransactOpts, args challengeV2gen.CreateEdgeArgs) (*types.Transaction, error)
+	MultiUpdateTimeCacheByChildren(opts *bind.TransactOpts, edgeIds [][32]byte, maximumCachedTime *big.Int) (*types.Transaction, error)
+	UpdateTimerCacheByClaim(opts *bind.TransactOpts, edgeId [32]byte, claimingEdgeId [32]byte, maximumCachedTime *big.Int) (*types.Transaction, error)
+}
+
+// EdgeChallengeManagerFilterer is the subset of the events of the challenge manager the spec
+// challenge manager decodes from the receipts of its transactions.
+type EdgeChallengeManagerFilterer interface {
+	ParseEdgeAdded(log types.Log) (*challengeV2gen.EdgeChallengeManagerEdgeAdded, error)
+	ParseEdgeBisected(log types.Log) (*challengeV2gen.EdgeChallengeManagerEdgeBisected, error)
+}
+
+// ChallengeManagerBindings are the bindings of a challenge manager contract at an address.
+type ChallengeManagerBindings struct {
+	Caller     EdgeChallengeManagerCaller
+	Transactor EdgeChallengeManagerTransactor
+	Filterer   EdgeChallengeManagerFilterer
+}
+
+// ChallengeManagerBinder binds to the challenge manager at an address with the bindings generated
+// for one version of the contract.
+type ChallengeManagerBinder func(addr common.Address, backend bind.ContractBackend) (*ChallengeManagerBindings, error)
+
+var (
+	challengeManagerBindersLock sync.RWMutex
+	challengeManagerBinders     = map[string]ChallengeManagerBinder{
+		DefaultChallengeManagerVersion: bindChallengeManagerV2,
+	}
+)
+
+// RegisterChallengeManagerVersion makes the bindings of a version of the challenge manager
+// selectable with WithChallengeManagerVersion. Bindings of versions which are not deployed
+// everywhere, such as the next generation while it is being rolled out, are registered from files
+// built under a tag named after the version, such as challengev3, so that binaries only carry the
+// generations they are built for.
+func RegisterChallengeManagerVersion(version string, binder ChallengeManagerBinder) {
+	challengeManagerBindersLock.Lock()
+	defer challengeManagerBindersLock.Unlock()
+	challengeManagerBinders[version] = binder
+}
+
+// ChallengeManagerVersions lists the versions of the challenge manager bindings this binary was
+// built with.
+func ChallengeManagerVersions() []string {
+	challengeManagerBindersLock.RLock()
+	defer challengeManagerBindersLock.RUnlock()
+	versions := make([]string, 0, len(challengeManagerBinders))
+	for version := range challengeManagerBinders {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Binds to a challenge manager with the bindings of a version, or of the default version if empty.
+func bindChallengeManager(version string, addr common.Address, backend bind.ContractBackend) (*ChallengeManagerBindings, error) {
+	if version == "" {
+		version = DefaultChallengeManagerVersion
+	}
+	challengeManagerBindersLock.RLock()
+	binder, ok := challengeManagerBinders[version]
+	challengeManagerBindersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no bindings for challenge manager version %q, built with %v", version, ChallengeManagerVersions())
+	}
+	return binder(addr, backend)
+}
+
+func bindChallengeManagerV2(addr common.Address, backend bind.ContractBackend) (*ChallengeManagerBindings, error) {
+	binding, err := challengeV2gen.NewEdgeChallengeManager(addr, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ChallengeManagerBindings{
+		Caller:     &binding.EdgeChallengeManagerCaller,
+		Transactor: &binding.EdgeChallengeManagerTransactor,
+		Filterer:   &binding.EdgeChallengeManagerFilterer,
+	}, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeManagerVersions(t *testing.T) {
+	addr := common.HexToAddress("0x1234")
+	bindings, err := bindChallengeManager("", addr, nil)
+	require.NoError(t, err)
+	require.NotNil(t, bindings.Caller)
+
+	_, err = bindChallengeManager("v0", addr, nil)
+	require.ErrorContains(t, err, `no bindings for challenge manager version "v0"`)
+
+	var boundTo common.Address
+	RegisterChallengeManagerVersion("test", func(addr common.Address, backend bind.ContractBackend) (*ChallengeManagerBindings, error) {
+		boundTo = addr
+		return bindChallengeManagerV2(addr, backend)
+	})
+	t.Cleanup(func() {
+		challengeManagerBindersLock.Lock()
+		defer challengeManagerBindersLock.Unlock()
+		delete(challengeManagerBinders, "test")
+	})
+	require.Equal(t, []string{"test", DefaultChallengeManagerVersion}, ChallengeManagerVersions())
+	_, err = bindChallengeManager("test", addr, nil)
+	require.NoError(t, err)
+	require.Equal(t, addr, boundTo)
+}
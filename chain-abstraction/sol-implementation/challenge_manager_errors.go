@@ -0,0 +1,272 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// ChallengeManagerError is a custom error declared in the ABI of the edge challenge manager which
+// a call to it reverted with, decoded from the revert data of the call.
+type ChallengeManagerError struct {
+	Name string
+	// Arguments of the error by their name in the ABI. Bytes32 arguments are common.Hash.
+	Args map[string]any
+	// A typed error for the custom errors challenge logic branches on, such as
+	// *EdgeNotPendingError, or nil for the others.
+	Decoded error
+	// The error of the reverted call.
+	Err   error
+	names []string
+}
+
+func (e *ChallengeManagerError) Error() string {
+	args := make([]string, len(e.names))
+	for i, name := range e.names {
+		args[i] = fmt.Sprintf("%s: %v", name, e.Args[name])
+	}
+	return fmt.Sprintf("challenge manager reverted with %s(%s): %v", e.Name, strings.Join(args, ", "), e.Err)
+}
+
+// Unwrap to the typed error, if any, and to the error of the reverted call, so that errors.As finds
+// either.
+func (e *ChallengeManagerError) Unwrap() []error {
+	if e.Decoded != nil {
+		return []error{e.Decoded, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// IsChallengeManagerError checks whether an error is a revert of the challenge manager with the
+// custom error of a name, such as "EdgeNotPending".
+func IsChallengeManagerError(err error, name string) bool {
+	var cmErr *ChallengeManagerError
+	return errors.As(err, &cmErr) && cmErr.Name == name
+}
+
+// EdgeNotPendingError is the revert of an action on an edge which is already confirmed.
+type EdgeNotPendingError struct {
+	EdgeId protocol.EdgeId
+	Status protocol.EdgeStatus
+}
+
+func (e *EdgeNotPendingError) Error() string {
+	return fmt.Sprintf("edge %#x is not pending, it is %s", e.EdgeId.Hash, e.Status)
+}
+
+// EdgeNotConfirmedError is the revert of an action which needs an edge to be confirmed.
+type EdgeNotConfirmedError struct {
+	EdgeId protocol.EdgeId
+	Status protocol.EdgeStatus
+}
+
+func (e *EdgeNotConfirmedError) Error() string {
+	return fmt.Sprintf("edge %#x is not confirmed, it is %s", e.EdgeId.Hash, e.Status)
+}
+
+// EdgeAlreadyExistsError is the revert of the creation of an edge which was already created.
+type EdgeAlreadyExistsError struct {
+	EdgeId protocol.EdgeId
+}
+
+func (e *EdgeAlreadyExistsError) Error() string {
+	return fmt.Sprintf("edge %#x already exists", e.EdgeId.Hash)
+}
+
+// EdgeNotExistsError is the revert of an action on an edge which was never created.
+type EdgeNotExistsError struct {
+	EdgeId protocol.EdgeId
+}
+
+func (e *EdgeNotExistsError) Error() string {
+	return fmt.Sprintf("edge %#x does not exist", e.EdgeId.Hash)
+}
+
+// RivalEdgeConfirmedError is the revert of the confirmation of an edge whose rival was confirmed.
+type RivalEdgeConfirmedError struct {
+	EdgeId           protocol.EdgeId
+	ConfirmedRivalId protocol.EdgeId
+}
+
+func (e *RivalEdgeConfirmedError) Error() string {
+	return fmt.Sprintf("rival %#x of edge %#x is already confirmed", e.ConfirmedRivalId.Hash, e.EdgeId.Hash)
+}
+
+// EdgeUnrivaledError is the revert of an action which needs an edge to have a rival.
+type EdgeUnrivaledError struct {
+	EdgeId protocol.EdgeId
+}
+
+func (e *EdgeUnrivaledError) Error() string {
+	return fmt.Sprintf("edge %#x is unrivaled", e.EdgeId.Hash)
+}
+
+// EdgeNotLengthOneError is the revert of an action which needs an edge of length one.
+type EdgeNotLengthOneError struct {
+	Length *big.Int
+}
+
+func (e *EdgeNotLengthOneError) Error() string {
+	return fmt.Sprintf("edge has length %v, not length 1", e.Length)
+}
+
+// ClaimEdgeNotLengthOneRivalError is the revert of the creation of a subchallenge edge whose claim
+// has no length one rival.
+type ClaimEdgeNotLengthOneRivalError struct {
+	ClaimId protocol.ClaimId
+}
+
+func (e *ClaimEdgeNotLengthOneRivalError) Error() string {
+	return fmt.Sprintf("claimed edge %#x does not have a length one rival", common.Hash(e.ClaimId))
+}
+
+// CachedTimeSufficientError is the revert of a timer cache update for an edge which already
+// caches the desired timer.
+type CachedTimeSufficientError struct {
+	Actual   *big.Int
+	Expected *big.Int
+}
+
+func (e *CachedTimeSufficientError) Error() string {
+	return fmt.Sprintf("edge already caches a timer of %v, at least %v", e.Actual, e.Expected)
+}
+
+// InsufficientConfirmationBlocksError is the revert of the confirmation of an edge by time whose
+// timer is below the challenge period.
+type InsufficientConfirmationBlocksError struct {
+	TotalBlocks     *big.Int
+	ThresholdBlocks *big.Int
+}
+
+func (e *InsufficientConfirmationBlocksError) Error() string {
+	return fmt.Sprintf("edge timer of %v blocks is below the %v needed to confirm it", e.TotalBlocks, e.ThresholdBlocks)
+}
+
+// AccountHasMadeLayerZeroRivalError is the revert of the creation of a layer zero edge by an
+// account which already created one rivaling it.
+type AccountHasMadeLayerZeroRivalError struct {
+	Account  common.Address
+	MutualId protocol.MutualId
+}
+
+func (e *AccountHasMadeLayerZeroRivalError) Error() string {
+	return fmt.Sprintf("account %s already made a layer zero rival with mutual id %#x", e.Account.Hex(), common.Hash(e.MutualId))
+}
+
+// DecodeChallengeManagerError decodes the custom error of the challenge manager a call reverted
+// with into a *ChallengeManagerError wrapping the error of the call. Errors which are not reverts
+// with a custom error of the challenge manager are returned as they are.
+func DecodeChallengeManagerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var cmErr *ChallengeManagerError
+	if errors.As(err, &cmErr) {
+		return err
+	}
+	data := revertData(err)
+	if len(data) < 4 {
+		return err
+	}
+	managerAbi, abiErr := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if abiErr != nil {
+		return err
+	}
+	abiError, abiErr := managerAbi.ErrorByID([4]byte(data[:4]))
+	if abiErr != nil {
+		return err
+	}
+	vals, abiErr := abiError.Unpack(data)
+	if abiErr != nil || len(vals) != len(abiError.Inputs) {
+		return err
+	}
+	decoded := &ChallengeManagerError{
+		Name:  abiError.Name,
+		Args:  make(map[string]any, len(vals)),
+		Err:   err,
+		names: make([]string, len(vals)),
+	}
+	for i, val := range vals {
+		if b, ok := val.([32]byte); ok {
+			val = common.Hash(b)
+		}
+		decoded.names[i] = abiError.Inputs[i].Name
+		decoded.Args[abiError.Inputs[i].Name] = val
+	}
+	decoded.Decoded = typedChallengeManagerError(decoded.Name, vals)
+	return decoded
+}
+
+// The typed error of a custom error of the challenge manager, given the values of its arguments as
+// unpacked from the ABI, or nil if it has none.
+func typedChallengeManagerError(name string, vals []any) error {
+	hash := func(i int) common.Hash {
+		b, _ := vals[i].([32]byte)
+		return b
+	}
+	status := func(i int) protocol.EdgeStatus {
+		s, _ := vals[i].(uint8)
+		return protocol.EdgeStatus(s)
+	}
+	bigInt := func(i int) *big.Int {
+		n, _ := vals[i].(*big.Int)
+		return n
+	}
+	switch name {
+	case "EdgeNotPending":
+		return &EdgeNotPendingError{EdgeId: protocol.EdgeId{Hash: hash(0)}, Status: status(1)}
+	case "EdgeNotConfirmed":
+		return &EdgeNotConfirmedError{EdgeId: protocol.EdgeId{Hash: hash(0)}, Status: status(1)}
+	case "EdgeAlreadyExists":
+		return &EdgeAlreadyExistsError{EdgeId: protocol.EdgeId{Hash: hash(0)}}
+	case "EdgeNotExists":
+		return &EdgeNotExistsError{EdgeId: protocol.EdgeId{Hash: hash(0)}}
+	case "RivalEdgeConfirmed":
+		return &RivalEdgeConfirmedError{EdgeId: protocol.EdgeId{Hash: hash(0)}, ConfirmedRivalId: protocol.EdgeId{Hash: hash(1)}}
+	case "EdgeUnrivaled":
+		return &EdgeUnrivaledError{EdgeId: protocol.EdgeId{Hash: hash(0)}}
+	case "EdgeNotLengthOne":
+		return &EdgeNotLengthOneError{Length: bigInt(0)}
+	case "ClaimEdgeNotLengthOneRival":
+		return &ClaimEdgeNotLengthOneRivalError{ClaimId: protocol.ClaimId(hash(0))}
+	case "CachedTimeSufficient":
+		return &CachedTimeSufficientError{Actual: bigInt(0), Expected: bigInt(1)}
+	case "InsufficientConfirmationBlocks":
+		return &InsufficientConfirmationBlocksError{TotalBlocks: bigInt(0), ThresholdBlocks: bigInt(1)}
+	case "AccountHasMadeLayerZeroRival":
+		account, _ := vals[0].(common.Address)
+		return &AccountHasMadeLayerZeroRivalError{Account: account, MutualId: protocol.MutualId(hash(1))}
+	default:
+		return nil
+	}
+}
+
+// The revert data of a reverted call, if the node returned it with the error.
+func revertData(err error) []byte {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		b, decodeErr := hexutil.Decode(data)
+		if decodeErr != nil {
+			return nil
+		}
+		return b
+	case []byte:
+		return data
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"math/big"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type revertDataError struct {
+	data any
+}
+
+func (e *revertDataError) Error() string {
+	return "execution reverted"
+}
+
+func (e *revertDataError) ErrorData() any {
+	return e.data
+}
+
+func packChallengeManagerError(t *testing.T, name string, args ...any) string {
+	t.Helper()
+	managerAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	abiError, ok := managerAbi.Errors[name]
+	require.True(t, ok)
+	packed, err := abiError.Inputs.Pack(args...)
+	require.NoError(t, err)
+	return hexutil.Encode(append(abiError.ID[:4], packed...))
+}
+
+func TestDecodeChallengeManagerError(t *testing.T) {
+	edgeId := common.HexToHash("0x01")
+	rivalId := common.HexToHash("0x02")
+
+	t.Run("typed error", func(t *testing.T) {
+		reverted := &revertDataError{data: packChallengeManagerError(t, "EdgeNotPending", edgeId, protocol.EdgeConfirmed.Uint8())}
+		err := DecodeChallengeManagerError(errors.Wrap(reverted, "could not confirm edge"))
+
+		var notPending *EdgeNotPendingError
+		require.ErrorAs(t, err, &notPending)
+		require.Equal(t, protocol.EdgeId{Hash: edgeId}, notPending.EdgeId)
+		require.Equal(t, protocol.EdgeConfirmed, notPending.Status)
+		require.ErrorIs(t, err, reverted)
+		require.True(t, IsChallengeManagerError(err, "EdgeNotPending"))
+		require.False(t, IsChallengeManagerError(err, "EdgeAlreadyExists"))
+		require.ErrorContains(t, err, "EdgeNotPending(edgeId: 0x0000000000000000000000000000000000000000000000000000000000000001, status: 1)")
+
+		// Decoding again leaves the error as it is.
+		wrapped := errors.Wrap(err, "retrying")
+		require.Equal(t, wrapped, DecodeChallengeManagerError(wrapped))
+	})
+	t.Run("typed error with two edges", func(t *testing.T) {
+		err := DecodeChallengeManagerError(&revertDataError{data: packChallengeManagerError(t, "RivalEdgeConfirmed", edgeId, rivalId)})
+		var rivalConfirmed *RivalEdgeConfirmedError
+		require.ErrorAs(t, err, &rivalConfirmed)
+		require.Equal(t, protocol.EdgeId{Hash: edgeId}, rivalConfirmed.EdgeId)
+		require.Equal(t, protocol.EdgeId{Hash: rivalId}, rivalConfirmed.ConfirmedRivalId)
+	})
+	t.Run("typed error with amounts", func(t *testing.T) {
+		err := DecodeChallengeManagerError(&revertDataError{data: packChallengeManagerError(t, "CachedTimeSufficient", big.NewInt(10), big.NewInt(5))})
+		var cachedTimeSufficient *CachedTimeSufficientError
+		require.ErrorAs(t, err, &cachedTimeSufficient)
+		require.Equal(t, big.NewInt(10), cachedTimeSufficient.Actual)
+		require.Equal(t, big.NewInt(5), cachedTimeSufficient.Expected)
+	})
+	t.Run("untyped error", func(t *testing.T) {
+		err := DecodeChallengeManagerError(&revertDataError{data: packChallengeManagerError(t, "LevelTooHigh", uint8(4), uint8(2))})
+		var cmErr *ChallengeManagerError
+		require.ErrorAs(t, err, &cmErr)
+		require.Equal(t, "LevelTooHigh", cmErr.Name)
+		require.Equal(t, map[string]any{"level": uint8(4), "numBigStepLevels": uint8(2)}, cmErr.Args)
+		require.Nil(t, cmErr.Decoded)
+	})
+	t.Run("revert data as bytes", func(t *testing.T) {
+		data := hexutil.MustDecode(packChallengeManagerError(t, "EdgeAlreadyExists", edgeId))
+		var exists *EdgeAlreadyExistsError
+		require.ErrorAs(t, DecodeChallengeManagerError(&revertDataError{data: data}), &exists)
+		require.Equal(t, protocol.EdgeId{Hash: edgeId}, exists.EdgeId)
+	})
+	t.Run("not a custom error of the challenge manager", func(t *testing.T) {
+		for _, err := range []error{
+			errors.New("connection refused"),
+			&revertDataError{data: "0x"},
+			&revertDataError{data: "0xdeadbeef"},
+			&revertDataError{data: 42},
+		} {
+			require.Equal(t, err, DecodeChallengeManagerError(err))
+		}
+		require.NoError(t, DecodeChallengeManagerError(nil))
+	})
+}
@@ -0,0 +1,462 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/util/objectstore"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	scanReorgsCounter         = metrics.NewRegisteredCounter("arb/validator/scanner/reorgs", nil)
+	scanLogLimitSplitsCounter = metrics.NewRegisteredCounter("arb/validator/scanner/log_limit_splits", nil)
+	scanLastBlockGauge        = metrics.NewRegisteredGauge("arb/validator/scanner/last_processed_block", nil)
+)
+
+const (
+	defaultScanChunkBlocks   = 10_000
+	defaultScanReorgDepth    = 64
+	defaultScanRetryInterval = 5 * time.Second
+)
+
+// The errors providers return for a log query over too many blocks or matching too many logs,
+// rather than a truncated result, which LogCapBackend guards against.
+var logLimitErrors = []string{
+	"query returned more than",
+	"block range",
+	"range is too large",
+	"range too large",
+	"response size exceeded",
+	"log response size",
+	"limit exceeded",
+	"too many",
+}
+
+func isLogLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range logLimitErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanPosition is the last block whose events a scanner processed, along with its hash, with which
+// a reorg of the block is detected.
+type ScanPosition struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// ScanCheckpointStore persists the position of a scanner, so that it resumes after the last block it
+// processed rather than from its start block.
+type ScanCheckpointStore interface {
+	GetScanPosition() (option.Option[ScanPosition], error)
+	PutScanPosition(ScanPosition) error
+}
+
+// MemoryScanCheckpointStore keeps the position of a scanner in memory, so that a scan which is
+// restarted, such as after its subscription drops, resumes where it was.
+type MemoryScanCheckpointStore struct {
+	lock     sync.Mutex
+	position option.Option[ScanPosition]
+}
+
+func NewMemoryScanCheckpointStore() *MemoryScanCheckpointStore {
+	return &MemoryScanCheckpointStore{}
+}
+
+func (s *MemoryScanCheckpointStore) GetScanPosition() (option.Option[ScanPosition], error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.position, nil
+}
+
+func (s *MemoryScanCheckpointStore) PutScanPosition(position ScanPosition) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.position = option.Some(position)
+	return nil
+}
+
+// The time allowed for each read or write of a position in a bucket.
+const scanCheckpointTimeout = time.Minute
+
+// BucketScanCheckpointStore persists the position of a scanner as a JSON object in a bucket, such as
+// one in object storage, so that it survives restarts.
+type BucketScanCheckpointStore struct {
+	bucket objectstore.Bucket
+	name   string
+}
+
+func NewBucketScanCheckpointStore(bucket objectstore.Bucket, name string) *BucketScanCheckpointStore {
+	return &BucketScanCheckpointStore{bucket: bucket, name: name}
+}
+
+func (s *BucketScanCheckpointStore) GetScanPosition() (option.Option[ScanPosition], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), scanCheckpointTimeout)
+	defer cancel()
+	data, err := s.bucket.Get(ctx, s.name)
+	if err != nil {
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return option.None[ScanPosition](), nil
+		}
+		return option.None[ScanPosition](), err
+	}
+	var position ScanPosition
+	if err = json.Unmarshal(data, &position); err != nil {
+		return option.None[ScanPosition](), fmt.Errorf("could not decode scan position %s: %w", s.name, err)
+	}
+	return option.Some(position), nil
+}
+
+func (s *BucketScanCheckpointStore) PutScanPosition(position ScanPosition) error {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), scanCheckpointTimeout)
+	defer cancel()
+	return s.bucket.Put(ctx, s.name, data)
+}
+
+// EdgeAddedHandler processes an edge added event. Events removed from the chain by a reorg are
+// delivered again with their Raw.Removed flag set. Events may be delivered more than once, such as
+// after a restart resumes from the last checkpoint, so handlers must be idempotent.
+type EdgeAddedHandler func(ctx context.Context, ev *challengeV2gen.EdgeChallengeManagerEdgeAdded) error
+
+// ScanBackend is what a scanner reads events and headers with.
+type ScanBackend interface {
+	bind.ContractFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+type ScannerOpt func(*EdgeAddedScanner)
+
+// WithScanCheckpointStore sets where the scanner persists the last block it processed. Defaults to
+// memory, so a new scanner starts from its start block.
+func WithScanCheckpointStore(store ScanCheckpointStore) ScannerOpt {
+	return func(s *EdgeAddedScanner) {
+		s.checkpoints = store
+	}
+}
+
+// WithScanChunkBlocks sets the number of blocks requested at once while backfilling. Chunks over
+// which the provider refuses to return logs are halved until it does. Defaults to 10,000.
+func WithScanChunkBlocks(blocks uint64) ScannerOpt {
+	return func(s *EdgeAddedScanner) {
+		s.chunkBlocks = blocks
+	}
+}
+
+// WithScanReorgDepth sets how many blocks before its checkpoint the scanner rescans from if the
+// checkpointed block was reorged out. Defaults to 64.
+func WithScanReorgDepth(blocks uint64) ScannerOpt {
+	return func(s *EdgeAddedScanner) {
+		s.reorgDepth = blocks
+	}
+}
+
+// WithScanRetryInterval sets how long the scanner waits to resume after failing to read from the
+// chain or losing its subscription. Defaults to 5 seconds.
+func WithScanRetryInterval(interval time.Duration) ScannerOpt {
+	return func(s *EdgeAddedScanner) {
+		s.retryInterval = interval
+	}
+}
+
+// EdgeAddedScanner delivers the edge added events of a challenge manager from a start block onwards,
+// backfilling historical blocks in chunks before switching to a live subscription. The last block
+// processed is checkpointed, so a scan resumes after it, and rescans from before it if it was
+// reorged out.
+type EdgeAddedScanner struct {
+	filterer      *challengeV2gen.EdgeChallengeManagerFilterer
+	backend       ScanBackend
+	startBlock    uint64
+	checkpoints   ScanCheckpointStore
+	chunkBlocks   uint64
+	reorgDepth    uint64
+	retryInterval time.Duration
+}
+
+func NewEdgeAddedScanner(
+	challengeManager common.Address,
+	backend ScanBackend,
+	startBlock uint64,
+	opts ...ScannerOpt,
+) (*EdgeAddedScanner, error) {
+	filterer, err := challengeV2gen.NewEdgeChallengeManagerFilterer(challengeManager, backend)
+	if err != nil {
+		return nil, err
+	}
+	s := &EdgeAddedScanner{
+		filterer:      filterer,
+		backend:       backend,
+		startBlock:    startBlock,
+		checkpoints:   NewMemoryScanCheckpointStore(),
+		chunkBlocks:   defaultScanChunkBlocks,
+		reorgDepth:    defaultScanReorgDepth,
+		retryInterval: defaultScanRetryInterval,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.chunkBlocks == 0 {
+		return nil, errors.New("scan chunk blocks must be positive")
+	}
+	return s, nil
+}
+
+// Backfill delivers the edge added events in an inclusive block range, requesting them in chunks.
+// It neither reads nor writes the checkpoint.
+func (s *EdgeAddedScanner) Backfill(ctx context.Context, fromBlock, toBlock uint64, handler EdgeAddedHandler) error {
+	return s.backfill(ctx, fromBlock, toBlock, handler, nil)
+}
+
+// Delivers the events of a block range chunk by chunk, calling done with the last block of each
+// chunk once its events are handled.
+func (s *EdgeAddedScanner) backfill(
+	ctx context.Context,
+	fromBlock,
+	toBlock uint64,
+	handler EdgeAddedHandler,
+	done func(block uint64) error,
+) error {
+	if fromBlock > toBlock {
+		return errors.Errorf("invalid block range: end %d was < start %d", toBlock, fromBlock)
+	}
+	chunk := s.chunkBlocks
+	for start := fromBlock; start <= toBlock; {
+		end := toBlock
+		if toBlock-start >= chunk {
+			end = start + chunk - 1
+		}
+		events, err := s.filterEdgeAdded(ctx, start, end)
+		if isLogLimitError(err) && end > start {
+			// The chunk stays halved, as later blocks likely hold as many events.
+			scanLogLimitSplitsCounter.Inc(1)
+			chunk = max((end-start+1)/2, 1)
+			log.Warn("Provider refused to return the logs of a block range, narrowing it", "fromBlock", start, "toBlock", end, "err", err)
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not filter edge added events from block %d to %d", start, end)
+		}
+		for _, ev := range events {
+			if err = handler(ctx, ev); err != nil {
+				return err
+			}
+		}
+		if done != nil {
+			if err = done(end); err != nil {
+				return err
+			}
+		}
+		if end == toBlock {
+			break
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+func (s *EdgeAddedScanner) filterEdgeAdded(ctx context.Context, fromBlock, toBlock uint64) ([]*challengeV2gen.EdgeChallengeManagerEdgeAdded, error) {
+	it, err := s.filterer.FilterEdgeAdded(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: ctx}, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := it.Close(); closeErr != nil {
+			log.Error("Could not close filter iterator", "err", closeErr)
+		}
+	}()
+	events := make([]*challengeV2gen.EdgeChallengeManagerEdgeAdded, 0)
+	for it.Next() {
+		events = append(events, it.Event)
+	}
+	return events, it.Error()
+}
+
+// Scan delivers the edge added events from the block after the checkpoint, or the start block if
+// there is none, backfilling up to the latest block before following new events as they are
+// emitted. Failures to read from the chain and dropped subscriptions are retried, resuming from
+// the checkpoint, so Scan only returns once the context is done or the handler fails.
+func (s *EdgeAddedScanner) Scan(ctx context.Context, handler EdgeAddedHandler) error {
+	for {
+		err := s.scan(ctx, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var handlerErr *scanHandlerError
+		if errors.As(err, &handlerErr) {
+			return handlerErr.err
+		}
+		log.Warn("Edge added scan interrupted, resuming from the last checkpoint", "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+// Distinguishes the errors of the handler, which stop a scan, from those of the chain, which are
+// retried.
+type scanHandlerError struct {
+	err error
+}
+
+func (e *scanHandlerError) Error() string {
+	return e.err.Error()
+}
+
+func (s *EdgeAddedScanner) scan(ctx context.Context, handler EdgeAddedHandler) error {
+	handle := func(ctx context.Context, ev *challengeV2gen.EdgeChallengeManagerEdgeAdded) error {
+		if err := handler(ctx, ev); err != nil {
+			return &scanHandlerError{err: err}
+		}
+		return nil
+	}
+	position, err := s.resumePosition(ctx)
+	if err != nil {
+		return err
+	}
+	// The subscription is made before backfilling, so no event is missed in between. The events it
+	// delivers for blocks the backfill covered are skipped.
+	sink := make(chan *challengeV2gen.EdgeChallengeManagerEdgeAdded, 64)
+	sub, err := s.filterer.WatchEdgeAdded(&bind.WatchOpts{Context: ctx}, sink, nil, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not subscribe to edge added events")
+	}
+	defer sub.Unsubscribe()
+
+	head, err := s.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not get latest header")
+	}
+	if !head.Number.IsUint64() {
+		return errors.New("latest block number is not a uint64")
+	}
+	backfilledTo := head.Number.Uint64()
+	next := s.startBlock
+	if position.IsSome() {
+		next = position.Unwrap().Number + 1
+	}
+	if next <= backfilledTo {
+		if err = s.backfill(ctx, next, backfilledTo, handle, func(block uint64) error {
+			return s.checkpointBlock(ctx, block)
+		}); err != nil {
+			return err
+		}
+	} else if next > 0 {
+		backfilledTo = next - 1
+	}
+
+	// The block of the last event delivered, which is only checkpointed once an event of a later
+	// block shows all its events were delivered.
+	var pending option.Option[ScanPosition]
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return errors.Wrap(err, "edge added subscription failed")
+		case ev := <-sink:
+			if ev.Raw.Removed {
+				scanReorgsCounter.Inc(1)
+				if err = handle(ctx, ev); err != nil {
+					return err
+				}
+				// Events of the new chain may be at the same heights as those removed, so they
+				// must not be skipped as backfilled, and the checkpoint is moved before them.
+				if ev.Raw.BlockNumber > 0 && ev.Raw.BlockNumber <= backfilledTo {
+					backfilledTo = ev.Raw.BlockNumber - 1
+					if err = s.checkpointBlock(ctx, backfilledTo); err != nil {
+						return err
+					}
+				}
+				pending = option.None[ScanPosition]()
+				continue
+			}
+			if ev.Raw.BlockNumber <= backfilledTo {
+				continue
+			}
+			if pending.IsSome() && pending.Unwrap().Number < ev.Raw.BlockNumber {
+				if err = s.putPosition(pending.Unwrap()); err != nil {
+					return err
+				}
+			}
+			if err = handle(ctx, ev); err != nil {
+				return err
+			}
+			pending = option.Some(ScanPosition{Number: ev.Raw.BlockNumber, Hash: ev.Raw.BlockHash})
+		}
+	}
+}
+
+// Reads the checkpoint, moving it back by the reorg depth if its block is no longer in the chain.
+func (s *EdgeAddedScanner) resumePosition(ctx context.Context) (option.Option[ScanPosition], error) {
+	position, err := s.checkpoints.GetScanPosition()
+	if err != nil {
+		return option.None[ScanPosition](), errors.Wrap(err, "could not read scan checkpoint")
+	}
+	if position.IsNone() {
+		return position, nil
+	}
+	checkpoint := position.Unwrap()
+	header, err := s.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(checkpoint.Number))
+	if err != nil {
+		return option.None[ScanPosition](), errors.Wrapf(err, "could not get header of checkpointed block %d", checkpoint.Number)
+	}
+	if header.Hash() == checkpoint.Hash {
+		return position, nil
+	}
+	scanReorgsCounter.Inc(1)
+	if checkpoint.Number < s.startBlock+s.reorgDepth {
+		log.Warn("Checkpointed block was reorged out, rescanning from the start block", "block", checkpoint.Number, "startBlock", s.startBlock)
+		return option.None[ScanPosition](), nil
+	}
+	rewound := checkpoint.Number - s.reorgDepth
+	log.Warn("Checkpointed block was reorged out, rescanning from before it", "block", checkpoint.Number, "fromBlock", rewound+1)
+	if err = s.checkpointBlock(ctx, rewound); err != nil {
+		return option.None[ScanPosition](), err
+	}
+	return s.checkpoints.GetScanPosition()
+}
+
+// Checkpoints a block with its hash as of the latest chain.
+func (s *EdgeAddedScanner) checkpointBlock(ctx context.Context, block uint64) error {
+	header, err := s.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return errors.Wrapf(err, "could not get header of block %d", block)
+	}
+	return s.putPosition(ScanPosition{Number: block, Hash: header.Hash()})
+}
+
+func (s *EdgeAddedScanner) putPosition(position ScanPosition) error {
+	if err := s.checkpoints.PutScanPosition(position); err != nil {
+		return errors.Wrap(err, "could not write scan checkpoint")
+	}
+	scanLastBlockGauge.Update(int64(position.Number))
+	return nil
+}
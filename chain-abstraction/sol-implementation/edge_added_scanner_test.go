@@ -0,0 +1,128 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/containers/option"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	challenge_testing "github.com/OffchainLabs/bold/testing"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Refuses log queries over more than a number of blocks, as some providers do.
+type rangeLimitedBackend struct {
+	*setup.SimulatedBackendWrapper
+	maxBlocks uint64
+}
+
+func (b *rangeLimitedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if query.FromBlock != nil && query.ToBlock != nil && query.ToBlock.Uint64()-query.FromBlock.Uint64()+1 > b.maxBlocks {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	return b.SimulatedBackendWrapper.FilterLogs(ctx, query)
+}
+
+func TestEdgeAddedScanner(t *testing.T) {
+	ctx := context.Background()
+	scenario := setupBisectionScenario(t)
+	backend := &rangeLimitedBackend{SimulatedBackendWrapper: scenario.topLevelFork.Backend, maxBlocks: 4}
+	challengeManager, err := scenario.topLevelFork.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	head, err := backend.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	levelZeroEdges := []common.Hash{scenario.honestLevelZeroEdge.Id().Hash, scenario.evilLevelZeroEdge.Id().Hash}
+
+	t.Run("backfills in chunks the provider accepts", func(t *testing.T) {
+		scanner, err := solimpl.NewEdgeAddedScanner(challengeManager.Address(), backend, 0)
+		require.NoError(t, err)
+		edges := make([]common.Hash, 0)
+		err = scanner.Backfill(ctx, 0, head.Number.Uint64(), func(_ context.Context, ev *challengeV2gen.EdgeChallengeManagerEdgeAdded) error {
+			edges = append(edges, ev.EdgeId)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, levelZeroEdges, edges)
+	})
+
+	t.Run("resumes from its checkpoint and follows new events", func(t *testing.T) {
+		checkpoints := solimpl.NewMemoryScanCheckpointStore()
+		newScan := func() (chan common.Hash, context.CancelFunc, chan error) {
+			scanner, err := solimpl.NewEdgeAddedScanner(
+				challengeManager.Address(),
+				backend,
+				0,
+				solimpl.WithScanCheckpointStore(checkpoints),
+				solimpl.WithScanRetryInterval(10*time.Millisecond),
+			)
+			require.NoError(t, err)
+			edges := make(chan common.Hash, 10)
+			scanCtx, cancel := context.WithCancel(ctx)
+			done := make(chan error, 1)
+			go func() {
+				done <- scanner.Scan(scanCtx, func(_ context.Context, ev *challengeV2gen.EdgeChallengeManagerEdgeAdded) error {
+					edges <- ev.EdgeId
+					return nil
+				})
+			}()
+			return edges, cancel, done
+		}
+		receive := func(edges chan common.Hash, n int) []common.Hash {
+			got := make([]common.Hash, 0, n)
+			for len(got) < n {
+				select {
+				case edge := <-edges:
+					got = append(got, edge)
+				case <-time.After(10 * time.Second):
+					t.Fatalf("received %d of %d edges", len(got), n)
+				}
+			}
+			return got
+		}
+
+		edges, cancel, done := newScan()
+		require.Equal(t, levelZeroEdges, receive(edges, 2))
+		require.Eventually(t, func() bool {
+			position, err := checkpoints.GetScanPosition()
+			require.NoError(t, err)
+			return position.IsSome() && position.Unwrap().Number == head.Number.Uint64()
+		}, 10*time.Second, 10*time.Millisecond)
+
+		req := &l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              common.Hash{},
+			FromBatch:                   0,
+			ToBatch:                     1,
+			UpperChallengeOriginHeights: []l2stateprovider.Height{},
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(l2stateprovider.Height(challenge_testing.LevelZeroBlockEdgeHeight / 2)),
+		}
+		bisectCommit, err := scenario.honestStateManager.HistoryCommitment(ctx, req)
+		require.NoError(t, err)
+		req.UpToHeight = option.Some(l2stateprovider.Height(challenge_testing.LevelZeroBlockEdgeHeight))
+		proof, err := scenario.honestStateManager.PrefixProof(ctx, req, challenge_testing.LevelZeroBlockEdgeHeight/2)
+		require.NoError(t, err)
+		lower, upper, err := scenario.honestLevelZeroEdge.Bisect(ctx, bisectCommit.Merkle, proof)
+		require.NoError(t, err)
+		children := []common.Hash{lower.Id().Hash, upper.Id().Hash}
+		require.ElementsMatch(t, children, receive(edges, 2))
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+
+		// A new scan resumes after the checkpoint, so the level zero edges are not delivered again.
+		edges, cancel, done = newScan()
+		require.ElementsMatch(t, children, receive(edges, 2))
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+}
@@ -18,11 +18,11 @@ import (
 	"github.com/OffchainLabs/bold/solgen/go/ospgen"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	"github.com/OffchainLabs/bold/util/eventbus"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/pkg/errors"
 )
@@ -106,7 +106,7 @@ func (e *specEdge) Status(ctx context.Context) (protocol.EdgeStatus, error) {
 	if err != nil {
 		return 0, err
 	}
-	return protocol.EdgeStatus(edge.Status), nil
+	return protocol.EdgeStatusFromUint8(edge.Status)
 }
 
 func (e *specEdge) ConfirmedAtBlock(ctx context.Context) (uint64, error) {
@@ -195,11 +195,13 @@ func (e *specEdge) HasLengthOneRival(ctx context.Context) (bool, error) {
 	}
 	ok, err := e.manager.caller.HasLengthOneRival(e.manager.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}), e.id)
 	if err != nil {
-		errS := err.Error()
+		err = DecodeChallengeManagerError(err)
+		var notLengthOne *EdgeNotLengthOneError
+		var unrivaled *EdgeUnrivaledError
 		switch {
-		case strings.Contains(errS, "not length 1"):
+		case errors.As(err, &notLengthOne):
 			return false, nil
-		case strings.Contains(errS, "is unrivaled"):
+		case errors.As(err, &unrivaled):
 			return false, nil
 		default:
 			return false, err
@@ -250,37 +252,61 @@ func (e *specEdge) Bisect(
 		return lower, upper, nil
 	}
 
-	_, err = e.manager.assertionChain.transact(ctx, e.manager.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
-		return e.manager.writer.BisectEdge(opts, e.id, prefixHistoryRoot, prefixProof)
-	})
+	// The children are known before bisecting, so the bisection event can be matched against them
+	// and they can be read without refreshing the edge.
+	_, expectedLower, expectedUpper, err := protocol.ComputeBisectionChildIds(e, prefixHistoryRoot)
 	if err != nil {
 		return nil, nil, err
 	}
-	someEdge, err := e.manager.GetEdge(ctx, protocol.EdgeId{Hash: e.id})
-	if err != nil {
+	if err = e.manager.assertionChain.intents.CheckNotPaused(); err != nil {
 		return nil, nil, err
 	}
-	if someEdge.IsNone() {
-		return nil, nil, errors.New("could not refresh edge after bisecting, got empty result")
+	receipt, err := e.manager.transact(ctx, e.manager.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return e.manager.writer.BisectEdge(opts, e.id, prefixHistoryRoot, prefixProof)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	edge, ok := someEdge.Unwrap().(*specEdge)
-	if !ok {
-		return nil, nil, errors.New("not a *SpecEdge")
+	// Reads pinned to a block from before the bisection would not find its children.
+	ctx = protocol.UnpinReads(ctx)
+	// A rival which agrees with us up to the bisection point may have bisected first,
+	// creating our lower child, in which case the bisection adopts it.
+	var lowerChildAlreadyExists bool
+	for _, log := range receipt.Logs {
+		bisected, parseErr := eventbus.DecodeLog(e.manager.assertionChain.eventBus, *log, e.manager.filterer.ParseEdgeBisected)
+		if parseErr == nil && bisected.EdgeId == e.id {
+			if bisected.LowerChildId != expectedLower.Hash || bisected.UpperChildId != expectedUpper.Hash {
+				return nil, nil, fmt.Errorf(
+					"bisection of edge %#x created children lower=%#x,upper=%#x, expected lower=%#x,upper=%#x",
+					e.id,
+					bisected.LowerChildId,
+					bisected.UpperChildId,
+					expectedLower.Hash,
+					expectedUpper.Hash,
+				)
+			}
+			lowerChildAlreadyExists = bisected.LowerChildAlreadyExists
+			break
+		}
 	}
-	// Refresh the edge.
-	e = edge
-	someLowerChild, err := e.manager.GetEdge(ctx, protocol.EdgeId{Hash: e.inner.LowerChildId})
+	someLowerChild, err := e.manager.GetEdge(ctx, expectedLower)
 	if err != nil {
 		return nil, nil, err
 	}
-	someUpperChild, err := e.manager.GetEdge(ctx, protocol.EdgeId{Hash: e.inner.UpperChildId})
+	someUpperChild, err := e.manager.GetEdge(ctx, expectedUpper)
 	if err != nil {
 		return nil, nil, err
 	}
 	if someLowerChild.IsNone() || someUpperChild.IsNone() {
 		return nil, nil, errors.New("expected edge to have children post-bisection, but has none")
 	}
-	lower := &honestEdge{someLowerChild.Unwrap()}
+	var lower protocol.VerifiedRoyalEdge = &honestEdge{someLowerChild.Unwrap()}
+	if lowerChildAlreadyExists {
+		lower = &adoptedEdge{
+			honestEdge: honestEdge{someLowerChild.Unwrap()},
+			parentId:   protocol.EdgeId{Hash: e.id},
+		}
+	}
 	upper := &honestEdge{someUpperChild.Unwrap()}
 	return lower, upper, nil
 }
@@ -302,22 +328,12 @@ func (e *specEdge) ConfirmByTimer(ctx context.Context) (*types.Transaction, erro
 	assertionHash := protocol.AssertionHash{
 		Hash: e.inner.ClaimId,
 	}
-	assertionCreation, err := e.manager.assertionChain.ReadAssertionCreationInfo(ctx, assertionHash)
+	stateData, err := NewAssertionStateDataBuilder(e.manager.assertionChain).Build(ctx, assertionHash)
 	if err != nil {
 		return nil, err
 	}
-	// The confirm by timer used to require a list of ancestors, but it has since
-	// been refactored to use them. However, the function signature still needs this empty list.
-	receipt, err := e.manager.assertionChain.transact(ctx, e.manager.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
-		return e.manager.writer.ConfirmEdgeByTime(opts, e.id, challengeV2gen.AssertionStateData{
-			AssertionState: challengeV2gen.AssertionState{
-				GlobalState:    challengeV2gen.GlobalState(assertionCreation.AfterState.GlobalState),
-				MachineStatus:  assertionCreation.AfterState.MachineStatus,
-				EndHistoryRoot: assertionCreation.AfterState.EndHistoryRoot,
-			},
-			PrevAssertionHash: assertionCreation.ParentAssertionHash,
-			InboxAcc:          assertionCreation.AfterInboxBatchAcc,
-		})
+	receipt, err := e.manager.transact(ctx, e.manager.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return e.manager.writer.ConfirmEdgeByTime(opts, e.id, stateData.AsSolidityStruct())
 	})
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -339,7 +355,7 @@ func (e *specEdge) ConfirmByTimer(ctx context.Context) (*types.Transaction, erro
 // is the height of A.
 func (e *specEdge) TopLevelClaimHeight(ctx context.Context) (protocol.OriginHeights, error) {
 	challengeLevel := e.GetChallengeLevel()
-	if challengeLevel == 0 {
+	if challengeLevel.IsBlockChallengeLevel() {
 		startHeight, _ := e.StartCommitment()
 		return protocol.OriginHeights{
 			ChallengeOriginHeights: []protocol.Height{startHeight},
@@ -380,15 +396,26 @@ func (e *specEdge) TopLevelClaimHeight(ctx context.Context) (protocol.OriginHeig
 
 // Wrapper around the challenge manager contract with developer-friendly methods.
 type specChallengeManager struct {
-	addr                  common.Address
-	backend               protocol.ChainBackend
-	assertionChain        *AssertionChain
-	txOpts                *bind.TransactOpts
-	caller                *challengeV2gen.EdgeChallengeManagerCaller
-	writer                *challengeV2gen.EdgeChallengeManagerTransactor
-	filterer              *challengeV2gen.EdgeChallengeManagerFilterer
-	challengePeriodBlocks uint64
-	numBigStepLevel       uint8
+	addr           common.Address
+	backend        protocol.ChainBackend
+	assertionChain *AssertionChain
+	txOpts         *bind.TransactOpts
+	caller         EdgeChallengeManagerCaller
+	writer         EdgeChallengeManagerTransactor
+	filterer       EdgeChallengeManagerFilterer
+	immutables     *immutableCache
+}
+
+// Sends a transaction to the challenge manager as the assertion chain does, decoding the custom
+// error the challenge manager reverted with, if any.
+func (cm *specChallengeManager) transact(
+	ctx context.Context,
+	backend ChainBackend,
+	fn func(opts *bind.TransactOpts) (*types.Transaction, error),
+	opts ...transactOpt,
+) (*types.Receipt, error) {
+	receipt, err := cm.assertionChain.transact(ctx, backend, fn, opts...)
+	return receipt, DecodeChallengeManagerError(err)
 }
 
 // NewSpecChallengeManager returns an instance of the spec challenge manager
@@ -400,109 +427,101 @@ func NewSpecChallengeManager(
 	backend protocol.ChainBackend,
 	txOpts *bind.TransactOpts,
 ) (protocol.SpecChallengeManager, error) {
-	managerBinding, err := challengeV2gen.NewEdgeChallengeManager(addr, backend)
+	bindings, err := bindChallengeManager(assertionChain.challengeManagerVersions[addr], addr, backend)
 	if err != nil {
 		return nil, err
 	}
-	numBigStepLevel, err := managerBinding.EdgeChallengeManagerCaller.NUMBIGSTEPLEVEL(assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
-	if err != nil {
+	cm := &specChallengeManager{
+		addr:           addr,
+		assertionChain: assertionChain,
+		backend:        backend,
+		txOpts:         txOpts,
+		caller:         bindings.Caller,
+		writer:         bindings.Transactor,
+		filterer:       bindings.Filterer,
+		immutables:     &immutableCache{upgradeCheckInterval: assertionChain.upgradeCheckInterval},
+	}
+	// Values needed by most operations are fetched upfront, so a misconfigured address fails here.
+	if _, err = cm.NumBigSteps(ctx); err != nil {
 		return nil, err
 	}
-	challengePeriodBlocks, err := managerBinding.EdgeChallengeManagerCaller.ChallengePeriodBlocks(assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
-	if err != nil {
+	if _, err = cm.ChallengePeriodBlocks(ctx); err != nil {
 		return nil, err
 	}
-	return &specChallengeManager{
-		addr:                  addr,
-		assertionChain:        assertionChain,
-		backend:               backend,
-		txOpts:                txOpts,
-		caller:                &managerBinding.EdgeChallengeManagerCaller,
-		writer:                &managerBinding.EdgeChallengeManagerTransactor,
-		filterer:              &managerBinding.EdgeChallengeManagerFilterer,
-		numBigStepLevel:       numBigStepLevel,
-		challengePeriodBlocks: challengePeriodBlocks,
-	}, nil
+	return cm, nil
 }
 
 func (cm *specChallengeManager) Address() common.Address {
 	return cm.addr
 }
 
-func (cm *specChallengeManager) LayerZeroHeights(ctx context.Context) (*protocol.LayerZeroHeights, error) {
-	h, err := cm.caller.LAYERZEROBLOCKEDGEHEIGHT(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+// Reads a getter of the challenge manager whose value is fixed at initialization through the
+// cache of immutable values.
+func (cm *specChallengeManager) immutable(ctx context.Context, key string, fetch func(opts *bind.CallOpts) (*big.Int, error)) (*big.Int, error) {
+	cm.immutables.checkForUpgrade(ctx, cm.backend, cm.addr)
+	return getImmutable(cm.immutables, key, func() (*big.Int, error) {
+		return fetch(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	})
+}
+
+func (cm *specChallengeManager) immutableUint64(ctx context.Context, key string, fetch func(opts *bind.CallOpts) (*big.Int, error)) (uint64, error) {
+	value, err := cm.immutable(ctx, key, fetch)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	if !h.IsUint64() {
-		return nil, errors.New("layer zero block edge height was not a uint64")
+	if !value.IsUint64() {
+		return 0, fmt.Errorf("%s was not a uint64", key)
 	}
-	bs, err := cm.caller.LAYERZEROBIGSTEPEDGEHEIGHT(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	return value.Uint64(), nil
+}
+
+func (cm *specChallengeManager) LayerZeroHeights(ctx context.Context) (*protocol.LayerZeroHeights, error) {
+	h, err := cm.immutableUint64(ctx, "layer zero block edge height", cm.caller.LAYERZEROBLOCKEDGEHEIGHT)
 	if err != nil {
 		return nil, err
 	}
-	if !bs.IsUint64() {
-		return nil, errors.New("layer zero big step edge height was not a uint64")
-	}
-	ss, err := cm.caller.LAYERZEROSMALLSTEPEDGEHEIGHT(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	bs, err := cm.immutableUint64(ctx, "layer zero big step edge height", cm.caller.LAYERZEROBIGSTEPEDGEHEIGHT)
 	if err != nil {
 		return nil, err
 	}
-	if !ss.IsUint64() {
-		return nil, errors.New("layer zero small step height was not a uint64")
+	ss, err := cm.immutableUint64(ctx, "layer zero small step edge height", cm.caller.LAYERZEROSMALLSTEPEDGEHEIGHT)
+	if err != nil {
+		return nil, err
 	}
 	return &protocol.LayerZeroHeights{
-		BlockChallengeHeight:     h.Uint64(),
-		BigStepChallengeHeight:   bs.Uint64(),
-		SmallStepChallengeHeight: ss.Uint64(),
+		BlockChallengeHeight:     h,
+		BigStepChallengeHeight:   bs,
+		SmallStepChallengeHeight: ss,
 	}, nil
 }
 
 func (cm *specChallengeManager) NumBigSteps(ctx context.Context) (uint8, error) {
-	return cm.numBigStepLevel, nil
+	cm.immutables.checkForUpgrade(ctx, cm.backend, cm.addr)
+	return getImmutable(cm.immutables, "num big step levels", func() (uint8, error) {
+		return cm.caller.NUMBIGSTEPLEVEL(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	})
 }
 
 func (cm *specChallengeManager) LevelZeroBlockEdgeHeight(ctx context.Context) (uint64, error) {
-	h, err := cm.caller.LAYERZEROBLOCKEDGEHEIGHT(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
-	if err != nil {
-		return 0, err
-	}
-	if !h.IsUint64() {
-		return 0, errors.New("level zero block edge height was not a uint64")
-	}
-	return h.Uint64(), nil
+	return cm.immutableUint64(ctx, "layer zero block edge height", cm.caller.LAYERZEROBLOCKEDGEHEIGHT)
 }
 
 // ChallengePeriodBlocks is the duration of the challenge period in blocks.
 func (cm *specChallengeManager) ChallengePeriodBlocks(
 	ctx context.Context,
 ) (uint64, error) {
-	return cm.challengePeriodBlocks, nil
-}
-
-var uint8Type = newStaticType("uint8", "", nil)
-var uint256Type = newStaticType("uint256", "", nil)
-var mutualIdAbi = abi.Arguments{
-	{Type: uint8Type, Name: "level"},
-	{Type: bytes32Type, Name: "originId"},
-	{Type: uint256Type, Name: "startHeight"},
-	{Type: bytes32Type, Name: "startHistoryRoot"},
-	{Type: uint256Type, Name: "endHeight"},
+	cm.immutables.checkForUpgrade(ctx, cm.backend, cm.addr)
+	return getImmutable(cm.immutables, "challenge period blocks", func() (uint64, error) {
+		return cm.caller.ChallengePeriodBlocks(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	})
 }
 
-func calculateMutualId(level uint8, originId [32]byte, startHeight *big.Int, startHistoryRoot [32]byte, endHeight *big.Int) (common.Hash, error) {
-	mutualIdByte, err := mutualIdAbi.Pack(
-		level,
-		originId,
-		startHeight,
-		startHistoryRoot,
-		endHeight,
-	)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	// Pack stores level(uint8) as 32 bytes, so we need to slice off the first 31 bytes
-	return crypto.Keccak256Hash(mutualIdByte[31:]), nil
+// StakeToken is the token in which stakes on layer zero edges are denominated.
+func (cm *specChallengeManager) StakeToken(ctx context.Context) (common.Address, error) {
+	cm.immutables.checkForUpgrade(ctx, cm.backend, cm.addr)
+	return getImmutable(cm.immutables, "stake token", func() (common.Address, error) {
+		return cm.caller.StakeToken(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
+	})
 }
 
 // GetEdge gets an edge by its hash.
@@ -518,22 +537,21 @@ func (cm *specChallengeManager) GetEdge(
 	if edge.Staker != (common.Address{}) {
 		miniStaker = option.Some(edge.Staker)
 	}
-	mutual, err := calculateMutualId(
-		edge.Level,
-		edge.OriginId,
-		edge.StartHeight,
-		edge.StartHistoryRoot,
-		edge.EndHeight,
-	)
+	startHeight, err := protocol.HeightFromBig(edge.StartHeight)
 	if err != nil {
-		return option.None[protocol.SpecEdge](), err
-	}
-	if !edge.StartHeight.IsUint64() {
-		return option.None[protocol.SpecEdge](), errors.New("start height not a uint64")
+		return option.None[protocol.SpecEdge](), errors.Wrap(err, "start height")
 	}
-	if !edge.EndHeight.IsUint64() {
-		return option.None[protocol.SpecEdge](), errors.New("end height not a uint64")
+	endHeight, err := protocol.HeightFromBig(edge.EndHeight)
+	if err != nil {
+		return option.None[protocol.SpecEdge](), errors.Wrap(err, "end height")
 	}
+	mutual := protocol.ComputeMutualId(
+		protocol.ChallengeLevel(edge.Level),
+		edge.OriginId,
+		startHeight,
+		edge.StartHistoryRoot,
+		endHeight,
+	)
 	numbigsteplevel, err := cm.NumBigSteps(ctx)
 	if err != nil {
 		return option.Option[protocol.SpecEdge]{}, err
@@ -555,6 +573,15 @@ func (cm *specChallengeManager) GetEdge(
 	})), nil
 }
 
+// ChallengeEdge reads an edge as stored by the challenge manager, in the struct of the bindings
+// which EncodeChallengeEdge serializes.
+func (cm *specChallengeManager) ChallengeEdge(
+	ctx context.Context,
+	edgeId protocol.EdgeId,
+) (challengeV2gen.ChallengeEdge, error) {
+	return cm.caller.GetEdge(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}), edgeId.Hash)
+}
+
 func (e *specEdge) SafeHeadInheritedTimer(ctx context.Context) (protocol.InheritedTimer, error) {
 	edge, err := e.manager.caller.GetEdge(e.manager.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}), e.id)
 	if err != nil {
@@ -598,7 +625,7 @@ func (e *specEdge) fetchEdge(
 	}
 
 	// Update the edge with the latest data, if they are in now in constant state.
-	if protocol.EdgeStatus(edge.Status) == protocol.EdgeConfirmed {
+	if edge.Status == protocol.EdgeConfirmed.Uint8() {
 		e.isConfirmed = true
 	}
 	if edge.ConfirmedAtBlock != 0 {
@@ -614,6 +641,7 @@ func (e *specEdge) fetchEdge(
 }
 
 // CalculateEdgeId calculates an edge hash given its challenge id, start history, and end history.
+// It is computed locally, as it does not depend on any onchain state.
 func (cm *specChallengeManager) CalculateEdgeId(
 	ctx context.Context,
 	challengeLevel protocol.ChallengeLevel,
@@ -623,18 +651,13 @@ func (cm *specChallengeManager) CalculateEdgeId(
 	endHeight protocol.Height,
 	endHistoryRoot common.Hash,
 ) (protocol.EdgeId, error) {
-	id, err := cm.caller.CalculateEdgeId(
-		cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}),
-		challengeLevel.Uint8(),
-		originId,
-		big.NewInt(int64(startHeight)),
-		startHistoryRoot,
-		big.NewInt(int64(endHeight)),
-		endHistoryRoot,
-	)
-	return protocol.EdgeId{Hash: id}, err
+	return protocol.ComputeEdgeId(challengeLevel, originId, startHeight, startHistoryRoot, endHeight, endHistoryRoot), nil
 }
 
+// MultiUpdateInheritedTimers updates the timer caches of a branch of edges, from the bottom up.
+// Segments of the branch whose top edge already caches at least the desired timer are skipped, as
+// the contract refuses to update them, which happens once another validator or an earlier branch
+// of the same confirmation job updated them. Returns nil if every segment was skipped.
 func (cm *specChallengeManager) MultiUpdateInheritedTimers(
 	ctx context.Context,
 	challengeBranch []protocol.ReadOnlyEdge,
@@ -643,72 +666,55 @@ func (cm *specChallengeManager) MultiUpdateInheritedTimers(
 	if len(challengeBranch) == 0 {
 		return nil, errors.New("no edges to update")
 	}
-	edgeIds := make([][32]byte, 0)
+	desiredTimer := new(big.Int).SetUint64(desiredNewTimerForLastEdge)
 	var lastReceipt *types.Receipt
+	update := func(fn func(opts *bind.TransactOpts) (*types.Transaction, error)) error {
+		receipt, err := cm.transact(ctx, cm.assertionChain.backend, fn, withoutSafeWait())
+		if err != nil {
+			var cachedTimeSufficient *CachedTimeSufficientError
+			if errors.As(err, &cachedTimeSufficient) {
+				return nil
+			}
+			return errors.Wrap(
+				err,
+				"could not update inherited timer for multiple edge ids",
+			)
+		}
+		lastReceipt = receipt
+		return nil
+	}
+	edgeIds := make([][32]byte, 0)
 	for _, edgeId := range challengeBranch {
 		edgeIds = append(edgeIds, edgeId.Id().Hash)
 		if challengetree.IsClaimingAnEdge(edgeId) {
-			_, err := cm.assertionChain.transact(
-				ctx,
-				cm.assertionChain.backend,
-				func(opts *bind.TransactOpts) (*types.Transaction, error) {
-					return cm.writer.MultiUpdateTimeCacheByChildren(
-						opts,
-						edgeIds,
-						new(big.Int).SetUint64(desiredNewTimerForLastEdge),
-					)
-				},
-				withoutSafeWait(),
-			)
-			if err != nil {
-				return nil, errors.Wrap(
-					err,
-					"could not update inherited timer for multiple edge ids",
-				)
+			ids := edgeIds
+			if err := update(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return cm.writer.MultiUpdateTimeCacheByChildren(opts, ids, desiredTimer)
+			}); err != nil {
+				return nil, err
 			}
-			receipt, err := cm.assertionChain.transact(
-				ctx,
-				cm.assertionChain.backend,
-				func(opts *bind.TransactOpts) (*types.Transaction, error) {
-					return cm.writer.UpdateTimerCacheByClaim(
-						opts,
-						edgeId.ClaimId().Unwrap(),
-						edgeId.Id().Hash,
-						new(big.Int).SetUint64(desiredNewTimerForLastEdge),
-					)
-				},
-				withoutSafeWait(),
-			)
-			if err != nil {
-				return nil, errors.Wrap(
-					err,
-					"could not update inherited timer for multiple edge ids",
+			if err := update(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return cm.writer.UpdateTimerCacheByClaim(
+					opts,
+					edgeId.ClaimId().Unwrap(),
+					edgeId.Id().Hash,
+					desiredTimer,
 				)
+			}); err != nil {
+				return nil, err
 			}
 			edgeIds = make([][32]byte, 0)
-			lastReceipt = receipt
 		}
 	}
 	if len(edgeIds) > 0 {
-		receipt, err := cm.assertionChain.transact(
-			ctx,
-			cm.assertionChain.backend,
-			func(opts *bind.TransactOpts) (*types.Transaction, error) {
-				return cm.writer.MultiUpdateTimeCacheByChildren(
-					opts,
-					edgeIds,
-					new(big.Int).SetUint64(desiredNewTimerForLastEdge),
-				)
-			},
-			withoutSafeWait(),
-		)
-		if err != nil {
-			return nil, errors.Wrap(
-				err,
-				"could not update inherited timer for multiple edge ids",
-			)
+		if err := update(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return cm.writer.MultiUpdateTimeCacheByChildren(opts, edgeIds, desiredTimer)
+		}); err != nil {
+			return nil, err
 		}
-		lastReceipt = receipt
+	}
+	if lastReceipt == nil {
+		return nil, nil
 	}
 	tx, _, err := cm.backend.TransactionByHash(ctx, lastReceipt.TxHash)
 	if err != nil {
@@ -796,7 +802,7 @@ func (cm *specChallengeManager) ConfirmEdgeByOneStepProof(
 			result,
 		)
 	}
-	if _, err = cm.assertionChain.transact(
+	if _, err = cm.transact(
 		ctx,
 		cm.assertionChain.backend,
 		func(opts *bind.TransactOpts) (*types.Transaction, error) {
@@ -906,6 +912,8 @@ var blockEdgeCreateProofAbi = abi.Arguments{
 	},
 }
 
+// AssertionStateData is the state of an assertion along with the data the rollup needs to
+// recompute its hash. It can be built with an AssertionStateDataBuilder.
 type AssertionStateData struct {
 	AssertionState    rollupgen.AssertionState
 	PrevAssertionHash [32]byte
@@ -978,15 +986,55 @@ func (cm *specChallengeManager) AddBlockChallengeLevelZeroEdge(
 	if err == nil && !someLevelZeroEdge.IsNone() {
 		return &honestEdge{someLevelZeroEdge.Unwrap()}, nil
 	}
+	isPending, err := cm.assertionChain.userLogic.IsPending(cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}), assertionCreation.AssertionHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not check if assertion %#x is pending", assertionCreation.AssertionHash)
+	}
+	parentAssertion, err := cm.assertionChain.GetAssertion(ctx, prevId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get parent assertion %#x", prevId.Hash)
+	}
+	secondChildCreationBlock, err := parentAssertion.SecondChildCreationBlock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get second child creation block of parent assertion %#x", prevId.Hash)
+	}
+	if err = checkBlockLayerZeroEdgeClaim(
+		assertionCreation,
+		parentAssertionCreation,
+		isPending,
+		secondChildCreationBlock > 0,
+		endCommit,
+	); err != nil {
+		return nil, errors.Wrap(err, "block challenge edge failed pre-flight checks")
+	}
+	if err = checkLayerZeroEdgeCommon(startCommit, endCommit, levelZeroBlockHeight.Uint64(), startEndPrefixProof); err != nil {
+		return nil, errors.Wrap(err, "block challenge edge failed pre-flight checks")
+	}
+	mutualId := protocol.ComputeMutualId(
+		protocol.NewBlockChallengeLevel(),
+		protocol.OriginId(assertionCreation.ParentAssertionHash),
+		protocol.Height(startCommit.Height),
+		startCommit.Merkle,
+		protocol.Height(endCommit.Height),
+	)
+	if err = cm.checkLayerZeroEdgeSender(ctx, common.Hash(mutualId)); err != nil {
+		return nil, errors.Wrap(err, "block challenge edge failed pre-flight checks")
+	}
+	if err = cm.checkStakeAllowance(ctx, protocol.NewBlockChallengeLevel()); err != nil {
+		return nil, errors.Wrap(err, "block challenge edge failed pre-flight checks")
+	}
 	args := challengeV2gen.CreateEdgeArgs{
 		Level:          protocol.NewBlockChallengeLevel().Uint8(),
 		EndHistoryRoot: endCommit.Merkle,
-		EndHeight:      big.NewInt(int64(endCommit.Height)),
+		EndHeight:      protocol.Height(endCommit.Height).Big(),
 		ClaimId:        assertionCreation.AssertionHash,
 		PrefixProof:    startEndPrefixProof,
 		Proof:          blockEdgeProof,
 	}
-	receipt, err := cm.assertionChain.transact(ctx, cm.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+	if err = cm.assertionChain.intents.RecordEdge(edgeId.Hash); err != nil {
+		return nil, err
+	}
+	receipt, err := cm.transact(ctx, cm.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
 		return cm.writer.CreateLayerZeroEdge(
 			opts,
 			args,
@@ -1004,7 +1052,7 @@ func (cm *specChallengeManager) AddBlockChallengeLevelZeroEdge(
 	var edgeAdded *challengeV2gen.EdgeChallengeManagerEdgeAdded
 	var found bool
 	for _, log := range receipt.Logs {
-		creationEvent, creationErr := cm.filterer.ParseEdgeAdded(*log)
+		creationEvent, creationErr := eventbus.DecodeLog(cm.assertionChain.eventBus, *log, cm.filterer.ParseEdgeAdded)
 		if creationErr == nil {
 			edgeAdded = creationEvent
 			found = true
@@ -1014,7 +1062,8 @@ func (cm *specChallengeManager) AddBlockChallengeLevelZeroEdge(
 	if !found {
 		return nil, errors.New("could not find edge added event in logs")
 	}
-	someLevelZeroEdge, err = cm.GetEdge(ctx, protocol.EdgeId{Hash: edgeAdded.EdgeId})
+	// The edge did not exist yet at any block reads were pinned to.
+	someLevelZeroEdge, err = cm.GetEdge(protocol.UnpinReads(ctx), protocol.EdgeId{Hash: edgeAdded.EdgeId})
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not get created edge by id: %#x", edgeAdded.EdgeId)
 	}
@@ -1081,6 +1130,47 @@ func (cm *specChallengeManager) AddSubChallengeLevelZeroEdge(
 		return &honestEdge{e.Unwrap()}, nil
 	}
 
+	layerZeroHeights, err := cm.LayerZeroHeights(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get layer zero heights")
+	}
+	numBigStepLevel, err := cm.NumBigSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expectedEndHeight := layerZeroHeights.BigStepChallengeHeight
+	if subChalTyp.Uint8() == numBigStepLevel+1 {
+		expectedEndHeight = layerZeroHeights.SmallStepChallengeHeight
+	}
+	if err = checkSubChallengeLayerZeroEdgeClaim(
+		ctx,
+		challengedEdge,
+		subChalTyp,
+		numBigStepLevel,
+		startCommit,
+		endCommit,
+		startParentInclusionProof,
+		endParentInclusionProof,
+	); err != nil {
+		return nil, errors.Wrapf(err, "subchallenge edge at level %d failed pre-flight checks", subChalTyp)
+	}
+	if err = checkLayerZeroEdgeCommon(startCommit, endCommit, expectedEndHeight, startEndPrefixProof); err != nil {
+		return nil, errors.Wrapf(err, "subchallenge edge at level %d failed pre-flight checks", subChalTyp)
+	}
+	subMutualId := protocol.ComputeMutualId(
+		subChalTyp,
+		protocol.OriginId(mutualId),
+		protocol.Height(startCommit.Height),
+		startCommit.Merkle,
+		protocol.Height(endCommit.Height),
+	)
+	if err = cm.checkLayerZeroEdgeSender(ctx, common.Hash(subMutualId)); err != nil {
+		return nil, errors.Wrapf(err, "subchallenge edge at level %d failed pre-flight checks", subChalTyp)
+	}
+	if err = cm.checkStakeAllowance(ctx, subChalTyp); err != nil {
+		return nil, errors.Wrapf(err, "subchallenge edge at level %d failed pre-flight checks", subChalTyp)
+	}
+
 	subchallengeEdgeProof, err := subchallengeEdgeProofAbi.Pack(
 		startCommit.FirstLeaf,
 		endCommit.LastLeaf,
@@ -1091,13 +1181,16 @@ func (cm *specChallengeManager) AddSubChallengeLevelZeroEdge(
 	if err != nil {
 		return nil, err
 	}
-	_, err = cm.assertionChain.transact(ctx, cm.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+	if err = cm.assertionChain.intents.RecordEdge(edgeId.Hash); err != nil {
+		return nil, err
+	}
+	_, err = cm.transact(ctx, cm.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
 		return cm.writer.CreateLayerZeroEdge(
 			opts,
 			challengeV2gen.CreateEdgeArgs{
 				Level:          subChalTyp.Uint8(),
 				EndHistoryRoot: endCommit.Merkle,
-				EndHeight:      big.NewInt(int64(endCommit.Height)),
+				EndHeight:      protocol.Height(endCommit.Height).Big(),
 				ClaimId:        challengedEdge.Id().Hash,
 				PrefixProof:    startEndPrefixProof,
 				Proof:          subchallengeEdgeProof,
@@ -1111,7 +1204,8 @@ func (cm *specChallengeManager) AddSubChallengeLevelZeroEdge(
 		return nil, err
 	}
 
-	e, err = cm.GetEdge(ctx, edgeId)
+	// The edge did not exist yet at any block reads were pinned to.
+	e, err = cm.GetEdge(protocol.UnpinReads(ctx), edgeId)
 	if err != nil {
 		return nil, err
 	}
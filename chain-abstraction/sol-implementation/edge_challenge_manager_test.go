@@ -5,12 +5,16 @@ package solimpl_test
 
 import (
 	"context"
+	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
 	"github.com/OffchainLabs/bold/containers/option"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
 	"github.com/OffchainLabs/bold/solgen/go/mocksgen"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	commitments "github.com/OffchainLabs/bold/state-commitments/history"
@@ -21,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 func TestEdgeChallengeManager_IsUnrivaled(t *testing.T) {
@@ -230,6 +235,65 @@ func TestEdgeChallengeManager_BlockChallengeAddLevelZeroEdge(t *testing.T) {
 	})
 }
 
+func TestEdgeChallengeManager_AddLevelZeroEdgeStakeAllowance(t *testing.T) {
+	ctx := context.Background()
+	createdData, err := setup.CreateTwoValidatorFork(ctx, &setup.CreateForkConfig{}, setup.WithMockOneStepProver())
+	require.NoError(t, err)
+
+	staker := createdData.Accounts[1]
+	challengeManager, err := createdData.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	stakeToken, err := challengeManager.StakeToken(ctx)
+	require.NoError(t, err)
+	token, err := mocksgen.NewTestWETH9(stakeToken, createdData.Backend)
+	require.NoError(t, err)
+	_, err = token.Approve(staker.TxOpts, challengeManager.Address(), common.Big0)
+	require.NoError(t, err)
+	createdData.Backend.Commit()
+
+	req := &l2stateprovider.HistoryCommitmentRequest{
+		WasmModuleRoot:              common.Hash{},
+		FromBatch:                   0,
+		ToBatch:                     1,
+		UpperChallengeOriginHeights: []l2stateprovider.Height{},
+		FromHeight:                  0,
+		UpToHeight:                  option.Some(l2stateprovider.Height(0)),
+	}
+	start, err := createdData.HonestStateManager.HistoryCommitment(ctx, req)
+	require.NoError(t, err)
+	req.UpToHeight = option.Some(l2stateprovider.Height(challenge_testing.LevelZeroBlockEdgeHeight))
+	end, err := createdData.HonestStateManager.HistoryCommitment(ctx, req)
+	require.NoError(t, err)
+	prefixProof, err := createdData.HonestStateManager.PrefixProof(ctx, req, l2stateprovider.Height(0))
+	require.NoError(t, err)
+
+	_, err = challengeManager.AddBlockChallengeLevelZeroEdge(ctx, createdData.Leaf1, start, end, prefixProof)
+	require.ErrorIs(t, err, solimpl.ErrInsufficientStakeAllowance)
+
+	// A chain managing the allowance approves the stake of the whole challenge before creating the edge.
+	managedChain, err := solimpl.NewAssertionChain(
+		ctx,
+		createdData.Addrs.Rollup,
+		challengeManager.Address(),
+		staker.TxOpts,
+		createdData.Backend,
+		solimpl.NewChainBackendTransactor(createdData.Backend),
+		solimpl.WithStakeAllowanceManagement(),
+	)
+	require.NoError(t, err)
+	managedChallengeManager, err := managedChain.SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	_, err = managedChallengeManager.AddBlockChallengeLevelZeroEdge(ctx, createdData.Leaf1, start, end, prefixProof)
+	require.NoError(t, err)
+
+	amounts, err := challengeManager.StakeAmounts(ctx)
+	require.NoError(t, err)
+	allowance, err := token.Allowance(&bind.CallOpts{Context: ctx}, staker.TxOpts.From, challengeManager.Address())
+	require.NoError(t, err)
+	remaining := protocol.ChallengeStakeCost(amounts, protocol.NewBlockChallengeLevel().Next())
+	require.Equal(t, remaining, allowance)
+}
+
 func TestEdgeChallengeManager_Bisect(t *testing.T) {
 	ctx := context.Background()
 	bisectionScenario := setupBisectionScenario(t)
@@ -250,8 +314,13 @@ func TestEdgeChallengeManager_Bisect(t *testing.T) {
 		req.UpToHeight = option.Some(l2stateprovider.Height(challenge_testing.LevelZeroBlockEdgeHeight))
 		honestProof, err := honestStateManager.PrefixProof(ctx, req, challenge_testing.LevelZeroBlockEdgeHeight/2)
 		require.NoError(t, err)
+		bisectionHeight, expectedLower, expectedUpper, err := protocol.ComputeBisectionChildIds(honestEdge, honestBisectCommit.Merkle)
+		require.NoError(t, err)
+		require.Equal(t, protocol.Height(challenge_testing.LevelZeroBlockEdgeHeight/2), bisectionHeight)
 		lower, upper, err := honestEdge.Bisect(ctx, honestBisectCommit.Merkle, honestProof)
 		require.NoError(t, err)
+		require.Equal(t, expectedLower, lower.Id())
+		require.Equal(t, expectedUpper, upper.Id())
 
 		gotLower, gotUpper, err := honestEdge.Bisect(ctx, honestBisectCommit.Merkle, honestProof)
 		require.NoError(t, err)
@@ -260,7 +329,60 @@ func TestEdgeChallengeManager_Bisect(t *testing.T) {
 	})
 }
 
+func TestEdgeChallengeManager_Bisect_LowerChildAlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	// The evil validator's machine diverges after the bisection point of the level zero big step
+	// edges, so both rivals bisect to the same lower child.
+	bisectTo := l2stateprovider.Height(challenge_testing.LevelZeroBigStepEdgeHeight / 2)
+	bisectionScenario := setupBigStepChallengeScenario(t, &setup.CreateForkConfig{
+		DivergeMachineHeight: (uint64(bisectTo) + 2) * challenge_testing.LevelZeroSmallStepEdgeHeight,
+	})
+
+	bisect := func(stateManager l2stateprovider.Provider, edge protocol.SpecEdge) (protocol.VerifiedRoyalEdge, protocol.VerifiedRoyalEdge) {
+		req := &l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              common.Hash{},
+			FromBatch:                   0,
+			ToBatch:                     1,
+			UpperChallengeOriginHeights: []l2stateprovider.Height{0},
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(bisectTo),
+		}
+		bisectCommit, err := stateManager.HistoryCommitment(ctx, req)
+		require.NoError(t, err)
+		req.UpToHeight = option.Some(l2stateprovider.Height(challenge_testing.LevelZeroBigStepEdgeHeight))
+		proof, err := stateManager.PrefixProof(ctx, req, bisectTo)
+		require.NoError(t, err)
+		lower, upper, err := edge.Bisect(ctx, bisectCommit.Merkle, proof)
+		require.NoError(t, err)
+		return lower, upper
+	}
+
+	evilLower, evilUpper := bisect(bisectionScenario.evilStateManager, bisectionScenario.evilLevelZeroEdge)
+	_, ok := evilLower.(protocol.AdoptedEdge)
+	require.False(t, ok, "the first bisection creates its lower child")
+
+	honestLower, honestUpper := bisect(bisectionScenario.honestStateManager, bisectionScenario.honestLevelZeroEdge)
+	require.Equal(t, evilLower.Id(), honestLower.Id())
+	require.NotEqual(t, evilUpper.Id(), honestUpper.Id())
+	adopted, ok := honestLower.(protocol.AdoptedEdge)
+	require.True(t, ok, "the second bisection adopts the existing lower child")
+	require.Equal(t, bisectionScenario.honestLevelZeroEdge.Id(), adopted.AdoptedBy())
+	_, ok = honestUpper.(protocol.AdoptedEdge)
+	require.False(t, ok)
+}
+
 func TestEdgeChallengeManager_AddSubchallengeLeaf(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		testAddSubchallengeLeaf(t, false)
+	})
+	// Edge trackers pin the reads of each decision to the head block from before their moves, so
+	// the edges created by a move must still be read after it.
+	t.Run("pinned reads", func(t *testing.T) {
+		testAddSubchallengeLeaf(t, true)
+	})
+}
+
+func testAddSubchallengeLeaf(t *testing.T, pinReads bool) {
 	// Set up a scenario we can bisect.
 	ctx := context.Background()
 
@@ -287,6 +409,14 @@ func TestEdgeChallengeManager_AddSubchallengeLeaf(t *testing.T) {
 
 	challengeManager, err := bisectionScenario.topLevelFork.Chains[1].SpecChallengeManager(ctx)
 	require.NoError(t, err)
+	moveCtx := func() context.Context {
+		if !pinReads {
+			return ctx
+		}
+		header, headerErr := bisectionScenario.topLevelFork.Backend.HeaderByNumber(ctx, nil)
+		require.NoError(t, headerErr)
+		return protocol.PinReads(ctx, header)
+	}
 
 	// Perform bisections all the way down to a one step fork.
 	var blockHeight uint64 = challenge_testing.LevelZeroBlockEdgeHeight
@@ -305,7 +435,7 @@ func TestEdgeChallengeManager_AddSubchallengeLeaf(t *testing.T) {
 		req.UpToHeight = option.Some(l2stateprovider.Height(blockHeight))
 		honestProof, honestProofErr := honestStateManager.PrefixProof(ctx, req, bisectTo)
 		require.NoError(t, honestProofErr)
-		honestEdge, _, err = honestEdge.Bisect(ctx, honestBisectCommit.Merkle, honestProof)
+		honestEdge, _, err = honestEdge.Bisect(moveCtx(), honestBisectCommit.Merkle, honestProof)
 		require.NoError(t, err)
 
 		req.UpToHeight = option.Some(bisectTo)
@@ -314,7 +444,7 @@ func TestEdgeChallengeManager_AddSubchallengeLeaf(t *testing.T) {
 		req.UpToHeight = option.Some(l2stateprovider.Height(blockHeight))
 		evilProof, evilErr := evilStateManager.PrefixProof(ctx, req, bisectTo)
 		require.NoError(t, evilErr)
-		evilEdge, _, err = evilEdge.Bisect(ctx, evilBisectCommit.Merkle, evilProof)
+		evilEdge, _, err = evilEdge.Bisect(moveCtx(), evilBisectCommit.Merkle, evilProof)
 		require.NoError(t, err)
 
 		blockHeight /= 2
@@ -368,7 +498,7 @@ func TestEdgeChallengeManager_AddSubchallengeLeaf(t *testing.T) {
 	require.NoError(t, proofErr)
 
 	leaf, err := challengeManager.AddSubChallengeLevelZeroEdge(
-		ctx,
+		moveCtx(),
 		honestEdge,
 		startCommit,
 		endCommit,
@@ -688,11 +818,19 @@ type bisectionScenario struct {
 func setupBisectionScenario(
 	t *testing.T,
 	opts ...setup.Opt,
+) *bisectionScenario {
+	return setupBisectionScenarioWithForkConfig(t, &setup.CreateForkConfig{}, opts...)
+}
+
+func setupBisectionScenarioWithForkConfig(
+	t *testing.T,
+	forkConfig *setup.CreateForkConfig,
+	opts ...setup.Opt,
 ) *bisectionScenario {
 	ctx := context.Background()
 
 	opts = append(opts, setup.WithMockOneStepProver())
-	createdData, err := setup.CreateTwoValidatorFork(ctx, &setup.CreateForkConfig{}, opts...)
+	createdData, err := setup.CreateTwoValidatorFork(ctx, forkConfig, opts...)
 	require.NoError(t, err)
 
 	challengeManager, err := createdData.Chains[0].SpecChallengeManager(ctx)
@@ -773,20 +911,21 @@ type oneStepProofScenario struct {
 	smallStepEvilEdge   protocol.SpecEdge
 }
 
-// Sets up a challenge between two validators in which they make challenge moves
-// to reach a one-step-proof in a small step subchallenge. It returns the data needed
-// to then confirm the winner by one-step-proof execution.
-func setupOneStepProofScenario(
+// Sets up a challenge between two validators in which they bisect down to a one step fork in the
+// block challenge, and open rival big step subchallenge edges from it. The returned scenario holds
+// the level zero big step edges.
+func setupBigStepChallengeScenario(
 	t *testing.T,
-) *oneStepProofScenario {
+	forkConfig *setup.CreateForkConfig,
+) *bisectionScenario {
 	ctx := context.Background()
-	bisectionScenario := setupBisectionScenario(t)
-	honestStateManager := bisectionScenario.honestStateManager
-	evilStateManager := bisectionScenario.evilStateManager
-	honestEdge := bisectionScenario.honestLevelZeroEdge
-	evilEdge := bisectionScenario.evilLevelZeroEdge
+	scenario := setupBisectionScenarioWithForkConfig(t, forkConfig)
+	honestStateManager := scenario.honestStateManager
+	evilStateManager := scenario.evilStateManager
+	honestEdge := scenario.honestLevelZeroEdge
+	evilEdge := scenario.evilLevelZeroEdge
 
-	challengeManager, err := bisectionScenario.topLevelFork.Chains[1].SpecChallengeManager(ctx)
+	challengeManager, err := scenario.topLevelFork.Chains[1].SpecChallengeManager(ctx)
 	require.NoError(t, err)
 
 	var blockHeight uint64 = challenge_testing.LevelZeroBlockEdgeHeight
@@ -896,6 +1035,31 @@ func setupOneStepProofScenario(
 	require.Equal(t, true, uint8(challengeLevel) < totalChallengeLevels-1)
 	require.Equal(t, true, challengeLevel > 0)
 
+	return &bisectionScenario{
+		topLevelFork:        scenario.topLevelFork,
+		honestStateManager:  honestStateManager,
+		evilStateManager:    evilStateManager,
+		honestLevelZeroEdge: honestEdge,
+		evilLevelZeroEdge:   evilEdge,
+	}
+}
+
+// Sets up a challenge between two validators in which they make challenge moves
+// to reach a one-step-proof in a small step subchallenge. It returns the data needed
+// to then confirm the winner by one-step-proof execution.
+func setupOneStepProofScenario(
+	t *testing.T,
+) *oneStepProofScenario {
+	ctx := context.Background()
+	bisectionScenario := setupBigStepChallengeScenario(t, &setup.CreateForkConfig{})
+	honestStateManager := bisectionScenario.honestStateManager
+	evilStateManager := bisectionScenario.evilStateManager
+	honestEdge := bisectionScenario.honestLevelZeroEdge
+	evilEdge := bisectionScenario.evilLevelZeroEdge
+
+	challengeManager, err := bisectionScenario.topLevelFork.Chains[1].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+
 	var bigStepHeight uint64 = challenge_testing.LevelZeroBigStepEdgeHeight
 	for bigStepHeight > 1 {
 		bisectTo := l2stateprovider.Height(bigStepHeight / 2)
@@ -937,7 +1101,7 @@ func setupOneStepProofScenario(
 		require.Equal(t, bigStepHeight == 1, isOSF)
 	}
 
-	hasRival, err = honestEdge.HasRival(ctx)
+	hasRival, err := honestEdge.HasRival(ctx)
 	require.NoError(t, err)
 	require.Equal(t, false, !hasRival)
 	hasRival, err = evilEdge.HasRival(ctx)
@@ -1017,8 +1181,8 @@ func setupOneStepProofScenario(
 	}
 
 	honestEdge = smallStepAdder(honestStateManager, honestEdge)
-	challengeLevel = honestEdge.GetChallengeLevel()
-	totalChallengeLevels = honestEdge.GetTotalChallengeLevels(ctx)
+	challengeLevel := honestEdge.GetChallengeLevel()
+	totalChallengeLevels := honestEdge.GetTotalChallengeLevels(ctx)
 	require.Equal(t, true, uint8(challengeLevel) == totalChallengeLevels-1)
 	hasRival, err = honestEdge.HasRival(ctx)
 	require.NoError(t, err)
@@ -1097,3 +1261,136 @@ func setupOneStepProofScenario(
 		smallStepEvilEdge:   evilEdge,
 	}
 }
+
+func TestDetectAbiDrift_DeployedChallengeManager(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := defaultSetup()
+	require.NoError(t, err)
+	chain := cfg.Chains[0]
+	challengeManager, err := chain.SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+
+	// The challenge manager is deployed behind a proxy.
+	drift, err := solimpl.DetectAbiDrift(ctx, cfg.Backend, challengeManager.Address(), contractAbi)
+	require.NoError(t, err)
+	require.NotEqual(t, challengeManager.Address(), drift.Implementation)
+	require.False(t, drift.HasDrift(), "%+v", drift)
+}
+
+func TestDetectAbiDrift_WrappedBackends(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := defaultSetup()
+	require.NoError(t, err)
+	challengeManager, err := cfg.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+
+	// Each wrapper the assertion chain may be configured with must still resolve the proxy.
+	var backend protocol.ChainBackend = cfg.Backend
+	backend = solimpl.NewMetricsContractBackend(backend)
+	backend = solimpl.NewTrackedContractBackend(backend)
+	backend = solimpl.NewRateLimitedContractBackend(backend, rate.NewLimiter(rate.Inf, 1))
+	backend = solimpl.NewLogCapBackend(backend, 1000)
+	backend = solimpl.NewResubscribingBackend(backend, time.Second)
+	backend, err = solimpl.NewRpcMetricsBackend(backend, "test", solimpl.DefaultRpcSLO())
+	require.NoError(t, err)
+
+	drift, err := solimpl.DetectAbiDrift(ctx, backend, challengeManager.Address(), contractAbi)
+	require.NoError(t, err)
+	require.NotEqual(t, challengeManager.Address(), drift.Implementation)
+	require.False(t, drift.HasDrift(), "%+v", drift)
+	require.NoError(t, solimpl.CheckChallengeManagerAbiDrift(ctx, backend, challengeManager.Address()))
+}
+
+func TestEdgeChallengeManager_StakeRequirements(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := defaultSetup()
+	require.NoError(t, err)
+	challengeManager, err := cfg.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+
+	stakeToken, err := challengeManager.StakeToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, cfg.RollupConfig.StakeToken, stakeToken)
+	for level, want := range cfg.RollupConfig.MiniStakeValues {
+		got, err := challengeManager.StakeAmount(ctx, protocol.ChallengeLevel(level))
+		require.NoError(t, err)
+		require.Equal(t, want, got, "level %d", level)
+	}
+	amounts, err := challengeManager.StakeAmounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, cfg.RollupConfig.MiniStakeValues, amounts)
+	noStake, err := challengeManager.NoStakeMode(ctx)
+	require.NoError(t, err)
+	require.False(t, noStake)
+}
+
+func FuzzComputeEdgeId_GoSolidityEquivalence(f *testing.F) {
+	type edge struct {
+		level       uint8
+		startHeight uint64
+		endHeight   uint64
+	}
+	testcases := []edge{
+		{0, 0, 32},
+		{1, 16, 32},
+		{2, 0, 1},
+		{3, 1 << 25, 1 << 26},
+		{255, 0, 0},
+		{0, ^uint64(0), ^uint64(0)},
+	}
+	for i, tc := range testcases {
+		seed := []byte{byte(i)}
+		f.Add(tc.level, append([]byte("origin"), seed...), tc.startHeight, append([]byte("start"), seed...), tc.endHeight, append([]byte("end"), seed...))
+	}
+	accs, backend, err := setup.Accounts(1)
+	require.NoError(f, err)
+	_, _, challengeManager, err := challengeV2gen.DeployEdgeChallengeManager(accs[0].TxOpts, backend)
+	require.NoError(f, err)
+	backend.Commit()
+	opts := &bind.CallOpts{}
+	f.Fuzz(func(t *testing.T, level uint8, origin []byte, startHeight uint64, start []byte, endHeight uint64, end []byte) {
+		originId := common.BytesToHash(origin)
+		startRoot := common.BytesToHash(start)
+		endRoot := common.BytesToHash(end)
+		wantMutualId, err := challengeManager.CalculateMutualId(
+			opts,
+			level,
+			originId,
+			new(big.Int).SetUint64(startHeight),
+			startRoot,
+			new(big.Int).SetUint64(endHeight),
+		)
+		require.NoError(t, err)
+		wantId, err := challengeManager.CalculateEdgeId(
+			opts,
+			level,
+			originId,
+			new(big.Int).SetUint64(startHeight),
+			startRoot,
+			new(big.Int).SetUint64(endHeight),
+			endRoot,
+		)
+		require.NoError(t, err)
+		gotMutualId := protocol.ComputeMutualId(
+			protocol.ChallengeLevel(level),
+			protocol.OriginId(originId),
+			protocol.Height(startHeight),
+			startRoot,
+			protocol.Height(endHeight),
+		)
+		gotId := protocol.ComputeEdgeId(
+			protocol.ChallengeLevel(level),
+			protocol.OriginId(originId),
+			protocol.Height(startHeight),
+			startRoot,
+			protocol.Height(endHeight),
+			endRoot,
+		)
+		require.Equal(t, common.Hash(wantMutualId), common.Hash(gotMutualId))
+		require.Equal(t, common.Hash(wantId), gotId.Hash)
+	})
+}
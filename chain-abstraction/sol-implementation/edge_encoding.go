@@ -0,0 +1,136 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ChallengeEdgeEncodingVersion prefixes the canonical encoding of an edge, and changes whenever
+// the layout of the encoding does.
+const ChallengeEdgeEncodingVersion byte = 1
+
+// ChallengeEdgeEncodingLength is the length in bytes of the canonical encoding of an edge.
+const ChallengeEdgeEncodingLength = 1 + 8*common.HashLength + common.AddressLength + 3*8 + 3
+
+var errInvalidEdgeEncoding = errors.New("invalid challenge edge encoding")
+
+// EncodeChallengeEdge serializes an edge as stored by the challenge manager into its canonical
+// encoding, the version byte followed by the fields of the edge in the order of the contract's
+// struct, each with a fixed width: 32 bytes for ids, roots and heights, which are big-endian,
+// 20 bytes for the staker, 8 big-endian bytes for block numbers and timers, and a byte for the
+// status, level, and refunded flag. Equal edges always have equal encodings, so the encoding may
+// be hashed and compared across clients.
+func EncodeChallengeEdge(edge *challengeV2gen.ChallengeEdge) ([]byte, error) {
+	startHeight, err := encodeEdgeHeight(edge.StartHeight)
+	if err != nil {
+		return nil, errors.Wrap(err, "start height")
+	}
+	endHeight, err := encodeEdgeHeight(edge.EndHeight)
+	if err != nil {
+		return nil, errors.Wrap(err, "end height")
+	}
+	data := make([]byte, 0, ChallengeEdgeEncodingLength)
+	data = append(data, ChallengeEdgeEncodingVersion)
+	data = append(data, edge.OriginId[:]...)
+	data = append(data, edge.StartHistoryRoot[:]...)
+	data = append(data, startHeight[:]...)
+	data = append(data, edge.EndHistoryRoot[:]...)
+	data = append(data, endHeight[:]...)
+	data = append(data, edge.LowerChildId[:]...)
+	data = append(data, edge.UpperChildId[:]...)
+	data = append(data, edge.ClaimId[:]...)
+	data = append(data, edge.Staker[:]...)
+	data = binary.BigEndian.AppendUint64(data, edge.CreatedAtBlock)
+	data = binary.BigEndian.AppendUint64(data, edge.ConfirmedAtBlock)
+	data = append(data, edge.Status, edge.Level, encodeBool(edge.Refunded))
+	data = binary.BigEndian.AppendUint64(data, edge.TotalTimeUnrivaledCache)
+	return data, nil
+}
+
+// DecodeChallengeEdge parses the canonical encoding of an edge. Encodings which are not the one
+// EncodeChallengeEdge returns for some edge, such as with a refunded flag other than zero or one,
+// are rejected.
+func DecodeChallengeEdge(data []byte) (*challengeV2gen.ChallengeEdge, error) {
+	if len(data) != ChallengeEdgeEncodingLength {
+		return nil, errors.Wrapf(errInvalidEdgeEncoding, "got %d bytes, expected %d", len(data), ChallengeEdgeEncodingLength)
+	}
+	if data[0] != ChallengeEdgeEncodingVersion {
+		return nil, errors.Wrapf(errInvalidEdgeEncoding, "unsupported version %d", data[0])
+	}
+	d := edgeDecoder{data: data[1:]}
+	edge := &challengeV2gen.ChallengeEdge{}
+	edge.OriginId = d.hash()
+	edge.StartHistoryRoot = d.hash()
+	edge.StartHeight = new(big.Int).SetBytes(d.next(common.HashLength))
+	edge.EndHistoryRoot = d.hash()
+	edge.EndHeight = new(big.Int).SetBytes(d.next(common.HashLength))
+	edge.LowerChildId = d.hash()
+	edge.UpperChildId = d.hash()
+	edge.ClaimId = d.hash()
+	edge.Staker = common.BytesToAddress(d.next(common.AddressLength))
+	edge.CreatedAtBlock = binary.BigEndian.Uint64(d.next(8))
+	edge.ConfirmedAtBlock = binary.BigEndian.Uint64(d.next(8))
+	edge.Status = d.next(1)[0]
+	edge.Level = d.next(1)[0]
+	refunded := d.next(1)[0]
+	if refunded > 1 {
+		return nil, errors.Wrapf(errInvalidEdgeEncoding, "refunded flag %d is not a boolean", refunded)
+	}
+	edge.Refunded = refunded == 1
+	edge.TotalTimeUnrivaledCache = binary.BigEndian.Uint64(d.next(8))
+	return edge, nil
+}
+
+// ChallengeEdgeHash is the keccak256 hash of the canonical encoding of an edge. It identifies the
+// state of an edge, rather than the edge like its id does, so it serves as an integrity hash of
+// stored edges and as the key to deduplicate identical reads of an edge.
+func ChallengeEdgeHash(edge *challengeV2gen.ChallengeEdge) (common.Hash, error) {
+	data, err := EncodeChallengeEdge(edge)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+func encodeEdgeHeight(height *big.Int) ([common.HashLength]byte, error) {
+	var encoded [common.HashLength]byte
+	if height == nil {
+		return encoded, errors.New("height is nil")
+	}
+	if height.Sign() < 0 || height.BitLen() > 256 {
+		return encoded, fmt.Errorf("height %s is not a uint256", height)
+	}
+	height.FillBytes(encoded[:])
+	return encoded, nil
+}
+
+func encodeBool(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Reads the fields of an encoding of the expected length in turn.
+type edgeDecoder struct {
+	data []byte
+}
+
+func (d *edgeDecoder) next(n int) []byte {
+	field := d.data[:n]
+	d.data = d.data[n:]
+	return field
+}
+
+func (d *edgeDecoder) hash() [32]byte {
+	return common.BytesToHash(d.next(common.HashLength))
+}
@@ -0,0 +1,104 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"math/big"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func testChallengeEdge() *challengeV2gen.ChallengeEdge {
+	return &challengeV2gen.ChallengeEdge{
+		OriginId:                common.HexToHash("0x01"),
+		StartHistoryRoot:        common.HexToHash("0x02"),
+		StartHeight:             big.NewInt(16),
+		EndHistoryRoot:          common.HexToHash("0x03"),
+		EndHeight:               big.NewInt(32),
+		LowerChildId:            common.HexToHash("0x04"),
+		UpperChildId:            common.HexToHash("0x05"),
+		ClaimId:                 common.HexToHash("0x06"),
+		Staker:                  common.HexToAddress("0x07"),
+		CreatedAtBlock:          100,
+		ConfirmedAtBlock:        200,
+		Status:                  protocol.EdgeConfirmed.Uint8(),
+		Level:                   2,
+		Refunded:                true,
+		TotalTimeUnrivaledCache: 50,
+	}
+}
+
+func TestChallengeEdgeEncoding_RoundTrip(t *testing.T) {
+	edge := testChallengeEdge()
+	data, err := EncodeChallengeEdge(edge)
+	require.NoError(t, err)
+	require.Len(t, data, ChallengeEdgeEncodingLength)
+	decoded, err := DecodeChallengeEdge(data)
+	require.NoError(t, err)
+	require.Equal(t, edge, decoded)
+
+	// Edges returned by the contract decode into the binding struct through the ABI, and must
+	// encode the same after it.
+	getEdge, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	outputs := getEdge.Methods["getEdge"].Outputs
+	packed, err := outputs.Pack(*edge)
+	require.NoError(t, err)
+	unpacked, err := outputs.Unpack(packed)
+	require.NoError(t, err)
+	fromAbi := *abi.ConvertType(unpacked[0], new(challengeV2gen.ChallengeEdge)).(*challengeV2gen.ChallengeEdge)
+	abiData, err := EncodeChallengeEdge(&fromAbi)
+	require.NoError(t, err)
+	require.Equal(t, data, abiData)
+
+	hash, err := ChallengeEdgeHash(edge)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256Hash(data), hash)
+}
+
+// The layout of the encoding is fixed, as other clients and stored hashes depend on it.
+func TestChallengeEdgeEncoding_Layout(t *testing.T) {
+	data, err := EncodeChallengeEdge(testChallengeEdge())
+	require.NoError(t, err)
+	require.Equal(t, ChallengeEdgeEncodingVersion, data[0])
+	require.Equal(t, byte(0x01), data[32])
+	require.Equal(t, byte(16), data[96])
+	require.Equal(t, byte(32), data[160])
+	require.Equal(t, byte(0x06), data[256])
+	require.Equal(t, byte(0x07), data[276])
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 100}, data[277:285])
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 200}, data[285:293])
+	require.Equal(t, []byte{1, 2, 1}, data[293:296])
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 50}, data[296:])
+	require.Equal(t, "0xd44553477bcaae7ed33ffa6fba5324c2ddd17da1cd0f15481593b8f755bfe7a7", crypto.Keccak256Hash(data).Hex())
+}
+
+func TestChallengeEdgeEncoding_Invalid(t *testing.T) {
+	edge := testChallengeEdge()
+	edge.StartHeight = big.NewInt(-1)
+	_, err := EncodeChallengeEdge(edge)
+	require.ErrorContains(t, err, "not a uint256")
+	edge.StartHeight = nil
+	_, err = EncodeChallengeEdge(edge)
+	require.ErrorContains(t, err, "nil")
+
+	data, err := EncodeChallengeEdge(testChallengeEdge())
+	require.NoError(t, err)
+	_, err = DecodeChallengeEdge(data[1:])
+	require.ErrorIs(t, err, errInvalidEdgeEncoding)
+	badVersion := append([]byte{}, data...)
+	badVersion[0] = 2
+	_, err = DecodeChallengeEdge(badVersion)
+	require.ErrorContains(t, err, "unsupported version")
+	badFlag := append([]byte{}, data...)
+	badFlag[295] = 2
+	_, err = DecodeChallengeEdge(badFlag)
+	require.ErrorContains(t, err, "not a boolean")
+}
@@ -0,0 +1,232 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	inclusionproofs "github.com/OffchainLabs/bold/state-commitments/inclusion-proofs"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Errors returned when pre-flight checks of a layer zero edge fail. These mirror the
+// validations performed by the challenge manager's createLayerZeroEdge, so that a
+// misconfigured edge is diagnosed locally instead of via an opaque revert.
+var (
+	ErrLayerZeroClaimNotPending   = errors.New("layer zero edge claim is not pending")
+	ErrLayerZeroClaimUnrivaled    = errors.New("layer zero edge claim is not rivaled")
+	ErrLayerZeroInvalidLevel      = errors.New("invalid layer zero edge level")
+	ErrLayerZeroInvalidHeight     = errors.New("invalid layer zero edge height")
+	ErrLayerZeroInvalidState      = errors.New("invalid layer zero edge state")
+	ErrLayerZeroInvalidProof      = errors.New("invalid layer zero edge proof")
+	ErrLayerZeroSenderNotAllowed  = errors.New("sender may not create layer zero edge")
+	ErrLayerZeroEmptyPrefixProof  = errors.New("empty layer zero edge prefix proof")
+	ErrLayerZeroEmptyAssertionRef = errors.New("empty layer zero edge assertion hash")
+)
+
+var prefixProofAbi = abi.Arguments{
+	{Type: bytes32ArrayType, Name: "prefixExpansion"},
+	{Type: bytes32ArrayType, Name: "prefixProof"},
+}
+
+// Mirrors the layerZeroCommonChecks of the challenge manager, which apply to layer zero
+// edges of all levels: the end height must be the expected power of two, the end state must be
+// included in the end history root, and the start history root must be a prefix of the end one.
+func checkLayerZeroEdgeCommon(
+	startCommit,
+	endCommit commitments.History,
+	expectedEndHeight uint64,
+	startEndPrefixProof []byte,
+) error {
+	if expectedEndHeight == 0 || expectedEndHeight&(expectedEndHeight-1) != 0 {
+		return errors.Wrapf(ErrLayerZeroInvalidHeight, "expected end height %d is not a power of two", expectedEndHeight)
+	}
+	if startCommit.Height != 0 {
+		return errors.Wrapf(ErrLayerZeroInvalidHeight, "start commit has height %d (expected 0)", startCommit.Height)
+	}
+	if endCommit.Height != expectedEndHeight {
+		return errors.Wrapf(ErrLayerZeroInvalidHeight, "end commit has height %d (expected %d)", endCommit.Height, expectedEndHeight)
+	}
+	// Layer zero edges start at height zero, so their start history root commits to a single leaf.
+	if startRoot := crypto.Keccak256Hash(startCommit.FirstLeaf[:]); startRoot != startCommit.Merkle {
+		return errors.Wrapf(
+			ErrLayerZeroInvalidProof,
+			"start history root %#x does not commit to only the start state %#x",
+			startCommit.Merkle,
+			startCommit.FirstLeaf,
+		)
+	}
+	if err := verifyInclusionProof(endCommit.Merkle, endCommit.LastLeaf, endCommit.Height, endCommit.LastLeafProof); err != nil {
+		return errors.Wrap(err, "end state not in end history root")
+	}
+	if len(startEndPrefixProof) == 0 {
+		return ErrLayerZeroEmptyPrefixProof
+	}
+	decoded, err := prefixProofAbi.Unpack(startEndPrefixProof)
+	if err != nil {
+		return errors.Wrapf(ErrLayerZeroInvalidProof, "could not decode prefix proof: %v", err)
+	}
+	if len(decoded) != 2 {
+		return errors.Wrapf(ErrLayerZeroInvalidProof, "prefix proof has %d fields (expected 2)", len(decoded))
+	}
+	preExpansion, ok := decoded[0].([][32]byte)
+	if !ok {
+		return errors.Wrap(ErrLayerZeroInvalidProof, "prefix expansion is not a bytes32 array")
+	}
+	proof, ok := decoded[1].([][32]byte)
+	if !ok {
+		return errors.Wrap(ErrLayerZeroInvalidProof, "prefix proof is not a bytes32 array")
+	}
+	if err = prefixproofs.VerifyPrefixProof(&prefixproofs.VerifyPrefixProofConfig{
+		PreRoot:      startCommit.Merkle,
+		PreSize:      1,
+		PostRoot:     endCommit.Merkle,
+		PostSize:     endCommit.Height + 1,
+		PreExpansion: toHashes(preExpansion),
+		PrefixProof:  toHashes(proof),
+	}); err != nil {
+		return errors.Wrapf(ErrLayerZeroInvalidProof, "start history root is not a prefix of end history root: %v", err)
+	}
+	return nil
+}
+
+// Mirrors the block level checks of the challenge manager: the claimed assertion must be
+// pending and rivaled, both the start and end states must have finished executing, and the
+// end history root must match the one the assertion committed to.
+func checkBlockLayerZeroEdgeClaim(
+	claim,
+	parent *protocol.AssertionCreatedInfo,
+	isPending,
+	hasSibling bool,
+	endCommit commitments.History,
+) error {
+	if claim.AssertionHash == (common.Hash{}) {
+		return ErrLayerZeroEmptyAssertionRef
+	}
+	if !isPending {
+		return errors.Wrapf(ErrLayerZeroClaimNotPending, "assertion %#x", claim.AssertionHash)
+	}
+	if !hasSibling {
+		return errors.Wrapf(ErrLayerZeroClaimUnrivaled, "assertion %#x has no sibling", claim.AssertionHash)
+	}
+	if parent.AfterState.MachineStatus == protocol.MachineStatusRunning.Uint8() {
+		return errors.Wrapf(ErrLayerZeroInvalidState, "parent assertion %#x has a running machine status", parent.AssertionHash)
+	}
+	if claim.AfterState.MachineStatus == protocol.MachineStatusRunning.Uint8() {
+		return errors.Wrapf(ErrLayerZeroInvalidState, "assertion %#x has a running machine status", claim.AssertionHash)
+	}
+	if endCommit.Merkle != claim.AfterState.EndHistoryRoot {
+		return errors.Wrapf(
+			ErrLayerZeroInvalidProof,
+			"end history root %#x does not match assertion %#x end history root %#x",
+			endCommit.Merkle,
+			claim.AssertionHash,
+			common.Hash(claim.AfterState.EndHistoryRoot),
+		)
+	}
+	return nil
+}
+
+// Mirrors the subchallenge level checks of the challenge manager: the claimed edge must be
+// a pending edge with a length one rival at the level below, and the start and end states of the
+// new edge must be included in the claimed edge's start and end history roots.
+func checkSubChallengeLayerZeroEdgeClaim(
+	ctx context.Context,
+	claimEdge protocol.SpecEdge,
+	level protocol.ChallengeLevel,
+	numBigStepLevel uint8,
+	startCommit,
+	endCommit commitments.History,
+	startParentInclusionProof,
+	endParentInclusionProof []common.Hash,
+) error {
+	// There is one block level and one small step level in addition to the big step levels.
+	if level.IsBlockChallengeLevel() || level.Uint8() > numBigStepLevel+1 {
+		return errors.Wrapf(
+			ErrLayerZeroInvalidLevel,
+			"cannot open subchallenge at level %d on edge %#x at level %d",
+			level,
+			claimEdge.Id().Hash,
+			claimEdge.GetChallengeLevel(),
+		)
+	}
+	hasLengthOneRival, err := claimEdge.HasLengthOneRival(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if edge %#x has a length one rival", claimEdge.Id().Hash)
+	}
+	if !hasLengthOneRival {
+		return errors.Wrapf(ErrLayerZeroClaimUnrivaled, "edge %#x has no length one rival", claimEdge.Id().Hash)
+	}
+	status, err := claimEdge.Status(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get status of edge %#x", claimEdge.Id().Hash)
+	}
+	if status != protocol.EdgePending {
+		return errors.Wrapf(ErrLayerZeroClaimNotPending, "edge %#x has status %s", claimEdge.Id().Hash, status)
+	}
+	claimStartHeight, claimStartRoot := claimEdge.StartCommitment()
+	if err = verifyInclusionProof(claimStartRoot, startCommit.FirstLeaf, uint64(claimStartHeight), startParentInclusionProof); err != nil {
+		return errors.Wrapf(err, "start state not in start history root of edge %#x", claimEdge.Id().Hash)
+	}
+	claimEndHeight, claimEndRoot := claimEdge.EndCommitment()
+	if err = verifyInclusionProof(claimEndRoot, endCommit.LastLeaf, uint64(claimEndHeight), endParentInclusionProof); err != nil {
+		return errors.Wrapf(err, "end state not in end history root of edge %#x", claimEdge.Id().Hash)
+	}
+	return nil
+}
+
+// Mirrors the validator whitelist checks of the challenge manager. If the whitelist is enabled,
+// only validators may create layer zero edges, and each may only create one edge per mutual id.
+func (cm *specChallengeManager) checkLayerZeroEdgeSender(ctx context.Context, mutualId common.Hash) error {
+	opts := cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx})
+	whitelistDisabled, err := cm.assertionChain.userLogic.ValidatorWhitelistDisabled(opts)
+	if err != nil {
+		return errors.Wrap(err, "could not check if validator whitelist is disabled")
+	}
+	if whitelistDisabled {
+		return nil
+	}
+	sender := cm.assertionChain.txOpts.From
+	isValidator, err := cm.assertionChain.userLogic.IsValidator(opts, sender)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if %#x is a validator", sender)
+	}
+	if !isValidator {
+		return errors.Wrapf(ErrLayerZeroSenderNotAllowed, "%#x is not a whitelisted validator", sender)
+	}
+	hasMadeRival, err := cm.caller.HasMadeLayerZeroRival(opts, sender, mutualId)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if %#x has made a layer zero rival", sender)
+	}
+	if hasMadeRival {
+		return errors.Wrapf(ErrLayerZeroSenderNotAllowed, "%#x already created a layer zero edge with mutual id %#x", sender, mutualId)
+	}
+	return nil
+}
+
+func verifyInclusionProof(root, leaf common.Hash, index uint64, proof []common.Hash) error {
+	computed, err := inclusionproofs.CalculateRootFromProof(proof, index, leaf)
+	if err != nil {
+		return errors.Wrapf(ErrLayerZeroInvalidProof, "could not compute root from inclusion proof: %v", err)
+	}
+	if computed != root {
+		return errors.Wrapf(ErrLayerZeroInvalidProof, "leaf %#x at index %d does not prove into root %#x", leaf, index, root)
+	}
+	return nil
+}
+
+func toHashes(items [][32]byte) []common.Hash {
+	hashes := make([]common.Hash, len(items))
+	for i, item := range items {
+		hashes[i] = item
+	}
+	return hashes
+}
@@ -0,0 +1,105 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func layerZeroCommitments(t *testing.T, height uint64) (commitments.History, commitments.History, []byte) {
+	t.Helper()
+	leaves := make([]common.Hash, height+1)
+	for i := range leaves {
+		leaves[i] = common.BytesToHash([]byte{byte(i + 1)})
+	}
+	startCommit, err := commitments.New(leaves[:1])
+	require.NoError(t, err)
+	endCommit, err := commitments.New(leaves)
+	require.NoError(t, err)
+	prefixExpansion, err := prefixproofs.ExpansionFromLeaves(leaves[:1])
+	require.NoError(t, err)
+	proof, err := prefixproofs.GeneratePrefixProof(1, prefixExpansion, leaves[1:], prefixproofs.RootFetcherFromExpansion)
+	require.NoError(t, err)
+	_, numRead := prefixproofs.MerkleExpansionFromCompact(proof, 1)
+	onlyProof := proof[numRead:]
+	packed, err := prefixProofAbi.Pack(&prefixExpansion, &onlyProof)
+	require.NoError(t, err)
+	return startCommit, endCommit, packed
+}
+
+func TestCheckLayerZeroEdgeCommon(t *testing.T) {
+	startCommit, endCommit, prefixProof := layerZeroCommitments(t, 8)
+	require.NoError(t, checkLayerZeroEdgeCommon(startCommit, endCommit, 8, prefixProof))
+
+	err := checkLayerZeroEdgeCommon(startCommit, endCommit, 6, prefixProof)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidHeight)
+	require.ErrorContains(t, err, "not a power of two")
+
+	err = checkLayerZeroEdgeCommon(startCommit, endCommit, 16, prefixProof)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidHeight)
+	require.ErrorContains(t, err, "end commit has height 8 (expected 16)")
+
+	err = checkLayerZeroEdgeCommon(startCommit, endCommit, 8, nil)
+	require.ErrorIs(t, err, ErrLayerZeroEmptyPrefixProof)
+
+	err = checkLayerZeroEdgeCommon(startCommit, endCommit, 8, []byte{1, 2, 3})
+	require.ErrorIs(t, err, ErrLayerZeroInvalidProof)
+	require.ErrorContains(t, err, "could not decode prefix proof")
+
+	// A prefix proof for a different end history commitment is rejected.
+	_, _, otherPrefixProof := layerZeroCommitments(t, 4)
+	err = checkLayerZeroEdgeCommon(startCommit, endCommit, 8, otherPrefixProof)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidProof)
+	require.ErrorContains(t, err, "not a prefix")
+
+	badEnd := endCommit
+	badEnd.LastLeaf = common.BytesToHash([]byte("foo"))
+	err = checkLayerZeroEdgeCommon(startCommit, badEnd, 8, prefixProof)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidProof)
+	require.ErrorContains(t, err, "end state not in end history root")
+
+	badStart := startCommit
+	badStart.FirstLeaf = common.BytesToHash([]byte("foo"))
+	err = checkLayerZeroEdgeCommon(badStart, endCommit, 8, prefixProof)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidProof)
+	require.ErrorContains(t, err, "does not commit to only the start state")
+}
+
+func TestCheckBlockLayerZeroEdgeClaim(t *testing.T) {
+	_, endCommit, _ := layerZeroCommitments(t, 8)
+	parent := &protocol.AssertionCreatedInfo{
+		AssertionHash: common.BytesToHash([]byte("parent")),
+	}
+	parent.AfterState.MachineStatus = protocol.MachineStatusFinished.Uint8()
+	claim := &protocol.AssertionCreatedInfo{
+		AssertionHash:       common.BytesToHash([]byte("claim")),
+		ParentAssertionHash: parent.AssertionHash,
+	}
+	claim.AfterState.MachineStatus = protocol.MachineStatusFinished.Uint8()
+	claim.AfterState.EndHistoryRoot = endCommit.Merkle
+	require.NoError(t, checkBlockLayerZeroEdgeClaim(claim, parent, true, true, endCommit))
+
+	require.ErrorIs(t, checkBlockLayerZeroEdgeClaim(claim, parent, false, true, endCommit), ErrLayerZeroClaimNotPending)
+	require.ErrorIs(t, checkBlockLayerZeroEdgeClaim(claim, parent, true, false, endCommit), ErrLayerZeroClaimUnrivaled)
+
+	running := *parent
+	running.AfterState.MachineStatus = protocol.MachineStatusRunning.Uint8()
+	err := checkBlockLayerZeroEdgeClaim(claim, &running, true, true, endCommit)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidState)
+	require.ErrorContains(t, err, "parent assertion")
+
+	mismatched := *claim
+	mismatched.AfterState.EndHistoryRoot = common.BytesToHash([]byte("foo"))
+	err = checkBlockLayerZeroEdgeClaim(&mismatched, parent, true, true, endCommit)
+	require.ErrorIs(t, err, ErrLayerZeroInvalidProof)
+	require.ErrorContains(t, err, "does not match assertion")
+
+	require.ErrorIs(t, checkBlockLayerZeroEdgeClaim(&protocol.AssertionCreatedInfo{}, parent, true, true, endCommit), ErrLayerZeroEmptyAssertionRef)
+}
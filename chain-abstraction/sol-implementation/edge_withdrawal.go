@@ -0,0 +1,84 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"strings"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// ErrEdgeWithdrawalUnsupported is returned when withdrawing an edge from a challenge manager which
+// does not let stakers withdraw edges.
+var ErrEdgeWithdrawalUnsupported = errors.New("the challenge manager does not support withdrawing edges")
+
+// The method by which versions of the challenge manager are expected to let the staker of a
+// pending layer zero edge withdraw it and recover its stake. No deployed version has it yet.
+const edgeWithdrawalAbi = `[{"type":"function","name":"withdrawEdge","inputs":[{"name":"edgeId","type":"bytes32"}],"outputs":[],"stateMutability":"nonpayable"}]`
+
+// EdgeChallengeManagerWithdrawer is implemented by the transactors of the bindings of versions of
+// the challenge manager which let the staker of a pending layer zero edge withdraw it, so that
+// registering such a version with RegisterChallengeManagerVersion is all it takes to support it.
+type EdgeChallengeManagerWithdrawer interface {
+	WithdrawEdge(opts *bind.TransactOpts, edgeId [32]byte) (*types.Transaction, error)
+}
+
+// SupportsEdgeWithdrawal checks whether the bindings of the challenge manager can withdraw edges,
+// and whether its deployed code, or that of its implementation if behind a proxy, dispatches the
+// withdrawal method. The result is cached until the implementation is upgraded.
+func (cm *specChallengeManager) SupportsEdgeWithdrawal(ctx context.Context) (bool, error) {
+	if _, ok := cm.writer.(EdgeChallengeManagerWithdrawer); !ok {
+		return false, nil
+	}
+	cm.immutables.checkForUpgrade(ctx, cm.backend, cm.addr)
+	return getImmutable(cm.immutables, "edge withdrawal support", func() (bool, error) {
+		return dispatchesEdgeWithdrawal(ctx, cm.backend, cm.addr)
+	})
+}
+
+// Checks whether the code deployed for a challenge manager, or for its implementation if behind a
+// proxy, dispatches the edge withdrawal method.
+func dispatchesEdgeWithdrawal(ctx context.Context, backend bind.ContractCaller, addr common.Address) (bool, error) {
+	withdrawalAbi, err := abi.JSON(strings.NewReader(edgeWithdrawalAbi))
+	if err != nil {
+		return false, err
+	}
+	drift, err := DetectAbiDrift(ctx, backend, addr, &withdrawalAbi)
+	if err != nil {
+		return false, errors.Wrap(err, "could not inspect the challenge manager code for edge withdrawal")
+	}
+	return len(drift.MissingMethods) == 0, nil
+}
+
+// WithdrawEdge withdraws a pending layer zero edge to recover its stake, returning
+// ErrEdgeWithdrawalUnsupported if the challenge manager does not support it. Whether the edge
+// should be withdrawn is up to the caller.
+func (cm *specChallengeManager) WithdrawEdge(ctx context.Context, edgeId protocol.EdgeId) (*types.Transaction, error) {
+	supported, err := cm.SupportsEdgeWithdrawal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	withdrawer, ok := cm.writer.(EdgeChallengeManagerWithdrawer)
+	if !supported || !ok {
+		return nil, ErrEdgeWithdrawalUnsupported
+	}
+	receipt, err := cm.transact(ctx, cm.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return withdrawer.WithdrawEdge(opts, edgeId.Hash)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not withdraw edge %s", containers.Trunc(edgeId.Bytes()))
+	}
+	tx, _, err := cm.backend.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get transaction by hash: %#x", receipt.TxHash)
+	}
+	return tx, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsEdgeWithdrawal_CurrentBindings(t *testing.T) {
+	ctx := context.Background()
+	cm := &specChallengeManager{
+		writer:     &challengeV2gen.EdgeChallengeManagerTransactor{},
+		immutables: &immutableCache{},
+	}
+	supported, err := cm.SupportsEdgeWithdrawal(ctx)
+	require.NoError(t, err)
+	require.False(t, supported)
+	_, err = cm.WithdrawEdge(ctx, protocol.EdgeId{Hash: common.HexToHash("0x01")})
+	require.ErrorIs(t, err, ErrEdgeWithdrawalUnsupported)
+}
+
+func Test_dispatchesEdgeWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	withdrawalAbi, err := abi.JSON(strings.NewReader(edgeWithdrawalAbi))
+	require.NoError(t, err)
+	selector := withdrawalAbi.Methods["withdrawEdge"].ID
+	current := common.FromHex(challengeV2gen.EdgeChallengeManagerMetaData.Bin)
+	dispatch := append([]byte{byte(vm.DUP1), byte(vm.PUSH4)}, append(selector, byte(vm.EQ))...)
+
+	proxy := common.BytesToAddress([]byte("proxy"))
+	impl := common.BytesToAddress([]byte("impl"))
+	backend := &mockCodeBackend{
+		code: map[common.Address][]byte{
+			proxy: {byte(vm.STOP)},
+			impl:  current,
+		},
+		storage: map[common.Address]map[common.Hash][]byte{
+			proxy: {eip1967ImplementationSlot: common.LeftPadBytes(impl.Bytes(), 32)},
+		},
+	}
+	supported, err := dispatchesEdgeWithdrawal(ctx, backend, proxy)
+	require.NoError(t, err)
+	require.False(t, supported)
+
+	// The implementation is upgraded to one dispatching the withdrawal method.
+	backend.code[impl] = append(dispatch, current...)
+	supported, err = dispatchesEdgeWithdrawal(ctx, backend, proxy)
+	require.NoError(t, err)
+	require.True(t, supported)
+
+	_, err = dispatchesEdgeWithdrawal(ctx, &codeOnlyBackend{inner: backend}, proxy)
+	require.ErrorContains(t, err, "cannot read storage")
+}
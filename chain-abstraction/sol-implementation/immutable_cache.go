@@ -0,0 +1,112 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	immutableCacheHitCounter          = metrics.NewRegisteredCounter("arb/validator/immutable_cache/hit", nil)
+	immutableCacheMissCounter         = metrics.NewRegisteredCounter("arb/validator/immutable_cache/miss", nil)
+	immutableCacheInvalidationCounter = metrics.NewRegisteredCounter("arb/validator/immutable_cache/invalidation", nil)
+)
+
+// Caches the results of a contract's getters whose values are fixed at its initialization, such
+// as the challenge manager's stake token and layer zero heights, for the lifetime of the process.
+// As a proxied contract can be upgraded to an implementation initialized with different values,
+// the implementation behind the proxy is checked at most once per interval on reads, and the
+// cache is invalidated if it has changed. The zero value is an empty cache which never checks
+// for upgrades.
+type immutableCache struct {
+	lock                 sync.RWMutex
+	values               map[string]any
+	upgradeCheckInterval time.Duration
+	lastUpgradeCheck     time.Time
+	implementation       common.Address
+}
+
+// Gets a cached value, fetching it on a miss.
+func getImmutable[T any](c *immutableCache, key string, fetch func() (T, error)) (T, error) {
+	c.lock.RLock()
+	value, ok := c.values[key]
+	c.lock.RUnlock()
+	if ok {
+		immutableCacheHitCounter.Inc(1)
+		return value.(T), nil
+	}
+	immutableCacheMissCounter.Inc(1)
+	got, err := fetch()
+	if err != nil {
+		return got, err
+	}
+	c.set(key, got)
+	return got, nil
+}
+
+func (c *immutableCache) set(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]any)
+	}
+	c.values[key] = value
+}
+
+func (c *immutableCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.values = nil
+	immutableCacheInvalidationCounter.Inc(1)
+}
+
+// Returns true if the implementation is due to be checked for an upgrade, in which case the
+// check is considered done, so concurrent readers do not all check at once.
+func (c *immutableCache) upgradeCheckDue(now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.upgradeCheckInterval == 0 || now.Sub(c.lastUpgradeCheck) < c.upgradeCheckInterval {
+		return false
+	}
+	c.lastUpgradeCheck = now
+	return true
+}
+
+// Records the implementation of the contract, invalidating the cache if it differs from the
+// implementation previously recorded. Returns true if the cache was invalidated.
+func (c *immutableCache) observeImplementation(impl common.Address) bool {
+	c.lock.Lock()
+	prev := c.implementation
+	c.implementation = impl
+	c.lock.Unlock()
+	if prev == (common.Address{}) || prev == impl {
+		return false
+	}
+	log.Warn("Contract implementation was upgraded, invalidating cached immutable values", "previous", prev, "current", impl)
+	c.invalidate()
+	return true
+}
+
+// Checks whether a proxied contract has been upgraded if a check is due, invalidating the cache if
+// so. Failing to read the implementation leaves the cache as is, to be checked again after the
+// next interval.
+func (c *immutableCache) checkForUpgrade(ctx context.Context, backend bind.ContractCaller, addr common.Address) {
+	if _, ok := backend.(protocol.StorageReader); !ok || !c.upgradeCheckDue(time.Now()) {
+		return
+	}
+	slot, err := StorageAt(ctx, backend, addr, eip1967ImplementationSlot, nil)
+	if err != nil {
+		log.Debug("Could not read implementation slot to check for upgrades", "address", addr, "err", err)
+		return
+	}
+	c.observeImplementation(common.BytesToAddress(slot))
+}
@@ -0,0 +1,93 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutableCache(t *testing.T) {
+	cache := &immutableCache{}
+	fetches := 0
+	fetch := func() (uint64, error) {
+		fetches++
+		return 42, nil
+	}
+	for i := 0; i < 3; i++ {
+		got, err := getImmutable(cache, "value", fetch)
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), got)
+	}
+	require.Equal(t, 1, fetches)
+
+	// Errors are not cached.
+	_, err := getImmutable(cache, "failing", func() (uint64, error) {
+		return 0, errors.New("bad")
+	})
+	require.ErrorContains(t, err, "bad")
+	got, err := getImmutable(cache, "failing", fetch)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), got)
+	require.Equal(t, 2, fetches)
+
+	cache.invalidate()
+	_, err = getImmutable(cache, "value", fetch)
+	require.NoError(t, err)
+	require.Equal(t, 3, fetches)
+}
+
+func TestImmutableCache_InvalidatedOnUpgrade(t *testing.T) {
+	ctx := context.Background()
+	proxy := common.BytesToAddress([]byte("proxy"))
+	implA := common.BytesToAddress([]byte("implA"))
+	backend := &mockCodeBackend{
+		storage: map[common.Address]map[common.Hash][]byte{
+			proxy: {eip1967ImplementationSlot: common.LeftPadBytes(implA.Bytes(), 32)},
+		},
+	}
+	cache := &immutableCache{upgradeCheckInterval: time.Hour}
+	fetches := 0
+	read := func() {
+		cache.checkForUpgrade(ctx, backend, proxy)
+		_, err := getImmutable(cache, "value", func() (uint64, error) {
+			fetches++
+			return 1, nil
+		})
+		require.NoError(t, err)
+	}
+	read()
+	read()
+	require.Equal(t, 1, fetches)
+	require.Equal(t, implA, cache.implementation)
+
+	// An upgrade is only noticed once the check interval has passed.
+	implB := common.BytesToAddress([]byte("implB"))
+	backend.storage[proxy][eip1967ImplementationSlot] = common.LeftPadBytes(implB.Bytes(), 32)
+	read()
+	require.Equal(t, 1, fetches)
+	cache.lastUpgradeCheck = time.Now().Add(-2 * time.Hour)
+	read()
+	require.Equal(t, 2, fetches)
+	require.Equal(t, implB, cache.implementation)
+
+	// Backends which cannot read storage are never checked.
+	unreadable := &immutableCache{upgradeCheckInterval: time.Hour}
+	unreadable.checkForUpgrade(ctx, &codeOnlyBackend{inner: backend}, proxy)
+	require.Equal(t, common.Address{}, unreadable.implementation)
+	require.True(t, unreadable.lastUpgradeCheck.IsZero())
+
+	// Without a check interval, the implementation is never read.
+	unchecked := &immutableCache{}
+	unchecked.checkForUpgrade(ctx, backend, proxy)
+	require.Equal(t, common.Address{}, unchecked.implementation)
+	require.False(t, unchecked.observeImplementation(implA))
+	require.False(t, unchecked.observeImplementation(implA))
+	require.True(t, unchecked.observeImplementation(implB))
+}
@@ -0,0 +1,78 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	truncatedLogsCounter = metrics.NewRegisteredCounter("arb/validator/backend/truncated_log_results", nil)
+	uncappedLogsCounter  = metrics.NewRegisteredCounter("arb/validator/backend/unsplittable_log_results", nil)
+)
+
+// LogCapBackend guards against providers which silently truncate the result of eth_getLogs to a
+// maximum number of logs. A result of that many logs is assumed to be truncated, and its block
+// range is halved and each half requested again, until every result is below the cap, so that no
+// logs are silently missed.
+type LogCapBackend struct {
+	protocol.ChainBackend
+	maxLogs int
+}
+
+func NewLogCapBackend(backend protocol.ChainBackend, maxLogs int) *LogCapBackend {
+	return &LogCapBackend{
+		ChainBackend: backend,
+		maxLogs:      maxLogs,
+	}
+}
+
+func (b *LogCapBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return StorageAt(ctx, b.ChainBackend, account, key, blockNumber)
+}
+
+func (b *LogCapBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := b.ChainBackend.FilterLogs(ctx, query)
+	if err != nil || len(logs) < b.maxLogs {
+		return logs, err
+	}
+	truncatedLogsCounter.Inc(1)
+	// Ranges relative to the head of the chain or a block hash cannot be narrowed.
+	if query.BlockHash != nil || query.FromBlock == nil || query.ToBlock == nil ||
+		query.FromBlock.Sign() < 0 || query.ToBlock.Sign() < 0 {
+		uncappedLogsCounter.Inc(1)
+		log.Error("Logs result may have been truncated by the provider, but its range cannot be narrowed", "numLogs", len(logs), "cap", b.maxLogs)
+		return logs, nil
+	}
+	fromBlock, toBlock := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	if fromBlock >= toBlock {
+		uncappedLogsCounter.Inc(1)
+		log.Error("Logs result of a single block may have been truncated by the provider", "block", fromBlock, "numLogs", len(logs), "cap", b.maxLogs)
+		return logs, nil
+	}
+	mid := fromBlock + (toBlock-fromBlock)/2
+	log.Warn("Logs result may have been truncated by the provider, narrowing its range", "fromBlock", fromBlock, "toBlock", toBlock, "numLogs", len(logs), "cap", b.maxLogs)
+	lower := query
+	lower.ToBlock = new(big.Int).SetUint64(mid)
+	lowerLogs, err := b.FilterLogs(ctx, lower)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not filter logs from block %d to %d", fromBlock, mid)
+	}
+	upper := query
+	upper.FromBlock = new(big.Int).SetUint64(mid + 1)
+	upperLogs, err := b.FilterLogs(ctx, upper)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not filter logs from block %d to %d", mid+1, toBlock)
+	}
+	return append(lowerLogs, upperLogs...), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Truncates every result to a maximum number of logs, as some providers do.
+type truncatingBackend struct {
+	MockContractBackend
+	logs     []types.Log
+	maxLogs  int
+	requests int
+}
+
+func (b *truncatingBackend) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	b.requests++
+	logs := make([]types.Log, 0)
+	for _, l := range b.logs {
+		if l.BlockNumber >= query.FromBlock.Uint64() && l.BlockNumber <= query.ToBlock.Uint64() && len(logs) < b.maxLogs {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func TestLogCapBackend(t *testing.T) {
+	inner := &truncatingBackend{maxLogs: 3}
+	for block := uint64(0); block < 8; block++ {
+		inner.logs = append(inner.logs, testLog(block, 0))
+	}
+	backend := NewLogCapBackend(inner, 3)
+	query := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(7)}
+	logs, err := backend.FilterLogs(context.Background(), query)
+	require.NoError(t, err)
+	require.Equal(t, inner.logs, logs)
+	require.Greater(t, inner.requests, 1)
+
+	// Results below the cap are returned as they are.
+	inner.requests = 0
+	logs, err = backend.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(6), ToBlock: big.NewInt(7)})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, 1, inner.requests)
+
+	// A single block cannot be narrowed any further.
+	inner.logs = []types.Log{testLog(1, 0), testLog(1, 1), testLog(1, 2), testLog(1, 3)}
+	logs, err = backend.FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(1), ToBlock: big.NewInt(1)})
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+}
@@ -39,6 +39,11 @@ func (t *MetricsContractBackend) CodeAt(ctx context.Context, contract common.Add
 	return t.ChainBackend.CodeAt(ctx, contract, blockNumber)
 }
 
+func (t *MetricsContractBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	metrics.GetOrRegisterCounter("arb/backend/storage_at/count", nil).Inc(1)
+	return StorageAt(ctx, t.ChainBackend, account, key, blockNumber)
+}
+
 func (t *MetricsContractBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
 	metrics.GetOrRegisterCounter("arb/backend/header_by_number/count", nil).Inc(1)
 	return t.ChainBackend.HeaderByNumber(ctx, number)
@@ -0,0 +1,305 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	externalNoncesCounter = metrics.NewRegisteredCounter("arb/validator/tx/nonce/external", nil)
+	displacedTxsCounter   = metrics.NewRegisteredCounter("arb/validator/tx/nonce/displaced", nil)
+	nonceGapsCounter      = metrics.NewRegisteredCounter("arb/validator/tx/nonce/gaps_filled", nil)
+)
+
+const (
+	defaultNonceHealInterval = 5 * time.Second
+	// The gas limit of the transactions filling nonce gaps, which transfer nothing to the sender.
+	nonceGapFillerGas = 21000
+)
+
+// A transaction sent by the transactor which is not yet known to be mined.
+type trackedTx struct {
+	tx *types.Transaction
+	// Builds the transaction again with another nonce.
+	build func(ctx context.Context, nonce uint64) (*types.Transaction, error)
+	// The nonce the transaction was first sent with, which orders the transactions displaced
+	// together when they are sent again.
+	firstNonce uint64
+}
+
+// Assigns strictly increasing nonces to the transactions sent from a key, rather than reading the
+// pending nonce of the node for each, and heals the nonces of the key when transactions sent from
+// it by someone else, such as an operator sending one by hand, desynchronize them:
+//   - Nonces consumed by others ahead of the next nonce are skipped.
+//   - Transactions whose nonce was consumed by another transaction are sent again, in the order
+//     they were first sent in, with the next nonces.
+//   - Gaps below transactions waiting for their nonce are filled, with the transaction for the
+//     nonce if it was dropped, or with a transfer of nothing to the key otherwise.
+type nonceTracker struct {
+	backend      ChainBackend
+	healInterval time.Duration
+
+	lock sync.Mutex
+	from common.Address
+	// Signs transactions filling nonce gaps, as the latest transaction sent was signed.
+	sign   func(ctx context.Context, template *types.Transaction) (*types.Transaction, error)
+	synced bool
+	next   uint64
+	// Transactions not yet known to be mined by their nonce.
+	pending map[uint64]*trackedTx
+	// The transactions sent again in the stead of displaced ones, by the hash of the displaced.
+	replacedBy map[common.Hash]*types.Transaction
+	lastHealed time.Time
+}
+
+func newNonceTracker(backend ChainBackend) *nonceTracker {
+	return &nonceTracker{
+		backend:      backend,
+		healInterval: defaultNonceHealInterval,
+		pending:      make(map[uint64]*trackedTx),
+		replacedBy:   make(map[common.Hash]*types.Transaction),
+	}
+}
+
+// Sends the transaction built with the next nonce of the key, skipping the nonces others consumed.
+func (n *nonceTracker) send(
+	ctx context.Context,
+	from common.Address,
+	build func(ctx context.Context, nonce uint64) (*types.Transaction, error),
+	sign func(ctx context.Context, template *types.Transaction) (*types.Transaction, error),
+) (*types.Transaction, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.synced && n.from != from {
+		return nil, errors.Errorf("nonces are tracked for %s rather than %s", n.from.Hex(), from.Hex())
+	}
+	n.from = from
+	n.sign = sign
+	if err := n.resync(ctx); err != nil {
+		return nil, err
+	}
+	tx, err := build(ctx, n.next)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.backend.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	n.pending[tx.Nonce()] = &trackedTx{tx: tx, build: build, firstNonce: tx.Nonce()}
+	n.next = tx.Nonce() + 1
+	return tx, nil
+}
+
+// Moves the next nonce past the nonces of the transactions others sent from the key, which the
+// node counts in the pending nonce of the key. Must be called with the lock held.
+func (n *nonceTracker) resync(ctx context.Context) error {
+	pendingNonce, err := n.backend.PendingNonceAt(ctx, n.from)
+	if err != nil {
+		return errors.Wrap(err, "could not get pending nonce")
+	}
+	if !n.synced {
+		n.next = pendingNonce
+		n.synced = true
+		return nil
+	}
+	if pendingNonce > n.next {
+		externalNoncesCounter.Inc(int64(pendingNonce - n.next))
+		log.Warn(
+			"Nonces of the validator key were consumed by transactions sent by someone else",
+			"from", n.from,
+			"nonces", pendingNonce-n.next,
+			"expectedNonce", n.next,
+			"pendingNonce", pendingNonce,
+		)
+		n.next = pendingNonce
+	}
+	return nil
+}
+
+// The latest transaction sent for a transaction, which is itself unless it was displaced.
+func (n *nonceTracker) current(tx *types.Transaction) *types.Transaction {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for {
+		replacement, ok := n.replacedBy[tx.Hash()]
+		if !ok {
+			return tx
+		}
+		tx = replacement
+	}
+}
+
+// Forgets the transactions sent in the stead of a transaction, once the latest was mined.
+func (n *nonceTracker) forget(tx *types.Transaction) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for {
+		replacement, ok := n.replacedBy[tx.Hash()]
+		if !ok {
+			return
+		}
+		delete(n.replacedBy, tx.Hash())
+		tx = replacement
+	}
+}
+
+// Heals the nonces of the key if they were desynchronized since they were last healed, which is
+// done at most once per heal interval.
+func (n *nonceTracker) heal(ctx context.Context) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if len(n.pending) == 0 || time.Since(n.lastHealed) < n.healInterval {
+		return nil
+	}
+	reader, ok := n.backend.(accountReader)
+	if !ok {
+		return nil
+	}
+	n.lastHealed = time.Now()
+	minedNonce, err := reader.NonceAt(ctx, n.from, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not get latest nonce")
+	}
+
+	// The nonces below the mined nonce were consumed either by our transactions or by others.
+	displaced := make([]*trackedTx, 0)
+	for nonce, tracked := range n.pending {
+		if nonce >= minedNonce {
+			continue
+		}
+		receipt, err := n.backend.TransactionReceipt(ctx, tracked.tx.Hash())
+		switch {
+		case err == nil && receipt != nil:
+		case err == nil || errors.Is(err, ethereum.NotFound):
+			displaced = append(displaced, tracked)
+		default:
+			return errors.Wrapf(err, "could not get receipt of transaction %#x", tracked.tx.Hash())
+		}
+		delete(n.pending, nonce)
+	}
+	if err := n.resync(ctx); err != nil {
+		return err
+	}
+	if err := n.fillGaps(ctx); err != nil {
+		return err
+	}
+
+	sort.Slice(displaced, func(i, j int) bool {
+		return displaced[i].firstNonce < displaced[j].firstNonce
+	})
+	for _, tracked := range displaced {
+		tx, err := tracked.build(ctx, n.next)
+		if err != nil {
+			return errors.Wrapf(err, "could not rebuild displaced transaction %#x", tracked.tx.Hash())
+		}
+		if err := n.backend.SendTransaction(ctx, tx); err != nil {
+			return errors.Wrapf(err, "could not send displaced transaction %#x again", tracked.tx.Hash())
+		}
+		displacedTxsCounter.Inc(1)
+		log.Warn(
+			"Transaction was displaced by a transaction sent by someone else with its nonce, sent it again",
+			"from", n.from,
+			"displacedTxHash", tracked.tx.Hash(),
+			"displacedNonce", tracked.tx.Nonce(),
+			"txHash", tx.Hash(),
+			"nonce", tx.Nonce(),
+		)
+		n.replacedBy[tracked.tx.Hash()] = tx
+		n.pending[tx.Nonce()] = &trackedTx{tx: tx, build: tracked.build, firstNonce: tracked.firstNonce}
+		n.next = tx.Nonce() + 1
+	}
+	return nil
+}
+
+// Fills the nonces the node has no transaction for below our transactions waiting for their nonce,
+// from the pending nonce of the key, which is the first one the node has no transaction for. Must
+// be called with the lock held.
+func (n *nonceTracker) fillGaps(ctx context.Context) error {
+	pendingNonce, err := n.backend.PendingNonceAt(ctx, n.from)
+	if err != nil {
+		return errors.Wrap(err, "could not get pending nonce")
+	}
+	var highest uint64
+	for nonce := range n.pending {
+		highest = max(highest, nonce)
+	}
+	for nonce := pendingNonce; nonce < highest; nonce++ {
+		if tracked, ok := n.pending[nonce]; ok {
+			// The node dropped our transaction, so it is sent to it again as is.
+			if err := n.backend.SendTransaction(ctx, tracked.tx); err != nil {
+				return errors.Wrapf(err, "could not send dropped transaction %#x again", tracked.tx.Hash())
+			}
+			log.Warn("Sent a transaction dropped by the node again", "txHash", tracked.tx.Hash(), "nonce", nonce)
+			continue
+		}
+		filler, err := n.gapFiller(ctx, nonce)
+		if err != nil {
+			return err
+		}
+		if err := n.backend.SendTransaction(ctx, filler); err != nil {
+			return errors.Wrapf(err, "could not fill nonce gap at %d", nonce)
+		}
+		nonceGapsCounter.Inc(1)
+		log.Warn(
+			"Filled a nonce gap below transactions waiting for their nonce",
+			"from", n.from,
+			"nonce", nonce,
+			"txHash", filler.Hash(),
+		)
+	}
+	return nil
+}
+
+// Builds a transfer of nothing from the key to itself with a nonce, to consume it.
+func (n *nonceTracker) gapFiller(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+	tip, err := n.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not suggest gas tip cap")
+	}
+	header, err := n.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get latest header")
+	}
+	to := n.from
+	var template *types.Transaction
+	if header != nil && header.BaseFee != nil {
+		template = types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       nonceGapFillerGas,
+			GasTipCap: tip,
+			GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(header.BaseFee, big.NewInt(2))),
+			Value:     big.NewInt(0),
+		})
+	} else {
+		gasPrice, err := n.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not suggest gas price")
+		}
+		template = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &to,
+			Gas:      nonceGapFillerGas,
+			GasPrice: gasPrice,
+			Value:    big.NewInt(0),
+		})
+	}
+	filler, err := n.sign(ctx, template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not sign nonce gap filler at %d", nonce)
+	}
+	return filler, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// A node whose nonces are set by the test, standing in for transactions sent by someone else.
+type nonceBackend struct {
+	MockContractBackend
+	minedNonce   uint64
+	pendingNonce uint64
+	sent         []*types.Transaction
+	receipts     map[common.Hash]*types.Receipt
+}
+
+func (b *nonceBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	b.pendingNonce = max(b.pendingNonce, tx.Nonce()+1)
+	return nil
+}
+
+func (b *nonceBackend) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return b.pendingNonce, nil
+}
+
+func (b *nonceBackend) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return b.minedNonce, nil
+}
+
+func (b *nonceBackend) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (b *nonceBackend) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(10)}, nil
+}
+
+func (b *nonceBackend) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, ok := b.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// Mines the transactions sent from the key up to a nonce, with receipts for the given ones.
+func (b *nonceBackend) mine(nonce uint64, txs ...*types.Transaction) {
+	b.minedNonce = nonce
+	b.pendingNonce = max(b.pendingNonce, nonce)
+	for _, tx := range txs {
+		b.receipts[tx.Hash()] = &types.Receipt{TxHash: tx.Hash(), Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(1)}
+	}
+}
+
+func setupNonceHealing(t *testing.T, nonce uint64) (*nonceBackend, *ChainBackendTransactor, func(data byte) *types.Transaction) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+	backend := &nonceBackend{
+		minedNonce:   nonce,
+		pendingNonce: nonce,
+		receipts:     make(map[common.Hash]*types.Receipt),
+	}
+	transactor := NewChainBackendTransactor(backend, WithNonceHealing())
+	transactor.nonces.healInterval = 0
+	to := common.BytesToAddress([]byte("to"))
+	send := func(data byte) *types.Transaction {
+		// Builds transactions with the nonce of the options, as the bindings do.
+		fn := func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			require.NotNil(t, opts.Nonce)
+			return opts.Signer(opts.From, types.NewTx(&types.DynamicFeeTx{
+				Nonce:     opts.Nonce.Uint64(),
+				To:        &to,
+				Gas:       100000,
+				GasTipCap: big.NewInt(1),
+				GasFeeCap: big.NewInt(100),
+				Data:      []byte{data},
+			}))
+		}
+		tx, err := transactor.SendTransaction(context.Background(), fn, copyTxOpts(opts), 100000)
+		require.NoError(t, err)
+		return tx
+	}
+	return backend, transactor, send
+}
+
+func TestNonceHealing_SkipsExternallyConsumedNonces(t *testing.T) {
+	backend, _, send := setupNonceHealing(t, 5)
+	require.Equal(t, uint64(5), send(1).Nonce())
+	require.Equal(t, uint64(6), send(2).Nonce())
+
+	// Someone else sends two transactions from the key.
+	backend.pendingNonce = 9
+	require.Equal(t, uint64(9), send(3).Nonce())
+
+	// A node lagging behind our transactions does not make us reuse their nonces.
+	backend.pendingNonce = 7
+	require.Equal(t, uint64(10), send(4).Nonce())
+}
+
+func TestNonceHealing_ResendsDisplacedTransactionsInOrder(t *testing.T) {
+	ctx := context.Background()
+	backend, transactor, send := setupNonceHealing(t, 5)
+	first := send(1)
+	second := send(2)
+	third := send(3)
+
+	// Someone else replaced our transactions with nonces 5 and 6, and ours with nonce 7 was mined.
+	backend.mine(8, third)
+	require.NoError(t, transactor.nonces.heal(ctx))
+	require.Len(t, backend.sent, 5)
+	resentFirst, resentSecond := backend.sent[3], backend.sent[4]
+	require.Equal(t, first.Data(), resentFirst.Data())
+	require.Equal(t, uint64(8), resentFirst.Nonce())
+	require.Equal(t, second.Data(), resentSecond.Data())
+	require.Equal(t, uint64(9), resentSecond.Nonce())
+
+	// Waiting for a displaced transaction follows the one sent in its stead.
+	backend.mine(10, resentFirst, resentSecond)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	mined, receipt, err := transactor.WaitMined(ctx, second)
+	require.NoError(t, err)
+	require.Equal(t, resentSecond.Hash(), mined.Hash())
+	require.Equal(t, resentSecond.Hash(), receipt.TxHash)
+	mined, _, err = transactor.WaitMined(ctx, third)
+	require.NoError(t, err)
+	require.Equal(t, third.Hash(), mined.Hash())
+
+	// Nothing is sent again once everything was mined.
+	require.NoError(t, transactor.nonces.heal(ctx))
+	require.Len(t, backend.sent, 5)
+	require.Equal(t, uint64(10), send(4).Nonce())
+}
+
+func TestNonceHealing_FillsGaps(t *testing.T) {
+	ctx := context.Background()
+	backend, transactor, send := setupNonceHealing(t, 5)
+	// Someone else sends a transaction with nonce 5, so ours takes 6.
+	backend.pendingNonce = 6
+	ours := send(1)
+	require.Equal(t, uint64(6), ours.Nonce())
+	next := send(2)
+
+	// The node drops the transaction of someone else, and ours with nonce 6.
+	backend.pendingNonce = 5
+	require.NoError(t, transactor.nonces.heal(ctx))
+	require.Len(t, backend.sent, 4)
+	filler := backend.sent[2]
+	require.Equal(t, uint64(5), filler.Nonce())
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1337)), filler)
+	require.NoError(t, err)
+	require.Equal(t, sender, *filler.To())
+	require.Equal(t, big.NewInt(0), filler.Value())
+	require.Equal(t, ours.Hash(), backend.sent[3].Hash())
+
+	// Our transactions are not displaced by the filler.
+	backend.mine(8, ours, next)
+	require.NoError(t, transactor.nonces.heal(ctx))
+	require.Len(t, backend.sent, 4)
+}
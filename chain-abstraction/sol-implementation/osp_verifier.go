@@ -0,0 +1,83 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/ospgen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// OneStepProofVerifier checks one step proofs with a call to the one step proof entry of the
+// challenge manager, which is the verifier edges are confirmed by, without sending a transaction.
+type OneStepProofVerifier struct {
+	chain   *AssertionChain
+	entry   *ospgen.OneStepProofEntryCaller
+	execCtx ospgen.ExecutionContext
+}
+
+// NewOneStepProofVerifier for proofs of machines executing with a wasm module root, which may read
+// up to maxInboxMessagesRead messages.
+func NewOneStepProofVerifier(
+	ctx context.Context,
+	chain *AssertionChain,
+	wasmModuleRoot common.Hash,
+	maxInboxMessagesRead *big.Int,
+) (*OneStepProofVerifier, error) {
+	cm, ok := chain.specChallengeManager.(*specChallengeManager)
+	if !ok {
+		return nil, errors.New("challenge manager does not expose its one step proof entry")
+	}
+	opts := chain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx})
+	entryAddr, err := cm.caller.OneStepProofEntry(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get one step proof entry of challenge manager")
+	}
+	entry, err := ospgen.NewOneStepProofEntryCaller(entryAddr, cm.backend)
+	if err != nil {
+		return nil, err
+	}
+	bridgeAddr, err := chain.rollup.Bridge(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get bridge of rollup")
+	}
+	return &OneStepProofVerifier{
+		chain: chain,
+		entry: entry,
+		execCtx: ospgen.ExecutionContext{
+			MaxInboxMessagesRead:  maxInboxMessagesRead,
+			Bridge:                bridgeAddr,
+			InitialWasmModuleRoot: wasmModuleRoot,
+		},
+	}, nil
+}
+
+// VerifyOneStepProof checks that the one step proof entry proves the before hash of the proof at
+// a machine step transitions into its after hash.
+func (v *OneStepProofVerifier) VerifyOneStepProof(ctx context.Context, machineStep uint64, data *protocol.OneStepData) error {
+	afterHash, err := v.entry.ProveOneStep(
+		v.chain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}),
+		v.execCtx,
+		new(big.Int).SetUint64(machineStep),
+		data.BeforeHash,
+		data.Proof,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "one step proof entry rejected proof at machine step %d from before hash %#x", machineStep, data.BeforeHash)
+	}
+	if afterHash != data.AfterHash {
+		return errors.Errorf(
+			"one step proof entry proved after hash %#x at machine step %d, but the proof claims %#x",
+			afterHash,
+			machineStep,
+			data.AfterHash,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	challenge_testing "github.com/OffchainLabs/bold/testing"
+	stateprovider "github.com/OffchainLabs/bold/testing/mocks/state-provider"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOneStepProofVerifier(t *testing.T) {
+	ctx := context.Background()
+	chainSetup, err := setup.ChainsWithEdgeChallengeManager(setup.WithMockOneStepProver())
+	require.NoError(t, err)
+	verifier, err := solimpl.NewOneStepProofVerifier(ctx, chainSetup.Chains[0], common.Hash{}, big.NewInt(1))
+	require.NoError(t, err)
+
+	// The self test passes over a machine whose proofs the deployed verifier accepts.
+	machine, err := stateprovider.NewForSimpleMachine()
+	require.NoError(t, err)
+	heights := []l2stateprovider.Height{
+		challenge_testing.LevelZeroBlockEdgeHeight,
+		challenge_testing.LevelZeroBigStepEdgeHeight,
+		challenge_testing.LevelZeroSmallStepEdgeHeight,
+	}
+	require.NoError(t, l2stateprovider.SelfTest(
+		ctx,
+		l2stateprovider.WithSelfTestMachine(machine, heights, common.Hash{}),
+		l2stateprovider.WithOneStepProofVerifier(verifier),
+	))
+
+	// Proofs of the synthetic machine are rejected by it.
+	err = l2stateprovider.SelfTest(ctx, l2stateprovider.WithOneStepProofVerifier(verifier))
+	require.ErrorContains(t, err, "one step proof entry")
+
+	// As are proofs claiming the wrong after hash.
+	proof, err := machine.CollectProof(ctx, common.Hash{}, 0, 0, 0)
+	require.NoError(t, err)
+	err = verifier.VerifyOneStepProof(ctx, 0, &protocol.OneStepData{
+		BeforeHash: protocol.GoGlobalState{}.Hash(),
+		AfterHash:  common.Hash{1},
+		Proof:      proof,
+	})
+	require.ErrorContains(t, err, "but the proof claims")
+}
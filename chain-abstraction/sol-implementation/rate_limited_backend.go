@@ -0,0 +1,122 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitedContractBackend waits on a rate limiter before each request to the wrapped chain
+// backend. As every service of a validator reaches the chain through the assertion chain's backend,
+// wrapping it bounds the request rate of the validator as a whole, however many requests its
+// services make concurrently.
+type RateLimitedContractBackend struct {
+	protocol.ChainBackend
+	limiter *rate.Limiter
+}
+
+func NewRateLimitedContractBackend(backend protocol.ChainBackend, limiter *rate.Limiter) *RateLimitedContractBackend {
+	return &RateLimitedContractBackend{
+		ChainBackend: backend,
+		limiter:      limiter,
+	}
+}
+
+func (t *RateLimitedContractBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.CallContract(ctx, call, blockNumber)
+}
+
+func (t *RateLimitedContractBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.CodeAt(ctx, contract, blockNumber)
+}
+
+func (t *RateLimitedContractBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return StorageAt(ctx, t.ChainBackend, account, key, blockNumber)
+}
+
+func (t *RateLimitedContractBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.HeaderByNumber(ctx, number)
+}
+
+func (t *RateLimitedContractBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.PendingCodeAt(ctx, account)
+}
+
+func (t *RateLimitedContractBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	return t.ChainBackend.PendingNonceAt(ctx, account)
+}
+
+func (t *RateLimitedContractBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.SuggestGasPrice(ctx)
+}
+
+func (t *RateLimitedContractBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.SuggestGasTipCap(ctx)
+}
+
+func (t *RateLimitedContractBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	return t.ChainBackend.EstimateGas(ctx, call)
+}
+
+func (t *RateLimitedContractBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return t.ChainBackend.SendTransaction(ctx, tx)
+}
+
+func (t *RateLimitedContractBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.FilterLogs(ctx, query)
+}
+
+func (t *RateLimitedContractBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.ChainBackend.TransactionReceipt(ctx, txHash)
+}
+
+func (t *RateLimitedContractBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, false, err
+	}
+	return t.ChainBackend.TransactionByHash(ctx, txHash)
+}
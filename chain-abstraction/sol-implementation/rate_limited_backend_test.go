@@ -0,0 +1,29 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitedContractBackend(t *testing.T) {
+	backend := NewRateLimitedContractBackend(&MockContractBackend{}, rate.NewLimiter(rate.Every(time.Hour), 2))
+	ctx := context.Background()
+	_, err := backend.FilterLogs(ctx, ethereum.FilterQuery{})
+	require.NoError(t, err)
+	_, err = backend.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+
+	// The burst is used up, so the next request waits longer than the deadline allows.
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = backend.CallContract(ctx, ethereum.CallMsg{}, nil)
+	require.Error(t, err)
+}
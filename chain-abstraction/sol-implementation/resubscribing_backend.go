@@ -0,0 +1,260 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+var (
+	resubscriptionsCounter = metrics.NewRegisteredCounter("arb/validator/rpc/resubscriptions", nil)
+	backfilledHeadsCounter = metrics.NewRegisteredCounter("arb/validator/rpc/backfilled_heads", nil)
+	backfilledLogsCounter  = metrics.NewRegisteredCounter("arb/validator/rpc/backfilled_logs", nil)
+)
+
+// The longest wait between attempts to resubscribe.
+const defaultResubscribeBackoff = 30 * time.Second
+
+// DialChainBackend connects to a parent chain endpoint. Websocket endpoints are wrapped in a
+// ResubscribingBackend, as websocket connections drop their subscriptions when they are lost.
+// Requests reconnect by themselves, as the underlying client redials a lost websocket connection
+// on the next request.
+func DialChainBackend(ctx context.Context, endpoint string) (protocol.ChainBackend, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse endpoint")
+	}
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not dial %s endpoint", u.Scheme)
+	}
+	backend := ethclient.NewClient(client)
+	if u.Scheme == "ws" || u.Scheme == "wss" {
+		return NewResubscribingBackend(backend, defaultResubscribeBackoff), nil
+	}
+	return backend, nil
+}
+
+// ResubscribingBackend wraps a chain backend whose subscriptions may be dropped, such as one
+// connected over a websocket. Dropped head and log subscriptions are resubscribed with a backoff,
+// and the heads and logs emitted while a subscription was down are fetched and delivered before
+// those of the new subscription, so subscribers see no gap. The error channel of a subscription
+// only closes once it is unsubscribed.
+type ResubscribingBackend struct {
+	protocol.ChainBackend
+	backoffMax time.Duration
+}
+
+func NewResubscribingBackend(backend protocol.ChainBackend, backoffMax time.Duration) *ResubscribingBackend {
+	return &ResubscribingBackend{
+		ChainBackend: backend,
+		backoffMax:   backoffMax,
+	}
+}
+
+// Resubscribes through subscribe whenever a subscription fails. The first subscription is made
+// before returning, so a backend which does not support subscriptions at all fails immediately.
+// Each subscription is served by serve until it fails, with resubscribed set for all but the first.
+func (b *ResubscribingBackend) resubscribe(
+	ctx context.Context,
+	subscribe func(ctx context.Context) (ethereum.Subscription, error),
+	serve func(sub ethereum.Subscription, resubscribed bool, quit <-chan struct{}) error,
+) (ethereum.Subscription, error) {
+	first, err := subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var lock sync.Mutex
+	return event.ResubscribeErr(b.backoffMax, func(ctx context.Context, lastErr error) (event.Subscription, error) {
+		lock.Lock()
+		sub, resubscribed := first, first == nil
+		first = nil
+		lock.Unlock()
+		if resubscribed {
+			log.Warn("Subscription to the chain backend was dropped, resubscribing", "err", lastErr)
+			resubscriptionsCounter.Inc(1)
+			var err error
+			if sub, err = subscribe(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			defer sub.Unsubscribe()
+			return serve(sub, resubscribed, quit)
+		}), nil
+	}), nil
+}
+
+func (b *ResubscribingBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return StorageAt(ctx, b.ChainBackend, account, key, blockNumber)
+}
+
+// SubscribeNewHead subscribes to new heads, backfilling the heads between the last one delivered
+// and the latest one after resubscribing.
+func (b *ResubscribingBackend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	var inner chan *types.Header
+	var lastDelivered *types.Header
+	deliver := func(header *types.Header, quit <-chan struct{}) bool {
+		select {
+		case ch <- header:
+			lastDelivered = header
+			return true
+		case <-quit:
+			return false
+		}
+	}
+	return b.resubscribe(
+		ctx,
+		func(ctx context.Context) (ethereum.Subscription, error) {
+			inner = make(chan *types.Header, cap(ch))
+			return b.ChainBackend.SubscribeNewHead(ctx, inner)
+		},
+		func(sub ethereum.Subscription, resubscribed bool, quit <-chan struct{}) error {
+			if resubscribed && lastDelivered != nil {
+				if err := b.backfillHeads(lastDelivered.Number, quit, deliver); err != nil {
+					return err
+				}
+			}
+			for {
+				select {
+				case header := <-inner:
+					// The new subscription may repeat the latest head we backfilled.
+					if lastDelivered != nil && header.Hash() == lastDelivered.Hash() {
+						continue
+					}
+					if !deliver(header, quit) {
+						return nil
+					}
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			}
+		},
+	)
+}
+
+func (b *ResubscribingBackend) backfillHeads(
+	after *big.Int,
+	quit <-chan struct{},
+	deliver func(*types.Header, <-chan struct{}) bool,
+) error {
+	ctx := context.Background()
+	latest, err := b.ChainBackend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not get latest header to backfill heads")
+	}
+	for n := new(big.Int).Add(after, common.Big1); n.Cmp(latest.Number) <= 0; n.Add(n, common.Big1) {
+		header := latest
+		if n.Cmp(latest.Number) < 0 {
+			if header, err = b.ChainBackend.HeaderByNumber(ctx, n); err != nil {
+				return errors.Wrapf(err, "could not get header %d to backfill heads", n)
+			}
+		}
+		if !deliver(header, quit) {
+			return nil
+		}
+		backfilledHeadsCounter.Inc(1)
+	}
+	return nil
+}
+
+// SubscribeFilterLogs subscribes to logs matching a query, backfilling the logs from the block of
+// the last one delivered up to the latest block after resubscribing. If no log was delivered
+// before the subscription dropped, logs are backfilled from the block at which it was made.
+func (b *ResubscribingBackend) SubscribeFilterLogs(
+	ctx context.Context,
+	query ethereum.FilterQuery,
+	ch chan<- types.Log,
+) (ethereum.Subscription, error) {
+	header, err := b.ChainBackend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get latest header to subscribe to logs")
+	}
+	// The logs delivered in the block of the last log delivered, which a backfill starting at
+	// that block must skip.
+	lastBlock := header.Number.Uint64()
+	deliveredInLastBlock := make(map[uint]bool)
+	var inner chan types.Log
+	deliver := func(l types.Log, quit <-chan struct{}) bool {
+		if l.BlockNumber < lastBlock || (l.BlockNumber == lastBlock && deliveredInLastBlock[l.Index]) {
+			return true
+		}
+		select {
+		case ch <- l:
+		case <-quit:
+			return false
+		}
+		if l.BlockNumber > lastBlock {
+			lastBlock = l.BlockNumber
+			deliveredInLastBlock = make(map[uint]bool)
+		}
+		deliveredInLastBlock[l.Index] = true
+		return true
+	}
+	return b.resubscribe(
+		ctx,
+		func(ctx context.Context) (ethereum.Subscription, error) {
+			inner = make(chan types.Log, cap(ch))
+			return b.ChainBackend.SubscribeFilterLogs(ctx, query, inner)
+		},
+		func(sub ethereum.Subscription, resubscribed bool, quit <-chan struct{}) error {
+			if resubscribed {
+				backfill := query
+				backfill.FromBlock = new(big.Int).SetUint64(lastBlock)
+				backfill.ToBlock = nil
+				logs, err := b.ChainBackend.FilterLogs(context.Background(), backfill)
+				if err != nil {
+					return errors.Wrapf(err, "could not backfill logs from block %d", lastBlock)
+				}
+				for _, l := range logs {
+					if !deliver(l, quit) {
+						return nil
+					}
+					backfilledLogsCounter.Inc(1)
+				}
+			}
+			for {
+				select {
+				case l := <-inner:
+					// Removed logs of a reorg precede the logs of the new chain, which may
+					// be at lower blocks than those we delivered.
+					if l.Removed {
+						select {
+						case ch <- l:
+						case <-quit:
+							return nil
+						}
+						lastBlock = min(lastBlock, l.BlockNumber)
+						deliveredInLastBlock = make(map[uint]bool)
+						continue
+					}
+					if !deliver(l, quit) {
+						return nil
+					}
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			}
+		},
+	)
+}
@@ -0,0 +1,213 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResubscribingBackend_BackfillsHeads(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeSubscriptionBackend(1)
+	resubscribing := NewResubscribingBackend(backend, time.Second)
+	heads := make(chan *types.Header, 10)
+	sub, err := resubscribing.SubscribeNewHead(ctx, heads)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	first := backend.nextSubscription(t)
+	first.heads <- backend.header(1)
+	require.Equal(t, uint64(1), receive(t, heads).Number.Uint64())
+
+	// Heads mined while the subscription is down are backfilled once it is back.
+	backend.mine(4)
+	first.err <- errors.New("connection lost")
+	second := backend.nextSubscription(t)
+	for want := uint64(2); want <= 4; want++ {
+		require.Equal(t, want, receive(t, heads).Number.Uint64())
+	}
+
+	// The new subscription repeating the latest backfilled head is not delivered twice.
+	second.heads <- backend.header(4)
+	backend.mine(5)
+	second.heads <- backend.header(5)
+	require.Equal(t, uint64(5), receive(t, heads).Number.Uint64())
+	require.Empty(t, heads)
+}
+
+func TestResubscribingBackend_BackfillsLogs(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeSubscriptionBackend(10)
+	resubscribing := NewResubscribingBackend(backend, time.Second)
+	logs := make(chan types.Log, 10)
+	sub, err := resubscribing.SubscribeFilterLogs(ctx, ethereum.FilterQuery{}, logs)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	first := backend.nextSubscription(t)
+	first.logs <- testLog(11, 0)
+	first.logs <- testLog(11, 1)
+	requireLog(t, logs, 11, 0, false)
+	requireLog(t, logs, 11, 1, false)
+
+	// The backfill starts at the block of the last log delivered, skipping those already delivered.
+	backend.setLogs(testLog(11, 0), testLog(11, 1), testLog(12, 0))
+	first.err <- errors.New("connection lost")
+	second := backend.nextSubscription(t)
+	requireLog(t, logs, 12, 0, false)
+
+	second.logs <- testLog(12, 0)
+	second.logs <- testLog(13, 0)
+	requireLog(t, logs, 13, 0, false)
+
+	// Logs removed by a reorg are forwarded, and the logs replacing them delivered.
+	removed := testLog(13, 0)
+	removed.Removed = true
+	second.logs <- removed
+	replacement := testLog(13, 0)
+	replacement.TxHash = common.BytesToHash([]byte("replacement"))
+	second.logs <- replacement
+	requireLog(t, logs, 13, 0, true)
+	require.Equal(t, replacement.TxHash, receive(t, logs).TxHash)
+	require.Empty(t, logs)
+}
+
+func TestResubscribingBackend_InitialSubscriptionFails(t *testing.T) {
+	backend := newFakeSubscriptionBackend(1)
+	backend.subscribeErr = errors.New("notifications not supported")
+	resubscribing := NewResubscribingBackend(backend, time.Second)
+	_, err := resubscribing.SubscribeNewHead(context.Background(), make(chan *types.Header))
+	require.ErrorContains(t, err, "notifications not supported")
+	_, err = resubscribing.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, make(chan types.Log))
+	require.ErrorContains(t, err, "notifications not supported")
+}
+
+// A subscription of the fake backend, through which a test delivers heads or logs and drops the
+// subscription by sending an error.
+type fakeSubscription struct {
+	heads chan<- *types.Header
+	logs  chan<- types.Log
+	err   chan error
+}
+
+func (s *fakeSubscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *fakeSubscription) Unsubscribe() {}
+
+type fakeSubscriptionBackend struct {
+	MockContractBackend
+	lock          sync.Mutex
+	latest        uint64
+	logs          []types.Log
+	subscribeErr  error
+	subscriptions chan *fakeSubscription
+}
+
+func newFakeSubscriptionBackend(latest uint64) *fakeSubscriptionBackend {
+	return &fakeSubscriptionBackend{
+		latest:        latest,
+		subscriptions: make(chan *fakeSubscription, 10),
+	}
+}
+
+func (b *fakeSubscriptionBackend) header(number uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number)}
+}
+
+func (b *fakeSubscriptionBackend) mine(latest uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.latest = latest
+}
+
+func (b *fakeSubscriptionBackend) setLogs(logs ...types.Log) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.logs = logs
+}
+
+func (b *fakeSubscriptionBackend) nextSubscription(t *testing.T) *fakeSubscription {
+	select {
+	case sub := <-b.subscriptions:
+		return sub
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a subscription")
+		return nil
+	}
+}
+
+func (b *fakeSubscriptionBackend) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if number == nil {
+		return b.header(b.latest), nil
+	}
+	return b.header(number.Uint64()), nil
+}
+
+func (b *fakeSubscriptionBackend) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	logs := make([]types.Log, 0)
+	for _, l := range b.logs {
+		if query.FromBlock == nil || l.BlockNumber >= query.FromBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (b *fakeSubscriptionBackend) SubscribeNewHead(_ context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	if b.subscribeErr != nil {
+		return nil, b.subscribeErr
+	}
+	sub := &fakeSubscription{heads: ch, err: make(chan error, 1)}
+	b.subscriptions <- sub
+	return sub, nil
+}
+
+func (b *fakeSubscriptionBackend) SubscribeFilterLogs(
+	_ context.Context, _ ethereum.FilterQuery, ch chan<- types.Log,
+) (ethereum.Subscription, error) {
+	if b.subscribeErr != nil {
+		return nil, b.subscribeErr
+	}
+	sub := &fakeSubscription{logs: ch, err: make(chan error, 1)}
+	b.subscriptions <- sub
+	return sub, nil
+}
+
+func testLog(block uint64, index uint) types.Log {
+	return types.Log{BlockNumber: block, Index: index}
+}
+
+func requireLog(t *testing.T, logs <-chan types.Log, block uint64, index uint, removed bool) {
+	l := receive(t, logs)
+	require.Equal(t, block, l.BlockNumber)
+	require.Equal(t, index, l.Index)
+	require.Equal(t, removed, l.Removed)
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a subscription to deliver")
+		var zero T
+		return zero
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// The gas limit with which the calldata of a reverting transaction is built, which is never sent.
+const reproGasLimit = 30_000_000
+
+// RevertRepro describes a call to a contract which reverted unexpectedly, with everything needed to
+// replay it against the same chain state with Foundry.
+type RevertRepro struct {
+	From  common.Address
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+	// The block whose post-state the call reverted against, or nil for the latest block.
+	BlockNumber *big.Int
+	// The reverted transaction, if it was mined rather than rejected by gas estimation.
+	TxHash common.Hash
+}
+
+// RevertError is returned by the assertion chain when one of its transactions reverts, carrying a
+// reproduction of the revert for error reports.
+type RevertError struct {
+	Err   error
+	Repro *RevertRepro
+}
+
+func (e *RevertError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Err
+}
+
+// CastCommands are shell commands replaying the revert with cast against the RPC endpoint in the
+// ETH_RPC_URL environment variable.
+func (r *RevertRepro) CastCommands() string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	if r.TxHash != (common.Hash{}) {
+		fmt.Fprintf(&b, "# Replays the reverted transaction with a trace.\ncast run --rpc-url \"$ETH_RPC_URL\" %#x\n", r.TxHash)
+	}
+	fmt.Fprintf(&b, "# Calls the contract with the reverted calldata.\ncast call --rpc-url \"$ETH_RPC_URL\" --trace --from %s", r.From.Hex())
+	if r.Value != nil && r.Value.Sign() != 0 {
+		fmt.Fprintf(&b, " --value %s", r.Value)
+	}
+	if r.BlockNumber != nil {
+		fmt.Fprintf(&b, " --block %s", r.BlockNumber)
+	}
+	fmt.Fprintf(&b, " %s %s\n", r.To.Hex(), hexutil.Encode(r.Data))
+	return b.String()
+}
+
+// ForgeScript is a Foundry script replaying the revert on a fork of the chain at the RPC endpoint
+// in the ETH_RPC_URL environment variable, to be run with forge script.
+func (r *RevertRepro) ForgeScript() string {
+	fork := "vm.createSelectFork(vm.envString(\"ETH_RPC_URL\"));"
+	switch {
+	case r.TxHash != (common.Hash{}):
+		// Forking at a transaction replays the transactions before it in its block.
+		fork = fmt.Sprintf("vm.createSelectFork(vm.envString(\"ETH_RPC_URL\"), bytes32(%#x));", r.TxHash)
+	case r.BlockNumber != nil:
+		fork = fmt.Sprintf("vm.createSelectFork(vm.envString(\"ETH_RPC_URL\"), %s);", r.BlockNumber)
+	}
+	value := "0"
+	if r.Value != nil {
+		value = r.Value.String()
+	}
+	return fmt.Sprintf(`// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.0;
+
+import {Script} from "forge-std/Script.sol";
+
+contract RevertRepro is Script {
+    function run() external {
+        %s
+        vm.prank(%s);
+        (bool success, bytes memory result) = address(%s).call{value: %s}(hex"%x");
+        if (!success) {
+            assembly {
+                revert(add(result, 32), mload(result))
+            }
+        }
+    }
+}
+`, fork, r.From.Hex(), r.To.Hex(), value, r.Data)
+}
+
+// Whether an error is a revert of a contract call, rather than a failure to reach the node.
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
+
+// Attaches a reproduction of a reverted call to its error, writing it to the repro directory of the
+// chain if it has one. Errors which are not reverts are returned as they are.
+func (a *AssertionChain) withRevertRepro(ctx context.Context, backend ChainBackend, err error, repro *RevertRepro) error {
+	if !isRevert(err) {
+		return err
+	}
+	if repro.BlockNumber == nil && repro.TxHash == (common.Hash{}) {
+		if header, headerErr := backend.HeaderByNumber(ctx, nil); headerErr == nil {
+			repro.BlockNumber = header.Number
+		}
+	}
+	if a.revertReproDir != "" {
+		if writeErr := writeRevertRepro(a.revertReproDir, time.Now(), repro); writeErr != nil {
+			log.Error("Could not write reproduction of reverted call", "to", repro.To, "err", writeErr)
+		}
+	}
+	return &RevertError{Err: err, Repro: repro}
+}
+
+// Writes the cast commands and forge script reproducing a revert to a directory, named after the
+// time of the revert and the selector of the call.
+func writeRevertRepro(dir string, at time.Time, repro *RevertRepro) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s", at.UTC().Format("20060102T150405.000000000Z"), repro.To.Hex())
+	if len(repro.Data) >= 4 {
+		name += fmt.Sprintf("-%x", repro.Data[:4])
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path+".sh", []byte(repro.CastCommands()), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".s.sol", []byte(repro.ForgeScript()), 0o644); err != nil {
+		return err
+	}
+	log.Warn("Wrote reproduction of reverted call", "cast", path+".sh", "forge", path+".s.sol")
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertRepro(t *testing.T) {
+	repro := &RevertRepro{
+		From:        common.HexToAddress("0x01"),
+		To:          common.HexToAddress("0x02"),
+		Data:        []byte{0xde, 0xad, 0xbe, 0xef, 0x01},
+		Value:       big.NewInt(0),
+		BlockNumber: big.NewInt(100),
+	}
+	require.Equal(t, `#!/bin/sh
+# Calls the contract with the reverted calldata.
+cast call --rpc-url "$ETH_RPC_URL" --trace --from 0x0000000000000000000000000000000000000001 --block 100 0x0000000000000000000000000000000000000002 0xdeadbeef01
+`, repro.CastCommands())
+	script := repro.ForgeScript()
+	require.Contains(t, script, `vm.createSelectFork(vm.envString("ETH_RPC_URL"), 100);`)
+	require.Contains(t, script, `vm.prank(0x0000000000000000000000000000000000000001);`)
+	require.Contains(t, script, `address(0x0000000000000000000000000000000000000002).call{value: 0}(hex"deadbeef01")`)
+
+	// Mined transactions are replayed from the transaction itself.
+	repro.TxHash = common.HexToHash("0x03")
+	require.Contains(t, repro.CastCommands(), "cast run --rpc-url \"$ETH_RPC_URL\" 0x0000000000000000000000000000000000000000000000000000000000000003\n")
+	require.Contains(t, repro.ForgeScript(), `vm.createSelectFork(vm.envString("ETH_RPC_URL"), bytes32(0x0000000000000000000000000000000000000000000000000000000000000003));`)
+
+	dir := t.TempDir()
+	require.NoError(t, writeRevertRepro(dir, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), repro))
+	name := filepath.Join(dir, "20240102T030405.000000000Z-0x0000000000000000000000000000000000000002-deadbeef")
+	cast, err := os.ReadFile(name + ".sh")
+	require.NoError(t, err)
+	require.Equal(t, repro.CastCommands(), string(cast))
+	forge, err := os.ReadFile(name + ".s.sol")
+	require.NoError(t, err)
+	require.Equal(t, repro.ForgeScript(), string(forge))
+}
+
+func TestIsRevert(t *testing.T) {
+	require.True(t, isRevert(errors.New("execution reverted: EDGE_NOT_CONFIRMED")))
+	require.False(t, isRevert(errors.New("connection refused")))
+}
@@ -0,0 +1,134 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+// ErrRollupPaused is returned for a transaction which reverted because the rollup is paused, such
+// as for an upgrade, when waiting for it to be unpaused is disabled or given up on.
+var ErrRollupPaused = errors.New("the rollup is paused")
+
+var (
+	rollupPausedGauge      = metrics.NewRegisteredGauge("arb/validator/rollup/paused", nil)
+	txsWaitingUnpauseGauge = metrics.NewRegisteredGauge("arb/validator/rollup/txs_waiting_unpause", nil)
+)
+
+// The reason OpenZeppelin's Pausable reverts with, which the rollup's whenNotPaused functions use.
+const pausedRevertReason = "Pausable: paused"
+
+const defaultPauseCheckInterval = 30 * time.Second
+
+// The transactions waiting for the rollup to be unpaused, so that the pause is alerted on once
+// rather than by each of them.
+type pauseWaiters struct {
+	lock    sync.Mutex
+	waiting int
+	since   time.Time
+}
+
+// WithPauseCheckInterval sets how often the rollup is checked for being unpaused while transactions
+// which reverted because it is paused wait to be sent again. Defaults to 30 seconds, and zero
+// disables waiting, failing such transactions with ErrRollupPaused instead.
+func WithPauseCheckInterval(interval time.Duration) Opt {
+	return func(a *AssertionChain) {
+		a.pauseCheckInterval = interval
+	}
+}
+
+// IsPaused checks if the rollup is paused as of the latest block, which transactions are
+// estimated against.
+func (a *AssertionChain) IsPaused(ctx context.Context) (bool, error) {
+	return a.rollup.Paused(&bind.CallOpts{Context: ctx})
+}
+
+func isPausedRevert(err error) bool {
+	return err != nil && strings.Contains(err.Error(), pausedRevertReason)
+}
+
+// Checks if a transaction reverted because the rollup is paused, either by the reason of its revert,
+// or, for transactions to the rollup which revert without one, by probing if the rollup is paused.
+// If so, waits for the rollup to be unpaused and returns true, so that the transaction is sent
+// again rather than failed and the intent behind it is preserved.
+func (a *AssertionChain) waitIfPaused(ctx context.Context, to common.Address, revertErr error) (bool, error) {
+	if !isPausedRevert(revertErr) {
+		if to != a.rollupAddr {
+			return false, nil
+		}
+		paused, err := a.IsPaused(ctx)
+		if err != nil || !paused {
+			return false, nil
+		}
+	}
+	if a.pauseCheckInterval == 0 {
+		return false, errors.Wrapf(ErrRollupPaused, "transaction to %#x reverted: %v", to, revertErr)
+	}
+	a.eventBus.PublishTx(eventbus.TxEvent{Kind: eventbus.TxWaitingUnpause, To: to, Err: revertErr})
+	if err := a.waitUntilUnpaused(ctx, to); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Waits for the rollup to be unpaused, alerting once when the first transaction starts waiting, and
+// once when the rollup is found to be unpaused.
+func (a *AssertionChain) waitUntilUnpaused(ctx context.Context, to common.Address) error {
+	a.pause.lock.Lock()
+	if a.pause.waiting == 0 {
+		a.pause.since = time.Now()
+		rollupPausedGauge.Update(1)
+		log.Error("Rollup is paused, waiting for it to be unpaused before sending transactions again", "rollup", a.rollupAddr)
+	}
+	a.pause.waiting++
+	txsWaitingUnpauseGauge.Update(int64(a.pause.waiting))
+	a.pause.lock.Unlock()
+	defer func() {
+		a.pause.lock.Lock()
+		defer a.pause.lock.Unlock()
+		a.pause.waiting--
+		txsWaitingUnpauseGauge.Update(int64(a.pause.waiting))
+		if a.pause.waiting == 0 {
+			rollupPausedGauge.Update(0)
+		}
+	}()
+
+	ticker := time.NewTicker(a.pauseCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ErrRollupPaused, "gave up waiting to send transaction to %#x: %v", to, ctx.Err())
+		case <-ticker.C:
+		}
+		paused, err := a.IsPaused(ctx)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ErrRollupPaused, "gave up waiting to send transaction to %#x: %v", to, ctx.Err())
+		}
+		if err != nil {
+			log.Warn("Could not check if the rollup is paused", "err", err)
+			continue
+		}
+		if paused {
+			continue
+		}
+		a.pause.lock.Lock()
+		if !a.pause.since.IsZero() {
+			log.Info("Rollup was unpaused, sending transactions again", "rollup", a.rollupAddr, "pausedFor", time.Since(a.pause.since))
+			a.pause.since = time.Time{}
+		}
+		a.pause.lock.Unlock()
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/solgen/go/mocksgen"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/require"
+)
+
+func setRollupPaused(t *testing.T, cfg *setup.ChainSetup, paused bool) {
+	execBindings, err := mocksgen.NewUpgradeExecutorMock(cfg.Addrs.UpgradeExecutor, cfg.Backend)
+	require.NoError(t, err)
+	adminAbi, err := abi.JSON(strings.NewReader(rollupgen.RollupAdminLogicABI))
+	require.NoError(t, err)
+	method := "resume"
+	if paused {
+		method = "pause"
+	}
+	data, err := adminAbi.Pack(method)
+	require.NoError(t, err)
+	_, err = execBindings.ExecuteCall(cfg.Accounts[0].TxOpts, cfg.Addrs.Rollup, data)
+	require.NoError(t, err)
+	cfg.Backend.Commit()
+}
+
+func TestRollupPause(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := setup.ChainsWithEdgeChallengeManager()
+	require.NoError(t, err)
+	challengeManager, err := cfg.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	newChain := func(opts ...solimpl.Opt) *solimpl.AssertionChain {
+		chain, err := solimpl.NewAssertionChain(
+			ctx,
+			cfg.Addrs.Rollup,
+			challengeManager.Address(),
+			cfg.Accounts[1].TxOpts,
+			cfg.Backend,
+			solimpl.NewChainBackendTransactor(cfg.Backend),
+			opts...,
+		)
+		require.NoError(t, err)
+		return chain
+	}
+	genesisHash, err := cfg.Chains[0].GenesisAssertionHash(ctx)
+	require.NoError(t, err)
+	genesisInfo, err := cfg.Chains[0].ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: genesisHash})
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		cfg.Backend.Commit()
+	}
+	postState := &protocol.ExecutionState{
+		GlobalState: protocol.GoGlobalState{
+			BlockHash: cfg.Backend.Commit(),
+			Batch:     1,
+		},
+		MachineStatus: protocol.MachineStatusFinished,
+	}
+
+	setRollupPaused(t, cfg, true)
+	paused, err := cfg.Chains[0].IsPaused(ctx)
+	require.NoError(t, err)
+	require.True(t, paused)
+
+	t.Run("fails without waiting", func(t *testing.T) {
+		chain := newChain(solimpl.WithPauseCheckInterval(0))
+		_, err := chain.NewStakeOnNewAssertion(ctx, genesisInfo, postState)
+		require.ErrorIs(t, err, solimpl.ErrRollupPaused)
+	})
+	t.Run("gives up waiting", func(t *testing.T) {
+		chain := newChain(solimpl.WithPauseCheckInterval(time.Millisecond))
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := chain.NewStakeOnNewAssertion(timeoutCtx, genesisInfo, postState)
+		require.ErrorIs(t, err, solimpl.ErrRollupPaused)
+	})
+	t.Run("resumes once unpaused", func(t *testing.T) {
+		bus := eventbus.New()
+		txs := bus.Txs.Subscribe("test")
+		defer txs.Unsubscribe()
+		chain := newChain(solimpl.WithPauseCheckInterval(time.Millisecond), solimpl.WithEventBus(bus))
+		type result struct {
+			assertion protocol.Assertion
+			err       error
+		}
+		done := make(chan result, 1)
+		go func() {
+			assertion, err := chain.NewStakeOnNewAssertion(ctx, genesisInfo, postState)
+			done <- result{assertion, err}
+		}()
+
+		select {
+		case ev := <-txs.Events():
+			require.Equal(t, eventbus.TxWaitingUnpause, ev.Kind)
+			require.Equal(t, cfg.Addrs.Rollup, ev.To)
+		case <-time.After(10 * time.Second):
+			t.Fatal("transaction did not wait for the rollup to be unpaused")
+		}
+		select {
+		case res := <-done:
+			t.Fatalf("transaction finished while the rollup is paused: %v", res.err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		setRollupPaused(t, cfg, false)
+		select {
+		case res := <-done:
+			require.NoError(t, res.err)
+			require.NotNil(t, res.assertion)
+		case <-time.After(10 * time.Second):
+			t.Fatal("transaction was not sent again once the rollup was unpaused")
+		}
+	})
+}
@@ -0,0 +1,416 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+// BurnRateAlert fires when the error budget of an SLO is being spent at least Threshold times
+// faster than it can be sustained over both windows. The long window keeps short blips from
+// firing the alert, and the short window resolves it soon after the provider recovers.
+type BurnRateAlert struct {
+	Name        string
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+	Threshold   float64
+}
+
+// RpcSLO is the service level objective of the requests made to an endpoint, checked separately
+// for each JSON-RPC method. A request is good if it succeeds within LatencyThreshold, and the
+// objective is the fraction of requests which should be good, such as 0.99. The error budget is
+// the remaining fraction, and its burn rate is the fraction of bad requests over a window divided
+// by the error budget, so a burn rate of 1 spends the budget exactly as fast as the objective
+// allows.
+type RpcSLO struct {
+	Objective float64
+	// Zero counts every request which succeeds as good, however slow.
+	LatencyThreshold time.Duration
+	// Alerts only fire once the short window of the alert has at least this many requests, so
+	// that a single failure of a rarely requested method does not fire them.
+	MinRequests uint64
+	Alerts      []BurnRateAlert
+}
+
+// DefaultRpcSLO expects 99% of requests to succeed within 5 seconds, and alerts on the fast and
+// slow burn rates commonly used to page for a 30 day error budget.
+func DefaultRpcSLO() RpcSLO {
+	return RpcSLO{
+		Objective:        0.99,
+		LatencyThreshold: 5 * time.Second,
+		MinRequests:      10,
+		Alerts: []BurnRateAlert{
+			{Name: "fast_burn", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, Threshold: 14.4},
+			{Name: "slow_burn", LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, Threshold: 6},
+		},
+	}
+}
+
+// Validate checks the objective is a fraction and each alert has a short window within its long
+// window.
+func (s RpcSLO) Validate() error {
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("objective must be in (0, 1), got %v", s.Objective)
+	}
+	if s.LatencyThreshold < 0 {
+		return fmt.Errorf("latency threshold cannot be negative, got %v", s.LatencyThreshold)
+	}
+	names := make(map[string]bool, len(s.Alerts))
+	for _, alert := range s.Alerts {
+		if alert.Name == "" || names[alert.Name] {
+			return fmt.Errorf("alert names must be unique and non-empty, got %q", alert.Name)
+		}
+		names[alert.Name] = true
+		if alert.ShortWindow <= 0 || alert.LongWindow < alert.ShortWindow {
+			return fmt.Errorf("alert %s must have a positive short window within its long window", alert.Name)
+		}
+		if alert.Threshold <= 0 {
+			return fmt.Errorf("alert %s must have a positive threshold, got %v", alert.Name, alert.Threshold)
+		}
+	}
+	return nil
+}
+
+// FiringRpcAlert is a burn rate alert firing for a method of an endpoint.
+type FiringRpcAlert struct {
+	Endpoint      string
+	Method        string
+	Alert         string
+	LongBurnRate  float64
+	ShortBurnRate float64
+	Since         time.Time
+}
+
+// RpcMetricsBackend records the latency and errors of each JSON-RPC method requested through the
+// wrapped backend, labelled by endpoint, and checks them against an SLO. Alerts are logged when
+// they fire and resolve, and exported as gauges, so that a degrading provider is noticed and
+// replaced before it delays our moves past a challenge deadline.
+//
+// Requests cancelled by their caller are not counted against the provider. The backend should
+// wrap the client of the endpoint directly, beneath wrappers such as rate limiting, so that
+// latencies measure the provider alone.
+type RpcMetricsBackend struct {
+	protocol.ChainBackend
+	endpoint    string
+	slo         RpcSLO
+	bucketWidth time.Duration
+	numBuckets  int
+	now         func() time.Time
+	lock        sync.Mutex
+	methods     map[string]*rpcMethodStats
+}
+
+// NewRpcMetricsBackend measures the requests to an endpoint, given a name for it in metrics and
+// logs. The name should not be the endpoint's URL, which often embeds an API key.
+func NewRpcMetricsBackend(backend protocol.ChainBackend, endpoint string, slo RpcSLO) (*RpcMetricsBackend, error) {
+	if err := slo.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid rpc slo")
+	}
+	// Buckets are a fifth of the shortest window, so that windows are measured within a fifth of
+	// their length, and cover the longest window.
+	var shortest, longest time.Duration
+	for _, alert := range slo.Alerts {
+		if shortest == 0 || alert.ShortWindow < shortest {
+			shortest = alert.ShortWindow
+		}
+		longest = max(longest, alert.LongWindow)
+	}
+	bucketWidth := max(shortest/5, time.Second)
+	return &RpcMetricsBackend{
+		ChainBackend: backend,
+		endpoint:     metricName(endpoint),
+		slo:          slo,
+		bucketWidth:  bucketWidth,
+		numBuckets:   int(longest/bucketWidth) + 1,
+		now:          time.Now,
+		methods:      make(map[string]*rpcMethodStats),
+	}, nil
+}
+
+// metricName replaces the characters of a name which separate the parts of metric names.
+func metricName(name string) string {
+	return strings.NewReplacer("/", "_", ".", "_", ":", "_", " ", "_").Replace(name)
+}
+
+type rpcBucket struct {
+	start time.Time
+	total uint64
+	bad   uint64
+}
+
+type rpcMethodStats struct {
+	latency  metrics.Timer
+	requests metrics.Counter
+	errors   metrics.Counter
+	slow     metrics.Counter
+	buckets  []rpcBucket
+	firing   map[string]time.Time
+	gauges   map[string]metrics.Gauge
+}
+
+func (b *RpcMetricsBackend) stats(method string) *rpcMethodStats {
+	s, ok := b.methods[method]
+	if ok {
+		return s
+	}
+	prefix := "arb/validator/rpc/" + b.endpoint + "/" + method
+	s = &rpcMethodStats{
+		latency:  metrics.GetOrRegisterTimer(prefix+"/latency", nil),
+		requests: metrics.GetOrRegisterCounter(prefix+"/requests", nil),
+		errors:   metrics.GetOrRegisterCounter(prefix+"/errors", nil),
+		slow:     metrics.GetOrRegisterCounter(prefix+"/slow_requests", nil),
+		buckets:  make([]rpcBucket, b.numBuckets),
+		firing:   make(map[string]time.Time),
+		gauges:   make(map[string]metrics.Gauge, len(b.slo.Alerts)),
+	}
+	for _, alert := range b.slo.Alerts {
+		s.gauges[alert.Name] = metrics.GetOrRegisterGauge(prefix+"/alerts/"+alert.Name, nil)
+	}
+	b.methods[method] = s
+	return s
+}
+
+func (b *RpcMetricsBackend) record(ctx context.Context, method string, start time.Time, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	now := b.now()
+	elapsed := now.Sub(start)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s := b.stats(method)
+	s.latency.Update(elapsed)
+	s.requests.Inc(1)
+	bad := err != nil
+	if err != nil {
+		s.errors.Inc(1)
+	} else if b.slo.LatencyThreshold > 0 && elapsed > b.slo.LatencyThreshold {
+		s.slow.Inc(1)
+		bad = true
+	}
+	bucketStart := now.Truncate(b.bucketWidth)
+	bucket := &s.buckets[int(bucketStart.UnixNano()/int64(b.bucketWidth))%b.numBuckets]
+	if !bucket.start.Equal(bucketStart) {
+		*bucket = rpcBucket{start: bucketStart}
+	}
+	bucket.total++
+	if bad {
+		bucket.bad++
+	}
+	b.checkAlerts(method, s, now)
+}
+
+// burnRate over the window ending now, and the number of requests it is measured from. A window
+// without requests burns nothing.
+func (b *RpcMetricsBackend) burnRate(s *rpcMethodStats, now time.Time, window time.Duration) (float64, uint64) {
+	var total, bad uint64
+	from := now.Add(-window)
+	for _, bucket := range s.buckets {
+		if bucket.total == 0 || !bucket.start.Add(b.bucketWidth).After(from) || bucket.start.After(now) {
+			continue
+		}
+		total += bucket.total
+		bad += bucket.bad
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(bad) / float64(total) / (1 - b.slo.Objective), total
+}
+
+func (b *RpcMetricsBackend) checkAlerts(method string, s *rpcMethodStats, now time.Time) {
+	for _, alert := range b.slo.Alerts {
+		long, _ := b.burnRate(s, now, alert.LongWindow)
+		short, requests := b.burnRate(s, now, alert.ShortWindow)
+		since, firing := s.firing[alert.Name]
+		switch {
+		case long >= alert.Threshold && short >= alert.Threshold && requests >= b.slo.MinRequests && !firing:
+			s.firing[alert.Name] = now
+			s.gauges[alert.Name].Update(1)
+			log.Error("RPC method is burning through its error budget",
+				"endpoint", b.endpoint,
+				"method", method,
+				"alert", alert.Name,
+				"longBurnRate", long,
+				"shortBurnRate", short,
+				"threshold", alert.Threshold,
+			)
+		case firing && (long < alert.Threshold || short < alert.Threshold):
+			delete(s.firing, alert.Name)
+			s.gauges[alert.Name].Update(0)
+			log.Info("RPC method burn rate alert resolved",
+				"endpoint", b.endpoint,
+				"method", method,
+				"alert", alert.Name,
+				"firingFor", now.Sub(since),
+			)
+		}
+	}
+}
+
+// BurnRate returns the current burn rate of the error budget of a method over a window.
+func (b *RpcMetricsBackend) BurnRate(method string, window time.Duration) float64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s, ok := b.methods[method]
+	if !ok {
+		return 0
+	}
+	rate, _ := b.burnRate(s, b.now(), window)
+	return rate
+}
+
+// FiringAlerts lists the alerts currently firing, as of the latest request of each method.
+func (b *RpcMetricsBackend) FiringAlerts() []FiringRpcAlert {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	var firing []FiringRpcAlert
+	for method, s := range b.methods {
+		for _, alert := range b.slo.Alerts {
+			since, ok := s.firing[alert.Name]
+			if !ok {
+				continue
+			}
+			long, _ := b.burnRate(s, now, alert.LongWindow)
+			short, _ := b.burnRate(s, now, alert.ShortWindow)
+			firing = append(firing, FiringRpcAlert{
+				Endpoint:      b.endpoint,
+				Method:        method,
+				Alert:         alert.Name,
+				LongBurnRate:  long,
+				ShortBurnRate: short,
+				Since:         since,
+			})
+		}
+	}
+	return firing
+}
+
+func (b *RpcMetricsBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	start := b.now()
+	res, err := b.ChainBackend.CallContract(ctx, call, blockNumber)
+	b.record(ctx, "eth_call", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	start := b.now()
+	res, err := b.ChainBackend.CodeAt(ctx, contract, blockNumber)
+	b.record(ctx, "eth_getCode", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	start := b.now()
+	res, err := StorageAt(ctx, b.ChainBackend, account, key, blockNumber)
+	b.record(ctx, "eth_getStorageAt", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	start := b.now()
+	res, err := b.ChainBackend.HeaderByNumber(ctx, number)
+	b.record(ctx, "eth_getBlockByNumber", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	start := b.now()
+	res, err := b.ChainBackend.PendingCodeAt(ctx, account)
+	b.record(ctx, "eth_getCode", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	start := b.now()
+	res, err := b.ChainBackend.PendingNonceAt(ctx, account)
+	b.record(ctx, "eth_getTransactionCount", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	start := b.now()
+	res, err := b.ChainBackend.SuggestGasPrice(ctx)
+	b.record(ctx, "eth_gasPrice", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	start := b.now()
+	res, err := b.ChainBackend.SuggestGasTipCap(ctx)
+	b.record(ctx, "eth_maxPriorityFeePerGas", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	start := b.now()
+	res, err := b.ChainBackend.EstimateGas(ctx, call)
+	b.record(ctx, "eth_estimateGas", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	start := b.now()
+	err := b.ChainBackend.SendTransaction(ctx, tx)
+	b.record(ctx, "eth_sendRawTransaction", start, err)
+	return err
+}
+
+func (b *RpcMetricsBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	start := b.now()
+	res, err := b.ChainBackend.FilterLogs(ctx, query)
+	b.record(ctx, "eth_getLogs", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	start := b.now()
+	res, err := b.ChainBackend.SubscribeFilterLogs(ctx, query, ch)
+	b.record(ctx, "eth_subscribe", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	start := b.now()
+	res, err := b.ChainBackend.SubscribeNewHead(ctx, ch)
+	b.record(ctx, "eth_subscribe", start, err)
+	return res, err
+}
+
+func (b *RpcMetricsBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	start := b.now()
+	res, err := b.ChainBackend.TransactionReceipt(ctx, txHash)
+	// A receipt not found yet is the expected answer while waiting for a transaction to be mined.
+	if errors.Is(err, ethereum.NotFound) {
+		b.record(ctx, "eth_getTransactionReceipt", start, nil)
+	} else {
+		b.record(ctx, "eth_getTransactionReceipt", start, err)
+	}
+	return res, err
+}
+
+func (b *RpcMetricsBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	start := b.now()
+	res, pending, err := b.ChainBackend.TransactionByHash(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		b.record(ctx, "eth_getTransactionByHash", start, nil)
+	} else {
+		b.record(ctx, "eth_getTransactionByHash", start, err)
+	}
+	return res, pending, err
+}
@@ -0,0 +1,113 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type failingHeaderBackend struct {
+	MockContractBackend
+	fail bool
+}
+
+func (b *failingHeaderBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if b.fail {
+		return nil, errors.New("provider unavailable")
+	}
+	return &types.Header{}, nil
+}
+
+func TestRpcMetricsBackend_BurnRateAlerts(t *testing.T) {
+	ctx := context.Background()
+	inner := &failingHeaderBackend{}
+	slo := RpcSLO{
+		Objective: 0.9,
+		Alerts: []BurnRateAlert{
+			{Name: "fast_burn", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, Threshold: 5},
+		},
+	}
+	backend, err := NewRpcMetricsBackend(inner, "test.endpoint", slo)
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	backend.now = func() time.Time { return now }
+
+	// An hour of healthy requests, one a minute.
+	for i := 0; i < 60; i++ {
+		_, err = backend.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+		now = now.Add(time.Minute)
+	}
+	require.Zero(t, backend.BurnRate("eth_getBlockByNumber", time.Hour))
+	require.Empty(t, backend.FiringAlerts())
+
+	// Every request fails for a while. The short window burns fast at once, but the alert only
+	// fires once the long window also burns past the threshold, at half of the hour failing.
+	inner.fail = true
+	for i := 0; i < 20; i++ {
+		_, err = backend.HeaderByNumber(ctx, nil)
+		require.Error(t, err)
+		now = now.Add(time.Minute)
+	}
+	require.InDelta(t, 10, backend.BurnRate("eth_getBlockByNumber", 5*time.Minute), 0.01)
+	require.Empty(t, backend.FiringAlerts())
+	for i := 0; i < 15; i++ {
+		_, err = backend.HeaderByNumber(ctx, nil)
+		require.Error(t, err)
+		now = now.Add(time.Minute)
+	}
+	firing := backend.FiringAlerts()
+	require.Len(t, firing, 1)
+	require.Equal(t, "test_endpoint", firing[0].Endpoint)
+	require.Equal(t, "eth_getBlockByNumber", firing[0].Method)
+	require.Equal(t, "fast_burn", firing[0].Alert)
+	require.GreaterOrEqual(t, firing[0].LongBurnRate, 5.0)
+
+	// The alert resolves once the short window recovers, although the long one still burns.
+	inner.fail = false
+	for i := 0; i < 6; i++ {
+		_, err = backend.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+		now = now.Add(time.Minute)
+	}
+	require.Greater(t, backend.BurnRate("eth_getBlockByNumber", time.Hour), 5.0)
+	require.Empty(t, backend.FiringAlerts())
+}
+
+func TestRpcMetricsBackend_IgnoresCancelledRequests(t *testing.T) {
+	inner := &failingHeaderBackend{fail: true}
+	backend, err := NewRpcMetricsBackend(inner, "test", DefaultRpcSLO())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = backend.HeaderByNumber(ctx, nil)
+	require.Error(t, err)
+	require.Zero(t, backend.BurnRate("eth_getBlockByNumber", time.Hour))
+
+	_, err = backend.HeaderByNumber(context.Background(), nil)
+	require.Error(t, err)
+	require.InDelta(t, 100, backend.BurnRate("eth_getBlockByNumber", time.Hour), 0.01)
+	// Too few requests to fire an alert.
+	require.Empty(t, backend.FiringAlerts())
+}
+
+func TestRpcSLO_Validate(t *testing.T) {
+	require.NoError(t, DefaultRpcSLO().Validate())
+	slo := DefaultRpcSLO()
+	slo.Objective = 1
+	require.ErrorContains(t, slo.Validate(), "objective")
+	slo = DefaultRpcSLO()
+	slo.Alerts[0].ShortWindow = 2 * slo.Alerts[0].LongWindow
+	require.ErrorContains(t, slo.Validate(), "short window")
+	slo = DefaultRpcSLO()
+	slo.Alerts[1].Name = slo.Alerts[0].Name
+	require.ErrorContains(t, slo.Validate(), "unique")
+}
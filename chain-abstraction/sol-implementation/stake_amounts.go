@@ -0,0 +1,183 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// Errors returned when the staker cannot cover the stake of a layer zero edge, which the challenge
+// manager would otherwise fail to transfer from it with an opaque revert.
+var (
+	ErrInsufficientStakeBalance   = errors.New("insufficient stake token balance")
+	ErrInsufficientStakeAllowance = errors.New("insufficient stake token allowance")
+)
+
+// Challenge managers which require the same stake at every level only have a single stakeAmount
+// getter, rather than the stakeAmounts getter indexed by level of our bindings.
+var legacyStakeAmountAbi = mustParseAbi(`[{"inputs":[],"name":"stakeAmount","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`)
+
+// The subset of the ERC20 interface needed to manage the stake token allowance of the challenge manager.
+var erc20Abi = mustParseAbi(`[
+	{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"address","name":"spender","type":"address"}],"name":"allowance","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}],"name":"approve","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}
+]`)
+
+func mustParseAbi(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// StakeAmount is the amount of stake required to create a layer zero edge at a challenge level.
+// The per level getter of the challenge manager is probed first, falling back to the single
+// stake amount of challenge managers which require the same stake at every level.
+func (cm *specChallengeManager) StakeAmount(ctx context.Context, level protocol.ChallengeLevel) (*big.Int, error) {
+	amount, err := cm.immutable(ctx, fmt.Sprintf("stake amount %d", level), func(opts *bind.CallOpts) (*big.Int, error) {
+		perLevel, err := cm.caller.StakeAmounts(opts, big.NewInt(int64(level)))
+		if err == nil {
+			return perLevel, nil
+		}
+		single, legacyErr := cm.legacyStakeAmount(opts)
+		if legacyErr != nil {
+			return nil, err
+		}
+		return single, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Callers may modify the amount, which must not change the cached value.
+	return new(big.Int).Set(amount), nil
+}
+
+func (cm *specChallengeManager) legacyStakeAmount(opts *bind.CallOpts) (*big.Int, error) {
+	contract := bind.NewBoundContract(cm.addr, legacyStakeAmountAbi, cm.backend, nil, nil)
+	return callUint256(contract, opts, "stakeAmount")
+}
+
+// StakeAmounts are the amounts of stake required to create a layer zero edge at each challenge
+// level, indexed by level.
+func (cm *specChallengeManager) StakeAmounts(ctx context.Context) ([]*big.Int, error) {
+	numBigSteps, err := cm.NumBigSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The block level, the big step levels and the small step level.
+	amounts := make([]*big.Int, int(numBigSteps)+2)
+	for level := range amounts {
+		amounts[level], err = cm.StakeAmount(ctx, protocol.ChallengeLevel(level))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get stake amount of level %d", level)
+		}
+	}
+	return amounts, nil
+}
+
+// NoStakeMode is whether the challenge manager was deployed without stakes, as on devnets. The
+// challenge manager only transfers stakes when both its stake token and the stake amount of a level
+// are set, so layer zero edges lock no tokens if the stake token is the zero address or every stake
+// amount is zero.
+func (cm *specChallengeManager) NoStakeMode(ctx context.Context) (bool, error) {
+	stakeToken, err := cm.StakeToken(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "could not get stake token")
+	}
+	if stakeToken == (common.Address{}) {
+		return true, nil
+	}
+	amounts, err := cm.StakeAmounts(ctx)
+	if err != nil {
+		return false, err
+	}
+	return isNoStakeConfig(stakeToken, amounts), nil
+}
+
+func isNoStakeConfig(stakeToken common.Address, amounts []*big.Int) bool {
+	if stakeToken == (common.Address{}) {
+		return true
+	}
+	for _, amount := range amounts {
+		if amount.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks that the staker can cover the stake of a layer zero edge at a level. If the chain manages
+// the stake allowance and the challenge manager may not transfer enough of the staker's tokens, it
+// is approved to transfer the stake of the rest of the challenge from the level down, so that an
+// allowance is not needed for every layer zero edge.
+// Without stakes, there is no token to check the balance and allowance of.
+func (cm *specChallengeManager) checkStakeAllowance(ctx context.Context, level protocol.ChallengeLevel) error {
+	amounts, err := cm.StakeAmounts(ctx)
+	if err != nil {
+		return err
+	}
+	if int(level.Uint8()) >= len(amounts) {
+		return errors.Wrapf(ErrLayerZeroInvalidLevel, "level %d", level)
+	}
+	stakeToken, err := cm.StakeToken(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get stake token")
+	}
+	if isNoStakeConfig(stakeToken, amounts) {
+		return nil
+	}
+	amount := amounts[level.Uint8()]
+	if amount.Sign() == 0 {
+		return nil
+	}
+	token := bind.NewBoundContract(stakeToken, erc20Abi, cm.backend, cm.backend, nil)
+	opts := cm.assertionChain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx})
+	staker := cm.assertionChain.txOpts.From
+	balance, err := callUint256(token, opts, "balanceOf", staker)
+	if err != nil {
+		return errors.Wrapf(err, "could not get stake token balance of %#x", staker)
+	}
+	if balance.Cmp(amount) < 0 {
+		return errors.Wrapf(ErrInsufficientStakeBalance, "%#x has %s, need %s for level %d", staker, balance, amount, level)
+	}
+	allowance, err := callUint256(token, opts, "allowance", staker, cm.addr)
+	if err != nil {
+		return errors.Wrapf(err, "could not get stake token allowance of %#x", staker)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil
+	}
+	if !cm.assertionChain.manageStakeAllowance {
+		return errors.Wrapf(ErrInsufficientStakeAllowance, "%#x allows %s, need %s for level %d", staker, allowance, amount, level)
+	}
+	cost := protocol.ChallengeStakeCost(amounts, level)
+	log.Info("Approving challenge manager to transfer stake", "staker", staker, "level", level, "amount", cost)
+	if _, err = cm.transact(ctx, cm.backend, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return token.Transact(opts, "approve", cm.addr, cost)
+	}); err != nil {
+		return errors.Wrap(err, "could not approve stake token allowance")
+	}
+	return nil
+}
+
+func callUint256(contract *bind.BoundContract, opts *bind.CallOpts, method string, args ...any) (*big.Int, error) {
+	var out []any
+	if err := contract.Call(opts, &out, method, args...); err != nil {
+		return nil, err
+	}
+	return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNoStakeConfig(t *testing.T) {
+	token := common.HexToAddress("0x1234")
+	zero := []*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+	staked := []*big.Int{big.NewInt(0), big.NewInt(2), big.NewInt(0)}
+	for _, tt := range []struct {
+		name    string
+		token   common.Address
+		amounts []*big.Int
+		want    bool
+	}{
+		{name: "staked", token: token, amounts: staked, want: false},
+		{name: "zero stake token", token: common.Address{}, amounts: staked, want: true},
+		{name: "zero stake amounts", token: token, amounts: zero, want: true},
+		{name: "no levels", token: token, want: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, isNoStakeConfig(tt.token, tt.amounts))
+		})
+	}
+}
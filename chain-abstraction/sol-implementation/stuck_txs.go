@@ -0,0 +1,390 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+// ErrTxEscalated is returned when sending a transaction which is waiting in the manual
+// intervention queue, until an operator resolves it.
+var ErrTxEscalated = errors.New("transaction is stuck and escalated for manual intervention")
+
+var (
+	stuckTxsGauge      = metrics.NewRegisteredGauge("arb/validator/tx/stuck", nil)
+	escalatedTxCounter = metrics.NewRegisteredCounter("arb/validator/tx/escalated", nil)
+)
+
+const defaultMaxPendingTxAge = 30 * time.Minute
+
+// The contracts whose methods the intent of a stuck transaction is decoded with.
+var intentAbis = func() []*abi.ABI {
+	abis := []*abi.ABI{&erc20Abi}
+	for _, metadata := range []interface{ GetAbi() (*abi.ABI, error) }{
+		rollupgen.RollupUserLogicMetaData,
+		challengeV2gen.EdgeChallengeManagerMetaData,
+	} {
+		parsed, err := metadata.GetAbi()
+		if err != nil {
+			panic(err)
+		}
+		abis = append(abis, parsed)
+	}
+	return abis
+}()
+
+// StuckTx is a transaction which stayed unconfirmed for longer than the assertion chain keeps
+// sending it for. It waits in the manual intervention queue, and sending it again fails with
+// ErrTxEscalated, until an operator unblocks it and resolves it.
+type StuckTx struct {
+	// The latest transaction sent with the intent.
+	TxHash    common.Hash
+	Nonce     uint64
+	From      common.Address
+	To        common.Address
+	Data      []byte
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+	// The method called and its arguments by name, decoded from the calldata.
+	Method string
+	Args   map[string]string
+	// When a transaction with the intent was first sent, and how many were sent.
+	FirstSentAt    time.Time
+	Attempts       int
+	EscalatedAt    time.Time
+	SuggestedFixes []string
+}
+
+// Identifies the transactions sent for the same purpose, as each attempt is a new transaction.
+type txIntentKey struct {
+	to       common.Address
+	dataHash common.Hash
+}
+
+func intentKeyOf(tx *types.Transaction) txIntentKey {
+	var key txIntentKey
+	if tx.To() != nil {
+		key.to = *tx.To()
+	}
+	key.dataHash = crypto.Keccak256Hash(tx.Data())
+	return key
+}
+
+type pendingIntent struct {
+	firstSentAt time.Time
+	lastSentAt  time.Time
+	attempts    int
+}
+
+// Tracks the age of the intents of unconfirmed transactions, and holds those escalated for manual
+// intervention.
+type stuckTxQueue struct {
+	lock    sync.Mutex
+	maxAge  time.Duration
+	pending map[txIntentKey]*pendingIntent
+	stuck   map[txIntentKey]*StuckTx
+}
+
+func newStuckTxQueue(maxAge time.Duration) *stuckTxQueue {
+	return &stuckTxQueue{
+		maxAge:  maxAge,
+		pending: make(map[txIntentKey]*pendingIntent),
+		stuck:   make(map[txIntentKey]*StuckTx),
+	}
+}
+
+func (q *stuckTxQueue) escalated(key txIntentKey) (*StuckTx, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	stuck, ok := q.stuck[key]
+	return stuck, ok
+}
+
+// Records an attempt at sending a transaction with an intent. Intents which were not sent again
+// within the maximum age were given up on, so they expire rather than being escalated the next time
+// a transaction with the same intent is sent.
+func (q *stuckTxQueue) sent(key txIntentKey, now time.Time) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.expire(now)
+	intent, ok := q.pending[key]
+	if !ok {
+		intent = &pendingIntent{firstSentAt: now}
+		q.pending[key] = intent
+	}
+	intent.lastSentAt = now
+	intent.attempts++
+}
+
+func (q *stuckTxQueue) expire(now time.Time) {
+	if q.maxAge <= 0 {
+		return
+	}
+	for key, intent := range q.pending {
+		if now.Sub(intent.lastSentAt) >= q.maxAge {
+			delete(q.pending, key)
+		}
+	}
+}
+
+// Forgets the age of an intent once one of its transactions is confirmed, or when waiting for it
+// to be mined failed for another reason than taking too long, as it may not be sent again.
+func (q *stuckTxQueue) forget(key txIntentKey) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	delete(q.pending, key)
+}
+
+// Returns the pending intent if it is older than the maximum age, and so should be escalated.
+func (q *stuckTxQueue) overdue(key txIntentKey, now time.Time) (pendingIntent, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	intent, ok := q.pending[key]
+	if !ok || q.maxAge <= 0 || now.Sub(intent.firstSentAt) < q.maxAge {
+		return pendingIntent{}, false
+	}
+	return *intent, true
+}
+
+func (q *stuckTxQueue) escalate(key txIntentKey, stuck *StuckTx) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	delete(q.pending, key)
+	q.stuck[key] = stuck
+	stuckTxsGauge.Update(int64(len(q.stuck)))
+	escalatedTxCounter.Inc(1)
+}
+
+func (q *stuckTxQueue) list() []*StuckTx {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	stuck := make([]*StuckTx, 0, len(q.stuck))
+	for _, tx := range q.stuck {
+		stuck = append(stuck, tx)
+	}
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].EscalatedAt.Before(stuck[j].EscalatedAt)
+	})
+	return stuck
+}
+
+func (q *stuckTxQueue) resolve(txHash common.Hash) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for key, tx := range q.stuck {
+		if tx.TxHash == txHash {
+			delete(q.stuck, key)
+			stuckTxsGauge.Update(int64(len(q.stuck)))
+			return true
+		}
+	}
+	return false
+}
+
+// StuckTxs lists the transactions in the manual intervention queue, in the order they were
+// escalated.
+func (a *AssertionChain) StuckTxs() []*StuckTx {
+	return a.stuckTxs.list()
+}
+
+// ResolveStuckTx removes a transaction from the manual intervention queue once an operator has
+// unblocked it, such as by replacing it with a higher fee, so that it may be sent again.
+func (a *AssertionChain) ResolveStuckTx(txHash common.Hash) error {
+	if !a.stuckTxs.resolve(txHash) {
+		return errors.Wrapf(ErrNotFound, "no stuck transaction with hash %#x", txHash)
+	}
+	log.Info("Resolved stuck transaction", "txHash", txHash)
+	return nil
+}
+
+// Checks whether an intent is waiting in the manual intervention queue. Its transaction may have
+// been mined since it was escalated, such as after an operator raised its fee, in which case it is
+// resolved without waiting for the operator to.
+func (a *AssertionChain) isEscalated(ctx context.Context, backend ChainBackend, key txIntentKey) bool {
+	stuck, ok := a.stuckTxs.escalated(key)
+	if !ok {
+		return false
+	}
+	if receipt, err := backend.TransactionReceipt(ctx, stuck.TxHash); err != nil || receipt == nil {
+		return true
+	}
+	if a.stuckTxs.resolve(stuck.TxHash) {
+		log.Info("Stuck transaction was mined and is resolved", "txHash", stuck.TxHash)
+	}
+	return false
+}
+
+// The reads needed to diagnose why a transaction is stuck, which not every backend supports.
+type accountReader interface {
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// What the chain knows of the account and the network when a transaction got stuck. Fields are
+// nil when they could not be read.
+type stuckTxDiagnosis struct {
+	knownToNode *bool
+	latestNonce *uint64
+	balance     *big.Int
+	baseFee     *big.Int
+}
+
+// Escalates a transaction which was not mined in time to the manual intervention queue if its
+// intent has been pending for longer than the maximum age, returning ErrTxEscalated if so.
+func (a *AssertionChain) escalateIfStuck(ctx context.Context, backend ChainBackend, tx *types.Transaction, from common.Address) error {
+	key := intentKeyOf(tx)
+	intent, ok := a.stuckTxs.overdue(key, time.Now())
+	if !ok {
+		return nil
+	}
+	method, args := decodeTxIntent(tx.Data())
+	stuck := &StuckTx{
+		TxHash:         tx.Hash(),
+		Nonce:          tx.Nonce(),
+		From:           from,
+		To:             key.to,
+		Data:           tx.Data(),
+		GasFeeCap:      tx.GasFeeCap(),
+		GasTipCap:      tx.GasTipCap(),
+		Method:         method,
+		Args:           args,
+		FirstSentAt:    intent.firstSentAt,
+		Attempts:       intent.attempts,
+		EscalatedAt:    time.Now(),
+		SuggestedFixes: suggestStuckTxFixes(tx, from, diagnoseStuckTx(ctx, backend, tx, from)),
+	}
+	a.stuckTxs.escalate(key, stuck)
+	log.Error(
+		"Transaction is stuck and was escalated for manual intervention",
+		"txHash", stuck.TxHash,
+		"nonce", stuck.Nonce,
+		"method", stuck.Method,
+		"to", stuck.To,
+		"pendingFor", time.Since(stuck.FirstSentAt),
+		"attempts", stuck.Attempts,
+		"suggestedFixes", stuck.SuggestedFixes,
+	)
+	return errors.Wrapf(ErrTxEscalated, "%s to %#x with nonce %d", method, key.to, tx.Nonce())
+}
+
+func diagnoseStuckTx(ctx context.Context, backend ChainBackend, tx *types.Transaction, from common.Address) *stuckTxDiagnosis {
+	diagnosis := &stuckTxDiagnosis{}
+	if header, err := backend.HeaderByNumber(ctx, nil); err == nil {
+		diagnosis.baseFee = header.BaseFee
+	}
+	if fetcher, ok := backend.(protocol.TxFetcher); ok {
+		_, _, err := fetcher.TransactionByHash(ctx, tx.Hash())
+		known := err == nil
+		diagnosis.knownToNode = &known
+	}
+	if reader, ok := backend.(accountReader); ok {
+		if nonce, err := reader.NonceAt(ctx, from, nil); err == nil {
+			diagnosis.latestNonce = &nonce
+		}
+		if balance, err := reader.BalanceAt(ctx, from, nil); err == nil {
+			diagnosis.balance = balance
+		}
+	}
+	return diagnosis
+}
+
+// Suggests how an operator may unblock a stuck transaction, from the most to the least likely
+// cause of it being stuck.
+func suggestStuckTxFixes(tx *types.Transaction, from common.Address, diagnosis *stuckTxDiagnosis) []string {
+	fixes := make([]string, 0)
+	if diagnosis.knownToNode != nil && !*diagnosis.knownToNode {
+		fixes = append(fixes, "The node no longer knows the transaction, which was likely dropped from its mempool: resolve it to send it again")
+	}
+	if diagnosis.latestNonce != nil && tx.Nonce() > *diagnosis.latestNonce {
+		fixes = append(fixes, fmt.Sprintf(
+			"Transactions from %s with nonces %d to %d are pending ahead of it: unblock those first",
+			from.Hex(), *diagnosis.latestNonce, tx.Nonce()-1,
+		))
+	}
+	if diagnosis.baseFee != nil && tx.GasFeeCap().Cmp(diagnosis.baseFee) < 0 {
+		fixes = append(fixes, fmt.Sprintf(
+			"Its fee cap of %s wei is below the base fee of %s wei: replace it with a transaction with nonce %d and a higher fee cap",
+			tx.GasFeeCap(), diagnosis.baseFee, tx.Nonce(),
+		))
+	}
+	if diagnosis.balance != nil && diagnosis.balance.Cmp(tx.Cost()) < 0 {
+		fixes = append(fixes, fmt.Sprintf(
+			"%s holds %s wei, less than the %s wei the transaction may cost: fund it",
+			from.Hex(), diagnosis.balance, tx.Cost(),
+		))
+	}
+	if len(fixes) == 0 {
+		fixes = append(fixes, fmt.Sprintf(
+			"Replace it with a transaction with nonce %d and a higher tip, such as a transfer of zero from %s to itself, then resolve it",
+			tx.Nonce(), from.Hex(),
+		))
+	}
+	return fixes
+}
+
+// Decodes the method called by calldata and its arguments by name, with the ABIs of the contracts
+// the assertion chain sends transactions to.
+func decodeTxIntent(data []byte) (string, map[string]string) {
+	if len(data) < 4 {
+		return "unknown", nil
+	}
+	for _, contractAbi := range intentAbis {
+		method, err := contractAbi.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return method.Name, nil
+		}
+		args := make(map[string]string, len(values))
+		for i, value := range values {
+			name := method.Inputs[i].Name
+			if name == "" {
+				name = fmt.Sprintf("arg%d", i)
+			}
+			args[name] = formatIntentArg(value)
+		}
+		return method.Name, args
+	}
+	return fmt.Sprintf("unknown selector %#x", data[:4]), nil
+}
+
+func formatIntentArg(value any) string {
+	switch v := value.(type) {
+	case [32]byte:
+		return common.Hash(v).Hex()
+	case common.Address:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case *big.Int:
+		return v.String()
+	case [][32]byte:
+		hashes := make([]common.Hash, len(v))
+		for i, h := range v {
+			hashes[i] = h
+		}
+		return fmt.Sprintf("%v", hashes)
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStuckTxQueue(t *testing.T) {
+	q := newStuckTxQueue(time.Hour)
+	key := txIntentKey{to: common.HexToAddress("0x1"), dataHash: common.HexToHash("0x2")}
+	start := time.Now()
+
+	q.sent(key, start)
+	q.sent(key, start.Add(time.Minute))
+	_, ok := q.overdue(key, start.Add(59*time.Minute))
+	require.False(t, ok)
+	intent, ok := q.overdue(key, start.Add(time.Hour))
+	require.True(t, ok)
+	require.Equal(t, start, intent.firstSentAt)
+	require.Equal(t, 2, intent.attempts)
+
+	// Confirming a transaction with the intent resets its age.
+	q.forget(key)
+	_, ok = q.overdue(key, start.Add(time.Hour))
+	require.False(t, ok)
+
+	q.sent(key, start)
+	q.escalate(key, &StuckTx{TxHash: common.HexToHash("0x3"), EscalatedAt: start})
+	_, ok = q.escalated(key)
+	require.True(t, ok)
+	require.Len(t, q.list(), 1)
+	require.False(t, q.resolve(common.HexToHash("0x4")))
+	require.True(t, q.resolve(common.HexToHash("0x3")))
+	_, ok = q.escalated(key)
+	require.False(t, ok)
+	require.Empty(t, q.list())
+
+	// An intent which is not sent again within the maximum age expires, and so does not count the
+	// time it was given up for when a transaction with it is sent later.
+	other := txIntentKey{to: common.HexToAddress("0x5")}
+	q.sent(key, start)
+	q.sent(other, start.Add(30*time.Minute))
+	q.sent(key, start.Add(time.Hour))
+	_, ok = q.overdue(key, start.Add(time.Hour+time.Minute))
+	require.False(t, ok)
+	intent, ok = q.overdue(key, start.Add(2*time.Hour))
+	require.True(t, ok)
+	require.Equal(t, start.Add(time.Hour), intent.firstSentAt)
+	require.Equal(t, 1, intent.attempts)
+	q.sent(key, start.Add(2*time.Hour))
+	require.NotContains(t, q.pending, other)
+
+	// A zero maximum age disables escalation.
+	q = newStuckTxQueue(0)
+	q.sent(key, start)
+	_, ok = q.overdue(key, start.Add(24*time.Hour))
+	require.False(t, ok)
+}
+
+// A backend which estimates gas for any call and knows the receipts of the transactions mined.
+type stuckTxBackend struct {
+	ChainBackend
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (b *stuckTxBackend) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (b *stuckTxBackend) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, ok := b.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// A transactor whose transactions are mined, or fail to be waited for, as a test decides.
+type stuckTxTransactor struct {
+	waitErr error
+}
+
+func (t *stuckTxTransactor) SendTransaction(
+	_ context.Context,
+	fn func(opts *bind.TransactOpts) (*types.Transaction, error),
+	opts *bind.TransactOpts,
+	_ uint64,
+) (*types.Transaction, error) {
+	return fn(opts)
+}
+
+func (t *stuckTxTransactor) WaitMined(_ context.Context, tx *types.Transaction) (*types.Transaction, *types.Receipt, error) {
+	if t.waitErr != nil {
+		return nil, nil, t.waitErr
+	}
+	return tx, &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(1)}, nil
+}
+
+func TestTransact_StuckTxs(t *testing.T) {
+	ctx := context.Background()
+	to := common.HexToAddress("0x1")
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 7, To: &to, Data: []byte{1, 2, 3, 4}})
+	send := func(*bind.TransactOpts) (*types.Transaction, error) {
+		return tx, nil
+	}
+	newChain := func(transactor *stuckTxTransactor) *AssertionChain {
+		return &AssertionChain{
+			txOpts:     &bind.TransactOpts{From: common.HexToAddress("0x2")},
+			transactor: transactor,
+			stuckTxs:   newStuckTxQueue(time.Hour),
+		}
+	}
+
+	t.Run("drops the intent when waiting fails before its deadline", func(t *testing.T) {
+		chain := newChain(&stuckTxTransactor{waitErr: errors.New("connection refused")})
+		backend := &stuckTxBackend{}
+		_, err := chain.transact(ctx, backend, send, withoutSafeWait())
+		require.ErrorContains(t, err, "connection refused")
+		require.Empty(t, chain.stuckTxs.pending)
+	})
+	t.Run("resolves an escalated intent once its transaction is mined", func(t *testing.T) {
+		chain := newChain(&stuckTxTransactor{})
+		backend := &stuckTxBackend{receipts: make(map[common.Hash]*types.Receipt)}
+		chain.stuckTxs.escalate(intentKeyOf(tx), &StuckTx{TxHash: tx.Hash()})
+
+		_, err := chain.transact(ctx, backend, send, withoutSafeWait())
+		require.ErrorIs(t, err, ErrTxEscalated)
+		require.Len(t, chain.StuckTxs(), 1)
+
+		backend.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+		receipt, err := chain.transact(ctx, backend, send, withoutSafeWait())
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		require.Empty(t, chain.StuckTxs())
+		require.Empty(t, chain.stuckTxs.pending)
+	})
+}
+
+func TestDecodeTxIntent(t *testing.T) {
+	spender := common.HexToAddress("0xabcd")
+	data, err := erc20Abi.Pack("approve", spender, big.NewInt(100))
+	require.NoError(t, err)
+	method, args := decodeTxIntent(data)
+	require.Equal(t, "approve", method)
+	require.Equal(t, map[string]string{"spender": spender.Hex(), "amount": "100"}, args)
+
+	method, args = decodeTxIntent([]byte{1, 2, 3, 4})
+	require.Equal(t, "unknown selector 0x01020304", method)
+	require.Nil(t, args)
+}
+
+func TestSuggestStuckTxFixes(t *testing.T) {
+	from := common.HexToAddress("0x1")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     5,
+		GasFeeCap: big.NewInt(10),
+		GasTipCap: big.NewInt(1),
+		Gas:       100,
+	})
+	known, dropped := true, false
+	latestNonce := uint64(3)
+
+	fixes := suggestStuckTxFixes(tx, from, &stuckTxDiagnosis{knownToNode: &known})
+	require.Len(t, fixes, 1)
+	require.Contains(t, fixes[0], "higher tip")
+
+	fixes = suggestStuckTxFixes(tx, from, &stuckTxDiagnosis{
+		knownToNode: &dropped,
+		latestNonce: &latestNonce,
+		baseFee:     big.NewInt(20),
+		balance:     big.NewInt(999),
+	})
+	require.Len(t, fixes, 4)
+	require.Contains(t, fixes[0], "dropped from its mempool")
+	require.Contains(t, fixes[1], "nonces 3 to 4")
+	require.Contains(t, fixes[2], "below the base fee of 20 wei")
+	require.Contains(t, fixes[3], "less than the 1000 wei")
+}
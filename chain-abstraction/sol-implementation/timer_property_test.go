@@ -0,0 +1,183 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	challengetree "github.com/OffchainLabs/bold/challenge-manager/challenge-tree"
+	"github.com/OffchainLabs/bold/containers/option"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	challenge_testing "github.com/OffchainLabs/bold/testing"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// An edge created in a random challenge, and whether it was created by the honest validator.
+type generatedEdge struct {
+	edge   protocol.SpecEdge
+	honest bool
+}
+
+// Generates random block challenges in a simulated backend, with rivals created and edges bisected
+// after random delays, and checks that the cumulative unrivaled time the challenge tree computes for
+// the honest root edge matches the timer cache the challenge manager computes, and that the
+// challenge manager confirms the root edge by time exactly when the challenge tree predicts.
+func TestTimerAccumulation_MatchesChallengeManager(t *testing.T) {
+	for seed := int64(0); seed < 16; seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed_%d", seed), func(t *testing.T) {
+			checkRandomChallengeTimers(t, rand.New(rand.NewSource(seed)))
+		})
+	}
+}
+
+func checkRandomChallengeTimers(t *testing.T, rng *rand.Rand) {
+	ctx := context.Background()
+	createdData, err := setup.CreateTwoValidatorFork(ctx, &setup.CreateForkConfig{}, setup.WithMockOneStepProver())
+	require.NoError(t, err)
+	chain := createdData.Chains[0]
+	challengeManager, err := chain.SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	advance := func(maxBlocks int) {
+		for i := rng.Intn(maxBlocks + 1); i > 0; i-- {
+			createdData.Backend.Commit()
+		}
+	}
+	stateManagers := map[bool]l2stateprovider.Provider{
+		true:  createdData.HonestStateManager,
+		false: createdData.EvilStateManager,
+	}
+	historyRequest := func(upTo uint64) *l2stateprovider.HistoryCommitmentRequest {
+		return &l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              common.Hash{},
+			FromBatch:                   0,
+			ToBatch:                     1,
+			UpperChallengeOriginHeights: []l2stateprovider.Height{},
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(l2stateprovider.Height(upTo)),
+		}
+	}
+	addLevelZeroEdge := func(honest bool, leaf protocol.Assertion) protocol.SpecEdge {
+		stateManager := stateManagers[honest]
+		startCommit, err := stateManager.HistoryCommitment(ctx, historyRequest(0))
+		require.NoError(t, err)
+		req := historyRequest(challenge_testing.LevelZeroBlockEdgeHeight)
+		endCommit, err := stateManager.HistoryCommitment(ctx, req)
+		require.NoError(t, err)
+		prefixProof, err := stateManager.PrefixProof(ctx, req, 0)
+		require.NoError(t, err)
+		edge, err := challengeManager.AddBlockChallengeLevelZeroEdge(ctx, leaf, startCommit, endCommit, prefixProof)
+		require.NoError(t, err)
+		return edge
+	}
+
+	advance(10)
+	edges := []*generatedEdge{{edge: addLevelZeroEdge(true, createdData.Leaf1), honest: true}}
+	if rng.Intn(4) > 0 {
+		advance(10)
+		edges = append(edges, &generatedEdge{edge: addLevelZeroEdge(false, createdData.Leaf2), honest: false})
+	}
+
+	// Randomly bisect edges which have a rival and can still be bisected. The validators diverge
+	// at the first block, so only edges starting at height zero have rivals.
+	bisected := make(map[protocol.EdgeId]bool)
+	for step := rng.Intn(8); step > 0; step-- {
+		candidates := make([]*generatedEdge, 0)
+		for _, e := range edges {
+			start, _ := e.edge.StartCommitment()
+			end, _ := e.edge.EndCommitment()
+			hasRival, err := e.edge.HasRival(ctx)
+			require.NoError(t, err)
+			if hasRival && !bisected[e.edge.Id()] && end-start > 1 {
+				candidates = append(candidates, e)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		advance(10)
+		e := candidates[rng.Intn(len(candidates))]
+		start, _ := e.edge.StartCommitment()
+		end, _ := e.edge.EndCommitment()
+		mid := uint64(start+end) / 2
+		stateManager := stateManagers[e.honest]
+		bisectCommit, err := stateManager.HistoryCommitment(ctx, historyRequest(mid))
+		require.NoError(t, err)
+		proof, err := stateManager.PrefixProof(ctx, historyRequest(uint64(end)), l2stateprovider.Height(mid))
+		require.NoError(t, err)
+		lower, upper, err := e.edge.Bisect(ctx, bisectCommit.Merkle, proof)
+		require.NoError(t, err)
+		bisected[e.edge.Id()] = true
+		edges = append(edges, &generatedEdge{edge: lower, honest: e.honest}, &generatedEdge{edge: upper, honest: e.honest})
+	}
+	advance(30)
+
+	honestRoot := edges[0].edge
+	challengedAssertionHash, err := honestRoot.AssertionHash(ctx)
+	require.NoError(t, err)
+	numBigStepLevels, err := challengeManager.NumBigSteps(ctx)
+	require.NoError(t, err)
+	tree := challengetree.New(challengedAssertionHash, chain, createdData.HonestStateManager, numBigStepLevels, "honest")
+	honestBranch := make([]protocol.ReadOnlyEdge, 0)
+	for _, e := range edges {
+		if !e.honest {
+			isRoyal, err := tree.AddEdge(ctx, e.edge)
+			require.NoError(t, err)
+			require.False(t, isRoyal)
+			continue
+		}
+		// Our own edges are known to be royal, as they are when the challenge manager creates them.
+		royal, ok := e.edge.(protocol.VerifiedRoyalEdge)
+		require.True(t, ok)
+		require.NoError(t, tree.AddRoyalEdge(royal))
+		// Children are created after their parents, so the reversed branch updates children first.
+		honestBranch = append([]protocol.ReadOnlyEdge{e.edge}, honestBranch...)
+	}
+
+	// The cached timers of the honest edges are updated in a single block, at which the challenge
+	// tree's timer must match the root edge's cache exactly.
+	_, err = challengeManager.MultiUpdateInheritedTimers(ctx, honestBranch, math.MaxUint64)
+	require.NoError(t, err)
+	updatedAt := latestBlockNumber(t, ctx, createdData.Backend)
+	expected, err := tree.ComputeRootInheritedTimer(ctx, challengedAssertionHash, updatedAt)
+	require.NoError(t, err)
+	onchain, err := honestRoot.LatestInheritedTimer(ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected, onchain)
+
+	// Confirmation happens in the next block, by which the root edge's own timer may have grown
+	// while the cached timers of its descendants stay as they were.
+	confirmAt := updatedAt + 1
+	rootTimerAtUpdate, err := tree.LocalTimer(honestRoot, updatedAt)
+	require.NoError(t, err)
+	rootTimerAtConfirm, err := tree.LocalTimer(honestRoot, confirmAt)
+	require.NoError(t, err)
+	predicted := uint64(expected) + rootTimerAtConfirm - rootTimerAtUpdate
+	challengePeriodBlocks, err := challengeManager.ChallengePeriodBlocks(ctx)
+	require.NoError(t, err)
+
+	_, err = honestRoot.ConfirmByTimer(ctx)
+	status, statusErr := honestRoot.Status(ctx)
+	require.NoError(t, statusErr)
+	if predicted >= challengePeriodBlocks {
+		require.NoError(t, err, "predicted timer %d reaches the challenge period %d", predicted, challengePeriodBlocks)
+		require.Equal(t, protocol.EdgeConfirmed, status)
+	} else {
+		require.Error(t, err, "predicted timer %d is below the challenge period %d", predicted, challengePeriodBlocks)
+		require.Equal(t, protocol.EdgePending, status)
+	}
+}
+
+func latestBlockNumber(t *testing.T, ctx context.Context, backend *setup.SimulatedBackendWrapper) uint64 {
+	header, err := backend.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	return header.Number.Uint64()
+}
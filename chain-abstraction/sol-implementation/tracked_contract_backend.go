@@ -12,6 +12,7 @@ import (
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -55,6 +56,10 @@ func (t *TrackedContractBackend) CallContract(ctx context.Context, call ethereum
 	return t.ChainBackend.CallContract(ctx, call, blockNumber)
 }
 
+func (t *TrackedContractBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return StorageAt(ctx, t.ChainBackend, account, key, blockNumber)
+}
+
 func (t *TrackedContractBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
 	if tx != nil && len(tx.Data()) >= 4 {
 		methodHash := fmt.Sprintf("%#x", tx.Data()[:4])
@@ -79,6 +79,10 @@ func (m *MockContractBackend) CodeAt(ctx context.Context, contract common.Addres
 	return nil, nil
 }
 
+func (m *MockContractBackend) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
 func (m *MockContractBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
 	return nil, nil
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/OffchainLabs/bold/containers"
+	"github.com/OffchainLabs/bold/util/eventbus"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -53,15 +54,48 @@ func (a *AssertionChain) transact(
 	for _, o := range configOpts {
 		o(config)
 	}
+	if err := a.poison.CheckNotPoisoned(); err != nil {
+		return nil, err
+	}
 	// We do not send the tx, but instead estimate gas first.
 	opts := copyTxOpts(a.txOpts)
 
 	// No BOLD transactions require a value.
 	opts.Value = big.NewInt(0)
 	opts.NoSend = true
-	tx, err := fn(opts)
+	// The test execution only needs the calldata, so it is not signed, as the transactor may
+	// sign transactions itself rather than with the signer of the options.
+	testOpts := copyTxOpts(opts)
+	testOpts.Signer = unsignedSigner
+	tx, err := fn(testOpts)
 	if err != nil {
-		return nil, errors.Wrap(err, "test execution of tx errored before sending payable tx")
+		err = errors.Wrap(err, "test execution of tx errored before sending payable tx")
+		if !isRevert(err) {
+			return nil, err
+		}
+		// The bindings estimate gas before building the transaction, so its calldata is only
+		// known by building it again with a gas limit, which skips the estimation.
+		reproOpts := copyTxOpts(testOpts)
+		reproOpts.GasLimit = reproGasLimit
+		reproTx, reproErr := fn(reproOpts)
+		if reproErr != nil {
+			return nil, err
+		}
+		var to common.Address
+		if reproTx.To() != nil {
+			to = *reproTx.To()
+		}
+		if waited, waitErr := a.waitIfPaused(ctx, to, err); waitErr != nil {
+			return nil, waitErr
+		} else if waited {
+			return a.transact(ctx, backend, fn, configOpts...)
+		}
+		return nil, a.withRevertRepro(ctx, backend, err, &RevertRepro{
+			From:  opts.From,
+			To:    to,
+			Data:  reproTx.Data(),
+			Value: opts.Value,
+		})
 	}
 	// Convert the transaction into a CallMsg.
 	msg := ethereum.CallMsg{
@@ -75,38 +109,89 @@ func (a *AssertionChain) transact(
 
 	// Estimate the gas required for the transaction. This will catch errors early
 	// without needing to pay for the transaction and waste funds.
+	var to common.Address
+	if tx.To() != nil {
+		to = *tx.To()
+	}
 	gas, err := backend.EstimateGas(ctx, msg)
 	if err != nil {
-		return nil, errors.Wrapf(err, "gas estimation errored for tx with hash %s", containers.Trunc(tx.Hash().Bytes()))
+		if waited, waitErr := a.waitIfPaused(ctx, to, err); waitErr != nil {
+			return nil, waitErr
+		} else if waited {
+			return a.transact(ctx, backend, fn, configOpts...)
+		}
+		return nil, a.withRevertRepro(ctx, backend, errors.Wrapf(err, "gas estimation errored for tx with hash %s", containers.Trunc(tx.Hash().Bytes())), &RevertRepro{
+			From:  opts.From,
+			To:    to,
+			Data:  tx.Data(),
+			Value: opts.Value,
+		})
+	}
+
+	// A transaction stuck for too long waits for an operator to unblock it, rather than being
+	// sent again.
+	intentKey := intentKeyOf(tx)
+	if a.isEscalated(ctx, backend, intentKey) {
+		method, _ := decodeTxIntent(tx.Data())
+		return nil, errors.Wrapf(ErrTxEscalated, "%s to %#x", method, to)
 	}
 
 	// Now, we send the tx with the estimated gas.
 	opts.GasLimit = gas + 500000
 	tx, err = a.transactor.SendTransaction(ctx, fn, opts, gas)
 	if err != nil {
+		a.eventBus.PublishTx(eventbus.TxEvent{Kind: eventbus.TxFailed, To: to, Err: err})
 		return nil, err
 	}
+	a.stuckTxs.sent(intentKey, time.Now())
+	a.eventBus.PublishTx(eventbus.TxEvent{Kind: eventbus.TxSubmitted, TxHash: tx.Hash(), To: to})
 
 	if commiter, ok := backend.(ChainCommitter); ok {
 		commiter.Commit()
 	}
 	ctxWaitMined, cancelWaitMined := context.WithTimeout(ctx, time.Minute)
 	defer cancelWaitMined()
-	receipt, err := bind.WaitMined(ctxWaitMined, backend, tx)
+	mined, receipt, err := a.waitMined(ctxWaitMined, backend, tx)
 	if err != nil {
+		if ctx.Err() == nil && errors.Is(ctxWaitMined.Err(), context.DeadlineExceeded) {
+			if escalateErr := a.escalateIfStuck(ctx, backend, tx, opts.From); escalateErr != nil {
+				a.eventBus.PublishTx(eventbus.TxEvent{Kind: eventbus.TxEscalated, TxHash: tx.Hash(), To: to, Err: escalateErr})
+				return nil, escalateErr
+			}
+		} else {
+			a.stuckTxs.forget(intentKey)
+		}
+		a.eventBus.PublishTx(eventbus.TxEvent{Kind: eventbus.TxFailed, TxHash: tx.Hash(), To: to, Err: err})
 		return nil, err
 	}
+	tx = mined
+	a.stuckTxs.forget(intentKey)
 
 	if config.waitForDesiredBlockNum {
 		ctxWaitSafe, cancelWaitSafe := context.WithTimeout(ctx, time.Minute*20)
 		defer cancelWaitSafe()
 		receipt, err = a.waitForTxToBeSafe(ctxWaitSafe, backend, tx, receipt)
 		if err != nil {
+			a.eventBus.PublishTx(eventbus.TxEvent{Kind: eventbus.TxFailed, TxHash: tx.Hash(), To: to, Err: err})
 			return nil, err
 		}
 	}
 
-	if receipt.Status != types.ReceiptStatusSuccessful {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		a.eventBus.PublishTx(eventbus.TxEvent{
+			Kind:        eventbus.TxMined,
+			TxHash:      tx.Hash(),
+			To:          to,
+			BlockNumber: receipt.BlockNumber.Uint64(),
+		})
+	} else {
+		a.eventBus.PublishTx(eventbus.TxEvent{
+			Kind:        eventbus.TxFailed,
+			TxHash:      tx.Hash(),
+			To:          to,
+			BlockNumber: receipt.BlockNumber.Uint64(),
+			Err:         errors.New("transaction reverted"),
+		})
 		callMsg := ethereum.CallMsg{
 			From:       opts.From,
 			To:         tx.To(),
@@ -117,12 +202,39 @@ func (a *AssertionChain) transact(
 			AccessList: tx.AccessList(),
 		}
 		if _, err := backend.CallContract(ctx, callMsg, nil); err != nil {
-			return nil, errors.Wrap(err, "transaction errored")
+			return nil, a.withRevertRepro(ctx, backend, errors.Wrap(err, "transaction errored"), &RevertRepro{
+				From:        opts.From,
+				To:          to,
+				Data:        tx.Data(),
+				Value:       tx.Value(),
+				BlockNumber: new(big.Int).Sub(receipt.BlockNumber, common.Big1),
+				TxHash:      tx.Hash(),
+			})
 		}
 	}
 	return receipt, nil
 }
 
+// Transactors which may send a transaction again in the stead of one they sent, such as when it
+// was displaced, and know which of them was mined.
+type minedWaiter interface {
+	WaitMined(ctx context.Context, tx *types.Transaction) (*types.Transaction, *types.Receipt, error)
+}
+
+// waitMined waits for a transaction to be mined, and returns the transaction which was mined in its
+// stead if the transactor sent another.
+func (a *AssertionChain) waitMined(
+	ctx context.Context,
+	backend ChainBackend,
+	tx *types.Transaction,
+) (*types.Transaction, *types.Receipt, error) {
+	if waiter, ok := a.transactor.(minedWaiter); ok {
+		return waiter.WaitMined(ctx, tx)
+	}
+	receipt, err := bind.WaitMined(ctx, backend, tx)
+	return tx, receipt, err
+}
+
 // waitForTxToBeSafe waits for the transaction to be mined in a block that is safe.
 func (a *AssertionChain) waitForTxToBeSafe(
 	ctx context.Context,
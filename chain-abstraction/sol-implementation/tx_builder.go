@@ -0,0 +1,83 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// TxBuilder builds and signs the transactions a ChainBackendTransactor sends, for parent chains
+// which need a signing scheme or transaction type other than the standard ones the contract
+// bindings produce. It is given an unsigned template of the transaction, with the recipient,
+// calldata, value, nonce, gas limit and fees the bindings chose, and returns the transaction to
+// send.
+type TxBuilder interface {
+	BuildTx(ctx context.Context, from common.Address, template *types.Transaction) (*types.Transaction, error)
+}
+
+// Stands in for the signer of transaction options, so the bindings produce unsigned transactions.
+func unsignedSigner(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	return tx, nil
+}
+
+// SignerTxBuilder signs the transactions the bindings produce with a custom types.Signer, such
+// as one for a parent chain with its own chain id scheme, leaving their type and fields as they
+// are.
+type SignerTxBuilder struct {
+	signer types.Signer
+	sign   func(hash common.Hash) ([]byte, error)
+}
+
+// NewSignerTxBuilder signs transactions with the given signer, using sign to sign the hash the
+// signer computes for a transaction, such as with crypto.Sign and a private key.
+func NewSignerTxBuilder(signer types.Signer, sign func(hash common.Hash) ([]byte, error)) *SignerTxBuilder {
+	return &SignerTxBuilder{
+		signer: signer,
+		sign:   sign,
+	}
+}
+
+func (b *SignerTxBuilder) BuildTx(_ context.Context, from common.Address, template *types.Transaction) (*types.Transaction, error) {
+	signature, err := b.sign(b.signer.Hash(template))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign transaction")
+	}
+	tx, err := template.WithSignature(b.signer, signature)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := types.Sender(b.signer, tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not recover transaction sender")
+	}
+	if sender != from {
+		return nil, errors.Errorf("transaction signed by %s rather than %s", sender.Hex(), from.Hex())
+	}
+	return tx, nil
+}
+
+// Produces a transaction through the bindings and builds it with a tx builder, if any.
+func buildTx(
+	ctx context.Context,
+	builder TxBuilder,
+	fn func(opts *bind.TransactOpts) (*types.Transaction, error),
+	opts *bind.TransactOpts,
+) (*types.Transaction, error) {
+	if builder == nil {
+		return fn(opts)
+	}
+	unsignedOpts := copyTxOpts(opts)
+	unsignedOpts.Signer = unsignedSigner
+	unsignedOpts.NoSend = true
+	template, err := fn(unsignedOpts)
+	if err != nil {
+		return nil, err
+	}
+	return builder.BuildTx(ctx, opts.From, template)
+}
@@ -0,0 +1,78 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package solimpl
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type sentTxBackend struct {
+	MockContractBackend
+	sent []*types.Transaction
+}
+
+func (b *sentTxBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func TestChainBackendTransactor_WithTxBuilder(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.BytesToAddress([]byte("to"))
+	signer := types.NewEIP155Signer(big.NewInt(424242))
+	backend := &sentTxBackend{}
+	transactor := NewChainBackendTransactor(backend, WithTxBuilder(NewSignerTxBuilder(signer, func(hash common.Hash) ([]byte, error) {
+		return crypto.Sign(hash.Bytes(), key)
+	})))
+
+	// The bindings are given options whose signer leaves transactions unsigned.
+	fn := func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		require.True(t, opts.NoSend)
+		return opts.Signer(opts.From, types.NewTx(&types.LegacyTx{
+			Nonce:    7,
+			To:       &to,
+			Gas:      21000,
+			GasPrice: big.NewInt(1),
+			Data:     []byte{1, 2, 3},
+		}))
+	}
+	opts := &bind.TransactOpts{
+		From:   from,
+		NoSend: true,
+		Signer: func(common.Address, *types.Transaction) (*types.Transaction, error) {
+			t.Fatal("the signer of the options should not be used")
+			return nil, nil
+		},
+	}
+	tx, err := transactor.SendTransaction(ctx, fn, opts, 21000)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Transaction{tx}, backend.sent)
+	require.Equal(t, big.NewInt(424242), tx.ChainId())
+	sender, err := types.Sender(signer, tx)
+	require.NoError(t, err)
+	require.Equal(t, from, sender)
+	require.Equal(t, uint64(7), tx.Nonce())
+	require.Equal(t, []byte{1, 2, 3}, tx.Data())
+}
+
+func TestSignerTxBuilder_RejectsOtherSender(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	builder := NewSignerTxBuilder(types.NewEIP155Signer(big.NewInt(1)), func(hash common.Hash) ([]byte, error) {
+		return crypto.Sign(hash.Bytes(), key)
+	})
+	_, err = builder.BuildTx(context.Background(), common.BytesToAddress([]byte("someone else")), types.NewTx(&types.LegacyTx{}))
+	require.ErrorContains(t, err, "rather than")
+}
@@ -141,6 +141,15 @@ type honestEdge struct {
 
 func (h *honestEdge) Honest() {}
 
+type adoptedEdge struct {
+	honestEdge
+	parentId protocol.EdgeId
+}
+
+func (a *adoptedEdge) AdoptedBy() protocol.EdgeId {
+	return a.parentId
+}
+
 type specEdge struct {
 	id                   [32]byte
 	mutualId             [32]byte
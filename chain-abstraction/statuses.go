@@ -0,0 +1,168 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EdgeStatus of an edge in the protocol.
+type EdgeStatus uint8
+
+const (
+	EdgePending EdgeStatus = iota
+	EdgeConfirmed
+)
+
+// EdgeStatusFromUint8 converts the status of an edge read from the challenge manager
+// bindings, failing if the contracts report a status this client does not know of.
+func EdgeStatusFromUint8(status uint8) (EdgeStatus, error) {
+	e := EdgeStatus(status)
+	if !e.IsValid() {
+		return 0, errors.Errorf("unknown edge status %d", status)
+	}
+	return e, nil
+}
+
+// EdgeStatusFromString parses the name of an edge status, such as "pending" or "confirmed".
+func EdgeStatusFromString(s string) (EdgeStatus, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "pending":
+		return EdgePending, nil
+	case "confirmed":
+		return EdgeConfirmed, nil
+	default:
+		return 0, errors.Errorf("unknown edge status %q, expected pending or confirmed", s)
+	}
+}
+
+func (e EdgeStatus) Uint8() uint8 {
+	return uint8(e)
+}
+
+func (e EdgeStatus) IsValid() bool {
+	return e <= EdgeConfirmed
+}
+
+func (e EdgeStatus) String() string {
+	switch e {
+	case EdgePending:
+		return "pending"
+	case EdgeConfirmed:
+		return "confirmed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes an edge status by its name, so it reads as such in JSON.
+func (e EdgeStatus) MarshalText() ([]byte, error) {
+	if !e.IsValid() {
+		return nil, errors.Errorf("unknown edge status %d", uint8(e))
+	}
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText decodes an edge status from its name, or from its number as in the bindings.
+func (e *EdgeStatus) UnmarshalText(text []byte) error {
+	if n, err := strconv.ParseUint(string(text), 10, 8); err == nil {
+		status, err := EdgeStatusFromUint8(uint8(n))
+		if err != nil {
+			return err
+		}
+		*e = status
+		return nil
+	}
+	status, err := EdgeStatusFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*e = status
+	return nil
+}
+
+// MachineStatus of the machine at an execution state.
+type MachineStatus uint8
+
+const (
+	MachineStatusRunning  MachineStatus = 0
+	MachineStatusFinished MachineStatus = 1
+	MachineStatusErrored  MachineStatus = 2
+)
+
+// MachineStatusFromUint8 converts the machine status of an execution state read from the
+// rollup bindings, failing if the contracts report a status this client does not know of.
+func MachineStatusFromUint8(status uint8) (MachineStatus, error) {
+	s := MachineStatus(status)
+	if !s.IsValid() {
+		return 0, errors.Errorf("unknown machine status %d", status)
+	}
+	return s, nil
+}
+
+// MachineStatusFromString parses the name of a machine status, such as "running", "finished"
+// or "errored".
+func MachineStatusFromString(str string) (MachineStatus, error) {
+	switch strings.TrimSpace(strings.ToLower(str)) {
+	case "running":
+		return MachineStatusRunning, nil
+	case "finished":
+		return MachineStatusFinished, nil
+	case "errored":
+		return MachineStatusErrored, nil
+	default:
+		return 0, errors.Errorf("unknown machine status %q, expected running, finished or errored", str)
+	}
+}
+
+func (s MachineStatus) Uint8() uint8 {
+	return uint8(s)
+}
+
+func (s MachineStatus) IsValid() bool {
+	return s <= MachineStatusErrored
+}
+
+func (s MachineStatus) String() string {
+	switch s {
+	case MachineStatusRunning:
+		return "running"
+	case MachineStatusFinished:
+		return "finished"
+	case MachineStatusErrored:
+		return "errored"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// MarshalText encodes a machine status by its name, so it reads as such in JSON.
+func (s MachineStatus) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return nil, errors.Errorf("unknown machine status %d", uint8(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a machine status from its name, or from its number as in the bindings.
+func (s *MachineStatus) UnmarshalText(text []byte) error {
+	if n, err := strconv.ParseUint(string(text), 10, 8); err == nil {
+		status, err := MachineStatusFromUint8(uint8(n))
+		if err != nil {
+			return err
+		}
+		*s = status
+		return nil
+	}
+	status, err := MachineStatusFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdgeStatus(t *testing.T) {
+	for _, status := range []EdgeStatus{EdgePending, EdgeConfirmed} {
+		require.True(t, status.IsValid())
+		fromUint8, err := EdgeStatusFromUint8(status.Uint8())
+		require.NoError(t, err)
+		require.Equal(t, status, fromUint8)
+		fromString, err := EdgeStatusFromString(status.String())
+		require.NoError(t, err)
+		require.Equal(t, status, fromString)
+	}
+	require.False(t, EdgeStatus(2).IsValid())
+	_, err := EdgeStatusFromUint8(2)
+	require.ErrorContains(t, err, "unknown edge status 2")
+	_, err = EdgeStatusFromString("confirmable")
+	require.ErrorContains(t, err, "expected pending or confirmed")
+
+	status, err := EdgeStatusFromString(" Confirmed ")
+	require.NoError(t, err)
+	require.Equal(t, EdgeConfirmed, status)
+}
+
+func TestEdgeStatus_JSON(t *testing.T) {
+	type edge struct {
+		Status EdgeStatus `json:"status"`
+	}
+	enc, err := json.Marshal(edge{Status: EdgeConfirmed})
+	require.NoError(t, err)
+	require.Equal(t, `{"status":"confirmed"}`, string(enc))
+
+	var dec edge
+	require.NoError(t, json.Unmarshal(enc, &dec))
+	require.Equal(t, EdgeConfirmed, dec.Status)
+	require.NoError(t, json.Unmarshal([]byte(`{"status":"0"}`), &dec))
+	require.Equal(t, EdgePending, dec.Status)
+
+	require.Error(t, json.Unmarshal([]byte(`{"status":"2"}`), &dec))
+	require.Error(t, json.Unmarshal([]byte(`{"status":"unknown"}`), &dec))
+	_, err = json.Marshal(edge{Status: EdgeStatus(2)})
+	require.Error(t, err)
+}
+
+func TestMachineStatus(t *testing.T) {
+	for _, status := range []MachineStatus{MachineStatusRunning, MachineStatusFinished, MachineStatusErrored} {
+		require.True(t, status.IsValid())
+		fromUint8, err := MachineStatusFromUint8(status.Uint8())
+		require.NoError(t, err)
+		require.Equal(t, status, fromUint8)
+		fromString, err := MachineStatusFromString(status.String())
+		require.NoError(t, err)
+		require.Equal(t, status, fromString)
+	}
+	require.False(t, MachineStatus(3).IsValid())
+	require.Equal(t, "unknown(3)", MachineStatus(3).String())
+	_, err := MachineStatusFromUint8(3)
+	require.ErrorContains(t, err, "unknown machine status 3")
+	_, err = MachineStatusFromString("halted")
+	require.ErrorContains(t, err, "expected running, finished or errored")
+}
+
+func TestMachineStatus_JSON(t *testing.T) {
+	type state struct {
+		MachineStatus MachineStatus `json:"machineStatus"`
+	}
+	enc, err := json.Marshal(state{MachineStatus: MachineStatusErrored})
+	require.NoError(t, err)
+	require.Equal(t, `{"machineStatus":"errored"}`, string(enc))
+
+	var dec state
+	require.NoError(t, json.Unmarshal(enc, &dec))
+	require.Equal(t, MachineStatusErrored, dec.MachineStatus)
+	require.NoError(t, json.Unmarshal([]byte(`{"machineStatus":"1"}`), &dec))
+	require.Equal(t, MachineStatusFinished, dec.MachineStatus)
+
+	require.Error(t, json.Unmarshal([]byte(`{"machineStatus":"3"}`), &dec))
+	_, err = json.Marshal(state{MachineStatus: MachineStatus(3)})
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package challengemanager
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/OffchainLabs/bold/assertions"
+	"github.com/OffchainLabs/bold/challenge-manager/artifacts"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Artifacts keeps the working directory of each challenge the manager makes moves in, which is
+// nil if the manager does not keep artifacts.
+func (m *Manager) Artifacts() *artifacts.Store {
+	return m.artifacts
+}
+
+// Records the creation of our block challenge edge with its history commitments and prefix proof.
+// Failing to write artifacts does not fail the move, so errors are only logged.
+func (m *Manager) recordBlockChallengeEdge(
+	parentAssertionHash, edgeId common.Hash,
+	startCommit, endCommit commitments.History,
+	prefixProof []byte,
+) {
+	if m.artifacts == nil {
+		return
+	}
+	commitment, err := json.Marshal(map[string]commitments.History{
+		"start": startCommit,
+		"end":   endCommit,
+	})
+	if err != nil {
+		log.Warn("Could not encode block challenge edge commitments", "edgeId", edgeId, "err", err)
+		return
+	}
+	if err = m.artifacts.RecordMove(parentAssertionHash, &artifacts.Move{
+		Kind:   "block_challenge_edge",
+		EdgeId: edgeId,
+		Artifacts: []artifacts.Artifact{
+			{Kind: artifacts.Commitments, Name: "block-edge-" + edgeId.Hex() + ".json", Data: commitment},
+			{Kind: artifacts.Proofs, Name: "block-edge-" + edgeId.Hex() + ".bin", Data: prefixProof},
+		},
+	}); err != nil {
+		log.Warn("Could not record challenge artifacts", "edgeId", edgeId, "err", err)
+	}
+}
+
+// Marks the challenge on the children of an assertion as resolved once one of them is confirmed,
+// archiving and pruning its directory as the store is configured.
+type artifactsFinalizationHandler struct {
+	store *artifacts.Store
+}
+
+func (h *artifactsFinalizationHandler) Name() string {
+	return "challenge-artifacts"
+}
+
+func (h *artifactsFinalizationHandler) AssertionFinalized(_ context.Context, assertion *assertions.FinalizedAssertion) error {
+	return h.store.Resolve(assertion.ParentAssertionHash.Hash, assertion.AssertionHash.Hash)
+}
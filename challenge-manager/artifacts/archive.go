@@ -0,0 +1,170 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package artifacts
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The largest artifact extracted from an archive, guarding against decompression bombs.
+const maxArtifactSize = 1 << 30
+
+// Archive writes the directory of a challenge as a gzipped tarball, with the manifest first so that
+// the archive describes itself. Only the manifest and the artifacts it lists are included.
+func (s *Store) Archive(assertionHash common.Hash, w io.Writer) error {
+	d, err := s.Challenge(assertionHash)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err = verifyManifest(d.dir, d.manifest); err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	files := append([]string{ManifestFile}, d.manifest.Paths()...)
+	for _, rel := range files {
+		data, err := os.ReadFile(filepath.Join(d.dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		if err = tw.WriteHeader(&tar.Header{
+			Name: rel,
+			Mode: 0o644,
+			Size: int64(len(data)),
+		}); err != nil {
+			return err
+		}
+		if _, err = tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (s *Store) archiveToFile(assertionHash common.Hash) error {
+	file := filepath.Join(s.archiveDir, assertionHash.Hex()+".tar.gz")
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err = s.Archive(assertionHash, f); err != nil {
+		f.Close()
+		return fmt.Errorf("could not archive challenge on assertion %#x: %w", assertionHash, err)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Import extracts an archive written by Archive, such as one from another machine, into the
+// directory of its challenge, returning the assertion it is for. The artifacts are verified against
+// the manifest before the directory is created, and a challenge which already has a directory is
+// not overwritten.
+func (s *Store) Import(r io.Reader) (common.Hash, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("could not read archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	var manifest *Manifest
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("could not read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxArtifactSize {
+			return common.Hash{}, fmt.Errorf("artifact %s of %d bytes is too large", header.Name, header.Size)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxArtifactSize))
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("could not read artifact %s: %w", header.Name, err)
+		}
+		if header.Name == ManifestFile {
+			manifest = &Manifest{}
+			if err = json.Unmarshal(data, manifest); err != nil {
+				return common.Hash{}, fmt.Errorf("could not decode manifest: %w", err)
+			}
+			continue
+		}
+		files[header.Name] = data
+	}
+	if manifest == nil {
+		return common.Hash{}, errors.New("archive has no manifest")
+	}
+	if manifest.Version != manifestVersion {
+		return common.Hash{}, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+	for _, rel := range manifest.Paths() {
+		data, ok := files[rel]
+		if !ok {
+			return common.Hash{}, fmt.Errorf("%w: %s is missing from the archive", ErrCorrupt, rel)
+		}
+		entry := manifest.Entries[rel]
+		kind, name := path.Split(rel)
+		if expected, err := artifactPath(entry.Kind, name); err != nil || expected != rel || kind != string(entry.Kind)+"/" {
+			return common.Hash{}, fmt.Errorf("invalid artifact path %q in manifest", rel)
+		}
+		if err := checkEntry(rel, entry, data); err != nil {
+			return common.Hash{}, err
+		}
+	}
+
+	assertionHash := manifest.AssertionHash
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	dir := s.challengePath(assertionHash)
+	if _, err := os.Stat(dir); err == nil {
+		return common.Hash{}, fmt.Errorf("challenge on assertion %#x already has a directory", assertionHash)
+	}
+	// The artifacts are extracted to a temporary directory which is renamed once complete, so a
+	// failed import leaves nothing behind.
+	tmp := dir + ".import"
+	if err := os.RemoveAll(tmp); err != nil {
+		return common.Hash{}, err
+	}
+	for _, rel := range manifest.Paths() {
+		if err := writeFileAtomic(filepath.Join(tmp, filepath.FromSlash(rel)), files[rel], 0o644); err != nil {
+			os.RemoveAll(tmp)
+			return common.Hash{}, err
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err = writeFileAtomic(filepath.Join(tmp, ManifestFile), data, 0o644); err != nil {
+		os.RemoveAll(tmp)
+		return common.Hash{}, err
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		os.RemoveAll(tmp)
+		return common.Hash{}, err
+	}
+	return assertionHash, nil
+}
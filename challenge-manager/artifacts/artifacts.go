@@ -0,0 +1,463 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package artifacts organizes the artifacts the validator writes to disk for each challenge, such as
+// the history commitments and proofs of its moves and a log of them, under a working directory per
+// challenge. Each directory has a manifest describing its contents with their integrity hashes, so
+// that it can be archived, transferred to another machine and verified there, and removed once the
+// challenge is resolved.
+package artifacts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// ErrNotFound is returned when reading an artifact which is not in the manifest.
+	ErrNotFound = errors.New("artifact not found")
+	// ErrCorrupt is returned when an artifact does not match the integrity hash of its manifest.
+	ErrCorrupt = errors.New("artifact does not match its manifest")
+
+	artifactsWrittenCounter = metrics.NewRegisteredCounter("arb/validator/artifacts/written", nil)
+	challengesPrunedCounter = metrics.NewRegisteredCounter("arb/validator/artifacts/challenges_pruned", nil)
+)
+
+// Kind is the kind of an artifact, which is also the subdirectory it is written to.
+type Kind string
+
+const (
+	Commitments Kind = "commitments"
+	Proofs      Kind = "proofs"
+	Logs        Kind = "logs"
+)
+
+// ManifestFile is the name of the manifest in each challenge directory.
+const ManifestFile = "manifest.json"
+
+const manifestVersion = 1
+
+// Entry describes an artifact in a manifest.
+type Entry struct {
+	Kind Kind `json:"kind"`
+	// The size and SHA-256 hash of the artifact as last written.
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Manifest describes the artifacts of a challenge, keyed by their slash separated path relative to
+// the challenge directory.
+type Manifest struct {
+	Version int `json:"version"`
+	// The assertion whose children are challenged.
+	AssertionHash common.Hash       `json:"assertionHash"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Resolved      bool              `json:"resolved"`
+	ResolvedAt    time.Time         `json:"resolvedAt,omitempty"`
+	Winner        common.Hash       `json:"winner,omitempty"`
+	Entries       map[string]*Entry `json:"entries"`
+}
+
+func (m *Manifest) clone() *Manifest {
+	c := *m
+	c.Entries = make(map[string]*Entry, len(m.Entries))
+	for path, entry := range m.Entries {
+		e := *entry
+		c.Entries[path] = &e
+	}
+	return &c
+}
+
+// Paths lists the artifacts of the manifest in order.
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Entries))
+	for path := range m.Entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ChallengeDir is the working directory of a challenge. Its manifest is rewritten with each
+// artifact written, so the directory can be archived or verified at any time.
+type ChallengeDir struct {
+	dir      string
+	lock     sync.Mutex
+	manifest *Manifest
+}
+
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid artifact name %q", name)
+	}
+	return nil
+}
+
+func artifactPath(kind Kind, name string) (string, error) {
+	switch kind {
+	case Commitments, Proofs, Logs:
+	default:
+		return "", fmt.Errorf("unknown artifact kind %q", kind)
+	}
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+	return string(kind) + "/" + name, nil
+}
+
+func openChallengeDir(dir string, assertionHash common.Hash) (*ChallengeDir, error) {
+	manifest, err := readManifest(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		manifest = &Manifest{
+			Version:       manifestVersion,
+			AssertionHash: assertionHash,
+			CreatedAt:     time.Now().UTC(),
+			Entries:       make(map[string]*Entry),
+		}
+	case err != nil:
+		return nil, err
+	case manifest.AssertionHash != assertionHash:
+		return nil, fmt.Errorf("manifest in %s is for assertion %#x, not %#x", dir, manifest.AssertionHash, assertionHash)
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create challenge directory %s: %w", dir, err)
+	}
+	return &ChallengeDir{dir: dir, manifest: manifest}, nil
+}
+
+func readManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("could not decode manifest in %s: %w", dir, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d in %s", manifest.Version, dir)
+	}
+	if manifest.Entries == nil {
+		manifest.Entries = make(map[string]*Entry)
+	}
+	return manifest, nil
+}
+
+// Path is the directory of the challenge.
+func (d *ChallengeDir) Path() string {
+	return d.dir
+}
+
+// Manifest returns a copy of the manifest of the challenge.
+func (d *ChallengeDir) Manifest() *Manifest {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.manifest.clone()
+}
+
+// Put creates or replaces an artifact, writing it to a temporary file before renaming it so that a
+// crash never leaves a partially written artifact behind.
+func (d *ChallengeDir) Put(kind Kind, name string, data []byte) error {
+	rel, err := artifactPath(kind, name)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err = writeFileAtomic(filepath.Join(d.dir, filepath.FromSlash(rel)), data, 0o644); err != nil {
+		return err
+	}
+	return d.record(kind, rel, data)
+}
+
+// Append appends to an artifact, such as a log, creating it if it does not exist.
+func (d *ChallengeDir) Append(kind Kind, name string, data []byte) error {
+	rel, err := artifactPath(kind, name)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	path := filepath.Join(d.dir, filepath.FromSlash(rel))
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return d.record(kind, rel, contents)
+}
+
+// Records an artifact as written in the manifest. Must be called with the lock held.
+func (d *ChallengeDir) record(kind Kind, rel string, contents []byte) error {
+	sum := sha256.Sum256(contents)
+	d.manifest.Entries[rel] = &Entry{
+		Kind:      kind,
+		Size:      int64(len(contents)),
+		SHA256:    hex.EncodeToString(sum[:]),
+		UpdatedAt: time.Now().UTC(),
+	}
+	artifactsWrittenCounter.Inc(1)
+	return d.writeManifest()
+}
+
+// Must be called with the lock held.
+func (d *ChallengeDir) writeManifest() error {
+	data, err := json.MarshalIndent(d.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(d.dir, ManifestFile), data, 0o644)
+}
+
+// Get reads an artifact, checking it against the integrity hash of the manifest.
+func (d *ChallengeDir) Get(kind Kind, name string) ([]byte, error) {
+	rel, err := artifactPath(kind, name)
+	if err != nil {
+		return nil, err
+	}
+	d.lock.Lock()
+	entry, ok := d.manifest.Entries[rel]
+	d.lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, rel)
+	}
+	data, err := os.ReadFile(filepath.Join(d.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+	if err = checkEntry(rel, entry, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func checkEntry(rel string, entry *Entry, data []byte) error {
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != entry.Size || hex.EncodeToString(sum[:]) != entry.SHA256 {
+		return fmt.Errorf("%w: %s", ErrCorrupt, rel)
+	}
+	return nil
+}
+
+// Verify checks every artifact of the manifest against its integrity hash.
+func (d *ChallengeDir) Verify() error {
+	manifest := d.Manifest()
+	return verifyManifest(d.dir, manifest)
+}
+
+func verifyManifest(dir string, manifest *Manifest) error {
+	for _, rel := range manifest.Paths() {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("could not read artifact %s: %w", rel, err)
+		}
+		if err = checkEntry(rel, manifest.Entries[rel], data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Marks the challenge as resolved in favor of an assertion.
+func (d *ChallengeDir) resolve(winner common.Hash, at time.Time) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.manifest.Resolved {
+		return nil
+	}
+	d.manifest.Resolved = true
+	d.manifest.ResolvedAt = at.UTC()
+	d.manifest.Winner = winner
+	return d.writeManifest()
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+type Opt func(*Store)
+
+// WithArchiveDir archives the directory of each challenge to a file in a directory as it is
+// resolved, before it may be pruned.
+func WithArchiveDir(dir string) Opt {
+	return func(s *Store) {
+		s.archiveDir = dir
+	}
+}
+
+// WithRetention prunes the directories of challenges this long after they are resolved. Defaults to
+// zero, which keeps them until pruned explicitly.
+func WithRetention(retention time.Duration) Opt {
+	return func(s *Store) {
+		s.retention = retention
+	}
+}
+
+// Store keeps a working directory per challenge under a root directory, named after the assertion
+// whose children are challenged.
+type Store struct {
+	root       string
+	archiveDir string
+	retention  time.Duration
+	lock       sync.Mutex
+	dirs       map[common.Hash]*ChallengeDir
+}
+
+// NewStore keeps the working directories of challenges under a root directory, creating it if needed.
+func NewStore(root string, opts ...Opt) (*Store, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create artifacts directory %s: %w", root, err)
+	}
+	s := &Store{
+		root: root,
+		dirs: make(map[common.Hash]*ChallengeDir),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.archiveDir != "" {
+		if err := os.MkdirAll(s.archiveDir, 0o755); err != nil {
+			return nil, fmt.Errorf("could not create archive directory %s: %w", s.archiveDir, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) challengePath(assertionHash common.Hash) string {
+	return filepath.Join(s.root, assertionHash.Hex())
+}
+
+// Challenge opens the working directory of the challenge on the children of an assertion, creating
+// it if it does not exist.
+func (s *Store) Challenge(assertionHash common.Hash) (*ChallengeDir, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if d, ok := s.dirs[assertionHash]; ok {
+		return d, nil
+	}
+	d, err := openChallengeDir(s.challengePath(assertionHash), assertionHash)
+	if err != nil {
+		return nil, err
+	}
+	s.dirs[assertionHash] = d
+	return d, nil
+}
+
+// Challenges lists the assertions with a challenge directory.
+func (s *Store) Challenges() ([]common.Hash, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, 0, len(entries))
+	for _, entry := range entries {
+		// Directories being imported are named after their challenge with a suffix.
+		if !entry.IsDir() || common.HexToHash(entry.Name()).Hex() != entry.Name() {
+			continue
+		}
+		if _, err = os.Stat(filepath.Join(s.root, entry.Name(), ManifestFile)); err != nil {
+			continue
+		}
+		hashes = append(hashes, common.HexToHash(entry.Name()))
+	}
+	return hashes, nil
+}
+
+// Resolve marks the challenge on the children of an assertion as resolved in favor of one of them,
+// archiving its directory if an archive directory is configured, and prunes the challenges resolved
+// longer ago than the retention. Assertions without a challenge directory are ignored.
+func (s *Store) Resolve(assertionHash, winner common.Hash) error {
+	if _, err := os.Stat(filepath.Join(s.challengePath(assertionHash), ManifestFile)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	d, err := s.Challenge(assertionHash)
+	if err != nil {
+		return err
+	}
+	if err = d.resolve(winner, time.Now()); err != nil {
+		return err
+	}
+	if s.archiveDir != "" {
+		if err = s.archiveToFile(assertionHash); err != nil {
+			return err
+		}
+	}
+	if s.retention > 0 {
+		if _, err = s.Prune(time.Now().Add(-s.retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune removes the directories of the challenges resolved before a time, returning their
+// assertions.
+func (s *Store) Prune(resolvedBefore time.Time) ([]common.Hash, error) {
+	hashes, err := s.Challenges()
+	if err != nil {
+		return nil, err
+	}
+	pruned := make([]common.Hash, 0)
+	for _, hash := range hashes {
+		manifest, err := readManifest(s.challengePath(hash))
+		if err != nil {
+			log.Warn("Could not read challenge manifest to prune it", "assertionHash", hash, "err", err)
+			continue
+		}
+		if !manifest.Resolved || !manifest.ResolvedAt.Before(resolvedBefore) {
+			continue
+		}
+		if err = s.Remove(hash); err != nil {
+			return pruned, err
+		}
+		challengesPrunedCounter.Inc(1)
+		pruned = append(pruned, hash)
+	}
+	return pruned, nil
+}
+
+// Remove deletes the directory of a challenge.
+func (s *Store) Remove(assertionHash common.Hash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.dirs, assertionHash)
+	return os.RemoveAll(s.challengePath(assertionHash))
+}
@@ -0,0 +1,157 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package artifacts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeDir(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	assertionHash := common.Hash{1}
+	d, err := store.Challenge(assertionHash)
+	require.NoError(t, err)
+
+	require.NoError(t, d.Put(Proofs, "bisection.bin", []byte{1, 2, 3}))
+	require.NoError(t, d.Append(Logs, "moves.jsonl", []byte("a\n")))
+	require.NoError(t, d.Append(Logs, "moves.jsonl", []byte("b\n")))
+	_, err = d.Get(Commitments, "missing.json")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Error(t, d.Put(Proofs, "../escape", nil))
+	require.Error(t, d.Put(Kind("other"), "name", nil))
+
+	got, err := d.Get(Logs, "moves.jsonl")
+	require.NoError(t, err)
+	require.Equal(t, []byte("a\nb\n"), got)
+	require.Equal(t, []string{"logs/moves.jsonl", "proofs/bisection.bin"}, d.Manifest().Paths())
+	require.NoError(t, d.Verify())
+
+	// The manifest is read back when the directory is opened again.
+	reopened, err := NewStore(filepath.Dir(d.Path()))
+	require.NoError(t, err)
+	d2, err := reopened.Challenge(assertionHash)
+	require.NoError(t, err)
+	require.Equal(t, d.Manifest().Entries, d2.Manifest().Entries)
+	hashes, err := reopened.Challenges()
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{assertionHash}, hashes)
+
+	require.NoError(t, os.WriteFile(filepath.Join(d.Path(), "proofs", "bisection.bin"), []byte{1, 2, 4}, 0o644))
+	_, err = d.Get(Proofs, "bisection.bin")
+	require.ErrorIs(t, err, ErrCorrupt)
+	require.ErrorIs(t, d.Verify(), ErrCorrupt)
+}
+
+func TestArchiveAndImport(t *testing.T) {
+	source, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	assertionHash := common.Hash{2}
+	d, err := source.Challenge(assertionHash)
+	require.NoError(t, err)
+	require.NoError(t, d.Put(Commitments, "edge.json", []byte(`{"height":32}`)))
+	require.NoError(t, d.Put(Proofs, "one-step.json", []byte(`{"proof":"0x01"}`)))
+
+	var archive bytes.Buffer
+	require.NoError(t, source.Archive(assertionHash, &archive))
+
+	dest, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	imported, err := dest.Import(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, assertionHash, imported)
+	d2, err := dest.Challenge(assertionHash)
+	require.NoError(t, err)
+	require.NoError(t, d2.Verify())
+	got, err := d2.Get(Proofs, "one-step.json")
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"proof":"0x01"}`), got)
+
+	// A challenge is not overwritten by an import.
+	_, err = dest.Import(bytes.NewReader(archive.Bytes()))
+	require.ErrorContains(t, err, "already has a directory")
+
+	// An archive is not imported if its artifacts do not match its manifest.
+	require.NoError(t, os.WriteFile(filepath.Join(d.Path(), "proofs", "one-step.json"), []byte("tampered"), 0o644))
+	require.ErrorIs(t, source.Archive(assertionHash, &bytes.Buffer{}), ErrCorrupt)
+}
+
+func TestResolveArchivesAndPrunes(t *testing.T) {
+	archiveDir := t.TempDir()
+	store, err := NewStore(t.TempDir(), WithArchiveDir(archiveDir), WithRetention(time.Hour))
+	require.NoError(t, err)
+	resolved, unresolved := common.Hash{3}, common.Hash{4}
+	for _, hash := range []common.Hash{resolved, unresolved} {
+		d, err := store.Challenge(hash)
+		require.NoError(t, err)
+		require.NoError(t, d.Put(Proofs, "proof.bin", hash.Bytes()))
+	}
+
+	// Assertions without a challenge directory are ignored.
+	require.NoError(t, store.Resolve(common.Hash{5}, common.Hash{6}))
+
+	require.NoError(t, store.Resolve(resolved, common.Hash{7}))
+	d, err := store.Challenge(resolved)
+	require.NoError(t, err)
+	manifest := d.Manifest()
+	require.True(t, manifest.Resolved)
+	require.Equal(t, common.Hash{7}, manifest.Winner)
+	_, err = os.Stat(filepath.Join(archiveDir, resolved.Hex()+".tar.gz"))
+	require.NoError(t, err)
+
+	// Challenges are only pruned once resolved for longer than the retention.
+	pruned, err := store.Prune(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, pruned)
+	pruned, err = store.Prune(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{resolved}, pruned)
+	hashes, err := store.Challenges()
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{unresolved}, hashes)
+
+	// The archive of a pruned challenge can be imported again.
+	f, err := os.Open(filepath.Join(archiveDir, resolved.Hex()+".tar.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	imported, err := store.Import(f)
+	require.NoError(t, err)
+	require.Equal(t, resolved, imported)
+}
+
+func TestRecordMove(t *testing.T) {
+	var nilStore *Store
+	require.NoError(t, nilStore.RecordMove(common.Hash{1}, &Move{Kind: "bisection"}))
+
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	assertionHash := common.Hash{1}
+	require.NoError(t, store.RecordMove(assertionHash, &Move{
+		Kind:      "bisection",
+		EdgeId:    common.Hash{2},
+		Details:   map[string]any{"bisectionHeight": 16},
+		Artifacts: []Artifact{{Kind: Proofs, Name: "bisection.bin", Data: []byte{1}}},
+	}))
+	require.NoError(t, store.RecordMove(assertionHash, &Move{Kind: "one_step_proof", EdgeId: common.Hash{3}}))
+
+	d, err := store.Challenge(assertionHash)
+	require.NoError(t, err)
+	proof, err := d.Get(Proofs, "bisection.bin")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, proof)
+	moves, err := d.Get(Logs, MovesLog)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(moves), []byte("\n"))
+	require.Len(t, lines, 2)
+	require.Contains(t, string(lines[0]), `"kind":"bisection"`)
+	require.Contains(t, string(lines[0]), `"name":"bisection.bin"`)
+	require.Contains(t, string(lines[1]), `"kind":"one_step_proof"`)
+}
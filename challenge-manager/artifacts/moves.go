@@ -0,0 +1,57 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package artifacts
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MovesLog is the name of the log of the moves made in a challenge, with a JSON line per move.
+const MovesLog = "moves.jsonl"
+
+// Artifact is a file written for a move, such as a proof it submitted.
+type Artifact struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"`
+	Data []byte `json:"-"`
+}
+
+// Move is a move made in a challenge, such as creating or bisecting an edge.
+type Move struct {
+	Time time.Time `json:"time"`
+	// Names the move, such as "block_challenge_edge", "bisection" or "one_step_proof".
+	Kind      string         `json:"kind"`
+	EdgeId    common.Hash    `json:"edgeId"`
+	Details   map[string]any `json:"details,omitempty"`
+	Artifacts []Artifact     `json:"artifacts,omitempty"`
+}
+
+// RecordMove writes the artifacts of a move to the directory of the challenge on the children of
+// an assertion, then appends the move to its log. A nil store records nothing, so callers need not
+// check whether artifacts are kept.
+func (s *Store) RecordMove(assertionHash common.Hash, move *Move) error {
+	if s == nil {
+		return nil
+	}
+	d, err := s.Challenge(assertionHash)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range move.Artifacts {
+		if err = d.Put(artifact.Kind, artifact.Name, artifact.Data); err != nil {
+			return err
+		}
+	}
+	if move.Time.IsZero() {
+		move.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(move)
+	if err != nil {
+		return err
+	}
+	return d.Append(Logs, MovesLog, append(line, '\n'))
+}
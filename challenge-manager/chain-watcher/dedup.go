@@ -0,0 +1,101 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var duplicateEventsCounter = metrics.NewRegisteredCounter("arb/validator/watcher/duplicate_events", nil)
+
+// Uniquely identifies a log emitted onchain.
+type eventKey struct {
+	txHash   common.Hash
+	logIndex uint
+}
+
+// The set of events ingested into the watcher's queue whose blocks may still be scanned
+// again. Consecutive scans overlap, as the backfill and the polling loop both include their
+// boundary block and the polling loop may be configured to rescan recent blocks, so an event
+// can be seen more than once. Keys are pruned once their block falls behind the start of the
+// next scan, so the set only holds the events of the overlap window.
+type ingestedEvents struct {
+	lock    sync.Mutex
+	blocks  map[eventKey]uint64
+	minKept uint64
+}
+
+func newIngestedEvents() *ingestedEvents {
+	return &ingestedEvents{
+		blocks: make(map[eventKey]uint64),
+	}
+}
+
+// Marks an event as ingested, returning false if it already was, or if its block was
+// pruned, in which case it was ingested by an earlier scan.
+func (s *ingestedEvents) markIngested(key eventKey, blockNumber uint64) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if blockNumber < s.minKept {
+		return false
+	}
+	if _, ok := s.blocks[key]; ok {
+		return false
+	}
+	s.blocks[key] = blockNumber
+	return true
+}
+
+// Forgets an event, so that it is ingested again if it is scanned again.
+func (s *ingestedEvents) unmark(key eventKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.blocks, key)
+}
+
+// Forgets events before the given block, which will not be scanned again.
+func (s *ingestedEvents) prune(nextScanStart uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if nextScanStart <= s.minKept {
+		return
+	}
+	s.minKept = nextScanStart
+	for key, blockNumber := range s.blocks {
+		if blockNumber < nextScanStart {
+			delete(s.blocks, key)
+		}
+	}
+}
+
+func (s *ingestedEvents) len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.blocks)
+}
+
+// Pushes a scanned event to the queue unless the log it was decoded from was already
+// ingested, so that the overlap between scans does not process events twice.
+func (w *Watcher) ingestEvent(ev *watcherEvent, raw types.Log) error {
+	ev.TxHash = raw.TxHash
+	ev.LogIndex = raw.Index
+	ev.BlockNumber = raw.BlockNumber
+	key := eventKey{txHash: raw.TxHash, logIndex: raw.Index}
+	if !w.ingested.markIngested(key, raw.BlockNumber) {
+		duplicateEventsCounter.Inc(1)
+		log.Debug("Skipping duplicate challenge manager event", "txHash", raw.TxHash, "logIndex", raw.Index, "blockNum", raw.BlockNumber)
+		return nil
+	}
+	if err := w.eventQueue.Push(ev); err != nil {
+		// The event was not ingested, so it must not be skipped when the range is scanned again.
+		w.ingested.unmark(key)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Logs of one edge added event per block, with two logs in each transaction.
+func edgeAddedLogs(fromBlock, toBlock uint64) []types.Log {
+	logs := make([]types.Log, 0)
+	for block := fromBlock; block <= toBlock; block++ {
+		for i := uint(0); i < 2; i++ {
+			logs = append(logs, types.Log{
+				Topics:      []common.Hash{common.BytesToHash([]byte("topic"))},
+				Data:        []byte{},
+				BlockNumber: block,
+				TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+				Index:       i,
+			})
+		}
+	}
+	return logs
+}
+
+func ingestLogs(w *Watcher, logs []types.Log) error {
+	for _, raw := range logs {
+		ev := &watcherEvent{
+			Kind: edgeAddedEvent,
+			EdgeAdded: &challengeV2gen.EdgeChallengeManagerEdgeAdded{
+				EdgeId: common.BytesToHash([]byte{byte(raw.BlockNumber), byte(raw.Index)}),
+				Raw:    raw,
+			},
+		}
+		if err := w.ingestEvent(ev, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestWatcher_ingestEvent_handoffRace(t *testing.T) {
+	w, err := New(nil, nil, nil, nil, time.Second, 1, "alice", nil, time.Second, time.Second, nil)
+	require.NoError(t, err)
+
+	// The backfill scans up to block 20 while the first poll, which races with it, scans from
+	// the boundary block onwards, and a retried backfill scans part of the range again.
+	scans := [][]types.Log{edgeAddedLogs(1, 20), edgeAddedLogs(20, 30), edgeAddedLogs(10, 20)}
+	errs := make([]error, len(scans))
+	var wg sync.WaitGroup
+	for i, logs := range scans {
+		wg.Add(1)
+		go func(i int, logs []types.Log) {
+			defer wg.Done()
+			errs[i] = ingestLogs(w, logs)
+		}(i, logs)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 60, w.eventQueue.Len())
+
+	seen := make(map[eventKey]bool)
+	for _, ev := range w.eventQueue.Items() {
+		key := eventKey{txHash: ev.TxHash, logIndex: ev.LogIndex}
+		require.False(t, seen[key], "event %+v queued twice", key)
+		seen[key] = true
+		require.Equal(t, ev.EdgeAdded.Raw.BlockNumber, ev.BlockNumber)
+	}
+}
+
+func TestWatcher_ingestEvent_overlapWindow(t *testing.T) {
+	w, err := New(nil, nil, nil, nil, time.Second, 1, "alice", nil, time.Second, time.Second, nil, WithScanOverlap(5))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), w.nextScanStart(1, 4))
+	require.Equal(t, uint64(15), w.nextScanStart(1, 20))
+
+	require.NoError(t, ingestLogs(w, edgeAddedLogs(1, 20)))
+	w.ingested.prune(w.nextScanStart(1, 20))
+	// Only the events of the overlap window are remembered.
+	require.Equal(t, 12, w.ingested.len())
+
+	require.NoError(t, ingestLogs(w, edgeAddedLogs(15, 25)))
+	require.Equal(t, 50, w.eventQueue.Len())
+	w.ingested.prune(w.nextScanStart(1, 25))
+	require.Equal(t, 12, w.ingested.len())
+
+	// Events before the window were already ingested, even though they are no longer remembered.
+	require.NoError(t, ingestLogs(w, edgeAddedLogs(10, 12)))
+	require.Equal(t, 50, w.eventQueue.Len())
+}
+
+func TestWatcher_ingestEvent_replayedJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.journal")
+	w, err := New(nil, nil, nil, nil, time.Second, 1, "alice", nil, time.Second, time.Second, nil, WithEventJournal(path))
+	require.NoError(t, err)
+	require.NoError(t, ingestLogs(w, edgeAddedLogs(1, 3)))
+	require.NoError(t, w.eventQueue.Ack())
+	require.NoError(t, w.eventQueue.Close())
+
+	// Scanning restarts from the first block after a restart, but the events pending in the
+	// journal are not queued again. Only the acknowledged event and those of the new block are.
+	restarted, err := New(nil, nil, nil, nil, time.Second, 1, "alice", nil, time.Second, time.Second, nil, WithEventJournal(path))
+	require.NoError(t, err)
+	require.Equal(t, 5, restarted.eventQueue.Len())
+	require.NoError(t, ingestLogs(restarted, edgeAddedLogs(1, 4)))
+	require.Equal(t, 8, restarted.eventQueue.Len())
+}
@@ -0,0 +1,182 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+type watcherEventKind uint8
+
+const (
+	edgeAddedEvent watcherEventKind = iota
+	edgeConfirmedByOneStepProofEvent
+	edgeConfirmedByTimeEvent
+)
+
+// A scanned challenge manager event pending processing by the watcher. Events are
+// ingested into a durable queue by the polling loop and processed independently, so that
+// bursts of events are absorbed and processing can be retried without rescanning the chain.
+type watcherEvent struct {
+	Kind      watcherEventKind                              `json:"kind"`
+	EdgeAdded *challengeV2gen.EdgeChallengeManagerEdgeAdded `json:"edgeAdded,omitempty"`
+	EdgeId    common.Hash                                   `json:"edgeId"`
+	// Identifies the log the event was decoded from, to deduplicate events scanned more than once.
+	TxHash      common.Hash `json:"txHash"`
+	LogIndex    uint        `json:"logIndex"`
+	BlockNumber uint64      `json:"blockNumber"`
+}
+
+// Processes events from the watcher's event queue in the order they were scanned,
+// retrying each one until it succeeds before acknowledging it.
+func (w *Watcher) processQueuedEvents(ctx context.Context) {
+	for {
+		next := w.eventQueue.Peek()
+		if next.IsNone() {
+			// Track the edges shed while events were backlogged, now the backlog is processed.
+			w.restoreShedRivals(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.eventQueue.Notify():
+				continue
+			}
+		}
+		ev := next.Unwrap()
+		if _, err := retry.UntilSucceeds(ctx, func() (bool, error) {
+			return true, w.processEvent(ctx, ev)
+		}); err != nil {
+			// Only returns an error if the context is canceled, in which case the event
+			// remains in the queue and will be processed again once the watcher restarts.
+			return
+		}
+		if err := w.eventQueue.Ack(); err != nil {
+			log.Error("Could not acknowledge processed watcher event", "err", err)
+		}
+	}
+}
+
+func (w *Watcher) processEvent(ctx context.Context, ev *watcherEvent) error {
+	switch ev.Kind {
+	case edgeAddedEvent:
+		if ev.EdgeAdded == nil {
+			return errors.New("edge added event missing its contents")
+		}
+		if err := w.checkForSplitBrain(ctx, ev); err != nil {
+			return err
+		}
+		edgeAdded, err := w.processEdgeAddedEvent(ctx, ev.EdgeAdded)
+		if err != nil {
+			return err
+		}
+		if edgeAdded {
+			edgeAddedCounter.Inc(1)
+		}
+		w.publishEdgeEvent(ctx, ev, protocol.EdgeId{Hash: ev.EdgeAdded.EdgeId}, eventbus.EdgeAdded)
+	case edgeConfirmedByOneStepProofEvent:
+		if err := w.checkForLostOneStepProof(ctx, ev); err != nil {
+			return err
+		}
+		if err := w.processEdgeConfirmation(ctx, protocol.EdgeId{Hash: ev.EdgeId}); err != nil {
+			return err
+		}
+		if err := w.saveEdgeConfirmationToDB(ev); err != nil {
+			return err
+		}
+		edgeConfirmedByOSPCounter.Inc(1)
+		w.publishEdgeEvent(ctx, ev, protocol.EdgeId{Hash: ev.EdgeId}, eventbus.EdgeConfirmedByOneStepProof)
+	case edgeConfirmedByTimeEvent:
+		if err := w.processEdgeConfirmation(ctx, protocol.EdgeId{Hash: ev.EdgeId}); err != nil {
+			return err
+		}
+		if err := w.saveEdgeConfirmationToDB(ev); err != nil {
+			return err
+		}
+		edgeConfirmedByTimeCounter.Inc(1)
+		w.publishEdgeEvent(ctx, ev, protocol.EdgeId{Hash: ev.EdgeId}, eventbus.EdgeConfirmedByTime)
+	default:
+		return errors.Errorf("unknown watcher event kind %d", ev.Kind)
+	}
+	return nil
+}
+
+// Checks whether a level zero edge was staked on by our address, and if so, whether we intended to
+// post it. The intent journal pauses posting if we did not.
+func (w *Watcher) checkForSplitBrain(ctx context.Context, ev *watcherEvent) error {
+	if w.intents == nil || !ev.EdgeAdded.IsLayerZero {
+		return nil
+	}
+	challengeManager, err := w.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return err
+	}
+	edgeOpt, err := challengeManager.GetEdge(ctx, protocol.EdgeId{Hash: ev.EdgeAdded.EdgeId})
+	if err != nil {
+		return err
+	}
+	if edgeOpt.IsNone() {
+		return errors.Errorf("no edge found with id %#x", ev.EdgeAdded.EdgeId)
+	}
+	staker := edgeOpt.Unwrap().MiniStaker()
+	if staker.IsNone() || staker.Unwrap() != w.chain.StakerAddress() {
+		return nil
+	}
+	if !w.intents.ObserveEdge(ev.EdgeAdded.EdgeId, ev.BlockNumber) {
+		log.Error(
+			"Split brain: observed a level zero edge staked on by our address we did not post, is another instance using our key? Posting is paused",
+			"edgeId", ev.EdgeAdded.EdgeId,
+			"txHash", ev.TxHash,
+			"staker", staker.Unwrap(),
+			"validatorName", w.validatorName,
+		)
+	}
+	return nil
+}
+
+// Publishes an edge event observed after the watcher started to the event bus, if one is configured.
+func (w *Watcher) publishEdgeEvent(
+	ctx context.Context,
+	ev *watcherEvent,
+	edgeId protocol.EdgeId,
+	kind eventbus.EdgeEventKind,
+) {
+	fromBlock := w.publishFromBlock.Load()
+	if w.eventBus == nil || fromBlock == 0 || ev.BlockNumber < fromBlock {
+		return
+	}
+	challengeManager, err := w.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		log.Error("Could not get challenge manager to publish edge event", "edgeId", edgeId.Hash, "err", err)
+		return
+	}
+	edgeOpt, err := challengeManager.GetEdge(ctx, edgeId)
+	if err != nil || edgeOpt.IsNone() {
+		log.Error("Could not get edge to publish edge event", "edgeId", edgeId.Hash, "err", err)
+		return
+	}
+	edge := edgeOpt.Unwrap()
+	assertionHash, err := edge.AssertionHash(ctx)
+	if err != nil {
+		log.Error("Could not get assertion hash of edge to publish edge event", "edgeId", edgeId.Hash, "err", err)
+		return
+	}
+	w.eventBus.PublishEdge(eventbus.EdgeEvent{
+		Kind:          kind,
+		EdgeId:        edgeId,
+		AssertionHash: assertionHash,
+		Level:         edge.GetChallengeLevel(),
+		Royal:         w.IsRoyal(assertionHash, edgeId),
+		BlockNumber:   ev.BlockNumber,
+		TxHash:        ev.TxHash,
+		LogIndex:      ev.LogIndex,
+	})
+}
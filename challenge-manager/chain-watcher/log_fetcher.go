@@ -0,0 +1,145 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+var logShardsCounter = metrics.NewRegisteredCounter("arb/validator/watcher/log_shards", nil)
+
+// Fetches the logs of a block range with concurrent requests, each over a shard of the range and,
+// if filtering on many values of an indexed topic, a subset of those values. Requests go through
+// the backend, so they are subject to the rate limit of the chain backend if it has one, and at
+// most a number of them are in flight at once.
+type parallelLogFetcher struct {
+	backend           ethereum.LogFilterer
+	shardBlocks       uint64
+	maxTopicsPerShard int
+	concurrency       int
+}
+
+// A single request of a parallel fetch.
+type logShard struct {
+	fromBlock uint64
+	toBlock   uint64
+	topics    [][]common.Hash
+}
+
+// FilterLogs returns the logs emitted by a contract in the inclusive block range which match the
+// topics, as FilterLogs of the backend would, ordered by their position in the chain.
+func (f *parallelLogFetcher) FilterLogs(
+	ctx context.Context,
+	address common.Address,
+	fromBlock,
+	toBlock uint64,
+	topics [][]common.Hash,
+) ([]types.Log, error) {
+	if fromBlock > toBlock {
+		return nil, errors.Errorf("invalid block range: end %d was < start %d", toBlock, fromBlock)
+	}
+	shards := f.shards(fromBlock, toBlock, topics)
+	logShardsCounter.Inc(int64(len(shards)))
+	results := make([][]types.Log, len(shards))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(f.concurrency, 1))
+	for i, shard := range shards {
+		i, shard := i, shard
+		g.Go(func() error {
+			logs, err := f.backend.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(shard.fromBlock),
+				ToBlock:   new(big.Int).SetUint64(shard.toBlock),
+				Addresses: []common.Address{address},
+				Topics:    shard.topics,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "could not filter logs from block %d to %d", shard.fromBlock, shard.toBlock)
+			}
+			results[i] = logs
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return mergeLogs(results), nil
+}
+
+// Splits the block range into shards of at most shardBlocks blocks and, if a topic position
+// filters on more than maxTopicsPerShard values, the values of the largest such position into
+// subsets of at most that many. Each block shard is requested with every topic subset.
+func (f *parallelLogFetcher) shards(fromBlock, toBlock uint64, topics [][]common.Hash) []logShard {
+	topicSets := [][][]common.Hash{topics}
+	largest := -1
+	for i, values := range topics {
+		if len(values) > f.maxTopicsPerShard && (largest < 0 || len(values) > len(topics[largest])) {
+			largest = i
+		}
+	}
+	if f.maxTopicsPerShard > 0 && largest >= 0 {
+		values := topics[largest]
+		topicSets = nil
+		for start := 0; start < len(values); start += f.maxTopicsPerShard {
+			subset := make([][]common.Hash, len(topics))
+			copy(subset, topics)
+			subset[largest] = values[start:min(start+f.maxTopicsPerShard, len(values))]
+			topicSets = append(topicSets, subset)
+		}
+	}
+	shardBlocks := f.shardBlocks
+	if shardBlocks == 0 {
+		shardBlocks = toBlock - fromBlock + 1
+	}
+	shards := make([]logShard, 0)
+	for start := fromBlock; ; start += shardBlocks {
+		end := toBlock
+		if toBlock-start >= shardBlocks {
+			end = start + shardBlocks - 1
+		}
+		for _, set := range topicSets {
+			shards = append(shards, logShard{fromBlock: start, toBlock: end, topics: set})
+		}
+		if end == toBlock {
+			break
+		}
+	}
+	return shards
+}
+
+// Merges the logs of all shards into a single list ordered by block, transaction, and log index.
+// Logs are deduplicated, as a log can match several topic subsets when it has repeated topics.
+func mergeLogs(results [][]types.Log) []types.Log {
+	seen := make(map[eventKey]bool)
+	merged := make([]types.Log, 0)
+	for _, logs := range results {
+		for _, l := range logs {
+			key := eventKey{txHash: l.TxHash, logIndex: l.Index}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, l)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.BlockNumber != b.BlockNumber {
+			return a.BlockNumber < b.BlockNumber
+		}
+		if a.TxIndex != b.TxIndex {
+			return a.TxIndex < b.TxIndex
+		}
+		return a.Index < b.Index
+	})
+	return merged
+}
@@ -0,0 +1,133 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Filters a fixed set of logs, returning them in reverse order to check results are reordered.
+type mockLogFilterer struct {
+	logs      []types.Log
+	failFrom  uint64
+	lock      sync.Mutex
+	queries   []ethereum.FilterQuery
+	inFlight  atomic.Int32
+	maxFlight atomic.Int32
+}
+
+func (m *mockLogFilterer) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	n := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		prev := m.maxFlight.Load()
+		if n <= prev || m.maxFlight.CompareAndSwap(prev, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	m.lock.Lock()
+	m.queries = append(m.queries, q)
+	m.lock.Unlock()
+	if m.failFrom != 0 && q.FromBlock.Uint64() >= m.failFrom {
+		return nil, errors.New("too many requests")
+	}
+	matched := make([]types.Log, 0)
+	for i := len(m.logs) - 1; i >= 0; i-- {
+		l := m.logs[i]
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() {
+			continue
+		}
+		if matchesTopics(l, q.Topics) {
+			matched = append(matched, l)
+		}
+	}
+	return matched, nil
+}
+
+func (m *mockLogFilterer) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func matchesTopics(l types.Log, topics [][]common.Hash) bool {
+	for i, values := range topics {
+		if len(values) == 0 {
+			continue
+		}
+		if i >= len(l.Topics) {
+			return false
+		}
+		found := false
+		for _, v := range values {
+			found = found || v == l.Topics[i]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParallelLogFetcher(t *testing.T) {
+	ctx := context.Background()
+	eventId := common.BytesToHash([]byte("EdgeAdded"))
+	edgeIds := make([]common.Hash, 10)
+	for i := range edgeIds {
+		edgeIds[i] = common.BigToHash(big.NewInt(int64(i)))
+	}
+	logs := make([]types.Log, 0)
+	for block := uint64(0); block < 100; block++ {
+		for i := uint(0); i < 2; i++ {
+			logs = append(logs, types.Log{
+				BlockNumber: block,
+				TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+				TxIndex:     i,
+				Index:       i,
+				Topics:      []common.Hash{eventId, edgeIds[(block+uint64(i))%10]},
+			})
+		}
+	}
+	topics := [][]common.Hash{{eventId}, edgeIds[:7]}
+
+	sequential := &parallelLogFetcher{backend: &mockLogFilterer{logs: logs}}
+	expected, err := sequential.FilterLogs(ctx, common.Address{}, 10, 89, topics)
+	require.NoError(t, err)
+	require.Len(t, expected, 112)
+
+	backend := &mockLogFilterer{logs: logs}
+	fetcher := &parallelLogFetcher{backend: backend, shardBlocks: 7, maxTopicsPerShard: 3, concurrency: 4}
+	got, err := fetcher.FilterLogs(ctx, common.Address{}, 10, 89, topics)
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+	// 80 blocks in shards of 7 blocks, each with 3 subsets of the 7 edge ids.
+	require.Len(t, backend.queries, 12*3)
+	require.LessOrEqual(t, backend.maxFlight.Load(), int32(4))
+	for i := 1; i < len(got); i++ {
+		require.True(t, got[i-1].BlockNumber < got[i].BlockNumber || got[i-1].Index < got[i].Index)
+	}
+
+	// Logs matching several topic subsets are only returned once.
+	duplicated := &parallelLogFetcher{backend: &mockLogFilterer{logs: logs}, maxTopicsPerShard: 1}
+	got, err = duplicated.FilterLogs(ctx, common.Address{}, 10, 89, [][]common.Hash{{eventId}, {edgeIds[1], edgeIds[1]}})
+	require.NoError(t, err)
+	require.Len(t, got, 16)
+
+	failing := &parallelLogFetcher{backend: &mockLogFilterer{logs: logs, failFrom: 50}, shardBlocks: 10, concurrency: 2}
+	_, err = failing.FilterLogs(ctx, common.Address{}, 0, 99, topics)
+	require.ErrorContains(t, err, "too many requests")
+
+	_, err = fetcher.FilterLogs(ctx, common.Address{}, 10, 9, topics)
+	require.ErrorContains(t, err, "invalid block range")
+}
@@ -0,0 +1,106 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/util/poison"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// WithPoisonFlag poisons the flag, freezing all posting, when an edge we agree with loses to a rival
+// confirmed by one step proof.
+func WithPoisonFlag(flag *poison.Flag) Opt {
+	return func(w *Watcher) {
+		w.poison = flag
+	}
+}
+
+// Checks whether an edge confirmed by one step proof rivals one of the honest edges we track. A one
+// step proof is checked onchain, so the loss of our edge means the history of our state provider is
+// wrong, and the flag is poisoned.
+func (w *Watcher) checkForLostOneStepProof(ctx context.Context, ev *watcherEvent) error {
+	if w.poison == nil || w.watchtower != nil {
+		return nil
+	}
+	challengeManager, err := w.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return err
+	}
+	winningOpt, err := challengeManager.GetEdge(ctx, protocol.EdgeId{Hash: ev.EdgeId})
+	if err != nil {
+		return err
+	}
+	if winningOpt.IsNone() {
+		return errors.Errorf("no edge found with id %#x", ev.EdgeId)
+	}
+	winning := winningOpt.Unwrap()
+	challengeParentAssertionHash, err := winning.AssertionHash(ctx)
+	if err != nil {
+		return err
+	}
+	chal, ok := w.challenges.TryGet(challengeParentAssertionHash)
+	if !ok {
+		return nil
+	}
+	var lost protocol.SpecEdge
+	if err = chal.honestEdgeTree.GetEdges().ForEach(func(edgeId protocol.EdgeId, edge protocol.SpecEdge) error {
+		if lost == nil && edgeId != winning.Id() && edge.MutualId() == winning.MutualId() {
+			lost = edge
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if lost == nil {
+		return nil
+	}
+	log.Error(
+		"Our edge lost to a rival confirmed by one step proof, our state provider is faulty. Posting is frozen until an operator clears the poisoned flag",
+		"lostEdgeId", lost.Id(),
+		"winningEdgeId", winning.Id(),
+		"challengeLevel", winning.GetChallengeLevel(),
+		"challengedAssertionHash", challengeParentAssertionHash.Hash,
+		"blockNumber", ev.BlockNumber,
+		"validatorName", w.validatorName,
+	)
+	return w.poison.Poison(ctx, &poison.Incident{
+		ChallengedAssertionHash: challengeParentAssertionHash.Hash,
+		LostEdgeId:              lost.Id().Hash,
+		WinningEdgeId:           winning.Id().Hash,
+		ChallengeLevel:          uint8(winning.GetChallengeLevel()),
+		BlockNumber:             ev.BlockNumber,
+	}, map[string]any{
+		"validatorName":         w.validatorName,
+		"confirmationTxHash":    ev.TxHash,
+		"lostEdge":              edgeDetails(lost),
+		"winningEdge":           edgeDetails(winning),
+		"numTrackedHonestEdges": chal.honestEdgeTree.GetEdges().NumItems(),
+	})
+}
+
+func edgeDetails(edge protocol.SpecEdge) map[string]any {
+	startHeight, startRoot := edge.StartCommitment()
+	endHeight, endRoot := edge.EndCommitment()
+	details := map[string]any{
+		"id":               edge.Id().Hash,
+		"mutualId":         common.Hash(edge.MutualId()),
+		"challengeLevel":   edge.GetChallengeLevel(),
+		"startHeight":      startHeight,
+		"startHistoryRoot": startRoot,
+		"endHeight":        endHeight,
+		"endHistoryRoot":   endRoot,
+	}
+	if edge.ClaimId().IsSome() {
+		details["claimId"] = common.Hash(edge.ClaimId().Unwrap())
+	}
+	if createdAt, err := edge.CreatedAtBlock(); err == nil {
+		details["createdAtBlock"] = createdAt
+	}
+	return details
+}
@@ -0,0 +1,84 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/containers/threadsafe"
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/OffchainLabs/bold/util/poison"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func mockLevelZeroEdge(ctx context.Context, id string, assertionHash protocol.AssertionHash, mutualId protocol.MutualId) *mocks.MockSpecEdge {
+	edge := &mocks.MockSpecEdge{}
+	edge.On("Id").Return(protocol.EdgeId{Hash: common.BytesToHash([]byte(id))})
+	edge.On("AssertionHash", ctx).Return(assertionHash, nil)
+	edge.On("Status", ctx).Return(protocol.EdgePending, nil)
+	edge.On("HasChildren", ctx).Return(false, nil)
+	edge.On("ClaimId").Return(option.Some(protocol.ClaimId(assertionHash.Hash)))
+	edge.On("OriginId").Return(protocol.OriginId(assertionHash.Hash))
+	edge.On("MutualId").Return(mutualId)
+	edge.On("GetChallengeLevel").Return(protocol.NewBlockChallengeLevel(), nil)
+	edge.On("GetReversedChallengeLevel").Return(protocol.ChallengeLevel(2), nil)
+	edge.On("CreatedAtBlock").Return(uint64(5), nil)
+	edge.On("StartCommitment").Return(protocol.Height(0), common.BytesToHash([]byte("start")))
+	edge.On("EndCommitment").Return(protocol.Height(32), common.BytesToHash([]byte(id)))
+	return edge
+}
+
+func TestWatcher_checkForLostOneStepProof(t *testing.T) {
+	ctx := context.Background()
+	assertionHash := protocol.AssertionHash{Hash: common.BytesToHash([]byte("foo"))}
+	mutualId := protocol.MutualId(common.BytesToHash([]byte("mutual")))
+	honest := mockLevelZeroEdge(ctx, "honest", assertionHash, mutualId)
+	rival := mockLevelZeroEdge(ctx, "rival", assertionHash, mutualId)
+	unrelated := mockLevelZeroEdge(ctx, "unrelated", assertionHash, protocol.MutualId(common.BytesToHash([]byte("other"))))
+
+	mockChain := &mocks.MockProtocol{}
+	mockChallengeManager := &mocks.MockSpecChallengeManager{}
+	mockChain.On("SpecChallengeManager", ctx).Return(mockChallengeManager, nil)
+	mockChain.On("AssertionUnrivaledBlocks", ctx, assertionHash).Return(uint64(1), nil)
+	for _, edge := range []*mocks.MockSpecEdge{honest, rival, unrelated} {
+		mockChallengeManager.On("GetEdge", ctx, edge.Id()).Return(option.Some(protocol.SpecEdge(edge)), nil)
+	}
+	mockManager := &mocks.MockEdgeTracker{}
+	honestEdge := &mockHonestEdge{honest}
+	mockManager.On("TrackEdge", ctx, honestEdge).Return(nil)
+
+	flag, err := poison.Open(filepath.Join(t.TempDir(), "poisoned"))
+	require.NoError(t, err)
+	w := &Watcher{
+		challenges:       threadsafe.NewMap[protocol.AssertionHash, *trackedChallenge](),
+		histChecker:      &mocks.MockStateManager{},
+		chain:            mockChain,
+		edgeManager:      mockManager,
+		numBigStepLevels: 1,
+		poison:           flag,
+	}
+	require.NoError(t, w.AddVerifiedHonestEdge(ctx, honestEdge))
+
+	// Edges which do not rival ours may be confirmed by one step proof.
+	require.NoError(t, w.checkForLostOneStepProof(ctx, &watcherEvent{Kind: edgeConfirmedByOneStepProofEvent, EdgeId: unrelated.Id().Hash}))
+	require.NoError(t, flag.CheckNotPoisoned())
+
+	// Our edge losing to a rival confirmed by one step proof poisons the flag.
+	require.NoError(t, w.checkForLostOneStepProof(ctx, &watcherEvent{
+		Kind:        edgeConfirmedByOneStepProofEvent,
+		EdgeId:      rival.Id().Hash,
+		BlockNumber: 42,
+	}))
+	require.ErrorIs(t, flag.CheckNotPoisoned(), poison.ErrPoisoned)
+	incident := flag.Incident()
+	require.Equal(t, assertionHash.Hash, incident.ChallengedAssertionHash)
+	require.Equal(t, honest.Id().Hash, incident.LostEdgeId)
+	require.Equal(t, rival.Id().Hash, incident.WinningEdgeId)
+	require.Equal(t, uint64(42), incident.BlockNumber)
+}
@@ -0,0 +1,251 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	shedRivalsCounter     = metrics.NewRegisteredCounter("arb/validator/watcher/shed_rivals", nil)
+	restoredRivalsCounter = metrics.NewRegisteredCounter("arb/validator/watcher/restored_shed_rivals", nil)
+	pendingShedGauge      = metrics.NewRegisteredGauge("arb/validator/watcher/shed_rivals_pending", nil)
+	sheddingActiveGauge   = metrics.NewRegisteredGauge("arb/validator/watcher/load_shedding_active", nil)
+)
+
+// EdgePriority ranks observed edges by how critical tracking them is, which decides what the
+// watcher sheds under peak load.
+type EdgePriority uint8
+
+const (
+	// RoyalPathPriority edges may affect the royal path: block challenge root edges, which may be
+	// royal, and rivals of royal edges, which stop their unrivaled timers. Never shed.
+	RoyalPathPriority EdgePriority = iota
+	// ImminentDeadlinePriority edges belong to challenges whose royal root edge is close to being
+	// confirmable, where any delay in tracking them may be costly. Never shed.
+	ImminentDeadlinePriority
+	// RivalSubtreePriority edges are in subtrees of non-royal edges, which cannot threaten the royal
+	// path. Shed under peak load, and tracked once the load subsides.
+	RivalSubtreePriority
+)
+
+func (p EdgePriority) String() string {
+	switch p {
+	case RoyalPathPriority:
+		return "royal_path"
+	case ImminentDeadlinePriority:
+		return "imminent_deadline"
+	case RivalSubtreePriority:
+		return "rival_subtree"
+	default:
+		return fmt.Sprintf("unknown(%d)", p)
+	}
+}
+
+type loadShedding struct {
+	backlogThreshold      int
+	deadlineWindowBlocks  uint64
+	deadlineMargin        func() uint64
+	challengePeriodBlocks atomic.Uint64
+	totalShed             atomic.Uint64
+	totalRestored         atomic.Uint64
+}
+
+// WithLoadShedding sheds the tracking of edges which cannot threaten the royal path while the
+// backlog of scanned events pending processing is at least backlogThreshold, as under an
+// adversary's spam. Edges on the royal path, and the edges of challenges whose royal root edge is
+// within deadlineWindowBlocks of being confirmable, are always processed. Shed edges are tracked
+// once the backlog is processed, or as soon as they rival a royal edge. Disabled by default.
+func WithLoadShedding(backlogThreshold int, deadlineWindowBlocks uint64) Opt {
+	return func(w *Watcher) {
+		if backlogThreshold <= 0 {
+			return
+		}
+		w.shedding = &loadShedding{
+			backlogThreshold:     backlogThreshold,
+			deadlineWindowBlocks: deadlineWindowBlocks,
+		}
+	}
+}
+
+// WithDeadlineMargin widens the deadline window of load shedding to the safety margin of blocks the
+// challenge manager keeps before deadlines, which may tighten as inclusion latencies degrade. Has
+// no effect without load shedding, so must be given after WithLoadShedding.
+func WithDeadlineMargin(margin func() uint64) Opt {
+	return func(w *Watcher) {
+		if w.shedding != nil {
+			w.shedding.deadlineMargin = margin
+		}
+	}
+}
+
+// ShedEdge is an edge whose tracking was shed under peak load.
+type ShedEdge struct {
+	AssertionHash  protocol.AssertionHash
+	EdgeId         protocol.EdgeId
+	ChallengeLevel protocol.ChallengeLevel
+}
+
+// SheddingStatus describes the load shedding of the watcher.
+type SheddingStatus struct {
+	Enabled              bool
+	Active               bool
+	Backlog              int
+	BacklogThreshold     int
+	DeadlineWindowBlocks uint64
+	TotalShed            uint64
+	TotalRestored        uint64
+	// The edges shed and not yet tracked, ordered by assertion hash and edge id.
+	ShedEdges []ShedEdge
+}
+
+// SheddingStatus returns whether the watcher is shedding load, and what it has shed.
+func (w *Watcher) SheddingStatus() *SheddingStatus {
+	status := &SheddingStatus{
+		ShedEdges: make([]ShedEdge, 0),
+	}
+	if w.eventQueue != nil {
+		status.Backlog = w.eventQueue.Len()
+	}
+	if w.shedding == nil {
+		return status
+	}
+	status.Enabled = true
+	status.Active = w.underPeakLoad()
+	status.BacklogThreshold = w.shedding.backlogThreshold
+	status.DeadlineWindowBlocks = w.shedding.deadlineWindowBlocks
+	status.TotalShed = w.shedding.totalShed.Load()
+	status.TotalRestored = w.shedding.totalRestored.Load()
+	_ = w.challenges.ForEach(func(assertionHash protocol.AssertionHash, chal *trackedChallenge) error {
+		return chal.shedRivals.ForEach(func(id protocol.EdgeId, edge protocol.SpecEdge) error {
+			status.ShedEdges = append(status.ShedEdges, ShedEdge{
+				AssertionHash:  assertionHash,
+				EdgeId:         id,
+				ChallengeLevel: edge.GetChallengeLevel(),
+			})
+			return nil
+		})
+	})
+	sort.Slice(status.ShedEdges, func(i, j int) bool {
+		a, b := status.ShedEdges[i], status.ShedEdges[j]
+		if a.AssertionHash != b.AssertionHash {
+			return a.AssertionHash.Hash.Cmp(b.AssertionHash.Hash) < 0
+		}
+		return a.EdgeId.Hash.Cmp(b.EdgeId.Hash) < 0
+	})
+	return status
+}
+
+func (w *Watcher) underPeakLoad() bool {
+	return w.shedding != nil && w.eventQueue != nil && w.eventQueue.Len() >= w.shedding.backlogThreshold
+}
+
+// Ranks an edge observed in a challenge by the hierarchy load is shed by.
+func (w *Watcher) edgePriority(ctx context.Context, chal *trackedChallenge, edge protocol.SpecEdge) EdgePriority {
+	if threatensRoyalPath(chal, edge) {
+		return RoyalPathPriority
+	}
+	if w.deadlineImminent(ctx, chal) {
+		return ImminentDeadlinePriority
+	}
+	return RivalSubtreePriority
+}
+
+func threatensRoyalPath(chal *trackedChallenge, edge protocol.ReadOnlyEdge) bool {
+	if edge.GetChallengeLevel().IsBlockChallengeLevel() && edge.ClaimId().IsSome() {
+		return true
+	}
+	return chal.honestEdgeTree.RivalsRoyalEdge(edge)
+}
+
+// Checks if the royal root edge of a challenge was last computed to be within the deadline window,
+// or the deadline margin if wider, of a challenge period. If the challenge period cannot be read,
+// deadlines are assumed imminent, so that nothing is shed by mistake.
+func (w *Watcher) deadlineImminent(ctx context.Context, chal *trackedChallenge) bool {
+	period := w.shedding.challengePeriodBlocks.Load()
+	if period == 0 {
+		challengeManager, err := w.chain.SpecChallengeManager(ctx)
+		if err != nil {
+			return true
+		}
+		period, err = challengeManager.ChallengePeriodBlocks(ctx)
+		if err != nil {
+			return true
+		}
+		w.shedding.challengePeriodBlocks.Store(period)
+	}
+	window := w.shedding.deadlineWindowBlocks
+	if w.shedding.deadlineMargin != nil {
+		window = max(window, w.shedding.deadlineMargin())
+	}
+	return chal.lastRootTimer.Load()+window >= period
+}
+
+// Checks if the tracking of an edge should be shed, which is the case for rival subtree edges
+// under peak load.
+func (w *Watcher) shouldShedRival(ctx context.Context, chal *trackedChallenge, edge protocol.SpecEdge) bool {
+	if !w.underPeakLoad() {
+		return false
+	}
+	return w.edgePriority(ctx, chal, edge) == RivalSubtreePriority
+}
+
+func (w *Watcher) shedRival(chal *trackedChallenge, edge protocol.SpecEdge, assertionHash protocol.AssertionHash) {
+	chal.shedRivals.Put(edge.Id(), edge)
+	w.shedding.totalShed.Add(1)
+	shedRivalsCounter.Inc(1)
+	pendingShedGauge.Inc(1)
+	sheddingActiveGauge.Update(1)
+	log.Debug(
+		"Shedding the tracking of a rival edge under peak load",
+		"edgeId", fmt.Sprintf("%#x", edge.Id().Hash.Bytes()[:4]),
+		"challengedAssertionHash", fmt.Sprintf("%#x", assertionHash.Hash.Bytes()[:4]),
+		"backlog", w.eventQueue.Len(),
+	)
+}
+
+// Tracks the edges shed under peak load once the load has subsided.
+func (w *Watcher) restoreShedRivals(ctx context.Context) {
+	if w.shedding == nil || w.underPeakLoad() {
+		return
+	}
+	sheddingActiveGauge.Update(0)
+	challenges := make([]*trackedChallenge, 0)
+	_ = w.challenges.ForEach(func(_ protocol.AssertionHash, chal *trackedChallenge) error {
+		challenges = append(challenges, chal)
+		return nil
+	})
+	shed := make([]protocol.SpecEdge, 0)
+	for _, chal := range challenges {
+		edges := make([]protocol.SpecEdge, 0)
+		_ = chal.shedRivals.ForEach(func(_ protocol.EdgeId, edge protocol.SpecEdge) error {
+			edges = append(edges, edge)
+			return nil
+		})
+		for _, edge := range edges {
+			chal.shedRivals.Delete(edge.Id())
+			pendingShedGauge.Dec(1)
+		}
+		shed = append(shed, edges...)
+	}
+	if len(shed) == 0 {
+		return
+	}
+	log.Info("Tracking rival edges shed under peak load", "numEdges", len(shed))
+	for _, edge := range shed {
+		if _, err := w.AddEdge(ctx, edge); err != nil {
+			log.Error("Could not track rival edge shed under peak load", "edgeId", edge.Id().Hash, "err", err)
+			continue
+		}
+		w.shedding.totalRestored.Add(1)
+		restoredRivalsCounter.Inc(1)
+	}
+}
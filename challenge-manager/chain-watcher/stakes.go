@@ -0,0 +1,292 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	edgeStakeLockedGauge   = metrics.GetOrRegisterGaugeFloat64("arb/validator/stake/edge/locked", nil)
+	edgeStakeRefundedGauge = metrics.GetOrRegisterGaugeFloat64("arb/validator/stake/edge/refunded", nil)
+	// Mini-stakes of layer zero edges created with a rival, which go to the excess stake receiver.
+	edgeStakeExcessGauge = metrics.GetOrRegisterGaugeFloat64("arb/validator/stake/edge/sent_to_excess_receiver", nil)
+	// Mini-stakes of the rivals of confirmed layer zero edges, which their stakers lost.
+	edgeStakeForfeitedGauge = metrics.GetOrRegisterGaugeFloat64("arb/validator/stake/edge/attacker_forfeited", nil)
+)
+
+// Filters for layer zero edge creations and edge refunds within a range, and records the
+// mini-stakes they lock and refund in the API database so stake exposure can be tracked over time.
+// Deployments without stakes still emit refunds of the configured amounts, which are not recorded
+// as no tokens move.
+func (w *Watcher) checkForEdgeStakes(
+	ctx context.Context,
+	filterer *challengeV2gen.EdgeChallengeManagerFilterer,
+	filterOpts *bind.FilterOpts,
+) error {
+	if api.IsNil(w.apiDB) {
+		return nil
+	}
+	challengeManager, err := w.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return err
+	}
+	noStake, err := challengeManager.NoStakeMode(ctx)
+	if err != nil {
+		return err
+	}
+	if noStake {
+		return nil
+	}
+	if err = w.loadStakeTotals(); err != nil {
+		return err
+	}
+	timestamps := make(map[uint64]time.Time)
+	managerAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	// The watcher scanned the range for edge creations just before, so the logs are fetched
+	// raw and their decoded events shared through the event bus rather than decoded again.
+	addedLogs, err := w.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(filterOpts.Start),
+		ToBlock:   new(big.Int).SetUint64(*filterOpts.End),
+		Addresses: []common.Address{challengeManager.Address()},
+		Topics:    [][]common.Hash{{managerAbi.Events["EdgeAdded"].ID}},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not scan edge creations from block %d to %d", filterOpts.Start, *filterOpts.End)
+	}
+	for _, l := range addedLogs {
+		event, err := eventbus.DecodeLog(w.eventBus, l, filterer.ParseEdgeAdded)
+		if err != nil {
+			return errors.Wrapf(err, "could not parse edge creation in tx %#x", l.TxHash)
+		}
+		if !event.IsLayerZero {
+			continue
+		}
+		amount, err := challengeManager.StakeAmount(ctx, protocol.ChallengeLevel(event.Level))
+		if err != nil {
+			return errors.Wrapf(err, "could not get stake amount for level %d", event.Level)
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		// Only the first edge of a mutual keeps its stake in the challenge manager.
+		inserted, err := w.recordEdgeStakeEvent(ctx, challengeManager, timestamps, event.EdgeId, api.StakeEventLocked, amount, event.HasRival, l.BlockNumber, l.TxHash, l.Index)
+		if err != nil {
+			return errors.Wrapf(err, "could not scan edge creations from block %d to %d", filterOpts.Start, *filterOpts.End)
+		}
+		if inserted && event.HasRival {
+			w.stakeTotals.excess.add(amount)
+		}
+	}
+	refunded, err := filterer.FilterEdgeRefunded(filterOpts, nil, nil)
+	if err != nil {
+		return err
+	}
+	err = iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeRefunded](refunded, func(event *challengeV2gen.EdgeChallengeManagerEdgeRefunded) error {
+		inserted, err := w.recordEdgeStakeEvent(ctx, challengeManager, timestamps, event.EdgeId, api.StakeEventRefunded, event.StakeAmount, false, event.Raw.BlockNumber, event.Raw.TxHash, event.Raw.Index)
+		if err != nil || !inserted {
+			return err
+		}
+		return w.recordForfeitedRivalStakes(ctx, challengeManager, event.EdgeId, event.StakeAmount)
+	})
+	return errors.Wrapf(err, "could not scan edge refunds from block %d to %d", filterOpts.Start, *filterOpts.End)
+}
+
+func (w *Watcher) recordEdgeStakeEvent(
+	ctx context.Context,
+	challengeManager protocol.SpecChallengeManager,
+	timestamps map[uint64]time.Time,
+	edgeId common.Hash,
+	kind string,
+	amount *big.Int,
+	sentToExcessReceiver bool,
+	blockNum uint64,
+	txHash common.Hash,
+	logIndex uint,
+) (bool, error) {
+	edgeOpt, err := challengeManager.GetEdge(ctx, protocol.EdgeId{Hash: edgeId})
+	if err != nil {
+		return false, err
+	}
+	if edgeOpt.IsNone() {
+		return false, errors.Errorf("no edge found with id %#x", edgeId)
+	}
+	var staker common.Address
+	if edgeOpt.Unwrap().MiniStaker().IsSome() {
+		staker = edgeOpt.Unwrap().MiniStaker().Unwrap()
+	}
+	timestamp, ok := timestamps[blockNum]
+	if !ok {
+		header, err := w.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNum))
+		if err != nil {
+			return false, err
+		}
+		timestamp = time.Unix(int64(header.Time), 0).UTC()
+		timestamps[blockNum] = timestamp
+	}
+	inserted, err := w.apiDB.InsertStakeEvent(&api.JsonStakeEvent{
+		Staker:               staker,
+		Kind:                 kind,
+		Source:               api.StakeSourceEdge,
+		EdgeId:               edgeId,
+		Amount:               amount.String(),
+		BlockNumber:          blockNum,
+		TransactionHash:      txHash,
+		LogIndex:             logIndex,
+		Timestamp:            timestamp,
+		SentToExcessReceiver: sentToExcessReceiver,
+	})
+	if err != nil {
+		return false, err
+	}
+	// Events in a range may be scanned more than once, so only new events are counted.
+	if inserted {
+		if kind == api.StakeEventRefunded {
+			w.stakeTotals.refunded.add(amount)
+		} else {
+			w.stakeTotals.locked.add(amount)
+		}
+	}
+	return inserted, nil
+}
+
+// Counts the mini-stakes the rivals of a refunded edge lost once it was confirmed. Layer zero
+// edges of a mutual share their challenge level, and so the amount of their mini-stakes.
+func (w *Watcher) recordForfeitedRivalStakes(
+	ctx context.Context,
+	challengeManager protocol.SpecChallengeManager,
+	edgeId common.Hash,
+	amount *big.Int,
+) error {
+	edgeOpt, err := challengeManager.GetEdge(ctx, protocol.EdgeId{Hash: edgeId})
+	if err != nil {
+		return err
+	}
+	if edgeOpt.IsNone() {
+		return errors.Errorf("no edge found with id %#x", edgeId)
+	}
+	forfeited, err := w.forfeitedRivalStakes(edgeOpt.Unwrap().MutualId(), edgeId, amount)
+	if err != nil {
+		return err
+	}
+	w.stakeTotals.forfeited.add(forfeited)
+	return nil
+}
+
+// Sums the mini-stakes of the layer zero rivals of a confirmed edge, known from the edges stored.
+func (w *Watcher) forfeitedRivalStakes(mutualId protocol.MutualId, edgeId common.Hash, amount *big.Int) (*big.Int, error) {
+	mutuals, err := w.apiDB.GetEdges(db.WithMutualId(mutualId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get rivals of edge %#x", edgeId)
+	}
+	forfeited := new(big.Int)
+	for _, e := range mutuals {
+		if e.Id == edgeId || e.ClaimId == (common.Hash{}) {
+			continue
+		}
+		forfeited.Add(forfeited, amount)
+	}
+	return forfeited, nil
+}
+
+// A total amount of stake reported by a gauge. It is kept as an integer, as amounts in wei are too
+// large to be summed exactly as floats.
+type stakeTotal struct {
+	gauge metrics.GaugeFloat64
+	total *big.Int
+}
+
+func newStakeTotal(gauge metrics.GaugeFloat64, total *big.Int) *stakeTotal {
+	t := &stakeTotal{gauge: gauge, total: total}
+	t.add(new(big.Int))
+	return t
+}
+
+func (t *stakeTotal) add(amount *big.Int) {
+	t.total.Add(t.total, amount)
+	totalFloat, _ := new(big.Float).SetInt(t.total).Float64()
+	t.gauge.Update(totalFloat)
+}
+
+type edgeStakeTotals struct {
+	locked    *stakeTotal
+	refunded  *stakeTotal
+	excess    *stakeTotal
+	forfeited *stakeTotal
+}
+
+// Computes the totals reported by the stake gauges from the stake events recorded, so that they
+// carry over restarts.
+func (w *Watcher) loadStakeTotals() error {
+	if w.stakeTotals != nil {
+		return nil
+	}
+	sum := func(opts ...db.StakeEventOption) (*big.Int, error) {
+		events, err := w.apiDB.GetStakeEvents(append(opts, db.WithStakeEventSource(api.StakeSourceEdge))...)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get edge stakes")
+		}
+		return db.SumStakeAmounts(events)
+	}
+	locked, err := sum(db.WithStakeEventKind(api.StakeEventLocked))
+	if err != nil {
+		return err
+	}
+	refunded, err := sum(db.WithStakeEventKind(api.StakeEventRefunded))
+	if err != nil {
+		return err
+	}
+	excess, err := sum(db.WithStakeEventKind(api.StakeEventLocked), db.WithStakeEventSentToExcessReceiver())
+	if err != nil {
+		return err
+	}
+	refunds, err := w.apiDB.GetStakeEvents(db.WithStakeEventSource(api.StakeSourceEdge), db.WithStakeEventKind(api.StakeEventRefunded))
+	if err != nil {
+		return errors.Wrap(err, "could not get edge refunds")
+	}
+	forfeited := new(big.Int)
+	for _, refund := range refunds {
+		edges, err := w.apiDB.GetEdges(db.WithId(protocol.EdgeId{Hash: refund.EdgeId}))
+		if err != nil {
+			return errors.Wrapf(err, "could not get refunded edge %#x", refund.EdgeId)
+		}
+		// The rivals of edges which are not stored are not known either.
+		if len(edges) == 0 {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(refund.Amount, 10)
+		if !ok {
+			return errors.Errorf("could not parse stake amount %q", refund.Amount)
+		}
+		rivals, err := w.forfeitedRivalStakes(protocol.MutualId(edges[0].MutualId), refund.EdgeId, amount)
+		if err != nil {
+			return err
+		}
+		forfeited.Add(forfeited, rivals)
+	}
+	w.stakeTotals = &edgeStakeTotals{
+		locked:    newStakeTotal(edgeStakeLockedGauge, locked),
+		refunded:  newStakeTotal(edgeStakeRefundedGauge, refunded),
+		excess:    newStakeTotal(edgeStakeExcessGauge, excess),
+		forfeited: newStakeTotal(edgeStakeForfeitedGauge, forfeited),
+	}
+	return nil
+}
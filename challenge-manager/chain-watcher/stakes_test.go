@@ -0,0 +1,152 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// A backend serving a fixed set of logs, filtered by block range and first topic.
+type stakeLogsBackend struct {
+	bind.ContractBackend
+	logs []types.Log
+}
+
+func (b *stakeLogsBackend) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, l := range b.logs {
+		if l.BlockNumber < query.FromBlock.Uint64() || l.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		for _, topic := range query.Topics[0] {
+			if l.Topics[0] == topic {
+				logs = append(logs, l)
+				break
+			}
+		}
+	}
+	return logs, nil
+}
+
+func (b *stakeLogsBackend) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: number, Time: 1000 + number.Uint64()}, nil
+}
+
+func TestWatcher_checkForEdgeStakes(t *testing.T) {
+	ctx := context.Background()
+	managerAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	challengeManagerAddr := common.BytesToAddress([]byte("challengeManager"))
+	staker := common.BytesToAddress([]byte("staker"))
+	amount := big.NewInt(10)
+
+	edgeAdded := func(id string, blockNum uint64) types.Log {
+		event := managerAbi.Events["EdgeAdded"]
+		data, packErr := event.Inputs.NonIndexed().Pack(common.Hash{}, big.NewInt(32), uint8(0), false, true)
+		require.NoError(t, packErr)
+		return types.Log{
+			Address:     challengeManagerAddr,
+			Topics:      []common.Hash{event.ID, common.BytesToHash([]byte(id)), {}, {}},
+			Data:        data,
+			BlockNumber: blockNum,
+			TxHash:      common.BytesToHash([]byte(id)),
+		}
+	}
+	edgeRefunded := func(id string, blockNum uint64) types.Log {
+		event := managerAbi.Events["EdgeRefunded"]
+		data, packErr := event.Inputs.NonIndexed().Pack(common.Address{}, amount)
+		require.NoError(t, packErr)
+		return types.Log{
+			Address:     challengeManagerAddr,
+			Topics:      []common.Hash{event.ID, common.BytesToHash([]byte(id)), {}},
+			Data:        data,
+			BlockNumber: blockNum,
+			TxHash:      common.BytesToHash([]byte("refund-" + id)),
+			Index:       1,
+		}
+	}
+	backend := &stakeLogsBackend{logs: []types.Log{
+		edgeAdded("before-restart", 5),
+		edgeRefunded("before-restart", 8),
+		edgeAdded("after-restart", 15),
+	}}
+
+	mockChallengeManager := &mocks.MockSpecChallengeManager{MockAddr: challengeManagerAddr}
+	mockChallengeManager.On("NoStakeMode", ctx).Return(false, nil)
+	mockChallengeManager.On("StakeAmount", ctx, protocol.ChallengeLevel(0)).Return(amount, nil)
+	edge := &mocks.MockSpecEdge{}
+	edge.On("MiniStaker").Return(option.Some(staker))
+	edge.On("MutualId").Return(protocol.MutualId{})
+	mockChallengeManager.On("GetEdge", ctx, mock.Anything).Return(option.Some[protocol.SpecEdge](edge), nil)
+	mockChain := &mocks.MockProtocol{}
+	mockChain.On("SpecChallengeManager", ctx).Return(mockChallengeManager, nil)
+
+	apiDB, err := db.NewDatabase(filepath.Join(t.TempDir(), "api.db"))
+	require.NoError(t, err)
+	w := &Watcher{chain: mockChain, backend: backend, apiDB: apiDB}
+	filterer, err := challengeV2gen.NewEdgeChallengeManagerFilterer(challengeManagerAddr, backend)
+	require.NoError(t, err)
+	scan := func(start, end uint64) {
+		require.NoError(t, w.checkForEdgeStakes(ctx, filterer, &bind.FilterOpts{Start: start, End: &end, Context: ctx}))
+	}
+	kinds := func() []string {
+		events, getErr := apiDB.GetStakeEvents()
+		require.NoError(t, getErr)
+		got := make([]string, 0, len(events))
+		for _, e := range events {
+			require.Equal(t, staker, e.Staker)
+			require.Equal(t, amount.String(), e.Amount)
+			got = append(got, e.Kind)
+		}
+		return got
+	}
+
+	// The backfill at startup records the stakes locked and refunded before the restart.
+	scan(0, 10)
+	require.ElementsMatch(t, []string{api.StakeEventLocked, api.StakeEventRefunded}, kinds())
+
+	// Polls record new stakes, and scanning blocks again does not record them twice.
+	scan(10, 20)
+	scan(8, 20)
+	require.ElementsMatch(t, []string{api.StakeEventLocked, api.StakeEventRefunded, api.StakeEventLocked}, kinds())
+	require.Equal(t, big.NewInt(20), w.stakeTotals.locked.total)
+	require.Equal(t, big.NewInt(10), w.stakeTotals.refunded.total)
+
+	// The totals reported by the gauges are computed from the stakes recorded after a restart.
+	w = &Watcher{chain: mockChain, backend: backend, apiDB: apiDB}
+	scan(20, 20)
+	require.Equal(t, big.NewInt(20), w.stakeTotals.locked.total)
+	require.Equal(t, big.NewInt(10), w.stakeTotals.refunded.total)
+	require.Equal(t, new(big.Int), w.stakeTotals.excess.total)
+
+	// Nothing is recorded for deployments without stakes.
+	noStakeManager := &mocks.MockSpecChallengeManager{MockAddr: challengeManagerAddr}
+	noStakeManager.On("NoStakeMode", ctx).Return(true, nil)
+	noStakeChain := &mocks.MockProtocol{}
+	noStakeChain.On("SpecChallengeManager", ctx).Return(noStakeManager, nil)
+	emptyDB, err := db.NewDatabase(filepath.Join(t.TempDir(), "empty.db"))
+	require.NoError(t, err)
+	w = &Watcher{chain: noStakeChain, backend: backend, apiDB: emptyDB}
+	end := uint64(20)
+	require.NoError(t, w.checkForEdgeStakes(ctx, filterer, &bind.FilterOpts{Start: 0, End: &end, Context: ctx}))
+	events, err := emptyDB.GetStakeEvents()
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
@@ -0,0 +1,92 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+type codeReader interface {
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// FindDeploymentBlock finds the block a contract was deployed at by binary searching for the
+// first block at which it has code, up to the latest block. This requires the backend to serve
+// historical state, so it will fail against a pruned node.
+func FindDeploymentBlock(ctx context.Context, backend codeReader, addr common.Address, latest uint64) (uint64, error) {
+	hasCode := func(block uint64) (bool, error) {
+		code, err := backend.CodeAt(ctx, addr, new(big.Int).SetUint64(block))
+		if err != nil {
+			return false, errors.Wrapf(err, "could not get code of %#x at block %d", addr, block)
+		}
+		return len(code) > 0, nil
+	}
+	deployed, err := hasCode(latest)
+	if err != nil {
+		return 0, err
+	}
+	if !deployed {
+		return 0, fmt.Errorf("no code deployed at %#x as of block %d", addr, latest)
+	}
+	lo, hi := uint64(0), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		deployed, err = hasCode(mid)
+		if err != nil {
+			return 0, err
+		}
+		if deployed {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// Gets the block from which to scan for events of a challenge manager deployment. An override
+// configured for the deployment takes precedence, followed by a start block recorded by a previous
+// startup, and otherwise the deployment block is discovered from the chain. The result is recorded
+// in the database, so subsequent startups skip discovery.
+func (w *Watcher) deploymentStartBlock(ctx context.Context, challengeManager common.Address, latest uint64) (uint64, error) {
+	if override, ok := w.startBlockOverrides[challengeManager]; ok {
+		w.recordDeploymentStartBlock(challengeManager, override, api.DeploymentStartBlockOverride)
+		return override, nil
+	}
+	if !api.IsNil(w.apiDB) {
+		recorded, err := w.apiDB.GetDeploymentStartBlock(challengeManager)
+		if err != nil {
+			log.Warn("Could not read recorded deployment start block", "challengeManager", challengeManager, "err", err)
+		} else if recorded.IsSome() {
+			return recorded.Unwrap().StartBlock, nil
+		}
+	}
+	startBlock, err := FindDeploymentBlock(ctx, w.backend, challengeManager, latest)
+	if err != nil {
+		return 0, err
+	}
+	log.Info("Discovered challenge manager deployment block", "challengeManager", challengeManager, "block", startBlock)
+	w.recordDeploymentStartBlock(challengeManager, startBlock, api.DeploymentStartBlockDiscovered)
+	return startBlock, nil
+}
+
+func (w *Watcher) recordDeploymentStartBlock(challengeManager common.Address, startBlock uint64, source string) {
+	if api.IsNil(w.apiDB) {
+		return
+	}
+	if err := w.apiDB.InsertDeploymentStartBlock(&api.JsonDeploymentStartBlock{
+		ChallengeManager: challengeManager,
+		StartBlock:       startBlock,
+		Source:           source,
+	}); err != nil {
+		log.Warn("Could not record deployment start block", "challengeManager", challengeManager, "err", err)
+	}
+}
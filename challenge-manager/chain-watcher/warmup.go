@@ -0,0 +1,163 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/math"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	warmupCommitmentsCounter = metrics.NewRegisteredCounter("arb/validator/watcher/warmup/commitments", nil)
+	warmupFailuresCounter    = metrics.NewRegisteredCounter("arb/validator/watcher/warmup/failures", nil)
+	warmupDurationGauge      = metrics.NewRegisteredGauge("arb/validator/watcher/warmup/duration_ms", nil)
+)
+
+// WithCacheWarmup computes the history commitments the royal edges of open challenges in the API
+// database need next when the watcher first starts, before it resumes tracking edges. This fills the
+// commitment and machine hash caches of the state provider after a restart mid-challenge, so the
+// first actions of the trackers are not slowed down by cold caches. Warming up gives up after
+// the timeout, which is unbounded if zero.
+func WithCacheWarmup(committer l2stateprovider.GeneralHistoryCommitter, timeout time.Duration) Opt {
+	return func(w *Watcher) {
+		w.warmupCommitter = committer
+		w.warmupTimeout = timeout
+	}
+}
+
+// The assertion metadata of the history commitments of a challenge.
+type warmupChallenge struct {
+	fromBatch      l2stateprovider.Batch
+	toBatch        l2stateprovider.Batch
+	wasmModuleRoot common.Hash
+}
+
+// Warms up the caches of the state provider for the royal edges of open challenges, which a tracker
+// will bisect next. Failures are logged rather than returned, as warming up is only an optimization.
+func (w *Watcher) warmUpCaches(ctx context.Context) {
+	if w.warmupCommitter == nil || api.IsNil(w.apiDB) {
+		return
+	}
+	if w.warmupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.warmupTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	edges, err := w.apiDB.GetEdges(
+		db.WithRoyal(true),
+		db.WithRival(true),
+		db.WithEdgeStatus(protocol.EdgePending),
+	)
+	if err != nil {
+		log.Error("Could not get open challenge edges to warm up caches for", "err", err)
+		return
+	}
+	challenges := make(map[common.Hash]option.Option[*warmupChallenge])
+	numWarmed := 0
+	for _, edge := range edges {
+		if ctx.Err() != nil {
+			log.Warn("Stopped warming up caches", "warmed", numWarmed, "took", time.Since(start), "err", ctx.Err())
+			return
+		}
+		// Bisected edges and edges at a one step fork need no further commitments at their level.
+		if edge.HasChildren || edge.HasLengthOneRival || edge.EndHeight-edge.StartHeight < 2 {
+			continue
+		}
+		challenge, ok := challenges[edge.AssertionHash]
+		if !ok {
+			challenge = w.warmupChallengeFor(ctx, protocol.AssertionHash{Hash: edge.AssertionHash})
+			challenges[edge.AssertionHash] = challenge
+		}
+		if challenge.IsNone() {
+			continue
+		}
+		if err := w.warmUpEdge(ctx, edge, challenge.Unwrap()); err != nil {
+			warmupFailuresCounter.Inc(1)
+			log.Warn("Could not warm up caches for edge", "edgeId", edge.Id, "err", err)
+			continue
+		}
+		numWarmed++
+	}
+	took := time.Since(start)
+	warmupDurationGauge.Update(took.Milliseconds())
+	if numWarmed > 0 {
+		log.Info("Warmed up caches for open challenges", "edges", numWarmed, "took", took)
+	}
+}
+
+// Gets the assertion metadata of a challenge from the claim of its royal block challenge root edge,
+// in the same way the metadata of edge trackers is read.
+func (w *Watcher) warmupChallengeFor(ctx context.Context, assertionHash protocol.AssertionHash) option.Option[*warmupChallenge] {
+	roots, err := w.apiDB.GetEdges(
+		db.WithEdgeAssertionHash(assertionHash),
+		db.WithChallengeLevel(protocol.NewBlockChallengeLevel().Uint8()),
+		db.WithRootEdges(),
+		db.WithRoyal(true),
+		db.WithLimit(1),
+	)
+	if err != nil || len(roots) == 0 {
+		log.Warn("Could not find royal block challenge root edge to warm up caches for", "assertionHash", assertionHash.Hash, "err", err)
+		return option.None[*warmupChallenge]()
+	}
+	claimed, err := w.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: roots[0].ClaimId})
+	if err != nil {
+		log.Warn("Could not read claimed assertion to warm up caches for", "assertionHash", roots[0].ClaimId, "err", err)
+		return option.None[*warmupChallenge]()
+	}
+	parent, err := w.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: claimed.ParentAssertionHash})
+	if err != nil {
+		log.Warn("Could not read challenged assertion to warm up caches for", "assertionHash", claimed.ParentAssertionHash, "err", err)
+		return option.None[*warmupChallenge]()
+	}
+	return option.Some(&warmupChallenge{
+		fromBatch:      l2stateprovider.Batch(protocol.GoGlobalStateFromSolidity(claimed.BeforeState.GlobalState).Batch),
+		toBatch:        l2stateprovider.Batch(protocol.GoGlobalStateFromSolidity(claimed.AfterState.GlobalState).Batch),
+		wasmModuleRoot: parent.WasmModuleRoot,
+	})
+}
+
+// Computes the history commitments a tracker needs to bisect an edge, which are up to the bisection
+// point for the commitment and up to the end of the edge for its prefix proof.
+func (w *Watcher) warmUpEdge(ctx context.Context, edge *api.JsonEdge, challenge *warmupChallenge) error {
+	bisectTo, err := math.Bisect(edge.StartHeight, edge.EndHeight)
+	if err != nil {
+		return err
+	}
+	originHeights := make([]l2stateprovider.Height, 0)
+	if edge.ChallengeLevel != protocol.NewBlockChallengeLevel().Uint8() {
+		heights, err := w.chain.TopLevelClaimHeights(ctx, protocol.EdgeId{Hash: edge.Id})
+		if err != nil {
+			return errors.Wrap(err, "could not get origin heights")
+		}
+		for _, height := range heights.ChallengeOriginHeights {
+			originHeights = append(originHeights, l2stateprovider.Height(height))
+		}
+	}
+	for _, upTo := range []uint64{bisectTo, edge.EndHeight} {
+		if _, err := w.warmupCommitter.HistoryCommitment(ctx, &l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              challenge.wasmModuleRoot,
+			FromBatch:                   challenge.fromBatch,
+			ToBatch:                     challenge.toBatch,
+			UpperChallengeOriginHeights: originHeights,
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(l2stateprovider.Height(upTo)),
+		}); err != nil {
+			return errors.Wrapf(err, "could not compute history commitment up to height %d", upTo)
+		}
+		warmupCommitmentsCounter.Inc(1)
+	}
+	return nil
+}
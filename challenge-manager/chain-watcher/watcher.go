@@ -21,10 +21,15 @@ import (
 	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
 	challengetree "github.com/OffchainLabs/bold/challenge-manager/challenge-tree"
 	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/containers/queue"
 	"github.com/OffchainLabs/bold/containers/threadsafe"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	retry "github.com/OffchainLabs/bold/runtime"
 	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/OffchainLabs/bold/util/intents"
+	"github.com/OffchainLabs/bold/util/poison"
 	"github.com/OffchainLabs/bold/util/stopwaiter"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -40,6 +45,8 @@ var (
 	edgeConfirmedByOSPCounter               = metrics.NewRegisteredCounter("arb/validator/watcher/confirmed_by_osp", nil)
 	errorConfirmingAssertionByWinnerCounter = metrics.NewRegisteredCounter("arb/validator/watcher/error_confirming_assertion_by_winner", nil)
 	assertionConfirmedCounter               = metrics.GetOrRegisterCounter("arb/validator/scanner/assertion_confirmed", nil)
+	deferredRivalsCounter                   = metrics.NewRegisteredCounter("arb/validator/watcher/deferred_rivals", nil)
+	promotedRivalsCounter                   = metrics.NewRegisteredCounter("arb/validator/watcher/promoted_rivals", nil)
 )
 
 // EdgeManager provides a method to track edges, via edge tracker goroutines.
@@ -55,6 +62,13 @@ type EdgeManager interface {
 type trackedChallenge struct {
 	honestEdgeTree                 *challengetree.RoyalChallengeTree
 	confirmedLevelZeroEdgeClaimIds *threadsafe.Map[protocol.ClaimId, protocol.EdgeId]
+	numTrackedRivals               atomic.Uint64
+	deferredRivals                 *threadsafe.Map[protocol.EdgeId, protocol.SpecEdge]
+	shedRivals                     *threadsafe.Map[protocol.EdgeId, protocol.SpecEdge]
+	// The edges we disagree with, only kept by watchtowers.
+	evilEdges *threadsafe.Map[protocol.EdgeId, protocol.SpecEdge]
+	// The inherited timer of the royal root edge when it was last computed, in blocks.
+	lastRootTimer atomic.Uint64
 }
 
 // The Watcher implements a service in the validator runtime
@@ -76,10 +90,97 @@ type Watcher struct {
 	numBigStepLevels                    uint8
 	initialSyncCompleted                atomic.Bool
 	apiDB                               db.Database
+	stakeTotals                         *edgeStakeTotals
 	assertionConfirmingInterval         time.Duration
 	averageTimeForBlockCreation         time.Duration
 	evilEdgesByLevel                    *threadsafe.Map[protocol.ChallengeLevel, *threadsafe.Set[protocol.EdgeId]]
 	trackChallengeParentAssertionHashes []protocol.AssertionHash // Only track challenges for these parent assertion hashes. Track all if empty / nil.
+	eventQueue                          *queue.Durable[*watcherEvent]
+	eventJournalPath                    string
+	startBlockOverrides                 map[common.Address]uint64
+	maxTrackedRivalsPerChallenge        uint64
+	shedding                            *loadShedding
+	ingested                            *ingestedEvents
+	scanOverlapBlocks                   uint64
+	logFetcher                          *parallelLogFetcher
+	eventBus                            *eventbus.Bus
+	intents                             *intents.Journal
+	poison                              *poison.Flag
+	warmupCommitter                     l2stateprovider.GeneralHistoryCommitter
+	warmupTimeout                       time.Duration
+	watchtower                          *watchtower
+	// The first block whose events are published to the event bus, set once the range of blocks
+	// to scan at startup is known, so historical events are not published again.
+	publishFromBlock atomic.Uint64
+}
+
+type Opt func(*Watcher)
+
+// WithEventJournal persists scanned events that are pending processing to a file,
+// so they are replayed if the watcher is restarted before processing them.
+func WithEventJournal(path string) Opt {
+	return func(w *Watcher) {
+		w.eventJournalPath = path
+	}
+}
+
+// WithStartBlockOverride sets the block from which to scan for events of the challenge manager
+// deployed at the given address, instead of discovering its deployment block from the chain.
+func WithStartBlockOverride(challengeManager common.Address, startBlock uint64) Opt {
+	return func(w *Watcher) {
+		w.startBlockOverrides[challengeManager] = startBlock
+	}
+}
+
+// WithMaxTrackedRivalsPerChallenge limits the number of non-royal edges the watcher adds to the
+// challenge tree of each challenge. Once the limit is reached, further non-royal edges are deferred
+// unless they rival a royal edge, and are only added if a royal edge they rival is observed later.
+// This bounds the work spent on spam edges, which cannot affect the royal path otherwise.
+// Defaults to 0, which is unlimited.
+func WithMaxTrackedRivalsPerChallenge(limit uint64) Opt {
+	return func(w *Watcher) {
+		w.maxTrackedRivalsPerChallenge = limit
+	}
+}
+
+// WithScanOverlap makes each poll for events rescan this many blocks before the last scanned
+// block, in case logs of recent blocks were not yet indexed by the node when they were first
+// scanned. Events seen again are deduplicated. Defaults to 0, which only rescans the last
+// scanned block.
+func WithScanOverlap(blocks uint64) Opt {
+	return func(w *Watcher) {
+		w.scanOverlapBlocks = blocks
+	}
+}
+
+// WithParallelLogFetching scans for edge additions with concurrent requests, each over a shard of
+// at most shardBlocks blocks and, if filtering on more than maxTopicsPerShard values of an indexed
+// topic, a subset of them, with at most concurrency requests in flight. Requests are subject to the
+// rate limit of the chain backend, if any. By default, each range is scanned with a single request.
+func WithParallelLogFetching(shardBlocks uint64, maxTopicsPerShard, concurrency int) Opt {
+	return func(w *Watcher) {
+		w.logFetcher = &parallelLogFetcher{
+			shardBlocks:       shardBlocks,
+			maxTopicsPerShard: maxTopicsPerShard,
+			concurrency:       concurrency,
+		}
+	}
+}
+
+// WithEventBus publishes the edge additions and confirmations observed after startup to the
+// event bus.
+func WithEventBus(bus *eventbus.Bus) Opt {
+	return func(w *Watcher) {
+		w.eventBus = bus
+	}
+}
+
+// WithIntentJournal checks the level zero edges observed from our address against the journal of
+// those we intended to post, pausing posting on any we did not.
+func WithIntentJournal(journal *intents.Journal) Opt {
+	return func(w *Watcher) {
+		w.intents = journal
+	}
 }
 
 // New initializes a watcher service for frequently scanning the chain
@@ -96,11 +197,12 @@ func New(
 	assertionConfirmingInterval time.Duration,
 	averageTimeForBlockCreation time.Duration,
 	trackChallengeParentAssertionHashes []protocol.AssertionHash,
+	opts ...Opt,
 ) (*Watcher, error) {
 	if interval == 0 {
 		return nil, errors.New("chain watcher polling interval must be greater than 0")
 	}
-	return &Watcher{
+	w := &Watcher{
 		chain:                               chain,
 		edgeManager:                         edgeManager,
 		pollEventsInterval:                  interval,
@@ -114,7 +216,30 @@ func New(
 		averageTimeForBlockCreation:         averageTimeForBlockCreation,
 		evilEdgesByLevel:                    threadsafe.NewMap[protocol.ChallengeLevel, *threadsafe.Set[protocol.EdgeId]](threadsafe.MapWithMetric[protocol.ChallengeLevel, *threadsafe.Set[protocol.EdgeId]]("evilEdgesByLevel")),
 		trackChallengeParentAssertionHashes: trackChallengeParentAssertionHashes,
-	}, nil
+		startBlockOverrides:                 make(map[common.Address]uint64),
+		ingested:                            newIngestedEvents(),
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	if w.logFetcher != nil {
+		w.logFetcher.backend = backend
+	}
+	eventQueue, err := queue.NewDurable[*watcherEvent](
+		queue.WithJournal[*watcherEvent](w.eventJournalPath),
+		queue.WithMetric[*watcherEvent]("watcher_events"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	w.eventQueue = eventQueue
+	// Replayed events will be scanned again, as scanning restarts from the first block.
+	for _, ev := range eventQueue.Items() {
+		if ev.TxHash != (common.Hash{}) {
+			w.ingested.markIngested(eventKey{txHash: ev.TxHash, logIndex: ev.LogIndex}, ev.BlockNumber)
+		}
+	}
+	return w, nil
 }
 
 // HonestBlockChallengeRootEdge gets the honest block challenge root edge for a given challenge
@@ -175,24 +300,44 @@ func (w *Watcher) IsSynced() bool {
 	return w.initialSyncCompleted.Load()
 }
 
+// StopAndWait stops processing queued events, then closes the journal of the event queue.
+func (w *Watcher) StopAndWait() {
+	w.StopWaiter.StopAndWait()
+	if w.eventQueue == nil {
+		return
+	}
+	if err := w.eventQueue.Close(); err != nil {
+		log.Error("Could not close watcher event queue", "err", err)
+	}
+}
+
 // Start watching the chain via a polling mechanism for all edge added and confirmation events
 // in order to process some of this data into internal representations for confirmation purposes.
 func (w *Watcher) Start(ctx context.Context) {
-	w.StopWaiter.Start(ctx, w)
+	// The watcher is started again if it is restarted after panicking.
+	if !w.Started() {
+		w.StopWaiter.Start(ctx, w)
+		// Edges are tracked as queued events are processed, so caches are warmed up before.
+		w.warmUpCaches(ctx)
+		w.LaunchThread(w.processQueuedEvents)
+	}
 	scanRange, err := retry.UntilSucceeds(ctx, func() (filterRange, error) {
 		return w.getStartEndBlockNum(ctx)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get start and end block num", "err", err)
 		return
 	}
 	fromBlock := scanRange.startBlockNum
 	toBlock := scanRange.endBlockNum
+	if w.publishFromBlock.Load() == 0 {
+		w.publishFromBlock.Store(toBlock + 1)
+	}
 
 	// Get a challenge manager instance and filterer.
 	challengeManager, err := retry.UntilSucceeds(ctx, func() (protocol.SpecChallengeManager, error) {
 		return w.chain.SpecChallengeManager(ctx)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get spec challenge manager", "err", err)
 		return
@@ -212,28 +357,34 @@ func (w *Watcher) Start(ctx context.Context) {
 
 	// Checks for different events right away before we start polling.
 	_, err = retry.UntilSucceeds(ctx, func() (bool, error) {
-		return true, w.checkForEdgeAdded(ctx, filterer, filterOpts)
-	})
+		return true, w.checkForEdgeAdded(ctx, filterer, challengeManager.Address(), filterOpts)
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not check for edge added", "err", err)
 		return
 	}
 	_, err = retry.UntilSucceeds(ctx, func() (bool, error) {
 		return true, w.checkForEdgeConfirmedByOneStepProof(ctx, filterer, filterOpts)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not check for edge confirmed by osp", "err", err)
 		return
 	}
 	_, err = retry.UntilSucceeds(ctx, func() (bool, error) {
 		return true, w.checkForEdgeConfirmedByTime(ctx, filterer, filterOpts)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not check for edge confirmed by time", "err", err)
 		return
 	}
+	// Stakes locked and refunded before a restart are recorded from the backfilled range, so that
+	// the stake history is complete.
+	if err = w.checkForEdgeStakes(ctx, filterer, filterOpts); err != nil {
+		log.Error("Could not check for edge stakes", "err", err)
+	}
 
-	fromBlock = toBlock
+	lastScanned := toBlock
+	w.ingested.prune(w.nextScanStart(scanRange.startBlockNum, lastScanned))
 	ticker := time.NewTicker(w.pollEventsInterval)
 	defer ticker.Stop()
 	for {
@@ -249,15 +400,18 @@ func (w *Watcher) Start(ctx context.Context) {
 				continue
 			}
 			toBlock := latestBlock.Number.Uint64()
-			if fromBlock == toBlock {
-				w.initialSyncCompleted.Store(true)
-				log.Info("BOLD chain event scraper caught up to latest block", "blockNum", toBlock)
+			if lastScanned == toBlock {
+				// We are only synced once all the scanned events have also been processed.
+				if w.eventQueue.Len() == 0 {
+					w.initialSyncCompleted.Store(true)
+					log.Info("BOLD chain event scraper caught up to latest block", "blockNum", toBlock)
+				}
 				continue
 			}
 			// Get a challenge manager instance and filterer.
 			challengeManager, err := retry.UntilSucceeds(ctx, func() (protocol.SpecChallengeManager, error) {
 				return w.chain.SpecChallengeManager(ctx)
-			})
+			}, retry.WithPolicy(retry.RPCRead))
 			if err != nil {
 				log.Error("Could not get spec challenge manager", "err", err)
 				return
@@ -270,11 +424,11 @@ func (w *Watcher) Start(ctx context.Context) {
 				return
 			}
 			filterOpts := &bind.FilterOpts{
-				Start:   fromBlock,
+				Start:   w.nextScanStart(scanRange.startBlockNum, lastScanned),
 				End:     &toBlock,
 				Context: ctx,
 			}
-			if err = w.checkForEdgeAdded(ctx, filterer, filterOpts); err != nil {
+			if err = w.checkForEdgeAdded(ctx, filterer, challengeManager.Address(), filterOpts); err != nil {
 				log.Error("Could not check for edge added", "err", err)
 				continue
 			}
@@ -286,13 +440,29 @@ func (w *Watcher) Start(ctx context.Context) {
 				log.Error("Could not check for edge confirmed by time", "err", err)
 				continue
 			}
-			fromBlock = toBlock
+			// Stake accounting is not needed for the protocol to make progress, so failures
+			// are logged rather than causing the range to be scanned again.
+			if err = w.checkForEdgeStakes(ctx, filterer, filterOpts); err != nil {
+				log.Error("Could not check for edge stakes", "err", err)
+			}
+			w.checkEvilEdgesOnTrack(ctx, toBlock)
+			lastScanned = toBlock
+			w.ingested.prune(w.nextScanStart(scanRange.startBlockNum, lastScanned))
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// The first block of the poll following a scan up to the last scanned block, which is always
+// scanned again, along with the configured overlap before it.
+func (w *Watcher) nextScanStart(firstBlock, lastScanned uint64) uint64 {
+	if lastScanned < firstBlock+w.scanOverlapBlocks {
+		return firstBlock
+	}
+	return lastScanned - w.scanOverlapBlocks
+}
+
 // GetRoyalEdges returns all royal, tracked edges in the watcher by assertion hash.
 func (w *Watcher) GetRoyalEdges(ctx context.Context) (map[protocol.AssertionHash][]*api.JsonTrackedRoyalEdge, error) {
 	header, err := w.chain.Backend().HeaderByNumber(ctx, w.chain.GetDesiredRpcHeadBlockNumber())
@@ -456,7 +626,12 @@ func (w *Watcher) ComputeRootInheritedTimer(
 	if !blockHeader.Number.IsUint64() {
 		return 0, errors.New("block number is not uint64")
 	}
-	return chal.honestEdgeTree.ComputeRootInheritedTimer(ctx, challengedAssertionHash, blockHeader.Number.Uint64())
+	timer, err := chal.honestEdgeTree.ComputeRootInheritedTimer(ctx, challengedAssertionHash, blockHeader.Number.Uint64())
+	if err != nil {
+		return 0, err
+	}
+	chal.lastRootTimer.Store(uint64(timer))
+	return timer, nil
 }
 
 // AddVerifiedHonestEdge adds an edge known to be honest to the chain watcher's internally
@@ -471,17 +646,7 @@ func (w *Watcher) AddVerifiedHonestEdge(ctx context.Context, edge protocol.Verif
 	// for the edge's assertion hash, it adds an entry to the map.
 	chal, ok := w.challenges.TryGet(assertionHash)
 	if !ok {
-		tree := challengetree.New(
-			assertionHash,
-			w.chain,
-			w.histChecker,
-			w.numBigStepLevels,
-			w.validatorName,
-		)
-		chal = &trackedChallenge{
-			honestEdgeTree:                 tree,
-			confirmedLevelZeroEdgeClaimIds: threadsafe.NewMap[protocol.ClaimId, protocol.EdgeId](threadsafe.MapWithMetric[protocol.ClaimId, protocol.EdgeId]("confirmedLevelZeroEdgeClaimIds")),
-		}
+		chal = w.newTrackedChallenge(assertionHash)
 		w.challenges.Put(assertionHash, chal)
 	}
 	// Add the edge to a local challenge tree of honest edges and, if needed,
@@ -503,12 +668,23 @@ func (w *Watcher) AddVerifiedHonestEdge(ctx context.Context, edge protocol.Verif
 		log.Error("Could not add verified honest edge to local cache", "err", err)
 		return errors.Wrap(err, "could not add honest edge to challenge tree")
 	}
+	if err = w.promoteDeferredRivals(ctx, chal, edge); err != nil {
+		return err
+	}
 	go func() {
 		if _, err = retry.UntilSucceeds(ctx, func() (bool, error) {
 			if innerErr := w.saveEdgeToDB(ctx, edge, true /* is royal */); innerErr != nil {
 				log.Error("Could not save edge to db", "err", innerErr)
 				return false, innerErr
 			}
+			// An adopted child may have been saved when the rival bisection created it, before we
+			// knew it to be royal, and saving it again leaves the existing row untouched.
+			if adopted, ok := edge.(protocol.AdoptedEdge); ok && !api.IsNil(w.apiDB) {
+				if innerErr := w.apiDB.UpdateAdoptedLowerChild(adopted.AdoptedBy().Hash, edge.Id().Hash); innerErr != nil {
+					log.Error("Could not save adopted lower child to db", "err", innerErr)
+					return false, innerErr
+				}
+			}
 			return false, nil
 		}); err != nil {
 			log.Error("Could not save edge to db", "err", err)
@@ -521,34 +697,57 @@ func (w *Watcher) AddVerifiedHonestEdge(ctx context.Context, edge protocol.Verif
 func (w *Watcher) checkForEdgeAdded(
 	ctx context.Context,
 	filterer *challengeV2gen.EdgeChallengeManagerFilterer,
+	challengeManagerAddr common.Address,
 	filterOpts *bind.FilterOpts,
 ) error {
+	if w.logFetcher != nil {
+		return w.fetchEdgeAdded(ctx, filterer, challengeManagerAddr, filterOpts)
+	}
 	it, err := filterer.FilterEdgeAdded(filterOpts, nil, nil, nil)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err = it.Close(); err != nil {
-			log.Error("Could not close filter iterator", "err", err)
-		}
-	}()
-	for it.Next() {
-		if it.Error() != nil {
-			return errors.Wrapf(
-				err,
-				"got iterator error when scanning edge creations from block %d to %d",
-				filterOpts.Start,
-				*filterOpts.End,
-			)
-		}
-		edgeAdded, processErr := retry.UntilSucceeds(ctx, func() (bool, error) {
-			return w.processEdgeAddedEvent(ctx, it.Event)
-		})
-		if processErr != nil {
-			return processErr
+	return iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeAdded](it, func(event *challengeV2gen.EdgeChallengeManagerEdgeAdded) error {
+		eventbus.RememberLog(w.eventBus, event.Raw, event)
+		return w.ingestEvent(&watcherEvent{
+			Kind:      edgeAddedEvent,
+			EdgeAdded: event,
+		}, event.Raw)
+	})
+}
+
+// Fetches the edge added events within a range with the parallel log fetcher, ingesting them in
+// the order they were emitted.
+func (w *Watcher) fetchEdgeAdded(
+	ctx context.Context,
+	filterer *challengeV2gen.EdgeChallengeManagerFilterer,
+	challengeManagerAddr common.Address,
+	filterOpts *bind.FilterOpts,
+) error {
+	managerAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	logs, err := w.logFetcher.FilterLogs(
+		ctx,
+		challengeManagerAddr,
+		filterOpts.Start,
+		*filterOpts.End,
+		[][]common.Hash{{managerAbi.Events["EdgeAdded"].ID}},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "could not fetch edge creations from block %d to %d", filterOpts.Start, *filterOpts.End)
+	}
+	for _, l := range logs {
+		event, err := eventbus.DecodeLog(w.eventBus, l, filterer.ParseEdgeAdded)
+		if err != nil {
+			return errors.Wrapf(err, "could not parse edge creation in tx %#x", l.TxHash)
 		}
-		if edgeAdded {
-			edgeAddedCounter.Inc(1)
+		if err = w.ingestEvent(&watcherEvent{
+			Kind:      edgeAddedEvent,
+			EdgeAdded: event,
+		}, l); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -575,19 +774,24 @@ func (w *Watcher) AddEdge(ctx context.Context, edge protocol.SpecEdge) (bool, er
 	}
 	chal, ok := w.challenges.TryGet(challengeParentAssertionHash)
 	if !ok {
-		tree := challengetree.New(
-			challengeParentAssertionHash,
-			w.chain,
-			w.histChecker,
-			w.numBigStepLevels,
-			w.validatorName,
-		)
-		chal = &trackedChallenge{
-			honestEdgeTree:                 tree,
-			confirmedLevelZeroEdgeClaimIds: threadsafe.NewMap[protocol.ClaimId, protocol.EdgeId](threadsafe.MapWithMetric[protocol.ClaimId, protocol.EdgeId]("confirmedLevelZeroEdgeClaimIds")),
-		}
+		chal = w.newTrackedChallenge(challengeParentAssertionHash)
 		w.challenges.Put(challengeParentAssertionHash, chal)
 	}
+	if w.shouldDeferRival(chal, edge) {
+		chal.deferredRivals.Put(edge.Id(), edge)
+		deferredRivalsCounter.Inc(1)
+		log.Debug(
+			"Deferring rival edge, as the limit of tracked rivals for the challenge was reached",
+			"edgeId", fmt.Sprintf("%#x", edge.Id().Hash.Bytes()[:4]),
+			"challengedAssertionHash", fmt.Sprintf("%#x", challengeParentAssertionHash.Hash.Bytes()[:4]),
+			"numDeferred", chal.deferredRivals.NumItems(),
+		)
+		return false, nil
+	}
+	if w.shouldShedRival(ctx, chal, edge) {
+		w.shedRival(chal, edge, challengeParentAssertionHash)
+		return false, nil
+	}
 	// Add the edge to a local challenge tree of tracked edges. If it is honest,
 	// we also spawn a tracker for the edge.
 	isRoyalEdge, err := chal.honestEdgeTree.AddEdge(ctx, edge)
@@ -599,10 +803,21 @@ func (w *Watcher) AddEdge(ctx context.Context, edge protocol.SpecEdge) (bool, er
 		return false, nil
 	}
 	if isRoyalEdge {
-		err = w.edgeManager.TrackEdge(ctx, edge)
-		if err != nil {
+		// Watchtowers only observe challenges, so do not act on honest edges.
+		if w.watchtower == nil {
+			err = w.edgeManager.TrackEdge(ctx, edge)
+			if err != nil {
+				return false, err
+			}
+		}
+		if err = w.promoteDeferredRivals(ctx, chal, edge); err != nil {
 			return false, err
 		}
+	} else {
+		chal.numTrackedRivals.Add(1)
+		if w.watchtower != nil {
+			chal.evilEdges.Put(edge.Id(), edge)
+		}
 	}
 	fields := []any{
 		"edgeId", fmt.Sprintf("%#x", edge.Id().Hash.Bytes()[:4]),
@@ -647,6 +862,65 @@ func (w *Watcher) AddEdge(ctx context.Context, edge protocol.SpecEdge) (bool, er
 	return true, nil
 }
 
+func (w *Watcher) newTrackedChallenge(assertionHash protocol.AssertionHash) *trackedChallenge {
+	return &trackedChallenge{
+		honestEdgeTree: challengetree.New(
+			assertionHash,
+			w.chain,
+			w.histChecker,
+			w.numBigStepLevels,
+			w.validatorName,
+		),
+		confirmedLevelZeroEdgeClaimIds: threadsafe.NewMap[protocol.ClaimId, protocol.EdgeId](threadsafe.MapWithMetric[protocol.ClaimId, protocol.EdgeId]("confirmedLevelZeroEdgeClaimIds")),
+		deferredRivals:                 threadsafe.NewMap[protocol.EdgeId, protocol.SpecEdge](threadsafe.MapWithMetric[protocol.EdgeId, protocol.SpecEdge]("deferredRivals")),
+		shedRivals:                     threadsafe.NewMap[protocol.EdgeId, protocol.SpecEdge](threadsafe.MapWithMetric[protocol.EdgeId, protocol.SpecEdge]("shedRivals")),
+		evilEdges:                      threadsafe.NewMap[protocol.EdgeId, protocol.SpecEdge](threadsafe.MapWithMetric[protocol.EdgeId, protocol.SpecEdge]("watchtowerEvilEdges")),
+	}
+}
+
+// Checks if an edge should be deferred rather than added to the challenge tree, which is the case
+// once the challenge has reached its limit of tracked rivals. Edges that threaten the royal path
+// are never deferred: block challenge root edges, which may be royal, and rivals of royal edges,
+// which affect the unrivaled timers of the royal path.
+func (w *Watcher) shouldDeferRival(chal *trackedChallenge, edge protocol.SpecEdge) bool {
+	if w.maxTrackedRivalsPerChallenge == 0 {
+		return false
+	}
+	if chal.numTrackedRivals.Load() < w.maxTrackedRivalsPerChallenge {
+		return false
+	}
+	return !threatensRoyalPath(chal, edge)
+}
+
+// Adds the deferred or shed edges that rival a newly observed royal edge to the challenge tree,
+// as they now affect the royal edge's unrivaled timer.
+func (w *Watcher) promoteDeferredRivals(ctx context.Context, chal *trackedChallenge, royal protocol.ReadOnlyEdge) error {
+	if w.maxTrackedRivalsPerChallenge == 0 && w.shedding == nil {
+		return nil
+	}
+	rivals := make([]protocol.SpecEdge, 0)
+	collect := func(_ protocol.EdgeId, edge protocol.SpecEdge) error {
+		if edge.MutualId() == royal.MutualId() && edge.OriginId() == royal.OriginId() {
+			rivals = append(rivals, edge)
+		}
+		return nil
+	}
+	_ = chal.deferredRivals.ForEach(collect)
+	_ = chal.shedRivals.ForEach(collect)
+	for _, rival := range rivals {
+		chal.deferredRivals.Delete(rival.Id())
+		if chal.shedRivals.Has(rival.Id()) {
+			chal.shedRivals.Delete(rival.Id())
+			pendingShedGauge.Dec(1)
+		}
+		if _, err := w.AddEdge(ctx, rival); err != nil {
+			return errors.Wrapf(err, "could not add deferred rival edge %#x", rival.Id())
+		}
+		promotedRivalsCounter.Inc(1)
+	}
+	return nil
+}
+
 // Processes an edge added event by adding it to the honest challenge tree if it is honest.
 func (w *Watcher) processEdgeAddedEvent(
 	ctx context.Context,
@@ -697,31 +971,13 @@ func (w *Watcher) checkForEdgeConfirmedByOneStepProof(
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err = it.Close(); err != nil {
-			log.Error("Could not close filter iterator", "err", err)
-		}
-	}()
-	for it.Next() {
-		if it.Error() != nil {
-			return errors.Wrapf(
-				err,
-				"got iterator error when scanning edge creations from block %d to %d",
-				filterOpts.Start,
-				*filterOpts.End,
-			)
-		}
-		_, processErr := retry.UntilSucceeds(ctx, func() (bool, error) {
-			return true, w.processEdgeConfirmation(ctx, protocol.EdgeId{
-				Hash: it.Event.EdgeId,
-			})
-		})
-		if processErr != nil {
-			return processErr
-		}
-		edgeConfirmedByOSPCounter.Inc(1)
-	}
-	return nil
+	return iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeConfirmedByOneStepProof](it, func(event *challengeV2gen.EdgeChallengeManagerEdgeConfirmedByOneStepProof) error {
+		eventbus.RememberLog(w.eventBus, event.Raw, event)
+		return w.ingestEvent(&watcherEvent{
+			Kind:   edgeConfirmedByOneStepProofEvent,
+			EdgeId: event.EdgeId,
+		}, event.Raw)
+	})
 }
 
 // Filters for edge confirmed by time within a range.
@@ -735,31 +991,13 @@ func (w *Watcher) checkForEdgeConfirmedByTime(
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err = it.Close(); err != nil {
-			log.Error("Could not close filter iterator", "err", err)
-		}
-	}()
-	for it.Next() {
-		if it.Error() != nil {
-			return errors.Wrapf(
-				err,
-				"got iterator error when scanning edge creations from block %d to %d",
-				filterOpts.Start,
-				*filterOpts.End,
-			)
-		}
-		_, processErr := retry.UntilSucceeds(ctx, func() (bool, error) {
-			return true, w.processEdgeConfirmation(ctx, protocol.EdgeId{
-				Hash: it.Event.EdgeId,
-			})
-		})
-		if processErr != nil {
-			return processErr
-		}
-		edgeConfirmedByTimeCounter.Inc(1)
-	}
-	return nil
+	return iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeConfirmedByTime](it, func(event *challengeV2gen.EdgeChallengeManagerEdgeConfirmedByTime) error {
+		eventbus.RememberLog(w.eventBus, event.Raw, event)
+		return w.ingestEvent(&watcherEvent{
+			Kind:   edgeConfirmedByTimeEvent,
+			EdgeId: event.EdgeId,
+		}, event.Raw)
+	})
 }
 
 // Processes an edge confirmation event by checking if it claims an edge. If so, we add
@@ -790,6 +1028,12 @@ func (w *Watcher) processEdgeConfirmation(
 		return nil
 	}
 
+	// Watchtowers do not confirm assertions, and only alert on the confirmation of evil edges.
+	if w.watchtower != nil {
+		w.observeEdgeConfirmation(challengeParentAssertionHash, edge)
+		return nil
+	}
+
 	// If an edge does not have a claim ID, it is not a level zero edge, and thus we can return early,
 	// as the following operations only operate on level zero edges.
 	if edge.ClaimId().IsNone() {
@@ -830,14 +1074,14 @@ func (w *Watcher) processEdgeConfirmation(
 func (w *Watcher) confirmAssertionByChallengeWinner(ctx context.Context, edge protocol.SpecEdge, claimId protocol.ClaimId, challengeParentAssertionHash protocol.AssertionHash) {
 	edgeConfirmedAtBlock, err := retry.UntilSucceeds(ctx, func() (uint64, error) {
 		return edge.ConfirmedAtBlock(ctx)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get edge confirmed at block", "err", err)
 		return
 	}
 	challengeGracePeriodBlocks, err := retry.UntilSucceeds(ctx, func() (uint64, error) {
 		return w.chain.RollupUserLogic().RollupUserLogicCaller.ChallengeGracePeriodBlocks(w.chain.GetCallOptsWithDesiredRpcHeadBlockNumber(&bind.CallOpts{Context: ctx}))
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get challenge grace period blocks", "err", err)
 		return
@@ -846,7 +1090,7 @@ func (w *Watcher) confirmAssertionByChallengeWinner(ctx context.Context, edge pr
 		return w.chain.ReadAssertionCreationInfo(
 			ctx, protocol.AssertionHash{Hash: common.Hash(claimId)},
 		)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get assertion creation info", "err", err)
 		return
@@ -855,7 +1099,7 @@ func (w *Watcher) confirmAssertionByChallengeWinner(ctx context.Context, edge pr
 		return w.chain.ReadAssertionCreationInfo(
 			ctx, protocol.AssertionHash{Hash: assertionCreationInfo.ParentAssertionHash},
 		)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		log.Error("Could not get parent assertion creation info", "err", err)
 		return
@@ -918,7 +1162,8 @@ type filterRange struct {
 }
 
 // Gets the start and end block numbers for our filter queries, starting from the
-// latest confirmed assertion's block number up to the latest block number.
+// latest confirmed assertion's block number up to the latest block number. No edges
+// can exist before the challenge manager was deployed, so we never start before then.
 func (w *Watcher) getStartEndBlockNum(ctx context.Context) (filterRange, error) {
 	latestConfirmed, err := w.chain.LatestConfirmed(ctx)
 	if err != nil {
@@ -933,12 +1178,31 @@ func (w *Watcher) getStartEndBlockNum(ctx context.Context) (filterRange, error)
 	if !header.Number.IsUint64() {
 		return filterRange{}, errors.New("header number is not a uint64")
 	}
+	challengeManager, err := w.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return filterRange{}, err
+	}
+	deploymentBlock, err := w.deploymentStartBlock(ctx, challengeManager.Address(), header.Number.Uint64())
+	if err != nil {
+		log.Warn("Could not get challenge manager deployment block, scanning from latest confirmed assertion", "err", err)
+	} else if deploymentBlock > startBlock {
+		startBlock = deploymentBlock
+	}
 	return filterRange{
 		startBlockNum: startBlock,
 		endBlockNum:   header.Number.Uint64(),
 	}, nil
 }
 
+// Records the block at which an edge was confirmed in the API database, from the block of the
+// confirmation event.
+func (w *Watcher) saveEdgeConfirmationToDB(ev *watcherEvent) error {
+	if api.IsNil(w.apiDB) {
+		return nil
+	}
+	return w.apiDB.UpdateEdgeConfirmation(ev.EdgeId, ev.BlockNumber)
+}
+
 func (w *Watcher) saveEdgeToDB(
 	ctx context.Context,
 	edge protocol.SpecEdge,
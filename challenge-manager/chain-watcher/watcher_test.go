@@ -6,15 +6,25 @@ package watcher
 import (
 	"context"
 	"math/big"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/containers/queue"
 	"github.com/OffchainLabs/bold/containers/threadsafe"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
 	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -298,3 +308,428 @@ func TestWatcher_AddVerifiedHonestEdge(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, blockNum-createdAt+assertionUnrivaledBlocks, uint64(resp))
 }
+
+func TestWatcher_eventJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.journal")
+	edgeAdded := &challengeV2gen.EdgeChallengeManagerEdgeAdded{
+		EdgeId:      common.BytesToHash([]byte("foo")),
+		MutualId:    common.BytesToHash([]byte("bar")),
+		OriginId:    common.BytesToHash([]byte("baz")),
+		ClaimId:     common.BytesToHash([]byte("qux")),
+		Length:      big.NewInt(32),
+		Level:       1,
+		HasRival:    true,
+		IsLayerZero: true,
+		Raw: types.Log{
+			Address: common.BytesToAddress([]byte("manager")),
+			Topics:  []common.Hash{common.BytesToHash([]byte("topic"))},
+			Data:    []byte{},
+		},
+	}
+	w, err := New(nil, nil, nil, nil, time.Second, 1, "alice", nil, time.Second, time.Second, nil, WithEventJournal(path))
+	require.NoError(t, err)
+	require.NoError(t, w.eventQueue.Push(&watcherEvent{Kind: edgeAddedEvent, EdgeAdded: edgeAdded}))
+	require.NoError(t, w.eventQueue.Push(&watcherEvent{Kind: edgeConfirmedByTimeEvent, EdgeId: edgeAdded.EdgeId}))
+	// Stopping the watcher closes its journal.
+	w.StopAndWait()
+	require.ErrorContains(t, w.eventQueue.Push(&watcherEvent{Kind: edgeConfirmedByTimeEvent}), "closed")
+
+	// A restarted watcher replays the events it had not yet processed.
+	restarted, err := New(nil, nil, nil, nil, time.Second, 1, "alice", nil, time.Second, time.Second, nil, WithEventJournal(path))
+	require.NoError(t, err)
+	require.Equal(t, 2, restarted.eventQueue.Len())
+	replayed := restarted.eventQueue.Peek().Unwrap()
+	require.Equal(t, edgeAddedEvent, replayed.Kind)
+	require.Equal(t, edgeAdded.EdgeId, replayed.EdgeAdded.EdgeId)
+	require.Equal(t, edgeAdded.Length, replayed.EdgeAdded.Length)
+	require.Equal(t, edgeAdded.Raw.Address, replayed.EdgeAdded.Raw.Address)
+	require.NoError(t, restarted.eventQueue.Ack())
+	replayed = restarted.eventQueue.Peek().Unwrap()
+	require.Equal(t, edgeConfirmedByTimeEvent, replayed.Kind)
+	require.Equal(t, common.Hash(edgeAdded.EdgeId), replayed.EdgeId)
+}
+
+// A backend for which a contract has code from its deployment block onwards.
+type deployedCodeBackend struct {
+	bind.ContractBackend
+	deployedAt uint64
+	calls      int
+}
+
+func (b *deployedCodeBackend) CodeAt(_ context.Context, _ common.Address, blockNumber *big.Int) ([]byte, error) {
+	b.calls++
+	if blockNumber.Uint64() < b.deployedAt {
+		return nil, nil
+	}
+	return []byte{1}, nil
+}
+
+func TestFindDeploymentBlock(t *testing.T) {
+	ctx := context.Background()
+	for _, deployedAt := range []uint64{0, 1, 1234, 4999, 5000} {
+		backend := &deployedCodeBackend{deployedAt: deployedAt}
+		got, err := FindDeploymentBlock(ctx, backend, common.Address{}, 5000)
+		require.NoError(t, err)
+		require.Equal(t, deployedAt, got)
+	}
+	_, err := FindDeploymentBlock(ctx, &deployedCodeBackend{deployedAt: 5001}, common.Address{}, 5000)
+	require.ErrorContains(t, err, "no code deployed")
+}
+
+func TestWatcher_deploymentStartBlock(t *testing.T) {
+	ctx := context.Background()
+	apiDB, err := db.NewDatabase(filepath.Join(t.TempDir(), "api.db"))
+	require.NoError(t, err)
+	backend := &deployedCodeBackend{deployedAt: 1234}
+	challengeManager := common.BytesToAddress([]byte("challengeManager"))
+	w := &Watcher{
+		backend:             backend,
+		apiDB:               apiDB,
+		startBlockOverrides: make(map[common.Address]uint64),
+	}
+
+	// The deployment block is discovered and recorded.
+	startBlock, err := w.deploymentStartBlock(ctx, challengeManager, 5000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1234), startBlock)
+	recorded, err := apiDB.GetDeploymentStartBlock(challengeManager)
+	require.NoError(t, err)
+	require.Equal(t, api.DeploymentStartBlockDiscovered, recorded.Unwrap().Source)
+
+	// Subsequent startups skip discovery.
+	calls := backend.calls
+	startBlock, err = w.deploymentStartBlock(ctx, challengeManager, 6000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1234), startBlock)
+	require.Equal(t, calls, backend.calls)
+
+	// An override takes precedence and replaces the recorded start block.
+	WithStartBlockOverride(challengeManager, 2000)(w)
+	startBlock, err = w.deploymentStartBlock(ctx, challengeManager, 6000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2000), startBlock)
+	recorded, err = apiDB.GetDeploymentStartBlock(challengeManager)
+	require.NoError(t, err)
+	require.Equal(t, &api.JsonDeploymentStartBlock{
+		ChallengeManager: challengeManager,
+		StartBlock:       2000,
+		Source:           api.DeploymentStartBlockOverride,
+	}, recorded.Unwrap())
+	require.Equal(t, calls, backend.calls)
+}
+
+func TestWatcher_deferRivalsOverLimit(t *testing.T) {
+	ctx := context.Background()
+	assertionHash := protocol.AssertionHash{Hash: common.BytesToHash([]byte("foo"))}
+	royalOrigin := protocol.OriginId(assertionHash.Hash)
+	royalMutual := protocol.MutualId(common.BytesToHash([]byte("royal mutual")))
+	spamMutual := protocol.MutualId(common.BytesToHash([]byte("spam mutual")))
+
+	mockChain := &mocks.MockProtocol{}
+	mockChain.On("IsChallengeComplete", ctx, assertionHash).Return(false, nil)
+	mockChain.On("TopLevelAssertion", ctx, mock.Anything).Return(assertionHash, nil)
+
+	newEdge := func(name string, mutualId protocol.MutualId, claimId option.Option[protocol.ClaimId]) *mocks.MockSpecEdge {
+		edge := &mocks.MockSpecEdge{}
+		edge.On("Id").Return(protocol.EdgeId{Hash: common.BytesToHash([]byte(name))})
+		edge.On("AssertionHash", ctx).Return(assertionHash, nil)
+		edge.On("OriginId").Return(royalOrigin)
+		edge.On("MutualId").Return(mutualId)
+		edge.On("ClaimId").Return(claimId)
+		edge.On("CreatedAtBlock").Return(uint64(1), nil)
+		edge.On("GetChallengeLevel").Return(protocol.NewBlockChallengeLevel(), nil)
+		edge.On("GetReversedChallengeLevel").Return(protocol.ChallengeLevel(2), nil)
+		edge.On("StartCommitment").Return(protocol.Height(0), common.Hash{})
+		edge.On("EndCommitment").Return(protocol.Height(4), common.BytesToHash([]byte(name)))
+		return edge
+	}
+	watcher := &Watcher{
+		challenges:                   threadsafe.NewMap[protocol.AssertionHash, *trackedChallenge](),
+		chain:                        mockChain,
+		histChecker:                  &mocks.MockStateManager{},
+		numBigStepLevels:             1,
+		maxTrackedRivalsPerChallenge: 1,
+	}
+
+	// The first rival is tracked, but further ones are deferred once the limit is reached.
+	added, err := watcher.AddEdge(ctx, newEdge("spam1", spamMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = watcher.AddEdge(ctx, newEdge("spam2", spamMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.False(t, added)
+	rival := newEdge("rival", royalMutual, option.None[protocol.ClaimId]())
+	added, err = watcher.AddEdge(ctx, rival)
+	require.NoError(t, err)
+	require.False(t, added)
+	chal := watcher.challenges.Get(assertionHash)
+	require.Equal(t, uint64(2), chal.deferredRivals.NumItems())
+
+	// Observing a royal edge promotes the deferred edges that rival it.
+	royal := newEdge("royal", royalMutual, option.Some(protocol.ClaimId(assertionHash.Hash)))
+	require.NoError(t, watcher.AddVerifiedHonestEdge(ctx, &mockHonestEdge{royal}))
+	require.Equal(t, uint64(1), chal.deferredRivals.NumItems())
+	require.True(t, chal.deferredRivals.Has(protocol.EdgeId{Hash: common.BytesToHash([]byte("spam2"))}))
+	unrivaled, err := chal.honestEdgeTree.IsUnrivaledAtBlockNum(royal, 10)
+	require.NoError(t, err)
+	require.False(t, unrivaled)
+
+	// Rivals of royal edges are never deferred.
+	added, err = watcher.AddEdge(ctx, newEdge("rival2", royalMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, uint64(1), chal.deferredRivals.NumItems())
+}
+
+func TestWatcher_shedRivalSubtreesUnderPeakLoad(t *testing.T) {
+	ctx := context.Background()
+	assertionHash := protocol.AssertionHash{Hash: common.BytesToHash([]byte("foo"))}
+	royalOrigin := protocol.OriginId(assertionHash.Hash)
+	royalMutual := protocol.MutualId(common.BytesToHash([]byte("royal mutual")))
+	spamMutual := protocol.MutualId(common.BytesToHash([]byte("spam mutual")))
+
+	challengeManager := &mocks.MockSpecChallengeManager{}
+	challengeManager.On("ChallengePeriodBlocks", ctx).Return(uint64(100), nil)
+	mockChain := &mocks.MockProtocol{}
+	mockChain.On("IsChallengeComplete", ctx, assertionHash).Return(false, nil)
+	mockChain.On("TopLevelAssertion", ctx, mock.Anything).Return(assertionHash, nil)
+	mockChain.On("SpecChallengeManager", ctx).Return(challengeManager, nil)
+
+	newEdge := func(name string, mutualId protocol.MutualId, claimId option.Option[protocol.ClaimId]) *mocks.MockSpecEdge {
+		edge := &mocks.MockSpecEdge{}
+		edge.On("Id").Return(protocol.EdgeId{Hash: common.BytesToHash([]byte(name))})
+		edge.On("AssertionHash", ctx).Return(assertionHash, nil)
+		edge.On("OriginId").Return(royalOrigin)
+		edge.On("MutualId").Return(mutualId)
+		edge.On("ClaimId").Return(claimId)
+		edge.On("CreatedAtBlock").Return(uint64(1), nil)
+		edge.On("GetChallengeLevel").Return(protocol.NewBlockChallengeLevel(), nil)
+		edge.On("GetReversedChallengeLevel").Return(protocol.ChallengeLevel(2), nil)
+		edge.On("StartCommitment").Return(protocol.Height(0), common.Hash{})
+		edge.On("EndCommitment").Return(protocol.Height(4), common.BytesToHash([]byte(name)))
+		return edge
+	}
+	eventQueue, err := queue.NewDurable[*watcherEvent]()
+	require.NoError(t, err)
+	watcher := &Watcher{
+		challenges:       threadsafe.NewMap[protocol.AssertionHash, *trackedChallenge](),
+		chain:            mockChain,
+		histChecker:      &mocks.MockStateManager{},
+		numBigStepLevels: 1,
+		eventQueue:       eventQueue,
+	}
+	WithLoadShedding(1, 10)(watcher)
+
+	// Without a backlog, nothing is shed.
+	added, err := watcher.AddEdge(ctx, newEdge("spam1", spamMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.True(t, added)
+	require.False(t, watcher.SheddingStatus().Active)
+
+	// Under peak load, rival subtree edges are shed.
+	require.NoError(t, eventQueue.Push(&watcherEvent{}))
+	added, err = watcher.AddEdge(ctx, newEdge("spam2", spamMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.False(t, added)
+	added, err = watcher.AddEdge(ctx, newEdge("rival", royalMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.False(t, added)
+	chal := watcher.challenges.Get(assertionHash)
+	require.Equal(t, uint64(2), chal.shedRivals.NumItems())
+
+	// Shed edges that rival a royal edge are tracked once it is observed, and further rivals of
+	// royal edges are never shed.
+	royal := newEdge("royal", royalMutual, option.Some(protocol.ClaimId(assertionHash.Hash)))
+	require.NoError(t, watcher.AddVerifiedHonestEdge(ctx, &mockHonestEdge{royal}))
+	require.Equal(t, uint64(1), chal.shedRivals.NumItems())
+	added, err = watcher.AddEdge(ctx, newEdge("rival2", royalMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Nothing is shed in challenges whose deadline is imminent.
+	chal.lastRootTimer.Store(90)
+	added, err = watcher.AddEdge(ctx, newEdge("spam3", spamMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Nor in challenges within the deadline margin, once it is wider than the deadline window.
+	chal.lastRootTimer.Store(50)
+	WithDeadlineMargin(func() uint64 { return 60 })(watcher)
+	added, err = watcher.AddEdge(ctx, newEdge("spam4", spamMutual, option.None[protocol.ClaimId]()))
+	require.NoError(t, err)
+	require.True(t, added)
+
+	status := watcher.SheddingStatus()
+	require.True(t, status.Active)
+	require.Equal(t, uint64(2), status.TotalShed)
+	require.Equal(t, []ShedEdge{{
+		AssertionHash:  assertionHash,
+		EdgeId:         protocol.EdgeId{Hash: common.BytesToHash([]byte("spam2"))},
+		ChallengeLevel: protocol.NewBlockChallengeLevel(),
+	}}, status.ShedEdges)
+
+	// Shed edges are tracked once the backlog is processed.
+	require.NoError(t, eventQueue.Ack())
+	watcher.restoreShedRivals(ctx)
+	status = watcher.SheddingStatus()
+	require.False(t, status.Active)
+	require.Equal(t, uint64(1), status.TotalRestored)
+	require.Empty(t, status.ShedEdges)
+	unrivaled, err := chal.honestEdgeTree.IsUnrivaledAtBlockNum(royal, 10)
+	require.NoError(t, err)
+	require.False(t, unrivaled)
+}
+
+type recordingCommitter struct {
+	requests []*l2stateprovider.HistoryCommitmentRequest
+}
+
+func (c *recordingCommitter) HistoryCommitment(
+	_ context.Context,
+	req *l2stateprovider.HistoryCommitmentRequest,
+) (commitments.History, error) {
+	c.requests = append(c.requests, req)
+	return commitments.History{}, nil
+}
+
+func TestWatcher_warmUpCaches(t *testing.T) {
+	ctx := context.Background()
+	apiDB, err := db.NewDatabase(filepath.Join(t.TempDir(), "api.db"))
+	require.NoError(t, err)
+	mockChain := &mocks.MockProtocol{}
+
+	challengedAssertion := common.BytesToHash([]byte("challenged"))
+	claimedAssertion := common.BytesToHash([]byte("claimed"))
+	wasmModuleRoot := common.BytesToHash([]byte("wasm"))
+	claimedInfo := &protocol.AssertionCreatedInfo{
+		ParentAssertionHash: challengedAssertion,
+		BeforeState:         rollupgen.AssertionState{GlobalState: rollupgen.GlobalState{U64Vals: [2]uint64{3, 0}}},
+		AfterState:          rollupgen.AssertionState{GlobalState: rollupgen.GlobalState{U64Vals: [2]uint64{5, 0}}},
+	}
+	mockChain.On("ReadAssertionCreationInfo", mock.Anything, protocol.AssertionHash{Hash: claimedAssertion}).Return(claimedInfo, nil)
+	mockChain.On("ReadAssertionCreationInfo", mock.Anything, protocol.AssertionHash{Hash: challengedAssertion}).Return(
+		&protocol.AssertionCreatedInfo{WasmModuleRoot: wasmModuleRoot}, nil,
+	)
+	subchallengeEdgeId := protocol.EdgeId{Hash: common.BytesToHash([]byte("subchallenge"))}
+	mockChain.On("TopLevelClaimHeights", mock.Anything, subchallengeEdgeId).Return(
+		protocol.OriginHeights{ChallengeOriginHeights: []protocol.Height{7}}, nil,
+	)
+
+	newEdge := func(id string, level uint8, start, end uint64) *api.JsonEdge {
+		return &api.JsonEdge{
+			Id:             common.BytesToHash([]byte(id)),
+			ChallengeLevel: level,
+			StartHeight:    start,
+			EndHeight:      end,
+			AssertionHash:  challengedAssertion,
+			HasRival:       true,
+			IsRoyal:        true,
+			Status:         protocol.EdgePending.String(),
+		}
+	}
+	root := newEdge("root", 0, 0, 32)
+	root.ClaimId = claimedAssertion
+	// Bisected edges and edges at a one step fork are skipped.
+	root.HasChildren = true
+	upper := newEdge("upper", 0, 16, 32)
+	oneStepFork := newEdge("oneStepFork", 0, 7, 8)
+	oneStepFork.HasLengthOneRival = true
+	subchallenge := newEdge("subchallenge", 1, 0, 16)
+	// Non-royal edges are not warmed up for.
+	evil := newEdge("evil", 0, 0, 16)
+	evil.IsRoyal = false
+	for _, edge := range []*api.JsonEdge{root, upper, oneStepFork, subchallenge, evil} {
+		require.NoError(t, apiDB.InsertEdge(edge))
+	}
+
+	committer := &recordingCommitter{}
+	w := &Watcher{chain: mockChain, apiDB: apiDB}
+	WithCacheWarmup(committer, time.Minute)(w)
+	w.warmUpCaches(ctx)
+
+	type request struct {
+		origins []l2stateprovider.Height
+		upTo    l2stateprovider.Height
+	}
+	got := make([]request, 0, len(committer.requests))
+	for _, req := range committer.requests {
+		require.Equal(t, wasmModuleRoot, req.WasmModuleRoot)
+		require.Equal(t, l2stateprovider.Batch(3), req.FromBatch)
+		require.Equal(t, l2stateprovider.Batch(5), req.ToBatch)
+		require.Equal(t, l2stateprovider.Height(0), req.FromHeight)
+		got = append(got, request{origins: req.UpperChallengeOriginHeights, upTo: req.UpToHeight.Unwrap()})
+	}
+	require.ElementsMatch(t, []request{
+		{origins: []l2stateprovider.Height{}, upTo: 24},
+		{origins: []l2stateprovider.Height{}, upTo: 32},
+		{origins: []l2stateprovider.Height{7}, upTo: 8},
+		{origins: []l2stateprovider.Height{7}, upTo: 16},
+	}, got)
+}
+
+func TestWatcher_watchtowerAlerts(t *testing.T) {
+	ctx := context.Background()
+	assertionHash := protocol.AssertionHash{Hash: common.BytesToHash([]byte("foo"))}
+	origin := protocol.OriginId(assertionHash.Hash)
+
+	mockChallengeManager := &mocks.MockSpecChallengeManager{}
+	mockChallengeManager.On("ChallengePeriodBlocks", ctx).Return(uint64(10), nil)
+	mockChain := &mocks.MockProtocol{}
+	mockChain.On("IsChallengeComplete", ctx, assertionHash).Return(false, nil)
+	mockChain.On("TopLevelAssertion", ctx, mock.Anything).Return(assertionHash, nil)
+	mockChain.On("SpecChallengeManager", ctx).Return(mockChallengeManager, nil)
+
+	newEdge := func(name string, mutual string, createdAt uint64) *mocks.MockSpecEdge {
+		edge := &mocks.MockSpecEdge{}
+		edge.On("Id").Return(protocol.EdgeId{Hash: common.BytesToHash([]byte(name))})
+		edge.On("AssertionHash", ctx).Return(assertionHash, nil)
+		edge.On("OriginId").Return(origin)
+		edge.On("MutualId").Return(protocol.MutualId(common.BytesToHash([]byte(mutual))))
+		edge.On("ClaimId").Return(option.None[protocol.ClaimId]())
+		edge.On("CreatedAtBlock").Return(createdAt, nil)
+		edge.On("GetChallengeLevel").Return(protocol.NewBlockChallengeLevel(), nil)
+		edge.On("GetReversedChallengeLevel").Return(protocol.ChallengeLevel(2), nil)
+		edge.On("StartCommitment").Return(protocol.Height(0), common.Hash{})
+		edge.On("EndCommitment").Return(protocol.Height(4), common.BytesToHash([]byte(name)))
+		return edge
+	}
+	var alerts []*Alert
+	// No edge manager is given, as watchtowers never track edges.
+	watcher := &Watcher{
+		challenges:       threadsafe.NewMap[protocol.AssertionHash, *trackedChallenge](),
+		chain:            mockChain,
+		histChecker:      &mocks.MockStateManager{},
+		numBigStepLevels: 1,
+	}
+	WithWatchtower(3, func(alert *Alert) {
+		alerts = append(alerts, alert)
+	}, func(*Alert) {
+		panic("misbehaving handler")
+	})(watcher)
+
+	unopposed := newEdge("unopposed", "unopposed mutual", 1)
+	_, err := watcher.AddEdge(ctx, unopposed)
+	require.NoError(t, err)
+	for _, name := range []string{"rival1", "rival2"} {
+		_, err = watcher.AddEdge(ctx, newEdge(name, "rival mutual", 1))
+		require.NoError(t, err)
+	}
+
+	// Not yet within the alert window of the challenge period.
+	watcher.checkEvilEdgesOnTrack(ctx, 7)
+	require.Empty(t, alerts)
+
+	// Only the unrivaled edge is on track, and is only alerted on once.
+	watcher.checkEvilEdgesOnTrack(ctx, 8)
+	watcher.checkEvilEdgesOnTrack(ctx, 9)
+	require.Len(t, alerts, 1)
+	require.Equal(t, EvilEdgeOnTrackAlert, alerts[0].Kind)
+	require.Equal(t, unopposed.Id(), alerts[0].EdgeId)
+	require.Equal(t, uint64(7), alerts[0].UnrivaledBlocks)
+	require.Equal(t, uint64(10), alerts[0].ChallengePeriodBlocks)
+
+	watcher.observeEdgeConfirmation(assertionHash, unopposed)
+	require.Len(t, alerts, 2)
+	require.Equal(t, EvilEdgeConfirmedAlert, alerts[1].Kind)
+	require.False(t, watcher.challenges.Get(assertionHash).evilEdges.Has(unopposed.Id()))
+}
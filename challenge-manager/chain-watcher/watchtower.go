@@ -0,0 +1,209 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/threadsafe"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	watchtowerAlertsCounter = metrics.NewRegisteredCounter("arb/validator/watcher/watchtower/alerts", nil)
+	evilEdgesOnTrackGauge   = metrics.NewRegisteredGauge("arb/validator/watcher/watchtower/evil_edges_on_track", nil)
+)
+
+// AlertKind is the kind of an alert raised by a watchtower.
+type AlertKind string
+
+const (
+	// EvilEdgeOnTrackAlert is raised when an edge we disagree with, which no honest edge rivals,
+	// has been unrivaled for long enough to be within the alert window of being confirmable by time.
+	EvilEdgeOnTrackAlert AlertKind = "evil_edge_on_track"
+	// EvilEdgeConfirmedAlert is raised when an edge we disagree with is confirmed.
+	EvilEdgeConfirmedAlert AlertKind = "evil_edge_confirmed"
+)
+
+// Alert raised by a watchtower about an edge we disagree with.
+type Alert struct {
+	Kind                    AlertKind
+	ChallengedAssertionHash protocol.AssertionHash
+	EdgeId                  protocol.EdgeId
+	ChallengeLevel          protocol.ChallengeLevel
+	// The number of blocks the edge was unrivaled for as of the block the alert was raised at.
+	UnrivaledBlocks       uint64
+	ChallengePeriodBlocks uint64
+	BlockNumber           uint64
+}
+
+// AlertHandler is called with each alert raised by a watchtower. It is called synchronously as
+// events are processed, so must not block.
+type AlertHandler func(alert *Alert)
+
+type alertKey struct {
+	kind   AlertKind
+	edgeId protocol.EdgeId
+}
+
+type watchtower struct {
+	alertWindowBlocks     uint64
+	handlers              []AlertHandler
+	alerted               *threadsafe.Set[alertKey]
+	challengePeriodBlocks atomic.Uint64
+}
+
+// WithWatchtower makes the watcher only observe challenges, never sending transactions. It does
+// not track honest edges nor confirm assertions by challenge winners, but keeps the edges we
+// disagree with along with their rivals, and alerts when one is on track to be confirmed by time,
+// once it is within alertWindowBlocks of a challenge period unrivaled by any honest edge, and when
+// one is confirmed. Each alert is logged, counted and passed to the handlers once per edge.
+func WithWatchtower(alertWindowBlocks uint64, handlers ...AlertHandler) Opt {
+	return func(w *Watcher) {
+		w.watchtower = &watchtower{
+			alertWindowBlocks: alertWindowBlocks,
+			handlers:          handlers,
+			alerted:           threadsafe.NewSet[alertKey](),
+		}
+	}
+}
+
+// IsWatchtower checks if the watcher only observes challenges.
+func (w *Watcher) IsWatchtower() bool {
+	return w.watchtower != nil
+}
+
+// Alerts on the edges we disagree with which are on track to be confirmed by time as of a block.
+// Challenges which are complete are no longer observed.
+func (w *Watcher) checkEvilEdgesOnTrack(ctx context.Context, blockNum uint64) {
+	if w.watchtower == nil {
+		return
+	}
+	period, err := w.watchtowerChallengePeriod(ctx)
+	if err != nil {
+		log.Error("Could not get challenge period blocks", "err", err)
+		return
+	}
+	assertionHashes := make([]protocol.AssertionHash, 0)
+	_ = w.challenges.ForEach(func(assertionHash protocol.AssertionHash, _ *trackedChallenge) error {
+		assertionHashes = append(assertionHashes, assertionHash)
+		return nil
+	})
+	onTrack := int64(0)
+	for _, assertionHash := range assertionHashes {
+		complete, err := w.chain.IsChallengeComplete(ctx, assertionHash)
+		if err != nil {
+			log.Error("Could not check if challenge is complete", "challengedAssertionHash", assertionHash.Hash, "err", err)
+			continue
+		}
+		if complete {
+			w.challenges.Delete(assertionHash)
+			continue
+		}
+		chal, ok := w.challenges.TryGet(assertionHash)
+		if !ok {
+			continue
+		}
+		edges := make([]protocol.SpecEdge, 0)
+		_ = chal.evilEdges.ForEach(func(_ protocol.EdgeId, edge protocol.SpecEdge) error {
+			edges = append(edges, edge)
+			return nil
+		})
+		for _, edge := range edges {
+			if chal.honestEdgeTree.RivalsRoyalEdge(edge) {
+				continue
+			}
+			unrivaled, err := chal.honestEdgeTree.LocalTimer(edge, blockNum)
+			if err != nil {
+				log.Error("Could not compute local timer of evil edge", "edgeId", edge.Id().Hash, "err", err)
+				continue
+			}
+			if unrivaled+w.watchtower.alertWindowBlocks < period {
+				continue
+			}
+			onTrack++
+			w.raiseAlert(&Alert{
+				Kind:                    EvilEdgeOnTrackAlert,
+				ChallengedAssertionHash: assertionHash,
+				EdgeId:                  edge.Id(),
+				ChallengeLevel:          edge.GetChallengeLevel(),
+				UnrivaledBlocks:         unrivaled,
+				ChallengePeriodBlocks:   period,
+				BlockNumber:             blockNum,
+			})
+		}
+	}
+	evilEdgesOnTrackGauge.Update(onTrack)
+}
+
+// Alerts if a confirmed edge is one we disagree with.
+func (w *Watcher) observeEdgeConfirmation(challengedAssertionHash protocol.AssertionHash, edge protocol.SpecEdge) {
+	chal, ok := w.challenges.TryGet(challengedAssertionHash)
+	if !ok || !chal.evilEdges.Has(edge.Id()) {
+		return
+	}
+	chal.evilEdges.Delete(edge.Id())
+	w.raiseAlert(&Alert{
+		Kind:                    EvilEdgeConfirmedAlert,
+		ChallengedAssertionHash: challengedAssertionHash,
+		EdgeId:                  edge.Id(),
+		ChallengeLevel:          edge.GetChallengeLevel(),
+		ChallengePeriodBlocks:   w.watchtower.challengePeriodBlocks.Load(),
+	})
+}
+
+func (w *Watcher) watchtowerChallengePeriod(ctx context.Context) (uint64, error) {
+	if period := w.watchtower.challengePeriodBlocks.Load(); period != 0 {
+		return period, nil
+	}
+	challengeManager, err := w.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return 0, err
+	}
+	period, err := challengeManager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	w.watchtower.challengePeriodBlocks.Store(period)
+	return period, nil
+}
+
+func (w *Watcher) raiseAlert(alert *Alert) {
+	key := alertKey{kind: alert.Kind, edgeId: alert.EdgeId}
+	if w.watchtower.alerted.Has(key) {
+		return
+	}
+	w.watchtower.alerted.Insert(key)
+	watchtowerAlertsCounter.Inc(1)
+	metrics.GetOrRegisterCounter("arb/validator/watcher/watchtower/alerts/"+string(alert.Kind), nil).Inc(1)
+	fields := []any{
+		"edgeId", fmt.Sprintf("%#x", alert.EdgeId.Hash.Bytes()[:4]),
+		"challengeLevel", alert.ChallengeLevel,
+		"challengedAssertionHash", fmt.Sprintf("%#x", alert.ChallengedAssertionHash.Hash.Bytes()[:4]),
+	}
+	switch alert.Kind {
+	case EvilEdgeConfirmedAlert:
+		log.Error("Watchtower observed an evil edge being confirmed", fields...)
+	default:
+		fields = append(fields, "unrivaledBlocks", alert.UnrivaledBlocks, "challengePeriodBlocks", alert.ChallengePeriodBlocks)
+		log.Warn("Watchtower observed an unopposed evil edge on track to be confirmed", fields...)
+	}
+	for _, handler := range w.watchtower.handlers {
+		callAlertHandler(handler, alert)
+	}
+}
+
+func callAlertHandler(handler AlertHandler, alert *Alert) {
+	// A misbehaving handler must not bring down the watcher.
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Error("Watchtower alert handler panicked", "kind", alert.Kind, "err", rec)
+		}
+	}()
+	handler(alert)
+}
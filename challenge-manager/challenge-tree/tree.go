@@ -108,6 +108,23 @@ func (ht *RoyalChallengeTree) HasRoyalEdge(edgeId protocol.EdgeId) bool {
 	return ht.edges.Has(edgeId)
 }
 
+// RivalsRoyalEdge checks if an edge shares a mutual id with a royal edge in the tree. Such edges
+// affect the unrivaled timers along the royal path, while other non-royal edges do not.
+func (ht *RoyalChallengeTree) RivalsRoyalEdge(edge protocol.ReadOnlyEdge) bool {
+	mutuals, ok := ht.edgeCreationTimes.TryGet(buildEdgeCreationTimeKey(edge.OriginId(), edge.MutualId()))
+	if !ok || mutuals == nil {
+		return false
+	}
+	rivalsRoyal := false
+	_ = mutuals.ForEach(func(id protocol.EdgeId, _ creationTime) error {
+		if id != edge.Id() && ht.edges.Has(id) {
+			rivalsRoyal = true
+		}
+		return nil
+	})
+	return rivalsRoyal
+}
+
 func (ht *RoyalChallengeTree) IsUnrivaledAtBlockNum(edge protocol.ReadOnlyEdge, blockNum uint64) (bool, error) {
 	return ht.UnrivaledAtBlockNum(edge, blockNum)
 }
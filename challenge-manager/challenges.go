@@ -11,8 +11,10 @@ import (
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/OffchainLabs/bold/api"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
 	"github.com/OffchainLabs/bold/containers"
 	"github.com/pkg/errors"
 )
@@ -73,11 +75,12 @@ func (m *Manager) ChallengeAssertion(ctx context.Context, id protocol.AssertionH
 		edgeTrackerAssertionInfo,
 		edgetracker.WithTimeReference(m.timeRef),
 		edgetracker.WithValidatorName(m.name),
+		edgetracker.WithBisectionPrefetcher(m.bisectionPrefetcher),
 	)
 	if err != nil {
 		return false, err
 	}
-	m.LaunchThread(tracker.Spawn)
+	m.LaunchThread(m.supervisor.Supervise("edge_tracker", tracker.Spawn))
 
 	log.Info("Successfully opened a challenge on an invalid assertion",
 		"name", m.name,
@@ -105,6 +108,16 @@ func (m *Manager) addBlockChallengeLevelZeroEdge(
 	}
 	fromBatch := l2stateprovider.Batch(protocol.GoGlobalStateFromSolidity(creationInfo.BeforeState.GlobalState).Batch)
 	toBatch := l2stateprovider.Batch(protocol.GoGlobalStateFromSolidity(creationInfo.AfterState.GlobalState).Batch)
+	// The level zero edge commits to the execution of the claimed assertion, which we must be able
+	// to prove step-by-step later in the challenge.
+	if err = l2stateprovider.EnsureBatchDataAvailable(
+		ctx,
+		m.batchAvailabilityChecker,
+		l2stateprovider.BatchCountForState(protocol.GoGlobalStateFromSolidity(creationInfo.AfterState.GlobalState)),
+		m.batchAvailabilityConfig,
+	); err != nil {
+		return nil, false, nil, false, err
+	}
 
 	startCommit, err := m.stateManager.HistoryCommitment(
 		ctx,
@@ -145,8 +158,7 @@ func (m *Manager) addBlockChallengeLevelZeroEdge(
 	if err != nil {
 		return nil, false, nil, false, err
 	}
-	precomputedEdgeId, err := manager.CalculateEdgeId(
-		ctx,
+	precomputedEdgeId := protocol.ComputeEdgeId(
 		protocol.NewBlockChallengeLevel(),
 		protocol.OriginId(creationInfo.ParentAssertionHash),
 		protocol.Height(startCommit.Height),
@@ -154,9 +166,6 @@ func (m *Manager) addBlockChallengeLevelZeroEdge(
 		protocol.Height(endCommit.Height),
 		endCommit.Merkle,
 	)
-	if err != nil {
-		return nil, false, nil, false, errors.Wrap(err, "could not calculate edge id")
-	}
 	someLevelZeroEdge, err := manager.GetEdge(ctx, precomputedEdgeId)
 
 	// If the edge already exists, we return true and everything else nil.
@@ -171,10 +180,29 @@ func (m *Manager) addBlockChallengeLevelZeroEdge(
 	if err != nil {
 		return nil, false, nil, false, err
 	}
+	if err = m.hooks.Gate(ctx, &hooks.Action{
+		Point:          hooks.BeforeCreateEdge,
+		Kind:           "block_challenge_edge",
+		AssertionHash:  creationInfo.ParentAssertionHash,
+		EdgeId:         precomputedEdgeId.Hash,
+		ChallengeLevel: protocol.NewBlockChallengeLevel().Uint8(),
+	}); err != nil {
+		return nil, false, nil, false, err
+	}
 	edge, err := manager.AddBlockChallengeLevelZeroEdge(ctx, assertion, startCommit, endCommit, startEndPrefixProof)
+	auditInputs := map[string]any{
+		"assertionHash":    assertion.Id().Hash,
+		"startHistoryRoot": startCommit.Merkle,
+		"endHistoryRoot":   endCommit.Merkle,
+	}
 	if err != nil {
+		m.auditLog.Record(api.AuditActionCreateBlockChallengeEdge, auditInputs, nil, err)
 		return nil, false, nil, false, errors.Wrap(err, "could not post block challenge root edge")
 	}
+	m.auditLog.Record(api.AuditActionCreateBlockChallengeEdge, auditInputs, map[string]any{
+		"edgeId": edge.Id().Hash,
+	}, nil)
+	m.recordBlockChallengeEdge(creationInfo.ParentAssertionHash, edge.Id().Hash, startCommit, endCommit, startEndPrefixProof)
 	return edge, true, &edgetracker.AssociatedAssertionMetadata{
 		FromBatch:            fromBatch,
 		ToBatch:              toBatch,
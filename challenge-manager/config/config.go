@@ -0,0 +1,427 @@
+// Package config defines the configuration of a challenge manager, with documented defaults and
+// validation, which can be built programmatically or loaded from a file, environment variables,
+// and command line flags, in increasing order of precedence.
+//
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// EnvPrefix is prepended to the name of a flag, upper-cased and with dashes replaced by
+// underscores, to give the environment variable setting the same option.
+const EnvPrefix = "BOLD_"
+
+// Config of a challenge manager. Dependencies which cannot be expressed as configuration values,
+// such as an RPC client or a batch availability checker, are instead given to the challenge
+// manager as options.
+type Config struct {
+	// A human-readable identifier for the challenge manager, used in logs. Defaults to empty.
+	Name string
+	// The mode determines which actions the challenge manager takes onchain. Defaults to
+	// watchtower mode, which only raises alerts.
+	Mode types.Mode
+	// The address of the staker, used in logs. Defaults to the zero address.
+	Address common.Address
+	// How often to post new assertions. Defaults to an hour.
+	AssertionPostingInterval time.Duration
+	// How often to scan for new assertions. Defaults to a minute.
+	AssertionScanningInterval time.Duration
+	// How often to attempt confirming assertions. Defaults to 10 seconds.
+	AssertionConfirmingInterval time.Duration
+	// The average time between blocks of the parent chain. Defaults to 12 seconds.
+	AvgBlockCreationTime time.Duration
+	// Edges tick every this many blocks of the parent chain. Defaults to every block.
+	TickEdgesOnNumberOfBlocks uint64
+	// The maximum random delay before challenging an assertion, so that validators do not all
+	// challenge at once. Defaults to no delay.
+	MaxDelaySeconds int
+	// The address to serve the API on, when the API service of the api/server package is added to
+	// the challenge manager. Defaults to empty, which disables the API.
+	APIAddr string
+	// The path of the API database. Required by the API, and defaults to empty.
+	APIDBPath string
+	// The path of the journal of challenge events pending processing. Defaults to empty, which
+	// disables the journal.
+	WatcherEventJournalPath string
+	// The block to scan for events from, for each challenge manager address. Defaults to
+	// discovering the deployment block of each challenge manager.
+	ChallengeScanStartBlocks map[common.Address]uint64
+	// Limits the number of non-royal edges tracked in each challenge. Defaults to 0, which is
+	// unlimited.
+	MaxTrackedRivalsPerChallenge uint64
+	// Sheds the tracking of edges which cannot threaten the royal path while at least this many
+	// scanned events are pending processing. Defaults to 0, which disables load shedding.
+	LoadSheddingBacklog int
+	// Challenges whose royal root edge is within this many blocks of being confirmable are never
+	// shed. Defaults to 0.
+	LoadSheddingDeadlineWindowBlocks uint64
+	// Staggers the first decision cycles of edge trackers at startup, admitting up to this many
+	// each second, those of edges closest to their deadline first. Defaults to 0, which disables
+	// the throttle.
+	TrackerStartupRate int
+	// The maximum random delay added to the first decision cycle of each tracker admitted by the
+	// startup throttle. Defaults to no delay.
+	TrackerStartupJitter time.Duration
+	// The trackers of edges within this many blocks of their deadline, or within the deadline
+	// margin if wider, skip the startup throttle. Defaults to 0.
+	TrackerStartupBoostWindowBlocks uint64
+	// Only tracks challenges on these parent assertion hashes. Defaults to tracking all challenges.
+	TrackChallengeParentAssertionHashes []common.Hash
+	// Overrides the backoff of named retry policies. Policies are shared by every challenge manager
+	// in the process. Defaults to retry.DefaultPolicies.
+	RetryPolicies map[retry.Policy]retry.Backoff
+	// Confirmation methods the validator does not use, which may be changed at runtime through the
+	// API. Only confirming by claim and by children may be disabled. Defaults to none.
+	DisabledConfirmationMethods []types.ConfirmationMethod
+	// The URL to fetch policy documents from, which are applied if signed by the
+	// RemotePolicySigner. Defaults to empty, which disables remote policies.
+	RemotePolicyURL string
+	// The operator address policy documents must be signed by. Required with a RemotePolicyURL.
+	RemotePolicySigner common.Address
+	// How often to fetch the policy document. Defaults to a minute.
+	RemotePolicyPollInterval time.Duration
+	// The file storing the last applied policy document and its version, so that it is applied
+	// again and older documents are not after a restart. Required with a RemotePolicyURL.
+	RemotePolicyVersionPath string
+	// Which of the siblings whose states we agree with to defend, exact or first. Defaults to
+	// exact.
+	AgreedSiblingPolicy types.SiblingPolicy
+	// The fraction of the challenge period a counter-move may take, from the block at which a
+	// rival moved against our edge to the block our move is included in. Operators are alerted
+	// when recent counter-moves put this budget at risk. Defaults to 0.1, and 0 disables alerts.
+	LatencyBudgetFraction float64
+	// The safety margin of blocks to keep before the deadlines of challenges, which must be below
+	// the challenge period. Challenges within it are never load shed. When recent counter-moves took
+	// longer than the margin to be included, it is insufficient and tightens to their latency, under
+	// which challenges are opened without a random delay and edges tick on every block. Defaults to
+	// 0, which disables the margin.
+	DeadlineMarginBlocks uint64
+	// How often to deliver a digest of dispute activity to operators, such as every 24h or 168h.
+	// Digests are logged, and sent to the confirmation webhooks if any. Requires an API database.
+	// Defaults to 0, which disables digests.
+	DigestInterval time.Duration
+	// Whether to run the proof pipeline on a small synthetic machine at startup, computing a history
+	// commitment, a prefix proof, and a one step proof and verifying them, so that a broken pipeline
+	// fails the startup rather than a challenge. Defaults to false.
+	ProofSelfTest bool
+	// Bounds the number of machine hash collections computing history commitments which run at
+	// once, by a pool growing from one worker up to this many while collections back up. Only
+	// takes effect with a state manager built on a HistoryCommitmentProvider. Defaults to 0,
+	// which leaves them unbounded.
+	CommitmentWorkers int
+	// Bounds the number of one step proof collections which run at once, as CommitmentWorkers
+	// does for machine hash collections. Defaults to 0, which leaves them unbounded.
+	ProofWorkers int
+	// The CPU utilization of the host, between 0 and 1, above which worker pools do not grow.
+	// Defaults to 0.8.
+	WorkerPoolMaxLoad float64
+	// Whether to withdraw the layer zero edges we staked to recover their stakes once the assertion
+	// claimed by their challenge is confirmed. Only takes effect with a challenge manager which
+	// supports withdrawing edges, which is detected from the contract. Defaults to false.
+	EdgeWithdrawal bool
+	// The path of the file storing the poisoned flag, which is set when an edge we agree with loses to
+	// a rival confirmed by one step proof, and freezes all posting until an operator clears it.
+	// Defaults to empty, which disables the flag.
+	PoisonFlagPath string
+	// The directory a diagnostic bundle is captured to when the flag is poisoned. Requires a
+	// PoisonFlagPath, and defaults to empty, which captures none.
+	PoisonBundleDir string
+	// Whether to observe challenges in watchtower mode, alerting when an edge we disagree with is
+	// confirmed or on track to be confirmed, without ever sending transactions. Defaults to false.
+	WatchtowerAlerts bool
+	// Watchtowers alert on an edge we disagree with once it has been unrivaled by any honest edge
+	// for this many blocks short of a challenge period. Defaults to 0, which alerts once it is
+	// confirmable.
+	WatchtowerAlertWindowBlocks uint64
+}
+
+// Default returns the default configuration.
+func Default() *Config {
+	return &Config{
+		Mode:                        types.WatchTowerMode,
+		AssertionPostingInterval:    time.Hour,
+		AssertionScanningInterval:   time.Minute,
+		AssertionConfirmingInterval: time.Second * 10,
+		AvgBlockCreationTime:        time.Second * 12,
+		TickEdgesOnNumberOfBlocks:   1,
+		LatencyBudgetFraction:       0.1,
+		WorkerPoolMaxLoad:           0.8,
+	}
+}
+
+// Validate checks that the values of the config are within range and consistent with each other.
+func (c *Config) Validate() error {
+	if c.Mode > types.MakeMode {
+		return fmt.Errorf("invalid mode %d", c.Mode)
+	}
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"assertion-posting-interval", c.AssertionPostingInterval},
+		{"assertion-scanning-interval", c.AssertionScanningInterval},
+		{"assertion-confirming-interval", c.AssertionConfirmingInterval},
+		{"avg-block-creation-time", c.AvgBlockCreationTime},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %v", d.name, d.value)
+		}
+	}
+	if c.TickEdgesOnNumberOfBlocks == 0 {
+		return errors.New("tick-edges-on-number-of-blocks must be at least 1")
+	}
+	if c.MaxDelaySeconds < 0 {
+		return fmt.Errorf("max-delay-seconds cannot be negative, got %d", c.MaxDelaySeconds)
+	}
+	if c.LatencyBudgetFraction < 0 || c.LatencyBudgetFraction > 1 {
+		return fmt.Errorf("latency-budget-fraction must be between 0 and 1, got %v", c.LatencyBudgetFraction)
+	}
+	if c.DigestInterval < 0 {
+		return fmt.Errorf("digest-interval cannot be negative, got %v", c.DigestInterval)
+	}
+	if c.DigestInterval > 0 && c.APIDBPath == "" {
+		return errors.New("digest-interval requires api-db-path to be set")
+	}
+	if c.LoadSheddingBacklog < 0 {
+		return fmt.Errorf("load-shedding-backlog cannot be negative, got %d", c.LoadSheddingBacklog)
+	}
+	if c.TrackerStartupRate < 0 {
+		return fmt.Errorf("tracker-startup-rate cannot be negative, got %d", c.TrackerStartupRate)
+	}
+	if c.TrackerStartupJitter < 0 {
+		return fmt.Errorf("tracker-startup-jitter cannot be negative, got %v", c.TrackerStartupJitter)
+	}
+	if c.CommitmentWorkers < 0 {
+		return fmt.Errorf("commitment-workers cannot be negative, got %d", c.CommitmentWorkers)
+	}
+	if c.ProofWorkers < 0 {
+		return fmt.Errorf("proof-workers cannot be negative, got %d", c.ProofWorkers)
+	}
+	if (c.CommitmentWorkers > 0 || c.ProofWorkers > 0) && (c.WorkerPoolMaxLoad <= 0 || c.WorkerPoolMaxLoad > 1) {
+		return fmt.Errorf("worker-pool-max-load must be above 0 and at most 1, got %v", c.WorkerPoolMaxLoad)
+	}
+	if c.RemotePolicyURL != "" && c.RemotePolicySigner == (common.Address{}) {
+		return errors.New("remote-policy-url requires remote-policy-signer to be set")
+	}
+	if c.RemotePolicyURL != "" && c.RemotePolicyVersionPath == "" {
+		return errors.New("remote-policy-url requires remote-policy-version-path to be set")
+	}
+	if c.RemotePolicyPollInterval < 0 {
+		return fmt.Errorf("remote-policy-poll-interval cannot be negative, got %v", c.RemotePolicyPollInterval)
+	}
+	if _, err := types.ParseSiblingPolicy(string(c.AgreedSiblingPolicy)); err != nil {
+		return errors.Wrap(err, "agreed-sibling-policy")
+	}
+	if c.PoisonBundleDir != "" && c.PoisonFlagPath == "" {
+		return errors.New("poison-bundle-dir requires poison-flag-path to be set")
+	}
+	if c.APIAddr != "" && c.APIDBPath == "" {
+		return errors.New("api-addr requires api-db-path to be set")
+	}
+	for addr := range c.ChallengeScanStartBlocks {
+		if addr == (common.Address{}) {
+			return errors.New("challenge-scan-start-blocks cannot contain the zero address")
+		}
+	}
+	for policy, backoff := range c.RetryPolicies {
+		if _, ok := retry.DefaultPolicies()[policy]; !ok {
+			return fmt.Errorf("unknown retry policy %q", policy)
+		}
+		if err := backoff.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid backoff for retry-%s", policy)
+		}
+	}
+	for _, m := range c.DisabledConfirmationMethods {
+		if !m.CanBeDisabled() {
+			return fmt.Errorf("confirmation method %q cannot be disabled", m)
+		}
+	}
+	if c.WatchtowerAlerts && c.Mode != types.WatchTowerMode {
+		return fmt.Errorf("watchtower-alerts requires watchtower mode, got %s", c.Mode)
+	}
+	if c.Mode == types.WatchTowerMode && len(c.TrackChallengeParentAssertionHashes) > 0 {
+		return errors.New("track-challenge-parent-assertion-hashes is mutually exclusive with watchtower mode, " +
+			"which does not take part in challenges")
+	}
+	return nil
+}
+
+// RegisterFlags defines a flag for each option of the config on the flag set, which sets the option
+// when parsed. The current values of the config are the defaults of the flags.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Name, "name", c.Name, "human-readable identifier for the challenge manager, used in logs")
+	fs.Var((*modeValue)(&c.Mode), "mode", "one of watchtower, defensive, resolve, or make")
+	fs.Var((*addressValue)(&c.Address), "address", "address of the staker, used in logs")
+	fs.DurationVar(&c.AssertionPostingInterval, "assertion-posting-interval", c.AssertionPostingInterval, "how often to post new assertions")
+	fs.DurationVar(&c.AssertionScanningInterval, "assertion-scanning-interval", c.AssertionScanningInterval, "how often to scan for new assertions")
+	fs.DurationVar(&c.AssertionConfirmingInterval, "assertion-confirming-interval", c.AssertionConfirmingInterval, "how often to attempt confirming assertions")
+	fs.DurationVar(&c.AvgBlockCreationTime, "avg-block-creation-time", c.AvgBlockCreationTime, "average time between blocks of the parent chain")
+	fs.Uint64Var(&c.TickEdgesOnNumberOfBlocks, "tick-edges-on-number-of-blocks", c.TickEdgesOnNumberOfBlocks, "number of parent chain blocks between edge ticks")
+	fs.IntVar(&c.MaxDelaySeconds, "max-delay-seconds", c.MaxDelaySeconds, "maximum random delay before challenging an assertion")
+	fs.StringVar(&c.APIAddr, "api-addr", c.APIAddr, "address to serve the API on, disabled if empty")
+	fs.StringVar(&c.APIDBPath, "api-db-path", c.APIDBPath, "path of the API database")
+	fs.StringVar(&c.WatcherEventJournalPath, "watcher-event-journal", c.WatcherEventJournalPath, "path of the journal of challenge events pending processing, disabled if empty")
+	fs.Var((*startBlocksValue)(&c.ChallengeScanStartBlocks), "challenge-scan-start-blocks", "comma-separated address=block pairs of the block to scan each challenge manager from")
+	fs.Uint64Var(&c.MaxTrackedRivalsPerChallenge, "max-tracked-rivals-per-challenge", c.MaxTrackedRivalsPerChallenge, "limit of non-royal edges tracked in each challenge, unlimited if 0")
+	fs.IntVar(&c.LoadSheddingBacklog, "load-shedding-backlog", c.LoadSheddingBacklog, "number of pending events from which edges off the royal path are shed, disabled if 0")
+	fs.Uint64Var(&c.LoadSheddingDeadlineWindowBlocks, "load-shedding-deadline-window-blocks", c.LoadSheddingDeadlineWindowBlocks, "blocks before the royal root edge of a challenge is confirmable from which its edges are never shed")
+	fs.IntVar(&c.TrackerStartupRate, "tracker-startup-rate", c.TrackerStartupRate, "edge trackers admitted to their first decision cycle each second at startup, closest to their deadline first, unthrottled if 0")
+	fs.DurationVar(&c.TrackerStartupJitter, "tracker-startup-jitter", c.TrackerStartupJitter, "maximum random delay of the first decision cycle of each throttled edge tracker")
+	fs.Uint64Var(&c.TrackerStartupBoostWindowBlocks, "tracker-startup-boost-window-blocks", c.TrackerStartupBoostWindowBlocks, "blocks before their deadline from which edge trackers skip the startup throttle")
+	fs.Var((*hashesValue)(&c.TrackChallengeParentAssertionHashes), "track-challenge-parent-assertion-hashes", "comma-separated parent assertion hashes of the only challenges to track")
+	fs.Var((*confirmationMethodsValue)(&c.DisabledConfirmationMethods), "disabled-confirmation-methods", "comma-separated confirmation methods not to use, among claim and children")
+	fs.StringVar(&c.RemotePolicyURL, "remote-policy-url", c.RemotePolicyURL, "url to fetch signed policy documents from, disabled if empty")
+	fs.Var((*addressValue)(&c.RemotePolicySigner), "remote-policy-signer", "operator address policy documents must be signed by")
+	fs.DurationVar(&c.RemotePolicyPollInterval, "remote-policy-poll-interval", c.RemotePolicyPollInterval, "how often to fetch the policy document, a minute if 0")
+	fs.StringVar(&c.RemotePolicyVersionPath, "remote-policy-version-path", c.RemotePolicyVersionPath, "path of the file storing the last applied policy document and its version")
+	fs.Var((*siblingPolicyValue)(&c.AgreedSiblingPolicy), "agreed-sibling-policy", "which sibling assertion with a state we agree with to defend, exact or first")
+	fs.Float64Var(&c.LatencyBudgetFraction, "latency-budget-fraction", c.LatencyBudgetFraction, "fraction of the challenge period a counter-move to a rival may take before operators are alerted, disabled if 0")
+	fs.Uint64Var(&c.DeadlineMarginBlocks, "deadline-margin-blocks", c.DeadlineMarginBlocks, "safety margin of blocks to keep before the deadlines of challenges, tightened under degraded inclusion latency, disabled if 0")
+	fs.DurationVar(&c.DigestInterval, "digest-interval", c.DigestInterval, "how often to deliver a digest of dispute activity to operators, disabled if 0")
+	fs.BoolVar(&c.ProofSelfTest, "proof-self-test", c.ProofSelfTest, "verify a history commitment, prefix proof and one step proof of a synthetic machine at startup")
+	fs.IntVar(&c.CommitmentWorkers, "commitment-workers", c.CommitmentWorkers, "maximum machine hash collections computing history commitments at once, grown to from one while they back up, unbounded if 0")
+	fs.IntVar(&c.ProofWorkers, "proof-workers", c.ProofWorkers, "maximum one step proof collections at once, grown to from one while they back up, unbounded if 0")
+	fs.Float64Var(&c.WorkerPoolMaxLoad, "worker-pool-max-load", c.WorkerPoolMaxLoad, "CPU utilization of the host, between 0 and 1, above which worker pools do not grow")
+	fs.BoolVar(&c.EdgeWithdrawal, "edge-withdrawal", c.EdgeWithdrawal, "withdraw our layer zero edges to recover their stakes once their claimed assertion is confirmed, where the challenge manager supports it")
+	fs.StringVar(&c.PoisonFlagPath, "poison-flag-path", c.PoisonFlagPath, "path of the flag freezing all posting once an edge we agree with loses by one step proof, disabled if empty")
+	fs.StringVar(&c.PoisonBundleDir, "poison-bundle-dir", c.PoisonBundleDir, "directory diagnostic bundles are captured to when the poisoned flag is set, none if empty")
+	fs.BoolVar(&c.WatchtowerAlerts, "watchtower-alerts", c.WatchtowerAlerts, "in watchtower mode, observe challenges and alert on edges we disagree with that are confirmed or on track to be confirmed")
+	fs.Uint64Var(&c.WatchtowerAlertWindowBlocks, "watchtower-alert-window-blocks", c.WatchtowerAlertWindowBlocks, "blocks short of a challenge period from which unopposed edges we disagree with are alerted on")
+	for _, policy := range retry.Policies() {
+		fs.Var(&backoffValue{policies: &c.RetryPolicies, policy: policy}, "retry-"+string(policy), fmt.Sprintf("backoff of the %s retry policy, as comma-separated initial, max, multiplier and jitter, e.g. initial=1s,max=1m", policy))
+	}
+}
+
+// Load builds a config from the defaults, overridden in turn by the JSON config file given by the
+// -config flag, if any, by environment variables, and by the remaining command line flags. The
+// resulting config is validated.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+	// Flags are parsed into a separate config first, to find the config file and which flags were
+	// set, but only applied after the other sources.
+	flags := flag.NewFlagSet("challenge-manager", flag.ContinueOnError)
+	path := flags.String("config", "", "path of a JSON config file")
+	Default().RegisterFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	options := flag.NewFlagSet("options", flag.ContinueOnError)
+	cfg.RegisterFlags(options)
+	if *path != "" {
+		if err := loadFile(options, *path); err != nil {
+			return nil, err
+		}
+	}
+	if lookupEnv != nil {
+		var err error
+		options.VisitAll(func(f *flag.Flag) {
+			name := EnvName(f.Name)
+			if value, ok := lookupEnv(name); ok && err == nil {
+				if setErr := options.Set(f.Name, value); setErr != nil {
+					err = errors.Wrapf(setErr, "invalid value of %s", name)
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "config" || err != nil {
+			return
+		}
+		err = options.Set(f.Name, f.Value.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// EnvName is the name of the environment variable setting the option of a flag.
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Reads a JSON object keyed by flag name. Lists may be given as arrays, and challenge scan start
+// blocks as an object keyed by address.
+func loadFile(options *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "could not read config file")
+	}
+	var values map[string]json.RawMessage
+	if err = json.Unmarshal(data, &values); err != nil {
+		return errors.Wrapf(err, "could not parse config file %s", path)
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if options.Lookup(name) == nil {
+			return fmt.Errorf("unknown option %q in config file %s", name, path)
+		}
+		value, err := jsonToFlagValue(values[name])
+		if err != nil {
+			return errors.Wrapf(err, "invalid value of %q in config file %s", name, path)
+		}
+		if err = options.Set(name, value); err != nil {
+			return errors.Wrapf(err, "invalid value of %q in config file %s", name, path)
+		}
+	}
+	return nil
+}
+
+func jsonToFlagValue(raw json.RawMessage) (string, error) {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return strings.Join(list, ","), nil
+	}
+	var blocks map[string]uint64
+	if err := json.Unmarshal(raw, &blocks); err == nil {
+		pairs := make([]string, 0, len(blocks))
+		for addr, block := range blocks {
+			pairs = append(pairs, fmt.Sprintf("%s=%d", addr, block))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ","), nil
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str, nil
+	}
+	var scalar any
+	if err := json.Unmarshal(raw, &scalar); err != nil {
+		return "", err
+	}
+	switch scalar.(type) {
+	case float64, bool:
+		return strings.TrimSpace(string(raw)), nil
+	default:
+		return "", fmt.Errorf("unsupported value %s", raw)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, types.WatchTowerMode, cfg.Mode)
+	require.Equal(t, time.Hour, cfg.AssertionPostingInterval)
+
+	loaded, err := Load(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, cfg, loaded)
+}
+
+func TestLoad_Layers(t *testing.T) {
+	chalManager := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"name": "from-file",
+		"mode": "defensive",
+		"assertion-posting-interval": "30m",
+		"assertion-scanning-interval": "30s",
+		"max-delay-seconds": 5,
+		"challenge-scan-start-blocks": {"0x5FbDB2315678afecb367f032d93F642f64180aa3": 100},
+		"track-challenge-parent-assertion-hashes": ["0x0000000000000000000000000000000000000000000000000000000000000001"],
+		"retry-rpc-read": "max=1m",
+		"commitment-workers": 4,
+		"disabled-confirmation-methods": ["claim"]
+	}`), 0600))
+	env := map[string]string{
+		"BOLD_NAME":                        "from-env",
+		"BOLD_ASSERTION_SCANNING_INTERVAL": "20s",
+		"BOLD_RETRY_RPC_READ":              "jitter=0",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	cfg, err := Load([]string{"-config", path, "-name", "from-flag", "-mode", "make"}, lookupEnv)
+	require.NoError(t, err)
+	// Flags override the environment, which overrides the file, which overrides the defaults.
+	require.Equal(t, "from-flag", cfg.Name)
+	require.Equal(t, types.MakeMode, cfg.Mode)
+	require.Equal(t, 20*time.Second, cfg.AssertionScanningInterval)
+	require.Equal(t, 30*time.Minute, cfg.AssertionPostingInterval)
+	require.Equal(t, time.Second*10, cfg.AssertionConfirmingInterval)
+	require.Equal(t, 5, cfg.MaxDelaySeconds)
+	require.Equal(t, 4, cfg.CommitmentWorkers)
+	require.Equal(t, map[common.Address]uint64{chalManager: 100}, cfg.ChallengeScanStartBlocks)
+	require.Equal(t, []common.Hash{common.BigToHash(common.Big1)}, cfg.TrackChallengeParentAssertionHashes)
+	// Fields of a backoff given in different layers are merged over its default.
+	rpcRead := retry.DefaultPolicies()[retry.RPCRead]
+	rpcRead.Max = time.Minute
+	rpcRead.Jitter = 0
+	require.Equal(t, map[retry.Policy]retry.Backoff{retry.RPCRead: rpcRead}, cfg.RetryPolicies)
+	require.Equal(t, []types.ConfirmationMethod{types.ConfirmByClaim}, cfg.DisabledConfirmationMethods)
+
+	_, err = Load([]string{"-config", path, "-mode", "watchtower"}, nil)
+	require.ErrorContains(t, err, "mutually exclusive")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"unknown-option": 1}`), 0600))
+	_, err = Load([]string{"-config", path}, nil)
+	require.ErrorContains(t, err, `unknown option "unknown-option"`)
+
+	env = map[string]string{"BOLD_ASSERTION_POSTING_INTERVAL": "soon"}
+	_, err = Load(nil, lookupEnv)
+	require.ErrorContains(t, err, "invalid value of BOLD_ASSERTION_POSTING_INTERVAL")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{"invalid mode", func(c *Config) { c.Mode = types.MakeMode + 1 }, "invalid mode"},
+		{"zero interval", func(c *Config) { c.AssertionConfirmingInterval = 0 }, "assertion-confirming-interval must be positive"},
+		{"zero tick", func(c *Config) { c.TickEdgesOnNumberOfBlocks = 0 }, "at least 1"},
+		{"negative delay", func(c *Config) { c.MaxDelaySeconds = -1 }, "cannot be negative"},
+		{"remote policy without signer", func(c *Config) { c.RemotePolicyURL = "https://example.com/policy.json" }, "requires remote-policy-signer"},
+		{"remote policy without version path", func(c *Config) {
+			c.RemotePolicyURL = "https://example.com/policy.json"
+			c.RemotePolicySigner = common.Address{1}
+		}, "requires remote-policy-version-path"},
+		{"unknown agreed sibling policy", func(c *Config) { c.AgreedSiblingPolicy = "last" }, "unknown sibling policy"},
+		{"latency budget above challenge period", func(c *Config) { c.LatencyBudgetFraction = 1.5 }, "latency-budget-fraction must be between 0 and 1"},
+		{"digest without database", func(c *Config) { c.DigestInterval = 24 * time.Hour }, "digest-interval requires api-db-path"},
+		{"negative load shedding backlog", func(c *Config) { c.LoadSheddingBacklog = -1 }, "load-shedding-backlog cannot be negative"},
+		{"negative tracker startup rate", func(c *Config) { c.TrackerStartupRate = -1 }, "tracker-startup-rate cannot be negative"},
+		{"negative proof workers", func(c *Config) { c.ProofWorkers = -1 }, "proof-workers cannot be negative"},
+		{"worker pools without headroom", func(c *Config) {
+			c.CommitmentWorkers = 4
+			c.WorkerPoolMaxLoad = 0
+		}, "worker-pool-max-load must be above 0"},
+		{"api without db", func(c *Config) { c.APIAddr = "localhost:8080" }, "requires api-db-path"},
+		{"poison bundle without flag", func(c *Config) { c.PoisonBundleDir = "/tmp/bundles" }, "poison-bundle-dir requires poison-flag-path"},
+		{"watchtower alerts outside watchtower mode", func(c *Config) {
+			c.Mode = types.DefensiveMode
+			c.WatchtowerAlerts = true
+		}, "watchtower-alerts requires watchtower mode"},
+		{"zero scan address", func(c *Config) {
+			c.ChallengeScanStartBlocks = map[common.Address]uint64{{}: 1}
+		}, "zero address"},
+		{"unknown retry policy", func(c *Config) {
+			c.RetryPolicies = map[retry.Policy]retry.Backoff{"rpc": retry.ConstantBackoff(time.Second)}
+		}, "unknown retry policy"},
+		{"invalid backoff", func(c *Config) {
+			c.RetryPolicies = map[retry.Policy]retry.Backoff{retry.RPCWrite: {}}
+		}, "invalid backoff for retry-rpc-write"},
+		{"disabled confirmation by time", func(c *Config) {
+			c.DisabledConfirmationMethods = []types.ConfirmationMethod{types.ConfirmByTime}
+		}, "cannot be disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			require.ErrorContains(t, cfg.Validate(), tt.err)
+		})
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	checksummed := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	for _, s := range []string{checksummed, strings.ToLower(checksummed), "0x" + strings.ToUpper(checksummed[2:])} {
+		addr, err := ParseAddress(s)
+		require.NoError(t, err)
+		require.Equal(t, checksummed, addr.Hex())
+	}
+	_, err := ParseAddress("0x5fbDB2315678afecb367f032d93F642f64180aa3")
+	require.ErrorContains(t, err, "invalid checksum")
+	_, err = ParseAddress("0x1234")
+	require.ErrorContains(t, err, "invalid address")
+}
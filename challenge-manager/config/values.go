@@ -0,0 +1,203 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Flag values for options without a flag type of their own. Each one's String method returns a
+// value its Set method accepts, so flags can be copied from one flag set to another.
+
+type modeValue types.Mode
+
+func (v *modeValue) String() string {
+	return types.Mode(*v).String()
+}
+
+func (v *modeValue) Set(s string) error {
+	m, err := types.ParseMode(s)
+	if err != nil {
+		return err
+	}
+	*v = modeValue(m)
+	return nil
+}
+
+type siblingPolicyValue types.SiblingPolicy
+
+func (v *siblingPolicyValue) String() string {
+	return string(*v)
+}
+
+func (v *siblingPolicyValue) Set(s string) error {
+	p, err := types.ParseSiblingPolicy(s)
+	if err != nil {
+		return err
+	}
+	*v = siblingPolicyValue(p)
+	return nil
+}
+
+type addressValue common.Address
+
+func (v *addressValue) String() string {
+	return common.Address(*v).Hex()
+}
+
+func (v *addressValue) Set(s string) error {
+	addr, err := ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	*v = addressValue(addr)
+	return nil
+}
+
+type startBlocksValue map[common.Address]uint64
+
+func (v *startBlocksValue) String() string {
+	pairs := make([]string, 0, len(*v))
+	for addr, block := range *v {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", addr.Hex(), block))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v *startBlocksValue) Set(s string) error {
+	blocks := make(map[common.Address]uint64)
+	for _, pair := range splitList(s) {
+		addrStr, blockStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("expected address=block, got %q", pair)
+		}
+		addr, err := ParseAddress(addrStr)
+		if err != nil {
+			return err
+		}
+		block, err := strconv.ParseUint(blockStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid block %q for %s: %w", blockStr, addrStr, err)
+		}
+		if _, ok := blocks[addr]; ok {
+			return fmt.Errorf("duplicate start block for %s", addrStr)
+		}
+		blocks[addr] = block
+	}
+	*v = blocks
+	return nil
+}
+
+type hashesValue []common.Hash
+
+func (v *hashesValue) String() string {
+	hashes := make([]string, len(*v))
+	for i, h := range *v {
+		hashes[i] = h.Hex()
+	}
+	return strings.Join(hashes, ",")
+}
+
+func (v *hashesValue) Set(s string) error {
+	var hashes []common.Hash
+	for _, str := range splitList(s) {
+		b, err := hexutil.Decode(str)
+		if err != nil || len(b) != common.HashLength {
+			return fmt.Errorf("invalid hash %q", str)
+		}
+		hashes = append(hashes, common.BytesToHash(b))
+	}
+	*v = hashes
+	return nil
+}
+
+type confirmationMethodsValue []types.ConfirmationMethod
+
+func (v *confirmationMethodsValue) String() string {
+	methods := make([]string, len(*v))
+	for i, m := range *v {
+		methods[i] = string(m)
+	}
+	return strings.Join(methods, ",")
+}
+
+func (v *confirmationMethodsValue) Set(s string) error {
+	var methods []types.ConfirmationMethod
+	for _, str := range splitList(s) {
+		m, err := types.ParseConfirmationMethod(str)
+		if err != nil {
+			return err
+		}
+		methods = append(methods, m)
+	}
+	*v = methods
+	return nil
+}
+
+// Sets the backoff of a single retry policy in a map shared by the flags of all policies. Fields
+// which are not given keep their previous value, or else their default.
+type backoffValue struct {
+	policies *map[retry.Policy]retry.Backoff
+	policy   retry.Policy
+}
+
+func (v *backoffValue) backoff() retry.Backoff {
+	if b, ok := (*v.policies)[v.policy]; ok {
+		return b
+	}
+	return retry.DefaultPolicies()[v.policy]
+}
+
+func (v *backoffValue) String() string {
+	// The flag package calls String on a zero value to check for a zero default.
+	if v.policies == nil {
+		return ""
+	}
+	return v.backoff().String()
+}
+
+func (v *backoffValue) Set(s string) error {
+	b, err := v.backoff().Parse(s)
+	if err != nil {
+		return err
+	}
+	if *v.policies == nil {
+		*v.policies = make(map[retry.Policy]retry.Backoff)
+	}
+	(*v.policies)[v.policy] = b
+	return nil
+}
+
+// ParseAddress parses a hex address. Addresses in mixed case must have a valid EIP-55 checksum,
+// while those in a single case carry no checksum.
+func ParseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	addr := common.HexToAddress(s)
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && "0x"+digits != addr.Hex() {
+		return common.Address{}, fmt.Errorf("invalid checksum of address %q, expected %s", s, addr.Hex())
+	}
+	return addr, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
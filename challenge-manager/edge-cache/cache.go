@@ -0,0 +1,388 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package edgecache mirrors the edges of the challenge manager in memory, from its events and reads
+// of the edges they touch, so that the edge tree can be queried without calls to the chain. Each
+// change is recorded along with the hash of the block it came from, and is undone if that block is
+// reorged out of the parent chain.
+package edgecache
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotFound         = errors.New("edge not found in cache")
+	ErrNoHonestParent   = errors.New("edge has no honest parent in cache")
+	edgesGauge          = metrics.NewRegisteredGauge("arb/validator/edgecache/edges", nil)
+	rolledBackCounter   = metrics.NewRegisteredCounter("arb/validator/edgecache/rolled_back_blocks", nil)
+	edgeReadErrsCounter = metrics.NewRegisteredCounter("arb/validator/edgecache/edge_read_errors", nil)
+)
+
+// Edge is the state of an edge as of the latest block applied to the cache.
+type Edge struct {
+	Id               protocol.EdgeId
+	Level            protocol.ChallengeLevel
+	OriginId         protocol.OriginId
+	MutualId         protocol.MutualId
+	StartHeight      protocol.Height
+	StartHistoryRoot common.Hash
+	EndHeight        protocol.Height
+	EndHistoryRoot   common.Hash
+	// Set for layer zero edges, to the edge they claim at the level above or, at the block
+	// challenge level, to the assertion they claim.
+	ClaimId          option.Option[protocol.ClaimId]
+	LowerChildId     option.Option[protocol.EdgeId]
+	UpperChildId     option.Option[protocol.EdgeId]
+	Staker           common.Address
+	CreatedAtBlock   uint64
+	ConfirmedAtBlock uint64
+	Status           protocol.EdgeStatus
+	Refunded         bool
+	// The total time unrivaled the challenge manager has cached for the edge and its ancestors.
+	TimeUnrivaledCache uint64
+}
+
+// EdgeReader reads an edge from the challenge manager at a block, such as the challenge manager
+// caller of the bindings.
+type EdgeReader interface {
+	GetEdge(opts *bind.CallOpts, edgeId [32]byte) (challengeV2gen.ChallengeEdge, error)
+}
+
+// HeaderReader reads the canonical headers of the parent chain.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// The changes made by the logs of a block, undone in reverse if the block is reorged out.
+type block struct {
+	number  uint64
+	hash    common.Hash
+	changes []change
+}
+
+// An edge as it was before a change, which is nil if the change added it.
+type change struct {
+	id   protocol.EdgeId
+	prev *Edge
+}
+
+// Cache mirrors the edges of a challenge manager. It is safe for concurrent use.
+type Cache struct {
+	challengeManager common.Address
+	reader           EdgeReader
+	events           map[common.Hash]string
+	lock             sync.RWMutex
+	edges            map[protocol.EdgeId]*Edge
+	byMutualId       map[protocol.MutualId]map[protocol.EdgeId]struct{}
+	parents          map[protocol.EdgeId]map[protocol.EdgeId]struct{}
+	honest           map[protocol.EdgeId]struct{}
+	// The blocks whose changes may still be undone, in ascending order.
+	journal []*block
+}
+
+// New creates an empty cache of the edges of a challenge manager, which reads the edges touched by
+// its events with a reader.
+func New(challengeManager common.Address, reader EdgeReader) (*Cache, error) {
+	parsed, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[common.Hash]string)
+	for _, name := range []string{
+		"EdgeAdded",
+		"EdgeBisected",
+		"EdgeConfirmedByTime",
+		"EdgeConfirmedByOneStepProof",
+		"EdgeRefunded",
+		"TimerCacheUpdated",
+	} {
+		ev, ok := parsed.Events[name]
+		if !ok {
+			return nil, fmt.Errorf("challenge manager abi has no %s event", name)
+		}
+		events[ev.ID] = name
+	}
+	return &Cache{
+		challengeManager: challengeManager,
+		reader:           reader,
+		events:           events,
+		edges:            make(map[protocol.EdgeId]*Edge),
+		byMutualId:       make(map[protocol.MutualId]map[protocol.EdgeId]struct{}),
+		parents:          make(map[protocol.EdgeId]map[protocol.EdgeId]struct{}),
+		honest:           make(map[protocol.EdgeId]struct{}),
+	}, nil
+}
+
+// HandleLog applies a log of the challenge manager, reading the edges it touches at its block. Logs
+// must be handled in the order of the chain. A removed log, or a log from a block other than the
+// one the cache has applied at its height, rolls the cache back to before its block first.
+func (c *Cache) HandleLog(ctx context.Context, l types.Log) error {
+	if l.Address != c.challengeManager || len(l.Topics) < 2 {
+		return nil
+	}
+	if l.Removed {
+		c.rollbackFrom(l.BlockNumber)
+		return nil
+	}
+	c.lock.Lock()
+	reorged := c.forksAt(l.BlockNumber, l.BlockHash)
+	c.lock.Unlock()
+	if reorged {
+		c.rollbackFrom(l.BlockNumber)
+	}
+	name, ok := c.events[l.Topics[0]]
+	if !ok {
+		return nil
+	}
+	// Every edge event has the id of its edge as its first indexed topic, and the bisection event
+	// the ids of the children as its next ones. The children are read as well, as the lower child
+	// of a bisection may have been created by a rival's.
+	ids := []protocol.EdgeId{{Hash: l.Topics[1]}}
+	if name == "EdgeBisected" {
+		if len(l.Topics) < 4 {
+			return fmt.Errorf("bisection log in block %d has %d topics", l.BlockNumber, len(l.Topics))
+		}
+		ids = append(ids, protocol.EdgeId{Hash: l.Topics[2]}, protocol.EdgeId{Hash: l.Topics[3]})
+	}
+	edges := make([]*Edge, 0, len(ids))
+	for _, id := range ids {
+		edge, err := c.readEdge(ctx, id, l.BlockNumber, l.BlockHash)
+		if err != nil {
+			edgeReadErrsCounter.Inc(1)
+			return errors.Wrapf(err, "could not read edge %#x after %s log in block %d", id.Hash, name, l.BlockNumber)
+		}
+		edges = append(edges, edge)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, edge := range edges {
+		c.put(l.BlockNumber, l.BlockHash, edge)
+	}
+	edgesGauge.Update(int64(len(c.edges)))
+	return nil
+}
+
+func (c *Cache) readEdge(ctx context.Context, id protocol.EdgeId, blockNumber uint64, blockHash common.Hash) (*Edge, error) {
+	inner, err := c.reader.GetEdge(&bind.CallOpts{Context: ctx, BlockHash: blockHash}, id.Hash)
+	if errors.Is(err, bind.ErrNoBlockHashState) {
+		// Backends which cannot call at a block hash are read at its number. A reorg between the
+		// log and the read is caught by the next reconciliation with the canonical headers.
+		inner, err = c.reader.GetEdge(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)}, id.Hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return edgeFromBinding(id, inner)
+}
+
+func edgeFromBinding(id protocol.EdgeId, inner challengeV2gen.ChallengeEdge) (*Edge, error) {
+	if inner.CreatedAtBlock == 0 {
+		return nil, fmt.Errorf("edge %#x does not exist", id.Hash)
+	}
+	startHeight, err := protocol.HeightFromBig(inner.StartHeight)
+	if err != nil {
+		return nil, err
+	}
+	endHeight, err := protocol.HeightFromBig(inner.EndHeight)
+	if err != nil {
+		return nil, err
+	}
+	status, err := protocol.EdgeStatusFromUint8(inner.Status)
+	if err != nil {
+		return nil, err
+	}
+	level := protocol.ChallengeLevel(inner.Level)
+	originId := protocol.OriginId(inner.OriginId)
+	edge := &Edge{
+		Id:                 id,
+		Level:              level,
+		OriginId:           originId,
+		MutualId:           protocol.ComputeMutualId(level, originId, startHeight, inner.StartHistoryRoot, endHeight),
+		StartHeight:        startHeight,
+		StartHistoryRoot:   inner.StartHistoryRoot,
+		EndHeight:          endHeight,
+		EndHistoryRoot:     inner.EndHistoryRoot,
+		ClaimId:            option.None[protocol.ClaimId](),
+		LowerChildId:       option.None[protocol.EdgeId](),
+		UpperChildId:       option.None[protocol.EdgeId](),
+		Staker:             inner.Staker,
+		CreatedAtBlock:     inner.CreatedAtBlock,
+		ConfirmedAtBlock:   inner.ConfirmedAtBlock,
+		Status:             status,
+		Refunded:           inner.Refunded,
+		TimeUnrivaledCache: inner.TotalTimeUnrivaledCache,
+	}
+	if inner.ClaimId != (common.Hash{}) {
+		edge.ClaimId = option.Some(protocol.ClaimId(inner.ClaimId))
+	}
+	if inner.LowerChildId != (common.Hash{}) {
+		edge.LowerChildId = option.Some(protocol.EdgeId{Hash: inner.LowerChildId})
+	}
+	if inner.UpperChildId != (common.Hash{}) {
+		edge.UpperChildId = option.Some(protocol.EdgeId{Hash: inner.UpperChildId})
+	}
+	return edge, nil
+}
+
+// Whether applying a block would fork the journal, because it already has a different block at the
+// same height or a later one. Must be called with the lock held.
+func (c *Cache) forksAt(number uint64, hash common.Hash) bool {
+	if len(c.journal) == 0 {
+		return false
+	}
+	latest := c.journal[len(c.journal)-1]
+	return latest.number > number || (latest.number == number && latest.hash != hash)
+}
+
+// Replaces an edge, recording its previous state in the journal of its block. Must be called with
+// the lock held.
+func (c *Cache) put(number uint64, hash common.Hash, edge *Edge) {
+	if len(c.journal) == 0 || c.journal[len(c.journal)-1].number != number {
+		c.journal = append(c.journal, &block{number: number, hash: hash})
+	}
+	b := c.journal[len(c.journal)-1]
+	prev := c.edges[edge.Id]
+	b.changes = append(b.changes, change{id: edge.Id, prev: prev})
+	c.set(edge.Id, prev, edge)
+}
+
+// Must be called with the lock held.
+func (c *Cache) set(id protocol.EdgeId, prev, next *Edge) {
+	if prev != nil {
+		delete(c.byMutualId[prev.MutualId], id)
+		if len(c.byMutualId[prev.MutualId]) == 0 {
+			delete(c.byMutualId, prev.MutualId)
+		}
+		for _, child := range []option.Option[protocol.EdgeId]{prev.LowerChildId, prev.UpperChildId} {
+			if child.IsSome() {
+				delete(c.parents[child.Unwrap()], id)
+				if len(c.parents[child.Unwrap()]) == 0 {
+					delete(c.parents, child.Unwrap())
+				}
+			}
+		}
+	}
+	if next == nil {
+		delete(c.edges, id)
+		return
+	}
+	c.edges[id] = next
+	if c.byMutualId[next.MutualId] == nil {
+		c.byMutualId[next.MutualId] = make(map[protocol.EdgeId]struct{})
+	}
+	c.byMutualId[next.MutualId][id] = struct{}{}
+	for _, child := range []option.Option[protocol.EdgeId]{next.LowerChildId, next.UpperChildId} {
+		if child.IsSome() {
+			if c.parents[child.Unwrap()] == nil {
+				c.parents[child.Unwrap()] = make(map[protocol.EdgeId]struct{})
+			}
+			c.parents[child.Unwrap()][id] = struct{}{}
+		}
+	}
+}
+
+// Rollback undoes the changes of the blocks after a block number.
+func (c *Cache) Rollback(toBlock uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.journal) > 0 && c.journal[len(c.journal)-1].number > toBlock {
+		c.undoLatest()
+	}
+	edgesGauge.Update(int64(len(c.edges)))
+}
+
+// Undoes the changes of the blocks from a block number on, which unlike rolling back to the block
+// before it does not underflow at the genesis block.
+func (c *Cache) rollbackFrom(fromBlock uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.journal) > 0 && c.journal[len(c.journal)-1].number >= fromBlock {
+		c.undoLatest()
+	}
+	edgesGauge.Update(int64(len(c.edges)))
+}
+
+// Must be called with the lock held.
+func (c *Cache) undoLatest() {
+	b := c.journal[len(c.journal)-1]
+	for i := len(b.changes) - 1; i >= 0; i-- {
+		ch := b.changes[i]
+		c.set(ch.id, c.edges[ch.id], ch.prev)
+	}
+	c.journal = c.journal[:len(c.journal)-1]
+	rolledBackCounter.Inc(1)
+	log.Info("Rolled back edge cache changes of reorged block", "number", b.number, "hash", b.hash, "changes", len(b.changes))
+}
+
+// Reconcile rolls back the blocks of the journal which are no longer canonical, comparing them to
+// the headers of the parent chain from the latest one down. It is meant to be called with each new
+// header, as a reorg which removes no logs of the challenge manager is not otherwise noticed.
+//
+// It returns the number of the earliest block rolled back, if any. The logs of the challenge
+// manager from that block on must be handled again from the canonical chain, as the cache no
+// longer holds the changes of any of its blocks. A block whose header the parent chain does not
+// return yet, as from a node which is behind, stops reconciliation without being rolled back.
+func (c *Cache) Reconcile(ctx context.Context, headers HeaderReader) (option.Option[uint64], error) {
+	rolledBackFrom := option.None[uint64]()
+	for {
+		c.lock.RLock()
+		if len(c.journal) == 0 {
+			c.lock.RUnlock()
+			return rolledBackFrom, nil
+		}
+		latest := c.journal[len(c.journal)-1]
+		c.lock.RUnlock()
+		header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(latest.number))
+		if errors.Is(err, ethereum.NotFound) {
+			return rolledBackFrom, nil
+		}
+		if err != nil {
+			return rolledBackFrom, err
+		}
+		if header.Hash() == latest.hash {
+			return rolledBackFrom, nil
+		}
+		c.lock.Lock()
+		if len(c.journal) > 0 && c.journal[len(c.journal)-1] == latest {
+			c.undoLatest()
+			rolledBackFrom = option.Some(latest.number)
+		}
+		edgesGauge.Update(int64(len(c.edges)))
+		c.lock.Unlock()
+	}
+}
+
+// Finalize forgets the changes of the blocks up to a block number, such as the latest finalized
+// block of the parent chain, as they can no longer be reorged out.
+func (c *Cache) Finalize(upToBlock uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	i := 0
+	for i < len(c.journal) && c.journal[i].number <= upToBlock {
+		i++
+	}
+	c.journal = c.journal[i:]
+}
+
+// MarkHonest records that an edge is one we agree with, so that it is followed when computing
+// the honest ancestors of its children. Honesty is not chain state and is kept across rollbacks.
+func (c *Cache) MarkHonest(edgeId protocol.EdgeId) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.honest[edgeId] = struct{}{}
+}
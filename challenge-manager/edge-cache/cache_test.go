@@ -0,0 +1,232 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgecache
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+var challengeManagerAddr = common.Address{0xcc}
+
+// A chain of blocks, each with the state of the edges as of its end.
+type fakeChain struct {
+	headers map[uint64]*types.Header
+	states  map[common.Hash]map[[32]byte]challengeV2gen.ChallengeEdge
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{
+		headers: make(map[uint64]*types.Header),
+		states:  make(map[common.Hash]map[[32]byte]challengeV2gen.ChallengeEdge),
+	}
+}
+
+// Adds a canonical block, replacing any at its height, with the edges of its parent plus updates.
+func (f *fakeChain) block(number uint64, fork string, updates ...challengeV2gen.ChallengeEdge) common.Hash {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte(fork)}
+	state := make(map[[32]byte]challengeV2gen.ChallengeEdge)
+	if parent, ok := f.headers[number-1]; ok {
+		for id, edge := range f.states[parent.Hash()] {
+			state[id] = edge
+		}
+	}
+	for _, edge := range updates {
+		state[edgeId(edge)] = edge
+	}
+	f.headers[number] = header
+	f.states[header.Hash()] = state
+	return header.Hash()
+}
+
+func (f *fakeChain) GetEdge(opts *bind.CallOpts, id [32]byte) (challengeV2gen.ChallengeEdge, error) {
+	state, ok := f.states[opts.BlockHash]
+	if !ok {
+		return challengeV2gen.ChallengeEdge{}, errors.New("unknown block")
+	}
+	return state[id], nil
+}
+
+func (f *fakeChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := f.headers[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+func newEdge(level uint8, start, end uint64, endRoot byte, createdAt uint64) challengeV2gen.ChallengeEdge {
+	return challengeV2gen.ChallengeEdge{
+		OriginId:         common.Hash{0x0a},
+		StartHistoryRoot: common.Hash{0x01},
+		StartHeight:      new(big.Int).SetUint64(start),
+		EndHistoryRoot:   common.Hash{endRoot},
+		EndHeight:        new(big.Int).SetUint64(end),
+		CreatedAtBlock:   createdAt,
+		Level:            level,
+	}
+}
+
+func edgeId(edge challengeV2gen.ChallengeEdge) [32]byte {
+	return protocol.ComputeEdgeId(
+		protocol.ChallengeLevel(edge.Level),
+		protocol.OriginId(edge.OriginId),
+		protocol.Height(edge.StartHeight.Uint64()),
+		edge.StartHistoryRoot,
+		protocol.Height(edge.EndHeight.Uint64()),
+		edge.EndHistoryRoot,
+	).Hash
+}
+
+func eventLog(t *testing.T, name string, number uint64, hash common.Hash, ids ...[32]byte) types.Log {
+	parsed, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	topics := []common.Hash{parsed.Events[name].ID}
+	for _, id := range ids {
+		topics = append(topics, id)
+	}
+	return types.Log{
+		Address:     challengeManagerAddr,
+		Topics:      topics,
+		BlockNumber: number,
+		BlockHash:   hash,
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	cache, err := New(challengeManagerAddr, chain)
+	require.NoError(t, err)
+
+	// A block challenge edge and its rival, then the bisection of the former.
+	root := newEdge(0, 0, 32, 0x02, 1)
+	root.ClaimId = common.Hash{0xaa}
+	rival := newEdge(0, 0, 32, 0x03, 2)
+	rival.ClaimId = common.Hash{0xbb}
+	lower := newEdge(0, 0, 16, 0x04, 3)
+	upper := newEdge(0, 16, 32, 0x02, 3)
+	upper.StartHistoryRoot = common.Hash{0x04}
+	bisectedRoot := root
+	bisectedRoot.LowerChildId = edgeId(lower)
+	bisectedRoot.UpperChildId = edgeId(upper)
+	// A big step edge claiming the lower child.
+	subchallenge := newEdge(1, 0, 8, 0x05, 4)
+	subchallenge.ClaimId = edgeId(lower)
+
+	hash1 := chain.block(1, "", root)
+	hash2 := chain.block(2, "", rival)
+	hash3 := chain.block(3, "", bisectedRoot, lower, upper)
+	hash4 := chain.block(4, "", subchallenge)
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeAdded", 1, hash1, edgeId(root))))
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeAdded", 2, hash2, edgeId(rival))))
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeBisected", 3, hash3, edgeId(root), edgeId(lower), edgeId(upper))))
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeAdded", 4, hash4, edgeId(subchallenge))))
+	require.Equal(t, 5, cache.Len())
+
+	rootId := protocol.EdgeId{Hash: edgeId(root)}
+	cached := cache.Edge(rootId).Unwrap()
+	require.Equal(t, protocol.EdgeId{Hash: edgeId(lower)}, cached.LowerChildId.Unwrap())
+	rivals, err := cache.Rivals(rootId)
+	require.NoError(t, err)
+	require.Equal(t, []protocol.EdgeId{{Hash: edgeId(rival)}}, rivals)
+
+	unrivaled, err := cache.UnrivaledTime(rootId, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), unrivaled)
+	unrivaled, err = cache.UnrivaledTime(protocol.EdgeId{Hash: edgeId(rival)}, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), unrivaled)
+	unrivaled, err = cache.UnrivaledTime(protocol.EdgeId{Hash: edgeId(upper)}, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), unrivaled)
+
+	subchallengeId := protocol.EdgeId{Hash: edgeId(subchallenge)}
+	_, err = cache.HonestAncestors(subchallengeId)
+	require.ErrorIs(t, err, ErrNoHonestParent)
+	cache.MarkHonest(rootId)
+	ancestors, err := cache.HonestAncestors(subchallengeId)
+	require.NoError(t, err)
+	require.Equal(t, []protocol.EdgeId{{Hash: edgeId(lower)}, rootId}, ancestors)
+
+	// A log from another block at the same height reorgs out the subchallenge.
+	forkedHash4 := chain.block(4, "fork")
+	timerUpdated := bisectedRoot
+	timerUpdated.TotalTimeUnrivaledCache = 5
+	chain.states[forkedHash4][edgeId(root)] = timerUpdated
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "TimerCacheUpdated", 4, forkedHash4, edgeId(root))))
+	require.True(t, cache.Edge(subchallengeId).IsNone())
+	require.Equal(t, uint64(5), cache.Edge(rootId).Unwrap().TimeUnrivaledCache)
+
+	// Removing the logs of the bisection undoes it.
+	removed := eventLog(t, "EdgeBisected", 3, hash3, edgeId(root), edgeId(lower), edgeId(upper))
+	removed.Removed = true
+	require.NoError(t, cache.HandleLog(ctx, removed))
+	require.True(t, cache.Edge(rootId).Unwrap().LowerChildId.IsNone())
+	require.True(t, cache.Edge(protocol.EdgeId{Hash: edgeId(lower)}).IsNone())
+	require.Equal(t, uint64(2), cache.LatestBlock().Unwrap().Number)
+
+	// Blocks which are no longer canonical are rolled back on reconciliation, down to the
+	// finalized ones.
+	cache.Finalize(1)
+	chain.block(2, "fork")
+	rolledBackFrom, err := cache.Reconcile(ctx, chain)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), rolledBackFrom.Unwrap())
+	require.True(t, cache.Edge(protocol.EdgeId{Hash: edgeId(rival)}).IsNone())
+	require.True(t, cache.Edge(rootId).IsSome())
+	require.True(t, cache.LatestBlock().IsNone())
+	require.Equal(t, 1, cache.Len())
+}
+
+func TestCache_ReconcileStopsAtUnknownHeader(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	cache, err := New(challengeManagerAddr, chain)
+	require.NoError(t, err)
+	root := newEdge(0, 0, 32, 0x02, 1)
+	hash1 := chain.block(1, "", root)
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeAdded", 1, hash1, edgeId(root))))
+
+	// A parent chain node which is behind has no header at the height of the cache yet.
+	delete(chain.headers, 1)
+	rolledBackFrom, err := cache.Reconcile(ctx, chain)
+	require.NoError(t, err)
+	require.True(t, rolledBackFrom.IsNone())
+	require.Equal(t, 1, cache.Len())
+}
+
+func TestCache_RollsBackGenesisBlock(t *testing.T) {
+	ctx := context.Background()
+	chain := newFakeChain()
+	cache, err := New(challengeManagerAddr, chain)
+	require.NoError(t, err)
+	root := newEdge(0, 0, 32, 0x02, 1)
+	hash0 := chain.block(0, "", root)
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeAdded", 0, hash0, edgeId(root))))
+	require.Equal(t, 1, cache.Len())
+
+	// A log from another block at height zero reorgs out the edge, rather than underflowing the
+	// block to roll back to.
+	rival := newEdge(0, 0, 32, 0x03, 1)
+	forkedHash0 := chain.block(0, "fork", rival)
+	require.NoError(t, cache.HandleLog(ctx, eventLog(t, "EdgeAdded", 0, forkedHash0, edgeId(rival))))
+	require.True(t, cache.Edge(protocol.EdgeId{Hash: edgeId(root)}).IsNone())
+	require.Equal(t, 1, cache.Len())
+
+	removed := eventLog(t, "EdgeAdded", 0, forkedHash0, edgeId(rival))
+	removed.Removed = true
+	require.NoError(t, cache.HandleLog(ctx, removed))
+	require.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,143 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgecache
+
+import (
+	"sort"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Edge returns a copy of an edge in the cache.
+func (c *Cache) Edge(edgeId protocol.EdgeId) option.Option[Edge] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	edge, ok := c.edges[edgeId]
+	if !ok {
+		return option.None[Edge]()
+	}
+	return option.Some(*edge)
+}
+
+// Len is the number of edges in the cache.
+func (c *Cache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.edges)
+}
+
+// LatestBlock is the number and hash of the latest block whose changes may still be rolled back.
+func (c *Cache) LatestBlock() option.Option[BlockId] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if len(c.journal) == 0 {
+		return option.None[BlockId]()
+	}
+	latest := c.journal[len(c.journal)-1]
+	return option.Some(BlockId{Number: latest.number, Hash: latest.hash})
+}
+
+// BlockId identifies a block of the parent chain.
+type BlockId struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// Rivals of an edge, which share its mutual id, sorted by id.
+func (c *Cache) Rivals(edgeId protocol.EdgeId) ([]protocol.EdgeId, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	edge, ok := c.edges[edgeId]
+	if !ok {
+		return nil, errors.Wrapf(ErrNotFound, "edge %#x", edgeId.Hash)
+	}
+	return c.rivals(edge), nil
+}
+
+// Must be called with the lock held.
+func (c *Cache) rivals(edge *Edge) []protocol.EdgeId {
+	rivals := make([]protocol.EdgeId, 0, len(c.byMutualId[edge.MutualId]))
+	for id := range c.byMutualId[edge.MutualId] {
+		if id != edge.Id {
+			rivals = append(rivals, id)
+		}
+	}
+	sort.Slice(rivals, func(i, j int) bool {
+		return rivals[i].Hash.Cmp(rivals[j].Hash) < 0
+	})
+	return rivals
+}
+
+// UnrivaledTime is the number of blocks an edge was unrivaled for up to a block, the same way as
+// the local timer of the challenge manager: from its creation until the creation of its earliest
+// rival, or until the block if it had no rival yet.
+func (c *Cache) UnrivaledTime(edgeId protocol.EdgeId, blockNum uint64) (uint64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	edge, ok := c.edges[edgeId]
+	if !ok {
+		return 0, errors.Wrapf(ErrNotFound, "edge %#x", edgeId.Hash)
+	}
+	if blockNum <= edge.CreatedAtBlock {
+		return 0, nil
+	}
+	end := blockNum
+	for _, rivalId := range c.rivals(edge) {
+		if rival := c.edges[rivalId]; rival.CreatedAtBlock < end {
+			end = rival.CreatedAtBlock
+		}
+	}
+	if end <= edge.CreatedAtBlock {
+		return 0, nil
+	}
+	return end - edge.CreatedAtBlock, nil
+}
+
+// HonestAncestors of an edge marked honest, from its parent up to and including the root of the
+// block challenge. Within a level, an edge's parent is the honest edge it is a child of, which is
+// unique though a lower child may also be shared with a rival. Across levels, the root of a
+// subchallenge is followed by the edge it claims at the level above.
+func (c *Cache) HonestAncestors(edgeId protocol.EdgeId) ([]protocol.EdgeId, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	edge, ok := c.edges[edgeId]
+	if !ok {
+		return nil, errors.Wrapf(ErrNotFound, "edge %#x", edgeId.Hash)
+	}
+	ancestors := make([]protocol.EdgeId, 0)
+	for {
+		if edge.ClaimId.IsSome() {
+			if edge.Level.IsBlockChallengeLevel() {
+				return ancestors, nil
+			}
+			claimedId := protocol.EdgeId{Hash: common.Hash(edge.ClaimId.Unwrap())}
+			claimed, ok := c.edges[claimedId]
+			if !ok {
+				return nil, errors.Wrapf(ErrNotFound, "edge %#x claimed by %#x", claimedId.Hash, edge.Id.Hash)
+			}
+			ancestors = append(ancestors, claimedId)
+			edge = claimed
+			continue
+		}
+		parent, ok := c.honestParent(edge.Id)
+		if !ok {
+			return nil, errors.Wrapf(ErrNoHonestParent, "edge %#x", edge.Id.Hash)
+		}
+		ancestors = append(ancestors, parent.Id)
+		edge = parent
+	}
+}
+
+// Must be called with the lock held.
+func (c *Cache) honestParent(edgeId protocol.EdgeId) (*Edge, bool) {
+	for parentId := range c.parents[edgeId] {
+		if _, ok := c.honest[parentId]; ok {
+			return c.edges[parentId], true
+		}
+	}
+	return nil, false
+}
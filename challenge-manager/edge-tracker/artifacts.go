@@ -0,0 +1,30 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/challenge-manager/artifacts"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Records a move on our edge in the working directory of its challenge, if the challenge manager
+// keeps one. Artifacts are for operators inspecting a challenge and do not fail the move, so
+// errors are only logged.
+func (et *Tracker) recordMove(ctx context.Context, move *artifacts.Move) {
+	store := et.challengeManager.Artifacts()
+	if store == nil {
+		return
+	}
+	assertionHash, err := et.edge.AssertionHash(ctx)
+	if err != nil {
+		log.Warn("Could not get assertion hash to record challenge artifacts", append(et.uniqueTrackerLogFields(), "err", err)...)
+		return
+	}
+	move.EdgeId = et.edge.Id().Hash
+	if err = store.RecordMove(assertionHash.Hash, move); err != nil {
+		log.Warn("Could not record challenge artifacts", append(et.uniqueTrackerLogFields(), "kind", move.Kind, "err", err)...)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"fmt"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/pkg/errors"
+)
+
+// BlockingReasonKind enumerates the conditions that can prevent a tracked edge
+// from making progress towards confirmation.
+type BlockingReasonKind uint8
+
+const (
+	// The edge's onchain inherited timer has not yet reached a challenge period.
+	BlockedByInsufficientTimer BlockingReasonKind = iota
+	// The locally computed timer is sufficient, but it has not yet been
+	// propagated onchain through the royal branch of the challenge tree.
+	BlockedByPendingTimerPropagation
+	// The edge has been bisected and inherits its timer from a child that is not yet confirmed.
+	BlockedByUnconfirmedChild
+	// The edge is at a one step fork and inherits its timer from a level zero
+	// edge in the next challenge level that claims it.
+	BlockedByPendingClaim
+	// The edge is at a one step fork in a small step challenge and awaits a one step proof.
+	BlockedByOneStepProof
+)
+
+// String turns a blocking reason kind into a readable string.
+func (k BlockingReasonKind) String() string {
+	switch k {
+	case BlockedByInsufficientTimer:
+		return "insufficient_timer"
+	case BlockedByPendingTimerPropagation:
+		return "pending_timer_propagation"
+	case BlockedByUnconfirmedChild:
+		return "unconfirmed_child"
+	case BlockedByPendingClaim:
+		return "pending_claim"
+	case BlockedByOneStepProof:
+		return "one_step_proof"
+	default:
+		return "unknown"
+	}
+}
+
+// BlockingReason describes exactly why a tracked edge cannot yet be confirmed, optionally
+// pointing to the edge which is blocking its progress.
+type BlockingReason struct {
+	Kind         BlockingReasonKind
+	BlockingEdge option.Option[protocol.EdgeId]
+	Detail       string
+}
+
+// BlockedBy analyzes the tracked edge and enumerates every condition that currently prevents
+// it from being confirmed. An empty list is returned if the edge is already confirmed, or if its
+// onchain timer suffices to confirm it by time, as nothing else then stands in its way.
+func (et *Tracker) BlockedBy(ctx context.Context) ([]*BlockingReason, error) {
+	reasons := make([]*BlockingReason, 0)
+	status, err := et.edge.Status(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edge status")
+	}
+	if status == protocol.EdgeConfirmed {
+		return reasons, nil
+	}
+	manager, err := et.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get challenge manager")
+	}
+	chalPeriod, err := manager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check the challenge period length")
+	}
+	onchainTimer, err := et.edge.SafeHeadInheritedTimer(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edge onchain inherited timer")
+	}
+	if onchainTimer >= protocol.InheritedTimer(chalPeriod) {
+		return reasons, nil
+	}
+
+	// An edge with children inherits its timer from the smallest timer among them,
+	// so any unconfirmed child blocks the edge.
+	lowerChild, err := et.edge.LowerChild(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get lower child")
+	}
+	upperChild, err := et.edge.UpperChild(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get upper child")
+	}
+	for _, child := range []option.Option[protocol.EdgeId]{lowerChild, upperChild} {
+		if child.IsNone() {
+			continue
+		}
+		childEdge, err := manager.GetEdge(ctx, child.Unwrap())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get child edge %#x", child.Unwrap().Hash)
+		}
+		if childEdge.IsNone() {
+			continue
+		}
+		childStatus, err := childEdge.Unwrap().Status(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get child edge status")
+		}
+		if childStatus != protocol.EdgeConfirmed {
+			reasons = append(reasons, &BlockingReason{
+				Kind:         BlockedByUnconfirmedChild,
+				BlockingEdge: child,
+				Detail:       fmt.Sprintf("child edge %#x is %s", child.Unwrap().Bytes()[:4], childStatus),
+			})
+		}
+	}
+
+	// A length one edge that has a rival can only gain timer from the level below it.
+	if lowerChild.IsNone() && upperChild.IsNone() {
+		atOneStepFork, err := et.edge.HasLengthOneRival(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not check if edge has length one rival")
+		}
+		if atOneStepFork {
+			canOsp, err := canOneStepProve(ctx, et.edge)
+			if err != nil {
+				return nil, err
+			}
+			if canOsp {
+				reasons = append(reasons, &BlockingReason{
+					Kind:         BlockedByOneStepProof,
+					BlockingEdge: option.None[protocol.EdgeId](),
+					Detail:       "edge must be confirmed by a one step proof",
+				})
+			} else {
+				reasons = append(reasons, &BlockingReason{
+					Kind:         BlockedByPendingClaim,
+					BlockingEdge: option.None[protocol.EdgeId](),
+					Detail: fmt.Sprintf(
+						"edge awaits a level zero edge claiming it in challenge level %d",
+						et.edge.GetChallengeLevel().Next().Uint8(),
+					),
+				})
+			}
+		}
+	}
+
+	if IsRootBlockChallengeEdge(et.edge) {
+		assertionHash, err := et.edge.AssertionHash(ctx)
+		if err != nil {
+			return nil, err
+		}
+		computedTimer, err := et.chainWatcher.ComputeRootInheritedTimer(ctx, assertionHash)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not compute root inherited timer")
+		}
+		if uint64(computedTimer) >= chalPeriod {
+			reasons = append(reasons, &BlockingReason{
+				Kind:         BlockedByPendingTimerPropagation,
+				BlockingEdge: option.None[protocol.EdgeId](),
+				Detail: fmt.Sprintf(
+					"local timer %d is confirmable but onchain timer is %d, need %d",
+					computedTimer,
+					onchainTimer,
+					chalPeriod,
+				),
+			})
+			return reasons, nil
+		}
+	}
+	reasons = append(reasons, &BlockingReason{
+		Kind:         BlockedByInsufficientTimer,
+		BlockingEdge: option.None[protocol.EdgeId](),
+		Detail:       fmt.Sprintf("onchain timer is %d, need %d", onchainTimer, chalPeriod),
+	})
+	return reasons, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type rootTimerWatcher struct {
+	RoyalChallengeWriter
+	timer protocol.InheritedTimer
+}
+
+func (w *rootTimerWatcher) ComputeRootInheritedTimer(context.Context, protocol.AssertionHash) (protocol.InheritedTimer, error) {
+	return w.timer, nil
+}
+
+func TestTracker_BlockedBy(t *testing.T) {
+	ctx := context.Background()
+	const challengePeriod = 100
+	lowerChildId := protocol.EdgeId{Hash: common.BytesToHash([]byte("lower"))}
+	upperChildId := protocol.EdgeId{Hash: common.BytesToHash([]byte("upper"))}
+	for _, tt := range []struct {
+		name        string
+		status      protocol.EdgeStatus
+		statusErr   error
+		timer       protocol.InheritedTimer
+		lowerChild  option.Option[protocol.EdgeStatus]
+		upperChild  option.Option[protocol.EdgeStatus]
+		oneStepFork bool
+		level       protocol.ChallengeLevel
+		rootEdge    bool
+		rootTimer   protocol.InheritedTimer
+		want        []BlockingReasonKind
+		wantEdges   []option.Option[protocol.EdgeId]
+		wantErr     string
+	}{
+		{
+			name:   "confirmed",
+			status: protocol.EdgeConfirmed,
+		},
+		{
+			name:       "onchain timer suffices despite unconfirmed children",
+			timer:      challengePeriod,
+			lowerChild: option.Some(protocol.EdgePending),
+			upperChild: option.Some(protocol.EdgePending),
+		},
+		{
+			name:       "unconfirmed child",
+			timer:      challengePeriod - 1,
+			lowerChild: option.Some(protocol.EdgePending),
+			upperChild: option.Some(protocol.EdgeConfirmed),
+			want:       []BlockingReasonKind{BlockedByUnconfirmedChild, BlockedByInsufficientTimer},
+			wantEdges:  []option.Option[protocol.EdgeId]{option.Some(lowerChildId), option.None[protocol.EdgeId]()},
+		},
+		{
+			name:       "both children unconfirmed",
+			lowerChild: option.Some(protocol.EdgePending),
+			upperChild: option.Some(protocol.EdgePending),
+			want:       []BlockingReasonKind{BlockedByUnconfirmedChild, BlockedByUnconfirmedChild, BlockedByInsufficientTimer},
+			wantEdges:  []option.Option[protocol.EdgeId]{option.Some(lowerChildId), option.Some(upperChildId), option.None[protocol.EdgeId]()},
+		},
+		{
+			name:        "one step fork in a small step challenge",
+			oneStepFork: true,
+			level:       2,
+			want:        []BlockingReasonKind{BlockedByOneStepProof, BlockedByInsufficientTimer},
+		},
+		{
+			name:        "one step fork above the small step level",
+			oneStepFork: true,
+			level:       1,
+			want:        []BlockingReasonKind{BlockedByPendingClaim, BlockedByInsufficientTimer},
+		},
+		{
+			name:      "root edge with a confirmable local timer",
+			rootEdge:  true,
+			rootTimer: challengePeriod,
+			want:      []BlockingReasonKind{BlockedByPendingTimerPropagation},
+		},
+		{
+			name:      "root edge with an insufficient local timer",
+			rootEdge:  true,
+			rootTimer: challengePeriod - 1,
+			want:      []BlockingReasonKind{BlockedByInsufficientTimer},
+		},
+		{
+			name:      "status unavailable",
+			statusErr: errors.New("rpc down"),
+			wantErr:   "could not get edge status",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &mocks.MockSpecChallengeManager{}
+			manager.On("ChallengePeriodBlocks", ctx).Return(uint64(challengePeriod), nil)
+			chain := &mocks.MockProtocol{}
+			chain.On("SpecChallengeManager", ctx).Return(manager, nil)
+
+			edge := &mocks.MockSpecEdge{}
+			edge.On("Status", ctx).Return(tt.status, tt.statusErr)
+			edge.On("SafeHeadInheritedTimer", ctx).Return(tt.timer, nil)
+			for _, child := range []struct {
+				method string
+				id     protocol.EdgeId
+				status option.Option[protocol.EdgeStatus]
+			}{
+				{"LowerChild", lowerChildId, tt.lowerChild},
+				{"UpperChild", upperChildId, tt.upperChild},
+			} {
+				if child.status.IsNone() {
+					edge.On(child.method, ctx).Return(option.None[protocol.EdgeId](), nil)
+					continue
+				}
+				childEdge := &mocks.MockSpecEdge{}
+				childEdge.On("Status", ctx).Return(child.status.Unwrap(), nil)
+				edge.On(child.method, ctx).Return(option.Some(child.id), nil)
+				manager.On("GetEdge", ctx, child.id).Return(option.Some[protocol.SpecEdge](childEdge), nil)
+			}
+			edge.On("HasLengthOneRival", ctx).Return(tt.oneStepFork, nil)
+			edge.On("StartCommitment").Return(protocol.Height(0), common.Hash{})
+			edge.On("EndCommitment").Return(protocol.Height(1), common.Hash{})
+			edge.On("GetChallengeLevel").Return(tt.level)
+			edge.On("GetTotalChallengeLevels", ctx).Return(uint8(3))
+			claimId := option.None[protocol.ClaimId]()
+			if tt.rootEdge {
+				claimId = option.Some(protocol.ClaimId{1})
+			}
+			edge.On("ClaimId").Return(claimId)
+			edge.On("AssertionHash", ctx).Return(protocol.AssertionHash{}, nil)
+
+			tracker := &Tracker{edge: edge, chain: chain, chainWatcher: &rootTimerWatcher{timer: tt.rootTimer}}
+			reasons, err := tracker.BlockedBy(ctx)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			kinds := make([]BlockingReasonKind, 0, len(reasons))
+			for _, reason := range reasons {
+				kinds = append(kinds, reason.Kind)
+			}
+			if tt.want == nil {
+				tt.want = []BlockingReasonKind{}
+			}
+			require.Equal(t, tt.want, kinds)
+			for i, wantEdge := range tt.wantEdges {
+				require.Equal(t, wantEdge, reasons[i].BlockingEdge)
+			}
+		})
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	challengetypes "github.com/OffchainLabs/bold/challenge-manager/types"
 	retry "github.com/OffchainLabs/bold/runtime"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -14,7 +17,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-var onchainTimerDifferAfterConfirmationJobCounter = metrics.NewRegisteredCounter("arb/validator/tracker/onchain_timer_differed_after_confirmation_job", nil)
+var (
+	onchainTimerDifferAfterConfirmationJobCounter = metrics.NewRegisteredCounter("arb/validator/tracker/onchain_timer_differed_after_confirmation_job", nil)
+	// Returned by a confirmation job which could not propagate the timer of the root edge onchain
+	// because of the confirmation methods an operator disabled.
+	errConfirmationMethodsDisabled = errors.New("confirmation methods needed to propagate timers are disabled")
+)
 
 // Defines a struct which can handle confirming of an entire challenge tree
 // in the BOLD protocol. It does so by updating the inherited timers of royal edges
@@ -28,6 +36,8 @@ type challengeConfirmer struct {
 	validatorName               string
 	averageTimeForBlockCreation time.Duration
 	chain                       protocol.Protocol
+	auditLog                    *db.AuditLogger
+	methods                     *challengetypes.ConfirmationMethods
 }
 
 // Defines a chain writer interface that is
@@ -64,6 +74,8 @@ func newChallengeConfirmer(
 	averageTimeForBlockCreation time.Duration,
 	validatorName string,
 	chain protocol.Protocol,
+	auditLog *db.AuditLogger,
+	methods *challengetypes.ConfirmationMethods,
 ) *challengeConfirmer {
 	return &challengeConfirmer{
 		reader:                      challengeReader,
@@ -72,6 +84,8 @@ func newChallengeConfirmer(
 		averageTimeForBlockCreation: averageTimeForBlockCreation,
 		backend:                     backend,
 		chain:                       chain,
+		auditLog:                    auditLog,
+		methods:                     methods,
 	}
 }
 
@@ -86,7 +100,9 @@ func newChallengeConfirmer(
 //
 // This function must only be called once the locally computed value of the block challenge, royal root
 // edge has an inherited timer that is confirmable. This function MUST complete, and it will retry
-// any external call if it errors during its execution.
+// any external call if it errors during its execution. Branches are only updated with the
+// confirmation methods that are enabled, and errConfirmationMethodsDisabled is returned if that
+// did not suffice to make the root edge confirmable.
 func (cc *challengeConfirmer) beginConfirmationJob(
 	ctx context.Context,
 	challengedAssertionHash protocol.AssertionHash,
@@ -109,7 +125,7 @@ func (cc *challengeConfirmer) beginConfirmationJob(
 			return nil, innerErr
 		}
 		return edges, nil
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
 	if err != nil {
 		return err
 	}
@@ -130,11 +146,14 @@ func (cc *challengeConfirmer) beginConfirmationJob(
 				return nil, innerErr
 			}
 			return resp, nil
-		})
+		}, retry.WithPolicy(retry.ConfirmationCritical))
 		if err2 != nil {
 			return err2
 		}
 		branch = append(branch, ancestors...)
+		if branch = cc.routeAroundDisabledMethods(branch); len(branch) == 0 {
+			continue
+		}
 		royalBranches = append(royalBranches, branch)
 	}
 	log.Info("Computed all the royal branches to update onchain", fields...)
@@ -156,7 +175,9 @@ func (cc *challengeConfirmer) beginConfirmationJob(
 		if innerErr != nil {
 			return innerErr
 		}
-		lastPropagationTx = tx
+		if tx != nil {
+			lastPropagationTx = tx
+		}
 	}
 
 	// Instead, we wait for the last transaction we made to reach `safe` head if it is not nil
@@ -178,7 +199,7 @@ func (cc *challengeConfirmer) beginConfirmationJob(
 			return 0, innerErr
 		}
 		return timer, nil
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
 	if err != nil {
 		return err
 	}
@@ -188,6 +209,13 @@ func (cc *challengeConfirmer) beginConfirmationJob(
 	// In this scenario, we can dump the confirmation job of royal edges for manual
 	// inspection and debugging
 	if onchainInheritedTimer < protocol.InheritedTimer(challengePeriodBlocks) {
+		if disabled := cc.methods.Disabled(); len(disabled) > 0 {
+			log.Warn(
+				fmt.Sprintf("Onchain timer %d was not >= %d after confirmation job with disabled methods", onchainInheritedTimer, challengePeriodBlocks),
+				append(fields, "disabledMethods", disabled)...,
+			)
+			return errConfirmationMethodsDisabled
+		}
 		onchainTimerDifferAfterConfirmationJobCounter.Inc(1)
 		log.Error(
 			fmt.Sprintf("Onchain timer %d was not >= %d after confirmation job", onchainInheritedTimer, challengePeriodBlocks),
@@ -200,19 +228,43 @@ func (cc *challengeConfirmer) beginConfirmationJob(
 		)
 	}
 	log.Info("Confirming edge by time", fields...)
-	if _, err = retry.UntilSucceeds(ctx, func() (bool, error) {
-		if _, innerErr := royalRootEdge.ConfirmByTimer(ctx); innerErr != nil {
+	tx, err := retry.UntilSucceeds(ctx, func() (*types.Transaction, error) {
+		innerTx, innerErr := royalRootEdge.ConfirmByTimer(ctx)
+		if innerErr != nil {
 			log.Error("Could not confirm edge by timer", fields, "err", innerErr)
-			return false, innerErr
+			return nil, innerErr
 		}
-		return false, nil
-	}); err != nil {
+		return innerTx, nil
+	}, retry.WithPolicy(retry.ConfirmationCritical))
+	cc.auditLog.Record(api.AuditActionConfirmEdgeByTime, map[string]any{
+		"edgeId": royalRootEdge.Id().Hash,
+	}, auditTxResult(tx), err)
+	if err != nil {
 		return err
 	}
 	log.Info("Challenge root edge confirmed, assertion can now be confirmed to finish challenge", fields...)
 	return nil
 }
 
+// Routes the update of a royal branch around the disabled confirmation methods. Without updates by
+// children, no timer can be propagated towards the root edge, so the branch is skipped. Without
+// updates by claim, timers cannot cross challenge levels, so only the block challenge level edges
+// of the branch, which are the last ones, are updated.
+func (cc *challengeConfirmer) routeAroundDisabledMethods(branch []protocol.ReadOnlyEdge) []protocol.ReadOnlyEdge {
+	if !cc.methods.Enabled(challengetypes.ConfirmByChildren) {
+		return nil
+	}
+	if cc.methods.Enabled(challengetypes.ConfirmByClaim) {
+		return branch
+	}
+	for i, edge := range branch {
+		if edge.GetChallengeLevel() == protocol.NewBlockChallengeLevel() {
+			return branch[i:]
+		}
+	}
+	return nil
+}
+
 func (cc *challengeConfirmer) propageTimerUpdateToBranch(
 	ctx context.Context,
 	royalRootEdge protocol.SpecEdge,
@@ -239,7 +291,7 @@ func (cc *challengeConfirmer) propageTimerUpdateToBranch(
 			return nil, innerErr
 		}
 		return tx, nil
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +304,7 @@ func (cc *challengeConfirmer) propageTimerUpdateToBranch(
 			return 0, innerErr
 		}
 		return timer, nil
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +325,10 @@ func (cc *challengeConfirmer) propageTimerUpdateToBranch(
 			return nil, innerErr
 		}
 		return innerTx, nil
-	})
+	}, retry.WithPolicy(retry.ConfirmationCritical))
+	cc.auditLog.Record(api.AuditActionConfirmEdgeByTime, map[string]any{
+		"edgeId": royalRootEdge.Id().Hash,
+	}, auditTxResult(tx), err)
 	if err != nil {
 		return nil, err
 	}
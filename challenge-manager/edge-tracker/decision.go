@@ -0,0 +1,218 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/math"
+	"github.com/pkg/errors"
+)
+
+// ErrEdgeNotYetCreated is returned when reconstructing the decision of a tracker at a block
+// before its edge was created.
+var ErrEdgeNotYetCreated = errors.New("edge not yet created")
+
+// DecisionKind enumerates the actions a tracker can decide on from its start state.
+type DecisionKind uint8
+
+const (
+	// The edge is already confirmed, so the tracker awaits the completion of the challenge.
+	DecideAwaitChallengeCompletion DecisionKind = iota
+	// The edge is of length one in a small step challenge and can be one step proven.
+	DecideOneStepProof
+	// The edge's onchain inherited timer has reached a challenge period.
+	DecideConfirmByTime
+	// The locally computed timer has reached a challenge period, so a confirmation job
+	// propagates it onchain through the royal branch of the challenge tree.
+	DecideConfirmByTimerPropagation
+	// The edge has no rival, so there is nothing to do yet.
+	DecideWaitForRival
+	// The edge has a rival and is of length one, so the tracker opens a subchallenge leaf.
+	DecideOpenSubchallengeLeaf
+	// The edge has a rival, so the tracker bisects it.
+	DecideBisect
+)
+
+// String turns a decision kind into a readable string.
+func (k DecisionKind) String() string {
+	switch k {
+	case DecideAwaitChallengeCompletion:
+		return "await_challenge_completion"
+	case DecideOneStepProof:
+		return "one_step_proof"
+	case DecideConfirmByTime:
+		return "confirm_by_time"
+	case DecideConfirmByTimerPropagation:
+		return "confirm_by_timer_propagation"
+	case DecideWaitForRival:
+		return "wait_for_rival"
+	case DecideOpenSubchallengeLeaf:
+		return "open_subchallenge_leaf"
+	case DecideBisect:
+		return "bisect"
+	default:
+		return "unknown"
+	}
+}
+
+// Decision is the action a tracker decides on from its start state, along with the checks that
+// led to it in the order the tracker makes them.
+type Decision struct {
+	Kind        DecisionKind
+	BlockNumber option.Option[uint64]
+	Reasons     []string
+	// The height the edge is bisected at, if the tracker decides to bisect.
+	BisectionHeight option.Option[uint64]
+}
+
+// DecideAt reconstructs the decision the tracker makes from its start state at a past block, by
+// running its decision logic with all contract reads pinned to that block. This answers what the
+// tracker did, or would have, decided at the block and why, such as why a bisection was or was
+// not sent. The locally computed timer of a root block challenge edge is taken from the chain
+// watcher, which only knows the current state of the challenge, so a confirmation by timer
+// propagation may be reported earlier than it was possible.
+func (et *Tracker) DecideAt(ctx context.Context, blockNumber uint64) (*Decision, error) {
+	createdAt, err := et.edge.CreatedAtBlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edge creation block")
+	}
+	if blockNumber < createdAt {
+		return nil, errors.Wrapf(ErrEdgeNotYetCreated, "edge created at block %d, after block %d", createdAt, blockNumber)
+	}
+	header, err := et.chain.Backend().HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get header of block %d", blockNumber)
+	}
+	decision, err := et.decide(protocol.PinReads(ctx, header))
+	if err != nil {
+		return nil, err
+	}
+	decision.BlockNumber = option.Some(header.Number.Uint64())
+	return decision, nil
+}
+
+// Mirrors the checks the tracker makes in its start state in Act, recording the outcome of each.
+func (et *Tracker) decide(ctx context.Context) (*Decision, error) {
+	decision := &Decision{
+		BisectionHeight: option.None[uint64](),
+	}
+	status, err := et.edge.Status(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edge status")
+	}
+	if status == protocol.EdgeConfirmed {
+		decision.Kind = DecideAwaitChallengeCompletion
+		decision.Reasons = append(decision.Reasons, "edge is confirmed")
+		return decision, nil
+	}
+	canOsp, err := canOneStepProve(ctx, et.edge)
+	if err != nil {
+		return nil, err
+	}
+	if canOsp {
+		decision.Kind = DecideOneStepProof
+		decision.Reasons = append(decision.Reasons, "edge is of length one in a small step challenge")
+		return decision, nil
+	}
+	decision.Reasons = append(decision.Reasons, "edge cannot be one step proven")
+	confirmable, err := et.decideConfirmation(ctx, decision)
+	if err != nil {
+		return nil, err
+	}
+	if confirmable {
+		return decision, nil
+	}
+	hasRival, err := et.edge.HasRival(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check if edge has rival")
+	}
+	if !hasRival {
+		decision.Kind = DecideWaitForRival
+		decision.Reasons = append(decision.Reasons, "edge has no rival")
+		return decision, nil
+	}
+	decision.Reasons = append(decision.Reasons, "edge has a rival")
+	atOneStepFork, err := et.edge.HasLengthOneRival(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check if edge has length one rival")
+	}
+	if atOneStepFork {
+		decision.Kind = DecideOpenSubchallengeLeaf
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+			"edge is at a one step fork, so it is claimed in challenge level %d",
+			et.edge.GetChallengeLevel().Next().Uint8(),
+		))
+		return decision, nil
+	}
+	startHeight, _ := et.edge.StartCommitment()
+	endHeight, _ := et.edge.EndCommitment()
+	bisectTo, err := math.Bisect(uint64(startHeight), uint64(endHeight))
+	if err != nil {
+		return nil, errors.Wrapf(err, "determining bisection point errored for %d and %d", startHeight, endHeight)
+	}
+	decision.Kind = DecideBisect
+	decision.BisectionHeight = option.Some(bisectTo)
+	decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+		"edge from height %d to %d is not at a one step fork, so it is bisected at height %d",
+		startHeight,
+		endHeight,
+		bisectTo,
+	))
+	return decision, nil
+}
+
+// Mirrors the checks of tryToConfirmEdge, returning true if the tracker would confirm the edge.
+func (et *Tracker) decideConfirmation(ctx context.Context, decision *Decision) (bool, error) {
+	if !IsRootBlockChallengeEdge(et.edge) {
+		decision.Reasons = append(decision.Reasons, "edge is not a root block challenge edge, so it is not confirmed by its tracker")
+		return false, nil
+	}
+	manager, err := et.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "could not get challenge manager")
+	}
+	chalPeriod, err := manager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "could not check the challenge period length")
+	}
+	onchainTimer, err := et.edge.SafeHeadInheritedTimer(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "could not get edge onchain inherited timer")
+	}
+	if onchainTimer >= protocol.InheritedTimer(chalPeriod) {
+		decision.Kind = DecideConfirmByTime
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf("onchain timer %d reached challenge period %d", onchainTimer, chalPeriod))
+		return true, nil
+	}
+	assertionHash, err := et.edge.AssertionHash(ctx)
+	if err != nil {
+		return false, err
+	}
+	computedTimer, err := et.chainWatcher.ComputeRootInheritedTimer(ctx, assertionHash)
+	if err != nil {
+		return false, errors.Wrap(err, "could not compute root inherited timer")
+	}
+	if uint64(computedTimer) >= chalPeriod {
+		decision.Kind = DecideConfirmByTimerPropagation
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+			"local timer %d reached challenge period %d, but onchain timer is %d",
+			computedTimer,
+			chalPeriod,
+			onchainTimer,
+		))
+		return true, nil
+	}
+	decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+		"onchain timer %d and local timer %d are below challenge period %d",
+		onchainTimer,
+		computedTimer,
+		chalPeriod,
+	))
+	return false, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/latency"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// Records the latency of a counter-move included in moveBlock, from the block at which our edge
+// was rivaled. Moves on edges that were never rivaled are not counter-moves, and are not recorded.
+// Failing to measure the latency does not fail the move, so errors are only logged.
+func (et *Tracker) recordCounterMove(ctx context.Context, kind latency.MoveKind, moveBlock uint64) {
+	budget := et.challengeManager.LatencyBudget()
+	if budget == nil {
+		return
+	}
+	// The move was just included, so reads must not be pinned to a block before it.
+	ctx = protocol.UnpinReads(ctx)
+	sample, err := et.counterMoveSample(ctx, kind, moveBlock)
+	if err != nil {
+		log.Warn("Could not measure counter-move latency", append(et.uniqueTrackerLogFields(), "kind", kind, "err", err)...)
+		return
+	}
+	if sample == nil {
+		return
+	}
+	if budget.ChallengePeriodBlocks() == 0 {
+		manager, err := et.chain.SpecChallengeManager(ctx)
+		if err != nil {
+			log.Warn("Could not get challenge manager to read the challenge period", "err", err)
+		} else if period, err := manager.ChallengePeriodBlocks(ctx); err != nil {
+			log.Warn("Could not read the challenge period for the latency budget", "err", err)
+		} else {
+			budget.SetChallengePeriodBlocks(period)
+		}
+	}
+	budget.Record(*sample)
+}
+
+func (et *Tracker) counterMoveSample(ctx context.Context, kind latency.MoveKind, moveBlock uint64) (*latency.Sample, error) {
+	hasRival, err := et.edge.HasRival(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check if edge has a rival")
+	}
+	if !hasRival {
+		return nil, nil
+	}
+	createdAt, err := et.edge.CreatedAtBlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edge creation block")
+	}
+	// Once an edge is rivaled, the time it was unrivaled stops at the block of its first rival.
+	unrivaled, err := et.edge.TimeUnrivaled(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get edge time unrivaled")
+	}
+	rivalBlock := createdAt + unrivaled
+	backend := et.chain.Backend()
+	rivalHeader, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(rivalBlock))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get header of rival block %d", rivalBlock)
+	}
+	moveHeader, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(moveBlock))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get header of move block %d", moveBlock)
+	}
+	var elapsed time.Duration
+	if moveHeader.Time > rivalHeader.Time {
+		elapsed = time.Duration(moveHeader.Time-rivalHeader.Time) * time.Second
+	}
+	return &latency.Sample{
+		Level:      et.edge.GetChallengeLevel(),
+		Kind:       kind,
+		EdgeId:     et.edge.Id(),
+		RivalBlock: rivalBlock,
+		MoveBlock:  moveBlock,
+		Latency:    elapsed,
+	}, nil
+}
@@ -0,0 +1,234 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OffchainLabs/bold/containers/option"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/math"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+var (
+	prefetchComputedCounter  = metrics.NewRegisteredCounter("arb/validator/tracker/prefetch/computed", nil)
+	prefetchHitCounter       = metrics.NewRegisteredCounter("arb/validator/tracker/prefetch/hits", nil)
+	prefetchThrottledCounter = metrics.NewRegisteredCounter("arb/validator/tracker/prefetch/throttled", nil)
+)
+
+const (
+	defaultPrefetchDepth     = 2
+	maxPrefetchDepth         = 3
+	defaultPrefetchCacheSize = 256
+)
+
+// HeightRange is the range of heights an edge spans at its challenge level.
+type HeightRange struct {
+	StartHeight uint64
+	EndHeight   uint64
+}
+
+// PredictedBisectionPath predicts the edges bisected after an edge from start to end, given the
+// first height at which the rival history diverges from ours. Rivals share the child of each edge
+// which ends before the divergence, so the path follows the child containing it, for up to depth
+// edges and until edges of length one which cannot be bisected.
+func PredictedBisectionPath(start, end, divergence uint64, depth int) []HeightRange {
+	path := make([]HeightRange, 0, depth)
+	for len(path) < depth {
+		mid, err := math.Bisect(start, end)
+		if err != nil {
+			break
+		}
+		if divergence <= mid {
+			end = mid
+		} else {
+			start = mid
+		}
+		if end-start < 2 {
+			break
+		}
+		path = append(path, HeightRange{StartHeight: start, EndHeight: end})
+	}
+	return path
+}
+
+// BisectionPrefetcher computes the history commitments and prefix proofs of bisections predicted
+// to come up next in a challenge ahead of time, so that the trackers of the edges need no further
+// computation to bisect them. Prefetching gives way to the actions of trackers, stopping whenever
+// the RPC rate limit or the limit on concurrent computations is reached.
+type BisectionPrefetcher struct {
+	committer l2stateprovider.GeneralHistoryCommitter
+	prover    l2stateprovider.GeneralPrefixProver
+	depth     int
+	limiter   *rate.Limiter
+	slots     chan struct{}
+	results   *lru.Cache[string, *prefetchedBisection]
+}
+
+// PrefetchOpt configures a bisection prefetcher.
+type PrefetchOpt func(*BisectionPrefetcher)
+
+// WithPrefetchDepth sets how many levels of bisections ahead are prefetched, which defaults to
+// two and is at most three.
+func WithPrefetchDepth(depth int) PrefetchOpt {
+	return func(p *BisectionPrefetcher) {
+		p.depth = min(max(depth, 1), maxPrefetchDepth)
+	}
+}
+
+// WithPrefetchRpcLimiter only prefetches a bisection while the limiter shared by the validator's
+// requests to the chain backend allows for another request.
+func WithPrefetchRpcLimiter(limiter *rate.Limiter) PrefetchOpt {
+	return func(p *BisectionPrefetcher) {
+		p.limiter = limiter
+	}
+}
+
+// WithPrefetchConcurrency bounds the number of bisections prefetched at once, which defaults to
+// one, to leave the CPU to the computations of trackers.
+func WithPrefetchConcurrency(n int) PrefetchOpt {
+	return func(p *BisectionPrefetcher) {
+		p.slots = make(chan struct{}, max(n, 1))
+	}
+}
+
+// NewBisectionPrefetcher creates a prefetcher computing bisections with a state provider.
+func NewBisectionPrefetcher(provider l2stateprovider.Provider, opts ...PrefetchOpt) *BisectionPrefetcher {
+	p := &BisectionPrefetcher{
+		committer: provider,
+		prover:    provider,
+		depth:     defaultPrefetchDepth,
+		slots:     make(chan struct{}, 1),
+		results:   lru.NewCache[string, *prefetchedBisection](defaultPrefetchCacheSize),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+type prefetchedBisection struct {
+	commitment commitments.History
+	proof      []byte
+}
+
+// Identifies the bisection of an edge by the history it commits to.
+func bisectionKey(metadata *AssociatedAssertionMetadata, originHeights []l2stateprovider.Height, edge HeightRange) string {
+	return fmt.Sprintf(
+		"%#x-%d-%d-%v-%d-%d",
+		metadata.WasmModuleRoot,
+		metadata.FromBatch,
+		metadata.ToBatch,
+		originHeights,
+		edge.StartHeight,
+		edge.EndHeight,
+	)
+}
+
+// Returns the prefetched bisection of an edge, if any.
+func (p *BisectionPrefetcher) lookup(
+	metadata *AssociatedAssertionMetadata,
+	originHeights []l2stateprovider.Height,
+	edge HeightRange,
+) (*prefetchedBisection, bool) {
+	if p == nil {
+		return nil, false
+	}
+	result, ok := p.results.Get(bisectionKey(metadata, originHeights, edge))
+	if ok {
+		prefetchHitCounter.Inc(1)
+	}
+	return result, ok
+}
+
+// Prefetches the bisections of the edges along a predicted path in order, until the end of the
+// path or until throttled. Failures are logged rather than returned, as the trackers compute the
+// bisections themselves when they are not prefetched.
+func (p *BisectionPrefetcher) prefetch(
+	ctx context.Context,
+	metadata *AssociatedAssertionMetadata,
+	originHeights []l2stateprovider.Height,
+	path []HeightRange,
+) {
+	for _, edge := range path {
+		if ctx.Err() != nil {
+			return
+		}
+		key := bisectionKey(metadata, originHeights, edge)
+		if p.results.Contains(key) {
+			continue
+		}
+		if p.limiter != nil && !p.limiter.Allow() {
+			prefetchThrottledCounter.Inc(1)
+			return
+		}
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			prefetchThrottledCounter.Inc(1)
+			return
+		}
+		commitment, proof, err := bisectionHistoryWithProof(ctx, p.committer, p.prover, metadata, originHeights, edge)
+		<-p.slots
+		if err != nil {
+			log.Debug("Could not prefetch bisection", "startHeight", edge.StartHeight, "endHeight", edge.EndHeight, "err", err)
+			return
+		}
+		p.results.Add(key, &prefetchedBisection{commitment: commitment, proof: proof})
+		prefetchComputedCounter.Inc(1)
+	}
+}
+
+// Computes the history commitment up to the bisection point of an edge, along with a proof that
+// it is a prefix of the history up to the end of the edge.
+func bisectionHistoryWithProof(
+	ctx context.Context,
+	committer l2stateprovider.GeneralHistoryCommitter,
+	prover l2stateprovider.GeneralPrefixProver,
+	metadata *AssociatedAssertionMetadata,
+	originHeights []l2stateprovider.Height,
+	edge HeightRange,
+) (commitments.History, []byte, error) {
+	bisectTo, err := math.Bisect(edge.StartHeight, edge.EndHeight)
+	if err != nil {
+		return commitments.History{}, nil, errors.Wrapf(err, "determining bisection point errored for %d and %d", edge.StartHeight, edge.EndHeight)
+	}
+	historyCommit, err := committer.HistoryCommitment(
+		ctx,
+		&l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              metadata.WasmModuleRoot,
+			FromBatch:                   metadata.FromBatch,
+			ToBatch:                     metadata.ToBatch,
+			UpperChallengeOriginHeights: originHeights,
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(l2stateprovider.Height(bisectTo)),
+		},
+	)
+	if err != nil {
+		return commitments.History{}, nil, errors.Wrap(err, "could not produce history commitment")
+	}
+	proof, err := prover.PrefixProof(
+		ctx,
+		&l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              metadata.WasmModuleRoot,
+			FromBatch:                   metadata.FromBatch,
+			ToBatch:                     metadata.ToBatch,
+			UpperChallengeOriginHeights: originHeights,
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(l2stateprovider.Height(edge.EndHeight)),
+		},
+		l2stateprovider.Height(bisectTo),
+	)
+	if err != nil {
+		return commitments.History{}, nil, errors.Wrap(err, "could not produce prefix proof")
+	}
+	return historyCommit, proof, nil
+}
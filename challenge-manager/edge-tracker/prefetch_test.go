@@ -0,0 +1,102 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestPredictedBisectionPath(t *testing.T) {
+	// Diverging right after the start follows the lower children.
+	require.Equal(t, []HeightRange{{0, 16}, {0, 8}, {0, 4}}, PredictedBisectionPath(0, 32, 1, 3))
+	// Diverging past the bisection point follows the upper child, then the lower children.
+	require.Equal(t, []HeightRange{{16, 32}, {16, 24}}, PredictedBisectionPath(0, 32, 17, 2))
+	require.Equal(t, []HeightRange{{16, 32}, {24, 32}, {24, 28}}, PredictedBisectionPath(0, 32, 25, 3))
+	// Edges of length one are not bisected.
+	require.Equal(t, []HeightRange{{0, 2}}, PredictedBisectionPath(0, 4, 1, 3))
+	require.Empty(t, PredictedBisectionPath(0, 2, 1, 3))
+}
+
+// Computes commitments whose roots are derived from the requested heights.
+type fakeBisectionProvider struct {
+	l2stateprovider.Provider
+	mu       sync.Mutex
+	requests []l2stateprovider.Height
+}
+
+func (f *fakeBisectionProvider) HistoryCommitment(_ context.Context, req *l2stateprovider.HistoryCommitmentRequest) (commitments.History, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req.UpToHeight.Unwrap())
+	return commitments.History{
+		Height: uint64(req.UpToHeight.Unwrap()),
+		Merkle: common.BigToHash(new(big.Int).SetUint64(uint64(req.UpToHeight.Unwrap()))),
+	}, nil
+}
+
+func (f *fakeBisectionProvider) PrefixProof(_ context.Context, req *l2stateprovider.HistoryCommitmentRequest, prefixHeight l2stateprovider.Height) ([]byte, error) {
+	return []byte{byte(prefixHeight), byte(req.UpToHeight.Unwrap())}, nil
+}
+
+func (f *fakeBisectionProvider) numRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func TestBisectionPrefetcher(t *testing.T) {
+	ctx := context.Background()
+	metadata := &AssociatedAssertionMetadata{FromBatch: 1, ToBatch: 2, WasmModuleRoot: common.Hash{1}}
+	origin := []l2stateprovider.Height{3}
+	path := PredictedBisectionPath(0, 32, 17, 2)
+
+	provider := &fakeBisectionProvider{}
+	p := NewBisectionPrefetcher(provider, WithPrefetchDepth(2))
+	p.prefetch(ctx, metadata, origin, path)
+	require.Equal(t, []l2stateprovider.Height{24, 20}, provider.requests)
+
+	prefetched, ok := p.lookup(metadata, origin, HeightRange{16, 32})
+	require.True(t, ok)
+	want, wantProof, err := bisectionHistoryWithProof(ctx, provider, provider, metadata, origin, HeightRange{16, 32})
+	require.NoError(t, err)
+	require.Equal(t, want, prefetched.commitment)
+	require.Equal(t, wantProof, prefetched.proof)
+	_, ok = p.lookup(metadata, origin, HeightRange{16, 24})
+	require.True(t, ok)
+	// Bisections are specific to the history they commit to.
+	_, ok = p.lookup(metadata, nil, HeightRange{16, 32})
+	require.False(t, ok)
+
+	// Prefetched bisections are not computed again.
+	p.prefetch(ctx, metadata, origin, path)
+	require.Equal(t, 3, provider.numRequests())
+
+	// Prefetching stops once the RPC rate limit is reached.
+	provider = &fakeBisectionProvider{}
+	p = NewBisectionPrefetcher(provider, WithPrefetchRpcLimiter(rate.NewLimiter(0, 1)))
+	p.prefetch(ctx, metadata, origin, path)
+	require.Equal(t, 1, provider.numRequests())
+
+	// Prefetching stops while other bisections are being prefetched.
+	provider = &fakeBisectionProvider{}
+	p = NewBisectionPrefetcher(provider)
+	p.slots <- struct{}{}
+	p.prefetch(ctx, metadata, origin, path)
+	require.Equal(t, 0, provider.numRequests())
+}
+
+func TestBisectionPrefetcher_Nil(t *testing.T) {
+	var p *BisectionPrefetcher
+	_, ok := p.lookup(&AssociatedAssertionMetadata{}, nil, HeightRange{0, 32})
+	require.False(t, ok)
+}
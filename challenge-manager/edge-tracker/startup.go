@@ -0,0 +1,178 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"container/heap"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	startupQueuedGauge     = metrics.NewRegisteredGauge("arb/validator/tracker/startup/queued", nil)
+	startupAdmittedCounter = metrics.NewRegisteredCounter("arb/validator/tracker/startup/admitted", nil)
+	startupBoostedCounter  = metrics.NewRegisteredCounter("arb/validator/tracker/startup/boosted", nil)
+)
+
+// StartupThrottle staggers the first decision cycles of trackers, so that a restart with many
+// open edges does not have every tracker poll the chain on the same block and trip the rate
+// limits of the RPC provider. Trackers are admitted one at a time at a global rate, those of
+// edges closest to their deadline first, and each is further delayed by a random jitter. The
+// trackers of edges within the boost window of their deadline skip the queue.
+type StartupThrottle struct {
+	interval    time.Duration
+	maxJitter   time.Duration
+	boostWindow func() uint64
+	lock        sync.Mutex
+	queue       startupQueue
+	seq         uint64
+	dispatching bool
+	lastAdmit   time.Time
+}
+
+// StartupOpt configures a startup throttle.
+type StartupOpt func(*StartupThrottle)
+
+// WithStartupJitter delays each admitted tracker by a random duration up to maxJitter, spreading
+// the trackers admitted together over time. Defaults to no jitter.
+func WithStartupJitter(maxJitter time.Duration) StartupOpt {
+	return func(s *StartupThrottle) {
+		s.maxJitter = max(maxJitter, 0)
+	}
+}
+
+// WithStartupBoostWindow admits the trackers of edges within a number of blocks of their deadline
+// at once. The window is read on each admission, so it may follow a deadline margin which tightens
+// over time. Defaults to no boosting.
+func WithStartupBoostWindow(blocks func() uint64) StartupOpt {
+	return func(s *StartupThrottle) {
+		s.boostWindow = blocks
+	}
+}
+
+// NewStartupThrottle creates a throttle admitting up to perSecond trackers each second.
+func NewStartupThrottle(perSecond int, opts ...StartupOpt) *StartupThrottle {
+	s := &StartupThrottle{
+		interval: time.Second / time.Duration(max(perSecond, 1)),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Wait blocks until a tracker is admitted to its first decision cycle, given the number of
+// blocks left until the deadline of its edge. Returns early with an error if the context is
+// canceled. A nil throttle admits trackers at once.
+func (s *StartupThrottle) Wait(ctx context.Context, blocksToDeadline uint64) error {
+	if s == nil {
+		return nil
+	}
+	if s.boostWindow != nil && blocksToDeadline <= s.boostWindow() {
+		startupBoostedCounter.Inc(1)
+		return nil
+	}
+	s.lock.Lock()
+	w := &startupWaiter{blocksToDeadline: blocksToDeadline, seq: s.seq, ready: make(chan struct{})}
+	s.seq++
+	heap.Push(&s.queue, w)
+	startupQueuedGauge.Update(int64(s.queue.Len()))
+	if !s.dispatching {
+		s.dispatching = true
+		go s.dispatch()
+	}
+	s.lock.Unlock()
+
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		s.lock.Lock()
+		if w.index >= 0 {
+			heap.Remove(&s.queue, w.index)
+			startupQueuedGauge.Update(int64(s.queue.Len()))
+		}
+		s.lock.Unlock()
+		return ctx.Err()
+	}
+	if s.maxJitter <= 0 {
+		return nil
+	}
+	jitter := time.NewTimer(time.Duration(rand.Int63n(int64(s.maxJitter) + 1)))
+	defer jitter.Stop()
+	select {
+	case <-jitter.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Admits the queued trackers one per interval, exiting once the queue is empty.
+func (s *StartupThrottle) dispatch() {
+	for {
+		s.lock.Lock()
+		wait := s.interval - time.Since(s.lastAdmit)
+		s.lock.Unlock()
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		s.lock.Lock()
+		if s.queue.Len() == 0 {
+			s.dispatching = false
+			s.lock.Unlock()
+			return
+		}
+		w := heap.Pop(&s.queue).(*startupWaiter)
+		s.lastAdmit = time.Now()
+		startupQueuedGauge.Update(int64(s.queue.Len()))
+		s.lock.Unlock()
+		startupAdmittedCounter.Inc(1)
+		close(w.ready)
+	}
+}
+
+type startupWaiter struct {
+	blocksToDeadline uint64
+	// Orders waiters with the same deadline by arrival.
+	seq   uint64
+	ready chan struct{}
+	// Position in the queue, or -1 once admitted.
+	index int
+}
+
+// A min-heap of waiters by blocks to their deadline.
+type startupQueue []*startupWaiter
+
+func (q startupQueue) Len() int { return len(q) }
+func (q startupQueue) Less(i, j int) bool {
+	if q[i].blocksToDeadline != q[j].blocksToDeadline {
+		return q[i].blocksToDeadline < q[j].blocksToDeadline
+	}
+	return q[i].seq < q[j].seq
+}
+func (q startupQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *startupQueue) Push(x any) {
+	w := x.(*startupWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *startupQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
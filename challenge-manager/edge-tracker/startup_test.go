@@ -0,0 +1,68 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("nil throttle admits at once", func(t *testing.T) {
+		var throttle *StartupThrottle
+		require.NoError(t, throttle.Wait(ctx, 100))
+	})
+	t.Run("admits closest to deadline first", func(t *testing.T) {
+		throttle := NewStartupThrottle(20)
+		// Occupies the dispatcher, so that the others queue up behind it.
+		require.NoError(t, throttle.Wait(ctx, 1000))
+
+		var lock sync.Mutex
+		var admitted []uint64
+		var wg sync.WaitGroup
+		for _, blocks := range []uint64{300, 100, 200} {
+			blocks := blocks
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, throttle.Wait(ctx, blocks))
+				lock.Lock()
+				admitted = append(admitted, blocks)
+				lock.Unlock()
+			}()
+		}
+		require.Eventually(t, func() bool {
+			throttle.lock.Lock()
+			defer throttle.lock.Unlock()
+			return throttle.queue.Len() == 3
+		}, time.Second, time.Millisecond)
+		start := time.Now()
+		wg.Wait()
+		require.Equal(t, []uint64{100, 200, 300}, admitted)
+		require.GreaterOrEqual(t, time.Since(start), 2*throttle.interval)
+	})
+	t.Run("boosts edges near their deadline", func(t *testing.T) {
+		throttle := NewStartupThrottle(1, WithStartupBoostWindow(func() uint64 { return 50 }))
+		require.NoError(t, throttle.Wait(ctx, 1000))
+		start := time.Now()
+		require.NoError(t, throttle.Wait(ctx, 50))
+		require.Less(t, time.Since(start), throttle.interval)
+	})
+	t.Run("canceled waiter leaves the queue", func(t *testing.T) {
+		throttle := NewStartupThrottle(1, WithStartupJitter(time.Millisecond))
+		require.NoError(t, throttle.Wait(ctx, 1000))
+		canceled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, throttle.Wait(canceled, 1000), context.DeadlineExceeded)
+		throttle.lock.Lock()
+		defer throttle.lock.Unlock()
+		require.Equal(t, 0, throttle.queue.Len())
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"fmt"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Gets the gauge of how many tracked edges of a challenge level are in a state, named
+// arb/validator/tracker/edges/level_<level>/<state>. Together, the gauges of all levels and states
+// form a heatmap of where the edges of ongoing challenges sit, in which a pile-up of edges at a
+// level and state stands out.
+func edgeStateGauge(level protocol.ChallengeLevel, state State) metrics.Gauge {
+	return metrics.GetOrRegisterGauge(fmt.Sprintf("arb/validator/tracker/edges/level_%d/%s", level.Uint8(), state), nil)
+}
+
+// Counts the tracker's edge in the gauge of its level and current state, moving it out of the
+// gauge of its previous state if that has changed. Only called by the tracker's own goroutine.
+func (et *Tracker) updateStateGauge() {
+	gauge := edgeStateGauge(et.edge.GetChallengeLevel(), et.CurrentState())
+	if gauge == et.stateGauge {
+		return
+	}
+	if et.stateGauge != nil {
+		et.stateGauge.Dec(1)
+	}
+	gauge.Inc(1)
+	et.stateGauge = gauge
+}
+
+// Stops counting the tracker's edge once it is no longer tracked.
+func (et *Tracker) clearStateGauge() {
+	if et.stateGauge != nil {
+		et.stateGauge.Dec(1)
+		et.stateGauge = nil
+	}
+}
@@ -7,20 +7,27 @@ package edgetracker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/OffchainLabs/bold/api"
+	"github.com/OffchainLabs/bold/api/db"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/artifacts"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
+	"github.com/OffchainLabs/bold/challenge-manager/latency"
+	"github.com/OffchainLabs/bold/challenge-manager/types"
 	"github.com/OffchainLabs/bold/containers"
 	"github.com/OffchainLabs/bold/containers/events"
 	"github.com/OffchainLabs/bold/containers/fsm"
 	"github.com/OffchainLabs/bold/containers/option"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
-	"github.com/OffchainLabs/bold/math"
 	retry "github.com/OffchainLabs/bold/runtime"
 	commitments "github.com/OffchainLabs/bold/state-commitments/history"
 	utilTime "github.com/OffchainLabs/bold/time"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -28,11 +35,12 @@ import (
 )
 
 var (
-	errBadOneStepProof   = errors.New("bad one step proof data")
-	spawnedCounter       = metrics.NewRegisteredCounter("arb/validator/tracker/spawned", nil)
-	bisectedCounter      = metrics.NewRegisteredCounter("arb/validator/tracker/bisected", nil)
-	confirmedCounter     = metrics.NewRegisteredCounter("arb/validator/tracker/confirmed", nil)
-	layerZeroLeafCounter = metrics.NewRegisteredCounter("arb/validator/tracker/layer_zero_leaves", nil)
+	errBadOneStepProof       = errors.New("bad one step proof data")
+	spawnedCounter           = metrics.NewRegisteredCounter("arb/validator/tracker/spawned", nil)
+	bisectedCounter          = metrics.NewRegisteredCounter("arb/validator/tracker/bisected", nil)
+	adoptedLowerChildCounter = metrics.NewRegisteredCounter("arb/validator/tracker/adopted_lower_children", nil)
+	confirmedCounter         = metrics.NewRegisteredCounter("arb/validator/tracker/confirmed", nil)
+	layerZeroLeafCounter     = metrics.NewRegisteredCounter("arb/validator/tracker/layer_zero_leaves", nil)
 )
 
 // ConfirmationMetadataChecker defines a struct which can retrieve information about
@@ -57,6 +65,11 @@ type ChallengeTracker interface {
 	RemovedTrackedEdge(protocol.EdgeId)
 	BlockTimes() time.Duration
 	NewBlockSubscriber() *events.Producer[*gethtypes.Header]
+	AuditLog() *db.AuditLogger
+	Hooks() *hooks.Registry
+	ConfirmationMethods() *types.ConfirmationMethods
+	LatencyBudget() *latency.Budget
+	Artifacts() *artifacts.Store
 }
 
 // AssociatedAssertionMetadata for the tracked edge.
@@ -94,6 +107,22 @@ func WithFSMOpts(opts ...fsm.Opt[edgeTrackerAction, State]) Opt {
 	}
 }
 
+// WithBisectionPrefetcher prefetches the bisections predicted to come up after the tracker bisects
+// its edge, and bisects with the ones prefetched for its edge.
+func WithBisectionPrefetcher(p *BisectionPrefetcher) Opt {
+	return func(et *Tracker) {
+		et.prefetcher = p
+	}
+}
+
+// WithStartupThrottle delays the first decision cycle of the tracker until the throttle shared by
+// the trackers of the challenge manager admits it.
+func WithStartupThrottle(t *StartupThrottle) Opt {
+	return func(et *Tracker) {
+		et.startupThrottle = t
+	}
+}
+
 type Tracker struct {
 	edge                        protocol.SpecEdge
 	fsm                         *fsm.Fsm[edgeTrackerAction, State]
@@ -106,6 +135,10 @@ type Tracker struct {
 	challengeManager            ChallengeTracker
 	associatedAssertionMetadata *AssociatedAssertionMetadata
 	challengeConfirmer          *challengeConfirmer
+	stateGauge                  metrics.Gauge
+	prefetcher                  *BisectionPrefetcher
+	startupThrottle             *StartupThrottle
+	withdrawOnDespawn           bool
 }
 
 func New(
@@ -132,11 +165,11 @@ func New(
 	}
 	chalManager, err := retry.UntilSucceeds(ctx, func() (protocol.SpecChallengeManager, error) {
 		return chain.SpecChallengeManager(ctx)
-	})
+	}, retry.WithPolicy(retry.RPCRead))
 	if err != nil {
 		return nil, err
 	}
-	tr.challengeConfirmer = newChallengeConfirmer(chainWatcher, chalManager, chain.Backend(), challengeManager.BlockTimes(), tr.validatorName, chain)
+	tr.challengeConfirmer = newChallengeConfirmer(chainWatcher, chalManager, chain.Backend(), challengeManager.BlockTimes(), tr.validatorName, chain, challengeManager.AuditLog(), challengeManager.ConfirmationMethods())
 	fsm, err := newEdgeTrackerFsm(
 		EdgeStarted,
 		tr.fsmOpts...,
@@ -186,25 +219,89 @@ func (et *Tracker) Spawn(ctx context.Context) {
 	log.Info("Now tracking challenge edge locally and making moves", fields...)
 	spawnedCounter.Inc(1)
 	et.challengeManager.MarkTrackedEdge(et.edge.Id(), et)
+	defer func() {
+		// Stop tracking the edge if we panic, so the tracker can be spawned again when restarted.
+		if r := recover(); r != nil {
+			spawnedCounter.Dec(1)
+			et.challengeManager.RemovedTrackedEdge(et.edge.Id())
+			panic(r)
+		}
+	}()
+	et.updateStateGauge()
+	defer et.clearStateGauge()
 
 	subscription := et.challengeManager.NewBlockSubscriber().Subscribe()
+	defer subscription.Unsubscribe()
+	started := et.startupThrottle == nil
 	for {
-		_, shouldExit := subscription.Next(ctx)
+		header, shouldExit := subscription.Next(ctx)
 		if ctx.Err() != nil || shouldExit {
 			log.Debug("Edge tracker goroutine exiting", fields...)
 			spawnedCounter.Dec(1)
 			return
 		}
-		if et.ShouldDespawn(ctx) {
-			log.Debug("Tracked edge received notice it should exit - now despawning", fields...)
+		if !started {
+			started = true
+			if err := et.startupThrottle.Wait(ctx, et.blocksToDeadline(ctx, header)); err != nil {
+				continue
+			}
+		}
+		cycleCtx, cycleFields := et.pinReadsToHead(ctx, fields)
+		if et.ShouldDespawn(cycleCtx) {
+			log.Debug("Tracked edge received notice it should exit - now despawning", cycleFields...)
+			et.withdrawBeforeDespawn(cycleCtx)
 			spawnedCounter.Dec(1)
 			et.challengeManager.RemovedTrackedEdge(et.edge.Id())
 			return
 		}
-		if err := et.Act(ctx); err != nil {
-			log.Error("Could not act with edge tracker", append(fields, "err", err)...)
+		if err := et.Act(cycleCtx); err != nil {
+			log.Error("Could not act with edge tracker", append(cycleFields, "err", err)...)
 		}
+		et.updateStateGauge()
+	}
+}
+
+// Estimates the blocks left until the deadline of the tracked edge by its age, as the challenge
+// of an edge open for longer is closer to its deadline. Returns zero if the challenge period
+// cannot be read, so that the tracker is not held back by mistake.
+func (et *Tracker) blocksToDeadline(ctx context.Context, header *gethtypes.Header) uint64 {
+	if header == nil || !header.Number.IsUint64() {
+		return 0
 	}
+	manager, err := et.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return 0
+	}
+	chalPeriod, err := manager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return 0
+	}
+	createdAt, err := et.edge.CreatedAtBlock()
+	if err != nil {
+		return 0
+	}
+	head := header.Number.Uint64()
+	if head < createdAt {
+		return chalPeriod
+	}
+	if age := head - createdAt; age < chalPeriod {
+		return chalPeriod - age
+	}
+	return 0
+}
+
+// Pins the reads of a decision cycle of the tracker to the desired RPC head block, so that the
+// cycle does not act on state mixed from different blocks. Returns the log fields of the tracker
+// with the pinned block added. If the head cannot be read, reads are left unpinned.
+func (et *Tracker) pinReadsToHead(ctx context.Context, fields []any) (context.Context, []any) {
+	header, err := et.chain.Backend().HeaderByNumber(ctx, et.chain.GetDesiredRpcHeadBlockNumber())
+	if err != nil {
+		log.Warn("Could not get head to pin edge tracker reads to, reading unpinned", append(fields, "err", err)...)
+		return protocol.UnpinReads(ctx), fields
+	}
+	fields = append(fields[:len(fields):len(fields)], "pinnedBlock", header.Number)
+	log.Trace("Pinned edge tracker reads to block", append(fields, "pinnedBlockHash", header.Hash())...)
+	return protocol.PinReads(ctx, header), fields
 }
 
 func (et *Tracker) CurrentState() State {
@@ -314,8 +411,9 @@ func (et *Tracker) Act(ctx context.Context) error {
 			et.fsm.MarkError(err)
 			return et.fsm.Do(edgeBackToStart{})
 		}
-		go firstTracker.Spawn(ctx)
-		go secondTracker.Spawn(ctx)
+		// The new trackers pin the reads of their own decision cycles.
+		go firstTracker.Spawn(protocol.UnpinReads(ctx))
+		go secondTracker.Spawn(protocol.UnpinReads(ctx))
 		return et.fsm.Do(edgeAwaitChallengeCompletion{})
 	case EdgeAwaitingChallengeCompletion:
 		_, err := et.tryToConfirmEdge(ctx)
@@ -431,7 +529,14 @@ func (et *Tracker) tryToConfirmEdge(ctx context.Context) (bool, error) {
 	// immediately confirm by time by sending a transaction.
 	if onchainTimer >= protocol.InheritedTimer(chalPeriod) {
 		log.Info("Onchain timer is greater than challenge period, now confirming edge by time", localFields...)
-		if _, err := et.edge.ConfirmByTimer(ctx); err != nil {
+		if err := et.gate(ctx, hooks.BeforeConfirm, "confirm_by_time"); err != nil {
+			return false, err
+		}
+		tx, err := et.edge.ConfirmByTimer(ctx)
+		et.challengeManager.AuditLog().Record(api.AuditActionConfirmEdgeByTime, map[string]any{
+			"edgeId": et.edge.Id().Hash,
+		}, auditTxResult(tx), err)
+		if err != nil {
 			return false, errors.Wrapf(
 				err,
 				"could not confirm by timer: got timer %d, chal period %d",
@@ -449,6 +554,9 @@ func (et *Tracker) tryToConfirmEdge(ctx context.Context) (bool, error) {
 	// We let our confirmer dependency take care of this confirmatin job.
 	if uint64(computedTimer) >= chalPeriod {
 		log.Info("Local computed timer big enough to confirm edge", localFields...)
+		if err := et.gate(ctx, hooks.BeforeConfirm, "confirm_by_time"); err != nil {
+			return false, err
+		}
 		if err := et.challengeConfirmer.beginConfirmationJob(
 			ctx,
 			assertionHash,
@@ -456,6 +564,10 @@ func (et *Tracker) tryToConfirmEdge(ctx context.Context) (bool, error) {
 			et.edge,
 			chalPeriod,
 		); err != nil {
+			if errors.Is(err, errConfirmationMethodsDisabled) {
+				log.Warn("Could not propagate the edge timer onchain with the enabled confirmation methods", localFields...)
+				return false, nil
+			}
 			return false, errors.Wrap(
 				err,
 				"could not complete confirmation job for royal, block challenge edge",
@@ -472,89 +584,56 @@ func (et *Tracker) tryToConfirmEdge(ctx context.Context) (bool, error) {
 func (et *Tracker) DetermineBisectionHistoryWithProof(
 	ctx context.Context,
 ) (commitments.History, []byte, error) {
-	startHeight, _ := et.edge.StartCommitment()
-	endHeight, _ := et.edge.EndCommitment()
-	bisectTo, err := math.Bisect(uint64(startHeight), uint64(endHeight))
+	originHeights, err := et.challengeOriginHeights(ctx)
 	if err != nil {
-		return commitments.History{}, nil, errors.Wrapf(err, "determining bisection point errored for %d and %d", startHeight, endHeight)
+		return commitments.History{}, nil, err
 	}
-	challengeLevel := et.edge.GetChallengeLevel()
-	if challengeLevel == protocol.NewBlockChallengeLevel() {
-		historyCommit, commitErr := et.stateProvider.HistoryCommitment(
-			ctx,
-			&l2stateprovider.HistoryCommitmentRequest{
-				WasmModuleRoot:              et.associatedAssertionMetadata.WasmModuleRoot,
-				FromBatch:                   et.associatedAssertionMetadata.FromBatch,
-				ToBatch:                     et.associatedAssertionMetadata.ToBatch,
-				UpperChallengeOriginHeights: []l2stateprovider.Height{},
-				FromHeight:                  0,
-				UpToHeight:                  option.Some(l2stateprovider.Height(bisectTo)),
-			},
-		)
-		if commitErr != nil {
-			return commitments.History{}, nil, commitErr
-		}
-		proof, proofErr := et.stateProvider.PrefixProof(
-			ctx,
-			&l2stateprovider.HistoryCommitmentRequest{
-				WasmModuleRoot:              et.associatedAssertionMetadata.WasmModuleRoot,
-				FromBatch:                   et.associatedAssertionMetadata.FromBatch,
-				ToBatch:                     et.associatedAssertionMetadata.ToBatch,
-				UpperChallengeOriginHeights: []l2stateprovider.Height{},
-				FromHeight:                  0,
-				UpToHeight:                  option.Some(l2stateprovider.Height(endHeight)),
-			},
-			l2stateprovider.Height(bisectTo),
-		)
-		if proofErr != nil {
-			return commitments.History{}, nil, proofErr
-		}
-		return historyCommit, proof, nil
+	startHeight, _ := et.edge.StartCommitment()
+	endHeight, _ := et.edge.EndCommitment()
+	edge := HeightRange{StartHeight: uint64(startHeight), EndHeight: uint64(endHeight)}
+	if prefetched, ok := et.prefetcher.lookup(et.associatedAssertionMetadata, originHeights, edge); ok {
+		return prefetched.commitment, prefetched.proof, nil
 	}
-	var historyCommit commitments.History
-	var commitErr error
-	var proof []byte
-	var proofErr error
+	return bisectionHistoryWithProof(ctx, et.stateProvider, et.stateProvider, et.associatedAssertionMetadata, originHeights, edge)
+}
 
+// The heights of the edges the tracked edge's challenge level is rooted in, which are none at the
+// block challenge level.
+func (et *Tracker) challengeOriginHeights(ctx context.Context) ([]l2stateprovider.Height, error) {
+	if et.edge.GetChallengeLevel() == protocol.NewBlockChallengeLevel() {
+		return []l2stateprovider.Height{}, nil
+	}
 	originHeights, err := et.edge.TopLevelClaimHeight(ctx)
 	if err != nil {
-		return commitments.History{}, nil, err
+		return nil, err
 	}
 	challengeOriginHeights := make([]l2stateprovider.Height, len(originHeights.ChallengeOriginHeights))
 	for index, height := range originHeights.ChallengeOriginHeights {
 		challengeOriginHeights[index] = l2stateprovider.Height(height)
 	}
-	// The first challenge origin height must account for the start block height of the assertion.
-	historyCommit, commitErr = et.stateProvider.HistoryCommitment(
-		ctx,
-		&l2stateprovider.HistoryCommitmentRequest{
-			WasmModuleRoot:              et.associatedAssertionMetadata.WasmModuleRoot,
-			FromBatch:                   et.associatedAssertionMetadata.FromBatch,
-			ToBatch:                     et.associatedAssertionMetadata.ToBatch,
-			UpperChallengeOriginHeights: challengeOriginHeights,
-			FromHeight:                  l2stateprovider.Height(0),
-			UpToHeight:                  option.Some(l2stateprovider.Height(bisectTo)),
-		},
-	)
-	if commitErr != nil {
-		return commitments.History{}, nil, errors.Wrap(commitErr, "could not produce history commitment")
+	return challengeOriginHeights, nil
+}
+
+// Prefetches the bisections of the child predicted to be rivaled after bisecting the tracked edge
+// and of its descendants. A rival sharing our lower child disagrees with us past the bisection
+// point. Otherwise, it may not have bisected yet, and is predicted to disagree from the start.
+func (et *Tracker) prefetchBisections(ctx context.Context, bisectTo uint64, lowerChildShared bool) {
+	if et.prefetcher == nil {
+		return
 	}
-	proof, proofErr = et.stateProvider.PrefixProof(
-		ctx,
-		&l2stateprovider.HistoryCommitmentRequest{
-			WasmModuleRoot:              et.associatedAssertionMetadata.WasmModuleRoot,
-			FromBatch:                   et.associatedAssertionMetadata.FromBatch,
-			ToBatch:                     et.associatedAssertionMetadata.ToBatch,
-			UpperChallengeOriginHeights: challengeOriginHeights,
-			FromHeight:                  l2stateprovider.Height(0),
-			UpToHeight:                  option.Some(l2stateprovider.Height(endHeight)),
-		},
-		l2stateprovider.Height(bisectTo),
-	)
-	if proofErr != nil {
-		return commitments.History{}, nil, errors.Wrap(proofErr, "could not produce prefix proof")
+	originHeights, err := et.challengeOriginHeights(ctx)
+	if err != nil {
+		log.Debug("Could not get origin heights to prefetch bisections", "edgeId", et.edge.Id(), "err", err)
+		return
 	}
-	return historyCommit, proof, nil
+	startHeight, _ := et.edge.StartCommitment()
+	endHeight, _ := et.edge.EndCommitment()
+	divergence := uint64(startHeight) + 1
+	if lowerChildShared {
+		divergence = bisectTo + 1
+	}
+	path := PredictedBisectionPath(uint64(startHeight), uint64(endHeight), divergence, et.prefetcher.depth)
+	go et.prefetcher.prefetch(ctx, et.associatedAssertionMetadata, originHeights, path)
 }
 
 func (et *Tracker) bisect(ctx context.Context) (protocol.SpecEdge, protocol.SpecEdge, error) {
@@ -564,8 +643,23 @@ func (et *Tracker) bisect(ctx context.Context) (protocol.SpecEdge, protocol.Spec
 	}
 	endHeight, endCommit := et.edge.EndCommitment()
 	bisectTo := historyCommit.Height
+	if err = et.gate(ctx, hooks.BeforeCreateEdge, "bisection"); err != nil {
+		return nil, nil, err
+	}
+	_, expectedLower, expectedUpper, err := protocol.ComputeBisectionChildIds(et.edge, historyCommit.Merkle)
+	if err != nil {
+		return nil, nil, err
+	}
+	auditInputs := map[string]any{
+		"edgeId":               et.edge.Id().Hash,
+		"bisectionHeight":      bisectTo,
+		"bisectionHistoryRoot": historyCommit.Merkle,
+		"expectedLowerChildId": expectedLower.Hash,
+		"expectedUpperChildId": expectedUpper.Hash,
+	}
 	firstChild, secondChild, err := et.edge.Bisect(ctx, historyCommit.Merkle, proof)
 	if err != nil {
+		et.challengeManager.AuditLog().Record(api.AuditActionBisectEdge, auditInputs, nil, err)
 		return nil, nil, errors.Wrapf(
 			err,
 			"%s could not bisect to height=%d,commit=%s from height=%d,commit=%s",
@@ -576,7 +670,45 @@ func (et *Tracker) bisect(ctx context.Context) (protocol.SpecEdge, protocol.Spec
 			containers.Trunc(endCommit.Bytes()),
 		)
 	}
+	// The children did not exist yet at the block the reads of this cycle are pinned to.
+	ctx = protocol.UnpinReads(ctx)
+	// If a rival that agrees with us up to the bisection point bisected first, our lower child
+	// already existed. The bisection still succeeded, and we adopt the child shared with the rival.
+	_, lowerChildAlreadyExists := firstChild.(protocol.AdoptedEdge)
+	et.challengeManager.AuditLog().Record(api.AuditActionBisectEdge, auditInputs, map[string]any{
+		"lowerChildId":            firstChild.Id().Hash,
+		"upperChildId":            secondChild.Id().Hash,
+		"lowerChildAlreadyExists": lowerChildAlreadyExists,
+	}, nil)
+	commitment, err := json.Marshal(historyCommit)
+	if err != nil {
+		return nil, nil, err
+	}
+	edgeHex := et.edge.Id().Hash.Hex()
+	et.recordMove(ctx, &artifacts.Move{
+		Kind: "bisection",
+		Details: map[string]any{
+			"bisectionHeight": bisectTo,
+			"lowerChildId":    firstChild.Id().Hash,
+			"upperChildId":    secondChild.Id().Hash,
+		},
+		Artifacts: []artifacts.Artifact{
+			{Kind: artifacts.Commitments, Name: "bisection-" + edgeHex + ".json", Data: commitment},
+			{Kind: artifacts.Proofs, Name: "bisection-" + edgeHex + ".bin", Data: proof},
+		},
+	})
 	log.Info("Bisecting honest edge", et.uniqueTrackerLogFields()...)
+	if lowerChildAlreadyExists {
+		adoptedLowerChildCounter.Inc(1)
+		log.Info(
+			"Adopted lower child created by the bisection of a rival edge",
+			append(et.uniqueTrackerLogFields(), "lowerChildId", containers.Trunc(firstChild.Id().Bytes()))...,
+		)
+	}
+	if moveBlock, blockErr := secondChild.CreatedAtBlock(); blockErr == nil {
+		et.recordCounterMove(ctx, latency.MoveBisection, moveBlock)
+	}
+	et.prefetchBisections(ctx, bisectTo, lowerChildAlreadyExists)
 	if addVerifiedErr := et.chainWatcher.AddVerifiedHonestEdge(ctx, firstChild); addVerifiedErr != nil {
 		// We simply log an error, as if this errored, it will be added later on by the chain watcher
 		// scraping events from the chain, but this is a helpful optimization.
@@ -775,6 +907,9 @@ func (et *Tracker) openSubchallengeLeaf(ctx context.Context) error {
 	log.Info("Identified single point of disagreement within a challenge level, now opening subchallenge", fields...)
 	log.Info("Making subchallenge creation move on edge", fields...)
 
+	if err = et.gate(ctx, hooks.BeforeCreateEdge, "subchallenge_edge"); err != nil {
+		return err
+	}
 	manager, err := et.chain.SpecChallengeManager(ctx)
 	if err != nil {
 		return err
@@ -788,12 +923,26 @@ func (et *Tracker) openSubchallengeLeaf(ctx context.Context) error {
 		endParentCommitment.LastLeafProof,
 		startEndPrefixProof,
 	)
+	auditInputs := map[string]any{
+		"claimId":          et.edge.Id().Hash,
+		"startHistoryRoot": startHistory.Merkle,
+		"endHistoryRoot":   endHistory.Merkle,
+	}
 	if err != nil {
+		et.challengeManager.AuditLog().Record(api.AuditActionCreateSubchallengeEdge, auditInputs, nil, err)
 		return err
 	}
+	et.challengeManager.AuditLog().Record(api.AuditActionCreateSubchallengeEdge, auditInputs, map[string]any{
+		"edgeId": addedLeaf.Id().Hash,
+	}, nil)
+	// The added edge did not exist yet at the block the reads of this cycle are pinned to.
+	ctx = protocol.UnpinReads(ctx)
 	addedLeafChallengeLevel := addedLeaf.GetChallengeLevel()
 	fields = append(fields, "subchallengeType", addedLeafChallengeLevel)
 	log.Info("Successfully created a subchallenge edge", fields...)
+	if moveBlock, blockErr := addedLeaf.CreatedAtBlock(); blockErr == nil {
+		et.recordCounterMove(ctx, latency.MoveSubchallenge, moveBlock)
+	}
 
 	if addVerifiedErr := et.chainWatcher.AddVerifiedHonestEdge(ctx, addedLeaf); addVerifiedErr != nil {
 		// We simply log an error, as if this errored, it will be added later on by the chain watcher
@@ -816,7 +965,7 @@ func (et *Tracker) openSubchallengeLeaf(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	go tracker.Spawn(ctx)
+	go tracker.Spawn(protocol.UnpinReads(ctx))
 	return nil
 }
 
@@ -854,6 +1003,9 @@ func (et *Tracker) submitOneStepProof(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrapf(errBadOneStepProof, "could not get one step data: %v", err)
 	}
+	if err = et.gate(ctx, hooks.BeforeConfirm, "confirm_by_one_step_proof"); err != nil {
+		return err
+	}
 	manager, err := et.chain.SpecChallengeManager(ctx)
 	if err != nil {
 		return err
@@ -868,9 +1020,44 @@ func (et *Tracker) submitOneStepProof(ctx context.Context) error {
 		return errors.Wrap(err, "could not confirm one step proof against protocol")
 	}
 	log.Info("Succeeded one-step-proof for edge and confirmed it as winner", fields...)
+	oneStepProof, err := json.Marshal(map[string]any{
+		"beforeHash":                data.BeforeHash,
+		"afterHash":                 data.AfterHash,
+		"proof":                     hexutil.Bytes(data.Proof),
+		"beforeStateInclusionProof": beforeStateInclusionProof,
+		"afterStateInclusionProof":  afterStateInclusionProof,
+	})
+	if err != nil {
+		return err
+	}
+	et.recordMove(ctx, &artifacts.Move{
+		Kind:    "one_step_proof",
+		Details: map[string]any{"machineStepHeight": pc},
+		Artifacts: []artifacts.Artifact{
+			{Kind: artifacts.Proofs, Name: "one-step-" + et.edge.Id().Hash.Hex() + ".json", Data: oneStepProof},
+		},
+	})
+	if moveBlock, blockErr := et.edge.ConfirmedAtBlock(protocol.UnpinReads(ctx)); blockErr == nil {
+		et.recordCounterMove(ctx, latency.MoveOneStepProof, moveBlock)
+	}
 	return nil
 }
 
+// Consults the operator's hooks before the tracker acts on its edge.
+func (et *Tracker) gate(ctx context.Context, point hooks.Point, kind string) error {
+	assertionHash, err := et.edge.AssertionHash(ctx)
+	if err != nil {
+		return err
+	}
+	return et.challengeManager.Hooks().Gate(ctx, &hooks.Action{
+		Point:          point,
+		Kind:           kind,
+		AssertionHash:  assertionHash.Hash,
+		EdgeId:         et.edge.Id().Hash,
+		ChallengeLevel: et.edge.GetChallengeLevel().Uint8(),
+	})
+}
+
 func canOneStepProve(ctx context.Context, edge protocol.SpecEdge) (bool, error) {
 	start, _ := edge.StartCommitment()
 	end, _ := edge.EndCommitment()
@@ -886,3 +1073,13 @@ func canOneStepProve(ctx context.Context, edge protocol.SpecEdge) (bool, error)
 func IsRootBlockChallengeEdge(edge protocol.ReadOnlyEdge) bool {
 	return edge.ClaimId().IsSome() && edge.GetChallengeLevel() == protocol.NewBlockChallengeLevel()
 }
+
+// Records the hash of the transaction sent by an operation, if any, as its result in the audit log.
+func auditTxResult(tx *gethtypes.Transaction) any {
+	if tx == nil {
+		return nil
+	}
+	return map[string]any{
+		"transactionHash": tx.Hash(),
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/api"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrWithdrawalUnsupported is returned when withdrawing an edge from a challenge manager which
+	// does not let stakers withdraw edges.
+	ErrWithdrawalUnsupported = errors.New("the challenge manager does not support withdrawing edges")
+	// ErrWithdrawalNotPermitted is returned when withdrawing an edge which may still affect the
+	// outcome of its challenge, or whose stake is not the validator's.
+	ErrWithdrawalNotPermitted = errors.New("edge may not be withdrawn")
+
+	withdrawnCounter = metrics.NewRegisteredCounter("arb/validator/tracker/withdrawn", nil)
+)
+
+// WithEdgeWithdrawal makes the tracker withdraw its edge to recover its stake once the edge can
+// no longer affect the outcome of its challenge, if the challenge manager supports it.
+func WithEdgeWithdrawal() Opt {
+	return func(et *Tracker) {
+		et.withdrawOnDespawn = true
+	}
+}
+
+// WithdrawEdge withdraws the tracked edge to recover its stake. The edge must pass the withdrawal
+// policy: it is a pending layer zero edge staked by the validator, and the assertion claimed by
+// its challenge is confirmed, so that the edge can no longer affect the outcome. Hooks are
+// consulted before the withdrawal is sent.
+func (et *Tracker) WithdrawEdge(ctx context.Context) (*gethtypes.Transaction, error) {
+	if err := et.checkWithdrawalPolicy(ctx); err != nil {
+		return nil, err
+	}
+	if err := et.gate(ctx, hooks.BeforeWithdraw, "withdraw_edge"); err != nil {
+		return nil, err
+	}
+	manager, err := et.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get challenge manager")
+	}
+	tx, err := manager.WithdrawEdge(ctx, et.edge.Id())
+	et.challengeManager.AuditLog().Record(api.AuditActionWithdrawEdge, map[string]any{
+		"edgeId": et.edge.Id().Hash,
+	}, auditTxResult(tx), err)
+	if err != nil {
+		return nil, err
+	}
+	withdrawnCounter.Inc(1)
+	log.Info("Withdrew edge to recover its stake", et.uniqueTrackerLogFields()...)
+	return tx, nil
+}
+
+// Checks that the challenge manager supports withdrawing edges, and that the tracked edge passes
+// the withdrawal policy.
+func (et *Tracker) checkWithdrawalPolicy(ctx context.Context) error {
+	manager, err := et.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get challenge manager")
+	}
+	supported, err := manager.SupportsEdgeWithdrawal(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not check if the challenge manager supports withdrawing edges")
+	}
+	if !supported {
+		return ErrWithdrawalUnsupported
+	}
+	staker := et.edge.MiniStaker()
+	if staker.IsNone() {
+		return errors.Wrap(ErrWithdrawalNotPermitted, "only layer zero edges hold a stake")
+	}
+	if staker.Unwrap() != et.chain.StakerAddress() {
+		return errors.Wrapf(ErrWithdrawalNotPermitted, "edge was staked by %s, not by the validator", staker.Unwrap().Hex())
+	}
+	status, err := et.edge.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get edge status")
+	}
+	if status != protocol.EdgePending {
+		return errors.Wrapf(ErrWithdrawalNotPermitted, "edge is %s", status)
+	}
+	claimedAssertion, err := et.chain.AssertionStatus(ctx, protocol.AssertionHash{
+		Hash: et.associatedAssertionMetadata.ClaimedAssertionHash,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not get claimed assertion status")
+	}
+	if claimedAssertion != protocol.AssertionConfirmed {
+		return errors.Wrap(ErrWithdrawalNotPermitted, "the claimed assertion is not confirmed, so the edge may still affect the outcome")
+	}
+	return nil
+}
+
+// Withdraws the edge of a despawning tracker if enabled and permitted. Failures are only logged,
+// as the stake remains recoverable by confirming the edge.
+func (et *Tracker) withdrawBeforeDespawn(ctx context.Context) {
+	if !et.withdrawOnDespawn {
+		return
+	}
+	if _, err := et.WithdrawEdge(ctx); err != nil {
+		fields := append(et.uniqueTrackerLogFields(), "err", err)
+		if errors.Is(err, ErrWithdrawalUnsupported) || errors.Is(err, ErrWithdrawalNotPermitted) {
+			log.Debug("Not withdrawing edge before despawning", fields...)
+			return
+		}
+		log.Error("Could not withdraw edge before despawning", fields...)
+	}
+}
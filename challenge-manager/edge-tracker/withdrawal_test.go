@@ -0,0 +1,124 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package edgetracker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/testing/mocks"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWithdrawalPolicy(t *testing.T) {
+	ctx := context.Background()
+	validator := common.Address{0x01}
+	claimedAssertionHash := common.Hash{0xaa}
+	tests := []struct {
+		name              string
+		supported         bool
+		staker            option.Option[common.Address]
+		status            protocol.EdgeStatus
+		assertionStatus   protocol.AssertionStatus
+		assertionErr      error
+		wantErr           error
+		wantErrContaining string
+	}{
+		{
+			name:            "permitted",
+			supported:       true,
+			staker:          option.Some(validator),
+			status:          protocol.EdgePending,
+			assertionStatus: protocol.AssertionConfirmed,
+		},
+		{
+			name:            "unsupported",
+			supported:       false,
+			staker:          option.Some(validator),
+			status:          protocol.EdgePending,
+			assertionStatus: protocol.AssertionConfirmed,
+			wantErr:         ErrWithdrawalUnsupported,
+		},
+		{
+			name:              "not a layer zero edge",
+			supported:         true,
+			staker:            option.None[common.Address](),
+			status:            protocol.EdgePending,
+			assertionStatus:   protocol.AssertionConfirmed,
+			wantErr:           ErrWithdrawalNotPermitted,
+			wantErrContaining: "only layer zero edges",
+		},
+		{
+			name:              "staked by another validator",
+			supported:         true,
+			staker:            option.Some(common.Address{0x02}),
+			status:            protocol.EdgePending,
+			assertionStatus:   protocol.AssertionConfirmed,
+			wantErr:           ErrWithdrawalNotPermitted,
+			wantErrContaining: "not by the validator",
+		},
+		{
+			name:              "confirmed edge",
+			supported:         true,
+			staker:            option.Some(validator),
+			status:            protocol.EdgeConfirmed,
+			assertionStatus:   protocol.AssertionConfirmed,
+			wantErr:           ErrWithdrawalNotPermitted,
+			wantErrContaining: "edge is",
+		},
+		{
+			name:              "claimed assertion pending",
+			supported:         true,
+			staker:            option.Some(validator),
+			status:            protocol.EdgePending,
+			assertionStatus:   protocol.AssertionPending,
+			wantErr:           ErrWithdrawalNotPermitted,
+			wantErrContaining: "claimed assertion is not confirmed",
+		},
+		{
+			name:              "claimed assertion status unknown",
+			supported:         true,
+			staker:            option.Some(validator),
+			status:            protocol.EdgePending,
+			assertionErr:      errors.New("rpc failed"),
+			wantErrContaining: "rpc failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &mocks.MockSpecChallengeManager{}
+			manager.On("SupportsEdgeWithdrawal", ctx).Return(tt.supported, nil)
+			chain := &mocks.MockProtocol{}
+			chain.On("SpecChallengeManager", ctx).Return(manager, nil)
+			chain.On("StakerAddress").Return(validator)
+			chain.On("AssertionStatus", ctx, protocol.AssertionHash{Hash: claimedAssertionHash}).Return(tt.assertionStatus, tt.assertionErr)
+			edge := &mocks.MockSpecEdge{}
+			edge.On("MiniStaker").Return(tt.staker)
+			edge.On("Status", ctx).Return(tt.status, nil)
+			et := &Tracker{
+				edge:  edge,
+				chain: chain,
+				associatedAssertionMetadata: &AssociatedAssertionMetadata{
+					ClaimedAssertionHash: claimedAssertionHash,
+				},
+			}
+
+			err := et.checkWithdrawalPolicy(ctx)
+			if tt.wantErr == nil && tt.wantErrContaining == "" {
+				require.NoError(t, err)
+				return
+			}
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.wantErrContaining != "" {
+				require.ErrorContains(t, err, tt.wantErrContaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package challengemanager
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/api/db"
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Service is an optional component run alongside a challenge manager, started and stopped with it.
+// Components such as the HTTP API are added as services, so that processes embedding a challenge
+// manager do not depend on them unless they use them.
+type Service interface {
+	Start(ctx context.Context) error
+	StopAndWait()
+}
+
+// ServiceFactory creates a service once the challenge manager it runs alongside is set up.
+type ServiceFactory func(m *Manager) (Service, error)
+
+// WithService runs the service created by the factory alongside the challenge manager. Like the
+// challenge watcher, services are not run in watchtower and resolve modes.
+func WithService(factory ServiceFactory) Opt {
+	return func(val *Manager) {
+		val.serviceFactories = append(val.serviceFactories, factory)
+	}
+}
+
+// Dependencies of a challenge manager embedded in another process, such as a Nitro node, which
+// provides its own connection to the parent chain, transaction signing, state provider and storage.
+type Dependencies struct {
+	// The backend of the parent chain the rollup is deployed on.
+	Backend protocol.ChainBackend
+	// Signs the transactions of the staker taking part in challenges.
+	Signer *bind.TransactOpts
+	// Provides the execution state and history commitments of the rollup.
+	StateProvider l2stateprovider.Provider
+	// Stores challenge data. Optional, as nothing is stored if nil.
+	Storage db.Database
+	// The address of the rollup contract.
+	Rollup common.Address
+}
+
+// NewEmbedded sets up a challenge manager from the dependencies provided by an embedding process.
+// Unlike New, it creates the assertion chain itself, discovering the challenge manager contract
+// from the rollup. No HTTP server is started and no file is opened unless options ask for it, and
+// metrics are only collected if the embedding process enables go-ethereum metrics. As the key of
+// the signer is often shared with the embedding process, its nonces are healed when transactions
+// sent from it by others desynchronize them.
+func NewEmbedded(ctx context.Context, deps Dependencies, opts ...Opt) (*Manager, error) {
+	switch {
+	case deps.Backend == nil:
+		return nil, errors.New("a backend is required")
+	case deps.Signer == nil:
+		return nil, errors.New("a signer is required")
+	case deps.StateProvider == nil:
+		return nil, errors.New("a state provider is required")
+	}
+	rollup, err := rollupgen.NewRollupUserLogicCaller(deps.Rollup, deps.Backend)
+	if err != nil {
+		return nil, err
+	}
+	chalManagerAddr, err := rollup.ChallengeManager(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get challenge manager of rollup %#x", deps.Rollup)
+	}
+	chain, err := solimpl.NewAssertionChain(
+		ctx,
+		deps.Rollup,
+		chalManagerAddr,
+		deps.Signer,
+		deps.Backend,
+		solimpl.NewChainBackendTransactor(deps.Backend, solimpl.WithNonceHealing()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	managerOpts := []Opt{WithAddress(deps.Signer.From)}
+	if deps.Storage != nil {
+		managerOpts = append(managerOpts, WithDatabase(deps.Storage))
+	}
+	return New(ctx, chain, deps.StateProvider, deps.Rollup, append(managerOpts, opts...)...)
+}
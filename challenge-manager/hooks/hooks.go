@@ -0,0 +1,183 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package hooks lets operators consult their own plugins before the validator takes certain
+// actions, such as creating an edge, confirming one, or responding to a rival assertion. A plugin
+// may veto an action, delay it, or annotate it for the logs, enabling custom risk controls
+// without forking the validator.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrVetoed is returned for an action a hook vetoed.
+	ErrVetoed = errors.New("action vetoed by hook")
+	// ErrDelayed is returned for an action a hook delayed, until its delay has passed.
+	ErrDelayed = errors.New("action delayed by hook")
+
+	vetoedCounter     = metrics.NewRegisteredCounter("arb/validator/hooks/vetoed", nil)
+	delayedCounter    = metrics.NewRegisteredCounter("arb/validator/hooks/delayed", nil)
+	hookErrorsCounter = metrics.NewRegisteredCounter("arb/validator/hooks/errors", nil)
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Point at which hooks are consulted.
+type Point string
+
+const (
+	// BeforeCreateEdge is consulted before creating a level zero edge or bisecting an edge.
+	BeforeCreateEdge Point = "before_create_edge"
+	// BeforeConfirm is consulted before confirming an edge by time or by one step proof.
+	BeforeConfirm Point = "before_confirm"
+	// BeforeWithdraw is consulted before withdrawing a layer zero edge to recover its stake.
+	BeforeWithdraw Point = "before_withdraw"
+	// OnRivalDetected is consulted when an assertion we disagree with is observed, before posting
+	// a rival to it.
+	OnRivalDetected Point = "on_rival_detected"
+)
+
+// Action describes an action the validator is about to take.
+type Action struct {
+	Point Point `json:"point"`
+	// Names the action, such as "block_challenge_edge", "bisection" or "confirm_by_time".
+	Kind           string      `json:"kind"`
+	AssertionHash  common.Hash `json:"assertionHash"`
+	EdgeId         common.Hash `json:"edgeId,omitempty"`
+	ChallengeLevel uint8       `json:"challengeLevel"`
+}
+
+// Verdict is a hook's decision on an action. The zero verdict lets the action proceed.
+type Verdict struct {
+	Veto   bool
+	Reason string
+	// Postpones the action for this long, after which hooks are consulted again.
+	Delay time.Duration
+	// Logged along with the action.
+	Annotations map[string]string
+}
+
+// Hook is a plugin consulted before actions.
+type Hook interface {
+	Name() string
+	Evaluate(ctx context.Context, action *Action) (*Verdict, error)
+}
+
+// Registry consults its hooks before actions. A nil registry, with no hooks, lets every action
+// proceed.
+type Registry struct {
+	hooks      []Hook
+	timeout    time.Duration
+	failClosed bool
+	lock       sync.Mutex
+	notBefore  map[Action]time.Time
+	timeRef    func() time.Time
+}
+
+type Opt func(*Registry)
+
+// WithTimeout bounds how long each hook is consulted for. Defaults to 10 seconds.
+func WithTimeout(timeout time.Duration) Opt {
+	return func(r *Registry) {
+		r.timeout = timeout
+	}
+}
+
+// WithFailClosed vetoes actions for which a hook errors. By default, a hook which errors is
+// ignored, so an unavailable plugin cannot stop the validator from defending its assertions.
+func WithFailClosed() Opt {
+	return func(r *Registry) {
+		r.failClosed = true
+	}
+}
+
+func NewRegistry(hooks []Hook, opts ...Opt) *Registry {
+	r := &Registry{
+		hooks:     hooks,
+		timeout:   defaultTimeout,
+		notBefore: make(map[Action]time.Time),
+		timeRef:   time.Now,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Gate consults every hook before an action, returning an error wrapping ErrVetoed if one vetoes
+// it, or ErrDelayed if one delays it and its delay has not passed yet. Callers retry delayed
+// actions as they would any other failed action.
+func (r *Registry) Gate(ctx context.Context, action *Action) error {
+	if r == nil || len(r.hooks) == 0 {
+		return nil
+	}
+	now := r.timeRef()
+	r.lock.Lock()
+	notBefore, delayed := r.notBefore[*action]
+	r.lock.Unlock()
+	if delayed && now.Before(notBefore) {
+		return errors.Wrapf(ErrDelayed, "%s %s until %s", action.Point, action.Kind, notBefore.Format(time.RFC3339))
+	}
+	var delay time.Duration
+	for _, h := range r.hooks {
+		verdict, err := r.evaluate(ctx, h, action)
+		if err != nil {
+			hookErrorsCounter.Inc(1)
+			if r.failClosed {
+				vetoedCounter.Inc(1)
+				return errors.Wrapf(ErrVetoed, "hook %s errored: %v", h.Name(), err)
+			}
+			log.Error("Hook errored, ignoring it", "hook", h.Name(), "point", action.Point, "kind", action.Kind, "err", err)
+			continue
+		}
+		if verdict == nil {
+			continue
+		}
+		if len(verdict.Annotations) > 0 {
+			fields := []any{"hook", h.Name(), "point", action.Point, "kind", action.Kind, "edgeId", action.EdgeId, "assertionHash", action.AssertionHash}
+			for k, v := range verdict.Annotations {
+				fields = append(fields, k, v)
+			}
+			log.Info("Hook annotated action", fields...)
+		}
+		if verdict.Veto {
+			vetoedCounter.Inc(1)
+			log.Warn("Hook vetoed action", "hook", h.Name(), "point", action.Point, "kind", action.Kind, "reason", verdict.Reason)
+			return errors.Wrapf(ErrVetoed, "hook %s: %s", h.Name(), verdict.Reason)
+		}
+		delay = max(delay, verdict.Delay)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if delay > 0 {
+		delayedCounter.Inc(1)
+		notBefore = now.Add(delay)
+		r.notBefore[*action] = notBefore
+		log.Info("Hook delayed action", "point", action.Point, "kind", action.Kind, "until", notBefore)
+		return errors.Wrapf(ErrDelayed, "%s %s until %s", action.Point, action.Kind, notBefore.Format(time.RFC3339))
+	}
+	delete(r.notBefore, *action)
+	return nil
+}
+
+func (r *Registry) evaluate(ctx context.Context, h Hook, action *Action) (verdict *Verdict, err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	// A misbehaving plugin must not bring down the validator.
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("hook panicked: %v", rec)
+		}
+	}()
+	return h.Evaluate(ctx, action)
+}
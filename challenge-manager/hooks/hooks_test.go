@@ -0,0 +1,108 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type funcHook struct {
+	name  string
+	calls int
+	fn    func(*Action) (*Verdict, error)
+}
+
+func (h *funcHook) Name() string {
+	return h.name
+}
+
+func (h *funcHook) Evaluate(_ context.Context, action *Action) (*Verdict, error) {
+	h.calls++
+	return h.fn(action)
+}
+
+func TestRegistry_Gate(t *testing.T) {
+	ctx := context.Background()
+	action := &Action{Point: BeforeConfirm, Kind: "confirm_by_time", EdgeId: common.BytesToHash([]byte("edge"))}
+
+	t.Run("nil registry lets actions proceed", func(t *testing.T) {
+		var r *Registry
+		require.NoError(t, r.Gate(ctx, action))
+	})
+	t.Run("veto", func(t *testing.T) {
+		annotator := &funcHook{name: "annotator", fn: func(*Action) (*Verdict, error) {
+			return &Verdict{Annotations: map[string]string{"risk": "low"}}, nil
+		}}
+		vetoer := &funcHook{name: "vetoer", fn: func(a *Action) (*Verdict, error) {
+			return &Verdict{Veto: a.Point == BeforeConfirm, Reason: "manual review"}, nil
+		}}
+		r := NewRegistry([]Hook{annotator, vetoer})
+		err := r.Gate(ctx, action)
+		require.ErrorIs(t, err, ErrVetoed)
+		require.ErrorContains(t, err, "manual review")
+		require.NoError(t, r.Gate(ctx, &Action{Point: BeforeCreateEdge, Kind: "bisection"}))
+		require.Equal(t, 2, annotator.calls)
+	})
+	t.Run("delay", func(t *testing.T) {
+		delaying := true
+		hook := &funcHook{name: "delayer", fn: func(*Action) (*Verdict, error) {
+			if delaying {
+				return &Verdict{Delay: time.Minute}, nil
+			}
+			return nil, nil
+		}}
+		now := time.Now()
+		r := NewRegistry([]Hook{hook})
+		r.timeRef = func() time.Time { return now }
+		require.ErrorIs(t, r.Gate(ctx, action), ErrDelayed)
+
+		// Hooks are not consulted again until the delay has passed.
+		delaying = false
+		require.ErrorIs(t, r.Gate(ctx, action), ErrDelayed)
+		require.Equal(t, 1, hook.calls)
+		// Other actions are not delayed.
+		require.NoError(t, r.Gate(ctx, &Action{Point: BeforeConfirm, Kind: "confirm_by_one_step_proof"}))
+
+		now = now.Add(time.Minute)
+		require.NoError(t, r.Gate(ctx, action))
+	})
+	t.Run("failing hooks", func(t *testing.T) {
+		failing := &funcHook{name: "failing", fn: func(*Action) (*Verdict, error) {
+			return nil, errors.New("unavailable")
+		}}
+		panicking := &funcHook{name: "panicking", fn: func(*Action) (*Verdict, error) {
+			panic("bad plugin")
+		}}
+		require.NoError(t, NewRegistry([]Hook{failing, panicking}).Gate(ctx, action))
+		err := NewRegistry([]Hook{panicking}, WithFailClosed()).Gate(ctx, action)
+		require.ErrorIs(t, err, ErrVetoed)
+		require.ErrorContains(t, err, "bad plugin")
+	})
+}
+
+func TestHTTPHook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action := &Action{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(action))
+		require.Equal(t, OnRivalDetected, action.Point)
+		_, err := w.Write([]byte(`{"delaySeconds": 30, "annotations": {"ticket": "42"}}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	verdict, err := NewHTTPHook("risk", srv.URL).Evaluate(context.Background(), &Action{Point: OnRivalDetected})
+	require.NoError(t, err)
+	require.False(t, verdict.Veto)
+	require.Equal(t, 30*time.Second, verdict.Delay)
+	require.Equal(t, map[string]string{"ticket": "42"}, verdict.Annotations)
+}
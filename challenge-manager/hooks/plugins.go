@@ -0,0 +1,102 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"plugin"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The symbol a Go plugin exports its hook as.
+const pluginSymbol = "Hook"
+
+// LoadPlugin opens a Go plugin, built with -buildmode=plugin against the same version of this
+// package, which exports a variable named Hook implementing the Hook interface.
+func LoadPlugin(path string) (Hook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open hook plugin %s", path)
+	}
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, errors.Wrapf(err, "hook plugin %s does not export %s", path, pluginSymbol)
+	}
+	switch h := sym.(type) {
+	case *Hook:
+		return *h, nil
+	case Hook:
+		return h, nil
+	default:
+		return nil, fmt.Errorf("%s of hook plugin %s is a %T, not a hook", pluginSymbol, path, sym)
+	}
+}
+
+// The body an HTTP hook responds with.
+type httpVerdict struct {
+	Veto         bool              `json:"veto"`
+	Reason       string            `json:"reason"`
+	DelaySeconds uint64            `json:"delaySeconds"`
+	Annotations  map[string]string `json:"annotations"`
+}
+
+// HTTPHook consults an external service, posting each action to it as JSON. The service responds
+// with a JSON verdict of the form
+//
+//	{"veto": false, "reason": "", "delaySeconds": 0, "annotations": {"key": "value"}}
+//
+// where every field may be omitted.
+type HTTPHook struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func NewHTTPHook(name, url string) *HTTPHook {
+	return &HTTPHook{
+		name:   name,
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+func (h *HTTPHook) Name() string {
+	return h.name
+}
+
+func (h *HTTPHook) Evaluate(ctx context.Context, action *Action) (*Verdict, error) {
+	body, err := json.Marshal(action)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook endpoint responded with status %d", resp.StatusCode)
+	}
+	v := &httpVerdict{}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, errors.Wrap(err, "could not decode hook verdict")
+	}
+	return &Verdict{
+		Veto:        v.Veto,
+		Reason:      v.Reason,
+		Delay:       time.Duration(v.DelaySeconds) * time.Second,
+		Annotations: v.Annotations,
+	}, nil
+}
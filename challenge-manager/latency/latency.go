@@ -0,0 +1,277 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package latency tracks how long the counter-moves of a challenge manager take, from the block at
+// which a rival moved against one of our edges to the block our counter-move was included in. The
+// latency is measured per challenge level against a budget, which is a fraction of the challenge
+// period, and operators are alerted when a level's recent latencies put the budget at risk.
+package latency
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	defaultWindowSize = 256
+	// The percentile of the recent latencies of a level which, once it reaches atRiskRatio of the
+	// budget, puts the budget of the level at risk.
+	atRiskPercentile = 95
+	atRiskRatio      = 0.8
+)
+
+var (
+	exceededCounter = metrics.NewRegisteredCounter("arb/validator/latency/budget_exceeded", nil)
+	atRiskGauge     = metrics.NewRegisteredGauge("arb/validator/latency/levels_at_risk", nil)
+)
+
+// MoveKind is the kind of counter-move made against a rival.
+type MoveKind string
+
+const (
+	MoveBisection    MoveKind = "bisection"
+	MoveSubchallenge MoveKind = "subchallenge"
+	MoveOneStepProof MoveKind = "one_step_proof"
+)
+
+// Sample is the latency of one counter-move.
+type Sample struct {
+	Level  protocol.ChallengeLevel
+	Kind   MoveKind
+	EdgeId protocol.EdgeId
+	// The block at which our edge was rivaled, and the block our counter-move was included in.
+	RivalBlock uint64
+	MoveBlock  uint64
+	// The time between the timestamps of the rival and move blocks.
+	Latency time.Duration
+}
+
+// Blocks between the rival move and our counter-move.
+func (s Sample) Blocks() uint64 {
+	if s.MoveBlock < s.RivalBlock {
+		return 0
+	}
+	return s.MoveBlock - s.RivalBlock
+}
+
+// Budget tracks the latencies of counter-moves against a budget of blocks.
+type Budget struct {
+	lock                  sync.RWMutex
+	fraction              float64
+	challengePeriodBlocks atomic.Uint64
+	windowSize            int
+	levels                map[protocol.ChallengeLevel]*level
+}
+
+type level struct {
+	window   []Sample
+	next     int
+	total    uint64
+	exceeded uint64
+	atRisk   bool
+}
+
+type Opt func(*Budget)
+
+// WithWindowSize sets how many of the most recent samples of each level percentiles are computed
+// over. Defaults to 256.
+func WithWindowSize(size int) Opt {
+	return func(b *Budget) {
+		if size > 0 {
+			b.windowSize = size
+		}
+	}
+}
+
+// New budget of the given fraction of the challenge period for each counter-move. A fraction of 0
+// tracks latencies without a budget, so that nothing is ever considered at risk. The challenge
+// period may be 0 if it is not known yet, and set once it is read from the chain.
+func New(challengePeriodBlocks uint64, fraction float64, opts ...Opt) (*Budget, error) {
+	if fraction < 0 || fraction > 1 || math.IsNaN(fraction) {
+		return nil, fmt.Errorf("latency budget fraction must be between 0 and 1, got %v", fraction)
+	}
+	b := &Budget{
+		fraction:   fraction,
+		windowSize: defaultWindowSize,
+		levels:     make(map[protocol.ChallengeLevel]*level),
+	}
+	b.challengePeriodBlocks.Store(challengePeriodBlocks)
+	for _, o := range opts {
+		o(b)
+	}
+	return b, nil
+}
+
+// ChallengePeriodBlocks the budget is a fraction of, which is 0 if it is not known yet.
+func (b *Budget) ChallengePeriodBlocks() uint64 {
+	return b.challengePeriodBlocks.Load()
+}
+
+// SetChallengePeriodBlocks sets the challenge period the budget is a fraction of.
+func (b *Budget) SetChallengePeriodBlocks(blocks uint64) {
+	b.challengePeriodBlocks.Store(blocks)
+}
+
+// BudgetBlocks is the number of blocks a counter-move may take, which is 0 without a budget or
+// before the challenge period is known.
+func (b *Budget) BudgetBlocks() uint64 {
+	return uint64(b.fraction * float64(b.challengePeriodBlocks.Load()))
+}
+
+// Record the latency of a counter-move. Logs an error if it exceeded the budget, and a warning
+// when the recent latencies of its level put the budget at risk.
+func (b *Budget) Record(s Sample) {
+	metrics.GetOrRegisterHistogram(
+		fmt.Sprintf("arb/validator/latency/counter_move/level_%d/duration", s.Level), nil, metrics.NewUniformSample(100),
+	).Update(s.Latency.Nanoseconds())
+	metrics.GetOrRegisterHistogram(
+		fmt.Sprintf("arb/validator/latency/counter_move/level_%d/blocks", s.Level), nil, metrics.NewUniformSample(100),
+	).Update(int64(s.Blocks()))
+
+	budget := b.BudgetBlocks()
+	fields := []any{
+		"level", s.Level,
+		"kind", s.Kind,
+		"edgeId", s.EdgeId.Hash,
+		"rivalBlock", s.RivalBlock,
+		"moveBlock", s.MoveBlock,
+		"blocks", s.Blocks(),
+		"latency", s.Latency,
+		"budgetBlocks", budget,
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	l, ok := b.levels[s.Level]
+	if !ok {
+		l = &level{window: make([]Sample, 0, b.windowSize)}
+		b.levels[s.Level] = l
+	}
+	if len(l.window) < b.windowSize {
+		l.window = append(l.window, s)
+	} else {
+		l.window[l.next] = s
+	}
+	l.next = (l.next + 1) % b.windowSize
+	l.total++
+	if budget == 0 {
+		log.Debug("Recorded counter-move latency", fields...)
+		return
+	}
+	if s.Blocks() > budget {
+		l.exceeded++
+		exceededCounter.Inc(1)
+		log.Error("Counter-move exceeded its latency budget", fields...)
+	} else {
+		log.Debug("Recorded counter-move latency", fields...)
+	}
+	p := percentile(blocksOf(l.window), atRiskPercentile)
+	atRisk := float64(p) >= atRiskRatio*float64(budget)
+	if atRisk && !l.atRisk {
+		atRiskGauge.Inc(1)
+		log.Warn(
+			"Counter-move latencies put the latency budget of a challenge level at risk",
+			"level", s.Level,
+			fmt.Sprintf("p%dBlocks", atRiskPercentile), p,
+			"budgetBlocks", budget,
+			"samples", len(l.window),
+		)
+	} else if !atRisk && l.atRisk {
+		atRiskGauge.Dec(1)
+		log.Info("Counter-move latencies of a challenge level are within budget again", "level", s.Level)
+	}
+	l.atRisk = atRisk
+}
+
+// LevelSummary describes the recent counter-move latencies of a challenge level.
+type LevelSummary struct {
+	Level protocol.ChallengeLevel
+	// Samples in the window percentiles are computed over, and samples ever recorded.
+	Samples   int
+	Total     uint64
+	Exceeded  uint64
+	AtRisk    bool
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+	P50Blocks uint64
+	P90Blocks uint64
+	P99Blocks uint64
+	MaxBlocks uint64
+}
+
+// Summary describes the budget and the recent counter-move latencies of every level.
+type Summary struct {
+	ChallengePeriodBlocks uint64
+	Fraction              float64
+	BudgetBlocks          uint64
+	// Ordered by challenge level.
+	Levels []LevelSummary
+}
+
+// Summary of the latencies recorded so far.
+func (b *Budget) Summary() *Summary {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	summary := &Summary{
+		ChallengePeriodBlocks: b.challengePeriodBlocks.Load(),
+		Fraction:              b.fraction,
+		BudgetBlocks:          b.BudgetBlocks(),
+		Levels:                make([]LevelSummary, 0, len(b.levels)),
+	}
+	for challengeLevel, l := range b.levels {
+		durations := make([]uint64, len(l.window))
+		for i, s := range l.window {
+			durations[i] = uint64(s.Latency)
+		}
+		blocks := blocksOf(l.window)
+		summary.Levels = append(summary.Levels, LevelSummary{
+			Level:     challengeLevel,
+			Samples:   len(l.window),
+			Total:     l.total,
+			Exceeded:  l.exceeded,
+			AtRisk:    l.atRisk,
+			P50:       time.Duration(percentile(durations, 50)),
+			P90:       time.Duration(percentile(durations, 90)),
+			P99:       time.Duration(percentile(durations, 99)),
+			Max:       time.Duration(percentile(durations, 100)),
+			P50Blocks: percentile(blocks, 50),
+			P90Blocks: percentile(blocks, 90),
+			P99Blocks: percentile(blocks, 99),
+			MaxBlocks: percentile(blocks, 100),
+		})
+	}
+	sort.Slice(summary.Levels, func(i, j int) bool {
+		return summary.Levels[i].Level < summary.Levels[j].Level
+	})
+	return summary
+}
+
+func blocksOf(samples []Sample) []uint64 {
+	blocks := make([]uint64, len(samples))
+	for i, s := range samples {
+		blocks[i] = s.Blocks()
+	}
+	return blocks
+}
+
+// Nearest-rank percentile of values, which is 0 for no values. Sorts the values in place.
+func percentile(values []uint64, p int) uint64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
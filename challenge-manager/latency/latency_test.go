@@ -0,0 +1,102 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/stretchr/testify/require"
+)
+
+func sample(level protocol.ChallengeLevel, blocks uint64) Sample {
+	return Sample{
+		Level:      level,
+		Kind:       MoveBisection,
+		RivalBlock: 100,
+		MoveBlock:  100 + blocks,
+		Latency:    time.Duration(blocks) * 12 * time.Second,
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(100, -0.1)
+	require.ErrorContains(t, err, "between 0 and 1")
+	_, err = New(100, 1.5)
+	require.ErrorContains(t, err, "between 0 and 1")
+	b, err := New(1000, 0.05)
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), b.BudgetBlocks())
+
+	b, err = New(0, 0.05)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), b.BudgetBlocks())
+	b.SetChallengePeriodBlocks(2000)
+	require.Equal(t, uint64(100), b.BudgetBlocks())
+}
+
+func TestBudget_Summary(t *testing.T) {
+	b, err := New(1000, 0.1)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 100; i++ {
+		b.Record(sample(0, i%50))
+	}
+	b.Record(sample(2, 5))
+
+	summary := b.Summary()
+	require.Equal(t, uint64(100), summary.BudgetBlocks)
+	require.Len(t, summary.Levels, 2)
+	blockLevel := summary.Levels[0]
+	require.Equal(t, protocol.ChallengeLevel(0), blockLevel.Level)
+	require.Equal(t, 100, blockLevel.Samples)
+	require.Equal(t, uint64(24), blockLevel.P50Blocks)
+	require.Equal(t, uint64(44), blockLevel.P90Blocks)
+	require.Equal(t, uint64(49), blockLevel.P99Blocks)
+	require.Equal(t, uint64(49), blockLevel.MaxBlocks)
+	require.Equal(t, 24*12*time.Second, blockLevel.P50)
+	require.Equal(t, uint64(0), blockLevel.Exceeded)
+	require.False(t, blockLevel.AtRisk)
+	require.Equal(t, protocol.ChallengeLevel(2), summary.Levels[1].Level)
+	require.Equal(t, uint64(5), summary.Levels[1].MaxBlocks)
+}
+
+func TestBudget_AtRisk(t *testing.T) {
+	b, err := New(100, 0.1, WithWindowSize(10))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		b.Record(sample(1, 2))
+	}
+	require.False(t, b.Summary().Levels[0].AtRisk)
+
+	// Once the p95 reaches 80% of the budget of 10 blocks, the budget is at risk.
+	b.Record(sample(1, 8))
+	summary := b.Summary().Levels[0]
+	require.True(t, summary.AtRisk)
+	require.Equal(t, uint64(0), summary.Exceeded)
+
+	b.Record(sample(1, 11))
+	summary = b.Summary().Levels[0]
+	require.Equal(t, uint64(1), summary.Exceeded)
+	require.Equal(t, uint64(12), summary.Total)
+
+	// Slow samples leave the window as fast ones are recorded.
+	for i := 0; i < 10; i++ {
+		b.Record(sample(1, 2))
+	}
+	summary = b.Summary().Levels[0]
+	require.False(t, summary.AtRisk)
+	require.Equal(t, 10, summary.Samples)
+	require.Equal(t, uint64(2), summary.MaxBlocks)
+}
+
+func TestBudget_WithoutBudget(t *testing.T) {
+	b, err := New(100, 0)
+	require.NoError(t, err)
+	b.Record(sample(0, 1000))
+	summary := b.Summary()
+	require.Equal(t, uint64(0), summary.BudgetBlocks)
+	require.False(t, summary.Levels[0].AtRisk)
+	require.Equal(t, uint64(0), summary.Levels[0].Exceeded)
+}
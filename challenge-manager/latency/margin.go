@@ -0,0 +1,109 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package latency
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	marginTightenedGauge = metrics.NewRegisteredGauge("arb/validator/latency/deadline_margin_tightened", nil)
+	marginBlocksGauge    = metrics.NewRegisteredGauge("arb/validator/latency/deadline_margin_blocks", nil)
+)
+
+// Margin is the safety margin of blocks a challenge manager keeps before the deadlines of
+// challenges, such as the royal root edge of a challenge becoming confirmable. A margin which our
+// recent counter-moves took longer than to be included is provably insufficient, as a deadline
+// within it may be missed, so the margin tightens to the inclusion latency the budget observed
+// until it recovers.
+type Margin struct {
+	configured uint64
+	budget     *Budget
+	tightened  atomic.Bool
+}
+
+// NewMargin of the given blocks, checked against the counter-move latencies the budget records.
+func NewMargin(blocks uint64, budget *Budget) *Margin {
+	return &Margin{
+		configured: blocks,
+		budget:     budget,
+	}
+}
+
+// Validate checks that the margin is sane relative to the challenge period. A margin of at least
+// the challenge period leaves no block outside of it, and one above half the challenge period
+// makes most of each challenge urgent, which is only warned about.
+func (m *Margin) Validate(challengePeriodBlocks uint64) error {
+	if challengePeriodBlocks == 0 || m.configured == 0 {
+		return nil
+	}
+	if m.configured >= challengePeriodBlocks {
+		return fmt.Errorf(
+			"deadline margin of %d blocks must be below the challenge period of %d blocks",
+			m.configured,
+			challengePeriodBlocks,
+		)
+	}
+	if m.configured > challengePeriodBlocks/2 {
+		log.Warn(
+			"Deadline margin is above half of the challenge period, so most of each challenge is treated as urgent",
+			"marginBlocks", m.configured,
+			"challengePeriodBlocks", challengePeriodBlocks,
+		)
+	}
+	return nil
+}
+
+// ConfiguredBlocks is the margin operators configured.
+func (m *Margin) ConfiguredBlocks() uint64 {
+	return m.configured
+}
+
+// ObservedBlocks is the inclusion latency of our recent counter-moves at the slowest challenge
+// level, by the p99 of its latencies, which is 0 until any is recorded.
+func (m *Margin) ObservedBlocks() uint64 {
+	if m.budget == nil {
+		return 0
+	}
+	var observed uint64
+	for _, l := range m.budget.Summary().Levels {
+		observed = max(observed, l.P99Blocks)
+	}
+	return observed
+}
+
+// Blocks is the margin to keep before deadlines, which is the configured margin unless recent
+// inclusion latencies exceed it, in which case it tightens to those latencies. Logs a warning when
+// the margin starts or stops being tightened.
+func (m *Margin) Blocks() uint64 {
+	observed := m.ObservedBlocks()
+	tightened := observed > m.configured
+	if tightened != m.tightened.Swap(tightened) {
+		if tightened {
+			marginTightenedGauge.Update(1)
+			log.Warn(
+				"Configured deadline margin is insufficient for the observed inclusion latency, tightening behavior",
+				"marginBlocks", m.configured,
+				"observedBlocks", observed,
+			)
+		} else {
+			marginTightenedGauge.Update(0)
+			log.Info("Observed inclusion latency is within the deadline margin again", "marginBlocks", m.configured, "observedBlocks", observed)
+		}
+	}
+	blocks := max(m.configured, observed)
+	marginBlocksGauge.Update(int64(blocks))
+	return blocks
+}
+
+// Tightened reports whether recent inclusion latencies exceed the configured margin, under which
+// the challenge manager tightens its behavior, such as by not delaying challenges.
+func (m *Margin) Tightened() bool {
+	m.Blocks()
+	return m.tightened.Load()
+}
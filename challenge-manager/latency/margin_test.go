@@ -0,0 +1,55 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package latency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMargin_Validate(t *testing.T) {
+	require.NoError(t, NewMargin(0, nil).Validate(100))
+	require.NoError(t, NewMargin(100, nil).Validate(0))
+	require.NoError(t, NewMargin(10, nil).Validate(100))
+	// Above half of the challenge period is only warned about.
+	require.NoError(t, NewMargin(60, nil).Validate(100))
+	require.ErrorContains(t, NewMargin(100, nil).Validate(100), "must be below the challenge period")
+	require.ErrorContains(t, NewMargin(150, nil).Validate(100), "must be below the challenge period")
+}
+
+func TestMargin_TightensUnderDegradedInclusion(t *testing.T) {
+	b, err := New(1000, 0.1)
+	require.NoError(t, err)
+	m := NewMargin(20, b)
+	require.Equal(t, uint64(0), m.ObservedBlocks())
+	require.Equal(t, uint64(20), m.Blocks())
+	require.False(t, m.Tightened())
+
+	for i := 0; i < 10; i++ {
+		b.Record(sample(0, 10))
+	}
+	require.Equal(t, uint64(10), m.ObservedBlocks())
+	require.Equal(t, uint64(20), m.Blocks())
+	require.False(t, m.Tightened())
+
+	// A single counter-move at any level taking longer than the margin proves it insufficient.
+	b.Record(sample(2, 35))
+	require.Equal(t, uint64(35), m.ObservedBlocks())
+	require.Equal(t, uint64(35), m.Blocks())
+	require.True(t, m.Tightened())
+	require.Equal(t, uint64(20), m.ConfiguredBlocks())
+
+	// The margin recovers once the slow counter-moves leave the window.
+	small, err := New(1000, 0.1, WithWindowSize(4))
+	require.NoError(t, err)
+	m = NewMargin(20, small)
+	small.Record(sample(0, 35))
+	require.True(t, m.Tightened())
+	for i := 0; i < 4; i++ {
+		small.Record(sample(0, 5))
+	}
+	require.False(t, m.Tightened())
+	require.Equal(t, uint64(20), m.Blocks())
+}
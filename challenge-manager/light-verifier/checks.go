@@ -0,0 +1,256 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package lightverifier
+
+import (
+	"fmt"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/math"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AnomalyKind classifies a violation of the challenge protocol found by the light verifier.
+type AnomalyKind uint8
+
+const (
+	// An edge's id or mutual id does not commit to its level, origin, heights, and history roots.
+	InconsistentEdgeId AnomalyKind = iota
+	// A layer zero edge does not span from height zero to the layer zero height of its level.
+	InvalidLayerZeroHeights
+	// The children of a bisected edge do not split it at its midpoint.
+	InvalidBisection
+	// An edge's timer accrued more blocks than have passed, or it was confirmed before its
+	// timer reached the challenge period.
+	ImpossibleTimer
+	// An event disagrees with the edge it describes as stored by the challenge manager.
+	InconsistentEvent
+)
+
+var anomalyKindNames = map[AnomalyKind]string{
+	InconsistentEdgeId:      "inconsistent_edge_id",
+	InvalidLayerZeroHeights: "invalid_layer_zero_heights",
+	InvalidBisection:        "invalid_bisection",
+	ImpossibleTimer:         "impossible_timer",
+	InconsistentEvent:       "inconsistent_event",
+}
+
+func (k AnomalyKind) String() string {
+	if name, ok := anomalyKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(k))
+}
+
+// Anomaly is a violation of the challenge protocol observed on chain.
+type Anomaly struct {
+	Kind   AnomalyKind
+	EdgeId protocol.EdgeId
+	Detail string
+}
+
+func (a *Anomaly) String() string {
+	return fmt.Sprintf("%s on edge %#x: %s", a.Kind, a.EdgeId.Bytes(), a.Detail)
+}
+
+// The parts of an edge the checks need, which are all read from the challenge manager's storage.
+type edgeInfo struct {
+	id          protocol.EdgeId
+	level       protocol.ChallengeLevel
+	originId    protocol.OriginId
+	mutualId    protocol.MutualId
+	claimId     common.Hash
+	startHeight protocol.Height
+	startRoot   common.Hash
+	endHeight   protocol.Height
+	endRoot     common.Hash
+	createdAt   uint64
+}
+
+func newEdgeInfo(edge protocol.SpecEdge) (*edgeInfo, error) {
+	createdAt, err := edge.CreatedAtBlock()
+	if err != nil {
+		return nil, err
+	}
+	info := &edgeInfo{
+		id:        edge.Id(),
+		level:     edge.GetChallengeLevel(),
+		originId:  edge.OriginId(),
+		mutualId:  edge.MutualId(),
+		createdAt: createdAt,
+	}
+	if claimId := edge.ClaimId(); claimId.IsSome() {
+		info.claimId = common.Hash(claimId.Unwrap())
+	}
+	info.startHeight, info.startRoot = edge.StartCommitment()
+	info.endHeight, info.endRoot = edge.EndCommitment()
+	return info, nil
+}
+
+func (e *edgeInfo) isLayerZero() bool {
+	return e.claimId != (common.Hash{})
+}
+
+// The height at which the layer zero edges of a level end, where the last level is the small
+// step level.
+func layerZeroHeight(heights *protocol.LayerZeroHeights, level protocol.ChallengeLevel, numBigStepLevels uint8) uint64 {
+	switch {
+	case level.IsBlockChallengeLevel():
+		return heights.BlockChallengeHeight
+	case level.Uint8() == numBigStepLevels+1:
+		return heights.SmallStepChallengeHeight
+	default:
+		return heights.BigStepChallengeHeight
+	}
+}
+
+// Checks that an edge's ids are the hashes of its contents, and that a layer zero edge spans its
+// whole level.
+func checkEdge(edge *edgeInfo, heights *protocol.LayerZeroHeights, numBigStepLevels uint8) []*Anomaly {
+	anomalies := make([]*Anomaly, 0)
+	mutualId := protocol.ComputeMutualId(edge.level, edge.originId, edge.startHeight, edge.startRoot, edge.endHeight)
+	if mutualId != edge.mutualId {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   InconsistentEdgeId,
+			EdgeId: edge.id,
+			Detail: fmt.Sprintf("mutual id %#x, computed %#x", edge.mutualId, mutualId),
+		})
+	}
+	id := protocol.ComputeEdgeId(edge.level, edge.originId, edge.startHeight, edge.startRoot, edge.endHeight, edge.endRoot)
+	if id != edge.id {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   InconsistentEdgeId,
+			EdgeId: edge.id,
+			Detail: fmt.Sprintf("computed edge id %#x", id.Bytes()),
+		})
+	}
+	if edge.isLayerZero() {
+		want := layerZeroHeight(heights, edge.level, numBigStepLevels)
+		if edge.startHeight != 0 || uint64(edge.endHeight) != want {
+			anomalies = append(anomalies, &Anomaly{
+				Kind:   InvalidLayerZeroHeights,
+				EdgeId: edge.id,
+				Detail: fmt.Sprintf("spans heights %d to %d, want 0 to %d", edge.startHeight, edge.endHeight, want),
+			})
+		}
+	}
+	return anomalies
+}
+
+// Checks that an edge added event describes the edge stored by the challenge manager.
+func checkEdgeAddedEvent(ev *challengeV2gen.EdgeChallengeManagerEdgeAdded, edge *edgeInfo) []*Anomaly {
+	anomalies := make([]*Anomaly, 0)
+	mismatch := func(field string, got, want any) {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   InconsistentEvent,
+			EdgeId: edge.id,
+			Detail: fmt.Sprintf("edge added event has %s %v, edge has %v", field, got, want),
+		})
+	}
+	if ev.MutualId != edge.mutualId {
+		mismatch("mutual id", common.Hash(ev.MutualId), common.Hash(edge.mutualId))
+	}
+	if ev.OriginId != edge.originId {
+		mismatch("origin id", common.Hash(ev.OriginId), common.Hash(edge.originId))
+	}
+	if ev.ClaimId != edge.claimId {
+		mismatch("claim id", common.Hash(ev.ClaimId), edge.claimId)
+	}
+	if ev.Level != edge.level.Uint8() {
+		mismatch("level", ev.Level, edge.level.Uint8())
+	}
+	length := protocol.Length(edge.endHeight - edge.startHeight)
+	if evLength, err := protocol.LengthFromBig(ev.Length); err != nil {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   InconsistentEvent,
+			EdgeId: edge.id,
+			Detail: fmt.Sprintf("edge added event: %v", err),
+		})
+	} else if evLength != length {
+		mismatch("length", evLength, length)
+	}
+	if ev.IsLayerZero != edge.isLayerZero() {
+		mismatch("layer zero flag", ev.IsLayerZero, edge.isLayerZero())
+	}
+	return anomalies
+}
+
+// Checks that the children of a bisected edge split it at the midpoint the challenge manager
+// bisects at, with the lower child's end history root being the upper child's start history root.
+// A bisected edge must also have had a rival.
+func checkBisection(parent, lower, upper *edgeInfo, parentHasRival bool) []*Anomaly {
+	anomalies := make([]*Anomaly, 0)
+	invalid := func(format string, args ...any) {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   InvalidBisection,
+			EdgeId: parent.id,
+			Detail: fmt.Sprintf(format, args...),
+		})
+	}
+	if !parentHasRival {
+		invalid("bisected without a rival")
+	}
+	for _, child := range []*edgeInfo{lower, upper} {
+		if child.level != parent.level || child.originId != parent.originId {
+			invalid("child %#x has level %d and origin %#x, parent has level %d and origin %#x",
+				child.id.Bytes(), child.level, child.originId, parent.level, parent.originId)
+		}
+	}
+	mid, err := math.Bisect(uint64(parent.startHeight), uint64(parent.endHeight))
+	if err != nil {
+		invalid("cannot bisect heights %d to %d", parent.startHeight, parent.endHeight)
+		return anomalies
+	}
+	if lower.startHeight != parent.startHeight || lower.startRoot != parent.startRoot {
+		invalid("lower child does not start at the start of the parent")
+	}
+	if uint64(lower.endHeight) != mid || uint64(upper.startHeight) != mid {
+		invalid("children meet at heights %d and %d, want %d", lower.endHeight, upper.startHeight, mid)
+	}
+	if lower.endRoot != upper.startRoot {
+		invalid("lower child ends at history root %#x, upper child starts at %#x", lower.endRoot, upper.startRoot)
+	}
+	if upper.endHeight != parent.endHeight || upper.endRoot != parent.endRoot {
+		invalid("upper child does not end at the end of the parent")
+	}
+	return anomalies
+}
+
+// Checks that an edge has not been unrivaled for longer than it has existed.
+func checkTimeUnrivaled(edge *edgeInfo, timeUnrivaled, blockNum uint64) *Anomaly {
+	if blockNum < edge.createdAt || timeUnrivaled <= blockNum-edge.createdAt {
+		return nil
+	}
+	return &Anomaly{
+		Kind:   ImpossibleTimer,
+		EdgeId: edge.id,
+		Detail: fmt.Sprintf("unrivaled for %d blocks at block %d, but created at block %d", timeUnrivaled, blockNum, edge.createdAt),
+	}
+}
+
+// Checks that an edge confirmed by time had a timer of at least the challenge period, which the
+// challenge manager requires, and that the event describes the confirmed edge.
+func checkConfirmedByTime(
+	ev *challengeV2gen.EdgeChallengeManagerEdgeConfirmedByTime,
+	edge *edgeInfo,
+	challengePeriodBlocks uint64,
+) []*Anomaly {
+	anomalies := make([]*Anomaly, 0)
+	if ev.MutualId != edge.mutualId {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   InconsistentEvent,
+			EdgeId: edge.id,
+			Detail: fmt.Sprintf("confirmed by time event has mutual id %#x, edge has %#x", ev.MutualId, edge.mutualId),
+		})
+	}
+	if ev.TotalTimeUnrivaled == nil || (ev.TotalTimeUnrivaled.IsUint64() && ev.TotalTimeUnrivaled.Uint64() < challengePeriodBlocks) {
+		anomalies = append(anomalies, &Anomaly{
+			Kind:   ImpossibleTimer,
+			EdgeId: edge.id,
+			Detail: fmt.Sprintf("confirmed by time with a timer of %v blocks, below the challenge period of %d", ev.TotalTimeUnrivaled, challengePeriodBlocks),
+		})
+	}
+	return anomalies
+}
@@ -0,0 +1,131 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package lightverifier
+
+import (
+	"math/big"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var heights = &protocol.LayerZeroHeights{
+	BlockChallengeHeight:     32,
+	BigStepChallengeHeight:   32,
+	SmallStepChallengeHeight: 32,
+}
+
+func root(height uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(height + 1000))
+}
+
+// Builds an edge whose ids commit to its contents, with the history root at each height derived
+// from the height.
+func newEdge(level protocol.ChallengeLevel, start, end uint64, claimId common.Hash) *edgeInfo {
+	originId := protocol.OriginId(common.BytesToHash([]byte("origin")))
+	return &edgeInfo{
+		id:          protocol.ComputeEdgeId(level, originId, protocol.Height(start), root(start), protocol.Height(end), root(end)),
+		level:       level,
+		originId:    originId,
+		mutualId:    protocol.ComputeMutualId(level, originId, protocol.Height(start), root(start), protocol.Height(end)),
+		claimId:     claimId,
+		startHeight: protocol.Height(start),
+		startRoot:   root(start),
+		endHeight:   protocol.Height(end),
+		endRoot:     root(end),
+		createdAt:   100,
+	}
+}
+
+func kinds(anomalies []*Anomaly) []AnomalyKind {
+	result := make([]AnomalyKind, 0, len(anomalies))
+	for _, a := range anomalies {
+		result = append(result, a.Kind)
+	}
+	return result
+}
+
+func TestCheckEdge(t *testing.T) {
+	claimId := common.BytesToHash([]byte("claim"))
+	require.Empty(t, checkEdge(newEdge(0, 0, 32, claimId), heights, 1))
+	require.Empty(t, checkEdge(newEdge(0, 16, 24, common.Hash{}), heights, 1))
+
+	tampered := newEdge(0, 0, 32, claimId)
+	tampered.endRoot = root(31)
+	require.Equal(t, []AnomalyKind{InconsistentEdgeId}, kinds(checkEdge(tampered, heights, 1)))
+
+	tampered = newEdge(0, 0, 32, claimId)
+	tampered.startRoot = root(1)
+	require.Equal(t, []AnomalyKind{InconsistentEdgeId, InconsistentEdgeId}, kinds(checkEdge(tampered, heights, 1)))
+
+	short := newEdge(2, 0, 16, claimId)
+	require.Equal(t, []AnomalyKind{InvalidLayerZeroHeights}, kinds(checkEdge(short, heights, 1)))
+	require.Equal(t, uint64(16), layerZeroHeight(&protocol.LayerZeroHeights{SmallStepChallengeHeight: 16}, 2, 1))
+}
+
+func TestCheckEdgeAddedEvent(t *testing.T) {
+	claimId := common.BytesToHash([]byte("claim"))
+	edge := newEdge(1, 0, 32, claimId)
+	ev := &challengeV2gen.EdgeChallengeManagerEdgeAdded{
+		EdgeId:      edge.id.Hash,
+		MutualId:    edge.mutualId,
+		OriginId:    edge.originId,
+		ClaimId:     claimId,
+		Length:      big.NewInt(32),
+		Level:       1,
+		IsLayerZero: true,
+	}
+	require.Empty(t, checkEdgeAddedEvent(ev, edge))
+
+	ev.Length = big.NewInt(16)
+	ev.IsLayerZero = false
+	anomalies := checkEdgeAddedEvent(ev, edge)
+	require.Equal(t, []AnomalyKind{InconsistentEvent, InconsistentEvent}, kinds(anomalies))
+	require.Contains(t, anomalies[0].Detail, "length 16, edge has 32")
+}
+
+func TestCheckBisection(t *testing.T) {
+	parent := newEdge(0, 0, 32, common.Hash{})
+	lower := newEdge(0, 0, 16, common.Hash{})
+	upper := newEdge(0, 16, 32, common.Hash{})
+	require.Empty(t, checkBisection(parent, lower, upper, true))
+	require.Equal(t, []AnomalyKind{InvalidBisection}, kinds(checkBisection(parent, lower, upper, false)))
+
+	// The children must meet at the midpoint, with the same history root.
+	offCenter := checkBisection(parent, newEdge(0, 0, 8, common.Hash{}), newEdge(0, 8, 32, common.Hash{}), true)
+	require.Len(t, offCenter, 1)
+	require.Contains(t, offCenter[0].Detail, "children meet at heights 8 and 8, want 16")
+
+	disjoint := newEdge(0, 16, 32, common.Hash{})
+	disjoint.startRoot = root(17)
+	require.Equal(t, []AnomalyKind{InvalidBisection}, kinds(checkBisection(parent, lower, disjoint, true)))
+
+	otherLevel := newEdge(1, 16, 32, common.Hash{})
+	require.Equal(t, []AnomalyKind{InvalidBisection}, kinds(checkBisection(parent, lower, otherLevel, true)))
+
+	lengthOne := newEdge(0, 4, 5, common.Hash{})
+	anomalies := checkBisection(lengthOne, lower, upper, true)
+	require.Len(t, anomalies, 1)
+	require.Contains(t, anomalies[0].Detail, "cannot bisect heights 4 to 5")
+}
+
+func TestCheckTimers(t *testing.T) {
+	edge := newEdge(0, 0, 32, common.Hash{})
+	require.Nil(t, checkTimeUnrivaled(edge, 50, 150))
+	anomaly := checkTimeUnrivaled(edge, 51, 150)
+	require.Equal(t, ImpossibleTimer, anomaly.Kind)
+
+	ev := &challengeV2gen.EdgeChallengeManagerEdgeConfirmedByTime{
+		EdgeId:             edge.id.Hash,
+		MutualId:           edge.mutualId,
+		TotalTimeUnrivaled: big.NewInt(100),
+	}
+	require.Empty(t, checkConfirmedByTime(ev, edge, 100))
+	require.Equal(t, []AnomalyKind{ImpossibleTimer}, kinds(checkConfirmedByTime(ev, edge, 101)))
+	ev.MutualId = [32]byte{}
+	require.Equal(t, []AnomalyKind{InconsistentEvent}, kinds(checkConfirmedByTime(ev, edge, 100)))
+}
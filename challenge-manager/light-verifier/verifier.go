@@ -0,0 +1,270 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package lightverifier implements a light verification mode for watchers without the resources
+// to run a state provider. Instead of executing the machine, it checks that challenges progress
+// as the protocol allows using only what the challenge manager stores and emits: that edge ids
+// commit to their history roots, that layer zero edges span their level, that bisections split
+// edges at their midpoint, and that timers are possible. It cannot tell which side of a challenge
+// is honest, only that the challenge manager's state is structurally valid.
+package lightverifier
+
+import (
+	"context"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
+	"github.com/OffchainLabs/bold/util/stopwaiter"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	edgesCheckedCounter      = metrics.NewRegisteredCounter("arb/validator/light_verifier/edges_checked", nil)
+	bisectionsCheckedCounter = metrics.NewRegisteredCounter("arb/validator/light_verifier/bisections_checked", nil)
+)
+
+// Verifier scans the challenge manager's events and reports any anomalies it finds.
+type Verifier struct {
+	stopwaiter.StopWaiter
+	chain        protocol.AssertionChain
+	pollInterval time.Duration
+	startBlock   uint64
+	onAnomaly    func(*Anomaly)
+}
+
+// Opt configures a light verifier.
+type Opt func(*Verifier)
+
+// WithStartBlock sets the block from which to scan for challenge events. Defaults to block zero,
+// so it should be set to the deployment block of the challenge manager when scanning from a node
+// with many blocks.
+func WithStartBlock(startBlock uint64) Opt {
+	return func(v *Verifier) {
+		v.startBlock = startBlock
+	}
+}
+
+// WithAnomalyHandler sets a function called with each anomaly found, in addition to it being
+// logged and counted in metrics.
+func WithAnomalyHandler(handler func(*Anomaly)) Opt {
+	return func(v *Verifier) {
+		v.onAnomaly = handler
+	}
+}
+
+// New creates a light verifier polling for challenge events at the given interval.
+func New(chain protocol.AssertionChain, pollInterval time.Duration, opts ...Opt) (*Verifier, error) {
+	if pollInterval == 0 {
+		return nil, errors.New("poll interval must be positive")
+	}
+	v := &Verifier{
+		chain:        chain,
+		pollInterval: pollInterval,
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v, nil
+}
+
+// The challenge manager's parameters the checks depend on, which are fixed at deployment.
+type challengeParams struct {
+	heights               *protocol.LayerZeroHeights
+	numBigStepLevels      uint8
+	challengePeriodBlocks uint64
+}
+
+func (v *Verifier) Start(ctx context.Context) {
+	v.StopWaiter.Start(ctx, v)
+	v.LaunchThread(v.run)
+}
+
+func (v *Verifier) run(ctx context.Context) {
+	challengeManager, err := retry.UntilSucceeds(ctx, func() (protocol.SpecChallengeManager, error) {
+		return v.chain.SpecChallengeManager(ctx)
+	}, retry.WithPolicy(retry.RPCRead))
+	if err != nil {
+		log.Error("Could not get spec challenge manager", "err", err)
+		return
+	}
+	params, err := retry.UntilSucceeds(ctx, func() (*challengeParams, error) {
+		return readChallengeParams(ctx, challengeManager)
+	}, retry.WithPolicy(retry.RPCRead))
+	if err != nil {
+		log.Error("Could not read challenge manager parameters", "err", err)
+		return
+	}
+	filterer, err := retry.UntilSucceeds(ctx, func() (*challengeV2gen.EdgeChallengeManagerFilterer, error) {
+		return challengeV2gen.NewEdgeChallengeManagerFilterer(challengeManager.Address(), v.chain.Backend())
+	})
+	if err != nil {
+		log.Error("Could not initialize edge challenge manager filterer", "err", err)
+		return
+	}
+	fromBlock := v.startBlock
+	ticker := time.NewTicker(v.pollInterval)
+	defer ticker.Stop()
+	for {
+		header, err := v.chain.Backend().HeaderByNumber(ctx, v.chain.GetDesiredRpcHeadBlockNumber())
+		switch {
+		case err != nil:
+			log.Error("Could not get latest header", "err", err)
+		case !header.Number.IsUint64():
+			log.Error("Latest block header number is not a uint64")
+		case header.Number.Uint64() >= fromBlock:
+			toBlock := header.Number.Uint64()
+			filterOpts := &bind.FilterOpts{
+				Start:   fromBlock,
+				End:     &toBlock,
+				Context: ctx,
+			}
+			if err := v.scan(ctx, challengeManager, filterer, params, filterOpts); err != nil {
+				log.Error("Could not verify challenge events", "fromBlock", fromBlock, "toBlock", toBlock, "err", err)
+			} else {
+				fromBlock = toBlock + 1
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func readChallengeParams(ctx context.Context, challengeManager protocol.SpecChallengeManager) (*challengeParams, error) {
+	heights, err := challengeManager.LayerZeroHeights(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get layer zero heights")
+	}
+	numBigStepLevels, err := challengeManager.NumBigSteps(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get number of big step levels")
+	}
+	challengePeriodBlocks, err := challengeManager.ChallengePeriodBlocks(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get challenge period blocks")
+	}
+	return &challengeParams{
+		heights:               heights,
+		numBigStepLevels:      numBigStepLevels,
+		challengePeriodBlocks: challengePeriodBlocks,
+	}, nil
+}
+
+// Checks the edges added, bisected, and confirmed by time in a block range. A range is only
+// considered verified once all its events are checked, so it is scanned again if any read fails.
+func (v *Verifier) scan(
+	ctx context.Context,
+	challengeManager protocol.SpecChallengeManager,
+	filterer *challengeV2gen.EdgeChallengeManagerFilterer,
+	params *challengeParams,
+	filterOpts *bind.FilterOpts,
+) error {
+	added, err := filterer.FilterEdgeAdded(filterOpts, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	err = iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeAdded](added, func(ev *challengeV2gen.EdgeChallengeManagerEdgeAdded) error {
+		edge, err := readEdge(ctx, challengeManager, protocol.EdgeId{Hash: ev.EdgeId})
+		if err != nil {
+			return err
+		}
+		info, err := newEdgeInfo(edge)
+		if err != nil {
+			return err
+		}
+		v.report(checkEdge(info, params.heights, params.numBigStepLevels)...)
+		v.report(checkEdgeAddedEvent(ev, info)...)
+		timeUnrivaled, err := edge.TimeUnrivaled(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "could not get time unrivaled of edge %#x", ev.EdgeId)
+		}
+		if anomaly := checkTimeUnrivaled(info, timeUnrivaled, *filterOpts.End); anomaly != nil {
+			v.report(anomaly)
+		}
+		edgesCheckedCounter.Inc(1)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bisected, err := filterer.FilterEdgeBisected(filterOpts, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	err = iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeBisected](bisected, func(ev *challengeV2gen.EdgeChallengeManagerEdgeBisected) error {
+		edges := make([]*edgeInfo, 0, 3)
+		var parentHasRival bool
+		for i, id := range [][32]byte{ev.EdgeId, ev.LowerChildId, ev.UpperChildId} {
+			edge, err := readEdge(ctx, challengeManager, protocol.EdgeId{Hash: id})
+			if err != nil {
+				return err
+			}
+			info, err := newEdgeInfo(edge)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				// An edge cannot lose its rival, so it must have one now if it had one when bisected.
+				parentHasRival, err = edge.HasRival(ctx)
+				if err != nil {
+					return errors.Wrapf(err, "could not check if edge %#x has a rival", id)
+				}
+			}
+			edges = append(edges, info)
+		}
+		v.report(checkBisection(edges[0], edges[1], edges[2], parentHasRival)...)
+		bisectionsCheckedCounter.Inc(1)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := filterer.FilterEdgeConfirmedByTime(filterOpts, nil, nil)
+	if err != nil {
+		return err
+	}
+	return iterators.ForEachEvent[*challengeV2gen.EdgeChallengeManagerEdgeConfirmedByTime](confirmed, func(ev *challengeV2gen.EdgeChallengeManagerEdgeConfirmedByTime) error {
+		edge, err := readEdge(ctx, challengeManager, protocol.EdgeId{Hash: ev.EdgeId})
+		if err != nil {
+			return err
+		}
+		info, err := newEdgeInfo(edge)
+		if err != nil {
+			return err
+		}
+		v.report(checkConfirmedByTime(ev, info, params.challengePeriodBlocks)...)
+		return nil
+	})
+}
+
+// Reads an edge an event refers to, which must exist in the challenge manager's storage.
+func readEdge(ctx context.Context, challengeManager protocol.SpecChallengeManager, edgeId protocol.EdgeId) (protocol.SpecEdge, error) {
+	edge, err := challengeManager.GetEdge(ctx, edgeId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get edge %#x", edgeId.Bytes())
+	}
+	if edge.IsNone() {
+		return nil, errors.Errorf("edge %#x from an event does not exist", edgeId.Bytes())
+	}
+	return edge.Unwrap(), nil
+}
+
+func (v *Verifier) report(anomalies ...*Anomaly) {
+	for _, a := range anomalies {
+		log.Error("Light verifier found a protocol anomaly", "kind", a.Kind, "edgeId", a.EdgeId.Hash, "detail", a.Detail)
+		metrics.GetOrRegisterCounter("arb/validator/light_verifier/anomalies/"+a.Kind.String(), nil).Inc(1)
+		if v.onAnomaly != nil {
+			v.onAnomaly(a)
+		}
+	}
+}
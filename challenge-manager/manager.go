@@ -8,30 +8,44 @@ package challengemanager
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
-	apibackend "github.com/OffchainLabs/bold/api/backend"
 	"github.com/OffchainLabs/bold/api/db"
-	"github.com/OffchainLabs/bold/api/server"
+	"github.com/OffchainLabs/bold/api/digest"
+	"github.com/OffchainLabs/bold/api/webhooks"
 	"github.com/OffchainLabs/bold/assertions"
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/challenge-manager/artifacts"
 	watcher "github.com/OffchainLabs/bold/challenge-manager/chain-watcher"
+	"github.com/OffchainLabs/bold/challenge-manager/config"
 	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
+	"github.com/OffchainLabs/bold/challenge-manager/hooks"
+	"github.com/OffchainLabs/bold/challenge-manager/latency"
+	"github.com/OffchainLabs/bold/challenge-manager/policy"
 	"github.com/OffchainLabs/bold/challenge-manager/types"
 	"github.com/OffchainLabs/bold/containers/events"
 	"github.com/OffchainLabs/bold/containers/option"
 	"github.com/OffchainLabs/bold/containers/threadsafe"
+	"github.com/OffchainLabs/bold/containers/workerpool"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	retry "github.com/OffchainLabs/bold/runtime"
 	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	utilTime "github.com/OffchainLabs/bold/time"
+	"github.com/OffchainLabs/bold/util/eventbus"
+	"github.com/OffchainLabs/bold/util/intents"
+	"github.com/OffchainLabs/bold/util/poison"
 	"github.com/OffchainLabs/bold/util/stopwaiter"
+	"github.com/OffchainLabs/bold/util/supervisor"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
 )
 
 type Opt = func(val *Manager)
@@ -69,11 +83,128 @@ type Manager struct {
 	mode                                types.Mode
 	maxDelaySeconds                     int
 	claimedAssertionsInChallenge        *threadsafe.LruSet[protocol.AssertionHash]
+	batchAvailabilityChecker            l2stateprovider.BatchAvailabilityChecker
+	batchAvailabilityConfig             l2stateprovider.BatchAvailabilityConfig
+	blockWindowReexecutor               l2stateprovider.BlockWindowReexecutor
+	onchainBatchAccumulators            l2stateprovider.BatchAccumulatorReader
+	watcherEventJournalPath             string
+	challengeScanStartBlocks            map[common.Address]uint64
+	maxTrackedRivalsPerChallenge        uint64
+	loadSheddingBacklog                 int
+	loadSheddingDeadlineWindowBlocks    uint64
+	watcherScanOverlapBlocks            uint64
+	watcherLogFetching                  *logFetchingConfig
+	cacheWarmup                         bool
+	cacheWarmupTimeout                  time.Duration
+	bisectionPrefetch                   bool
+	bisectionPrefetchOpts               []edgetracker.PrefetchOpt
+	bisectionPrefetcher                 *edgetracker.BisectionPrefetcher
+	trackerStartupRate                  int
+	trackerStartupOpts                  []edgetracker.StartupOpt
+	trackerStartupBoostWindowBlocks     uint64
+	trackerStartupThrottle              *edgetracker.StartupThrottle
+	edgeWithdrawal                      bool
+	watchtowerAlerts                    bool
+	watchtowerAlertWindowBlocks         uint64
+	watchtowerAlertHandlers             []watcher.AlertHandler
+	supervisor                          *supervisor.Supervisor
+	eventBus                            *eventbus.Bus
+	hooks                               *hooks.Registry
+	disabledConfirmationMethods         []types.ConfirmationMethod
+	confirmationMethods                 *types.ConfirmationMethods
+	intents                             *intents.Journal
+	poison                              *poison.Flag
+	poisonFlagPath                      string
+	poisonBundleDir                     string
+	webhookURLs                         []string
+	webhookSigner                       webhooks.Signer
+	webhooks                            *webhooks.Dispatcher
+	remotePolicyURL                     string
+	remotePolicySigner                  common.Address
+	remotePolicyPollInterval            time.Duration
+	remotePolicyVersionPath             string
+	agreedSiblingPolicy                 types.SiblingPolicy
+	latencyBudgetFraction               float64
+	latencyBudget                       *latency.Budget
+	deadlineMarginBlocks                uint64
+	deadlineMargin                      *latency.Margin
+	digestInterval                      time.Duration
+	proofSelfTest                       bool
+	proofSelfTestOpts                   []l2stateprovider.SelfTestOpt
+	commitmentWorkers                   int
+	proofWorkers                        int
+	workerPoolMaxLoad                   float64
+	commitmentPool                      *workerpool.Pool
+	proofPool                           *workerpool.Pool
+	finalizationOpts                    []assertions.Opt
+	artifacts                           *artifacts.Store
+	digest                              *digest.Scheduler
+	policyFetcher                       *policy.Fetcher
+	serviceFactories                    []ServiceFactory
+	services                            []Service
 	// API
-	apiAddr   string
 	apiDBPath string
-	api       *server.Server
 	apiDB     db.Database
+	auditLog  *db.AuditLogger
+}
+
+// WithConfig applies every option of a config, which should have been validated. Options given
+// after it override those of the config.
+func WithConfig(cfg *config.Config) Opt {
+	return func(val *Manager) {
+		val.name = cfg.Name
+		val.mode = cfg.Mode
+		val.address = cfg.Address
+		val.assertionPostingInterval = cfg.AssertionPostingInterval
+		val.assertionScanningInterval = cfg.AssertionScanningInterval
+		val.assertionConfirmingInterval = cfg.AssertionConfirmingInterval
+		val.averageTimeForBlockCreation = cfg.AvgBlockCreationTime
+		val.notifyOnNumberOfBlocks = cfg.TickEdgesOnNumberOfBlocks
+		val.maxDelaySeconds = cfg.MaxDelaySeconds
+		val.apiDBPath = cfg.APIDBPath
+		val.watcherEventJournalPath = cfg.WatcherEventJournalPath
+		val.challengeScanStartBlocks = make(map[common.Address]uint64, len(cfg.ChallengeScanStartBlocks))
+		for addr, startBlock := range cfg.ChallengeScanStartBlocks {
+			val.challengeScanStartBlocks[addr] = startBlock
+		}
+		val.maxTrackedRivalsPerChallenge = cfg.MaxTrackedRivalsPerChallenge
+		val.remotePolicyURL = cfg.RemotePolicyURL
+		val.remotePolicySigner = cfg.RemotePolicySigner
+		val.remotePolicyPollInterval = cfg.RemotePolicyPollInterval
+		val.remotePolicyVersionPath = cfg.RemotePolicyVersionPath
+		val.agreedSiblingPolicy = cfg.AgreedSiblingPolicy
+		val.latencyBudgetFraction = cfg.LatencyBudgetFraction
+		val.deadlineMarginBlocks = cfg.DeadlineMarginBlocks
+		val.digestInterval = cfg.DigestInterval
+		val.proofSelfTest = cfg.ProofSelfTest
+		val.edgeWithdrawal = cfg.EdgeWithdrawal
+		val.poisonFlagPath = cfg.PoisonFlagPath
+		val.poisonBundleDir = cfg.PoisonBundleDir
+		val.commitmentWorkers = cfg.CommitmentWorkers
+		val.proofWorkers = cfg.ProofWorkers
+		val.workerPoolMaxLoad = cfg.WorkerPoolMaxLoad
+		if cfg.WatchtowerAlerts {
+			val.watchtowerAlerts = true
+			val.watchtowerAlertWindowBlocks = cfg.WatchtowerAlertWindowBlocks
+		}
+		val.loadSheddingBacklog = cfg.LoadSheddingBacklog
+		val.loadSheddingDeadlineWindowBlocks = cfg.LoadSheddingDeadlineWindowBlocks
+		if cfg.TrackerStartupRate > 0 {
+			val.trackerStartupRate = cfg.TrackerStartupRate
+			val.trackerStartupOpts = []edgetracker.StartupOpt{edgetracker.WithStartupJitter(cfg.TrackerStartupJitter)}
+			val.trackerStartupBoostWindowBlocks = cfg.TrackerStartupBoostWindowBlocks
+		}
+		val.trackChallengeParentAssertionHashes = make([]protocol.AssertionHash, len(cfg.TrackChallengeParentAssertionHashes))
+		for i, hash := range cfg.TrackChallengeParentAssertionHashes {
+			val.trackChallengeParentAssertionHashes[i] = protocol.AssertionHash{Hash: hash}
+		}
+		val.disabledConfirmationMethods = append([]types.ConfirmationMethod{}, cfg.DisabledConfirmationMethods...)
+		for name, backoff := range cfg.RetryPolicies {
+			if err := retry.SetPolicy(name, backoff); err != nil {
+				log.Error("Could not set retry policy", "policy", name, "err", err)
+			}
+		}
+	}
 }
 
 // WithName is a human-readable identifier for this challenge manager for logging purposes.
@@ -129,20 +260,373 @@ func WithMode(m types.Mode) Opt {
 	}
 }
 
-// WithAPIEnabled specifies whether or not to enable the API and the address to listen on.
-func WithAPIEnabled(addr string, dbPath string) Opt {
+// WithAPIDatabase opens the database at the given path to store the data served by the API. The API
+// itself is served by adding the service of the api/server package.
+func WithAPIDatabase(dbPath string) Opt {
 	return func(val *Manager) {
-		val.apiAddr = addr
 		val.apiDBPath = dbPath
 	}
 }
 
+// WithDatabase stores the data served by the API in a database provided by the caller, rather than
+// opening one from a path.
+func WithDatabase(database db.Database) Opt {
+	return func(val *Manager) {
+		val.apiDB = database
+	}
+}
+
+// WithMaxDelaySeconds sets the maximum random delay before challenging an assertion, so that
+// validators do not all challenge at the same time.
+func WithMaxDelaySeconds(seconds int) Opt {
+	return func(val *Manager) {
+		val.maxDelaySeconds = seconds
+	}
+}
+
 func WithRPCClient(client *rpc.Client) Opt {
 	return func(val *Manager) {
 		val.client = client
 	}
 }
 
+// WithBatchAvailabilityChecker verifies that the batch data referenced by an assertion or
+// a block challenge level zero edge is retrievable before we commit to it onchain.
+func WithBatchAvailabilityChecker(
+	checker l2stateprovider.BatchAvailabilityChecker,
+	cfg l2stateprovider.BatchAvailabilityConfig,
+) Opt {
+	return func(val *Manager) {
+		val.batchAvailabilityChecker = checker
+		val.batchAvailabilityConfig = cfg
+	}
+}
+
+// WithBlockWindowReexecution re-executes only the blocks around the first suspected divergence from
+// an assertion we disagree with, found by comparing the batch accumulators of the local node against
+// those onchain, rather than the entire span of the assertion.
+func WithBlockWindowReexecution(
+	reexecutor l2stateprovider.BlockWindowReexecutor,
+	onchainAccumulators l2stateprovider.BatchAccumulatorReader,
+) Opt {
+	return func(val *Manager) {
+		val.blockWindowReexecutor = reexecutor
+		val.onchainBatchAccumulators = onchainAccumulators
+	}
+}
+
+// WithWatcherEventJournal persists challenge events scanned by the chain watcher
+// that are pending processing to a file, so they can be replayed after a restart.
+func WithWatcherEventJournal(path string) Opt {
+	return func(val *Manager) {
+		val.watcherEventJournalPath = path
+	}
+}
+
+// WithChallengeScanStartBlock sets the block from which to scan for events of the challenge manager
+// deployed at the given address, instead of discovering its deployment block from the chain.
+// It can be given once for each deployment.
+func WithChallengeScanStartBlock(challengeManager common.Address, startBlock uint64) Opt {
+	return func(val *Manager) {
+		if val.challengeScanStartBlocks == nil {
+			val.challengeScanStartBlocks = make(map[common.Address]uint64)
+		}
+		val.challengeScanStartBlocks[challengeManager] = startBlock
+	}
+}
+
+// WithMaxTrackedRivalsPerChallenge limits the number of non-royal edges tracked in each challenge,
+// deferring further ones unless they rival a royal edge. Defaults to 0, which is unlimited.
+func WithMaxTrackedRivalsPerChallenge(limit uint64) Opt {
+	return func(val *Manager) {
+		val.maxTrackedRivalsPerChallenge = limit
+	}
+}
+
+// WithWatchtowerAlerts makes a challenge manager in watchtower mode run the chain watcher to
+// observe challenges without sending transactions, alerting when an edge we disagree with is
+// confirmed, or is within alertWindowBlocks of being confirmable by time with no honest rival.
+// Has no effect in other modes.
+func WithWatchtowerAlerts(alertWindowBlocks uint64) Opt {
+	return func(val *Manager) {
+		val.watchtowerAlerts = true
+		val.watchtowerAlertWindowBlocks = alertWindowBlocks
+	}
+}
+
+// WithWatchtowerAlertHandler passes the alerts raised in watchtower mode to a handler, which must
+// not block.
+func WithWatchtowerAlertHandler(handler watcher.AlertHandler) Opt {
+	return func(val *Manager) {
+		val.watchtowerAlertHandlers = append(val.watchtowerAlertHandlers, handler)
+	}
+}
+
+// WithLoadShedding makes the chain watcher shed the tracking of edges which cannot threaten the
+// royal path while at least backlog scanned events are pending processing, except in challenges
+// whose royal root edge is within deadlineWindowBlocks of being confirmable. Shed edges are tracked
+// once the backlog is processed. Defaults to a backlog of 0, which disables load shedding.
+func WithLoadShedding(backlog int, deadlineWindowBlocks uint64) Opt {
+	return func(val *Manager) {
+		val.loadSheddingBacklog = backlog
+		val.loadSheddingDeadlineWindowBlocks = deadlineWindowBlocks
+	}
+}
+
+// WithAgreedSiblingPolicy sets which branch to defend when an assertion is posted with a state
+// equivalent to ours as a sibling of our own. Such siblings are never challenged. Defaults to
+// types.DefendExactSibling.
+func WithAgreedSiblingPolicy(policy types.SiblingPolicy) Opt {
+	return func(val *Manager) {
+		val.agreedSiblingPolicy = policy
+	}
+}
+
+// WithLatencyBudgetFraction sets the fraction of the challenge period our counter-moves to rivals may
+// take before operators are alerted. Defaults to 0.1, and 0 disables alerts.
+func WithLatencyBudgetFraction(fraction float64) Opt {
+	return func(val *Manager) {
+		val.latencyBudgetFraction = fraction
+	}
+}
+
+// WithDeadlineMargin sets the safety margin of blocks to keep before the deadlines of challenges,
+// which must be below the challenge period. Challenges within it are never load shed, and the
+// margin tightens to the inclusion latency of our recent counter-moves when it is insufficient for
+// them, under which challenges are opened without a random delay and edges tick on every block.
+// Defaults to 0, which disables the margin.
+func WithDeadlineMargin(blocks uint64) Opt {
+	return func(val *Manager) {
+		val.deadlineMarginBlocks = blocks
+	}
+}
+
+// WithDigest delivers a digest of the dispute activity recorded in the API database to operators at
+// the end of each interval, such as daily or weekly. Digests are logged, and sent to the
+// confirmation webhooks if any. Requires an API database. Defaults to 0, which disables digests.
+func WithDigest(interval time.Duration) Opt {
+	return func(val *Manager) {
+		val.digestInterval = interval
+	}
+}
+
+// WithProofSelfTest runs the proof pipeline at startup, failing to create the manager if it is
+// broken. By default, it verifies a history commitment, a prefix proof, and a one step proof of a
+// small synthetic machine locally. Options may run it over another machine, and check its one step
+// proofs with the verifier deployed onchain.
+func WithProofSelfTest(opts ...l2stateprovider.SelfTestOpt) Opt {
+	return func(val *Manager) {
+		val.proofSelfTest = true
+		val.proofSelfTestOpts = opts
+	}
+}
+
+// WithWorkerPools bounds the machine hash collections computing history commitments, and the one
+// step proof collections, of the state manager by pools of up to commitmentWorkers and proofWorkers
+// workers. Each pool starts with one worker and grows while collections back up and the CPU
+// utilization of the host is below maxLoad. Only takes effect with a state manager built on a
+// HistoryCommitmentProvider. Defaults to 0 workers, which leaves that kind of collection unbounded.
+func WithWorkerPools(commitmentWorkers, proofWorkers int, maxLoad float64) Opt {
+	return func(val *Manager) {
+		val.commitmentWorkers = commitmentWorkers
+		val.proofWorkers = proofWorkers
+		val.workerPoolMaxLoad = maxLoad
+	}
+}
+
+// WithWatcherScanOverlap makes the chain watcher rescan this many blocks before the last scanned
+// block on each poll, for nodes which may serve the logs of recent blocks late. Defaults to 0.
+func WithWatcherScanOverlap(blocks uint64) Opt {
+	return func(val *Manager) {
+		val.watcherScanOverlapBlocks = blocks
+	}
+}
+
+// WithCacheWarmup makes the chain watcher compute the history commitments the royal edges of open
+// challenges in the API database need next when it starts, before edges are tracked, so trackers
+// resuming after a restart do not act on cold caches. Warming up gives up after the timeout, which
+// is unbounded if zero. Requires an API database.
+func WithCacheWarmup(timeout time.Duration) Opt {
+	return func(val *Manager) {
+		val.cacheWarmup = true
+		val.cacheWarmupTimeout = timeout
+	}
+}
+
+// WithBisectionPrefetch makes trackers compute the bisections predicted to come up after they
+// bisect their edges ahead of time, as long as the RPC and CPU throttles of the prefetcher allow,
+// so that the next bisections need no computation when trackers decide to make them.
+func WithBisectionPrefetch(opts ...edgetracker.PrefetchOpt) Opt {
+	return func(val *Manager) {
+		val.bisectionPrefetch = true
+		val.bisectionPrefetchOpts = opts
+	}
+}
+
+// WithTrackerStartupThrottle staggers the first decision cycles of edge trackers, admitting up to
+// perSecond of them each second, those of the edges closest to their deadline first, so that a
+// restart with many open edges does not burst requests to the RPC provider. The trackers of edges
+// within boostWindowBlocks of their deadline, or within the deadline margin if wider, are admitted
+// at once. Disabled by default.
+func WithTrackerStartupThrottle(perSecond int, boostWindowBlocks uint64, opts ...edgetracker.StartupOpt) Opt {
+	return func(val *Manager) {
+		val.trackerStartupRate = perSecond
+		val.trackerStartupBoostWindowBlocks = boostWindowBlocks
+		val.trackerStartupOpts = opts
+	}
+}
+
+// WithEdgeWithdrawal makes edge trackers withdraw the layer zero edges staked by the validator to
+// recover their stakes once the assertion claimed by their challenge is confirmed, where the
+// challenge manager supports withdrawing edges. No deployed version does yet, so this has no effect
+// until the challenge manager is upgraded to one which does.
+func WithEdgeWithdrawal() Opt {
+	return func(val *Manager) {
+		val.edgeWithdrawal = true
+	}
+}
+
+type logFetchingConfig struct {
+	shardBlocks       uint64
+	maxTopicsPerShard int
+	concurrency       int
+}
+
+// WithParallelLogFetching makes the chain watcher scan for edge additions with up to concurrency
+// requests in flight, each over at most shardBlocks blocks and maxTopicsPerShard values of an
+// indexed topic. Requests are subject to the rate limit of the chain backend, if any.
+func WithParallelLogFetching(shardBlocks uint64, maxTopicsPerShard, concurrency int) Opt {
+	return func(val *Manager) {
+		val.watcherLogFetching = &logFetchingConfig{
+			shardBlocks:       shardBlocks,
+			maxTopicsPerShard: maxTopicsPerShard,
+			concurrency:       concurrency,
+		}
+	}
+}
+
+// WithRestartPolicy sets how edge trackers and the chain watcher are restarted after panicking: up
+// to maxRestarts times per run, waiting initialBackoff before the first restart and doubling the
+// wait on each further restart up to maxBackoff.
+func WithRestartPolicy(maxRestarts int, initialBackoff, maxBackoff time.Duration) Opt {
+	return func(val *Manager) {
+		val.supervisor = supervisor.New(
+			supervisor.WithMaxRestarts(maxRestarts),
+			supervisor.WithBackoff(initialBackoff, maxBackoff),
+		)
+	}
+}
+
+// WithEventBus sets the event bus the manager's modules publish their events on, such as one the
+// assertion chain also publishes its transactions on. By default, the manager creates its own.
+func WithEventBus(bus *eventbus.Bus) Opt {
+	return func(val *Manager) {
+		val.eventBus = bus
+	}
+}
+
+// WithAssertionFinalizationHandler notifies a handler of each assertion confirmed beyond
+// challenge after startup, with its execution state and how it was resolved.
+func WithAssertionFinalizationHandler(handler assertions.FinalizationHandler) Opt {
+	return func(val *Manager) {
+		val.finalizationOpts = append(val.finalizationOpts, assertions.WithFinalizationHandler(handler))
+	}
+}
+
+// WithArtifacts keeps the history commitments and proofs of our moves in a working directory per
+// challenge, with a log of the moves. Directories are marked resolved once an assertion of their
+// challenge is confirmed, then archived and pruned as the store is configured.
+func WithArtifacts(store *artifacts.Store) Opt {
+	return func(val *Manager) {
+		val.artifacts = store
+		val.finalizationOpts = append(val.finalizationOpts, assertions.WithFinalizationHandler(&artifactsFinalizationHandler{store}))
+	}
+}
+
+// WithAssertionFinalizationFromBlock notifies assertion finalization handlers of the assertions
+// confirmed from a block on, rather than after startup, so they can resume after a restart.
+func WithAssertionFinalizationFromBlock(block uint64) Opt {
+	return func(val *Manager) {
+		val.finalizationOpts = append(val.finalizationOpts, assertions.WithFinalizationFromBlock(block))
+	}
+}
+
+// WithHooks consults the hooks of a registry before creating and confirming edges, and before
+// posting rivals to assertions we disagree with, letting them veto, delay or annotate each action.
+func WithHooks(registry *hooks.Registry) Opt {
+	return func(val *Manager) {
+		val.hooks = registry
+	}
+}
+
+// WithDisabledConfirmationMethods starts the challenge manager without using the given confirmation
+// methods, which may be enabled again at runtime. Only confirming by claim and by children may be
+// disabled.
+func WithDisabledConfirmationMethods(methods ...types.ConfirmationMethod) Opt {
+	return func(val *Manager) {
+		val.disabledConfirmationMethods = methods
+	}
+}
+
+// WithIntentJournal checks the assertions and level zero edges observed from our address against
+// the journal of those we intended to post, pausing posting on detecting any we did not, as a
+// second instance posting with our key would. The assertion chain should record its posts in the
+// same journal with solimpl.WithIntentJournal.
+func WithIntentJournal(journal *intents.Journal) Opt {
+	return func(val *Manager) {
+		val.intents = journal
+	}
+}
+
+// WithPoisonFlag poisons the flag when an edge we agree with loses to a rival confirmed by one step
+// proof, which shows our state provider to be faulty. The diagnostic bundle then includes an archive
+// of the artifacts of the challenge, if kept. The assertion chain should freeze posting behind the
+// same flag with solimpl.WithPoisonFlag.
+func WithPoisonFlag(flag *poison.Flag) Opt {
+	return func(val *Manager) {
+		val.poison = flag
+	}
+}
+
+// Opens the poisoned flag at the configured path, unless one was given with WithPoisonFlag. Posting
+// through the assertion chain is frozen behind the same flag, if it does not have one already.
+func (m *Manager) openPoisonFlag() error {
+	if m.poison != nil || m.poisonFlagPath == "" {
+		return nil
+	}
+	flag, err := poison.Open(m.poisonFlagPath, poison.WithBundleDir(m.poisonBundleDir))
+	if err != nil {
+		return errors.Wrap(err, "could not open poisoned flag")
+	}
+	m.poison = flag
+	if chain, ok := m.chain.(*solimpl.AssertionChain); ok && chain.PoisonFlag() == nil {
+		solimpl.WithPoisonFlag(flag)(chain)
+	}
+	return nil
+}
+
+// WithConfirmationWebhooks delivers a webhook to each url when an assertion or edge, ours or a
+// rival's, is confirmed, signed by the operator's signer.
+func WithConfirmationWebhooks(urls []string, signer webhooks.Signer) Opt {
+	return func(val *Manager) {
+		val.webhookURLs = urls
+		val.webhookSigner = signer
+	}
+}
+
+// WithRemotePolicy fetches policy documents from a url every poll interval, and applies those
+// signed by the operator's address over the policies the challenge manager started with. The last
+// applied document is stored at versionPath, so that it is applied again and older documents are
+// not after a restart.
+func WithRemotePolicy(url string, signer common.Address, pollInterval time.Duration, versionPath string) Opt {
+	return func(val *Manager) {
+		val.remotePolicyURL = url
+		val.remotePolicySigner = signer
+		val.remotePolicyPollInterval = pollInterval
+		val.remotePolicyVersionPath = versionPath
+	}
+}
+
 func WithTrackChallengeParentAssertionHashes(trackChallengeParentAssertionHashes []string) Opt {
 	return func(val *Manager) {
 		val.trackChallengeParentAssertionHashes = make([]protocol.AssertionHash, len(trackChallengeParentAssertionHashes))
@@ -161,6 +645,7 @@ func New(
 	opts ...Opt,
 ) (*Manager, error) {
 
+	defaults := config.Default()
 	m := &Manager{
 		backend:                      chain.Backend(),
 		chain:                        chain,
@@ -171,17 +656,60 @@ func New(
 		chainWatcherInterval:         time.Millisecond * 500,
 		trackedEdgeIds:               threadsafe.NewMap[protocol.EdgeId, *edgetracker.Tracker](threadsafe.MapWithMetric[protocol.EdgeId, *edgetracker.Tracker]("trackedEdgeIds")),
 		batchIndexForAssertionCache:  threadsafe.NewLruMap[protocol.AssertionHash, edgetracker.AssociatedAssertionMetadata](1000, threadsafe.LruMapWithMetric[protocol.AssertionHash, edgetracker.AssociatedAssertionMetadata]("batchIndexForAssertionCache")),
-		notifyOnNumberOfBlocks:       1,
+		notifyOnNumberOfBlocks:       defaults.TickEdgesOnNumberOfBlocks,
+		mode:                         defaults.Mode,
 		newBlockNotifier:             events.NewProducer[*gethtypes.Header](),
-		assertionPostingInterval:     time.Hour,
-		assertionScanningInterval:    time.Minute,
-		assertionConfirmingInterval:  time.Second * 10,
-		averageTimeForBlockCreation:  time.Second * 12,
+		assertionPostingInterval:     defaults.AssertionPostingInterval,
+		assertionScanningInterval:    defaults.AssertionScanningInterval,
+		assertionConfirmingInterval:  defaults.AssertionConfirmingInterval,
+		averageTimeForBlockCreation:  defaults.AvgBlockCreationTime,
+		latencyBudgetFraction:        defaults.LatencyBudgetFraction,
 		claimedAssertionsInChallenge: threadsafe.NewLruSet[protocol.AssertionHash](1000, threadsafe.LruSetWithMetric[protocol.AssertionHash]("claimedAssertionsInChallenge")),
+		supervisor:                   supervisor.New(),
+		eventBus:                     eventbus.New(),
 	}
 	for _, o := range opts {
 		o(m)
 	}
+	if m.proofSelfTest {
+		start := time.Now()
+		if err := l2stateprovider.SelfTest(ctx, m.proofSelfTestOpts...); err != nil {
+			return nil, errors.Wrap(err, "proof pipeline self test failed")
+		}
+		log.Info("Proof pipeline self test passed", "elapsed", time.Since(start))
+	}
+	if err := m.setupWorkerPools(); err != nil {
+		return nil, err
+	}
+	if err := m.openPoisonFlag(); err != nil {
+		return nil, err
+	}
+	confirmationMethods, err := types.NewConfirmationMethods(m.disabledConfirmationMethods...)
+	if err != nil {
+		return nil, err
+	}
+	m.confirmationMethods = confirmationMethods
+	if m.remotePolicyURL != "" {
+		fetcherOpts := make([]policy.Opt, 0)
+		if m.remotePolicyPollInterval != 0 {
+			fetcherOpts = append(fetcherOpts, policy.WithPollInterval(m.remotePolicyPollInterval))
+		}
+		engineOpts := make([]policy.EngineOpt, 0)
+		if m.remotePolicyVersionPath != "" {
+			engineOpts = append(engineOpts, policy.WithVersionPath(m.remotePolicyVersionPath))
+		}
+		engine, err2 := policy.NewEngine(confirmationMethods, engineOpts...)
+		if err2 != nil {
+			return nil, err2
+		}
+		m.policyFetcher, err = policy.NewFetcher(m.remotePolicyURL, m.remotePolicySigner, engine, fetcherOpts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.bisectionPrefetch {
+		m.bisectionPrefetcher = edgetracker.NewBisectionPrefetcher(m.stateManager, m.bisectionPrefetchOpts...)
+	}
 	chalManager, err := m.chain.SpecChallengeManager(ctx)
 	if err != nil {
 		return nil, err
@@ -204,34 +732,130 @@ func New(
 	if err != nil {
 		return nil, err
 	}
+	// The challenge period is read by the edge trackers once they first record a counter-move.
+	m.latencyBudget, err = latency.New(0, m.latencyBudgetFraction)
+	if err != nil {
+		return nil, err
+	}
+	if m.deadlineMarginBlocks > 0 {
+		challengePeriodBlocks, err2 := chalManager.ChallengePeriodBlocks(ctx)
+		if err2 != nil {
+			return nil, errors.Wrap(err2, "could not check the deadline margin against the challenge period")
+		}
+		m.deadlineMargin = latency.NewMargin(m.deadlineMarginBlocks, m.latencyBudget)
+		if err2 := m.deadlineMargin.Validate(challengePeriodBlocks); err2 != nil {
+			return nil, err2
+		}
+	}
+	if m.trackerStartupRate > 0 {
+		boostWindow := func() uint64 {
+			if m.deadlineMargin == nil {
+				return m.trackerStartupBoostWindowBlocks
+			}
+			return max(m.trackerStartupBoostWindowBlocks, m.deadlineMargin.Blocks())
+		}
+		startupOpts := append([]edgetracker.StartupOpt{edgetracker.WithStartupBoostWindow(boostWindow)}, m.trackerStartupOpts...)
+		m.trackerStartupThrottle = edgetracker.NewStartupThrottle(m.trackerStartupRate, startupOpts...)
+	}
 	m.rollup = rollup
 	m.rollupFilterer = rollupFilterer
 	m.chalManagerAddr = chalManagerAddr
 	m.chalManager = chalManagerFilterer
 
-	if m.apiDBPath != "" {
+	if m.apiDBPath != "" && m.apiDB == nil {
 		apiDB, err2 := db.NewDatabase(m.apiDBPath)
 		if err2 != nil {
 			return nil, err2
 		}
 		m.apiDB = apiDB
 	}
-
-	watcher, err := watcher.New(m.chain, m, m.stateManager, m.backend, m.chainWatcherInterval, numBigStepLevels, m.name, m.apiDB, m.assertionConfirmingInterval, m.averageTimeForBlockCreation, m.trackChallengeParentAssertionHashes)
-	if err != nil {
-		return nil, err
+	if m.apiDB != nil {
+		m.auditLog = db.NewAuditLogger(m.apiDB, m.chain.StakerAddress().Hex())
 	}
-	m.watcher = watcher
 
-	if m.apiAddr != "" {
-		bknd := apibackend.NewBackend(m.apiDB, m.chain, m.watcher, m)
-		srv, err2 := server.New(m.apiAddr, bknd)
+	if len(m.webhookURLs) > 0 {
+		dispatcher, err2 := webhooks.New(m.webhookURLs, m.webhookSigner, m.chain.Backend(), webhooks.WithEventBus(m.eventBus))
+		if err2 != nil {
+			return nil, err2
+		}
+		m.webhooks = dispatcher
+	}
+	if m.digestInterval > 0 {
+		channels := []digest.Channel{digest.LogChannel{}}
+		if m.webhooks != nil {
+			channels = append(channels, digest.NewWebhookChannel(m.webhooks))
+		}
+		scheduler, err2 := digest.NewScheduler(
+			m.apiDB,
+			m.chain.Backend(),
+			m.chain.StakerAddress(),
+			m.digestInterval,
+			channels,
+			digest.WithAvgBlockCreationTime(m.averageTimeForBlockCreation),
+		)
 		if err2 != nil {
 			return nil, err2
 		}
-		m.api = srv
+		m.digest = scheduler
+	}
+
+	if m.poison != nil && m.artifacts != nil {
+		m.poison.AddBundleSource("challenge-artifacts.tar.gz", func(_ context.Context, incident *poison.Incident, w io.Writer) error {
+			return m.artifacts.Archive(incident.ChallengedAssertionHash, w)
+		})
+	}
+
+	watcherOpts := []watcher.Opt{
+		watcher.WithEventJournal(m.watcherEventJournalPath),
+		watcher.WithMaxTrackedRivalsPerChallenge(m.maxTrackedRivalsPerChallenge),
+		watcher.WithLoadShedding(m.loadSheddingBacklog, m.loadSheddingDeadlineWindowBlocks),
+		watcher.WithScanOverlap(m.watcherScanOverlapBlocks),
+		watcher.WithEventBus(m.eventBus),
+		watcher.WithIntentJournal(m.intents),
+		watcher.WithPoisonFlag(m.poison),
+	}
+	if m.deadlineMargin != nil {
+		watcherOpts = append(watcherOpts, watcher.WithDeadlineMargin(m.deadlineMargin.Blocks))
+	}
+	if cfg := m.watcherLogFetching; cfg != nil {
+		watcherOpts = append(watcherOpts, watcher.WithParallelLogFetching(cfg.shardBlocks, cfg.maxTopicsPerShard, cfg.concurrency))
+	}
+	if m.cacheWarmup {
+		watcherOpts = append(watcherOpts, watcher.WithCacheWarmup(m.stateManager, m.cacheWarmupTimeout))
+	}
+	for addr, startBlock := range m.challengeScanStartBlocks {
+		watcherOpts = append(watcherOpts, watcher.WithStartBlockOverride(addr, startBlock))
+	}
+	if m.observesAsWatchtower() {
+		watcherOpts = append(watcherOpts, watcher.WithWatchtower(m.watchtowerAlertWindowBlocks, m.watchtowerAlertHandlers...))
+	}
+	watcher, err := watcher.New(
+		m.chain,
+		m,
+		m.stateManager,
+		m.backend,
+		m.chainWatcherInterval,
+		numBigStepLevels,
+		m.name,
+		m.apiDB,
+		m.assertionConfirmingInterval,
+		m.averageTimeForBlockCreation,
+		m.trackChallengeParentAssertionHashes,
+		watcherOpts...,
+	)
+	if err != nil {
+		return nil, err
 	}
+	m.watcher = watcher
 
+	assertionOpts := []assertions.Opt{
+		assertions.WithBatchAvailabilityChecker(m.batchAvailabilityChecker, m.batchAvailabilityConfig),
+		assertions.WithBlockWindowReexecution(m.blockWindowReexecutor, m.onchainBatchAccumulators),
+		assertions.WithEventBus(m.eventBus),
+		assertions.WithHooks(m.hooks),
+		assertions.WithIntentJournal(m.intents),
+		assertions.WithSiblingPolicy(m.agreedSiblingPolicy),
+	}
 	assertionManager, err := assertions.NewManager(
 		m.chain,
 		m.stateManager,
@@ -246,11 +870,20 @@ func New(
 		m.assertionPostingInterval,
 		m.averageTimeForBlockCreation,
 		m.apiDB,
+		append(assertionOpts, m.finalizationOpts...)...,
 	)
 	if err != nil {
 		return nil, err
 	}
 	m.assertionManager = assertionManager
+
+	for _, factory := range m.serviceFactories {
+		service, err2 := factory(m)
+		if err2 != nil {
+			return nil, err2
+		}
+		m.services = append(m.services, service)
+	}
 	return m, nil
 }
 
@@ -261,6 +894,33 @@ func (m *Manager) GetEdgeTracker(edgeId protocol.EdgeId) option.Option[*edgetrac
 	return option.None[*edgetracker.Tracker]()
 }
 
+// WithdrawEdge withdraws a layer zero edge staked by the validator to recover its stake, if the
+// edge passes the withdrawal policy of edge trackers. The edge need not be tracked, as trackers
+// despawn once the assertion claimed by their challenge is confirmed.
+func (m *Manager) WithdrawEdge(ctx context.Context, edgeId protocol.EdgeId) (*gethtypes.Transaction, error) {
+	if trk := m.GetEdgeTracker(edgeId); trk.IsSome() {
+		return trk.Unwrap().WithdrawEdge(ctx)
+	}
+	chalManager, err := m.chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edge, err := chalManager.GetEdge(ctx, edgeId)
+	if err != nil {
+		return nil, err
+	}
+	if edge.IsNone() {
+		return nil, errors.Wrapf(solimpl.ErrNotFound, "no edge found with id %#x", edgeId.Hash)
+	}
+	// The edge was requested rather than seen by the watcher, so failing reads are returned rather
+	// than retried until they succeed.
+	trk, err := m.newTrackerForEdge(ctx, edge.Unwrap(), false /* retrying */)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not look up edge %#x to withdraw", edgeId.Hash)
+	}
+	return trk.WithdrawEdge(ctx)
+}
+
 // IsTrackingEdge returns true if we are currently tracking a specified edge id as an edge tracker goroutine.
 func (m *Manager) IsTrackingEdge(edgeId protocol.EdgeId) bool {
 	return m.trackedEdgeIds.Has(edgeId)
@@ -270,6 +930,51 @@ func (m *Manager) Database() db.Database {
 	return m.apiDB
 }
 
+// AuditLog records the operations performed by the challenge manager in the API database, if any.
+func (m *Manager) AuditLog() *db.AuditLogger {
+	return m.auditLog
+}
+
+// Hooks returns the registry of hooks consulted before actions, which is nil if none are configured.
+func (m *Manager) Hooks() *hooks.Registry {
+	return m.hooks
+}
+
+// ConfirmationMethods returns which confirmation methods the edge trackers may use, which operators
+// may change at runtime.
+func (m *Manager) ConfirmationMethods() *types.ConfirmationMethods {
+	return m.confirmationMethods
+}
+
+// LatencyBudget tracks the latency of our counter-moves to rivals against a budget relative to the
+// challenge period.
+func (m *Manager) LatencyBudget() *latency.Budget {
+	return m.latencyBudget
+}
+
+// DeadlineMargin is the safety margin of blocks kept before the deadlines of challenges, which is
+// nil if no margin is configured.
+func (m *Manager) DeadlineMargin() *latency.Margin {
+	return m.deadlineMargin
+}
+
+// Whether the deadline margin is tightened, as it is insufficient for recent inclusion latencies.
+func (m *Manager) deadlineMarginTightened() bool {
+	return m.deadlineMargin != nil && m.deadlineMargin.Tightened()
+}
+
+// PoisonFlag returns the flag poisoned when an edge we agree with loses by one step proof, which is
+// nil if not configured.
+func (m *Manager) PoisonFlag() *poison.Flag {
+	return m.poison
+}
+
+// IntentJournal returns the journal of the assertions and edges we intended to post, which is nil if
+// split brain detection is not configured.
+func (m *Manager) IntentJournal() *intents.Journal {
+	return m.intents
+}
+
 func (m *Manager) ChallengeManagerAddress() common.Address {
 	return m.chalManagerAddr
 }
@@ -287,6 +992,11 @@ func (m *Manager) RemovedTrackedEdge(edgeId protocol.EdgeId) {
 	m.trackedEdgeIds.Delete(edgeId)
 }
 
+// Checks if the manager is a watchtower observing challenges to raise alerts.
+func (m *Manager) observesAsWatchtower() bool {
+	return m.mode == types.WatchTowerMode && m.watchtowerAlerts
+}
+
 // Mode returns the mode of the challenge manager.
 func (m *Manager) Mode() types.Mode {
 	return m.mode
@@ -298,7 +1008,11 @@ func (m *Manager) IsClaimedByChallenge(assertionHash protocol.AssertionHash) boo
 }
 
 // MaxDelaySeconds returns the maximum number of seconds that the challenge manager will wait open a challenge.
+// There is no delay while the deadline margin is tightened.
 func (m *Manager) MaxDelaySeconds() int {
+	if m.deadlineMarginTightened() {
+		return 0
+	}
 	return m.maxDelaySeconds
 }
 
@@ -311,14 +1025,19 @@ func (m *Manager) TrackEdge(ctx context.Context, edge protocol.SpecEdge) error {
 	if err != nil {
 		return err
 	}
-	m.LaunchThread(trk.Spawn)
+	m.LaunchThread(m.supervisor.Supervise("edge_tracker", trk.Spawn))
 	return nil
 }
 
 // Gets an edge tracker for an edge by retrieving its associated assertion creation info.
 func (m *Manager) getTrackerForEdge(ctx context.Context, edge protocol.SpecEdge) (*edgetracker.Tracker, error) {
-	// Retry until you get the previous assertion Hash.
-	assertionHash, err := retry.UntilSucceeds(ctx, func() (protocol.AssertionHash, error) {
+	return m.newTrackerForEdge(ctx, edge, true /* retrying */)
+}
+
+// Reads what a tracker needs to know about the challenge of an edge, and creates it. Reads are
+// retried until they succeed if retrying, or else tried once.
+func (m *Manager) newTrackerForEdge(ctx context.Context, edge protocol.SpecEdge, retrying bool) (*edgetracker.Tracker, error) {
+	assertionHash, err := readForTracker(ctx, retrying, func() (protocol.AssertionHash, error) {
 		return edge.AssertionHash(ctx)
 	})
 	if err != nil {
@@ -342,13 +1061,13 @@ func (m *Manager) getTrackerForEdge(ctx context.Context, edge protocol.SpecEdge)
 	cachedHeightAndInboxMsgCount, ok := m.batchIndexForAssertionCache.TryGet(protocol.AssertionHash{Hash: common.Hash(claimedAssertionId)})
 	var edgeTrackerAssertionInfo edgetracker.AssociatedAssertionMetadata
 	if !ok {
-		assertionCreationInfo, creationErr := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
+		assertionCreationInfo, creationErr := readForTracker(ctx, retrying, func() (*protocol.AssertionCreatedInfo, error) {
 			return m.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: common.Hash(claimedAssertionId)})
 		})
 		if creationErr != nil {
 			return nil, creationErr
 		}
-		prevCreationInfo, prevCreationErr := retry.UntilSucceeds(ctx, func() (*protocol.AssertionCreatedInfo, error) {
+		prevCreationInfo, prevCreationErr := readForTracker(ctx, retrying, func() (*protocol.AssertionCreatedInfo, error) {
 			return m.chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: assertionCreationInfo.ParentAssertionHash})
 		})
 		if prevCreationErr != nil {
@@ -366,7 +1085,16 @@ func (m *Manager) getTrackerForEdge(ctx context.Context, edge protocol.SpecEdge)
 	} else {
 		edgeTrackerAssertionInfo = cachedHeightAndInboxMsgCount
 	}
-	return retry.UntilSucceeds(ctx, func() (*edgetracker.Tracker, error) {
+	trackerOpts := []edgetracker.Opt{
+		edgetracker.WithTimeReference(m.timeRef),
+		edgetracker.WithValidatorName(m.name),
+		edgetracker.WithBisectionPrefetcher(m.bisectionPrefetcher),
+		edgetracker.WithStartupThrottle(m.trackerStartupThrottle),
+	}
+	if m.edgeWithdrawal {
+		trackerOpts = append(trackerOpts, edgetracker.WithEdgeWithdrawal())
+	}
+	newTracker := func() (*edgetracker.Tracker, error) {
 		return edgetracker.New(
 			ctx,
 			edge,
@@ -375,11 +1103,28 @@ func (m *Manager) getTrackerForEdge(ctx context.Context, edge protocol.SpecEdge)
 			m.watcher,
 			m,
 			&edgeTrackerAssertionInfo,
-			edgetracker.WithTimeReference(m.timeRef),
-			edgetracker.WithValidatorName(m.name),
+			trackerOpts...,
 		)
-	})
+	}
+	if !retrying {
+		return newTracker()
+	}
+	return retry.UntilSucceeds(ctx, newTracker)
+}
+
+// Retries a read for a tracker until it succeeds if retrying, or else tries it once.
+func readForTracker[T any](ctx context.Context, retrying bool, fn func() (T, error)) (T, error) {
+	if !retrying {
+		return fn()
+	}
+	return retry.UntilSucceeds(ctx, fn, retry.WithPolicy(retry.RPCRead))
 }
+
+// Chain returns the protocol the challenge manager takes part in.
+func (m *Manager) Chain() protocol.Protocol {
+	return m.chain
+}
+
 func (m *Manager) Watcher() *watcher.Watcher {
 	return m.watcher
 }
@@ -388,37 +1133,124 @@ func (m *Manager) ChallengeManager() *challengeV2gen.EdgeChallengeManagerFiltere
 	return m.chalManager
 }
 
+// Supervisor returns the supervisor of the manager's edge trackers and chain watcher, which keeps
+// the panics recovered from them.
+func (m *Manager) Supervisor() *supervisor.Supervisor {
+	return m.supervisor
+}
+
+// EventBus returns the bus on which the manager's modules publish edge and assertion events, for
+// plugins to subscribe to.
+func (m *Manager) EventBus() *eventbus.Bus {
+	return m.eventBus
+}
+
 func (m *Manager) NewBlockSubscriber() *events.Producer[*gethtypes.Header] {
 	return m.newBlockNotifier
 }
 
+// The state managers whose machine hash and one step proof collections can be bounded by worker
+// pools, such as those built on a HistoryCommitmentProvider.
+type workerPoolUser interface {
+	UpdateWorkerPools(commitmentPool, proofPool *workerpool.Pool)
+}
+
+func (m *Manager) setupWorkerPools() error {
+	if m.commitmentWorkers <= 0 && m.proofWorkers <= 0 {
+		return nil
+	}
+	user, ok := m.stateManager.(workerPoolUser)
+	if !ok {
+		log.Warn("State manager does not support worker pools, leaving collections unbounded", "stateManager", fmt.Sprintf("%T", m.stateManager))
+		return nil
+	}
+	newPool := func(name string, maxWorkers int) (*workerpool.Pool, error) {
+		if maxWorkers <= 0 {
+			return nil, nil
+		}
+		opts := []workerpool.Opt{workerpool.WithMetrics(name)}
+		if m.workerPoolMaxLoad > 0 {
+			opts = append(opts, workerpool.WithMaxLoad(m.workerPoolMaxLoad))
+		}
+		pool, err := workerpool.New(1, maxWorkers, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create %s worker pool", name)
+		}
+		return pool, nil
+	}
+	var err error
+	if m.commitmentPool, err = newPool("commitment", m.commitmentWorkers); err != nil {
+		return err
+	}
+	if m.proofPool, err = newPool("proof", m.proofWorkers); err != nil {
+		return err
+	}
+	user.UpdateWorkerPools(m.commitmentPool, m.proofPool)
+	return nil
+}
+
 func (m *Manager) Start(ctx context.Context) {
 	m.StopWaiter.Start(ctx, m)
 	log.Info("Started challenge manager",
 		"validatorAddress", m.address.Hex(),
 	)
+	if incident := m.poison.Incident(); incident != nil {
+		log.Error(
+			"Challenge manager will not post until an operator clears the poisoned flag, as our edge lost by one step proof",
+			"lostEdgeId", incident.LostEdgeId,
+			"challengedAssertionHash", incident.ChallengedAssertionHash,
+			"bundle", incident.Bundle,
+		)
+	}
+
+	// Warn if the deployed challenge manager no longer matches our bindings.
+	m.LaunchThread(func(ctx context.Context) {
+		if err := solimpl.CheckChallengeManagerAbiDrift(ctx, m.backend, m.chalManagerAddr); err != nil {
+			log.Error("Could not check challenge manager for ABI drift", "err", err)
+		}
+	})
+
+	if m.webhooks != nil {
+		m.webhooks.Start(ctx)
+	}
+	if m.digest != nil {
+		m.digest.Start(ctx)
+	}
+	if m.policyFetcher != nil {
+		m.policyFetcher.Start(ctx)
+	}
+	if m.commitmentPool != nil {
+		m.commitmentPool.Start(ctx)
+	}
+	if m.proofPool != nil {
+		m.proofPool.Start(ctx)
+	}
 
 	// Start the assertion manager.
 	m.LaunchThread(m.assertionManager.Start)
 
-	// Watcher tower and resolve modes don't monitor challenges.
+	// Watchtowers with alerts observe challenges without taking part in them.
+	if m.observesAsWatchtower() {
+		m.LaunchThread(m.supervisor.Supervise("chain_watcher", m.watcher.Start))
+		return
+	}
+
+	// Watcher tower and resolve modes don't monitor challenges otherwise.
 	if m.mode == types.WatchTowerMode || m.mode == types.ResolveMode {
 		return
 	}
 
 	// Start watching for parent chain block events in the background.
-	m.LaunchThread(m.listenForBlockEvents)
+	m.LaunchThread(m.supervisor.Supervise("block_listener", m.listenForBlockEvents))
 
 	// Start watching for ongoing chain events in the background.
-	m.LaunchThread(m.watcher.Start)
+	m.LaunchThread(m.supervisor.Supervise("chain_watcher", m.watcher.Start))
 
-	if m.api != nil {
+	for _, service := range m.services {
+		service := service
 		m.LaunchThread(func(ctx context.Context) {
-			if err := m.api.Start(ctx); err != nil {
-				log.Error("Could not start API server",
-					"address", m.apiAddr,
-					"err", err,
-				)
+			if err := service.Start(ctx); err != nil {
+				log.Error("Could not start service", "service", fmt.Sprintf("%T", service), "err", err)
 			}
 		})
 	}
@@ -428,7 +1260,24 @@ func (m *Manager) StopAndWait() {
 	m.StopWaiter.StopAndWait()
 	m.assertionManager.StopAndWait()
 	m.watcher.StopAndWait()
-	m.api.StopAndWait()
+	for _, service := range m.services {
+		service.StopAndWait()
+	}
+	if m.webhooks != nil {
+		m.webhooks.StopAndWait()
+	}
+	if m.digest != nil {
+		m.digest.StopAndWait()
+	}
+	if m.policyFetcher != nil {
+		m.policyFetcher.StopAndWait()
+	}
+	if m.commitmentPool != nil {
+		m.commitmentPool.StopAndWait()
+	}
+	if m.proofPool != nil {
+		m.proofPool.StopAndWait()
+	}
 }
 
 func (m *Manager) listenForBlockEvents(ctx context.Context) {
@@ -440,26 +1289,67 @@ func (m *Manager) listenForBlockEvents(ctx context.Context) {
 
 	// Then, once the watcher has reached the latest head, we
 	// fire off a block notifications events normally.
+	m.broadcastNewHeads(ctx)
+}
+
+// Broadcasts new heads to the block notifier until the context is done. A failed subscription is
+// resubscribed with the backoff of the rpc-read retry policy, as the supervisor only restarts
+// components which panic.
+func (m *Manager) broadcastNewHeads(ctx context.Context) {
 	ch := make(chan *gethtypes.Header, 100)
-	sub, err := m.chain.Backend().SubscribeNewHead(ctx, ch)
-	if err != nil {
-		panic(err)
-	}
-	defer sub.Unsubscribe()
 	numBlocksReceived := uint64(0)
+	failures := 0
+	for {
+		sub, err := m.chain.Backend().SubscribeNewHead(ctx, ch)
+		if err == nil {
+			err = m.broadcastSubscribedHeads(ctx, sub, ch, &numBlocksReceived, &failures)
+			sub.Unsubscribe()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		failures++
+		backoff, _ := retry.PolicyBackoff(retry.RPCRead)
+		delay := backoff.Delay(failures)
+		log.Error("Subscription to new heads failed, resubscribing", "err", err, "failures", failures, "retryIn", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Broadcasts the heads of a subscription until it fails or the context is done, resetting the count
+// of failures once the subscription delivers a head.
+func (m *Manager) broadcastSubscribedHeads(
+	ctx context.Context,
+	sub ethereum.Subscription,
+	ch <-chan *gethtypes.Header,
+	numBlocksReceived *uint64,
+	failures *int,
+) error {
 	for {
 		select {
 		case header := <-ch:
-			numBlocksReceived += 1
+			*failures = 0
+			*numBlocksReceived += 1
 			// Only broadcast every N blocks received. This is important for Orbit chains
 			// that have parent chains with very fast block times, such as Arbitrum One, as broadcasting
 			// every 250ms would otherwise be too frequent.
-			if numBlocksReceived%m.notifyOnNumberOfBlocks == 0 {
+			// Edges tick on every block while the deadline margin is tightened.
+			if *numBlocksReceived%m.notifyOnNumberOfBlocks == 0 || m.deadlineMarginTightened() {
 				m.newBlockNotifier.Broadcast(ctx, header)
 			}
-		case <-sub.Err():
+		case err := <-sub.Err():
+			// A websocket backend dialed with solimpl.DialChainBackend resubscribes by itself,
+			// so the subscription only fails here on other backends.
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
 		case <-ctx.Done():
-			return
+			return nil
 		}
 	}
 }
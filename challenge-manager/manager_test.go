@@ -5,22 +5,32 @@ package challengemanager
 
 import (
 	"context"
+	"errors"
+	"math/big"
+	"sync"
 	"testing"
 	"time"
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
 	watcher "github.com/OffchainLabs/bold/challenge-manager/chain-watcher"
+	"github.com/OffchainLabs/bold/challenge-manager/config"
 	edgetracker "github.com/OffchainLabs/bold/challenge-manager/edge-tracker"
 	"github.com/OffchainLabs/bold/challenge-manager/types"
+	"github.com/OffchainLabs/bold/containers/events"
 	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/containers/workerpool"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	"github.com/OffchainLabs/bold/testing/mocks"
 	"github.com/OffchainLabs/bold/testing/setup"
 	customTime "github.com/OffchainLabs/bold/time"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/stretchr/testify/require"
 )
 
@@ -74,6 +84,37 @@ func TestEdgeTracker_Act_ConfirmedByTime(t *testing.T) {
 	require.Equal(t, true, honestTracker.ShouldDespawn(ctx))
 }
 
+func TestEdgeTracker_DecideAt(t *testing.T) {
+	ctx := context.Background()
+	createdData, err := setup.CreateTwoValidatorFork(ctx, &setup.CreateForkConfig{}, setup.WithMockOneStepProver())
+	require.NoError(t, err)
+
+	tkr, _ := setupEdgeTrackersForBisection(t, ctx, createdData, option.None[uint64]())
+	chalManager, err := createdData.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	edge, err := chalManager.GetEdge(ctx, tkr.EdgeId())
+	require.NoError(t, err)
+	createdAt, err := edge.Unwrap().CreatedAtBlock()
+	require.NoError(t, err)
+
+	_, err = tkr.DecideAt(ctx, createdAt-1)
+	require.ErrorIs(t, err, edgetracker.ErrEdgeNotYetCreated)
+
+	head, err := createdData.Backend.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	decision, err := tkr.DecideAt(ctx, head.Number.Uint64())
+	require.NoError(t, err)
+	require.Equal(t, edgetracker.DecideBisect, decision.Kind)
+	require.Equal(t, head.Number.Uint64(), decision.BlockNumber.Unwrap())
+	startHeight, _ := edge.Unwrap().StartCommitment()
+	endHeight, _ := edge.Unwrap().EndCommitment()
+	require.Equal(t, uint64(startHeight+endHeight)/2, decision.BisectionHeight.Unwrap())
+	require.NotEmpty(t, decision.Reasons)
+
+	// Deciding does not act, so the tracker is still in its start state.
+	require.Equal(t, edgetracker.EdgeStarted, tkr.CurrentState())
+}
+
 type verifiedHonestMock struct {
 	*mocks.MockSpecEdge
 }
@@ -120,6 +161,32 @@ func Test_getEdgeTrackers(t *testing.T) {
 	require.Equal(t, l2stateprovider.Batch(100), trk.AssertionInfo().ToBatch)
 }
 
+func TestManager_WithdrawEdgeUntracked(t *testing.T) {
+	ctx := context.Background()
+	v, m, _ := setupValidator(t)
+	cm, err := m.SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	edgeId := protocol.EdgeId{Hash: common.BytesToHash([]byte("foo"))}
+	edge := &mocks.MockSpecEdge{}
+	edge.On("Id").Return(edgeId)
+	edge.On("AssertionHash", ctx).Return(protocol.AssertionHash{}, errors.New("rpc failed"))
+	cm.(*mocks.MockSpecChallengeManager).On("GetEdge", ctx, edgeId).Return(option.Some(protocol.SpecEdge(edge)), nil)
+
+	// Looking up an edge which is not tracked returns its failure rather than retrying forever.
+	done := make(chan error, 1)
+	go func() {
+		_, err := v.WithdrawEdge(ctx, edgeId)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "could not look up edge")
+		require.ErrorContains(t, err, "rpc failed")
+	case <-time.After(10 * time.Second):
+		t.Fatal("withdrawing an untracked edge did not return")
+	}
+}
+
 func setupEdgeTrackersForBisection(
 	t *testing.T,
 	ctx context.Context,
@@ -237,3 +304,181 @@ func setupValidator(t *testing.T) (*Manager, *mocks.MockProtocol, *mocks.MockSta
 	require.NoError(t, err)
 	return v, p, s
 }
+
+// A backend whose first subscription to new heads fails, and whose second is dropped after
+// delivering a head.
+type flakyHeadsBackend struct {
+	protocol.ChainBackend
+	lock          sync.Mutex
+	subscriptions int64
+}
+
+func (b *flakyHeadsBackend) SubscribeNewHead(_ context.Context, ch chan<- *gethtypes.Header) (ethereum.Subscription, error) {
+	b.lock.Lock()
+	b.subscriptions++
+	n := b.subscriptions
+	b.lock.Unlock()
+	if n == 1 {
+		return nil, errors.New("dial failed")
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case ch <- &gethtypes.Header{Number: big.NewInt(n)}:
+		case <-quit:
+			return nil
+		}
+		if n == 2 {
+			return errors.New("connection reset")
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+func TestManager_BroadcastNewHeadsResubscribes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain := &mocks.MockProtocol{}
+	chain.On("Backend").Return(&flakyHeadsBackend{})
+	m := &Manager{
+		chain:                  chain,
+		newBlockNotifier:       events.NewProducer[*gethtypes.Header](),
+		notifyOnNumberOfBlocks: 1,
+	}
+	sub := m.newBlockNotifier.Subscribe()
+	done := make(chan struct{})
+	go func() {
+		m.broadcastNewHeads(ctx)
+		close(done)
+	}()
+
+	// Blocks are still notified after failing to subscribe, and after the subscription drops.
+	for _, want := range []int64{2, 3} {
+		header, stopped := sub.Next(ctx)
+		require.False(t, stopped)
+		require.Equal(t, want, header.Number.Int64())
+	}
+	cancel()
+	<-done
+}
+
+func TestWithConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.Name = "alice"
+	cfg.Mode = types.DefensiveMode
+	cfg.MaxDelaySeconds = 3
+	cfg.ChallengeScanStartBlocks = map[common.Address]uint64{common.HexToAddress("0x1"): 10}
+	cfg.TrackChallengeParentAssertionHashes = []common.Hash{common.HexToHash("0x2")}
+	require.NoError(t, cfg.Validate())
+
+	m := &Manager{}
+	WithConfig(cfg)(m)
+	WithName("bob")(m)
+	require.Equal(t, "bob", m.name)
+	require.Equal(t, types.DefensiveMode, m.Mode())
+	require.Equal(t, 3, m.MaxDelaySeconds())
+	require.Equal(t, cfg.AssertionPostingInterval, m.assertionPostingInterval)
+	require.Equal(t, uint64(1), m.notifyOnNumberOfBlocks)
+	require.Equal(t, uint64(10), m.challengeScanStartBlocks[common.HexToAddress("0x1")])
+	require.Equal(t, []protocol.AssertionHash{{Hash: common.HexToHash("0x2")}}, m.trackChallengeParentAssertionHashes)
+}
+
+type stubService struct{}
+
+func (stubService) Start(context.Context) error { return nil }
+func (stubService) StopAndWait()                {}
+
+// A state manager recording the worker pools it is given.
+type poolingStateManager struct {
+	*mocks.MockStateManager
+	commitmentPool *workerpool.Pool
+	proofPool      *workerpool.Pool
+}
+
+func (s *poolingStateManager) UpdateWorkerPools(commitmentPool, proofPool *workerpool.Pool) {
+	s.commitmentPool = commitmentPool
+	s.proofPool = proofPool
+}
+
+func TestNewEmbedded(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := setup.ChainsWithEdgeChallengeManager(setup.WithMockOneStepProver())
+	require.NoError(t, err)
+	deps := Dependencies{
+		Backend:       cfg.Backend,
+		Signer:        cfg.Accounts[1].TxOpts,
+		StateProvider: &mocks.MockStateManager{},
+		Rollup:        cfg.Addrs.Rollup,
+	}
+
+	t.Run("missing dependencies", func(t *testing.T) {
+		missing := deps
+		missing.Signer = nil
+		_, err := NewEmbedded(ctx, missing)
+		require.ErrorContains(t, err, "signer")
+		missing = deps
+		missing.StateProvider = nil
+		_, err = NewEmbedded(ctx, missing)
+		require.ErrorContains(t, err, "state provider")
+	})
+	t.Run("creates services", func(t *testing.T) {
+		var created *Manager
+		m, err := NewEmbedded(ctx, deps, WithMode(types.MakeMode), WithService(func(m *Manager) (Service, error) {
+			created = m
+			return stubService{}, nil
+		}))
+		require.NoError(t, err)
+		require.Equal(t, m, created)
+		require.Equal(t, []Service{stubService{}}, m.services)
+		require.Equal(t, cfg.Accounts[1].TxOpts.From, m.Chain().StakerAddress())
+		chalManager, err := cfg.Chains[0].SpecChallengeManager(ctx)
+		require.NoError(t, err)
+		require.Equal(t, chalManager.Address(), m.chalManagerAddr)
+		require.Nil(t, m.Database())
+	})
+	t.Run("deadline margin", func(t *testing.T) {
+		chalManager, err := cfg.Chains[0].SpecChallengeManager(ctx)
+		require.NoError(t, err)
+		period, err := chalManager.ChallengePeriodBlocks(ctx)
+		require.NoError(t, err)
+		_, err = NewEmbedded(ctx, deps, WithDeadlineMargin(period))
+		require.ErrorContains(t, err, "must be below the challenge period")
+		m, err := NewEmbedded(ctx, deps, WithDeadlineMargin(period/4))
+		require.NoError(t, err)
+		require.Equal(t, period/4, m.DeadlineMargin().Blocks())
+		require.False(t, m.deadlineMarginTightened())
+	})
+	t.Run("proof self test", func(t *testing.T) {
+		_, err := NewEmbedded(ctx, deps, WithProofSelfTest())
+		require.NoError(t, err)
+		// The deployed verifier rejects the proofs of the synthetic machine.
+		verifier, err := solimpl.NewOneStepProofVerifier(ctx, cfg.Chains[0], common.Hash{}, big.NewInt(1))
+		require.NoError(t, err)
+		_, err = NewEmbedded(ctx, deps, WithProofSelfTest(l2stateprovider.WithOneStepProofVerifier(verifier)))
+		require.ErrorContains(t, err, "proof pipeline self test failed")
+	})
+	t.Run("worker pools", func(t *testing.T) {
+		poolCfg := config.Default()
+		poolCfg.CommitmentWorkers = 4
+		stateManager := &poolingStateManager{MockStateManager: &mocks.MockStateManager{}}
+		pooled := deps
+		pooled.StateProvider = stateManager
+		m, err := NewEmbedded(ctx, pooled, WithConfig(poolCfg))
+		require.NoError(t, err)
+		require.NotNil(t, stateManager.commitmentPool)
+		require.Equal(t, m.commitmentPool, stateManager.commitmentPool)
+		require.Equal(t, 1, stateManager.commitmentPool.Size())
+		require.Nil(t, stateManager.proofPool)
+
+		// State managers which cannot use pools are left unbounded.
+		m, err = NewEmbedded(ctx, deps, WithConfig(poolCfg))
+		require.NoError(t, err)
+		require.Nil(t, m.commitmentPool)
+	})
+	t.Run("service error", func(t *testing.T) {
+		_, err := NewEmbedded(ctx, deps, WithService(func(*Manager) (Service, error) {
+			return nil, errors.New("bad service")
+		}))
+		require.ErrorContains(t, err, "bad service")
+	})
+}
@@ -0,0 +1,435 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package policy applies policy documents, which set the policies of a challenge manager that may
+// change at runtime, such as its retry backoffs and disabled confirmation methods. Documents are
+// fetched from a URL and must be signed by the operator, so that a fleet of validators can be
+// controlled centrally.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/util/stopwaiter"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader holds the hex encoded signature of the document, made over its EIP-191 text
+	// hash like the signatures of webhook requests.
+	SignatureHeader = "X-Bold-Signature"
+
+	defaultPollInterval = time.Minute
+	defaultTimeout      = 10 * time.Second
+	maxDocumentSize     = 1 << 20
+)
+
+var (
+	appliedCounter  = metrics.NewRegisteredCounter("arb/validator/policy/applied", nil)
+	rejectedCounter = metrics.NewRegisteredCounter("arb/validator/policy/rejected", nil)
+	versionGauge    = metrics.NewRegisteredGauge("arb/validator/policy/version", nil)
+)
+
+// Document sets the runtime policies of a challenge manager. Policies a document leaves out are
+// reset to the ones the challenge manager started with, so each document describes all of them.
+type Document struct {
+	// Increases with every document the operator publishes. Documents whose version is not
+	// greater than the applied one are ignored, so an old signed document cannot be replayed. The
+	// applied document itself is applied again once after a restart, if it was not restored.
+	Version uint64 `json:"version"`
+	// Backoffs of named retry policies, in the format of the retry flags, such as
+	// "initial=1s,max=1m". Fields not given keep the value the challenge manager started with.
+	RetryPolicies map[retry.Policy]string `json:"retryPolicies,omitempty"`
+	// Confirmation methods the validator does not use, among claim and children.
+	DisabledConfirmationMethods []types.ConfirmationMethod `json:"disabledConfirmationMethods,omitempty"`
+}
+
+// The policies a document sets, once validated.
+type policies struct {
+	retryPolicies map[retry.Policy]retry.Backoff
+	disabled      map[types.ConfirmationMethod]bool
+}
+
+// Engine applies documents to the policies of a challenge manager, rolling back to the policies
+// in effect before a document if any of them cannot be applied.
+type Engine struct {
+	methods     *types.ConfirmationMethods
+	initial     *policies
+	versionPath string
+	lock        sync.Mutex
+	version     uint64
+	// Whether the document of the version is in effect, which it is not after a restart until the
+	// stored document is applied again.
+	inEffect bool
+	stored   *storedDocument
+}
+
+// The last applied document, as stored in the version file along with its signature, so that it
+// can be verified and applied again after a restart.
+type storedDocument struct {
+	Version   uint64        `json:"version"`
+	Body      hexutil.Bytes `json:"body,omitempty"`
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+type EngineOpt func(*Engine)
+
+// WithVersionPath stores the version of the last applied document in a file, along with the
+// document and its signature if it was fetched, so that documents not newer than it are still
+// rejected after a restart, and it is applied again by the fetcher. Defaults to empty, which keeps
+// it in memory only.
+func WithVersionPath(path string) EngineOpt {
+	return func(e *Engine) {
+		e.versionPath = path
+	}
+}
+
+// NewEngine for the policies a challenge manager started with, which documents are applied over.
+func NewEngine(methods *types.ConfirmationMethods, opts ...EngineOpt) (*Engine, error) {
+	e := &Engine{
+		methods: methods,
+		initial: currentPolicies(methods),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	if e.versionPath == "" {
+		return e, nil
+	}
+	data, err := os.ReadFile(e.versionPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read applied policy version")
+	}
+	// Earlier versions stored only the version, as a decimal number.
+	if version, parseErr := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); parseErr == nil {
+		e.version = version
+		return e, nil
+	}
+	stored := &storedDocument{}
+	if err = json.Unmarshal(data, stored); err != nil {
+		return nil, errors.Wrapf(err, "could not decode applied policy version at %s", e.versionPath)
+	}
+	e.version = stored.Version
+	e.stored = stored
+	return e, nil
+}
+
+// Applies the stored document again once its signature is verified, so that the policies in
+// effect before a restart are restored. If it cannot be, the policies the challenge manager
+// started with stay in effect, and a document of the same version may be applied once.
+func (e *Engine) restore(operator common.Address) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	stored := e.stored
+	e.stored = nil
+	if stored == nil || len(stored.Body) == 0 {
+		return
+	}
+	doc, err := VerifyDocument(stored.Body, stored.Signature, operator)
+	if err == nil && doc.Version != stored.Version {
+		err = fmt.Errorf("stored document has version %d, expected %d", doc.Version, stored.Version)
+	}
+	if err == nil {
+		err = e.applyLocked(doc, stored)
+	}
+	if err != nil {
+		log.Error("Could not restore the applied policy document, keeping the startup policies until it is fetched again", "version", stored.Version, "err", err)
+		return
+	}
+	log.Info("Restored applied policy document", "version", doc.Version)
+}
+
+// Version of the last applied document, which is 0 if none was.
+func (e *Engine) Version() uint64 {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.version
+}
+
+// Apply validates a document and applies its policies. If the document is invalid, nothing is
+// applied, and if a policy fails to apply, the policies in effect before are restored.
+func (e *Engine) Apply(doc *Document) error {
+	return e.apply(doc, &storedDocument{Version: doc.Version})
+}
+
+// Checks whether a document of a version would be applied, which it is if it is newer than the
+// applied one, or of the same version while that one is not in effect.
+func (e *Engine) accepts(version uint64) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.acceptsLocked(version)
+}
+
+func (e *Engine) acceptsLocked(version uint64) bool {
+	return version > e.version || (version == e.version && !e.inEffect)
+}
+
+func (e *Engine) apply(doc *Document, stored *storedDocument) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.applyLocked(doc, stored)
+}
+
+func (e *Engine) applyLocked(doc *Document, stored *storedDocument) error {
+	if !e.acceptsLocked(doc.Version) {
+		return fmt.Errorf("document version %d is not newer than the applied version %d", doc.Version, e.version)
+	}
+	next, err := e.validate(doc)
+	if err != nil {
+		return errors.Wrapf(err, "invalid policy document version %d", doc.Version)
+	}
+	previous := currentPolicies(e.methods)
+	if err = e.set(next); err != nil {
+		if rollbackErr := e.set(previous); rollbackErr != nil {
+			log.Crit("Could not roll back policies after failing to apply a policy document", "version", doc.Version, "err", err, "rollbackErr", rollbackErr)
+		}
+		return errors.Wrapf(err, "could not apply policy document version %d, rolled back", doc.Version)
+	}
+	// A document is only kept applied once its version is stored, so that it cannot be replayed
+	// after a restart.
+	if err = e.store(stored); err != nil {
+		if rollbackErr := e.set(previous); rollbackErr != nil {
+			log.Crit("Could not roll back policies after failing to store the policy document version", "version", doc.Version, "err", err, "rollbackErr", rollbackErr)
+		}
+		return errors.Wrapf(err, "could not store policy document version %d, rolled back", doc.Version)
+	}
+	e.version = doc.Version
+	e.inEffect = true
+	versionGauge.Update(int64(doc.Version))
+	return nil
+}
+
+func (e *Engine) store(stored *storedDocument) error {
+	if e.versionPath == "" {
+		return nil
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	tmp := e.versionPath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.versionPath)
+}
+
+func (e *Engine) validate(doc *Document) (*policies, error) {
+	next := &policies{
+		retryPolicies: make(map[retry.Policy]retry.Backoff, len(e.initial.retryPolicies)),
+		disabled:      make(map[types.ConfirmationMethod]bool),
+	}
+	for p, b := range e.initial.retryPolicies {
+		next.retryPolicies[p] = b
+	}
+	for p, s := range doc.RetryPolicies {
+		initial, ok := e.initial.retryPolicies[p]
+		if !ok {
+			return nil, fmt.Errorf("unknown retry policy %q", p)
+		}
+		b, err := initial.Parse(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "retry policy %s", p)
+		}
+		next.retryPolicies[p] = b
+	}
+	for _, m := range doc.DisabledConfirmationMethods {
+		if _, err := types.ParseConfirmationMethod(string(m)); err != nil {
+			return nil, err
+		}
+		if !m.CanBeDisabled() {
+			return nil, fmt.Errorf("confirmation method %q cannot be disabled", m)
+		}
+		next.disabled[m] = true
+	}
+	return next, nil
+}
+
+func (e *Engine) set(p *policies) error {
+	for name, b := range p.retryPolicies {
+		if err := retry.SetPolicy(name, b); err != nil {
+			return err
+		}
+	}
+	if e.methods == nil {
+		if len(p.disabled) > 0 {
+			return errors.New("the confirmation methods of the challenge manager cannot be changed")
+		}
+		return nil
+	}
+	for _, m := range types.AllConfirmationMethods() {
+		if !m.CanBeDisabled() {
+			continue
+		}
+		if err := e.methods.SetEnabled(m, !p.disabled[m]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func currentPolicies(methods *types.ConfirmationMethods) *policies {
+	p := &policies{
+		retryPolicies: make(map[retry.Policy]retry.Backoff),
+		disabled:      make(map[types.ConfirmationMethod]bool),
+	}
+	for _, name := range retry.Policies() {
+		if b, ok := retry.PolicyBackoff(name); ok {
+			p.retryPolicies[name] = b
+		}
+	}
+	for _, m := range methods.Disabled() {
+		p.disabled[m] = true
+	}
+	return p
+}
+
+// VerifyDocument checks that a document was signed by the operator and decodes it. Unknown
+// fields are rejected, so a document written for a newer client is not partially applied.
+func VerifyDocument(body []byte, signature []byte, operator common.Address) (*Document, error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("policy document signature must be %d bytes, got %d", crypto.SignatureLength, len(signature))
+	}
+	// Signers such as wallets use a recovery id of 27 or 28, as in Ethereum transactions.
+	if v := signature[crypto.RecoveryIDOffset]; v == 27 || v == 28 {
+		signature = append([]byte{}, signature...)
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(accounts.TextHash(body), signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not recover policy document signer")
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != operator {
+		return nil, fmt.Errorf("policy document signed by %s, expected operator %s", signer, operator)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	doc := &Document{}
+	if err = decoder.Decode(doc); err != nil {
+		return nil, errors.Wrap(err, "could not decode policy document")
+	}
+	return doc, nil
+}
+
+// Fetcher polls a URL for policy documents signed by the operator, and applies new ones.
+type Fetcher struct {
+	stopwaiter.StopWaiter
+	url          string
+	operator     common.Address
+	engine       *Engine
+	client       *http.Client
+	pollInterval time.Duration
+}
+
+type Opt func(*Fetcher)
+
+// WithPollInterval sets how often the document is fetched. Defaults to a minute.
+func WithPollInterval(interval time.Duration) Opt {
+	return func(f *Fetcher) {
+		f.pollInterval = interval
+	}
+}
+
+// WithHTTPClient sets the client documents are fetched with.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+func NewFetcher(url string, operator common.Address, engine *Engine, opts ...Opt) (*Fetcher, error) {
+	if url == "" {
+		return nil, errors.New("no policy document url given")
+	}
+	if operator == (common.Address{}) {
+		return nil, errors.New("policy documents must be signed by an operator address")
+	}
+	f := &Fetcher{
+		url:          url,
+		operator:     operator,
+		engine:       engine,
+		client:       &http.Client{Timeout: defaultTimeout},
+		pollInterval: defaultPollInterval,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	if f.pollInterval <= 0 {
+		return nil, fmt.Errorf("policy poll interval must be positive, got %v", f.pollInterval)
+	}
+	engine.restore(operator)
+	return f, nil
+}
+
+func (f *Fetcher) Start(ctx context.Context) {
+	f.StopWaiter.Start(ctx, f)
+	f.CallIteratively(func(ctx context.Context) time.Duration {
+		if err := f.Poll(ctx); err != nil {
+			rejectedCounter.Inc(1)
+			log.Error("Could not apply remote policy document", "url", f.url, "err", err)
+		}
+		return f.pollInterval
+	})
+}
+
+// Poll fetches the document once, and applies it if it is newer than the applied one, or is the
+// applied one but not in effect since a restart.
+func (f *Fetcher) Poll(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not fetch policy document")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching policy document returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return errors.Wrap(err, "could not read policy document")
+	}
+	if len(body) > maxDocumentSize {
+		return fmt.Errorf("policy document exceeds %d bytes", maxDocumentSize)
+	}
+	signature, err := hexutil.Decode(resp.Header.Get(SignatureHeader))
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s header", SignatureHeader)
+	}
+	doc, err := VerifyDocument(body, signature, f.operator)
+	if err != nil {
+		return err
+	}
+	if !f.engine.accepts(doc.Version) {
+		return nil
+	}
+	if err = f.engine.apply(doc, &storedDocument{Version: doc.Version, Body: body, Signature: signature}); err != nil {
+		return err
+	}
+	appliedCounter.Inc(1)
+	log.Info("Applied remote policy document", "version", doc.Version, "retryPolicies", len(doc.RetryPolicies), "disabledConfirmationMethods", doc.DisabledConfirmationMethods)
+	return nil
+}
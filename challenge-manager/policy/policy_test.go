@@ -0,0 +1,249 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package policy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func resetRetryPolicies(t *testing.T) {
+	t.Cleanup(func() {
+		for p, b := range retry.DefaultPolicies() {
+			require.NoError(t, retry.SetPolicy(p, b))
+		}
+	})
+}
+
+func TestEngine_Apply(t *testing.T) {
+	resetRetryPolicies(t)
+	methods, err := types.NewConfirmationMethods()
+	require.NoError(t, err)
+	engine, err := NewEngine(methods)
+	require.NoError(t, err)
+
+	require.NoError(t, engine.Apply(&Document{
+		Version:                     1,
+		RetryPolicies:               map[retry.Policy]string{retry.RPCRead: "initial=2s,max=1m"},
+		DisabledConfirmationMethods: []types.ConfirmationMethod{types.ConfirmByClaim},
+	}))
+	require.Equal(t, uint64(1), engine.Version())
+	b, _ := retry.PolicyBackoff(retry.RPCRead)
+	require.Equal(t, 2*time.Second, b.Initial)
+	require.Equal(t, retry.DefaultPolicies()[retry.RPCRead].Multiplier, b.Multiplier)
+	require.False(t, methods.Enabled(types.ConfirmByClaim))
+
+	// Documents are replayed over the initial policies, so the policies they leave out are reset.
+	require.NoError(t, engine.Apply(&Document{Version: 2}))
+	b, _ = retry.PolicyBackoff(retry.RPCRead)
+	require.Equal(t, retry.DefaultPolicies()[retry.RPCRead], b)
+	require.True(t, methods.Enabled(types.ConfirmByClaim))
+
+	err = engine.Apply(&Document{Version: 2})
+	require.ErrorContains(t, err, "not newer")
+
+	// Invalid documents leave the policies unchanged.
+	err = engine.Apply(&Document{
+		Version:                     3,
+		RetryPolicies:               map[retry.Policy]string{retry.RPCRead: "initial=5s"},
+		DisabledConfirmationMethods: []types.ConfirmationMethod{types.ConfirmByTime},
+	})
+	require.ErrorContains(t, err, "cannot be disabled")
+	err = engine.Apply(&Document{
+		Version:       3,
+		RetryPolicies: map[retry.Policy]string{"unknown": "initial=5s"},
+	})
+	require.ErrorContains(t, err, "unknown retry policy")
+	err = engine.Apply(&Document{
+		Version:       3,
+		RetryPolicies: map[retry.Policy]string{retry.RPCRead: "initial=2m"},
+	})
+	require.ErrorContains(t, err, "less than initial")
+	b, _ = retry.PolicyBackoff(retry.RPCRead)
+	require.Equal(t, retry.DefaultPolicies()[retry.RPCRead], b)
+	require.Equal(t, uint64(2), engine.Version())
+}
+
+func TestEngine_RollsBackPartiallyAppliedDocuments(t *testing.T) {
+	resetRetryPolicies(t)
+	// Without confirmation methods to change, a document disabling one is only rejected once its
+	// retry policies were applied.
+	engine, err := NewEngine(nil)
+	require.NoError(t, err)
+	err = engine.Apply(&Document{
+		Version:                     1,
+		RetryPolicies:               map[retry.Policy]string{retry.RPCWrite: "initial=3s"},
+		DisabledConfirmationMethods: []types.ConfirmationMethod{types.ConfirmByChildren},
+	})
+	require.ErrorContains(t, err, "rolled back")
+	b, _ := retry.PolicyBackoff(retry.RPCWrite)
+	require.Equal(t, retry.DefaultPolicies()[retry.RPCWrite], b)
+	require.Equal(t, uint64(0), engine.Version())
+}
+
+func TestEngine_RejectsReplaysAfterRestart(t *testing.T) {
+	resetRetryPolicies(t)
+	path := filepath.Join(t.TempDir(), "policy-version")
+	engine, err := NewEngine(nil, WithVersionPath(path))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), engine.Version())
+	require.NoError(t, engine.Apply(&Document{Version: 3}))
+
+	restarted, err := NewEngine(nil, WithVersionPath(path))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), restarted.Version())
+	// The applied document is not in effect after a restart until it is applied again, once.
+	require.NoError(t, restarted.Apply(&Document{Version: 3}))
+	require.ErrorContains(t, restarted.Apply(&Document{Version: 3}), "not newer")
+	require.ErrorContains(t, restarted.Apply(&Document{Version: 2}), "not newer")
+	require.NoError(t, restarted.Apply(&Document{Version: 4}))
+
+	// A document whose version cannot be stored is rolled back, as it could be replayed later.
+	unwritable := filepath.Join(t.TempDir(), "missing", "policy-version")
+	engine, err = NewEngine(nil, WithVersionPath(unwritable))
+	require.NoError(t, err)
+	err = engine.Apply(&Document{
+		Version:       1,
+		RetryPolicies: map[retry.Policy]string{retry.RPCWrite: "initial=3s"},
+	})
+	require.ErrorContains(t, err, "rolled back")
+	b, _ := retry.PolicyBackoff(retry.RPCWrite)
+	require.Equal(t, retry.DefaultPolicies()[retry.RPCWrite], b)
+	require.Equal(t, uint64(0), engine.Version())
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = NewEngine(nil, WithVersionPath(path))
+	require.ErrorContains(t, err, "could not decode")
+}
+
+func TestVerifyDocument(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	operator := crypto.PubkeyToAddress(key.PublicKey)
+	body := []byte(`{"version":1}`)
+	signature, err := crypto.Sign(accounts.TextHash(body), key)
+	require.NoError(t, err)
+
+	doc, err := VerifyDocument(body, signature, operator)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), doc.Version)
+
+	// Signatures with a recovery id of 27 or 28, as made by wallets, are accepted.
+	walletSignature := append([]byte{}, signature...)
+	walletSignature[crypto.RecoveryIDOffset] += 27
+	doc, err = VerifyDocument(body, walletSignature, operator)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), doc.Version)
+	require.Equal(t, signature[crypto.RecoveryIDOffset]+27, walletSignature[crypto.RecoveryIDOffset])
+
+	_, err = VerifyDocument(body, signature[:crypto.RecoveryIDOffset], operator)
+	require.ErrorContains(t, err, "must be 65 bytes")
+}
+
+func serveDocument(t *testing.T, key *ecdsa.PrivateKey, body string) *httptest.Server {
+	signature, err := crypto.Sign(accounts.TextHash([]byte(body)), key)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(SignatureHeader, hexutil.Encode(signature))
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_Poll(t *testing.T) {
+	resetRetryPolicies(t)
+	ctx := context.Background()
+	operator, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	methods, err := types.NewConfirmationMethods()
+	require.NoError(t, err)
+	engine, err := NewEngine(methods)
+	require.NoError(t, err)
+	doc := `{"version":1,"disabledConfirmationMethods":["children"]}`
+
+	// Documents not signed by the operator are rejected.
+	server := serveDocument(t, other, doc)
+	fetcher, err := NewFetcher(server.URL, crypto.PubkeyToAddress(operator.PublicKey), engine)
+	require.NoError(t, err)
+	require.ErrorContains(t, fetcher.Poll(ctx), "expected operator")
+	require.True(t, methods.Enabled(types.ConfirmByChildren))
+
+	// As are documents with unknown fields.
+	server = serveDocument(t, operator, `{"version":1,"unknown":true}`)
+	fetcher, err = NewFetcher(server.URL, crypto.PubkeyToAddress(operator.PublicKey), engine)
+	require.NoError(t, err)
+	require.ErrorContains(t, fetcher.Poll(ctx), "unknown field")
+
+	server = serveDocument(t, operator, doc)
+	fetcher, err = NewFetcher(server.URL, crypto.PubkeyToAddress(operator.PublicKey), engine)
+	require.NoError(t, err)
+	require.NoError(t, fetcher.Poll(ctx))
+	require.False(t, methods.Enabled(types.ConfirmByChildren))
+	require.Equal(t, uint64(1), engine.Version())
+
+	// Polling the same document again is a no-op.
+	require.NoError(t, fetcher.Poll(ctx))
+}
+
+func TestFetcher_RestoresAppliedDocumentAfterRestart(t *testing.T) {
+	resetRetryPolicies(t)
+	ctx := context.Background()
+	operator, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	operatorAddr := crypto.PubkeyToAddress(operator.PublicKey)
+	path := filepath.Join(t.TempDir(), "policy-version")
+	server := serveDocument(t, operator, `{"version":1,"disabledConfirmationMethods":["children"]}`)
+
+	methods, err := types.NewConfirmationMethods()
+	require.NoError(t, err)
+	engine, err := NewEngine(methods, WithVersionPath(path))
+	require.NoError(t, err)
+	fetcher, err := NewFetcher(server.URL, operatorAddr, engine)
+	require.NoError(t, err)
+	require.NoError(t, fetcher.Poll(ctx))
+	require.False(t, methods.Enabled(types.ConfirmByChildren))
+
+	// After a restart, the document is in effect again before the remote is polled.
+	restartedMethods, err := types.NewConfirmationMethods()
+	require.NoError(t, err)
+	restarted, err := NewEngine(restartedMethods, WithVersionPath(path))
+	require.NoError(t, err)
+	require.True(t, restartedMethods.Enabled(types.ConfirmByChildren))
+	_, err = NewFetcher("http://unreachable.invalid", operatorAddr, restarted)
+	require.NoError(t, err)
+	require.False(t, restartedMethods.Enabled(types.ConfirmByChildren))
+	require.Equal(t, uint64(1), restarted.Version())
+	require.ErrorContains(t, restarted.Apply(&Document{Version: 1}), "not newer")
+
+	// A stored document which does not verify, such as after the operator changed, leaves the
+	// startup policies in effect until the remote document of the same version is fetched.
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherMethods, err := types.NewConfirmationMethods()
+	require.NoError(t, err)
+	unverified, err := NewEngine(otherMethods, WithVersionPath(path))
+	require.NoError(t, err)
+	otherServer := serveDocument(t, other, `{"version":1,"disabledConfirmationMethods":["children"]}`)
+	fetcher, err = NewFetcher(otherServer.URL, crypto.PubkeyToAddress(other.PublicKey), unverified)
+	require.NoError(t, err)
+	require.True(t, otherMethods.Enabled(types.ConfirmByChildren))
+	require.NoError(t, fetcher.Poll(ctx))
+	require.False(t, otherMethods.Enabled(types.ConfirmByChildren))
+}
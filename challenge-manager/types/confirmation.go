@@ -0,0 +1,118 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ConfirmationMethod is a way the validator confirms edges, or propagates the timers which let
+// them be confirmed.
+type ConfirmationMethod string
+
+const (
+	// ConfirmByTime confirms an edge whose onchain timer is at least a challenge period.
+	ConfirmByTime ConfirmationMethod = "time"
+	// ConfirmByOneStepProof confirms an edge of length one at the last challenge level.
+	ConfirmByOneStepProof ConfirmationMethod = "one-step-proof"
+	// ConfirmByClaim propagates the timer of the layer zero edge of a subchallenge to the edge it
+	// claims at the level above.
+	ConfirmByClaim ConfirmationMethod = "claim"
+	// ConfirmByChildren propagates the timers of the children of edges to their parents within a
+	// challenge level.
+	ConfirmByChildren ConfirmationMethod = "children"
+)
+
+var confirmationMethods = []ConfirmationMethod{
+	ConfirmByTime,
+	ConfirmByOneStepProof,
+	ConfirmByClaim,
+	ConfirmByChildren,
+}
+
+// AllConfirmationMethods lists every confirmation method.
+func AllConfirmationMethods() []ConfirmationMethod {
+	return append([]ConfirmationMethod{}, confirmationMethods...)
+}
+
+// ParseConfirmationMethod parses the name of a confirmation method.
+func ParseConfirmationMethod(name string) (ConfirmationMethod, error) {
+	for _, m := range confirmationMethods {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown confirmation method %q, expected one of time, one-step-proof, claim, or children", name)
+}
+
+// CanBeDisabled is true for the confirmation methods operators may disable. Confirming by time
+// and by one step proof are always enabled, as a challenge cannot be won without them.
+func (m ConfirmationMethod) CanBeDisabled() bool {
+	return m == ConfirmByClaim || m == ConfirmByChildren
+}
+
+// ConfirmationMethods holds which confirmation methods are disabled, and may be changed at
+// runtime, such as while a contract bug in one of them is being disclosed. A nil value has every
+// method enabled.
+type ConfirmationMethods struct {
+	disabled map[ConfirmationMethod]bool
+	lock     sync.RWMutex
+}
+
+// NewConfirmationMethods with the given methods disabled.
+func NewConfirmationMethods(disabled ...ConfirmationMethod) (*ConfirmationMethods, error) {
+	c := &ConfirmationMethods{
+		disabled: make(map[ConfirmationMethod]bool),
+	}
+	for _, m := range disabled {
+		if err := c.SetEnabled(m, false); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Enabled checks if the validator may use a confirmation method.
+func (c *ConfirmationMethods) Enabled(m ConfirmationMethod) bool {
+	if c == nil {
+		return true
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return !c.disabled[m]
+}
+
+// SetEnabled enables or disables a confirmation method. Fails for methods which cannot be disabled.
+func (c *ConfirmationMethods) SetEnabled(m ConfirmationMethod, enabled bool) error {
+	if _, err := ParseConfirmationMethod(string(m)); err != nil {
+		return err
+	}
+	if !m.CanBeDisabled() {
+		return fmt.Errorf("confirmation method %q cannot be disabled", m)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if enabled {
+		delete(c.disabled, m)
+	} else {
+		c.disabled[m] = true
+	}
+	return nil
+}
+
+// Disabled lists the disabled confirmation methods, sorted by name.
+func (c *ConfirmationMethods) Disabled() []ConfirmationMethod {
+	if c == nil {
+		return nil
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	disabled := make([]ConfirmationMethod, 0, len(c.disabled))
+	for m := range c.disabled {
+		disabled = append(disabled, m)
+	}
+	sort.Slice(disabled, func(i, j int) bool {
+		return disabled[i] < disabled[j]
+	})
+	return disabled
+}
@@ -1,5 +1,7 @@
 package types
 
+import "fmt"
+
 type Mode uint8
 
 const (
@@ -12,3 +14,27 @@ const (
 	// Make nodes: continually create new nodes, challenging bad assertions
 	MakeMode
 )
+
+var modeNames = map[Mode]string{
+	WatchTowerMode: "watchtower",
+	DefensiveMode:  "defensive",
+	ResolveMode:    "resolve",
+	MakeMode:       "make",
+}
+
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", m)
+}
+
+// ParseMode parses the name of a mode, as returned by its String method.
+func ParseMode(name string) (Mode, error) {
+	for m, n := range modeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q, expected one of watchtower, defensive, resolve, or make", name)
+}
@@ -0,0 +1,31 @@
+package types
+
+import "fmt"
+
+// SiblingPolicy decides which branch the validator defends when another party posts a sibling of
+// an assertion whose state is equivalent to the one the validator computes: the same block hash,
+// send root, and machine status, reached at a different position in the inbox. Such siblings are
+// never challenged, whatever the policy.
+type SiblingPolicy string
+
+const (
+	// DefendExactSibling defends the sibling whose state is exactly the one the validator computes,
+	// posting it if no one has yet.
+	DefendExactSibling SiblingPolicy = "exact"
+	// DefendFirstSibling defends the first posted sibling whose state is equivalent to ours, and
+	// builds on it rather than posting a sibling of our own.
+	DefendFirstSibling SiblingPolicy = "first"
+)
+
+// ParseSiblingPolicy parses the name of a sibling policy. An empty name is the default policy,
+// which defends the exact sibling.
+func ParseSiblingPolicy(name string) (SiblingPolicy, error) {
+	switch SiblingPolicy(name) {
+	case "", DefendExactSibling:
+		return DefendExactSibling, nil
+	case DefendFirstSibling:
+		return DefendFirstSibling, nil
+	default:
+		return "", fmt.Errorf("unknown sibling policy %q, expected exact or first", name)
+	}
+}
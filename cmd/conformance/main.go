@@ -0,0 +1,106 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Command conformance runs the conformance suite against a deployed edge challenge manager, to
+// check it behaves as the challenge logic of this repository assumes before going live. It only
+// reads from the chain, and exits with a non-zero status if any check fails or is skipped.
+//
+// Usage:
+//
+//	go run ./cmd/conformance -url http://localhost:8545 -rollup 0x...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/OffchainLabs/bold/testing/conformance"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("conformance", flag.ContinueOnError)
+	var (
+		url              = fs.String("url", "", "RPC endpoint of a node of the parent chain")
+		challengeManager = fs.String("challenge-manager", "", "address of the edge challenge manager")
+		rollup           = fs.String("rollup", "", "address of the rollup, to check its challenge manager")
+		fromBlock        = fs.Int64("from-block", -1, "block to scan for edges from, the deployment block of the challenge manager if negative")
+		maxEdges         = fs.Int("max-edges", conformance.DefaultMaxEdges, "maximum number of edges to check")
+		timeout          = fs.Duration("timeout", 10*time.Minute, "time allowed for the suite to run")
+		asJSON           = fs.Bool("json", false, "output the report as JSON")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *url == "" {
+		return errors.New("an RPC endpoint must be set with -url")
+	}
+	if (*challengeManager == "") == (*rollup == "") {
+		return errors.New("exactly one of -challenge-manager and -rollup must be set")
+	}
+	for _, addr := range []string{*challengeManager, *rollup} {
+		if addr != "" && !common.IsHexAddress(addr) {
+			return fmt.Errorf("invalid address %q", addr)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, *url)
+	if err != nil {
+		return fmt.Errorf("could not connect to %s: %w", *url, err)
+	}
+	defer client.Close()
+
+	addr := common.HexToAddress(*challengeManager)
+	if *rollup != "" {
+		rollupCore, err := rollupgen.NewRollupCoreCaller(common.HexToAddress(*rollup), client)
+		if err != nil {
+			return err
+		}
+		addr, err = rollupCore.ChallengeManager(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return fmt.Errorf("could not get the challenge manager of the rollup: %w", err)
+		}
+	}
+	opts := []conformance.Opt{conformance.WithMaxEdges(*maxEdges)}
+	if *fromBlock >= 0 {
+		opts = append(opts, conformance.WithFromBlock(uint64(*fromBlock)))
+	}
+	report, err := conformance.Run(ctx, client, addr, opts...)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if err := report.Print(out); err != nil {
+		return err
+	}
+	if failures := report.Failures(); len(failures) > 0 {
+		return fmt.Errorf("%d conformance checks failed", len(failures))
+	}
+	if skipped := report.Skipped(); len(skipped) > 0 {
+		return fmt.Errorf("conformance report is incomplete: %d checks were skipped", len(skipped))
+	}
+	return nil
+}
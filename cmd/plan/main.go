@@ -0,0 +1,215 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Command plan projects the resources a validator needs to take part in the worst case challenge
+// on a chain, given the parameters of the chain and the hardware of the validator. Costs of the
+// commitment and proof pipelines are measured on the machine it runs on, unless provided.
+//
+// Usage:
+//
+//	go run ./cmd/plan -adversaries 4 -cores 16 -memory-gb 64 -disk-gb 500
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+)
+
+const gb = 1 << 30
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	var (
+		blockTime             = fs.Duration("block-time", 12*time.Second, "time between parent chain blocks")
+		challengePeriodBlocks = fs.Uint64("challenge-period-blocks", 45818, "challenge period in parent chain blocks")
+		blockHeight           = fs.Uint64("block-challenge-height", 1<<26, "layer zero height of block challenge edges")
+		bigStepHeight         = fs.Uint64("big-step-challenge-height", 1<<19, "layer zero height of big step challenge edges")
+		smallStepHeight       = fs.Uint64("small-step-challenge-height", 1<<23, "layer zero height of small step challenge edges")
+		numBigStepLevels      = fs.Uint("big-step-levels", 1, "number of big step challenge levels")
+		adversaries           = fs.Uint64("adversaries", 1, "expected number of adversaries disagreeing at different points")
+		cores                 = fs.Uint64("cores", uint64(runtime.NumCPU()), "CPU cores available to the validator")
+		memoryGB              = fs.Float64("memory-gb", 0, "memory available to the validator in GB, unchecked if zero")
+		diskGB                = fs.Float64("disk-gb", 0, "disk available to the validator in GB, unchecked if zero")
+		stepsPerSecond        = fs.Float64("machine-steps-per-second", 5e7, "machine steps executed per second by a core")
+		machineMemoryGB       = fs.Float64("machine-memory-gb", 4, "memory used by a single machine in GB")
+		benchLeaves           = fs.Uint64("benchmark-leaves", 1<<16, "number of leaves to benchmark the commitment and proof pipelines with")
+		commitmentPerLeaf     = fs.Duration("commitment-per-leaf", 0, "cost of committing to a leaf, benchmarked if zero")
+		prefixProofPerLeaf    = fs.Duration("prefix-proof-per-leaf", 0, "cost of proving a prefix per leaf, benchmarked if zero")
+		asJSON                = fs.Bool("json", false, "output the plan as JSON")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *numBigStepLevels > 255 {
+		return fmt.Errorf("too many big step levels: %d", *numBigStepLevels)
+	}
+	chain := &chainParams{
+		blockTime: *blockTime,
+		heights: protocol.LayerZeroHeights{
+			BlockChallengeHeight:     *blockHeight,
+			BigStepChallengeHeight:   *bigStepHeight,
+			SmallStepChallengeHeight: *smallStepHeight,
+		},
+		numBigStepLevels:      uint8(*numBigStepLevels),
+		challengePeriodBlocks: *challengePeriodBlocks,
+		adversaries:           *adversaries,
+	}
+	hw := &hardwareSpecs{
+		cores:                 *cores,
+		memoryBytes:           uint64(*memoryGB * gb),
+		diskBytes:             uint64(*diskGB * gb),
+		machineStepsPerSecond: *stepsPerSecond,
+		machineMemoryBytes:    uint64(*machineMemoryGB * gb),
+	}
+	bench := &benchmarks{
+		commitmentPerLeaf:  *commitmentPerLeaf,
+		prefixProofPerLeaf: *prefixProofPerLeaf,
+	}
+	if bench.commitmentPerLeaf == 0 || bench.prefixProofPerLeaf == 0 {
+		measured, err := runBenchmarks(*benchLeaves, 3)
+		if err != nil {
+			return fmt.Errorf("could not benchmark commitments: %w", err)
+		}
+		if bench.commitmentPerLeaf == 0 {
+			bench.commitmentPerLeaf = measured.commitmentPerLeaf
+		}
+		if bench.prefixProofPerLeaf == 0 {
+			bench.prefixProofPerLeaf = measured.prefixProofPerLeaf
+		}
+	}
+	p, err := computePlan(chain, hw, bench)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p.report(bench))
+	}
+	return p.print(out, hw, bench)
+}
+
+type jsonLevel struct {
+	Name             string  `json:"name"`
+	Leaves           uint64  `json:"leaves"`
+	MachineSteps     float64 `json:"machineSteps"`
+	Moves            uint64  `json:"moves"`
+	MoveTime         string  `json:"moveTime"`
+	WorstCaseLatency string  `json:"worstCaseLatency"`
+}
+
+type jsonReport struct {
+	CommitmentPerLeaf        string       `json:"commitmentPerLeaf"`
+	PrefixProofPerLeaf       string       `json:"prefixProofPerLeaf"`
+	HonestPaths              uint64       `json:"honestPaths"`
+	Levels                   []*jsonLevel `json:"levels"`
+	OneStepProofMachineSteps float64      `json:"oneStepProofMachineSteps"`
+	OneStepProofTime         string       `json:"oneStepProofTime"`
+	TotalComputeTime         string       `json:"totalComputeTime"`
+	ChallengePeriod          string       `json:"challengePeriod"`
+	WorstCaseResponseLatency string       `json:"worstCaseResponseLatency"`
+	RequiredCores            uint64       `json:"requiredCores"`
+	RequiredMemoryBytes      uint64       `json:"requiredMemoryBytes"`
+	RequiredDiskBytes        uint64       `json:"requiredDiskBytes"`
+	RPCCallsPerSecond        float64      `json:"rpcCallsPerSecond"`
+	Sufficient               bool         `json:"sufficient"`
+}
+
+func (p *plan) report(bench *benchmarks) *jsonReport {
+	r := &jsonReport{
+		CommitmentPerLeaf:        bench.commitmentPerLeaf.String(),
+		PrefixProofPerLeaf:       bench.prefixProofPerLeaf.String(),
+		HonestPaths:              p.honestPaths,
+		OneStepProofMachineSteps: p.oneStepProofMachineSteps,
+		OneStepProofTime:         p.oneStepProofTime.String(),
+		TotalComputeTime:         p.totalComputeTime.String(),
+		ChallengePeriod:          p.challengePeriod.String(),
+		WorstCaseResponseLatency: p.worstCaseResponseLatency.String(),
+		RequiredCores:            p.requiredCores,
+		RequiredMemoryBytes:      p.requiredMemoryBytes,
+		RequiredDiskBytes:        p.requiredDiskBytes,
+		RPCCallsPerSecond:        p.rpcCallsPerSecond,
+		Sufficient:               p.sufficient(),
+	}
+	for _, l := range p.levels {
+		r.Levels = append(r.Levels, &jsonLevel{
+			Name:             l.name,
+			Leaves:           l.leaves,
+			MachineSteps:     l.machineSteps,
+			Moves:            l.moves,
+			MoveTime:         l.moveTime.String(),
+			WorstCaseLatency: l.worstCaseLatency.String(),
+		})
+	}
+	return r
+}
+
+func (p *plan) sufficient() bool {
+	return !p.insufficientCores && !p.insufficientMemory && !p.insufficientDisk && !p.latencyExceedsChalPeriod
+}
+
+func (p *plan) print(out io.Writer, hw *hardwareSpecs, bench *benchmarks) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Benchmarks\tcommitment %v/leaf, prefix proof %v/leaf\n", bench.commitmentPerLeaf, bench.prefixProofPerLeaf)
+	fmt.Fprintf(w, "Honest paths\t%d\n\n", p.honestPaths)
+
+	fmt.Fprintln(w, "LEVEL\tLEAVES\tMACHINE STEPS\tMOVES\tMOVE TIME\tWORST CASE LATENCY")
+	for _, l := range p.levels {
+		fmt.Fprintf(w, "%s\t%d\t%.3g\t%d\t%v\t%v\n", l.name, l.leaves, l.machineSteps, l.moves, formatDuration(l.moveTime), formatDuration(l.worstCaseLatency))
+	}
+	fmt.Fprintf(w, "one step proof\t\t%.3g\t1\t%v\t%v\n\n", p.oneStepProofMachineSteps, formatDuration(p.oneStepProofTime), formatDuration(p.oneStepProofTime))
+
+	fmt.Fprintln(w, "RESOURCE\tREQUIRED\tAVAILABLE\tSTATUS")
+	fmt.Fprintf(w, "CPU cores\t%d\t%d\t%s\n", p.requiredCores, hw.cores, status(p.insufficientCores))
+	fmt.Fprintf(w, "Memory\t%s\t%s\t%s\n", formatBytes(p.requiredMemoryBytes), formatBytes(hw.memoryBytes), checkedStatus(hw.memoryBytes, p.insufficientMemory))
+	fmt.Fprintf(w, "Disk\t%s\t%s\t%s\n", formatBytes(p.requiredDiskBytes), formatBytes(hw.diskBytes), checkedStatus(hw.diskBytes, p.insufficientDisk))
+	fmt.Fprintf(w, "RPC calls\t%.1f/s\t-\t-\n", p.rpcCallsPerSecond)
+	fmt.Fprintf(w, "Worst case latency\t%v\t%v\t%s\n", formatDuration(p.worstCaseResponseLatency), formatDuration(p.challengePeriod), status(p.latencyExceedsChalPeriod))
+	fmt.Fprintf(w, "Total compute\t%v\t\t\n", formatDuration(p.totalComputeTime))
+	return w.Flush()
+}
+
+func status(insufficient bool) string {
+	if insufficient {
+		return "INSUFFICIENT"
+	}
+	return "OK"
+}
+
+// Resources of which the available amount was not provided are not checked.
+func checkedStatus(available uint64, insufficient bool) string {
+	if available == 0 {
+		return "-"
+	}
+	return status(insufficient)
+}
+
+func formatBytes(b uint64) string {
+	if b == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f GB", float64(b)/gb)
+}
+
+// Rounds durations to a precision useful for planning.
+func formatDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,223 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+)
+
+const (
+	// Approximate number of RPC calls made every block to scan for new assertions and edges,
+	// regardless of how many edges are tracked.
+	rpcCallsPerBlockBase = 6
+	// Approximate number of RPC calls made every block by each edge tracker, to refresh the
+	// status and timers of its edge.
+	rpcCallsPerTrackedEdgePerBlock = 3
+	// Approximate size of an edge in the API database, including its indices.
+	dbBytesPerEdge = 2 << 10
+	// The validator needs to complete its moves well within the challenge period, so we only
+	// budget this fraction of it for computation.
+	challengePeriodBudget = 0.5
+	// Memory used by the validator process besides the machines it executes.
+	baseMemoryBytes = 1 << 30
+)
+
+// The parameters of the chain whose challenges a validator takes part in.
+type chainParams struct {
+	blockTime             time.Duration
+	heights               protocol.LayerZeroHeights
+	numBigStepLevels      uint8
+	challengePeriodBlocks uint64
+	// The number of adversaries, each of which can force the honest validator down a
+	// different path of the challenge by disagreeing with it at a different point.
+	adversaries uint64
+}
+
+// The resources available to a validator.
+type hardwareSpecs struct {
+	cores                 uint64
+	memoryBytes           uint64
+	diskBytes             uint64
+	machineStepsPerSecond float64
+	machineMemoryBytes    uint64
+}
+
+// Measured costs of the commitment and proof pipelines.
+type benchmarks struct {
+	commitmentPerLeaf  time.Duration
+	prefixProofPerLeaf time.Duration
+}
+
+// The projected requirements at a single challenge level.
+type levelPlan struct {
+	name string
+	// The number of leaves committed to by an edge at this level.
+	leaves uint64
+	// The number of machine steps executed to compute a commitment at this level.
+	machineSteps float64
+	// The number of moves made by the honest validator on a single path at this level.
+	moves uint64
+	// The time to compute a single move with a core to itself.
+	moveTime time.Duration
+	// The time to respond once every adversary makes a move at this level at once.
+	worstCaseLatency time.Duration
+}
+
+type plan struct {
+	levels                   []*levelPlan
+	honestPaths              uint64
+	totalComputeTime         time.Duration
+	requiredCores            uint64
+	requiredMemoryBytes      uint64
+	requiredDiskBytes        uint64
+	rpcCallsPerSecond        float64
+	worstCaseResponseLatency time.Duration
+	challengePeriod          time.Duration
+	oneStepProofMachineSteps float64
+	oneStepProofTime         time.Duration
+	insufficientCores        bool
+	insufficientMemory       bool
+	insufficientDisk         bool
+	latencyExceedsChalPeriod bool
+	concurrentMachines       uint64
+}
+
+func (c *chainParams) validate() error {
+	if c.blockTime <= 0 {
+		return errors.New("block time must be positive")
+	}
+	if c.challengePeriodBlocks == 0 {
+		return errors.New("challenge period must be positive")
+	}
+	for _, h := range []uint64{c.heights.BlockChallengeHeight, c.heights.BigStepChallengeHeight, c.heights.SmallStepChallengeHeight} {
+		if h < 2 || h&(h-1) != 0 {
+			return fmt.Errorf("layer zero height %d is not a power of two greater than one", h)
+		}
+	}
+	return nil
+}
+
+func (h *hardwareSpecs) validate() error {
+	if h.cores == 0 {
+		return errors.New("need at least one core")
+	}
+	if h.machineStepsPerSecond <= 0 {
+		return errors.New("machine steps per second must be positive")
+	}
+	return nil
+}
+
+// Heights of each challenge level, from the block challenge down to the small step challenge.
+func (c *chainParams) levelHeights() []uint64 {
+	heights := []uint64{c.heights.BlockChallengeHeight}
+	for i := uint8(0); i < c.numBigStepLevels; i++ {
+		heights = append(heights, c.heights.BigStepChallengeHeight)
+	}
+	return append(heights, c.heights.SmallStepChallengeHeight)
+}
+
+func levelName(level, numLevels int) string {
+	switch {
+	case level == 0:
+		return "block"
+	case level == numLevels-1:
+		return "small step"
+	default:
+		return fmt.Sprintf("big step %d", level)
+	}
+}
+
+// computePlan projects the requirements of a validator taking part in the worst case challenge on a
+// chain. Every adversary can force the honest validator down its own path through the challenge, by
+// disagreeing with it at a different point, and the honest validator must make every move on each
+// of these paths. Commitments at the block level are over block hashes, which the validator reads
+// from its node, while those at lower levels need a machine to be stepped through. The time to hash
+// commitments and generate proofs comes from benchmarks, while the time to step through machines
+// is derived from the expected machine throughput of the hardware.
+func computePlan(chain *chainParams, hw *hardwareSpecs, bench *benchmarks) (*plan, error) {
+	if err := chain.validate(); err != nil {
+		return nil, err
+	}
+	if err := hw.validate(); err != nil {
+		return nil, err
+	}
+	heights := chain.levelHeights()
+	p := &plan{
+		honestPaths:     max(chain.adversaries, 1),
+		challengePeriod: chain.blockTime * time.Duration(chain.challengePeriodBlocks),
+	}
+	// Moves on different paths are computed concurrently, at most one per core.
+	rounds := (p.honestPaths + hw.cores - 1) / hw.cores
+	p.concurrentMachines = min(p.honestPaths, hw.cores)
+
+	var totalCompute float64
+	var trackedEdges uint64
+	var maxLeaves uint64
+	for i, h := range heights {
+		// A machine level commitment has a leaf for every step size of machine steps, where the
+		// step size is the number of steps committed to by an edge at the level below.
+		var machineSteps float64
+		if i > 0 {
+			machineSteps = 1
+			for _, below := range heights[i:] {
+				machineSteps *= float64(below)
+			}
+		}
+		leaves := h + 1
+		hashTime := time.Duration(leaves) * (bench.commitmentPerLeaf + bench.prefixProofPerLeaf)
+		moveTime := hashTime + secondsToDuration(machineSteps/hw.machineStepsPerSecond)
+		// Opening the level zero edge, and then bisecting it down to a one step fork.
+		moves := uint64(bits.TrailingZeros64(h)) + 1
+		lvl := &levelPlan{
+			name:             levelName(i, len(heights)),
+			leaves:           leaves,
+			machineSteps:     machineSteps,
+			moves:            moves,
+			moveTime:         moveTime,
+			worstCaseLatency: moveTime * time.Duration(rounds),
+		}
+		p.levels = append(p.levels, lvl)
+		totalCompute += float64(p.honestPaths*moves) * moveTime.Seconds()
+		// Every bisection creates two edges, which are tracked until the challenge ends.
+		trackedEdges += p.honestPaths * (2*(moves-1) + 1)
+		maxLeaves = max(maxLeaves, leaves)
+		p.worstCaseResponseLatency = max(p.worstCaseResponseLatency, lvl.worstCaseLatency)
+	}
+	// Collecting a one step proof requires stepping the machine to the disputed step.
+	p.oneStepProofMachineSteps = 1
+	for _, h := range heights[1:] {
+		p.oneStepProofMachineSteps *= float64(h)
+	}
+	p.oneStepProofTime = secondsToDuration(p.oneStepProofMachineSteps / hw.machineStepsPerSecond)
+	totalCompute += float64(p.honestPaths) * p.oneStepProofTime.Seconds()
+	p.totalComputeTime = secondsToDuration(totalCompute)
+
+	budget := p.challengePeriod.Seconds() * challengePeriodBudget
+	p.requiredCores = max(uint64(math.Ceil(totalCompute/budget)), 1)
+	// Each concurrently computed commitment holds its leaves and their Merkle expansion in memory.
+	p.requiredMemoryBytes = baseMemoryBytes +
+		p.concurrentMachines*(hw.machineMemoryBytes+maxLeaves*2*32)
+	// Besides the edges in the database, commitments are journaled to disk as they are computed.
+	p.requiredDiskBytes = trackedEdges*dbBytesPerEdge + p.concurrentMachines*maxLeaves*32
+	p.rpcCallsPerSecond = float64(rpcCallsPerBlockBase+rpcCallsPerTrackedEdgePerBlock*trackedEdges) / chain.blockTime.Seconds()
+
+	p.insufficientCores = hw.cores < p.requiredCores
+	p.insufficientMemory = hw.memoryBytes != 0 && hw.memoryBytes < p.requiredMemoryBytes
+	p.insufficientDisk = hw.diskBytes != 0 && hw.diskBytes < p.requiredDiskBytes
+	p.latencyExceedsChalPeriod = p.worstCaseResponseLatency > p.challengePeriod
+	return p, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
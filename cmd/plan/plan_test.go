@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputePlan(t *testing.T) {
+	chain := &chainParams{
+		blockTime: 12 * time.Second,
+		heights: protocol.LayerZeroHeights{
+			BlockChallengeHeight:     1 << 4,
+			BigStepChallengeHeight:   1 << 3,
+			SmallStepChallengeHeight: 1 << 2,
+		},
+		numBigStepLevels:      1,
+		challengePeriodBlocks: 100,
+		adversaries:           3,
+	}
+	hw := &hardwareSpecs{
+		cores:                 2,
+		machineStepsPerSecond: 1,
+		machineMemoryBytes:    1000,
+	}
+	bench := &benchmarks{
+		commitmentPerLeaf:  time.Millisecond,
+		prefixProofPerLeaf: time.Millisecond,
+	}
+	p, err := computePlan(chain, hw, bench)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), p.honestPaths)
+	require.Len(t, p.levels, 3)
+
+	// Block level: no machine steps, 17 leaves, and 5 moves.
+	require.Equal(t, "block", p.levels[0].name)
+	require.Equal(t, float64(0), p.levels[0].machineSteps)
+	require.Equal(t, uint64(5), p.levels[0].moves)
+	require.Equal(t, 34*time.Millisecond, p.levels[0].moveTime)
+	// Three paths on two cores take two rounds.
+	require.Equal(t, 68*time.Millisecond, p.levels[0].worstCaseLatency)
+
+	// Big step level: each of its 8 leaves spans the 4 steps of a small step edge.
+	require.Equal(t, "big step 1", p.levels[1].name)
+	require.Equal(t, float64(32), p.levels[1].machineSteps)
+	require.Equal(t, uint64(4), p.levels[1].moves)
+	require.Equal(t, 32*time.Second+18*time.Millisecond, p.levels[1].moveTime)
+
+	require.Equal(t, "small step", p.levels[2].name)
+	require.Equal(t, float64(4), p.levels[2].machineSteps)
+	require.Equal(t, uint64(3), p.levels[2].moves)
+	require.Equal(t, 4*time.Second+10*time.Millisecond, p.levels[2].moveTime)
+
+	require.Equal(t, float64(32), p.oneStepProofMachineSteps)
+	require.Equal(t, 2*(32*time.Second+18*time.Millisecond), p.worstCaseResponseLatency)
+
+	// 3 paths * (5*34ms + 4*32.018s + 3*4.01s + 32s) of compute, within half of a 1200s period.
+	require.Equal(t, 3*(170*time.Millisecond+128072*time.Millisecond+12030*time.Millisecond+32*time.Second), p.totalComputeTime)
+	require.Equal(t, uint64(1), p.requiredCores)
+	require.False(t, p.insufficientCores)
+
+	// Two machines at once, each with the expansion of the largest commitment.
+	require.Equal(t, uint64(baseMemoryBytes+2*(1000+17*64)), p.requiredMemoryBytes)
+	// 3 paths * (9 + 7 + 5) edges.
+	require.Equal(t, uint64(63*dbBytesPerEdge+2*17*32), p.requiredDiskBytes)
+	require.Equal(t, float64(rpcCallsPerBlockBase+3*63)/12, p.rpcCallsPerSecond)
+	require.True(t, p.sufficient())
+
+	hw.cores = 1
+	hw.memoryBytes = 1 << 20
+	p, err = computePlan(chain, hw, bench)
+	require.NoError(t, err)
+	require.True(t, p.insufficientMemory)
+	require.False(t, p.insufficientDisk)
+	require.Equal(t, 3*(32*time.Second+18*time.Millisecond), p.worstCaseResponseLatency)
+
+	chain.heights.BigStepChallengeHeight = 6
+	_, err = computePlan(chain, hw, bench)
+	require.ErrorContains(t, err, "not a power of two")
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{
+		"-json",
+		"-block-challenge-height", "32",
+		"-big-step-challenge-height", "16",
+		"-small-step-challenge-height", "8",
+		"-big-step-levels", "2",
+		"-benchmark-leaves", "64",
+	}, &out)
+	require.NoError(t, err)
+	var report jsonReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Levels, 4)
+	require.Equal(t, "big step 2", report.Levels[2].Name)
+	require.Equal(t, float64(16*16*8), report.OneStepProofMachineSteps)
+	require.NotEqual(t, "0s", report.CommitmentPerLeaf)
+
+	out.Reset()
+	require.NoError(t, run([]string{"-commitment-per-leaf", "1us", "-prefix-proof-per-leaf", "1us"}, &out))
+	require.Contains(t, out.String(), "Worst case latency")
+}
@@ -16,7 +16,6 @@ type Producer[T any] struct {
 	sync.RWMutex
 	subscriptionBufferSize int
 	subs                   []*Subscription[T]
-	doneListener           chan subId    // channel to listen for IDs of subscriptions to be remove.
 	broadcastTimeout       time.Duration // maximum duration to wait for an event to be sent.
 }
 
@@ -41,7 +40,6 @@ func NewProducer[T any](opts ...ProducerOpt[T]) *Producer[T] {
 	producer := &Producer[T]{
 		subs:                   make([]*Subscription[T], 0),
 		subscriptionBufferSize: defaultSubscriptionBufferSize,
-		doneListener:           make(chan subId, 100),
 		broadcastTimeout:       defaultBroadcastTimeout,
 	}
 	for _, opt := range opts {
@@ -50,26 +48,12 @@ func NewProducer[T any](opts ...ProducerOpt[T]) *Producer[T] {
 	return producer
 }
 
-// Start begins listening for subscription cancelation requests or context cancelation.
+// Start clears all subscriptions once the context is canceled.
 func (ep *Producer[T]) Start(ctx context.Context) {
-	for {
-		select {
-		case id := <-ep.doneListener:
-			ep.Lock()
-			// Check if id overflows the length of the slice.
-			if int(id) >= len(ep.subs) {
-				ep.Unlock()
-				continue
-			}
-			// Otherwise, clear the subscription from the list.
-			ep.subs = append(ep.subs[:id], ep.subs[id+1:]...)
-			ep.Unlock()
-		case <-ctx.Done():
-			close(ep.doneListener)
-			ep.subs = nil
-			return
-		}
-	}
+	<-ctx.Done()
+	ep.Lock()
+	ep.subs = nil
+	ep.Unlock()
 }
 
 // Subscribe returns a handle to a new event subscription,
@@ -78,9 +62,8 @@ func (ep *Producer[T]) Subscribe() *Subscription[T] {
 	ep.Lock()
 	defer ep.Unlock()
 	sub := &Subscription[T]{
-		id:     subId(len(ep.subs)), // Assign a unique ID based on the current count of subscriptions
-		events: make(chan T),
-		done:   ep.doneListener,
+		events:   make(chan T),
+		producer: ep,
 	}
 	ep.subs = append(ep.subs, sub)
 	return sub
@@ -104,27 +87,37 @@ func (ep *Producer[T]) Broadcast(ctx context.Context, event T) {
 	}
 }
 
-type subId uint64
-
 // Subscription defines a generic handle to a subscription of
 // events from a producer.
 type Subscription[T any] struct {
-	id     subId
-	events chan T
-	done   chan subId
+	events   chan T
+	producer *Producer[T]
 }
 
 // Next waits for the next event or context cancelation, returning the event or an error.
+// The subscription is removed from its producer once the context is canceled.
 func (es *Subscription[T]) Next(ctx context.Context) (T, bool) {
 	var zeroVal T
-	for {
-		select {
-		case ev := <-es.events:
-			return ev, false
-		case <-ctx.Done():
-			es.done <- es.id
-			close(es.events)
-			return zeroVal, true
+	select {
+	case ev := <-es.events:
+		return ev, false
+	case <-ctx.Done():
+		es.Unsubscribe()
+		return zeroVal, true
+	}
+}
+
+// Unsubscribe removes the subscription from its producer, so that events are no longer
+// broadcast to it. Subscribers that stop calling Next must unsubscribe, or every broadcast
+// would wait on them until the broadcast timeout. Safe to call more than once.
+func (es *Subscription[T]) Unsubscribe() {
+	ep := es.producer
+	ep.Lock()
+	defer ep.Unlock()
+	for i, sub := range ep.subs {
+		if sub == es {
+			ep.subs = append(ep.subs[:i], ep.subs[i+1:]...)
+			return
 		}
 	}
 }
@@ -64,3 +64,21 @@ func TestEventProducer_Start(t *testing.T) {
 		t.Error("Expected to end after context cancellation")
 	}
 }
+
+func TestUnsubscribe(t *testing.T) {
+	producer := NewProducer[int]()
+	first := producer.Subscribe()
+	second := producer.Subscribe()
+	third := producer.Subscribe()
+
+	second.Unsubscribe()
+	require.Equal(t, []*Subscription[int]{first, third}, producer.subs)
+	second.Unsubscribe()
+	require.Equal(t, []*Subscription[int]{first, third}, producer.subs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, shouldEnd := first.Next(ctx)
+	require.True(t, shouldEnd)
+	require.Equal(t, []*Subscription[int]{third}, producer.subs)
+}
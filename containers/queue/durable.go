@@ -0,0 +1,321 @@
+// Package queue defines a generic, durable FIFO queue that can optionally be backed
+// by an append-only file on disk, allowing unprocessed items to be replayed after a restart.
+//
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var errQueueClosed = errors.New("queue is closed")
+
+type op uint8
+
+const (
+	opPush op = iota
+	opAck
+)
+
+// A single entry in the append-only journal of the queue.
+type record[T any] struct {
+	Op   op `json:"op"`
+	Item T  `json:"item,omitempty"`
+}
+
+// Durable is a threadsafe FIFO queue. Items are only removed once they are acknowledged,
+// so a consumer can peek at an item, retry processing it as many times as needed,
+// and then acknowledge it. If the queue is backed by a file, every push and
+// acknowledgement is journaled, pushes being synced to disk before they return, and
+// unacknowledged items are replayed when the queue is reopened. The journal is truncated whenever the queue is drained, and compacted to the
+// unacknowledged items once enough acknowledgements have accumulated, so it does not grow without
+// bound under a consumer which never quite catches up.
+type Durable[T any] struct {
+	lock                sync.Mutex
+	items               []T
+	journalPath         string
+	file                *os.File
+	closed              bool
+	journaledAcks       int
+	compactionThreshold int
+	notify              chan struct{}
+	sizeGauge           metrics.Gauge
+}
+
+type Opt[T any] func(*Durable[T])
+
+// WithJournal backs the queue with an append-only file at the given path.
+func WithJournal[T any](path string) Opt[T] {
+	return func(q *Durable[T]) {
+		q.journalPath = path
+	}
+}
+
+// WithCompactionThreshold sets the number of acknowledgements journaled since the journal was last
+// rewritten from which it is compacted. It is only compacted once they also outnumber the pending
+// items, so that rewriting a long queue costs no more than the acknowledgements it removes.
+// Defaults to 1024.
+func WithCompactionThreshold[T any](acks int) Opt[T] {
+	return func(q *Durable[T]) {
+		q.compactionThreshold = acks
+	}
+}
+
+// WithMetric reports the number of pending items in the queue under the given name.
+func WithMetric[T any](name string) Opt[T] {
+	return func(q *Durable[T]) {
+		q.sizeGauge = metrics.GetOrRegisterGauge("arb/validator/queue/"+name+"/size", nil)
+	}
+}
+
+// NewDurable creates a queue, replaying any unacknowledged items from its journal if configured.
+func NewDurable[T any](opts ...Opt[T]) (*Durable[T], error) {
+	q := &Durable[T]{
+		items:               make([]T, 0),
+		notify:              make(chan struct{}, 1),
+		compactionThreshold: 1024,
+	}
+	for _, o := range opts {
+		o(q)
+	}
+	if q.journalPath != "" {
+		f, err := os.OpenFile(q.journalPath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not open queue journal")
+		}
+		q.file = f
+		if err := q.replay(); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	q.updateMetric()
+	return q, nil
+}
+
+// Push appends an item to the back of the queue.
+func (q *Durable[T]) Push(item T) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return errQueueClosed
+	}
+	if err := q.journal(record[T]{Op: opPush, Item: item}); err != nil {
+		return err
+	}
+	if q.file != nil {
+		if err := q.file.Sync(); err != nil {
+			return errors.Wrap(err, "could not sync queue journal")
+		}
+	}
+	q.items = append(q.items, item)
+	q.updateMetric()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Peek returns the item at the front of the queue without removing it.
+func (q *Durable[T]) Peek() option.Option[T] {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.items) == 0 {
+		return option.None[T]()
+	}
+	return option.Some(q.items[0])
+}
+
+// Ack removes the item at the front of the queue once it has been processed.
+func (q *Durable[T]) Ack() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return errQueueClosed
+	}
+	if len(q.items) == 0 {
+		return errors.New("no item to acknowledge")
+	}
+	if len(q.items) == 1 && q.file != nil {
+		// The queue is drained, so the journal no longer needs to be replayed.
+		if err := q.file.Truncate(0); err != nil {
+			return errors.Wrap(err, "could not truncate queue journal")
+		}
+		q.journaledAcks = 0
+	} else if q.file != nil {
+		if err := q.journal(record[T]{Op: opAck}); err != nil {
+			return err
+		}
+		q.journaledAcks++
+	}
+	var zero T
+	q.items[0] = zero
+	q.items = q.items[1:]
+	q.updateMetric()
+	if q.file != nil && q.journaledAcks >= q.compactionThreshold && q.journaledAcks >= len(q.items) {
+		// The acknowledgement is already journaled, so a failure only leaves the journal longer
+		// than needed, and compacting is attempted again on the next acknowledgement.
+		if err := q.compact(); err != nil {
+			log.Warn("Could not compact queue journal", "path", q.journalPath, "err", err)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of unacknowledged items in the queue.
+func (q *Durable[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.items)
+}
+
+// Items returns a copy of the unacknowledged items in the queue, from front to back.
+func (q *Durable[T]) Items() []T {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	items := make([]T, len(q.items))
+	copy(items, q.items)
+	return items
+}
+
+// Notify returns a channel which receives a value whenever items are pushed to the queue.
+func (q *Durable[T]) Notify() <-chan struct{} {
+	return q.notify
+}
+
+// Close the journal backing the queue, if any, after which items can no longer be pushed or
+// acknowledged. Closing more than once has no effect.
+func (q *Durable[T]) Close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	if q.file == nil {
+		return nil
+	}
+	return q.file.Close()
+}
+
+func (q *Durable[T]) journal(r record[T]) error {
+	if q.file == nil {
+		return nil
+	}
+	enc, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "could not encode queue record")
+	}
+	if _, err = q.file.Write(append(enc, '\n')); err != nil {
+		return errors.Wrap(err, "could not write queue record")
+	}
+	return nil
+}
+
+// Rewrites the journal to push only the pending items. The new journal is written in full before it
+// replaces the old one, so that a crash while compacting replays either of them. Must be called
+// with the lock held.
+func (q *Durable[T]) compact() error {
+	tmp := q.journalPath + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, item := range q.items {
+		enc, err := json.Marshal(record[T]{Op: opPush, Item: item})
+		if err != nil {
+			_ = f.Close()
+			return errors.Wrap(err, "could not encode queue record")
+		}
+		if _, err = w.Write(append(enc, '\n')); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = os.Rename(tmp, q.journalPath); err != nil {
+		_ = f.Close()
+		return err
+	}
+	// The new journal stays open to append to, as it is now the file at the journal path.
+	_ = q.file.Close()
+	q.file = f
+	q.journaledAcks = 0
+	return nil
+}
+
+// Replays the journal. A final record cut short, such as by a crash while it was written, was never
+// pushed or acknowledged, so it is truncated away. Any other record which cannot be decoded means
+// the journal is corrupt.
+func (q *Durable[T]) replay() error {
+	if _, err := q.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(q.file)
+	offset := int64(0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				log.Warn("Truncating record cut short at the end of queue journal", "path", q.journalPath, "offset", offset, "size", len(line))
+				if err = q.file.Truncate(offset); err != nil {
+					return errors.Wrap(err, "could not truncate queue journal")
+				}
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+		var r record[T]
+		if err = json.Unmarshal(bytes.TrimSuffix(line, []byte{'\n'}), &r); err != nil {
+			return errors.Wrapf(err, "could not decode queue record ending at offset %d", offset)
+		}
+		switch r.Op {
+		case opPush:
+			q.items = append(q.items, r.Item)
+		case opAck:
+			if len(q.items) == 0 {
+				return errors.New("queue journal acknowledges more items than were pushed")
+			}
+			q.items = q.items[1:]
+			q.journaledAcks++
+		default:
+			return errors.Errorf("unknown queue journal op %d", r.Op)
+		}
+	}
+	if len(q.items) > 0 {
+		select {
+		case q.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (q *Durable[T]) updateMetric() {
+	if q.sizeGauge != nil {
+		q.sizeGauge.Update(int64(len(q.items)))
+	}
+}
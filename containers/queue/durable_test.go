@@ -0,0 +1,158 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurable_InMemory(t *testing.T) {
+	q, err := NewDurable[int]()
+	require.NoError(t, err)
+	require.True(t, q.Peek().IsNone())
+	require.ErrorContains(t, q.Ack(), "no item")
+
+	require.NoError(t, q.Push(1))
+	require.NoError(t, q.Push(2))
+	select {
+	case <-q.Notify():
+	default:
+		t.Fatal("expected a notification after pushing")
+	}
+	require.Equal(t, 2, q.Len())
+
+	// Peeking does not remove items until they are acknowledged.
+	require.Equal(t, 1, q.Peek().Unwrap())
+	require.Equal(t, 1, q.Peek().Unwrap())
+	require.NoError(t, q.Ack())
+	require.Equal(t, 2, q.Peek().Unwrap())
+	require.NoError(t, q.Ack())
+	require.Equal(t, 0, q.Len())
+}
+
+type item struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+func TestDurable_ReplaysUnacknowledgedItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.journal")
+	q, err := NewDurable[*item](WithJournal[*item](path))
+	require.NoError(t, err)
+	require.NoError(t, q.Push(&item{Name: "a", Value: 1}))
+	require.NoError(t, q.Push(&item{Name: "b", Value: 2}))
+	require.NoError(t, q.Push(&item{Name: "c", Value: 3}))
+	require.NoError(t, q.Ack())
+	require.NoError(t, q.Close())
+
+	reopened, err := NewDurable[*item](WithJournal[*item](path))
+	require.NoError(t, err)
+	require.Equal(t, 2, reopened.Len())
+	require.Equal(t, []*item{{Name: "b", Value: 2}, {Name: "c", Value: 3}}, reopened.Items())
+	select {
+	case <-reopened.Notify():
+	default:
+		t.Fatal("expected a notification for replayed items")
+	}
+	require.Equal(t, &item{Name: "b", Value: 2}, reopened.Peek().Unwrap())
+	require.NoError(t, reopened.Ack())
+	require.Equal(t, &item{Name: "c", Value: 3}, reopened.Peek().Unwrap())
+	require.NoError(t, reopened.Ack())
+
+	// Draining the queue truncates its journal.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), info.Size())
+	require.NoError(t, reopened.Push(&item{Name: "d", Value: 4}))
+	require.NoError(t, reopened.Close())
+
+	reopened, err = NewDurable[*item](WithJournal[*item](path))
+	require.NoError(t, err)
+	require.Equal(t, 1, reopened.Len())
+	require.Equal(t, &item{Name: "d", Value: 4}, reopened.Peek().Unwrap())
+	require.NoError(t, reopened.Close())
+}
+
+func TestDurable_CompactsJournalWithoutDraining(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.journal")
+	journalLines := func() int {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+	q, err := NewDurable[int](WithJournal[int](path), WithCompactionThreshold[int](3))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, q.Push(i))
+	}
+	// Acknowledgements are journaled until they outnumber the pending items.
+	for i := 0; i < 4; i++ {
+		require.NoError(t, q.Ack())
+	}
+	require.Equal(t, 14, journalLines())
+	require.NoError(t, q.Ack())
+	require.Equal(t, 5, journalLines())
+
+	// The compacted journal is appended to, and replays the pending items.
+	require.NoError(t, q.Push(10))
+	require.NoError(t, q.Ack())
+	require.Equal(t, 7, journalLines())
+	require.NoError(t, q.Close())
+
+	reopened, err := NewDurable[int](WithJournal[int](path), WithCompactionThreshold[int](3))
+	require.NoError(t, err)
+	require.Equal(t, []int{6, 7, 8, 9, 10}, reopened.Items())
+
+	// Acknowledgements replayed from the journal count towards the next compaction.
+	require.NoError(t, reopened.Ack())
+	require.Equal(t, 8, journalLines())
+	require.NoError(t, reopened.Ack())
+	require.Equal(t, 3, journalLines())
+	require.Equal(t, []int{8, 9, 10}, reopened.Items())
+	require.NoError(t, reopened.Close())
+}
+
+func TestDurable_RecoversFromTornFinalRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.journal")
+	q, err := NewDurable[*item](WithJournal[*item](path))
+	require.NoError(t, err)
+	require.NoError(t, q.Push(&item{Name: "a", Value: 1}))
+	require.NoError(t, q.Push(&item{Name: "b", Value: 2}))
+	require.NoError(t, q.Close())
+	require.NoError(t, q.Close())
+	require.ErrorContains(t, q.Push(&item{Name: "c", Value: 3}), "closed")
+	require.ErrorContains(t, q.Ack(), "closed")
+	intact, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// A crash while pushing leaves the last record cut short.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":0,"item":{"na`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened, err := NewDurable[*item](WithJournal[*item](path))
+	require.NoError(t, err)
+	require.Equal(t, []*item{{Name: "a", Value: 1}, {Name: "b", Value: 2}}, reopened.Items())
+	truncated, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, intact, truncated)
+	require.NoError(t, reopened.Push(&item{Name: "c", Value: 3}))
+	require.NoError(t, reopened.Close())
+	reopened, err = NewDurable[*item](WithJournal[*item](path))
+	require.NoError(t, err)
+	require.Equal(t, 3, reopened.Len())
+	require.NoError(t, reopened.Close())
+
+	// A record which cannot be decoded before the end of the journal is corruption.
+	require.NoError(t, os.WriteFile(path, append([]byte("garbage\n"), intact...), 0600))
+	_, err = NewDurable[*item](WithJournal[*item](path))
+	require.ErrorContains(t, err, "could not decode queue record")
+}
@@ -0,0 +1,289 @@
+// Package workerpool defines a pool bounding the number of concurrent jobs of some kind, such as
+// computing history commitments, which grows when jobs back up and shrinks when idle.
+//
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+package workerpool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/OffchainLabs/bold/util/stopwaiter"
+	gethmetrics "github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+// LoadFunc reports the current CPU utilization as a fraction between 0 and 1.
+type LoadFunc func() float64
+
+// Pool limits the number of jobs running concurrently. Once started, it periodically rescales its
+// limit between a minimum and a maximum number of workers: it adds a worker when jobs have been
+// waiting for a slot for a number of consecutive intervals and the CPU has headroom, and removes
+// one when fewer jobs than workers have been running for a (larger) number of consecutive intervals.
+// Requiring several intervals in a row, and resetting the count after every change, keeps the
+// pool from thrashing during bursts of jobs. A pool which is not started runs at its minimum size.
+type Pool struct {
+	stopwaiter.StopWaiter
+	lock           sync.Mutex
+	minWorkers     int
+	maxWorkers     int
+	limit          int
+	active         int
+	peakActive     int
+	sawWaiters     bool
+	waiters        []chan struct{}
+	scaleInterval  time.Duration
+	scaleUpAfter   int
+	scaleDownAfter int
+	backedUpFor    int
+	idleFor        int
+	maxLoad        float64
+	load           LoadFunc
+	sizeGauge      gethmetrics.Gauge
+	waitingGauge   gethmetrics.Gauge
+}
+
+type Opt func(*Pool)
+
+// WithScaleInterval sets how often the pool considers rescaling.
+func WithScaleInterval(d time.Duration) Opt {
+	return func(p *Pool) {
+		p.scaleInterval = d
+	}
+}
+
+// WithHysteresis sets the number of consecutive intervals the pool must be backed up before
+// growing, and idle before shrinking.
+func WithHysteresis(scaleUpAfter, scaleDownAfter int) Opt {
+	return func(p *Pool) {
+		p.scaleUpAfter = scaleUpAfter
+		p.scaleDownAfter = scaleDownAfter
+	}
+}
+
+// WithMaxLoad sets the CPU utilization, between 0 and 1, above which the pool does not grow.
+func WithMaxLoad(maxLoad float64) Opt {
+	return func(p *Pool) {
+		p.maxLoad = maxLoad
+	}
+}
+
+// WithLoadFunc overrides how the pool measures CPU utilization. By default, it uses the share of
+// the time of all CPUs spent working by any process since the last measurement.
+func WithLoadFunc(load LoadFunc) Opt {
+	return func(p *Pool) {
+		p.load = load
+	}
+}
+
+// WithMetrics reports the size of the pool and the number of waiting jobs under the given name.
+func WithMetrics(name string) Opt {
+	return func(p *Pool) {
+		p.sizeGauge = gethmetrics.GetOrRegisterGauge("arb/validator/workerpool/"+name+"/size", nil)
+		p.waitingGauge = gethmetrics.GetOrRegisterGauge("arb/validator/workerpool/"+name+"/waiting", nil)
+	}
+}
+
+// New creates a pool running between minWorkers and maxWorkers jobs at a time.
+func New(minWorkers, maxWorkers int, opts ...Opt) (*Pool, error) {
+	if minWorkers < 1 {
+		return nil, errors.New("worker pool needs at least one worker")
+	}
+	if maxWorkers < minWorkers {
+		return nil, errors.Errorf("max workers %d is less than min workers %d", maxWorkers, minWorkers)
+	}
+	p := &Pool{
+		minWorkers:     minWorkers,
+		maxWorkers:     maxWorkers,
+		limit:          minWorkers,
+		scaleInterval:  time.Second * 5,
+		scaleUpAfter:   2,
+		scaleDownAfter: 12,
+		maxLoad:        0.8,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	if p.load == nil {
+		p.load = newCPULoadSampler().load
+	}
+	p.updateMetrics()
+	return p, nil
+}
+
+// Start rescales the pool every scale interval until the context is done.
+func (p *Pool) Start(ctx context.Context) {
+	p.StopWaiter.Start(ctx, p)
+	p.LaunchThread(func(ctx context.Context) {
+		ticker := time.NewTicker(p.scaleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.rescale()
+			}
+		}
+	})
+}
+
+// Run runs a job once a worker is available. A nil pool runs the job right away.
+func (p *Pool) Run(ctx context.Context, job func() error) error {
+	if p == nil {
+		return job()
+	}
+	if err := p.acquire(ctx); err != nil {
+		return err
+	}
+	defer p.release()
+	return job()
+}
+
+// Size returns the number of jobs the pool currently lets run concurrently.
+func (p *Pool) Size() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.limit
+}
+
+func (p *Pool) acquire(ctx context.Context) error {
+	p.lock.Lock()
+	if p.active < p.limit && len(p.waiters) == 0 {
+		p.active++
+		p.peakActive = max(p.peakActive, p.active)
+		p.lock.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	p.waiters = append(p.waiters, ready)
+	p.sawWaiters = true
+	p.updateMetrics()
+	p.lock.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		for i, w := range p.waiters {
+			if w == ready {
+				p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+				p.updateMetrics()
+				return ctx.Err()
+			}
+		}
+		// The job was handed a worker just as its context was cancelled, so we give it back.
+		p.active--
+		p.dispatch()
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) release() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.active--
+	p.dispatch()
+}
+
+// Hands free workers to waiting jobs in the order they arrived. Must be called with the lock held.
+func (p *Pool) dispatch() {
+	for p.active < p.limit && len(p.waiters) > 0 {
+		ready := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		p.active++
+		p.peakActive = max(p.peakActive, p.active)
+		close(ready)
+	}
+	p.updateMetrics()
+}
+
+func (p *Pool) rescale() {
+	p.lock.Lock()
+	backedUp := p.sawWaiters || len(p.waiters) > 0
+	idle := !backedUp && p.peakActive < p.limit
+	p.sawWaiters = false
+	p.peakActive = p.active
+	p.lock.Unlock()
+
+	// The load is measured without holding the lock, as it is the only part which may be slow.
+	if backedUp {
+		p.idleFor = 0
+		p.backedUpFor++
+		if p.backedUpFor >= p.scaleUpAfter && p.load() < p.maxLoad {
+			p.resize(1)
+		}
+		return
+	}
+	p.backedUpFor = 0
+	if idle {
+		p.idleFor++
+		if p.idleFor >= p.scaleDownAfter {
+			p.resize(-1)
+		}
+		return
+	}
+	p.idleFor = 0
+}
+
+func (p *Pool) resize(delta int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.backedUpFor = 0
+	p.idleFor = 0
+	p.limit = min(max(p.limit+delta, p.minWorkers), p.maxWorkers)
+	// Shrinking does not interrupt running jobs, it only holds back new ones until enough finish.
+	p.dispatch()
+}
+
+// Must be called with the lock held, or before the pool is shared.
+func (p *Pool) updateMetrics() {
+	if p.sizeGauge != nil {
+		p.sizeGauge.Update(int64(p.limit))
+	}
+	if p.waitingGauge != nil {
+		p.waitingGauge.Update(int64(len(p.waiters)))
+	}
+}
+
+// Measures the share of the time of all CPUs which was spent working, by any process, since the
+// previous measurement, as reported by the operating system. Unlike the CPU classes of the Go
+// runtime, which are only brought up to date at garbage collections, this is current whenever it is
+// read.
+type cpuLoadSampler struct {
+	lock     sync.Mutex
+	read     func(*gethmetrics.CPUStats)
+	now      func() time.Time
+	numCPU   int
+	lastBusy float64
+	lastAt   time.Time
+}
+
+func newCPULoadSampler() *cpuLoadSampler {
+	return &cpuLoadSampler{
+		read:   gethmetrics.ReadCPUStats,
+		now:    time.Now,
+		numCPU: runtime.NumCPU(),
+	}
+}
+
+func (s *cpuLoadSampler) load() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stats := &gethmetrics.CPUStats{}
+	s.read(stats)
+	now := s.now()
+	busy, elapsed := stats.GlobalTime-s.lastBusy, now.Sub(s.lastAt).Seconds()
+	first := s.lastAt.IsZero()
+	s.lastBusy, s.lastAt = stats.GlobalTime, now
+	// The first measurement has nothing to compare against, and is taken as idle.
+	if first || elapsed <= 0 || busy <= 0 {
+		return 0
+	}
+	return min(busy/(elapsed*float64(s.numCPU)), 1)
+}
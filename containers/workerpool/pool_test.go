@@ -0,0 +1,170 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gethmetrics "github.com/ethereum/go-ethereum/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_LimitsConcurrentJobs(t *testing.T) {
+	ctx := context.Background()
+	p, err := New(2, 2)
+	require.NoError(t, err)
+
+	var running, peak atomic.Int32
+	done := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		go func() {
+			_ = p.Run(ctx, func() error {
+				n := running.Add(1)
+				for {
+					old := peak.Load()
+					if n <= old || peak.CompareAndSwap(old, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		<-done
+	}
+	require.Equal(t, int32(2), peak.Load())
+
+	var nilPool *Pool
+	require.NoError(t, nilPool.Run(ctx, func() error { return nil }))
+	_, err = New(2, 1)
+	require.ErrorContains(t, err, "less than min workers")
+}
+
+func TestPool_Rescale(t *testing.T) {
+	ctx := context.Background()
+	load := 0.5
+	p, err := New(1, 3, WithHysteresis(2, 3), WithMaxLoad(0.8), WithLoadFunc(func() float64 { return load }))
+	require.NoError(t, err)
+
+	// Occupy the only worker, and queue a job behind it.
+	hold := make(chan struct{})
+	started := make(chan struct{}, 3)
+	job := func() error {
+		started <- struct{}{}
+		<-hold
+		return nil
+	}
+	go func() { _ = p.Run(ctx, job) }()
+	<-started
+	go func() { _ = p.Run(ctx, job) }()
+	require.Eventually(t, func() bool {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return len(p.waiters) == 1
+	}, time.Second, time.Millisecond)
+
+	// The pool only grows once it has been backed up for two intervals.
+	p.rescale()
+	require.Equal(t, 1, p.Size())
+	p.rescale()
+	require.Equal(t, 2, p.Size())
+	<-started
+
+	// It does not grow without CPU headroom.
+	go func() { _ = p.Run(ctx, job) }()
+	require.Eventually(t, func() bool {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return len(p.waiters) == 1
+	}, time.Second, time.Millisecond)
+	load = 0.9
+	p.rescale()
+	p.rescale()
+	require.Equal(t, 2, p.Size())
+	load = 0.5
+	p.rescale()
+	p.rescale()
+	require.Equal(t, 3, p.Size())
+	<-started
+	close(hold)
+
+	// Once jobs are done, it shrinks one worker at a time after being idle for three intervals.
+	require.Eventually(t, func() bool {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return p.active == 0
+	}, time.Second, time.Millisecond)
+	p.rescale() // The interval in which the jobs ran was not idle.
+	for i := 0; i < 2; i++ {
+		p.rescale()
+		require.Equal(t, 3, p.Size())
+	}
+	p.rescale()
+	require.Equal(t, 2, p.Size())
+	for i := 0; i < 6; i++ {
+		p.rescale()
+	}
+	require.Equal(t, 1, p.Size())
+}
+
+func TestPool_CancelledWaiterDoesNotHoldWorker(t *testing.T) {
+	p, err := New(1, 1)
+	require.NoError(t, err)
+
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = p.Run(context.Background(), func() error {
+			close(started)
+			<-hold
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = p.Run(ctx, func() error { return nil })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	close(hold)
+
+	ran := false
+	require.NoError(t, p.Run(context.Background(), func() error {
+		ran = true
+		return nil
+	}))
+	require.True(t, ran)
+}
+
+func TestCPULoadSampler(t *testing.T) {
+	busy := 0.0
+	now := time.Unix(1000, 0)
+	s := &cpuLoadSampler{
+		read:   func(stats *gethmetrics.CPUStats) { stats.GlobalTime = busy },
+		now:    func() time.Time { return now },
+		numCPU: 4,
+	}
+	require.Equal(t, 0.0, s.load())
+
+	// The load reflects the CPU time spent since the previous measurement, without waiting for a
+	// garbage collection.
+	busy, now = 2, now.Add(time.Second)
+	require.Equal(t, 0.5, s.load())
+	busy, now = 6, now.Add(time.Second)
+	require.Equal(t, 1.0, s.load())
+	now = now.Add(time.Second)
+	require.Equal(t, 0.0, s.load())
+
+	// A failed read leaves the stats zeroed, which is taken as idle.
+	busy, now = 0, now.Add(time.Second)
+	require.Equal(t, 0.0, s.load())
+
+	load := newCPULoadSampler().load()
+	require.GreaterOrEqual(t, load, 0.0)
+	require.LessOrEqual(t, load, 1.0)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	ErrBatchDataUnavailable = errors.New("batch data is not available")
+
+	batchDataUnavailableCounter = metrics.NewRegisteredCounter("arb/validator/provider/batch_data_unavailable", nil)
+	batchDataCheckRetryCounter  = metrics.NewRegisteredCounter("arb/validator/provider/batch_data_check_retry", nil)
+)
+
+// BatchAvailabilityChecker checks whether the data for all batches up to a batch count
+// can actually be retrieved from the data availability layer. Before committing to a claim
+// that references an inbox position, we must be certain we can later prove its execution
+// step-by-step, which requires the underlying batch data.
+type BatchAvailabilityChecker interface {
+	BatchDataAvailable(ctx context.Context, batchCount Batch) (bool, error)
+}
+
+// BatchAvailabilityConfig defines how many times, and how often, batch data availability
+// is checked before giving up.
+type BatchAvailabilityConfig struct {
+	Attempts uint64
+	Interval time.Duration
+}
+
+// DefaultBatchAvailabilityConfig retries the check for up to a minute.
+var DefaultBatchAvailabilityConfig = BatchAvailabilityConfig{
+	Attempts: 12,
+	Interval: 5 * time.Second,
+}
+
+// EnsureBatchDataAvailable checks that the data for all batches up to the specified
+// batch count is retrievable, retrying according to the config. If the data is still not
+// available after all attempts, an error wrapping ErrBatchDataUnavailable is returned and
+// an alert is raised via logs and metrics.
+func EnsureBatchDataAvailable(
+	ctx context.Context,
+	checker BatchAvailabilityChecker,
+	batchCount Batch,
+	cfg BatchAvailabilityConfig,
+) error {
+	if checker == nil {
+		return nil
+	}
+	attempts := cfg.Attempts
+	if attempts == 0 {
+		attempts = 1
+	}
+	var lastErr error
+	for i := uint64(0); i < attempts; i++ {
+		if i > 0 {
+			batchDataCheckRetryCounter.Inc(1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(cfg.Interval):
+			}
+		}
+		available, err := checker.BatchDataAvailable(ctx, batchCount)
+		if err != nil {
+			lastErr = err
+			log.Warn("Could not check batch data availability", "batchCount", batchCount, "attempt", i+1, "err", err)
+			continue
+		}
+		if available {
+			return nil
+		}
+		lastErr = nil
+		log.Warn("Batch data not yet available", "batchCount", batchCount, "attempt", i+1)
+	}
+	batchDataUnavailableCounter.Inc(1)
+	log.Error(
+		"Batch data unavailable, refusing to commit to a claim we may not be able to prove",
+		"batchCount", batchCount,
+		"attempts", attempts,
+		"err", lastErr,
+	)
+	if lastErr != nil {
+		return fmt.Errorf("%w up to batch count %d: %v", ErrBatchDataUnavailable, batchCount, lastErr)
+	}
+	return fmt.Errorf("%w up to batch count %d", ErrBatchDataUnavailable, batchCount)
+}
+
+// BatchCountForState is the number of batches whose data is needed to reach a global state.
+// A state that is part-way through a batch also consumes that batch.
+func BatchCountForState(state protocol.GoGlobalState) Batch {
+	if state.PosInBatch > 0 {
+		return Batch(state.Batch + 1)
+	}
+	return Batch(state.Batch)
+}
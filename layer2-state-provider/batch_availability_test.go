@@ -0,0 +1,61 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAvailabilityChecker struct {
+	availableAfterCalls int
+	calls               int
+	err                 error
+}
+
+func (m *mockAvailabilityChecker) BatchDataAvailable(_ context.Context, _ Batch) (bool, error) {
+	m.calls++
+	if m.err != nil {
+		return false, m.err
+	}
+	return m.calls > m.availableAfterCalls, nil
+}
+
+func TestEnsureBatchDataAvailable(t *testing.T) {
+	ctx := context.Background()
+	cfg := BatchAvailabilityConfig{
+		Attempts: 3,
+		Interval: time.Millisecond,
+	}
+	t.Run("no checker configured", func(t *testing.T) {
+		require.NoError(t, EnsureBatchDataAvailable(ctx, nil, 10, cfg))
+	})
+	t.Run("available after retries", func(t *testing.T) {
+		checker := &mockAvailabilityChecker{availableAfterCalls: 2}
+		require.NoError(t, EnsureBatchDataAvailable(ctx, checker, 10, cfg))
+		require.Equal(t, 3, checker.calls)
+	})
+	t.Run("never available", func(t *testing.T) {
+		checker := &mockAvailabilityChecker{availableAfterCalls: 5}
+		err := EnsureBatchDataAvailable(ctx, checker, 10, cfg)
+		require.ErrorIs(t, err, ErrBatchDataUnavailable)
+		require.Equal(t, 3, checker.calls)
+	})
+	t.Run("checker errors", func(t *testing.T) {
+		checker := &mockAvailabilityChecker{err: errors.New("da layer unreachable")}
+		err := EnsureBatchDataAvailable(ctx, checker, 10, cfg)
+		require.ErrorIs(t, err, ErrBatchDataUnavailable)
+		require.ErrorContains(t, err, "da layer unreachable")
+	})
+}
+
+func TestBatchCountForState(t *testing.T) {
+	require.Equal(t, Batch(2), BatchCountForState(protocol.GoGlobalState{Batch: 2}))
+	require.Equal(t, Batch(3), BatchCountForState(protocol.GoGlobalState{Batch: 2, PosInBatch: 1}))
+}
@@ -0,0 +1,149 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrInvalidBatchBoundaries = errors.New("invalid batch boundaries")
+
+// BatchMessages is what the sequencer inbox and bridge record about the messages of a batch. Every
+// message of a batch produces a block, whether the sequencer posted it in the batch's data or the
+// batch read it from the delayed inbox, so blocks cannot be counted from the sequencer's data
+// alone. Batches forcing the inclusion of delayed messages have no sequencer messages at all.
+type BatchMessages struct {
+	// The number of messages the sequencer posted in the batch's data.
+	SequencerMessages uint64
+	// The number of delayed messages read from the bridge after the batch, as in SequencerBatch.
+	AfterDelayedMessagesRead uint64
+}
+
+// BatchMessagesReader reads the messages of the batches synced by the local node.
+type BatchMessagesReader interface {
+	BatchMessages(ctx context.Context, batch Batch) (*BatchMessages, error)
+}
+
+// BatchBoundaries maps the positions of messages in the inbox, as the batch and position in batch of
+// a global state, to their indexes among the messages of a range of batches, which are the heights
+// of the states after them in a block level history starting at the range.
+type BatchBoundaries struct {
+	fromBatch Batch
+	// The number of messages of the range after each of its batches.
+	messagesAfter []uint64
+}
+
+// NewBatchBoundaries computes the boundaries of the batches from fromBatch, given the number of
+// delayed messages read before it and the messages of each batch in order.
+func NewBatchBoundaries(fromBatch Batch, delayedMessagesReadBefore uint64, batches []*BatchMessages) (*BatchBoundaries, error) {
+	b := &BatchBoundaries{
+		fromBatch:     fromBatch,
+		messagesAfter: make([]uint64, 0, len(batches)),
+	}
+	var count uint64
+	delayedRead := delayedMessagesReadBefore
+	for i, batch := range batches {
+		if batch.AfterDelayedMessagesRead < delayedRead {
+			return nil, fmt.Errorf(
+				"%w: batch %d read %d delayed messages, fewer than the %d read before it",
+				ErrInvalidBatchBoundaries, fromBatch+Batch(i), batch.AfterDelayedMessagesRead, delayedRead,
+			)
+		}
+		count += batch.SequencerMessages + batch.AfterDelayedMessagesRead - delayedRead
+		delayedRead = batch.AfterDelayedMessagesRead
+		b.messagesAfter = append(b.messagesAfter, count)
+	}
+	return b, nil
+}
+
+// ReadBatchBoundaries reads the messages of the batches in the range [fromBatch, toBatch) and
+// computes their boundaries.
+func ReadBatchBoundaries(ctx context.Context, reader BatchMessagesReader, fromBatch, toBatch Batch) (*BatchBoundaries, error) {
+	if toBatch < fromBatch {
+		return nil, fmt.Errorf("invalid batch range: end %d was < start %d", toBatch, fromBatch)
+	}
+	var delayedReadBefore uint64
+	if fromBatch > 0 {
+		before, err := reader.BatchMessages(ctx, fromBatch-1)
+		if err != nil {
+			return nil, fmt.Errorf("could not read messages of batch %d: %w", fromBatch-1, err)
+		}
+		delayedReadBefore = before.AfterDelayedMessagesRead
+	}
+	batches := make([]*BatchMessages, 0, toBatch-fromBatch)
+	for batch := fromBatch; batch < toBatch; batch++ {
+		messages, err := reader.BatchMessages(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("could not read messages of batch %d: %w", batch, err)
+		}
+		batches = append(batches, messages)
+	}
+	return NewBatchBoundaries(fromBatch, delayedReadBefore, batches)
+}
+
+// MessageCount is the number of messages of all batches in the range.
+func (b *BatchBoundaries) MessageCount() uint64 {
+	if len(b.messagesAfter) == 0 {
+		return 0
+	}
+	return b.messagesAfter[len(b.messagesAfter)-1]
+}
+
+// MessagesBefore is the number of messages of the range before a batch, which may be the batch
+// right after the range.
+func (b *BatchBoundaries) MessagesBefore(batch Batch) (uint64, error) {
+	if batch < b.fromBatch || uint64(batch-b.fromBatch) > uint64(len(b.messagesAfter)) {
+		return 0, fmt.Errorf("batch %d is outside of the batches %d to %d", batch, b.fromBatch, b.endBatch())
+	}
+	if batch == b.fromBatch {
+		return 0, nil
+	}
+	return b.messagesAfter[batch-b.fromBatch-1], nil
+}
+
+// MessageIndex is the index among the messages of the range of the message at a position in a
+// batch, which is the number of messages of the range executed by a global state at the position.
+// The position right after the range is the batch after it at position 0.
+func (b *BatchBoundaries) MessageIndex(batch Batch, posInBatch uint64) (uint64, error) {
+	before, err := b.MessagesBefore(batch)
+	if err != nil {
+		return 0, err
+	}
+	if posInBatch == 0 {
+		return before, nil
+	}
+	if batch == b.endBatch() || posInBatch >= b.messagesAfter[batch-b.fromBatch]-before {
+		return 0, fmt.Errorf("%w: batch %d has no message at position %d", ErrInvalidBatchBoundaries, batch, posInBatch)
+	}
+	return before + posInBatch, nil
+}
+
+// Position is the batch and position in batch of the message at an index among the messages of the
+// range, as in the global state of the machine which executed all messages before it. The index
+// right after the last message of a batch is the first position of the next batch with messages,
+// and the index of the message count is the first position of the batch after the range.
+func (b *BatchBoundaries) Position(messageIndex uint64) (Batch, uint64, error) {
+	if messageIndex > b.MessageCount() {
+		return 0, 0, fmt.Errorf("message %d is outside of the %d messages of batches %d to %d", messageIndex, b.MessageCount(), b.fromBatch, b.endBatch())
+	}
+	// The first batch with messages after the index, which holds the message at it.
+	i := sort.Search(len(b.messagesAfter), func(i int) bool {
+		return b.messagesAfter[i] > messageIndex
+	})
+	if i == len(b.messagesAfter) {
+		return b.endBatch(), 0, nil
+	}
+	var before uint64
+	if i > 0 {
+		before = b.messagesAfter[i-1]
+	}
+	return b.fromBatch + Batch(i), messageIndex - before, nil
+}
+
+func (b *BatchBoundaries) endBatch() Batch {
+	return b.fromBatch + Batch(len(b.messagesAfter))
+}
@@ -0,0 +1,177 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBatchMessagesReader []*BatchMessages
+
+func (m mockBatchMessagesReader) BatchMessages(_ context.Context, batch Batch) (*BatchMessages, error) {
+	if int(batch) >= len(m) {
+		return nil, errors.New("batch not found")
+	}
+	return m[batch], nil
+}
+
+// Batches of an inbox with the init message, sequencer only batches, a batch interleaving delayed
+// messages, a forced inclusion of delayed messages only, and an empty batch.
+var inboxBatches = mockBatchMessagesReader{
+	{SequencerMessages: 0, AfterDelayedMessagesRead: 1},
+	{SequencerMessages: 3, AfterDelayedMessagesRead: 1},
+	{SequencerMessages: 2, AfterDelayedMessagesRead: 3},
+	{SequencerMessages: 0, AfterDelayedMessagesRead: 5},
+	{SequencerMessages: 0, AfterDelayedMessagesRead: 5},
+	{SequencerMessages: 1, AfterDelayedMessagesRead: 5},
+}
+
+func TestBatchBoundaries(t *testing.T) {
+	ctx := context.Background()
+	b, err := ReadBatchBoundaries(ctx, inboxBatches, 0, 6)
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), b.MessageCount())
+
+	for _, tt := range []struct {
+		batch      Batch
+		posInBatch uint64
+		index      uint64
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{1, 2, 3},
+		// Delayed messages read by a batch are among its messages.
+		{2, 0, 4},
+		{2, 3, 7},
+		// A forced inclusion has only delayed messages.
+		{3, 0, 8},
+		{3, 1, 9},
+		{5, 0, 10},
+		// The position after the range is right after its last message.
+		{6, 0, 11},
+	} {
+		t.Run(fmt.Sprintf("%d:%d", tt.batch, tt.posInBatch), func(t *testing.T) {
+			index, err := b.MessageIndex(tt.batch, tt.posInBatch)
+			require.NoError(t, err)
+			require.Equal(t, tt.index, index)
+			batch, pos, err := b.Position(index)
+			require.NoError(t, err)
+			require.Equal(t, tt.batch, batch)
+			require.Equal(t, tt.posInBatch, pos)
+		})
+	}
+
+	// The empty batch starts where the batch after it does, which is the position the index maps to.
+	index, err := b.MessageIndex(4, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), index)
+	batch, pos, err := b.Position(index)
+	require.NoError(t, err)
+	require.Equal(t, Batch(5), batch)
+	require.Equal(t, uint64(0), pos)
+
+	_, _, err = b.Position(12)
+	require.ErrorContains(t, err, "message 12 is outside of the 11 messages of batches 0 to 6")
+	_, err = b.MessageIndex(1, 3)
+	require.ErrorIs(t, err, ErrInvalidBatchBoundaries)
+	_, err = b.MessageIndex(4, 1)
+	require.ErrorIs(t, err, ErrInvalidBatchBoundaries)
+	_, err = b.MessageIndex(6, 1)
+	require.ErrorIs(t, err, ErrInvalidBatchBoundaries)
+	_, err = b.MessageIndex(7, 0)
+	require.ErrorContains(t, err, "batch 7 is outside of the batches 0 to 6")
+
+	// Ranges starting after the first batch count the delayed messages read before them.
+	b, err = ReadBatchBoundaries(ctx, inboxBatches, 2, 4)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), b.MessageCount())
+	before, err := b.MessagesBefore(3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), before)
+
+	b, err = ReadBatchBoundaries(ctx, inboxBatches, 3, 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), b.MessageCount())
+
+	_, err = NewBatchBoundaries(0, 3, []*BatchMessages{{SequencerMessages: 1, AfterDelayedMessagesRead: 2}})
+	require.ErrorIs(t, err, ErrInvalidBatchBoundaries)
+	_, err = ReadBatchBoundaries(ctx, inboxBatches, 5, 7)
+	require.ErrorContains(t, err, "could not read messages of batch 6")
+}
+
+// Collects a state per message of every batch, as a message state collector counting only the
+// messages posted by the sequencer would not.
+type batchStatesCollector struct {
+	messagesPerBatch func(batch Batch) uint64
+}
+
+func (c *batchStatesCollector) L2MessageStatesUpTo(
+	_ context.Context,
+	from Height,
+	upTo option.Option[Height],
+	fromBatch,
+	toBatch Batch,
+) ([]common.Hash, error) {
+	var messages uint64
+	for batch := fromBatch; batch < toBatch; batch++ {
+		messages += c.messagesPerBatch(batch)
+	}
+	hashes := make([]common.Hash, 0)
+	for h := from; h <= upTo.Unwrap() && uint64(h) <= messages; h++ {
+		hashes = append(hashes, common.BytesToHash([]byte{byte(h + 1)}))
+	}
+	return hashes, nil
+}
+
+func TestBlockStatesWithinBatches(t *testing.T) {
+	ctx := context.Background()
+	allMessages := func(batch Batch) uint64 {
+		b, err := ReadBatchBoundaries(ctx, inboxBatches, batch, batch+1)
+		require.NoError(t, err)
+		return b.MessageCount()
+	}
+	provider := NewHistoryCommitmentProvider(
+		&batchStatesCollector{messagesPerBatch: allMessages},
+		nil,
+		nil,
+		[]Height{16},
+		nil,
+		nil,
+		WithBatchMessages(inboxBatches),
+	)
+	state := func(h uint64) common.Hash {
+		return common.BytesToHash([]byte{byte(h + 1)})
+	}
+
+	// Batches 1 to 3 have 7 messages, 4 of them delayed, so states past height 7 repeat the last one.
+	hashes, err := provider.historyCommitmentImpl(ctx, &HistoryCommitmentRequest{FromBatch: 1, ToBatch: 3, UpToHeight: option.None[Height]()})
+	require.NoError(t, err)
+	require.Len(t, hashes, 17)
+	for h := 0; h <= 16; h++ {
+		require.Equal(t, state(min(uint64(h), 7)), hashes[h], "height %d", h)
+	}
+
+	hashes, err = provider.historyCommitmentImpl(ctx, &HistoryCommitmentRequest{FromBatch: 1, ToBatch: 3, FromHeight: 6, UpToHeight: option.Some(Height(8))})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{state(6), state(7), state(7)}, hashes)
+
+	hashes, err = provider.historyCommitmentImpl(ctx, &HistoryCommitmentRequest{FromBatch: 1, ToBatch: 3, FromHeight: 9, UpToHeight: option.Some(Height(10))})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{state(7), state(7)}, hashes)
+
+	// A collector which only counts sequencer messages ends the history early.
+	provider.l2MessageStateCollector = &batchStatesCollector{messagesPerBatch: func(batch Batch) uint64 {
+		return inboxBatches[batch].SequencerMessages
+	}}
+	_, err = provider.historyCommitmentImpl(ctx, &HistoryCommitmentRequest{FromBatch: 1, ToBatch: 3, UpToHeight: option.None[Height]()})
+	require.ErrorIs(t, err, ErrInvalidBatchBoundaries)
+	require.ErrorContains(t, err, "collected 6 states from height 0 to 7, but batches 1 to 3 have 7 messages")
+}
@@ -0,0 +1,265 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/OffchainLabs/bold/util/objectstore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	journalResumedCounter       = metrics.NewRegisteredCounter("arb/validator/provider/commitment_journal/resumed", nil)
+	journalResumedHashesCounter = metrics.NewRegisteredCounter("arb/validator/provider/commitment_journal/resumed_hashes", nil)
+)
+
+// CommitmentJournal durably records the machine hashes collected so far for a history commitment,
+// so that collection can resume from the last checkpoint if the process crashes mid-computation.
+type CommitmentJournal interface {
+	// Load returns the hashes recorded for a key, in the order they were appended.
+	Load(key common.Hash) ([]common.Hash, error)
+	// Append durably records more hashes for a key before returning.
+	Append(key common.Hash, hashes []common.Hash) error
+	// Remove discards the hashes recorded for a key once they are no longer needed.
+	Remove(key common.Hash) error
+}
+
+// FileCommitmentJournal appends the hashes for each key to a file in a directory,
+// syncing the file after each append.
+type FileCommitmentJournal struct {
+	dir string
+}
+
+func NewFileCommitmentJournal(dir string) (*FileCommitmentJournal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create commitment journal directory %s: %w", dir, err)
+	}
+	return &FileCommitmentJournal{dir: dir}, nil
+}
+
+func (j *FileCommitmentJournal) path(key common.Hash) string {
+	return filepath.Join(j.dir, key.Hex()+".journal")
+}
+
+// Load ignores a partially written hash at the end of the file, which is left behind
+// if the process crashed while appending to it.
+func (j *FileCommitmentJournal) Load(key common.Hash) ([]common.Hash, error) {
+	data, err := os.ReadFile(j.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	hashes := make([]common.Hash, len(data)/common.HashLength)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash(data[i*common.HashLength : (i+1)*common.HashLength])
+	}
+	return hashes, nil
+}
+
+func (j *FileCommitmentJournal) Append(key common.Hash, hashes []common.Hash) error {
+	f, err := os.OpenFile(j.path(key), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	// Drop any partially written hash so the file stays aligned.
+	end := info.Size() - info.Size()%common.HashLength
+	data := make([]byte, 0, len(hashes)*common.HashLength)
+	for _, h := range hashes {
+		data = append(data, h.Bytes()...)
+	}
+	if _, err = f.WriteAt(data, end); err != nil {
+		return err
+	}
+	if err = f.Truncate(end + int64(len(data))); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func (j *FileCommitmentJournal) Remove(key common.Hash) error {
+	if err := os.Remove(j.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// BucketCommitmentJournal keeps the hashes for each key in a bucket, such as one in object storage, so
+// that a validator rescheduled without its local disk resumes collecting them. As objects cannot be
+// appended to, the hashes of each append are stored as their own chunk object, so that an append
+// only uploads the hashes appended.
+type BucketCommitmentJournal struct {
+	bucket objectstore.Bucket
+	lock   sync.Mutex
+	// The number of chunks stored for each key, once known.
+	numChunks map[common.Hash]int
+}
+
+func NewBucketCommitmentJournal(bucket objectstore.Bucket) *BucketCommitmentJournal {
+	return &BucketCommitmentJournal{
+		bucket:    bucket,
+		numChunks: make(map[common.Hash]int),
+	}
+}
+
+func journalChunkName(key common.Hash, index int) string {
+	return fmt.Sprintf("commitment-journal/%s/%d", key.Hex(), index)
+}
+
+// Reads the chunks stored for a key in order, up to the first missing one.
+func (j *BucketCommitmentJournal) loadChunks(ctx context.Context, key common.Hash) ([][]byte, error) {
+	var chunks [][]byte
+	for {
+		data, err := j.bucket.Get(ctx, journalChunkName(key, len(chunks)))
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return chunks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, data)
+	}
+}
+
+func (j *BucketCommitmentJournal) chunkCount(ctx context.Context, key common.Hash) (int, error) {
+	if n, ok := j.numChunks[key]; ok {
+		return n, nil
+	}
+	chunks, err := j.loadChunks(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	j.numChunks[key] = len(chunks)
+	return len(chunks), nil
+}
+
+func (j *BucketCommitmentJournal) Load(key common.Hash) ([]common.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	chunks, err := j.loadChunks(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	j.numChunks[key] = len(chunks)
+	var hashes []common.Hash
+	for _, data := range chunks {
+		for i := 0; i+common.HashLength <= len(data); i += common.HashLength {
+			hashes = append(hashes, common.BytesToHash(data[i:i+common.HashLength]))
+		}
+	}
+	return hashes, nil
+}
+
+func (j *BucketCommitmentJournal) Append(key common.Hash, hashes []common.Hash) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	n, err := j.chunkCount(ctx, key)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 0, len(hashes)*common.HashLength)
+	for _, h := range hashes {
+		data = append(data, h.Bytes()...)
+	}
+	if err = j.bucket.Put(ctx, journalChunkName(key, n), data); err != nil {
+		return err
+	}
+	j.numChunks[key] = n + 1
+	return nil
+}
+
+func (j *BucketCommitmentJournal) Remove(key common.Hash) error {
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	n, err := j.chunkCount(ctx, key)
+	if err != nil {
+		return err
+	}
+	// Chunks are removed from the last, so a removal which fails partway leaves the first ones intact.
+	for ; n > 0; n-- {
+		if err = j.bucket.Delete(ctx, journalChunkName(key, n-1)); err != nil {
+			j.numChunks[key] = n
+			return err
+		}
+	}
+	delete(j.numChunks, key)
+	return nil
+}
+
+// Collects machine hashes in chunks of the checkpoint interval, journaling each chunk before
+// collecting the next one. If the journal already holds hashes for the request, collection
+// resumes after them rather than stepping through the machine from the start.
+func (p *HistoryCommitmentProvider) collectMachineHashesWithJournal(
+	ctx context.Context,
+	cfg *HashCollectorConfig,
+) ([]common.Hash, error) {
+	key := crypto.Keccak256Hash([]byte(cfg.String()))
+	collected, err := p.commitmentJournal.Load(key)
+	if err != nil {
+		log.Warn("Could not load journaled machine hashes, collecting from the start", "key", key, "err", err)
+		collected = nil
+	}
+	if uint64(len(collected)) > cfg.NumDesiredHashes {
+		collected = collected[:cfg.NumDesiredHashes]
+	}
+	if len(collected) > 0 {
+		journalResumedCounter.Inc(1)
+		journalResumedHashesCounter.Inc(int64(len(collected)))
+		log.Info("Resuming machine hash collection from journal", "key", key, "collected", len(collected), "desired", cfg.NumDesiredHashes)
+	}
+	for uint64(len(collected)) < cfg.NumDesiredHashes {
+		// Chunks collected so far stay journaled, so a cancelled collection resumes from them.
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		remaining := cfg.NumDesiredHashes - uint64(len(collected))
+		chunk := *cfg
+		chunk.MachineStartIndex = cfg.MachineStartIndex + OpcodeIndex(uint64(len(collected))*uint64(cfg.StepSize))
+		chunk.NumDesiredHashes = min(remaining, p.checkpointInterval)
+		hashes, err := p.machineHashCollector.CollectMachineHashes(ctx, &chunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(hashes) == 0 && len(collected) == 0 {
+			return nil, fmt.Errorf("collected no machine hashes from opcode index %d", chunk.MachineStartIndex)
+		}
+		if err = p.commitmentJournal.Append(key, hashes); err != nil {
+			log.Warn("Could not journal machine hashes", "key", key, "err", err)
+		}
+		collected = append(collected, hashes...)
+		// The machine finished before the end of the chunk, so there are no more hashes to collect.
+		// The caller pads the history with the last of them, as without a journal.
+		if uint64(len(hashes)) < chunk.NumDesiredHashes {
+			break
+		}
+	}
+	if err = p.commitmentJournal.Remove(key); err != nil {
+		log.Warn("Could not remove journaled machine hashes", "key", key, "err", err)
+	}
+	return collected, nil
+}
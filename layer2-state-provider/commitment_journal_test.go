@@ -0,0 +1,183 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/OffchainLabs/bold/util/objectstore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Hashes the opcode index of each collected hash, failing once it has been called failAfter times.
+type countingCollector struct {
+	calls       int
+	failAfter   int
+	startChunks []OpcodeIndex
+	// If set, the machine finishes after this many steps from the start of the first chunk.
+	finishAfter uint64
+}
+
+func (c *countingCollector) CollectMachineHashes(_ context.Context, cfg *HashCollectorConfig) ([]common.Hash, error) {
+	if c.failAfter > 0 && c.calls >= c.failAfter {
+		return nil, errors.New("crashed")
+	}
+	c.calls++
+	c.startChunks = append(c.startChunks, cfg.MachineStartIndex)
+	numHashes := cfg.NumDesiredHashes
+	if c.finishAfter > 0 {
+		done := (uint64(cfg.MachineStartIndex) - uint64(c.startChunks[0])) / uint64(cfg.StepSize)
+		numHashes = min(numHashes, c.finishAfter-min(done, c.finishAfter))
+	}
+	hashes := make([]common.Hash, numHashes)
+	for i := range hashes {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(cfg.MachineStartIndex)+uint64(i)*uint64(cfg.StepSize))
+		hashes[i] = crypto.Keccak256Hash(buf[:])
+	}
+	return hashes, nil
+}
+
+func TestCollectMachineHashesWithJournal_ResumesAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	journal, err := NewFileCommitmentJournal(t.TempDir())
+	require.NoError(t, err)
+	cfg := &HashCollectorConfig{
+		FromBatch:         1,
+		StepHeights:       []Height{4},
+		NumDesiredHashes:  10,
+		MachineStartIndex: 100,
+		StepSize:          2,
+	}
+	want, err := (&countingCollector{}).CollectMachineHashes(ctx, cfg)
+	require.NoError(t, err)
+
+	crashing := &countingCollector{failAfter: 2}
+	p := NewHistoryCommitmentProvider(nil, crashing, nil, nil, nil, nil, WithCommitmentJournal(journal, 3))
+	_, err = p.collectMachineHashesWithJournal(ctx, cfg)
+	require.ErrorContains(t, err, "crashed")
+
+	resumed := &countingCollector{}
+	p = NewHistoryCommitmentProvider(nil, resumed, nil, nil, nil, nil, WithCommitmentJournal(journal, 3))
+	got, err := p.collectMachineHashesWithJournal(ctx, cfg)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	// Only the hashes after the two journaled chunks are collected again.
+	require.Equal(t, []OpcodeIndex{112, 118}, resumed.startChunks)
+
+	remaining, err := journal.Load(crypto.Keccak256Hash([]byte(cfg.String())))
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+}
+
+func TestCollectMachineHashesWithJournal_MachineFinishesMidChunk(t *testing.T) {
+	ctx := context.Background()
+	journal, err := NewFileCommitmentJournal(t.TempDir())
+	require.NoError(t, err)
+	cfg := &HashCollectorConfig{
+		FromBatch:         1,
+		StepHeights:       []Height{4},
+		NumDesiredHashes:  10,
+		MachineStartIndex: 100,
+		StepSize:          2,
+	}
+	want, err := (&countingCollector{}).CollectMachineHashes(ctx, &HashCollectorConfig{
+		FromBatch:         1,
+		StepHeights:       []Height{4},
+		NumDesiredHashes:  5,
+		MachineStartIndex: 100,
+		StepSize:          2,
+	})
+	require.NoError(t, err)
+
+	collector := &countingCollector{finishAfter: 5}
+	p := NewHistoryCommitmentProvider(nil, collector, nil, nil, nil, nil, WithCommitmentJournal(journal, 3))
+	got, err := p.collectMachineHashesWithJournal(ctx, cfg)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	// Collection stops at the chunk the machine finished in.
+	require.Equal(t, []OpcodeIndex{100, 106}, collector.startChunks)
+}
+
+func TestBucketCommitmentJournal_ResumesAfterReschedule(t *testing.T) {
+	ctx := context.Background()
+	remote, err := objectstore.NewDirBucket(t.TempDir())
+	require.NoError(t, err)
+	cfg := &HashCollectorConfig{
+		FromBatch:         1,
+		StepHeights:       []Height{4},
+		NumDesiredHashes:  10,
+		MachineStartIndex: 100,
+		StepSize:          2,
+	}
+	want, err := (&countingCollector{}).CollectMachineHashes(ctx, cfg)
+	require.NoError(t, err)
+
+	bucket, err := objectstore.NewCachedBucket(remote, t.TempDir())
+	require.NoError(t, err)
+	crashing := &countingCollector{failAfter: 2}
+	p := NewHistoryCommitmentProvider(nil, crashing, nil, nil, nil, nil, WithCommitmentJournal(NewBucketCommitmentJournal(bucket), 3))
+	_, err = p.collectMachineHashesWithJournal(ctx, cfg)
+	require.ErrorContains(t, err, "crashed")
+	// Each journaled chunk is stored as its own object.
+	key := crypto.Keccak256Hash([]byte(cfg.String()))
+	chunk, err := remote.Get(ctx, journalChunkName(key, 1))
+	require.NoError(t, err)
+	require.Equal(t, 3*common.HashLength, len(chunk))
+	_, err = remote.Get(ctx, journalChunkName(key, 2))
+	require.ErrorIs(t, err, objectstore.ErrNotFound)
+
+	// Rescheduled with an empty local disk, collection resumes from the remote journal.
+	bucket, err = objectstore.NewCachedBucket(remote, t.TempDir())
+	require.NoError(t, err)
+	journal := NewBucketCommitmentJournal(bucket)
+	resumed := &countingCollector{}
+	p = NewHistoryCommitmentProvider(nil, resumed, nil, nil, nil, nil, WithCommitmentJournal(journal, 3))
+	got, err := p.collectMachineHashesWithJournal(ctx, cfg)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, []OpcodeIndex{112, 118}, resumed.startChunks)
+
+	remaining, err := journal.Load(key)
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+	_, err = remote.Get(ctx, journalChunkName(key, 0))
+	require.ErrorIs(t, err, objectstore.ErrNotFound)
+}
+
+func TestFileCommitmentJournal_TornWrite(t *testing.T) {
+	journal, err := NewFileCommitmentJournal(t.TempDir())
+	require.NoError(t, err)
+	key := common.BytesToHash([]byte("key"))
+	hashes := []common.Hash{common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})}
+	require.NoError(t, journal.Append(key, hashes))
+
+	// Simulate a crash partway through writing a third hash.
+	f, err := os.OpenFile(journal.path(key), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{3, 3, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	loaded, err := journal.Load(key)
+	require.NoError(t, err)
+	require.Equal(t, hashes, loaded)
+
+	next := common.BytesToHash([]byte{4})
+	require.NoError(t, journal.Append(key, []common.Hash{next}))
+	loaded, err = journal.Load(key)
+	require.NoError(t, err)
+	require.Equal(t, append(hashes, next), loaded)
+
+	require.NoError(t, journal.Remove(key))
+	loaded, err = journal.Load(key)
+	require.NoError(t, err)
+	require.Empty(t, loaded)
+}
@@ -0,0 +1,228 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/OffchainLabs/bold/containers/threadsafe"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	"github.com/OffchainLabs/bold/util/objectstore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	expansionReusedCounter = metrics.NewRegisteredCounter("arb/validator/provider/expansion_reused", nil)
+	expansionLeavesHashed  = metrics.NewRegisteredCounter("arb/validator/provider/expansion_leaves_hashed", nil)
+	expansionCheckpointed  = metrics.NewRegisteredCounter("arb/validator/provider/expansion_checkpointed", nil)
+)
+
+// StoredExpansion is the Merkle expansion over a prefix of the leaves of a block level history
+// and the proofs of its first and last leaves, along with the last leaf of the prefix, used to
+// check the prefix still matches the history.
+type StoredExpansion struct {
+	commitments.Prefix
+	LastLeaf common.Hash `json:"lastLeaf"`
+}
+
+// ExpansionStore persists the Merkle expansions of block level histories, so that history
+// commitments over them can be extended as the chain advances without rehashing every leaf.
+type ExpansionStore interface {
+	GetExpansion(key common.Hash) (option.Option[*StoredExpansion], error)
+	PutExpansion(key common.Hash, expansion *StoredExpansion) error
+}
+
+// MemoryExpansionStore keeps a bounded number of expansions in memory.
+type MemoryExpansionStore struct {
+	items *threadsafe.LruMap[common.Hash, *StoredExpansion]
+}
+
+func NewMemoryExpansionStore(capacity int) *MemoryExpansionStore {
+	return &MemoryExpansionStore{
+		items: threadsafe.NewLruMap[common.Hash, *StoredExpansion](capacity),
+	}
+}
+
+func (s *MemoryExpansionStore) GetExpansion(key common.Hash) (option.Option[*StoredExpansion], error) {
+	expansion, ok := s.items.TryGet(key)
+	if !ok {
+		return option.None[*StoredExpansion](), nil
+	}
+	return option.Some(expansion), nil
+}
+
+func (s *MemoryExpansionStore) PutExpansion(key common.Hash, expansion *StoredExpansion) error {
+	s.items.Put(key, expansion)
+	return nil
+}
+
+// FileExpansionStore persists each expansion as a JSON file in a directory, so they
+// survive restarts of the validator.
+type FileExpansionStore struct {
+	dir string
+}
+
+func NewFileExpansionStore(dir string) (*FileExpansionStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create expansion store directory %s: %w", dir, err)
+	}
+	return &FileExpansionStore{dir: dir}, nil
+}
+
+func (s *FileExpansionStore) path(key common.Hash) string {
+	return filepath.Join(s.dir, key.Hex()+".json")
+}
+
+func (s *FileExpansionStore) GetExpansion(key common.Hash) (option.Option[*StoredExpansion], error) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return option.None[*StoredExpansion](), nil
+		}
+		return option.None[*StoredExpansion](), err
+	}
+	expansion := &StoredExpansion{}
+	if err = json.Unmarshal(data, expansion); err != nil {
+		return option.None[*StoredExpansion](), fmt.Errorf("could not decode expansion %#x: %w", key, err)
+	}
+	return option.Some(expansion), nil
+}
+
+// PutExpansion writes the expansion to a temporary file before renaming it, so a crash
+// never leaves a partially written expansion behind.
+func (s *FileExpansionStore) PutExpansion(key common.Hash, expansion *StoredExpansion) error {
+	data, err := json.Marshal(expansion)
+	if err != nil {
+		return err
+	}
+	tmp := s.path(key) + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(key))
+}
+
+// The time allowed for each read or write of an artifact in a bucket.
+const bucketTimeout = time.Minute
+
+// BucketExpansionStore persists each expansion as a JSON object in a bucket, such as one in object
+// storage, so they survive the loss of the local disk.
+type BucketExpansionStore struct {
+	bucket objectstore.Bucket
+}
+
+func NewBucketExpansionStore(bucket objectstore.Bucket) *BucketExpansionStore {
+	return &BucketExpansionStore{bucket: bucket}
+}
+
+func expansionObjectName(key common.Hash) string {
+	return "expansions/" + key.Hex() + ".json"
+}
+
+func (s *BucketExpansionStore) GetExpansion(key common.Hash) (option.Option[*StoredExpansion], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+	data, err := s.bucket.Get(ctx, expansionObjectName(key))
+	if err != nil {
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return option.None[*StoredExpansion](), nil
+		}
+		return option.None[*StoredExpansion](), err
+	}
+	expansion := &StoredExpansion{}
+	if err = json.Unmarshal(data, expansion); err != nil {
+		return option.None[*StoredExpansion](), fmt.Errorf("could not decode expansion %#x: %w", key, err)
+	}
+	return option.Some(expansion), nil
+}
+
+func (s *BucketExpansionStore) PutExpansion(key common.Hash, expansion *StoredExpansion) error {
+	data, err := json.Marshal(expansion)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+	return s.bucket.Put(ctx, expansionObjectName(key), data)
+}
+
+// The leaves of a block level history are determined by the batches and starting height it is over,
+// so its expansion can be reused by any commitment over the same range up to a greater height.
+func blockExpansionKey(req *HistoryCommitmentRequest) common.Hash {
+	var buf [24]byte
+	binary.BigEndian.PutUint64(buf[0:8], uint64(req.FromBatch))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(req.ToBatch))
+	binary.BigEndian.PutUint64(buf[16:24], uint64(req.FromHeight))
+	return crypto.Keccak256Hash(req.WasmModuleRoot.Bytes(), buf[:])
+}
+
+// Computes the commitment to a block level history, extending a stored prefix of its leaves if
+// there is one, and storing the prefix over all of them. If the context is done first, the prefix
+// over the leaves hashed until then is stored instead, so that the next computation resumes from
+// it.
+func (p *HistoryCommitmentProvider) blockHistory(
+	ctx context.Context,
+	req *HistoryCommitmentRequest,
+	leaves []common.Hash,
+) (commitments.History, error) {
+	key := blockExpansionKey(req)
+	prefix := commitments.Prefix{}
+	storedSize := uint64(0)
+	stored, err := p.expansionStore.GetExpansion(key)
+	if err != nil {
+		log.Warn("Could not read stored history expansion", "key", key, "err", err)
+	} else if stored.IsSome() {
+		storedSize = stored.Unwrap().Size()
+		if storedSize > 0 && storedSize <= uint64(len(leaves)) && leaves[storedSize-1] == stored.Unwrap().LastLeaf {
+			// Prefixes stored without their proofs are recomputed, and replaced once they are.
+			if err = stored.Unwrap().Validate(); err != nil {
+				log.Warn("Not reusing stored history expansion", "key", key, "err", err)
+				storedSize = 0
+			} else {
+				prefix = stored.Unwrap().Prefix
+				expansionReusedCounter.Inc(1)
+			}
+		}
+	}
+	prefixSize := prefix.Size()
+	commit, extended, err := commitments.NewFromPrefixContext(ctx, prefix, leaves)
+	if err != nil {
+		if ctx.Err() == nil {
+			return commitments.History{}, err
+		}
+		if size := extended.Size(); size > prefixSize && size > storedSize {
+			expansionLeavesHashed.Inc(int64(size - prefixSize))
+			p.storeExpansion(key, extended, leaves[size-1])
+			expansionCheckpointed.Inc(1)
+			log.Info("Checkpointed history expansion before giving up on it", "key", key, "leaves", size, "total", len(leaves))
+		}
+		return commitments.History{}, err
+	}
+	expansionLeavesHashed.Inc(int64(uint64(len(leaves)) - prefixSize))
+	// Stored expansions are only replaced by larger ones, or by one over different leaves.
+	if uint64(len(leaves)) >= storedSize && uint64(len(leaves)) > prefixSize {
+		p.storeExpansion(key, extended, leaves[len(leaves)-1])
+	}
+	return commit, nil
+}
+
+func (p *HistoryCommitmentProvider) storeExpansion(key common.Hash, prefix commitments.Prefix, lastLeaf common.Hash) {
+	if err := p.expansionStore.PutExpansion(key, &StoredExpansion{
+		Prefix:   prefix,
+		LastLeaf: lastLeaf,
+	}); err != nil {
+		log.Warn("Could not store history expansion", "key", key, "err", err)
+	}
+}
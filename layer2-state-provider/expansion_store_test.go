@@ -0,0 +1,161 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/OffchainLabs/bold/util/objectstore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockHistory_ExtendsStoredExpansion(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileExpansionStore(t.TempDir())
+	require.NoError(t, err)
+	provider := &HistoryCommitmentProvider{expansionStore: store}
+	req := &HistoryCommitmentRequest{
+		WasmModuleRoot: common.BytesToHash([]byte("wasm")),
+		FromBatch:      1,
+		ToBatch:        2,
+	}
+	leaves := make([]common.Hash, 20)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte{byte(i)})
+	}
+	key := blockExpansionKey(req)
+
+	_, err = provider.blockHistory(ctx, req, leaves[:8])
+	require.NoError(t, err)
+	stored, err := store.GetExpansion(key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), stored.Unwrap().Size())
+	require.Equal(t, leaves[7], stored.Unwrap().LastLeaf)
+
+	// Growing the history extends the stored expansion.
+	commit, err := provider.blockHistory(ctx, req, leaves)
+	require.NoError(t, err)
+	want, err := commitments.New(leaves)
+	require.NoError(t, err)
+	require.Equal(t, want, commit)
+	stored, err = store.GetExpansion(key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), stored.Unwrap().Size())
+
+	// Smaller histories are computed from scratch without replacing the stored expansion.
+	commit, err = provider.blockHistory(ctx, req, leaves[:5])
+	require.NoError(t, err)
+	want, err = commitments.New(leaves[:5])
+	require.NoError(t, err)
+	require.Equal(t, want, commit)
+	stored, err = store.GetExpansion(key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), stored.Unwrap().Size())
+
+	// A stored expansion over different leaves is not reused.
+	changed := append([]common.Hash{}, leaves...)
+	changed[19] = common.BytesToHash([]byte("changed"))
+	changed = append(changed, leaves[0])
+	commit, err = provider.blockHistory(ctx, req, changed)
+	require.NoError(t, err)
+	want, err = commitments.New(changed)
+	require.NoError(t, err)
+	require.Equal(t, want, commit)
+
+	// Nor is one stored without its proofs, which is replaced.
+	expansion, err := prefixproofs.ExpansionFromLeaves(changed[:10])
+	require.NoError(t, err)
+	require.NoError(t, store.PutExpansion(key, &StoredExpansion{Prefix: commitments.Prefix{Expansion: expansion}, LastLeaf: changed[9]}))
+	commit, err = provider.blockHistory(ctx, req, changed)
+	require.NoError(t, err)
+	require.Equal(t, want, commit)
+	stored, err = store.GetExpansion(key)
+	require.NoError(t, err)
+	require.NoError(t, stored.Unwrap().Validate())
+	require.Equal(t, uint64(len(changed)), stored.Unwrap().Size())
+
+	// A different range does not share the expansion.
+	other := *req
+	other.FromHeight = 1
+	stored, err = store.GetExpansion(blockExpansionKey(&other))
+	require.NoError(t, err)
+	require.True(t, stored.IsNone())
+}
+
+// A context which is done after its error was checked a given number of times.
+type doneAfterChecks struct {
+	context.Context
+	checks int
+}
+
+func (c *doneAfterChecks) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestBlockHistory_CheckpointsOnCancellation(t *testing.T) {
+	store := NewMemoryExpansionStore(10)
+	provider := &HistoryCommitmentProvider{expansionStore: store}
+	req := &HistoryCommitmentRequest{
+		WasmModuleRoot: common.BytesToHash([]byte("wasm")),
+		FromBatch:      1,
+		ToBatch:        2,
+	}
+	leaves := make([]common.Hash, 3*prefixproofs.CancellationCheckInterval+5)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash(common.BigToHash(big.NewInt(int64(i))).Bytes())
+	}
+	key := blockExpansionKey(req)
+
+	// Cancelled after hashing two intervals of leaves, which are checkpointed.
+	ctx := &doneAfterChecks{Context: context.Background(), checks: 2}
+	_, err := provider.blockHistory(ctx, req, leaves)
+	require.ErrorIs(t, err, context.Canceled)
+	checkpointed := uint64(2 * prefixproofs.CancellationCheckInterval)
+	stored, err := store.GetExpansion(key)
+	require.NoError(t, err)
+	require.Equal(t, checkpointed, stored.Unwrap().Size())
+	require.Equal(t, leaves[checkpointed-1], stored.Unwrap().LastLeaf)
+	_, want, err := commitments.NewFromPrefix(commitments.Prefix{}, leaves[:checkpointed])
+	require.NoError(t, err)
+	require.Equal(t, want, stored.Unwrap().Prefix)
+
+	// The next computation resumes from the checkpoint.
+	commit, err := provider.blockHistory(context.Background(), req, leaves)
+	require.NoError(t, err)
+	wantCommit, err := commitments.New(leaves)
+	require.NoError(t, err)
+	require.Equal(t, wantCommit, commit)
+	stored, err = store.GetExpansion(key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(leaves)), stored.Unwrap().Size())
+}
+
+func TestBucketExpansionStore(t *testing.T) {
+	bucket, err := objectstore.NewDirBucket(t.TempDir())
+	require.NoError(t, err)
+	store := NewBucketExpansionStore(bucket)
+	key := common.BytesToHash([]byte("key"))
+	stored, err := store.GetExpansion(key)
+	require.NoError(t, err)
+	require.True(t, stored.IsNone())
+
+	leaves := []common.Hash{common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2}), common.BytesToHash([]byte{3})}
+	_, prefix, err := commitments.NewFromPrefix(commitments.Prefix{}, leaves)
+	require.NoError(t, err)
+	want := &StoredExpansion{Prefix: prefix, LastLeaf: leaves[2]}
+	require.NoError(t, store.PutExpansion(key, want))
+	stored, err = store.GetExpansion(key)
+	require.NoError(t, err)
+	require.Equal(t, want, stored.Unwrap())
+}
@@ -2,6 +2,7 @@ package l2stateprovider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -9,8 +10,9 @@ import (
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	inprogresscache "github.com/OffchainLabs/bold/containers/in-progress-cache"
+	"github.com/OffchainLabs/bold/containers/workerpool"
+	inclusionproofs "github.com/OffchainLabs/bold/state-commitments/inclusion-proofs"
 	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/OffchainLabs/bold/api"
@@ -107,9 +109,59 @@ type HistoryCommitmentProvider struct {
 	challengeLeafHeights    []Height
 	inFlightRequestCache    *inprogresscache.Cache[string, []common.Hash]
 	apiDB                   db.Database
+	expansionStore          ExpansionStore
+	commitmentJournal       CommitmentJournal
+	checkpointInterval      uint64
+	commitmentPool          *workerpool.Pool
+	proofPool               *workerpool.Pool
+	batchMessages           BatchMessagesReader
 	ExecutionProvider
 }
 
+type HistoryCommitmentProviderOpt func(*HistoryCommitmentProvider)
+
+// WithExpansionStore persists the Merkle expansions of block level histories in the given store,
+// which are kept in memory by default.
+func WithExpansionStore(store ExpansionStore) HistoryCommitmentProviderOpt {
+	return func(p *HistoryCommitmentProvider) {
+		p.expansionStore = store
+	}
+}
+
+// WithCommitmentJournal checkpoints the machine hashes collected for a history commitment to the given
+// journal every checkpointInterval hashes, so that a commitment interrupted by a crash resumes from
+// its last checkpoint instead of stepping through the machine from the start.
+func WithCommitmentJournal(journal CommitmentJournal, checkpointInterval uint64) HistoryCommitmentProviderOpt {
+	return func(p *HistoryCommitmentProvider) {
+		if checkpointInterval == 0 {
+			return
+		}
+		p.commitmentJournal = journal
+		p.checkpointInterval = checkpointInterval
+	}
+}
+
+// WithWorkerPools bounds the number of machine hash collections and one step proof collections
+// running concurrently by the given pools. Either may be nil, in which case that kind of job is not
+// bounded. Rescaling the pools must be started by the caller, which the challenge manager does for
+// the pools it creates.
+func WithWorkerPools(commitmentPool, proofPool *workerpool.Pool) HistoryCommitmentProviderOpt {
+	return func(p *HistoryCommitmentProvider) {
+		p.commitmentPool = commitmentPool
+		p.proofPool = proofPool
+	}
+}
+
+// WithBatchMessages bounds the states of block level histories by the messages of their batches read
+// from the given reader, counting the delayed messages each batch reads along with those the
+// sequencer posted. States past the last message of the batches repeat the state after it, rather
+// than being whatever the message state collector returns for them.
+func WithBatchMessages(reader BatchMessagesReader) HistoryCommitmentProviderOpt {
+	return func(p *HistoryCommitmentProvider) {
+		p.batchMessages = reader
+	}
+}
+
 // NewHistoryCommitmentProvider creates an instance of a struct which can compute history commitments
 // over any number of challenge levels for BOLD.
 func NewHistoryCommitmentProvider(
@@ -119,8 +171,9 @@ func NewHistoryCommitmentProvider(
 	challengeLeafHeights []Height,
 	executionProvider ExecutionProvider,
 	apiDB db.Database,
+	opts ...HistoryCommitmentProviderOpt,
 ) *HistoryCommitmentProvider {
-	return &HistoryCommitmentProvider{
+	p := &HistoryCommitmentProvider{
 		l2MessageStateCollector: l2MessageStateCollector,
 		machineHashCollector:    machineHashCollector,
 		proofCollector:          proofCollector,
@@ -128,7 +181,12 @@ func NewHistoryCommitmentProvider(
 		ExecutionProvider:       executionProvider,
 		inFlightRequestCache:    inprogresscache.New[string, []common.Hash](),
 		apiDB:                   apiDB,
+		expansionStore:          NewMemoryExpansionStore(1000),
 	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
 }
 
 // A list of heights that have been validated to be non-empty
@@ -139,6 +197,13 @@ func (p *HistoryCommitmentProvider) UpdateAPIDatabase(apiDB db.Database) {
 	p.apiDB = apiDB
 }
 
+// UpdateWorkerPools replaces the pools bounding machine hash and one step proof collections, as
+// WithWorkerPools does. It must be called before the provider is used.
+func (p *HistoryCommitmentProvider) UpdateWorkerPools(commitmentPool, proofPool *workerpool.Pool) {
+	p.commitmentPool = commitmentPool
+	p.proofPool = proofPool
+}
+
 // HistoryCommitment computes a Merklelized commitment over a set of hashes
 // at specified challenge levels. For block challenges, for example, this is a set
 // of machine hashes corresponding each message in a range N to M.
@@ -150,7 +215,12 @@ func (p *HistoryCommitmentProvider) HistoryCommitment(
 	if err != nil {
 		return commitments.History{}, err
 	}
-	return commitments.New(hashes)
+	// Block level histories grow as the chain advances, so we extend the prefix
+	// of the history we last committed to rather than rebuilding it.
+	if len(req.UpperChallengeOriginHeights) == 0 && len(hashes) > 0 {
+		return p.blockHistory(ctx, req, hashes)
+	}
+	return commitments.NewContext(ctx, hashes)
 }
 
 func (p *HistoryCommitmentProvider) historyCommitmentImpl(
@@ -166,6 +236,9 @@ func (p *HistoryCommitmentProvider) historyCommitmentImpl(
 	// those states and return a commitment for them.
 	var fromBlockChallengeHeight Height
 	if len(validatedHeights) == 0 {
+		if p.batchMessages != nil {
+			return p.blockStatesWithinBatches(ctx, req)
+		}
 		hashes, hashesErr := p.l2MessageStateCollector.L2MessageStatesUpTo(
 			ctx,
 			req.FromHeight,
@@ -258,10 +331,71 @@ func (p *HistoryCommitmentProvider) historyCommitmentImpl(
 			// Eg https://github.com/OffchainLabs/nitro/blob/ab6790a9e33884c3b4e81de2a97dae5bf904266e/das/restful_server.go#L30
 			metrics.GetOrRegisterHistogram("arb/state_provider/collect_machine_hashes/step_size_"+strconv.Itoa(int(stepSize))+"/duration", nil, metrics.NewUniformSample(100)).Update(time.Since(startTime).Nanoseconds())
 		}()
-		return p.machineHashCollector.CollectMachineHashes(ctx, cfg)
+		var hashes []common.Hash
+		err := p.commitmentPool.Run(ctx, func() error {
+			var collectErr error
+			if p.commitmentJournal != nil {
+				hashes, collectErr = p.collectMachineHashesWithJournal(ctx, cfg)
+			} else {
+				hashes, collectErr = p.machineHashCollector.CollectMachineHashes(ctx, cfg)
+			}
+			return collectErr
+		})
+		return hashes, err
 	})
 }
 
+// Collects the states of a block level history only up to the state after the last message of its
+// batches, including the delayed messages they read, and pads the rest of the history with that
+// state as the protocol does.
+func (p *HistoryCommitmentProvider) blockStatesWithinBatches(ctx context.Context, req *HistoryCommitmentRequest) ([]common.Hash, error) {
+	boundaries, err := ReadBatchBoundaries(ctx, p.batchMessages, req.FromBatch, req.ToBatch)
+	if err != nil {
+		return nil, err
+	}
+	var upTo Height
+	if req.UpToHeight.IsSome() {
+		upTo = req.UpToHeight.Unwrap()
+	} else {
+		if len(p.challengeLeafHeights) == 0 {
+			return nil, errors.New("no challenge leaf heights")
+		}
+		upTo = p.challengeLeafHeights[0]
+	}
+	if upTo < req.FromHeight {
+		return nil, fmt.Errorf("end height %d is less than start height %d", upTo, req.FromHeight)
+	}
+	// The state at height n is after the first n messages, so the last one is at the message count.
+	lastHeight := Height(boundaries.MessageCount())
+	collectFrom := min(req.FromHeight, lastHeight)
+	collectTo := min(upTo, lastHeight)
+	hashes, err := p.l2MessageStateCollector.L2MessageStatesUpTo(
+		ctx,
+		collectFrom,
+		option.Some(collectTo),
+		req.FromBatch,
+		req.ToBatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(hashes)) != uint64(collectTo-collectFrom)+1 {
+		return nil, fmt.Errorf(
+			"%w: collected %d states from height %d to %d, but batches %d to %d have %d messages",
+			ErrInvalidBatchBoundaries, len(hashes), collectFrom, collectTo, req.FromBatch, req.ToBatch, boundaries.MessageCount(),
+		)
+	}
+	if collectFrom < req.FromHeight {
+		// The whole history is past the last message.
+		hashes = hashes[len(hashes)-1:]
+	}
+	last := hashes[len(hashes)-1]
+	for uint64(len(hashes)) < uint64(upTo-req.FromHeight)+1 {
+		hashes = append(hashes, last)
+	}
+	return hashes, nil
+}
+
 // AgreesWithHistoryCommitment checks if the l2 state provider agrees with a specified start and end
 // history commitment for a type of edge under a specified assertion challenge. It returns an agreement struct
 // which informs the caller whether (a) we agree with the start commitment, and whether (b) the edge is honest, meaning
@@ -276,20 +410,26 @@ func (p *HistoryCommitmentProvider) AgreesWithHistoryCommitment(
 	var err error
 	switch challengeLevel {
 	case protocol.NewBlockChallengeLevel():
-		localCommit, err = p.HistoryCommitment(
-			ctx,
-			&HistoryCommitmentRequest{
-				WasmModuleRoot:              historyCommitMetadata.WasmModuleRoot,
-				FromBatch:                   historyCommitMetadata.FromBatch,
-				ToBatch:                     historyCommitMetadata.ToBatch,
-				UpperChallengeOriginHeights: []Height{},
-				FromHeight:                  historyCommitMetadata.FromHeight,
-				UpToHeight:                  option.Some[Height](historyCommitMetadata.FromHeight + Height(commit.Height)),
-			},
-		)
+		req := &HistoryCommitmentRequest{
+			WasmModuleRoot:              historyCommitMetadata.WasmModuleRoot,
+			FromBatch:                   historyCommitMetadata.FromBatch,
+			ToBatch:                     historyCommitMetadata.ToBatch,
+			UpperChallengeOriginHeights: []Height{},
+			FromHeight:                  historyCommitMetadata.FromHeight,
+			UpToHeight:                  option.Some[Height](historyCommitMetadata.FromHeight + Height(commit.Height)),
+		}
+		hashes, err := p.historyCommitmentImpl(ctx, req)
 		if err != nil {
 			return false, err
 		}
+		if len(hashes) == 0 {
+			return false, nil
+		}
+		local, err := p.blockHistory(ctx, req, hashes)
+		if err != nil {
+			return false, err
+		}
+		return local.Height == commit.Height && local.Merkle == commit.MerkleRoot, nil
 	default:
 		localCommit, err = p.HistoryCommitment(
 			ctx,
@@ -309,14 +449,8 @@ func (p *HistoryCommitmentProvider) AgreesWithHistoryCommitment(
 	return localCommit.Height == commit.Height && localCommit.Merkle == commit.MerkleRoot, nil
 }
 
-var (
-	b32Arr, _ = abi.NewType("bytes32[]", "", nil)
-	// ProofArgs for submission to the protocol.
-	ProofArgs = abi.Arguments{
-		{Type: b32Arr, Name: "prefixExpansion"},
-		{Type: b32Arr, Name: "prefixProof"},
-	}
-)
+// ProofArgs for submission to the protocol.
+var ProofArgs = prefixproofs.ProofArgs
 
 // PrefixProof allows a caller to provide a proof that, given heights N < M,
 // that the history commitment for height N is a Merkle prefix of the commitment at height M.
@@ -374,43 +508,30 @@ func (p *HistoryCommitmentProvider) PrefixProof(
 		return nil, fmt.Errorf("low prefix size %d was greater than high prefix size %d", lowCommitmentNumLeaves, highCommitmentNumLeaves)
 	}
 
-	prefixExpansion, err := prefixproofs.ExpansionFromLeaves(leaves[:lowCommitmentNumLeaves])
+	proof, err := prefixproofs.GenerateEncodedPrefixProof(ctx, leaves[:highCommitmentNumLeaves], lowCommitmentNumLeaves)
 	if err != nil {
 		return nil, err
 	}
-	prefixProof, err := prefixproofs.GeneratePrefixProof(
-		lowCommitmentNumLeaves,
-		prefixExpansion,
-		leaves[lowCommitmentNumLeaves:highCommitmentNumLeaves],
-		prefixproofs.RootFetcherFromExpansion,
-	)
+	bigCommit, err := commitments.NewContext(ctx, leaves[:highCommitmentNumLeaves])
 	if err != nil {
 		return nil, err
 	}
-	bigCommit, err := commitments.New(leaves[:highCommitmentNumLeaves])
+	prefixCommit, err := commitments.NewContext(ctx, leaves[:lowCommitmentNumLeaves])
 	if err != nil {
 		return nil, err
 	}
-
-	prefixCommit, err := commitments.New(leaves[:lowCommitmentNumLeaves])
-	if err != nil {
-		return nil, err
-	}
-	_, numRead := prefixproofs.MerkleExpansionFromCompact(prefixProof, lowCommitmentNumLeaves)
-	onlyProof := prefixProof[numRead:]
-
-	// We verify our prefix proof before an onchain submission as an extra safety-check.
-	if err = prefixproofs.VerifyPrefixProof(&prefixproofs.VerifyPrefixProofConfig{
-		PreRoot:      prefixCommit.Merkle,
-		PreSize:      lowCommitmentNumLeaves,
-		PostRoot:     bigCommit.Merkle,
-		PostSize:     highCommitmentNumLeaves,
-		PreExpansion: prefixExpansion,
-		PrefixProof:  onlyProof,
-	}); err != nil {
+	// We verify our prefix proof against the history commitments before an onchain submission as an
+	// extra safety-check.
+	if err = prefixproofs.VerifyEncodedPrefixProof(
+		prefixCommit.Merkle,
+		lowCommitmentNumLeaves,
+		bigCommit.Merkle,
+		highCommitmentNumLeaves,
+		proof,
+	); err != nil {
 		return nil, fmt.Errorf("could not verify prefix proof locally: %w", err)
 	}
-	return ProofArgs.Pack(&prefixExpansion, &onlyProof)
+	return proof, nil
 }
 
 func (p *HistoryCommitmentProvider) OneStepProofData(
@@ -426,7 +547,10 @@ func (p *HistoryCommitmentProvider) OneStepProofData(
 	if len(startHeights) < 1 {
 		return nil, nil, nil, fmt.Errorf("upper challenge origin heights must have at least length 1, got %d", len(startHeights))
 	}
-	endCommit, err := p.HistoryCommitment(
+	// The histories before and after the step share all but their last leaf, so we collect the
+	// leaves of the history after the step once and prove the last leaf of both histories from a
+	// single tree over them.
+	hashes, err := p.historyCommitmentImpl(
 		ctx,
 		&HistoryCommitmentRequest{
 			WasmModuleRoot:              wasmModuleRoot,
@@ -440,17 +564,11 @@ func (p *HistoryCommitmentProvider) OneStepProofData(
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	startCommit, err := p.HistoryCommitment(
-		ctx,
-		&HistoryCommitmentRequest{
-			WasmModuleRoot:              wasmModuleRoot,
-			FromBatch:                   fromBatch,
-			ToBatch:                     toBatch,
-			UpperChallengeOriginHeights: startHeights,
-			FromHeight:                  fromHeight,
-			UpToHeight:                  option.Some(upToHeight),
-		},
-	)
+	if len(hashes) < 2 {
+		return nil, nil, nil, fmt.Errorf("expected at least 2 hashes for a one step proof, got %d", len(hashes))
+	}
+	numHashes := uint64(len(hashes))
+	proofs, err := inclusionproofs.GenerateLastLeafProofsContext(ctx, hashes, numHashes-1, numHashes)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -463,17 +581,22 @@ func (p *HistoryCommitmentProvider) OneStepProofData(
 	}
 	machineIndex += OpcodeIndex(upToHeight)
 
-	osp, err := p.proofCollector.CollectProof(ctx, wasmModuleRoot, fromBatch, startHeights[0], machineIndex)
+	var osp []byte
+	err = p.proofPool.Run(ctx, func() error {
+		var collectErr error
+		osp, collectErr = p.proofCollector.CollectProof(ctx, wasmModuleRoot, fromBatch, startHeights[0], machineIndex)
+		return collectErr
+	})
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	data := &protocol.OneStepData{
-		BeforeHash: startCommit.LastLeaf,
-		AfterHash:  endCommit.LastLeaf,
+		BeforeHash: hashes[numHashes-2],
+		AfterHash:  hashes[numHashes-1],
 		Proof:      osp,
 	}
-	return data, startCommit.LastLeafProof, endCommit.LastLeafProof, nil
+	return data, proofs[0], proofs[1], nil
 }
 
 // Computes the required number of hashes for a history commitment
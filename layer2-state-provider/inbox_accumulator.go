@@ -0,0 +1,142 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	ErrInboxAccumulatorMismatch = errors.New("inbox accumulator does not match the sequencer batches")
+
+	inboxAccumulatorMismatchCounter = metrics.NewRegisteredCounter("arb/validator/provider/inbox_accumulator_mismatch", nil)
+)
+
+// The flag the sequencer inbox prepends to the versioned hashes of a batch posted in blobs.
+const dataBlobHeaderFlag = 0x50
+
+// SequencerBatch is the data the sequencer inbox hashes into the accumulator of a batch.
+type SequencerBatch struct {
+	MinTimestamp             uint64
+	MaxTimestamp             uint64
+	MinBlockNumber           uint64
+	MaxBlockNumber           uint64
+	AfterDelayedMessagesRead uint64
+	// The calldata of the batch, if it was posted as calldata.
+	Data []byte
+	// The versioned hashes of the blobs of the batch, if it was posted in blobs.
+	BlobHashes []common.Hash
+	// The delayed inbox accumulator after the last delayed message the batch read, or the zero
+	// hash if it read none.
+	DelayedAcc common.Hash
+}
+
+// DataHash is the hash of the batch's header and data, as formed by the sequencer inbox.
+func (b *SequencerBatch) DataHash() common.Hash {
+	header := make([]byte, 0, 40)
+	for _, field := range []uint64{b.MinTimestamp, b.MaxTimestamp, b.MinBlockNumber, b.MaxBlockNumber, b.AfterDelayedMessagesRead} {
+		header = binary.BigEndian.AppendUint64(header, field)
+	}
+	if len(b.BlobHashes) == 0 {
+		return crypto.Keccak256Hash(header, b.Data)
+	}
+	data := []byte{dataBlobHeaderFlag}
+	for _, h := range b.BlobHashes {
+		data = append(data, h.Bytes()...)
+	}
+	return crypto.Keccak256Hash(header, data)
+}
+
+// NextInboxAccumulator computes the accumulator after a batch from the accumulator before it, as
+// the bridge does when the batch is enqueued.
+func NextInboxAccumulator(beforeAcc common.Hash, batch *SequencerBatch) common.Hash {
+	return crypto.Keccak256Hash(beforeAcc.Bytes(), batch.DataHash().Bytes(), batch.DelayedAcc.Bytes())
+}
+
+// SequencerBatchReader reads the sequencer batches synced by the local node.
+type SequencerBatchReader interface {
+	SequencerBatch(ctx context.Context, batch Batch) (*SequencerBatch, error)
+}
+
+// InboxAccumulatorVerifier reconstructs inbox accumulators from the sequencer batches synced by the
+// local node, so that accumulators taken from other components, such as the state data of an
+// assertion, can be checked before they are submitted onchain. Reconstructed accumulators are
+// remembered, so each batch is only read once as long as verified batches are increasing.
+type InboxAccumulatorVerifier struct {
+	reader SequencerBatchReader
+	lock   sync.Mutex
+	known  map[Batch]common.Hash
+}
+
+type InboxAccumulatorVerifierOpt func(*InboxAccumulatorVerifier)
+
+// WithTrustedInboxAccumulator starts reconstructing accumulators of later batches from the given
+// accumulator, rather than from the first batch.
+func WithTrustedInboxAccumulator(batch Batch, acc common.Hash) InboxAccumulatorVerifierOpt {
+	return func(v *InboxAccumulatorVerifier) {
+		v.known[batch] = acc
+	}
+}
+
+func NewInboxAccumulatorVerifier(reader SequencerBatchReader, opts ...InboxAccumulatorVerifierOpt) *InboxAccumulatorVerifier {
+	v := &InboxAccumulatorVerifier{
+		reader: reader,
+		known:  make(map[Batch]common.Hash),
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+// BatchAccumulator reconstructs the accumulator after the batch with the given sequence number,
+// from the closest known accumulator of an earlier batch.
+func (v *InboxAccumulatorVerifier) BatchAccumulator(ctx context.Context, batch Batch) (common.Hash, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if acc, ok := v.known[batch]; ok {
+		return acc, nil
+	}
+	from := Batch(0)
+	acc := common.Hash{}
+	for knownBatch, knownAcc := range v.known {
+		if knownBatch < batch && knownBatch+1 > from {
+			from = knownBatch + 1
+			acc = knownAcc
+		}
+	}
+	for b := from; b <= batch; b++ {
+		contents, err := v.reader.SequencerBatch(ctx, b)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("could not read sequencer batch %d: %w", b, err)
+		}
+		acc = NextInboxAccumulator(acc, contents)
+	}
+	v.known[batch] = acc
+	return acc, nil
+}
+
+// Verify checks that an accumulator claimed after the given batch matches the one reconstructed
+// from the local sequencer batches, returning an error wrapping ErrInboxAccumulatorMismatch if not.
+func (v *InboxAccumulatorVerifier) Verify(ctx context.Context, batch Batch, claimedAcc common.Hash) error {
+	acc, err := v.BatchAccumulator(ctx, batch)
+	if err != nil {
+		return err
+	}
+	if acc != claimedAcc {
+		inboxAccumulatorMismatchCounter.Inc(1)
+		log.Error("Inbox accumulator does not match the local sequencer batches", "batch", batch, "claimed", claimedAcc, "local", acc)
+		return fmt.Errorf("%w: batch %d has accumulator %#x, but %#x was claimed", ErrInboxAccumulatorMismatch, batch, acc, claimedAcc)
+	}
+	return nil
+}
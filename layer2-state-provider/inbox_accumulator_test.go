@@ -0,0 +1,116 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBatchReader struct {
+	batches []*SequencerBatch
+	reads   int
+}
+
+func (m *mockBatchReader) SequencerBatch(_ context.Context, batch Batch) (*SequencerBatch, error) {
+	m.reads++
+	if int(batch) >= len(m.batches) {
+		return nil, errors.New("batch not found")
+	}
+	return m.batches[batch], nil
+}
+
+func sequencerBatches(n int) []*SequencerBatch {
+	batches := make([]*SequencerBatch, n)
+	for i := range batches {
+		batches[i] = &SequencerBatch{
+			MinTimestamp:             uint64(i),
+			MaxTimestamp:             uint64(i) + 100,
+			MinBlockNumber:           uint64(i) * 2,
+			MaxBlockNumber:           uint64(i)*2 + 100,
+			AfterDelayedMessagesRead: uint64(i / 2),
+			Data:                     []byte{0, byte(i)},
+		}
+		if i >= 2 {
+			batches[i].DelayedAcc = crypto.Keccak256Hash([]byte{byte(i / 2)})
+		}
+	}
+	return batches
+}
+
+func TestSequencerBatch_DataHash(t *testing.T) {
+	batch := &SequencerBatch{
+		MinTimestamp:             1,
+		MaxTimestamp:             2,
+		MinBlockNumber:           3,
+		MaxBlockNumber:           4,
+		AfterDelayedMessagesRead: 5,
+		Data:                     []byte{0xaa},
+	}
+	// The header packs each bound and the delayed message count as 8 byte big endian integers.
+	header := hexutil.MustDecode("0x" +
+		"0000000000000001" + "0000000000000002" + "0000000000000003" + "0000000000000004" + "0000000000000005")
+	require.Equal(t, crypto.Keccak256Hash(header, []byte{0xaa}), batch.DataHash())
+
+	batch.Data = nil
+	require.Equal(t, crypto.Keccak256Hash(header), batch.DataHash())
+
+	blobHash := common.HexToHash("0x01")
+	batch.BlobHashes = []common.Hash{blobHash}
+	require.Equal(t, crypto.Keccak256Hash(header, []byte{0x50}, blobHash.Bytes()), batch.DataHash())
+}
+
+func TestInboxAccumulatorVerifier(t *testing.T) {
+	ctx := context.Background()
+	batches := sequencerBatches(10)
+	accs := make([]common.Hash, len(batches))
+	before := common.Hash{}
+	for i, b := range batches {
+		accs[i] = crypto.Keccak256Hash(before.Bytes(), b.DataHash().Bytes(), b.DelayedAcc.Bytes())
+		before = accs[i]
+	}
+
+	t.Run("reconstructs from the first batch", func(t *testing.T) {
+		reader := &mockBatchReader{batches: batches}
+		v := NewInboxAccumulatorVerifier(reader)
+		require.NoError(t, v.Verify(ctx, 5, accs[5]))
+		require.Equal(t, 6, reader.reads)
+
+		// Later batches continue from the last reconstructed accumulator.
+		require.NoError(t, v.Verify(ctx, 9, accs[9]))
+		require.Equal(t, 10, reader.reads)
+		require.NoError(t, v.Verify(ctx, 5, accs[5]))
+		require.Equal(t, 10, reader.reads)
+	})
+	t.Run("rejects a wrong accumulator", func(t *testing.T) {
+		v := NewInboxAccumulatorVerifier(&mockBatchReader{batches: batches})
+		err := v.Verify(ctx, 3, accs[4])
+		require.ErrorIs(t, err, ErrInboxAccumulatorMismatch)
+		require.ErrorContains(t, err, "batch 3")
+	})
+	t.Run("rejects an accumulator over different batch contents", func(t *testing.T) {
+		tampered := sequencerBatches(10)
+		tampered[2].MaxTimestamp++
+		v := NewInboxAccumulatorVerifier(&mockBatchReader{batches: tampered})
+		require.NoError(t, v.Verify(ctx, 1, accs[1]))
+		require.ErrorIs(t, v.Verify(ctx, 2, accs[2]), ErrInboxAccumulatorMismatch)
+		require.ErrorIs(t, v.Verify(ctx, 8, accs[8]), ErrInboxAccumulatorMismatch)
+	})
+	t.Run("starts from a trusted accumulator", func(t *testing.T) {
+		reader := &mockBatchReader{batches: batches}
+		v := NewInboxAccumulatorVerifier(reader, WithTrustedInboxAccumulator(6, accs[6]))
+		require.NoError(t, v.Verify(ctx, 8, accs[8]))
+		require.Equal(t, 2, reader.reads)
+	})
+	t.Run("missing batch", func(t *testing.T) {
+		v := NewInboxAccumulatorVerifier(&mockBatchReader{batches: batches})
+		require.ErrorContains(t, v.Verify(ctx, 10, common.Hash{}), "could not read sequencer batch 10")
+	})
+}
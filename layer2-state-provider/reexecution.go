@@ -0,0 +1,154 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"fmt"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BatchAccumulatorReader reads the inbox accumulator after the batch with a given sequence number.
+// Accumulators are a hash chain over the batches, so two readers which disagree on a batch also
+// disagree on every batch after it.
+type BatchAccumulatorReader interface {
+	BatchAccumulator(ctx context.Context, batch Batch) (common.Hash, error)
+}
+
+// BlockWindowReexecutor can re-execute a window of blocks, rather than an entire assertion, and
+// reports the batch accumulators the local node executed blocks against.
+type BlockWindowReexecutor interface {
+	BatchAccumulatorReader
+	// ReexecuteBlockWindow re-executes the blocks within the window, populating the caches of
+	// block states and machines needed to compute history commitments and proofs over them.
+	ReexecuteBlockWindow(ctx context.Context, window *ReexecutionWindow) error
+}
+
+// ReexecutionWindow is a range of blocks around a suspected divergence from an assertion. As
+// every message of a batch produces a block, its bounds are given as positions in the inbox.
+type ReexecutionWindow struct {
+	WasmModuleRoot common.Hash
+	// The position of the first block of the window.
+	FromBatch      Batch
+	FromPosInBatch uint64
+	// The position at which the window ends, exclusive.
+	ToBatch      Batch
+	ToPosInBatch uint64
+	// The assertion the window is re-executed for.
+	ClaimId common.Hash
+}
+
+func (w *ReexecutionWindow) String() string {
+	return fmt.Sprintf("%d:%d-%d:%d", w.FromBatch, w.FromPosInBatch, w.ToBatch, w.ToPosInBatch)
+}
+
+// FirstDivergentBatch finds the first batch in the inclusive range [from, to] at which two readers
+// disagree on the batch accumulator, if any, with a binary search.
+func FirstDivergentBatch(
+	ctx context.Context,
+	local,
+	claimed BatchAccumulatorReader,
+	from,
+	to Batch,
+) (option.Option[Batch], error) {
+	none := option.None[Batch]()
+	if from > to {
+		return none, fmt.Errorf("invalid batch range: end %d was < start %d", to, from)
+	}
+	agrees := func(batch Batch) (bool, error) {
+		localAcc, err := local.BatchAccumulator(ctx, batch)
+		if err != nil {
+			return false, fmt.Errorf("could not read local accumulator of batch %d: %w", batch, err)
+		}
+		claimedAcc, err := claimed.BatchAccumulator(ctx, batch)
+		if err != nil {
+			return false, fmt.Errorf("could not read claimed accumulator of batch %d: %w", batch, err)
+		}
+		return localAcc == claimedAcc, nil
+	}
+	lastAgrees, err := agrees(to)
+	if err != nil {
+		return none, err
+	}
+	if lastAgrees {
+		return none, nil
+	}
+	// Invariant: the readers disagree at hi, and agree at every batch before lo.
+	lo, hi := from, to
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		midAgrees, err := agrees(mid)
+		if err != nil {
+			return none, err
+		}
+		if midAgrees {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return option.Some(hi), nil
+}
+
+// FindReexecutionWindow finds the blocks to re-execute when disagreeing with an assertion that
+// claims claimedEnd after start, while the local node expects expectedEnd. The window always ends
+// at expectedEnd, and starts at the first batch where the two may differ. Comparing batch
+// accumulators first finds whether the local node executed any of the assertion's batches against
+// different inputs than the onchain inbox, in which case its blocks from the first such batch
+// onwards are suspect. Otherwise, both sides executed the same inputs, and the first global state
+// mismatch is in the last batch consumed by the earlier of the two end states, unless both end at
+// the same position, in which case it can be anywhere in the assertion.
+func FindReexecutionWindow(
+	ctx context.Context,
+	local,
+	claimed BatchAccumulatorReader,
+	start,
+	claimedEnd,
+	expectedEnd protocol.GoGlobalState,
+) (*ReexecutionWindow, error) {
+	window := &ReexecutionWindow{
+		FromBatch:      Batch(start.Batch),
+		FromPosInBatch: start.PosInBatch,
+		ToBatch:        Batch(expectedEnd.Batch),
+		ToPosInBatch:   expectedEnd.PosInBatch,
+	}
+	startFrom := func(batch Batch) *ReexecutionWindow {
+		if batch > window.FromBatch {
+			window.FromBatch = batch
+			window.FromPosInBatch = 0
+		}
+		return window
+	}
+	lastBatch := max(BatchCountForState(claimedEnd), BatchCountForState(expectedEnd))
+	if lastBatch > Batch(start.Batch) {
+		divergent, err := FirstDivergentBatch(ctx, local, claimed, Batch(start.Batch), lastBatch-1)
+		if err != nil {
+			return nil, err
+		}
+		if divergent.IsSome() {
+			return startFrom(divergent.Unwrap()), nil
+		}
+	}
+	if claimedEnd.Batch == expectedEnd.Batch && claimedEnd.PosInBatch == expectedEnd.PosInBatch {
+		return window, nil
+	}
+	earlierEnd := expectedEnd
+	if isBefore(claimedEnd, expectedEnd) {
+		earlierEnd = claimedEnd
+	}
+	if BatchCountForState(earlierEnd) == 0 {
+		return window, nil
+	}
+	return startFrom(BatchCountForState(earlierEnd) - 1), nil
+}
+
+func isBefore(a, b protocol.GoGlobalState) bool {
+	if a.Batch != b.Batch {
+		return a.Batch < b.Batch
+	}
+	return a.PosInBatch < b.PosInBatch
+}
@@ -0,0 +1,110 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Accumulates the hash of each batch, switching to different batch contents from divergeAt onwards.
+type mockAccumulators struct {
+	numBatches uint64
+	divergeAt  uint64
+	reads      int
+}
+
+func (m *mockAccumulators) BatchAccumulator(_ context.Context, batch Batch) (common.Hash, error) {
+	m.reads++
+	if uint64(batch) >= m.numBatches {
+		return common.Hash{}, errors.New("batch not found")
+	}
+	acc := common.Hash{}
+	for i := uint64(0); i <= uint64(batch); i++ {
+		contents := []byte{byte(i)}
+		if i >= m.divergeAt {
+			contents = append(contents, 1)
+		}
+		acc = crypto.Keccak256Hash(acc.Bytes(), contents)
+	}
+	return acc, nil
+}
+
+func TestFirstDivergentBatch(t *testing.T) {
+	ctx := context.Background()
+	onchain := &mockAccumulators{numBatches: 100, divergeAt: 100}
+	for _, divergeAt := range []uint64{0, 1, 37, 63, 64, 99} {
+		local := &mockAccumulators{numBatches: 100, divergeAt: divergeAt}
+		divergent, err := FirstDivergentBatch(ctx, local, onchain, 0, 99)
+		require.NoError(t, err)
+		require.Equal(t, Batch(divergeAt), divergent.Unwrap())
+		// A binary search reads far fewer accumulators than the range holds.
+		require.LessOrEqual(t, local.reads, 8)
+	}
+
+	local := &mockAccumulators{numBatches: 100, divergeAt: 50}
+	divergent, err := FirstDivergentBatch(ctx, local, onchain, 10, 49)
+	require.NoError(t, err)
+	require.True(t, divergent.IsNone())
+	divergent, err = FirstDivergentBatch(ctx, local, onchain, 60, 70)
+	require.NoError(t, err)
+	require.Equal(t, Batch(60), divergent.Unwrap())
+
+	_, err = FirstDivergentBatch(ctx, local, onchain, 10, 9)
+	require.ErrorContains(t, err, "invalid batch range")
+	_, err = FirstDivergentBatch(ctx, local, onchain, 10, 100)
+	require.ErrorContains(t, err, "could not read local accumulator of batch 100")
+}
+
+func TestFindReexecutionWindow(t *testing.T) {
+	ctx := context.Background()
+	onchain := &mockAccumulators{numBatches: 100, divergeAt: 100}
+	agreeing := &mockAccumulators{numBatches: 100, divergeAt: 100}
+	start := protocol.GoGlobalState{Batch: 10, PosInBatch: 3}
+	expectedEnd := protocol.GoGlobalState{Batch: 20, PosInBatch: 5}
+
+	t.Run("local node executed different inputs", func(t *testing.T) {
+		local := &mockAccumulators{numBatches: 100, divergeAt: 15}
+		window, err := FindReexecutionWindow(ctx, local, onchain, start, expectedEnd, expectedEnd)
+		require.NoError(t, err)
+		require.Equal(t, "15:0-20:5", window.String())
+	})
+	t.Run("inputs diverge before the assertion", func(t *testing.T) {
+		local := &mockAccumulators{numBatches: 100, divergeAt: 2}
+		window, err := FindReexecutionWindow(ctx, local, onchain, start, expectedEnd, expectedEnd)
+		require.NoError(t, err)
+		require.Equal(t, "10:3-20:5", window.String())
+	})
+	t.Run("same end position", func(t *testing.T) {
+		claimedEnd := expectedEnd
+		claimedEnd.BlockHash = common.BytesToHash([]byte("evil"))
+		window, err := FindReexecutionWindow(ctx, agreeing, onchain, start, claimedEnd, expectedEnd)
+		require.NoError(t, err)
+		require.Equal(t, "10:3-20:5", window.String())
+	})
+	t.Run("assertion stopped early", func(t *testing.T) {
+		claimedEnd := protocol.GoGlobalState{Batch: 17, PosInBatch: 2}
+		window, err := FindReexecutionWindow(ctx, agreeing, onchain, start, claimedEnd, expectedEnd)
+		require.NoError(t, err)
+		require.Equal(t, "17:0-20:5", window.String())
+
+		// An end at the start of a batch consumed none of it.
+		claimedEnd = protocol.GoGlobalState{Batch: 17}
+		window, err = FindReexecutionWindow(ctx, agreeing, onchain, start, claimedEnd, expectedEnd)
+		require.NoError(t, err)
+		require.Equal(t, "16:0-20:5", window.String())
+	})
+	t.Run("assertion went further", func(t *testing.T) {
+		claimedEnd := protocol.GoGlobalState{Batch: 30}
+		window, err := FindReexecutionWindow(ctx, agreeing, onchain, start, claimedEnd, expectedEnd)
+		require.NoError(t, err)
+		require.Equal(t, "20:0-20:5", window.String())
+	})
+}
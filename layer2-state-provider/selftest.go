@@ -0,0 +1,250 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	inclusionproofs "github.com/OffchainLabs/bold/state-commitments/inclusion-proofs"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SelfTestMachine provides the states, machine hashes, and one step proofs the self test runs the
+// proof pipeline over.
+type SelfTestMachine interface {
+	L2MessageStateCollector
+	MachineHashCollector
+	ProofCollector
+}
+
+// OneStepProofVerifier checks that a one step proof at a machine step proves its before hash
+// transitions into its after hash.
+type OneStepProofVerifier interface {
+	VerifyOneStepProof(ctx context.Context, machineStep uint64, data *protocol.OneStepData) error
+}
+
+type selfTestConfig struct {
+	machine        SelfTestMachine
+	leafHeights    []Height
+	wasmModuleRoot common.Hash
+	verifiers      []OneStepProofVerifier
+}
+
+type SelfTestOpt func(*selfTestConfig)
+
+// WithSelfTestMachine runs the self test over a machine with the given leaf heights at each
+// challenge level, rather than over a synthetic one. A machine whose one step proofs a deployed
+// verifier accepts allows checking the proofs against it.
+func WithSelfTestMachine(machine SelfTestMachine, leafHeights []Height, wasmModuleRoot common.Hash) SelfTestOpt {
+	return func(c *selfTestConfig) {
+		c.machine = machine
+		c.leafHeights = leafHeights
+		c.wasmModuleRoot = wasmModuleRoot
+	}
+}
+
+// WithOneStepProofVerifier additionally checks the one step proof of the self test with the given
+// verifier, such as one calling the verifier deployed onchain.
+func WithOneStepProofVerifier(verifier OneStepProofVerifier) SelfTestOpt {
+	return func(c *selfTestConfig) {
+		c.verifiers = append(c.verifiers, verifier)
+	}
+}
+
+// SelfTest runs the proof pipeline end to end on a small machine, synthetic by default, so that a
+// broken pipeline is caught at startup rather than once a challenge needs it. It computes a block
+// level history commitment, generates a prefix proof and a one step proof at the deepest challenge
+// level, and verifies them locally the way the challenge manager contracts would.
+func SelfTest(ctx context.Context, opts ...SelfTestOpt) error {
+	cfg := &selfTestConfig{}
+	for _, o := range opts {
+		o(cfg)
+	}
+	if cfg.machine == nil {
+		synthetic := syntheticMachine{}
+		cfg.machine = synthetic
+		cfg.leafHeights = []Height{8, 4, 4}
+		cfg.verifiers = append(cfg.verifiers, synthetic)
+	}
+	if len(cfg.leafHeights) < 2 {
+		return fmt.Errorf("self test needs at least 2 challenge levels, got %d", len(cfg.leafHeights))
+	}
+	provider := NewHistoryCommitmentProvider(cfg.machine, cfg.machine, cfg.machine, cfg.leafHeights, nil, nil)
+	request := func(originHeights []Height, upTo Height) *HistoryCommitmentRequest {
+		return &HistoryCommitmentRequest{
+			WasmModuleRoot:              cfg.wasmModuleRoot,
+			FromBatch:                   0,
+			ToBatch:                     1,
+			UpperChallengeOriginHeights: originHeights,
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(upTo),
+		}
+	}
+
+	blockCommit, err := provider.HistoryCommitment(ctx, request(nil, cfg.leafHeights[0]))
+	if err != nil {
+		return fmt.Errorf("could not compute block history commitment: %w", err)
+	}
+	if err = verifyLeaf(blockCommit.Merkle, blockCommit.LastLeaf, blockCommit.Height, blockCommit.LastLeafProof); err != nil {
+		return fmt.Errorf("last leaf of block history commitment: %w", err)
+	}
+
+	// Proofs are generated at the deepest challenge level, at the start of every level above it.
+	originHeights := make([]Height, len(cfg.leafHeights)-1)
+	leafHeight := cfg.leafHeights[len(cfg.leafHeights)-1]
+	commit := func(upTo Height) (commitments.History, error) {
+		return provider.HistoryCommitment(ctx, request(originHeights, upTo))
+	}
+	prefixHeight := leafHeight / 2
+	packed, err := provider.PrefixProof(ctx, request(originHeights, leafHeight), prefixHeight)
+	if err != nil {
+		return fmt.Errorf("could not generate prefix proof: %w", err)
+	}
+	args, err := ProofArgs.Unpack(packed)
+	if err != nil {
+		return fmt.Errorf("could not decode prefix proof: %w", err)
+	}
+	preExpansion, ok := args[0].([][32]byte)
+	if !ok {
+		return fmt.Errorf("prefix expansion of prefix proof has type %T", args[0])
+	}
+	proof, ok := args[1].([][32]byte)
+	if !ok {
+		return fmt.Errorf("prefix proof has type %T", args[1])
+	}
+	preCommit, err := commit(prefixHeight)
+	if err != nil {
+		return fmt.Errorf("could not compute history commitment: %w", err)
+	}
+	postCommit, err := commit(leafHeight)
+	if err != nil {
+		return fmt.Errorf("could not compute history commitment: %w", err)
+	}
+	if err = prefixproofs.VerifyPrefixProof(&prefixproofs.VerifyPrefixProofConfig{
+		PreRoot:      preCommit.Merkle,
+		PreSize:      uint64(prefixHeight) + 1,
+		PostRoot:     postCommit.Merkle,
+		PostSize:     uint64(leafHeight) + 1,
+		PreExpansion: toHashes(preExpansion),
+		PrefixProof:  toHashes(proof),
+	}); err != nil {
+		return fmt.Errorf("could not verify prefix proof: %w", err)
+	}
+
+	const machineStep = 0
+	data, beforeProof, afterProof, err := provider.OneStepProofData(ctx, cfg.wasmModuleRoot, 0, 1, originHeights, 0, machineStep)
+	if err != nil {
+		return fmt.Errorf("could not generate one step proof: %w", err)
+	}
+	beforeCommit, err := commit(machineStep)
+	if err != nil {
+		return fmt.Errorf("could not compute history commitment: %w", err)
+	}
+	afterCommit, err := commit(machineStep + 1)
+	if err != nil {
+		return fmt.Errorf("could not compute history commitment: %w", err)
+	}
+	if err = verifyLeaf(beforeCommit.Merkle, data.BeforeHash, machineStep, beforeProof); err != nil {
+		return fmt.Errorf("before hash of one step proof: %w", err)
+	}
+	if err = verifyLeaf(afterCommit.Merkle, data.AfterHash, machineStep+1, afterProof); err != nil {
+		return fmt.Errorf("after hash of one step proof: %w", err)
+	}
+	for _, verifier := range cfg.verifiers {
+		if err = verifier.VerifyOneStepProof(ctx, machineStep, data); err != nil {
+			return fmt.Errorf("could not verify one step proof: %w", err)
+		}
+	}
+	return nil
+}
+
+func verifyLeaf(root, leaf common.Hash, index uint64, proof []common.Hash) error {
+	computed, err := inclusionproofs.CalculateRootFromProof(proof, index, leaf)
+	if err != nil {
+		return err
+	}
+	if computed != root {
+		return fmt.Errorf("leaf %#x at index %d does not prove into root %#x", leaf, index, root)
+	}
+	return nil
+}
+
+func toHashes(items [][32]byte) []common.Hash {
+	hashes := make([]common.Hash, len(items))
+	for i, item := range items {
+		hashes[i] = item
+	}
+	return hashes
+}
+
+// A machine whose hash at each step of each block is derived from the block and step, and whose
+// one step proofs are the block and step to prove.
+type syntheticMachine struct{}
+
+func (syntheticMachine) hash(blockHeight Height, machineIndex OpcodeIndex) common.Hash {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(blockHeight))
+	binary.BigEndian.PutUint64(buf[8:], uint64(machineIndex))
+	return crypto.Keccak256Hash([]byte("synthetic machine:"), buf[:])
+}
+
+func (m syntheticMachine) L2MessageStatesUpTo(
+	_ context.Context,
+	fromHeight Height,
+	toHeight option.Option[Height],
+	_,
+	_ Batch,
+) ([]common.Hash, error) {
+	if toHeight.IsNone() {
+		return nil, fmt.Errorf("synthetic machine needs a height to collect states up to")
+	}
+	var states []common.Hash
+	for h := fromHeight; h <= toHeight.Unwrap(); h++ {
+		states = append(states, m.hash(h, 0))
+	}
+	return states, nil
+}
+
+func (m syntheticMachine) CollectMachineHashes(_ context.Context, cfg *HashCollectorConfig) ([]common.Hash, error) {
+	hashes := make([]common.Hash, cfg.NumDesiredHashes)
+	for i := range hashes {
+		hashes[i] = m.hash(cfg.BlockChallengeHeight, cfg.MachineStartIndex+OpcodeIndex(uint64(i)*uint64(cfg.StepSize)))
+	}
+	return hashes, nil
+}
+
+func (syntheticMachine) CollectProof(
+	_ context.Context,
+	_ common.Hash,
+	_ Batch,
+	blockChallengeHeight Height,
+	machineIndex OpcodeIndex,
+) ([]byte, error) {
+	proof := make([]byte, 16)
+	binary.BigEndian.PutUint64(proof[:8], uint64(blockChallengeHeight))
+	binary.BigEndian.PutUint64(proof[8:], uint64(machineIndex))
+	return proof, nil
+}
+
+func (m syntheticMachine) VerifyOneStepProof(_ context.Context, _ uint64, data *protocol.OneStepData) error {
+	if len(data.Proof) != 16 {
+		return fmt.Errorf("synthetic one step proof has %d bytes, want 16", len(data.Proof))
+	}
+	blockHeight := Height(binary.BigEndian.Uint64(data.Proof[:8]))
+	machineIndex := OpcodeIndex(binary.BigEndian.Uint64(data.Proof[8:]))
+	if before := m.hash(blockHeight, machineIndex); before != data.BeforeHash {
+		return fmt.Errorf("before hash %#x does not match %#x proven", data.BeforeHash, before)
+	}
+	if after := m.hash(blockHeight, machineIndex+1); after != data.AfterHash {
+		return fmt.Errorf("after hash %#x does not match %#x proven", data.AfterHash, after)
+	}
+	return nil
+}
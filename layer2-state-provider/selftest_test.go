@@ -0,0 +1,53 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package l2stateprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type verifierFunc func(ctx context.Context, machineStep uint64, data *protocol.OneStepData) error
+
+func (f verifierFunc) VerifyOneStepProof(ctx context.Context, machineStep uint64, data *protocol.OneStepData) error {
+	return f(ctx, machineStep, data)
+}
+
+// A synthetic machine whose one step proofs are of the wrong step.
+type offByOneMachine struct {
+	syntheticMachine
+}
+
+func (m offByOneMachine) CollectProof(ctx context.Context, wasmModuleRoot common.Hash, fromBatch Batch, blockChallengeHeight Height, machineIndex OpcodeIndex) ([]byte, error) {
+	return m.syntheticMachine.CollectProof(ctx, wasmModuleRoot, fromBatch, blockChallengeHeight, machineIndex+1)
+}
+
+func TestSelfTest(t *testing.T) {
+	ctx := context.Background()
+	var verified *protocol.OneStepData
+	require.NoError(t, SelfTest(ctx, WithOneStepProofVerifier(verifierFunc(func(_ context.Context, _ uint64, data *protocol.OneStepData) error {
+		verified = data
+		return nil
+	}))))
+	require.NotNil(t, verified)
+	require.NoError(t, syntheticMachine{}.VerifyOneStepProof(ctx, 0, verified))
+
+	rejected := errors.New("rejected")
+	err := SelfTest(ctx, WithOneStepProofVerifier(verifierFunc(func(context.Context, uint64, *protocol.OneStepData) error {
+		return rejected
+	})))
+	require.ErrorIs(t, err, rejected)
+
+	machine := offByOneMachine{}
+	err = SelfTest(ctx, WithSelfTestMachine(machine, []Height{4, 2}, common.Hash{}), WithOneStepProofVerifier(machine))
+	require.ErrorContains(t, err, "could not verify one step proof")
+
+	err = SelfTest(ctx, WithSelfTestMachine(machine, []Height{4}, common.Hash{}))
+	require.ErrorContains(t, err, "at least 2 challenge levels")
+}
@@ -0,0 +1,80 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package wire
+
+import (
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The JSON format, with hashes and proofs as hex strings, used with servers and clients which do
+// not share a version of the binary format.
+
+type jsonHashRangeRequest struct {
+	WasmModuleRoot       common.Hash `json:"wasmModuleRoot"`
+	FromBatch            uint64      `json:"fromBatch"`
+	BlockChallengeHeight uint64      `json:"blockChallengeHeight"`
+	StepHeights          []uint64    `json:"stepHeights"`
+	NumDesiredHashes     uint64      `json:"numDesiredHashes"`
+	MachineStartIndex    uint64      `json:"machineStartIndex"`
+	StepSize             uint64      `json:"stepSize"`
+	ClaimId              common.Hash `json:"claimId"`
+}
+
+func newJsonHashRangeRequest(cfg *l2stateprovider.HashCollectorConfig) *jsonHashRangeRequest {
+	req := &jsonHashRangeRequest{
+		WasmModuleRoot:       cfg.WasmModuleRoot,
+		FromBatch:            uint64(cfg.FromBatch),
+		BlockChallengeHeight: uint64(cfg.BlockChallengeHeight),
+		StepHeights:          make([]uint64, len(cfg.StepHeights)),
+		NumDesiredHashes:     cfg.NumDesiredHashes,
+		MachineStartIndex:    uint64(cfg.MachineStartIndex),
+		StepSize:             uint64(cfg.StepSize),
+		ClaimId:              cfg.ClaimId,
+	}
+	for i, h := range cfg.StepHeights {
+		req.StepHeights[i] = uint64(h)
+	}
+	return req
+}
+
+func (r *jsonHashRangeRequest) config() *l2stateprovider.HashCollectorConfig {
+	cfg := &l2stateprovider.HashCollectorConfig{
+		WasmModuleRoot:       r.WasmModuleRoot,
+		FromBatch:            l2stateprovider.Batch(r.FromBatch),
+		BlockChallengeHeight: l2stateprovider.Height(r.BlockChallengeHeight),
+		NumDesiredHashes:     r.NumDesiredHashes,
+		MachineStartIndex:    l2stateprovider.OpcodeIndex(r.MachineStartIndex),
+		StepSize:             l2stateprovider.StepSize(r.StepSize),
+		ClaimId:              r.ClaimId,
+	}
+	for _, h := range r.StepHeights {
+		cfg.StepHeights = append(cfg.StepHeights, l2stateprovider.Height(h))
+	}
+	return cfg
+}
+
+type jsonProofRequest struct {
+	WasmModuleRoot       common.Hash `json:"wasmModuleRoot"`
+	FromBatch            uint64      `json:"fromBatch"`
+	BlockChallengeHeight uint64      `json:"blockChallengeHeight"`
+	MachineIndex         uint64      `json:"machineIndex"`
+}
+
+type jsonHashesResponse struct {
+	Hashes []common.Hash `json:"hashes"`
+}
+
+type jsonProofResponse struct {
+	Proof hexutil.Bytes `json:"proof"`
+}
+
+type jsonErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type jsonVersionsResponse struct {
+	Versions []Version `json:"versions"`
+}
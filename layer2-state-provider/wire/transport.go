@@ -0,0 +1,408 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	ContentTypeBinary = "application/vnd.bold.wire"
+	ContentTypeJSON   = "application/json"
+
+	VersionsPath  = "/versions"
+	HashRangePath = "/hashes"
+	ProofPath     = "/proof"
+
+	maxRequestSize = 1 << 20
+)
+
+var (
+	_ = l2stateprovider.MachineHashCollector(&Client{})
+	_ = l2stateprovider.ProofCollector(&Client{})
+)
+
+// Negotiate picks the most preferred of the local versions which the remote supports. Returns
+// false if they share no version, in which case JSON is used.
+func Negotiate(local, remote []Version) (Version, bool) {
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r {
+				return l, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Handler serves hash-range and proof requests of clients in the format they negotiated.
+type Handler struct {
+	hashes l2stateprovider.MachineHashCollector
+	proofs l2stateprovider.ProofCollector
+	mux    *http.ServeMux
+}
+
+// NewHandler serving requests from the given collectors.
+func NewHandler(hashes l2stateprovider.MachineHashCollector, proofs l2stateprovider.ProofCollector) *Handler {
+	h := &Handler{
+		hashes: hashes,
+		proofs: proofs,
+		mux:    http.NewServeMux(),
+	}
+	h.mux.HandleFunc(VersionsPath, h.serveVersions)
+	h.mux.HandleFunc(HashRangePath, h.serveHashRange)
+	h.mux.HandleFunc(ProofPath, h.serveProof)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) serveVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, &jsonVersionsResponse{Versions: SupportedVersions()})
+}
+
+func (h *Handler) serveHashRange(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, KindHashRangeRequest, func(req []byte, binary bool) (any, error) {
+		var cfg *l2stateprovider.HashCollectorConfig
+		if binary {
+			var err error
+			if cfg, err = UnmarshalHashRangeRequest(req); err != nil {
+				return nil, err
+			}
+		} else {
+			jsonReq := &jsonHashRangeRequest{}
+			if err := json.Unmarshal(req, jsonReq); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+			}
+			cfg = jsonReq.config()
+		}
+		return h.hashes.CollectMachineHashes(r.Context(), cfg)
+	})
+}
+
+func (h *Handler) serveProof(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, KindProofRequest, func(req []byte, binary bool) (any, error) {
+		var proofReq *ProofRequest
+		if binary {
+			var err error
+			if proofReq, err = UnmarshalProofRequest(req); err != nil {
+				return nil, err
+			}
+		} else {
+			jsonReq := &jsonProofRequest{}
+			if err := json.Unmarshal(req, jsonReq); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+			}
+			proofReq = &ProofRequest{
+				WasmModuleRoot:       jsonReq.WasmModuleRoot,
+				FromBatch:            l2stateprovider.Batch(jsonReq.FromBatch),
+				BlockChallengeHeight: l2stateprovider.Height(jsonReq.BlockChallengeHeight),
+				MachineIndex:         l2stateprovider.OpcodeIndex(jsonReq.MachineIndex),
+			}
+		}
+		return h.proofs.CollectProof(r.Context(), proofReq.WasmModuleRoot, proofReq.FromBatch, proofReq.BlockChallengeHeight, proofReq.MachineIndex)
+	})
+}
+
+// Decodes a request in the format given by its content type, and responds in the same format. The
+// handle function returns either hashes or a proof.
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, kind Kind, handle func(req []byte, binary bool) (any, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestSize {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !isBinary(r.Header.Get("Content-Type")) {
+		resp, handleErr := handle(body, false)
+		if handleErr != nil {
+			writeJSON(w, errorStatus(handleErr), &jsonErrorResponse{Error: handleErr.Error()})
+			return
+		}
+		switch resp := resp.(type) {
+		case []common.Hash:
+			writeJSON(w, http.StatusOK, &jsonHashesResponse{Hashes: resp})
+		case []byte:
+			writeJSON(w, http.StatusOK, &jsonProofResponse{Proof: resp})
+		}
+		return
+	}
+	envelope, err := UnmarshalEnvelope(body)
+	if err != nil {
+		writeEnvelope(w, http.StatusBadRequest, &Envelope{Version: Version1, Kind: KindError, Payload: []byte(err.Error())})
+		return
+	}
+	payload, err := envelope.Open(kind)
+	if err != nil {
+		version := envelope.Version
+		if !supported(version) {
+			version = Version1
+		}
+		writeEnvelope(w, errorStatus(err), &Envelope{Version: version, Kind: KindError, Payload: []byte(err.Error())})
+		return
+	}
+	resp, err := handle(payload, true)
+	if err != nil {
+		writeEnvelope(w, errorStatus(err), &Envelope{Version: envelope.Version, Kind: KindError, Payload: []byte(err.Error())})
+		return
+	}
+	switch resp := resp.(type) {
+	case []common.Hash:
+		writeEnvelope(w, http.StatusOK, &Envelope{Version: envelope.Version, Kind: KindHashRangeResponse, Payload: MarshalHashes(resp)})
+	case []byte:
+		writeEnvelope(w, http.StatusOK, &Envelope{Version: envelope.Version, Kind: KindProofResponse, Payload: resp})
+	}
+}
+
+// Client requests hashes and proofs from a remote state provider, in the binary format if it
+// shares a version with the server, and in JSON otherwise.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	versions   []Version
+
+	lock       sync.Mutex
+	negotiated bool
+	version    Version
+	binary     bool
+}
+
+type Opt func(*Client)
+
+// WithHTTPClient sets the client requests are made with.
+func WithHTTPClient(c *http.Client) Opt {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithVersions sets the versions of the binary format the client may use, from the most
+// preferred. Defaults to SupportedVersions, and none forces JSON.
+func WithVersions(versions ...Version) Opt {
+	return func(client *Client) {
+		client.versions = versions
+	}
+}
+
+// NewClient of the state provider served at a base URL.
+func NewClient(url string, opts ...Opt) *Client {
+	c := &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: http.DefaultClient,
+		versions:   SupportedVersions(),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Format negotiates the format with the server once, and returns the version of the binary format
+// used, or false if requests are made in JSON. Servers without a versions endpoint only speak JSON.
+func (c *Client) Format(ctx context.Context) (Version, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.negotiated {
+		return c.version, c.binary, nil
+	}
+	if len(c.versions) > 0 {
+		remote, err := c.remoteVersions(ctx)
+		if err != nil {
+			return 0, false, err
+		}
+		c.version, c.binary = Negotiate(c.versions, remote)
+	}
+	c.negotiated = true
+	log.Debug("Negotiated state provider wire format", "url", c.url, "binary", c.binary, "version", c.version)
+	return c.version, c.binary, nil
+}
+
+func (c *Client) remoteVersions(ctx context.Context) ([]Version, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+VersionsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get state provider wire versions")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting state provider wire versions returned status %d", resp.StatusCode)
+	}
+	versions := &jsonVersionsResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(versions); err != nil {
+		return nil, errors.Wrap(err, "could not decode state provider wire versions")
+	}
+	return versions.Versions, nil
+}
+
+// CollectMachineHashes requests the machine hashes of a range from the state provider.
+func (c *Client) CollectMachineHashes(ctx context.Context, cfg *l2stateprovider.HashCollectorConfig) ([]common.Hash, error) {
+	version, binary, err := c.Format(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !binary {
+		resp := &jsonHashesResponse{}
+		if err = c.postJSON(ctx, HashRangePath, newJsonHashRangeRequest(cfg), resp); err != nil {
+			return nil, err
+		}
+		return resp.Hashes, nil
+	}
+	payload, err := c.postBinary(ctx, HashRangePath, &Envelope{
+		Version: version,
+		Kind:    KindHashRangeRequest,
+		Payload: MarshalHashRangeRequest(cfg),
+	}, KindHashRangeResponse)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalHashes(payload)
+}
+
+// CollectProof requests the one-step proof of a machine at an opcode index from the state provider.
+func (c *Client) CollectProof(
+	ctx context.Context,
+	wasmModuleRoot common.Hash,
+	fromBatch l2stateprovider.Batch,
+	blockChallengeHeight l2stateprovider.Height,
+	machineIndex l2stateprovider.OpcodeIndex,
+) ([]byte, error) {
+	version, binary, err := c.Format(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !binary {
+		resp := &jsonProofResponse{}
+		if err = c.postJSON(ctx, ProofPath, &jsonProofRequest{
+			WasmModuleRoot:       wasmModuleRoot,
+			FromBatch:            uint64(fromBatch),
+			BlockChallengeHeight: uint64(blockChallengeHeight),
+			MachineIndex:         uint64(machineIndex),
+		}, resp); err != nil {
+			return nil, err
+		}
+		return resp.Proof, nil
+	}
+	return c.postBinary(ctx, ProofPath, &Envelope{
+		Version: version,
+		Kind:    KindProofRequest,
+		Payload: MarshalProofRequest(&ProofRequest{
+			WasmModuleRoot:       wasmModuleRoot,
+			FromBatch:            fromBatch,
+			BlockChallengeHeight: blockChallengeHeight,
+			MachineIndex:         machineIndex,
+		}),
+	}, KindProofResponse)
+}
+
+func (c *Client) postJSON(ctx context.Context, path string, req any, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	respBody, status, err := c.post(ctx, path, ContentTypeJSON, body)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		errResp := &jsonErrorResponse{}
+		if json.Unmarshal(respBody, errResp) == nil && errResp.Error != "" {
+			return &RemoteError{Message: errResp.Error}
+		}
+		return fmt.Errorf("state provider request to %s returned status %d", path, status)
+	}
+	return json.Unmarshal(respBody, resp)
+}
+
+func (c *Client) postBinary(ctx context.Context, path string, req *Envelope, kind Kind) ([]byte, error) {
+	respBody, status, err := c.post(ctx, path, ContentTypeBinary, req.Marshal())
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := UnmarshalEnvelope(respBody)
+	if err != nil {
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("state provider request to %s returned status %d", path, status)
+		}
+		return nil, err
+	}
+	return envelope.Open(kind)
+}
+
+func (c *Client) post(ctx context.Context, path string, contentType string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "state provider request to %s failed", path)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "could not read state provider response to %s", path)
+	}
+	return respBody, resp.StatusCode, nil
+}
+
+func isBinary(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentTypeBinary
+}
+
+func errorStatus(err error) int {
+	if errors.Is(err, ErrMalformed) || errors.Is(err, ErrUnsupportedVersion) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("Could not write state provider response", "err", err)
+	}
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, e *Envelope) {
+	w.Header().Set("Content-Type", ContentTypeBinary)
+	w.WriteHeader(status)
+	if _, err := w.Write(e.Marshal()); err != nil {
+		log.Error("Could not write state provider response", "err", err)
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package wire defines the formats a remote state provider exchanges hash-range and proof requests
+// in. Besides JSON with hex strings, which is slow to encode and decode for millions of hashes, a
+// compact binary format is defined: messages are wrapped in a protobuf envelope, and hashes are
+// sent as a length-prefixed sequence of raw 32-byte words. The binary format is versioned, and
+// clients negotiate the version with the server before their first request.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Version of the binary format.
+type Version uint32
+
+const (
+	// Version1 wraps messages in a protobuf envelope and sends hashes as length-prefixed words.
+	Version1 Version = 1
+)
+
+// SupportedVersions of the binary format, from the most preferred.
+func SupportedVersions() []Version {
+	return []Version{Version1}
+}
+
+// Kind of message in an envelope.
+type Kind uint32
+
+const (
+	KindHashRangeRequest Kind = iota + 1
+	KindHashRangeResponse
+	KindProofRequest
+	KindProofResponse
+	KindError
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindHashRangeRequest:
+		return "hash_range_request"
+	case KindHashRangeResponse:
+		return "hash_range_response"
+	case KindProofRequest:
+		return "proof_request"
+	case KindProofResponse:
+		return "proof_response"
+	case KindError:
+		return "error"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint32(k))
+	}
+}
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported wire format version")
+	ErrMalformed          = errors.New("malformed wire message")
+)
+
+// ProofRequest asks for the one-step proof of a machine at an opcode index.
+type ProofRequest struct {
+	WasmModuleRoot       common.Hash
+	FromBatch            l2stateprovider.Batch
+	BlockChallengeHeight l2stateprovider.Height
+	MachineIndex         l2stateprovider.OpcodeIndex
+}
+
+// Envelope wraps the payload of every binary message. It is encoded as the protobuf message
+//
+//	message Envelope {
+//	  uint32 version = 1;
+//	  uint32 kind = 2;
+//	  bytes payload = 3;
+//	}
+type Envelope struct {
+	Version Version
+	Kind    Kind
+	Payload []byte
+}
+
+const (
+	envelopeVersionField protowire.Number = 1
+	envelopeKindField    protowire.Number = 2
+	envelopePayloadField protowire.Number = 3
+)
+
+// Marshal the envelope in the protobuf wire format.
+func (e *Envelope) Marshal() []byte {
+	b := make([]byte, 0, len(e.Payload)+16)
+	b = protowire.AppendTag(b, envelopeVersionField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(e.Version))
+	b = protowire.AppendTag(b, envelopeKindField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(e.Kind))
+	b = protowire.AppendTag(b, envelopePayloadField, protowire.BytesType)
+	b = protowire.AppendBytes(b, e.Payload)
+	return b
+}
+
+// UnmarshalEnvelope decodes an envelope, skipping unknown fields as protobuf does. The payload
+// references the given bytes.
+func UnmarshalEnvelope(b []byte) (*Envelope, error) {
+	e := &Envelope{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == envelopeVersionField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if v > math.MaxUint32 {
+				return 0, fmt.Errorf("%w: version %d out of range", ErrMalformed, v)
+			}
+			e.Version = Version(v)
+			return n, nil
+		case num == envelopeKindField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if v > math.MaxUint32 {
+				return 0, fmt.Errorf("%w: kind %d out of range", ErrMalformed, v)
+			}
+			e.Kind = Kind(v)
+			return n, nil
+		case num == envelopePayloadField && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			e.Payload = v
+			return n, nil
+		default:
+			return protowire.ConsumeFieldValue(num, typ, b), nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Open checks that an envelope is of a supported version and of the expected kind, and returns its
+// payload. The payload of error envelopes is returned as an error.
+func (e *Envelope) Open(kind Kind) ([]byte, error) {
+	if !supported(e.Version) {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, e.Version)
+	}
+	if e.Kind == KindError {
+		return nil, &RemoteError{Message: string(e.Payload)}
+	}
+	if e.Kind != kind {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrMalformed, kind, e.Kind)
+	}
+	return e.Payload, nil
+}
+
+// RemoteError is an error returned by the server.
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return "state provider: " + e.Message
+}
+
+// Fields of the hash range request payload, which is the protobuf message
+//
+//	message HashRangeRequest {
+//	  bytes wasm_module_root = 1;
+//	  uint64 from_batch = 2;
+//	  uint64 block_challenge_height = 3;
+//	  repeated uint64 step_heights = 4 [packed = true];
+//	  uint64 num_desired_hashes = 5;
+//	  uint64 machine_start_index = 6;
+//	  uint64 step_size = 7;
+//	  bytes claim_id = 8;
+//	}
+const (
+	hashRangeWasmModuleRootField       protowire.Number = 1
+	hashRangeFromBatchField            protowire.Number = 2
+	hashRangeBlockChallengeHeightField protowire.Number = 3
+	hashRangeStepHeightsField          protowire.Number = 4
+	hashRangeNumDesiredHashesField     protowire.Number = 5
+	hashRangeMachineStartIndexField    protowire.Number = 6
+	hashRangeStepSizeField             protowire.Number = 7
+	hashRangeClaimIdField              protowire.Number = 8
+)
+
+// MarshalHashRangeRequest encodes the request for the machine hashes of a range.
+func MarshalHashRangeRequest(cfg *l2stateprovider.HashCollectorConfig) []byte {
+	b := make([]byte, 0, 96+len(cfg.StepHeights)*4)
+	b = protowire.AppendTag(b, hashRangeWasmModuleRootField, protowire.BytesType)
+	b = protowire.AppendBytes(b, cfg.WasmModuleRoot.Bytes())
+	b = appendUint64Field(b, hashRangeFromBatchField, uint64(cfg.FromBatch))
+	b = appendUint64Field(b, hashRangeBlockChallengeHeightField, uint64(cfg.BlockChallengeHeight))
+	if len(cfg.StepHeights) > 0 {
+		packed := make([]byte, 0, len(cfg.StepHeights)*4)
+		for _, h := range cfg.StepHeights {
+			packed = protowire.AppendVarint(packed, uint64(h))
+		}
+		b = protowire.AppendTag(b, hashRangeStepHeightsField, protowire.BytesType)
+		b = protowire.AppendBytes(b, packed)
+	}
+	b = appendUint64Field(b, hashRangeNumDesiredHashesField, cfg.NumDesiredHashes)
+	b = appendUint64Field(b, hashRangeMachineStartIndexField, uint64(cfg.MachineStartIndex))
+	b = appendUint64Field(b, hashRangeStepSizeField, uint64(cfg.StepSize))
+	b = protowire.AppendTag(b, hashRangeClaimIdField, protowire.BytesType)
+	b = protowire.AppendBytes(b, cfg.ClaimId.Bytes())
+	return b
+}
+
+// UnmarshalHashRangeRequest decodes the request for the machine hashes of a range.
+func UnmarshalHashRangeRequest(b []byte) (*l2stateprovider.HashCollectorConfig, error) {
+	cfg := &l2stateprovider.HashCollectorConfig{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == hashRangeWasmModuleRootField && typ == protowire.BytesType:
+			return consumeHash(b, &cfg.WasmModuleRoot)
+		case num == hashRangeClaimIdField && typ == protowire.BytesType:
+			return consumeHash(b, &cfg.ClaimId)
+		case num == hashRangeStepHeightsField && typ == protowire.BytesType:
+			packed, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			for len(packed) > 0 {
+				v, m := protowire.ConsumeVarint(packed)
+				if m < 0 {
+					return m, nil
+				}
+				cfg.StepHeights = append(cfg.StepHeights, l2stateprovider.Height(v))
+				packed = packed[m:]
+			}
+			return n, nil
+		case typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			switch num {
+			case hashRangeFromBatchField:
+				cfg.FromBatch = l2stateprovider.Batch(v)
+			case hashRangeBlockChallengeHeightField:
+				cfg.BlockChallengeHeight = l2stateprovider.Height(v)
+			case hashRangeNumDesiredHashesField:
+				cfg.NumDesiredHashes = v
+			case hashRangeMachineStartIndexField:
+				cfg.MachineStartIndex = l2stateprovider.OpcodeIndex(v)
+			case hashRangeStepSizeField:
+				cfg.StepSize = l2stateprovider.StepSize(v)
+			}
+			return n, nil
+		default:
+			return protowire.ConsumeFieldValue(num, typ, b), nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Fields of the proof request payload, which is the protobuf message
+//
+//	message ProofRequest {
+//	  bytes wasm_module_root = 1;
+//	  uint64 from_batch = 2;
+//	  uint64 block_challenge_height = 3;
+//	  uint64 machine_index = 4;
+//	}
+const (
+	proofWasmModuleRootField       protowire.Number = 1
+	proofFromBatchField            protowire.Number = 2
+	proofBlockChallengeHeightField protowire.Number = 3
+	proofMachineIndexField         protowire.Number = 4
+)
+
+// MarshalProofRequest encodes the request for a one-step proof.
+func MarshalProofRequest(req *ProofRequest) []byte {
+	b := make([]byte, 0, 64)
+	b = protowire.AppendTag(b, proofWasmModuleRootField, protowire.BytesType)
+	b = protowire.AppendBytes(b, req.WasmModuleRoot.Bytes())
+	b = appendUint64Field(b, proofFromBatchField, uint64(req.FromBatch))
+	b = appendUint64Field(b, proofBlockChallengeHeightField, uint64(req.BlockChallengeHeight))
+	b = appendUint64Field(b, proofMachineIndexField, uint64(req.MachineIndex))
+	return b
+}
+
+// UnmarshalProofRequest decodes the request for a one-step proof.
+func UnmarshalProofRequest(b []byte) (*ProofRequest, error) {
+	req := &ProofRequest{}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == proofWasmModuleRootField && typ == protowire.BytesType:
+			return consumeHash(b, &req.WasmModuleRoot)
+		case typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			switch num {
+			case proofFromBatchField:
+				req.FromBatch = l2stateprovider.Batch(v)
+			case proofBlockChallengeHeightField:
+				req.BlockChallengeHeight = l2stateprovider.Height(v)
+			case proofMachineIndexField:
+				req.MachineIndex = l2stateprovider.OpcodeIndex(v)
+			}
+			return n, nil
+		default:
+			return protowire.ConsumeFieldValue(num, typ, b), nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// MarshalHashes encodes hashes as a big-endian uint32 count followed by the raw 32-byte words.
+func MarshalHashes(hashes []common.Hash) []byte {
+	b := make([]byte, 4, 4+len(hashes)*common.HashLength)
+	binary.BigEndian.PutUint32(b, uint32(len(hashes)))
+	for i := range hashes {
+		b = append(b, hashes[i][:]...)
+	}
+	return b
+}
+
+// UnmarshalHashes decodes hashes encoded by MarshalHashes.
+func UnmarshalHashes(b []byte) ([]common.Hash, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("%w: hashes without a length prefix", ErrMalformed)
+	}
+	count := uint64(binary.BigEndian.Uint32(b))
+	words := b[4:]
+	if uint64(len(words)) != count*common.HashLength {
+		return nil, fmt.Errorf("%w: %d hashes in %d bytes", ErrMalformed, count, len(words))
+	}
+	hashes := make([]common.Hash, count)
+	for i := range hashes {
+		copy(hashes[i][:], words[i*common.HashLength:])
+	}
+	return hashes, nil
+}
+
+func supported(v Version) bool {
+	for _, s := range SupportedVersions() {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUint64Field(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		// Proto3 omits fields with default values.
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func consumeHash(b []byte, h *common.Hash) (int, error) {
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return n, nil
+	}
+	if len(v) != common.HashLength {
+		return 0, fmt.Errorf("%w: hash of %d bytes", ErrMalformed, len(v))
+	}
+	copy(h[:], v)
+	return n, nil
+}
+
+// Iterates over the fields of a protobuf message. The consume function returns the length of the
+// field value, or a negative protowire error code.
+func consumeFields(b []byte, consume func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrMalformed, protowire.ParseError(n))
+		}
+		b = b[n:]
+		m, err := consume(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if m < 0 {
+			return fmt.Errorf("%w: %v", ErrMalformed, protowire.ParseError(m))
+		}
+		b = b[m:]
+	}
+	return nil
+}
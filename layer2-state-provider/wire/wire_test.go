@@ -0,0 +1,248 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package wire
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func testHashes(n int) []common.Hash {
+	hashes := make([]common.Hash, n)
+	for i := range hashes {
+		hashes[i] = crypto.Keccak256Hash(common.BigToHash(common.Big1).Bytes(), []byte{byte(i), byte(i >> 8), byte(i >> 16)})
+	}
+	return hashes
+}
+
+func testConfig() *l2stateprovider.HashCollectorConfig {
+	return &l2stateprovider.HashCollectorConfig{
+		WasmModuleRoot:       common.HexToHash("0x1234"),
+		FromBatch:            7,
+		BlockChallengeHeight: 300,
+		StepHeights:          []l2stateprovider.Height{0, 1 << 20, 5},
+		NumDesiredHashes:     1 << 21,
+		MachineStartIndex:    42,
+		StepSize:             1 << 10,
+		ClaimId:              common.HexToHash("0xabcd"),
+	}
+}
+
+func TestHashRangeRequest_RoundTrip(t *testing.T) {
+	cfg := testConfig()
+	decoded, err := UnmarshalHashRangeRequest(MarshalHashRangeRequest(cfg))
+	require.NoError(t, err)
+	require.Equal(t, cfg, decoded)
+
+	_, err = UnmarshalHashRangeRequest([]byte{0x0a, 0x02, 0x01, 0x02})
+	require.ErrorIs(t, err, ErrMalformed)
+	_, err = UnmarshalHashRangeRequest([]byte{0x0a, 0x40})
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestProofRequest_RoundTrip(t *testing.T) {
+	req := &ProofRequest{
+		WasmModuleRoot:       common.HexToHash("0x1234"),
+		FromBatch:            1,
+		BlockChallengeHeight: 2,
+		MachineIndex:         1 << 40,
+	}
+	decoded, err := UnmarshalProofRequest(MarshalProofRequest(req))
+	require.NoError(t, err)
+	require.Equal(t, req, decoded)
+}
+
+func TestHashes_RoundTrip(t *testing.T) {
+	hashes := testHashes(100)
+	encoded := MarshalHashes(hashes)
+	require.Len(t, encoded, 4+100*32)
+	decoded, err := UnmarshalHashes(encoded)
+	require.NoError(t, err)
+	require.Equal(t, hashes, decoded)
+
+	decoded, err = UnmarshalHashes(MarshalHashes(nil))
+	require.NoError(t, err)
+	require.Empty(t, decoded)
+
+	_, err = UnmarshalHashes(encoded[:len(encoded)-1])
+	require.ErrorIs(t, err, ErrMalformed)
+	_, err = UnmarshalHashes(encoded[:3])
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestEnvelope(t *testing.T) {
+	e := &Envelope{Version: Version1, Kind: KindProofResponse, Payload: []byte{1, 2, 3}}
+	decoded, err := UnmarshalEnvelope(e.Marshal())
+	require.NoError(t, err)
+	require.Equal(t, e, decoded)
+	payload, err := decoded.Open(KindProofResponse)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, payload)
+
+	_, err = decoded.Open(KindHashRangeResponse)
+	require.ErrorIs(t, err, ErrMalformed)
+	decoded.Version = 99
+	_, err = decoded.Open(KindProofResponse)
+	require.ErrorIs(t, err, ErrUnsupportedVersion)
+	_, err = (&Envelope{Version: Version1, Kind: KindError, Payload: []byte("boom")}).Open(KindProofResponse)
+	remoteErr := &RemoteError{}
+	require.True(t, errors.As(err, &remoteErr))
+	require.Equal(t, "boom", remoteErr.Message)
+
+	// Fields unknown to this version are skipped, so that envelopes may be extended.
+	extended := append(e.Marshal(), 0x28, 0x01)
+	decoded, err = UnmarshalEnvelope(extended)
+	require.NoError(t, err)
+	require.Equal(t, e, decoded)
+}
+
+func TestNegotiate(t *testing.T) {
+	v, ok := Negotiate([]Version{3, 2, 1}, []Version{1, 2})
+	require.True(t, ok)
+	require.Equal(t, Version(2), v)
+	_, ok = Negotiate([]Version{1}, []Version{2})
+	require.False(t, ok)
+	_, ok = Negotiate(nil, SupportedVersions())
+	require.False(t, ok)
+}
+
+type provider struct {
+	hashes []common.Hash
+	cfgs   []*l2stateprovider.HashCollectorConfig
+	proofs []*ProofRequest
+}
+
+func (p *provider) CollectMachineHashes(_ context.Context, cfg *l2stateprovider.HashCollectorConfig) ([]common.Hash, error) {
+	p.cfgs = append(p.cfgs, cfg)
+	if cfg.NumDesiredHashes == 0 {
+		return nil, errors.New("no hashes desired")
+	}
+	return p.hashes, nil
+}
+
+func (p *provider) CollectProof(
+	_ context.Context,
+	wasmModuleRoot common.Hash,
+	fromBatch l2stateprovider.Batch,
+	blockChallengeHeight l2stateprovider.Height,
+	machineIndex l2stateprovider.OpcodeIndex,
+) ([]byte, error) {
+	p.proofs = append(p.proofs, &ProofRequest{wasmModuleRoot, fromBatch, blockChallengeHeight, machineIndex})
+	return []byte("proof"), nil
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	p := &provider{hashes: testHashes(1000)}
+	server := httptest.NewServer(NewHandler(p, p))
+	t.Cleanup(server.Close)
+	cfg := testConfig()
+
+	for _, tt := range []struct {
+		name   string
+		opts   []Opt
+		binary bool
+	}{
+		{"binary", nil, true},
+		{"no shared version", []Opt{WithVersions(99)}, false},
+		{"json only", []Opt{WithVersions()}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(server.URL, tt.opts...)
+			_, binary, err := client.Format(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.binary, binary)
+
+			hashes, err := client.CollectMachineHashes(ctx, cfg)
+			require.NoError(t, err)
+			require.Equal(t, p.hashes, hashes)
+			require.Equal(t, cfg, p.cfgs[len(p.cfgs)-1])
+
+			proof, err := client.CollectProof(ctx, cfg.WasmModuleRoot, 1, 2, 3)
+			require.NoError(t, err)
+			require.Equal(t, []byte("proof"), proof)
+			require.Equal(t, &ProofRequest{cfg.WasmModuleRoot, 1, 2, 3}, p.proofs[len(p.proofs)-1])
+
+			_, err = client.CollectMachineHashes(ctx, &l2stateprovider.HashCollectorConfig{})
+			require.ErrorContains(t, err, "no hashes desired")
+		})
+	}
+}
+
+func TestClient_LegacyServer(t *testing.T) {
+	ctx := context.Background()
+	p := &provider{hashes: testHashes(10)}
+	handler := NewHandler(p, p)
+	// A server which predates the binary format has no versions endpoint.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == VersionsPath {
+			http.NotFound(w, r)
+			return
+		}
+		require.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+	hashes, err := client.CollectMachineHashes(ctx, testConfig())
+	require.NoError(t, err)
+	require.Equal(t, p.hashes, hashes)
+	_, binary, err := client.Format(ctx)
+	require.NoError(t, err)
+	require.False(t, binary)
+}
+
+func TestHashes_SmallerThanJSON(t *testing.T) {
+	hashes := testHashes(1 << 12)
+	jsonEncoded, err := json.Marshal(&jsonHashesResponse{Hashes: hashes})
+	require.NoError(t, err)
+	binaryEncoded := (&Envelope{Version: Version1, Kind: KindHashRangeResponse, Payload: MarshalHashes(hashes)}).Marshal()
+	// Hex strings take 66 bytes and a separator per hash, against 32 bytes.
+	require.Less(t, len(binaryEncoded)*2, len(jsonEncoded))
+}
+
+const benchmarkHashes = 1 << 16
+
+func BenchmarkHashes_Binary(b *testing.B) {
+	hashes := testHashes(benchmarkHashes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoded := (&Envelope{Version: Version1, Kind: KindHashRangeResponse, Payload: MarshalHashes(hashes)}).Marshal()
+		envelope, err := UnmarshalEnvelope(encoded)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err = UnmarshalHashes(envelope.Payload); err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(encoded)))
+	}
+}
+
+func BenchmarkHashes_JSON(b *testing.B) {
+	hashes := testHashes(benchmarkHashes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoded, err := json.Marshal(&jsonHashesResponse{Hashes: hashes})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err = json.Unmarshal(encoded, &jsonHashesResponse{}); err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(encoded)))
+	}
+}
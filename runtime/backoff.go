@@ -0,0 +1,189 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package retry
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backoff describes how long to wait between attempts of a retried function. The wait starts at
+// Initial and is multiplied by Multiplier after each failed attempt, up to Max. Each wait is then
+// reduced by a random fraction of up to Jitter, so that many retrying callers do not all hit the
+// same endpoint at once.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+// ConstantBackoff waits the same interval between every attempt.
+func ConstantBackoff(interval time.Duration) Backoff {
+	return Backoff{
+		Initial:    interval,
+		Max:        interval,
+		Multiplier: 1,
+	}
+}
+
+// Delay is the wait after the given number of failed attempts, counting from one.
+func (b Backoff) Delay(attempt int) time.Duration {
+	wait := float64(b.Initial) * math.Pow(b.Multiplier, float64(max(attempt-1, 0)))
+	wait = min(wait, float64(b.Max))
+	if b.Jitter > 0 {
+		wait -= wait * b.Jitter * rand.Float64() // #nosec G404
+	}
+	return time.Duration(wait)
+}
+
+// Validate checks that the backoff waits a positive time that never decreases.
+func (b Backoff) Validate() error {
+	if b.Initial <= 0 {
+		return fmt.Errorf("initial backoff must be positive, got %v", b.Initial)
+	}
+	if b.Max < b.Initial {
+		return fmt.Errorf("max backoff %v is less than initial backoff %v", b.Max, b.Initial)
+	}
+	if b.Multiplier < 1 {
+		return fmt.Errorf("backoff multiplier must be at least 1, got %v", b.Multiplier)
+	}
+	if b.Jitter < 0 || b.Jitter >= 1 {
+		return fmt.Errorf("backoff jitter must be in [0, 1), got %v", b.Jitter)
+	}
+	return nil
+}
+
+// String formats the backoff as accepted by Parse.
+func (b Backoff) String() string {
+	return fmt.Sprintf(
+		"initial=%s,max=%s,multiplier=%s,jitter=%s",
+		b.Initial,
+		b.Max,
+		strconv.FormatFloat(b.Multiplier, 'g', -1, 64),
+		strconv.FormatFloat(b.Jitter, 'g', -1, 64),
+	)
+}
+
+// Parse overrides the fields of the backoff given as comma-separated key=value pairs, such as
+// "initial=1s,max=1m,multiplier=2,jitter=0.2". Fields which are not given keep their value.
+func (b Backoff) Parse(s string) (Backoff, error) {
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Backoff{}, fmt.Errorf("invalid backoff field %q, expected key=value", pair)
+		}
+		var err error
+		switch strings.TrimSpace(key) {
+		case "initial":
+			b.Initial, err = time.ParseDuration(value)
+		case "max":
+			b.Max, err = time.ParseDuration(value)
+		case "multiplier":
+			b.Multiplier, err = strconv.ParseFloat(value, 64)
+		case "jitter":
+			b.Jitter, err = strconv.ParseFloat(value, 64)
+		default:
+			return Backoff{}, fmt.Errorf("unknown backoff field %q", key)
+		}
+		if err != nil {
+			return Backoff{}, fmt.Errorf("invalid backoff field %q: %w", pair, err)
+		}
+	}
+	return b, b.Validate()
+}
+
+// Policy names a backoff shared by every retry of a kind of operation, so that operators can tune
+// how all of them retry at once.
+type Policy string
+
+const (
+	// Reads from the parent chain, such as calls and log queries.
+	RPCRead Policy = "rpc-read"
+	// Transactions sent to the parent chain.
+	RPCWrite Policy = "rpc-write"
+	// Requests to the state provider, which may need to execute blocks to answer.
+	StateProvider Policy = "state-provider"
+	// Operations which confirm assertions and edges, where waiting longer risks missing a
+	// deadline, so failures are retried quickly.
+	ConfirmationCritical Policy = "confirmation-critical"
+)
+
+var (
+	policiesLock sync.RWMutex
+	policies     = DefaultPolicies()
+)
+
+// DefaultPolicies returns the backoff of every policy before any is overridden.
+func DefaultPolicies() map[Policy]Backoff {
+	return map[Policy]Backoff{
+		RPCRead: {
+			Initial:    500 * time.Millisecond,
+			Max:        30 * time.Second,
+			Multiplier: 2,
+			Jitter:     0.2,
+		},
+		RPCWrite: {
+			Initial:    time.Second,
+			Max:        time.Minute,
+			Multiplier: 2,
+			Jitter:     0.2,
+		},
+		StateProvider: {
+			Initial:    time.Second,
+			Max:        30 * time.Second,
+			Multiplier: 2,
+			Jitter:     0.2,
+		},
+		ConfirmationCritical: {
+			Initial:    500 * time.Millisecond,
+			Max:        5 * time.Second,
+			Multiplier: 1.5,
+			Jitter:     0.1,
+		},
+	}
+}
+
+// Policies lists the names of every policy, in alphabetical order.
+func Policies() []Policy {
+	defaults := DefaultPolicies()
+	names := make([]Policy, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// PolicyBackoff returns the current backoff of a policy.
+func PolicyBackoff(p Policy) (Backoff, bool) {
+	policiesLock.RLock()
+	defer policiesLock.RUnlock()
+	b, ok := policies[p]
+	return b, ok
+}
+
+// SetPolicy overrides the backoff of a policy for every retry using it from then on.
+func SetPolicy(p Policy, b Backoff) error {
+	if err := b.Validate(); err != nil {
+		return fmt.Errorf("invalid backoff for retry policy %s: %w", p, err)
+	}
+	policiesLock.Lock()
+	defer policiesLock.Unlock()
+	if _, ok := policies[p]; !ok {
+		return fmt.Errorf("unknown retry policy %q", p)
+	}
+	policies[p] = b
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2}
+	require.Equal(t, time.Second, b.Delay(1))
+	require.Equal(t, 2*time.Second, b.Delay(2))
+	require.Equal(t, 8*time.Second, b.Delay(4))
+	require.Equal(t, 10*time.Second, b.Delay(5))
+	require.Equal(t, 10*time.Second, b.Delay(100))
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := b.Delay(2)
+		require.GreaterOrEqual(t, d, time.Second)
+		require.LessOrEqual(t, d, 2*time.Second)
+	}
+}
+
+func TestBackoff_Parse(t *testing.T) {
+	defaults := DefaultPolicies()[RPCRead]
+	b, err := defaults.Parse("max=1m, jitter=0")
+	require.NoError(t, err)
+	require.Equal(t, Backoff{Initial: defaults.Initial, Max: time.Minute, Multiplier: defaults.Multiplier}, b)
+
+	roundTrip, err := Backoff{}.Parse(b.String())
+	require.NoError(t, err)
+	require.Equal(t, b, roundTrip)
+
+	_, err = defaults.Parse("initial=1m")
+	require.ErrorContains(t, err, "less than initial")
+	_, err = defaults.Parse("multiplier=0.5")
+	require.ErrorContains(t, err, "at least 1")
+	_, err = defaults.Parse("jitter=1")
+	require.ErrorContains(t, err, "jitter")
+	_, err = defaults.Parse("timeout=1s")
+	require.ErrorContains(t, err, "unknown backoff field")
+	_, err = defaults.Parse("max")
+	require.ErrorContains(t, err, "expected key=value")
+}
+
+func TestSetPolicy(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetPolicy(RPCRead, DefaultPolicies()[RPCRead]))
+	})
+	require.ErrorContains(t, SetPolicy("rpc-reads", ConstantBackoff(time.Second)), "unknown retry policy")
+	require.ErrorContains(t, SetPolicy(RPCRead, Backoff{}), "invalid backoff")
+
+	fast := ConstantBackoff(time.Millisecond)
+	require.NoError(t, SetPolicy(RPCRead, fast))
+	got, ok := PolicyBackoff(RPCRead)
+	require.True(t, ok)
+	require.Equal(t, fast, got)
+
+	// Retries pick up the policy's backoff when they start.
+	attempts := 0
+	start := time.Now()
+	_, err := UntilSucceeds(context.Background(), func() (int, error) {
+		attempts++
+		if attempts < 5 {
+			return 0, errors.New("failed")
+		}
+		return attempts, nil
+	}, WithPolicy(RPCRead))
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestUntilSucceeds_CanceledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := UntilSucceeds(ctx, func() (int, error) {
+		return 0, errors.New("failed")
+	}, WithBackoff(ConstantBackoff(time.Hour)))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -21,7 +21,8 @@ var (
 )
 
 type RetryConfig struct {
-	sleepTime time.Duration
+	backoff Backoff
+	policy  Policy
 }
 
 type Opt func(*RetryConfig)
@@ -29,13 +30,33 @@ type Opt func(*RetryConfig)
 // WithInterval specifies how often to retry an errored function.
 func WithInterval(d time.Duration) Opt {
 	return func(rc *RetryConfig) {
-		rc.sleepTime = d
+		rc.backoff = ConstantBackoff(d)
+		rc.policy = ""
+	}
+}
+
+// WithBackoff retries an errored function with the given backoff.
+func WithBackoff(b Backoff) Opt {
+	return func(rc *RetryConfig) {
+		rc.backoff = b
+		rc.policy = ""
+	}
+}
+
+// WithPolicy retries an errored function with the current backoff of a named policy. Unknown
+// policies are ignored.
+func WithPolicy(p Policy) Opt {
+	return func(rc *RetryConfig) {
+		if b, ok := PolicyBackoff(p); ok {
+			rc.backoff = b
+			rc.policy = p
+		}
 	}
 }
 
 func UntilSucceedsMultipleReturnValue[T, U any](ctx context.Context, fn func() (T, U, error), opts ...Opt) (T, U, error) {
 	cfg := &RetryConfig{
-		sleepTime: defaultSleepTime,
+		backoff: ConstantBackoff(defaultSleepTime),
 	}
 	for _, o := range opts {
 		o(cfg)
@@ -48,12 +69,17 @@ func UntilSucceedsMultipleReturnValue[T, U any](ctx context.Context, fn func() (
 		got, got2, err := fn()
 		if err != nil {
 			count++
-			log.Error("Could not succeed function after retries",
-				"retryCount", count,
-				"err", err,
-			)
+			fields := []any{"retryCount", count, "err", err}
+			if cfg.policy != "" {
+				fields = append(fields, "policy", cfg.policy)
+			}
+			log.Error("Could not succeed function after retries", fields...)
 			retryCounter.Inc(1)
-			time.Sleep(cfg.sleepTime)
+			select {
+			case <-ctx.Done():
+				return zeroVal[T](), zeroVal[U](), ctx.Err()
+			case <-time.After(cfg.backoff.Delay(count)):
+			}
 			continue
 		}
 		return got, got2, nil
@@ -0,0 +1,44 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package assertionStakingPoolgen
+
+// Current returns the AbsBoldStakingPoolStakeDeposited event the iterator was last advanced to.
+func (it *AbsBoldStakingPoolStakeDepositedIterator) Current() *AbsBoldStakingPoolStakeDeposited {
+	return it.Event
+}
+
+// Current returns the AbsBoldStakingPoolStakeWithdrawn event the iterator was last advanced to.
+func (it *AbsBoldStakingPoolStakeWithdrawnIterator) Current() *AbsBoldStakingPoolStakeWithdrawn {
+	return it.Event
+}
+
+// Current returns the AssertionStakingPoolStakeDeposited event the iterator was last advanced to.
+func (it *AssertionStakingPoolStakeDepositedIterator) Current() *AssertionStakingPoolStakeDeposited {
+	return it.Event
+}
+
+// Current returns the AssertionStakingPoolStakeWithdrawn event the iterator was last advanced to.
+func (it *AssertionStakingPoolStakeWithdrawnIterator) Current() *AssertionStakingPoolStakeWithdrawn {
+	return it.Event
+}
+
+// Current returns the AssertionStakingPoolCreatorNewAssertionPoolCreated event the iterator was last advanced to.
+func (it *AssertionStakingPoolCreatorNewAssertionPoolCreatedIterator) Current() *AssertionStakingPoolCreatorNewAssertionPoolCreated {
+	return it.Event
+}
+
+// Current returns the EdgeStakingPoolStakeDeposited event the iterator was last advanced to.
+func (it *EdgeStakingPoolStakeDepositedIterator) Current() *EdgeStakingPoolStakeDeposited {
+	return it.Event
+}
+
+// Current returns the EdgeStakingPoolStakeWithdrawn event the iterator was last advanced to.
+func (it *EdgeStakingPoolStakeWithdrawnIterator) Current() *EdgeStakingPoolStakeWithdrawn {
+	return it.Event
+}
+
+// Current returns the EdgeStakingPoolCreatorNewEdgeStakingPoolCreated event the iterator was last advanced to.
+func (it *EdgeStakingPoolCreatorNewEdgeStakingPoolCreatedIterator) Current() *EdgeStakingPoolCreatorNewEdgeStakingPoolCreated {
+	return it.Event
+}
@@ -0,0 +1,549 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bridgegen
+
+// Current returns the AbsBridgeBridgeCallTriggered event the iterator was last advanced to.
+func (it *AbsBridgeBridgeCallTriggeredIterator) Current() *AbsBridgeBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the AbsBridgeInboxToggle event the iterator was last advanced to.
+func (it *AbsBridgeInboxToggleIterator) Current() *AbsBridgeInboxToggle {
+	return it.Event
+}
+
+// Current returns the AbsBridgeInitialized event the iterator was last advanced to.
+func (it *AbsBridgeInitializedIterator) Current() *AbsBridgeInitialized {
+	return it.Event
+}
+
+// Current returns the AbsBridgeMessageDelivered event the iterator was last advanced to.
+func (it *AbsBridgeMessageDeliveredIterator) Current() *AbsBridgeMessageDelivered {
+	return it.Event
+}
+
+// Current returns the AbsBridgeOutboxToggle event the iterator was last advanced to.
+func (it *AbsBridgeOutboxToggleIterator) Current() *AbsBridgeOutboxToggle {
+	return it.Event
+}
+
+// Current returns the AbsBridgeRollupUpdated event the iterator was last advanced to.
+func (it *AbsBridgeRollupUpdatedIterator) Current() *AbsBridgeRollupUpdated {
+	return it.Event
+}
+
+// Current returns the AbsBridgeSequencerInboxUpdated event the iterator was last advanced to.
+func (it *AbsBridgeSequencerInboxUpdatedIterator) Current() *AbsBridgeSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the AbsInboxAllowListAddressSet event the iterator was last advanced to.
+func (it *AbsInboxAllowListAddressSetIterator) Current() *AbsInboxAllowListAddressSet {
+	return it.Event
+}
+
+// Current returns the AbsInboxAllowListEnabledUpdated event the iterator was last advanced to.
+func (it *AbsInboxAllowListEnabledUpdatedIterator) Current() *AbsInboxAllowListEnabledUpdated {
+	return it.Event
+}
+
+// Current returns the AbsInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *AbsInboxInboxMessageDeliveredIterator) Current() *AbsInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the AbsInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *AbsInboxInboxMessageDeliveredFromOriginIterator) Current() *AbsInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the AbsInboxInitialized event the iterator was last advanced to.
+func (it *AbsInboxInitializedIterator) Current() *AbsInboxInitialized {
+	return it.Event
+}
+
+// Current returns the AbsInboxPaused event the iterator was last advanced to.
+func (it *AbsInboxPausedIterator) Current() *AbsInboxPaused {
+	return it.Event
+}
+
+// Current returns the AbsInboxUnpaused event the iterator was last advanced to.
+func (it *AbsInboxUnpausedIterator) Current() *AbsInboxUnpaused {
+	return it.Event
+}
+
+// Current returns the AbsOutboxOutBoxTransactionExecuted event the iterator was last advanced to.
+func (it *AbsOutboxOutBoxTransactionExecutedIterator) Current() *AbsOutboxOutBoxTransactionExecuted {
+	return it.Event
+}
+
+// Current returns the AbsOutboxSendRootUpdated event the iterator was last advanced to.
+func (it *AbsOutboxSendRootUpdatedIterator) Current() *AbsOutboxSendRootUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeBridgeCallTriggered event the iterator was last advanced to.
+func (it *BridgeBridgeCallTriggeredIterator) Current() *BridgeBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the BridgeInboxToggle event the iterator was last advanced to.
+func (it *BridgeInboxToggleIterator) Current() *BridgeInboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeInitialized event the iterator was last advanced to.
+func (it *BridgeInitializedIterator) Current() *BridgeInitialized {
+	return it.Event
+}
+
+// Current returns the BridgeMessageDelivered event the iterator was last advanced to.
+func (it *BridgeMessageDeliveredIterator) Current() *BridgeMessageDelivered {
+	return it.Event
+}
+
+// Current returns the BridgeOutboxToggle event the iterator was last advanced to.
+func (it *BridgeOutboxToggleIterator) Current() *BridgeOutboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeRollupUpdated event the iterator was last advanced to.
+func (it *BridgeRollupUpdatedIterator) Current() *BridgeRollupUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeSequencerInboxUpdated event the iterator was last advanced to.
+func (it *BridgeSequencerInboxUpdatedIterator) Current() *BridgeSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeBridgeCallTriggered event the iterator was last advanced to.
+func (it *ERC20BridgeBridgeCallTriggeredIterator) Current() *ERC20BridgeBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeInboxToggle event the iterator was last advanced to.
+func (it *ERC20BridgeInboxToggleIterator) Current() *ERC20BridgeInboxToggle {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeInitialized event the iterator was last advanced to.
+func (it *ERC20BridgeInitializedIterator) Current() *ERC20BridgeInitialized {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeMessageDelivered event the iterator was last advanced to.
+func (it *ERC20BridgeMessageDeliveredIterator) Current() *ERC20BridgeMessageDelivered {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeOutboxToggle event the iterator was last advanced to.
+func (it *ERC20BridgeOutboxToggleIterator) Current() *ERC20BridgeOutboxToggle {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeRollupUpdated event the iterator was last advanced to.
+func (it *ERC20BridgeRollupUpdatedIterator) Current() *ERC20BridgeRollupUpdated {
+	return it.Event
+}
+
+// Current returns the ERC20BridgeSequencerInboxUpdated event the iterator was last advanced to.
+func (it *ERC20BridgeSequencerInboxUpdatedIterator) Current() *ERC20BridgeSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the ERC20InboxAllowListAddressSet event the iterator was last advanced to.
+func (it *ERC20InboxAllowListAddressSetIterator) Current() *ERC20InboxAllowListAddressSet {
+	return it.Event
+}
+
+// Current returns the ERC20InboxAllowListEnabledUpdated event the iterator was last advanced to.
+func (it *ERC20InboxAllowListEnabledUpdatedIterator) Current() *ERC20InboxAllowListEnabledUpdated {
+	return it.Event
+}
+
+// Current returns the ERC20InboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *ERC20InboxInboxMessageDeliveredIterator) Current() *ERC20InboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the ERC20InboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *ERC20InboxInboxMessageDeliveredFromOriginIterator) Current() *ERC20InboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the ERC20InboxInitialized event the iterator was last advanced to.
+func (it *ERC20InboxInitializedIterator) Current() *ERC20InboxInitialized {
+	return it.Event
+}
+
+// Current returns the ERC20InboxPaused event the iterator was last advanced to.
+func (it *ERC20InboxPausedIterator) Current() *ERC20InboxPaused {
+	return it.Event
+}
+
+// Current returns the ERC20InboxUnpaused event the iterator was last advanced to.
+func (it *ERC20InboxUnpausedIterator) Current() *ERC20InboxUnpaused {
+	return it.Event
+}
+
+// Current returns the ERC20OutboxOutBoxTransactionExecuted event the iterator was last advanced to.
+func (it *ERC20OutboxOutBoxTransactionExecutedIterator) Current() *ERC20OutboxOutBoxTransactionExecuted {
+	return it.Event
+}
+
+// Current returns the ERC20OutboxSendRootUpdated event the iterator was last advanced to.
+func (it *ERC20OutboxSendRootUpdatedIterator) Current() *ERC20OutboxSendRootUpdated {
+	return it.Event
+}
+
+// Current returns the GasRefunderCommonParameterSet event the iterator was last advanced to.
+func (it *GasRefunderCommonParameterSetIterator) Current() *GasRefunderCommonParameterSet {
+	return it.Event
+}
+
+// Current returns the GasRefunderContractAllowedSet event the iterator was last advanced to.
+func (it *GasRefunderContractAllowedSetIterator) Current() *GasRefunderContractAllowedSet {
+	return it.Event
+}
+
+// Current returns the GasRefunderDeposited event the iterator was last advanced to.
+func (it *GasRefunderDepositedIterator) Current() *GasRefunderDeposited {
+	return it.Event
+}
+
+// Current returns the GasRefunderDisallowerSet event the iterator was last advanced to.
+func (it *GasRefunderDisallowerSetIterator) Current() *GasRefunderDisallowerSet {
+	return it.Event
+}
+
+// Current returns the GasRefunderOwnershipTransferred event the iterator was last advanced to.
+func (it *GasRefunderOwnershipTransferredIterator) Current() *GasRefunderOwnershipTransferred {
+	return it.Event
+}
+
+// Current returns the GasRefunderRefundGasCostsDenied event the iterator was last advanced to.
+func (it *GasRefunderRefundGasCostsDeniedIterator) Current() *GasRefunderRefundGasCostsDenied {
+	return it.Event
+}
+
+// Current returns the GasRefunderRefundedGasCosts event the iterator was last advanced to.
+func (it *GasRefunderRefundedGasCostsIterator) Current() *GasRefunderRefundedGasCosts {
+	return it.Event
+}
+
+// Current returns the GasRefunderRefundeeAllowedSet event the iterator was last advanced to.
+func (it *GasRefunderRefundeeAllowedSetIterator) Current() *GasRefunderRefundeeAllowedSet {
+	return it.Event
+}
+
+// Current returns the GasRefunderWithdrawn event the iterator was last advanced to.
+func (it *GasRefunderWithdrawnIterator) Current() *GasRefunderWithdrawn {
+	return it.Event
+}
+
+// Current returns the IBridgeBridgeCallTriggered event the iterator was last advanced to.
+func (it *IBridgeBridgeCallTriggeredIterator) Current() *IBridgeBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the IBridgeInboxToggle event the iterator was last advanced to.
+func (it *IBridgeInboxToggleIterator) Current() *IBridgeInboxToggle {
+	return it.Event
+}
+
+// Current returns the IBridgeMessageDelivered event the iterator was last advanced to.
+func (it *IBridgeMessageDeliveredIterator) Current() *IBridgeMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IBridgeOutboxToggle event the iterator was last advanced to.
+func (it *IBridgeOutboxToggleIterator) Current() *IBridgeOutboxToggle {
+	return it.Event
+}
+
+// Current returns the IBridgeRollupUpdated event the iterator was last advanced to.
+func (it *IBridgeRollupUpdatedIterator) Current() *IBridgeRollupUpdated {
+	return it.Event
+}
+
+// Current returns the IBridgeSequencerInboxUpdated event the iterator was last advanced to.
+func (it *IBridgeSequencerInboxUpdatedIterator) Current() *IBridgeSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the IDelayedMessageProviderInboxMessageDelivered event the iterator was last advanced to.
+func (it *IDelayedMessageProviderInboxMessageDeliveredIterator) Current() *IDelayedMessageProviderInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IDelayedMessageProviderInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *IDelayedMessageProviderInboxMessageDeliveredFromOriginIterator) Current() *IDelayedMessageProviderInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the IERC20BridgeBridgeCallTriggered event the iterator was last advanced to.
+func (it *IERC20BridgeBridgeCallTriggeredIterator) Current() *IERC20BridgeBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the IERC20BridgeInboxToggle event the iterator was last advanced to.
+func (it *IERC20BridgeInboxToggleIterator) Current() *IERC20BridgeInboxToggle {
+	return it.Event
+}
+
+// Current returns the IERC20BridgeMessageDelivered event the iterator was last advanced to.
+func (it *IERC20BridgeMessageDeliveredIterator) Current() *IERC20BridgeMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IERC20BridgeOutboxToggle event the iterator was last advanced to.
+func (it *IERC20BridgeOutboxToggleIterator) Current() *IERC20BridgeOutboxToggle {
+	return it.Event
+}
+
+// Current returns the IERC20BridgeRollupUpdated event the iterator was last advanced to.
+func (it *IERC20BridgeRollupUpdatedIterator) Current() *IERC20BridgeRollupUpdated {
+	return it.Event
+}
+
+// Current returns the IERC20BridgeSequencerInboxUpdated event the iterator was last advanced to.
+func (it *IERC20BridgeSequencerInboxUpdatedIterator) Current() *IERC20BridgeSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the IERC20InboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *IERC20InboxInboxMessageDeliveredIterator) Current() *IERC20InboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IERC20InboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *IERC20InboxInboxMessageDeliveredFromOriginIterator) Current() *IERC20InboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the IEthBridgeBridgeCallTriggered event the iterator was last advanced to.
+func (it *IEthBridgeBridgeCallTriggeredIterator) Current() *IEthBridgeBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the IEthBridgeInboxToggle event the iterator was last advanced to.
+func (it *IEthBridgeInboxToggleIterator) Current() *IEthBridgeInboxToggle {
+	return it.Event
+}
+
+// Current returns the IEthBridgeMessageDelivered event the iterator was last advanced to.
+func (it *IEthBridgeMessageDeliveredIterator) Current() *IEthBridgeMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IEthBridgeOutboxToggle event the iterator was last advanced to.
+func (it *IEthBridgeOutboxToggleIterator) Current() *IEthBridgeOutboxToggle {
+	return it.Event
+}
+
+// Current returns the IEthBridgeRollupUpdated event the iterator was last advanced to.
+func (it *IEthBridgeRollupUpdatedIterator) Current() *IEthBridgeRollupUpdated {
+	return it.Event
+}
+
+// Current returns the IEthBridgeSequencerInboxUpdated event the iterator was last advanced to.
+func (it *IEthBridgeSequencerInboxUpdatedIterator) Current() *IEthBridgeSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the IInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *IInboxInboxMessageDeliveredIterator) Current() *IInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *IInboxInboxMessageDeliveredFromOriginIterator) Current() *IInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the IInboxBaseInboxMessageDelivered event the iterator was last advanced to.
+func (it *IInboxBaseInboxMessageDeliveredIterator) Current() *IInboxBaseInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the IInboxBaseInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *IInboxBaseInboxMessageDeliveredFromOriginIterator) Current() *IInboxBaseInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the IOutboxOutBoxTransactionExecuted event the iterator was last advanced to.
+func (it *IOutboxOutBoxTransactionExecutedIterator) Current() *IOutboxOutBoxTransactionExecuted {
+	return it.Event
+}
+
+// Current returns the IOutboxSendRootUpdated event the iterator was last advanced to.
+func (it *IOutboxSendRootUpdatedIterator) Current() *IOutboxSendRootUpdated {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxBatchPosterManagerSet event the iterator was last advanced to.
+func (it *ISequencerInboxBatchPosterManagerSetIterator) Current() *ISequencerInboxBatchPosterManagerSet {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxBatchPosterSet event the iterator was last advanced to.
+func (it *ISequencerInboxBatchPosterSetIterator) Current() *ISequencerInboxBatchPosterSet {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxBufferConfigSet event the iterator was last advanced to.
+func (it *ISequencerInboxBufferConfigSetIterator) Current() *ISequencerInboxBufferConfigSet {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *ISequencerInboxInboxMessageDeliveredIterator) Current() *ISequencerInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *ISequencerInboxInboxMessageDeliveredFromOriginIterator) Current() *ISequencerInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxInvalidateKeyset event the iterator was last advanced to.
+func (it *ISequencerInboxInvalidateKeysetIterator) Current() *ISequencerInboxInvalidateKeyset {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxMaxTimeVariationSet event the iterator was last advanced to.
+func (it *ISequencerInboxMaxTimeVariationSetIterator) Current() *ISequencerInboxMaxTimeVariationSet {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxOwnerFunctionCalled event the iterator was last advanced to.
+func (it *ISequencerInboxOwnerFunctionCalledIterator) Current() *ISequencerInboxOwnerFunctionCalled {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxSequencerBatchData event the iterator was last advanced to.
+func (it *ISequencerInboxSequencerBatchDataIterator) Current() *ISequencerInboxSequencerBatchData {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxSequencerBatchDelivered event the iterator was last advanced to.
+func (it *ISequencerInboxSequencerBatchDeliveredIterator) Current() *ISequencerInboxSequencerBatchDelivered {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxSequencerSet event the iterator was last advanced to.
+func (it *ISequencerInboxSequencerSetIterator) Current() *ISequencerInboxSequencerSet {
+	return it.Event
+}
+
+// Current returns the ISequencerInboxSetValidKeyset event the iterator was last advanced to.
+func (it *ISequencerInboxSetValidKeysetIterator) Current() *ISequencerInboxSetValidKeyset {
+	return it.Event
+}
+
+// Current returns the InboxAllowListAddressSet event the iterator was last advanced to.
+func (it *InboxAllowListAddressSetIterator) Current() *InboxAllowListAddressSet {
+	return it.Event
+}
+
+// Current returns the InboxAllowListEnabledUpdated event the iterator was last advanced to.
+func (it *InboxAllowListEnabledUpdatedIterator) Current() *InboxAllowListEnabledUpdated {
+	return it.Event
+}
+
+// Current returns the InboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *InboxInboxMessageDeliveredIterator) Current() *InboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the InboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *InboxInboxMessageDeliveredFromOriginIterator) Current() *InboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the InboxInitialized event the iterator was last advanced to.
+func (it *InboxInitializedIterator) Current() *InboxInitialized {
+	return it.Event
+}
+
+// Current returns the InboxPaused event the iterator was last advanced to.
+func (it *InboxPausedIterator) Current() *InboxPaused {
+	return it.Event
+}
+
+// Current returns the InboxUnpaused event the iterator was last advanced to.
+func (it *InboxUnpausedIterator) Current() *InboxUnpaused {
+	return it.Event
+}
+
+// Current returns the OutboxOutBoxTransactionExecuted event the iterator was last advanced to.
+func (it *OutboxOutBoxTransactionExecutedIterator) Current() *OutboxOutBoxTransactionExecuted {
+	return it.Event
+}
+
+// Current returns the OutboxSendRootUpdated event the iterator was last advanced to.
+func (it *OutboxSendRootUpdatedIterator) Current() *OutboxSendRootUpdated {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBatchPosterManagerSet event the iterator was last advanced to.
+func (it *SequencerInboxBatchPosterManagerSetIterator) Current() *SequencerInboxBatchPosterManagerSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBatchPosterSet event the iterator was last advanced to.
+func (it *SequencerInboxBatchPosterSetIterator) Current() *SequencerInboxBatchPosterSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBufferConfigSet event the iterator was last advanced to.
+func (it *SequencerInboxBufferConfigSetIterator) Current() *SequencerInboxBufferConfigSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *SequencerInboxInboxMessageDeliveredIterator) Current() *SequencerInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the SequencerInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *SequencerInboxInboxMessageDeliveredFromOriginIterator) Current() *SequencerInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the SequencerInboxInvalidateKeyset event the iterator was last advanced to.
+func (it *SequencerInboxInvalidateKeysetIterator) Current() *SequencerInboxInvalidateKeyset {
+	return it.Event
+}
+
+// Current returns the SequencerInboxMaxTimeVariationSet event the iterator was last advanced to.
+func (it *SequencerInboxMaxTimeVariationSetIterator) Current() *SequencerInboxMaxTimeVariationSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxOwnerFunctionCalled event the iterator was last advanced to.
+func (it *SequencerInboxOwnerFunctionCalledIterator) Current() *SequencerInboxOwnerFunctionCalled {
+	return it.Event
+}
+
+// Current returns the SequencerInboxSequencerBatchData event the iterator was last advanced to.
+func (it *SequencerInboxSequencerBatchDataIterator) Current() *SequencerInboxSequencerBatchData {
+	return it.Event
+}
+
+// Current returns the SequencerInboxSequencerBatchDelivered event the iterator was last advanced to.
+func (it *SequencerInboxSequencerBatchDeliveredIterator) Current() *SequencerInboxSequencerBatchDelivered {
+	return it.Event
+}
+
+// Current returns the SequencerInboxSequencerSet event the iterator was last advanced to.
+func (it *SequencerInboxSequencerSetIterator) Current() *SequencerInboxSequencerSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxSetValidKeyset event the iterator was last advanced to.
+func (it *SequencerInboxSetValidKeysetIterator) Current() *SequencerInboxSetValidKeyset {
+	return it.Event
+}
@@ -0,0 +1,39 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package challengeV2gen
+
+// Current returns the EdgeChallengeManagerEdgeAdded event the iterator was last advanced to.
+func (it *EdgeChallengeManagerEdgeAddedIterator) Current() *EdgeChallengeManagerEdgeAdded {
+	return it.Event
+}
+
+// Current returns the EdgeChallengeManagerEdgeBisected event the iterator was last advanced to.
+func (it *EdgeChallengeManagerEdgeBisectedIterator) Current() *EdgeChallengeManagerEdgeBisected {
+	return it.Event
+}
+
+// Current returns the EdgeChallengeManagerEdgeConfirmedByOneStepProof event the iterator was last advanced to.
+func (it *EdgeChallengeManagerEdgeConfirmedByOneStepProofIterator) Current() *EdgeChallengeManagerEdgeConfirmedByOneStepProof {
+	return it.Event
+}
+
+// Current returns the EdgeChallengeManagerEdgeConfirmedByTime event the iterator was last advanced to.
+func (it *EdgeChallengeManagerEdgeConfirmedByTimeIterator) Current() *EdgeChallengeManagerEdgeConfirmedByTime {
+	return it.Event
+}
+
+// Current returns the EdgeChallengeManagerEdgeRefunded event the iterator was last advanced to.
+func (it *EdgeChallengeManagerEdgeRefundedIterator) Current() *EdgeChallengeManagerEdgeRefunded {
+	return it.Event
+}
+
+// Current returns the EdgeChallengeManagerInitialized event the iterator was last advanced to.
+func (it *EdgeChallengeManagerInitializedIterator) Current() *EdgeChallengeManagerInitialized {
+	return it.Event
+}
+
+// Current returns the EdgeChallengeManagerTimerCacheUpdated event the iterator was last advanced to.
+func (it *EdgeChallengeManagerTimerCacheUpdatedIterator) Current() *EdgeChallengeManagerTimerCacheUpdated {
+	return it.Event
+}
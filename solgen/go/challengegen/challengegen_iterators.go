@@ -0,0 +1,54 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package challengegen
+
+// Current returns the IOldChallengeManagerBisected event the iterator was last advanced to.
+func (it *IOldChallengeManagerBisectedIterator) Current() *IOldChallengeManagerBisected {
+	return it.Event
+}
+
+// Current returns the IOldChallengeManagerChallengeEnded event the iterator was last advanced to.
+func (it *IOldChallengeManagerChallengeEndedIterator) Current() *IOldChallengeManagerChallengeEnded {
+	return it.Event
+}
+
+// Current returns the IOldChallengeManagerExecutionChallengeBegun event the iterator was last advanced to.
+func (it *IOldChallengeManagerExecutionChallengeBegunIterator) Current() *IOldChallengeManagerExecutionChallengeBegun {
+	return it.Event
+}
+
+// Current returns the IOldChallengeManagerInitiatedChallenge event the iterator was last advanced to.
+func (it *IOldChallengeManagerInitiatedChallengeIterator) Current() *IOldChallengeManagerInitiatedChallenge {
+	return it.Event
+}
+
+// Current returns the IOldChallengeManagerOneStepProofCompleted event the iterator was last advanced to.
+func (it *IOldChallengeManagerOneStepProofCompletedIterator) Current() *IOldChallengeManagerOneStepProofCompleted {
+	return it.Event
+}
+
+// Current returns the OldChallengeManagerBisected event the iterator was last advanced to.
+func (it *OldChallengeManagerBisectedIterator) Current() *OldChallengeManagerBisected {
+	return it.Event
+}
+
+// Current returns the OldChallengeManagerChallengeEnded event the iterator was last advanced to.
+func (it *OldChallengeManagerChallengeEndedIterator) Current() *OldChallengeManagerChallengeEnded {
+	return it.Event
+}
+
+// Current returns the OldChallengeManagerExecutionChallengeBegun event the iterator was last advanced to.
+func (it *OldChallengeManagerExecutionChallengeBegunIterator) Current() *OldChallengeManagerExecutionChallengeBegun {
+	return it.Event
+}
+
+// Current returns the OldChallengeManagerInitiatedChallenge event the iterator was last advanced to.
+func (it *OldChallengeManagerInitiatedChallengeIterator) Current() *OldChallengeManagerInitiatedChallenge {
+	return it.Event
+}
+
+// Current returns the OldChallengeManagerOneStepProofCompleted event the iterator was last advanced to.
+func (it *OldChallengeManagerOneStepProofCompletedIterator) Current() *OldChallengeManagerOneStepProofCompleted {
+	return it.Event
+}
@@ -0,0 +1,86 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package iterators consumes the event iterators of the generated bindings, which are easy to
+// misuse: Next returns false on failure as well as at the end of the events, so the error must be
+// checked after the loop rather than within it, and the iterator must be closed to release its
+// subscription. The helpers here do both, collecting the events into a slice or streaming them on
+// a channel.
+package iterators
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Iterator is satisfied by every event iterator of the generated bindings, which are given a
+// Current method by solgen returning the event they were last advanced to.
+type Iterator[T any] interface {
+	Next() bool
+	Error() error
+	Close() error
+	Current() T
+}
+
+// ErrTooManyEvents is returned when an iterator has more events than the caller allows.
+var ErrTooManyEvents = errors.New("too many events")
+
+// CollectEvents reads every event of the iterator into a slice, then closes it. If limit is
+// positive and the iterator has more than limit events, ErrTooManyEvents is returned along with the
+// first limit events, so a caller can narrow the range it filtered for and try again.
+func CollectEvents[T any](it Iterator[T], limit int) (events []T, err error) {
+	defer func() {
+		if closeErr := it.Close(); err == nil && closeErr != nil {
+			err = errors.Wrap(closeErr, "could not close event iterator")
+		}
+	}()
+	for it.Next() {
+		if limit > 0 && len(events) == limit {
+			return events, errors.Wrapf(ErrTooManyEvents, "more than %d", limit)
+		}
+		events = append(events, it.Current())
+	}
+	if err = it.Error(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ForEachEvent calls fn with each event of the iterator in order, then closes it. It stops at the
+// first error returned by fn or the iterator.
+func ForEachEvent[T any](it Iterator[T], fn func(T) error) (err error) {
+	defer func() {
+		if closeErr := it.Close(); err == nil && closeErr != nil {
+			err = errors.Wrap(closeErr, "could not close event iterator")
+		}
+	}()
+	for it.Next() {
+		if err = fn(it.Current()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// StreamEvents sends the events of the iterator on the returned channel as they are read, closing
+// it and the iterator once the events run out, the iterator fails or the context is done. The
+// error channel then receives the reason iteration stopped, nil if every event was sent. A caller
+// which stops receiving events early must cancel the context to release the iterator.
+func StreamEvents[T any](ctx context.Context, it Iterator[T]) (<-chan T, <-chan error) {
+	events := make(chan T)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(events)
+		errs <- ForEachEvent(it, func(event T) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events, errs
+}
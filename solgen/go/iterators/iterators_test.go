@@ -0,0 +1,122 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package iterators
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ Iterator[*challengeV2gen.EdgeChallengeManagerEdgeAdded] = (*challengeV2gen.EdgeChallengeManagerEdgeAddedIterator)(nil)
+	_ Iterator[*rollupgen.RollupUserLogicAssertionCreated]    = (*rollupgen.RollupUserLogicAssertionCreatedIterator)(nil)
+)
+
+type sliceIterator struct {
+	events  []int
+	pos     int
+	failAt  int
+	current int
+	closed  bool
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.pos >= len(it.events) || (it.failAt > 0 && it.pos == it.failAt) {
+		return false
+	}
+	it.current = it.events[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *sliceIterator) Error() error {
+	if it.failAt > 0 && it.pos == it.failAt {
+		return errors.New("iterator failed")
+	}
+	return nil
+}
+
+func (it *sliceIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+func (it *sliceIterator) Current() int {
+	return it.current
+}
+
+func TestCollectEvents(t *testing.T) {
+	it := &sliceIterator{events: []int{1, 2, 3}}
+	events, err := CollectEvents[int](it, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, events)
+	require.True(t, it.closed)
+
+	it = &sliceIterator{events: []int{1, 2, 3}}
+	events, err = CollectEvents[int](it, 3)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, events)
+
+	it = &sliceIterator{events: []int{1, 2, 3}}
+	events, err = CollectEvents[int](it, 2)
+	require.ErrorIs(t, err, ErrTooManyEvents)
+	require.Equal(t, []int{1, 2}, events)
+	require.True(t, it.closed)
+
+	it = &sliceIterator{events: []int{1, 2, 3}, failAt: 2}
+	_, err = CollectEvents[int](it, 0)
+	require.ErrorContains(t, err, "iterator failed")
+	require.True(t, it.closed)
+}
+
+func TestForEachEvent(t *testing.T) {
+	it := &sliceIterator{events: []int{1, 2, 3}}
+	var seen []int
+	errStop := errors.New("stop")
+	err := ForEachEvent[int](it, func(event int) error {
+		seen = append(seen, event)
+		if event == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []int{1, 2}, seen)
+	require.True(t, it.closed)
+}
+
+func TestStreamEvents(t *testing.T) {
+	ctx := context.Background()
+	it := &sliceIterator{events: []int{1, 2, 3}}
+	events, errs := StreamEvents[int](ctx, it)
+	var seen []int
+	for event := range events {
+		seen = append(seen, event)
+	}
+	require.NoError(t, <-errs)
+	require.Equal(t, []int{1, 2, 3}, seen)
+	require.True(t, it.closed)
+
+	it = &sliceIterator{events: []int{1, 2, 3}, failAt: 1}
+	events, errs = StreamEvents[int](ctx, it)
+	for range events {
+	}
+	require.ErrorContains(t, <-errs, "iterator failed")
+
+	// Stops once the context is cancelled, although events remain.
+	ctx, cancel := context.WithCancel(ctx)
+	it = &sliceIterator{events: []int{1, 2, 3}}
+	events, errs = StreamEvents[int](ctx, it)
+	require.Equal(t, 1, <-events)
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+	_, ok := <-events
+	require.False(t, ok)
+	require.True(t, it.closed)
+}
@@ -0,0 +1,84 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package librariesgen
+
+// Current returns the AdminFallbackProxyAdminChanged event the iterator was last advanced to.
+func (it *AdminFallbackProxyAdminChangedIterator) Current() *AdminFallbackProxyAdminChanged {
+	return it.Event
+}
+
+// Current returns the AdminFallbackProxyBeaconUpgraded event the iterator was last advanced to.
+func (it *AdminFallbackProxyBeaconUpgradedIterator) Current() *AdminFallbackProxyBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the AdminFallbackProxyUpgraded event the iterator was last advanced to.
+func (it *AdminFallbackProxyUpgradedIterator) Current() *AdminFallbackProxyUpgraded {
+	return it.Event
+}
+
+// Current returns the AdminFallbackProxyUpgradedSecondary event the iterator was last advanced to.
+func (it *AdminFallbackProxyUpgradedSecondaryIterator) Current() *AdminFallbackProxyUpgradedSecondary {
+	return it.Event
+}
+
+// Current returns the DoubleLogicERC1967UpgradeAdminChanged event the iterator was last advanced to.
+func (it *DoubleLogicERC1967UpgradeAdminChangedIterator) Current() *DoubleLogicERC1967UpgradeAdminChanged {
+	return it.Event
+}
+
+// Current returns the DoubleLogicERC1967UpgradeBeaconUpgraded event the iterator was last advanced to.
+func (it *DoubleLogicERC1967UpgradeBeaconUpgradedIterator) Current() *DoubleLogicERC1967UpgradeBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the DoubleLogicERC1967UpgradeUpgraded event the iterator was last advanced to.
+func (it *DoubleLogicERC1967UpgradeUpgradedIterator) Current() *DoubleLogicERC1967UpgradeUpgraded {
+	return it.Event
+}
+
+// Current returns the DoubleLogicERC1967UpgradeUpgradedSecondary event the iterator was last advanced to.
+func (it *DoubleLogicERC1967UpgradeUpgradedSecondaryIterator) Current() *DoubleLogicERC1967UpgradeUpgradedSecondary {
+	return it.Event
+}
+
+// Current returns the DoubleLogicUUPSUpgradeableAdminChanged event the iterator was last advanced to.
+func (it *DoubleLogicUUPSUpgradeableAdminChangedIterator) Current() *DoubleLogicUUPSUpgradeableAdminChanged {
+	return it.Event
+}
+
+// Current returns the DoubleLogicUUPSUpgradeableBeaconUpgraded event the iterator was last advanced to.
+func (it *DoubleLogicUUPSUpgradeableBeaconUpgradedIterator) Current() *DoubleLogicUUPSUpgradeableBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the DoubleLogicUUPSUpgradeableUpgraded event the iterator was last advanced to.
+func (it *DoubleLogicUUPSUpgradeableUpgradedIterator) Current() *DoubleLogicUUPSUpgradeableUpgraded {
+	return it.Event
+}
+
+// Current returns the DoubleLogicUUPSUpgradeableUpgradedSecondary event the iterator was last advanced to.
+func (it *DoubleLogicUUPSUpgradeableUpgradedSecondaryIterator) Current() *DoubleLogicUUPSUpgradeableUpgradedSecondary {
+	return it.Event
+}
+
+// Current returns the UUPSNotUpgradeableAdminChanged event the iterator was last advanced to.
+func (it *UUPSNotUpgradeableAdminChangedIterator) Current() *UUPSNotUpgradeableAdminChanged {
+	return it.Event
+}
+
+// Current returns the UUPSNotUpgradeableBeaconUpgraded event the iterator was last advanced to.
+func (it *UUPSNotUpgradeableBeaconUpgradedIterator) Current() *UUPSNotUpgradeableBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the UUPSNotUpgradeableUpgraded event the iterator was last advanced to.
+func (it *UUPSNotUpgradeableUpgradedIterator) Current() *UUPSNotUpgradeableUpgraded {
+	return it.Event
+}
+
+// Current returns the UUPSNotUpgradeableUpgradedSecondary event the iterator was last advanced to.
+func (it *UUPSNotUpgradeableUpgradedSecondaryIterator) Current() *UUPSNotUpgradeableUpgradedSecondary {
+	return it.Event
+}
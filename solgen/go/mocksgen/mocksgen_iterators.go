@@ -0,0 +1,274 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package mocksgen
+
+// Current returns the BridgeStubBridgeCallTriggered event the iterator was last advanced to.
+func (it *BridgeStubBridgeCallTriggeredIterator) Current() *BridgeStubBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the BridgeStubInboxToggle event the iterator was last advanced to.
+func (it *BridgeStubInboxToggleIterator) Current() *BridgeStubInboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeStubMessageDelivered event the iterator was last advanced to.
+func (it *BridgeStubMessageDeliveredIterator) Current() *BridgeStubMessageDelivered {
+	return it.Event
+}
+
+// Current returns the BridgeStubOutboxToggle event the iterator was last advanced to.
+func (it *BridgeStubOutboxToggleIterator) Current() *BridgeStubOutboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeStubRollupUpdated event the iterator was last advanced to.
+func (it *BridgeStubRollupUpdatedIterator) Current() *BridgeStubRollupUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeStubSequencerInboxUpdated event the iterator was last advanced to.
+func (it *BridgeStubSequencerInboxUpdatedIterator) Current() *BridgeStubSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedBridgeCallTriggered event the iterator was last advanced to.
+func (it *BridgeUnproxiedBridgeCallTriggeredIterator) Current() *BridgeUnproxiedBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedInboxToggle event the iterator was last advanced to.
+func (it *BridgeUnproxiedInboxToggleIterator) Current() *BridgeUnproxiedInboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedInitialized event the iterator was last advanced to.
+func (it *BridgeUnproxiedInitializedIterator) Current() *BridgeUnproxiedInitialized {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedMessageDelivered event the iterator was last advanced to.
+func (it *BridgeUnproxiedMessageDeliveredIterator) Current() *BridgeUnproxiedMessageDelivered {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedOutboxToggle event the iterator was last advanced to.
+func (it *BridgeUnproxiedOutboxToggleIterator) Current() *BridgeUnproxiedOutboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedRollupUpdated event the iterator was last advanced to.
+func (it *BridgeUnproxiedRollupUpdatedIterator) Current() *BridgeUnproxiedRollupUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeUnproxiedSequencerInboxUpdated event the iterator was last advanced to.
+func (it *BridgeUnproxiedSequencerInboxUpdatedIterator) Current() *BridgeUnproxiedSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the InboxStubInboxMessageDelivered event the iterator was last advanced to.
+func (it *InboxStubInboxMessageDeliveredIterator) Current() *InboxStubInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the InboxStubInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *InboxStubInboxMessageDeliveredFromOriginIterator) Current() *InboxStubInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the MockRollupEventInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *MockRollupEventInboxInboxMessageDeliveredIterator) Current() *MockRollupEventInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the MockRollupEventInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *MockRollupEventInboxInboxMessageDeliveredFromOriginIterator) Current() *MockRollupEventInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the ProxyAdminForBindingOwnershipTransferred event the iterator was last advanced to.
+func (it *ProxyAdminForBindingOwnershipTransferredIterator) Current() *ProxyAdminForBindingOwnershipTransferred {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockBatchPosterManagerSet event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockBatchPosterManagerSetIterator) Current() *SequencerInboxBlobMockBatchPosterManagerSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockBatchPosterSet event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockBatchPosterSetIterator) Current() *SequencerInboxBlobMockBatchPosterSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockBufferConfigSet event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockBufferConfigSetIterator) Current() *SequencerInboxBlobMockBufferConfigSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockInboxMessageDelivered event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockInboxMessageDeliveredIterator) Current() *SequencerInboxBlobMockInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockInboxMessageDeliveredFromOriginIterator) Current() *SequencerInboxBlobMockInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockInvalidateKeyset event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockInvalidateKeysetIterator) Current() *SequencerInboxBlobMockInvalidateKeyset {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockMaxTimeVariationSet event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockMaxTimeVariationSetIterator) Current() *SequencerInboxBlobMockMaxTimeVariationSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockOwnerFunctionCalled event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockOwnerFunctionCalledIterator) Current() *SequencerInboxBlobMockOwnerFunctionCalled {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockSequencerBatchData event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockSequencerBatchDataIterator) Current() *SequencerInboxBlobMockSequencerBatchData {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockSequencerBatchDelivered event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockSequencerBatchDeliveredIterator) Current() *SequencerInboxBlobMockSequencerBatchDelivered {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockSequencerSet event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockSequencerSetIterator) Current() *SequencerInboxBlobMockSequencerSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxBlobMockSetValidKeyset event the iterator was last advanced to.
+func (it *SequencerInboxBlobMockSetValidKeysetIterator) Current() *SequencerInboxBlobMockSetValidKeyset {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubBatchPosterManagerSet event the iterator was last advanced to.
+func (it *SequencerInboxStubBatchPosterManagerSetIterator) Current() *SequencerInboxStubBatchPosterManagerSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubBatchPosterSet event the iterator was last advanced to.
+func (it *SequencerInboxStubBatchPosterSetIterator) Current() *SequencerInboxStubBatchPosterSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubBufferConfigSet event the iterator was last advanced to.
+func (it *SequencerInboxStubBufferConfigSetIterator) Current() *SequencerInboxStubBufferConfigSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubInboxMessageDelivered event the iterator was last advanced to.
+func (it *SequencerInboxStubInboxMessageDeliveredIterator) Current() *SequencerInboxStubInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *SequencerInboxStubInboxMessageDeliveredFromOriginIterator) Current() *SequencerInboxStubInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubInvalidateKeyset event the iterator was last advanced to.
+func (it *SequencerInboxStubInvalidateKeysetIterator) Current() *SequencerInboxStubInvalidateKeyset {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubMaxTimeVariationSet event the iterator was last advanced to.
+func (it *SequencerInboxStubMaxTimeVariationSetIterator) Current() *SequencerInboxStubMaxTimeVariationSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubOwnerFunctionCalled event the iterator was last advanced to.
+func (it *SequencerInboxStubOwnerFunctionCalledIterator) Current() *SequencerInboxStubOwnerFunctionCalled {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubSequencerBatchData event the iterator was last advanced to.
+func (it *SequencerInboxStubSequencerBatchDataIterator) Current() *SequencerInboxStubSequencerBatchData {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubSequencerBatchDelivered event the iterator was last advanced to.
+func (it *SequencerInboxStubSequencerBatchDeliveredIterator) Current() *SequencerInboxStubSequencerBatchDelivered {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubSequencerSet event the iterator was last advanced to.
+func (it *SequencerInboxStubSequencerSetIterator) Current() *SequencerInboxStubSequencerSet {
+	return it.Event
+}
+
+// Current returns the SequencerInboxStubSetValidKeyset event the iterator was last advanced to.
+func (it *SequencerInboxStubSetValidKeysetIterator) Current() *SequencerInboxStubSetValidKeyset {
+	return it.Event
+}
+
+// Current returns the SimpleCounterEvent event the iterator was last advanced to.
+func (it *SimpleCounterEventIterator) Current() *SimpleCounterEvent {
+	return it.Event
+}
+
+// Current returns the SimpleLogAndIncrementCalled event the iterator was last advanced to.
+func (it *SimpleLogAndIncrementCalledIterator) Current() *SimpleLogAndIncrementCalled {
+	return it.Event
+}
+
+// Current returns the SimpleNullEvent event the iterator was last advanced to.
+func (it *SimpleNullEventIterator) Current() *SimpleNullEvent {
+	return it.Event
+}
+
+// Current returns the SimpleRedeemedEvent event the iterator was last advanced to.
+func (it *SimpleRedeemedEventIterator) Current() *SimpleRedeemedEvent {
+	return it.Event
+}
+
+// Current returns the TestWETH9Approval event the iterator was last advanced to.
+func (it *TestWETH9ApprovalIterator) Current() *TestWETH9Approval {
+	return it.Event
+}
+
+// Current returns the TestWETH9Transfer event the iterator was last advanced to.
+func (it *TestWETH9TransferIterator) Current() *TestWETH9Transfer {
+	return it.Event
+}
+
+// Current returns the UpgradeExecutorMockInitialized event the iterator was last advanced to.
+func (it *UpgradeExecutorMockInitializedIterator) Current() *UpgradeExecutorMockInitialized {
+	return it.Event
+}
+
+// Current returns the UpgradeExecutorMockRoleAdminChanged event the iterator was last advanced to.
+func (it *UpgradeExecutorMockRoleAdminChangedIterator) Current() *UpgradeExecutorMockRoleAdminChanged {
+	return it.Event
+}
+
+// Current returns the UpgradeExecutorMockRoleGranted event the iterator was last advanced to.
+func (it *UpgradeExecutorMockRoleGrantedIterator) Current() *UpgradeExecutorMockRoleGranted {
+	return it.Event
+}
+
+// Current returns the UpgradeExecutorMockRoleRevoked event the iterator was last advanced to.
+func (it *UpgradeExecutorMockRoleRevokedIterator) Current() *UpgradeExecutorMockRoleRevoked {
+	return it.Event
+}
+
+// Current returns the UpgradeExecutorMockTargetCallExecuted event the iterator was last advanced to.
+func (it *UpgradeExecutorMockTargetCallExecutedIterator) Current() *UpgradeExecutorMockTargetCallExecuted {
+	return it.Event
+}
+
+// Current returns the UpgradeExecutorMockUpgradeExecuted event the iterator was last advanced to.
+func (it *UpgradeExecutorMockUpgradeExecutedIterator) Current() *UpgradeExecutorMockUpgradeExecuted {
+	return it.Event
+}
@@ -0,0 +1,9 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package ospgen
+
+// Current returns the HashProofHelperPreimagePartProven event the iterator was last advanced to.
+func (it *HashProofHelperPreimagePartProvenIterator) Current() *HashProofHelperPreimagePartProven {
+	return it.Event
+}
@@ -0,0 +1,84 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package precompilesgen
+
+// Current returns the ArbDebugBasic event the iterator was last advanced to.
+func (it *ArbDebugBasicIterator) Current() *ArbDebugBasic {
+	return it.Event
+}
+
+// Current returns the ArbDebugMixed event the iterator was last advanced to.
+func (it *ArbDebugMixedIterator) Current() *ArbDebugMixed {
+	return it.Event
+}
+
+// Current returns the ArbDebugStore event the iterator was last advanced to.
+func (it *ArbDebugStoreIterator) Current() *ArbDebugStore {
+	return it.Event
+}
+
+// Current returns the ArbOwnerOwnerActs event the iterator was last advanced to.
+func (it *ArbOwnerOwnerActsIterator) Current() *ArbOwnerOwnerActs {
+	return it.Event
+}
+
+// Current returns the ArbOwnerPublicChainOwnerRectified event the iterator was last advanced to.
+func (it *ArbOwnerPublicChainOwnerRectifiedIterator) Current() *ArbOwnerPublicChainOwnerRectified {
+	return it.Event
+}
+
+// Current returns the ArbRetryableTxCanceled event the iterator was last advanced to.
+func (it *ArbRetryableTxCanceledIterator) Current() *ArbRetryableTxCanceled {
+	return it.Event
+}
+
+// Current returns the ArbRetryableTxLifetimeExtended event the iterator was last advanced to.
+func (it *ArbRetryableTxLifetimeExtendedIterator) Current() *ArbRetryableTxLifetimeExtended {
+	return it.Event
+}
+
+// Current returns the ArbRetryableTxRedeemScheduled event the iterator was last advanced to.
+func (it *ArbRetryableTxRedeemScheduledIterator) Current() *ArbRetryableTxRedeemScheduled {
+	return it.Event
+}
+
+// Current returns the ArbRetryableTxRedeemed event the iterator was last advanced to.
+func (it *ArbRetryableTxRedeemedIterator) Current() *ArbRetryableTxRedeemed {
+	return it.Event
+}
+
+// Current returns the ArbRetryableTxTicketCreated event the iterator was last advanced to.
+func (it *ArbRetryableTxTicketCreatedIterator) Current() *ArbRetryableTxTicketCreated {
+	return it.Event
+}
+
+// Current returns the ArbSysL2ToL1Transaction event the iterator was last advanced to.
+func (it *ArbSysL2ToL1TransactionIterator) Current() *ArbSysL2ToL1Transaction {
+	return it.Event
+}
+
+// Current returns the ArbSysL2ToL1Tx event the iterator was last advanced to.
+func (it *ArbSysL2ToL1TxIterator) Current() *ArbSysL2ToL1Tx {
+	return it.Event
+}
+
+// Current returns the ArbSysSendMerkleUpdate event the iterator was last advanced to.
+func (it *ArbSysSendMerkleUpdateIterator) Current() *ArbSysSendMerkleUpdate {
+	return it.Event
+}
+
+// Current returns the ArbWasmProgramActivated event the iterator was last advanced to.
+func (it *ArbWasmProgramActivatedIterator) Current() *ArbWasmProgramActivated {
+	return it.Event
+}
+
+// Current returns the ArbWasmProgramLifetimeExtended event the iterator was last advanced to.
+func (it *ArbWasmProgramLifetimeExtendedIterator) Current() *ArbWasmProgramLifetimeExtended {
+	return it.Event
+}
+
+// Current returns the ArbWasmCacheUpdateProgramCache event the iterator was last advanced to.
+func (it *ArbWasmCacheUpdateProgramCacheIterator) Current() *ArbWasmCacheUpdateProgramCache {
+	return it.Event
+}
@@ -0,0 +1,554 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package rollupgen
+
+// Current returns the AbsRollupEventInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *AbsRollupEventInboxInboxMessageDeliveredIterator) Current() *AbsRollupEventInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the AbsRollupEventInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *AbsRollupEventInboxInboxMessageDeliveredFromOriginIterator) Current() *AbsRollupEventInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the BOLDUpgradeActionRollupMigrated event the iterator was last advanced to.
+func (it *BOLDUpgradeActionRollupMigratedIterator) Current() *BOLDUpgradeActionRollupMigrated {
+	return it.Event
+}
+
+// Current returns the BridgeCreatorERC20TemplatesUpdated event the iterator was last advanced to.
+func (it *BridgeCreatorERC20TemplatesUpdatedIterator) Current() *BridgeCreatorERC20TemplatesUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeCreatorOwnershipTransferred event the iterator was last advanced to.
+func (it *BridgeCreatorOwnershipTransferredIterator) Current() *BridgeCreatorOwnershipTransferred {
+	return it.Event
+}
+
+// Current returns the BridgeCreatorTemplatesUpdated event the iterator was last advanced to.
+func (it *BridgeCreatorTemplatesUpdatedIterator) Current() *BridgeCreatorTemplatesUpdated {
+	return it.Event
+}
+
+// Current returns the ERC20RollupEventInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *ERC20RollupEventInboxInboxMessageDeliveredIterator) Current() *ERC20RollupEventInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the ERC20RollupEventInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *ERC20RollupEventInboxInboxMessageDeliveredFromOriginIterator) Current() *ERC20RollupEventInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the IOldRollupNodeCreated event the iterator was last advanced to.
+func (it *IOldRollupNodeCreatedIterator) Current() *IOldRollupNodeCreated {
+	return it.Event
+}
+
+// Current returns the IRollupAdminAnyTrustFastConfirmerSet event the iterator was last advanced to.
+func (it *IRollupAdminAnyTrustFastConfirmerSetIterator) Current() *IRollupAdminAnyTrustFastConfirmerSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminAssertionForceConfirmed event the iterator was last advanced to.
+func (it *IRollupAdminAssertionForceConfirmedIterator) Current() *IRollupAdminAssertionForceConfirmed {
+	return it.Event
+}
+
+// Current returns the IRollupAdminAssertionForceCreated event the iterator was last advanced to.
+func (it *IRollupAdminAssertionForceCreatedIterator) Current() *IRollupAdminAssertionForceCreated {
+	return it.Event
+}
+
+// Current returns the IRollupAdminBaseStakeSet event the iterator was last advanced to.
+func (it *IRollupAdminBaseStakeSetIterator) Current() *IRollupAdminBaseStakeSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminChallengeManagerSet event the iterator was last advanced to.
+func (it *IRollupAdminChallengeManagerSetIterator) Current() *IRollupAdminChallengeManagerSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminConfirmPeriodBlocksSet event the iterator was last advanced to.
+func (it *IRollupAdminConfirmPeriodBlocksSetIterator) Current() *IRollupAdminConfirmPeriodBlocksSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminDelayedInboxSet event the iterator was last advanced to.
+func (it *IRollupAdminDelayedInboxSetIterator) Current() *IRollupAdminDelayedInboxSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminInboxSet event the iterator was last advanced to.
+func (it *IRollupAdminInboxSetIterator) Current() *IRollupAdminInboxSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminLoserStakeEscrowSet event the iterator was last advanced to.
+func (it *IRollupAdminLoserStakeEscrowSetIterator) Current() *IRollupAdminLoserStakeEscrowSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminMinimumAssertionPeriodSet event the iterator was last advanced to.
+func (it *IRollupAdminMinimumAssertionPeriodSetIterator) Current() *IRollupAdminMinimumAssertionPeriodSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminOldOutboxRemoved event the iterator was last advanced to.
+func (it *IRollupAdminOldOutboxRemovedIterator) Current() *IRollupAdminOldOutboxRemoved {
+	return it.Event
+}
+
+// Current returns the IRollupAdminOutboxSet event the iterator was last advanced to.
+func (it *IRollupAdminOutboxSetIterator) Current() *IRollupAdminOutboxSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminSequencerInboxSet event the iterator was last advanced to.
+func (it *IRollupAdminSequencerInboxSetIterator) Current() *IRollupAdminSequencerInboxSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminStakersForceRefunded event the iterator was last advanced to.
+func (it *IRollupAdminStakersForceRefundedIterator) Current() *IRollupAdminStakersForceRefunded {
+	return it.Event
+}
+
+// Current returns the IRollupAdminValidatorAfkBlocksSet event the iterator was last advanced to.
+func (it *IRollupAdminValidatorAfkBlocksSetIterator) Current() *IRollupAdminValidatorAfkBlocksSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminValidatorWhitelistDisabledSet event the iterator was last advanced to.
+func (it *IRollupAdminValidatorWhitelistDisabledSetIterator) Current() *IRollupAdminValidatorWhitelistDisabledSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminValidatorsSet event the iterator was last advanced to.
+func (it *IRollupAdminValidatorsSetIterator) Current() *IRollupAdminValidatorsSet {
+	return it.Event
+}
+
+// Current returns the IRollupAdminWasmModuleRootSet event the iterator was last advanced to.
+func (it *IRollupAdminWasmModuleRootSetIterator) Current() *IRollupAdminWasmModuleRootSet {
+	return it.Event
+}
+
+// Current returns the IRollupCoreAssertionConfirmed event the iterator was last advanced to.
+func (it *IRollupCoreAssertionConfirmedIterator) Current() *IRollupCoreAssertionConfirmed {
+	return it.Event
+}
+
+// Current returns the IRollupCoreAssertionCreated event the iterator was last advanced to.
+func (it *IRollupCoreAssertionCreatedIterator) Current() *IRollupCoreAssertionCreated {
+	return it.Event
+}
+
+// Current returns the IRollupCoreRollupChallengeStarted event the iterator was last advanced to.
+func (it *IRollupCoreRollupChallengeStartedIterator) Current() *IRollupCoreRollupChallengeStarted {
+	return it.Event
+}
+
+// Current returns the IRollupCoreRollupInitialized event the iterator was last advanced to.
+func (it *IRollupCoreRollupInitializedIterator) Current() *IRollupCoreRollupInitialized {
+	return it.Event
+}
+
+// Current returns the IRollupCoreUserStakeUpdated event the iterator was last advanced to.
+func (it *IRollupCoreUserStakeUpdatedIterator) Current() *IRollupCoreUserStakeUpdated {
+	return it.Event
+}
+
+// Current returns the IRollupCoreUserWithdrawableFundsUpdated event the iterator was last advanced to.
+func (it *IRollupCoreUserWithdrawableFundsUpdatedIterator) Current() *IRollupCoreUserWithdrawableFundsUpdated {
+	return it.Event
+}
+
+// Current returns the IRollupUserAssertionConfirmed event the iterator was last advanced to.
+func (it *IRollupUserAssertionConfirmedIterator) Current() *IRollupUserAssertionConfirmed {
+	return it.Event
+}
+
+// Current returns the IRollupUserAssertionCreated event the iterator was last advanced to.
+func (it *IRollupUserAssertionCreatedIterator) Current() *IRollupUserAssertionCreated {
+	return it.Event
+}
+
+// Current returns the IRollupUserRollupChallengeStarted event the iterator was last advanced to.
+func (it *IRollupUserRollupChallengeStartedIterator) Current() *IRollupUserRollupChallengeStarted {
+	return it.Event
+}
+
+// Current returns the IRollupUserRollupInitialized event the iterator was last advanced to.
+func (it *IRollupUserRollupInitializedIterator) Current() *IRollupUserRollupInitialized {
+	return it.Event
+}
+
+// Current returns the IRollupUserUserStakeUpdated event the iterator was last advanced to.
+func (it *IRollupUserUserStakeUpdatedIterator) Current() *IRollupUserUserStakeUpdated {
+	return it.Event
+}
+
+// Current returns the IRollupUserUserWithdrawableFundsUpdated event the iterator was last advanced to.
+func (it *IRollupUserUserWithdrawableFundsUpdatedIterator) Current() *IRollupUserUserWithdrawableFundsUpdated {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicAdminChanged event the iterator was last advanced to.
+func (it *RollupAdminLogicAdminChangedIterator) Current() *RollupAdminLogicAdminChanged {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicAnyTrustFastConfirmerSet event the iterator was last advanced to.
+func (it *RollupAdminLogicAnyTrustFastConfirmerSetIterator) Current() *RollupAdminLogicAnyTrustFastConfirmerSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicAssertionConfirmed event the iterator was last advanced to.
+func (it *RollupAdminLogicAssertionConfirmedIterator) Current() *RollupAdminLogicAssertionConfirmed {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicAssertionCreated event the iterator was last advanced to.
+func (it *RollupAdminLogicAssertionCreatedIterator) Current() *RollupAdminLogicAssertionCreated {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicAssertionForceConfirmed event the iterator was last advanced to.
+func (it *RollupAdminLogicAssertionForceConfirmedIterator) Current() *RollupAdminLogicAssertionForceConfirmed {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicAssertionForceCreated event the iterator was last advanced to.
+func (it *RollupAdminLogicAssertionForceCreatedIterator) Current() *RollupAdminLogicAssertionForceCreated {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicBaseStakeSet event the iterator was last advanced to.
+func (it *RollupAdminLogicBaseStakeSetIterator) Current() *RollupAdminLogicBaseStakeSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicBeaconUpgraded event the iterator was last advanced to.
+func (it *RollupAdminLogicBeaconUpgradedIterator) Current() *RollupAdminLogicBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicChallengeManagerSet event the iterator was last advanced to.
+func (it *RollupAdminLogicChallengeManagerSetIterator) Current() *RollupAdminLogicChallengeManagerSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicConfirmPeriodBlocksSet event the iterator was last advanced to.
+func (it *RollupAdminLogicConfirmPeriodBlocksSetIterator) Current() *RollupAdminLogicConfirmPeriodBlocksSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicDelayedInboxSet event the iterator was last advanced to.
+func (it *RollupAdminLogicDelayedInboxSetIterator) Current() *RollupAdminLogicDelayedInboxSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicInboxSet event the iterator was last advanced to.
+func (it *RollupAdminLogicInboxSetIterator) Current() *RollupAdminLogicInboxSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicInitialized event the iterator was last advanced to.
+func (it *RollupAdminLogicInitializedIterator) Current() *RollupAdminLogicInitialized {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicLoserStakeEscrowSet event the iterator was last advanced to.
+func (it *RollupAdminLogicLoserStakeEscrowSetIterator) Current() *RollupAdminLogicLoserStakeEscrowSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicMinimumAssertionPeriodSet event the iterator was last advanced to.
+func (it *RollupAdminLogicMinimumAssertionPeriodSetIterator) Current() *RollupAdminLogicMinimumAssertionPeriodSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicOldOutboxRemoved event the iterator was last advanced to.
+func (it *RollupAdminLogicOldOutboxRemovedIterator) Current() *RollupAdminLogicOldOutboxRemoved {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicOutboxSet event the iterator was last advanced to.
+func (it *RollupAdminLogicOutboxSetIterator) Current() *RollupAdminLogicOutboxSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicPaused event the iterator was last advanced to.
+func (it *RollupAdminLogicPausedIterator) Current() *RollupAdminLogicPaused {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicRollupChallengeStarted event the iterator was last advanced to.
+func (it *RollupAdminLogicRollupChallengeStartedIterator) Current() *RollupAdminLogicRollupChallengeStarted {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicRollupInitialized event the iterator was last advanced to.
+func (it *RollupAdminLogicRollupInitializedIterator) Current() *RollupAdminLogicRollupInitialized {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicSequencerInboxSet event the iterator was last advanced to.
+func (it *RollupAdminLogicSequencerInboxSetIterator) Current() *RollupAdminLogicSequencerInboxSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicStakersForceRefunded event the iterator was last advanced to.
+func (it *RollupAdminLogicStakersForceRefundedIterator) Current() *RollupAdminLogicStakersForceRefunded {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicUnpaused event the iterator was last advanced to.
+func (it *RollupAdminLogicUnpausedIterator) Current() *RollupAdminLogicUnpaused {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicUpgraded event the iterator was last advanced to.
+func (it *RollupAdminLogicUpgradedIterator) Current() *RollupAdminLogicUpgraded {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicUpgradedSecondary event the iterator was last advanced to.
+func (it *RollupAdminLogicUpgradedSecondaryIterator) Current() *RollupAdminLogicUpgradedSecondary {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicUserStakeUpdated event the iterator was last advanced to.
+func (it *RollupAdminLogicUserStakeUpdatedIterator) Current() *RollupAdminLogicUserStakeUpdated {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicUserWithdrawableFundsUpdated event the iterator was last advanced to.
+func (it *RollupAdminLogicUserWithdrawableFundsUpdatedIterator) Current() *RollupAdminLogicUserWithdrawableFundsUpdated {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicValidatorAfkBlocksSet event the iterator was last advanced to.
+func (it *RollupAdminLogicValidatorAfkBlocksSetIterator) Current() *RollupAdminLogicValidatorAfkBlocksSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicValidatorWhitelistDisabledSet event the iterator was last advanced to.
+func (it *RollupAdminLogicValidatorWhitelistDisabledSetIterator) Current() *RollupAdminLogicValidatorWhitelistDisabledSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicValidatorsSet event the iterator was last advanced to.
+func (it *RollupAdminLogicValidatorsSetIterator) Current() *RollupAdminLogicValidatorsSet {
+	return it.Event
+}
+
+// Current returns the RollupAdminLogicWasmModuleRootSet event the iterator was last advanced to.
+func (it *RollupAdminLogicWasmModuleRootSetIterator) Current() *RollupAdminLogicWasmModuleRootSet {
+	return it.Event
+}
+
+// Current returns the RollupCoreAssertionConfirmed event the iterator was last advanced to.
+func (it *RollupCoreAssertionConfirmedIterator) Current() *RollupCoreAssertionConfirmed {
+	return it.Event
+}
+
+// Current returns the RollupCoreAssertionCreated event the iterator was last advanced to.
+func (it *RollupCoreAssertionCreatedIterator) Current() *RollupCoreAssertionCreated {
+	return it.Event
+}
+
+// Current returns the RollupCoreInitialized event the iterator was last advanced to.
+func (it *RollupCoreInitializedIterator) Current() *RollupCoreInitialized {
+	return it.Event
+}
+
+// Current returns the RollupCorePaused event the iterator was last advanced to.
+func (it *RollupCorePausedIterator) Current() *RollupCorePaused {
+	return it.Event
+}
+
+// Current returns the RollupCoreRollupChallengeStarted event the iterator was last advanced to.
+func (it *RollupCoreRollupChallengeStartedIterator) Current() *RollupCoreRollupChallengeStarted {
+	return it.Event
+}
+
+// Current returns the RollupCoreRollupInitialized event the iterator was last advanced to.
+func (it *RollupCoreRollupInitializedIterator) Current() *RollupCoreRollupInitialized {
+	return it.Event
+}
+
+// Current returns the RollupCoreUnpaused event the iterator was last advanced to.
+func (it *RollupCoreUnpausedIterator) Current() *RollupCoreUnpaused {
+	return it.Event
+}
+
+// Current returns the RollupCoreUserStakeUpdated event the iterator was last advanced to.
+func (it *RollupCoreUserStakeUpdatedIterator) Current() *RollupCoreUserStakeUpdated {
+	return it.Event
+}
+
+// Current returns the RollupCoreUserWithdrawableFundsUpdated event the iterator was last advanced to.
+func (it *RollupCoreUserWithdrawableFundsUpdatedIterator) Current() *RollupCoreUserWithdrawableFundsUpdated {
+	return it.Event
+}
+
+// Current returns the RollupCreatorOwnershipTransferred event the iterator was last advanced to.
+func (it *RollupCreatorOwnershipTransferredIterator) Current() *RollupCreatorOwnershipTransferred {
+	return it.Event
+}
+
+// Current returns the RollupCreatorRollupCreated event the iterator was last advanced to.
+func (it *RollupCreatorRollupCreatedIterator) Current() *RollupCreatorRollupCreated {
+	return it.Event
+}
+
+// Current returns the RollupCreatorTemplatesUpdated event the iterator was last advanced to.
+func (it *RollupCreatorTemplatesUpdatedIterator) Current() *RollupCreatorTemplatesUpdated {
+	return it.Event
+}
+
+// Current returns the RollupEventInboxInboxMessageDelivered event the iterator was last advanced to.
+func (it *RollupEventInboxInboxMessageDeliveredIterator) Current() *RollupEventInboxInboxMessageDelivered {
+	return it.Event
+}
+
+// Current returns the RollupEventInboxInboxMessageDeliveredFromOrigin event the iterator was last advanced to.
+func (it *RollupEventInboxInboxMessageDeliveredFromOriginIterator) Current() *RollupEventInboxInboxMessageDeliveredFromOrigin {
+	return it.Event
+}
+
+// Current returns the RollupProxyAdminChanged event the iterator was last advanced to.
+func (it *RollupProxyAdminChangedIterator) Current() *RollupProxyAdminChanged {
+	return it.Event
+}
+
+// Current returns the RollupProxyBeaconUpgraded event the iterator was last advanced to.
+func (it *RollupProxyBeaconUpgradedIterator) Current() *RollupProxyBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the RollupProxyUpgraded event the iterator was last advanced to.
+func (it *RollupProxyUpgradedIterator) Current() *RollupProxyUpgraded {
+	return it.Event
+}
+
+// Current returns the RollupProxyUpgradedSecondary event the iterator was last advanced to.
+func (it *RollupProxyUpgradedSecondaryIterator) Current() *RollupProxyUpgradedSecondary {
+	return it.Event
+}
+
+// Current returns the RollupReaderNodeCreated event the iterator was last advanced to.
+func (it *RollupReaderNodeCreatedIterator) Current() *RollupReaderNodeCreated {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicAdminChanged event the iterator was last advanced to.
+func (it *RollupUserLogicAdminChangedIterator) Current() *RollupUserLogicAdminChanged {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicAssertionConfirmed event the iterator was last advanced to.
+func (it *RollupUserLogicAssertionConfirmedIterator) Current() *RollupUserLogicAssertionConfirmed {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicAssertionCreated event the iterator was last advanced to.
+func (it *RollupUserLogicAssertionCreatedIterator) Current() *RollupUserLogicAssertionCreated {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicBeaconUpgraded event the iterator was last advanced to.
+func (it *RollupUserLogicBeaconUpgradedIterator) Current() *RollupUserLogicBeaconUpgraded {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicInitialized event the iterator was last advanced to.
+func (it *RollupUserLogicInitializedIterator) Current() *RollupUserLogicInitialized {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicPaused event the iterator was last advanced to.
+func (it *RollupUserLogicPausedIterator) Current() *RollupUserLogicPaused {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicRollupChallengeStarted event the iterator was last advanced to.
+func (it *RollupUserLogicRollupChallengeStartedIterator) Current() *RollupUserLogicRollupChallengeStarted {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicRollupInitialized event the iterator was last advanced to.
+func (it *RollupUserLogicRollupInitializedIterator) Current() *RollupUserLogicRollupInitialized {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicUnpaused event the iterator was last advanced to.
+func (it *RollupUserLogicUnpausedIterator) Current() *RollupUserLogicUnpaused {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicUpgraded event the iterator was last advanced to.
+func (it *RollupUserLogicUpgradedIterator) Current() *RollupUserLogicUpgraded {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicUpgradedSecondary event the iterator was last advanced to.
+func (it *RollupUserLogicUpgradedSecondaryIterator) Current() *RollupUserLogicUpgradedSecondary {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicUserStakeUpdated event the iterator was last advanced to.
+func (it *RollupUserLogicUserStakeUpdatedIterator) Current() *RollupUserLogicUserStakeUpdated {
+	return it.Event
+}
+
+// Current returns the RollupUserLogicUserWithdrawableFundsUpdated event the iterator was last advanced to.
+func (it *RollupUserLogicUserWithdrawableFundsUpdatedIterator) Current() *RollupUserLogicUserWithdrawableFundsUpdated {
+	return it.Event
+}
+
+// Current returns the StateHashPreImageLookupHashSet event the iterator was last advanced to.
+func (it *StateHashPreImageLookupHashSetIterator) Current() *StateHashPreImageLookupHashSet {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletAllowedExecutorDestinationsUpdated event the iterator was last advanced to.
+func (it *ValidatorWalletAllowedExecutorDestinationsUpdatedIterator) Current() *ValidatorWalletAllowedExecutorDestinationsUpdated {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletExecutorUpdated event the iterator was last advanced to.
+func (it *ValidatorWalletExecutorUpdatedIterator) Current() *ValidatorWalletExecutorUpdated {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletInitialized event the iterator was last advanced to.
+func (it *ValidatorWalletInitializedIterator) Current() *ValidatorWalletInitialized {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletOwnershipTransferred event the iterator was last advanced to.
+func (it *ValidatorWalletOwnershipTransferredIterator) Current() *ValidatorWalletOwnershipTransferred {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletCreatorOwnershipTransferred event the iterator was last advanced to.
+func (it *ValidatorWalletCreatorOwnershipTransferredIterator) Current() *ValidatorWalletCreatorOwnershipTransferred {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletCreatorTemplateUpdated event the iterator was last advanced to.
+func (it *ValidatorWalletCreatorTemplateUpdatedIterator) Current() *ValidatorWalletCreatorTemplateUpdated {
+	return it.Event
+}
+
+// Current returns the ValidatorWalletCreatorWalletCreated event the iterator was last advanced to.
+func (it *ValidatorWalletCreatorWalletCreatedIterator) Current() *ValidatorWalletCreatorWalletCreated {
+	return it.Event
+}
@@ -0,0 +1,69 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package test_helpersgen
+
+// Current returns the BridgeTesterBridgeCallTriggered event the iterator was last advanced to.
+func (it *BridgeTesterBridgeCallTriggeredIterator) Current() *BridgeTesterBridgeCallTriggered {
+	return it.Event
+}
+
+// Current returns the BridgeTesterInboxToggle event the iterator was last advanced to.
+func (it *BridgeTesterInboxToggleIterator) Current() *BridgeTesterInboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeTesterInitialized event the iterator was last advanced to.
+func (it *BridgeTesterInitializedIterator) Current() *BridgeTesterInitialized {
+	return it.Event
+}
+
+// Current returns the BridgeTesterMessageDelivered event the iterator was last advanced to.
+func (it *BridgeTesterMessageDeliveredIterator) Current() *BridgeTesterMessageDelivered {
+	return it.Event
+}
+
+// Current returns the BridgeTesterOutboxToggle event the iterator was last advanced to.
+func (it *BridgeTesterOutboxToggleIterator) Current() *BridgeTesterOutboxToggle {
+	return it.Event
+}
+
+// Current returns the BridgeTesterRollupUpdated event the iterator was last advanced to.
+func (it *BridgeTesterRollupUpdatedIterator) Current() *BridgeTesterRollupUpdated {
+	return it.Event
+}
+
+// Current returns the BridgeTesterSequencerInboxUpdated event the iterator was last advanced to.
+func (it *BridgeTesterSequencerInboxUpdatedIterator) Current() *BridgeTesterSequencerInboxUpdated {
+	return it.Event
+}
+
+// Current returns the OutboxWithoutOptTesterOutBoxTransactionExecuted event the iterator was last advanced to.
+func (it *OutboxWithoutOptTesterOutBoxTransactionExecutedIterator) Current() *OutboxWithoutOptTesterOutBoxTransactionExecuted {
+	return it.Event
+}
+
+// Current returns the OutboxWithoutOptTesterSendRootUpdated event the iterator was last advanced to.
+func (it *OutboxWithoutOptTesterSendRootUpdatedIterator) Current() *OutboxWithoutOptTesterSendRootUpdated {
+	return it.Event
+}
+
+// Current returns the RollupMockWithdrawTriggered event the iterator was last advanced to.
+func (it *RollupMockWithdrawTriggeredIterator) Current() *RollupMockWithdrawTriggered {
+	return it.Event
+}
+
+// Current returns the RollupMockZombieTriggered event the iterator was last advanced to.
+func (it *RollupMockZombieTriggeredIterator) Current() *RollupMockZombieTriggered {
+	return it.Event
+}
+
+// Current returns the TestTokenApproval event the iterator was last advanced to.
+func (it *TestTokenApprovalIterator) Current() *TestTokenApproval {
+	return it.Event
+}
+
+// Current returns the TestTokenTransfer event the iterator was last advanced to.
+func (it *TestTokenTransferIterator) Current() *TestTokenTransfer {
+	return it.Event
+}
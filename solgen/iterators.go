@@ -0,0 +1,39 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+)
+
+// Matches the event iterators generated by abigen, capturing the names of the iterator and event.
+var iteratorPattern = regexp.MustCompile(`(?m)^type (\w+Iterator) struct \{\n\tEvent \*(\w+) `)
+
+// iteratorAccessors generates a Current method for every event iterator in the bindings of a
+// module, returning the event the iterator was last advanced to, so that the iterators satisfy
+// the generic iterator interface of the solgen/go/iterators package. Returns false if the bindings
+// have no event iterators.
+func iteratorAccessors(module string, bindings string) (string, bool, error) {
+	matches := iteratorPattern.FindAllStringSubmatch(bindings, -1)
+	if len(matches) == 0 {
+		return "", false, nil
+	}
+	var code strings.Builder
+	code.WriteString("// Code generated - DO NOT EDIT.\n")
+	code.WriteString("// This file is a generated binding and any manual changes will be lost.\n\n")
+	fmt.Fprintf(&code, "package %s\n", module)
+	for _, match := range matches {
+		iterator, event := match[1], match[2]
+		fmt.Fprintf(&code, "\n// Current returns the %s event the iterator was last advanced to.\n", event)
+		fmt.Fprintf(&code, "func (it *%s) Current() *%s {\n\treturn it.Event\n}\n", iterator, event)
+	}
+	formatted, err := format.Source([]byte(code.String()))
+	if err != nil {
+		return "", false, err
+	}
+	return string(formatted), true, nil
+}
@@ -169,6 +169,18 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
+
+		accessors, ok, err := iteratorAccessors(module, code)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if ok {
+			// #nosec G306
+			err = os.WriteFile(filepath.Join(folder, module+"_iterators.go"), []byte(accessors), 0o644)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
 	}
 
 	fmt.Println("successfully generated go abi files")
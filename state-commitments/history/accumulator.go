@@ -0,0 +1,131 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package history
+
+import (
+	"errors"
+
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Accumulator computes a history commitment as leaves are appended to it one at a time, such as
+// the states of a machine as it steps, so that the commitment need not be rebuilt from all the
+// leaves after each one. The roots are the same at every challenge level, so it serves block, big
+// step and small step histories alike. Each append hashes only O(log n) nodes, and the accumulator
+// holds only the Merkle expansions over its leaves with and without the last one, and the roots of
+// the complete subtrees in the proof of the first leaf.
+type Accumulator struct {
+	exp prefixproofs.MerkleExpansion
+	// The expansion over all the leaves but the last, from which the proof of the last leaf is read.
+	prefix prefixproofs.MerkleExpansion
+	// The roots of the complete subtrees right of the ancestors of the first leaf.
+	complete  []common.Hash
+	size      uint64
+	firstLeaf common.Hash
+	lastLeaf  common.Hash
+}
+
+// NewAccumulator creates an accumulator without any leaves.
+func NewAccumulator() *Accumulator {
+	return &Accumulator{
+		exp:    prefixproofs.NewEmptyMerkleExpansion(),
+		prefix: prefixproofs.NewEmptyMerkleExpansion(),
+	}
+}
+
+// AppendLeaf appends a leaf to the history.
+func (a *Accumulator) AppendLeaf(leaf common.Hash) error {
+	exp, err := prefixproofs.AppendLeaves(a.exp, []common.Hash{leaf})
+	if err != nil {
+		return err
+	}
+	if size := a.size + 1; size > 1 && size&(size-1) == 0 {
+		// The leaf completes the subtree right of the ancestor of the first leaf at the level
+		// below the top, whose other leaves are those of the lower levels of the expansion.
+		level := uint64(len(a.complete))
+		subtree, err := prefixproofs.AppendLeaves(a.exp[:level], []common.Hash{leaf})
+		if err != nil {
+			return err
+		}
+		a.complete = append(a.complete, subtree[level])
+	}
+	a.prefix = a.exp
+	a.exp = exp
+	if a.size == 0 {
+		a.firstLeaf = leaf
+	}
+	a.lastLeaf = leaf
+	a.size++
+	return nil
+}
+
+// Size is the number of leaves appended.
+func (a *Accumulator) Size() uint64 {
+	return a.size
+}
+
+// Root computes the Merkle root over the leaves appended, as New does over the same leaves.
+func (a *Accumulator) Root() (common.Hash, error) {
+	if a.size == 0 {
+		return common.Hash{}, errors.New("must commit to at least one leaf")
+	}
+	return prefixproofs.Root(a.exp)
+}
+
+// LastLeafProof computes the inclusion proof of the last leaf appended, as New does over the same
+// leaves.
+func (a *Accumulator) LastLeafProof() ([]common.Hash, error) {
+	if a.size == 0 {
+		return nil, errors.New("must commit to at least one leaf")
+	}
+	return lastLeafProofFromExpansion(a.prefix, a.size)
+}
+
+// FirstLeafProof computes the inclusion proof of the first leaf appended, as New does over the
+// same leaves.
+func (a *Accumulator) FirstLeafProof() ([]common.Hash, error) {
+	if a.size == 0 {
+		return nil, errors.New("must commit to at least one leaf")
+	}
+	return firstLeafProofFromExpansion(a.complete, a.exp, a.size)
+}
+
+// History is the commitment over the leaves appended, as New computes over the same leaves.
+func (a *Accumulator) History() (History, error) {
+	root, err := a.Root()
+	if err != nil {
+		return emptyCommit, err
+	}
+	firstLeafProof, err := a.FirstLeafProof()
+	if err != nil {
+		return emptyCommit, err
+	}
+	lastLeafProof, err := a.LastLeafProof()
+	if err != nil {
+		return emptyCommit, err
+	}
+	return History{
+		Height:         a.size - 1,
+		Merkle:         root,
+		FirstLeaf:      a.firstLeaf,
+		LastLeaf:       a.lastLeaf,
+		FirstLeafProof: firstLeafProof,
+		LastLeafProof:  lastLeafProof,
+	}, nil
+}
+
+// Prefix is the prefix over the leaves appended, from which NewFromPrefix can extend the
+// commitment.
+func (a *Accumulator) Prefix() (Prefix, error) {
+	commit, err := a.History()
+	if err != nil {
+		return Prefix{}, err
+	}
+	return Prefix{
+		Expansion:      a.exp.Clone(),
+		FirstLeafProof: commit.FirstLeafProof,
+		LastLeafProof:  commit.LastLeafProof,
+	}, nil
+}
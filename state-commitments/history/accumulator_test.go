@@ -0,0 +1,44 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package history
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccumulator(t *testing.T) {
+	acc := NewAccumulator()
+	_, err := acc.Root()
+	require.Error(t, err)
+	_, err = acc.LastLeafProof()
+	require.Error(t, err)
+
+	leaves := make([]common.Hash, 70)
+	for i := 0; i < len(leaves); i++ {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+		require.NoError(t, acc.AppendLeaf(leaves[i]))
+		require.Equal(t, uint64(i+1), acc.Size())
+
+		want, err := New(leaves[:i+1])
+		require.NoError(t, err)
+		got, err := acc.History()
+		require.NoError(t, err)
+		require.Equal(t, want, got, "leaves %d", i+1)
+	}
+
+	// The prefix extends the commitment without the accumulator.
+	more := append(leaves, crypto.Keccak256Hash([]byte("more")))
+	want, err := New(more)
+	require.NoError(t, err)
+	prefix, err := acc.Prefix()
+	require.NoError(t, err)
+	got, _, err := NewFromPrefix(prefix, more)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
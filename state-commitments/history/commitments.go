@@ -6,11 +6,12 @@
 package history
 
 import (
+	"context"
 	"errors"
-	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
-	"sync"
+	"fmt"
+	"math/bits"
 
-	inclusionproofs "github.com/OffchainLabs/bold/state-commitments/inclusion-proofs"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -35,57 +36,202 @@ type History struct {
 }
 
 func New(leaves []common.Hash) (History, error) {
-	if len(leaves) == 0 {
-		return emptyCommit, errors.New("must commit to at least one leaf")
-	}
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(3)
+	return NewContext(context.Background(), leaves)
+}
+
+// NewContext computes a history commitment over a list of leaves like New, giving up once the
+// context is done.
+func NewContext(ctx context.Context, leaves []common.Hash) (History, error) {
+	commit, _, err := NewFromPrefixContext(ctx, Prefix{}, leaves)
+	return commit, err
+}
+
+// Prefix is what a history commitment is extended from: the Merkle expansion over a prefix of its
+// leaves, along with the inclusion proofs of the first and last leaves of the prefix. Together they
+// hold every node the commitment needs from the prefix, so its leaves need not be hashed again.
+type Prefix struct {
+	Expansion      prefixproofs.MerkleExpansion `json:"expansion"`
+	FirstLeafProof []common.Hash                `json:"firstLeafProof,omitempty"`
+	LastLeafProof  []common.Hash                `json:"lastLeafProof,omitempty"`
+}
 
-	var firstLeafProof []common.Hash
-	var err1 error
-	go func() {
-		defer waitGroup.Done()
-		firstLeafProof, err1 = inclusionproofs.GenerateInclusionProof(leaves, 0)
-	}()
+// Size is the number of leaves of the prefix.
+func (p Prefix) Size() uint64 {
+	return p.Expansion.Size()
+}
+
+// Validate checks that the proofs of the prefix are those of a tree of its size, which they are
+// not in prefixes stored before the proofs were.
+func (p Prefix) Validate() error {
+	want, err := proofLength(p.Size())
+	if err != nil {
+		return err
+	}
+	if uint64(len(p.FirstLeafProof)) != want || uint64(len(p.LastLeafProof)) != want {
+		return fmt.Errorf("prefix over %d leaves needs proofs of %d nodes, got %d and %d", p.Size(), want, len(p.FirstLeafProof), len(p.LastLeafProof))
+	}
+	return nil
+}
 
-	var lastLeafProof []common.Hash
-	var err2 error
-	go func() {
-		defer waitGroup.Done()
-		lastLeafProof, err2 = inclusionproofs.GenerateInclusionProof(leaves, uint64(len(leaves))-1)
-	}()
+// NewFromPrefix computes a history commitment over a list of leaves given a prefix of them, so that
+// only the leaves after the prefix are hashed. It also returns the prefix over all the leaves,
+// which can be used to extend the commitment later.
+func NewFromPrefix(prefix Prefix, leaves []common.Hash) (History, Prefix, error) {
+	return NewFromPrefixContext(context.Background(), prefix, leaves)
+}
 
-	var root common.Hash
-	var err3 error
-	go func() {
-		defer waitGroup.Done()
-		exp := prefixproofs.NewEmptyMerkleExpansion()
-		for _, r := range leaves {
-			exp, err3 = prefixproofs.AppendLeaf(exp, r)
-			if err3 != nil {
-				return
+// NewFromPrefixContext is NewFromPrefix, giving up once the context is done. If it is, the prefix
+// over the leaves hashed until then is returned along with the error, so that callers may
+// checkpoint it and resume from it later.
+//
+// The inclusion proofs are read from expansions as the leaves are appended. The ancestors of the
+// last leaf are the last nodes of their levels, so the proof of the last leaf is the expansion over
+// the leaves before it. The proof of the first leaf holds, at each level, the root of the subtree
+// right of the first leaf's ancestor, which is completed when the number of leaves reaches the next
+// power of two, or is the root of the lower levels of the expansion over all the leaves for the
+// subtree at the top, if it is not complete.
+func NewFromPrefixContext(
+	ctx context.Context,
+	prefix Prefix,
+	leaves []common.Hash,
+) (History, Prefix, error) {
+	if len(leaves) == 0 {
+		return emptyCommit, Prefix{}, errors.New("must commit to at least one leaf")
+	}
+	numLeaves := uint64(len(leaves))
+	prefixSize := prefix.Size()
+	if prefixSize > numLeaves {
+		return emptyCommit, Prefix{}, fmt.Errorf("expansion over %d leaves is larger than the %d leaves committed to", prefixSize, numLeaves)
+	}
+	if err := prefix.Validate(); err != nil {
+		return emptyCommit, Prefix{}, err
+	}
+	exp := prefix.Expansion.Clone()
+	completeLevels := uint64(0)
+	if prefixSize > 0 {
+		var err error
+		if completeLevels, err = prefixproofs.MostSignificantBit(prefixSize); err != nil {
+			return emptyCommit, Prefix{}, err
+		}
+	}
+	// The roots of the subtrees right of the ancestors of the first leaf which are complete.
+	complete := append([]common.Hash{}, prefix.FirstLeafProof[:completeLevels]...)
+	lastLeafProof := append([]common.Hash{}, prefix.LastLeafProof...)
+	size := prefixSize
+	for size < numLeaves {
+		// The context is checked as often as prefixproofs.AppendLeavesContext checks it, so that
+		// the leaves hashed before giving up are checkpointed at the same sizes.
+		if (size-prefixSize)%prefixproofs.CancellationCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				firstLeafProof, proofErr := firstLeafProofFromExpansion(complete, exp, size)
+				if proofErr != nil {
+					return emptyCommit, Prefix{}, proofErr
+				}
+				return emptyCommit, Prefix{Expansion: exp, FirstLeafProof: firstLeafProof, LastLeafProof: lastLeafProof}, err
 			}
 		}
-		root, err3 = prefixproofs.Root(exp)
-	}()
-	waitGroup.Wait()
-
-	if err1 != nil {
-		return emptyCommit, err1
+		end := prefixSize + ((size-prefixSize)/prefixproofs.CancellationCheckInterval+1)*prefixproofs.CancellationCheckInterval
+		end = min(end, numLeaves, nextPowerOfTwo(size))
+		before, err := prefixproofs.AppendLeaves(exp, leaves[size:end-1])
+		if err != nil {
+			return emptyCommit, Prefix{}, err
+		}
+		leaf := leaves[end-1]
+		if end > 1 && end&(end-1) == 0 {
+			// The last leaf completes the subtree right of the ancestor of the first leaf at the
+			// level below the top, whose leaves are those of the lower levels of the expansion.
+			level := uint64(bits.TrailingZeros64(end)) - 1
+			subtree, err := prefixproofs.AppendLeaves(before[:level], []common.Hash{leaf})
+			if err != nil {
+				return emptyCommit, Prefix{}, err
+			}
+			complete = append(complete, subtree[level])
+		}
+		if exp, err = prefixproofs.AppendLeaves(before, []common.Hash{leaf}); err != nil {
+			return emptyCommit, Prefix{}, err
+		}
+		if lastLeafProof, err = lastLeafProofFromExpansion(before, end); err != nil {
+			return emptyCommit, Prefix{}, err
+		}
+		size = end
 	}
-	if err2 != nil {
-		return emptyCommit, err2
+	root, err := prefixproofs.Root(exp)
+	if err != nil {
+		return emptyCommit, Prefix{}, err
 	}
-	if err3 != nil {
-		return emptyCommit, err3
+	firstLeafProof, err := firstLeafProofFromExpansion(complete, exp, numLeaves)
+	if err != nil {
+		return emptyCommit, Prefix{}, err
 	}
-
-	return History{
+	commit := History{
 		Merkle:         root,
-		Height:         uint64(len(leaves) - 1),
+		Height:         numLeaves - 1,
 		FirstLeaf:      leaves[0],
-		LastLeaf:       leaves[len(leaves)-1],
+		LastLeaf:       leaves[numLeaves-1],
 		FirstLeafProof: firstLeafProof,
 		LastLeafProof:  lastLeafProof,
-	}, nil
+	}
+	return commit, Prefix{Expansion: exp, FirstLeafProof: firstLeafProof, LastLeafProof: lastLeafProof}, nil
+}
+
+// The number of nodes in the inclusion proofs of a tree over a number of leaves.
+func proofLength(size uint64) (uint64, error) {
+	if size <= 1 {
+		return 0, nil
+	}
+	msb, err := prefixproofs.MostSignificantBit(size - 1)
+	if err != nil {
+		return 0, err
+	}
+	return msb + 1, nil
+}
+
+// The smallest power of two greater than x.
+func nextPowerOfTwo(x uint64) uint64 {
+	return 1 << bits.Len64(x)
+}
+
+// Computes the inclusion proof of the first leaf of a tree over some leaves from the roots of the
+// complete subtrees right of its ancestors, and the expansion over the leaves. If the number of
+// leaves is not a power of two, the subtree right of the ancestor at the top is incomplete, and its
+// leaves are those of the levels of the expansion below the top, whose root is padded up to it.
+func firstLeafProofFromExpansion(complete []common.Hash, exp prefixproofs.MerkleExpansion, size uint64) ([]common.Hash, error) {
+	length, err := proofLength(size)
+	if err != nil {
+		return nil, err
+	}
+	proof := make([]common.Hash, 0, length)
+	proof = append(proof, complete...)
+	if uint64(len(proof)) == length {
+		return proof, nil
+	}
+	top := uint64(len(complete))
+	root, err := prefixproofs.Root(exp[:top])
+	if err != nil {
+		return nil, err
+	}
+	// The root of an expansion whose only subtree is at its highest level is at that level,
+	// rather than above it as for any other expansion.
+	if size-(1<<top) == 1<<(top-1) {
+		root = prefixproofs.HashPair(root, common.Hash{})
+	}
+	return append(proof, root), nil
+}
+
+// Computes the inclusion proof of the last leaf of a tree over some leaves from the expansion over
+// the leaves before it. The ancestors of the last leaf are the last nodes of their levels, so each
+// is either the right child of a complete subtree in that expansion, or a left child without a
+// sibling.
+func lastLeafProofFromExpansion(before prefixproofs.MerkleExpansion, size uint64) ([]common.Hash, error) {
+	length, err := proofLength(size)
+	if err != nil {
+		return nil, err
+	}
+	proof := make([]common.Hash, length)
+	for level := range proof {
+		if level < len(before) {
+			proof[level] = before[level]
+		}
+	}
+	return proof, nil
 }
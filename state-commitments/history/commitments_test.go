@@ -5,10 +5,13 @@ package history
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
 
 	inclusionproofs "github.com/OffchainLabs/bold/state-commitments/inclusion-proofs"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,3 +32,132 @@ func TestHistoryCommitment_LeafProofs(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, history.Merkle, computed)
 }
+
+func TestHistoryCommitment_FromPrefix(t *testing.T) {
+	leaves := make([]common.Hash, 11)
+	for i := 0; i < len(leaves); i++ {
+		leaves[i] = common.BytesToHash([]byte(fmt.Sprintf("%d", i)))
+	}
+	want, err := New(leaves)
+	require.NoError(t, err)
+
+	_, prefix, err := NewFromPrefix(Prefix{}, leaves[:6])
+	require.NoError(t, err)
+	got, extended, err := NewFromPrefix(prefix, leaves)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, uint64(len(leaves)), extended.Size())
+
+	_, _, err = NewFromPrefix(extended, leaves[:5])
+	require.ErrorContains(t, err, "larger than the 5 leaves")
+
+	// Prefixes stored with only their expansion cannot be extended.
+	expansion, err := prefixproofs.ExpansionFromLeaves(leaves[:6])
+	require.NoError(t, err)
+	_, _, err = NewFromPrefix(Prefix{Expansion: expansion}, leaves)
+	require.ErrorContains(t, err, "needs proofs")
+}
+
+// The commitment and both its proofs are computed from the prefix and the leaves after it, so the
+// leaves of the prefix are never read but for the first and last leaves committed to.
+func TestHistoryCommitment_FromPrefixSkipsPrefixLeaves(t *testing.T) {
+	leaves := make([]common.Hash, 70)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+	}
+	for size := 1; size <= len(leaves); size++ {
+		want, err := New(leaves[:size])
+		require.NoError(t, err)
+		for _, idx := range []uint64{0, want.Height} {
+			proof, err := inclusionproofs.GenerateInclusionProof(leaves[:size], idx)
+			require.NoError(t, err)
+			if idx == 0 {
+				require.Equal(t, proof, want.FirstLeafProof, "leaves %d", size)
+			} else {
+				require.Equal(t, proof, want.LastLeafProof, "leaves %d", size)
+			}
+		}
+		for _, prefixSize := range []int{1, size / 2, size - 1, size} {
+			if prefixSize < 1 {
+				continue
+			}
+			_, prefix, err := NewFromPrefix(Prefix{}, leaves[:prefixSize])
+			require.NoError(t, err)
+			scrambled := append([]common.Hash{}, leaves[:size]...)
+			for i := 1; i < prefixSize && i < size-1; i++ {
+				scrambled[i] = common.Hash{}
+			}
+			got, _, err := NewFromPrefix(prefix, scrambled)
+			require.NoError(t, err)
+			require.Equal(t, want, got, "leaves %d, prefix %d", size, prefixSize)
+		}
+	}
+}
+
+// The roots below were computed on linux/amd64. Commitments must not depend on the platform
+// they are computed on, as validators on different architectures need to agree on them, so
+// this test runs in CI on arm64 and windows as well.
+func TestHistoryCommitment_PlatformIndependentRoots(t *testing.T) {
+	for _, tt := range []struct {
+		numLeaves int
+		root      common.Hash
+	}{
+		{1, common.HexToHash("0x167ec62fb9079cd62ea3c433bdf947569464c2abbd0fb690d51c83f6e8184ed7")},
+		{2, common.HexToHash("0x1dc4495236450188e96d13b154dca7135d9a4892c5975e030514bd49a70e98a5")},
+		{3, common.HexToHash("0xa55d0b9314a34e74df5e118a8cdefa8c480bebbccf2146c059d55857ff277f69")},
+		{7, common.HexToHash("0x47dae68ecd2bc29c053b207c146138df916ef4c46bff5e22067f73297ea57076")},
+		{8, common.HexToHash("0x9d1558f5e7378730d4c50760c4df80e4b1acc1353dc758835e297769d3e4d38b")},
+		{33, common.HexToHash("0x1c3a0241575ca5cb689e9248effddd93beb76ae879c0a5c305a513889daf1f31")},
+		{1000, common.HexToHash("0xeff6736e31ca4fcccac05a49d19176abf7acbdc20e704cd410beb24b9fe33737")},
+	} {
+		t.Run(fmt.Sprintf("%d leaves", tt.numLeaves), func(t *testing.T) {
+			leaves := make([]common.Hash, tt.numLeaves)
+			for i := range leaves {
+				leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("leaf-%d", i)))
+			}
+			history, err := New(leaves)
+			require.NoError(t, err)
+			require.Equal(t, tt.root, history.Merkle)
+		})
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	for _, size := range []int{1 << 10, 1 << 16} {
+		leaves := make([]common.Hash, size)
+		for i := range leaves {
+			leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+		}
+		b.Run(fmt.Sprintf("leaves=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			for i := 0; i < b.N; i++ {
+				if _, err := New(leaves); err != nil {
+					b.Fatal(err)
+				}
+			}
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+			b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gcs/op")
+		})
+	}
+}
+
+func BenchmarkNewFromPrefix(b *testing.B) {
+	leaves := make([]common.Hash, 1<<16+1)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+	}
+	_, prefix, err := NewFromPrefix(Prefix{}, leaves[:len(leaves)-1])
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := NewFromPrefix(prefix, leaves); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -6,9 +6,10 @@
 package inclusionproofs
 
 import (
+	"context"
+
 	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	"runtime"
 	"sync"
@@ -21,6 +22,18 @@ var (
 
 // FullTree generates a Merkle tree from a list of leaves.
 func FullTree(leaves []common.Hash) ([][]common.Hash, error) {
+	return FullTreeContext(context.Background(), leaves)
+}
+
+// FullTreeContext generates a Merkle tree from a list of leaves like FullTree, giving up once the
+// context is done.
+func FullTreeContext(ctx context.Context, leaves []common.Hash) ([][]common.Hash, error) {
+	return fullTree(ctx, leaves, make([]common.Hash, nodesAboveLeaves(len(leaves))))
+}
+
+// Builds the layers of the tree above the leaves into a buffer of at least nodesAboveLeaves hashes,
+// rather than allocating each layer separately.
+func fullTree(ctx context.Context, leaves, buf []common.Hash) ([][]common.Hash, error) {
 	msb, err := prefixproofs.MostSignificantBit(uint64(len(leaves)))
 	if err != nil {
 		return nil, err
@@ -40,12 +53,19 @@ func FullTree(leaves []common.Hash) ([][]common.Hash, error) {
 
 	prevLayer := leaves
 	for len(prevLayer) > 1 {
-		nextLayer := make([]common.Hash, (len(prevLayer)+1)/2)
+		size := (len(prevLayer) + 1) / 2
+		nextLayer := buf[:size:size]
+		buf = buf[size:]
 		for i := 0; i < len(nextLayer); i++ {
+			if i%prefixproofs.CancellationCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
 			if 2*i+1 < len(prevLayer) {
-				nextLayer[i] = crypto.Keccak256Hash(prevLayer[2*i].Bytes(), prevLayer[2*i+1].Bytes())
+				nextLayer[i] = prefixproofs.HashPair(prevLayer[2*i], prevLayer[2*i+1])
 			} else {
-				nextLayer[i] = crypto.Keccak256Hash(prevLayer[2*i].Bytes(), (common.Hash{}).Bytes())
+				nextLayer[i] = prefixproofs.HashPair(prevLayer[2*i], common.Hash{})
 			}
 		}
 		layers[l] = nextLayer
@@ -55,8 +75,38 @@ func FullTree(leaves []common.Hash) ([][]common.Hash, error) {
 	return layers, nil
 }
 
+// The number of nodes in the layers of a tree above its leaves.
+func nodesAboveLeaves(numLeaves int) int {
+	nodes := 0
+	for numLeaves > 1 {
+		numLeaves = (numLeaves + 1) / 2
+		nodes += numLeaves
+	}
+	return nodes
+}
+
+// Trees built only to read proofs from are discarded right after, so the buffers holding their
+// rehashed leaves and layers are reused across proofs rather than left to the garbage collector.
+var treeBufferPool sync.Pool
+
+// Gets a buffer for the rehashed leaves and the layers above them of a tree over some leaves.
+func getTreeBuffer(numLeaves int) *[]common.Hash {
+	size := numLeaves + nodesAboveLeaves(numLeaves)
+	if buf, ok := treeBufferPool.Get().(*[]common.Hash); ok && cap(*buf) >= size {
+		*buf = (*buf)[:size]
+		return buf
+	}
+	buf := make([]common.Hash, size)
+	return &buf
+}
+
 // GenerateInclusionProof from a list of Merkle leaves at a specified index.
 func GenerateInclusionProof(leaves []common.Hash, idx uint64) ([]common.Hash, error) {
+	return GenerateInclusionProofContext(context.Background(), leaves, idx)
+}
+
+// GenerateInclusionProofContext is GenerateInclusionProof, giving up once the context is done.
+func GenerateInclusionProofContext(ctx context.Context, leaves []common.Hash, idx uint64) ([]common.Hash, error) {
 	if len(leaves) == 0 {
 		return nil, ErrInvalidLeaves
 	}
@@ -66,31 +116,13 @@ func GenerateInclusionProof(leaves []common.Hash, idx uint64) ([]common.Hash, er
 	if len(leaves) == 1 {
 		return make([]common.Hash, 0), nil
 	}
-	rehashed := make([]common.Hash, len(leaves))
-	var waitGroup sync.WaitGroup
-	gomaxprocs := runtime.GOMAXPROCS(-1)
-	waitGroup.Add(gomaxprocs)
-	batchSize := len(leaves) / gomaxprocs
-	batchRemainder := len(leaves) % gomaxprocs
-	for i := 0; i < gomaxprocs-1; i++ {
-		start := i * batchSize
-		go func() {
-			defer waitGroup.Done()
-			for j := start; j < start+batchSize; j++ {
-				rehashed[j] = crypto.Keccak256Hash(leaves[j].Bytes())
-			}
-		}()
+	buf := getTreeBuffer(len(leaves))
+	defer treeBufferPool.Put(buf)
+	rehashed := (*buf)[:len(leaves)]
+	if err := rehashLeaves(ctx, leaves, rehashed); err != nil {
+		return nil, err
 	}
-	start := (gomaxprocs - 1) * batchSize
-	go func() {
-		defer waitGroup.Done()
-		for j := start; j < start+batchSize+batchRemainder; j++ {
-			rehashed[j] = crypto.Keccak256Hash(leaves[j].Bytes())
-		}
-	}()
-	waitGroup.Wait()
-
-	fullT, err := FullTree(rehashed)
+	fullT, err := fullTree(ctx, rehashed, (*buf)[len(leaves):])
 	if err != nil {
 		return nil, err
 	}
@@ -114,18 +146,106 @@ func GenerateInclusionProof(leaves []common.Hash, idx uint64) ([]common.Hash, er
 	return proof, nil
 }
 
+// GenerateLastLeafProofs generates the inclusion proofs of the last leaf of each prefix of the
+// leaves with the given sizes, as GenerateInclusionProof would for leaves[:size] at index size-1,
+// hashing the leaves into a tree only once for all of them. This works because the counterparts
+// along the path of the last leaf of a prefix either cover only earlier leaves, and so are shared
+// by the tree over all the leaves, or lie past the end of the prefix, and so are empty.
+func GenerateLastLeafProofs(leaves []common.Hash, sizes ...uint64) ([][]common.Hash, error) {
+	return GenerateLastLeafProofsContext(context.Background(), leaves, sizes...)
+}
+
+// GenerateLastLeafProofsContext is GenerateLastLeafProofs, giving up once the context is done.
+func GenerateLastLeafProofsContext(ctx context.Context, leaves []common.Hash, sizes ...uint64) ([][]common.Hash, error) {
+	for _, size := range sizes {
+		if size == 0 || size > uint64(len(leaves)) {
+			return nil, ErrInvalidLeaves
+		}
+	}
+	proofs := make([][]common.Hash, len(sizes))
+	if len(leaves) == 1 {
+		for i := range proofs {
+			proofs[i] = make([]common.Hash, 0)
+		}
+		return proofs, nil
+	}
+	buf := getTreeBuffer(len(leaves))
+	defer treeBufferPool.Put(buf)
+	rehashed := (*buf)[:len(leaves)]
+	if err := rehashLeaves(ctx, leaves, rehashed); err != nil {
+		return nil, err
+	}
+	fullT, err := fullTree(ctx, rehashed, (*buf)[len(leaves):])
+	if err != nil {
+		return nil, err
+	}
+	for i, size := range sizes {
+		if size == 1 {
+			proofs[i] = make([]common.Hash, 0)
+			continue
+		}
+		idx := size - 1
+		maxLevel, err := prefixproofs.MostSignificantBit(idx)
+		if err != nil {
+			return nil, err
+		}
+		proof := make([]common.Hash, maxLevel+1)
+		for level := uint64(0); level <= maxLevel; level++ {
+			levelIndex := idx >> level
+			if levelIndex&1 == 1 {
+				proof[level] = fullT[level][levelIndex-1]
+			}
+		}
+		proofs[i] = proof
+	}
+	return proofs, nil
+}
+
+// Hashes each of the leaves into rehashed, in parallel, giving up once the context is done.
+func rehashLeaves(ctx context.Context, leaves, rehashed []common.Hash) error {
+	var waitGroup sync.WaitGroup
+	gomaxprocs := runtime.GOMAXPROCS(-1)
+	waitGroup.Add(gomaxprocs)
+	batchSize := len(leaves) / gomaxprocs
+	batchRemainder := len(leaves) % gomaxprocs
+	for i := 0; i < gomaxprocs-1; i++ {
+		start := i * batchSize
+		go func() {
+			defer waitGroup.Done()
+			for j := start; j < start+batchSize; j++ {
+				if (j-start)%prefixproofs.CancellationCheckInterval == 0 && ctx.Err() != nil {
+					return
+				}
+				rehashed[j] = prefixproofs.HashLeaf(leaves[j])
+			}
+		}()
+	}
+	start := (gomaxprocs - 1) * batchSize
+	go func() {
+		defer waitGroup.Done()
+		for j := start; j < start+batchSize+batchRemainder; j++ {
+			if (j-start)%prefixproofs.CancellationCheckInterval == 0 && ctx.Err() != nil {
+				return
+			}
+			rehashed[j] = prefixproofs.HashLeaf(leaves[j])
+		}
+	}()
+	waitGroup.Wait()
+	return ctx.Err()
+}
+
 // CalculateRootFromProof calculates a Merkle root from a Merkle proof, index, and leaf.
 func CalculateRootFromProof(proof []common.Hash, index uint64, leaf common.Hash) (common.Hash, error) {
 	if len(proof) > 256 {
 		return common.Hash{}, ErrProofTooLong
 	}
-	h := crypto.Keccak256Hash(leaf[:])
+	h := prefixproofs.HashLeaf(leaf)
 	for i := 0; i < len(proof); i++ {
 		node := proof[i]
 		if index&(1<<i) == 0 {
-			h = crypto.Keccak256Hash(h[:], node[:])
+			h = prefixproofs.HashPair(h, node)
 		} else {
-			h = crypto.Keccak256Hash(node[:], h[:])
+			h = prefixproofs.HashPair(node, h)
 		}
 	}
 	return h, nil
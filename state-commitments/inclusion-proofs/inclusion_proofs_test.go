@@ -73,3 +73,38 @@ func TestInclusionProof(t *testing.T) {
 		require.NotEqual(t, ErrProofTooLong, err)
 	})
 }
+
+func TestGenerateLastLeafProofs(t *testing.T) {
+	leaves := make([]common.Hash, 37)
+	for i := 0; i < len(leaves); i++ {
+		leaves[i] = common.BytesToHash([]byte(fmt.Sprintf("%d", i)))
+	}
+	for n := 1; n <= len(leaves); n++ {
+		sizes := []uint64{uint64(n), uint64((n + 1) / 2), 1}
+		proofs, err := GenerateLastLeafProofs(leaves[:n], sizes...)
+		require.NoError(t, err)
+		for i, size := range sizes {
+			expected, err := GenerateInclusionProof(leaves[:size], size-1)
+			require.NoError(t, err)
+			require.Equal(t, expected, proofs[i], "prefix of %d leaves of %d", size, n)
+		}
+	}
+	_, err := GenerateLastLeafProofs(leaves[:4], 5)
+	require.Equal(t, ErrInvalidLeaves, err)
+	_, err = GenerateLastLeafProofs(leaves[:4], 0)
+	require.Equal(t, ErrInvalidLeaves, err)
+}
+
+func BenchmarkGenerateInclusionProof(b *testing.B) {
+	leaves := make([]common.Hash, 1<<16)
+	for i := range leaves {
+		leaves[i] = common.BytesToHash([]byte(fmt.Sprintf("%d", i)))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GenerateInclusionProof(leaves, uint64(i%len(leaves))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package prefixproofs
+
+import (
+	"context"
+
+	"github.com/OffchainLabs/bold/math"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	b32Arr, _ = abi.NewType("bytes32[]", "", nil)
+	// ProofArgs is the ABI encoding of a prefix proof as createLayerZeroEdge and bisectEdge decode
+	// it: the expansion over the prefix, followed by the roots appended to it to reach the full tree.
+	ProofArgs = abi.Arguments{
+		{Type: b32Arr, Name: "prefixExpansion"},
+		{Type: b32Arr, Name: "prefixProof"},
+	}
+)
+
+// GenerateEncodedPrefixProof proves that the tree over the first preSize leaves is a prefix of the
+// tree over all the leaves, encoded as the challenge manager expects it. The proof is verified
+// against the roots of both trees before it is returned, so that an invalid proof is caught here
+// rather than by a reverted transaction.
+func GenerateEncodedPrefixProof(ctx context.Context, leaves []common.Hash, preSize uint64) ([]byte, error) {
+	postSize := uint64(len(leaves))
+	if preSize == 0 {
+		return nil, errors.Wrap(ErrCannotBeZero, "presize was 0")
+	}
+	if preSize >= postSize {
+		return nil, errors.Wrapf(ErrStartNotLessThanEnd, "presize %d >= postsize %d", preSize, postSize)
+	}
+	preExpansion, err := AppendLeavesContext(ctx, NewEmptyMerkleExpansion(), leaves[:preSize])
+	if err != nil {
+		return nil, err
+	}
+	postExpansion, err := AppendLeavesContext(ctx, preExpansion, leaves[preSize:])
+	if err != nil {
+		return nil, err
+	}
+	proof, err := GeneratePrefixProof(preSize, preExpansion, leaves[preSize:], RootFetcherFromExpansion)
+	if err != nil {
+		return nil, err
+	}
+	// The generated proof starts with the compact pre expansion, which the encoding carries apart.
+	_, numRead := MerkleExpansionFromCompact(proof, preSize)
+	onlyProof := proof[numRead:]
+	encoded, err := ProofArgs.Pack(&preExpansion, &onlyProof)
+	if err != nil {
+		return nil, err
+	}
+	preRoot, err := Root(preExpansion)
+	if err != nil {
+		return nil, err
+	}
+	postRoot, err := Root(postExpansion)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyEncodedPrefixProof(preRoot, preSize, postRoot, postSize, encoded); err != nil {
+		return nil, errors.Wrap(err, "could not verify prefix proof locally")
+	}
+	return encoded, nil
+}
+
+// GenerateLayerZeroPrefixProof proves that the start of a layer zero edge, its first leaf, is a
+// prefix of its end, as createLayerZeroEdge expects. The leaves are those of the edge from height
+// zero up to its end height.
+func GenerateLayerZeroPrefixProof(ctx context.Context, leaves []common.Hash) ([]byte, error) {
+	return GenerateEncodedPrefixProof(ctx, leaves, 1)
+}
+
+// GenerateBisectionPrefixProof proves that the history at the height an edge from startHeight to
+// endHeight bisects at is a prefix of the edge's end history, as bisectEdge expects. The leaves are
+// those of the edge's challenge level from height zero up to its end height, as the edge's histories
+// commit to them. The root of the history at the bisection height is returned with the proof, which
+// bisectEdge takes along with it.
+func GenerateBisectionPrefixProof(
+	ctx context.Context,
+	leaves []common.Hash,
+	startHeight,
+	endHeight uint64,
+) (common.Hash, []byte, error) {
+	if uint64(len(leaves)) != endHeight+1 {
+		return common.Hash{}, nil, errors.Errorf("got %d leaves for an edge ending at height %d", len(leaves), endHeight)
+	}
+	middleHeight, err := math.Bisect(startHeight, endHeight)
+	if err != nil {
+		return common.Hash{}, nil, errors.Wrapf(err, "edge from height %d to %d", startHeight, endHeight)
+	}
+	proof, err := GenerateEncodedPrefixProof(ctx, leaves, middleHeight+1)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	preExpansion, _, err := DecodePrefixProof(proof)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	bisectionRoot, err := Root(preExpansion)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return bisectionRoot, proof, nil
+}
+
+// DecodePrefixProof decodes a prefix proof as the challenge manager does, into the expansion over
+// the prefix and the roots appended to it.
+func DecodePrefixProof(encoded []byte) ([]common.Hash, []common.Hash, error) {
+	if len(encoded) == 0 {
+		return nil, nil, errors.New("empty prefix proof")
+	}
+	values, err := ProofArgs.Unpack(encoded)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode prefix proof")
+	}
+	preExpansion, ok := values[0].([][32]byte)
+	if !ok {
+		return nil, nil, errors.Errorf("unexpected prefix expansion type %T", values[0])
+	}
+	proof, ok := values[1].([][32]byte)
+	if !ok {
+		return nil, nil, errors.Errorf("unexpected prefix proof type %T", values[1])
+	}
+	return toHashes(preExpansion), toHashes(proof), nil
+}
+
+// VerifyEncodedPrefixProof verifies an encoded prefix proof as the challenge manager does, that the
+// tree of preSize leaves with the pre root is a prefix of the tree of postSize leaves with the post
+// root.
+func VerifyEncodedPrefixProof(preRoot common.Hash, preSize uint64, postRoot common.Hash, postSize uint64, encoded []byte) error {
+	preExpansion, proof, err := DecodePrefixProof(encoded)
+	if err != nil {
+		return err
+	}
+	return VerifyPrefixProof(&VerifyPrefixProofConfig{
+		PreRoot:      preRoot,
+		PreSize:      preSize,
+		PostRoot:     postRoot,
+		PostSize:     postSize,
+		PreExpansion: preExpansion,
+		PrefixProof:  proof,
+	})
+}
+
+func toHashes(values [][32]byte) []common.Hash {
+	hashes := make([]common.Hash, len(values))
+	for i, v := range values {
+		hashes[i] = v
+	}
+	return hashes
+}
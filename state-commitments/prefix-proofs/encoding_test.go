@@ -0,0 +1,98 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package prefixproofs_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/OffchainLabs/bold/math"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBisectionPrefixProof_GoSolidityEquivalence(t *testing.T) {
+	ctx := context.Background()
+	merkleTreeContract, _ := setupMerkleTreeContract(t)
+	root := func(leaves []common.Hash) common.Hash {
+		exp, err := prefixproofs.ExpansionFromLeaves(leaves)
+		require.NoError(t, err)
+		r, err := prefixproofs.Root(exp)
+		require.NoError(t, err)
+		return r
+	}
+	for _, edge := range [][2]uint64{{0, 2}, {0, 16}, {0, 32}, {8, 16}, {3, 11}, {5, 7}, {16, 31}} {
+		start, end := edge[0], edge[1]
+		t.Run(fmt.Sprintf("%d to %d", start, end), func(t *testing.T) {
+			leaves := make([]common.Hash, end+1)
+			for i := range leaves {
+				leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+			}
+			middle, err := math.Bisect(start, end)
+			require.NoError(t, err)
+			bisectionRoot, proof, err := prefixproofs.GenerateBisectionPrefixProof(ctx, leaves, start, end)
+			require.NoError(t, err)
+			require.Equal(t, root(leaves[:middle+1]), bisectionRoot)
+
+			preExpansion, prefixProof, err := prefixproofs.DecodePrefixProof(proof)
+			require.NoError(t, err)
+			err = merkleTreeContract.VerifyPrefixProof(
+				&bind.CallOpts{},
+				bisectionRoot,
+				new(big.Int).SetUint64(middle+1),
+				root(leaves),
+				new(big.Int).SetUint64(end+1),
+				toArrays(preExpansion),
+				toArrays(prefixProof),
+			)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGenerateLayerZeroPrefixProof(t *testing.T) {
+	ctx := context.Background()
+	leaves := make([]common.Hash, 33)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+	}
+	proof, err := prefixproofs.GenerateLayerZeroPrefixProof(ctx, leaves)
+	require.NoError(t, err)
+	startExp, err := prefixproofs.ExpansionFromLeaves(leaves[:1])
+	require.NoError(t, err)
+	startRoot, err := prefixproofs.Root(startExp)
+	require.NoError(t, err)
+	endExp, err := prefixproofs.ExpansionFromLeaves(leaves)
+	require.NoError(t, err)
+	endRoot, err := prefixproofs.Root(endExp)
+	require.NoError(t, err)
+	require.NoError(t, prefixproofs.VerifyEncodedPrefixProof(startRoot, 1, endRoot, 33, proof))
+
+	// The proof does not hold for a different end, nor once tampered with.
+	require.Error(t, prefixproofs.VerifyEncodedPrefixProof(startRoot, 1, startRoot, 33, proof))
+	preExpansion, prefixProof, err := prefixproofs.DecodePrefixProof(proof)
+	require.NoError(t, err)
+	prefixProof[0] = common.Hash{1}
+	tampered, err := prefixproofs.ProofArgs.Pack(toArrays(preExpansion), toArrays(prefixProof))
+	require.NoError(t, err)
+	require.ErrorIs(t, prefixproofs.VerifyEncodedPrefixProof(startRoot, 1, endRoot, 33, tampered), prefixproofs.ErrRootMismatch)
+
+	_, err = prefixproofs.GenerateLayerZeroPrefixProof(ctx, leaves[:1])
+	require.ErrorIs(t, err, prefixproofs.ErrStartNotLessThanEnd)
+	_, _, err = prefixproofs.DecodePrefixProof(nil)
+	require.Error(t, err)
+}
+
+func toArrays(hashes []common.Hash) [][32]byte {
+	arrays := make([][32]byte, len(hashes))
+	for i, h := range hashes {
+		arrays[i] = h
+	}
+	return arrays
+}
@@ -0,0 +1,54 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package prefixproofs
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Hashing with crypto.Keccak256Hash allocates a Keccak state for every hash, and 32 bytes for every
+// hash passed to it as a slice, which adds up to millions of short-lived allocations over a large
+// commitment. Hashers are pooled instead, and hash from and into buffers of their own.
+type hasher struct {
+	state crypto.KeccakState
+	in    [2 * common.HashLength]byte
+	out   common.Hash
+}
+
+var hasherPool = sync.Pool{
+	New: func() any {
+		return &hasher{state: crypto.NewKeccakState()}
+	},
+}
+
+func (h *hasher) hash(in []byte) common.Hash {
+	h.state.Reset()
+	h.state.Write(in)
+	h.state.Read(h.out[:])
+	return h.out
+}
+
+// HashLeaf hashes a leaf as it is appended to a tree, which is the same as
+// crypto.Keccak256Hash(leaf[:]) without allocating.
+func HashLeaf(leaf common.Hash) common.Hash {
+	h := hasherPool.Get().(*hasher)
+	copy(h.in[:common.HashLength], leaf[:])
+	out := h.hash(h.in[:common.HashLength])
+	hasherPool.Put(h)
+	return out
+}
+
+// HashPair hashes two nodes of a tree into their parent, which is the same as
+// crypto.Keccak256Hash(left[:], right[:]) without allocating.
+func HashPair(left, right common.Hash) common.Hash {
+	h := hasherPool.Get().(*hasher)
+	copy(h.in[:common.HashLength], left[:])
+	copy(h.in[common.HashLength:], right[:])
+	out := h.hash(h.in[:])
+	hasherPool.Put(h)
+	return out
+}
@@ -0,0 +1,23 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package prefixproofs
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashing(t *testing.T) {
+	left := crypto.Keccak256Hash([]byte("left"))
+	right := crypto.Keccak256Hash([]byte("right"))
+	require.Equal(t, crypto.Keccak256Hash(left[:]), HashLeaf(left))
+	require.Equal(t, crypto.Keccak256Hash(left[:], right[:]), HashPair(left, right))
+	require.Equal(t, crypto.Keccak256Hash(right[:], (common.Hash{}).Bytes()), HashPair(right, common.Hash{}))
+	require.Zero(t, testing.AllocsPerRun(100, func() {
+		HashPair(HashLeaf(left), right)
+	}))
+}
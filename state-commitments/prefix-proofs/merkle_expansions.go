@@ -4,9 +4,14 @@
 package prefixproofs
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// The number of leaves hashed between checks of whether the context is done.
+const CancellationCheckInterval = 1 << 12
+
 type MerkleExpansion []common.Hash
 
 func NewEmptyMerkleExpansion() MerkleExpansion {
@@ -46,18 +51,68 @@ func MerkleExpansionFromCompact(comp []common.Hash, size uint64) (MerkleExpansio
 	return me, numRead
 }
 
+// Size is the number of leaves the expansion commits to.
+func (me MerkleExpansion) Size() uint64 {
+	_, size := me.Compact()
+	return size
+}
+
 func ExpansionFromLeaves(leaves []common.Hash) (MerkleExpansion, error) {
-	ret := NewEmptyMerkleExpansion()
-	for _, leaf := range leaves {
-		appended, err := AppendLeaf(ret, leaf)
+	return AppendLeaves(NewEmptyMerkleExpansion(), leaves)
+}
+
+// AppendLeaves extends an expansion with more leaves, only hashing the new leaves.
+// The input expansion is not modified.
+func AppendLeaves(me MerkleExpansion, leaves []common.Hash) (MerkleExpansion, error) {
+	return AppendLeavesContext(context.Background(), me, leaves)
+}
+
+// AppendLeavesContext extends an expansion with more leaves like AppendLeaves, stopping early once
+// the context is done. The expansion over the leaves appended until then is returned along with the
+// error of the context, so that callers may resume from it rather than from the input expansion.
+func AppendLeavesContext(ctx context.Context, me MerkleExpansion, leaves []common.Hash) (MerkleExpansion, error) {
+	// The leaves are appended to a copy of the expansion in place, which has room for the highest
+	// level up front, rather than allocating a new expansion for each leaf as AppendLeaf does.
+	ret := make(MerkleExpansion, len(me), max(uint64(len(me)), MAX_LEVEL))
+	copy(ret, me)
+	for i, leaf := range leaves {
+		if i%CancellationCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return ret, err
+			}
+		}
+		var err error
+		ret, err = ret.appendLeaf(leaf)
 		if err != nil {
 			return nil, err
 		}
-		ret = appended
 	}
 	return ret, nil
 }
 
+// Appends a leaf to the expansion in place, as AppendLeaf would to a copy of it.
+func (me MerkleExpansion) appendLeaf(leaf common.Hash) (MerkleExpansion, error) {
+	if uint64(len(me)) > MAX_LEVEL {
+		return nil, ErrExpansionTooLarge
+	}
+	accum := HashLeaf(leaf)
+	if accum == (common.Hash{}) {
+		return nil, ErrCannotAppendEmpty
+	}
+	for i := range me {
+		if me[i] == (common.Hash{}) {
+			me[i] = accum
+			return me, nil
+		}
+		accum = HashPair(me[i], accum)
+		me[i] = common.Hash{}
+	}
+	if uint64(len(me)) >= MAX_LEVEL {
+		return nil, ErrLevelTooHigh
+	}
+	return append(me, accum), nil
+}
+
 type MerkleExpansionRootFetcherFunc = func(leaves []common.Hash, upTo uint64) (common.Hash, error)
 
 func RootFetcherFromExpansion(leaves []common.Hash, upTo uint64) (common.Hash, error) {
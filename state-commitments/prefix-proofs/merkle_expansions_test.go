@@ -4,8 +4,10 @@
 package prefixproofs
 
 import (
+	"context"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
@@ -65,3 +67,80 @@ func compUncompTest(t *testing.T, me MerkleExpansion) {
 	require.NoError(t, err)
 	require.Equal(t, root, root2)
 }
+
+func TestAppendLeaves(t *testing.T) {
+	leaves := make([]common.Hash, 13)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte{byte(i)})
+	}
+	full, err := ExpansionFromLeaves(leaves)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(leaves)), full.Size())
+	// Appending in place matches appending each leaf to a new expansion.
+	var oneByOne []common.Hash
+	for _, leaf := range leaves {
+		oneByOne, err = AppendLeaf(oneByOne, leaf)
+		require.NoError(t, err)
+	}
+	require.Equal(t, oneByOne, []common.Hash(full))
+	fullRoot, err := Root(full)
+	require.NoError(t, err)
+
+	for k := 0; k <= len(leaves); k++ {
+		prefix, err := ExpansionFromLeaves(leaves[:k])
+		require.NoError(t, err)
+		prefixCopy := prefix.Clone()
+		extended, err := AppendLeaves(prefix, leaves[k:])
+		require.NoError(t, err)
+		require.Equal(t, prefixCopy, prefix)
+		root, err := Root(extended)
+		require.NoError(t, err)
+		require.Equal(t, fullRoot, root)
+	}
+}
+
+func TestAppendLeavesContext_ReturnsPartialExpansion(t *testing.T) {
+	leaves := make([]common.Hash, 2*CancellationCheckInterval+1)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	prefix, err := ExpansionFromLeaves(leaves[:1])
+	require.NoError(t, err)
+	partial, err := AppendLeavesContext(ctx, prefix, leaves[1:])
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, prefix, partial)
+
+	full, err := AppendLeavesContext(context.Background(), prefix, leaves[1:])
+	require.NoError(t, err)
+	want, err := ExpansionFromLeaves(leaves)
+	require.NoError(t, err)
+	require.Equal(t, want, full)
+}
+
+func TestAppendLeaves_AllocationsDoNotGrowWithLeaves(t *testing.T) {
+	leaves := make([]common.Hash, 1<<12)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		_, err := ExpansionFromLeaves(leaves)
+		require.NoError(t, err)
+	})
+	require.LessOrEqual(t, allocs, 2.0)
+}
+
+func BenchmarkAppendLeaves(b *testing.B) {
+	leaves := make([]common.Hash, 1<<16)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ExpansionFromLeaves(leaves); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Package streamingproofs generates Merkle inclusion and prefix proofs for trees too large to hold
+// in memory. Rather than building the full tree, a generator streams the leaves once to cache the
+// upper levels of the tree, and reads back the leaves below a cached node whenever a proof needs
+// the nodes under it, trading recomputation for memory.
+//
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+package streamingproofs
+
+import (
+	"context"
+
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// DefaultMemoryCap is the default number of bytes a generator may hold at once.
+const DefaultMemoryCap = uint64(256 << 20)
+
+var (
+	ErrMemoryCapTooLow = errors.New("memory cap too low for the tree")
+	ErrShortRead       = errors.New("leaf reader returned the wrong number of leaves")
+)
+
+// LeafReader reads the leaves of a tree from start, inclusive, to end, exclusive.
+type LeafReader func(ctx context.Context, start, end uint64) ([]common.Hash, error)
+
+// Generator generates proofs over the leaves of a tree read from a LeafReader. It caches the
+// levels of the tree from its cache level up, and recomputes the levels below from the leaves
+// under a single cached node at a time. The cache level is the lowest one for which the cached
+// levels and the leaves under a cached node fit in the memory cap.
+type Generator struct {
+	numLeaves  uint64
+	read       LeafReader
+	memoryCap  uint64
+	height     uint64
+	cacheLevel uint64
+	// The levels of the tree from the cache level up to the root.
+	cache [][]common.Hash
+}
+
+// Opt configures a generator.
+type Opt func(*Generator)
+
+// WithMemoryCap bounds the number of bytes of hashes a generator holds at once, counting its cache
+// and the leaves under a cached node along with the levels computed from them. Defaults to
+// DefaultMemoryCap.
+func WithMemoryCap(bytes uint64) Opt {
+	return func(g *Generator) {
+		g.memoryCap = bytes
+	}
+}
+
+// NewGenerator streams the leaves of a tree once, in chunks of the leaves under a node of the
+// cache level, to compute its root and cache its upper levels.
+func NewGenerator(ctx context.Context, numLeaves uint64, read LeafReader, opts ...Opt) (*Generator, error) {
+	if numLeaves == 0 {
+		return nil, errors.Wrap(prefixproofs.ErrCannotBeZero, "number of leaves was 0")
+	}
+	g := &Generator{
+		numLeaves: numLeaves,
+		read:      read,
+		memoryCap: DefaultMemoryCap,
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	if numLeaves > 1 {
+		msb, err := prefixproofs.MostSignificantBit(numLeaves - 1)
+		if err != nil {
+			return nil, err
+		}
+		g.height = msb + 1
+	}
+	cacheLevel, needed, ok := g.chooseCacheLevel()
+	if !ok {
+		return nil, errors.Wrapf(
+			ErrMemoryCapTooLow,
+			"cap of %d bytes is below the %d bytes needed for a tree of %d leaves",
+			g.memoryCap,
+			needed,
+			numLeaves,
+		)
+	}
+	g.cacheLevel = cacheLevel
+
+	chunkSize := uint64(1) << cacheLevel
+	numChunks := (numLeaves + chunkSize - 1) / chunkSize
+	layer := make([]common.Hash, numChunks)
+	for i := uint64(0); i < numChunks; i++ {
+		node, err := g.subtreeRoot(ctx, cacheLevel, i)
+		if err != nil {
+			return nil, err
+		}
+		layer[i] = node
+	}
+	g.cache = [][]common.Hash{layer}
+	for level := cacheLevel; level < g.height; level++ {
+		layer = hashLayer(layer)
+		g.cache = append(g.cache, layer)
+	}
+	return g, nil
+}
+
+// NumLeaves is the number of leaves of the tree.
+func (g *Generator) NumLeaves() uint64 {
+	return g.numLeaves
+}
+
+// Root of the tree, as prefixproofs.Root computes it from the expansion over all its leaves.
+func (g *Generator) Root() common.Hash {
+	return g.cache[len(g.cache)-1][0]
+}
+
+// InclusionProof generates the inclusion proof of the leaf at an index, as
+// inclusionproofs.GenerateInclusionProof does over all the leaves, reading only the leaves under
+// the cached node above it.
+func (g *Generator) InclusionProof(ctx context.Context, idx uint64) ([]common.Hash, error) {
+	if idx >= g.numLeaves {
+		return nil, errors.Wrapf(prefixproofs.ErrIndexOutOfRange, "index %d, %d leaves", idx, g.numLeaves)
+	}
+	proof := make([]common.Hash, g.height)
+	layers, err := g.subtreeLayers(ctx, g.cacheLevel, idx>>g.cacheLevel)
+	if err != nil {
+		return nil, err
+	}
+	for level := uint64(0); level < g.height; level++ {
+		var layer []common.Hash
+		counterpart := (idx >> level) ^ 1
+		if level < g.cacheLevel {
+			layer = layers[level]
+			counterpart -= (idx >> g.cacheLevel) << (g.cacheLevel - level)
+		} else {
+			layer = g.cache[level-g.cacheLevel]
+		}
+		if counterpart < uint64(len(layer)) {
+			proof[level] = layer[counterpart]
+		}
+	}
+	return proof, nil
+}
+
+// Expansion computes the Merkle expansion over the first size leaves of the tree.
+func (g *Generator) Expansion(ctx context.Context, size uint64) (prefixproofs.MerkleExpansion, error) {
+	if size == 0 || size > g.numLeaves {
+		return nil, errors.Wrapf(prefixproofs.ErrIndexOutOfRange, "size %d, %d leaves", size, g.numLeaves)
+	}
+	msb, err := prefixproofs.MostSignificantBit(size)
+	if err != nil {
+		return nil, err
+	}
+	exp := make(prefixproofs.MerkleExpansion, msb+1)
+	for level := uint64(0); level <= msb; level++ {
+		if size&(1<<level) == 0 {
+			continue
+		}
+		exp[level], err = g.node(ctx, level, (size>>level)-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return exp, nil
+}
+
+// PrefixProof generates the proof that the first preSize leaves of the tree are a prefix of its
+// first postSize leaves, as prefixproofs.GeneratePrefixProof does: the compact expansion over the
+// prefix, followed by the roots of the complete subtrees appended to it.
+func (g *Generator) PrefixProof(ctx context.Context, preSize, postSize uint64) ([]common.Hash, error) {
+	if postSize > g.numLeaves {
+		return nil, errors.Wrapf(prefixproofs.ErrIndexOutOfRange, "post size %d, %d leaves", postSize, g.numLeaves)
+	}
+	if preSize >= postSize {
+		return nil, errors.Wrapf(prefixproofs.ErrStartNotLessThanEnd, "presize %d >= postsize %d", preSize, postSize)
+	}
+	exp, err := g.Expansion(ctx, preSize)
+	if err != nil {
+		return nil, err
+	}
+	proof, _ := exp.Compact()
+	for size := preSize; size < postSize; {
+		level, err := prefixproofs.MaximumAppendBetween(size, postSize)
+		if err != nil {
+			return nil, err
+		}
+		node, err := g.node(ctx, level, size>>level)
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, node)
+		size += 1 << level
+	}
+	return proof, nil
+}
+
+// The node of the tree at a level and index, from the cache if the level is cached, or else
+// computed from the leaves under it.
+func (g *Generator) node(ctx context.Context, level, idx uint64) (common.Hash, error) {
+	if level >= g.cacheLevel {
+		return g.cache[level-g.cacheLevel][idx], nil
+	}
+	return g.subtreeRoot(ctx, level, idx)
+}
+
+func (g *Generator) subtreeRoot(ctx context.Context, level, idx uint64) (common.Hash, error) {
+	layers, err := g.subtreeLayers(ctx, level, idx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return layers[level][0], nil
+}
+
+// Reads the leaves under the node at a level and index and computes the levels of the subtree
+// rooted at it. As in the full tree, a node without a right sibling is hashed with an empty hash,
+// so that the root of the subtree over the last leaves matches its node in the tree.
+func (g *Generator) subtreeLayers(ctx context.Context, level, idx uint64) ([][]common.Hash, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	start := idx << level
+	end := min(start+(1<<level), g.numLeaves)
+	leaves, err := g.read(ctx, start, end)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read leaves %d to %d", start, end)
+	}
+	if uint64(len(leaves)) != end-start {
+		return nil, errors.Wrapf(ErrShortRead, "got %d leaves, wanted %d", len(leaves), end-start)
+	}
+	layers := make([][]common.Hash, level+1)
+	layers[0] = make([]common.Hash, len(leaves))
+	for i, leaf := range leaves {
+		layers[0][i] = prefixproofs.HashLeaf(leaf)
+	}
+	for l := uint64(1); l <= level; l++ {
+		layers[l] = hashLayer(layers[l-1])
+	}
+	return layers, nil
+}
+
+// Hashes the nodes of a level of the tree in pairs into the level above.
+func hashLayer(layer []common.Hash) []common.Hash {
+	next := make([]common.Hash, (len(layer)+1)/2)
+	for i := range next {
+		right := common.Hash{}
+		if 2*i+1 < len(layer) {
+			right = layer[2*i+1]
+		}
+		next[i] = prefixproofs.HashPair(layer[2*i], right)
+	}
+	return next
+}
+
+// Chooses the lowest cache level for which the generator fits in its memory cap. If none does,
+// returns the least memory any cache level needs.
+func (g *Generator) chooseCacheLevel() (uint64, uint64, bool) {
+	least := uint64(0)
+	for level := uint64(0); level <= g.height; level++ {
+		needed := memoryNeeded(g.numLeaves, g.height, level)
+		if needed <= g.memoryCap {
+			return level, needed, true
+		}
+		if level == 0 || needed < least {
+			least = needed
+		}
+	}
+	return 0, least, false
+}
+
+// The number of bytes held by a generator over a tree of a height with the given cache level: the
+// cached levels, and the leaves under a cached node as read, rehashed and hashed up to it.
+func memoryNeeded(numLeaves, height, cacheLevel uint64) uint64 {
+	nodes := uint64(0)
+	width := numLeaves
+	for level := uint64(0); level <= height; level++ {
+		if level >= cacheLevel {
+			nodes += width
+		}
+		width = (width + 1) / 2
+	}
+	chunkSize := min(uint64(1)<<cacheLevel, numLeaves)
+	nodes += 3 * chunkSize
+	return nodes * common.HashLength
+}
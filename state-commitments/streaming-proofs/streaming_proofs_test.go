@@ -0,0 +1,132 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package streamingproofs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	inclusionproofs "github.com/OffchainLabs/bold/state-commitments/inclusion-proofs"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func testLeaves(n int) []common.Hash {
+	leaves := make([]common.Hash, n)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("%d", i)))
+	}
+	return leaves
+}
+
+// Reads leaves from a slice, recording the largest read.
+func sliceReader(leaves []common.Hash, largestRead *uint64) LeafReader {
+	return func(_ context.Context, start, end uint64) ([]common.Hash, error) {
+		*largestRead = max(*largestRead, end-start)
+		return leaves[start:end], nil
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	ctx := context.Background()
+	for _, n := range []int{1, 2, 3, 5, 8, 13, 64, 100} {
+		leaves := testLeaves(n)
+		exp, err := prefixproofs.ExpansionFromLeaves(leaves)
+		require.NoError(t, err)
+		wantRoot, err := prefixproofs.Root(exp)
+		require.NoError(t, err)
+
+		// Caps from caching the whole tree down to caching only its upper levels.
+		for _, memoryCap := range []uint64{DefaultMemoryCap, 64 * 32, 20 * 32} {
+			var largestRead uint64
+			g, err := NewGenerator(ctx, uint64(n), sliceReader(leaves, &largestRead), WithMemoryCap(memoryCap))
+			if errors.Is(err, ErrMemoryCapTooLow) {
+				continue
+			}
+			require.NoError(t, err)
+			require.Equal(t, wantRoot, g.Root(), "n=%d cap=%d", n, memoryCap)
+			require.LessOrEqual(t, largestRead, uint64(1)<<g.cacheLevel)
+
+			for i := 0; i < n; i++ {
+				want, err := inclusionproofs.GenerateInclusionProof(leaves, uint64(i))
+				require.NoError(t, err)
+				got, err := g.InclusionProof(ctx, uint64(i))
+				require.NoError(t, err)
+				require.Equal(t, want, got, "n=%d cap=%d idx=%d", n, memoryCap, i)
+			}
+
+			for pre := 1; pre < n; pre++ {
+				for post := pre + 1; post <= n; post++ {
+					preExp, err := prefixproofs.ExpansionFromLeaves(leaves[:pre])
+					require.NoError(t, err)
+					gotExp, err := g.Expansion(ctx, uint64(pre))
+					require.NoError(t, err)
+					require.Equal(t, preExp, gotExp)
+
+					want, err := prefixproofs.GeneratePrefixProof(uint64(pre), preExp, leaves[pre:post], prefixproofs.RootFetcherFromExpansion)
+					require.NoError(t, err)
+					got, err := g.PrefixProof(ctx, uint64(pre), uint64(post))
+					require.NoError(t, err)
+					require.Equal(t, want, got, "n=%d cap=%d pre=%d post=%d", n, memoryCap, pre, post)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerator_MemoryCap(t *testing.T) {
+	ctx := context.Background()
+	leaves := testLeaves(1 << 10)
+
+	var largestRead uint64
+	g, err := NewGenerator(ctx, uint64(len(leaves)), sliceReader(leaves, &largestRead))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), g.cacheLevel)
+
+	// A tighter cap caches fewer levels and reads more leaves at once.
+	largestRead = 0
+	g, err = NewGenerator(ctx, uint64(len(leaves)), sliceReader(leaves, &largestRead), WithMemoryCap(256*32))
+	require.NoError(t, err)
+	require.Greater(t, g.cacheLevel, uint64(0))
+	require.LessOrEqual(t, memoryNeeded(g.numLeaves, g.height, g.cacheLevel), uint64(256*32))
+	_, err = g.InclusionProof(ctx, 700)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1)<<g.cacheLevel, largestRead)
+
+	_, err = NewGenerator(ctx, uint64(len(leaves)), sliceReader(leaves, &largestRead), WithMemoryCap(32*32))
+	require.ErrorIs(t, err, ErrMemoryCapTooLow)
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	ctx := context.Background()
+	leaves := testLeaves(10)
+	var largestRead uint64
+
+	_, err := NewGenerator(ctx, 0, sliceReader(leaves, &largestRead))
+	require.ErrorIs(t, err, prefixproofs.ErrCannotBeZero)
+
+	short := func(_ context.Context, start, end uint64) ([]common.Hash, error) {
+		return leaves[start : end-1], nil
+	}
+	_, err = NewGenerator(ctx, 10, short)
+	require.ErrorIs(t, err, ErrShortRead)
+
+	g, err := NewGenerator(ctx, 10, sliceReader(leaves, &largestRead))
+	require.NoError(t, err)
+	_, err = g.InclusionProof(ctx, 10)
+	require.ErrorIs(t, err, prefixproofs.ErrIndexOutOfRange)
+	_, err = g.PrefixProof(ctx, 4, 11)
+	require.ErrorIs(t, err, prefixproofs.ErrIndexOutOfRange)
+	_, err = g.PrefixProof(ctx, 4, 4)
+	require.ErrorIs(t, err, prefixproofs.ErrStartNotLessThanEnd)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = NewGenerator(canceled, 10, sliceReader(leaves, &largestRead))
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,238 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package conformance
+
+import (
+	"context"
+	"fmt"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/containers/option"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	"github.com/OffchainLabs/bold/math"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const categoryActions = "actions"
+
+// Rival is a level zero block edge for the suite to create, claiming an assertion with the history
+// a provider computes for it. It is created with the key of its chain, which must be funded, or
+// impersonated on a fork, and approved to stake on the challenge manager.
+type Rival struct {
+	Chain     protocol.AssertionChain
+	Assertion protocol.Assertion
+	Provider  l2stateprovider.Provider
+}
+
+// A level zero edge of a rival, along with the request of the history it commits to.
+type rivalEdge struct {
+	rival *Rival
+	edge  protocol.SpecEdge
+	req   *l2stateprovider.HistoryCommitmentRequest
+}
+
+// Creates rival level zero block edges and bisects one of them, checking the edges created and the
+// errors actions revert with before they are allowed. The events and timers of the edges created are
+// then checked along with those of the other edges.
+func (s *suite) checkActions(ctx context.Context, firstRival, secondRival *Rival) error {
+	for _, r := range []*Rival{firstRival, secondRival} {
+		manager, err := r.Chain.SpecChallengeManager(ctx)
+		if err != nil {
+			return err
+		}
+		if manager.Address() != s.challengeManager {
+			return fmt.Errorf("rival chain uses challenge manager %s, not %s", manager.Address().Hex(), s.challengeManager.Hex())
+		}
+	}
+	first, err := s.addLevelZeroEdge(ctx, firstRival)
+	if err != nil {
+		s.fail(categoryActions, "rival level zero edges are created", err.Error())
+		return nil
+	}
+	bisectionRoot, proof, err := bisectionHistoryWithProof(ctx, first)
+	if err != nil {
+		return err
+	}
+	s.checkBisectionRevert(ctx, first, bisectionRoot, proof, "bisecting an unrivaled edge reverts with EdgeUnrivaled", func(err error) string {
+		var unrivaled *solimpl.EdgeUnrivaledError
+		if !errors.As(err, &unrivaled) {
+			return err.Error()
+		}
+		if unrivaled.EdgeId != first.edge.Id() {
+			return fmt.Sprintf("reverted for edge %#x, not %#x", unrivaled.EdgeId.Hash, first.edge.Id().Hash)
+		}
+		return ""
+	})
+
+	second, err := s.addLevelZeroEdge(ctx, secondRival)
+	if err != nil {
+		s.fail(categoryActions, "rival level zero edges are created", err.Error())
+		return nil
+	}
+	if first.edge.MutualId() != second.edge.MutualId() {
+		s.fail(categoryActions, "rival level zero edges are created", fmt.Sprintf(
+			"edges %#x and %#x have different mutual ids, so the assertions are not rivals", first.edge.Id().Hash, second.edge.Id().Hash,
+		))
+		return nil
+	}
+	s.pass(categoryActions, "rival level zero edges are created", fmt.Sprintf("%#x and %#x", first.edge.Id().Hash, second.edge.Id().Hash))
+
+	_, expectedLower, expectedUpper, err := protocol.ComputeBisectionChildIds(first.edge, bisectionRoot)
+	if err != nil {
+		return err
+	}
+	lower, upper, err := first.edge.Bisect(ctx, bisectionRoot, proof)
+	switch {
+	case err != nil:
+		s.fail(categoryActions, "edges bisect into the children we compute", err.Error())
+		return nil
+	case lower.Id() != expectedLower || upper.Id() != expectedUpper:
+		s.fail(categoryActions, "edges bisect into the children we compute", fmt.Sprintf(
+			"children %#x and %#x, expected %#x and %#x", lower.Id().Hash, upper.Id().Hash, expectedLower.Hash, expectedUpper.Hash,
+		))
+	default:
+		s.pass(categoryActions, "edges bisect into the children we compute", "")
+	}
+	s.checkBisectedEvent(ctx, first.edge, lower, upper)
+	s.checkBisectionRevert(ctx, first, bisectionRoot, proof, "bisecting an edge again reverts with EdgeAlreadyExists", func(err error) string {
+		var exists *solimpl.EdgeAlreadyExistsError
+		if !errors.As(err, &exists) {
+			return err.Error()
+		}
+		return ""
+	})
+	return nil
+}
+
+// Creates the level zero block edge of a rival, committing to the history of the assertion it
+// claims as the challenge manager of the repository does.
+func (s *suite) addLevelZeroEdge(ctx context.Context, r *Rival) (*rivalEdge, error) {
+	creationInfo, err := r.Chain.ReadAssertionCreationInfo(ctx, r.Assertion.Id())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get assertion creation info")
+	}
+	prevCreationInfo, err := r.Chain.ReadAssertionCreationInfo(ctx, protocol.AssertionHash{Hash: creationInfo.ParentAssertionHash})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get parent assertion creation info")
+	}
+	manager, err := r.Chain.SpecChallengeManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	layerZeroHeights, err := manager.LayerZeroHeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := &l2stateprovider.HistoryCommitmentRequest{
+		WasmModuleRoot:              prevCreationInfo.WasmModuleRoot,
+		FromBatch:                   l2stateprovider.Batch(protocol.GoGlobalStateFromSolidity(creationInfo.BeforeState.GlobalState).Batch),
+		ToBatch:                     l2stateprovider.Batch(protocol.GoGlobalStateFromSolidity(creationInfo.AfterState.GlobalState).Batch),
+		UpperChallengeOriginHeights: []l2stateprovider.Height{},
+		FromHeight:                  0,
+		UpToHeight:                  option.Some(l2stateprovider.Height(0)),
+		ClaimId:                     creationInfo.AssertionHash,
+	}
+	startCommit, err := r.Provider.HistoryCommitment(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	endReq := *req
+	endReq.UpToHeight = option.Some(l2stateprovider.Height(layerZeroHeights.BlockChallengeHeight))
+	endCommit, err := r.Provider.HistoryCommitment(ctx, &endReq)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := r.Provider.PrefixProof(ctx, &endReq, 0)
+	if err != nil {
+		return nil, err
+	}
+	edge, err := manager.AddBlockChallengeLevelZeroEdge(ctx, r.Assertion, startCommit, endCommit, proof)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create level zero edge claiming assertion %#x", r.Assertion.Id().Hash)
+	}
+	return &rivalEdge{rival: r, edge: edge, req: &endReq}, nil
+}
+
+// The history root a level zero edge is bisected to, and the proof that it is a prefix of the
+// history of the edge.
+func bisectionHistoryWithProof(ctx context.Context, e *rivalEdge) (common.Hash, []byte, error) {
+	startHeight, _ := e.edge.StartCommitment()
+	endHeight, _ := e.edge.EndCommitment()
+	bisectionHeight, err := math.Bisect(uint64(startHeight), uint64(endHeight))
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	req := *e.req
+	req.UpToHeight = option.Some(l2stateprovider.Height(bisectionHeight))
+	commit, err := e.rival.Provider.HistoryCommitment(ctx, &req)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	proof, err := e.rival.Provider.PrefixProof(ctx, e.req, l2stateprovider.Height(bisectionHeight))
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return commit.Merkle, proof, nil
+}
+
+// Checks that bisecting an edge, called from the key of its rival, reverts as a check describes.
+func (s *suite) checkBisectionRevert(
+	ctx context.Context,
+	e *rivalEdge,
+	bisectionRoot common.Hash,
+	proof []byte,
+	name string,
+	mismatch func(error) string,
+) {
+	err := s.callForRevertFrom(ctx, e.rival.Chain.StakerAddress(), nil, "bisectEdge", e.edge.Id().Hash, bisectionRoot, proof)
+	if err == nil {
+		s.fail(categoryActions, name, "call did not revert")
+		return
+	}
+	if detail := mismatch(err); detail != "" {
+		s.fail(categoryActions, name, detail)
+		return
+	}
+	s.pass(categoryActions, name, "")
+}
+
+// Checks that the bisection of an edge emitted an event with the children it was bisected into.
+func (s *suite) checkBisectedEvent(ctx context.Context, edge, lower, upper protocol.SpecEdge) {
+	const name = "bisections emit edge bisected events"
+	block, err := upper.CreatedAtBlock()
+	if err != nil {
+		s.fail(categoryActions, name, err.Error())
+		return
+	}
+	filterer, err := challengeV2gen.NewEdgeChallengeManagerFilterer(s.challengeManager, s.backend)
+	if err != nil {
+		s.fail(categoryActions, name, err.Error())
+		return
+	}
+	it, err := filterer.FilterEdgeBisected(&bind.FilterOpts{Start: block, End: &block, Context: ctx}, [][32]byte{edge.Id().Hash}, nil, nil)
+	if err != nil {
+		s.fail(categoryActions, name, err.Error())
+		return
+	}
+	defer it.Close()
+	if !it.Next() {
+		if err = it.Error(); err == nil {
+			err = fmt.Errorf("no event for edge %#x in block %d", edge.Id().Hash, block)
+		}
+		s.fail(categoryActions, name, err.Error())
+		return
+	}
+	ev := it.Event
+	if ev.LowerChildId != lower.Id().Hash || ev.UpperChildId != upper.Id().Hash {
+		s.fail(categoryActions, name, fmt.Sprintf(
+			"event has children %#x and %#x, not %#x and %#x", ev.LowerChildId, ev.UpperChildId, lower.Id().Hash, upper.Id().Hash,
+		))
+		return
+	}
+	s.pass(categoryActions, name, "")
+}
@@ -0,0 +1,379 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	categoryAbi        = "abi"
+	categoryParameters = "parameters"
+	categoryErrors     = "errors"
+	categoryEvents     = "events"
+	categoryTimers     = "timers"
+
+	// The number of failures of a check over many edges listed in its detail.
+	maxListedFailures = 5
+)
+
+// An edge id no deployment has, used to check the reverts of actions on edges which do not exist.
+var probeEdgeId = crypto.Keccak256Hash([]byte("conformance probe edge"))
+
+// Checks that the deployed code dispatches every method and emits every event of our bindings.
+func (s *suite) checkAbi(ctx context.Context) {
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if err != nil {
+		s.fail(categoryAbi, "bindings methods are dispatched", err.Error())
+		return
+	}
+	drift, err := solimpl.DetectAbiDrift(ctx, s.backend, s.challengeManager, contractAbi)
+	if err != nil {
+		s.fail(categoryAbi, "bindings methods are dispatched", err.Error())
+		s.fail(categoryAbi, "bindings events are emitted", err.Error())
+		return
+	}
+	s.report.Implementation = drift.Implementation
+	if len(drift.MissingMethods) > 0 {
+		s.fail(categoryAbi, "bindings methods are dispatched", "missing "+strings.Join(drift.MissingMethods, ", "))
+	} else {
+		s.pass(categoryAbi, "bindings methods are dispatched", fmt.Sprintf("%d methods", len(contractAbi.Methods)))
+	}
+	if len(drift.MissingEvents) > 0 {
+		s.fail(categoryAbi, "bindings events are emitted", "missing "+strings.Join(drift.MissingEvents, ", "))
+	} else {
+		s.pass(categoryAbi, "bindings events are emitted", fmt.Sprintf("%d events", len(contractAbi.Events)))
+	}
+}
+
+// Checks the parameters of the challenge manager are ones the challenge logic can work with.
+func (s *suite) checkParameters() {
+	period, err := s.caller.ChallengePeriodBlocks(s.callOpts)
+	switch {
+	case err != nil:
+		s.fail(categoryParameters, "challenge period is set", err.Error())
+	case period == 0:
+		s.fail(categoryParameters, "challenge period is set", "challenge period is 0 blocks")
+	default:
+		s.pass(categoryParameters, "challenge period is set", fmt.Sprintf("%d blocks", period))
+	}
+
+	// History commitments and bisections assume layer zero edges span a power of two leaves.
+	heights := []struct {
+		name string
+		get  func(*bind.CallOpts) (*big.Int, error)
+	}{
+		{"block", s.caller.LAYERZEROBLOCKEDGEHEIGHT},
+		{"big step", s.caller.LAYERZEROBIGSTEPEDGEHEIGHT},
+		{"small step", s.caller.LAYERZEROSMALLSTEPEDGEHEIGHT},
+	}
+	failures := make([]string, 0)
+	details := make([]string, 0, len(heights))
+	for _, h := range heights {
+		raw, err := h.get(s.callOpts)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", h.name, err))
+			continue
+		}
+		height, err := protocol.HeightFromBig(raw)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", h.name, err))
+			continue
+		}
+		if height == 0 || height&(height-1) != 0 {
+			failures = append(failures, fmt.Sprintf("%s height %d is not a power of two", h.name, height))
+			continue
+		}
+		details = append(details, fmt.Sprintf("%s %d", h.name, height))
+	}
+	if len(failures) > 0 {
+		s.fail(categoryParameters, "layer zero heights are powers of two", strings.Join(failures, "; "))
+	} else {
+		s.pass(categoryParameters, "layer zero heights are powers of two", strings.Join(details, ", "))
+	}
+
+	// Edges are staked at each of the block, big step and small step levels.
+	numBigStepLevels, err := s.caller.NUMBIGSTEPLEVEL(s.callOpts)
+	if err != nil {
+		s.fail(categoryParameters, "stake amounts are set for every level", err.Error())
+		return
+	}
+	numLevels := int64(numBigStepLevels) + 2
+	for level := int64(0); level < numLevels; level++ {
+		if _, err := s.caller.StakeAmounts(s.callOpts, big.NewInt(level)); err != nil {
+			s.fail(categoryParameters, "stake amounts are set for every level", fmt.Sprintf("level %d: %v", level, err))
+			return
+		}
+	}
+	s.pass(categoryParameters, "stake amounts are set for every level", fmt.Sprintf("%d levels", numLevels))
+}
+
+// Checks that actions on an edge which does not exist revert with the custom errors our challenge
+// logic decodes and branches on, and that the node returns the revert data to decode them from.
+func (s *suite) checkErrors(ctx context.Context) {
+	probes := []struct {
+		method string
+		args   []any
+	}{
+		{"getEdge", []any{probeEdgeId}},
+		{"hasRival", []any{probeEdgeId}},
+		{"timeUnrivaled", []any{probeEdgeId}},
+		{"bisectEdge", []any{probeEdgeId, common.Hash{}, []byte{}}},
+		{"confirmEdgeByTime", []any{probeEdgeId, challengeV2gen.AssertionStateData{}}},
+		{"updateTimerCacheByChildren", []any{probeEdgeId, new(big.Int).SetUint64(math.MaxUint64)}},
+		{"refundStake", []any{probeEdgeId}},
+	}
+	for _, p := range probes {
+		name := p.method + " reverts with EdgeNotExists"
+		err := s.callForRevert(ctx, p.method, p.args...)
+		var notExists *solimpl.EdgeNotExistsError
+		switch {
+		case err == nil:
+			s.fail(categoryErrors, name, "call did not revert")
+		case !errors.As(err, &notExists):
+			s.fail(categoryErrors, name, err.Error())
+		case notExists.EdgeId.Hash != probeEdgeId:
+			s.fail(categoryErrors, name, fmt.Sprintf("reverted for edge %#x, not %#x", notExists.EdgeId.Hash, probeEdgeId))
+		default:
+			s.pass(categoryErrors, name, "")
+		}
+	}
+
+	name := "multiUpdateTimeCacheByChildren reverts with EmptyArray"
+	err := s.callForRevert(ctx, "multiUpdateTimeCacheByChildren", [][32]byte{}, big.NewInt(0))
+	switch {
+	case err == nil:
+		s.fail(categoryErrors, name, "call did not revert")
+	case !solimpl.IsChallengeManagerError(err, "EmptyArray"):
+		s.fail(categoryErrors, name, err.Error())
+	default:
+		s.pass(categoryErrors, name, "")
+	}
+}
+
+// Calls a method of the challenge manager, returning its revert decoded as our challenge logic
+// decodes it, or nil if the call succeeded.
+func (s *suite) callForRevert(ctx context.Context, method string, args ...any) error {
+	return s.callForRevertFrom(ctx, common.Address{}, s.callOpts.BlockNumber, method, args...)
+}
+
+// Calls a method of the challenge manager from an account at a block, or the latest one if nil.
+func (s *suite) callForRevertFrom(ctx context.Context, from common.Address, block *big.Int, method string, args ...any) error {
+	contractAbi, err := challengeV2gen.EdgeChallengeManagerMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	data, err := contractAbi.Pack(method, args...)
+	if err != nil {
+		return errors.Wrapf(err, "could not pack call to %s", method)
+	}
+	_, err = s.backend.CallContract(ctx, ethereum.CallMsg{
+		From: from,
+		To:   &s.challengeManager,
+		Data: data,
+	}, block)
+	return solimpl.DecodeChallengeManagerError(err)
+}
+
+// The failures of a check over many edges.
+type edgeCheck struct {
+	category string
+	name     string
+	checked  int
+	failures []string
+}
+
+func (c *edgeCheck) check(failure string) {
+	c.checked++
+	if failure != "" {
+		c.failures = append(c.failures, failure)
+	}
+}
+
+func (s *suite) recordEdgeCheck(c *edgeCheck) {
+	if c.checked == 0 {
+		s.skip(c.category, c.name, "no edges to check")
+		return
+	}
+	if len(c.failures) == 0 {
+		s.pass(c.category, c.name, fmt.Sprintf("%d edges", c.checked))
+		return
+	}
+	listed := c.failures[:min(len(c.failures), maxListedFailures)]
+	detail := fmt.Sprintf("%d of %d edges: %s", len(c.failures), c.checked, strings.Join(listed, "; "))
+	if len(c.failures) > len(listed) {
+		detail += fmt.Sprintf("; and %d more", len(c.failures)-len(listed))
+	}
+	s.fail(c.category, c.name, detail)
+}
+
+// Checks the edges added since a block against the edges the challenge manager stores, the ids our
+// challenge logic computes, and the unrivaled timers our challenge tree computes.
+func (s *suite) checkEdges(ctx context.Context, fromBlock uint64, maxEdges int) error {
+	added := make([]*challengeV2gen.EdgeChallengeManagerEdgeAdded, 0)
+	err := s.scanner.Backfill(ctx, fromBlock, s.blockNum, func(_ context.Context, ev *challengeV2gen.EdgeChallengeManagerEdgeAdded) error {
+		added = append(added, ev)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not read edge added events")
+	}
+	// The creation blocks of the edges with each mutual id, in the order they were added.
+	rivals := make(map[common.Hash][]uint64)
+	for _, ev := range added {
+		rivals[ev.MutualId] = append(rivals[ev.MutualId], ev.Raw.BlockNumber)
+	}
+
+	stored := &edgeCheck{category: categoryEvents, name: "edge added events match stored edges"}
+	ids := &edgeCheck{category: categoryEvents, name: "edge ids match our computation"}
+	hasRival := &edgeCheck{category: categoryEvents, name: "edge added events flag rivals"}
+	timers := &edgeCheck{category: categoryTimers, name: "time unrivaled matches our challenge tree"}
+	seen := make(map[common.Hash]int)
+	for i, ev := range added {
+		position := seen[ev.MutualId]
+		seen[ev.MutualId]++
+		if i >= maxEdges {
+			continue
+		}
+		edge, err := s.caller.GetEdge(s.callOpts, ev.EdgeId)
+		if err != nil {
+			stored.check(fmt.Sprintf("%#x: %v", ev.EdgeId, solimpl.DecodeChallengeManagerError(err)))
+			continue
+		}
+		s.report.EdgesChecked++
+		if startHeight, endHeight, err := edgeHeights(&edge); err != nil {
+			stored.check(fmt.Sprintf("%#x: %v", ev.EdgeId, err))
+			ids.check(fmt.Sprintf("%#x: %v", ev.EdgeId, err))
+		} else {
+			stored.check(edgeAddedMismatch(ev, &edge, startHeight, endHeight))
+			ids.check(edgeIdMismatch(ev, &edge, startHeight, endHeight))
+		}
+		if ev.HasRival != (position > 0) {
+			hasRival.check(fmt.Sprintf("%#x: flagged %t, but is edge %d of its mutual id", ev.EdgeId, ev.HasRival, position+1))
+		} else {
+			hasRival.check("")
+		}
+
+		onchain, err := s.caller.TimeUnrivaled(s.callOpts, ev.EdgeId)
+		if err != nil {
+			timers.check(fmt.Sprintf("%#x: %v", ev.EdgeId, solimpl.DecodeChallengeManagerError(err)))
+			continue
+		}
+		group := rivals[ev.MutualId]
+		others := make([]uint64, 0, len(group)-1)
+		others = append(others, group[:position]...)
+		others = append(others, group[position+1:]...)
+		expected := expectedTimeUnrivaled(edge.CreatedAtBlock, others, s.blockNum)
+		if !onchain.IsUint64() || onchain.Uint64() != expected {
+			timers.check(fmt.Sprintf("%#x: %s blocks, expected %d", ev.EdgeId, onchain, expected))
+		} else {
+			timers.check("")
+		}
+	}
+	for _, c := range []*edgeCheck{stored, ids, hasRival, timers} {
+		s.recordEdgeCheck(c)
+	}
+	return nil
+}
+
+func edgeHeights(edge *challengeV2gen.ChallengeEdge) (protocol.Height, protocol.Height, error) {
+	startHeight, err := protocol.HeightFromBig(edge.StartHeight)
+	if err != nil {
+		return 0, 0, err
+	}
+	endHeight, err := protocol.HeightFromBig(edge.EndHeight)
+	if err != nil {
+		return 0, 0, err
+	}
+	return startHeight, endHeight, nil
+}
+
+// Describes how an edge added event differs from the edge stored, or is empty if it matches.
+func edgeAddedMismatch(
+	ev *challengeV2gen.EdgeChallengeManagerEdgeAdded,
+	edge *challengeV2gen.ChallengeEdge,
+	startHeight,
+	endHeight protocol.Height,
+) string {
+	mismatches := make([]string, 0)
+	if ev.OriginId != edge.OriginId {
+		mismatches = append(mismatches, "origin id")
+	}
+	if ev.ClaimId != edge.ClaimId {
+		mismatches = append(mismatches, "claim id")
+	}
+	if ev.Level != edge.Level {
+		mismatches = append(mismatches, "level")
+	}
+	if length, err := protocol.LengthFromBig(ev.Length); err != nil || endHeight < startHeight || length != protocol.Length(endHeight-startHeight) {
+		mismatches = append(mismatches, "length")
+	}
+	if ev.IsLayerZero != (edge.ClaimId != [32]byte{}) {
+		mismatches = append(mismatches, "is layer zero")
+	}
+	if ev.Raw.BlockNumber != edge.CreatedAtBlock {
+		mismatches = append(mismatches, "created at block")
+	}
+	if len(mismatches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%#x: %s", ev.EdgeId, strings.Join(mismatches, ", "))
+}
+
+// Describes how the ids of an edge added event differ from the ids we compute for the edge stored,
+// or is empty if they match.
+func edgeIdMismatch(
+	ev *challengeV2gen.EdgeChallengeManagerEdgeAdded,
+	edge *challengeV2gen.ChallengeEdge,
+	startHeight,
+	endHeight protocol.Height,
+) string {
+	level := protocol.ChallengeLevel(edge.Level)
+	originId := protocol.OriginId(edge.OriginId)
+	mutualId := protocol.ComputeMutualId(level, originId, startHeight, edge.StartHistoryRoot, endHeight)
+	edgeId := protocol.ComputeEdgeId(level, originId, startHeight, edge.StartHistoryRoot, endHeight, edge.EndHistoryRoot)
+	mismatches := make([]string, 0)
+	if edgeId.Hash != ev.EdgeId {
+		mismatches = append(mismatches, fmt.Sprintf("computed edge id %#x", edgeId.Hash))
+	}
+	if common.Hash(mutualId) != ev.MutualId {
+		mismatches = append(mismatches, fmt.Sprintf("computed mutual id %#x", common.Hash(mutualId)))
+	}
+	if len(mismatches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%#x: %s", ev.EdgeId, strings.Join(mismatches, ", "))
+}
+
+// The number of blocks an edge was unrivaled for as of a block, as the challenge tree's LocalTimer
+// computes it: until the earliest creation of a rival if it has one, or else until the block.
+func expectedTimeUnrivaled(createdAt uint64, rivalsCreatedAt []uint64, blockNum uint64) uint64 {
+	if blockNum <= createdAt {
+		return 0
+	}
+	if len(rivalsCreatedAt) == 0 {
+		return blockNum - createdAt
+	}
+	earliest := rivalsCreatedAt[0]
+	for _, b := range rivalsCreatedAt[1:] {
+		earliest = min(earliest, b)
+	}
+	if createdAt >= earliest {
+		return 0
+	}
+	return earliest - createdAt
+}
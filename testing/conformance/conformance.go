@@ -0,0 +1,250 @@
+// Package conformance checks that a deployed edge challenge manager behaves as the challenge logic
+// of this repository assumes, before operators go live against it. Pointed at any deployment, such
+// as a devnet or a fork of a live chain, it compares the deployed code against the ABI of our
+// bindings, checks the custom errors calls revert with, the shape of the edges added events, and
+// the unrivaled timers of the edges, and produces a compatibility report.
+//
+// By default, the suite only reads from the chain with calls and log queries, so it never sends
+// transactions and is safe to run against a live deployment. Deployments without edges can only be
+// checked in part that way, so against a devnet or a fork, the suite can be given rival assertions
+// to create and bisect edges on, checking the actions and errors of the challenge manager as well.
+//
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"text/tabwriter"
+
+	solimpl "github.com/OffchainLabs/bold/chain-abstraction/sol-implementation"
+	watcher "github.com/OffchainLabs/bold/challenge-manager/chain-watcher"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxEdges is the default number of edges whose events and timers are checked.
+const DefaultMaxEdges = 1000
+
+// Backend is what the suite needs from a node. Resolving proxies and finding the deployment block
+// of the challenge manager read state, and finding the deployment block reads historical state, so
+// a pruned node needs the block to scan from to be set.
+type Backend interface {
+	bind.ContractCaller
+	bind.ContractFilterer
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Status of a check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	// StatusSkip is the status of a check which had nothing to check, such as the checks of edges
+	// against a deployment without any. A report with skipped checks is incomplete.
+	StatusSkip Status = "skip"
+)
+
+// Result of a check of the suite.
+type Result struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Status   Status `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// Report of a run of the suite against a challenge manager, as of a block.
+type Report struct {
+	ChallengeManager common.Address `json:"challengeManager"`
+	// The address whose code was inspected, which differs from the challenge manager if it is
+	// behind an EIP-1967 proxy.
+	Implementation common.Address `json:"implementation"`
+	BlockNumber    uint64         `json:"blockNumber"`
+	FromBlock      uint64         `json:"fromBlock"`
+	EdgesChecked   int            `json:"edgesChecked"`
+	Results        []*Result      `json:"results"`
+}
+
+// Passed checks if every check of the report passed. A report with skipped checks did not pass,
+// as the deployment was not checked in full.
+func (r *Report) Passed() bool {
+	return len(r.Failures()) == 0 && len(r.Skipped()) == 0
+}
+
+// Failures are the results of the checks which failed.
+func (r *Report) Failures() []*Result {
+	return r.withStatus(StatusFail)
+}
+
+// Skipped are the results of the checks which had nothing to check.
+func (r *Report) Skipped() []*Result {
+	return r.withStatus(StatusSkip)
+}
+
+func (r *Report) withStatus(status Status) []*Result {
+	results := make([]*Result, 0)
+	for _, result := range r.Results {
+		if result.Status == status {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Print the report as a table.
+func (r *Report) Print(out io.Writer) error {
+	fmt.Fprintf(out, "Challenge manager %s", r.ChallengeManager.Hex())
+	if r.Implementation != (common.Address{}) && r.Implementation != r.ChallengeManager {
+		fmt.Fprintf(out, " (implementation %s)", r.Implementation.Hex())
+	}
+	fmt.Fprintf(out, " at block %d, %d edges checked from block %d\n\n", r.BlockNumber, r.EdgesChecked, r.FromBlock)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tCHECK\tSTATUS\tDETAIL")
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Category, result.Name, result.Status, result.Detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	switch {
+	case len(r.Failures()) > 0:
+		fmt.Fprintf(out, "\nThe deployment is incompatible: %d checks failed\n", len(r.Failures()))
+	case len(r.Skipped()) > 0:
+		fmt.Fprintf(out, "\nThe report is incomplete: %d checks were skipped, create edges to check them\n", len(r.Skipped()))
+	default:
+		fmt.Fprintln(out, "\nThe deployment is compatible")
+	}
+	return nil
+}
+
+type config struct {
+	fromBlock    uint64
+	hasFromBlock bool
+	maxEdges     int
+	rivals       []*Rival
+}
+
+// Opt configures a run of the suite.
+type Opt func(*config)
+
+// WithFromBlock sets the block from which to scan for edges. Defaults to the deployment block of
+// the challenge manager. Timers and rivals are computed from the edges scanned, so the block must
+// not be after the first edge of any challenge checked.
+func WithFromBlock(block uint64) Opt {
+	return func(c *config) {
+		c.fromBlock = block
+		c.hasFromBlock = true
+	}
+}
+
+// WithMaxEdges bounds the number of edges whose events and timers are checked. Defaults to
+// DefaultMaxEdges.
+func WithMaxEdges(n int) Opt {
+	return func(c *config) {
+		c.maxEdges = n
+	}
+}
+
+// WithRivals makes the suite create level zero edges claiming two rival assertions, and bisect the
+// first, before checking the edges of the challenge manager. It checks the edges created and the
+// errors actions revert with before they are allowed. It sends transactions and stakes on the edges
+// with the keys of the chains of the rivals, which must differ, so it is only meant for a devnet or
+// a fork of a live chain.
+func WithRivals(first, second Rival) Opt {
+	return func(c *config) {
+		c.rivals = []*Rival{&first, &second}
+	}
+}
+
+// Run the suite against a challenge manager as of the latest block. Errors are only returned if
+// the suite could not run at all, while the checks the deployment fails are in the report.
+func Run(ctx context.Context, backend Backend, challengeManager common.Address, opts ...Opt) (*Report, error) {
+	cfg := &config{maxEdges: DefaultMaxEdges}
+	for _, o := range opts {
+		o(cfg)
+	}
+	caller, err := challengeV2gen.NewEdgeChallengeManagerCaller(challengeManager, backend)
+	if err != nil {
+		return nil, err
+	}
+	s := &suite{
+		backend:          backend,
+		challengeManager: challengeManager,
+		caller:           caller,
+		report: &Report{
+			ChallengeManager: challengeManager,
+			Results:          make([]*Result, 0),
+		},
+	}
+	// Edges are created first, so that the checks of the edges which follow cover them as well.
+	if cfg.rivals != nil {
+		if err = s.checkActions(ctx, cfg.rivals[0], cfg.rivals[1]); err != nil {
+			return nil, errors.Wrap(err, "could not act on the challenge manager")
+		}
+	}
+	header, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get latest header")
+	}
+	blockNum := header.Number.Uint64()
+	if !cfg.hasFromBlock {
+		cfg.fromBlock, err = watcher.FindDeploymentBlock(ctx, backend, challengeManager, blockNum)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not find the deployment block of the challenge manager, set the block to scan from")
+		}
+	}
+	s.scanner, err = solimpl.NewEdgeAddedScanner(challengeManager, backend, cfg.fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	s.blockNum = blockNum
+	s.callOpts = &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNum)}
+	s.report.BlockNumber = blockNum
+	s.report.FromBlock = cfg.fromBlock
+	s.checkAbi(ctx)
+	s.checkParameters()
+	s.checkErrors(ctx)
+	if err := s.checkEdges(ctx, cfg.fromBlock, cfg.maxEdges); err != nil {
+		return nil, err
+	}
+	return s.report, nil
+}
+
+type suite struct {
+	backend          Backend
+	challengeManager common.Address
+	caller           *challengeV2gen.EdgeChallengeManagerCaller
+	scanner          *solimpl.EdgeAddedScanner
+	blockNum         uint64
+	callOpts         *bind.CallOpts
+	report           *Report
+}
+
+func (s *suite) pass(category, name, detail string) {
+	s.record(category, name, StatusPass, detail)
+}
+
+func (s *suite) fail(category, name, detail string) {
+	s.record(category, name, StatusFail, detail)
+}
+
+func (s *suite) skip(category, name, detail string) {
+	s.record(category, name, StatusSkip, detail)
+}
+
+func (s *suite) record(category, name string, status Status, detail string) {
+	s.report.Results = append(s.report.Results, &Result{
+		Name:     name,
+		Category: category,
+		Status:   status,
+		Detail:   detail,
+	})
+}
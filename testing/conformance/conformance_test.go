@@ -0,0 +1,154 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/containers/option"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
+	challenge_testing "github.com/OffchainLabs/bold/testing"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	createdData, err := setup.CreateTwoValidatorFork(ctx, &setup.CreateForkConfig{}, setup.WithMockOneStepProver())
+	require.NoError(t, err)
+	challengeManager, err := createdData.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+	addr := challengeManager.Address()
+
+	// Without edges, only the checks of edges are skipped, which leaves the report incomplete.
+	report, err := Run(ctx, createdData.Backend, addr)
+	require.NoError(t, err)
+	require.False(t, report.Passed())
+	require.Empty(t, report.Failures())
+	require.Len(t, report.Skipped(), 4)
+	require.Equal(t, 0, report.EdgesChecked)
+	for _, result := range report.Results {
+		if result.Category == categoryEvents || result.Category == categoryTimers {
+			require.Equal(t, StatusSkip, result.Status)
+		} else {
+			require.Equal(t, StatusPass, result.Status, result.Name)
+		}
+	}
+	var out bytes.Buffer
+	require.NoError(t, report.Print(&out))
+	require.Contains(t, out.String(), "The report is incomplete: 4 checks were skipped")
+
+	historyRequest := func(upTo uint64) *l2stateprovider.HistoryCommitmentRequest {
+		return &l2stateprovider.HistoryCommitmentRequest{
+			WasmModuleRoot:              common.Hash{},
+			FromBatch:                   0,
+			ToBatch:                     1,
+			UpperChallengeOriginHeights: []l2stateprovider.Height{},
+			FromHeight:                  0,
+			UpToHeight:                  option.Some(l2stateprovider.Height(upTo)),
+		}
+	}
+	addLevelZeroEdge := func(stateManager l2stateprovider.Provider, leaf protocol.Assertion) {
+		startCommit, err := stateManager.HistoryCommitment(ctx, historyRequest(0))
+		require.NoError(t, err)
+		req := historyRequest(challenge_testing.LevelZeroBlockEdgeHeight)
+		endCommit, err := stateManager.HistoryCommitment(ctx, req)
+		require.NoError(t, err)
+		prefixProof, err := stateManager.PrefixProof(ctx, req, 0)
+		require.NoError(t, err)
+		_, err = challengeManager.AddBlockChallengeLevelZeroEdge(ctx, leaf, startCommit, endCommit, prefixProof)
+		require.NoError(t, err)
+	}
+	addLevelZeroEdge(createdData.HonestStateManager, createdData.Leaf1)
+	for i := 0; i < 3; i++ {
+		createdData.Backend.Commit()
+	}
+	addLevelZeroEdge(createdData.EvilStateManager, createdData.Leaf2)
+	for i := 0; i < 5; i++ {
+		createdData.Backend.Commit()
+	}
+
+	report, err = Run(ctx, createdData.Backend, addr)
+	require.NoError(t, err)
+	require.True(t, report.Passed(), "%+v", report.Failures())
+	require.Equal(t, 2, report.EdgesChecked)
+	for _, result := range report.Results {
+		require.Equal(t, StatusPass, result.Status, result.Name)
+	}
+
+	// Scanning past the first edge misses that the second was created as its rival.
+	report, err = Run(ctx, createdData.Backend, addr, WithFromBlock(report.BlockNumber-5))
+	require.NoError(t, err)
+	require.False(t, report.Passed())
+	require.Equal(t, 1, report.EdgesChecked)
+
+	report, err = Run(ctx, createdData.Backend, addr, WithMaxEdges(1))
+	require.NoError(t, err)
+	require.True(t, report.Passed(), "%+v", report.Failures())
+	require.Equal(t, 1, report.EdgesChecked)
+
+	out.Reset()
+	require.NoError(t, report.Print(&out))
+	require.Contains(t, out.String(), "The deployment is compatible")
+}
+
+func TestRun_WithRivals(t *testing.T) {
+	ctx := context.Background()
+	createdData, err := setup.CreateTwoValidatorFork(ctx, &setup.CreateForkConfig{}, setup.WithMockOneStepProver())
+	require.NoError(t, err)
+	challengeManager, err := createdData.Chains[0].SpecChallengeManager(ctx)
+	require.NoError(t, err)
+
+	report, err := Run(
+		ctx,
+		createdData.Backend,
+		challengeManager.Address(),
+		WithRivals(
+			Rival{Chain: createdData.Chains[0], Assertion: createdData.Leaf1, Provider: createdData.HonestStateManager},
+			Rival{Chain: createdData.Chains[1], Assertion: createdData.Leaf2, Provider: createdData.EvilStateManager},
+		),
+	)
+	require.NoError(t, err)
+	require.True(t, report.Passed(), "%+v %+v", report.Failures(), report.Skipped())
+	// The rival level zero edges and the children of the bisection.
+	require.Equal(t, 4, report.EdgesChecked)
+	actions := make([]string, 0)
+	for _, result := range report.Results {
+		if result.Category == categoryActions {
+			actions = append(actions, result.Name)
+		}
+	}
+	require.Equal(t, []string{
+		"bisecting an unrivaled edge reverts with EdgeUnrivaled",
+		"rival level zero edges are created",
+		"edges bisect into the children we compute",
+		"bisections emit edge bisected events",
+		"bisecting an edge again reverts with EdgeAlreadyExists",
+	}, actions)
+}
+
+func TestExpectedTimeUnrivaled(t *testing.T) {
+	tests := []struct {
+		name      string
+		createdAt uint64
+		rivals    []uint64
+		blockNum  uint64
+		want      uint64
+	}{
+		{"unrivaled", 10, nil, 25, 15},
+		{"not yet created", 10, nil, 10, 0},
+		{"rivaled later", 10, []uint64{14, 12}, 25, 2},
+		{"rivaled in the same block", 10, []uint64{10}, 25, 0},
+		{"created after its rival", 12, []uint64{10, 11}, 25, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, expectedTimeUnrivaled(tt.createdAt, tt.rivals, tt.blockNum))
+		})
+	}
+}
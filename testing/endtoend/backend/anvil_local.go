@@ -172,7 +172,7 @@ func (a *AnvilLocal) DeployRollup(ctx context.Context, opts ...challenge_testing
 	anyTrustFastConfirmer := common.Address{}
 	genesisExecutionState := rollupgen.AssertionState{
 		GlobalState:   rollupgen.GlobalState{},
-		MachineStatus: 1,
+		MachineStatus: protocol.MachineStatusFinished.Uint8(),
 	}
 	genesisInboxCount := big.NewInt(0)
 
@@ -0,0 +1,419 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package backend
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	_                         Backend               = &ChaosBackend{}
+	_                         protocol.ChainBackend = &chaosClient{}
+	_                         ethereum.Subscription = &chaosSubscription{}
+	ErrChaosRPCFailure                              = errors.New("chaos: injected rpc failure")
+	ErrChaosSubscriptionDrop                        = errors.New("chaos: subscription dropped")
+	errReorgNotSupportedByEnv                       = errors.New("chaos: backend does not support reorgs")
+)
+
+// Reorger defines a backend that can reorg its chain by a specified number of blocks.
+type Reorger interface {
+	Reorg(depth uint64) error
+}
+
+// ChaosStep is a single, scheduled piece of misbehavior applied to a chaos backend.
+// Steps are applied in order, each one waiting for its After duration to elapse
+// since the previous step was applied.
+type ChaosStep struct {
+	After       time.Duration
+	Description string
+	Apply       func(c *ChaosBackend) error
+}
+
+// FailRPCs makes every RPC call fail with the given probability in the range [0, 1].
+func FailRPCs(after time.Duration, rate float64) ChaosStep {
+	return ChaosStep{
+		After:       after,
+		Description: "fail rpcs",
+		Apply: func(c *ChaosBackend) error {
+			c.SetRPCFailureRate(rate)
+			return nil
+		},
+	}
+}
+
+// DelayReceipts hides transaction receipts until they have been requested for at least the given delay.
+func DelayReceipts(after time.Duration, delay time.Duration) ChaosStep {
+	return ChaosStep{
+		After:       after,
+		Description: "delay receipts",
+		Apply: func(c *ChaosBackend) error {
+			c.SetReceiptDelay(delay)
+			return nil
+		},
+	}
+}
+
+// SkewClock shifts the timestamp of every header returned by the backend by the given amount.
+func SkewClock(after time.Duration, skew time.Duration) ChaosStep {
+	return ChaosStep{
+		After:       after,
+		Description: "skew clock",
+		Apply: func(c *ChaosBackend) error {
+			c.SetClockSkew(skew)
+			return nil
+		},
+	}
+}
+
+// DropSubscriptions terminates all active log and header subscriptions with an error.
+func DropSubscriptions(after time.Duration) ChaosStep {
+	return ChaosStep{
+		After:       after,
+		Description: "drop subscriptions",
+		Apply: func(c *ChaosBackend) error {
+			c.DropSubscriptions()
+			return nil
+		},
+	}
+}
+
+// ReorgChain reorgs the chain of the underlying backend by the given depth.
+func ReorgChain(after time.Duration, depth uint64) ChaosStep {
+	return ChaosStep{
+		After:       after,
+		Description: "reorg chain",
+		Apply: func(c *ChaosBackend) error {
+			return c.Reorg(depth)
+		},
+	}
+}
+
+// ChaosBackend wraps a backend and injects infrastructure misbehavior into the client
+// it hands out, such as random RPC failures, delayed receipts, dropped subscriptions,
+// clock skew, and chain reorgs. All toggles can be changed at runtime, either directly
+// or via a script of chaos steps.
+type ChaosBackend struct {
+	Backend
+	client *chaosClient
+}
+
+// NewChaos wraps a backend with chaos toggles, all of which are disabled by default.
+func NewChaos(inner Backend) *ChaosBackend {
+	return &ChaosBackend{
+		Backend: inner,
+		client: &chaosClient{
+			ChainBackend:    inner.Client(),
+			receiptsFirstAt: make(map[common.Hash]time.Time),
+			subscriptions:   make(map[*chaosSubscription]struct{}),
+		},
+	}
+}
+
+// Client returns the misbehaving client of the chaos backend.
+func (c *ChaosBackend) Client() protocol.ChainBackend {
+	return c.client
+}
+
+// SetRPCFailureRate sets the probability in the range [0, 1] of any RPC call failing.
+func (c *ChaosBackend) SetRPCFailureRate(rate float64) {
+	c.client.lock.Lock()
+	defer c.client.lock.Unlock()
+	c.client.failureRate = rate
+}
+
+// SetReceiptDelay sets how long receipts are hidden after they are first requested.
+func (c *ChaosBackend) SetReceiptDelay(delay time.Duration) {
+	c.client.lock.Lock()
+	defer c.client.lock.Unlock()
+	c.client.receiptDelay = delay
+}
+
+// SetClockSkew sets the amount by which header timestamps are shifted.
+func (c *ChaosBackend) SetClockSkew(skew time.Duration) {
+	c.client.lock.Lock()
+	defer c.client.lock.Unlock()
+	c.client.clockSkew = skew
+}
+
+// DropSubscriptions terminates all currently active subscriptions with an error.
+func (c *ChaosBackend) DropSubscriptions() {
+	c.client.lock.Lock()
+	subs := make([]*chaosSubscription, 0, len(c.client.subscriptions))
+	for sub := range c.client.subscriptions {
+		subs = append(subs, sub)
+	}
+	c.client.subscriptions = make(map[*chaosSubscription]struct{})
+	c.client.lock.Unlock()
+	for _, sub := range subs {
+		sub.fail(ErrChaosSubscriptionDrop)
+	}
+}
+
+// Reorg reorgs the chain of the underlying backend, if it supports it.
+func (c *ChaosBackend) Reorg(depth uint64) error {
+	reorger, ok := c.Backend.(Reorger)
+	if !ok {
+		return errReorgNotSupportedByEnv
+	}
+	return reorger.Reorg(depth)
+}
+
+// RunScript applies the given chaos steps in order until all of them are applied
+// or the context is canceled.
+func (c *ChaosBackend) RunScript(ctx context.Context, steps []ChaosStep) error {
+	for _, step := range steps {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(step.After):
+		}
+		log.Info("Applying chaos step", "step", step.Description)
+		if err := step.Apply(c); err != nil {
+			return errors.Wrapf(err, "could not apply chaos step %s", step.Description)
+		}
+	}
+	return nil
+}
+
+type chaosClient struct {
+	protocol.ChainBackend
+	lock            sync.RWMutex
+	failureRate     float64
+	receiptDelay    time.Duration
+	clockSkew       time.Duration
+	receiptsFirstAt map[common.Hash]time.Time
+	subscriptions   map[*chaosSubscription]struct{}
+}
+
+func (c *chaosClient) flaky() error {
+	c.lock.RLock()
+	rate := c.failureRate
+	c.lock.RUnlock()
+	//nolint:gosec
+	if rate > 0 && rand.Float64() < rate {
+		return ErrChaosRPCFailure
+	}
+	return nil
+}
+
+func (c *chaosClient) skewed(header *types.Header) *types.Header {
+	if header == nil {
+		return nil
+	}
+	c.lock.RLock()
+	skew := c.clockSkew
+	c.lock.RUnlock()
+	if skew == 0 {
+		return header
+	}
+	skewed := types.CopyHeader(header)
+	seconds := int64(skew / time.Second)
+	if seconds < 0 && uint64(-seconds) > skewed.Time {
+		skewed.Time = 0
+	} else {
+		skewed.Time = uint64(int64(skewed.Time) + seconds)
+	}
+	return skewed
+}
+
+func (c *chaosClient) track(inner ethereum.Subscription) *chaosSubscription {
+	sub := &chaosSubscription{
+		inner:  inner,
+		errCh:  make(chan error, 1),
+		doneCh: make(chan struct{}),
+	}
+	c.lock.Lock()
+	c.subscriptions[sub] = struct{}{}
+	c.lock.Unlock()
+	go func() {
+		if err, ok := <-inner.Err(); ok && err != nil {
+			sub.fail(err)
+		}
+		c.lock.Lock()
+		delete(c.subscriptions, sub)
+		c.lock.Unlock()
+	}()
+	return sub
+}
+
+func (c *chaosClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	delay := c.receiptDelay
+	firstAt, ok := c.receiptsFirstAt[txHash]
+	if !ok {
+		firstAt = time.Now()
+		c.receiptsFirstAt[txHash] = firstAt
+	}
+	c.lock.Unlock()
+	if delay > 0 && time.Since(firstAt) < delay {
+		return nil, ethereum.NotFound
+	}
+	return c.ChainBackend.TransactionReceipt(ctx, txHash)
+}
+
+func (c *chaosClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if err := c.flaky(); err != nil {
+		return nil, false, err
+	}
+	return c.ChainBackend.TransactionByHash(ctx, txHash)
+}
+
+func (c *chaosClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	return c.ChainBackend.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *chaosClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	return c.ChainBackend.CallContract(ctx, call, blockNumber)
+}
+
+func (c *chaosClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	header, err := c.ChainBackend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return c.skewed(header), nil
+}
+
+func (c *chaosClient) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	return c.ChainBackend.PendingCodeAt(ctx, account)
+}
+
+func (c *chaosClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := c.flaky(); err != nil {
+		return 0, err
+	}
+	return c.ChainBackend.PendingNonceAt(ctx, account)
+}
+
+func (c *chaosClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	return c.ChainBackend.SuggestGasPrice(ctx)
+}
+
+func (c *chaosClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	return c.ChainBackend.SuggestGasTipCap(ctx)
+}
+
+func (c *chaosClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := c.flaky(); err != nil {
+		return 0, err
+	}
+	return c.ChainBackend.EstimateGas(ctx, call)
+}
+
+func (c *chaosClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.flaky(); err != nil {
+		return err
+	}
+	return c.ChainBackend.SendTransaction(ctx, tx)
+}
+
+func (c *chaosClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	return c.ChainBackend.FilterLogs(ctx, query)
+}
+
+func (c *chaosClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	inner, err := c.ChainBackend.SubscribeFilterLogs(ctx, query, ch)
+	if err != nil {
+		return nil, err
+	}
+	return c.track(inner), nil
+}
+
+func (c *chaosClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	if err := c.flaky(); err != nil {
+		return nil, err
+	}
+	innerCh := make(chan *types.Header, 1)
+	inner, err := c.ChainBackend.SubscribeNewHead(ctx, innerCh)
+	if err != nil {
+		return nil, err
+	}
+	sub := c.track(inner)
+	go func() {
+		for {
+			select {
+			case header := <-innerCh:
+				select {
+				case ch <- c.skewed(header):
+				case <-sub.done():
+					return
+				}
+			case <-sub.done():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// A subscription which can be terminated by the chaos backend with an error,
+// as if the connection to the node was lost.
+type chaosSubscription struct {
+	inner  ethereum.Subscription
+	errCh  chan error
+	doneCh chan struct{}
+	once   sync.Once
+}
+
+func (s *chaosSubscription) done() <-chan struct{} {
+	return s.doneCh
+}
+
+// Terminates the subscription, delivering the error to the subscriber if non-nil.
+func (s *chaosSubscription) fail(err error) {
+	s.once.Do(func() {
+		s.inner.Unsubscribe()
+		if err != nil {
+			s.errCh <- err
+		}
+		close(s.errCh)
+		close(s.doneCh)
+	})
+}
+
+func (s *chaosSubscription) Unsubscribe() {
+	s.fail(nil)
+}
+
+func (s *chaosSubscription) Err() <-chan error {
+	return s.errCh
+}
@@ -0,0 +1,90 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosBackend(t *testing.T) {
+	ctx := context.Background()
+	sim, err := NewSimulated(time.Hour)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		sim.Commit()
+	}
+	chaos := NewChaos(sim)
+	client := chaos.Client()
+
+	t.Run("rpc failures", func(t *testing.T) {
+		chaos.SetRPCFailureRate(1)
+		_, err := client.HeaderByNumber(ctx, nil)
+		require.ErrorIs(t, err, ErrChaosRPCFailure)
+		chaos.SetRPCFailureRate(0)
+		_, err = client.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+	})
+	t.Run("delayed receipts", func(t *testing.T) {
+		chaos.SetReceiptDelay(time.Hour)
+		defer chaos.SetReceiptDelay(0)
+		_, err := client.TransactionReceipt(ctx, common.Hash{1})
+		require.ErrorIs(t, err, ethereum.NotFound)
+	})
+	t.Run("clock skew", func(t *testing.T) {
+		head, err := sim.Client().HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+		chaos.SetClockSkew(time.Minute)
+		defer chaos.SetClockSkew(0)
+		skewed, err := client.HeaderByNumber(ctx, head.Number)
+		require.NoError(t, err)
+		require.Equal(t, head.Time+60, skewed.Time)
+	})
+	t.Run("dropped subscriptions", func(t *testing.T) {
+		sub, err := client.SubscribeNewHead(ctx, make(chan *types.Header, 10))
+		require.NoError(t, err)
+		chaos.DropSubscriptions()
+		select {
+		case err := <-sub.Err():
+			require.ErrorIs(t, err, ErrChaosSubscriptionDrop)
+		case <-time.After(time.Second):
+			t.Fatal("subscription was not dropped")
+		}
+	})
+	t.Run("reorg", func(t *testing.T) {
+		account := sim.Accounts()[0]
+		nonce, err := client.PendingNonceAt(ctx, account.From)
+		require.NoError(t, err)
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		require.NoError(t, err)
+		tx, err := account.Signer(account.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &common.Address{1},
+			Value:    big.NewInt(1),
+			Gas:      21000,
+			GasPrice: gasPrice,
+		}))
+		require.NoError(t, err)
+		require.NoError(t, client.SendTransaction(ctx, tx))
+		sim.Commit()
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		require.NoError(t, chaos.RunScript(ctx, []ChaosStep{ReorgChain(0, 2)}))
+		after, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+		require.NoError(t, err)
+		require.NotEqual(t, receipt.BlockHash, after.Hash())
+		// The transaction of the reorged block is included in the side chain.
+		reincluded, err := client.TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+		require.NotEqual(t, receipt.BlockHash, reincluded.BlockHash)
+	})
+}
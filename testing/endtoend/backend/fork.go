@@ -0,0 +1,438 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	challenge_testing "github.com/OffchainLabs/bold/testing"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+var _ Backend = &Fork{}
+
+// ForkFlavor is the development node serving a fork, which decides the namespace of the methods
+// used to impersonate accounts and manipulate the chain.
+type ForkFlavor string
+
+const (
+	AnvilFlavor   ForkFlavor = "anvil"
+	HardhatFlavor ForkFlavor = "hardhat"
+)
+
+const defaultForkNodeURL = "http://localhost:8687"
+
+// ForkConfig configures a fork of a chain where a rollup and its challenge manager are deployed,
+// such as mainnet.
+type ForkConfig struct {
+	// The RPC URL of the chain to fork. If set, anvil is started forking it. If empty, the fork
+	// is expected to be served at NodeURL already, such as by a Hardhat node with forking enabled.
+	ForkURL string
+	// The block to fork at. Defaults to the latest block.
+	ForkBlock uint64
+	// The URL the fork is served at. Defaults to http://localhost:8687.
+	NodeURL string
+	// The node serving the fork. Defaults to anvil.
+	Flavor ForkFlavor
+	// The address of the deployed rollup.
+	Rollup common.Address
+	// Stakers to impersonate, whose accounts are returned by Accounts.
+	Stakers []common.Address
+	// The block time of a fork started with anvil. Defaults to a second.
+	BlockTime time.Duration
+}
+
+// ForkConfigFromEnv reads the configuration of a fork from the environment, and returns false if
+// no fork is configured. The variables are:
+//
+//   - BOLD_FORK_URL: the RPC URL of the chain to fork with anvil
+//   - BOLD_FORK_BLOCK: the block to fork at
+//   - BOLD_FORK_NODE_URL: the URL of a node already serving a fork, or the URL to start anvil at
+//   - BOLD_FORK_FLAVOR: anvil or hardhat
+//   - BOLD_FORK_ROLLUP: the address of the deployed rollup, required
+//   - BOLD_FORK_STAKERS: comma-separated addresses of stakers to impersonate
+func ForkConfigFromEnv() (*ForkConfig, bool, error) {
+	cfg := &ForkConfig{
+		ForkURL: os.Getenv("BOLD_FORK_URL"),
+		NodeURL: os.Getenv("BOLD_FORK_NODE_URL"),
+		Flavor:  ForkFlavor(os.Getenv("BOLD_FORK_FLAVOR")),
+	}
+	rollup := os.Getenv("BOLD_FORK_ROLLUP")
+	if cfg.ForkURL == "" && cfg.NodeURL == "" && rollup == "" {
+		return nil, false, nil
+	}
+	if !common.IsHexAddress(rollup) {
+		return nil, false, fmt.Errorf("BOLD_FORK_ROLLUP must be a rollup address, got %q", rollup)
+	}
+	cfg.Rollup = common.HexToAddress(rollup)
+	if block := os.Getenv("BOLD_FORK_BLOCK"); block != "" {
+		n, err := strconv.ParseUint(block, 10, 64)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "BOLD_FORK_BLOCK")
+		}
+		cfg.ForkBlock = n
+	}
+	if stakers := os.Getenv("BOLD_FORK_STAKERS"); stakers != "" {
+		for _, s := range strings.Split(stakers, ",") {
+			s = strings.TrimSpace(s)
+			if !common.IsHexAddress(s) {
+				return nil, false, fmt.Errorf("BOLD_FORK_STAKERS has invalid address %q", s)
+			}
+			cfg.Stakers = append(cfg.Stakers, common.HexToAddress(s))
+		}
+	}
+	return cfg, true, nil
+}
+
+// Fork is a backend forking a chain where a rollup is deployed, served by anvil or Hardhat. Stakers
+// of the rollup are impersonated, so that challenge scenarios can be driven against the deployed
+// contracts, in CI or by operators rehearsing a challenge.
+type Fork struct {
+	cfg       *ForkConfig
+	rpc       *rpc.Client
+	client    *forkClient
+	cmd       *exec.Cmd
+	addresses *setup.RollupAddresses
+	accounts  []*bind.TransactOpts
+}
+
+// NewFork backend for the configuration. You must call Start() on the returned backend to start
+// the fork, or connect to the node serving it.
+func NewFork(cfg *ForkConfig) (*Fork, error) {
+	if cfg.Rollup == (common.Address{}) {
+		return nil, errors.New("a fork requires the address of a deployed rollup")
+	}
+	c := *cfg
+	if c.NodeURL == "" {
+		c.NodeURL = defaultForkNodeURL
+	}
+	if c.Flavor == "" {
+		c.Flavor = AnvilFlavor
+	}
+	if c.Flavor != AnvilFlavor && c.Flavor != HardhatFlavor {
+		return nil, fmt.Errorf("unknown fork flavor %q", c.Flavor)
+	}
+	if c.ForkURL != "" && c.Flavor != AnvilFlavor {
+		return nil, errors.New("only anvil forks can be started, start a hardhat node and give its node url instead")
+	}
+	if c.BlockTime == 0 {
+		c.BlockTime = time.Second
+	}
+	return &Fork{cfg: &c}, nil
+}
+
+// Start the fork with anvil if a fork URL is configured, connect to the node, impersonate the
+// stakers and read the addresses of the deployed rollup.
+func (f *Fork) Start(ctx context.Context) error {
+	if f.cfg.ForkURL != "" {
+		if err := f.startAnvil(ctx); err != nil {
+			return err
+		}
+	}
+	c, err := rpc.DialContext(ctx, f.cfg.NodeURL)
+	if err != nil {
+		return errors.Wrapf(err, "could not dial fork at %s", f.cfg.NodeURL)
+	}
+	f.rpc = c
+	f.client = newForkClient(c)
+	go func() {
+		<-ctx.Done()
+		f.rpc.Close()
+	}()
+	if err = f.waitUntilReady(ctx); err != nil {
+		return err
+	}
+	for _, staker := range f.cfg.Stakers {
+		opts, err := f.Impersonate(ctx, staker)
+		if err != nil {
+			return err
+		}
+		f.accounts = append(f.accounts, opts)
+	}
+	f.addresses, err = f.readAddresses(ctx)
+	return err
+}
+
+func (f *Fork) startAnvil(ctx context.Context) error {
+	binaryPath, ok := os.LookupEnv("ANVIL")
+	if !ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return errors.Wrap(err, "unable to determine user home directory")
+		}
+		binaryPath = path.Join(home, ".foundry/bin/anvil")
+	}
+	port := "8687"
+	if i := strings.LastIndex(f.cfg.NodeURL, ":"); i >= 0 {
+		port = strings.TrimSuffix(f.cfg.NodeURL[i+1:], "/")
+	}
+	args := []string{
+		"--fork-url=" + f.cfg.ForkURL,
+		fmt.Sprintf("--block-time=%d", max(1, int(f.cfg.BlockTime.Seconds()))),
+		"--port=" + port,
+	}
+	if f.cfg.ForkBlock != 0 {
+		args = append(args, fmt.Sprintf("--fork-block-number=%d", f.cfg.ForkBlock))
+	}
+	cmd := exec.CommandContext(ctx, binaryPath, args...) // #nosec G204 -- Test only code.
+	if outputsDir, ok := os.LookupEnv("TEST_UNDECLARED_OUTPUTS_DIR"); ok {
+		stdout, err := os.Create(path.Join(outputsDir, "anvil_fork_out.log")) // #nosec G304 -- Test only code.
+		if err != nil {
+			return err
+		}
+		stderr, err := os.Create(path.Join(outputsDir, "anvil_fork_err.log")) // #nosec G304 -- Test only code.
+		if err != nil {
+			return err
+		}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "could not start anvil fork")
+	}
+	f.cmd = cmd
+	go func() {
+		<-ctx.Done()
+		if err := f.cmd.Process.Kill(); err != nil {
+			fmt.Printf("Could not kill anvil fork process: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// Forking fetches state from the forked chain, so the node may take a while to serve requests.
+func (f *Fork) waitUntilReady(ctx context.Context) error {
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	for {
+		if _, err := f.client.BlockNumber(waitCtx); err == nil {
+			return nil
+		}
+		select {
+		case <-waitCtx.Done():
+			return errors.Wrapf(waitCtx.Err(), "fork at %s did not become ready", f.cfg.NodeURL)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (f *Fork) readAddresses(ctx context.Context) (*setup.RollupAddresses, error) {
+	rollup, err := rollupgen.NewRollupUserLogicCaller(f.cfg.Rollup, f.client)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	addresses := &setup.RollupAddresses{
+		Rollup:          f.cfg.Rollup,
+		RollupUserLogic: f.cfg.Rollup,
+	}
+	if addresses.Bridge, err = rollup.Bridge(opts); err != nil {
+		return nil, errors.Wrap(err, "could not read rollup bridge, is the rollup deployed at the fork block?")
+	}
+	if addresses.Inbox, err = rollup.Inbox(opts); err != nil {
+		return nil, errors.Wrap(err, "could not read rollup inbox")
+	}
+	if addresses.SequencerInbox, err = rollup.SequencerInbox(opts); err != nil {
+		return nil, errors.Wrap(err, "could not read rollup sequencer inbox")
+	}
+	if addresses.ValidatorWalletCreator, err = rollup.ValidatorWalletCreator(opts); err != nil {
+		return nil, errors.Wrap(err, "could not read rollup validator wallet creator")
+	}
+	return addresses, nil
+}
+
+// Client returns the client of the fork, which sends the transactions of impersonated accounts.
+func (f *Fork) Client() protocol.ChainBackend {
+	return f.client
+}
+
+// Accounts of the impersonated stakers.
+func (f *Fork) Accounts() []*bind.TransactOpts {
+	return f.accounts
+}
+
+func (f *Fork) Commit() common.Hash {
+	return common.Hash{}
+}
+
+// DeployRollup does not deploy anything, as the rollup of a fork is already deployed, and returns
+// its addresses.
+func (f *Fork) DeployRollup(_ context.Context, _ ...challenge_testing.Opt) (*setup.RollupAddresses, error) {
+	if f.addresses == nil {
+		return nil, errors.New("fork not started")
+	}
+	return f.addresses, nil
+}
+
+func (f *Fork) ContractAddresses() *setup.RollupAddresses {
+	return f.addresses
+}
+
+// Impersonate an account, such as a staker or a holder of the stake token, and fund it with ether
+// for gas. Transactions made with the returned options are sent unsigned, from the account.
+func (f *Fork) Impersonate(ctx context.Context, account common.Address) (*bind.TransactOpts, error) {
+	if err := f.rpc.CallContext(ctx, nil, f.method("impersonateAccount"), account); err != nil {
+		return nil, errors.Wrapf(err, "could not impersonate %s", account)
+	}
+	balance, err := f.client.BalanceAt(ctx, account, nil)
+	if err != nil {
+		return nil, err
+	}
+	minBalance := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	if balance.Cmp(minBalance) < 0 {
+		if err = f.SetBalance(ctx, account, minBalance); err != nil {
+			return nil, err
+		}
+	}
+	return &bind.TransactOpts{
+		From: account,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != account {
+				return nil, bind.ErrNotAuthorized
+			}
+			f.client.recordSender(tx, account)
+			return tx, nil
+		},
+		Context: ctx,
+	}, nil
+}
+
+// SetBalance of an account, in wei.
+func (f *Fork) SetBalance(ctx context.Context, account common.Address, wei *big.Int) error {
+	return f.rpc.CallContext(ctx, nil, f.method("setBalance"), account, (*hexutil.Big)(wei))
+}
+
+// MineBlocks instantly mines n blocks, such as to get past the confirmation period of an assertion.
+func (f *Fork) MineBlocks(ctx context.Context, n uint64) error {
+	return f.rpc.CallContext(ctx, nil, f.method("mine"), hexutil.EncodeUint64(n))
+}
+
+// IncreaseTime advances the timestamp of the next block.
+func (f *Fork) IncreaseTime(ctx context.Context, d time.Duration) error {
+	return f.rpc.CallContext(ctx, nil, "evm_increaseTime", hexutil.EncodeUint64(uint64(d.Seconds())))
+}
+
+// Snapshot the state of the fork, so that a scenario can be rehearsed again from it with Revert.
+func (f *Fork) Snapshot(ctx context.Context) (string, error) {
+	var id string
+	err := f.rpc.CallContext(ctx, &id, "evm_snapshot")
+	return id, err
+}
+
+// Revert the fork to a snapshot.
+func (f *Fork) Revert(ctx context.Context, id string) error {
+	var ok bool
+	if err := f.rpc.CallContext(ctx, &ok, "evm_revert", id); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("could not revert to snapshot %s", id)
+	}
+	return nil
+}
+
+func (f *Fork) method(name string) string {
+	return string(f.cfg.Flavor) + "_" + name
+}
+
+// A client which sends the transactions of impersonated accounts with eth_sendTransaction, as they
+// cannot be signed. The sender of an unsigned transaction cannot be recovered, so it is recorded
+// when the transaction is "signed". The hashes of the transactions sent by the node differ from the
+// hashes of the unsigned transactions given to the client, so lookups by hash are translated.
+type forkClient struct {
+	*ethclient.Client
+	rpc     *rpc.Client
+	lock    sync.RWMutex
+	senders map[common.Hash]common.Address
+	sent    map[common.Hash]common.Hash
+}
+
+func newForkClient(c *rpc.Client) *forkClient {
+	return &forkClient{
+		Client:  ethclient.NewClient(c),
+		rpc:     c,
+		senders: make(map[common.Hash]common.Address),
+		sent:    make(map[common.Hash]common.Hash),
+	}
+}
+
+func (c *forkClient) recordSender(tx *types.Transaction, sender common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.senders[tx.Hash()] = sender
+}
+
+// SendTransaction sends unsigned transactions of impersonated accounts with eth_sendTransaction.
+func (c *forkClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	v, r, s := tx.RawSignatureValues()
+	if v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0 {
+		return c.Client.SendTransaction(ctx, tx)
+	}
+	c.lock.RLock()
+	from, ok := c.senders[tx.Hash()]
+	c.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("unsigned transaction %s was not made by an impersonated account", tx.Hash())
+	}
+	args := map[string]any{
+		"from":  from,
+		"gas":   hexutil.Uint64(tx.Gas()),
+		"value": (*hexutil.Big)(tx.Value()),
+		"data":  hexutil.Bytes(tx.Data()),
+		"nonce": hexutil.Uint64(tx.Nonce()),
+	}
+	if tx.To() != nil {
+		args["to"] = tx.To()
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args["maxFeePerGas"] = (*hexutil.Big)(tx.GasFeeCap())
+		args["maxPriorityFeePerGas"] = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args["gasPrice"] = (*hexutil.Big)(tx.GasPrice())
+	}
+	var hash common.Hash
+	if err := c.rpc.CallContext(ctx, &hash, "eth_sendTransaction", args); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	delete(c.senders, tx.Hash())
+	c.sent[tx.Hash()] = hash
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *forkClient) nodeHash(hash common.Hash) common.Hash {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if sent, ok := c.sent[hash]; ok {
+		return sent
+	}
+	return hash
+}
+
+func (c *forkClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return c.Client.TransactionReceipt(ctx, c.nodeHash(hash))
+}
+
+func (c *forkClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return c.Client.TransactionByHash(ctx, c.nodeHash(hash))
+}
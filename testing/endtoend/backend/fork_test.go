@@ -0,0 +1,133 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkConfigFromEnv(t *testing.T) {
+	t.Setenv("BOLD_FORK_URL", "")
+	t.Setenv("BOLD_FORK_NODE_URL", "")
+	t.Setenv("BOLD_FORK_ROLLUP", "")
+	_, ok, err := ForkConfigFromEnv()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	t.Setenv("BOLD_FORK_URL", "https://eth.example.com")
+	_, _, err = ForkConfigFromEnv()
+	require.ErrorContains(t, err, "BOLD_FORK_ROLLUP")
+
+	t.Setenv("BOLD_FORK_ROLLUP", "0x0000000000000000000000000000000000000001")
+	t.Setenv("BOLD_FORK_BLOCK", "19000000")
+	t.Setenv("BOLD_FORK_STAKERS", "0x0000000000000000000000000000000000000002, 0x0000000000000000000000000000000000000003")
+	cfg, ok, err := ForkConfigFromEnv()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, common.HexToAddress("0x1"), cfg.Rollup)
+	require.Equal(t, uint64(19000000), cfg.ForkBlock)
+	require.Equal(t, []common.Address{common.HexToAddress("0x2"), common.HexToAddress("0x3")}, cfg.Stakers)
+
+	t.Setenv("BOLD_FORK_STAKERS", "0x2,staker")
+	_, _, err = ForkConfigFromEnv()
+	require.ErrorContains(t, err, "invalid address")
+}
+
+func TestNewFork(t *testing.T) {
+	_, err := NewFork(&ForkConfig{ForkURL: "https://eth.example.com"})
+	require.ErrorContains(t, err, "deployed rollup")
+	_, err = NewFork(&ForkConfig{Rollup: common.HexToAddress("0x1"), Flavor: "ganache"})
+	require.ErrorContains(t, err, "unknown fork flavor")
+	_, err = NewFork(&ForkConfig{Rollup: common.HexToAddress("0x1"), ForkURL: "https://eth.example.com", Flavor: HardhatFlavor})
+	require.ErrorContains(t, err, "only anvil forks")
+
+	f, err := NewFork(&ForkConfig{Rollup: common.HexToAddress("0x1")})
+	require.NoError(t, err)
+	require.Equal(t, defaultForkNodeURL, f.cfg.NodeURL)
+	require.Equal(t, "anvil_impersonateAccount", f.method("impersonateAccount"))
+}
+
+type fakeEthService struct {
+	sent []map[string]any
+}
+
+func (s *fakeEthService) SendTransaction(args map[string]any) common.Hash {
+	s.sent = append(s.sent, args)
+	return common.HexToHash("0xfeed")
+}
+
+func TestForkClient_SendsImpersonatedTransactions(t *testing.T) {
+	ctx := context.Background()
+	server := rpc.NewServer()
+	eth := &fakeEthService{}
+	require.NoError(t, server.RegisterName("eth", eth))
+	client := newForkClient(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+
+	staker := common.HexToAddress("0x2")
+	to := common.HexToAddress("0x1")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     3,
+		To:        &to,
+		Gas:       100_000,
+		GasFeeCap: big.NewInt(10),
+		GasTipCap: big.NewInt(1),
+		Value:     big.NewInt(0),
+		Data:      []byte{0x12, 0x34},
+	})
+
+	// Unsigned transactions not made by an impersonated account are rejected.
+	require.ErrorContains(t, client.SendTransaction(ctx, tx), "not made by an impersonated account")
+
+	client.recordSender(tx, staker)
+	require.NoError(t, client.SendTransaction(ctx, tx))
+	require.Len(t, eth.sent, 1)
+	require.Equal(t, staker.Hex(), common.HexToAddress(eth.sent[0]["from"].(string)).Hex())
+	require.Equal(t, hexutil.EncodeUint64(3), eth.sent[0]["nonce"])
+	require.Equal(t, "0x1234", eth.sent[0]["data"])
+	require.Contains(t, eth.sent[0], "maxFeePerGas")
+	require.Equal(t, common.HexToHash("0xfeed"), client.nodeHash(tx.Hash()))
+	require.Equal(t, common.HexToHash("0xbeef"), client.nodeHash(common.HexToHash("0xbeef")))
+}
+
+// Rehearses against a fork configured by the BOLD_FORK_* variables, see README.md.
+func TestFork_Rehearsal(t *testing.T) {
+	cfg, ok, err := ForkConfigFromEnv()
+	require.NoError(t, err)
+	if !ok {
+		t.Skip("No fork configured, set BOLD_FORK_ROLLUP and BOLD_FORK_URL or BOLD_FORK_NODE_URL")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	f, err := NewFork(cfg)
+	require.NoError(t, err)
+	require.NoError(t, f.Start(ctx))
+
+	addresses, err := f.DeployRollup(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, common.Address{}, addresses.Bridge)
+	require.Len(t, f.Accounts(), len(cfg.Stakers))
+
+	snapshot, err := f.Snapshot(ctx)
+	require.NoError(t, err)
+	before, err := f.Client().HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, f.MineBlocks(ctx, 100))
+	after, err := f.Client().HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, after.Number.Uint64(), before.Number.Uint64()+100)
+	require.NoError(t, f.Revert(ctx, snapshot))
+	reverted, err := f.Client().HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.Less(t, reverted.Number.Uint64(), after.Number.Uint64())
+}
@@ -2,6 +2,9 @@ package backend
 
 import (
 	"context"
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
@@ -9,9 +12,21 @@ import (
 	"github.com/OffchainLabs/bold/testing/setup"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 )
 
-var _ Backend = &LocalSimulatedBackend{}
+var (
+	_ Backend = &LocalSimulatedBackend{}
+	_ Reorger = &LocalSimulatedBackend{}
+)
+
+const (
+	resendAttempts = 50
+	resendInterval = 10 * time.Millisecond
+)
 
 type LocalSimulatedBackend struct {
 	blockTime time.Duration
@@ -47,6 +62,65 @@ func (l *LocalSimulatedBackend) Commit() common.Hash {
 	return l.setup.Backend.Commit()
 }
 
+// Reorg rewinds the simulated chain by the specified depth and then mines a longer side chain on
+// top of the common ancestor so that it becomes canonical. As a node returns the transactions of
+// the blocks reorged out to its pool, they are sent again to be included in the side chain.
+func (l *LocalSimulatedBackend) Reorg(depth uint64) error {
+	ctx := context.Background()
+	client := l.setup.Backend.Client()
+	// The chain cannot be forked with pending transactions, so they are mined first.
+	l.setup.Backend.Commit()
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if head.Number.Uint64() < depth {
+		return fmt.Errorf("cannot reorg %d blocks from head %d", depth, head.Number.Uint64())
+	}
+	ancestorNum := head.Number.Uint64() - depth
+	ancestor, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(ancestorNum))
+	if err != nil {
+		return err
+	}
+	reorged := make([]*types.Transaction, 0)
+	for num := ancestorNum + 1; num <= head.Number.Uint64(); num++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+		if err != nil {
+			return err
+		}
+		reorged = append(reorged, block.Transactions()...)
+	}
+	if err := l.setup.Backend.Fork(ancestor.Hash()); err != nil {
+		return err
+	}
+	for _, tx := range reorged {
+		l.resend(ctx, tx)
+	}
+	for i := uint64(0); i <= depth; i++ {
+		l.setup.Backend.Commit()
+	}
+	return nil
+}
+
+// Sends a transaction of a reorged block again, waiting for the pool to be reset to the side chain
+// if it rejects the nonce of the transaction as too low. Transactions the pool still holds, or which
+// are invalid on the side chain, are left out.
+func (l *LocalSimulatedBackend) resend(ctx context.Context, tx *types.Transaction) {
+	for attempt := 0; attempt < resendAttempts; attempt++ {
+		// Errors are matched by their message, as they are returned over RPC.
+		err := l.setup.Backend.Client().SendTransaction(ctx, tx)
+		if err == nil || strings.Contains(err.Error(), txpool.ErrAlreadyKnown.Error()) {
+			return
+		}
+		if !strings.Contains(err.Error(), core.ErrNonceTooLow.Error()) {
+			log.Warn("Could not resend reorged transaction", "hash", tx.Hash(), "err", err)
+			return
+		}
+		time.Sleep(resendInterval)
+	}
+	log.Warn("Could not resend reorged transaction before the pool was reset", "hash", tx.Hash())
+}
+
 func (l *LocalSimulatedBackend) Accounts() []*bind.TransactOpts {
 	accs := make([]*bind.TransactOpts, len(l.setup.Accounts))
 	for i := 0; i < len(l.setup.Accounts); i++ {
@@ -55,6 +55,8 @@ type e2eConfig struct {
 	timings      timeParams
 	inbox        inboxParams
 	actors       actorParams
+	chaos        []backend.ChaosStep
+	soak         *soakParams
 	expectations []expect
 }
 
@@ -133,6 +135,31 @@ func TestEndToEnd_SmokeTest(t *testing.T) {
 	})
 }
 
+func TestEndToEnd_Chaos(t *testing.T) {
+	runEndToEndTest(t, &e2eConfig{
+		backend:  simulated,
+		protocol: defaultProtocolParams(),
+		inbox:    defaultInboxParams(),
+		actors: actorParams{
+			numEvilValidators: 1,
+		},
+		timings: defaultTimeParams(),
+		chaos: []backend.ChaosStep{
+			backend.DelayReceipts(0, 2*time.Second),
+			backend.SkewClock(10*time.Second, 30*time.Second),
+			backend.FailRPCs(5*time.Second, 0.05),
+			backend.FailRPCs(10*time.Second, 0),
+			backend.DropSubscriptions(5 * time.Second),
+			backend.ReorgChain(10*time.Second, 2),
+			backend.SkewClock(10*time.Second, 0),
+		},
+		expectations: []expect{
+			// Expect one assertion is confirmed by challenge win despite the misbehaving infrastructure.
+			expectAssertionConfirmedByChallengeWin,
+		},
+	})
+}
+
 func TestEndToEnd_MaxWavmOpcodes(t *testing.T) {
 	t.Skip("Flakey simulated backend")
 	protocolCfg := defaultProtocolParams()
@@ -209,6 +236,10 @@ func runEndToEndTest(t *testing.T, cfg *e2eConfig) {
 
 	// Validators include a chain admin, a single honest validators, and any number of evil entities.
 	totalValidators := cfg.actors.numEvilValidators + 2
+	if cfg.soak != nil {
+		// Synthetic adversaries each get an account of their own.
+		totalValidators += cfg.soak.maxAdversaries
+	}
 
 	challengeTestingOpts := []challenge_testing.Opt{
 		challenge_testing.WithConfirmPeriodBlocks(cfg.protocol.challengePeriodBlocks),
@@ -241,6 +272,12 @@ func runEndToEndTest(t *testing.T, cfg *e2eConfig) {
 
 	require.NoError(t, bk.Start(ctx))
 
+	var chaosBackend *backend.ChaosBackend
+	if len(cfg.chaos) > 0 {
+		chaosBackend = backend.NewChaos(bk)
+		bk = chaosBackend
+	}
+
 	accounts := bk.Accounts()
 	bk.Commit()
 
@@ -294,9 +331,7 @@ func runEndToEndTest(t *testing.T, cfg *e2eConfig) {
 	assertionDivergenceHeight := uint64(1)
 	assertionBlockHeightDifference := int64(1)
 
-	evilChallengeManagers := make([]*challengemanager.Manager, cfg.actors.numEvilValidators)
-	for i := uint64(0); i < cfg.actors.numEvilValidators; i++ {
-		machineDivergenceStep := randUint64(totalOpcodes)
+	newEvilChallengeManager := func(ctx context.Context, account uint64, name string, machineDivergenceStep uint64) *challengemanager.Manager {
 		//nolint:gocritic
 		evilStateManagerOpts := append(
 			baseStateManagerOpts,
@@ -307,19 +342,22 @@ func runEndToEndTest(t *testing.T, cfg *e2eConfig) {
 		evilStateManager, err := statemanager.NewForSimpleMachine(evilStateManagerOpts...)
 		require.NoError(t, err)
 
-		// Honest validator has index 1 in the accounts slice, as 0 is admin, so evil ones should start at 2.
-		txOpts = accounts[2+i]
-		name = fmt.Sprintf("evil-%d", i)
+		txOpts := accounts[account]
 		//nolint:gocritic
 		evilOpts := append(
 			baseChallengeManagerOpts,
 			challengemanager.WithAddress(txOpts.From),
 			challengemanager.WithName(name),
 		)
-		evilManager := setupChallengeManager(
+		return setupChallengeManager(
 			t, ctx, bk.Client(), rollupAddr.Rollup, evilStateManager, txOpts, name, evilOpts...,
 		)
-		evilChallengeManagers[i] = evilManager
+	}
+
+	evilChallengeManagers := make([]*challengemanager.Manager, cfg.actors.numEvilValidators)
+	for i := uint64(0); i < cfg.actors.numEvilValidators; i++ {
+		// Honest validator has index 1 in the accounts slice, as 0 is admin, so evil ones should start at 2.
+		evilChallengeManagers[i] = newEvilChallengeManager(ctx, 2+i, fmt.Sprintf("evil-%d", i), randUint64(totalOpcodes))
 	}
 
 	honestManager.Start(ctx)
@@ -328,8 +366,28 @@ func runEndToEndTest(t *testing.T, cfg *e2eConfig) {
 		evilManager.Start(ctx)
 	}
 
+	if cfg.soak != nil {
+		runSoak(t, ctx, cfg.soak, func(ctx context.Context, i uint64) *challengemanager.Manager {
+			// Synthetic adversaries use the accounts after those of the long-lived evil validators.
+			account := 2 + cfg.actors.numEvilValidators + i
+			return newEvilChallengeManager(ctx, account, fmt.Sprintf("adversary-%d", i), randUint64(totalOpcodes))
+		})
+	}
+	expectations := cfg.expectations
+	if cfg.soak != nil {
+		expectations = append(
+			expectations,
+			expectConfirmedAssertionsAgreeWith(honestStateManager, cfg.protocol.layerZeroHeights.BlockChallengeHeight),
+		)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
-	for _, e := range cfg.expectations {
+	if chaosBackend != nil {
+		g.Go(func() error {
+			return chaosBackend.RunScript(ctx, cfg.chaos)
+		})
+	}
+	for _, e := range expectations {
 		fn := e // loop closure
 		g.Go(func() error {
 			return fn(t, ctx, bk.ContractAddresses(), bk.Client())
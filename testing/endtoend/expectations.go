@@ -6,10 +6,13 @@ import (
 	"time"
 
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	retry "github.com/OffchainLabs/bold/runtime"
+	"github.com/OffchainLabs/bold/solgen/go/iterators"
 	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
 	"github.com/OffchainLabs/bold/testing/setup"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -54,3 +57,77 @@ func expectAssertionConfirmedByChallengeWin(
 	})
 	return nil
 }
+
+// Expects that every confirmed assertion agrees with the execution state computed by the honest
+// state provider, and that at least one assertion was confirmed. Unlike other expectations, this
+// checks the chain once rather than waiting for a condition, so it is meant to verify invariants
+// at the end of a run.
+func expectConfirmedAssertionsAgreeWith(honest l2stateprovider.Provider, blockChallengeHeight uint64) expect {
+	return func(
+		t *testing.T,
+		ctx context.Context,
+		addresses *setup.RollupAddresses,
+		backend protocol.ChainBackend,
+	) error {
+		rc, err := rollupgen.NewRollupCore(addresses.Rollup, backend)
+		if err != nil {
+			return err
+		}
+		created := make(map[[32]byte]*rollupgen.RollupCoreAssertionCreated)
+		createdIt, err := retry.UntilSucceeds(ctx, func() (*rollupgen.RollupCoreAssertionCreatedIterator, error) {
+			return rc.FilterAssertionCreated(&bind.FilterOpts{Context: ctx}, nil, nil)
+		})
+		if err != nil {
+			return err
+		}
+		createdEvents, err := iterators.CollectEvents[*rollupgen.RollupCoreAssertionCreated](createdIt, 0)
+		if err != nil {
+			return err
+		}
+		for _, event := range createdEvents {
+			created[event.AssertionHash] = event
+		}
+		confirmedIt, err := retry.UntilSucceeds(ctx, func() (*rollupgen.RollupCoreAssertionConfirmedIterator, error) {
+			return rc.FilterAssertionConfirmed(&bind.FilterOpts{Context: ctx}, nil)
+		})
+		if err != nil {
+			return err
+		}
+		confirmedEvents, err := iterators.CollectEvents[*rollupgen.RollupCoreAssertionConfirmed](confirmedIt, 0)
+		if err != nil {
+			return err
+		}
+		var numChecked int
+		for _, confirmed := range confirmedEvents {
+			assertion, ok := created[confirmed.AssertionHash]
+			if !ok {
+				return errors.Errorf("confirmed assertion %#x was never created", confirmed.AssertionHash)
+			}
+			parent, ok := created[assertion.ParentAssertionHash]
+			if !ok {
+				// The genesis assertion has no parent to compute its state from.
+				continue
+			}
+			parentGlobalState := protocol.GoGlobalStateFromSolidity(parent.Assertion.AfterState.GlobalState)
+			want, err := honest.ExecutionStateAfterPreviousState(
+				ctx, parent.InboxMaxCount.Uint64(), &parentGlobalState, blockChallengeHeight-1,
+			)
+			if err != nil {
+				return err
+			}
+			got := protocol.GoExecutionStateFromSolidity(assertion.Assertion.AfterState)
+			if got.MachineStatus != want.MachineStatus || !got.GlobalState.Equals(want.GlobalState) {
+				return errors.Errorf(
+					"confirmed assertion %#x has state %+v, but the honest state is %+v",
+					confirmed.AssertionHash, got, want,
+				)
+			}
+			numChecked++
+		}
+		if numChecked == 0 {
+			return errors.New("no assertion was confirmed")
+		}
+		t.Logf("Verified %d confirmed assertions agree with the honest state", numChecked)
+		return nil
+	}
+}
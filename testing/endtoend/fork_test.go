@@ -0,0 +1,76 @@
+package endtoend
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	challengemanager "github.com/OffchainLabs/bold/challenge-manager"
+	"github.com/OffchainLabs/bold/challenge-manager/types"
+	"github.com/OffchainLabs/bold/testing/endtoend/backend"
+	statemanager "github.com/OffchainLabs/bold/testing/mocks/state-provider"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+// Runs a challenge manager against the contracts of a rollup deployed on a forked chain, as the
+// first impersonated staker, for operators rehearsing a challenge. The state provider does not
+// know the states of the forked rollup, so it disagrees with its assertions, and a challenge
+// manager in defensive or make mode challenges them. The fork is reverted once the test is done,
+// so a rehearsal can be rerun against the same state. Skipped unless a fork is configured by the
+// BOLD_FORK_* variables, see README.md.
+func TestEndToEnd_Fork(t *testing.T) {
+	cfg, ok, err := backend.ForkConfigFromEnv()
+	require.NoError(t, err)
+	if !ok {
+		t.Skip("No fork configured, set BOLD_FORK_ROLLUP and BOLD_FORK_URL or BOLD_FORK_NODE_URL")
+	}
+	mode := types.WatchTowerMode
+	if name := os.Getenv("BOLD_FORK_MODE"); name != "" {
+		mode, err = types.ParseMode(name)
+		require.NoError(t, err)
+	}
+	duration := durationFromEnv(t, "BOLD_FORK_DURATION", time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fork, err := backend.NewFork(cfg)
+	require.NoError(t, err)
+	require.NoError(t, fork.Start(ctx))
+	snapshot, err := fork.Snapshot(ctx)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, fork.Revert(context.Background(), snapshot))
+	}()
+	addresses, err := fork.DeployRollup(ctx)
+	require.NoError(t, err)
+
+	var txOpts *bind.TransactOpts
+	if len(fork.Accounts()) > 0 {
+		txOpts = fork.Accounts()[0]
+	} else {
+		require.Equal(t, types.WatchTowerMode, mode, "modes other than watchtower need an impersonated staker, set BOLD_FORK_STAKERS")
+		txOpts = &bind.TransactOpts{}
+	}
+	stateManager, err := statemanager.NewForSimpleMachine()
+	require.NoError(t, err)
+	manager := setupChallengeManager(
+		t, ctx, fork.Client(), addresses.Rollup, stateManager, txOpts, "rehearsal",
+		challengemanager.WithMode(mode),
+		challengemanager.WithAddress(txOpts.From),
+		challengemanager.WithName("rehearsal"),
+	)
+	manager.Start(ctx)
+	defer manager.StopAndWait()
+
+	require.Eventually(t, manager.Watcher().IsSynced, duration, time.Second, "challenge manager did not sync the forked rollup")
+	latest, err := manager.Chain().LatestConfirmed(ctx)
+	require.NoError(t, err)
+	t.Logf("Synced the forked rollup %s, latest confirmed assertion %s", addresses.Rollup, latest.Id().Hash)
+	// Let the challenge manager act on the assertions of the fork for the rest of the rehearsal.
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+	}
+}
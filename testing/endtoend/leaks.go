@@ -0,0 +1,97 @@
+package endtoend
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Resource usage of the process at a point in time.
+type resourceUsage struct {
+	goroutines int
+	heapBytes  uint64
+	// Negative where open files cannot be counted, such as outside of Linux.
+	openFiles int
+}
+
+func (u resourceUsage) String() string {
+	return fmt.Sprintf("goroutines=%d heap=%dMiB openFiles=%d", u.goroutines, u.heapBytes>>20, u.openFiles)
+}
+
+// Measures the resource usage of the process, after a garbage collection so that the heap only
+// holds live objects.
+func measureResources() resourceUsage {
+	runtime.GC()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	openFiles := -1
+	if entries, err := os.ReadDir("/proc/self/fd"); err == nil {
+		openFiles = len(entries)
+	}
+	return resourceUsage{
+		goroutines: runtime.NumGoroutine(),
+		heapBytes:  mem.HeapAlloc,
+		openFiles:  openFiles,
+	}
+}
+
+// Limits on the growth of resource usage over its baseline, beyond which resources are considered
+// leaked. A zero limit disables the check of that resource.
+type leakLimits struct {
+	goroutines int
+	heapBytes  uint64
+	openFiles  int
+}
+
+// Detects leaks by comparing the resource usage of the process at the end of a long run with its
+// usage once it reached a steady state.
+type leakDetector struct {
+	limits   leakLimits
+	measure  func() resourceUsage
+	baseline resourceUsage
+	peak     resourceUsage
+}
+
+func newLeakDetector(limits leakLimits) *leakDetector {
+	return &leakDetector{
+		limits:  limits,
+		measure: measureResources,
+	}
+}
+
+// Records the baseline usage, which should be measured once the process reached a steady state.
+func (d *leakDetector) setBaseline() resourceUsage {
+	d.baseline = d.measure()
+	d.peak = d.baseline
+	return d.baseline
+}
+
+// Measures the current usage, keeping track of the peak usage for reporting.
+func (d *leakDetector) sample() resourceUsage {
+	u := d.measure()
+	d.peak.goroutines = max(d.peak.goroutines, u.goroutines)
+	d.peak.heapBytes = max(d.peak.heapBytes, u.heapBytes)
+	d.peak.openFiles = max(d.peak.openFiles, u.openFiles)
+	return u
+}
+
+// Measures the final usage and returns an error describing every resource which grew beyond its
+// limit over the baseline.
+func (d *leakDetector) check() error {
+	final := d.sample()
+	leaks := make([]string, 0)
+	if d.limits.goroutines > 0 && final.goroutines-d.baseline.goroutines > d.limits.goroutines {
+		leaks = append(leaks, fmt.Sprintf("goroutines grew from %d to %d", d.baseline.goroutines, final.goroutines))
+	}
+	if d.limits.heapBytes > 0 && final.heapBytes > d.baseline.heapBytes && final.heapBytes-d.baseline.heapBytes > d.limits.heapBytes {
+		leaks = append(leaks, fmt.Sprintf("heap grew from %dMiB to %dMiB", d.baseline.heapBytes>>20, final.heapBytes>>20))
+	}
+	if d.limits.openFiles > 0 && d.baseline.openFiles >= 0 && final.openFiles-d.baseline.openFiles > d.limits.openFiles {
+		leaks = append(leaks, fmt.Sprintf("open files grew from %d to %d", d.baseline.openFiles, final.openFiles))
+	}
+	if len(leaks) > 0 {
+		return fmt.Errorf("resources leaked (peak %s): %s", d.peak, strings.Join(leaks, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package endtoend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeakDetector(t *testing.T) {
+	usage := resourceUsage{goroutines: 100, heapBytes: 1 << 30, openFiles: 20}
+	d := newLeakDetector(leakLimits{goroutines: 10, heapBytes: 1 << 20, openFiles: 5})
+	d.measure = func() resourceUsage { return usage }
+	d.setBaseline()
+
+	// Usage may go up and down during the run, as long as it returns to within the limits.
+	usage.goroutines = 500
+	d.sample()
+	usage = resourceUsage{goroutines: 110, heapBytes: 1<<30 + 1<<20, openFiles: 25}
+	require.NoError(t, d.check())
+
+	usage = resourceUsage{goroutines: 111, heapBytes: 1<<30 + 1<<21, openFiles: 26}
+	err := d.check()
+	require.ErrorContains(t, err, "goroutines grew from 100 to 111")
+	require.ErrorContains(t, err, "heap grew from 1024MiB to 1026MiB")
+	require.ErrorContains(t, err, "open files grew from 20 to 26")
+	require.ErrorContains(t, err, "goroutines=500")
+
+	// Disabled limits are not checked.
+	d.limits = leakLimits{}
+	require.NoError(t, d.check())
+}
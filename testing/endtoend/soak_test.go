@@ -0,0 +1,190 @@
+package endtoend
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	challengemanager "github.com/OffchainLabs/bold/challenge-manager"
+	"github.com/stretchr/testify/require"
+)
+
+// Defines a long-running soak test, in which synthetic adversaries come and go at random while the
+// honest validator keeps running, checking that the honest validator does not leak resources over
+// time.
+type soakParams struct {
+	duration time.Duration
+	// Mean of the exponentially distributed intervals between new adversaries appearing.
+	adversaryInterval time.Duration
+	// Mean of the exponentially distributed lifetimes of adversaries.
+	adversaryLifetime time.Duration
+	// Maximum number of adversaries over the whole run, as each needs a funded account.
+	maxAdversaries uint64
+	// Time to let the system reach a steady state before measuring baseline resource usage.
+	warmup time.Duration
+	// Time to let the system settle after the last adversary is stopped before checking for leaks.
+	cooldown       time.Duration
+	sampleInterval time.Duration
+	leakLimits     leakLimits
+	seed           int64
+}
+
+// Runs the full stack for the duration configured by BOLD_SOAK_DURATION, e.g. "72h". Skipped
+// unless the variable is set. See README.md for the other variables it accepts.
+func TestEndToEnd_Soak(t *testing.T) {
+	durationStr := os.Getenv("BOLD_SOAK_DURATION")
+	if durationStr == "" {
+		t.Skip("BOLD_SOAK_DURATION not set")
+	}
+	duration, err := time.ParseDuration(durationStr)
+	require.NoError(t, err)
+	soak := &soakParams{
+		duration:          duration,
+		adversaryInterval: durationFromEnv(t, "BOLD_SOAK_ADVERSARY_INTERVAL", 10*time.Minute),
+		adversaryLifetime: durationFromEnv(t, "BOLD_SOAK_ADVERSARY_LIFETIME", 30*time.Minute),
+		maxAdversaries:    uint64FromEnv(t, "BOLD_SOAK_MAX_ADVERSARIES", 50),
+		warmup:            durationFromEnv(t, "BOLD_SOAK_WARMUP", time.Minute),
+		cooldown:          durationFromEnv(t, "BOLD_SOAK_COOLDOWN", time.Minute),
+		sampleInterval:    10 * time.Second,
+		leakLimits: leakLimits{
+			goroutines: 100,
+			heapBytes:  512 << 20,
+			openFiles:  50,
+		},
+		seed: int64(uint64FromEnv(t, "BOLD_SOAK_SEED", uint64(time.Now().UnixNano()))),
+	}
+	kind := simulated
+	if os.Getenv("ANVIL") != "" {
+		kind = anvil
+	} else {
+		// The simulated chain lives in process and its state grows with every block, so heap growth
+		// says nothing about leaks in the validator.
+		soak.leakLimits.heapBytes = 0
+	}
+	timings := defaultTimeParams()
+	timings.assertionPostingInterval = time.Minute
+	runEndToEndTest(t, &e2eConfig{
+		backend:  kind,
+		protocol: defaultProtocolParams(),
+		inbox:    defaultInboxParams(),
+		timings:  timings,
+		soak:     soak,
+		expectations: []expect{
+			expectAssertionConfirmedByChallengeWin,
+		},
+	})
+}
+
+// Keeps the system running for the duration of the soak test, starting and stopping adversaries
+// created by newAdversary at random, and fails the test if resources leaked once all adversaries
+// are gone.
+func runSoak(
+	t *testing.T,
+	ctx context.Context,
+	cfg *soakParams,
+	newAdversary func(ctx context.Context, i uint64) *challengemanager.Manager,
+) {
+	t.Logf("Soaking for %s with seed %d", cfg.duration, cfg.seed)
+	rng := rand.New(rand.NewSource(cfg.seed)) // #nosec G404
+	randDuration := func(mean time.Duration) time.Duration {
+		return time.Duration(rng.ExpFloat64() * float64(mean))
+	}
+	type adversary struct {
+		manager *challengemanager.Manager
+		cancel  context.CancelFunc
+		retire  time.Time
+	}
+	stop := func(a *adversary) {
+		a.manager.StopAndWait()
+		a.cancel()
+	}
+	adversaries := make(map[uint64]*adversary)
+	defer func() {
+		for _, a := range adversaries {
+			stop(a)
+		}
+	}()
+
+	sleep := func(d time.Duration) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(d):
+		}
+	}
+	sleep(cfg.warmup)
+	detector := newLeakDetector(cfg.leakLimits)
+	t.Logf("Baseline resource usage: %s", detector.setBaseline())
+
+	deadline := time.Now().Add(cfg.duration)
+	nextSpawn := time.Now().Add(randDuration(cfg.adversaryInterval))
+	var spawned uint64
+	ticker := time.NewTicker(cfg.sampleInterval)
+	defer ticker.Stop()
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		case <-ticker.C:
+		}
+		now := time.Now()
+		for i, a := range adversaries {
+			if now.After(a.retire) {
+				t.Logf("Stopping adversary %d", i)
+				stop(a)
+				delete(adversaries, i)
+			}
+		}
+		if now.After(nextSpawn) && spawned < cfg.maxAdversaries {
+			advCtx, cancel := context.WithCancel(ctx)
+			a := &adversary{
+				manager: newAdversary(advCtx, spawned),
+				cancel:  cancel,
+				retire:  now.Add(randDuration(cfg.adversaryLifetime)),
+			}
+			t.Logf("Starting adversary %d until %s", spawned, a.retire.Format(time.RFC3339))
+			a.manager.Start(advCtx)
+			adversaries[spawned] = a
+			spawned++
+			nextSpawn = now.Add(randDuration(cfg.adversaryInterval))
+		}
+		t.Logf("Resource usage with %d adversaries: %s", len(adversaries), detector.sample())
+	}
+
+	for i, a := range adversaries {
+		stop(a)
+		delete(adversaries, i)
+	}
+	sleep(cfg.cooldown)
+	if err := detector.check(); err != nil {
+		var buf strings.Builder
+		_ = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		t.Logf("Goroutines at the end of the soak:\n%s", buf.String())
+		t.Fatal(err)
+	}
+	t.Logf("Soak finished after spawning %d adversaries", spawned)
+}
+
+func durationFromEnv(t *testing.T, key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	require.NoError(t, err, key)
+	return d
+}
+
+func uint64FromEnv(t *testing.T, key string, def uint64) uint64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	require.NoError(t, err, key)
+	return n
+}
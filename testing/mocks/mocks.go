@@ -168,6 +168,36 @@ func (m *MockSpecChallengeManager) ChallengePeriodBlocks(ctx context.Context) (u
 	args := m.Called(ctx)
 	return args.Get(0).(uint64), args.Error(1)
 }
+func (m *MockSpecChallengeManager) StakeToken(ctx context.Context) (common.Address, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(common.Address), args.Error(1)
+}
+
+func (m *MockSpecChallengeManager) StakeAmount(ctx context.Context, level protocol.ChallengeLevel) (*big.Int, error) {
+	args := m.Called(ctx, level)
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockSpecChallengeManager) StakeAmounts(ctx context.Context) ([]*big.Int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*big.Int), args.Error(1)
+}
+
+func (m *MockSpecChallengeManager) NoStakeMode(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSpecChallengeManager) SupportsEdgeWithdrawal(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSpecChallengeManager) WithdrawEdge(ctx context.Context, edgeId protocol.EdgeId) (*types.Transaction, error) {
+	args := m.Called(ctx, edgeId)
+	return args.Get(0).(*types.Transaction), args.Error(1)
+}
+
 func (m *MockSpecChallengeManager) MultiUpdateInheritedTimers(ctx context.Context, branch []protocol.ReadOnlyEdge, desiredTimerForLastEdge uint64) (*types.Transaction, error) {
 	args := m.Called(ctx, branch, desiredTimerForLastEdge)
 	return args.Get(0).(*types.Transaction), args.Error(1)
@@ -476,6 +506,16 @@ func (m *MockProtocol) ConfirmAssertionByChallengeWinner(
 	return args.Error(0)
 }
 
+func (m *MockProtocol) StakerAddress() common.Address {
+	args := m.Called()
+	return args.Get(0).(common.Address)
+}
+
+func (m *MockProtocol) ValidatorAllowlist(ctx context.Context) (*protocol.ValidatorAllowlist, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*protocol.ValidatorAllowlist), args.Error(1)
+}
+
 func (m *MockProtocol) IsStaked(ctx context.Context) (bool, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(bool), args.Error(1)
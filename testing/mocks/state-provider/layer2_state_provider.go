@@ -15,20 +15,14 @@ import (
 	protocol "github.com/OffchainLabs/bold/chain-abstraction"
 	l2stateprovider "github.com/OffchainLabs/bold/layer2-state-provider"
 	"github.com/OffchainLabs/bold/state-commitments/history"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
 	challenge_testing "github.com/OffchainLabs/bold/testing"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 )
 
-// Defines the ABI encoding structure for submission of prefix proofs to the protocol contracts
-var (
-	b32Arr, _ = abi.NewType("bytes32[]", "", nil)
-	// ProofArgs for submission to the protocol.
-	ProofArgs = abi.Arguments{
-		{Type: b32Arr, Name: "prefixExpansion"},
-		{Type: b32Arr, Name: "prefixProof"},
-	}
-)
+// ProofArgs defines the ABI encoding structure for submission of prefix proofs to the protocol
+// contracts.
+var ProofArgs = prefixproofs.ProofArgs
 
 // L2StateBackend defines a very naive state manager that is initialized from a list of predetermined
 // state roots. It can produce state and history commitments from those roots.
@@ -238,7 +232,7 @@ func (s *L2StateBackend) ExecutionStateAfterPreviousState(ctx context.Context, m
 			if err != nil {
 				return nil, err
 			}
-			commit, err := history.New(historyCommit)
+			commit, err := history.NewContext(ctx, historyCommit)
 			if err != nil {
 				return nil, err
 			}
@@ -279,7 +279,7 @@ func ChainsWithEdgeChallengeManager(opts ...Opt) (*ChainSetup, error) {
 	}
 	genesisExecutionState := rollupgen.AssertionState{
 		GlobalState:   rollupgen.GlobalState{},
-		MachineStatus: 1,
+		MachineStatus: protocol.MachineStatusFinished.Uint8(),
 	}
 	genesisInboxCount := big.NewInt(0)
 	anyTrustFastConfirmer := common.Address{}
@@ -309,34 +309,9 @@ func ChainsWithEdgeChallengeManager(opts ...Opt) (*ChainSetup, error) {
 		return nil, err
 	}
 
-	chains := make([]*solimpl.AssertionChain, 0)
-	for _, acc := range accs[1:] {
-		var assertionChainBinding *rollupgen.RollupUserLogic
-		assertionChainBinding, err = rollupgen.NewRollupUserLogic(
-			addresses.Rollup, backend,
-		)
-		if err != nil {
-			return nil, err
-		}
-		var challengeManagerAddr common.Address
-		challengeManagerAddr, err = assertionChainBinding.RollupUserLogicCaller.ChallengeManager(
-			&bind.CallOpts{Context: ctx},
-		)
-		if err != nil {
-			return nil, err
-		}
-		chain, chainErr := solimpl.NewAssertionChain(
-			ctx,
-			addresses.Rollup,
-			challengeManagerAddr,
-			acc.TxOpts,
-			backend,
-			solimpl.NewChainBackendTransactor(backend),
-		)
-		if chainErr != nil {
-			return nil, chainErr
-		}
-		chains = append(chains, chain)
+	chains, err := assertionChains(ctx, accs, addresses.Rollup, backend)
+	if err != nil {
+		return nil, err
 	}
 	chalManager, err := chains[1].SpecChallengeManager(ctx)
 	if err != nil {
@@ -400,6 +375,43 @@ func ChainsWithEdgeChallengeManager(opts ...Opt) (*ChainSetup, error) {
 	return setp, nil
 }
 
+// Creates an assertion chain for every account but the first, which deploys the rollup.
+func assertionChains(
+	ctx context.Context,
+	accs []*TestAccount,
+	rollup common.Address,
+	backend *SimulatedBackendWrapper,
+) ([]*solimpl.AssertionChain, error) {
+	chains := make([]*solimpl.AssertionChain, 0, len(accs)-1)
+	for _, acc := range accs[1:] {
+		assertionChainBinding, err := rollupgen.NewRollupUserLogic(
+			rollup, backend,
+		)
+		if err != nil {
+			return nil, err
+		}
+		challengeManagerAddr, err := assertionChainBinding.RollupUserLogicCaller.ChallengeManager(
+			&bind.CallOpts{Context: ctx},
+		)
+		if err != nil {
+			return nil, err
+		}
+		chain, err := solimpl.NewAssertionChain(
+			ctx,
+			rollup,
+			challengeManagerAddr,
+			acc.TxOpts,
+			backend,
+			solimpl.NewChainBackendTransactor(backend),
+		)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
 type RollupAddresses struct {
 	Bridge                 common.Address `json:"bridge"`
 	Inbox                  common.Address `json:"inbox"`
@@ -996,9 +1008,6 @@ type TestAccount struct {
 }
 
 func Accounts(numAccounts uint64) ([]*TestAccount, *SimulatedBackendWrapper, error) {
-	genesis := make(core.GenesisAlloc)
-	gasLimit := uint64(100000000)
-
 	accs := make([]*TestAccount, numAccounts)
 	for i := uint64(0); i < numAccounts; i++ {
 		privKey, err := crypto.GenerateKey()
@@ -1022,16 +1031,24 @@ func Accounts(numAccounts uint64) ([]*TestAccount, *SimulatedBackendWrapper, err
 		if err != nil {
 			return nil, nil, err
 		}
-		startingBalance, _ := new(big.Int).SetString(
-			"100000000000000000000000000000000000000",
-			10,
-		)
-		genesis[addr] = core.GenesisAccount{Balance: startingBalance}
 		accs[i] = &TestAccount{
 			AccountAddr: addr,
 			TxOpts:      txOpts,
 		}
 	}
-	backend := NewSimulatedBackendWrapper(simulated.NewBackend(genesis, simulated.WithBlockGasLimit(gasLimit)))
-	return accs, backend, nil
+	return accs, newSimulatedBackend(accs), nil
+}
+
+// Creates a simulated backend whose genesis funds the given accounts.
+func newSimulatedBackend(accs []*TestAccount) *SimulatedBackendWrapper {
+	genesis := make(core.GenesisAlloc)
+	gasLimit := uint64(100000000)
+	for _, acc := range accs {
+		startingBalance, _ := new(big.Int).SetString(
+			"100000000000000000000000000000000000000",
+			10,
+		)
+		genesis[acc.AccountAddr] = core.GenesisAccount{Balance: startingBalance}
+	}
+	return NewSimulatedBackendWrapper(simulated.NewBackend(genesis, simulated.WithBlockGasLimit(gasLimit)))
 }
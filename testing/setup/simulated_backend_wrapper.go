@@ -27,6 +27,10 @@ func (s *SimulatedBackendWrapper) CodeAt(ctx context.Context, contract common.Ad
 	return s.Client().CodeAt(ctx, contract, blockNumber)
 }
 
+func (s *SimulatedBackendWrapper) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return s.Client().StorageAt(ctx, account, key, blockNumber)
+}
+
 func (s *SimulatedBackendWrapper) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
 	return s.Client().CallContract(ctx, call, blockNumber)
 }
@@ -0,0 +1,148 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package setup
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/OffchainLabs/bold/solgen/go/rollupgen"
+	statemanager "github.com/OffchainLabs/bold/testing/mocks/state-provider"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Snapshot of a chain setup, from which any number of independent copies of the setup can be
+// restored. The simulated backend cannot copy its state, so a snapshot instead records the
+// transactions of every block, which are replayed on restore. Replaying them all at once is much
+// faster than sending them one by one and waiting for each to be mined, as setup does.
+//
+// Blocks are replayed with the current time, so contract storage depending on block timestamps
+// may differ from that of the original setup, while block numbers and addresses are the same.
+type Snapshot struct {
+	accounts         []*TestAccount
+	blocks           []types.Transactions
+	statuses         map[common.Hash]uint64
+	addrs            *RollupAddresses
+	rollupConfig     rollupgen.Config
+	stateManagerOpts []statemanager.Opt
+}
+
+// Snapshot records the chain of the setup up to its latest block. Transactions sent after
+// the snapshot is taken are not part of it, even once mined.
+func (s *ChainSetup) Snapshot(ctx context.Context) (*Snapshot, error) {
+	head, err := s.Backend.Client().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]types.Transactions, head.Number.Uint64())
+	statuses := make(map[common.Hash]uint64)
+	for i := range blocks {
+		block, err := s.Backend.Client().BlockByNumber(ctx, new(big.Int).SetUint64(uint64(i+1)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get block %d", i+1)
+		}
+		blocks[i] = block.Transactions()
+		for _, tx := range blocks[i] {
+			receipt, err := s.Backend.TransactionReceipt(ctx, tx.Hash())
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not get receipt of transaction %#x", tx.Hash())
+			}
+			statuses[tx.Hash()] = receipt.Status
+		}
+	}
+	return &Snapshot{
+		accounts:         s.Accounts,
+		blocks:           blocks,
+		statuses:         statuses,
+		addrs:            s.Addrs,
+		rollupConfig:     s.RollupConfig,
+		stateManagerOpts: s.StateManagerOpts,
+	}, nil
+}
+
+// Restore replays the snapshot on a new simulated backend, returning a setup independent of the
+// one the snapshot was taken from, and of any other restored from it.
+func (snap *Snapshot) Restore(ctx context.Context) (*ChainSetup, error) {
+	// Accounts are copied, as tests may change the options of their transactors.
+	accs := make([]*TestAccount, len(snap.accounts))
+	for i, acc := range snap.accounts {
+		txOpts := *acc.TxOpts
+		accs[i] = &TestAccount{
+			AccountAddr: acc.AccountAddr,
+			TxOpts:      &txOpts,
+		}
+	}
+	backend := newSimulatedBackend(accs)
+	for i, txs := range snap.blocks {
+		for _, tx := range txs {
+			if err := backend.SendTransaction(ctx, tx); err != nil {
+				return nil, errors.Wrapf(err, "could not replay transaction %#x of block %d", tx.Hash(), i+1)
+			}
+		}
+		backend.Commit()
+	}
+	// Transactions which depend on timestamps could behave differently when replayed, which
+	// should fail the restore rather than the tests using it.
+	for txHash, status := range snap.statuses {
+		receipt, err := backend.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get receipt of replayed transaction %#x", txHash)
+		}
+		if receipt.Status != status {
+			return nil, errors.Errorf(
+				"replayed transaction %#x has status %d, but had status %d in the snapshot",
+				txHash, receipt.Status, status,
+			)
+		}
+	}
+	chains, err := assertionChains(ctx, accs, snap.addrs.Rollup, backend)
+	if err != nil {
+		return nil, err
+	}
+	addrs := *snap.addrs
+	return &ChainSetup{
+		Chains:           chains,
+		Accounts:         accs,
+		Addrs:            &addrs,
+		Backend:          backend,
+		RollupConfig:     snap.rollupConfig,
+		StateManagerOpts: append([]statemanager.Opt(nil), snap.stateManagerOpts...),
+	}, nil
+}
+
+// SnapshotOnce returns a function which builds a setup the first time it is called, and from
+// then on restores a fresh copy of it from a snapshot. It is meant to be shared by the tests of a
+// package which all need the same setup, for example:
+//
+//	var defaultSetup = setup.SnapshotOnce(func() (*setup.ChainSetup, error) {
+//		return setup.ChainsWithEdgeChallengeManager(setup.WithMockOneStepProver())
+//	})
+func SnapshotOnce(build func() (*ChainSetup, error)) func() (*ChainSetup, error) {
+	var (
+		once sync.Once
+		snap *Snapshot
+		err  error
+	)
+	return func() (*ChainSetup, error) {
+		ctx := context.Background()
+		once.Do(func() {
+			var s *ChainSetup
+			s, err = build()
+			if err != nil {
+				return
+			}
+			snap, err = s.Snapshot(ctx)
+			if closeErr := s.Backend.Close(); err == nil {
+				err = closeErr
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return snap.Restore(ctx)
+	}
+}
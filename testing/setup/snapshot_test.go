@@ -0,0 +1,56 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package setup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	original, err := ChainsWithEdgeChallengeManager(WithMockOneStepProver())
+	require.NoError(t, err)
+	snap, err := original.Snapshot(ctx)
+	require.NoError(t, err)
+
+	restored, err := snap.Restore(ctx)
+	require.NoError(t, err)
+	require.Equal(t, original.Addrs, restored.Addrs)
+	require.Equal(t, len(original.Chains), len(restored.Chains))
+
+	// The restored chain reads the same rollup state, and is independent of the original.
+	want, err := original.Chains[0].LatestConfirmed(ctx)
+	require.NoError(t, err)
+	got, err := restored.Chains[0].LatestConfirmed(ctx)
+	require.NoError(t, err)
+	require.Equal(t, want.Id(), got.Id())
+
+	restored.Backend.Commit()
+	originalHead, err := original.Backend.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	restoredHead, err := restored.Backend.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, originalHead.Number.Uint64()+1, restoredHead.Number.Uint64())
+
+	restored.Accounts[0].TxOpts.GasLimit = 1
+	require.Zero(t, original.Accounts[0].TxOpts.GasLimit)
+}
+
+func TestSnapshotOnce(t *testing.T) {
+	builds := 0
+	get := SnapshotOnce(func() (*ChainSetup, error) {
+		builds++
+		return ChainsWithEdgeChallengeManager()
+	})
+	first, err := get()
+	require.NoError(t, err)
+	second, err := get()
+	require.NoError(t, err)
+	require.Equal(t, 1, builds)
+	require.Equal(t, first.Addrs, second.Addrs)
+	require.NotSame(t, first.Backend, second.Backend)
+}
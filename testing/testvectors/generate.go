@@ -0,0 +1,177 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package testvectors
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/mocksgen"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	historySizes = []uint64{1, 2, 3, 4, 5, 7, 8, 9, 16, 17, 31, 32, 33}
+	prefixSizes  = [][2]uint64{{1, 2}, {1, 8}, {2, 3}, {3, 7}, {4, 8}, {5, 17}, {8, 16}, {9, 32}, {16, 32}, {31, 32}, {32, 33}}
+	edgeHeights  = [][2]uint64{{0, 1}, {0, 32}, {16, 32}, {31, 32}, {0, 1 << 26}, {1 << 25, 1 << 26}}
+	edgeLevels   = []uint8{0, 1, 2, 3}
+)
+
+// Generate computes the canonical fixtures using the embedded contract code, which is
+// the source of truth for the protocol. The Merkle tree caller is a deployed MerkleTreeAccess
+// contract, and the challenge manager caller is a deployed EdgeChallengeManager.
+func Generate(
+	ctx context.Context,
+	merkleTree *mocksgen.MerkleTreeAccessCaller,
+	challengeManager *challengeV2gen.EdgeChallengeManagerCaller,
+) (*Vectors, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	vectors := &Vectors{
+		HistoryRoots: make([]*HistoryRoot, 0, len(historySizes)),
+		PrefixProofs: make([]*PrefixProof, 0, len(prefixSizes)),
+		EdgeIds:      make([]*EdgeId, 0, len(edgeLevels)*len(edgeHeights)),
+	}
+	for _, size := range historySizes {
+		leaves := Leaves(size)
+		root, err := contractRoot(opts, merkleTree, leaves)
+		if err != nil {
+			return nil, err
+		}
+		vectors.HistoryRoots = append(vectors.HistoryRoots, &HistoryRoot{
+			Leaves: leaves,
+			Root:   root,
+		})
+	}
+	for _, sizes := range prefixSizes {
+		proof, err := prefixProof(opts, merkleTree, sizes[0], sizes[1])
+		if err != nil {
+			return nil, err
+		}
+		vectors.PrefixProofs = append(vectors.PrefixProofs, proof)
+	}
+	for _, level := range edgeLevels {
+		for i, heights := range edgeHeights {
+			edge := &EdgeId{
+				Level:            level,
+				OriginId:         crypto.Keccak256Hash([]byte("origin"), []byte{level, byte(i)}),
+				StartHeight:      heights[0],
+				StartHistoryRoot: crypto.Keccak256Hash([]byte("start"), []byte{level, byte(i)}),
+				EndHeight:        heights[1],
+				EndHistoryRoot:   crypto.Keccak256Hash([]byte("end"), []byte{level, byte(i)}),
+			}
+			mutualId, err := challengeManager.CalculateMutualId(
+				opts,
+				edge.Level,
+				edge.OriginId,
+				new(big.Int).SetUint64(edge.StartHeight),
+				edge.StartHistoryRoot,
+				new(big.Int).SetUint64(edge.EndHeight),
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not calculate mutual id")
+			}
+			id, err := challengeManager.CalculateEdgeId(
+				opts,
+				edge.Level,
+				edge.OriginId,
+				new(big.Int).SetUint64(edge.StartHeight),
+				edge.StartHistoryRoot,
+				new(big.Int).SetUint64(edge.EndHeight),
+				edge.EndHistoryRoot,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not calculate edge id")
+			}
+			edge.MutualId = mutualId
+			edge.Id = id
+			vectors.EdgeIds = append(vectors.EdgeIds, edge)
+		}
+	}
+	return vectors, nil
+}
+
+func contractRoot(opts *bind.CallOpts, merkleTree *mocksgen.MerkleTreeAccessCaller, leaves []common.Hash) (common.Hash, error) {
+	expansion := make([][32]byte, 0)
+	for _, leaf := range leaves {
+		appended, err := merkleTree.AppendLeaf(opts, expansion, leaf)
+		if err != nil {
+			return common.Hash{}, errors.Wrap(err, "could not append leaf")
+		}
+		expansion = appended
+	}
+	root, err := merkleTree.Root(opts, expansion)
+	if err != nil {
+		return common.Hash{}, errors.Wrapf(err, "could not compute root over %d leaves", len(leaves))
+	}
+	return root, nil
+}
+
+// The contracts can only verify prefix proofs, so they are generated in Go and
+// must pass the contract's verification before being included in the fixtures.
+func prefixProof(
+	opts *bind.CallOpts,
+	merkleTree *mocksgen.MerkleTreeAccessCaller,
+	preSize,
+	postSize uint64,
+) (*PrefixProof, error) {
+	leaves := Leaves(postSize)
+	preExpansion, err := prefixproofs.ExpansionFromLeaves(leaves[:preSize])
+	if err != nil {
+		return nil, err
+	}
+	compactProof, err := prefixproofs.GeneratePrefixProof(
+		preSize,
+		preExpansion,
+		leaves[preSize:],
+		prefixproofs.RootFetcherFromExpansion,
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, numRead := prefixproofs.MerkleExpansionFromCompact(compactProof, preSize)
+	proof := compactProof[numRead:]
+
+	preRoot, err := contractRoot(opts, merkleTree, leaves[:preSize])
+	if err != nil {
+		return nil, err
+	}
+	postRoot, err := contractRoot(opts, merkleTree, leaves)
+	if err != nil {
+		return nil, err
+	}
+	if err = merkleTree.VerifyPrefixProof(
+		opts,
+		preRoot,
+		new(big.Int).SetUint64(preSize),
+		postRoot,
+		new(big.Int).SetUint64(postSize),
+		toBytes32(preExpansion),
+		toBytes32(proof),
+	); err != nil {
+		return nil, fmt.Errorf("contract rejected prefix proof from %d to %d leaves: %w", preSize, postSize, err)
+	}
+	return &PrefixProof{
+		Leaves:       leaves,
+		PreSize:      preSize,
+		PostSize:     postSize,
+		PreRoot:      preRoot,
+		PostRoot:     postRoot,
+		PreExpansion: preExpansion,
+		Proof:        proof,
+	}, nil
+}
+
+func toBytes32(hashes []common.Hash) [][32]byte {
+	items := make([][32]byte, len(hashes))
+	for i, hash := range hashes {
+		items[i] = hash
+	}
+	return items
+}
@@ -0,0 +1,83 @@
+// Package testvectors provides canonical fixtures for the commitments and identifiers
+// of the BOLD protocol, generated against the embedded contract code. External
+// implementations of the protocol can check their history roots, prefix proofs,
+// edge ids and mutual ids against these to verify compatibility with this repository.
+//
+// The fixtures are stored in vectors.json, and can be regenerated by running
+//
+//	go test ./testing/testvectors -update
+//
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+package testvectors
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//go:embed vectors.json
+var vectorsJson []byte
+
+// Vectors are the canonical fixtures of the protocol.
+type Vectors struct {
+	HistoryRoots []*HistoryRoot `json:"historyRoots"`
+	PrefixProofs []*PrefixProof `json:"prefixProofs"`
+	EdgeIds      []*EdgeId      `json:"edgeIds"`
+}
+
+// HistoryRoot is the Merkle root of a history commitment over a list of leaves.
+type HistoryRoot struct {
+	Leaves []common.Hash `json:"leaves"`
+	Root   common.Hash   `json:"root"`
+}
+
+// PrefixProof proves that the history over the first PreSize leaves is a prefix of
+// the history over the first PostSize leaves. The proof is in the form expected by
+// the contract's verifyPrefixProof, which takes the expansion of the prefix separately.
+type PrefixProof struct {
+	Leaves       []common.Hash `json:"leaves"`
+	PreSize      uint64        `json:"preSize"`
+	PostSize     uint64        `json:"postSize"`
+	PreRoot      common.Hash   `json:"preRoot"`
+	PostRoot     common.Hash   `json:"postRoot"`
+	PreExpansion []common.Hash `json:"preExpansion"`
+	Proof        []common.Hash `json:"proof"`
+}
+
+// EdgeId is the mutual id and edge id of an edge with the given level, origin id and commitments.
+type EdgeId struct {
+	Level            uint8       `json:"level"`
+	OriginId         common.Hash `json:"originId"`
+	StartHeight      uint64      `json:"startHeight"`
+	StartHistoryRoot common.Hash `json:"startHistoryRoot"`
+	EndHeight        uint64      `json:"endHeight"`
+	EndHistoryRoot   common.Hash `json:"endHistoryRoot"`
+	MutualId         common.Hash `json:"mutualId"`
+	Id               common.Hash `json:"id"`
+}
+
+// Load decodes the canonical fixtures embedded in this package.
+func Load() (*Vectors, error) {
+	vectors := &Vectors{}
+	if err := json.Unmarshal(vectorsJson, vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// Leaves returns the leaves the fixtures commit to, where the leaf at index i is the
+// keccak256 hash of i encoded as a big endian uint64.
+func Leaves(n uint64) []common.Hash {
+	leaves := make([]common.Hash, n)
+	for i := uint64(0); i < n; i++ {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], i)
+		leaves[i] = crypto.Keccak256Hash(buf[:])
+	}
+	return leaves
+}
@@ -0,0 +1,90 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package testvectors_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/OffchainLabs/bold/solgen/go/challengeV2gen"
+	"github.com/OffchainLabs/bold/solgen/go/mocksgen"
+	commitments "github.com/OffchainLabs/bold/state-commitments/history"
+	prefixproofs "github.com/OffchainLabs/bold/state-commitments/prefix-proofs"
+	"github.com/OffchainLabs/bold/testing/setup"
+	"github.com/OffchainLabs/bold/testing/testvectors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "regenerate vectors.json from the contracts")
+
+func TestVectors_MatchContracts(t *testing.T) {
+	ctx := context.Background()
+	accs, backend, err := setup.Accounts(1)
+	require.NoError(t, err)
+	_, _, merkleTree, err := mocksgen.DeployMerkleTreeAccess(accs[0].TxOpts, backend)
+	require.NoError(t, err)
+	_, _, challengeManager, err := challengeV2gen.DeployEdgeChallengeManager(accs[0].TxOpts, backend)
+	require.NoError(t, err)
+	backend.Commit()
+
+	generated, err := testvectors.Generate(ctx, &merkleTree.MerkleTreeAccessCaller, &challengeManager.EdgeChallengeManagerCaller)
+	require.NoError(t, err)
+	if *update {
+		data, err := json.MarshalIndent(generated, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile("vectors.json", append(data, '\n'), 0o644))
+		return
+	}
+	vectors, err := testvectors.Load()
+	require.NoError(t, err)
+	require.Equal(t, generated, vectors, "fixtures are out of date, regenerate them with -update")
+}
+
+func TestVectors_MatchGo(t *testing.T) {
+	vectors, err := testvectors.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, vectors.HistoryRoots)
+	require.NotEmpty(t, vectors.PrefixProofs)
+	require.NotEmpty(t, vectors.EdgeIds)
+
+	for _, v := range vectors.HistoryRoots {
+		commit, err := commitments.New(v.Leaves)
+		require.NoError(t, err)
+		require.Equal(t, v.Root, commit.Merkle, "history root over %d leaves", len(v.Leaves))
+	}
+	for _, v := range vectors.PrefixProofs {
+		require.NoError(t, prefixproofs.VerifyPrefixProof(&prefixproofs.VerifyPrefixProofConfig{
+			PreRoot:      v.PreRoot,
+			PreSize:      v.PreSize,
+			PostRoot:     v.PostRoot,
+			PostSize:     v.PostSize,
+			PreExpansion: v.PreExpansion,
+			PrefixProof:  v.Proof,
+		}), "prefix proof from %d to %d leaves", v.PreSize, v.PostSize)
+	}
+	for _, v := range vectors.EdgeIds {
+		mutualId := protocol.ComputeMutualId(
+			protocol.ChallengeLevel(v.Level),
+			protocol.OriginId(v.OriginId),
+			protocol.Height(v.StartHeight),
+			v.StartHistoryRoot,
+			protocol.Height(v.EndHeight),
+		)
+		require.Equal(t, v.MutualId, common.Hash(mutualId))
+		id := protocol.ComputeEdgeId(
+			protocol.ChallengeLevel(v.Level),
+			protocol.OriginId(v.OriginId),
+			protocol.Height(v.StartHeight),
+			v.StartHistoryRoot,
+			protocol.Height(v.EndHeight),
+			v.EndHistoryRoot,
+		)
+		require.Equal(t, v.Id, id.Hash)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package eventbus defines a typed, in-process event bus on which the modules of the validator
+// publish what they observe and do, such as edges added to challenges, assertions confirmed and
+// transactions mined, so that other modules, and external plugins, can react to them by
+// subscribing rather than by being referenced by the publisher.
+package eventbus
+
+import (
+	"sync"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const defaultSubscriptionBuffer = 256
+
+type EdgeEventKind uint8
+
+const (
+	EdgeAdded EdgeEventKind = iota
+	EdgeConfirmedByTime
+	EdgeConfirmedByOneStepProof
+)
+
+func (k EdgeEventKind) String() string {
+	switch k {
+	case EdgeAdded:
+		return "edge_added"
+	case EdgeConfirmedByTime:
+		return "edge_confirmed_by_time"
+	case EdgeConfirmedByOneStepProof:
+		return "edge_confirmed_by_one_step_proof"
+	default:
+		return "unknown"
+	}
+}
+
+// EdgeEvent is published by the chain watcher for each challenge manager event it processes.
+type EdgeEvent struct {
+	Kind          EdgeEventKind
+	EdgeId        protocol.EdgeId
+	AssertionHash protocol.AssertionHash
+	Level         protocol.ChallengeLevel
+	// Whether the edge is on the royal branch of its challenge, agreeing with our state provider.
+	Royal bool
+	// Identifies the log of the event.
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+}
+
+type AssertionEventKind uint8
+
+const (
+	AssertionPosted AssertionEventKind = iota
+	AssertionConfirmed
+)
+
+func (k AssertionEventKind) String() string {
+	switch k {
+	case AssertionPosted:
+		return "assertion_posted"
+	case AssertionConfirmed:
+		return "assertion_confirmed"
+	default:
+		return "unknown"
+	}
+}
+
+// AssertionEvent is published by the assertion manager for the assertions we post and the
+// assertions confirmed onchain.
+type AssertionEvent struct {
+	Kind          AssertionEventKind
+	AssertionHash protocol.AssertionHash
+	// Whether the assertion agrees with our state provider.
+	Canonical bool
+	// Identifies the log of the event. Posted assertions are identified by their transaction only.
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+}
+
+type TxEventKind uint8
+
+const (
+	TxSubmitted TxEventKind = iota
+	TxMined
+	TxFailed
+	// The transaction stayed unconfirmed for too long and is waiting for manual intervention.
+	TxEscalated
+	// The transaction reverted because the rollup is paused, and is waiting for it to be unpaused
+	// to be sent again.
+	TxWaitingUnpause
+)
+
+func (k TxEventKind) String() string {
+	switch k {
+	case TxSubmitted:
+		return "tx_submitted"
+	case TxMined:
+		return "tx_mined"
+	case TxFailed:
+		return "tx_failed"
+	case TxEscalated:
+		return "tx_escalated"
+	case TxWaitingUnpause:
+		return "tx_waiting_unpause"
+	default:
+		return "unknown"
+	}
+}
+
+// TxEvent is published by the assertion chain over the lifecycle of each transaction it sends.
+type TxEvent struct {
+	Kind   TxEventKind
+	TxHash common.Hash
+	To     common.Address
+	// The block the transaction was mined in, for mined and reverted transactions.
+	BlockNumber uint64
+	Err         error
+}
+
+// Bus carries the events of each kind on its own topic. A nil bus drops all events published on
+// it, so modules can publish without checking whether a bus was configured. It also shares the
+// events decoded from logs between its modules, so that subscribers can look up the decoded log
+// an event identifies with LookupLog rather than decoding it again.
+type Bus struct {
+	Edges      *Topic[EdgeEvent]
+	Assertions *Topic[AssertionEvent]
+	Txs        *Topic[TxEvent]
+	logs       *decodedLogs
+}
+
+func New() *Bus {
+	return &Bus{
+		Edges:      newTopic[EdgeEvent]("edges"),
+		Assertions: newTopic[AssertionEvent]("assertions"),
+		Txs:        newTopic[TxEvent]("txs"),
+		logs:       newDecodedLogs(defaultDecodedLogsCapacity),
+	}
+}
+
+func (b *Bus) PublishEdge(ev EdgeEvent) {
+	if b != nil {
+		b.Edges.Publish(ev)
+	}
+}
+
+func (b *Bus) PublishAssertion(ev AssertionEvent) {
+	if b != nil {
+		b.Assertions.Publish(ev)
+	}
+}
+
+func (b *Bus) PublishTx(ev TxEvent) {
+	if b != nil {
+		b.Txs.Publish(ev)
+	}
+}
+
+// Topic delivers each event published on it to every subscription, in the order published.
+// Publishing never blocks: a subscription whose buffer is full misses the event, which is
+// counted in the topic's dropped events metric, so a slow subscriber cannot stall a publisher.
+type Topic[T any] struct {
+	lock           sync.RWMutex
+	name           string
+	subs           map[*Subscription[T]]struct{}
+	publishedCount metrics.Counter
+	droppedCount   metrics.Counter
+}
+
+func newTopic[T any](name string) *Topic[T] {
+	return &Topic[T]{
+		name:           name,
+		subs:           make(map[*Subscription[T]]struct{}),
+		publishedCount: metrics.GetOrRegisterCounter("arb/validator/eventbus/"+name+"/published", nil),
+		droppedCount:   metrics.GetOrRegisterCounter("arb/validator/eventbus/"+name+"/dropped", nil),
+	}
+}
+
+func (t *Topic[T]) Publish(ev T) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	t.publishedCount.Inc(1)
+	for sub := range t.subs {
+		select {
+		case sub.events <- ev:
+		default:
+			t.droppedCount.Inc(1)
+			log.Warn("Event bus subscriber is not keeping up, dropping event", "topic", t.name, "subscriber", sub.name)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events published from now on, buffering up to 256
+// events the subscriber has yet to receive. The name identifies the subscriber in logs.
+func (t *Topic[T]) Subscribe(name string) *Subscription[T] {
+	return t.SubscribeWithBuffer(name, defaultSubscriptionBuffer)
+}
+
+// SubscribeWithBuffer returns a subscription buffering up to size events.
+func (t *Topic[T]) SubscribeWithBuffer(name string, size int) *Subscription[T] {
+	sub := &Subscription[T]{
+		name:   name,
+		events: make(chan T, size),
+		topic:  t,
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.subs[sub] = struct{}{}
+	return sub
+}
+
+type Subscription[T any] struct {
+	name   string
+	events chan T
+	topic  *Topic[T]
+	once   sync.Once
+}
+
+// Events returns the channel events are delivered on, which is closed once unsubscribed.
+func (s *Subscription[T]) Events() <-chan T {
+	return s.events
+}
+
+func (s *Subscription[T]) Unsubscribe() {
+	s.once.Do(func() {
+		s.topic.lock.Lock()
+		defer s.topic.lock.Unlock()
+		delete(s.topic.subs, s)
+		close(s.events)
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package eventbus
+
+import (
+	"testing"
+
+	protocol "github.com/OffchainLabs/bold/chain-abstraction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopic_DeliversToEverySubscriberInOrder(t *testing.T) {
+	bus := New()
+	first := bus.Edges.Subscribe("first")
+	second := bus.Edges.Subscribe("second")
+	for i := 0; i < 3; i++ {
+		bus.PublishEdge(EdgeEvent{Kind: EdgeAdded, EdgeId: protocol.EdgeId{Hash: common.BytesToHash([]byte{byte(i)})}})
+	}
+	for _, sub := range []*Subscription[EdgeEvent]{first, second} {
+		for i := 0; i < 3; i++ {
+			ev := <-sub.Events()
+			require.Equal(t, common.BytesToHash([]byte{byte(i)}), ev.EdgeId.Hash)
+		}
+	}
+
+	// Events of other kinds are published on their own topics.
+	bus.PublishTx(TxEvent{Kind: TxMined})
+	require.Len(t, first.Events(), 0)
+}
+
+func TestTopic_DropsEventsForSlowSubscribers(t *testing.T) {
+	bus := New()
+	slow := bus.Assertions.SubscribeWithBuffer("slow", 1)
+	fast := bus.Assertions.SubscribeWithBuffer("fast", 2)
+	bus.PublishAssertion(AssertionEvent{Kind: AssertionPosted})
+	bus.PublishAssertion(AssertionEvent{Kind: AssertionConfirmed})
+
+	require.Equal(t, AssertionPosted, (<-slow.Events()).Kind)
+	require.Len(t, slow.Events(), 0)
+	require.Equal(t, AssertionPosted, (<-fast.Events()).Kind)
+	require.Equal(t, AssertionConfirmed, (<-fast.Events()).Kind)
+}
+
+func TestSubscription_Unsubscribe(t *testing.T) {
+	bus := New()
+	sub := bus.Txs.Subscribe("plugin")
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	bus.PublishTx(TxEvent{Kind: TxSubmitted})
+	_, ok := <-sub.Events()
+	require.False(t, ok)
+}
+
+func TestBus_NilDropsEvents(t *testing.T) {
+	var bus *Bus
+	bus.PublishEdge(EdgeEvent{})
+	bus.PublishAssertion(AssertionEvent{})
+	bus.PublishTx(TxEvent{})
+}
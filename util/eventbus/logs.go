@@ -0,0 +1,86 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package eventbus
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const defaultDecodedLogsCapacity = 4096
+
+var (
+	decodedLogHitsCounter   = metrics.NewRegisteredCounter("arb/validator/eventbus/decoded_logs/hits", nil)
+	decodedLogMissesCounter = metrics.NewRegisteredCounter("arb/validator/eventbus/decoded_logs/misses", nil)
+)
+
+// Identifies a log by the transaction that emitted it and its index in the block.
+type logKey struct {
+	txHash   common.Hash
+	logIndex uint
+}
+
+type decodedLog struct {
+	// A reorg can include the same transaction in another block, where it may emit different logs.
+	blockHash common.Hash
+	event     any
+}
+
+// Caches the events decoded from the most recently decoded logs, so that the modules
+// consuming the same log, such as the chain watcher scanning challenge manager events and the
+// challenge manager reading the receipts of the edges it creates, decode it only once.
+type decodedLogs struct {
+	logs *lru.Cache[logKey, decodedLog]
+}
+
+func newDecodedLogs(capacity int) *decodedLogs {
+	return &decodedLogs{logs: lru.NewCache[logKey, decodedLog](capacity)}
+}
+
+// DecodeLog decodes a log with a binding's parse function, such as ParseEdgeAdded, unless an event
+// of the same type was already decoded from it, which is returned instead. Failures to decode are
+// not cached, as parse functions are tried on logs of other events. Without a bus, the log is
+// always decoded.
+func DecodeLog[T any](b *Bus, l types.Log, parse func(types.Log) (T, error)) (T, error) {
+	if event, ok := LookupLog[T](b, l); ok {
+		return event, nil
+	}
+	event, err := parse(l)
+	if err != nil {
+		return event, err
+	}
+	RememberLog(b, l, event)
+	return event, nil
+}
+
+// RememberLog caches an event already decoded from a log, such as by a binding's event iterator,
+// for other consumers of the log.
+func RememberLog[T any](b *Bus, l types.Log, event T) {
+	if b == nil || b.logs == nil {
+		return
+	}
+	b.logs.logs.Add(logKey{txHash: l.TxHash, logIndex: l.Index}, decodedLog{blockHash: l.BlockHash, event: event})
+}
+
+// LookupLog returns the event of a type decoded from a log, if it is cached.
+func LookupLog[T any](b *Bus, l types.Log) (T, bool) {
+	var zero T
+	if b == nil || b.logs == nil {
+		return zero, false
+	}
+	cached, ok := b.logs.logs.Get(logKey{txHash: l.TxHash, logIndex: l.Index})
+	if !ok || cached.blockHash != l.BlockHash {
+		decodedLogMissesCounter.Inc(1)
+		return zero, false
+	}
+	event, ok := cached.event.(T)
+	if !ok {
+		decodedLogMissesCounter.Inc(1)
+		return zero, false
+	}
+	decodedLogHitsCounter.Inc(1)
+	return event, true
+}
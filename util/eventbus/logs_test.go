@@ -0,0 +1,70 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type decodedEvent struct {
+	data string
+}
+
+func TestDecodeLog_DecodesOncePerLog(t *testing.T) {
+	bus := New()
+	decodes := 0
+	parse := func(l types.Log) (*decodedEvent, error) {
+		decodes++
+		if len(l.Data) == 0 {
+			return nil, errors.New("not our event")
+		}
+		return &decodedEvent{data: string(l.Data)}, nil
+	}
+	l := types.Log{TxHash: common.BytesToHash([]byte("tx")), Index: 3, BlockHash: common.BytesToHash([]byte("block")), Data: []byte("foo")}
+
+	first, err := DecodeLog(bus, l, parse)
+	require.NoError(t, err)
+	second, err := DecodeLog(bus, l, parse)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+	require.Equal(t, 1, decodes)
+
+	// Consumers of other event types decode the log themselves.
+	_, ok := LookupLog[string](bus, l)
+	require.False(t, ok)
+
+	// The same transaction reorged into another block is decoded again.
+	reorged := l
+	reorged.BlockHash = common.BytesToHash([]byte("other block"))
+	reorged.Data = []byte("bar")
+	event, err := DecodeLog(bus, reorged, parse)
+	require.NoError(t, err)
+	require.Equal(t, "bar", event.data)
+	require.Equal(t, 2, decodes)
+
+	// Failures are not cached.
+	other := types.Log{TxHash: l.TxHash, Index: 4}
+	for i := 0; i < 2; i++ {
+		_, err = DecodeLog(bus, other, parse)
+		require.Error(t, err)
+	}
+	require.Equal(t, 4, decodes)
+
+	// Events decoded elsewhere, such as by binding iterators, are shared once remembered.
+	RememberLog(bus, other, &decodedEvent{data: "remembered"})
+	event, err = DecodeLog(bus, other, parse)
+	require.NoError(t, err)
+	require.Equal(t, "remembered", event.data)
+	require.Equal(t, 4, decodes)
+
+	// Without a bus, logs are always decoded.
+	_, err = DecodeLog(nil, l, parse)
+	require.NoError(t, err)
+	require.Equal(t, 5, decodes)
+}
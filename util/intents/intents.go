@@ -0,0 +1,256 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package intents journals the assertions and edges the validator intends to post, so that any
+// posted from its address which it did not intend can be detected. Those are a sign of split
+// brain, such as a misconfigured second instance running with the same staker key, which may post
+// conflicting claims and lose the stake. Once detected, further posting is paused until an
+// operator resumes it.
+package intents
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrPaused is returned when posting while paused after detecting split brain.
+	ErrPaused = errors.New("posting paused after detecting assertions or edges from our address we did not post")
+
+	splitBrainGauge   = metrics.NewRegisteredGauge("arb/validator/intents/split_brain", nil)
+	unintendedCounter = metrics.NewRegisteredCounter("arb/validator/intents/unintended", nil)
+)
+
+type kind string
+
+const (
+	kindStart     kind = "start"
+	kindAssertion kind = "assertion"
+	kindEdge      kind = "edge"
+)
+
+// A single entry in the append-only journal.
+type record struct {
+	Kind  kind        `json:"kind"`
+	Hash  common.Hash `json:"hash,omitempty"`
+	Block uint64      `json:"block,omitempty"`
+}
+
+// Journal durably records the hashes of the assertions and ids of the edges the validator intends
+// to post before posting them, and checks those observed onchain from its address against them.
+// A nil journal records nothing and never detects split brain.
+type Journal struct {
+	lock       sync.Mutex
+	file       *os.File
+	startBlock uint64
+	recorded   map[kind]map[common.Hash]bool
+	// The claims from our address we did not intend, which posting is paused for.
+	unintended []record
+}
+
+// NewJournal opens the journal at the given path, creating it if it does not exist yet. Only
+// assertions and edges observed from the start block on are checked, which is recorded when the
+// journal is created, as the validator may have posted earlier ones without journaling them.
+func NewJournal(path string, startBlock uint64) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open intent journal")
+	}
+	j := &Journal{
+		file: f,
+		recorded: map[kind]map[common.Hash]bool{
+			kindAssertion: make(map[common.Hash]bool),
+			kindEdge:      make(map[common.Hash]bool),
+		},
+	}
+	started, err := j.replay()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if !started {
+		j.startBlock = startBlock
+		if err := j.append(record{Kind: kindStart, Block: startBlock}); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// Loads the records of the journal, ignoring a partially written record at its end, which is left
+// behind if the process crashed while appending to it.
+func (j *Journal) replay() (bool, error) {
+	if _, err := j.file.Seek(0, 0); err != nil {
+		return false, errors.Wrap(err, "could not read intent journal")
+	}
+	started := false
+	scanner := bufio.NewScanner(j.file)
+	for scanner.Scan() {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			log.Warn("Ignoring malformed intent journal record", "err", err)
+			continue
+		}
+		if r.Kind == kindStart {
+			j.startBlock = r.Block
+			started = true
+		} else if recorded, ok := j.recorded[r.Kind]; ok {
+			recorded[r.Hash] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, errors.Wrap(err, "could not read intent journal")
+	}
+	// Terminates a partially written record, so that the next record starts on its own line.
+	info, err := j.file.Stat()
+	if err != nil {
+		return false, errors.Wrap(err, "could not read intent journal")
+	}
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := j.file.ReadAt(last, info.Size()-1); err != nil {
+			return false, errors.Wrap(err, "could not read intent journal")
+		}
+		if last[0] != '\n' {
+			if _, err := j.file.Write([]byte{'\n'}); err != nil {
+				return false, errors.Wrap(err, "could not write to intent journal")
+			}
+		}
+	}
+	return started, nil
+}
+
+func (j *Journal) append(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err, "could not write to intent journal")
+	}
+	return j.file.Sync()
+}
+
+// RecordAssertion durably records that we intend to post an assertion before returning, or returns
+// an error wrapping ErrPaused if posting is paused.
+func (j *Journal) RecordAssertion(hash common.Hash) error {
+	return j.record(kindAssertion, hash)
+}
+
+// RecordEdge durably records that we intend to post a level zero edge before returning, or returns
+// an error wrapping ErrPaused if posting is paused.
+func (j *Journal) RecordEdge(id common.Hash) error {
+	return j.record(kindEdge, id)
+}
+
+func (j *Journal) record(k kind, hash common.Hash) error {
+	if j == nil {
+		return nil
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if err := j.checkNotPaused(); err != nil {
+		return err
+	}
+	if j.recorded[k][hash] {
+		return nil
+	}
+	if err := j.append(record{Kind: k, Hash: hash}); err != nil {
+		return err
+	}
+	j.recorded[k][hash] = true
+	return nil
+}
+
+// CheckNotPaused returns an error wrapping ErrPaused if posting is paused, for actions which are not
+// journaled, such as bisections.
+func (j *Journal) CheckNotPaused() error {
+	if j == nil {
+		return nil
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.checkNotPaused()
+}
+
+func (j *Journal) checkNotPaused() error {
+	if len(j.unintended) > 0 {
+		first := j.unintended[0]
+		return errors.Wrapf(ErrPaused, "%s %#x at block %d was not posted by us", first.Kind, first.Hash, first.Block)
+	}
+	return nil
+}
+
+// ObserveAssertion checks an assertion observed onchain from our address against the journal,
+// pausing posting and returning false if we did not intend to post it, which callers alert on.
+func (j *Journal) ObserveAssertion(hash common.Hash, block uint64) bool {
+	return j.observe(kindAssertion, hash, block)
+}
+
+// ObserveEdge checks a level zero edge observed onchain from our address against the journal,
+// pausing posting and returning false if we did not intend to post it.
+func (j *Journal) ObserveEdge(id common.Hash, block uint64) bool {
+	return j.observe(kindEdge, id, block)
+}
+
+func (j *Journal) observe(k kind, hash common.Hash, block uint64) bool {
+	if j == nil {
+		return true
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if block < j.startBlock || j.recorded[k][hash] {
+		return true
+	}
+	for _, r := range j.unintended {
+		if r.Kind == k && r.Hash == hash {
+			return false
+		}
+	}
+	unintendedCounter.Inc(1)
+	splitBrainGauge.Update(1)
+	j.unintended = append(j.unintended, record{Kind: k, Hash: hash, Block: block})
+	return false
+}
+
+// Paused returns whether posting is paused after detecting split brain.
+func (j *Journal) Paused() bool {
+	return j.CheckNotPaused() != nil
+}
+
+// Resume resumes posting once an operator has made sure a single instance posts with our key.
+// The claims posting was paused for are recorded as intended, so that they do not pause posting
+// again when observed after a restart.
+func (j *Journal) Resume() error {
+	if j == nil {
+		return nil
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	for len(j.unintended) > 0 {
+		r := j.unintended[0]
+		if err := j.append(record{Kind: r.Kind, Hash: r.Hash}); err != nil {
+			return err
+		}
+		j.recorded[r.Kind][r.Hash] = true
+		j.unintended = j.unintended[1:]
+		log.Warn("Resuming posting after split brain", "kind", r.Kind, "hash", r.Hash, "block", r.Block)
+	}
+	splitBrainGauge.Update(0)
+	return nil
+}
+
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
@@ -0,0 +1,80 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package intents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_DetectsSplitBrain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intents.journal")
+	j, err := NewJournal(path, 100)
+	require.NoError(t, err)
+	ours := common.BytesToHash([]byte("ours"))
+	theirs := common.BytesToHash([]byte("theirs"))
+	require.NoError(t, j.RecordAssertion(ours))
+	require.NoError(t, j.RecordEdge(ours))
+
+	require.True(t, j.ObserveAssertion(ours, 101))
+	require.True(t, j.ObserveEdge(ours, 101))
+	// Claims from before the journal was created are not checked.
+	require.True(t, j.ObserveAssertion(theirs, 99))
+	require.False(t, j.Paused())
+
+	require.False(t, j.ObserveEdge(theirs, 102))
+	require.True(t, j.Paused())
+	require.ErrorIs(t, j.RecordAssertion(common.Hash{}), ErrPaused)
+	require.ErrorIs(t, j.CheckNotPaused(), ErrPaused)
+
+	require.NoError(t, j.Resume())
+	require.False(t, j.Paused())
+	require.NoError(t, j.Close())
+
+	// Intents, the acknowledged unintended edge, and the start block survive a restart.
+	j, err = NewJournal(path, 200)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, j.Close()) }()
+	require.True(t, j.ObserveAssertion(ours, 150))
+	require.True(t, j.ObserveEdge(theirs, 150))
+	require.False(t, j.ObserveAssertion(theirs, 150))
+}
+
+func TestJournal_IgnoresPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intents.journal")
+	j, err := NewJournal(path, 0)
+	require.NoError(t, err)
+	hash := common.BytesToHash([]byte("ours"))
+	require.NoError(t, j.RecordAssertion(hash))
+	require.NoError(t, j.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"kind":"edge","ha`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	j, err = NewJournal(path, 0)
+	require.NoError(t, err)
+	edge := common.BytesToHash([]byte("edge"))
+	require.NoError(t, j.RecordEdge(edge))
+	require.NoError(t, j.Close())
+
+	j, err = NewJournal(path, 0)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, j.Close()) }()
+	require.True(t, j.ObserveAssertion(hash, 1))
+	require.True(t, j.ObserveEdge(edge, 1))
+}
+
+func TestJournal_Nil(t *testing.T) {
+	var j *Journal
+	require.NoError(t, j.RecordAssertion(common.Hash{}))
+	require.True(t, j.ObserveEdge(common.Hash{}, 1))
+	require.False(t, j.Paused())
+}
@@ -0,0 +1,143 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGCSEndpoint = "https://storage.googleapis.com"
+	// The metadata server of the GCE or GKE instance, which hands out tokens for its service account.
+	metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	// Tokens are refreshed this long before they expire.
+	tokenExpiryMargin = time.Minute
+)
+
+// TokenSource returns an OAuth2 access token to authenticate requests to GCS with.
+type TokenSource func(ctx context.Context) (string, error)
+
+// StaticToken always returns the same token.
+func StaticToken(token string) TokenSource {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// MetadataTokenSource fetches the tokens of the service account of the instance the process runs
+// on from its metadata server, as on GKE with workload identity, and caches them until they are
+// about to expire.
+func MetadataTokenSource(httpClient *http.Client) TokenSource {
+	var lock sync.Mutex
+	var token string
+	var expiry time.Time
+	return func(ctx context.Context) (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if token != "" && time.Now().Before(expiry) {
+			return token, nil
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		body, err := (&client{httpClient: httpClient}).do(req)
+		if err != nil {
+			return "", fmt.Errorf("could not fetch token from the metadata server: %w", err)
+		}
+		var resp struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int64  `json:"expires_in"`
+		}
+		if err = json.Unmarshal(body, &resp); err != nil {
+			return "", fmt.Errorf("could not decode token from the metadata server: %w", err)
+		}
+		if resp.AccessToken == "" {
+			return "", errors.New("metadata server returned no token")
+		}
+		token = resp.AccessToken
+		expiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - tokenExpiryMargin)
+		return token, nil
+	}
+}
+
+// GCSConfig locates a GCS bucket and the tokens to access it with.
+type GCSConfig struct {
+	// The URL of the GCS XML API. Defaults to https://storage.googleapis.com.
+	Endpoint string
+	Bucket   string
+	// Prepended to the names of objects, to share a bucket between validators.
+	Prefix      string
+	TokenSource TokenSource
+}
+
+// GCSBucket stores objects in a GCS bucket through its XML API.
+type GCSBucket struct {
+	*client
+	cfg  GCSConfig
+	base string
+}
+
+func NewGCSBucket(cfg GCSConfig, opts ...Opt) (*GCSBucket, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("no GCS bucket given")
+	}
+	if cfg.TokenSource == nil {
+		return nil, errors.New("no GCS token source given")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGCSEndpoint
+	}
+	return &GCSBucket{
+		client: newClient(opts),
+		cfg:    cfg,
+		base:   strings.TrimSuffix(endpoint, "/") + "/" + uriEncode(cfg.Bucket) + "/",
+	}, nil
+}
+
+func (b *GCSBucket) Get(ctx context.Context, name string) ([]byte, error) {
+	return b.request(ctx, http.MethodGet, name, nil)
+}
+
+func (b *GCSBucket) Put(ctx context.Context, name string, data []byte) error {
+	_, err := b.request(ctx, http.MethodPut, name, data)
+	return err
+}
+
+func (b *GCSBucket) Delete(ctx context.Context, name string) error {
+	_, err := b.request(ctx, http.MethodDelete, name, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (b *GCSBucket) request(ctx context.Context, method string, name string, data []byte) ([]byte, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	token, err := b.cfg.TokenSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.base+escapeName(b.cfg.Prefix+name), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.ContentLength = int64(len(data))
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return b.do(req)
+}
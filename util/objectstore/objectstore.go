@@ -0,0 +1,242 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package objectstore stores named objects in buckets, such as the history commitment artifacts of
+// a validator, on the local disk or in object storage. Object storage lets stateless deployments,
+// whose local disk is lost when they are rescheduled, resume challenges without recomputing their
+// artifacts, and a CachedBucket keeps a local copy of the objects to avoid reading them remotely.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	cacheHitCounter  = metrics.NewRegisteredCounter("arb/validator/objectstore/cache_hit", nil)
+	cacheMissCounter = metrics.NewRegisteredCounter("arb/validator/objectstore/cache_miss", nil)
+)
+
+// ErrNotFound is returned when reading an object which does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Bucket stores objects by name. Names are relative slash separated paths.
+type Bucket interface {
+	// Get reads an object, returning ErrNotFound if it does not exist.
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Put creates or replaces an object.
+	Put(ctx context.Context, name string, data []byte) error
+	// Delete removes an object, succeeding if it does not exist.
+	Delete(ctx context.Context, name string) error
+}
+
+type Opt func(*client)
+
+// The options shared by the object storage buckets.
+type client struct {
+	httpClient *http.Client
+}
+
+// WithHTTPClient sets the client object storage is accessed with.
+func WithHTTPClient(httpClient *http.Client) Opt {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+func newClient(opts []Opt) *client {
+	c := &client{httpClient: http.DefaultClient}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func validateName(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") {
+		return fmt.Errorf("invalid object name %q", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid object name %q", name)
+		}
+	}
+	return nil
+}
+
+// DirBucket stores each object as a file in a directory.
+type DirBucket struct {
+	dir string
+}
+
+func NewDirBucket(dir string) (*DirBucket, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create bucket directory %s: %w", dir, err)
+	}
+	return &DirBucket{dir: dir}, nil
+}
+
+func (b *DirBucket) path(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(b.dir, filepath.FromSlash(name)), nil
+}
+
+func (b *DirBucket) Get(_ context.Context, name string) ([]byte, error) {
+	path, err := b.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put writes the object to a temporary file before renaming it, so a crash never leaves a
+// partially written object behind.
+func (b *DirBucket) Put(_ context.Context, name string, data []byte) error {
+	path, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (b *DirBucket) Delete(_ context.Context, name string) error {
+	path, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// CachedBucket writes objects through to a remote bucket and a local cache of it, and reads them
+// from the cache when it holds them. The remote bucket is the source of truth: writes only succeed
+// once they are remote, and objects missing from the cache, as after a reschedule, are read remotely.
+type CachedBucket struct {
+	remote Bucket
+	cache  *DirBucket
+}
+
+// NewCachedBucket caches the objects of a remote bucket in a local directory. The cache must not be
+// shared with another remote bucket.
+func NewCachedBucket(remote Bucket, cacheDir string) (*CachedBucket, error) {
+	cache, err := NewDirBucket(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return &CachedBucket{remote: remote, cache: cache}, nil
+}
+
+func (b *CachedBucket) Get(ctx context.Context, name string) ([]byte, error) {
+	data, err := b.cache.Get(ctx, name)
+	if err == nil {
+		cacheHitCounter.Inc(1)
+		return data, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		log.Warn("Could not read cached object", "name", name, "err", err)
+	}
+	cacheMissCounter.Inc(1)
+	data, err = b.remote.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err = b.cache.Put(ctx, name, data); err != nil {
+		log.Warn("Could not cache object", "name", name, "err", err)
+	}
+	return data, nil
+}
+
+func (b *CachedBucket) Put(ctx context.Context, name string, data []byte) error {
+	if err := b.remote.Put(ctx, name, data); err != nil {
+		return err
+	}
+	if err := b.cache.Put(ctx, name, data); err != nil {
+		// A stale cached object would be read instead of the one just written.
+		log.Warn("Could not cache object", "name", name, "err", err)
+		if err = b.cache.Delete(ctx, name); err != nil {
+			return fmt.Errorf("could not evict stale cached object %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (b *CachedBucket) Delete(ctx context.Context, name string) error {
+	if err := b.remote.Delete(ctx, name); err != nil {
+		return err
+	}
+	return b.cache.Delete(ctx, name)
+}
+
+// The size of error responses included in errors.
+const maxErrorBodySize = 1 << 10
+
+// Sends a request to object storage, returning the body of a successful response and ErrNotFound
+// for a missing object.
+func (c *client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		if len(body) > maxErrorBodySize {
+			body = body[:maxErrorBodySize]
+		}
+		return nil, fmt.Errorf("%s %s returned status %d: %s", req.Method, req.URL.Redacted(), resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// Escapes each segment of an object name for use in a URL path.
+func escapeName(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = uriEncode(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// Percent encodes all but the unreserved characters of RFC 3986, as object storage signatures expect.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
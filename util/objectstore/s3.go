@@ -0,0 +1,174 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	scopeDateFormat = "20060102"
+)
+
+// S3Config locates an S3 bucket and the credentials to access it with.
+type S3Config struct {
+	// The URL of the S3 API, such as that of an S3 compatible store. Buckets are addressed by path.
+	// Defaults to the AWS endpoint of the region.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prepended to the names of objects, to share a bucket between validators.
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// The token of temporary credentials, if any.
+	SessionToken string
+}
+
+// S3Bucket stores objects in an S3 bucket, signing its requests with AWS signature version 4.
+type S3Bucket struct {
+	*client
+	cfg  S3Config
+	now  func() time.Time
+	base string
+}
+
+func NewS3Bucket(cfg S3Config, opts ...Opt) (*S3Bucket, error) {
+	if cfg.Region == "" {
+		return nil, errors.New("no S3 region given")
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("no S3 bucket given")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("no S3 credentials given")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	return &S3Bucket{
+		client: newClient(opts),
+		cfg:    cfg,
+		now:    time.Now,
+		base:   strings.TrimSuffix(endpoint, "/") + "/" + uriEncode(cfg.Bucket) + "/",
+	}, nil
+}
+
+func (b *S3Bucket) Get(ctx context.Context, name string) ([]byte, error) {
+	return b.request(ctx, http.MethodGet, name, nil)
+}
+
+func (b *S3Bucket) Put(ctx context.Context, name string, data []byte) error {
+	_, err := b.request(ctx, http.MethodPut, name, data)
+	return err
+}
+
+// Delete succeeds for missing objects, as S3 does.
+func (b *S3Bucket) Delete(ctx context.Context, name string) error {
+	_, err := b.request(ctx, http.MethodDelete, name, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (b *S3Bucket) request(ctx context.Context, method string, name string, data []byte) ([]byte, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.base+escapeName(b.cfg.Prefix+name), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.ContentLength = int64(len(data))
+	}
+	payloadHash := sha256.Sum256(data)
+	if b.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.cfg.SessionToken)
+	}
+	signV4(req, hex.EncodeToString(payloadHash[:]), b.cfg.Region, b.cfg.AccessKeyID, b.cfg.SecretAccessKey, b.now())
+	return b.do(req)
+}
+
+// Signs a request with AWS signature version 4, over its host and every header it has.
+func signV4(req *http.Request, payloadHash string, region string, accessKeyID string, secretAccessKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	scopeDate := now.Format(scopeDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join([]string{scopeDate, region, s3Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), scopeDate)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", s3Algorithm, accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
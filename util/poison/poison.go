@@ -0,0 +1,267 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package poison keeps a durable flag set when an edge of ours loses its challenge by one step
+// proof. Our edges commit to the history computed by our state provider, so the one step proof of a
+// rival shows that history to be wrong: the state provider is faulty, and nothing it computes can
+// be trusted. Once poisoned, all posting is frozen, including after restarts, until an operator
+// clears the flag once the state provider is fixed.
+package poison
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrPoisoned is returned when posting while frozen after an edge of ours lost by one step proof.
+	ErrPoisoned = errors.New("posting frozen since an edge of ours lost by one step proof")
+
+	poisonedGauge        = metrics.NewRegisteredGauge("arb/validator/poison/poisoned", nil)
+	bundleFailureCounter = metrics.NewRegisteredCounter("arb/validator/poison/bundle_failures", nil)
+)
+
+// Incident describes the loss which poisoned the flag.
+type Incident struct {
+	// The challenge, by the hash of the assertion whose children are challenged.
+	ChallengedAssertionHash common.Hash `json:"challengedAssertionHash"`
+	LostEdgeId              common.Hash `json:"lostEdgeId"`
+	WinningEdgeId           common.Hash `json:"winningEdgeId"`
+	ChallengeLevel          uint8       `json:"challengeLevel"`
+	// The block the winning edge was confirmed at.
+	BlockNumber uint64    `json:"blockNumber"`
+	DetectedAt  time.Time `json:"detectedAt"`
+	// The directory the diagnostic bundle was captured to, if any.
+	Bundle string `json:"bundle,omitempty"`
+}
+
+// BundleSource writes a file of the diagnostic bundle, such as an archive of the artifacts of the
+// challenge.
+type BundleSource func(ctx context.Context, incident *Incident, w io.Writer) error
+
+// AlertHandler is called once when the flag is poisoned, such as to page an operator. It is called
+// synchronously, so must not block.
+type AlertHandler func(incident *Incident)
+
+type Opt func(f *Flag)
+
+// WithBundleDir captures a diagnostic bundle to a new directory under dir when the flag is
+// poisoned. Defaults to empty, which captures none.
+func WithBundleDir(dir string) Opt {
+	return func(f *Flag) {
+		f.bundleDir = dir
+	}
+}
+
+// WithAlertHandler calls a handler when the flag is poisoned, after the bundle is captured.
+func WithAlertHandler(handler AlertHandler) Opt {
+	return func(f *Flag) {
+		f.handlers = append(f.handlers, handler)
+	}
+}
+
+type namedSource struct {
+	name   string
+	source BundleSource
+}
+
+// Flag is the poisoned flag, stored in a file which exists only while the flag is set. A nil flag
+// is never poisoned.
+type Flag struct {
+	lock      sync.Mutex
+	path      string
+	bundleDir string
+	sources   []namedSource
+	handlers  []AlertHandler
+	incident  *Incident
+}
+
+// Open reads the flag stored at a path. If the flag is poisoned, posting stays frozen from startup.
+func Open(path string, opts ...Opt) (*Flag, error) {
+	f := &Flag{path: path}
+	for _, o := range opts {
+		o(f)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		poisonedGauge.Update(0)
+		return f, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read poisoned flag")
+	}
+	incident := &Incident{}
+	if err = json.Unmarshal(data, incident); err != nil {
+		return nil, errors.Wrapf(err, "could not decode poisoned flag at %s, remove it once the state provider is fixed", path)
+	}
+	f.incident = incident
+	poisonedGauge.Update(1)
+	log.Error(
+		"Poisoned flag is set, an edge of ours lost by one step proof. Posting is frozen until an operator clears the flag",
+		"lostEdgeId", incident.LostEdgeId,
+		"challengedAssertionHash", incident.ChallengedAssertionHash,
+		"detectedAt", incident.DetectedAt,
+		"bundle", incident.Bundle,
+	)
+	return f, nil
+}
+
+// AddBundleSource adds a file to the diagnostic bundle, written by a source when it is captured.
+func (f *Flag) AddBundleSource(name string, source BundleSource) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sources = append(f.sources, namedSource{name: name, source: source})
+}
+
+// Poison durably sets the flag, freezing posting, then captures the diagnostic bundle with the
+// incident and its details, and alerts. A flag already poisoned keeps its first incident.
+func (f *Flag) Poison(ctx context.Context, incident *Incident, details map[string]any) error {
+	if f == nil {
+		return nil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.incident != nil {
+		return nil
+	}
+	if incident.DetectedAt.IsZero() {
+		incident.DetectedAt = time.Now().UTC()
+	}
+	// The flag is stored before anything else, so that posting stays frozen if the process stops
+	// while capturing the bundle.
+	if err := f.store(incident); err != nil {
+		return err
+	}
+	f.incident = incident
+	poisonedGauge.Update(1)
+	if f.bundleDir != "" {
+		dir, err := f.captureBundle(ctx, incident, details)
+		if err != nil {
+			bundleFailureCounter.Inc(1)
+			log.Error("Could not capture diagnostic bundle of one step proof loss", "dir", dir, "err", err)
+		}
+		if dir != "" {
+			incident.Bundle = dir
+			if err = f.store(incident); err != nil {
+				log.Error("Could not record diagnostic bundle in poisoned flag", "err", err)
+			}
+		}
+	}
+	for _, handler := range f.handlers {
+		handler(incident)
+	}
+	return nil
+}
+
+func (f *Flag) store(incident *Incident) error {
+	data, err := json.MarshalIndent(incident, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return errors.Wrap(err, "could not write poisoned flag")
+	}
+	if err = os.Rename(tmp, f.path); err != nil {
+		return errors.Wrap(err, "could not write poisoned flag")
+	}
+	return nil
+}
+
+// Captures the bundle to a new directory, returning it even if a source failed, as the rest of the
+// bundle is still worth keeping.
+func (f *Flag) captureBundle(ctx context.Context, incident *Incident, details map[string]any) (string, error) {
+	dir := filepath.Join(f.bundleDir, fmt.Sprintf("poisoned-%s-%s", incident.DetectedAt.Format("20060102T150405Z"), incident.LostEdgeId.Hex()[2:10]))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	writeJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+	}
+	if err := writeJSON("incident.json", incident); err != nil {
+		return dir, err
+	}
+	if err := writeJSON("details.json", details); err != nil {
+		return dir, err
+	}
+	var failed error
+	for _, s := range f.sources {
+		if err := writeSource(ctx, filepath.Join(dir, filepath.Base(s.name)), incident, s.source); err != nil {
+			log.Error("Could not write diagnostic bundle file", "name", s.name, "err", err)
+			failed = errors.Wrapf(err, "could not write %s", s.name)
+		}
+	}
+	return dir, failed
+}
+
+func writeSource(ctx context.Context, path string, incident *Incident, source BundleSource) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = source(ctx, incident, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Incident returns the incident which poisoned the flag, or nil if it is not poisoned.
+func (f *Flag) Incident() *Incident {
+	if f == nil {
+		return nil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.incident == nil {
+		return nil
+	}
+	incident := *f.incident
+	return &incident
+}
+
+// CheckNotPoisoned returns an error wrapping ErrPoisoned if the flag is poisoned.
+func (f *Flag) CheckNotPoisoned() error {
+	incident := f.Incident()
+	if incident == nil {
+		return nil
+	}
+	return errors.Wrapf(ErrPoisoned, "edge %#x lost to %#x in the challenge on assertion %#x", incident.LostEdgeId, incident.WinningEdgeId, incident.ChallengedAssertionHash)
+}
+
+// Clear clears the flag once an operator has fixed the state provider, resuming posting.
+func (f *Flag) Clear() error {
+	if f == nil {
+		return nil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.incident == nil {
+		return nil
+	}
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "could not remove poisoned flag")
+	}
+	log.Warn("Cleared poisoned flag, resuming posting", "lostEdgeId", f.incident.LostEdgeId, "detectedAt", f.incident.DetectedAt)
+	f.incident = nil
+	poisonedGauge.Update(0)
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package poison
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlag(t *testing.T) {
+	ctx := context.Background()
+	var nilFlag *Flag
+	require.NoError(t, nilFlag.Poison(ctx, &Incident{}, nil))
+	require.NoError(t, nilFlag.CheckNotPoisoned())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "poisoned.json")
+	bundleDir := filepath.Join(dir, "bundles")
+	var alerted []*Incident
+	flag, err := Open(path, WithBundleDir(bundleDir), WithAlertHandler(func(incident *Incident) {
+		alerted = append(alerted, incident)
+	}))
+	require.NoError(t, err)
+	require.NoError(t, flag.CheckNotPoisoned())
+	flag.AddBundleSource("artifacts.tar.gz", func(_ context.Context, incident *Incident, w io.Writer) error {
+		_, err := w.Write(incident.ChallengedAssertionHash.Bytes())
+		return err
+	})
+	flag.AddBundleSource("broken.txt", func(context.Context, *Incident, io.Writer) error {
+		return errors.New("broken")
+	})
+
+	incident := &Incident{
+		ChallengedAssertionHash: common.Hash{1},
+		LostEdgeId:              common.Hash{2},
+		WinningEdgeId:           common.Hash{3},
+	}
+	require.NoError(t, flag.Poison(ctx, incident, map[string]any{"lostEdge": "details"}))
+	require.ErrorIs(t, flag.CheckNotPoisoned(), ErrPoisoned)
+	require.Len(t, alerted, 1)
+
+	// A failing source does not keep the rest of the bundle from being captured.
+	bundle := flag.Incident().Bundle
+	require.NotEmpty(t, bundle)
+	for _, name := range []string{"incident.json", "details.json"} {
+		_, err = os.Stat(filepath.Join(bundle, name))
+		require.NoError(t, err)
+	}
+	archive, err := os.ReadFile(filepath.Join(bundle, "artifacts.tar.gz"))
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{1}.Bytes(), archive)
+
+	// Only the first incident is kept.
+	require.NoError(t, flag.Poison(ctx, &Incident{LostEdgeId: common.Hash{4}}, nil))
+	require.Equal(t, common.Hash{2}, flag.Incident().LostEdgeId)
+	require.Len(t, alerted, 1)
+
+	// The flag stays poisoned after a restart until cleared.
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	require.ErrorIs(t, reopened.CheckNotPoisoned(), ErrPoisoned)
+	require.Equal(t, bundle, reopened.Incident().Bundle)
+	require.NoError(t, reopened.Clear())
+	require.NoError(t, reopened.CheckNotPoisoned())
+	reopened, err = Open(path)
+	require.NoError(t, err)
+	require.Nil(t, reopened.Incident())
+}
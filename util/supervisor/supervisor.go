@@ -0,0 +1,182 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+// Package supervisor runs long-lived components of the validator, such as edge trackers and the
+// chain watcher, so that a panic in one of them is recovered and the component restarted under a
+// restart policy instead of taking down the whole process.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	defaultMaxRestarts     = 5
+	defaultInitialBackoff  = time.Second
+	defaultMaxBackoff      = time.Minute
+	defaultMaxPanicRecords = 32
+)
+
+var panicsCounter = metrics.NewRegisteredCounter("arb/validator/supervisor/panics", nil)
+
+// PanicRecord describes a panic recovered from a supervised component, kept for inclusion in
+// support bundles.
+type PanicRecord struct {
+	Component string    `json:"component"`
+	Value     string    `json:"value"`
+	Stack     string    `json:"stack"`
+	Time      time.Time `json:"time"`
+	Restarted bool      `json:"restarted"`
+}
+
+// Supervisor recovers panics of the components it runs and restarts them with an exponential
+// backoff, up to a maximum number of restarts per run of a component. A component which returns
+// without panicking is not restarted.
+type Supervisor struct {
+	maxRestarts     int
+	initialBackoff  time.Duration
+	maxBackoff      time.Duration
+	maxPanicRecords int
+	lock            sync.Mutex
+	restarts        map[string]uint64
+	panics          []PanicRecord
+}
+
+type Opt func(*Supervisor)
+
+// WithMaxRestarts sets how many times a component is restarted after panicking before it is given
+// up on. Defaults to 5. Zero never restarts components, though their panics are still recovered.
+func WithMaxRestarts(n int) Opt {
+	return func(s *Supervisor) {
+		s.maxRestarts = n
+	}
+}
+
+// WithBackoff sets the wait before the first restart of a component, which doubles on each
+// further restart up to maxBackoff. Defaults to one second, doubling up to a minute.
+func WithBackoff(initial, maxBackoff time.Duration) Opt {
+	return func(s *Supervisor) {
+		s.initialBackoff = initial
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// WithMaxPanicRecords sets how many of the latest panics are kept. Defaults to 32.
+func WithMaxPanicRecords(n int) Opt {
+	return func(s *Supervisor) {
+		s.maxPanicRecords = n
+	}
+}
+
+func New(opts ...Opt) *Supervisor {
+	s := &Supervisor{
+		maxRestarts:     defaultMaxRestarts,
+		initialBackoff:  defaultInitialBackoff,
+		maxBackoff:      defaultMaxBackoff,
+		maxPanicRecords: defaultMaxPanicRecords,
+		restarts:        make(map[string]uint64),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Supervise wraps a component's run function so it can be launched as a thread. The component
+// name groups the restart counts and metrics of components of the same kind, such as all edge
+// trackers, so it should not be unique per instance.
+func (s *Supervisor) Supervise(component string, run func(ctx context.Context)) func(ctx context.Context) {
+	restartsCounter := metrics.GetOrRegisterCounter("arb/validator/supervisor/restarts/"+component, nil)
+	return func(ctx context.Context) {
+		backoff := s.initialBackoff
+		for restarts := 0; ; restarts++ {
+			recovered, stack, panicked := runRecovered(ctx, run)
+			if !panicked {
+				return
+			}
+			restart := restarts < s.maxRestarts && ctx.Err() == nil
+			s.recordPanic(PanicRecord{
+				Component: component,
+				Value:     fmt.Sprint(recovered),
+				Stack:     stack,
+				Time:      time.Now(),
+				Restarted: restart,
+			})
+			if !restart {
+				log.Error("Supervised component panicked and will not be restarted",
+					"component", component,
+					"restarts", restarts,
+					"panic", recovered,
+					"stack", stack,
+				)
+				return
+			}
+			log.Error("Supervised component panicked, restarting",
+				"component", component,
+				"restarts", restarts,
+				"backoff", backoff,
+				"panic", recovered,
+				"stack", stack,
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, s.maxBackoff)
+			s.recordRestart(component)
+			restartsCounter.Inc(1)
+		}
+	}
+}
+
+func runRecovered(ctx context.Context, run func(ctx context.Context)) (recovered any, stack string, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered, stack, panicked = r, string(debug.Stack()), true
+		}
+	}()
+	run(ctx)
+	return
+}
+
+func (s *Supervisor) recordPanic(record PanicRecord) {
+	panicsCounter.Inc(1)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.panics = append(s.panics, record)
+	if len(s.panics) > s.maxPanicRecords {
+		s.panics = s.panics[len(s.panics)-s.maxPanicRecords:]
+	}
+}
+
+func (s *Supervisor) recordRestart(component string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.restarts[component]++
+}
+
+// Panics returns the latest panics recovered, oldest first.
+func (s *Supervisor) Panics() []PanicRecord {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]PanicRecord(nil), s.panics...)
+}
+
+// Restarts returns how many times components of each kind have been restarted.
+func (s *Supervisor) Restarts() map[string]uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	restarts := make(map[string]uint64, len(s.restarts))
+	for component, n := range s.restarts {
+		restarts[component] = n
+	}
+	return restarts
+}
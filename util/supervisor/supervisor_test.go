@@ -0,0 +1,64 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/offchainlabs/bold/blob/main/LICENSE
+
+package supervisor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervise_RestartsAfterPanic(t *testing.T) {
+	s := New(WithMaxRestarts(3), WithBackoff(time.Millisecond, 2*time.Millisecond))
+	runs := 0
+	s.Supervise("flaky", func(ctx context.Context) {
+		runs++
+		if runs < 3 {
+			panic("boom")
+		}
+	})(context.Background())
+
+	require.Equal(t, 3, runs)
+	require.Equal(t, map[string]uint64{"flaky": 2}, s.Restarts())
+	panics := s.Panics()
+	require.Len(t, panics, 2)
+	require.Equal(t, "flaky", panics[0].Component)
+	require.Equal(t, "boom", panics[0].Value)
+	require.Contains(t, panics[0].Stack, "TestSupervise_RestartsAfterPanic")
+	require.True(t, panics[1].Restarted)
+}
+
+func TestSupervise_GivesUpAfterMaxRestarts(t *testing.T) {
+	s := New(WithMaxRestarts(2), WithBackoff(time.Millisecond, time.Millisecond), WithMaxPanicRecords(2))
+	runs := 0
+	s.Supervise("broken", func(ctx context.Context) {
+		runs++
+		panic(runs)
+	})(context.Background())
+
+	require.Equal(t, 3, runs)
+	require.Equal(t, uint64(2), s.Restarts()["broken"])
+	panics := s.Panics()
+	require.Len(t, panics, 2)
+	require.Equal(t, "2", panics[0].Value)
+	require.Equal(t, "3", panics[1].Value)
+	require.False(t, panics[1].Restarted)
+}
+
+func TestSupervise_NotRestartedOnceCancelled(t *testing.T) {
+	s := New(WithBackoff(time.Hour, time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	s.Supervise("cancelled", func(ctx context.Context) {
+		runs++
+		cancel()
+		panic("boom")
+	})(ctx)
+
+	require.Equal(t, 1, runs)
+	require.Empty(t, s.Restarts())
+	require.Len(t, s.Panics(), 1)
+}